@@ -0,0 +1,403 @@
+// Command genmocks generates the interfaces of the repositories and services
+// and mocks implementing them. For each exported struct of a package whose
+// name ends in the given suffix, e.g. UserRepository, it writes an interface
+// of its exported methods to the package, e.g. UserStore, and to the mocks
+// package a struct with one function field per method, e.g.
+//
+//	users := &mocks.UserStore{
+//		FindByIDFunc: func(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error) {
+//			return &models.User{ID: userID}, nil
+//		},
+//	}
+//
+// A mock method that isn't stubbed panics, so a test fails on calls it
+// doesn't expect. Run through go generate, from the package directory:
+//
+//	go run ../../cmd/genmocks -suffix Repository -iface Store
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const header = "// Code generated by genmocks. DO NOT EDIT.\n\n"
+
+// component is a struct to generate an interface and a mock for
+type component struct {
+	name    string // e.g. UserRepository
+	iface   string // e.g. UserStore
+	methods []*ast.FuncDecl
+	imports map[string]string // Package name to import path, per file
+}
+
+func main() {
+	suffix := flag.String("suffix", "", "Suffix of the struct names, e.g. Repository")
+	ifaceSuffix := flag.String("iface", "", "Suffix of the interface names replacing it, e.g. Store")
+	out := flag.String("out", "interfaces.go", "File of the interfaces")
+	mocksDir := flag.String("mocks", "mocks", "Directory of the mocks package")
+	flag.Parse()
+
+	if *suffix == "" || *ifaceSuffix == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != *out
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatalf("Failed to parse package: %v", err)
+	}
+	if len(pkgs) != 1 {
+		log.Fatalf("Expected one package, found %d", len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	modulePath, pkgPath := importPath()
+	components, localTypes := collect(pkg, *suffix, *ifaceSuffix)
+
+	interfaces := generateInterfaces(fset, pkg.Name, modulePath, components)
+	if err := writeSource(*out, interfaces); err != nil {
+		log.Fatalf("Failed to write interfaces: %v", err)
+	}
+
+	mocks := generateMocks(fset, pkg.Name, modulePath, pkgPath, components, localTypes)
+	if err := os.MkdirAll(*mocksDir, 0o755); err != nil {
+		log.Fatalf("Failed to create mocks directory: %v", err)
+	}
+	if err := writeSource(filepath.Join(*mocksDir, "mocks.go"), mocks); err != nil {
+		log.Fatalf("Failed to write mocks: %v", err)
+	}
+}
+
+// collect returns the structs with the suffix and their exported methods,
+// sorted by name, and the names of the package's types
+func collect(pkg *ast.Package, suffix, ifaceSuffix string) ([]*component, map[string]bool) {
+	byName := make(map[string]*component)
+	localTypes := make(map[string]bool)
+
+	fileNames := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		for _, decl := range pkg.Files[fileName].Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				localTypes[typeSpec.Name.Name] = true
+				if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct && typeSpec.Name.IsExported() &&
+					strings.HasSuffix(typeSpec.Name.Name, suffix) && typeSpec.Name.Name != suffix {
+					byName[typeSpec.Name.Name] = &component{
+						name:    typeSpec.Name.Name,
+						iface:   strings.TrimSuffix(typeSpec.Name.Name, suffix) + ifaceSuffix,
+						imports: make(map[string]string),
+					}
+				}
+			}
+		}
+	}
+
+	for _, fileName := range fileNames {
+		file := pkg.Files[fileName]
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() {
+				continue
+			}
+			c := byName[receiverName(fn)]
+			if c == nil {
+				continue
+			}
+			c.methods = append(c.methods, fn)
+			for _, imp := range file.Imports {
+				path, _ := strconv.Unquote(imp.Path.Value)
+				name := importName(path)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				c.imports[name] = path
+			}
+		}
+	}
+
+	components := make([]*component, 0, len(byName))
+	for _, c := range byName {
+		sort.Slice(c.methods, func(i, j int) bool { return c.methods[i].Name.Name < c.methods[j].Name.Name })
+		components = append(components, c)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].name < components[j].name })
+	return components, localTypes
+}
+
+// importName returns the name a package is imported under by default,
+// assuming it is named after its path, e.g. redis for
+// github.com/redis/go-redis/v9
+func importName(path string) string {
+	name := filepath.Base(path)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = filepath.Base(filepath.Dir(path))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func receiverName(fn *ast.FuncDecl) string {
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func generateInterfaces(fset *token.FileSet, pkgName, modulePath string, components []*component) []byte {
+	var body bytes.Buffer
+	imports := make(map[string]bool)
+
+	for _, c := range components {
+		fmt.Fprintf(&body, "// %s is implemented by %s. Depend on it rather than\n// on the struct, to substitute a mock in tests.\n", c.iface, c.name)
+		fmt.Fprintf(&body, "type %s interface {\n", c.iface)
+		for _, fn := range c.methods {
+			usedImports(fn.Type, c.imports, imports)
+			fmt.Fprintf(&body, "\t%s%s\n", fn.Name.Name, signature(fset, fn.Type))
+		}
+		body.WriteString("}\n\n")
+	}
+
+	body.WriteString("var (\n")
+	for _, c := range components {
+		fmt.Fprintf(&body, "\t_ %s = (*%s)(nil)\n", c.iface, c.name)
+	}
+	body.WriteString(")\n")
+
+	var src bytes.Buffer
+	src.WriteString(header)
+	fmt.Fprintf(&src, "package %s\n\n", pkgName)
+	writeImports(&src, imports, modulePath)
+	src.Write(body.Bytes())
+	return src.Bytes()
+}
+
+func generateMocks(fset *token.FileSet, pkgName, modulePath, pkgPath string, components []*component, localTypes map[string]bool) []byte {
+	var body bytes.Buffer
+	imports := map[string]bool{modulePath + "/" + pkgPath: true}
+
+	for _, c := range components {
+		fields := make([]string, 0, len(c.methods))
+		for _, fn := range c.methods {
+			qualify(fn.Type, pkgName, localTypes)
+			usedImports(fn.Type, c.imports, imports)
+			fields = append(fields, fmt.Sprintf("\t%sFunc func%s\n", fn.Name.Name, signature(fset, fn.Type)))
+		}
+
+		fmt.Fprintf(&body, "// %s is a mock of %s.%s\n", c.iface, pkgName, c.iface)
+		fmt.Fprintf(&body, "type %s struct {\n%s}\n\n", c.iface, strings.Join(fields, ""))
+
+		for _, fn := range c.methods {
+			params, args := namedParams(fset, fn.Type)
+			results := ""
+			if fn.Type.Results != nil {
+				results = " " + strings.TrimPrefix(node(fset, &ast.FuncType{Params: &ast.FieldList{}, Results: fn.Type.Results}), "func() ")
+			}
+			fmt.Fprintf(&body, "// %s calls %sFunc\n", fn.Name.Name, fn.Name.Name)
+			fmt.Fprintf(&body, "func (mock *%s) %s(%s)%s {\n", c.iface, fn.Name.Name, params, results)
+			fmt.Fprintf(&body, "\tif mock.%sFunc == nil {\n\t\tpanic(\"%s.%s is not stubbed\")\n\t}\n", fn.Name.Name, c.iface, fn.Name.Name)
+			call := fmt.Sprintf("mock.%sFunc(%s)", fn.Name.Name, args)
+			if fn.Type.Results != nil {
+				fmt.Fprintf(&body, "\treturn %s\n}\n\n", call)
+			} else {
+				fmt.Fprintf(&body, "\t%s\n}\n\n", call)
+			}
+		}
+	}
+
+	body.WriteString("var (\n")
+	for _, c := range components {
+		fmt.Fprintf(&body, "\t_ %s.%s = (*%s)(nil)\n", pkgName, c.iface, c.iface)
+	}
+	body.WriteString(")\n")
+
+	var src bytes.Buffer
+	src.WriteString(header)
+	fmt.Fprintf(&src, "// Package mocks implements the interfaces of package %s with\n// function fields, for unit tests.\n", pkgName)
+	src.WriteString("package mocks\n\n")
+	writeImports(&src, imports, modulePath)
+	src.Write(body.Bytes())
+	return src.Bytes()
+}
+
+// signature returns a method's parameters and results, e.g.
+// "(ctx context.Context) error"
+func signature(fset *token.FileSet, fnType *ast.FuncType) string {
+	return strings.TrimPrefix(node(fset, fnType), "func")
+}
+
+// namedParams returns a method's parameter list, naming unnamed parameters,
+// and the arguments passing them on
+func namedParams(fset *token.FileSet, fnType *ast.FuncType) (string, string) {
+	var params, args []string
+	i := 0
+	for _, field := range fnType.Params.List {
+		typ := node(fset, field.Type)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
+		}
+		for _, name := range names {
+			n := name.Name
+			if n == "_" || n == "mock" {
+				n = fmt.Sprintf("arg%d", i)
+			}
+			i++
+			params = append(params, n+" "+typ)
+			if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+				n += "..."
+			}
+			args = append(args, n)
+		}
+	}
+	return strings.Join(params, ", "), strings.Join(args, ", ")
+}
+
+// qualify prefixes the package's own types in a method's signature with the
+// package name, for use from the mocks package
+func qualify(fnType *ast.FuncType, pkgName string, localTypes map[string]bool) {
+	var fields []*ast.Field
+	fields = append(fields, fnType.Params.List...)
+	if fnType.Results != nil {
+		fields = append(fields, fnType.Results.List...)
+	}
+	for _, field := range fields {
+		ast.Inspect(field.Type, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				return false
+			case *ast.Field:
+				// Field names of inline structs and funcs aren't types
+				ast.Inspect(n.Type, func(m ast.Node) bool {
+					if ident, ok := m.(*ast.Ident); ok && localTypes[ident.Name] {
+						ident.Name = pkgName + "." + ident.Name
+					}
+					_, selector := m.(*ast.SelectorExpr)
+					return !selector
+				})
+				return false
+			case *ast.Ident:
+				if localTypes[n.Name] {
+					n.Name = pkgName + "." + n.Name
+				}
+			}
+			return true
+		})
+	}
+}
+
+// usedImports adds the import paths of the packages a signature refers to
+func usedImports(fnType *ast.FuncType, fileImports map[string]string, imports map[string]bool) {
+	ast.Inspect(fnType, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if path, ok := fileImports[ident.Name]; ok {
+					imports[path] = true
+				}
+			}
+			return false
+		}
+		return true
+	})
+}
+
+func writeImports(buf *bytes.Buffer, imports map[string]bool, modulePath string) {
+	if len(imports) == 0 {
+		return
+	}
+	var std, other []string
+	for path := range imports {
+		if first := strings.Split(path, "/")[0]; strings.Contains(first, ".") || first == modulePath {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	// The standard library first, then the other packages
+	buf.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(buf, "\t%q\n", path)
+	}
+	buf.WriteString("\n")
+	for _, path := range other {
+		fmt.Fprintf(buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+}
+
+func node(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, n); err != nil {
+		log.Fatalf("Failed to print node: %v", err)
+	}
+	return buf.String()
+}
+
+func writeSource(path string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w\n%s", path, err, src)
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// importPath returns the module path and the path of the current directory
+// within the module
+func importPath() (string, string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Failed to get working directory: %v", err)
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "module ") {
+					rel, _ := filepath.Rel(root, dir)
+					return strings.TrimSpace(strings.TrimPrefix(line, "module ")), filepath.ToSlash(rel)
+				}
+			}
+		}
+		if filepath.Dir(root) == root {
+			log.Fatalf("No go.mod found above %s", dir)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+)
+
+func main() {
+	flags := flag.NewFlagSet("mask-data", flag.ExitOnError)
+	rulesPath := flags.String("rules", "", "JSON file with masking rules (defaults to the built-in rules)")
+	dryRun := flags.Bool("dry-run", false, "Print the masking statements without running them")
+	confirm := flags.Bool("confirm", false, "Confirm that the configured database is a copy that may be masked")
+	showRules := flags.Bool("show-rules", false, "Print the built-in masking rules as JSON and exit")
+	flags.Usage = printUsage
+	flags.Parse(os.Args[1:])
+
+	if *showRules {
+		printDefaultRules()
+		return
+	}
+
+	// Load configuration (primary database + DB_REGION_URLS)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.Server.Environment == "production" {
+		log.Fatal("Refusing to mask data with ENVIRONMENT=production")
+	}
+	if !*confirm && !*dryRun {
+		log.Printf("Masking rewrites personal data in %s on %s and every region in DB_REGION_URLS.", cfg.Database.Database, cfg.Database.Host)
+		log.Fatal("Pass --confirm to proceed, or --dry-run to preview")
+	}
+
+	rules := database.DefaultMaskingRules()
+	if *rulesPath != "" {
+		rules, err = database.LoadMaskingRules(*rulesPath)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	router, err := database.ConnectRegions(db, cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to regions: %v", err)
+	}
+	defer router.Close()
+
+	ctx := context.Background()
+	opts := database.MaskDatabaseOptions{
+		DryRun: *dryRun,
+		Logf:   log.Printf,
+	}
+
+	for _, region := range router.Regions() {
+		regionDB, err := router.RegionDB(region)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+
+		log.Printf("Masking region %s", region)
+		if err := database.MaskDatabase(ctx, regionDB, rules, opts); err != nil {
+			log.Fatalf("❌ Masking failed: %v", err)
+		}
+	}
+
+	if *dryRun {
+		log.Println("✅ Dry run complete, nothing was changed")
+		return
+	}
+	log.Println("✅ Personal data masked")
+}
+
+func printDefaultRules() {
+	data, err := json.MarshalIndent(database.DefaultMaskingRules(), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode rules: %v", err)
+	}
+	fmt.Println(string(data))
+}
+
+func printUsage() {
+	fmt.Println("Usage: mask-data [--rules FILE] [--dry-run] [--confirm] [--show-rules]")
+	fmt.Println("")
+	fmt.Println("Scrambles personal data (names, emails, phone numbers, addresses, tax and")
+	fmt.Println("registration numbers, request metadata) in a copy of the database, so that")
+	fmt.Println("staging environments can be refreshed from production safely.")
+	fmt.Println("")
+	fmt.Println("Options:")
+	fmt.Println("  --rules FILE     JSON array of {\"table\", \"column\", \"strategy\", \"value\"} rules")
+	fmt.Println("  --dry-run        Print the statements without running them")
+	fmt.Println("  --confirm        Required to actually mask the configured database")
+	fmt.Println("  --show-rules     Print the built-in rules (a starting point for --rules)")
+	fmt.Println("")
+	fmt.Println("Strategies: email, name, phone, text (hashed, stable per value), null, fixed (value).")
+	fmt.Println("The command refuses to run with ENVIRONMENT=production.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  mask-data --dry-run")
+	fmt.Println("  mask-data --confirm")
+	fmt.Println("  mask-data --show-rules > masking-rules.json && mask-data --rules masking-rules.json --confirm")
+}
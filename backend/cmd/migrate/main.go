@@ -1,14 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"myerp-v2/internal/migrator"
+)
+
+const (
+	migrationsDir = "migrations"
+	backfillsDir  = "migrations/backfills"
 )
 
 func main() {
@@ -17,12 +33,34 @@ func main() {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
-	// Get database URL from environment
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL environment variable is required")
+	// Parse command line arguments
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	// check only connects when it has to compare against the applied version,
+	// so "check --since N" can run in CI without a database
+	if command == "check" {
+		runCheck(os.Args[2:])
+		return
+	}
+	// create only writes files
+	if command == "create" {
+		runCreate(os.Args[2:])
+		return
+	}
+
+	args := os.Args[2:]
+	var opts options
+	if command != "backfill" {
+		opts, args = parseOptions(args)
 	}
 
+	dbURL := databaseURL()
+
 	// Create migration instance
 	m, err := migrate.New(
 		"file://migrations",
@@ -33,39 +71,38 @@ func main() {
 	}
 	defer m.Close()
 
-	// Parse command line arguments
-	if len(os.Args) < 2 {
-		printUsage()
-		os.Exit(1)
-	}
-
-	command := os.Args[1]
-
 	switch command {
 	case "up":
-		if err := m.Up(); err != nil {
-			if err == migrate.ErrNoChange {
-				log.Println("✅ No migrations to apply")
-				return
+		// up      -> every pending migration (post-deploy)
+		// up pre  -> only migrations that are safe before the new code is deployed
+		phase := migrator.PhasePost
+		if len(args) > 0 {
+			phase = migrator.Phase(args[0])
+			if phase != migrator.PhasePre && phase != migrator.PhasePost {
+				log.Fatalf("Unknown phase %q (expected pre or post)", args[0])
 			}
-			log.Fatalf("Failed to apply migrations: %v", err)
 		}
-		log.Println("✅ Migrations applied successfully")
+		runUp(m, dbURL, phase, opts)
 
 	case "down":
 		steps := 1
-		if len(os.Args) > 2 {
-			fmt.Sscanf(os.Args[2], "%d", &steps)
+		if len(args) > 0 {
+			fmt.Sscanf(args[0], "%d", &steps)
 		}
+		runDown(m, steps, opts)
 
-		if err := m.Steps(-steps); err != nil {
-			if err == migrate.ErrNoChange {
-				log.Println("✅ No migrations to rollback")
-				return
-			}
-			log.Fatalf("Failed to rollback migrations: %v", err)
+	case "goto":
+		if len(args) < 1 {
+			log.Fatal("Usage: migrate goto VERSION [--dry-run] [--yes]")
 		}
-		log.Printf("✅ Rolled back %d migration(s)\n", steps)
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q", args[0])
+		}
+		runGoto(m, uint(version), opts)
+
+	case "status":
+		runStatus(m)
 
 	case "version":
 		version, dirty, err := m.Version()
@@ -79,17 +116,26 @@ func main() {
 		}
 
 	case "force":
-		if len(os.Args) < 3 {
-			log.Fatal("Usage: migrate force VERSION")
+		if len(args) < 1 {
+			log.Fatal("Usage: migrate force VERSION [--dry-run] [--yes]")
 		}
 		var version int
-		fmt.Sscanf(os.Args[2], "%d", &version)
+		fmt.Sscanf(args[0], "%d", &version)
+
+		if opts.dryRun {
+			log.Printf("Would force version to %d without running migrations", version)
+			return
+		}
+		confirm(fmt.Sprintf("Force version to %d without running migrations?", version), opts)
 
 		if err := m.Force(version); err != nil {
 			log.Fatalf("Failed to force version: %v", err)
 		}
 		log.Printf("✅ Forced version to %d\n", version)
 
+	case "backfill":
+		runBackfill(dbURL, args)
+
 	default:
 		log.Printf("Unknown command: %s\n", command)
 		printUsage()
@@ -97,19 +143,465 @@ func main() {
 	}
 }
 
+// runUp applies pending migrations up to the furthest version allowed by the
+// phase and by the backfills the migrations depend on
+func runUp(m *migrate.Migrate, dbURL string, phase migrator.Phase, opts options) {
+	migrations, err := migrator.Load(migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	current := currentVersion(m)
+	pending := migrator.Pending(migrations, current)
+	if len(pending) == 0 {
+		log.Println("✅ No migrations to apply")
+		return
+	}
+
+	ctx := context.Background()
+	var runner *migrator.BackfillRunner
+	backfillDone := func(name string) (bool, error) {
+		if runner == nil {
+			runner = migrator.NewBackfillRunner(openDB(dbURL))
+			if err := runner.EnsureTable(ctx); err != nil {
+				return false, err
+			}
+		}
+		return runner.IsCompleted(ctx, name)
+	}
+
+	target, stopReason, err := migrator.Target(pending, current, phase, backfillDone)
+	if err != nil {
+		log.Fatalf("Failed to plan migrations: %v", err)
+	}
+
+	if target > current && opts.dryRun {
+		printPlan("Would apply", migrator.Between(migrations, current, target))
+	} else if target > current {
+		if err := m.Migrate(target); err != nil && err != migrate.ErrNoChange {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+		log.Printf("✅ Migrated from version %d to %d", current, target)
+	}
+
+	if stopReason != "" {
+		log.Printf("⏸️  Stopped before pending migrations: %s", stopReason)
+		return
+	}
+	if target == current {
+		log.Println("✅ No migrations to apply")
+	}
+}
+
+// runDown rolls back the given number of migrations, after confirmation
+func runDown(m *migrate.Migrate, steps int, opts options) {
+	migrations, err := migrator.Load(migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	applied := migrator.Applied(migrations, currentVersion(m))
+	if steps > len(applied) {
+		steps = len(applied)
+	}
+	if steps <= 0 {
+		log.Println("✅ No migrations to rollback")
+		return
+	}
+
+	if opts.dryRun {
+		printPlan("Would roll back", applied[:steps])
+		return
+	}
+	printPlan("Rolling back", applied[:steps])
+	confirm(fmt.Sprintf("Roll back %d migration(s)?", steps), opts)
+
+	if err := m.Steps(-steps); err != nil {
+		if err == migrate.ErrNoChange {
+			log.Println("✅ No migrations to rollback")
+			return
+		}
+		log.Fatalf("Failed to rollback migrations: %v", err)
+	}
+	log.Printf("✅ Rolled back %d migration(s)\n", steps)
+}
+
+// runGoto migrates up or down to a version. Unlike up, it doesn't stop for
+// post-deploy migrations or pending backfills; rolling back asks for
+// confirmation.
+func runGoto(m *migrate.Migrate, version uint, opts options) {
+	migrations, err := migrator.Load(migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	known := false
+	for _, mig := range migrations {
+		known = known || mig.Version == version
+	}
+	if !known {
+		log.Fatalf("Unknown migration version %d (see 'migrate status')", version)
+	}
+
+	current := currentVersion(m)
+	if version == current {
+		log.Printf("✅ Already at version %d", version)
+		return
+	}
+
+	steps := migrator.Between(migrations, current, version)
+	switch {
+	case opts.dryRun && version > current:
+		printPlan("Would apply", steps)
+		return
+	case opts.dryRun:
+		printPlan("Would roll back", steps)
+		return
+	case version > current:
+		printPlan("Applying", steps)
+	default:
+		printPlan("Rolling back", steps)
+		confirm(fmt.Sprintf("Roll back %d migration(s) to version %d?", len(steps), version), opts)
+	}
+
+	if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+		log.Fatalf("Failed to migrate to version %d: %v", version, err)
+	}
+	log.Printf("✅ Migrated from version %d to %d", current, version)
+}
+
+// runStatus lists every migration as applied or pending
+func runStatus(m *migrate.Migrate) {
+	migrations, err := migrator.Load(migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		log.Fatalf("Failed to get version: %v", err)
+	}
+
+	pending := 0
+	for _, mig := range migrations {
+		state := "applied"
+		switch {
+		case mig.Version > version:
+			state = "pending"
+			pending++
+		case dirty && mig.Version == version:
+			state = "dirty"
+		}
+		fmt.Printf("%-8s %s\n", state, describe(mig))
+	}
+
+	fmt.Println("")
+	if dirty {
+		fmt.Printf("Current version: %d (dirty; fix it and run 'migrate force VERSION')\n", version)
+	} else {
+		fmt.Printf("Current version: %d\n", version)
+	}
+	fmt.Printf("%d applied, %d pending\n", len(migrations)-pending, pending)
+}
+
+// runCreate writes the up and down files of a new migration
+//
+//	create NAME [--phase post]
+func runCreate(args []string) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	phase := fs.String("phase", string(migrator.PhasePre), "pre (default) or post: when the migration runs relative to a deploy")
+
+	// The name may come before or after the flags
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if name == "" {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		log.Fatal("Usage: migrate create NAME [--phase post]")
+	}
+
+	paths, err := migrator.Create(migrationsDir, name, migrator.Phase(*phase), time.Now())
+	if err != nil {
+		log.Fatalf("Failed to create migration: %v", err)
+	}
+	for _, path := range paths {
+		log.Printf("✅ Created %s", path)
+	}
+}
+
+// printPlan lists the migrations a command runs, in order
+func printPlan(action string, migrations []migrator.Migration) {
+	log.Printf("%s %d migration(s):", action, len(migrations))
+	for _, mig := range migrations {
+		fmt.Printf("  %s\n", describe(mig))
+	}
+}
+
+// describe names a migration as its files do, with its phase when post-deploy
+func describe(mig migrator.Migration) string {
+	if mig.Phase == migrator.PhasePost {
+		return fmt.Sprintf("%03d_%s (post-deploy)", mig.Version, mig.Name)
+	}
+	return fmt.Sprintf("%03d_%s", mig.Version, mig.Name)
+}
+
+// options are the flags of the commands that change the schema. They may
+// come before or after the command's arguments: migrate down 3 --dry-run
+type options struct {
+	dryRun bool // Print what would run without changing anything
+	yes    bool // Confirm without asking, for CI pipelines
+}
+
+func parseOptions(args []string) (options, []string) {
+	var opts options
+	rest := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "dry-run":
+			opts.dryRun = true
+		case "yes", "y":
+			opts.yes = true
+		default:
+			log.Fatalf("Unknown flag %s (expected --dry-run or --yes)", arg)
+		}
+	}
+	return opts, rest
+}
+
+// confirm asks before a change that can lose data. --yes answers for CI
+// pipelines; without it and without an answer on stdin, it aborts.
+func confirm(question string, opts options) {
+	if opts.yes {
+		return
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return
+	}
+	log.Fatal("Aborted (pass --yes to confirm without a prompt)")
+}
+
+// runCheck lints migrations for statements that break the running version
+//
+//	check              pending migrations (needs the database)
+//	check --since N    migrations newer than version N (e.g. in CI)
+//	check --all        every migration
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	all := fs.Bool("all", false, "check every migration")
+	since := fs.Uint("since", 0, "check migrations newer than this version")
+	fs.Parse(args)
+
+	migrations, err := migrator.Load(migrationsDir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch {
+	case *all:
+	case *since > 0:
+		migrations = migrator.Pending(migrations, *since)
+	default:
+		m, err := migrate.New("file://migrations", databaseURL())
+		if err != nil {
+			log.Fatalf("Failed to create migration instance: %v", err)
+		}
+		migrations = migrator.Pending(migrations, currentVersion(m))
+		m.Close()
+	}
+
+	for _, mig := range migrations {
+		fmt.Printf("%03d_%s (%s-deploy)\n", mig.Version, mig.Name, mig.Phase)
+	}
+
+	findings := migrator.Check(migrations)
+	if len(findings) > 0 {
+		fmt.Println("")
+	}
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+
+	if migrator.HasErrors(findings) {
+		fmt.Println("")
+		fmt.Println("❌ Destructive statements in pre-deploy migrations. Move them to a")
+		fmt.Println("   '-- migrate:phase post' migration, or acknowledge them with")
+		fmt.Println("   '-- migrate:allow-destructive'.")
+		os.Exit(1)
+	}
+	log.Printf("✅ %d migration(s) checked, %d warning(s)", len(migrations), len(findings))
+}
+
+// runBackfill manages resumable data backfills
+func runBackfill(dbURL string, args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate backfill list | run NAME [flags] | reset NAME")
+	}
+
+	backfills, err := migrator.LoadBackfills(backfillsDir)
+	if err != nil {
+		log.Fatalf("Failed to load backfills: %v", err)
+	}
+
+	// Stop cleanly between batches on Ctrl+C; progress is kept
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runner := migrator.NewBackfillRunner(openDB(dbURL))
+	if err := runner.EnsureTable(ctx); err != nil {
+		log.Fatalf("Failed to prepare backfills: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		if len(backfills) == 0 {
+			fmt.Println("No backfills defined in " + backfillsDir)
+		}
+		for _, backfill := range backfills {
+			progress, err := runner.Progress(ctx, backfill.Name)
+			if err != nil {
+				log.Fatalf("Failed to get progress: %v", err)
+			}
+			if progress == nil {
+				fmt.Printf("%-40s not started\n", backfill.Name)
+				continue
+			}
+			fmt.Printf("%-40s %-10s %d rows (updated %s)\n", backfill.Name, progress.Status, progress.RowsProcessed, progress.UpdatedAt.Format(time.RFC3339))
+			if progress.LastError.Valid {
+				fmt.Printf("%-40s last error: %s\n", "", progress.LastError.String)
+			}
+		}
+
+	case "run":
+		fs := flag.NewFlagSet("backfill run", flag.ExitOnError)
+		batchSize := fs.Int("batch-size", 0, "rows per batch (default: from the backfill file)")
+		pause := fs.Duration("pause", 100*time.Millisecond, "pause between batches")
+		maxBatches := fs.Int("max-batches", 0, "stop after N batches (0 = until complete)")
+		if len(args) < 2 {
+			log.Fatal("Usage: migrate backfill run NAME [-batch-size N] [-pause D] [-max-batches N]")
+		}
+		fs.Parse(args[2:])
+
+		backfill := findBackfill(backfills, args[1])
+		err := runner.Run(ctx, backfill, migrator.BackfillOptions{
+			BatchSize:  *batchSize,
+			Pause:      *pause,
+			MaxBatches: *maxBatches,
+			Logf:       log.Printf,
+		})
+		if err == context.Canceled {
+			log.Printf("⏸️  Backfill %s interrupted; run it again to resume", backfill.Name)
+			return
+		}
+		if err != nil {
+			log.Fatalf("❌ Backfill failed: %v", err)
+		}
+
+	case "reset":
+		if len(args) < 2 {
+			log.Fatal("Usage: migrate backfill reset NAME")
+		}
+		backfill := findBackfill(backfills, args[1])
+		if err := runner.Reset(ctx, backfill.Name); err != nil {
+			log.Fatalf("Failed to reset backfill: %v", err)
+		}
+		log.Printf("✅ Backfill %s reset", backfill.Name)
+
+	default:
+		log.Fatalf("Unknown backfill command: %s", args[0])
+	}
+}
+
+func findBackfill(backfills []migrator.Backfill, name string) migrator.Backfill {
+	for _, backfill := range backfills {
+		if backfill.Name == name {
+			return backfill
+		}
+	}
+	log.Fatalf("Unknown backfill %q (see 'migrate backfill list')", name)
+	return migrator.Backfill{}
+}
+
+// currentVersion returns the applied migration version (0 when none ran yet)
+func currentVersion(m *migrate.Migrate) uint {
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0
+	}
+	if err != nil {
+		log.Fatalf("Failed to get version: %v", err)
+	}
+	if dirty {
+		log.Fatalf("Database is dirty at version %d; fix it and run 'migrate force VERSION'", version)
+	}
+	return version
+}
+
+// databaseURL reads DATABASE_URL from the environment
+func databaseURL() string {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable is required")
+	}
+	return dbURL
+}
+
+func openDB(dbURL string) *sqlx.DB {
+	db, err := sqlx.Connect("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	return db
+}
+
 func printUsage() {
 	fmt.Println("Usage: migrate <command> [arguments]")
 	fmt.Println("")
 	fmt.Println("Commands:")
-	fmt.Println("  up              Apply all pending migrations")
+	fmt.Println("  up [pre|post]   Apply pending migrations; 'pre' stops before post-deploy ones")
 	fmt.Println("  down [N]        Rollback N migrations (default: 1)")
+	fmt.Println("  goto VERSION    Migrate up or down to VERSION")
+	fmt.Println("  status          List applied and pending migrations")
 	fmt.Println("  version         Show current migration version")
 	fmt.Println("  force VERSION   Force set migration version (use with caution)")
+	fmt.Println("  create NAME [--phase post]")
+	fmt.Println("                  Write timestamped up and down files for a new migration")
+	fmt.Println("  check [--all | --since VERSION]")
+	fmt.Println("                  Flag destructive statements in pre-deploy migrations")
+	fmt.Println("  backfill list | run NAME | reset NAME")
+	fmt.Println("                  Manage resumable data backfills (migrations/backfills)")
+	fmt.Println("")
+	fmt.Println("Flags of up, down, goto and force:")
+	fmt.Println("  --dry-run       List the migrations that would run, without running them")
+	fmt.Println("  --yes           Skip the confirmation of down, goto (to a lower version) and force")
+	fmt.Println("")
+	fmt.Println("Zero-downtime deploys (see docs/MIGRATIONS.md):")
+	fmt.Println("  1. migrate up pre        expand the schema while the old version runs")
+	fmt.Println("  2. deploy the new version")
+	fmt.Println("  3. migrate backfill run NAME   for any pending data backfills")
+	fmt.Println("  4. migrate up            contract: apply post-deploy migrations")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  migrate up")
 	fmt.Println("  migrate down")
 	fmt.Println("  migrate down 3")
+	fmt.Println("  migrate down 2 --dry-run")
+	fmt.Println("  migrate goto 70 --yes")
+	fmt.Println("  migrate status")
+	fmt.Println("  migrate create add_invoice_notes")
 	fmt.Println("  migrate version")
 	fmt.Println("  migrate force 5")
+	fmt.Println("  migrate check --since 23")
+	fmt.Println("  migrate backfill run users_fill_display_name -batch-size 500")
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	// Users live in their tenant's data region
+	if len(cfg.Regions.DatabaseURLs) > 0 {
+		regionRouter, err := database.ConnectRegions(db, cfg)
+		if err != nil {
+			log.Fatalf("Failed to connect to data regions: %v", err)
+		}
+		defer regionRouter.Close()
+
+		database.SetRegionRouter(regionRouter)
+	}
+
+	tenantRepo := repository.NewTenantRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	platformRepo := repository.NewPlatformRepository(db)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "list":
+		admins, err := platformRepo.ListAdmins(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list platform admins: %v", err)
+		}
+		if len(admins) == 0 {
+			fmt.Println("No platform admins")
+		}
+		for _, admin := range admins {
+			fmt.Printf("%s  %s  (tenant %s, since %s)\n", admin.UserID, admin.Email, admin.TenantID, admin.CreatedAt.UTC().Format(time.RFC3339))
+		}
+
+	case "grant", "revoke":
+		if len(os.Args) < 4 {
+			log.Fatalf("Usage: platform-admin %s TENANT_SLUG EMAIL", os.Args[1])
+		}
+
+		tenant, err := tenantRepo.FindBySlug(ctx, os.Args[2])
+		if err != nil {
+			log.Fatalf("Failed to find tenant: %v", err)
+		}
+		user, err := userRepo.FindByEmail(ctx, tenant.ID, os.Args[3])
+		if err != nil {
+			log.Fatalf("Failed to find user: %v", err)
+		}
+
+		if os.Args[1] == "revoke" {
+			if err := platformRepo.RevokeAdmin(ctx, user.ID); err != nil {
+				log.Fatalf("❌ Revoke failed: %v", err)
+			}
+			log.Printf("✅ %s is no longer a platform admin", user.Email)
+			return
+		}
+
+		admin := &models.PlatformAdmin{UserID: user.ID, TenantID: tenant.ID, Email: user.Email}
+		if err := platformRepo.GrantAdmin(ctx, admin); err != nil {
+			log.Fatalf("❌ Grant failed: %v", err)
+		}
+		log.Printf("✅ %s is a platform admin", user.Email)
+		if !user.TwoFactorEnabled {
+			log.Printf("⚠️  %s has no authenticator app set up: /admin routes stay refused until they set up 2FA or register a security key", user.Email)
+		}
+
+	default:
+		log.Printf("Unknown command: %s\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: platform-admin <command> [arguments]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  list                           List platform admins")
+	fmt.Println("  grant TENANT_SLUG EMAIL        Make a user a platform admin")
+	fmt.Println("  revoke TENANT_SLUG EMAIL       Remove a user's platform administration")
+	fmt.Println("")
+	fmt.Println("Platform admins sign in to their tenant as usual and manage every tenant")
+	fmt.Println("through /api/admin/tenants and /api/admin/stats. They need a second factor")
+	fmt.Println("(2FA or a security key) to use them.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  platform-admin grant acme-ops ops@acme.example")
+	fmt.Println("  platform-admin revoke acme-ops ops@acme.example")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,16 +13,38 @@ import (
 
 	"myerp-v2/internal/config"
 	"myerp-v2/internal/database"
+	"myerp-v2/internal/diagnostics"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/metrics"
 	"myerp-v2/internal/server"
+	"myerp-v2/internal/storage"
+	"myerp-v2/internal/tracing"
 )
 
 func main() {
+	// "server diagnose" checks the deployment instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "diagnose" {
+		os.Exit(runDiagnose(os.Args[2:]))
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Queries slower than this enter the slow-query log
+	metrics.SetSlowQueryThreshold(cfg.Metrics.SlowQueryThreshold)
+
+	// Export traces over OTLP when enabled
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, cfg.Server.Environment)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	if cfg.Tracing.Enabled {
+		log.Printf("✅ Exporting traces to %s", cfg.Tracing.Endpoint)
+	}
+
 	// Initialize PostgreSQL connection
 	db, err := database.NewPostgresDB(&cfg.Database)
 	if err != nil {
@@ -31,6 +54,28 @@ func main() {
 
 	log.Println("✅ Connected to PostgreSQL")
 
+	// Connect additional data regions (tenant data residency)
+	if len(cfg.Regions.DatabaseURLs) > 0 {
+		regionRouter, err := database.ConnectRegions(db, cfg)
+		if err != nil {
+			log.Fatalf("Failed to connect to data regions: %v", err)
+		}
+		defer regionRouter.Close()
+
+		database.SetRegionRouter(regionRouter)
+		log.Printf("✅ Connected to data regions: %v", regionRouter.Regions())
+	}
+
+	// Connect the read replica, if any; read-only transactions go to it
+	replica, err := database.ConnectReplica(db, &cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to read replica: %v", err)
+	}
+	if replica != nil {
+		defer replica.Close()
+		log.Println("✅ Connected to read replica")
+	}
+
 	// Initialize Redis connection
 	redisClient, err := database.NewRedisClient(&cfg.Redis)
 	if err != nil {
@@ -40,10 +85,38 @@ func main() {
 
 	log.Println("✅ Connected to Redis")
 
+	// Initialize file storage
+	fileStorage, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	log.Printf("✅ File storage: %s", fileStorage.Name())
+
+	// Initialize the email provider
+	mailer, err := mail.New(&cfg.Email)
+	if err != nil {
+		log.Fatalf("Failed to initialize email provider: %v", err)
+	}
+
+	log.Printf("✅ Email provider: %s", mailer.Name())
+
 	// Initialize HTTP router with all dependencies
-	router := server.NewRouter(db, redisClient, cfg)
+	router := server.NewRouter(db, redisClient, fileStorage, mailer, cfg)
 	handler := router.Setup() // Call Setup() to configure routes
 
+	// Start background jobs. Every replica may run them; advisory locks make
+	// sure each job executes on only one instance at a time.
+	if cfg.Jobs.Enabled {
+		if err := router.Jobs().Start(context.Background()); err != nil {
+			log.Fatalf("Failed to start background jobs: %v", err)
+		}
+	}
+
+	// Every replica writes the request cost and usage it accounted for
+	router.Performance().Start(context.Background())
+	router.Usage().Start(context.Background())
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -61,7 +134,7 @@ func main() {
 		log.Printf("💾 Redis: localhost:26379")
 		log.Println("---")
 		log.Println("API Endpoints:")
-		log.Println("  Health:        GET  /health")
+		log.Println("  Health:        GET  /health/live, /health/ready")
 		log.Println("  Auth:          POST /api/auth/register, /login, /logout")
 		log.Println("  Users:         GET  /api/users")
 		log.Println("  Roles:         GET  /api/roles")
@@ -82,6 +155,8 @@ func main() {
 
 	log.Println("🛑 Shutting down server...")
 
+	router.Jobs().Stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
@@ -89,5 +164,70 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	router.Performance().Stop()
+	router.Usage().Stop()
+
+	// Flush the spans not yet exported
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("⚠️  Failed to flush traces: %v", err)
+	}
+
 	log.Println("✅ Server exited gracefully")
 }
+
+// runDiagnose checks the databases, Redis, SMTP server, encryption key and
+// clocks, prints a report and returns the exit code: 1 if a check failed
+func runDiagnose(args []string) int {
+	flags := flag.NewFlagSet("diagnose", flag.ExitOnError)
+	migrationsDir := flags.String("migrations", "migrations", "Directory of the migrations shipped with this server")
+	timeout := flags.Duration("timeout", time.Minute, "Time allowed for all checks")
+	flags.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("[FAIL] configuration                %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	databases := []diagnostics.Database{}
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		databases = append(databases, diagnostics.Database{Name: cfg.Regions.DefaultRegion, Err: err})
+	} else {
+		defer db.Close()
+		databases = append(databases, diagnostics.Database{Name: cfg.Regions.DefaultRegion, DB: db})
+
+		// Other data regions are checked too
+		if len(cfg.Regions.DatabaseURLs) > 0 {
+			regionRouter, err := database.ConnectRegions(db, cfg)
+			if err != nil {
+				databases = append(databases, diagnostics.Database{Name: "regions", Err: err})
+			} else {
+				defer regionRouter.Close()
+				for _, name := range regionRouter.Regions() {
+					if name == regionRouter.DefaultRegion() {
+						continue
+					}
+					regionDB, err := regionRouter.RegionDB(name)
+					databases = append(databases, diagnostics.Database{Name: name, DB: regionDB, Err: err})
+				}
+			}
+		}
+	}
+
+	redisClient, redisErr := database.NewRedisClient(&cfg.Redis)
+	if redisErr == nil {
+		defer redisClient.Close()
+	}
+
+	report := diagnostics.New(cfg, databases, redisClient, redisErr, *migrationsDir).Run(ctx)
+	report.Print(os.Stdout)
+
+	if report.HasFailures() {
+		return 1
+	}
+	return 0
+}
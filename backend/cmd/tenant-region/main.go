@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Load configuration (primary database + DB_REGION_URLS)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	router, err := database.ConnectRegions(db, cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to regions: %v", err)
+	}
+	defer router.Close()
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "regions":
+		for _, name := range router.Regions() {
+			if name == router.DefaultRegion() {
+				fmt.Printf("%s (default)\n", name)
+			} else {
+				fmt.Println(name)
+			}
+		}
+
+	case "show":
+		if len(os.Args) < 3 {
+			log.Fatal("Usage: tenant-region show TENANT_ID")
+		}
+		tenantID := parseTenantID(os.Args[2])
+
+		region, err := router.TenantRegion(ctx, tenantID)
+		if err != nil {
+			log.Fatalf("Failed to resolve region: %v", err)
+		}
+		fmt.Println(region)
+
+	case "move":
+		if len(os.Args) < 4 {
+			log.Fatal("Usage: tenant-region move TENANT_ID REGION [DRAIN_WAIT]")
+		}
+		tenantID := parseTenantID(os.Args[2])
+		targetRegion := os.Args[3]
+
+		// Default drain wait covers the region cache of running servers
+		drainWait := cfg.Regions.CacheTTL + 5*time.Second
+		if len(os.Args) > 4 {
+			drainWait, err = time.ParseDuration(os.Args[4])
+			if err != nil {
+				log.Fatalf("Invalid drain wait: %v", err)
+			}
+		}
+
+		log.Printf("Moving tenant %s to region %s", tenantID, targetRegion)
+		err := database.MoveTenant(ctx, router, tenantID, targetRegion, database.MoveTenantOptions{
+			DrainWait: drainWait,
+			Logf:      log.Printf,
+		})
+		if err != nil {
+			log.Fatalf("❌ Move failed: %v", err)
+		}
+		log.Printf("✅ Tenant %s now lives in region %s", tenantID, targetRegion)
+
+	default:
+		log.Printf("Unknown command: %s\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func parseTenantID(value string) uuid.UUID {
+	tenantID, err := uuid.Parse(value)
+	if err != nil {
+		log.Fatalf("Invalid tenant ID: %v", err)
+	}
+	return tenantID
+}
+
+func printUsage() {
+	fmt.Println("Usage: tenant-region <command> [arguments]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  regions                                List configured data regions")
+	fmt.Println("  show TENANT_ID                         Show the region holding a tenant's data")
+	fmt.Println("  move TENANT_ID REGION [DRAIN_WAIT]     Move a tenant's data to another region")
+	fmt.Println("")
+	fmt.Println("Regions are configured with DB_REGION_URLS (e.g. eu=postgres://...,us=postgres://...).")
+	fmt.Println("Every regional database must be migrated with cmd/migrate before use.")
+	fmt.Println("The tenant is unavailable while it is being moved.")
+	fmt.Println("Moves need a superuser database user (rds_superuser on managed Postgres) in")
+	fmt.Println("both regions, to copy rows without firing foreign keys and triggers.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  tenant-region regions")
+	fmt.Println("  tenant-region move 6f1c...e2 eu")
+	fmt.Println("  tenant-region move 6f1c...e2 eu 2m")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	tenantRepo := repository.NewTenantRepository(db)
+	ctx := context.Background()
+	tenantID := parseTenantID(os.Args[2])
+
+	switch os.Args[1] {
+	case "show":
+		tenant, err := tenantRepo.FindByID(ctx, tenantID)
+		if err != nil {
+			log.Fatalf("Failed to find tenant: %v", err)
+		}
+		fmt.Printf("%s (%s): %s\n", tenant.CompanyName, tenant.Slug, tenant.Status)
+		if tenant.IsReadOnly() {
+			fmt.Printf("Read-only since %s: %s\n", tenant.SuspendedAt.UTC().Format(time.RFC3339), tenant.ReadOnlyReason())
+		}
+
+	case "suspend":
+		if len(os.Args) < 4 {
+			log.Fatal("Usage: tenant-status suspend TENANT_ID REASON")
+		}
+		reason := os.Args[3]
+		if !isSuspensionReason(reason) {
+			log.Fatalf("Invalid reason %q, expected one of: %s", reason, strings.Join(models.TenantSuspensionReasons, ", "))
+		}
+
+		if err := tenantRepo.Suspend(ctx, tenantID, reason); err != nil {
+			log.Fatalf("❌ Suspend failed: %v", err)
+		}
+		log.Printf("✅ Tenant %s is read-only (%s)", tenantID, reason)
+
+	case "reactivate":
+		if err := tenantRepo.Reactivate(ctx, tenantID); err != nil {
+			log.Fatalf("❌ Reactivate failed: %v", err)
+		}
+		log.Printf("✅ Tenant %s is active", tenantID)
+
+	default:
+		log.Printf("Unknown command: %s\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func isSuspensionReason(reason string) bool {
+	for _, valid := range models.TenantSuspensionReasons {
+		if reason == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTenantID(value string) uuid.UUID {
+	tenantID, err := uuid.Parse(value)
+	if err != nil {
+		log.Fatalf("Invalid tenant ID: %v", err)
+	}
+	return tenantID
+}
+
+func printUsage() {
+	fmt.Println("Usage: tenant-status <command> TENANT_ID [arguments]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  show TENANT_ID                 Show a tenant's status")
+	fmt.Println("  suspend TENANT_ID REASON       Make a tenant read-only")
+	fmt.Println("  reactivate TENANT_ID           Lift a tenant's suspension")
+	fmt.Println("")
+	fmt.Println("Reasons: payment_overdue, terms_violation, requested.")
+	fmt.Println("Users of a suspended tenant can sign in and read their data; changes are")
+	fmt.Println("refused with 402 (payment_overdue) or 423 and the reason.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  tenant-status suspend 6f1c...e2 payment_overdue")
+	fmt.Println("  tenant-status reactivate 6f1c...e2")
+}
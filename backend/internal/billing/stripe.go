@@ -0,0 +1,291 @@
+// Package billing talks to Stripe: it starts Checkout sessions for plan
+// subscriptions, changes and cancels subscriptions, and verifies the
+// webhooks Stripe reports subscription changes through.
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+// stripeVersion is the API version requests and webhooks are read as; later
+// versions moved current_period_end from the subscription to its items
+const stripeVersion = "2024-06-20"
+
+// webhookTolerance is how old a signed webhook request may be, so captured
+// requests cannot be replayed later
+const webhookTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for webhook requests that are not signed
+// with the endpoint's secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Webhook event types the subscription state is synced from
+const (
+	EventCheckoutCompleted   = "checkout.session.completed"
+	EventSubscriptionCreated = "customer.subscription.created"
+	EventSubscriptionUpdated = "customer.subscription.updated"
+	EventSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+// Stripe is a client of the Stripe API
+type Stripe struct {
+	cfg    *config.BillingConfig
+	client *http.Client
+}
+
+// NewStripe creates a Stripe client
+func NewStripe(cfg *config.BillingConfig) *Stripe {
+	return &Stripe{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport("stripe", nil),
+		},
+	}
+}
+
+// Enabled reports whether a secret key is configured
+func (s *Stripe) Enabled() bool {
+	return s.cfg.StripeSecretKey != ""
+}
+
+// CheckoutParams describes a Checkout session subscribing a tenant to a price
+type CheckoutParams struct {
+	TenantID   string
+	PlanTier   string
+	PriceID    string
+	CustomerID string // Existing customer; empty creates one with Email
+	Email      string
+	SuccessURL string
+	CancelURL  string
+}
+
+// CheckoutSession is a Stripe Checkout session
+type CheckoutSession struct {
+	ID                string            `json:"id"`
+	URL               string            `json:"url"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// Subscription is a Stripe subscription
+type Subscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// PriceID returns the price of the subscription's first item
+func (s *Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// PeriodEnd returns the end of the current billing period
+func (s *Subscription) PeriodEnd() *time.Time {
+	if s.CurrentPeriodEnd == 0 {
+		return nil
+	}
+	end := time.Unix(s.CurrentPeriodEnd, 0).UTC()
+	return &end
+}
+
+// Event is a webhook event
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// CreateCheckoutSession starts a Checkout session subscribing the tenant to
+// the price. The tenant ID is kept on the session and the subscription.
+func (s *Stripe) CreateCheckoutSession(ctx context.Context, params *CheckoutParams) (*CheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", params.PriceID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("success_url", params.SuccessURL)
+	form.Set("cancel_url", params.CancelURL)
+	form.Set("client_reference_id", params.TenantID)
+	form.Set("metadata[tenant_id]", params.TenantID)
+	form.Set("metadata[plan_tier]", params.PlanTier)
+	form.Set("subscription_data[metadata][tenant_id]", params.TenantID)
+	if params.CustomerID != "" {
+		form.Set("customer", params.CustomerID)
+	} else {
+		form.Set("customer_email", params.Email)
+	}
+
+	var session CheckoutSession
+	if err := s.do(ctx, http.MethodPost, "/v1/checkout/sessions", form, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetSubscription retrieves a subscription
+func (s *Stripe) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	var subscription Subscription
+	if err := s.do(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(id), nil, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ChangePrice moves a subscription to another price, prorating the current
+// period, and withdraws a scheduled cancellation
+func (s *Stripe) ChangePrice(ctx context.Context, subscription *Subscription, priceID string) (*Subscription, error) {
+	if len(subscription.Items.Data) == 0 {
+		return nil, fmt.Errorf("stripe subscription %s has no items", subscription.ID)
+	}
+
+	form := url.Values{}
+	form.Set("items[0][id]", subscription.Items.Data[0].ID)
+	form.Set("items[0][price]", priceID)
+	form.Set("proration_behavior", "create_prorations")
+	form.Set("cancel_at_period_end", "false")
+
+	var updated Subscription
+	if err := s.do(ctx, http.MethodPost, "/v1/subscriptions/"+url.PathEscape(subscription.ID), form, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// CancelAtPeriodEnd schedules a subscription to end with its billing period
+func (s *Stripe) CancelAtPeriodEnd(ctx context.Context, id string) (*Subscription, error) {
+	form := url.Values{}
+	form.Set("cancel_at_period_end", "true")
+
+	var updated Subscription
+	if err := s.do(ctx, http.MethodPost, "/v1/subscriptions/"+url.PathEscape(id), form, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ParseWebhook verifies the Stripe-Signature header of a webhook request,
+// an HMAC-SHA256 of its timestamp and body under the endpoint's signing
+// secret, and decodes its event
+func (s *Stripe) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if s.cfg.StripeWebhookSecret == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return nil, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.cfg.StripeWebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+	return &event, nil
+}
+
+// do sends an API request with a form body and decodes the response into out
+func (s *Stripe) do(ctx context.Context, method, path string, form url.Values, out interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.cfg.StripeAPIURL, "/")+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	req.SetBasicAuth(s.cfg.StripeSecretKey, "")
+	req.Header.Set("Stripe-Version", stripeVersion)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("stripe request failed with status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid stripe response: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,13 +14,38 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Email    EmailConfig
-	Security SecurityConfig
-	App      AppConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Regions       RegionConfig
+	Redis         RedisConfig
+	JWT           JWTConfig
+	Email         EmailConfig
+	Security      SecurityConfig
+	Jobs          JobsConfig
+	Sandbox       SandboxConfig
+	Deletion      DeletionConfig
+	Broadcast     BroadcastConfig
+	Webhooks      WebhookConfig
+	SIEM          SIEMConfig
+	Queues        QueueConfig
+	Automation    AutomationConfig
+	Approvals     ApprovalConfig
+	UserImport    UserImportConfig
+	SSO           SSOConfig
+	WebAuthn      WebAuthnConfig
+	Quotas        QuotaConfig
+	Billing       BillingConfig
+	Storage       StorageConfig
+	Notifications NotificationConfig
+	Metrics       MetricsConfig
+	Health        HealthConfig
+	Tracing       TracingConfig
+	CORS          CORSConfig
+	Domains       TenantDomainConfig
+	Headers       SecurityHeadersConfig
+	Usage         UsageAnalyticsConfig
+	Metering      MeteringConfig
+	App           AppConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -39,9 +67,26 @@ type DatabaseConfig struct {
 	Database        string
 	SSLMode         string
 	MaxOpenConns    int
-	MaxIdleConns    int
+	MinConns        int // Connections the pool keeps open even when idle
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
+
+	// StatementCacheCapacity is the number of prepared statements cached per
+	// connection; 0 disables caching and describes each statement every time
+	StatementCacheCapacity int
+
+	// ReplicaURL is the connection string of a read replica of the database,
+	// which serves read-only transactions; empty when there is none
+	ReplicaURL string
+}
+
+// RegionConfig holds multi-region database routing configuration.
+// The primary database (DB_* settings) always holds the tenants catalog and
+// serves DefaultRegion; DatabaseURLs adds extra clusters for data residency.
+type RegionConfig struct {
+	DefaultRegion string            // Region name of the primary database
+	DatabaseURLs  map[string]string // Additional regions: name -> connection string
+	CacheTTL      time.Duration     // How long tenant -> region lookups are cached
 }
 
 // RedisConfig holds Redis configuration
@@ -63,14 +108,29 @@ type JWTConfig struct {
 	TrustedDeviceExpiry time.Duration // How long to remember trusted devices
 }
 
-// EmailConfig holds SMTP email configuration
+// EmailConfig holds the email provider configuration
 type EmailConfig struct {
+	Provider     string // smtp, sendgrid, ses or mailgun
 	SMTPHost     string
 	SMTPPort     int
 	SMTPUser     string
 	SMTPPassword string
 	FromEmail    string
 	FromName     string
+
+	SendGridAPIKey           string
+	SendGridWebhookPublicKey string // Base64 ECDSA key verifying Event Webhook requests
+
+	SESRegion       string
+	SESAccessKeyID  string
+	SESSecretKey    string
+	SESEndpoint     string   // Defaults to https://email.{region}.amazonaws.com
+	SESSNSTopicARNs []string // SNS topics bounce and complaint notifications are accepted from; empty accepts any
+
+	MailgunDomain     string
+	MailgunAPIKey     string
+	MailgunBaseURL    string // https://api.mailgun.net, or https://api.eu.mailgun.net for the EU region
+	MailgunWebhookKey string // HTTP webhook signing key
 }
 
 // SecurityConfig holds security-related configuration
@@ -78,13 +138,277 @@ type SecurityConfig struct {
 	EncryptionKey          string // AES-256 key for encrypting sensitive data (2FA secrets, etc.)
 	BcryptCost             int    // bcrypt cost factor (10-12 recommended)
 	PasswordResetExpiry    time.Duration
+	PasswordHistoryCount   int // Recent passwords, the current one included, a new password may not repeat; 0 allows any
 	VerificationExpiry     time.Duration
 	InvitationExpiry       time.Duration
 	MaxLoginAttempts       int
 	LoginRateLimitWindow   time.Duration
 	Max2FAAttempts         int
 	TwoFARateLimitWindow   time.Duration
+	TwoFABypassCodeTTL     time.Duration // How long an emailed 2FA bypass code is valid
+	TwoFABypassDailyLimit  int           // 2FA bypass codes a user may be sent per 24 hours
 	SessionInactivityLimit time.Duration
+	SessionCacheTTL        time.Duration // How long a session record is cached in Redis; 0 reads it from the database on every request
+	MaxConcurrentSessions  int           // Default sessions a user may have at once, tenants may override it; 0 = unlimited
+	SessionLimitPolicy     string        // evict_oldest | reject: what a sign-in past the limit does
+	RateLimitEnabled       bool          // Apply the per-tenant and per-IP API rate limits
+	TenantRateLimit        int           // Requests per minute a tenant may sustain
+	TenantRateBurst        int           // Requests a tenant may send at once before being throttled
+	IPRateLimit            int           // Requests per minute a single client IP may sustain
+	IPRateBurst            int           // Requests a single client IP may send at once before being throttled
+}
+
+// JobsConfig holds background job configuration. Jobs are safe to enable on
+// every replica: each run is guarded by a cluster-wide advisory lock.
+type JobsConfig struct {
+	Enabled                      bool          // Run background jobs in this process
+	CleanupInterval              time.Duration // How often expired sessions/invitations/tokens are purged
+	CleanupSchedule              string        // Cron expression for the cleanups instead of CleanupInterval, e.g. "30 3 * * *"
+	EmailPollInterval            time.Duration // How often the email outbox is checked for due messages
+	EmailConcurrency             int           // Emails sent at the same time per data region
+	SandboxPollInterval          time.Duration // How often pending sandbox clones are picked up
+	DeletionPollInterval         time.Duration // How often staged deletions past their undo window are executed
+	BroadcastPollInterval        time.Duration // How often the next batch of broadcast emails is queued
+	AnnouncementPollInterval     time.Duration // How often the audiences of newly published announcements are notified
+	AsyncPollInterval            time.Duration // How often queued async jobs (imports, exports, reports) are picked up
+	AsyncTimeout                 time.Duration // Longest a worker run (and so a single async job) may take
+	AsyncConcurrency             int           // Async jobs executed at the same time
+	AsyncRetention               time.Duration // How long finished async jobs and their results are kept
+	WebhookPollInterval          time.Duration // How often due webhook deliveries are posted
+	WebhookConcurrency           int           // Webhook deliveries posted at the same time per data region
+	QueueMonitorInterval         time.Duration // How often queue depths are checked against the alert thresholds
+	AutomationPollInterval       time.Duration // How often triggered automation rules are executed
+	EscalationPollInterval       time.Duration // How often overdue approvals are checked against escalation policies
+	RecentViewsFlushInterval     time.Duration // How often recently viewed lists are saved from Redis to the database
+	SessionActivityFlushInterval time.Duration // How often buffered session activity is written to the database
+	DataQualitySchedule          string        // Cron expression on which data-quality rules are run, e.g. "0 6 * * *"
+	QuotaCheckInterval           time.Duration // How often tenants' usage is measured against their plan quotas
+	LeaveAccrualSchedule         string        // Cron expression on which leave balances accrue, e.g. "0 2 1 * *"
+	TwoFactorReminderSchedule    string        // Cron expression on which users are reminded to set up the 2FA their tenant requires
+	FilePurgeInterval            time.Duration // How often files deleted longer ago than the retention are removed
+	RoleExpiryInterval           time.Duration // How often temporary role assignments that ended are removed
+	UsageExportSchedule          string        // Cron expression on which the previous day's usage is sent to the sink, e.g. "15 0 * * *"
+	MeteringRollupInterval       time.Duration // How often metered tenant usage is copied from Redis to the database
+	NotificationDigestSchedule   string        // Cron expression on which due notification digests are emailed, e.g. "0 7 * * *"
+	ReportPollInterval           time.Duration // How often scheduled reports past their next run time are emailed
+	SIEMPollInterval             time.Duration // How often new audit log events are forwarded to SIEMs
+	SIEMConcurrency              int           // SIEM forwarders sending at the same time per data region
+}
+
+// SandboxConfig holds tenant sandbox configuration
+type SandboxConfig struct {
+	DefaultTTL   time.Duration // Lifetime of a sandbox when none is requested
+	MaxTTL       time.Duration // Longest lifetime a sandbox can be created with
+	MaxPerTenant int           // Sandboxes a tenant may have at the same time
+	CloneTimeout time.Duration // Longest a single clone may run
+}
+
+// DeletionConfig holds configuration for staged (undoable) deletes
+type DeletionConfig struct {
+	UndoWindow               time.Duration // How long a delete can be undone before it is executed
+	UserRetention            time.Duration // How long soft-deleted users can be restored before they are purged
+	TenantGracePeriod        time.Duration // How long a confirmed tenant deletion can be canceled before the tenant is purged
+	TenantConfirmationExpiry time.Duration // How long the emailed link confirming a tenant deletion is valid
+}
+
+// BroadcastConfig holds configuration for bulk emails to tenant users
+type BroadcastConfig struct {
+	BatchSize int // Broadcast emails queued per database per worker run (throttle)
+}
+
+// WebhookConfig holds configuration for outgoing webhook deliveries
+type WebhookConfig struct {
+	Timeout              time.Duration // Longest a delivery request may take
+	MaxAttempts          int           // Delivery attempts before a delivery is marked failed
+	AllowPrivateNetworks bool          // Allow endpoints on loopback/private addresses (development only)
+	DeliveryRetention    time.Duration // How long finished deliveries are kept in the delivery log
+}
+
+// SIEMConfig holds the settings of audit log forwarding to SIEMs. Endpoints
+// follow WEBHOOK_ALLOW_PRIVATE_NETWORKS.
+type SIEMConfig struct {
+	Timeout          time.Duration // Longest connecting and sending a batch may take
+	BatchSize        int           // Events sent per request (or connection, for syslog)
+	MaxBatchesPerRun int           // Batches a forwarder sends per job run, so one backlog can't hold up the others
+	MaxLag           time.Duration // Events older than this that were not forwarded yet are skipped
+}
+
+// QueueConfig bounds the email, webhook and async job queues, so an outage
+// of a downstream service can't pile up unbounded work, and sets when their
+// depth is alerted on. A limit of 0 disables it.
+type QueueConfig struct {
+	EmailMaxPending   int           // Pending emails a tenant may have; broadcasts stop queuing at half of it
+	WebhookMaxPending int           // Pending deliveries a webhook may have; further events to it are dropped
+	AsyncMaxQueued    int           // Queued async jobs a tenant may have
+	AlertDepth        int           // Pending items in a queue, across tenants, that raise an alert
+	AlertAge          time.Duration // How long the oldest due item may wait before an alert is raised
+}
+
+// AutomationConfig holds configuration for workflow automation rules
+type AutomationConfig struct {
+	ExecutionRetention time.Duration // How long finished executions are kept in the execution log
+}
+
+// NotificationConfig holds configuration for in-app notifications
+type NotificationConfig struct {
+	Retention     time.Duration // How long read notifications are kept
+	DefaultDigest string        // Digest frequency of users who never chose one: off, daily or weekly
+}
+
+// MetricsConfig holds configuration for request cost accounting
+type MetricsConfig struct {
+	Enabled              bool          // Serve /metrics
+	Token                string        // Bearer token required by /metrics (empty: open)
+	SlowQueryThreshold   time.Duration // Queries at least this slow are kept in the slow-query log
+	SlowRequestThreshold time.Duration // Requests at least this slow are logged with their cost
+	FlushInterval        time.Duration // How often each replica writes its aggregates to the database
+	Retention            time.Duration // How long hourly aggregates are kept
+}
+
+// HealthConfig holds configuration for the readiness probe (/health/ready)
+type HealthConfig struct {
+	Timeout     time.Duration // Longest each dependency check may take
+	RequireSMTP bool          // Report not ready when the SMTP server is unreachable
+}
+
+// TracingConfig holds configuration for OpenTelemetry tracing. Spans are
+// exported over OTLP/HTTP to a collector (or any OTLP backend).
+type TracingConfig struct {
+	Enabled     bool              // Record and export spans
+	Endpoint    string            // OTLP/HTTP endpoint, e.g. http://localhost:4318
+	Headers     map[string]string // Headers sent with each export, e.g. an API key of the backend
+	ServiceName string            // service.name of the spans
+	SampleRatio float64           // Share of traces started here that are recorded (0-1); traces started upstream follow the caller's decision
+}
+
+// CORSConfig holds the cross-origin policy of the API. FRONTEND_URL,
+// APP_BASE_URL and the verified custom domains of tenants are always allowed.
+type CORSConfig struct {
+	AllowedOrigins []string      // Further origins; one * matches any subdomain (https://*.example.com)
+	MaxAge         time.Duration // How long browsers may cache a preflight response
+}
+
+// TenantDomainConfig holds configuration for resolving requests to tenants
+// by host name: <slug>.<BaseDomain> or a verified custom domain
+type TenantDomainConfig struct {
+	BaseDomain         string        // Tenants are served at <slug>.<BaseDomain>; empty disables subdomains
+	ReservedSubdomains []string      // Subdomains of BaseDomain that are not tenants, and slugs tenants cannot get
+	CacheTTL           time.Duration // How long slug and domain lookups are cached in Redis; 0 disables the cache
+	MaxPerTenant       int           // Custom domains a tenant may add
+	SlugRedirectPeriod time.Duration // How long a renamed tenant's old slug keeps resolving to it
+}
+
+// SecurityHeadersConfig holds the security headers set on every response.
+// An empty value leaves its header out.
+type SecurityHeadersConfig struct {
+	HSTSMaxAge            time.Duration // Strict-Transport-Security max-age; 0 leaves HSTS out (the default outside production)
+	HSTSIncludeSubdomains bool          // Apply HSTS to the subdomains of the API's domain too
+	ContentSecurityPolicy string        // Content-Security-Policy of API responses
+	FrameOptions          string        // X-Frame-Options: DENY | SAMEORIGIN
+	ReferrerPolicy        string        // Referrer-Policy
+}
+
+// UsageAnalyticsConfig holds configuration for the anonymized feature-usage
+// statistics collected for the product team
+type UsageAnalyticsConfig struct {
+	Enabled       bool          // Collect usage of tenants that have not opted out
+	Secret        string        // Key tenant IDs are pseudonymized with; changing it breaks continuity of the series
+	FlushInterval time.Duration // How often each replica writes its counters to the database
+	Retention     time.Duration // How long daily usage rows are kept
+	SinkURL       string        // Optional endpoint each day's usage is POSTed to as JSON
+	SinkToken     string        // Bearer token sent to the sink
+	SinkTimeout   time.Duration // Timeout of a sink request
+}
+
+// MeteringConfig holds configuration for the per-tenant usage metered for
+// plans and billing
+type MeteringConfig struct {
+	Retention time.Duration // How long daily metered usage is kept
+}
+
+// ApprovalConfig holds configuration for approving from email links
+type ApprovalConfig struct {
+	LinkTTL      time.Duration // How long emailed Approve/Reject links can be used
+	StepUpAmount float64       // Amount from which link decisions need the approver's authenticator code (0 = never)
+}
+
+// UserImportConfig holds configuration for bulk user imports from CSV/XLSX files
+type UserImportConfig struct {
+	MaxFileSize  int64         // Largest file accepted, in bytes
+	MaxRows      int           // Most rows a single file may contain
+	SyncRowLimit int           // Files with more rows are imported by an async job
+	SetupLinkTTL time.Duration // How long imported users' set-password links can be used
+}
+
+// SSOConfig holds configuration for OpenID Connect and SAML single sign-on
+type SSOConfig struct {
+	CallbackBaseURL      string        // Public URL of the API; providers redirect to {CallbackBaseURL}/auth/sso/{provider}/callback
+	StateTTL             time.Duration // How long a started sign-on may take before the callback is refused
+	HTTPTimeout          time.Duration // Longest a request to a provider (discovery, keys, token) may take
+	MetadataCacheTTL     time.Duration // How long provider discovery documents and signing keys are cached
+	AllowInsecureIssuers bool          // Allow http and loopback/private issuers, e.g. a local Keycloak (development only)
+	SAMLPlanTiers        []string      // Plan tiers whose tenants may use SAML identity providers
+}
+
+// WebAuthnConfig holds the relying party settings of passkeys and security keys
+type WebAuthnConfig struct {
+	RPID         string        // Domain credentials are scoped to; the frontend must be served from it or a subdomain
+	RPName       string        // Name authenticators show when registering a credential
+	Origins      []string      // Frontend origins allowed to use credentials; "https://*.example.com" allows every subdomain
+	ChallengeTTL time.Duration // How long a registration or sign-in ceremony may take
+}
+
+// QuotaConfig holds the plan quotas and their warning thresholds. Plan tiers
+// without a limit are unlimited.
+type QuotaConfig struct {
+	UserLimits       map[string]int64 // Users a tenant of each plan tier may have
+	StorageLimits    map[string]int64 // Bytes of data a tenant of each plan tier may store
+	APICallLimits    map[string]int64 // API requests a tenant of each plan tier may make per calendar month (UTC)
+	Thresholds       []int            // Percents of a limit at which owners are warned, ascending
+	ReminderInterval time.Duration    // How often owners are reminded of alerts they have not acknowledged
+}
+
+// BillingConfig holds the plan features and the Stripe subscription settings.
+// Without a Stripe secret key plans can't be bought or changed by tenants.
+type BillingConfig struct {
+	FeatureTiers        map[string][]string // Plan tiers that include each feature
+	StripeSecretKey     string
+	StripeWebhookSecret string            // Signing secret of the webhook endpoint
+	StripeAPIURL        string            // https://api.stripe.com, or a mock for testing
+	StripePriceIDs      map[string]string // Stripe price of each plan tier that can be bought
+}
+
+// StorageConfig holds configuration for uploaded and generated files and the
+// backend they are stored on
+type StorageConfig struct {
+	Backend              string                   // local | s3 | gcs | azure
+	Prefix               string                   // Key prefix every file is stored under, e.g. to share a bucket between environments
+	LocalPath            string                   // Directory files are stored under with the local backend
+	S3Endpoint           string                   // S3-compatible endpoint, e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	S3Region             string                   // Region requests are signed for
+	S3Bucket             string                   // Bucket files are stored in
+	S3AccessKeyID        string                   // Access key of the S3 credentials
+	S3SecretKey          string                   // Secret key of the S3 credentials
+	S3PathStyle          bool                     // Address the bucket in the path instead of the host name (MinIO and most S3-compatible stores)
+	S3SSE                string                   // Server-side encryption: empty for the bucket default, AES256 or aws:kms
+	S3KMSKeyID           string                   // KMS key used with aws:kms, empty for the AWS managed key
+	GCSEndpoint          string                   // Cloud Storage XML API endpoint
+	GCSBucket            string                   // Bucket files are stored in
+	GCSAccessKeyID       string                   // HMAC key of a service account
+	GCSSecretKey         string                   // HMAC secret of the key
+	GCSKMSKeyName        string                   // Cloud KMS key files are encrypted with, empty for the bucket default
+	AzureEndpoint        string                   // Blob service endpoint, empty for https://{account}.blob.core.windows.net
+	AzureAccount         string                   // Storage account name
+	AzureAccountKey      string                   // Shared key of the storage account, base64
+	AzureContainer       string                   // Container files are stored in
+	AzureEncryptionScope string                   // Encryption scope files are encrypted with, empty for the container default
+	Lifecycle            map[string]time.Duration // How long files of a category are kept before they expire and are purged, e.g. artifact=720h
+	DownloadBaseURL      string                   // Public URL of the API; signed local downloads are served at {DownloadBaseURL}/files/signed/{token}
+	SignedURLTTL         time.Duration            // How long signed download URLs can be used
+	SigningSecret        string                   // Key signed download URLs of the local backend are signed with
+	MaxUploadSize        int64                    // Largest attachment accepted, in bytes
+	MaxAvatarSize        int64                    // Largest avatar accepted, in bytes
+	DeletedRetention     time.Duration            // How long deleted files can be restored before they are purged
 }
 
 // AppConfig holds general application configuration
@@ -102,6 +426,13 @@ func Load() (*Config, error) {
 	// Load .env file if exists (ignore errors in production)
 	_ = godotenv.Load()
 
+	// Browsers remember HSTS, so it is only sent by default in production
+	environment := getEnv("ENVIRONMENT", "development")
+	hstsMaxAge := time.Duration(0)
+	if environment == "production" {
+		hstsMaxAge = 365 * 24 * time.Hour
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
 			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
@@ -109,7 +440,7 @@ func Load() (*Config, error) {
 			ReadTimeout:     getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
 			WriteTimeout:    getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
 			ShutdownTimeout: getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
-			Environment:     getEnv("ENVIRONMENT", "development"),
+			Environment:     environment,
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -119,9 +450,17 @@ func Load() (*Config, error) {
 			Database:        getEnv("DB_NAME", "myerp_v2"),
 			SSLMode:         getEnv("DB_SSL_MODE", "disable"),
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 100),
-			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
+			MinConns:        getEnvAsInt("DB_MIN_CONNS", 5),
 			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 1*time.Hour),
 			ConnMaxIdleTime: getEnvAsDuration("DB_CONN_MAX_IDLE_TIME", 10*time.Minute),
+
+			StatementCacheCapacity: getEnvAsInt("DB_STATEMENT_CACHE_CAPACITY", 512),
+			ReplicaURL:             getEnv("DB_REPLICA_URL", ""),
+		},
+		Regions: RegionConfig{
+			DefaultRegion: getEnv("DB_DEFAULT_REGION", "default"),
+			DatabaseURLs:  getEnvAsMap("DB_REGION_URLS"),
+			CacheTTL:      getEnvAsDuration("DB_REGION_CACHE_TTL", 1*time.Minute),
 		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -139,24 +478,240 @@ func Load() (*Config, error) {
 			TrustedDeviceExpiry: getEnvAsDuration("TRUSTED_DEVICE_EXPIRY", 30*24*time.Hour),
 		},
 		Email: EmailConfig{
+			Provider:     getEnv("EMAIL_PROVIDER", "smtp"),
 			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
 			SMTPPort:     getEnvAsInt("SMTP_PORT", 1025),
 			SMTPUser:     getEnv("SMTP_USER", ""),
 			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 			FromEmail:    getEnv("EMAIL_FROM", "noreply@myerp.local"),
 			FromName:     getEnv("EMAIL_FROM_NAME", "MyERP v2"),
+
+			SendGridAPIKey:           getEnv("SENDGRID_API_KEY", ""),
+			SendGridWebhookPublicKey: getEnv("SENDGRID_WEBHOOK_PUBLIC_KEY", ""),
+			SESRegion:                getEnv("SES_REGION", "us-east-1"),
+			SESAccessKeyID:           getEnv("SES_ACCESS_KEY_ID", ""),
+			SESSecretKey:             getEnv("SES_SECRET_ACCESS_KEY", ""),
+			SESEndpoint:              getEnv("SES_ENDPOINT", ""),
+			SESSNSTopicARNs:          getEnvAsList("SES_SNS_TOPIC_ARNS", ""),
+			MailgunDomain:            getEnv("MAILGUN_DOMAIN", ""),
+			MailgunAPIKey:            getEnv("MAILGUN_API_KEY", ""),
+			MailgunBaseURL:           getEnv("MAILGUN_BASE_URL", "https://api.mailgun.net"),
+			MailgunWebhookKey:        getEnv("MAILGUN_WEBHOOK_SIGNING_KEY", ""),
 		},
 		Security: SecurityConfig{
 			EncryptionKey:          getEnv("ENCRYPTION_KEY", "change-this-to-a-32-byte-key!!"),
 			BcryptCost:             getEnvAsInt("BCRYPT_COST", 10),
 			PasswordResetExpiry:    getEnvAsDuration("PASSWORD_RESET_EXPIRY", 1*time.Hour),
+			PasswordHistoryCount:   getEnvAsInt("PASSWORD_HISTORY_COUNT", 5),
 			VerificationExpiry:     getEnvAsDuration("VERIFICATION_EXPIRY", 24*time.Hour),
 			InvitationExpiry:       getEnvAsDuration("INVITATION_EXPIRY", 7*24*time.Hour),
 			MaxLoginAttempts:       getEnvAsInt("MAX_LOGIN_ATTEMPTS", 5),
 			LoginRateLimitWindow:   getEnvAsDuration("LOGIN_RATE_LIMIT_WINDOW", 5*time.Minute),
 			Max2FAAttempts:         getEnvAsInt("MAX_2FA_ATTEMPTS", 5),
 			TwoFARateLimitWindow:   getEnvAsDuration("2FA_RATE_LIMIT_WINDOW", 15*time.Minute),
+			TwoFABypassCodeTTL:     getEnvAsDuration("2FA_BYPASS_CODE_TTL", 15*time.Minute),
+			TwoFABypassDailyLimit:  getEnvAsInt("2FA_BYPASS_DAILY_LIMIT", 3),
 			SessionInactivityLimit: getEnvAsDuration("SESSION_INACTIVITY_LIMIT", 30*time.Minute),
+			SessionCacheTTL:        getEnvAsDuration("SESSION_CACHE_TTL", time.Minute),
+			MaxConcurrentSessions:  getEnvAsInt("MAX_CONCURRENT_SESSIONS", 0),
+			SessionLimitPolicy:     getEnv("SESSION_LIMIT_POLICY", "evict_oldest"),
+			RateLimitEnabled:       getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			TenantRateLimit:        getEnvAsInt("RATE_LIMIT_TENANT_PER_MINUTE", 1200),
+			TenantRateBurst:        getEnvAsInt("RATE_LIMIT_TENANT_BURST", 200),
+			IPRateLimit:            getEnvAsInt("RATE_LIMIT_IP_PER_MINUTE", 300),
+			IPRateBurst:            getEnvAsInt("RATE_LIMIT_IP_BURST", 60),
+		},
+		Jobs: JobsConfig{
+			Enabled:                      getEnvAsBool("JOBS_ENABLED", true),
+			CleanupInterval:              getEnvAsDuration("JOBS_CLEANUP_INTERVAL", 1*time.Hour),
+			CleanupSchedule:              getEnv("JOBS_CLEANUP_SCHEDULE", ""),
+			EmailPollInterval:            getEnvAsDuration("JOBS_EMAIL_POLL_INTERVAL", 5*time.Second),
+			EmailConcurrency:             getEnvAsInt("JOBS_EMAIL_CONCURRENCY", 4),
+			SandboxPollInterval:          getEnvAsDuration("JOBS_SANDBOX_POLL_INTERVAL", 15*time.Second),
+			DeletionPollInterval:         getEnvAsDuration("JOBS_DELETION_POLL_INTERVAL", 30*time.Second),
+			BroadcastPollInterval:        getEnvAsDuration("JOBS_BROADCAST_POLL_INTERVAL", 1*time.Minute),
+			AnnouncementPollInterval:     getEnvAsDuration("JOBS_ANNOUNCEMENT_POLL_INTERVAL", 1*time.Minute),
+			AsyncPollInterval:            getEnvAsDuration("JOBS_ASYNC_POLL_INTERVAL", 5*time.Second),
+			AsyncTimeout:                 getEnvAsDuration("JOBS_ASYNC_TIMEOUT", 1*time.Hour),
+			AsyncConcurrency:             getEnvAsInt("JOBS_ASYNC_CONCURRENCY", 4),
+			AsyncRetention:               getEnvAsDuration("JOBS_ASYNC_RETENTION", 7*24*time.Hour),
+			WebhookPollInterval:          getEnvAsDuration("JOBS_WEBHOOK_POLL_INTERVAL", 5*time.Second),
+			WebhookConcurrency:           getEnvAsInt("JOBS_WEBHOOK_CONCURRENCY", 4),
+			QueueMonitorInterval:         getEnvAsDuration("JOBS_QUEUE_MONITOR_INTERVAL", 1*time.Minute),
+			AutomationPollInterval:       getEnvAsDuration("JOBS_AUTOMATION_POLL_INTERVAL", 5*time.Second),
+			EscalationPollInterval:       getEnvAsDuration("JOBS_ESCALATION_POLL_INTERVAL", 5*time.Minute),
+			RecentViewsFlushInterval:     getEnvAsDuration("JOBS_RECENT_VIEWS_FLUSH_INTERVAL", 1*time.Minute),
+			SessionActivityFlushInterval: getEnvAsDuration("JOBS_SESSION_ACTIVITY_FLUSH_INTERVAL", 30*time.Second),
+			DataQualitySchedule:          getEnv("JOBS_DATA_QUALITY_SCHEDULE", "0 6 * * *"),
+			QuotaCheckInterval:           getEnvAsDuration("JOBS_QUOTA_CHECK_INTERVAL", 1*time.Hour),
+			LeaveAccrualSchedule:         getEnv("JOBS_LEAVE_ACCRUAL_SCHEDULE", "0 2 1 * *"),
+			TwoFactorReminderSchedule:    getEnv("JOBS_2FA_REMINDER_SCHEDULE", "0 8 * * *"),
+			FilePurgeInterval:            getEnvAsDuration("JOBS_FILE_PURGE_INTERVAL", 1*time.Hour),
+			RoleExpiryInterval:           getEnvAsDuration("JOBS_ROLE_EXPIRY_INTERVAL", 15*time.Minute),
+			UsageExportSchedule:          getEnv("JOBS_USAGE_EXPORT_SCHEDULE", "15 0 * * *"),
+			MeteringRollupInterval:       getEnvAsDuration("JOBS_METERING_ROLLUP_INTERVAL", 15*time.Minute),
+			NotificationDigestSchedule:   getEnv("JOBS_NOTIFICATION_DIGEST_SCHEDULE", "0 7 * * *"),
+			ReportPollInterval:           getEnvAsDuration("JOBS_REPORT_POLL_INTERVAL", 5*time.Minute),
+			SIEMPollInterval:             getEnvAsDuration("JOBS_SIEM_POLL_INTERVAL", 5*time.Second),
+			SIEMConcurrency:              getEnvAsInt("JOBS_SIEM_CONCURRENCY", 4),
+		},
+		Sandbox: SandboxConfig{
+			DefaultTTL:   getEnvAsDuration("SANDBOX_DEFAULT_TTL", 7*24*time.Hour),
+			MaxTTL:       getEnvAsDuration("SANDBOX_MAX_TTL", 30*24*time.Hour),
+			MaxPerTenant: getEnvAsInt("SANDBOX_MAX_PER_TENANT", 2),
+			CloneTimeout: getEnvAsDuration("SANDBOX_CLONE_TIMEOUT", 30*time.Minute),
+		},
+		Deletion: DeletionConfig{
+			UndoWindow:               getEnvAsDuration("DELETION_UNDO_WINDOW", 10*time.Minute),
+			UserRetention:            getEnvAsDuration("DELETION_USER_RETENTION", 30*24*time.Hour),
+			TenantGracePeriod:        getEnvAsDuration("DELETION_TENANT_GRACE_PERIOD", 30*24*time.Hour),
+			TenantConfirmationExpiry: getEnvAsDuration("DELETION_TENANT_CONFIRMATION_EXPIRY", 24*time.Hour),
+		},
+		Broadcast: BroadcastConfig{
+			BatchSize: getEnvAsInt("BROADCAST_BATCH_SIZE", 100),
+		},
+		Webhooks: WebhookConfig{
+			Timeout:              getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:          getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			AllowPrivateNetworks: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+			DeliveryRetention:    getEnvAsDuration("WEBHOOK_DELIVERY_RETENTION", 30*24*time.Hour),
+		},
+		SIEM: SIEMConfig{
+			Timeout:          getEnvAsDuration("SIEM_TIMEOUT", 10*time.Second),
+			BatchSize:        getEnvAsInt("SIEM_BATCH_SIZE", 500),
+			MaxBatchesPerRun: getEnvAsInt("SIEM_MAX_BATCHES_PER_RUN", 20),
+			MaxLag:           getEnvAsDuration("SIEM_MAX_LAG", 24*time.Hour),
+		},
+		Queues: QueueConfig{
+			EmailMaxPending:   getEnvAsInt("QUEUE_EMAIL_MAX_PENDING", 10000),
+			WebhookMaxPending: getEnvAsInt("QUEUE_WEBHOOK_MAX_PENDING", 1000),
+			AsyncMaxQueued:    getEnvAsInt("QUEUE_ASYNC_MAX_QUEUED", 20),
+			AlertDepth:        getEnvAsInt("QUEUE_ALERT_DEPTH", 5000),
+			AlertAge:          getEnvAsDuration("QUEUE_ALERT_AGE", 15*time.Minute),
+		},
+		Automation: AutomationConfig{
+			ExecutionRetention: getEnvAsDuration("AUTOMATION_EXECUTION_RETENTION", 30*24*time.Hour),
+		},
+		Notifications: NotificationConfig{
+			Retention:     getEnvAsDuration("NOTIFICATION_RETENTION", 90*24*time.Hour),
+			DefaultDigest: getEnv("NOTIFICATION_DEFAULT_DIGEST", "weekly"),
+		},
+		Metrics: MetricsConfig{
+			Enabled:              getEnvAsBool("METRICS_ENABLED", true),
+			Token:                getEnv("METRICS_TOKEN", ""),
+			SlowQueryThreshold:   getEnvAsDuration("METRICS_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+			SlowRequestThreshold: getEnvAsDuration("METRICS_SLOW_REQUEST_THRESHOLD", 2*time.Second),
+			FlushInterval:        getEnvAsDuration("METRICS_FLUSH_INTERVAL", time.Minute),
+			Retention:            getEnvAsDuration("METRICS_RETENTION", 7*24*time.Hour),
+		},
+		Health: HealthConfig{
+			Timeout:     getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			RequireSMTP: getEnvAsBool("HEALTH_REQUIRE_SMTP", false),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvAsBool("OTEL_TRACING_ENABLED", false),
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+			Headers:     getEnvAsMap("OTEL_EXPORTER_OTLP_HEADERS"),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "myerp-api"),
+			SampleRatio: getEnvAsFloat("OTEL_TRACES_SAMPLE_RATIO", 1),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvAsList("CORS_ALLOWED_ORIGINS", ""),
+			MaxAge:         getEnvAsDuration("CORS_MAX_AGE", 5*time.Minute),
+		},
+		Domains: TenantDomainConfig{
+			BaseDomain:         strings.ToLower(getEnv("BASE_DOMAIN", "localhost")),
+			ReservedSubdomains: getEnvAsList("TENANT_RESERVED_SUBDOMAINS", "www,app,api,admin,mail,static,status"),
+			CacheTTL:           getEnvAsDuration("TENANT_CACHE_TTL", 5*time.Minute),
+			MaxPerTenant:       getEnvAsInt("TENANT_MAX_DOMAINS", 5),
+			SlugRedirectPeriod: getEnvAsDuration("TENANT_SLUG_REDIRECT_PERIOD", 90*24*time.Hour),
+		},
+		Headers: SecurityHeadersConfig{
+			HSTSMaxAge:            getEnvAsDuration("HSTS_MAX_AGE", hstsMaxAge),
+			HSTSIncludeSubdomains: getEnvAsBool("HSTS_INCLUDE_SUBDOMAINS", false),
+			ContentSecurityPolicy: getEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+			FrameOptions:          getEnv("X_FRAME_OPTIONS", "DENY"),
+			ReferrerPolicy:        getEnv("REFERRER_POLICY", "strict-origin-when-cross-origin"),
+		},
+		Usage: UsageAnalyticsConfig{
+			Enabled:       getEnvAsBool("USAGE_ANALYTICS_ENABLED", true),
+			Secret:        getEnv("USAGE_ANALYTICS_SECRET", getEnv("JWT_SECRET", "your-jwt-secret-key-change-in-production")),
+			FlushInterval: getEnvAsDuration("USAGE_ANALYTICS_FLUSH_INTERVAL", time.Minute),
+			Retention:     getEnvAsDuration("USAGE_ANALYTICS_RETENTION", 400*24*time.Hour),
+			SinkURL:       getEnv("USAGE_ANALYTICS_SINK_URL", ""),
+			SinkToken:     getEnv("USAGE_ANALYTICS_SINK_TOKEN", ""),
+			SinkTimeout:   getEnvAsDuration("USAGE_ANALYTICS_SINK_TIMEOUT", 30*time.Second),
+		},
+		Metering: MeteringConfig{
+			Retention: getEnvAsDuration("METERING_RETENTION", 400*24*time.Hour),
+		},
+		Approvals: ApprovalConfig{
+			LinkTTL:      getEnvAsDuration("APPROVAL_LINK_TTL", 72*time.Hour),
+			StepUpAmount: getEnvAsFloat("APPROVAL_LINK_STEP_UP_AMOUNT", 10000),
+		},
+		UserImport: UserImportConfig{
+			MaxFileSize:  int64(getEnvAsInt("USER_IMPORT_MAX_FILE_SIZE_MB", 5)) << 20,
+			MaxRows:      getEnvAsInt("USER_IMPORT_MAX_ROWS", 5000),
+			SyncRowLimit: getEnvAsInt("USER_IMPORT_SYNC_ROW_LIMIT", 100),
+			SetupLinkTTL: getEnvAsDuration("USER_IMPORT_SETUP_LINK_TTL", 7*24*time.Hour),
+		},
+		SSO: SSOConfig{
+			CallbackBaseURL:      getEnv("SSO_CALLBACK_BASE_URL", getEnv("APP_BASE_URL", "http://localhost:8080")),
+			StateTTL:             getEnvAsDuration("SSO_STATE_TTL", 10*time.Minute),
+			HTTPTimeout:          getEnvAsDuration("SSO_HTTP_TIMEOUT", 10*time.Second),
+			MetadataCacheTTL:     getEnvAsDuration("SSO_METADATA_CACHE_TTL", 1*time.Hour),
+			AllowInsecureIssuers: getEnvAsBool("SSO_ALLOW_INSECURE_ISSUERS", false),
+			SAMLPlanTiers:        getEnvAsList("SSO_SAML_PLAN_TIERS", "enterprise"),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:         getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:       getEnv("WEBAUTHN_RP_NAME", getEnv("APP_NAME", "MyERP v2")),
+			Origins:      getEnvAsList("WEBAUTHN_ORIGINS", getEnv("FRONTEND_URL", "http://localhost:3000")),
+			ChallengeTTL: getEnvAsDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+		},
+		Quotas: QuotaConfig{
+			UserLimits:       getEnvAsLimits("PLAN_USER_LIMITS", "free=5,starter=25,professional=100", 1),
+			StorageLimits:    getEnvAsLimits("PLAN_STORAGE_LIMITS_MB", "free=500,starter=5120,professional=51200", 1<<20),
+			APICallLimits:    getEnvAsLimits("PLAN_API_CALL_LIMITS", "free=100000,starter=1000000,professional=10000000", 1),
+			Thresholds:       getEnvAsPercents("QUOTA_WARNING_THRESHOLDS", "80,90,100"),
+			ReminderInterval: getEnvAsDuration("QUOTA_REMINDER_INTERVAL", 72*time.Hour),
+		},
+		Billing: BillingConfig{
+			FeatureTiers:        getEnvAsTiers("PLAN_FEATURES", "webhooks=starter|professional|enterprise,automations=professional|enterprise,sandboxes=professional|enterprise"),
+			StripeSecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
+			StripeWebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			StripeAPIURL:        getEnv("STRIPE_API_URL", "https://api.stripe.com"),
+			StripePriceIDs:      getEnvAsMap("STRIPE_PRICE_IDS"),
+		},
+		Storage: StorageConfig{
+			Backend:              getEnv("STORAGE_BACKEND", "local"),
+			Prefix:               getEnv("STORAGE_PREFIX", ""),
+			LocalPath:            getEnv("STORAGE_LOCAL_PATH", "./data/files"),
+			S3Endpoint:           getEnv("STORAGE_S3_ENDPOINT", ""),
+			S3Region:             getEnv("STORAGE_S3_REGION", "us-east-1"),
+			S3Bucket:             getEnv("STORAGE_S3_BUCKET", ""),
+			S3AccessKeyID:        getEnv("STORAGE_S3_ACCESS_KEY_ID", ""),
+			S3SecretKey:          getEnv("STORAGE_S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:          getEnvAsBool("STORAGE_S3_PATH_STYLE", false),
+			S3SSE:                getEnv("STORAGE_S3_SSE", ""),
+			S3KMSKeyID:           getEnv("STORAGE_S3_KMS_KEY_ID", ""),
+			GCSEndpoint:          getEnv("STORAGE_GCS_ENDPOINT", "https://storage.googleapis.com"),
+			GCSBucket:            getEnv("STORAGE_GCS_BUCKET", ""),
+			GCSAccessKeyID:       getEnv("STORAGE_GCS_ACCESS_KEY_ID", ""),
+			GCSSecretKey:         getEnv("STORAGE_GCS_SECRET", ""),
+			GCSKMSKeyName:        getEnv("STORAGE_GCS_KMS_KEY_NAME", ""),
+			AzureEndpoint:        getEnv("STORAGE_AZURE_ENDPOINT", ""),
+			AzureAccount:         getEnv("STORAGE_AZURE_ACCOUNT", ""),
+			AzureAccountKey:      getEnv("STORAGE_AZURE_ACCOUNT_KEY", ""),
+			AzureContainer:       getEnv("STORAGE_AZURE_CONTAINER", ""),
+			AzureEncryptionScope: getEnv("STORAGE_AZURE_ENCRYPTION_SCOPE", ""),
+			Lifecycle:            getEnvAsDurations("STORAGE_LIFECYCLE", "artifact=720h"),
+			DownloadBaseURL:      getEnv("STORAGE_DOWNLOAD_BASE_URL", getEnv("APP_BASE_URL", "http://localhost:8080")),
+			SignedURLTTL:         getEnvAsDuration("STORAGE_SIGNED_URL_TTL", 15*time.Minute),
+			SigningSecret:        getEnv("STORAGE_SIGNING_SECRET", getEnv("JWT_SECRET", "your-jwt-secret-key-change-in-production")),
+			MaxUploadSize:        int64(getEnvAsInt("STORAGE_MAX_UPLOAD_SIZE_MB", 25)) << 20,
+			MaxAvatarSize:        int64(getEnvAsInt("STORAGE_MAX_AVATAR_SIZE_MB", 2)) << 20,
+			DeletedRetention:     getEnvAsDuration("STORAGE_DELETED_RETENTION", 30*24*time.Hour),
 		},
 		App: AppConfig{
 			Name:            getEnv("APP_NAME", "MyERP v2"),
@@ -202,6 +757,274 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("DB_NAME is required")
 	}
 
+	// Validate regions
+	if _, exists := c.Regions.DatabaseURLs[c.Regions.DefaultRegion]; exists {
+		return fmt.Errorf("DB_REGION_URLS must not redefine the default region %q", c.Regions.DefaultRegion)
+	}
+
+	// Validate queue workers
+	if c.Jobs.AsyncConcurrency < 1 {
+		return fmt.Errorf("JOBS_ASYNC_CONCURRENCY must be at least 1")
+	}
+	if c.Jobs.EmailConcurrency < 1 {
+		return fmt.Errorf("JOBS_EMAIL_CONCURRENCY must be at least 1")
+	}
+	if c.Jobs.WebhookConcurrency < 1 {
+		return fmt.Errorf("JOBS_WEBHOOK_CONCURRENCY must be at least 1")
+	}
+	if c.Jobs.SIEMConcurrency < 1 {
+		return fmt.Errorf("JOBS_SIEM_CONCURRENCY must be at least 1")
+	}
+	if c.Queues.EmailMaxPending < 0 || c.Queues.WebhookMaxPending < 0 || c.Queues.AsyncMaxQueued < 0 || c.Queues.AlertDepth < 0 {
+		return fmt.Errorf("QUEUE_* limits must not be negative")
+	}
+
+	// Validate deletions
+	if c.Deletion.UserRetention <= 0 {
+		return fmt.Errorf("DELETION_USER_RETENTION must be positive")
+	}
+	if c.Deletion.TenantGracePeriod < 24*time.Hour {
+		return fmt.Errorf("DELETION_TENANT_GRACE_PERIOD must be at least 24h")
+	}
+	if c.Deletion.TenantConfirmationExpiry <= 0 {
+		return fmt.Errorf("DELETION_TENANT_CONFIRMATION_EXPIRY must be positive")
+	}
+
+	// Validate webhooks
+	if c.Webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.IsProduction() && c.Webhooks.AllowPrivateNetworks {
+		return fmt.Errorf("WEBHOOK_ALLOW_PRIVATE_NETWORKS must not be enabled in production")
+	}
+
+	// Validate SIEM forwarding
+	if c.SIEM.BatchSize < 1 || c.SIEM.MaxBatchesPerRun < 1 {
+		return fmt.Errorf("SIEM_BATCH_SIZE and SIEM_MAX_BATCHES_PER_RUN must be at least 1")
+	}
+	if c.SIEM.MaxLag < time.Hour {
+		return fmt.Errorf("SIEM_MAX_LAG must be at least 1h")
+	}
+
+	// Validate approval links
+	if c.Approvals.LinkTTL <= 0 {
+		return fmt.Errorf("APPROVAL_LINK_TTL must be positive")
+	}
+	if c.Approvals.StepUpAmount < 0 {
+		return fmt.Errorf("APPROVAL_LINK_STEP_UP_AMOUNT must not be negative")
+	}
+
+	// Validate user imports
+	if c.UserImport.MaxFileSize <= 0 || c.UserImport.MaxRows < 1 {
+		return fmt.Errorf("USER_IMPORT_MAX_FILE_SIZE_MB and USER_IMPORT_MAX_ROWS must be positive")
+	}
+	if c.UserImport.SyncRowLimit < 0 {
+		return fmt.Errorf("USER_IMPORT_SYNC_ROW_LIMIT must not be negative")
+	}
+	if c.UserImport.SetupLinkTTL <= 0 {
+		return fmt.Errorf("USER_IMPORT_SETUP_LINK_TTL must be positive")
+	}
+
+	// Validate single sign-on
+	if c.SSO.StateTTL <= 0 || c.SSO.HTTPTimeout <= 0 {
+		return fmt.Errorf("SSO_STATE_TTL and SSO_HTTP_TIMEOUT must be positive")
+	}
+	if c.IsProduction() && c.SSO.AllowInsecureIssuers {
+		return fmt.Errorf("SSO_ALLOW_INSECURE_ISSUERS must not be enabled in production")
+	}
+
+	// Validate 2FA bypass codes
+	if c.Security.TwoFABypassCodeTTL <= 0 {
+		return fmt.Errorf("2FA_BYPASS_CODE_TTL must be positive")
+	}
+	if c.Security.TwoFABypassDailyLimit < 0 {
+		return fmt.Errorf("2FA_BYPASS_DAILY_LIMIT must not be negative")
+	}
+
+	// Validate the session cache
+	if c.Security.SessionCacheTTL < 0 {
+		return fmt.Errorf("SESSION_CACHE_TTL must not be negative")
+	}
+
+	// Validate the concurrent session limit
+	if c.Security.MaxConcurrentSessions < 0 {
+		return fmt.Errorf("MAX_CONCURRENT_SESSIONS must not be negative")
+	}
+	if c.Security.SessionLimitPolicy != "evict_oldest" && c.Security.SessionLimitPolicy != "reject" {
+		return fmt.Errorf("SESSION_LIMIT_POLICY must be evict_oldest or reject")
+	}
+
+	// Validate passkeys
+	if c.WebAuthn.RPID == "" || len(c.WebAuthn.Origins) == 0 {
+		return fmt.Errorf("WEBAUTHN_RP_ID and WEBAUTHN_ORIGINS must be set")
+	}
+	if c.WebAuthn.ChallengeTTL <= 0 {
+		return fmt.Errorf("WEBAUTHN_CHALLENGE_TTL must be positive")
+	}
+
+	// Validate quotas
+	if len(c.Quotas.Thresholds) == 0 {
+		return fmt.Errorf("QUOTA_WARNING_THRESHOLDS must list percents between 1 and 100")
+	}
+	if c.Quotas.ReminderInterval <= 0 {
+		return fmt.Errorf("QUOTA_REMINDER_INTERVAL must be positive")
+	}
+
+	// Validate billing
+	if c.Billing.StripeSecretKey != "" && c.Billing.StripeWebhookSecret == "" {
+		return fmt.Errorf("STRIPE_WEBHOOK_SECRET is required with STRIPE_SECRET_KEY")
+	}
+	for tier := range c.Billing.StripePriceIDs {
+		switch tier {
+		case "starter", "professional", "enterprise":
+		default:
+			return fmt.Errorf("STRIPE_PRICE_IDS may only price the starter, professional and enterprise plans, not %q", tier)
+		}
+	}
+
+	// Validate the email provider
+	switch c.Email.Provider {
+	case "smtp":
+		if c.Email.SMTPHost == "" {
+			return fmt.Errorf("SMTP_HOST is required with the smtp email provider")
+		}
+	case "sendgrid":
+		if c.Email.SendGridAPIKey == "" {
+			return fmt.Errorf("SENDGRID_API_KEY is required with the sendgrid email provider")
+		}
+	case "ses":
+		if c.Email.SESAccessKeyID == "" || c.Email.SESSecretKey == "" {
+			return fmt.Errorf("SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY are required with the ses email provider")
+		}
+	case "mailgun":
+		if c.Email.MailgunDomain == "" || c.Email.MailgunAPIKey == "" {
+			return fmt.Errorf("MAILGUN_DOMAIN and MAILGUN_API_KEY are required with the mailgun email provider")
+		}
+	default:
+		return fmt.Errorf("EMAIL_PROVIDER must be smtp, sendgrid, ses or mailgun")
+	}
+
+	// Validate file storage
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalPath == "" {
+			return fmt.Errorf("STORAGE_LOCAL_PATH is required with the local storage backend")
+		}
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3AccessKeyID == "" || c.Storage.S3SecretKey == "" {
+			return fmt.Errorf("STORAGE_S3_ENDPOINT, STORAGE_S3_BUCKET, STORAGE_S3_ACCESS_KEY_ID and STORAGE_S3_SECRET_ACCESS_KEY are required with the s3 storage backend")
+		}
+		if c.Storage.S3SSE != "" && c.Storage.S3SSE != "AES256" && c.Storage.S3SSE != "aws:kms" {
+			return fmt.Errorf("STORAGE_S3_SSE must be empty, AES256 or aws:kms")
+		}
+	case "gcs":
+		if c.Storage.GCSBucket == "" || c.Storage.GCSAccessKeyID == "" || c.Storage.GCSSecretKey == "" {
+			return fmt.Errorf("STORAGE_GCS_BUCKET, STORAGE_GCS_ACCESS_KEY_ID and STORAGE_GCS_SECRET are required with the gcs storage backend")
+		}
+	case "azure":
+		if c.Storage.AzureAccount == "" || c.Storage.AzureAccountKey == "" || c.Storage.AzureContainer == "" {
+			return fmt.Errorf("STORAGE_AZURE_ACCOUNT, STORAGE_AZURE_ACCOUNT_KEY and STORAGE_AZURE_CONTAINER are required with the azure storage backend")
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be local, s3, gcs or azure")
+	}
+	for _, part := range strings.Split(strings.Trim(c.Storage.Prefix, "/"), "/") {
+		if part == "." || part == ".." || strings.Contains(part, "\\") {
+			return fmt.Errorf("STORAGE_PREFIX must be a slash-separated path without . or .. segments")
+		}
+	}
+	if c.Storage.SignedURLTTL <= 0 || c.Storage.DeletedRetention <= 0 {
+		return fmt.Errorf("STORAGE_SIGNED_URL_TTL and STORAGE_DELETED_RETENTION must be positive")
+	}
+	if c.Storage.MaxUploadSize <= 0 || c.Storage.MaxAvatarSize <= 0 {
+		return fmt.Errorf("STORAGE_MAX_UPLOAD_SIZE_MB and STORAGE_MAX_AVATAR_SIZE_MB must be positive")
+	}
+
+	// Validate request cost accounting
+	if c.Metrics.FlushInterval <= 0 || c.Metrics.Retention < 24*time.Hour {
+		return fmt.Errorf("METRICS_FLUSH_INTERVAL must be positive and METRICS_RETENTION at least 24h")
+	}
+
+	// Validate usage analytics
+	if c.Usage.FlushInterval <= 0 || c.Usage.Retention < 48*time.Hour || c.Usage.SinkTimeout <= 0 {
+		return fmt.Errorf("USAGE_ANALYTICS_FLUSH_INTERVAL and USAGE_ANALYTICS_SINK_TIMEOUT must be positive and USAGE_ANALYTICS_RETENTION at least 48h")
+	}
+	if c.Usage.SinkURL != "" {
+		if u, err := url.Parse(c.Usage.SinkURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("USAGE_ANALYTICS_SINK_URL must be an http(s) URL")
+		}
+	}
+
+	// Validate usage metering
+	if c.Jobs.MeteringRollupInterval <= 0 || c.Jobs.MeteringRollupInterval > 24*time.Hour {
+		return fmt.Errorf("JOBS_METERING_ROLLUP_INTERVAL must be between 0 and 24h")
+	}
+	if c.Metering.Retention < 62*24*time.Hour {
+		return fmt.Errorf("METERING_RETENTION must be at least 62 days (1488h)")
+	}
+
+	// Validate health checks
+	if c.Health.Timeout <= 0 {
+		return fmt.Errorf("HEALTH_CHECK_TIMEOUT must be positive")
+	}
+
+	// Validate CORS and security headers
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS must not allow every origin: requests carry credentials")
+		}
+		if strings.Count(origin, "*") > 1 || (!strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://")) {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS entry %q must be an http(s) origin with at most one *", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		return fmt.Errorf("CORS_MAX_AGE must not be negative")
+	}
+	if c.Headers.HSTSMaxAge < 0 {
+		return fmt.Errorf("HSTS_MAX_AGE must not be negative")
+	}
+	if c.Headers.FrameOptions != "" && c.Headers.FrameOptions != "DENY" && c.Headers.FrameOptions != "SAMEORIGIN" {
+		return fmt.Errorf("X_FRAME_OPTIONS must be DENY, SAMEORIGIN or empty")
+	}
+
+	// Validate tenant domains
+	if c.Domains.CacheTTL < 0 {
+		return fmt.Errorf("TENANT_CACHE_TTL must not be negative")
+	}
+	if c.Domains.MaxPerTenant < 0 {
+		return fmt.Errorf("TENANT_MAX_DOMAINS must not be negative")
+	}
+	if c.Domains.SlugRedirectPeriod < 0 {
+		return fmt.Errorf("TENANT_SLUG_REDIRECT_PERIOD must not be negative")
+	}
+
+	// Validate notifications
+	switch c.Notifications.DefaultDigest {
+	case "off", "daily", "weekly":
+	default:
+		return fmt.Errorf("NOTIFICATION_DEFAULT_DIGEST must be off, daily or weekly")
+	}
+
+	// Validate tracing
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be an http(s) URL")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("OTEL_TRACES_SAMPLE_RATIO must be between 0 and 1")
+		}
+	}
+
+	// Validate rate limits
+	if c.Security.RateLimitEnabled {
+		if c.Security.TenantRateLimit <= 0 || c.Security.TenantRateBurst <= 0 {
+			return fmt.Errorf("RATE_LIMIT_TENANT_PER_MINUTE and RATE_LIMIT_TENANT_BURST must be positive")
+		}
+		if c.Security.IPRateLimit <= 0 || c.Security.IPRateBurst <= 0 {
+			return fmt.Errorf("RATE_LIMIT_IP_PER_MINUTE and RATE_LIMIT_IP_BURST must be positive")
+		}
+	}
+
 	// Validate Redis connection
 	if c.Redis.Host == "" {
 		return fmt.Errorf("REDIS_HOST is required")
@@ -223,6 +1046,17 @@ func (c *RedisConfig) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// IsReserved reports whether a subdomain of BaseDomain is kept for the
+// platform rather than a tenant
+func (c *TenantDomainConfig) IsReserved(subdomain string) bool {
+	for _, reserved := range c.ReservedSubdomains {
+		if strings.EqualFold(subdomain, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Environment == "development"
@@ -250,6 +1084,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := getEnv(key, "")
 	if value, err := strconv.ParseBool(valueStr); err == nil {
@@ -265,3 +1107,93 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsList parses a comma-separated list, e.g. "professional,enterprise"
+func getEnvAsList(key, defaultValue string) []string {
+	var result []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// getEnvAsLimits parses a comma-separated list of plan=limit pairs, e.g.
+// "free=5,starter=25", multiplying the limits by unit
+func getEnvAsLimits(key, defaultValue string, unit int64) map[string]int64 {
+	limits := make(map[string]int64)
+	for _, pair := range getEnvAsList(key, defaultValue) {
+		plan, value, found := strings.Cut(pair, "=")
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if !found || err != nil || limit < 0 {
+			continue
+		}
+		limits[strings.TrimSpace(plan)] = limit * unit
+	}
+	return limits
+}
+
+// getEnvAsTiers parses a comma-separated list of name=tier|tier pairs, e.g.
+// "webhooks=starter|professional". A name without tiers is in none.
+func getEnvAsTiers(key, defaultValue string) map[string][]string {
+	tiers := make(map[string][]string)
+	for _, pair := range getEnvAsList(key, defaultValue) {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		list := []string{}
+		for _, tier := range strings.Split(value, "|") {
+			if tier = strings.TrimSpace(tier); tier != "" {
+				list = append(list, tier)
+			}
+		}
+		tiers[strings.TrimSpace(name)] = list
+	}
+	return tiers
+}
+
+// getEnvAsDurations parses a comma-separated list of name=duration pairs,
+// e.g. "artifact=720h". Invalid and non-positive durations are dropped.
+func getEnvAsDurations(key, defaultValue string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for _, pair := range getEnvAsList(key, defaultValue) {
+		name, value, found := strings.Cut(pair, "=")
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if !found || err != nil || duration <= 0 {
+			continue
+		}
+		durations[strings.TrimSpace(name)] = duration
+	}
+	return durations
+}
+
+// getEnvAsPercents parses a comma-separated list of percents between 1 and
+// 100, e.g. "80,90,100", in ascending order. Invalid values are dropped.
+func getEnvAsPercents(key, defaultValue string) []int {
+	var percents []int
+	for _, value := range getEnvAsList(key, defaultValue) {
+		percent, err := strconv.Atoi(value)
+		if err != nil || percent < 1 || percent > 100 {
+			continue
+		}
+		percents = append(percents, percent)
+	}
+	sort.Ints(percents)
+	return percents
+}
+
+// getEnvAsMap parses a comma-separated list of key=value pairs,
+// e.g. "eu=postgres://...,us=postgres://..."
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(getEnv(key, ""), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || name == "" || value == "" {
+			continue
+		}
+		result[name] = value
+	}
+	return result
+}
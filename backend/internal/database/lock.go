@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Distributed locks backed by PostgreSQL advisory locks.
+//
+// Every replica of the server shares the same database, so advisory locks give
+// us cluster-wide mutual exclusion without extra infrastructure. Keys are
+// arbitrary strings hashed with hashtext(); callers should namespace them
+// (e.g. "<tenant_id>:sales:quote" or "job:session_cleanup").
+
+// AdvisoryXactLock takes a transaction-scoped advisory lock, blocking until it
+// is available. The lock is released automatically on commit or rollback.
+func AdvisoryXactLock(ctx context.Context, tx *sqlx.Tx, key string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		return fmt.Errorf("failed to acquire advisory lock %q: %w", key, err)
+	}
+	return nil
+}
+
+// AdvisoryLock is a session-level advisory lock held on a dedicated connection
+type AdvisoryLock struct {
+	key  string
+	conn *sqlx.Conn
+}
+
+// TryAdvisoryLock attempts to take a session-level advisory lock without
+// blocking. It returns nil (and no error) when another process holds the lock.
+//
+// The lock is pinned to its own connection and must be released with Release.
+// If the process dies the connection closes and PostgreSQL frees the lock, so
+// a crashed replica can never hold it forever.
+//
+// Example usage:
+//
+//	lock, err := database.TryAdvisoryLock(ctx, db, "job:session_cleanup")
+//	if err != nil || lock == nil {
+//	    return err // another replica is running it
+//	}
+//	defer lock.Release()
+func TryAdvisoryLock(ctx context.Context, db *sqlx.DB, key string) (*AdvisoryLock, error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for advisory lock: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.GetContext(ctx, &acquired, `SELECT pg_try_advisory_lock(hashtext($1))`, key); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire advisory lock %q: %w", key, err)
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+
+	return &AdvisoryLock{key: key, conn: conn}, nil
+}
+
+// Release unlocks the advisory lock and returns its connection to the pool
+func (l *AdvisoryLock) Release() error {
+	defer l.conn.Close()
+
+	// Use a fresh context: the caller's context may already be cancelled
+	if _, err := l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, l.key); err != nil {
+		return fmt.Errorf("failed to release advisory lock %q: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Masking strategies. Scrambled values are derived from an md5 of the original
+// value, so equal inputs stay equal (and unique columns stay unique) without
+// the original being recoverable.
+const (
+	MaskEmail = "email" // masked-<hash>@example.invalid
+	MaskName  = "name"  // Masked <HASH>
+	MaskPhone = "phone" // +000<digits>
+	MaskText  = "text"  // masked-<hash>
+	MaskNull  = "null"  // NULL
+	MaskFixed = "fixed" // Value, for every row
+)
+
+// MaskingRule describes how one column is masked
+type MaskingRule struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Strategy string `json:"strategy"`
+	Value    string `json:"value,omitempty"` // Used by the fixed strategy
+}
+
+// MaskDatabaseOptions controls a masking run
+type MaskDatabaseOptions struct {
+	// DryRun only reports the statements that would be executed
+	DryRun bool
+
+	// Logf receives progress messages (optional)
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultMaskingRules covers the personal data stored by the application:
+// names, emails, phone numbers, addresses, tax and registration numbers, and
+// request metadata. Password hashes are replaced so production credentials
+// cannot be used against the copy.
+func DefaultMaskingRules() []MaskingRule {
+	return []MaskingRule{
+		{Table: "tenants", Column: "email", Strategy: MaskEmail},
+
+		{Table: "users", Column: "email", Strategy: MaskEmail},
+		{Table: "users", Column: "first_name", Strategy: MaskName},
+		{Table: "users", Column: "last_name", Strategy: MaskName},
+		{Table: "users", Column: "phone", Strategy: MaskPhone},
+		{Table: "users", Column: "avatar_url", Strategy: MaskNull},
+		{Table: "users", Column: "last_login_ip", Strategy: MaskNull},
+		{Table: "users", Column: "two_factor_recovery_email", Strategy: MaskEmail},
+		{Table: "users", Column: "password_hash", Strategy: MaskFixed, Value: "!"},
+
+		{Table: "company_settings", Column: "primary_email", Strategy: MaskEmail},
+		{Table: "company_settings", Column: "support_email", Strategy: MaskEmail},
+		{Table: "company_settings", Column: "phone_number", Strategy: MaskPhone},
+		{Table: "company_settings", Column: "fax", Strategy: MaskPhone},
+		{Table: "company_settings", Column: "street_address", Strategy: MaskText},
+		{Table: "company_settings", Column: "rc_number", Strategy: MaskText},
+		{Table: "company_settings", Column: "nif_number", Strategy: MaskText},
+		{Table: "company_settings", Column: "nis_number", Strategy: MaskText},
+		{Table: "company_settings", Column: "ai_number", Strategy: MaskText},
+
+		{Table: "invitations", Column: "email", Strategy: MaskEmail},
+		{Table: "invitations", Column: "message", Strategy: MaskNull},
+
+		{Table: "employees", Column: "first_name", Strategy: MaskName},
+		{Table: "employees", Column: "last_name", Strategy: MaskName},
+		{Table: "employees", Column: "email", Strategy: MaskEmail},
+		{Table: "employees", Column: "phone", Strategy: MaskPhone},
+		{Table: "employees", Column: "salary_band", Strategy: MaskNull},
+
+		{Table: "leave_requests", Column: "reason", Strategy: MaskNull},
+		{Table: "leave_requests", Column: "decision_note", Strategy: MaskNull},
+
+		{Table: "timesheets", Column: "decision_note", Strategy: MaskNull},
+		{Table: "timesheet_entries", Column: "description", Strategy: MaskNull},
+
+		{Table: "files", Column: "original_name", Strategy: MaskText},
+
+		{Table: "crm_customers", Column: "name", Strategy: MaskName},
+		{Table: "crm_customers", Column: "email", Strategy: MaskEmail},
+		{Table: "crm_customers", Column: "phone", Strategy: MaskPhone},
+		{Table: "crm_customers", Column: "address", Strategy: MaskNull},
+		{Table: "crm_customers", Column: "tax_id", Strategy: MaskText},
+		{Table: "crm_customers", Column: "notes", Strategy: MaskNull},
+
+		{Table: "crm_contacts", Column: "first_name", Strategy: MaskName},
+		{Table: "crm_contacts", Column: "last_name", Strategy: MaskName},
+		{Table: "crm_contacts", Column: "email", Strategy: MaskEmail},
+		{Table: "crm_contacts", Column: "phone", Strategy: MaskPhone},
+		{Table: "crm_contacts", Column: "notes", Strategy: MaskNull},
+
+		{Table: "crm_activities", Column: "body", Strategy: MaskNull},
+
+		{Table: "suppliers", Column: "name", Strategy: MaskName},
+		{Table: "suppliers", Column: "email", Strategy: MaskEmail},
+		{Table: "suppliers", Column: "phone", Strategy: MaskPhone},
+		{Table: "suppliers", Column: "address", Strategy: MaskNull},
+		{Table: "suppliers", Column: "tax_id", Strategy: MaskText},
+		{Table: "suppliers", Column: "notes", Strategy: MaskNull},
+
+		{Table: "sales_documents", Column: "customer_name", Strategy: MaskName},
+		{Table: "sales_documents", Column: "customer_email", Strategy: MaskEmail},
+		{Table: "sales_documents", Column: "customer_address", Strategy: MaskNull},
+		{Table: "sales_documents", Column: "notes", Strategy: MaskNull},
+
+		{Table: "sessions", Column: "ip_address", Strategy: MaskNull},
+		{Table: "sessions", Column: "user_agent", Strategy: MaskNull},
+		{Table: "sessions", Column: "city", Strategy: MaskNull},
+
+		{Table: "audit_logs", Column: "ip_address", Strategy: MaskNull},
+		{Table: "audit_logs", Column: "user_agent", Strategy: MaskNull},
+		{Table: "audit_logs", Column: "metadata", Strategy: MaskFixed, Value: "{}"},
+
+		{Table: "email_outbox", Column: "to_email", Strategy: MaskEmail},
+		{Table: "email_outbox", Column: "body", Strategy: MaskFixed, Value: "[masked]"},
+		{Table: "email_outbox", Column: "text_body", Strategy: MaskNull},
+
+		{Table: "email_broadcast_recipients", Column: "email", Strategy: MaskEmail},
+		{Table: "email_broadcast_recipients", Column: "first_name", Strategy: MaskName},
+		{Table: "email_broadcast_recipients", Column: "last_name", Strategy: MaskName},
+
+		{Table: "pending_deletions", Column: "label", Strategy: MaskText},
+	}
+}
+
+// LoadMaskingRules reads masking rules from a JSON file holding an array of
+// rules ({"table", "column", "strategy", "value"})
+func LoadMaskingRules(path string) ([]MaskingRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read masking rules: %w", err)
+	}
+
+	var rules []MaskingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse masking rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if _, err := maskExpression(rule, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
+
+// MaskDatabase scrambles personal data in every row of db according to rules.
+// It is meant for copies of production (e.g. staging refreshes) and must
+// never be pointed at production itself. Each table is masked in its own
+// transaction; tables or columns missing from the schema are skipped.
+func MaskDatabase(ctx context.Context, db *sqlx.DB, rules []MaskingRule, opts MaskDatabaseOptions) error {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+
+	byTable := make(map[string][]MaskingRule)
+	for _, rule := range rules {
+		byTable[rule.Table] = append(byTable[rule.Table], rule)
+	}
+
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		columns, err := tableColumns(ctx, db, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			logf("Skipping %s: table does not exist", table)
+			continue
+		}
+
+		var assignments []string
+		var args []interface{}
+		for _, rule := range byTable[table] {
+			if !columns[rule.Column] {
+				logf("Skipping %s.%s: column does not exist", table, rule.Column)
+				continue
+			}
+
+			expr, err := maskExpression(rule, len(args)+1)
+			if err != nil {
+				return err
+			}
+			if rule.Strategy == MaskFixed {
+				args = append(args, rule.Value)
+			}
+			assignments = append(assignments, fmt.Sprintf("%s = %s", pq.QuoteIdentifier(rule.Column), expr))
+		}
+		if len(assignments) == 0 {
+			continue
+		}
+
+		query := fmt.Sprintf("UPDATE %s SET %s", pq.QuoteIdentifier(table), strings.Join(assignments, ", "))
+		if opts.DryRun {
+			logf("[dry run] %s", query)
+			continue
+		}
+
+		masked, err := maskTable(ctx, db, query, args)
+		if err != nil {
+			return fmt.Errorf("failed to mask %s: %w", table, err)
+		}
+		logf("Masked %d rows in %s", masked, table)
+	}
+
+	return nil
+}
+
+// maskTable runs a masking statement across every tenant
+func maskTable(ctx context.Context, db *sqlx.DB, query string, args []interface{}) (int64, error) {
+	tx, err := WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	masked, _ := result.RowsAffected()
+
+	return masked, tx.Commit()
+}
+
+// tableColumns returns the columns of a table in the public schema, or none
+// if it does not exist
+func tableColumns(ctx context.Context, db *sqlx.DB, table string) (map[string]bool, error) {
+	var names []string
+	err := db.SelectContext(ctx, &names, `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = $1
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+	return columns, nil
+}
+
+// maskExpression returns the SQL expression masking rule's column. param is
+// the placeholder number used by the fixed strategy.
+func maskExpression(rule MaskingRule, param int) (string, error) {
+	if rule.Table == "" || rule.Column == "" {
+		return "", fmt.Errorf("masking rule needs a table and a column")
+	}
+
+	column := pq.QuoteIdentifier(rule.Column)
+	hash := fmt.Sprintf("md5(%s::text)", column)
+
+	var expr string
+	switch rule.Strategy {
+	case MaskEmail:
+		expr = fmt.Sprintf("'masked-' || left(%s, 16) || '@example.invalid'", hash)
+	case MaskName:
+		expr = fmt.Sprintf("'Masked ' || upper(left(%s, 8))", hash)
+	case MaskPhone:
+		expr = fmt.Sprintf("'+000' || left(translate(%s, 'abcdef', '012345'), 9)", hash)
+	case MaskText:
+		expr = fmt.Sprintf("'masked-' || left(%s, 12)", hash)
+	case MaskNull:
+		return "NULL", nil
+	case MaskFixed:
+		return fmt.Sprintf("$%d", param), nil
+	default:
+		return "", fmt.Errorf("unknown masking strategy %q for %s.%s", rule.Strategy, rule.Table, rule.Column)
+	}
+
+	// NULLs stay NULL
+	return fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE %s END", column, expr), nil
+}
@@ -2,38 +2,104 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq" // PostgreSQL driver
 	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
 )
 
 // NewPostgresDB creates a new PostgreSQL database connection with connection pooling
 func NewPostgresDB(cfg *config.DatabaseConfig) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", cfg.DSN())
+	db, err := connect(cfg.DSN(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(cfg.MaxOpenConns)       // Maximum number of open connections
-	db.SetMaxIdleConns(cfg.MaxIdleConns)       // Maximum number of idle connections
-	db.SetConnMaxLifetime(cfg.ConnMaxLifetime) // Maximum lifetime of a connection
-	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime) // Maximum idle time before closing
-
 	// Verify connection is working
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return db, nil
 }
 
+// connect opens a pgx connection pool (pgxpool) and exposes it through
+// database/sql, so repositories keep using sqlx. The pool, not database/sql,
+// holds idle connections; each connection caches the statements it prepares,
+// so a hot query is parsed and planned once per connection rather than on
+// every call. Statements are timed for request cost accounting (see
+// metrics.WrapConnector).
+func connect(dsn string, cfg *config.DatabaseConfig) (*sqlx.DB, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns) // Maximum number of open connections
+	}
+	poolConfig.MinConns = int32(cfg.MinConns)        // Connections kept open while idle
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime // Maximum lifetime of a connection
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime // Maximum idle time before closing
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+	if cfg.StatementCacheCapacity == 0 {
+		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+
+	// A canceled context (client gone, request timeout) cancels the query on
+	// the server too, and the connection stays usable once it has; pgx's
+	// default only interrupts the network read, which closes the connection
+	poolConfig.ConnConfig.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:          conn,
+			DeadlineDelay: 5 * time.Second,
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &poolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool}
+	sqlDB := sql.OpenDB(metrics.WrapConnector(connector))
+	// Idle connections go back to the pool, which is what limits and ages them
+	sqlDB.SetMaxIdleConns(0)
+
+	db := sqlx.NewDb(sqlDB, "pgx")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// poolConnector closes the pool with the sql.DB opened on it
+type poolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c *poolConnector) Close() error {
+	c.pool.Close()
+	return nil
+}
+
 // Close gracefully closes the database connection
 func Close(db *sqlx.DB) error {
 	if db != nil {
@@ -113,6 +179,14 @@ func ExecInTransaction(ctx context.Context, db *sqlx.DB, fn func(*sqlx.Tx) error
 // ExecInTenantTransaction executes a function within a tenant-scoped transaction.
 // This automatically sets the RLS context before executing the function.
 func ExecInTenantTransaction(ctx context.Context, db *sqlx.DB, tenantID string, fn func(*sqlx.Tx) error) error {
+	// Route to the tenant's data region (no-op in single-region deployments)
+	if parsedID, err := uuid.Parse(tenantID); err == nil {
+		db, err = tenantDB(ctx, db, parsedID)
+		if err != nil {
+			return err
+		}
+	}
+
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
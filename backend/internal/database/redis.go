@@ -7,6 +7,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
 )
 
 // NewRedisClient creates a new Redis client with the provided configuration
@@ -34,6 +35,9 @@ func NewRedisClient(cfg *config.RedisConfig) (*redis.Client, error) {
 		MaxRetryBackoff: 512 * time.Millisecond,
 	})
 
+	// Time commands for request cost accounting
+	client.AddHook(metrics.RedisHook{})
+
 	// Verify connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
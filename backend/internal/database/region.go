@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+)
+
+// RegionRouter routes tenant-scoped queries to the database cluster of the
+// tenant's data region.
+//
+// The primary database is the catalog: it always holds the tenants table and
+// serves the default region. Tenants whose tenants.data_region is set to another
+// region have all their RLS-scoped rows stored in that region's cluster instead.
+//
+// Repositories do not need to know about regions: WithTenantContext and
+// WithTenantContextReadOnly swap the primary database for the tenant's regional
+// database when a router is installed with SetRegionRouter.
+type RegionRouter struct {
+	catalog       *sqlx.DB
+	defaultRegion string
+	regions       map[string]*sqlx.DB
+	cacheTTL      time.Duration
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]regionCacheEntry
+}
+
+type regionCacheEntry struct {
+	region    string
+	migrating bool
+	expiresAt time.Time
+}
+
+// activeRegionRouter holds the router used by WithTenantContext (nil = single
+// region). It is read by every tenant-scoped query, so it is swapped
+// atomically.
+var activeRegionRouter atomic.Pointer[RegionRouter]
+
+// NewRegionRouter creates a router whose default region is served by the catalog database
+func NewRegionRouter(catalog *sqlx.DB, defaultRegion string, cacheTTL time.Duration) *RegionRouter {
+	return &RegionRouter{
+		catalog:       catalog,
+		defaultRegion: defaultRegion,
+		regions:       map[string]*sqlx.DB{defaultRegion: catalog},
+		cacheTTL:      cacheTTL,
+		cache:         make(map[uuid.UUID]regionCacheEntry),
+	}
+}
+
+// ConnectRegions connects to every region configured in DB_REGION_URLS.
+// The returned router always contains the default region (the catalog database).
+func ConnectRegions(catalog *sqlx.DB, cfg *config.Config) (*RegionRouter, error) {
+	router := NewRegionRouter(catalog, cfg.Regions.DefaultRegion, cfg.Regions.CacheTTL)
+
+	for name, url := range cfg.Regions.DatabaseURLs {
+		db, err := connect(url, &cfg.Database)
+		if err != nil {
+			router.Close()
+			return nil, fmt.Errorf("failed to connect to region %s: %w", name, err)
+		}
+
+		router.AddRegion(name, db)
+	}
+
+	return router, nil
+}
+
+// SetRegionRouter installs the router used to resolve tenant databases.
+// Passing nil restores single-region behaviour.
+func SetRegionRouter(router *RegionRouter) {
+	activeRegionRouter.Store(router)
+}
+
+// AddRegion registers a regional database
+func (r *RegionRouter) AddRegion(name string, db *sqlx.DB) {
+	r.regions[name] = db
+}
+
+// DefaultRegion returns the name of the region served by the catalog database
+func (r *RegionRouter) DefaultRegion() string {
+	return r.defaultRegion
+}
+
+// Catalog returns the catalog (primary) database
+func (r *RegionRouter) Catalog() *sqlx.DB {
+	return r.catalog
+}
+
+// Regions returns the configured region names, sorted
+func (r *RegionRouter) Regions() []string {
+	names := make([]string, 0, len(r.regions))
+	for name := range r.regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegionDB returns the database of a region
+func (r *RegionRouter) RegionDB(region string) (*sqlx.DB, error) {
+	db, ok := r.regions[region]
+	if !ok {
+		return nil, fmt.Errorf("unknown data region: %s", region)
+	}
+	return db, nil
+}
+
+// TenantRegion returns the data region of a tenant, using the lookup cache
+func (r *RegionRouter) TenantRegion(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	entry, err := r.lookup(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	return entry.region, nil
+}
+
+// DBForTenant returns the database holding a tenant's data.
+// Access is refused while the tenant is being moved between regions.
+func (r *RegionRouter) DBForTenant(ctx context.Context, tenantID uuid.UUID) (*sqlx.DB, error) {
+	entry, err := r.lookup(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.migrating {
+		return nil, fmt.Errorf("tenant data is being moved to another region, please retry later")
+	}
+
+	return r.RegionDB(entry.region)
+}
+
+// Invalidate drops a tenant from the lookup cache
+func (r *RegionRouter) Invalidate(tenantID uuid.UUID) {
+	r.mu.Lock()
+	delete(r.cache, tenantID)
+	r.mu.Unlock()
+}
+
+// Close closes all regional connections except the catalog
+func (r *RegionRouter) Close() {
+	for name, db := range r.regions {
+		if name != r.defaultRegion {
+			db.Close()
+		}
+	}
+}
+
+// lookup resolves a tenant's region from the cache or the catalog
+func (r *RegionRouter) lookup(ctx context.Context, tenantID uuid.UUID) (regionCacheEntry, error) {
+	r.mu.RLock()
+	entry, ok := r.cache[tenantID]
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	var row struct {
+		DataRegion        *string    `db:"data_region"`
+		RegionMigrationAt *time.Time `db:"region_migration_started_at"`
+	}
+	query := `SELECT data_region, region_migration_started_at FROM tenants WHERE id = $1`
+
+	err := r.catalog.GetContext(ctx, &row, query, tenantID)
+	if err == sql.ErrNoRows {
+		return regionCacheEntry{}, fmt.Errorf("tenant not found")
+	}
+	if err != nil {
+		return regionCacheEntry{}, fmt.Errorf("failed to resolve tenant region: %w", err)
+	}
+
+	entry = regionCacheEntry{
+		region:    r.defaultRegion,
+		migrating: row.RegionMigrationAt != nil,
+		expiresAt: time.Now().Add(r.cacheTTL),
+	}
+	if row.DataRegion != nil && *row.DataRegion != "" {
+		entry.region = *row.DataRegion
+	}
+
+	r.mu.Lock()
+	r.cache[tenantID] = entry
+	r.mu.Unlock()
+
+	return entry, nil
+}
+
+// RegionDBs returns every database that may hold tenant rows for queries that
+// span tenants (e.g. login by email). Without a router, or for a database other
+// than the catalog, it returns db alone.
+func RegionDBs(db *sqlx.DB) []*sqlx.DB {
+	router := activeRegionRouter.Load()
+	if router == nil || db != router.catalog {
+		return []*sqlx.DB{db}
+	}
+
+	dbs := make([]*sqlx.DB, 0, len(router.regions))
+	for _, name := range router.Regions() {
+		dbs = append(dbs, router.regions[name])
+	}
+	return dbs
+}
+
+// NamedDB is a database by the name health checks report it under
+type NamedDB struct {
+	Name string
+	DB   *sqlx.DB
+}
+
+// Databases returns every database requests may use: db itself as the
+// primary, the other data regions' databases by region name and the read
+// replica
+func Databases(db *sqlx.DB) []NamedDB {
+	dbs := []NamedDB{{Name: "primary", DB: db}}
+
+	if router := activeRegionRouter.Load(); router != nil && db == router.catalog {
+		for _, name := range router.Regions() {
+			if name != router.defaultRegion {
+				dbs = append(dbs, NamedDB{Name: name, DB: router.regions[name]})
+			}
+		}
+	}
+	if replica, ok := activeReplicas[db]; ok {
+		dbs = append(dbs, NamedDB{Name: "replica", DB: replica})
+	}
+	return dbs
+}
+
+// tenantDB returns the database to use for a tenant-scoped transaction.
+// Only the catalog database is rerouted, so code that deliberately targets a
+// specific regional database (e.g. region moves) keeps using it.
+func tenantDB(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*sqlx.DB, error) {
+	router := activeRegionRouter.Load()
+	if router == nil || db != router.catalog {
+		return db, nil
+	}
+	return router.DBForTenant(ctx, tenantID)
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MoveTenantOptions controls a tenant region move
+type MoveTenantOptions struct {
+	// DrainWait is how long to wait after locking the tenant so that other
+	// server instances see the lock once their region cache expires.
+	// Should be at least DB_REGION_CACHE_TTL.
+	DrainWait time.Duration
+
+	// Logf receives progress messages (optional)
+	Logf func(format string, args ...interface{})
+}
+
+// MoveTenant moves all of a tenant's data from its current region to targetRegion.
+//
+// Steps:
+//  1. Lock the tenant in the catalog (region_migration_started_at) so that
+//     WithTenantContext refuses access, and wait for caches to drain
+//  2. Copy the tenant row and every table with a tenant_id column to the
+//     target region in a single transaction
+//  3. Switch tenants.data_region in the catalog and release the lock
+//  4. Delete the tenant's rows from the source region
+//
+// If the copy fails the target transaction is rolled back and the lock is
+// released, leaving the tenant in its original region.
+//
+// Rows are written with session_replication_role = replica so foreign keys
+// (including cycles such as users <-> departments) and triggers do not fire
+// during the copy. The connection user therefore needs superuser rights
+// (rds_superuser on managed Postgres) in both regions; this is checked before
+// the tenant is locked.
+func MoveTenant(ctx context.Context, router *RegionRouter, tenantID uuid.UUID, targetRegion string, opts MoveTenantOptions) error {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+
+	sourceRegion, err := router.TenantRegion(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if sourceRegion == targetRegion {
+		return fmt.Errorf("tenant is already in region %s", targetRegion)
+	}
+
+	sourceDB, err := router.RegionDB(sourceRegion)
+	if err != nil {
+		return err
+	}
+	targetDB, err := router.RegionDB(targetRegion)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRegionMovePrivileges(ctx, sourceDB); err != nil {
+		return fmt.Errorf("region %s: %w", sourceRegion, err)
+	}
+	if err := checkRegionMovePrivileges(ctx, targetDB); err != nil {
+		return fmt.Errorf("region %s: %w", targetRegion, err)
+	}
+
+	// 1. Lock tenant
+	result, err := router.catalog.ExecContext(ctx, `
+		UPDATE tenants SET region_migration_started_at = NOW()
+		WHERE id = $1 AND region_migration_started_at IS NULL
+	`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to lock tenant: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("tenant is already being moved")
+	}
+	router.Invalidate(tenantID)
+
+	unlock := func() {
+		router.catalog.ExecContext(context.Background(), `UPDATE tenants SET region_migration_started_at = NULL WHERE id = $1`, tenantID)
+		router.Invalidate(tenantID)
+	}
+
+	logf("Tenant locked, waiting %s for other instances to drain", opts.DrainWait)
+	select {
+	case <-time.After(opts.DrainWait):
+	case <-ctx.Done():
+		unlock()
+		return ctx.Err()
+	}
+
+	// 2. Copy data
+	tables, err := tenantTables(ctx, sourceDB)
+	if err != nil {
+		unlock()
+		return err
+	}
+
+	if err := copyTenantData(ctx, sourceDB, targetDB, tenantID, tables, logf); err != nil {
+		unlock()
+		return err
+	}
+
+	// 3. Switch region
+	var newRegion interface{} = targetRegion
+	if targetRegion == router.defaultRegion {
+		newRegion = nil
+	}
+	_, err = router.catalog.ExecContext(ctx, `
+		UPDATE tenants SET data_region = $1, region_migration_started_at = NULL
+		WHERE id = $2
+	`, newRegion, tenantID)
+	if err != nil {
+		unlock()
+		return fmt.Errorf("failed to switch tenant region (data was copied to %s, source left intact): %w", targetRegion, err)
+	}
+	router.Invalidate(tenantID)
+	logf("Tenant switched to region %s", targetRegion)
+
+	// 4. Clean up source
+	if err := deleteTenantData(ctx, sourceDB, tenantID, tables, sourceRegion != router.defaultRegion); err != nil {
+		return fmt.Errorf("tenant moved but source cleanup failed: %w", err)
+	}
+	logf("Source data removed from region %s", sourceRegion)
+
+	return nil
+}
+
+// tenantTables lists every base table with a tenant_id column
+func tenantTables(ctx context.Context, db *sqlx.DB) ([]string, error) {
+	var tables []string
+	err := db.SelectContext(ctx, &tables, `
+		SELECT c.table_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t
+			ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = 'public'
+		  AND c.column_name = 'tenant_id'
+		  AND t.table_type = 'BASE TABLE'
+		  AND c.table_name <> 'tenants'
+		ORDER BY c.table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant tables: %w", err)
+	}
+	return tables, nil
+}
+
+// copyTenantData copies the tenant row and all tenant rows into the target
+// database in one transaction. Rows are moved as JSON so column types survive
+// unchanged.
+func copyTenantData(
+	ctx context.Context,
+	sourceDB, targetDB *sqlx.DB,
+	tenantID uuid.UUID,
+	tables []string,
+	logf func(format string, args ...interface{}),
+) error {
+	source, err := regionMoveTx(ctx, sourceDB, tenantID)
+	if err != nil {
+		return err
+	}
+	defer source.Rollback()
+
+	target, err := regionMoveTx(ctx, targetDB, tenantID)
+	if err != nil {
+		return err
+	}
+	defer target.Rollback()
+
+	// Tenant row (needed for foreign keys; the catalog stays authoritative)
+	var tenantJSON string
+	err = source.GetContext(ctx, &tenantJSON, `SELECT row_to_json(t)::text FROM tenants t WHERE id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to read tenant row: %w", err)
+	}
+	_, err = target.ExecContext(ctx, `
+		INSERT INTO tenants SELECT * FROM json_populate_record(NULL::tenants, $1::json)
+		ON CONFLICT (id) DO NOTHING
+	`, tenantJSON)
+	if err != nil {
+		return fmt.Errorf("failed to copy tenant row: %w", err)
+	}
+
+	for _, table := range tables {
+		var rowsJSON string
+		err := source.GetContext(ctx, &rowsJSON, fmt.Sprintf(
+			`SELECT COALESCE(json_agg(t), '[]')::text FROM %s t WHERE tenant_id = $1`, table,
+		), tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", table, err)
+		}
+
+		var result sql.Result
+		if table == "role_permissions" {
+			// Permission IDs are generated per cluster; remap by resource/action
+			result, err = target.ExecContext(ctx, `
+				INSERT INTO role_permissions (id, tenant_id, role_id, permission_id, effect, created_at, created_by)
+				SELECT rp.id, rp.tenant_id, rp.role_id, tp.id, rp.effect, rp.created_at, rp.created_by
+				FROM json_populate_recordset(NULL::role_permissions, $1::json) rp
+				JOIN json_to_recordset($2::json) AS sp(id UUID, resource VARCHAR, action VARCHAR)
+					ON sp.id = rp.permission_id
+				JOIN permissions tp ON tp.resource = sp.resource AND tp.action = sp.action
+			`, rowsJSON, sourcePermissionsJSON(ctx, source))
+		} else {
+			result, err = target.ExecContext(ctx, fmt.Sprintf(
+				`INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json)`, table, table,
+			), rowsJSON)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+
+		rows, _ := result.RowsAffected()
+		logf("Copied %d row(s) from %s", rows, table)
+	}
+
+	if err := target.Commit(); err != nil {
+		return fmt.Errorf("failed to commit copy: %w", err)
+	}
+
+	return nil
+}
+
+// sourcePermissionsJSON returns the source permission catalog as JSON
+func sourcePermissionsJSON(ctx context.Context, tx *sqlx.Tx) string {
+	var permissionsJSON string
+	err := tx.GetContext(ctx, &permissionsJSON, `
+		SELECT COALESCE(json_agg(json_build_object('id', id, 'resource', resource, 'action', action)), '[]')::text
+		FROM permissions
+	`)
+	if err != nil {
+		return "[]"
+	}
+	return permissionsJSON
+}
+
+// deleteTenantData removes a tenant's rows from a region.
+// Outside the default region the tenant row itself is removed too.
+func deleteTenantData(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID, tables []string, deleteTenantRow bool) error {
+	tx, err := regionMoveTx(ctx, db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE tenant_id = $1`, table), tenantID); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", table, err)
+		}
+	}
+
+	if deleteTenantRow {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID); err != nil {
+			return fmt.Errorf("failed to delete tenant row: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkRegionMovePrivileges checks the connection user may disable foreign
+// key and trigger enforcement, as regionMoveTx does, so a move without the
+// rights fails before the tenant is locked or anything is copied
+func checkRegionMovePrivileges(ctx context.Context, db *sqlx.DB) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+		return fmt.Errorf("database user cannot move tenants, superuser (rds_superuser on managed Postgres) required: %w", err)
+	}
+
+	return nil
+}
+
+// regionMoveTx starts a transaction that can see and write the tenant's rows
+// under every RLS policy (including policies without a bypass clause), with
+// foreign key and trigger enforcement disabled
+func regionMoveTx(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*sqlx.Tx, error) {
+	tx, err := WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL app.current_tenant_id = '%s'", tenantID.String()))
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to set tenant context: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to disable constraint triggers (superuser required): %w", err)
+	}
+
+	return tx, nil
+}
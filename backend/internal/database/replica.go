@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+)
+
+// activeReplicas maps a primary database to its read replica. Read-only
+// transactions (WithTenantContextReadOnly) on a primary with a replica run
+// on the replica; everything else stays on the primary. It is set at
+// startup and only read afterwards.
+var activeReplicas = map[*sqlx.DB]*sqlx.DB{}
+
+// ConnectReplica connects to the read replica configured in DB_REPLICA_URL
+// and routes the primary's read-only transactions to it. It returns nil when
+// no replica is configured.
+func ConnectReplica(primary *sqlx.DB, cfg *config.DatabaseConfig) (*sqlx.DB, error) {
+	if cfg.ReplicaURL == "" {
+		return nil, nil
+	}
+
+	replica, err := connect(cfg.ReplicaURL, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	SetReplica(primary, replica)
+	return replica, nil
+}
+
+// SetReplica routes read-only transactions on primary to replica. Passing a
+// nil replica sends them back to the primary.
+func SetReplica(primary, replica *sqlx.DB) {
+	if replica == nil {
+		delete(activeReplicas, primary)
+		return
+	}
+	activeReplicas[primary] = replica
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads go to the primary database, for
+// callers that must read what they have just written: a replica lags behind
+// the primary. Within a request's session this is automatic once the
+// request has written.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// readDB returns the database a read-only transaction on db runs on: its
+// replica, unless ctx must read its writes
+func readDB(ctx context.Context, db *sqlx.DB) *sqlx.DB {
+	replica, ok := activeReplicas[db]
+	if !ok {
+		return db
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return db
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && s.hasWritten() {
+		return db
+	}
+	return replica
+}
@@ -34,7 +34,25 @@ type TenantContext struct {
 //	}
 //
 //	return tx.Commit()
-func WithTenantContext(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*sqlx.Tx, error) {
+//
+// Within a unit of work for the tenant (see TxManager) it returns a savepoint
+// of the unit's transaction instead. Within a request's session (see
+// WithSession) the transaction is opened on the session's connection.
+func WithTenantContext(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*Tx, error) {
+	if tx, joined, err := joinUnit(ctx, tenantID); joined {
+		return tx, err
+	}
+
+	// Route to the tenant's data region (no-op in single-region deployments)
+	db, err := tenantDB(ctx, db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx, ok, err := beginSession(ctx, db, tenantID, false); ok {
+		return tx, err
+	}
+
 	// Start a new transaction
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -50,7 +68,7 @@ func WithTenantContext(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*s
 		return nil, fmt.Errorf("failed to set tenant context: %w", err)
 	}
 
-	return tx, nil
+	return &Tx{Tx: tx}, nil
 }
 
 // WithBypassRLS temporarily bypasses RLS for administrative operations.
@@ -59,6 +77,9 @@ func WithTenantContext(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*s
 // - Cross-tenant analytics (with proper authorization)
 // - Administrative tools (with audit logging)
 //
+// In multi-region deployments this only sees the database it is given (by
+// default the catalog / default region); it is not routed per tenant.
+//
 // WARNING: Use this function with extreme caution. All operations using this
 // function should be logged in audit_logs for security compliance.
 //
@@ -96,8 +117,29 @@ func WithBypassRLS(ctx context.Context, db *sqlx.DB) (*sqlx.Tx, error) {
 
 // WithTenantContextReadOnly is similar to WithTenantContext but for read-only operations.
 // This can be used for SELECT queries where you want to ensure tenant isolation
-// but don't need write capabilities.
-func WithTenantContextReadOnly(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*sqlx.Tx, error) {
+// but don't need write capabilities. Within a unit of work for the tenant it
+// returns a savepoint of the unit's transaction, which sees its changes.
+//
+// With a read replica configured (see ConnectReplica) the transaction runs on
+// the replica, unless ctx must read its own writes (see WithPrimary).
+func WithTenantContextReadOnly(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) (*Tx, error) {
+	if tx, joined, err := joinUnit(ctx, tenantID); joined {
+		return tx, err
+	}
+
+	// Route to the tenant's data region (no-op in single-region deployments)
+	db, err := tenantDB(ctx, db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Route reads to the read replica, if any
+	db = readDB(ctx, db)
+
+	if tx, ok, err := beginSession(ctx, db, tenantID, true); ok {
+		return tx, err
+	}
+
 	// Start a transaction with read-only option
 	opts := &sql.TxOptions{ReadOnly: true}
 	tx, err := db.BeginTxx(ctx, opts)
@@ -114,7 +156,7 @@ func WithTenantContextReadOnly(ctx context.Context, db *sqlx.DB, tenantID uuid.U
 		return nil, fmt.Errorf("failed to set tenant context: %w", err)
 	}
 
-	return tx, nil
+	return &Tx{Tx: tx}, nil
 }
 
 // GetTenantIDFromContext extracts tenant ID from the request context.
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// CloneTenantOptions controls a tenant clone
+type CloneTenantOptions struct {
+	// SkipTables are tenant tables whose rows are not copied (e.g. sessions)
+	SkipTables []string
+
+	// Transform runs in the target transaction after every row was copied,
+	// e.g. to anonymize personal data (optional)
+	Transform func(ctx context.Context, tx *sqlx.Tx) error
+
+	// Logf receives progress messages (optional)
+	Logf func(format string, args ...interface{})
+}
+
+// CloneTenant copies all of a tenant's rows to another, already created
+// tenant in the same data region. db is the catalog database; the copy runs
+// against the source tenant's regional database.
+//
+// Row IDs are kept: primary keys are (tenant_id, id), so rewriting tenant_id is
+// enough and every intra-tenant reference stays valid. Tables keyed by id
+// alone get fresh IDs. Like region moves, the copy runs in a single target
+// transaction with session_replication_role = replica and needs superuser
+// rights.
+func CloneTenant(ctx context.Context, db *sqlx.DB, sourceTenantID, targetTenantID uuid.UUID, opts CloneTenantOptions) error {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+
+	dataDB, err := tenantDB(ctx, db, sourceTenantID)
+	if err != nil {
+		return err
+	}
+
+	tables, err := tenantTables(ctx, dataDB)
+	if err != nil {
+		return err
+	}
+
+	skip := make(map[string]bool, len(opts.SkipTables))
+	for _, table := range opts.SkipTables {
+		skip[table] = true
+	}
+
+	source, err := regionMoveTx(ctx, dataDB, sourceTenantID)
+	if err != nil {
+		return err
+	}
+	defer source.Rollback()
+
+	target, err := regionMoveTx(ctx, dataDB, targetTenantID)
+	if err != nil {
+		return err
+	}
+	defer target.Rollback()
+
+	// Outside the default region the target tenant row has to be copied from
+	// the catalog first (the catalog stays authoritative)
+	if dataDB != db {
+		var tenantJSON string
+		err := db.GetContext(ctx, &tenantJSON, `SELECT row_to_json(t)::text FROM tenants t WHERE id = $1`, targetTenantID)
+		if err != nil {
+			return fmt.Errorf("failed to read tenant row: %w", err)
+		}
+		_, err = target.ExecContext(ctx, `
+			INSERT INTO tenants SELECT * FROM json_populate_record(NULL::tenants, $1::json)
+			ON CONFLICT (id) DO NOTHING
+		`, tenantJSON)
+		if err != nil {
+			return fmt.Errorf("failed to copy tenant row: %w", err)
+		}
+	}
+
+	for _, table := range tables {
+		if skip[table] {
+			continue
+		}
+
+		var keyedByTenant bool
+		err := source.GetContext(ctx, &keyedByTenant, `
+			SELECT EXISTS (
+				SELECT 1 FROM pg_index i
+				JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+				WHERE i.indrelid = $1::regclass AND i.indisprimary AND a.attname = 'tenant_id'
+			)
+		`, table)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table, err)
+		}
+
+		overrides := `jsonb_build_object('tenant_id', $2::uuid)`
+		if !keyedByTenant {
+			overrides = `jsonb_build_object('tenant_id', $2::uuid, 'id', uuid_generate_v4())`
+		}
+
+		var rowsJSON string
+		err = source.GetContext(ctx, &rowsJSON, fmt.Sprintf(
+			`SELECT COALESCE(json_agg(to_jsonb(t) || %s), '[]')::text FROM %s t WHERE tenant_id = $1`, overrides, table,
+		), sourceTenantID, targetTenantID)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", table, err)
+		}
+
+		// Rows left by an interrupted earlier clone are replaced
+		if _, err := target.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE tenant_id = $1`, table), targetTenantID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+
+		result, err := target.ExecContext(ctx, fmt.Sprintf(
+			`INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json)`, table, table,
+		), rowsJSON)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", table, err)
+		}
+
+		rows, _ := result.RowsAffected()
+		logf("Cloned %d row(s) from %s", rows, table)
+	}
+
+	if opts.Transform != nil {
+		if err := opts.Transform(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	if err := target.Commit(); err != nil {
+		return fmt.Errorf("failed to commit clone: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeTenant permanently deletes a tenant and all of its rows, in its data
+// region and in the catalog. db is the catalog database.
+func PurgeTenant(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID) error {
+	dataDB, err := tenantDB(ctx, db, tenantID)
+	if err != nil {
+		return err
+	}
+
+	tables, err := tenantTables(ctx, dataDB)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteTenantData(ctx, dataDB, tenantID, tables, false); err != nil {
+		return err
+	}
+
+	// The tenant row is deleted with foreign keys enforced so that catalog
+	// references (ON DELETE CASCADE / SET NULL) are applied
+	if dataDB != db {
+		if _, err := dataDB.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID); err != nil {
+			return fmt.Errorf("failed to delete regional tenant row: %w", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to delete tenant row: %w", err)
+	}
+
+	if router := activeRegionRouter.Load(); router != nil {
+		router.Invalidate(tenantID)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+type sessionKey struct{}
+
+// session is the connection a request's repositories share. It is checked
+// out of the pool on first use and its tenant context is set once, for the
+// connection rather than per transaction, so the transactions repositories
+// open on it skip both the checkout and SET LOCAL. A transaction opened
+// while another is in progress, e.g. by a repository a service calls with
+// its own transaction open, is a savepoint of it.
+type session struct {
+	tenantID uuid.UUID
+
+	mu         sync.Mutex
+	db         *sqlx.DB // The database the connection belongs to, after region routing
+	conn       *sqlx.Conn
+	tx         *sqlx.Tx // The transaction in progress, if any
+	readOnly   bool
+	savepoints int
+	released   bool
+	wrote      bool // A writable transaction was opened; reads stay on the primary
+}
+
+// WithSession returns a context whose repository calls for the tenant share
+// one connection, until release is called. Authentication starts a session
+// for each request once it knows the tenant.
+//
+// The context must not be used by several goroutines at once, nor after
+// release, which returns the connection to the pool.
+func WithSession(ctx context.Context, tenantID uuid.UUID) (context.Context, func()) {
+	s := &session{tenantID: tenantID}
+	return context.WithValue(ctx, sessionKey{}, s), s.release
+}
+
+// beginSession returns a transaction on the connection of the request's
+// session, if ctx has one for the tenant and the database. It returns false
+// when the caller has to open its own transaction instead.
+func beginSession(ctx context.Context, db *sqlx.DB, tenantID uuid.UUID, readOnly bool) (*Tx, bool, error) {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok || s.tenantID != tenantID {
+		return nil, false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !readOnly {
+		s.wrote = true
+	}
+	if s.released {
+		return nil, false, nil
+	}
+	if s.conn == nil {
+		conn, err := db.Connx(ctx)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to check out connection: %w", err)
+		}
+		if _, err := conn.ExecContext(ctx, "SELECT set_config('app.current_tenant_id', $1, false)", tenantID.String()); err != nil {
+			discard(conn)
+			return nil, true, fmt.Errorf("failed to set tenant context: %w", err)
+		}
+		s.db, s.conn = db, conn
+	}
+	if s.db != db {
+		return nil, false, nil
+	}
+
+	if s.tx != nil {
+		// A write can't be a savepoint of a read-only transaction
+		if s.readOnly && !readOnly {
+			return nil, false, nil
+		}
+
+		s.savepoints++
+		savepoint := fmt.Sprintf("session_%d", s.savepoints)
+		_, err := s.tx.ExecContext(ctx, "SAVEPOINT "+savepoint)
+		if err == nil {
+			return &Tx{Tx: s.tx, savepoint: savepoint}, true, nil
+		}
+		if !errors.Is(err, sql.ErrTxDone) {
+			return nil, true, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		// The transaction ended with its context; start another
+		s.tx = nil
+	}
+
+	tx, err := s.conn.BeginTxx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	s.tx, s.readOnly = tx, readOnly
+	return &Tx{Tx: tx, end: func() { s.end(tx) }}, true, nil
+}
+
+// ReleaseSession returns the connection of the request's session to the
+// pool early, e.g. before a long-lived stream. Repository calls made after
+// open their own transactions.
+func ReleaseSession(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.release()
+	}
+}
+
+// hasWritten reports whether the request has opened a writable transaction,
+// after which its reads must see the primary
+func (s *session) hasWritten() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wrote
+}
+
+// end records that a transaction on the connection is over
+func (s *session) end(tx *sqlx.Tx) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == tx {
+		s.tx = nil
+	}
+}
+
+// release resets the connection's tenant context and returns it to the pool
+func (s *session) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.released = true
+	if s.conn == nil {
+		return
+	}
+	if s.tx != nil {
+		s.tx.Rollback()
+		s.tx = nil
+	}
+
+	// The connection outlives the request's context
+	if _, err := s.conn.ExecContext(context.Background(), "RESET app.current_tenant_id"); err != nil {
+		log.Printf("⚠️  Failed to reset tenant context, closing connection: %v", err)
+		discard(s.conn)
+	} else {
+		s.conn.Close()
+	}
+	s.conn = nil
+}
+
+// discard closes a connection rather than returning it to the pool, so
+// another tenant never gets it with this tenant's context
+func discard(conn *sqlx.Conn) {
+	conn.Raw(func(driverConn interface{}) error {
+		if wrapped, ok := driverConn.(interface{ Unwrap() driver.Conn }); ok {
+			driverConn = wrapped.Unwrap()
+		}
+		// Closing the driver connection would only release it to the pgx
+		// pool; the pool drops connections that are closed underneath it
+		if pgxConn, ok := driverConn.(*stdlib.Conn); ok {
+			pgxConn.Conn().Close(context.Background())
+		}
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
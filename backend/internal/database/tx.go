@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Tx is a transaction opened by WithTenantContext. Inside a unit of work, or
+// while another transaction of the request's session is in progress, it is
+// a savepoint of that transaction instead: Commit releases the savepoint and
+// Rollback undoes what was done since it, and the enclosing transaction
+// decides whether any of it is committed.
+type Tx struct {
+	*sqlx.Tx
+	savepoint string
+	done      bool
+	end       func() // Called once a session's transaction is over
+}
+
+// Commit commits the transaction, or releases its savepoint
+func (tx *Tx) Commit() error {
+	if tx.savepoint == "" {
+		defer tx.ended()
+		return tx.Tx.Commit()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.Tx.Exec("RELEASE SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+// Rollback rolls the transaction back, or back to its savepoint
+func (tx *Tx) Rollback() error {
+	if tx.savepoint == "" {
+		defer tx.ended()
+		return tx.Tx.Rollback()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.Tx.Exec("ROLLBACK TO SAVEPOINT " + tx.savepoint)
+	return err
+}
+
+func (tx *Tx) ended() {
+	if tx.end != nil {
+		tx.end()
+	}
+}
+
+// Querier runs queries on a database or in a transaction
+type Querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Conn returns the transaction of the unit of work ctx is part of, or else db.
+// Queries on tables without RLS, e.g. tenants, use it to take part in units
+// of work.
+func Conn(ctx context.Context, db *sqlx.DB) Querier {
+	if u, ok := ctx.Value(unitKey{}).(*unit); ok {
+		return u.tx
+	}
+	return db
+}
+
+// TxManager runs units of work: multi-step operations whose repository calls
+// all commit or all roll back together, e.g. creating a tenant and its first
+// user.
+//
+// Example usage:
+//
+//	err := txManager.InTenantTx(ctx, tenantID, func(ctx context.Context) error {
+//	    if err := userRepo.Create(ctx, tenantID, user); err != nil {
+//	        return err
+//	    }
+//	    return userRoleRepo.AssignRoles(ctx, tenantID, user.ID, roleIDs, actorID)
+//	})
+type TxManager struct {
+	db *sqlx.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *sqlx.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+type unitKey struct{}
+
+// unit is a unit of work in progress
+type unit struct {
+	tx         *sqlx.Tx
+	tenantID   uuid.UUID
+	savepoints int
+}
+
+// InTenantTx runs fn in one transaction scoped to the tenant. Repositories
+// called with the context fn gets join the transaction rather than opening
+// their own, for this tenant; the transaction commits when fn returns nil
+// and rolls back when it returns an error or panics. Called within a unit of
+// work for the same tenant, fn joins it.
+//
+// The context must not be used by several goroutines at once, nor outlive
+// fn. Work that must not be undone with the unit, e.g. sending an email,
+// belongs after InTenantTx returns.
+func (m *TxManager) InTenantTx(ctx context.Context, tenantID uuid.UUID, fn func(ctx context.Context) error) error {
+	if u, ok := ctx.Value(unitKey{}).(*unit); ok {
+		if u.tenantID != tenantID {
+			return fmt.Errorf("unit of work for tenant %s cannot join one for tenant %s", tenantID, u.tenantID)
+		}
+		return fn(ctx)
+	}
+
+	tx, err := WithTenantContext(ctx, m.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, unitKey{}, &unit{tx: tx.Tx, tenantID: tenantID})); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// joinUnit returns a savepoint of the unit of work ctx is part of, if it is
+// for the tenant
+func joinUnit(ctx context.Context, tenantID uuid.UUID) (*Tx, bool, error) {
+	u, ok := ctx.Value(unitKey{}).(*unit)
+	if !ok || u.tenantID != tenantID {
+		return nil, false, nil
+	}
+
+	u.savepoints++
+	savepoint := fmt.Sprintf("unit_%d", u.savepoints)
+	if _, err := u.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, true, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	return &Tx{Tx: u.tx, savepoint: savepoint}, true, nil
+}
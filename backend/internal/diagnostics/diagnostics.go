@@ -0,0 +1,440 @@
+// Package diagnostics checks that a deployment is ready to serve: its
+// databases, Redis, SMTP server, encryption key and clock. It backs the
+// server's diagnose command, run before go-live and in support situations.
+package diagnostics
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/migrator"
+	"myerp-v2/internal/utils"
+)
+
+// Status of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+const (
+	redisPings           = 10                     // Pings averaged to measure Redis latency
+	redisSlowLatency     = 10 * time.Millisecond  // Average latency worth a warning
+	redisFailLatency     = 100 * time.Millisecond // Average latency that slows every request down
+	clockSkewWarn        = 2 * time.Second        // Skew worth a warning
+	clockSkewFail        = 30 * time.Second       // Skew that breaks 2FA codes (30s TOTP steps)
+	smtpTimeout          = 10 * time.Second       // Dial and conversation timeout of the SMTP check
+	encryptedSampleSize  = 5                      // Stored secrets decrypted per column
+	defaultEncryptionKey = "change-this-to-a-32-byte-key!!"
+)
+
+// encryptedColumns hold values encrypted with ENCRYPTION_KEY
+var encryptedColumns = []struct{ table, column string }{
+	{"users", "two_factor_secret"},
+	{"webhooks", "secret"},
+	{"escalation_policies", "slack_webhook_url"},
+	{"sso_providers", "client_secret_encrypted"},
+}
+
+// Result is the outcome of one check. Hint says how to fix a failed or
+// warned check.
+type Result struct {
+	Check    string        `json:"check"`
+	Status   Status        `json:"status"`
+	Detail   string        `json:"detail"`
+	Hint     string        `json:"hint,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// HasFailures returns true if a check failed
+func (r *Report) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the report for people, one check per line followed by its
+// hint
+func (r *Report) Print(w io.Writer) {
+	counts := map[Status]int{}
+	for _, result := range r.Results {
+		counts[result.Status]++
+		fmt.Fprintf(w, "[%-4s] %-28s %s (%s)\n", strings.ToUpper(string(result.Status)), result.Check, result.Detail, result.Duration.Round(time.Millisecond))
+		if result.Hint != "" && result.Status != StatusOK {
+			fmt.Fprintf(w, "       → %s\n", result.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n%d ok, %d warnings, %d failures\n", counts[StatusOK], counts[StatusWarn], counts[StatusFail])
+}
+
+// Database is a database to check, by region name
+type Database struct {
+	Name string
+	DB   *sqlx.DB // nil if it could not be connected to
+	Err  error    // Why it could not be connected to
+}
+
+// Diagnostics runs the checks. Databases and Redis may be missing when the
+// server could not connect to them; their checks then fail.
+type Diagnostics struct {
+	config        *config.Config
+	databases     []Database
+	redis         *redis.Client
+	redisErr      error
+	migrationsDir string
+}
+
+// New creates the diagnostics of a deployment
+func New(cfg *config.Config, databases []Database, redisClient *redis.Client, redisErr error, migrationsDir string) *Diagnostics {
+	return &Diagnostics{
+		config:        cfg,
+		databases:     databases,
+		redis:         redisClient,
+		redisErr:      redisErr,
+		migrationsDir: migrationsDir,
+	}
+}
+
+// Run runs every check
+func (d *Diagnostics) Run(ctx context.Context) *Report {
+	report := &Report{}
+	run := func(check string, fn func(ctx context.Context) Result) {
+		start := time.Now()
+		result := fn(ctx)
+		result.Check = check
+		result.Duration = time.Since(start)
+		report.Results = append(report.Results, result)
+	}
+
+	for _, db := range d.databases {
+		run("database "+db.Name, func(ctx context.Context) Result { return d.checkConnection(ctx, db) })
+		if db.DB == nil {
+			continue
+		}
+		run("schema version "+db.Name, func(ctx context.Context) Result { return d.checkSchemaVersion(ctx, db.DB) })
+		run("row-level security "+db.Name, func(ctx context.Context) Result { return checkRLS(ctx, db.DB) })
+		run("clock skew "+db.Name, func(ctx context.Context) Result { return checkDatabaseClock(ctx, db.DB) })
+	}
+	run("redis latency", d.checkRedisLatency)
+	run("clock skew redis", d.checkRedisClock)
+	if d.config.Email.Provider == "smtp" {
+		run("smtp", d.checkSMTP)
+	}
+	run("encryption key", d.checkEncryptionKey)
+
+	return report
+}
+
+// checkConnection reports whether a database could be connected to
+func (d *Diagnostics) checkConnection(ctx context.Context, db Database) Result {
+	if db.DB == nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot connect: %v", db.Err), Hint: "Check DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_NAME (or DB_REGION_URLS) and that the server is reachable"}
+	}
+	if err := database.HealthCheck(ctx, db.DB); err != nil {
+		return Result{Status: StatusFail, Detail: err.Error(), Hint: "Check the database server is up and accepting connections"}
+	}
+	return Result{Status: StatusOK, Detail: "connected"}
+}
+
+// checkSchemaVersion compares the applied migration version with the
+// migrations shipped with the server
+func (d *Diagnostics) checkSchemaVersion(ctx context.Context, db *sqlx.DB) Result {
+	migrations, err := migrator.Load(d.migrationsDir)
+	if err != nil {
+		return Result{Status: StatusWarn, Detail: err.Error(), Hint: "Run diagnose from the backend directory or pass -migrations DIR"}
+	}
+	var latest uint
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	var version uint
+	var dirty bool
+	err = db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return Result{Status: StatusFail, Detail: "no migrations applied", Hint: "Run: migrate up"}
+	}
+	if err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot read schema_migrations: %v", err), Hint: "Run: migrate up"}
+	}
+
+	switch {
+	case dirty:
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("version %d is dirty: a migration failed halfway", version), Hint: "Fix the failed migration by hand, then run: migrate force VERSION"}
+	case version > latest:
+		return Result{Status: StatusWarn, Detail: fmt.Sprintf("version %d is newer than this server's latest migration %d", version, latest), Hint: "Deploy the server version matching the schema"}
+	case version < latest:
+		pending := migrator.Pending(migrations, version)
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("version %d, %d migration(s) pending up to %d", version, len(pending), latest), Hint: "Run: migrate up"}
+	}
+	return Result{Status: StatusOK, Detail: fmt.Sprintf("version %d (latest)", version)}
+}
+
+// checkRLS checks every table with a tenant_id column has row-level security
+// enabled and a tenant_isolation policy, and that the application's role is
+// subject to it
+func checkRLS(ctx context.Context, db *sqlx.DB) Result {
+	var tables []struct {
+		Name       string `db:"name"`
+		RLSEnabled bool   `db:"rls_enabled"`
+		RLSForced  bool   `db:"rls_forced"`
+		HasPolicy  bool   `db:"has_policy"`
+		Owned      bool   `db:"owned"`
+	}
+	query := `
+		SELECT c.relname AS name,
+			c.relrowsecurity AS rls_enabled,
+			c.relforcerowsecurity AS rls_forced,
+			EXISTS (
+				SELECT 1 FROM pg_policies p
+				WHERE p.schemaname = n.nspname AND p.tablename = c.relname AND p.policyname = 'tenant_isolation'
+			) AS has_policy,
+			pg_get_userbyid(c.relowner) = current_user AS owned
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attname = 'tenant_id' AND NOT a.attisdropped
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+		ORDER BY c.relname
+	`
+	if err := db.SelectContext(ctx, &tables, query); err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot list tables: %v", err)}
+	}
+
+	var unprotected, owned []string
+	for _, table := range tables {
+		if !table.RLSEnabled || !table.HasPolicy {
+			unprotected = append(unprotected, table.Name)
+		} else if table.Owned && !table.RLSForced {
+			owned = append(owned, table.Name)
+		}
+	}
+	if len(unprotected) > 0 {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("no row-level security on %s", strings.Join(unprotected, ", ")), Hint: "Add ENABLE ROW LEVEL SECURITY and a tenant_isolation policy in a migration"}
+	}
+
+	var bypasses bool
+	if err := db.GetContext(ctx, &bypasses, `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`); err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot read the database role: %v", err)}
+	}
+	if bypasses {
+		return Result{Status: StatusWarn, Detail: fmt.Sprintf("%d tables protected, but the database user bypasses row-level security", len(tables)), Hint: "Connect as a role without SUPERUSER or BYPASSRLS so tenant isolation is enforced"}
+	}
+	if len(owned) > 0 {
+		return Result{Status: StatusWarn, Detail: fmt.Sprintf("%d tables protected, but the database user owns %d of them, which exempts it", len(tables), len(owned)), Hint: "Connect as a role that does not own the tables, or FORCE ROW LEVEL SECURITY on them"}
+	}
+	return Result{Status: StatusOK, Detail: fmt.Sprintf("%d tables protected", len(tables))}
+}
+
+// checkDatabaseClock compares the database server's clock with ours
+func checkDatabaseClock(ctx context.Context, db *sqlx.DB) Result {
+	var remote time.Time
+	start := time.Now()
+	if err := db.GetContext(ctx, &remote, `SELECT clock_timestamp()`); err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot read the clock: %v", err)}
+	}
+	return clockResult(start, time.Now(), remote, "the database server")
+}
+
+// checkRedisLatency measures the round trip of a Redis PING
+func (d *Diagnostics) checkRedisLatency(ctx context.Context) Result {
+	if d.redis == nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot connect: %v", d.redisErr), Hint: "Check REDIS_HOST, REDIS_PORT and REDIS_PASSWORD and that the server is reachable"}
+	}
+
+	var total, worst time.Duration
+	for i := 0; i < redisPings; i++ {
+		start := time.Now()
+		if err := d.redis.Ping(ctx).Err(); err != nil {
+			return Result{Status: StatusFail, Detail: fmt.Sprintf("ping failed: %v", err), Hint: "Check the Redis server is up"}
+		}
+		elapsed := time.Since(start)
+		total += elapsed
+		if elapsed > worst {
+			worst = elapsed
+		}
+	}
+
+	average := total / redisPings
+	detail := fmt.Sprintf("average %s, worst %s over %d pings", average.Round(time.Microsecond), worst.Round(time.Microsecond), redisPings)
+	switch {
+	case average > redisFailLatency:
+		return Result{Status: StatusFail, Detail: detail, Hint: "Run Redis close to the servers; rate limiting and sessions hit it on every request"}
+	case average > redisSlowLatency:
+		return Result{Status: StatusWarn, Detail: detail, Hint: "Run Redis in the same network as the servers"}
+	}
+	return Result{Status: StatusOK, Detail: detail}
+}
+
+// checkRedisClock compares the Redis server's clock with ours
+func (d *Diagnostics) checkRedisClock(ctx context.Context) Result {
+	if d.redis == nil {
+		return Result{Status: StatusFail, Detail: "redis unavailable"}
+	}
+	start := time.Now()
+	remote, err := d.redis.Time(ctx).Result()
+	if err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot read the clock: %v", err)}
+	}
+	return clockResult(start, time.Now(), remote, "the Redis server")
+}
+
+// clockResult compares a remote clock read between start and end with the
+// middle of the round trip
+func clockResult(start, end, remote time.Time, server string) Result {
+	local := start.Add(end.Sub(start) / 2)
+	skew := remote.Sub(local)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	detail := fmt.Sprintf("%s off from %s", skew.Round(time.Millisecond), server)
+	switch {
+	case skew > clockSkewFail:
+		return Result{Status: StatusFail, Detail: detail, Hint: "Synchronize both clocks with NTP; tokens, 2FA codes and scheduled jobs depend on them"}
+	case skew > clockSkewWarn:
+		return Result{Status: StatusWarn, Detail: detail, Hint: "Synchronize both clocks with NTP"}
+	}
+	return Result{Status: StatusOK, Detail: detail}
+}
+
+// checkSMTP connects to the SMTP server and authenticates like the email
+// outbox does, without sending anything
+func (d *Diagnostics) checkSMTP(ctx context.Context) Result {
+	cfg := &d.config.Email
+	addr := net.JoinHostPort(cfg.SMTPHost, fmt.Sprint(cfg.SMTPPort))
+	hint := "Check SMTP_HOST and SMTP_PORT and that outgoing connections to the port are allowed"
+
+	conn, err := (&net.Dialer{Timeout: smtpTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("cannot connect to %s: %v", addr, err), Hint: hint}
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("no SMTP greeting from %s: %v", addr, err), Hint: hint}
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("EHLO rejected: %v", err), Hint: hint}
+	}
+
+	tlsEnabled := false
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.SMTPHost}); err != nil {
+			return Result{Status: StatusFail, Detail: fmt.Sprintf("STARTTLS failed: %v", err), Hint: "Check the SMTP server's certificate is valid for SMTP_HOST"}
+		}
+		tlsEnabled = true
+	}
+
+	if cfg.SMTPUser == "" && cfg.SMTPPassword == "" {
+		client.Quit()
+		if d.config.IsProduction() {
+			return Result{Status: StatusWarn, Detail: fmt.Sprintf("connected to %s without authentication", addr), Hint: "Set SMTP_USER and SMTP_PASSWORD for your mail provider"}
+		}
+		return Result{Status: StatusOK, Detail: fmt.Sprintf("connected to %s without authentication", addr)}
+	}
+
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("%s does not offer authentication", addr), Hint: "Use the submission port of your mail provider (usually 587)"}
+	}
+	if err := client.Auth(smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)); err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("authentication failed: %v", err), Hint: "Check SMTP_USER and SMTP_PASSWORD"}
+	}
+	client.Quit()
+
+	detail := fmt.Sprintf("authenticated to %s as %s", addr, cfg.SMTPUser)
+	if !tlsEnabled {
+		detail += " without TLS"
+	}
+	return Result{Status: StatusOK, Detail: detail}
+}
+
+// checkEncryptionKey checks ENCRYPTION_KEY is usable and still decrypts the
+// secrets stored with it
+func (d *Diagnostics) checkEncryptionKey(ctx context.Context) Result {
+	key := []byte(d.config.Security.EncryptionKey)
+	if len(key) != 32 {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("ENCRYPTION_KEY is %d bytes, AES-256 needs 32", len(key)), Hint: "Set ENCRYPTION_KEY to 32 random bytes, e.g. openssl rand -base64 24"}
+	}
+
+	encrypted, err := utils.Encrypt("diagnose", key)
+	if err == nil {
+		_, err = utils.Decrypt(encrypted, key)
+	}
+	if err != nil {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("ENCRYPTION_KEY cannot encrypt: %v", err)}
+	}
+
+	checked, failed := 0, []string{}
+	for _, db := range d.databases {
+		if db.DB == nil {
+			continue
+		}
+		for _, col := range encryptedColumns {
+			values, err := sampleEncrypted(ctx, db.DB, col.table, col.column)
+			if err != nil {
+				continue // Table not migrated yet: reported by the schema check
+			}
+			for _, value := range values {
+				checked++
+				if _, err := utils.Decrypt(value, key); err != nil {
+					failed = append(failed, fmt.Sprintf("%s.%s (%s)", col.table, col.column, db.Name))
+					break
+				}
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return Result{Status: StatusFail, Detail: fmt.Sprintf("ENCRYPTION_KEY does not decrypt %s", strings.Join(failed, ", ")), Hint: "Restore the ENCRYPTION_KEY the secrets were stored with; 2FA, webhooks, Slack escalations and SSO fail until then"}
+	}
+	if string(key) == defaultEncryptionKey {
+		status := StatusWarn
+		if d.config.IsProduction() {
+			status = StatusFail
+		}
+		return Result{Status: status, Detail: "ENCRYPTION_KEY is the example key", Hint: "Set ENCRYPTION_KEY to 32 random bytes before storing any secret"}
+	}
+	return Result{Status: StatusOK, Detail: fmt.Sprintf("valid, decrypts %d stored secret(s)", checked)}
+}
+
+// sampleEncrypted reads a few values of an encrypted column across tenants
+func sampleEncrypted(ctx context.Context, db *sqlx.DB, table, column string) ([]string, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	values := []string{}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s IS NOT NULL AND %s <> '' LIMIT %d`, column, table, column, column, encryptedSampleSize)
+	if err := tx.SelectContext(ctx, &values, query); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
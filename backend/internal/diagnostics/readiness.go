@@ -0,0 +1,160 @@
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+)
+
+// Readiness statuses
+const (
+	ReadinessReady    = "ready"
+	ReadinessDegraded = "degraded" // A dependency the server can work without is down
+	ReadinessNotReady = "not_ready"
+)
+
+// Dependency is the status of one dependency the readiness probe checked.
+// Errors are logged rather than reported: the probe is public.
+type Dependency struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"` // ok | fail
+	Required  bool    `json:"required"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Readiness is the outcome of a readiness probe
+type Readiness struct {
+	Status       string       `json:"status"`
+	Dependencies []Dependency `json:"dependencies"`
+	CheckedAt    time.Time    `json:"checked_at"`
+}
+
+// Ready reports whether the server should receive traffic: every required
+// dependency is reachable
+func (r *Readiness) Ready() bool {
+	return r.Status != ReadinessNotReady
+}
+
+// Probe checks the server can reach the databases, Redis and the SMTP server,
+// for the readiness probe of an orchestrator. Unlike the diagnose command it
+// only checks connectivity, so it is cheap enough to run every few seconds.
+type Probe struct {
+	db     *sqlx.DB
+	redis  *redis.Client
+	config *config.Config
+}
+
+// NewProbe creates the readiness probe of the server using db as its primary
+// database
+func NewProbe(db *sqlx.DB, redisClient *redis.Client, cfg *config.Config) *Probe {
+	return &Probe{db: db, redis: redisClient, config: cfg}
+}
+
+// probeCheck is a connectivity check of the probe
+type probeCheck struct {
+	name     string
+	required bool
+	check    func(ctx context.Context) error
+}
+
+// Ready checks every dependency concurrently, each within HEALTH_CHECK_TIMEOUT
+func (p *Probe) Ready(ctx context.Context) *Readiness {
+	checks := []probeCheck{}
+	for _, db := range database.Databases(p.db) {
+		name := "postgres"
+		if db.Name != "primary" {
+			name += ":" + db.Name
+		}
+		conn := db.DB
+		checks = append(checks, probeCheck{name, true, func(ctx context.Context) error {
+			return database.HealthCheck(ctx, conn)
+		}})
+	}
+	checks = append(checks, probeCheck{"redis", true, func(ctx context.Context) error {
+		return p.redis.Ping(ctx).Err()
+	}})
+	// API providers are only reachable by sending
+	if p.config.Email.Provider == "smtp" {
+		checks = append(checks, probeCheck{"smtp", p.config.Health.RequireSMTP, p.pingSMTP})
+	}
+
+	readiness := &Readiness{
+		Status:       ReadinessReady,
+		Dependencies: make([]Dependency, len(checks)),
+		CheckedAt:    time.Now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c probeCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, p.config.Health.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(ctx)
+			dep := Dependency{
+				Name:      c.name,
+				Status:    StatusOK,
+				Required:  c.required,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				log.Printf("⚠️  Readiness check %s failed: %v", c.name, err)
+				dep.Status = StatusFail
+			}
+			readiness.Dependencies[i] = dep
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, dep := range readiness.Dependencies {
+		if dep.Status == StatusOK {
+			continue
+		}
+		if dep.Required {
+			readiness.Status = ReadinessNotReady
+			break
+		}
+		readiness.Status = ReadinessDegraded
+	}
+	return readiness
+}
+
+// pingSMTP connects to the SMTP server and greets it, without authenticating
+// or sending anything
+func (p *Probe) pingSMTP(ctx context.Context) error {
+	cfg := &p.config.Email
+	addr := net.JoinHostPort(cfg.SMTPHost, fmt.Sprint(cfg.SMTPPort))
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("no SMTP greeting from %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return fmt.Errorf("EHLO rejected: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,730 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// AccountingHandler handles chart of accounts, posting period, journal entry
+// and trial balance endpoints
+type AccountingHandler struct {
+	accountingService services.AccountingManager
+}
+
+// NewAccountingHandler creates a new accounting handler
+func NewAccountingHandler(accountingService services.AccountingManager) *AccountingHandler {
+	return &AccountingHandler{
+		accountingService: accountingService,
+	}
+}
+
+// ListAccounts lists the chart of accounts
+// GET /api/accounting/accounts?account_type=asset&active=true&search=cash
+func (h *AccountingHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	accounts, err := h.accountingService.ListAccounts(
+		r.Context(),
+		tenantID,
+		r.URL.Query().Get("account_type"),
+		r.URL.Query().Get("active") == "true",
+		r.URL.Query().Get("search"),
+	)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list accounts")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"accounts": accounts,
+	})
+}
+
+// GetAccount retrieves an account
+// GET /api/accounting/accounts/{id}
+func (h *AccountingHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid account ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	account, err := h.accountingService.GetAccount(r.Context(), tenantID, accountID)
+	if err != nil {
+		utils.NotFound(w, "Account not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"account": account,
+	})
+}
+
+// CreateAccount adds an account to the chart of accounts
+// POST /api/accounting/accounts
+func (h *AccountingHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var req models.AccountCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	req.Code = strings.TrimSpace(req.Code)
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("code", req.Code, "Code", &errors)
+	utils.ValidateStringLength("code", req.Code, 1, 20, "Code", &errors)
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 255, "Name", &errors)
+	utils.ValidateEnum("account_type", req.AccountType, models.AccountTypes, "Account type", &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	account, err := h.accountingService.CreateAccount(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"account": account,
+		"message": "Account created successfully",
+	})
+}
+
+// UpdateAccount updates an account
+// PUT /api/accounting/accounts/{id}
+func (h *AccountingHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid account ID")
+		return
+	}
+
+	var req models.AccountUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Code != nil {
+		code := strings.TrimSpace(*req.Code)
+		req.Code = &code
+		utils.ValidateStringLength("code", code, 1, 20, "Code", &errors)
+	}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	account, err := h.accountingService.UpdateAccount(r.Context(), tenantID, userID, accountID, &req)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"account": account,
+		"message": "Account updated successfully",
+	})
+}
+
+// DeleteAccount deletes an unused account
+// DELETE /api/accounting/accounts/{id}
+func (h *AccountingHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid account ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.accountingService.DeleteAccount(r.Context(), tenantID, userID, accountID); err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Account deleted successfully",
+	})
+}
+
+// ListPeriods lists posting periods
+// GET /api/accounting/periods?status=open
+func (h *AccountingHandler) ListPeriods(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	periods, err := h.accountingService.ListPeriods(r.Context(), tenantID, r.URL.Query().Get("status"))
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list accounting periods")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"periods": periods,
+	})
+}
+
+// CreatePeriod creates a posting period
+// POST /api/accounting/periods
+func (h *AccountingHandler) CreatePeriod(w http.ResponseWriter, r *http.Request) {
+	var req models.AccountingPeriodCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	if req.StartDate.IsZero() {
+		errors.Add("start_date", "Start date is required")
+	}
+	if req.EndDate.IsZero() {
+		errors.Add("end_date", "End date is required")
+	}
+	if !req.StartDate.IsZero() && !req.EndDate.IsZero() && req.EndDate.Before(req.StartDate) {
+		errors.Add("end_date", "End date must be on or after start date")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	period, err := h.accountingService.CreatePeriod(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		if err.Error() == "period overlaps an existing period" {
+			utils.Conflict(w, err.Error())
+			return
+		}
+		utils.InternalServerError(w, "Failed to create accounting period")
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"period":  period,
+		"message": "Accounting period created successfully",
+	})
+}
+
+// ClosePeriod closes a posting period
+// POST /api/accounting/periods/{id}/close
+func (h *AccountingHandler) ClosePeriod(w http.ResponseWriter, r *http.Request) {
+	h.changePeriodStatus(w, r, h.accountingService.ClosePeriod, "Accounting period closed")
+}
+
+// ReopenPeriod reopens a closed posting period
+// POST /api/accounting/periods/{id}/reopen
+func (h *AccountingHandler) ReopenPeriod(w http.ResponseWriter, r *http.Request) {
+	h.changePeriodStatus(w, r, h.accountingService.ReopenPeriod, "Accounting period reopened")
+}
+
+// ListEntries lists journal entries
+// GET /api/accounting/journal-entries?page=1&page_size=20&status=posted&account_id=...&from=2026-01-01&to=2026-01-31&search=rent
+func (h *AccountingHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	filter := models.JournalEntryFilter{
+		Status: r.URL.Query().Get("status"),
+		Search: r.URL.Query().Get("search"),
+	}
+
+	if value := r.URL.Query().Get("account_id"); value != "" {
+		accountID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid account ID")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if filter.FromDate, err = parseAccountingDate(r.URL.Query().Get("from")); err != nil {
+		utils.BadRequest(w, "Invalid from date, expected YYYY-MM-DD")
+		return
+	}
+	if filter.ToDate, err = parseAccountingDate(r.URL.Query().Get("to")); err != nil {
+		utils.BadRequest(w, "Invalid to date, expected YYYY-MM-DD")
+		return
+	}
+
+	entries, totalCount, err := h.accountingService.ListEntries(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list journal entries")
+		return
+	}
+
+	utils.SuccessWithMeta(w, entries, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetEntry retrieves a journal entry with its lines
+// GET /api/accounting/journal-entries/{id}
+func (h *AccountingHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid journal entry ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.accountingService.GetEntry(r.Context(), tenantID, entryID)
+	if err != nil {
+		utils.NotFound(w, "Journal entry not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"journal_entry": entry,
+	})
+}
+
+// CreateEntry creates a draft journal entry
+// POST /api/accounting/journal-entries
+func (h *AccountingHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req models.JournalEntryCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.EntryDate.IsZero() {
+		errors.Add("entry_date", "Entry date is required")
+	}
+	utils.ValidateRequired("description", req.Description, "Description", &errors)
+	validateJournalLines(req.Lines, &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.accountingService.CreateEntry(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"journal_entry": entry,
+		"message":       "Journal entry created successfully",
+	})
+}
+
+// UpdateEntry updates a draft journal entry
+// PUT /api/accounting/journal-entries/{id}
+func (h *AccountingHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid journal entry ID")
+		return
+	}
+
+	var req models.JournalEntryUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Description != nil {
+		utils.ValidateRequired("description", *req.Description, "Description", &errors)
+	}
+	if req.Lines != nil {
+		validateJournalLines(*req.Lines, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.accountingService.UpdateEntry(r.Context(), tenantID, userID, entryID, &req)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"journal_entry": entry,
+		"message":       "Journal entry updated successfully",
+	})
+}
+
+// DeleteEntry deletes a draft journal entry
+// DELETE /api/accounting/journal-entries/{id}
+func (h *AccountingHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid journal entry ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.accountingService.DeleteEntry(r.Context(), tenantID, userID, entryID); err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Journal entry deleted successfully",
+	})
+}
+
+// PostEntry posts a balanced draft journal entry
+// POST /api/accounting/journal-entries/{id}/post
+func (h *AccountingHandler) PostEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid journal entry ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.accountingService.PostEntry(r.Context(), tenantID, userID, entryID)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"journal_entry": entry,
+		"message":       "Journal entry posted",
+	})
+}
+
+// ReverseEntry posts a reversing entry for a posted journal entry
+// POST /api/accounting/journal-entries/{id}/reverse
+func (h *AccountingHandler) ReverseEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid journal entry ID")
+		return
+	}
+
+	// Body is optional
+	var req models.JournalEntryReverseRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSONBody(r, &req); err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	reversal, err := h.accountingService.ReverseEntry(r.Context(), tenantID, userID, entryID, &req)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"journal_entry": reversal,
+		"message":       "Journal entry reversed",
+	})
+}
+
+// TrialBalance returns posted debit and credit totals per account
+// GET /api/accounting/trial-balance?period_id=... or ?as_of=2026-03-31
+func (h *AccountingHandler) TrialBalance(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var periodID *uuid.UUID
+	if value := r.URL.Query().Get("period_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid period ID")
+			return
+		}
+		periodID = &id
+	}
+
+	asOf, err := parseAccountingDate(r.URL.Query().Get("as_of"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid as_of date, expected YYYY-MM-DD")
+		return
+	}
+
+	balance, err := h.accountingService.TrialBalance(r.Context(), tenantID, periodID, asOf)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"trial_balance": balance,
+	})
+}
+
+// changePeriodStatus applies a posting period status transition
+func (h *AccountingHandler) changePeriodStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	transition func(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error),
+	message string,
+) {
+	periodID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid period ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	period, err := transition(r.Context(), tenantID, userID, periodID)
+	if err != nil {
+		respondAccountingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"period":  period,
+		"message": message,
+	})
+}
+
+// parseAccountingDate parses an optional YYYY-MM-DD query value
+func parseAccountingDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// validateJournalLines validates request line items. Each line must carry
+// either a debit or a credit; balance is checked when the entry is posted.
+func validateJournalLines(lines []models.JournalLineRequest, errors *utils.ValidationErrors) {
+	for i, line := range lines {
+		field := "lines." + strconv.Itoa(i)
+		if line.AccountID == uuid.Nil {
+			errors.Add(field+".account_id", "Account is required")
+		}
+		if line.Debit < 0 || line.Credit < 0 {
+			errors.Add(field+".amount", "Amounts cannot be negative")
+		} else if (line.Debit == 0) == (line.Credit == 0) {
+			errors.Add(field+".amount", "Line must have either a debit or a credit amount")
+		}
+	}
+}
+
+// respondAccountingError maps accounting service errors to HTTP responses
+func respondAccountingError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Accounting operation failed")
+}
+
+// RegisterRoutes registers accounting routes
+func (h *AccountingHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, navMiddleware *middleware.NavigationMiddleware) {
+	r.Route("/accounting", func(r chi.Router) {
+		// All accounting routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Chart of accounts
+		r.Route("/accounts", func(r chi.Router) {
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView)).Get("/", h.ListAccounts)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionCreate)).Post("/", h.CreateAccount)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView), navMiddleware.TrackView(models.NavigationEntityAccount)).Get("/{id}", h.GetAccount)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionEdit)).Put("/{id}", h.UpdateAccount)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionDelete)).Delete("/{id}", h.DeleteAccount)
+		})
+
+		// Posting periods
+		r.Route("/periods", func(r chi.Router) {
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView)).Get("/", h.ListPeriods)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionManagePeriods)).Post("/", h.CreatePeriod)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionManagePeriods)).Post("/{id}/close", h.ClosePeriod)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionManagePeriods)).Post("/{id}/reopen", h.ReopenPeriod)
+		})
+
+		// Journal entries
+		r.Route("/journal-entries", func(r chi.Router) {
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView)).Get("/", h.ListEntries)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionCreate)).Post("/", h.CreateEntry)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView), navMiddleware.TrackView(models.NavigationEntityJournalEntry)).Get("/{id}", h.GetEntry)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionEdit)).Put("/{id}", h.UpdateEntry)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionDelete)).Delete("/{id}", h.DeleteEntry)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionPost)).Post("/{id}/post", h.PostEntry)
+			r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionPost)).Post("/{id}/reverse", h.ReverseEntry)
+		})
+
+		// Reports
+		r.With(permMiddleware.RequirePermission(models.ResourceAccounting, models.ActionView)).Get("/trial-balance", h.TrialBalance)
+	})
+}
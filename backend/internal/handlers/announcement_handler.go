@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// AnnouncementHandler handles announcement endpoints: the announcements
+// addressed to the current user, and their management by admins
+type AnnouncementHandler struct {
+	announcementService services.AnnouncementManager
+}
+
+// NewAnnouncementHandler creates a new announcement handler
+func NewAnnouncementHandler(announcementService services.AnnouncementManager) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		announcementService: announcementService,
+	}
+}
+
+// List lists the published announcements addressed to the current user,
+// newest first
+// GET /api/announcements?unread=true
+func (h *AnnouncementHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := announcementPage(r)
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	announcements, totalCount, err := h.announcementService.ListForUser(r.Context(), tenantID, userID, unreadOnly, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list announcements")
+		return
+	}
+
+	utils.SuccessWithMeta(w, announcements, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// MarkRead marks an announcement read by the current user
+// POST /api/announcements/{id}/read
+func (h *AnnouncementHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.announcementService.MarkRead(r.Context(), tenantID, userID, announcementID); err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Announcement marked as read",
+	})
+}
+
+// ListAnnouncements lists the tenant's announcements, including scheduled
+// and expired ones
+// GET /api/announcements/manage?status=scheduled
+func (h *AnnouncementHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.AnnouncementStatusScheduled,
+			models.AnnouncementStatusActive,
+			models.AnnouncementStatusExpired,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, pageSize, offset := announcementPage(r)
+
+	announcements, totalCount, err := h.announcementService.ListAnnouncements(r.Context(), tenantID, status, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list announcements")
+		return
+	}
+
+	utils.SuccessWithMeta(w, announcements, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// CreateAnnouncement posts or schedules an announcement
+// POST /api/announcements/manage
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var req models.AnnouncementRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateAnnouncementRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	announcement, err := h.announcementService.CreateAnnouncement(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), announcement.ID)
+	middleware.SetAuditAfter(r.Context(), announcement)
+
+	utils.Created(w, map[string]interface{}{
+		"announcement": announcement,
+		"message":      "Announcement created successfully",
+	})
+}
+
+// GetAnnouncement retrieves an announcement
+// GET /api/announcements/manage/{id}
+func (h *AnnouncementHandler) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	announcement, err := h.announcementService.GetAnnouncement(r.Context(), tenantID, announcementID)
+	if err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"announcement": announcement,
+	})
+}
+
+// UpdateAnnouncement replaces an announcement's content, audience and
+// schedule
+// PUT /api/announcements/manage/{id}
+func (h *AnnouncementHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID")
+		return
+	}
+
+	var req models.AnnouncementRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateAnnouncementRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.announcementService.GetAnnouncement(r.Context(), tenantID, announcementID)
+	if err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	announcement, err := h.announcementService.UpdateAnnouncement(r.Context(), tenantID, announcementID, &req)
+	if err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), announcement)
+
+	utils.Success(w, map[string]interface{}{
+		"announcement": announcement,
+		"message":      "Announcement updated successfully",
+	})
+}
+
+// DeleteAnnouncement deletes an announcement
+// DELETE /api/announcements/manage/{id}
+func (h *AnnouncementHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.announcementService.DeleteAnnouncement(r.Context(), tenantID, announcementID); err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Announcement deleted successfully",
+	})
+}
+
+// ListReads lists the users who read an announcement
+// GET /api/announcements/manage/{id}/reads
+func (h *AnnouncementHandler) ListReads(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := announcementPage(r)
+
+	reads, totalCount, err := h.announcementService.ListReads(r.Context(), tenantID, announcementID, pageSize, offset)
+	if err != nil {
+		respondAnnouncementError(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, reads, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// validateAnnouncementRequest validates an announcement's content and
+// audience
+func validateAnnouncementRequest(req *models.AnnouncementRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("title", req.Title, "Title", &errors)
+	utils.ValidateStringLength("title", req.Title, 1, 200, "Title", &errors)
+	utils.ValidateRequired("body", req.Body, "Body", &errors)
+	if req.Audience == "" {
+		req.Audience = models.AnnouncementAudienceAll
+	}
+	utils.ValidateEnum("audience", req.Audience, models.AnnouncementAudiences, "Audience", &errors)
+	return errors
+}
+
+// announcementPage reads the page and page_size query parameters
+func announcementPage(r *http.Request) (page, pageSize, offset int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// respondAnnouncementError maps announcement service errors to HTTP responses
+func respondAnnouncementError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Announcement operation failed")
+}
+
+// RegisterRoutes registers announcement routes. Every user sees the
+// announcements addressed to them; announcements.* permissions are for
+// managing them.
+func (h *AnnouncementHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/announcements", func(r chi.Router) {
+		// All announcement routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.List)
+		r.Post("/{id}/read", h.MarkRead)
+
+		r.Route("/manage", func(r chi.Router) {
+			r.With(permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionView)).Get("/", h.ListAnnouncements)
+			r.With(permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionView)).Get("/{id}", h.GetAnnouncement)
+			r.With(permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionView)).Get("/{id}/reads", h.ListReads)
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionCreate),
+				auditMiddleware.Record(models.ActionAnnouncementCreated, models.ResourceAnnouncements),
+			).Post("/", h.CreateAnnouncement)
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionEdit),
+				auditMiddleware.Record(models.ActionAnnouncementUpdated, models.ResourceAnnouncements),
+			).Put("/{id}", h.UpdateAnnouncement)
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceAnnouncements, models.ActionDelete),
+				auditMiddleware.Record(models.ActionAnnouncementDeleted, models.ResourceAnnouncements),
+			).Delete("/{id}", h.DeleteAnnouncement)
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// ApprovalLinkHandler handles the public endpoints behind emailed approval
+// links. The signed token authorizes the request instead of a login.
+type ApprovalLinkHandler struct {
+	approvalLinkService services.ApprovalLinkManager
+}
+
+// NewApprovalLinkHandler creates a new approval link handler
+func NewApprovalLinkHandler(approvalLinkService services.ApprovalLinkManager) *ApprovalLinkHandler {
+	return &ApprovalLinkHandler{
+		approvalLinkService: approvalLinkService,
+	}
+}
+
+// Preview shows what a link decides and whether a verification code is
+// needed, without using the link
+// POST /api/approval-links/preview
+func (h *ApprovalLinkHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseApprovalLinkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	preview, err := h.approvalLinkService.Preview(r.Context(), tenantID, req)
+	if err != nil {
+		respondApprovalLinkError(w, err)
+		return
+	}
+
+	utils.Success(w, preview)
+}
+
+// Decide uses a link to approve or reject in the approver's name
+// POST /api/approval-links/decide
+func (h *ApprovalLinkHandler) Decide(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseApprovalLinkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	subject, err := h.approvalLinkService.Decide(r.Context(), tenantID, req, utils.GetClientIP(r), r.UserAgent())
+	if err != nil {
+		respondApprovalLinkError(w, err)
+		return
+	}
+
+	message := "Approved"
+	if req.Decision == models.ApprovalDecisionReject {
+		message = "Rejected"
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"subject": subject,
+		"message": message,
+	})
+}
+
+// parseApprovalLinkRequest reads and validates a link request body
+func parseApprovalLinkRequest(w http.ResponseWriter, r *http.Request) (*models.ApprovalLinkRequest, bool) {
+	var req models.ApprovalLinkRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return nil, false
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("token", req.Token, "Token", &errors)
+	utils.ValidateEnum("decision", req.Decision, []string{
+		models.ApprovalDecisionApprove,
+		models.ApprovalDecisionReject,
+	}, "Decision", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// respondApprovalLinkError maps approval link errors to HTTP responses
+func respondApprovalLinkError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "invalid approval link":
+		utils.NotFound(w, "Invalid approval link")
+	case "approval link has expired or was already used", "approval was already decided":
+		utils.Conflict(w, err.Error())
+	case "sign in to decide on this approval", "verification code required", "approver is no longer allowed to decide":
+		utils.Forbidden(w, err.Error())
+	case "invalid verification code", "too many invalid verification codes, the link was revoked":
+		utils.Unauthorized(w, err.Error())
+	case "too many failed 2FA attempts, please try again in 15 minutes":
+		utils.TooManyRequests(w, err.Error())
+	case "invoice does not match the purchase order and receipts":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"decision": "This invoice can only be approved in the app"})
+	default:
+		utils.InternalServerError(w, "Approval link operation failed")
+	}
+}
+
+// RegisterRoutes registers approval link routes (public - the token authorizes
+// them)
+func (h *ApprovalLinkHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/approval-links", func(r chi.Router) {
+		r.Post("/preview", h.Preview)
+		r.Post("/decide", h.Decide)
+	})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+const jobEventsKeepAlive = 15 * time.Second // Comment line sent so proxies keep idle streams open
+
+// AsyncJobHandler handles long-running job endpoints (status, cancellation,
+// progress events). Jobs are started by the endpoints of the modules that
+// own the work.
+type AsyncJobHandler struct {
+	asyncJobService services.AsyncJobManager
+}
+
+// NewAsyncJobHandler creates a new async job handler
+func NewAsyncJobHandler(asyncJobService services.AsyncJobManager) *AsyncJobHandler {
+	return &AsyncJobHandler{
+		asyncJobService: asyncJobService,
+	}
+}
+
+// ListJobs lists the jobs the current user started
+// GET /api/jobs?job_type=&status=running
+func (h *AsyncJobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.AsyncJobStatusQueued,
+			models.AsyncJobStatusRunning,
+			models.AsyncJobStatusSucceeded,
+			models.AsyncJobStatusFailed,
+			models.AsyncJobStatusCancelled,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	jobs, totalCount, err := h.asyncJobService.ListJobs(r.Context(), tenantID, userID, r.URL.Query().Get("job_type"), status, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list jobs")
+		return
+	}
+
+	utils.SuccessWithMeta(w, jobs, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetJob retrieves a job's status, progress and result
+// GET /api/jobs/{id}
+func (h *AsyncJobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.asyncJobService.GetJob(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"job": job,
+	})
+}
+
+// CancelJob cancels a queued or running job
+// POST /api/jobs/{id}/cancel
+func (h *AsyncJobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.asyncJobService.Cancel(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	message := "Job cancelled"
+	if job.Status == models.AsyncJobStatusRunning {
+		message = "Cancellation requested, the job will stop shortly"
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"job":     job,
+		"message": message,
+	})
+}
+
+// StreamEvents streams a job's progress as Server-Sent Events. Every event is
+// a "job" event carrying the job as JSON; the stream ends once the job has
+// finished.
+// GET /api/jobs/{id}/events
+func (h *AsyncJobHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.InternalServerError(w, "Streaming is not supported")
+		return
+	}
+
+	// Subscribe before reading the job, so no update in between is missed
+	sub := h.asyncJobService.Subscribe(r.Context(), tenantID, jobID)
+	defer sub.Close()
+
+	job, err := h.asyncJobService.GetJob(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	// Hold no database connection while streaming
+	database.ReleaseSession(r.Context())
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return
+	}
+	writeJobEvent(w, payload)
+	flusher.Flush()
+	if job.IsFinished() {
+		return
+	}
+
+	keepAlive := time.NewTicker(jobEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			writeJobEvent(w, []byte(msg.Payload))
+			flusher.Flush()
+
+			var update models.AsyncJob
+			if err := json.Unmarshal([]byte(msg.Payload), &update); err == nil && update.IsFinished() {
+				return
+			}
+		}
+	}
+}
+
+// writeJobEvent writes one Server-Sent Event carrying a job
+func writeJobEvent(w http.ResponseWriter, payload []byte) {
+	fmt.Fprintf(w, "event: job\ndata: %s\n\n", payload)
+}
+
+// respondAsyncJobError maps async job service errors to HTTP responses
+func respondAsyncJobError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Job operation failed")
+}
+
+// RegisterRoutes registers async job routes. Access is checked per job: the
+// requester, or users with the view permission on the job type's resource.
+func (h *AsyncJobHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/jobs", func(r chi.Router) {
+		// All job routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.ListJobs)
+		r.Get("/{id}", h.GetJob)
+		r.Get("/{id}/events", h.StreamEvents)
+		r.Post("/{id}/cancel", h.CancelJob)
+	})
+}
@@ -9,24 +9,26 @@ import (
 	"github.com/google/uuid"
 	"myerp-v2/internal/middleware"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
 	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
 )
 
 // AuditHandler handles audit log endpoints
 type AuditHandler struct {
-	auditService *services.AuditService
+	auditService services.AuditManager
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+func NewAuditHandler(auditService services.AuditManager) *AuditHandler {
 	return &AuditHandler{
 		auditService: auditService,
 	}
 }
 
 // ListAuditLogs retrieves audit logs with filtering
-// GET /api/audit-logs?user_id=xxx&action=login&status=success&start_date=...&end_date=...&page=1&page_size=20
+// GET /api/audit-logs?user_id=xxx&action=login&status=success&start_date=...&end_date=...&page=1&page_size=20&sort=-created_at
+// GET /api/audit-logs?cursor=...
 func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
 	if err != nil {
@@ -34,74 +36,72 @@ func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters
-	filters := services.AuditFilters{}
+	filters := parseAuditFilters(r)
 
-	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
-		userID, err := uuid.Parse(userIDStr)
-		if err == nil {
-			filters.UserID = &userID
-		}
-	}
-
-	if action := r.URL.Query().Get("action"); action != "" {
-		filters.Action = action
+	page, err := pagination.Parse(r, services.AuditListSpec)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to query audit logs")
+		return
 	}
 
-	if resourceType := r.URL.Query().Get("resource_type"); resourceType != "" {
-		filters.ResourceType = resourceType
+	// Query audit logs
+	logs, totalCount, nextCursor, err := h.auditService.Query(r.Context(), tenantID, filters, page)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to query audit logs")
+		return
 	}
 
-	if resourceIDStr := r.URL.Query().Get("resource_id"); resourceIDStr != "" {
-		resourceID, err := uuid.Parse(resourceIDStr)
-		if err == nil {
-			filters.ResourceID = &resourceID
-		}
-	}
+	utils.SuccessWithMeta(w, map[string]interface{}{
+		"logs": logs,
+	}, page.Meta(totalCount, nextCursor))
+}
 
-	if status := r.URL.Query().Get("status"); status != "" {
-		filters.Status = status
+// Export downloads the audit logs matching the list filters as CSV, XLSX or
+// JSON, streamed as they are read
+// GET /api/audit-logs/export?format=csv&user_id=xxx&action=login&start_date=...&end_date=...
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
 	}
 
-	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
-		startDate, err := time.Parse(time.RFC3339, startDateStr)
-		if err == nil {
-			filters.StartDate = &startDate
-		}
+	format, ok := parseExportFormat(w, r)
+	if !ok {
+		return
 	}
 
-	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
-		endDate, err := time.Parse(time.RFC3339, endDateStr)
-		if err == nil {
-			filters.EndDate = &endDate
-		}
-	}
+	filters := parseAuditFilters(r)
 
-	// Pagination
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	export, err := utils.NewExportWriter(w, format, "audit-logs", models.AuditLogExportColumns)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to export audit logs")
+		return
 	}
 
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	err = h.auditService.Export(r.Context(), tenantID, filters, func(log *models.AuditLog) error {
+		return export.Write(log, []string{
+			utils.ExportValue(log.ID),
+			utils.ExportValue(log.CreatedAt),
+			utils.ExportValue(log.UserID),
+			log.Action,
+			utils.ExportValue(log.ResourceType),
+			utils.ExportValue(log.ResourceID),
+			log.Status,
+			utils.ExportValue(log.IPAddress),
+			utils.ExportValue(log.UserAgent),
+			utils.ExportValue(log.Metadata),
+		})
+	})
+	if err == nil {
+		err = export.Close()
 	}
-
-	offset := (page - 1) * pageSize
-
-	// Query audit logs
-	logs, totalCount, err := h.auditService.Query(r.Context(), tenantID, filters, pageSize, offset)
 	if err != nil {
-		utils.InternalServerError(w, "Failed to query audit logs")
-		return
+		export.Abort(err)
 	}
 
-	meta := utils.NewMeta(page, pageSize, totalCount)
-
-	utils.SuccessWithMeta(w, map[string]interface{}{
-		"logs": logs,
-	}, meta)
+	middleware.SetAuditMetadata(r.Context(), "format", format)
+	middleware.SetAuditMetadata(r.Context(), "records", export.Records())
 }
 
 // GetUserActivity retrieves recent activity for a specific user
@@ -302,8 +302,39 @@ func (h *AuditHandler) Search(w http.ResponseWriter, r *http.Request) {
 	}, meta)
 }
 
+// GetDiff renders the field-level changes recorded by an audit log entry
+// GET /api/audit-logs/{id}/diff
+func (h *AuditHandler) GetDiff(w http.ResponseWriter, r *http.Request) {
+	logID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid audit log ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	diff, err := h.auditService.Diff(r.Context(), tenantID, logID)
+	if err != nil {
+		switch err.Error() {
+		case "audit log not found":
+			utils.NotFound(w, "Audit log not found")
+		case "audit log has no snapshots":
+			utils.BadRequest(w, "This audit log entry did not record any changes")
+		default:
+			utils.InternalServerError(w, "Failed to get audit log diff")
+		}
+		return
+	}
+
+	utils.Success(w, diff)
+}
+
 // RegisterRoutes registers all audit log routes
-func (h *AuditHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+func (h *AuditHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
 	r.Route("/audit-logs", func(r chi.Router) {
 		// All audit log routes require authentication
 		r.Use(authMiddleware.Authenticate)
@@ -316,6 +347,12 @@ func (h *AuditHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.A
 		// List audit logs with filters
 		r.Get("/", h.ListAuditLogs)
 
+		// Export audit logs as CSV/XLSX/JSON - also requires security.export
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport),
+			auditMiddleware.Record(models.ActionAuditLogsExported, models.ResourceSecurity),
+		).Get("/export", h.Export)
+
 		// Search audit logs
 		r.Get("/search", h.Search)
 
@@ -330,5 +367,56 @@ func (h *AuditHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.A
 
 		// Resource activity
 		r.Get("/resource/{resource_type}/{resource_id}", h.GetResourceActivity)
+
+		// Field-level changes of a single entry
+		r.Get("/{id}/diff", h.GetDiff)
 	})
 }
+
+// parseAuditFilters reads the audit log filters shared by the list and the
+// export from the query string. Malformed IDs and dates are ignored.
+func parseAuditFilters(r *http.Request) services.AuditFilters {
+	filters := services.AuditFilters{}
+
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err == nil {
+			filters.UserID = &userID
+		}
+	}
+
+	if action := r.URL.Query().Get("action"); action != "" {
+		filters.Action = action
+	}
+
+	if resourceType := r.URL.Query().Get("resource_type"); resourceType != "" {
+		filters.ResourceType = resourceType
+	}
+
+	if resourceIDStr := r.URL.Query().Get("resource_id"); resourceIDStr != "" {
+		resourceID, err := uuid.Parse(resourceIDStr)
+		if err == nil {
+			filters.ResourceID = &resourceID
+		}
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		filters.Status = status
+	}
+
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		startDate, err := time.Parse(time.RFC3339, startDateStr)
+		if err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		endDate, err := time.Parse(time.RFC3339, endDateStr)
+		if err == nil {
+			filters.EndDate = &endDate
+		}
+	}
+
+	return filters
+}
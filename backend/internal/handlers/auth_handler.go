@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -11,15 +14,21 @@ import (
 	"myerp-v2/internal/utils"
 )
 
+// samlMaxFormSize bounds the forms SAML identity providers post to the
+// assertion consumer service
+const samlMaxFormSize = 512 << 10
+
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	authService *services.AuthService
+	authService       services.AuthManager
+	permissionService services.PermissionManager
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthManager, permissionService services.PermissionManager) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:       authService,
+		permissionService: permissionService,
 	}
 }
 
@@ -50,7 +59,7 @@ func (h *AuthHandler) RegisterTenant(w http.ResponseWriter, r *http.Request) {
 	// Register tenant
 	tenant, err := h.authService.RegisterTenant(r.Context(), &req)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Registration failed")
 		return
 	}
 
@@ -82,7 +91,7 @@ func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	// Verify email
 	tenant, err := h.authService.VerifyTenantEmail(r.Context(), token)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to verify email")
 		return
 	}
 
@@ -119,7 +128,9 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Login (email-based, supports multi-tenant selection)
 	response, err := h.authService.Login(r.Context(), &req, deviceInfo, ipAddress)
 	if err != nil {
-		utils.Unauthorized(w, err.Error())
+		// On SSO_REQUIRED the frontend sends the user to the tenant's
+		// providers, on PASSKEY_REQUIRED it starts a passkey sign-in
+		respondSignInError(w, err)
 		return
 	}
 
@@ -155,7 +166,247 @@ func (h *AuthHandler) Verify2FA(w http.ResponseWriter, r *http.Request) {
 		r.Context(), req.TwoFactorToken, req.Code, deviceInfo, ipAddress, req.RememberMe,
 	)
 	if err != nil {
-		utils.Unauthorized(w, err.Error())
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, response)
+}
+
+// BeginTwoFactorSetup generates the TOTP secret and backup codes of a user
+// whose sign-in the tenant's 2FA policy blocked
+// POST /api/auth/2fa-setup/begin
+func (h *AuthHandler) BeginTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorSetupBeginRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.TwoFactorToken == "" {
+		utils.BadRequest(w, "Two-factor token is required")
+		return
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(r.Context(), req.TwoFactorToken)
+	if err != nil {
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"secret":        setup.Secret,
+		"qr_code_url":   setup.QRCodeURL,
+		"qr_code_image": setup.QRCodeImage,
+		"backup_codes":  setup.BackupCodes,
+		"message":       "Scan the QR code with your authenticator app and verify with a code to enable 2FA",
+	})
+}
+
+// FinishTwoFactorSetup enables 2FA for a user whose sign-in the tenant's 2FA
+// policy blocked and completes the sign-in
+// POST /api/auth/2fa-setup/finish
+func (h *AuthHandler) FinishTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorSetupFinishRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("two_factor_token", req.TwoFactorToken, "Two-factor token", &errors)
+	utils.ValidateRequired("secret", req.Secret, "Secret", &errors)
+	utils.ValidateRequired("verification_code", req.VerificationCode, "Verification code", &errors)
+	if len(req.BackupCodes) == 0 {
+		errors.Add("backup_codes", "Backup codes are required")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	response, err := h.authService.FinishTwoFactorSetup(r.Context(), &req, utils.ParseDeviceInfo(r), utils.GetClientIP(r))
+	if err != nil {
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, response)
+}
+
+// respondSignInError writes the response for a failed sign-in step. Domain
+// errors get the status of their kind: 401 for wrong credentials, codes and
+// tokens, 403 for inactive accounts, 429 when rate limited.
+func respondSignInError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Sign-in failed")
+}
+
+// RequestTwoFABypass emails a user who lost their authenticator a one-time
+// code to complete their sign-in with
+// POST /api/auth/2fa-bypass/request
+func (h *AuthHandler) RequestTwoFABypass(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TwoFactorToken string `json:"two_factor_token"`
+	}
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.TwoFactorToken == "" {
+		utils.BadRequest(w, "Two-factor token is required")
+		return
+	}
+
+	expiresAt, err := h.authService.RequestTwoFABypassCode(r.Context(), req.TwoFactorToken, utils.ParseDeviceInfo(r), utils.GetClientIP(r))
+	if err != nil {
+		utils.WriteError(w, err, "Failed to send bypass code")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message":    "A bypass code has been sent to your email address",
+		"expires_at": expiresAt,
+	})
+}
+
+// VerifyTwoFABypass completes a sign-in with an emailed bypass code instead
+// of the second factor
+// POST /api/auth/2fa-bypass/verify
+func (h *AuthHandler) VerifyTwoFABypass(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TwoFactorToken string `json:"two_factor_token"`
+		Code           string `json:"code"`
+		RememberMe     bool   `json:"remember_me"`
+	}
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.TwoFactorToken == "" || req.Code == "" {
+		utils.BadRequest(w, "Two-factor token and code are required")
+		return
+	}
+
+	response, err := h.authService.VerifyTwoFABypassCode(
+		r.Context(), req.TwoFactorToken, req.Code, req.RememberMe, utils.ParseDeviceInfo(r), utils.GetClientIP(r),
+	)
+	if err != nil {
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, response)
+}
+
+// BeginWebAuthnLogin starts a passkey sign-in and returns the options to
+// pass to navigator.credentials.get()
+// POST /api/auth/webauthn/login/begin
+func (h *AuthHandler) BeginWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthnLoginBeginRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.Email != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEmail("email", req.Email, &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	// Extract tenant ID from context
+	tenantID, ok := r.Context().Value("tenant_id").(uuid.UUID)
+	if !ok {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	options, err := h.authService.BeginWebAuthnLogin(r.Context(), tenantID, req.Email)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to start passkey sign-in")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"options": options,
+	})
+}
+
+// FinishWebAuthnLogin completes a passkey sign-in. Passkeys verify the user,
+// so no second factor is asked.
+// POST /api/auth/webauthn/login/finish
+func (h *AuthHandler) FinishWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthnLoginFinishRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Extract tenant ID from context
+	tenantID, ok := r.Context().Value("tenant_id").(uuid.UUID)
+	if !ok {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	response, err := h.authService.FinishWebAuthnLogin(r.Context(), tenantID, &req, utils.ParseDeviceInfo(r), utils.GetClientIP(r))
+	if err != nil {
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, response)
+}
+
+// BeginWebAuthn2FA starts completing a password sign-in with a security key
+// and returns the options to pass to navigator.credentials.get()
+// POST /api/auth/webauthn/2fa/begin
+func (h *AuthHandler) BeginWebAuthn2FA(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthn2FABeginRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.TwoFactorToken == "" {
+		utils.BadRequest(w, "Two-factor token is required")
+		return
+	}
+
+	options, err := h.authService.BeginWebAuthn2FA(r.Context(), req.TwoFactorToken)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to start security key verification")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"options": options,
+	})
+}
+
+// FinishWebAuthn2FA completes a password sign-in with a security key
+// POST /api/auth/webauthn/2fa/finish
+func (h *AuthHandler) FinishWebAuthn2FA(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthn2FAFinishRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.TwoFactorToken == "" {
+		utils.BadRequest(w, "Two-factor token is required")
+		return
+	}
+
+	response, err := h.authService.FinishWebAuthn2FA(r.Context(), &req, utils.ParseDeviceInfo(r), utils.GetClientIP(r))
+	if err != nil {
+		respondSignInError(w, err)
 		return
 	}
 
@@ -172,18 +423,20 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
 	accessToken, ok := r.Context().Value("access_token").(string)
 	if !ok {
 		utils.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	// Hash token
-	// TODO: Extract this to a utility function
-	// For now, the auth service will handle it
-
 	// Logout
-	if err := h.authService.Logout(r.Context(), tenantID, accessToken); err != nil {
+	if err := h.authService.Logout(r.Context(), tenantID, userID, accessToken); err != nil {
 		utils.InternalServerError(w, "Failed to logout")
 		return
 	}
@@ -240,7 +493,7 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	// Refresh token
 	response, err := h.authService.RefreshToken(r.Context(), req.RefreshToken)
 	if err != nil {
-		utils.Unauthorized(w, err.Error())
+		respondSignInError(w, err)
 		return
 	}
 
@@ -321,7 +574,7 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	// Reset password
 	if err := h.authService.ResetPassword(r.Context(), tenantID, token, req.NewPassword); err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to reset password")
 		return
 	}
 
@@ -330,6 +583,41 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ConfirmEmailChange confirms a user's new email with the token emailed to
+// it. The user's sessions are revoked, so they sign in again with it.
+// POST /api/auth/confirm-email
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req models.UserEmailChangeConfirmRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	token, err := uuid.Parse(req.Token)
+	if err != nil {
+		utils.BadRequest(w, "Invalid email change token")
+		return
+	}
+
+	// Extract tenant ID from context
+	tenantID, ok := r.Context().Value("tenant_id").(uuid.UUID)
+	if !ok {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	user, err := h.authService.ConfirmEmailChange(r.Context(), tenantID, token, utils.GetClientIP(r), r.UserAgent())
+	if err != nil {
+		utils.WriteError(w, err, "Failed to change email")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"email":   user.Email,
+		"message": "Email changed successfully. Please sign in with your new email.",
+	})
+}
+
 // ChangePassword handles password change (requires authentication)
 // POST /api/auth/change-password
 func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
@@ -364,7 +652,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	// Change password
 	if err := h.authService.ChangePassword(r.Context(), tenantID, userID, req.CurrentPassword, req.NewPassword); err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to change password")
 		return
 	}
 
@@ -373,7 +661,8 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetCurrentUser returns the currently authenticated user
+// GetCurrentUser returns the currently authenticated user with their tenant,
+// roles, effective permissions and 2FA status
 // GET /api/auth/me
 func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Get user from context
@@ -383,11 +672,261 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tenant, err := middleware.GetTenantFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	roles, err := h.permissionService.GetUserRoles(r.Context(), user.TenantID, user.ID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load roles")
+		return
+	}
+
+	permissions, err := h.permissionService.GetUserPermissions(r.Context(), user.TenantID, user.ID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load permissions")
+		return
+	}
+
+	// The version lets the frontend tell whether permission checks it has
+	// cached are still current without refetching the whole set
+	version, err := h.permissionService.GetPermissionsVersion(r.Context(), user.TenantID, user.ID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load permissions")
+		return
+	}
+
+	twoFactor, err := h.authService.GetTwoFactorStatus(r.Context(), tenant, user)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load 2FA status")
+		return
+	}
+
+	// Everything the SPA needs to bootstrap, in one request
+	utils.Success(w, map[string]interface{}{
+		"user":                user,
+		"tenant":              tenant,
+		"plan_tier":           tenant.PlanTier,
+		"roles":               roles,
+		"permissions":         permissions,
+		"permissions_version": version,
+		"two_factor":          twoFactor,
+	})
+}
+
+// ListSSOProviders lists the providers shown on a tenant's login page
+// GET /api/auth/sso/providers?tenant={slug}
+func (h *AuthHandler) ListSSOProviders(w http.ResponseWriter, r *http.Request) {
+	tenantSlug := ssoTenantSlug(r)
+	if tenantSlug == "" {
+		utils.BadRequest(w, "Tenant is required")
+		return
+	}
+
+	providers, ssoRequired, err := h.authService.ListSSOLoginProviders(r.Context(), tenantSlug)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list sso providers")
+		return
+	}
+
 	utils.Success(w, map[string]interface{}{
-		"user": user,
+		"providers":    providers,
+		"sso_required": ssoRequired,
 	})
 }
 
+// StartSSO sends the browser to the provider to sign in
+// GET /api/auth/sso/{provider}/start?tenant={slug}&remember_me=true&redirect=/path
+func (h *AuthHandler) StartSSO(w http.ResponseWriter, r *http.Request) {
+	tenantSlug := ssoTenantSlug(r)
+	if tenantSlug == "" {
+		h.redirectSSOError(w, r, "tenant_required")
+		return
+	}
+
+	query := r.URL.Query()
+	authURL, err := h.authService.StartSSO(
+		r.Context(), tenantSlug, chi.URLParam(r, "provider"), query.Get("remember_me") == "true", query.Get("redirect"),
+	)
+	if err != nil {
+		log.Printf("⚠️  SSO sign-on for tenant %s failed to start: %v", tenantSlug, err)
+		h.redirectSSOError(w, r, ssoErrorCode(err))
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// SSOCallback finishes a sign-on when the provider sends the browser back,
+// and forwards it to the frontend with a code to exchange for a session
+// GET /api/auth/sso/{provider}/callback?code=...&state=...
+func (h *AuthHandler) SSOCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		// e.g. the user declined consent
+		if providerError != "access_denied" {
+			providerError = "provider_error"
+		}
+		h.redirectSSOError(w, r, providerError)
+		return
+	}
+	if query.Get("code") == "" || query.Get("state") == "" {
+		h.redirectSSOError(w, r, "invalid_request")
+		return
+	}
+
+	code, redirect, err := h.authService.CompleteSSO(r.Context(), chi.URLParam(r, "provider"), query.Get("state"), query.Get("code"))
+	if err != nil {
+		log.Printf("⚠️  SSO sign-on with %s failed: %v", chi.URLParam(r, "provider"), err)
+		h.redirectSSOError(w, r, ssoErrorCode(err))
+		return
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if redirect != "" {
+		params.Set("redirect", redirect)
+	}
+	http.Redirect(w, r, h.authService.SSOFrontendURL(params), http.StatusFound)
+}
+
+// SAMLACS is the assertion consumer service SAML identity providers post
+// their responses to. It finishes the sign-on and forwards the browser to the
+// frontend like SSOCallback.
+// POST /api/auth/sso/{provider}/acs
+func (h *AuthHandler) SAMLACS(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, samlMaxFormSize)
+	if err := r.ParseForm(); err != nil {
+		h.redirectSSOError(w, r, "invalid_request")
+		return
+	}
+
+	samlResponse, relayState := r.PostForm.Get("SAMLResponse"), r.PostForm.Get("RelayState")
+	if samlResponse == "" || relayState == "" {
+		// Sign-ons started at the identity provider have no RelayState
+		h.redirectSSOError(w, r, "invalid_request")
+		return
+	}
+
+	code, redirect, err := h.authService.CompleteSAML(r.Context(), chi.URLParam(r, "provider"), relayState, samlResponse)
+	if err != nil {
+		log.Printf("⚠️  SAML sign-on with %s failed: %v", chi.URLParam(r, "provider"), err)
+		h.redirectSSOError(w, r, ssoErrorCode(err))
+		return
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if redirect != "" {
+		params.Set("redirect", redirect)
+	}
+	http.Redirect(w, r, h.authService.SSOFrontendURL(params), http.StatusSeeOther)
+}
+
+// SAMLMetadata serves the service provider metadata of a SAML provider, for
+// tenants to register at their identity provider. Its URL is the entity ID.
+// GET /api/auth/sso/{provider}/metadata?tenant={slug}
+func (h *AuthHandler) SAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	tenantSlug := ssoTenantSlug(r)
+	if tenantSlug == "" {
+		utils.BadRequest(w, "Tenant is required")
+		return
+	}
+
+	metadata, err := h.authService.SAMLMetadata(r.Context(), tenantSlug, chi.URLParam(r, "provider"))
+	if err != nil {
+		utils.WriteError(w, err, "Failed to get saml metadata")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(metadata)
+}
+
+// ExchangeSSOCode exchanges the code of a finished sign-on for a session
+// POST /api/auth/sso/exchange
+func (h *AuthHandler) ExchangeSSOCode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if req.Code == "" {
+		utils.BadRequest(w, "Code is required")
+		return
+	}
+
+	// Get device info
+	deviceInfo := utils.ParseDeviceInfo(r)
+	ipAddress := utils.GetClientIP(r)
+
+	response, err := h.authService.ExchangeSSOCode(r.Context(), req.Code, deviceInfo, ipAddress)
+	if err != nil {
+		respondSignInError(w, err)
+		return
+	}
+
+	utils.Success(w, response)
+}
+
+// redirectSSOError sends the browser to the frontend with why the sign-on
+// failed
+func (h *AuthHandler) redirectSSOError(w http.ResponseWriter, r *http.Request, code string) {
+	params := url.Values{}
+	params.Set("error", code)
+	http.Redirect(w, r, h.authService.SSOFrontendURL(params), http.StatusFound)
+}
+
+// ssoTenantSlug returns the tenant a sign-on is for: the tenant query
+// parameter, as browsers navigate to the SSO routes without the tenant header,
+// or the resolved tenant
+func ssoTenantSlug(r *http.Request) string {
+	if slug := r.URL.Query().Get("tenant"); slug != "" {
+		return slug
+	}
+	slug, _ := r.Context().Value("tenant_slug").(string)
+	return slug
+}
+
+// ssoErrorCode is the error code the frontend is given for a failed sign-on
+func ssoErrorCode(err error) string {
+	var domainErr *utils.DomainError
+	if !errors.As(err, &domainErr) {
+		return "sso_failed"
+	}
+	switch domainErr.Code {
+	case "TENANT_NOT_FOUND", "TENANT_INACTIVE":
+		return "tenant_unavailable"
+	case "SSO_PROVIDER_NOT_FOUND":
+		return "provider_not_found"
+	case "SSO_EXPIRED":
+		return "expired"
+	case "SSO_NO_ACCOUNT":
+		return "no_account"
+	case "SSO_EMAIL_DOMAIN_NOT_ALLOWED":
+		return "domain_not_allowed"
+	case "SSO_NO_EMAIL", "SSO_EMAIL_NOT_VERIFIED":
+		return "email_not_verified"
+	case "USER_INACTIVE":
+		return "account_inactive"
+	case "SAML_NOT_AVAILABLE":
+		return "plan_required"
+	case "SAML_REJECTED":
+		return "provider_error"
+	case "PLAN_USER_LIMIT_REACHED":
+		return "user_limit_reached"
+	default:
+		return "sso_failed"
+	}
+}
+
 // RegisterRoutes registers all auth routes
 func (h *AuthHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, tenantMiddleware *middleware.TenantMiddleware) {
 	// Public routes
@@ -399,12 +938,41 @@ func (h *AuthHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.Au
 		r.Post("/login", h.Login)
 		r.Post("/verify-2fa", h.Verify2FA)
 
+		// Setting up 2FA when the tenant's policy blocks the sign-in
+		r.Post("/2fa-setup/begin", h.BeginTwoFactorSetup)
+		r.Post("/2fa-setup/finish", h.FinishTwoFactorSetup)
+
+		// Emailed one-time codes for users who lost their authenticator
+		r.Post("/2fa-bypass/request", h.RequestTwoFABypass)
+		r.Post("/2fa-bypass/verify", h.VerifyTwoFABypass)
+
+		// Security keys as the second factor: the 2FA token carries the tenant
+		r.Post("/webauthn/2fa/begin", h.BeginWebAuthn2FA)
+		r.Post("/webauthn/2fa/finish", h.FinishWebAuthn2FA)
+
+		// Passkey sign-in requires tenant context, passkeys being per tenant
+		r.With(tenantMiddleware.RequireTenant).Post("/webauthn/login/begin", h.BeginWebAuthnLogin)
+		r.With(tenantMiddleware.RequireTenant).Post("/webauthn/login/finish", h.FinishWebAuthnLogin)
+
 		r.Post("/refresh", h.RefreshToken)
 
+		// Single sign-on: browsers navigate to start and are sent back to
+		// callback (or post to acs, for SAML) by the provider, so the tenant comes from the tenant query
+		// parameter and the saved sign-on
+		r.Get("/sso/providers", h.ListSSOProviders)
+		r.Get("/sso/{provider}/start", h.StartSSO)
+		r.Get("/sso/{provider}/callback", h.SSOCallback)
+		r.Post("/sso/{provider}/acs", h.SAMLACS)
+		r.Get("/sso/{provider}/metadata", h.SAMLMetadata)
+		r.Post("/sso/exchange", h.ExchangeSSOCode)
+
 		// Password reset requires tenant context
 		r.With(tenantMiddleware.RequireTenant).Post("/forgot-password", h.RequestPasswordReset)
 		r.With(tenantMiddleware.RequireTenant).Post("/reset-password", h.ResetPassword)
 
+		// Email change confirmation comes from the emailed link
+		r.With(tenantMiddleware.RequireTenant).Post("/confirm-email", h.ConfirmEmailChange)
+
 		// Protected routes
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware.Authenticate)
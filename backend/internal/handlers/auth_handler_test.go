@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services/mocks"
+	"myerp-v2/internal/utils"
+)
+
+func TestVerify2FA(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		response   *models.UserLoginResponse
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "Valid code",
+			body:       `{"two_factor_token": "token", "code": "123456"}`,
+			response:   &models.UserLoginResponse{AccessToken: "access"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Missing code",
+			body:       `{"two_factor_token": "token"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "BAD_REQUEST",
+		},
+		{
+			name:       "Invalid code",
+			body:       `{"two_factor_token": "token", "code": "000000"}`,
+			err:        utils.NewUnauthorizedError("INVALID_2FA_CODE", "invalid 2FA code"),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "INVALID_2FA_CODE",
+		},
+		{
+			name:       "Inactive user",
+			body:       `{"two_factor_token": "token", "code": "123456"}`,
+			err:        utils.NewForbiddenError("USER_INACTIVE", "user account is not active"),
+			wantStatus: http.StatusForbidden,
+			wantCode:   "USER_INACTIVE",
+		},
+		{
+			name:       "Too many attempts",
+			body:       `{"two_factor_token": "token", "code": "000000"}`,
+			err:        utils.NewTooManyRequestsError("TOO_MANY_2FA_ATTEMPTS", "too many failed 2FA attempts, please try again in 15 minutes"),
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "TOO_MANY_2FA_ATTEMPTS",
+		},
+		{
+			name:       "Session limit reached",
+			body:       `{"two_factor_token": "token", "code": "123456"}`,
+			err:        utils.NewConflictError("SESSION_LIMIT_REACHED", "concurrent session limit reached"),
+			wantStatus: http.StatusConflict,
+			wantCode:   "SESSION_LIMIT_REACHED",
+		},
+		{
+			name:       "Internal error",
+			body:       `{"two_factor_token": "token", "code": "123456"}`,
+			err:        errors.New("failed to create session: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &mocks.AuthManager{
+				Verify2FAAndLoginFunc: func(ctx context.Context, twoFactorToken, code string, deviceInfo utils.DeviceInfo, ipAddress string, rememberMe bool) (*models.UserLoginResponse, error) {
+					assert.Equal(t, "token", twoFactorToken)
+					return tt.response, tt.err
+				},
+			}
+			h := NewAuthHandler(authService, &mocks.PermissionManager{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/verify-2fa", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			h.Verify2FA(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var resp utils.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			if tt.wantCode == "" {
+				assert.True(t, resp.Success)
+				return
+			}
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.wantCode, resp.Error.Code)
+			assert.NotContains(t, resp.Error.Message, "connection refused")
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// AutomationHandler handles automation rule and execution log endpoints
+type AutomationHandler struct {
+	automationService services.AutomationManager
+}
+
+// NewAutomationHandler creates a new automation handler
+func NewAutomationHandler(automationService services.AutomationManager) *AutomationHandler {
+	return &AutomationHandler{
+		automationService: automationService,
+	}
+}
+
+// ListTriggers lists the events rules can be triggered by, and the condition
+// operators and action types they can use
+// GET /api/automation/triggers
+func (h *AutomationHandler) ListTriggers(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, map[string]interface{}{
+		"event_types":  models.EventTypes,
+		"operators":    models.AutomationOperators,
+		"action_types": models.AutomationActionTypes,
+	})
+}
+
+// ListRules lists the tenant's rules
+// GET /api/automation/rules
+func (h *AutomationHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := automationPagination(r)
+
+	rules, totalCount, err := h.automationService.ListRules(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list automation rules")
+		return
+	}
+
+	utils.SuccessWithMeta(w, rules, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetRule retrieves a rule
+// GET /api/automation/rules/{id}
+func (h *AutomationHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rule, err := h.automationService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// CreateRule creates a rule
+// POST /api/automation/rules
+func (h *AutomationHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req models.AutomationRuleRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if errors := validateAutomationRuleRequest(&req); errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rule, err := h.automationService.CreateRule(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondAutomationRuleError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), rule.ID)
+	middleware.SetAuditAfter(r.Context(), rule)
+
+	utils.Created(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// UpdateRule replaces a rule's definition
+// PUT /api/automation/rules/{id}
+func (h *AutomationHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	var req models.AutomationRuleRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if errors := validateAutomationRuleRequest(&req); errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.automationService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	rule, err := h.automationService.UpdateRule(r.Context(), tenantID, ruleID, &req)
+	if err != nil {
+		respondAutomationRuleError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), rule)
+
+	utils.Success(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// EnableRule enables a rule
+// POST /api/automation/rules/{id}/enable
+func (h *AutomationHandler) EnableRule(w http.ResponseWriter, r *http.Request) {
+	h.setRuleEnabled(w, r, true)
+}
+
+// DisableRule disables a rule. Its queued executions are not run.
+// POST /api/automation/rules/{id}/disable
+func (h *AutomationHandler) DisableRule(w http.ResponseWriter, r *http.Request) {
+	h.setRuleEnabled(w, r, false)
+}
+
+// setRuleEnabled enables or disables the rule in the URL
+func (h *AutomationHandler) setRuleEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rule, err := h.automationService.SetRuleEnabled(r.Context(), tenantID, ruleID, enabled)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), rule)
+
+	utils.Success(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// DeleteRule deletes a rule and its execution log
+// DELETE /api/automation/rules/{id}
+func (h *AutomationHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.automationService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.automationService.DeleteRule(r.Context(), tenantID, ruleID); err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Automation rule deleted successfully",
+	})
+}
+
+// ListRuleExecutions lists a rule's execution log, newest first
+// GET /api/automation/rules/{id}/executions?status=failed
+func (h *AutomationHandler) ListRuleExecutions(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	h.listExecutions(w, r, &ruleID)
+}
+
+// ListExecutions lists the execution log of all rules, newest first
+// GET /api/automation/executions?status=failed
+func (h *AutomationHandler) ListExecutions(w http.ResponseWriter, r *http.Request) {
+	h.listExecutions(w, r, nil)
+}
+
+// listExecutions lists executions of ruleID, or of all rules if nil
+func (h *AutomationHandler) listExecutions(w http.ResponseWriter, r *http.Request, ruleID *uuid.UUID) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.AutomationExecutionPending,
+			models.AutomationExecutionSucceeded,
+			models.AutomationExecutionFailed,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, pageSize, offset := automationPagination(r)
+
+	executions, totalCount, err := h.automationService.ListExecutions(r.Context(), tenantID, ruleID, status, pageSize, offset)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, executions, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetExecution retrieves one execution, with its event and action results
+// GET /api/automation/executions/{executionID}
+func (h *AutomationHandler) GetExecution(w http.ResponseWriter, r *http.Request) {
+	executionID, err := uuid.Parse(chi.URLParam(r, "executionID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid execution ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	execution, err := h.automationService.GetExecution(r.Context(), tenantID, executionID)
+	if err != nil {
+		respondAutomationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"execution": execution,
+	})
+}
+
+// validateAutomationRuleRequest checks the fields of a rule that do not need
+// the database; the service checks the conditions and actions in depth
+func validateAutomationRuleRequest(req *models.AutomationRuleRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 0, 255, "Name", &errors)
+	utils.ValidateStringLength("description", req.Description, 0, 1000, "Description", &errors)
+	utils.ValidateRequired("trigger_event", req.TriggerEvent, "Trigger event", &errors)
+	if req.TriggerEvent != "" {
+		utils.ValidateEnum("trigger_event", req.TriggerEvent, models.EventTypes, "Trigger event", &errors)
+	}
+	for _, condition := range req.Conditions {
+		utils.ValidateEnum("conditions", condition.Operator, models.AutomationOperators, "Operator", &errors)
+	}
+	if len(req.Actions) == 0 {
+		errors.Add("actions", "At least one action is required")
+	}
+	for _, action := range req.Actions {
+		utils.ValidateEnum("actions", action.Type, models.AutomationActionTypes, "Action type", &errors)
+	}
+	return errors
+}
+
+// automationPagination reads the page and page_size query parameters
+func automationPagination(r *http.Request) (int, int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// respondAutomationError maps automation service errors to HTTP responses
+func respondAutomationError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Automation operation failed")
+}
+
+// respondAutomationRuleError maps errors from creating or updating a rule
+func respondAutomationRuleError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Failed to save automation rule")
+}
+
+// RegisterRoutes registers all automation routes
+func (h *AutomationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, planMiddleware *middleware.PlanMiddleware) {
+	r.Route("/automation", func(r chi.Router) {
+		// All automation routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Available triggers, operators and actions - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/triggers", h.ListTriggers)
+
+		// List and get rules - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/rules", h.ListRules)
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/rules/{id}", h.GetRule)
+
+		// Create rule - requires create permission and a plan with automations
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionCreate),
+			planMiddleware.RequireFeature(models.PlanFeatureAutomations),
+			auditMiddleware.Record(models.ActionAutomationRuleCreated, models.ResourceAutomation),
+		).Post("/rules", h.CreateRule)
+
+		// Update rule - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionAutomationRuleUpdated, models.ResourceAutomation),
+		).Put("/rules/{id}", h.UpdateRule)
+
+		// Enable/disable rule - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionAutomationRuleEnabled, models.ResourceAutomation),
+		).Post("/rules/{id}/enable", h.EnableRule)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionAutomationRuleDisabled, models.ResourceAutomation),
+		).Post("/rules/{id}/disable", h.DisableRule)
+
+		// Delete rule - requires delete permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionDelete),
+			auditMiddleware.Record(models.ActionAutomationRuleDeleted, models.ResourceAutomation),
+		).Delete("/rules/{id}", h.DeleteRule)
+
+		// Execution log - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/rules/{id}/executions", h.ListRuleExecutions)
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/executions", h.ListExecutions)
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/executions/{executionID}", h.GetExecution)
+	})
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/billing"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// stripeWebhookMaxBody bounds Stripe webhook requests
+const stripeWebhookMaxBody = 1 << 20
+
+// BillingHandler handles plan and subscription endpoints
+type BillingHandler struct {
+	billingService services.BillingManager
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(billingService services.BillingManager) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+	}
+}
+
+// GetStatus retrieves the tenant's plan, subscription, quota usage and
+// metered usage this month
+// GET /api/billing
+func (h *BillingHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status, err := h.billingService.GetStatus(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get billing status")
+		return
+	}
+
+	utils.Success(w, status)
+}
+
+// ListPlans lists the plans with their limits and features
+// GET /api/billing/plans
+func (h *BillingHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, h.billingService.Plans())
+}
+
+// Checkout starts a Stripe Checkout session for a paid plan. The client
+// sends the user to the returned URL.
+// POST /api/billing/checkout
+func (h *BillingHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var req models.BillingCheckoutRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if !slices.Contains(models.PlanTiers, req.PlanTier) || req.PlanTier == models.PlanTierFree {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"plan_tier": "Plan tier must be starter, professional or enterprise",
+		})
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	checkout, err := h.billingService.Checkout(r.Context(), tenantID, userID, req.PlanTier)
+	if err != nil {
+		h.writeError(w, err, "Failed to start checkout")
+		return
+	}
+
+	utils.Success(w, checkout)
+}
+
+// ChangePlan moves a subscribed tenant to another plan
+// PUT /api/billing/plan
+func (h *BillingHandler) ChangePlan(w http.ResponseWriter, r *http.Request) {
+	var req models.BillingPlanChangeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if !slices.Contains(models.PlanTiers, req.PlanTier) {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"plan_tier": "Plan tier must be free, starter, professional or enterprise",
+		})
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status, err := h.billingService.ChangePlan(r.Context(), tenantID, userID, req.PlanTier)
+	if err != nil {
+		h.writeError(w, err, "Failed to change plan")
+		return
+	}
+
+	utils.Success(w, status)
+}
+
+// writeError writes the response of a failed checkout or plan change
+func (h *BillingHandler) writeError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "billing is not configured":
+		utils.ServiceUnavailable(w, "Billing is not available")
+	case "plan cannot be purchased":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"plan_tier": "This plan cannot be purchased, contact sales",
+		})
+	case "sandboxes cannot be billed", "tenant already has a subscription", "tenant has no subscription", "plan is already active":
+		utils.Conflict(w, err.Error())
+	case "usage exceeds the plan's limits":
+		utils.Conflict(w, "Your usage exceeds the limits of this plan")
+	default:
+		if strings.HasPrefix(err.Error(), "stripe request failed") {
+			log.Printf("⚠️  %s: %v", fallback, err)
+			utils.Error(w, http.StatusBadGateway, "PAYMENT_PROVIDER_ERROR", "The payment provider could not be reached, try again later")
+			return
+		}
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// HandleWebhook receives Stripe webhooks and syncs the tenants'
+// subscriptions. Stripe signs its requests, so no authentication is required.
+// POST /api/billing/webhooks/stripe
+func (h *BillingHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, stripeWebhookMaxBody))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook payload")
+		return
+	}
+
+	if err := h.billingService.HandleWebhook(r.Context(), r.Header, body); err != nil {
+		switch {
+		case errors.Is(err, billing.ErrInvalidSignature):
+			utils.Unauthorized(w, "Invalid webhook signature")
+		case strings.HasPrefix(err.Error(), "invalid stripe event"):
+			utils.BadRequest(w, "Invalid webhook payload")
+		default:
+			// Stripe retries failed deliveries
+			log.Printf("⚠️  Failed to handle Stripe webhook: %v", err)
+			utils.InternalServerError(w, "Failed to handle webhook")
+		}
+		return
+	}
+
+	utils.Success(w, nil)
+}
+
+// RegisterRoutes registers the billing routes of signed-in users
+func (h *BillingHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/billing", func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		// Plan, subscription and plans - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetStatus)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/plans", h.ListPlans)
+
+		// Buying and changing plans - owners only
+		r.With(permMiddleware.RequireOwner).Post("/checkout", h.Checkout)
+		r.With(permMiddleware.RequireOwner).Put("/plan", h.ChangePlan)
+	})
+}
+
+// RegisterWebhookRoutes registers the Stripe webhook route, which is not
+// tied to a tenant
+func (h *BillingHandler) RegisterWebhookRoutes(r chi.Router) {
+	r.Post("/billing/webhooks/stripe", h.HandleWebhook)
+}
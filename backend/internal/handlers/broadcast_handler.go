@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// BroadcastHandler handles bulk email (broadcast) endpoints
+type BroadcastHandler struct {
+	broadcastService services.BroadcastManager
+}
+
+// NewBroadcastHandler creates a new broadcast handler
+func NewBroadcastHandler(broadcastService services.BroadcastManager) *BroadcastHandler {
+	return &BroadcastHandler{
+		broadcastService: broadcastService,
+	}
+}
+
+// CreateBroadcast composes an email to all or a filtered set of users
+// POST /api/broadcasts
+func (h *BroadcastHandler) CreateBroadcast(w http.ResponseWriter, r *http.Request) {
+	var req models.BroadcastCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	req.Subject = strings.TrimSpace(req.Subject)
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("subject", req.Subject, "Subject", &errors)
+	utils.ValidateStringLength("subject", req.Subject, 1, 500, "Subject", &errors)
+	utils.ValidateRequired("body", strings.TrimSpace(req.Body), "Body", &errors)
+	for _, status := range req.Filter.Statuses {
+		utils.ValidateEnum("filter.statuses", status, []string{
+			models.UserStatusActive,
+			models.UserStatusPending,
+			models.UserStatusSuspended,
+			models.UserStatusDeactivated,
+		}, "Status", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	broadcast, err := h.broadcastService.CreateBroadcast(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondBroadcastError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"broadcast": broadcast,
+		"message":   "Broadcast is being sent",
+	})
+}
+
+// ListBroadcasts lists the tenant's broadcasts
+// GET /api/broadcasts
+func (h *BroadcastHandler) ListBroadcasts(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	broadcasts, totalCount, err := h.broadcastService.ListBroadcasts(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list broadcasts")
+		return
+	}
+
+	utils.SuccessWithMeta(w, broadcasts, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetBroadcast retrieves a broadcast with its delivery counts
+// GET /api/broadcasts/{id}
+func (h *BroadcastHandler) GetBroadcast(w http.ResponseWriter, r *http.Request) {
+	broadcastID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid broadcast ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	broadcast, err := h.broadcastService.GetBroadcast(r.Context(), tenantID, broadcastID)
+	if err != nil {
+		respondBroadcastError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"broadcast": broadcast,
+	})
+}
+
+// ListRecipients lists a broadcast's recipients with their delivery status
+// GET /api/broadcasts/{id}/recipients?status=failed
+func (h *BroadcastHandler) ListRecipients(w http.ResponseWriter, r *http.Request) {
+	broadcastID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid broadcast ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.DeliveryStatusPending,
+			models.DeliveryStatusSending,
+			models.DeliveryStatusSent,
+			models.DeliveryStatusFailed,
+			models.DeliveryStatusSkipped,
+			models.DeliveryStatusCancelled,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	recipients, totalCount, err := h.broadcastService.ListRecipients(r.Context(), tenantID, broadcastID, status, pageSize, offset)
+	if err != nil {
+		respondBroadcastError(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, recipients, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// CancelBroadcast stops a broadcast that is still sending
+// POST /api/broadcasts/{id}/cancel
+func (h *BroadcastHandler) CancelBroadcast(w http.ResponseWriter, r *http.Request) {
+	broadcastID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid broadcast ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	broadcast, err := h.broadcastService.CancelBroadcast(r.Context(), tenantID, userID, broadcastID)
+	if err != nil {
+		respondBroadcastError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"broadcast": broadcast,
+		"message":   "Broadcast cancelled",
+	})
+}
+
+// Unsubscribe opts a recipient out of future broadcasts using the token from
+// their email. The tenant is resolved from the request like other public routes.
+// POST /api/broadcasts/unsubscribe
+func (h *BroadcastHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	token, err := uuid.Parse(req.Token)
+	if err != nil {
+		utils.BadRequest(w, "Invalid unsubscribe link")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.BadRequest(w, "Tenant context required")
+		return
+	}
+
+	if err := h.broadcastService.Unsubscribe(r.Context(), tenantID, token); err != nil {
+		respondBroadcastError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "You have been unsubscribed from announcements",
+	})
+}
+
+// respondBroadcastError maps broadcast service errors to HTTP responses
+func respondBroadcastError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Broadcast operation failed")
+}
+
+// RegisterRoutes registers broadcast routes
+func (h *BroadcastHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/broadcasts", func(r chi.Router) {
+		// Unsubscribe (public endpoint - the token authorizes it)
+		r.Post("/unsubscribe", h.Unsubscribe)
+
+		// All other routes require authentication
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate)
+
+			r.With(permMiddleware.RequirePermission(models.ResourceBroadcasts, models.ActionView)).Get("/", h.ListBroadcasts)
+			r.With(permMiddleware.RequirePermission(models.ResourceBroadcasts, models.ActionCreate)).Post("/", h.CreateBroadcast)
+			r.With(permMiddleware.RequirePermission(models.ResourceBroadcasts, models.ActionView)).Get("/{id}", h.GetBroadcast)
+			r.With(permMiddleware.RequirePermission(models.ResourceBroadcasts, models.ActionView)).Get("/{id}/recipients", h.ListRecipients)
+			r.With(permMiddleware.RequirePermission(models.ResourceBroadcasts, models.ActionCancel)).Post("/{id}/cancel", h.CancelBroadcast)
+		})
+	})
+}
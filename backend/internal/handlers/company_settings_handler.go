@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,11 +13,11 @@ import (
 
 // CompanySettingsHandler handles company settings endpoints
 type CompanySettingsHandler struct {
-	service *services.CompanySettingsService
+	service services.CompanySettingsManager
 }
 
 // NewCompanySettingsHandler creates a new company settings handler
-func NewCompanySettingsHandler(service *services.CompanySettingsService) *CompanySettingsHandler {
+func NewCompanySettingsHandler(service services.CompanySettingsManager) *CompanySettingsHandler {
 	return &CompanySettingsHandler{service: service}
 }
 
@@ -38,28 +39,61 @@ func (h *CompanySettingsHandler) GetSettings(w http.ResponseWriter, r *http.Requ
 	utils.Success(w, settings)
 }
 
-// UpdateSettings updates company settings (partial update)
+// UpdateSettings updates company settings (partial update). Fields left out
+// or null keep their value.
 // PUT /api/settings/company
 func (h *CompanySettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
-	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	var req models.CompanySettingsUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.updateSettings(w, r, &req, nil)
+}
+
+// PatchSettings applies a JSON merge patch to company settings. Fields left
+// out keep their value and null clears optional fields.
+// PATCH /api/settings/company
+func (h *CompanySettingsHandler) PatchSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.CompanySettingsUpdateRequest
+	nulls, err := utils.ParseMergePatch(r, &req)
 	if err != nil {
-		utils.Unauthorized(w, "Authentication required")
+		utils.BadRequest(w, "Invalid request body")
 		return
 	}
 
-	userID, err := middleware.GetUserIDFromContext(r.Context())
+	// Fields outside the optional ones must keep a value
+	errors := utils.ValidationErrors{}
+	for field := range nulls {
+		if !models.CompanySettingsNullableFields[field] {
+			errors.Add(field, fmt.Sprintf("%s cannot be null", field))
+		}
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	h.updateSettings(w, r, &req, nulls)
+}
+
+// updateSettings applies an update to company settings, clearing the fields
+// named in nulls
+func (h *CompanySettingsHandler) updateSettings(w http.ResponseWriter, r *http.Request, req *models.CompanySettingsUpdateRequest, nulls map[string]bool) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
 	if err != nil {
 		utils.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	var req models.CompanySettingsUpdateRequest
-	if err := utils.ParseJSONBody(r, &req); err != nil {
-		utils.BadRequest(w, "Invalid request body")
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
 		return
 	}
 
-	settings, err := h.service.UpdateSettings(r.Context(), tenantID, userID, &req)
+	settings, err := h.service.UpdateSettings(r.Context(), tenantID, userID, req, nulls)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to update settings")
 		return
@@ -74,5 +108,6 @@ func (h *CompanySettingsHandler) RegisterRoutes(r chi.Router, authMiddleware *mi
 		r.Use(authMiddleware.Authenticate)
 		r.Get("/", h.GetSettings)
 		r.Put("/", h.UpdateSettings)
+		r.Patch("/", h.PatchSettings)
 	})
 }
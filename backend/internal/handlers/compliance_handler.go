@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// maxComplianceReportDays is the longest period a report covers, a leap year
+const maxComplianceReportDays = 366
+
+// ComplianceHandler handles compliance evidence reports
+type ComplianceHandler struct {
+	complianceService services.ComplianceManager
+}
+
+// NewComplianceHandler creates a new compliance handler
+func NewComplianceHandler(complianceService services.ComplianceManager) *ComplianceHandler {
+	return &ComplianceHandler{
+		complianceService: complianceService,
+	}
+}
+
+// RequestReport queues the compilation of an evidence package for auditors.
+// The response is 202 Accepted with the job; its result links the package.
+// POST /api/compliance/reports
+func (h *ComplianceHandler) RequestReport(w http.ResponseWriter, r *http.Request) {
+	var req models.ComplianceReportRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	params, errors := validateComplianceReportRequest(&req, time.Now().UTC())
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.complianceService.RequestReport(r.Context(), tenantID, userID, params)
+	if err != nil {
+		if err.Error() == "job queue is full" {
+			utils.TooManyRequests(w, "Too many jobs are waiting to run, try again later")
+			return
+		}
+		utils.InternalServerError(w, "Failed to queue the report")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), job.ID)
+	middleware.SetAuditMetadata(r.Context(), "framework", params.Framework)
+	middleware.SetAuditMetadata(r.Context(), "period_start", req.PeriodStart)
+	middleware.SetAuditMetadata(r.Context(), "period_end", req.PeriodEnd)
+
+	utils.Accepted(w, map[string]interface{}{
+		"job":     job,
+		"message": "Report queued",
+	})
+}
+
+// GetReport retrieves a report job, with the package once it has finished
+// GET /api/compliance/reports/{jobID}
+func (h *ComplianceHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.complianceService.GetReport(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"job": job,
+	})
+}
+
+// validateComplianceReportRequest checks a report request and returns the
+// job parameters. The period ends today at the latest.
+func validateComplianceReportRequest(req *models.ComplianceReportRequest, now time.Time) (models.ComplianceReportJobParams, utils.ValidationErrors) {
+	errors := utils.ValidationErrors{}
+	params := models.ComplianceReportJobParams{Framework: req.Framework}
+
+	if !slices.Contains(models.ComplianceFrameworks, req.Framework) {
+		errors.Add("framework", "Framework must be soc2 or iso27001")
+	}
+
+	var err error
+	if params.PeriodStart, err = time.Parse("2006-01-02", req.PeriodStart); err != nil {
+		errors.Add("period_start", "Period start must be a date (YYYY-MM-DD)")
+	}
+	if params.PeriodEnd, err = time.Parse("2006-01-02", req.PeriodEnd); err != nil {
+		errors.Add("period_end", "Period end must be a date (YYYY-MM-DD)")
+	}
+	if errors.HasErrors() {
+		return params, errors
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	switch {
+	case params.PeriodEnd.Before(params.PeriodStart):
+		errors.Add("period_end", "Period end must not be before its start")
+	case params.PeriodEnd.After(today):
+		errors.Add("period_end", "Period end must not be in the future")
+	case params.PeriodEnd.Sub(params.PeriodStart) >= maxComplianceReportDays*24*time.Hour:
+		errors.Add("period_end", "Period must not exceed 366 days")
+	}
+
+	return params, errors
+}
+
+// RegisterRoutes registers compliance report routes
+func (h *ComplianceHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/compliance/reports", func(r chi.Router) {
+		// All compliance routes require authentication
+		r.Use(authMiddleware.Authenticate)
+		r.Use(permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport))
+
+		r.With(
+			auditMiddleware.Record(models.ActionComplianceReportRequested, models.ResourceSecurity),
+		).Post("/", h.RequestReport)
+		r.Get("/{jobID}", h.GetReport)
+	})
+}
@@ -0,0 +1,1287 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// CRMHandler handles CRM customer, contact, pipeline, opportunity and
+// activity endpoints
+type CRMHandler struct {
+	crmService services.CRMManager
+}
+
+// NewCRMHandler creates a new CRM handler
+func NewCRMHandler(crmService services.CRMManager) *CRMHandler {
+	return &CRMHandler{
+		crmService: crmService,
+	}
+}
+
+// ListCustomers lists customers
+// GET /api/crm/customers?page=1&page_size=20&owner_id=uuid&status=lead&search=acme
+func (h *CRMHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := models.CRMCustomerFilter{
+		Status: r.URL.Query().Get("status"),
+		Search: r.URL.Query().Get("search"),
+	}
+	if filter.OwnerID, ok = crmQueryID(w, r, "owner_id", "Invalid owner ID"); !ok {
+		return
+	}
+
+	page, pageSize, offset := crmPagination(r)
+	customers, totalCount, err := h.crmService.ListCustomers(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list customers")
+		return
+	}
+
+	utils.SuccessWithMeta(w, customers, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetCustomer retrieves a customer
+// GET /api/crm/customers/{id}
+func (h *CRMHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	customerID, ok := crmURLID(w, r, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.crmService.GetCustomer(r.Context(), tenantID, customerID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to get customer")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"customer": customer,
+	})
+}
+
+// CreateCustomer creates a customer
+// POST /api/crm/customers
+func (h *CRMHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var req models.CRMCustomerCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 255, "Name", &errors)
+	if req.CustomerType != "" {
+		utils.ValidateEnum("customer_type", req.CustomerType, models.CRMCustomerTypes, "Customer type", &errors)
+	}
+	if req.Status != "" {
+		utils.ValidateEnum("status", req.Status, models.CRMCustomerStatuses, "Status", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.crmService.CreateCustomer(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to create customer")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), customer.ID)
+	middleware.SetAuditAfter(r.Context(), customer)
+
+	utils.Created(w, map[string]interface{}{
+		"customer": customer,
+		"message":  "Customer created successfully",
+	})
+}
+
+// UpdateCustomer updates a customer
+// PUT /api/crm/customers/{id}
+func (h *CRMHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	customerID, ok := crmURLID(w, r, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMCustomerUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+	if req.CustomerType != nil {
+		utils.ValidateEnum("customer_type", *req.CustomerType, models.CRMCustomerTypes, "Customer type", &errors)
+	}
+	if req.Status != nil {
+		utils.ValidateEnum("status", *req.Status, models.CRMCustomerStatuses, "Status", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetCustomer(r.Context(), tenantID, customerID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update customer")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	customer, err := h.crmService.UpdateCustomer(r.Context(), tenantID, customerID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update customer")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), customer)
+
+	utils.Success(w, map[string]interface{}{
+		"customer": customer,
+		"message":  "Customer updated successfully",
+	})
+}
+
+// DeleteCustomer deletes a customer with its contacts and activities
+// DELETE /api/crm/customers/{id}
+func (h *CRMHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	customerID, ok := crmURLID(w, r, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.crmService.DeleteCustomer(r.Context(), tenantID, customerID); err != nil {
+		respondCRMError(w, err, "Failed to delete customer")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), customerID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Customer deleted successfully",
+	})
+}
+
+// ListContacts lists contacts
+// GET /api/crm/contacts?page=1&page_size=20&customer_id=uuid&owner_id=uuid&search=jane
+func (h *CRMHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := models.CRMContactFilter{
+		Search: r.URL.Query().Get("search"),
+	}
+	if filter.CustomerID, ok = crmQueryID(w, r, "customer_id", "Invalid customer ID"); !ok {
+		return
+	}
+	if filter.OwnerID, ok = crmQueryID(w, r, "owner_id", "Invalid owner ID"); !ok {
+		return
+	}
+
+	page, pageSize, offset := crmPagination(r)
+	contacts, totalCount, err := h.crmService.ListContacts(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list contacts")
+		return
+	}
+
+	utils.SuccessWithMeta(w, contacts, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetContact retrieves a contact
+// GET /api/crm/contacts/{id}
+func (h *CRMHandler) GetContact(w http.ResponseWriter, r *http.Request) {
+	contactID, ok := crmURLID(w, r, "id", "Invalid contact ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	contact, err := h.crmService.GetContact(r.Context(), tenantID, contactID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to get contact")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"contact": contact,
+	})
+}
+
+// CreateContact creates a contact at a customer
+// POST /api/crm/contacts
+func (h *CRMHandler) CreateContact(w http.ResponseWriter, r *http.Request) {
+	var req models.CRMContactCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.CustomerID == uuid.Nil {
+		errors.Add("customer_id", "Customer is required")
+	}
+	utils.ValidateRequired("first_name", req.FirstName, "First name", &errors)
+	utils.ValidateStringLength("first_name", req.FirstName, 1, 100, "First name", &errors)
+	utils.ValidateRequired("last_name", req.LastName, "Last name", &errors)
+	utils.ValidateStringLength("last_name", req.LastName, 1, 100, "Last name", &errors)
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	contact, err := h.crmService.CreateContact(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to create contact")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), contact.ID)
+	middleware.SetAuditAfter(r.Context(), contact)
+
+	utils.Created(w, map[string]interface{}{
+		"contact": contact,
+		"message": "Contact created successfully",
+	})
+}
+
+// UpdateContact updates a contact
+// PUT /api/crm/contacts/{id}
+func (h *CRMHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	contactID, ok := crmURLID(w, r, "id", "Invalid contact ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMContactUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.FirstName != nil {
+		utils.ValidateStringLength("first_name", *req.FirstName, 1, 100, "First name", &errors)
+	}
+	if req.LastName != nil {
+		utils.ValidateStringLength("last_name", *req.LastName, 1, 100, "Last name", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetContact(r.Context(), tenantID, contactID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update contact")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	contact, err := h.crmService.UpdateContact(r.Context(), tenantID, contactID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update contact")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), contact)
+
+	utils.Success(w, map[string]interface{}{
+		"contact": contact,
+		"message": "Contact updated successfully",
+	})
+}
+
+// DeleteContact deletes a contact
+// DELETE /api/crm/contacts/{id}
+func (h *CRMHandler) DeleteContact(w http.ResponseWriter, r *http.Request) {
+	contactID, ok := crmURLID(w, r, "id", "Invalid contact ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.crmService.DeleteContact(r.Context(), tenantID, contactID); err != nil {
+		respondCRMError(w, err, "Failed to delete contact")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), contactID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Contact deleted successfully",
+	})
+}
+
+// ListPipelines lists pipelines with their stages
+// GET /api/crm/pipelines
+func (h *CRMHandler) ListPipelines(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipelines, err := h.crmService.ListPipelines(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list pipelines")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"pipelines": pipelines,
+	})
+}
+
+// GetPipeline retrieves a pipeline with its stages
+// GET /api/crm/pipelines/{id}
+func (h *CRMHandler) GetPipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipeline, err := h.crmService.GetPipeline(r.Context(), tenantID, pipelineID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to get pipeline")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"pipeline": pipeline,
+	})
+}
+
+// PipelineSummary totals a pipeline's opportunities by stage
+// GET /api/crm/pipelines/{id}/summary
+func (h *CRMHandler) PipelineSummary(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipeline, stages, err := h.crmService.PipelineSummary(r.Context(), tenantID, pipelineID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to summarize pipeline")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"pipeline_id": pipeline.ID,
+		"name":        pipeline.Name,
+		"stages":      stages,
+	})
+}
+
+// CreatePipeline creates a pipeline
+// POST /api/crm/pipelines
+func (h *CRMHandler) CreatePipeline(w http.ResponseWriter, r *http.Request) {
+	var req models.CRMPipelineRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateCRMPipelineRequest(&req, true)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipeline, err := h.crmService.CreatePipeline(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to create pipeline")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), pipeline.ID)
+	middleware.SetAuditAfter(r.Context(), pipeline)
+
+	utils.Created(w, map[string]interface{}{
+		"pipeline": pipeline,
+		"message":  "Pipeline created successfully",
+	})
+}
+
+// UpdatePipeline renames a pipeline or makes it the default
+// PUT /api/crm/pipelines/{id}
+func (h *CRMHandler) UpdatePipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMPipelineRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateCRMPipelineRequest(&req, false)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetPipeline(r.Context(), tenantID, pipelineID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update pipeline")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	pipeline, err := h.crmService.UpdatePipeline(r.Context(), tenantID, pipelineID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update pipeline")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), pipeline)
+
+	utils.Success(w, map[string]interface{}{
+		"pipeline": pipeline,
+		"message":  "Pipeline updated successfully",
+	})
+}
+
+// DeletePipeline deletes a pipeline without opportunities
+// DELETE /api/crm/pipelines/{id}
+func (h *CRMHandler) DeletePipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.crmService.DeletePipeline(r.Context(), tenantID, pipelineID); err != nil {
+		respondCRMError(w, err, "Failed to delete pipeline")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), pipelineID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Pipeline deleted successfully",
+	})
+}
+
+// AddStage adds a stage to a pipeline
+// POST /api/crm/pipelines/{id}/stages
+func (h *CRMHandler) AddStage(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMPipelineStageRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	validateCRMStageRequest("", &req, &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipeline, err := h.crmService.AddStage(r.Context(), tenantID, pipelineID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to add stage")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), pipelineID)
+	middleware.SetAuditAfter(r.Context(), pipeline)
+
+	utils.Created(w, map[string]interface{}{
+		"pipeline": pipeline,
+		"message":  "Stage added successfully",
+	})
+}
+
+// UpdateStage replaces a stage's settings and moves it to its position
+// PUT /api/crm/pipelines/{id}/stages/{stageId}
+func (h *CRMHandler) UpdateStage(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+	stageID, ok := crmURLID(w, r, "stageId", "Invalid stage ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMPipelineStageRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	validateCRMStageRequest("", &req, &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetPipeline(r.Context(), tenantID, pipelineID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update stage")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	pipeline, err := h.crmService.UpdateStage(r.Context(), tenantID, pipelineID, stageID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update stage")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), pipeline)
+
+	utils.Success(w, map[string]interface{}{
+		"pipeline": pipeline,
+		"message":  "Stage updated successfully",
+	})
+}
+
+// DeleteStage deletes a stage without opportunities
+// DELETE /api/crm/pipelines/{id}/stages/{stageId}
+func (h *CRMHandler) DeleteStage(w http.ResponseWriter, r *http.Request) {
+	pipelineID, ok := crmURLID(w, r, "id", "Invalid pipeline ID")
+	if !ok {
+		return
+	}
+	stageID, ok := crmURLID(w, r, "stageId", "Invalid stage ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	pipeline, err := h.crmService.DeleteStage(r.Context(), tenantID, pipelineID, stageID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to delete stage")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), pipelineID)
+	middleware.SetAuditMetadata(r.Context(), "stage_id", stageID)
+
+	utils.Success(w, map[string]interface{}{
+		"pipeline": pipeline,
+		"message":  "Stage deleted successfully",
+	})
+}
+
+// ListOpportunities lists opportunities
+// GET /api/crm/opportunities?page=1&page_size=20&customer_id=uuid&pipeline_id=uuid&stage_id=uuid&owner_id=uuid&status=open&search=renewal
+func (h *CRMHandler) ListOpportunities(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := models.CRMOpportunityFilter{
+		Status: r.URL.Query().Get("status"),
+		Search: r.URL.Query().Get("search"),
+	}
+	if filter.CustomerID, ok = crmQueryID(w, r, "customer_id", "Invalid customer ID"); !ok {
+		return
+	}
+	if filter.PipelineID, ok = crmQueryID(w, r, "pipeline_id", "Invalid pipeline ID"); !ok {
+		return
+	}
+	if filter.StageID, ok = crmQueryID(w, r, "stage_id", "Invalid stage ID"); !ok {
+		return
+	}
+	if filter.OwnerID, ok = crmQueryID(w, r, "owner_id", "Invalid owner ID"); !ok {
+		return
+	}
+
+	page, pageSize, offset := crmPagination(r)
+	opportunities, totalCount, err := h.crmService.ListOpportunities(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list opportunities")
+		return
+	}
+
+	utils.SuccessWithMeta(w, opportunities, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetOpportunity retrieves an opportunity
+// GET /api/crm/opportunities/{id}
+func (h *CRMHandler) GetOpportunity(w http.ResponseWriter, r *http.Request) {
+	opportunityID, ok := crmURLID(w, r, "id", "Invalid opportunity ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	opportunity, err := h.crmService.GetOpportunity(r.Context(), tenantID, opportunityID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to get opportunity")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"opportunity": opportunity,
+	})
+}
+
+// CreateOpportunity creates an opportunity
+// POST /api/crm/opportunities
+func (h *CRMHandler) CreateOpportunity(w http.ResponseWriter, r *http.Request) {
+	var req models.CRMOpportunityCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.CustomerID == uuid.Nil {
+		errors.Add("customer_id", "Customer is required")
+	}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 255, "Name", &errors)
+	if req.Amount < 0 {
+		errors.Add("amount", "Amount cannot be negative")
+	}
+	if req.Currency != "" {
+		utils.ValidateStringLength("currency", req.Currency, 3, 3, "Currency", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	opportunity, err := h.crmService.CreateOpportunity(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to create opportunity")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), opportunity.ID)
+	middleware.SetAuditAfter(r.Context(), opportunity)
+
+	utils.Created(w, map[string]interface{}{
+		"opportunity": opportunity,
+		"message":     "Opportunity created successfully",
+	})
+}
+
+// UpdateOpportunity updates an opportunity's details
+// PUT /api/crm/opportunities/{id}
+func (h *CRMHandler) UpdateOpportunity(w http.ResponseWriter, r *http.Request) {
+	opportunityID, ok := crmURLID(w, r, "id", "Invalid opportunity ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMOpportunityUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+	if req.Amount != nil && *req.Amount < 0 {
+		errors.Add("amount", "Amount cannot be negative")
+	}
+	if req.Currency != nil {
+		utils.ValidateStringLength("currency", *req.Currency, 3, 3, "Currency", &errors)
+	}
+	if req.Probability != nil && (*req.Probability < 0 || *req.Probability > 100) {
+		errors.Add("probability", "Probability must be between 0 and 100")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetOpportunity(r.Context(), tenantID, opportunityID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update opportunity")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	opportunity, err := h.crmService.UpdateOpportunity(r.Context(), tenantID, opportunityID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update opportunity")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), opportunity)
+
+	utils.Success(w, map[string]interface{}{
+		"opportunity": opportunity,
+		"message":     "Opportunity updated successfully",
+	})
+}
+
+// MoveStage moves an opportunity to another stage of its pipeline
+// PUT /api/crm/opportunities/{id}/stage
+func (h *CRMHandler) MoveStage(w http.ResponseWriter, r *http.Request) {
+	opportunityID, ok := crmURLID(w, r, "id", "Invalid opportunity ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMStageChangeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.StageID == uuid.Nil {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"stage_id": "Stage is required"})
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetOpportunity(r.Context(), tenantID, opportunityID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to move opportunity")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	opportunity, err := h.crmService.MoveStage(r.Context(), tenantID, userID, opportunityID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to move opportunity")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), opportunity)
+
+	utils.Success(w, map[string]interface{}{
+		"opportunity": opportunity,
+		"message":     "Opportunity moved successfully",
+	})
+}
+
+// DeleteOpportunity deletes an opportunity and its activities
+// DELETE /api/crm/opportunities/{id}
+func (h *CRMHandler) DeleteOpportunity(w http.ResponseWriter, r *http.Request) {
+	opportunityID, ok := crmURLID(w, r, "id", "Invalid opportunity ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.crmService.DeleteOpportunity(r.Context(), tenantID, opportunityID); err != nil {
+		respondCRMError(w, err, "Failed to delete opportunity")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), opportunityID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Opportunity deleted successfully",
+	})
+}
+
+// Assign returns a handler that gives a customer, contact or opportunity to
+// another owner, or unassigns it with a null owner_id
+// PUT /api/crm/{customers|contacts|opportunities}/{id}/owner
+func (h *CRMHandler) Assign(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entityID, ok := crmURLID(w, r, "id", "Invalid "+entity+" ID")
+		if !ok {
+			return
+		}
+
+		var req models.CRMAssignRequest
+		if err := utils.ParseJSONBody(r, &req); err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+
+		tenantID, userID, ok := crmUser(w, r)
+		if !ok {
+			return
+		}
+
+		if err := h.crmService.Assign(r.Context(), tenantID, userID, entity, entityID, req.OwnerID); err != nil {
+			respondCRMError(w, err, "Failed to assign owner")
+			return
+		}
+		middleware.SetAuditResourceID(r.Context(), entityID)
+		middleware.SetAuditMetadata(r.Context(), "entity", entity)
+		middleware.SetAuditAfter(r.Context(), req)
+
+		utils.Success(w, map[string]interface{}{
+			"message": "Owner updated successfully",
+		})
+	}
+}
+
+// ListActivities lists activities, the timeline of a customer, contact or
+// opportunity, or a user's open tasks
+// GET /api/crm/activities?page=1&page_size=20&customer_id=uuid&contact_id=uuid&opportunity_id=uuid&owner_id=uuid&activity_type=call&open_tasks=true
+func (h *CRMHandler) ListActivities(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := models.CRMActivityFilter{
+		ActivityType: r.URL.Query().Get("activity_type"),
+	}
+	filter.OpenTasks, _ = strconv.ParseBool(r.URL.Query().Get("open_tasks"))
+	if filter.CustomerID, ok = crmQueryID(w, r, "customer_id", "Invalid customer ID"); !ok {
+		return
+	}
+	if filter.ContactID, ok = crmQueryID(w, r, "contact_id", "Invalid contact ID"); !ok {
+		return
+	}
+	if filter.OpportunityID, ok = crmQueryID(w, r, "opportunity_id", "Invalid opportunity ID"); !ok {
+		return
+	}
+	if filter.OwnerID, ok = crmQueryID(w, r, "owner_id", "Invalid owner ID"); !ok {
+		return
+	}
+
+	page, pageSize, offset := crmPagination(r)
+	activities, totalCount, err := h.crmService.ListActivities(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list activities")
+		return
+	}
+
+	utils.SuccessWithMeta(w, activities, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetActivity retrieves an activity
+// GET /api/crm/activities/{id}
+func (h *CRMHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	activityID, ok := crmURLID(w, r, "id", "Invalid activity ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	activity, err := h.crmService.GetActivity(r.Context(), tenantID, activityID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to get activity")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"activity": activity,
+	})
+}
+
+// LogActivity logs a call, email, meeting, note or task
+// POST /api/crm/activities
+func (h *CRMHandler) LogActivity(w http.ResponseWriter, r *http.Request) {
+	var req models.CRMActivityCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.CustomerID == nil && req.ContactID == nil && req.OpportunityID == nil {
+		errors.Add("customer_id", "A customer, contact or opportunity is required")
+	}
+	utils.ValidateRequired("activity_type", req.ActivityType, "Activity type", &errors)
+	if req.ActivityType != "" {
+		utils.ValidateEnum("activity_type", req.ActivityType, models.CRMActivityTypes, "Activity type", &errors)
+	}
+	utils.ValidateRequired("subject", req.Subject, "Subject", &errors)
+	utils.ValidateStringLength("subject", req.Subject, 1, 255, "Subject", &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	activity, err := h.crmService.LogActivity(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to log activity")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), activity.ID)
+	middleware.SetAuditAfter(r.Context(), activity)
+
+	utils.Created(w, map[string]interface{}{
+		"activity": activity,
+		"message":  "Activity logged successfully",
+	})
+}
+
+// UpdateActivity updates an activity; completed marks it done or reopens it
+// PUT /api/crm/activities/{id}
+func (h *CRMHandler) UpdateActivity(w http.ResponseWriter, r *http.Request) {
+	activityID, ok := crmURLID(w, r, "id", "Invalid activity ID")
+	if !ok {
+		return
+	}
+
+	var req models.CRMActivityUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.Subject != nil {
+		utils.ValidateStringLength("subject", *req.Subject, 1, 255, "Subject", &errors)
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, userID, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	before, err := h.crmService.GetActivity(r.Context(), tenantID, activityID)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update activity")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	activity, err := h.crmService.UpdateActivity(r.Context(), tenantID, userID, activityID, &req)
+	if err != nil {
+		respondCRMError(w, err, "Failed to update activity")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), activity)
+
+	utils.Success(w, map[string]interface{}{
+		"activity": activity,
+		"message":  "Activity updated successfully",
+	})
+}
+
+// DeleteActivity deletes an activity
+// DELETE /api/crm/activities/{id}
+func (h *CRMHandler) DeleteActivity(w http.ResponseWriter, r *http.Request) {
+	activityID, ok := crmURLID(w, r, "id", "Invalid activity ID")
+	if !ok {
+		return
+	}
+	tenantID, _, ok := crmUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.crmService.DeleteActivity(r.Context(), tenantID, activityID); err != nil {
+		respondCRMError(w, err, "Failed to delete activity")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), activityID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Activity deleted successfully",
+	})
+}
+
+// crmUser returns the current tenant and user
+func crmUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+// crmURLID parses an ID from the URL path
+func crmURLID(w http.ResponseWriter, r *http.Request, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		utils.BadRequest(w, message)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// crmQueryID parses an optional ID filter from the query string
+func crmQueryID(w http.ResponseWriter, r *http.Request, param, message string) (*uuid.UUID, bool) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		utils.BadRequest(w, message)
+		return nil, false
+	}
+
+	return &id, true
+}
+
+// crmPagination reads page and page_size query parameters
+func crmPagination(r *http.Request) (page, pageSize, offset int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// validateCRMPipelineRequest validates a pipeline request; stages are only
+// read on creation
+func validateCRMPipelineRequest(req *models.CRMPipelineRequest, create bool) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	if create || req.Name != "" {
+		utils.ValidateRequired("name", req.Name, "Name", &errors)
+		utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	}
+	if create {
+		for i := range req.Stages {
+			validateCRMStageRequest("stages["+strconv.Itoa(i)+"].", &req.Stages[i], &errors)
+		}
+	}
+
+	return errors
+}
+
+// validateCRMStageRequest validates a stage, prefixing field names with
+// its place in the request
+func validateCRMStageRequest(prefix string, req *models.CRMPipelineStageRequest, errors *utils.ValidationErrors) {
+	utils.ValidateRequired(prefix+"name", req.Name, "Stage name", errors)
+	utils.ValidateStringLength(prefix+"name", req.Name, 1, 100, "Stage name", errors)
+	if req.Probability < 0 || req.Probability > 100 {
+		errors.Add(prefix+"probability", "Probability must be between 0 and 100")
+	}
+	if req.Outcome != "" {
+		utils.ValidateEnum(prefix+"outcome", req.Outcome, models.CRMOutcomes, "Outcome", errors)
+	}
+}
+
+// respondCRMError maps CRM service errors to responses
+func respondCRMError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "customer not found", "contact not found", "pipeline not found",
+		"stage not found", "opportunity not found", "activity not found":
+		utils.NotFound(w, err.Error())
+	case "insufficient permissions":
+		utils.Forbidden(w, "Assigning CRM records to others requires the crm.assign permission")
+	case "customer has opportunities", "pipeline is in use", "stage is in use",
+		"pipeline name already exists", "stage name already exists",
+		"cannot delete the default pipeline":
+		utils.Conflict(w, err.Error())
+	case "invalid customer", "invalid contact", "invalid opportunity", "invalid pipeline",
+		"invalid stage", "invalid owner", "activity links belong to different customers",
+		"pipeline needs an open stage", "system activities cannot be changed":
+		utils.BadRequest(w, err.Error())
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers CRM routes
+func (h *CRMHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/crm", func(r chi.Router) {
+		// All CRM routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		view := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionView)
+		create := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionCreate)
+		edit := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionEdit)
+		remove := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionDelete)
+		assign := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionAssign)
+		managePipelines := permMiddleware.RequirePermission(models.ResourceCRM, models.ActionManagePipelines)
+
+		r.Route("/customers", func(r chi.Router) {
+			r.With(view).Get("/", h.ListCustomers)
+			r.With(view).Get("/{id}", h.GetCustomer)
+			r.With(create, auditMiddleware.Record(models.ActionCRMCustomerCreated, models.ResourceCRM)).Post("/", h.CreateCustomer)
+			r.With(edit, auditMiddleware.Record(models.ActionCRMCustomerUpdated, models.ResourceCRM)).Put("/{id}", h.UpdateCustomer)
+			r.With(remove, auditMiddleware.Record(models.ActionCRMCustomerDeleted, models.ResourceCRM)).Delete("/{id}", h.DeleteCustomer)
+			r.With(assign, auditMiddleware.Record(models.ActionCRMOwnerAssigned, models.ResourceCRM)).Put("/{id}/owner", h.Assign("customer"))
+		})
+
+		r.Route("/contacts", func(r chi.Router) {
+			r.With(view).Get("/", h.ListContacts)
+			r.With(view).Get("/{id}", h.GetContact)
+			r.With(create, auditMiddleware.Record(models.ActionCRMContactCreated, models.ResourceCRM)).Post("/", h.CreateContact)
+			r.With(edit, auditMiddleware.Record(models.ActionCRMContactUpdated, models.ResourceCRM)).Put("/{id}", h.UpdateContact)
+			r.With(remove, auditMiddleware.Record(models.ActionCRMContactDeleted, models.ResourceCRM)).Delete("/{id}", h.DeleteContact)
+			r.With(assign, auditMiddleware.Record(models.ActionCRMOwnerAssigned, models.ResourceCRM)).Put("/{id}/owner", h.Assign("contact"))
+		})
+
+		r.Route("/pipelines", func(r chi.Router) {
+			r.With(view).Get("/", h.ListPipelines)
+			r.With(view).Get("/{id}", h.GetPipeline)
+			r.With(view).Get("/{id}/summary", h.PipelineSummary)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineCreated, models.ResourceCRM)).Post("/", h.CreatePipeline)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineUpdated, models.ResourceCRM)).Put("/{id}", h.UpdatePipeline)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineDeleted, models.ResourceCRM)).Delete("/{id}", h.DeletePipeline)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineUpdated, models.ResourceCRM)).Post("/{id}/stages", h.AddStage)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineUpdated, models.ResourceCRM)).Put("/{id}/stages/{stageId}", h.UpdateStage)
+			r.With(managePipelines, auditMiddleware.Record(models.ActionCRMPipelineUpdated, models.ResourceCRM)).Delete("/{id}/stages/{stageId}", h.DeleteStage)
+		})
+
+		r.Route("/opportunities", func(r chi.Router) {
+			r.With(view).Get("/", h.ListOpportunities)
+			r.With(view).Get("/{id}", h.GetOpportunity)
+			r.With(create, auditMiddleware.Record(models.ActionCRMOpportunityCreated, models.ResourceCRM)).Post("/", h.CreateOpportunity)
+			r.With(edit, auditMiddleware.Record(models.ActionCRMOpportunityUpdated, models.ResourceCRM)).Put("/{id}", h.UpdateOpportunity)
+			r.With(edit, auditMiddleware.Record(models.ActionCRMOpportunityStaged, models.ResourceCRM)).Put("/{id}/stage", h.MoveStage)
+			r.With(remove, auditMiddleware.Record(models.ActionCRMOpportunityDeleted, models.ResourceCRM)).Delete("/{id}", h.DeleteOpportunity)
+			r.With(assign, auditMiddleware.Record(models.ActionCRMOwnerAssigned, models.ResourceCRM)).Put("/{id}/owner", h.Assign("opportunity"))
+		})
+
+		r.Route("/activities", func(r chi.Router) {
+			r.With(view).Get("/", h.ListActivities)
+			r.With(view).Get("/{id}", h.GetActivity)
+			r.With(create, auditMiddleware.Record(models.ActionCRMActivityLogged, models.ResourceCRM)).Post("/", h.LogActivity)
+			r.With(edit, auditMiddleware.Record(models.ActionCRMActivityUpdated, models.ResourceCRM)).Put("/{id}", h.UpdateActivity)
+			r.With(remove, auditMiddleware.Record(models.ActionCRMActivityDeleted, models.ResourceCRM)).Delete("/{id}", h.DeleteActivity)
+		})
+	})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// CustomFieldHandler handles the custom fields a tenant defines on users and
+// departments. Their values are set through the entities' own endpoints, in
+// custom_fields.
+type CustomFieldHandler struct {
+	customFieldService services.CustomFieldManager
+}
+
+// NewCustomFieldHandler creates a new custom field handler
+func NewCustomFieldHandler(customFieldService services.CustomFieldManager) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// ListFields retrieves the tenant's custom fields in display order
+// GET /api/settings/custom-fields?entity=user
+func (h *CustomFieldHandler) ListFields(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entity := r.URL.Query().Get("entity")
+	if entity != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("entity", entity, models.CustomFieldEntities, "Entity", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	fields, err := h.customFieldService.ListFields(r.Context(), tenantID, entity)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"custom_fields": fields,
+	})
+}
+
+// GetField retrieves a custom field
+// GET /api/settings/custom-fields/{id}
+func (h *CustomFieldHandler) GetField(w http.ResponseWriter, r *http.Request) {
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid custom field ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	field, err := h.customFieldService.GetField(r.Context(), tenantID, fieldID)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"custom_field": field,
+	})
+}
+
+// CreateField defines a custom field
+// POST /api/settings/custom-fields
+func (h *CustomFieldHandler) CreateField(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomFieldCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("entity", req.Entity, models.CustomFieldEntities, "Entity", &errors)
+	utils.ValidateRequired("key", req.Key, "Key", &errors)
+	utils.ValidateRequired("label", req.Label, "Label", &errors)
+	utils.ValidateStringLength("label", req.Label, 1, 100, "Label", &errors)
+	utils.ValidateEnum("field_type", req.FieldType, models.CustomFieldTypes, "Field type", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	field, err := h.customFieldService.CreateField(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), field.ID)
+	middleware.SetAuditAfter(r.Context(), field)
+
+	utils.Created(w, map[string]interface{}{
+		"custom_field": field,
+		"message":      "Custom field created successfully",
+	})
+}
+
+// UpdateField replaces a custom field's label, options and validation. The
+// entity, key and type cannot be changed.
+// PUT /api/settings/custom-fields/{id}
+func (h *CustomFieldHandler) UpdateField(w http.ResponseWriter, r *http.Request) {
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid custom field ID")
+		return
+	}
+
+	var req models.CustomFieldUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("label", req.Label, "Label", &errors)
+	utils.ValidateStringLength("label", req.Label, 1, 100, "Label", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.customFieldService.GetField(r.Context(), tenantID, fieldID)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	field, err := h.customFieldService.UpdateField(r.Context(), tenantID, fieldID, &req)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), field)
+
+	utils.Success(w, map[string]interface{}{
+		"custom_field": field,
+		"message":      "Custom field updated successfully",
+	})
+}
+
+// DeleteField deletes a custom field and its values on every entity
+// DELETE /api/settings/custom-fields/{id}
+func (h *CustomFieldHandler) DeleteField(w http.ResponseWriter, r *http.Request) {
+	fieldID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid custom field ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.customFieldService.GetField(r.Context(), tenantID, fieldID)
+	if err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.customFieldService.DeleteField(r.Context(), tenantID, fieldID); err != nil {
+		respondCustomFieldError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Custom field deleted successfully",
+	})
+}
+
+// respondCustomFieldError maps custom field service errors to HTTP responses
+func respondCustomFieldError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Custom field operation failed")
+}
+
+// RegisterRoutes registers the custom field routes. Custom fields are tenant
+// settings.
+func (h *CustomFieldHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/custom-fields", func(r chi.Router) {
+		// All custom field routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// List and get fields - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.ListFields)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/{id}", h.GetField)
+
+		// Changes - require settings edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionCustomFieldCreated, models.ResourceSettings),
+		).Post("/", h.CreateField)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionCustomFieldUpdated, models.ResourceSettings),
+		).Put("/{id}", h.UpdateField)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionCustomFieldDeleted, models.ResourceSettings),
+		).Delete("/{id}", h.DeleteField)
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// DashboardHandler handles the tenant dashboard analytics
+type DashboardHandler struct {
+	dashboardService services.DashboardManager
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(dashboardService services.DashboardManager) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// GetDashboard returns the tenant's activity trends, invitations, sessions by
+// device and new users
+// GET /api/dashboard
+func (h *DashboardHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	stats, err := h.dashboardService.GetStats(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load dashboard")
+		return
+	}
+
+	utils.Success(w, stats)
+}
+
+// RegisterRoutes registers the dashboard routes
+func (h *DashboardHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/dashboard", func(r chi.Router) {
+		// All dashboard routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceReports, models.ActionView)).Get("/", h.GetDashboard)
+	})
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// DataQualityHandler handles data-quality rule and report endpoints
+type DataQualityHandler struct {
+	dataQualityService services.DataQualityManager
+}
+
+// NewDataQualityHandler creates a new data-quality handler
+func NewDataQualityHandler(dataQualityService services.DataQualityManager) *DataQualityHandler {
+	return &DataQualityHandler{
+		dataQualityService: dataQualityService,
+	}
+}
+
+// ListChecks lists the checks rules can enable and the severities rules can
+// have
+// GET /api/data-quality/checks
+func (h *DataQualityHandler) ListChecks(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, map[string]interface{}{
+		"checks":     models.DataQualityChecks,
+		"severities": models.DataQualitySeverities,
+	})
+}
+
+// GetReport retrieves the tenant's data-quality report from the last run of
+// its rules
+// GET /api/data-quality/report
+func (h *DataQualityHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	report, err := h.dataQualityService.GetReport(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get data quality report")
+		return
+	}
+
+	utils.Success(w, report)
+}
+
+// RunReport runs the tenant's rules now and returns the refreshed report
+// POST /api/data-quality/report/run
+func (h *DataQualityHandler) RunReport(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	report, err := h.dataQualityService.RunReport(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to run data quality checks")
+		return
+	}
+
+	utils.Success(w, report)
+}
+
+// ListRules lists the tenant's rules
+// GET /api/data-quality/rules
+func (h *DataQualityHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rules, err := h.dataQualityService.ListRules(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list data quality rules")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// GetRule retrieves a rule with the records its last run found
+// GET /api/data-quality/rules/{id}
+func (h *DataQualityHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rule, err := h.dataQualityService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// CreateRule enables a check for the tenant
+// POST /api/data-quality/rules
+func (h *DataQualityHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req models.DataQualityRuleRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("check_key", req.CheckKey, "Check", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	rule, err := h.dataQualityService.CreateRule(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), rule.ID)
+	middleware.SetAuditAfter(r.Context(), rule)
+
+	utils.Created(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// UpdateRule replaces a rule's severity, data stewards and state
+// PUT /api/data-quality/rules/{id}
+func (h *DataQualityHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	var req models.DataQualityRuleRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.dataQualityService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	rule, err := h.dataQualityService.UpdateRule(r.Context(), tenantID, ruleID, &req)
+	if err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), rule)
+
+	utils.Success(w, map[string]interface{}{
+		"rule": rule,
+	})
+}
+
+// DeleteRule deletes a rule
+// DELETE /api/data-quality/rules/{id}
+func (h *DataQualityHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.dataQualityService.GetRule(r.Context(), tenantID, ruleID)
+	if err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.dataQualityService.DeleteRule(r.Context(), tenantID, ruleID); err != nil {
+		respondDataQualityError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Data quality rule deleted successfully",
+	})
+}
+
+func respondDataQualityError(w http.ResponseWriter, err error) {
+	switch msg := err.Error(); {
+	case msg == "data quality rule not found":
+		utils.NotFound(w, msg)
+	case msg == "a rule for this check already exists":
+		utils.Conflict(w, msg)
+	case msg == "role not found":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"notify_role_id": "Role not found"})
+	case msg == "the check of a rule cannot be changed",
+		strings.HasPrefix(msg, "invalid check"),
+		strings.HasPrefix(msg, "invalid severity"):
+		utils.BadRequest(w, msg)
+	default:
+		utils.InternalServerError(w, "Data quality operation failed")
+	}
+}
+
+// RegisterRoutes registers all data-quality routes. Rules are managed with
+// the settings permissions.
+func (h *DataQualityHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/data-quality", func(r chi.Router) {
+		// All data-quality routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Checks, report and rules - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/checks", h.ListChecks)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/report", h.GetReport)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/rules", h.ListRules)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/rules/{id}", h.GetRule)
+
+		// Run the checks now - requires settings edit permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit)).Post("/report/run", h.RunReport)
+
+		// Rule changes - require settings edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDataQualityRuleCreated, models.ResourceSettings),
+		).Post("/rules", h.CreateRule)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDataQualityRuleUpdated, models.ResourceSettings),
+		).Put("/rules/{id}", h.UpdateRule)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDataQualityRuleDeleted, models.ResourceSettings),
+		).Delete("/rules/{id}", h.DeleteRule)
+	})
+}
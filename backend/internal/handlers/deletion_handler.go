@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// DeletionHandler handles staged deletion endpoints (undo window)
+type DeletionHandler struct {
+	deletionService services.DeletionManager
+}
+
+// NewDeletionHandler creates a new deletion handler
+func NewDeletionHandler(deletionService services.DeletionManager) *DeletionHandler {
+	return &DeletionHandler{
+		deletionService: deletionService,
+	}
+}
+
+// ListDeletions lists the deletions the current user staged
+// GET /api/deletions?status=pending
+func (h *DeletionHandler) ListDeletions(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.DeletionStatusPending,
+			models.DeletionStatusExecuted,
+			models.DeletionStatusUndone,
+			models.DeletionStatusFailed,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	deletions, totalCount, err := h.deletionService.ListDeletions(r.Context(), tenantID, userID, status, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list deletions")
+		return
+	}
+
+	utils.SuccessWithMeta(w, deletions, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetDeletion retrieves a staged deletion
+// GET /api/deletions/{id}
+func (h *DeletionHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	deletionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid deletion ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.deletionService.GetDeletion(r.Context(), tenantID, userID, deletionID)
+	if err != nil {
+		respondDeletionError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+	})
+}
+
+// Undo cancels a pending deletion before it becomes permanent
+// POST /api/deletions/{id}/undo
+func (h *DeletionHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	deletionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid deletion ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.deletionService.Undo(r.Context(), tenantID, userID, deletionID)
+	if err != nil {
+		respondDeletionError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "Deletion undone",
+	})
+}
+
+// respondDeletionError maps deletion service errors to HTTP responses
+func respondDeletionError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Deletion operation failed")
+}
+
+// RegisterRoutes registers deletion routes. Access is checked per deletion:
+// the requester, or users with the delete permission on the deleted resource.
+func (h *DeletionHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/deletions", func(r chi.Router) {
+		// All deletion routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.ListDeletions)
+		r.Get("/{id}", h.GetDeletion)
+		r.Post("/{id}/undo", h.Undo)
+	})
+}
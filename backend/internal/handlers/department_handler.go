@@ -8,28 +8,39 @@ import (
 	"myerp-v2/internal/middleware"
 	"myerp-v2/internal/models"
 	"myerp-v2/internal/repository"
+	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
 )
 
 // DepartmentHandler handles department management endpoints
 type DepartmentHandler struct {
-	departmentRepo *repository.DepartmentRepository
-	userRepo       *repository.UserRepository
+	departmentRepo     repository.DepartmentStore
+	userRepo           repository.UserStore
+	deletionService    services.DeletionManager
+	permissionService  services.PermissionManager
+	customFieldService services.CustomFieldManager
 }
 
 // NewDepartmentHandler creates a new department handler
 func NewDepartmentHandler(
-	departmentRepo *repository.DepartmentRepository,
-	userRepo *repository.UserRepository,
+	departmentRepo repository.DepartmentStore,
+	userRepo repository.UserStore,
+	deletionService services.DeletionManager,
+	permissionService services.PermissionManager,
+	customFieldService services.CustomFieldManager,
 ) *DepartmentHandler {
 	return &DepartmentHandler{
-		departmentRepo: departmentRepo,
-		userRepo:       userRepo,
+		departmentRepo:     departmentRepo,
+		userRepo:           userRepo,
+		deletionService:    deletionService,
+		permissionService:  permissionService,
+		customFieldService: customFieldService,
 	}
 }
 
-// List retrieves all departments
-// GET /departments
+// List retrieves all departments, optionally only those with given custom
+// field values
+// GET /departments?cf.<key>=...
 func (h *DepartmentHandler) List(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
 	if err != nil {
@@ -37,11 +48,40 @@ func (h *DepartmentHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	departments, err := h.departmentRepo.List(r.Context(), tenantID)
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var filter models.DepartmentFilter
+	filter.CustomFields, err = h.customFieldService.ParseFilter(r.Context(), tenantID, models.NavigationEntityDepartment, r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list departments")
+		return
+	}
+
+	departments, err := h.departmentRepo.List(r.Context(), tenantID, filter)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list departments")
+		return
+	}
+
+	// Leave out restricted departments the user may not view
+	hidden, err := h.permissionService.InaccessibleObjects(r.Context(), tenantID, userID, models.ResourceDepartments, models.ActionView)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to list departments")
 		return
 	}
+	if len(hidden) > 0 {
+		visible := departments[:0]
+		for _, dept := range departments {
+			if !hidden[dept.ID] {
+				visible = append(visible, dept)
+			}
+		}
+		departments = visible
+	}
 
 	utils.Success(w, map[string]interface{}{
 		"departments": departments,
@@ -65,6 +105,10 @@ func (h *DepartmentHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionView) {
+		return
+	}
+
 	department, err := h.departmentRepo.GetWithDetails(r.Context(), tenantID, deptID)
 	if err != nil {
 		utils.NotFound(w, "Department not found")
@@ -129,15 +173,22 @@ func (h *DepartmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	customFields, err := h.customFieldService.ApplyValues(r.Context(), tenantID, models.NavigationEntityDepartment, nil, req.CustomFields, true)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to create department")
+		return
+	}
+
 	// Create department
 	department := &models.Department{
-		Name:        req.Name,
-		Description: req.Description,
-		HeadUserID:  req.HeadUserID,
-		Color:       req.Color,
-		Icon:        req.Icon,
-		Status:      models.DepartmentStatusActive,
-		CreatedBy:   &creatorID,
+		Name:         req.Name,
+		Description:  req.Description,
+		HeadUserID:   req.HeadUserID,
+		Color:        req.Color,
+		Icon:         req.Icon,
+		Status:       models.DepartmentStatusActive,
+		CustomFields: customFields,
+		CreatedBy:    &creatorID,
 	}
 
 	if err := h.departmentRepo.Create(r.Context(), tenantID, department); err != nil {
@@ -148,15 +199,45 @@ func (h *DepartmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Get department with details
 	createdDept, _ := h.departmentRepo.GetWithDetails(r.Context(), tenantID, department.ID)
 
+	middleware.SetAuditResourceID(r.Context(), department.ID)
+	middleware.SetAuditAfter(r.Context(), createdDept)
+
 	utils.Created(w, map[string]interface{}{
 		"department": createdDept,
 		"message":    "Department created successfully",
 	})
 }
 
-// Update updates an existing department
+// Update updates an existing department. Fields left out or null keep their
+// value.
 // PUT /departments/{id}
 func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req models.DepartmentUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.update(w, r, &req, nil)
+}
+
+// Patch applies a JSON merge patch to a department. Fields left out keep
+// their value and null clears the description and head.
+// PATCH /departments/{id}
+func (h *DepartmentHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var req models.DepartmentUpdateRequest
+	nulls, err := utils.ParseMergePatch(r, &req)
+	if err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.update(w, r, &req, nulls)
+}
+
+// update applies an update to a department, clearing the fields named in
+// nulls
+func (h *DepartmentHandler) update(w http.ResponseWriter, r *http.Request, req *models.DepartmentUpdateRequest, nulls map[string]bool) {
 	deptIDStr := chi.URLParam(r, "id")
 	deptID, err := uuid.Parse(deptIDStr)
 	if err != nil {
@@ -164,9 +245,27 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.DepartmentUpdateRequest
-	if err := utils.ParseJSONBody(r, &req); err != nil {
-		utils.BadRequest(w, "Invalid request body")
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateNotNull("name", nulls, "Department name", &errors)
+	utils.ValidateNotNull("color", nulls, "Color", &errors)
+	utils.ValidateNotNull("icon", nulls, "Icon", &errors)
+	utils.ValidateNotNull("status", nulls, "Status", &errors)
+	utils.ValidateNotNull("custom_fields", nulls, "Custom fields", &errors)
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 2, 255, "Department name", &errors)
+	}
+	if req.Color != nil {
+		utils.ValidateRequired("color", *req.Color, "Color", &errors)
+	}
+	if req.Icon != nil {
+		utils.ValidateRequired("icon", *req.Icon, "Icon", &errors)
+	}
+	if req.Status != nil {
+		utils.ValidateEnum("status", *req.Status, []string{models.DepartmentStatusActive, models.DepartmentStatusInactive}, "Status", &errors)
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
 		return
 	}
 
@@ -176,12 +275,17 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionEdit) {
+		return
+	}
+
 	// Get existing department
 	department, err := h.departmentRepo.FindByID(r.Context(), tenantID, deptID)
 	if err != nil {
 		utils.NotFound(w, "Department not found")
 		return
 	}
+	middleware.SetAuditBefore(r.Context(), department)
 
 	// Update fields
 	if req.Name != nil {
@@ -197,9 +301,12 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 		department.Name = *req.Name
 	}
-	if req.Description != nil {
+	if req.Description != nil || nulls["description"] {
 		department.Description = req.Description
 	}
+	if nulls["head_user_id"] {
+		department.HeadUserID = nil
+	}
 	if req.HeadUserID != nil {
 		// Validate head_user_id
 		_, err := h.userRepo.FindByID(r.Context(), tenantID, *req.HeadUserID)
@@ -218,6 +325,11 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Status != nil {
 		department.Status = *req.Status
 	}
+	department.CustomFields, err = h.customFieldService.ApplyValues(r.Context(), tenantID, models.NavigationEntityDepartment, department.CustomFields, req.CustomFields, false)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to update department")
+		return
+	}
 
 	// Update department
 	if err := h.departmentRepo.Update(r.Context(), tenantID, department); err != nil {
@@ -227,6 +339,7 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	// Get updated department with details
 	updatedDept, _ := h.departmentRepo.GetWithDetails(r.Context(), tenantID, deptID)
+	middleware.SetAuditAfter(r.Context(), updatedDept)
 
 	utils.Success(w, map[string]interface{}{
 		"department": updatedDept,
@@ -234,7 +347,7 @@ func (h *DepartmentHandler) Update(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Delete deletes a department
+// Delete schedules a department for deletion (undoable until the window passes)
 // DELETE /departments/{id}
 func (h *DepartmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	deptIDStr := chi.URLParam(r, "id")
@@ -250,17 +363,37 @@ func (h *DepartmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionDelete) {
+		return
+	}
+
+	dept, err := h.departmentRepo.FindByID(r.Context(), tenantID, deptID)
+	if err != nil {
+		utils.NotFound(w, "Department not found")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), dept)
+
 	// Note: Members will have their department_id set to NULL due to ON DELETE SET NULL
 	// Frontend already confirms with user about member count impact
 
-	// Delete department
-	if err := h.departmentRepo.Delete(r.Context(), tenantID, deptID); err != nil {
-		utils.BadRequest(w, err.Error())
+	// Stage the deletion; it is executed once the undo window has passed
+	deletion, err := h.deletionService.Schedule(r.Context(), tenantID, userID, models.DeletionEntityDepartment, dept.ID, dept.Name)
+	if err != nil {
+		respondDeletionError(w, err)
 		return
 	}
+	middleware.SetAuditMetadata(r.Context(), "deletion_id", deletion.ID)
 
 	utils.Success(w, map[string]interface{}{
-		"message": "Department deleted successfully",
+		"deletion": deletion,
+		"message":  "Department scheduled for deletion",
 	})
 }
 
@@ -280,6 +413,10 @@ func (h *DepartmentHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionView) {
+		return
+	}
+
 	members, err := h.departmentRepo.GetMembers(r.Context(), tenantID, deptID)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to get department members")
@@ -292,8 +429,140 @@ func (h *DepartmentHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetACL retrieves who may access a department
+// GET /departments/{id}/acl
+func (h *DepartmentHandler) GetACL(w http.ResponseWriter, r *http.Request) {
+	deptID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid department ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionView) {
+		return
+	}
+	if _, err := h.departmentRepo.FindByID(r.Context(), tenantID, deptID); err != nil {
+		utils.NotFound(w, "Department not found")
+		return
+	}
+
+	acl, err := h.permissionService.GetObjectACL(r.Context(), tenantID, models.ResourceDepartments, deptID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get department access")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"acl": acl,
+	})
+}
+
+// UpdateACL replaces who may access a department: its owner, whether it is
+// restricted, and the users and roles granted access
+// PUT /departments/{id}/acl
+func (h *DepartmentHandler) UpdateACL(w http.ResponseWriter, r *http.Request) {
+	deptID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid department ID")
+		return
+	}
+
+	var req models.ObjectACLUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if !h.authorizeDepartment(w, r, tenantID, deptID, models.ActionEdit) {
+		return
+	}
+	if _, err := h.departmentRepo.FindByID(r.Context(), tenantID, deptID); err != nil {
+		utils.NotFound(w, "Department not found")
+		return
+	}
+
+	before, err := h.permissionService.GetObjectACL(r.Context(), tenantID, models.ResourceDepartments, deptID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get department access")
+		return
+	}
+
+	acl, err := h.permissionService.SetObjectACL(r.Context(), tenantID, userID, models.ResourceDepartments, deptID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "owner not found", "grant user not found", "grant role not found",
+			"each grant needs either a user or a role", "invalid grant action":
+			utils.BadRequest(w, err.Error())
+		default:
+			utils.InternalServerError(w, "Failed to update department access")
+		}
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), deptID)
+	middleware.SetAuditBefore(r.Context(), before)
+	middleware.SetAuditAfter(r.Context(), acl)
+
+	utils.Success(w, map[string]interface{}{
+		"acl":     acl,
+		"message": "Department access updated successfully",
+	})
+}
+
+// authorizeDepartment checks the user may perform an action on a department,
+// which may be restricted to some users. Users who may not even view it are
+// told it does not exist. Responds and returns false if not.
+func (h *DepartmentHandler) authorizeDepartment(w http.ResponseWriter, r *http.Request, tenantID, deptID uuid.UUID, action string) bool {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return false
+	}
+
+	allowed, err := h.permissionService.CanAccessObject(r.Context(), tenantID, userID, models.ResourceDepartments, deptID, action)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to check permissions")
+		return false
+	}
+	if allowed {
+		return true
+	}
+
+	if action != models.ActionView {
+		visible, err := h.permissionService.CanAccessObject(r.Context(), tenantID, userID, models.ResourceDepartments, deptID, models.ActionView)
+		if err != nil {
+			utils.InternalServerError(w, "Failed to check permissions")
+			return false
+		}
+		if visible {
+			utils.Forbidden(w, "Insufficient permissions")
+			return false
+		}
+	}
+	utils.NotFound(w, "Department not found")
+	return false
+}
+
 // RegisterRoutes registers all department routes
-func (h *DepartmentHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+func (h *DepartmentHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, navMiddleware *middleware.NavigationMiddleware) {
 	r.Route("/departments", func(r chi.Router) {
 		// All department routes require authentication
 		r.Use(authMiddleware.Authenticate)
@@ -302,18 +571,36 @@ func (h *DepartmentHandler) RegisterRoutes(r chi.Router, authMiddleware *middlew
 		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionView)).Get("/", h.List)
 
 		// Get single department - requires view permission
-		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionView)).Get("/{id}", h.Get)
+		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionView), navMiddleware.TrackView(models.NavigationEntityDepartment)).Get("/{id}", h.Get)
 
 		// Create department - requires create permission
-		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionCreate)).Post("/", h.Create)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionCreate),
+			auditMiddleware.Record(models.ActionDepartmentCreated, models.ResourceDepartments),
+		).Post("/", h.Create)
 
 		// Update department - requires edit permission
-		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionEdit)).Put("/{id}", h.Update)
+		edit := r.With(
+			permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDepartmentUpdated, models.ResourceDepartments),
+		)
+		edit.Put("/{id}", h.Update)
+		edit.Patch("/{id}", h.Patch)
 
 		// Delete department - requires delete permission
-		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionDelete)).Delete("/{id}", h.Delete)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionDelete),
+			auditMiddleware.Record(models.ActionDepartmentDeleted, models.ResourceDepartments),
+		).Delete("/{id}", h.Delete)
 
 		// Get department members - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionView)).Get("/{id}/members", h.GetMembers)
+
+		// Department access (owner, restriction and grants)
+		r.With(permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionView)).Get("/{id}/acl", h.GetACL)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceDepartments, models.ActionEdit),
+			auditMiddleware.Record(models.ActionObjectACLUpdated, models.ResourceDepartments),
+		).Put("/{id}/acl", h.UpdateACL)
 	})
 }
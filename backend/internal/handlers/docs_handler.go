@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// routeParamPattern matches a chi URL parameter, with its optional regexp
+var routeParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// handlerNamePattern extracts Type.Method from the name of a handler method
+// value, e.g. "myerp-v2/internal/handlers.(*UserHandler).List-fm"
+var handlerNamePattern = regexp.MustCompile(`\(\*?(\w+)\)\.(\w+)-fm$`)
+
+// documentedRoute is an operation of the API docs with who may call it
+type documentedRoute struct {
+	path      string
+	method    string
+	operation *models.OpenAPIOperation
+	access    middleware.RouteAccess
+}
+
+// DocsHandler serves OpenAPI docs of the API, built from the registered
+// routes
+type DocsHandler struct {
+	routes              chi.Routes
+	permissionService   services.PermissionManager
+	entitySchemaService services.EntitySchemaManager
+	config              *config.AppConfig
+
+	once       sync.Once
+	documented []documentedRoute
+}
+
+// NewDocsHandler creates a new docs handler documenting the routes of
+// router. Routes are read on the first request, once all are registered.
+func NewDocsHandler(router chi.Routes, permissionService services.PermissionManager, entitySchemaService services.EntitySchemaManager, cfg *config.AppConfig) *DocsHandler {
+	return &DocsHandler{
+		routes:              router,
+		permissionService:   permissionService,
+		entitySchemaService: entitySchemaService,
+		config:              cfg,
+	}
+}
+
+// GetOpenAPI serves the OpenAPI document of every operation, with the
+// built-in fields of entity types
+// GET /api/docs/openapi.json
+func (h *DocsHandler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, http.StatusOK, h.document(func(middleware.RouteAccess) bool { return true }, h.entitySchemaService.BuiltInSchemas()))
+}
+
+// GetMyOpenAPI serves the OpenAPI document of the operations the current
+// user's roles and permissions allow, so admins exploring the API only see
+// what is relevant to them. Entity types they may view carry the tenant's
+// fields.
+// GET /api/docs/me/openapi.json
+func (h *DocsHandler) GetMyOpenAPI(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	permissions, err := h.permissionService.GetUserPermissions(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get permissions")
+		return
+	}
+
+	roleNames, err := h.permissionService.GetUserRoleNames(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get roles")
+		return
+	}
+	schemas, err := h.entitySchemaService.ListSchemas(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list entity schemas")
+		return
+	}
+
+	roles := make(map[string]bool, len(roleNames))
+	for _, name := range roleNames {
+		roles[name] = true
+	}
+
+	utils.JSON(w, http.StatusOK, h.document(func(access middleware.RouteAccess) bool {
+		if access.PlatformAdmin {
+			return false
+		}
+		if access.Owner && !roles[models.RoleOwner] {
+			return false
+		}
+		if access.Admin && !roles[models.RoleOwner] && !roles[models.RoleAdmin] {
+			return false
+		}
+		if access.Role != "" && !roles[access.Role] {
+			return false
+		}
+		for _, anyOf := range access.Permissions {
+			allowed := false
+			for _, check := range anyOf {
+				if models.PermissionsAllow(permissions, check.Resource, check.Action) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return false
+			}
+		}
+		return true
+	}, schemas))
+}
+
+// document builds the OpenAPI document of the operations allowed accepts,
+// with schemas of the given entity types
+func (h *DocsHandler) document(allowed func(middleware.RouteAccess) bool, schemas []models.EntitySchema) *models.OpenAPIDocument {
+	h.once.Do(h.collectRoutes)
+
+	doc := &models.OpenAPIDocument{
+		OpenAPI: models.OpenAPIVersion,
+		Info: models.OpenAPIInfo{
+			Title:       h.config.Name + " API",
+			Description: "Operations are listed with the permissions they require (x-required-permissions). Responses use the standard envelope: success, data, error and meta.",
+			Version:     "v2",
+		},
+		Servers: []models.OpenAPIServer{{URL: "/api"}},
+		Paths:   map[string]map[string]*models.OpenAPIOperation{},
+		Components: models.OpenAPIComponents{
+			SecuritySchemes: map[string]models.OpenAPISecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+			Schemas: make(map[string]*models.OpenAPISchema, len(schemas)),
+		},
+	}
+
+	for _, schema := range schemas {
+		doc.Components.Schemas[schema.Entity] = openAPISchema(schema)
+	}
+
+	tags := map[string]bool{}
+	for _, route := range h.documented {
+		if !allowed(route.access) {
+			continue
+		}
+		if doc.Paths[route.path] == nil {
+			doc.Paths[route.path] = map[string]*models.OpenAPIOperation{}
+		}
+		doc.Paths[route.path][route.method] = route.operation
+		for _, tag := range route.operation.Tags {
+			if !tags[tag] {
+				tags[tag] = true
+				doc.Tags = append(doc.Tags, models.OpenAPITag{Name: tag})
+			}
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// collectRoutes documents every registered route, in path order
+func (h *DocsHandler) collectRoutes() {
+	var documented []documentedRoute
+	chi.Walk(h.routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		path := openAPIPath(route)
+		access := middleware.DescribeAccess(middlewares)
+		documented = append(documented, documentedRoute{
+			path:      path,
+			method:    strings.ToLower(method),
+			operation: describeOperation(method, path, handler, access),
+			access:    access,
+		})
+		return nil
+	})
+
+	sort.Slice(documented, func(i, j int) bool {
+		if documented[i].path != documented[j].path {
+			return documented[i].path < documented[j].path
+		}
+		return documented[i].method < documented[j].method
+	})
+
+	// A handler may serve several routes; operation IDs must stay unique
+	seen := map[string]int{}
+	for _, route := range documented {
+		id := route.operation.OperationID
+		seen[id]++
+		if seen[id] > 1 {
+			route.operation.OperationID = fmt.Sprintf("%s%d", id, seen[id])
+		}
+	}
+
+	h.documented = documented
+}
+
+// openAPIPath converts a chi route pattern to an OpenAPI path, dropping
+// parameter regexps and naming a trailing wildcard "path"
+func openAPIPath(route string) string {
+	path := routeParamPattern.ReplaceAllString(route, "{$1}")
+	if strings.HasSuffix(path, "/*") {
+		path = strings.TrimSuffix(path, "*") + "{path}"
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// describeOperation documents a route from its handler's name and the
+// access its middlewares enforce
+func describeOperation(method, path string, handler http.Handler, access middleware.RouteAccess) *models.OpenAPIOperation {
+	operation := &models.OpenAPIOperation{
+		OperationID: strings.ToLower(method) + operationName(path),
+		Summary:     method + " " + path,
+		Tags:        []string{strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]},
+		Responses: map[string]models.OpenAPIResponse{
+			"200": {Description: "Success"},
+		},
+	}
+
+	if fn := reflect.ValueOf(handler); fn.Kind() == reflect.Func {
+		if match := handlerNamePattern.FindStringSubmatch(runtime.FuncForPC(fn.Pointer()).Name()); match != nil {
+			operation.OperationID = strings.TrimSuffix(match[1], "Handler") + "." + match[2]
+			operation.Summary = sentenceCase(match[2])
+			if !strings.Contains(operation.Summary, " ") {
+				// e.g. "List" becomes "List users"
+				operation.Summary += " " + operation.Tags[0]
+			}
+		}
+	}
+
+	for _, match := range routeParamPattern.FindAllStringSubmatch(path, -1) {
+		operation.Parameters = append(operation.Parameters, models.OpenAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   map[string]string{"type": "string"},
+		})
+	}
+
+	if access.Authenticated {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+		operation.Responses["401"] = models.OpenAPIResponse{Description: "Authentication required"}
+	}
+
+	var requirements []string
+	for _, anyOf := range access.Permissions {
+		permissions := make([]string, len(anyOf))
+		for i, check := range anyOf {
+			permissions[i] = check.Resource + "." + check.Action
+		}
+		operation.RequiredPermissions = append(operation.RequiredPermissions, permissions)
+		requirements = append(requirements, strings.Join(permissions, " or "))
+	}
+	switch {
+	case access.Owner:
+		operation.RequiredRole = models.RoleOwner
+	case access.Admin:
+		operation.RequiredRole = models.RoleAdmin
+	default:
+		operation.RequiredRole = access.Role
+	}
+	if operation.RequiredRole != "" {
+		requirements = append(requirements, "the "+operation.RequiredRole+" role")
+	}
+	if access.PlatformAdmin {
+		requirements = append(requirements, "platform administration")
+	}
+
+	if access.Restricted() {
+		operation.Description = "Requires " + strings.Join(requirements, " and ") + "."
+		operation.Responses["403"] = models.OpenAPIResponse{Description: "Missing required permission"}
+	}
+
+	return operation
+}
+
+// openAPISchema describes an entity type as an OpenAPI object schema
+func openAPISchema(schema models.EntitySchema) *models.OpenAPISchema {
+	object := &models.OpenAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*models.OpenAPISchema, len(schema.Fields)),
+	}
+	for _, field := range schema.Fields {
+		property := &models.OpenAPISchema{
+			Type:      field.Type,
+			Format:    field.Format,
+			Title:     field.Label,
+			Enum:      field.Options,
+			MaxLength: field.MaxLength,
+			ReadOnly:  field.ReadOnly,
+			WriteOnly: field.WriteOnly,
+			Custom:    field.Custom,
+		}
+		switch field.Type {
+		case models.FieldTypeUUID:
+			property.Type, property.Format = "string", "uuid"
+		case models.FieldTypeDateTime:
+			property.Type, property.Format = "string", "date-time"
+		case models.FieldTypeArray:
+			property.Items = &models.OpenAPISchema{}
+		}
+		object.Properties[field.Name] = property
+		if field.Required {
+			object.Required = append(object.Required, field.Name)
+		}
+	}
+	return object
+}
+
+// operationName turns a path into an identifier, e.g. "/users/{id}/roles"
+// into "UsersIdRoles"
+func operationName(path string) string {
+	var name strings.Builder
+	upper := true
+	for _, c := range path {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		name.WriteRune(c)
+	}
+	return name.String()
+}
+
+// sentenceCase splits a Go identifier into a sentence, e.g.
+// "GetRoleAssignments" into "Get role assignments"
+func sentenceCase(name string) string {
+	var sentence strings.Builder
+	runes := []rune(name)
+	for i, c := range runes {
+		if i > 0 && unicode.IsUpper(c) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			sentence.WriteRune(' ')
+		}
+		if i > 0 && unicode.IsUpper(c) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			c = unicode.ToLower(c)
+		}
+		sentence.WriteRune(c)
+	}
+	return sentence.String()
+}
+
+// RegisterRoutes registers the API docs routes
+func (h *DocsHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/docs", func(r chi.Router) {
+		// Every operation (public)
+		r.Get("/openapi.json", h.GetOpenAPI)
+
+		// Operations the current user can call
+		r.With(authMiddleware.Authenticate).Get("/me/openapi.json", h.GetMyOpenAPI)
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// EmailQueueHandler handles email outbox inspection endpoints
+type EmailQueueHandler struct {
+	emailQueueService services.EmailQueueManager
+}
+
+// NewEmailQueueHandler creates a new email queue handler
+func NewEmailQueueHandler(emailQueueService services.EmailQueueManager) *EmailQueueHandler {
+	return &EmailQueueHandler{
+		emailQueueService: emailQueueService,
+	}
+}
+
+// List retrieves the tenant's queued emails
+// GET /api/admin/email-queue?status=failed
+func (h *EmailQueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{models.EmailStatusPending, models.EmailStatusSent, models.EmailStatusFailed}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	emails, totalCount, err := h.emailQueueService.ListEmails(r.Context(), tenantID, status, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list queued emails")
+		return
+	}
+
+	utils.SuccessWithMeta(w, emails, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetStats counts the tenant's queued emails per status
+// GET /api/admin/email-queue/stats
+func (h *EmailQueueHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	stats, err := h.emailQueueService.GetStats(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get email queue stats")
+		return
+	}
+
+	utils.Success(w, stats)
+}
+
+// Retry requeues a permanently failed email
+// POST /api/admin/email-queue/{id}/retry
+func (h *EmailQueueHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	emailID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid email ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.emailQueueService.RetryEmail(r.Context(), tenantID, userID, emailID); err != nil {
+		utils.WriteError(w, err, "Failed to retry email")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Email requeued for delivery",
+	})
+}
+
+// RegisterRoutes registers email queue routes
+func (h *EmailQueueHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/admin/email-queue", func(r chi.Router) {
+		// All email queue routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.List)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/stats", h.GetStats)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit)).Post("/{id}/retry", h.Retry)
+	})
+}
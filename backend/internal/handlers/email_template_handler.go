@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// EmailTemplateHandler lets admins preview the emails the app sends
+type EmailTemplateHandler struct {
+	emailService          services.EmailManager
+	tenantSettingsService services.TenantSettingsManager
+}
+
+// NewEmailTemplateHandler creates a new email template handler
+func NewEmailTemplateHandler(emailService services.EmailManager, tenantSettingsService services.TenantSettingsManager) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		emailService:          emailService,
+		tenantSettingsService: tenantSettingsService,
+	}
+}
+
+// ListTemplates lists the email templates and the languages they are
+// available in
+// GET /api/email-templates
+func (h *EmailTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, &models.EmailTemplateList{
+		Templates: models.EmailTemplates,
+		Languages: models.TenantLanguages,
+	})
+}
+
+// PreviewTemplate renders an email template with sample data in the tenant's
+// branding. The language query parameter defaults to the tenant's language.
+// GET /api/email-templates/{name}/preview
+func (h *EmailTemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.tenantSettingsService.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get tenant settings")
+		return
+	}
+
+	language := r.URL.Query().Get("language")
+	if language == "" {
+		language = settings.Locale.Language
+	}
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("language", language, models.TenantLanguages, "Language", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	preview, err := h.emailService.Preview(chi.URLParam(r, "name"), language, &settings.Branding)
+	if err != nil {
+		if err.Error() == "email template not found" {
+			utils.NotFound(w, "Email template not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to render email template")
+		return
+	}
+
+	utils.Success(w, preview)
+}
+
+// RegisterRoutes registers the email template routes
+func (h *EmailTemplateHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/email-templates", func(r chi.Router) {
+		// All email template routes require authentication
+		r.Use(authMiddleware.Authenticate)
+		r.Use(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView))
+
+		r.Get("/", h.ListTemplates)
+		r.Get("/{name}/preview", h.PreviewTemplate)
+	})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// emailWebhookMaxBody bounds webhook requests; SendGrid batches many events
+// in one request
+const emailWebhookMaxBody = 5 << 20
+
+// EmailWebhookHandler receives the bounce and complaint webhooks of the
+// email provider
+type EmailWebhookHandler struct {
+	mailer            mail.Mailer
+	emailQueueService services.EmailQueueManager
+}
+
+// NewEmailWebhookHandler creates a new email webhook handler
+func NewEmailWebhookHandler(mailer mail.Mailer, emailQueueService services.EmailQueueManager) *EmailWebhookHandler {
+	return &EmailWebhookHandler{
+		mailer:            mailer,
+		emailQueueService: emailQueueService,
+	}
+}
+
+// HandleWebhook verifies a provider webhook and marks the addresses it
+// reports as undeliverable. Only the configured provider is accepted.
+// POST /api/email/webhooks/{provider}
+func (h *EmailWebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	parser, ok := h.mailer.(mail.WebhookParser)
+	if !ok || chi.URLParam(r, "provider") != h.mailer.Name() {
+		utils.NotFound(w, "Email webhook not found")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, emailWebhookMaxBody))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook payload")
+		return
+	}
+
+	events, err := parser.ParseWebhook(r.Context(), r.Header, body)
+	if errors.Is(err, mail.ErrInvalidSignature) {
+		utils.Unauthorized(w, "Invalid webhook signature")
+		return
+	}
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook payload")
+		return
+	}
+
+	if err := h.emailQueueService.HandleDeliveryEvents(r.Context(), events); err != nil {
+		log.Printf("⚠️  Failed to handle %s webhook: %v", h.mailer.Name(), err)
+		utils.InternalServerError(w, "Failed to handle webhook")
+		return
+	}
+
+	utils.Success(w, map[string]int{"events": len(events)})
+}
+
+// RegisterRoutes registers the email webhook route. Providers sign their
+// requests, so no authentication is required.
+func (h *EmailWebhookHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/email/webhooks/{provider}", h.HandleWebhook)
+}
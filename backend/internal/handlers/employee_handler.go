@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// EmployeeHandler handles HR employee and org chart endpoints
+type EmployeeHandler struct {
+	employeeService   services.EmployeeManager
+	permissionService services.PermissionManager
+}
+
+// NewEmployeeHandler creates a new employee handler
+func NewEmployeeHandler(employeeService services.EmployeeManager, permissionService services.PermissionManager) *EmployeeHandler {
+	return &EmployeeHandler{
+		employeeService:   employeeService,
+		permissionService: permissionService,
+	}
+}
+
+// List lists employees
+// GET /api/employees?page=1&page_size=20&department_id=uuid&manager_id=uuid&status=active&search=jane
+func (h *EmployeeHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	filter := models.EmployeeFilter{
+		Status: r.URL.Query().Get("status"),
+		Search: r.URL.Query().Get("search"),
+	}
+	if value := r.URL.Query().Get("department_id"); value != "" {
+		departmentID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid department ID")
+			return
+		}
+		filter.DepartmentID = &departmentID
+	}
+	if value := r.URL.Query().Get("manager_id"); value != "" {
+		managerID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid manager ID")
+			return
+		}
+		filter.ManagerID = &managerID
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	employees, totalCount, err := h.employeeService.List(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list employees")
+		return
+	}
+
+	if !h.canViewCompensation(r) {
+		for i := range employees {
+			employees[i].SalaryBand = nil
+		}
+	}
+
+	utils.SuccessWithMeta(w, employees, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// Get retrieves an employee
+// GET /api/employees/{id}
+func (h *EmployeeHandler) Get(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid employee ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	employee, err := h.employeeService.Get(r.Context(), tenantID, employeeID)
+	if err != nil {
+		utils.NotFound(w, "Employee not found")
+		return
+	}
+
+	if !h.canViewCompensation(r) {
+		employee.SalaryBand = nil
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"employee": employee,
+	})
+}
+
+// Me retrieves the current user's own employee record, salary band included
+// GET /api/employees/me
+func (h *EmployeeHandler) Me(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	employee, err := h.employeeService.GetByUser(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.NotFound(w, "No employee record is linked to your account")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"employee": employee,
+	})
+}
+
+// Create creates an employee
+// POST /api/employees
+func (h *EmployeeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.EmployeeCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate; names may come from the linked user
+	errors := utils.ValidationErrors{}
+	if req.UserID == nil {
+		utils.ValidateRequired("first_name", req.FirstName, "First name", &errors)
+		utils.ValidateRequired("last_name", req.LastName, "Last name", &errors)
+	}
+	if req.FirstName != "" {
+		utils.ValidateStringLength("first_name", req.FirstName, 1, 100, "First name", &errors)
+	}
+	if req.LastName != "" {
+		utils.ValidateStringLength("last_name", req.LastName, 1, 100, "Last name", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+	utils.ValidateRequired("job_title", req.JobTitle, "Job title", &errors)
+	utils.ValidateStringLength("job_title", req.JobTitle, 1, 255, "Job title", &errors)
+	if req.EmploymentType != "" {
+		utils.ValidateEnum("employment_type", req.EmploymentType, models.EmploymentTypes, "Employment type", &errors)
+	}
+	if req.HireDate.IsZero() {
+		errors.Add("hire_date", "Hire date is required")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	if req.SalaryBand != nil && !h.canViewCompensation(r) {
+		utils.Forbidden(w, "Setting a salary band requires the employees.view_compensation permission")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	creatorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	employee, err := h.employeeService.Create(r.Context(), tenantID, creatorID, &req)
+	if err != nil {
+		respondEmployeeError(w, err, "Failed to create employee")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), employee.ID)
+	middleware.SetAuditAfter(r.Context(), employee)
+
+	utils.Created(w, map[string]interface{}{
+		"employee": employee,
+		"message":  "Employee created successfully",
+	})
+}
+
+// Update updates an employee
+// PUT /api/employees/{id}
+func (h *EmployeeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid employee ID")
+		return
+	}
+
+	var req models.EmployeeUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.FirstName != nil {
+		utils.ValidateStringLength("first_name", *req.FirstName, 1, 100, "First name", &errors)
+	}
+	if req.LastName != nil {
+		utils.ValidateStringLength("last_name", *req.LastName, 1, 100, "Last name", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+	if req.JobTitle != nil {
+		utils.ValidateStringLength("job_title", *req.JobTitle, 1, 255, "Job title", &errors)
+	}
+	if req.EmploymentType != nil {
+		utils.ValidateEnum("employment_type", *req.EmploymentType, models.EmploymentTypes, "Employment type", &errors)
+	}
+	if req.Status != nil {
+		utils.ValidateEnum("status", *req.Status, models.EmployeeStatuses, "Status", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	canViewCompensation := h.canViewCompensation(r)
+	if req.SalaryBand != nil && !canViewCompensation {
+		utils.Forbidden(w, "Setting a salary band requires the employees.view_compensation permission")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.employeeService.Get(r.Context(), tenantID, employeeID)
+	if err != nil {
+		utils.NotFound(w, "Employee not found")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	employee, err := h.employeeService.Update(r.Context(), tenantID, employeeID, &req)
+	if err != nil {
+		respondEmployeeError(w, err, "Failed to update employee")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), employee)
+
+	if !canViewCompensation {
+		employee.SalaryBand = nil
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"employee": employee,
+		"message":  "Employee updated successfully",
+	})
+}
+
+// Delete schedules an employee for deletion. Once the undo window has
+// passed, their direct reports move up to the employee's manager.
+// DELETE /api/employees/{id}
+func (h *EmployeeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid employee ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.employeeService.Delete(r.Context(), tenantID, userID, employeeID)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			utils.NotFound(w, "Employee not found")
+			return
+		}
+		respondDeletionError(w, err)
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), employeeID)
+	middleware.SetAuditMetadata(r.Context(), "deletion_id", deletion.ID)
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "Employee scheduled for deletion",
+	})
+}
+
+// OrgChart returns the reporting hierarchy of current employees, from the
+// top or from root_id down, optionally limited to depth levels
+// GET /api/employees/org-chart?root_id=uuid&depth=3
+func (h *EmployeeHandler) OrgChart(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var rootID *uuid.UUID
+	if value := r.URL.Query().Get("root_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid root employee ID")
+			return
+		}
+		rootID = &id
+	}
+
+	depth := 0
+	if value := r.URL.Query().Get("depth"); value != "" {
+		if depth, err = strconv.Atoi(value); err != nil || depth < 0 {
+			utils.BadRequest(w, "Invalid depth")
+			return
+		}
+	}
+
+	chart, err := h.employeeService.OrgChart(r.Context(), tenantID, rootID, depth)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			utils.NotFound(w, "Employee not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to build org chart")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"org_chart": chart,
+	})
+}
+
+// canViewCompensation checks whether the current user may see salary bands
+func (h *EmployeeHandler) canViewCompensation(r *http.Request) bool {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		return false
+	}
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		return false
+	}
+
+	allowed, err := h.permissionService.HasPermission(r.Context(), tenantID, userID, models.ResourceEmployees, models.ActionViewCompensation)
+	return err == nil && allowed
+}
+
+// respondEmployeeError maps employee service errors to responses
+func respondEmployeeError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "employee not found":
+		utils.NotFound(w, "Employee not found")
+	case "user already has an employee record", "employee number already exists":
+		utils.Conflict(w, err.Error())
+	case "invalid user", "invalid department", "invalid manager",
+		"manager would create a reporting cycle", "first and last name are required",
+		"termination date cannot be before hire date":
+		utils.BadRequest(w, err.Error())
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers employee routes
+func (h *EmployeeHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, navMiddleware *middleware.NavigationMiddleware) {
+	r.Route("/employees", func(r chi.Router) {
+		// All employee routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Own record - every user may see theirs
+		r.Get("/me", h.Me)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionView)).Get("/", h.List)
+		r.With(permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionView)).Get("/org-chart", h.OrgChart)
+		r.With(permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionView), navMiddleware.TrackView(models.NavigationEntityEmployee)).Get("/{id}", h.Get)
+
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionCreate),
+			auditMiddleware.Record(models.ActionEmployeeCreated, models.ResourceEmployees),
+		).Post("/", h.Create)
+
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionEdit),
+			auditMiddleware.Record(models.ActionEmployeeUpdated, models.ResourceEmployees),
+		).Put("/{id}", h.Update)
+
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceEmployees, models.ActionDelete),
+			auditMiddleware.Record(models.ActionEmployeeDeleted, models.ResourceEmployees),
+		).Delete("/{id}", h.Delete)
+	})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// EntityHandler describes the fields of entity types, so clients can render
+// forms and columns, including a tenant's custom fields, without hard-coding
+// them
+type EntityHandler struct {
+	entitySchemaService services.EntitySchemaManager
+}
+
+// NewEntityHandler creates a new entity handler
+func NewEntityHandler(entitySchemaService services.EntitySchemaManager) *EntityHandler {
+	return &EntityHandler{
+		entitySchemaService: entitySchemaService,
+	}
+}
+
+// ListSchemas describes the entity types the current user may view
+// GET /api/entities
+func (h *EntityHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	schemas, err := h.entitySchemaService.ListSchemas(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list entity schemas")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"entities": schemas,
+	})
+}
+
+// GetSchema describes the fields of an entity type
+// GET /api/entities/{type}/schema
+func (h *EntityHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	schema, err := h.entitySchemaService.GetSchema(r.Context(), tenantID, userID, chi.URLParam(r, "type"))
+	if err != nil {
+		utils.WriteError(w, err, "Failed to get entity schema")
+		return
+	}
+
+	utils.Success(w, schema)
+}
+
+// RegisterRoutes registers entity schema routes. Schemas are shown to users
+// who may view the entity type.
+func (h *EntityHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/entities", func(r chi.Router) {
+		// All entity routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.ListSchemas)
+		r.Get("/{type}/schema", h.GetSchema)
+	})
+}
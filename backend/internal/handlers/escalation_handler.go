@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// EscalationHandler handles escalation policy and escalation log endpoints
+type EscalationHandler struct {
+	escalationService services.EscalationManager
+}
+
+// NewEscalationHandler creates a new escalation handler
+func NewEscalationHandler(escalationService services.EscalationManager) *EscalationHandler {
+	return &EscalationHandler{
+		escalationService: escalationService,
+	}
+}
+
+// ListOptions lists what policies can escalate, and the targets and channels
+// steps can use
+// GET /api/escalation-policies/options
+func (h *EscalationHandler) ListOptions(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, map[string]interface{}{
+		"resource_types": h.escalationService.ResourceTypes(),
+		"targets":        models.EscalationTargets,
+		"channels":       models.EscalationChannels,
+	})
+}
+
+// ListPolicies lists the tenant's policies
+// GET /api/escalation-policies
+func (h *EscalationHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := automationPagination(r)
+
+	policies, totalCount, err := h.escalationService.ListPolicies(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list escalation policies")
+		return
+	}
+
+	utils.SuccessWithMeta(w, policies, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetPolicy retrieves a policy
+// GET /api/escalation-policies/{id}
+func (h *EscalationHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid policy ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	policy, err := h.escalationService.GetPolicy(r.Context(), tenantID, policyID)
+	if err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"policy": policy,
+	})
+}
+
+// CreatePolicy creates a policy
+// POST /api/escalation-policies
+func (h *EscalationHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	var req models.EscalationPolicyRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if errors := validateEscalationPolicyRequest(&req); errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	policy, err := h.escalationService.CreatePolicy(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondEscalationPolicyError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), policy.ID)
+	middleware.SetAuditAfter(r.Context(), policy)
+
+	utils.Created(w, map[string]interface{}{
+		"policy": policy,
+	})
+}
+
+// UpdatePolicy replaces a policy's definition
+// PUT /api/escalation-policies/{id}
+func (h *EscalationHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid policy ID")
+		return
+	}
+
+	var req models.EscalationPolicyRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if errors := validateEscalationPolicyRequest(&req); errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.escalationService.GetPolicy(r.Context(), tenantID, policyID)
+	if err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	policy, err := h.escalationService.UpdatePolicy(r.Context(), tenantID, policyID, &req)
+	if err != nil {
+		respondEscalationPolicyError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), policy)
+
+	utils.Success(w, map[string]interface{}{
+		"policy": policy,
+	})
+}
+
+// EnablePolicy enables a policy
+// POST /api/escalation-policies/{id}/enable
+func (h *EscalationHandler) EnablePolicy(w http.ResponseWriter, r *http.Request) {
+	h.setPolicyEnabled(w, r, true)
+}
+
+// DisablePolicy disables a policy. Overdue approvals are no longer escalated
+// by it.
+// POST /api/escalation-policies/{id}/disable
+func (h *EscalationHandler) DisablePolicy(w http.ResponseWriter, r *http.Request) {
+	h.setPolicyEnabled(w, r, false)
+}
+
+// setPolicyEnabled enables or disables the policy in the URL
+func (h *EscalationHandler) setPolicyEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid policy ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	policy, err := h.escalationService.SetPolicyEnabled(r.Context(), tenantID, policyID, enabled)
+	if err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), policy)
+
+	utils.Success(w, map[string]interface{}{
+		"policy": policy,
+	})
+}
+
+// DeletePolicy deletes a policy and its escalation log
+// DELETE /api/escalation-policies/{id}
+func (h *EscalationHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid policy ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.escalationService.GetPolicy(r.Context(), tenantID, policyID)
+	if err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.escalationService.DeletePolicy(r.Context(), tenantID, policyID); err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Escalation policy deleted successfully",
+	})
+}
+
+// ListEscalations lists a policy's escalation log, newest first
+// GET /api/escalation-policies/{id}/escalations
+func (h *EscalationHandler) ListEscalations(w http.ResponseWriter, r *http.Request) {
+	policyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid policy ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := automationPagination(r)
+
+	escalations, totalCount, err := h.escalationService.ListEscalations(r.Context(), tenantID, policyID, pageSize, offset)
+	if err != nil {
+		respondEscalationError(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, escalations, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// validateEscalationPolicyRequest checks the fields of a policy that do not
+// need the database; the service checks the steps in depth
+func validateEscalationPolicyRequest(req *models.EscalationPolicyRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 0, 255, "Name", &errors)
+	utils.ValidateStringLength("description", req.Description, 0, 1000, "Description", &errors)
+	utils.ValidateRequired("resource_type", req.ResourceType, "Resource type", &errors)
+	if len(req.Steps) == 0 {
+		errors.Add("steps", "At least one step is required")
+	}
+	for _, step := range req.Steps {
+		utils.ValidateEnum("steps", step.Target, models.EscalationTargets, "Target", &errors)
+		for _, channel := range step.Channels {
+			utils.ValidateEnum("steps", channel, models.EscalationChannels, "Channel", &errors)
+		}
+	}
+	return errors
+}
+
+// respondEscalationError maps escalation service errors to HTTP responses
+func respondEscalationError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Escalation operation failed")
+}
+
+// respondEscalationPolicyError maps errors from creating or updating a policy
+func respondEscalationPolicyError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Failed to save escalation policy")
+}
+
+// RegisterRoutes registers all escalation policy routes. Policies are managed
+// with the automation permissions.
+func (h *EscalationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/escalation-policies", func(r chi.Router) {
+		// All escalation routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Available resource types, targets and channels - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/options", h.ListOptions)
+
+		// List and get policies - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/", h.ListPolicies)
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/{id}", h.GetPolicy)
+
+		// Create policy - requires create permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionCreate),
+			auditMiddleware.Record(models.ActionEscalationPolicyCreated, models.ResourceAutomation),
+		).Post("/", h.CreatePolicy)
+
+		// Update policy - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionEscalationPolicyUpdated, models.ResourceAutomation),
+		).Put("/{id}", h.UpdatePolicy)
+
+		// Enable/disable policy - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionEscalationPolicyEnabled, models.ResourceAutomation),
+		).Post("/{id}/enable", h.EnablePolicy)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionEdit),
+			auditMiddleware.Record(models.ActionEscalationPolicyDisabled, models.ResourceAutomation),
+		).Post("/{id}/disable", h.DisablePolicy)
+
+		// Delete policy - requires delete permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionDelete),
+			auditMiddleware.Record(models.ActionEscalationPolicyDeleted, models.ResourceAutomation),
+		).Delete("/{id}", h.DeletePolicy)
+
+		// Escalation log - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceAutomation, models.ActionView)).Get("/{id}/escalations", h.ListEscalations)
+	})
+}
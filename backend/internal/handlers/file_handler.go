@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// fileFormOverhead is what a multipart upload may add to the file size
+const fileFormOverhead = 1 << 20
+
+// fileResourceTypePattern matches resource types files are attached to,
+// e.g. invoice or purchase_order
+var fileResourceTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// FileHandler handles file upload, download and delete endpoints
+type FileHandler struct {
+	fileService services.FileManager
+}
+
+// NewFileHandler creates a new file handler
+func NewFileHandler(fileService services.FileManager) *FileHandler {
+	return &FileHandler{
+		fileService: fileService,
+	}
+}
+
+// Upload stores an uploaded file. category is avatar or attachment (the
+// default); attachments may name the record they belong to.
+// POST /api/files (multipart/form-data: file, category, resource_type, resource_id)
+func (h *FileHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	maxSize := h.fileService.MaxFileSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+fileFormOverhead)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+				"file": fmt.Sprintf("File must not exceed %d MB", maxSize>>20),
+			})
+			return
+		}
+		utils.BadRequest(w, "A file is required in the file field")
+		return
+	}
+	defer file.Close()
+
+	upload := models.FileUpload{
+		OriginalName: header.Filename,
+		Size:         header.Size,
+		Category:     r.FormValue("category"),
+	}
+	if upload.Category == "" {
+		upload.Category = models.FileCategoryAttachment
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("category", upload.Category, models.UploadFileCategories, "Category", &errors)
+	if value := r.FormValue("resource_type"); value != "" {
+		if !fileResourceTypePattern.MatchString(value) {
+			errors.Add("resource_type", "Resource type must be lowercase letters, digits and underscores")
+		}
+		upload.ResourceType = &value
+	}
+	if value := r.FormValue("resource_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			errors.Add("resource_id", "Resource ID must be a UUID")
+		}
+		upload.ResourceID = &parsed
+	}
+	if (upload.ResourceType == nil) != (upload.ResourceID == nil) {
+		errors.Add("resource_id", "Resource type and resource ID must be given together")
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	stored, err := h.fileService.Upload(r.Context(), tenantID, userID, &upload, file)
+	if err != nil {
+		respondFileError(w, err, "Failed to upload file")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), stored.ID)
+	middleware.SetAuditAfter(r.Context(), stored)
+
+	utils.Created(w, map[string]interface{}{
+		"file":    stored,
+		"message": "File uploaded successfully",
+	})
+}
+
+// ListFiles lists the files the user may see
+// GET /api/files?page=1&page_size=20&category=attachment&resource_type=invoice&resource_id=uuid&uploaded_by=uuid&include_deleted=true
+func (h *FileHandler) ListFiles(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	filter := models.FileFilter{
+		Category:       r.URL.Query().Get("category"),
+		ResourceType:   r.URL.Query().Get("resource_type"),
+		IncludeDeleted: r.URL.Query().Get("include_deleted") == "true",
+	}
+	if filter.Category != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("category", filter.Category, models.FileCategories, "Category", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+	if value := r.URL.Query().Get("resource_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid resource ID")
+			return
+		}
+		filter.ResourceID = &parsed
+	}
+	if value := r.URL.Query().Get("uploaded_by"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid uploader ID")
+			return
+		}
+		filter.UploadedBy = &parsed
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	files, totalCount, err := h.fileService.List(r.Context(), tenantID, userID, filter)
+	if err != nil {
+		respondFileError(w, err, "Failed to list files")
+		return
+	}
+
+	utils.SuccessWithMeta(w, files, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetFile retrieves a file's metadata
+// GET /api/files/{id}
+func (h *FileHandler) GetFile(w http.ResponseWriter, r *http.Request) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid file ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	file, err := h.fileService.Get(r.Context(), tenantID, userID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to retrieve file")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"file": file,
+	})
+}
+
+// SignedURL returns a URL downloading the file without authentication until
+// it expires, e.g. for <img> tags and links
+// GET /api/files/{id}/url
+func (h *FileHandler) SignedURL(w http.ResponseWriter, r *http.Request) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid file ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	url, err := h.fileService.SignedURL(r.Context(), tenantID, userID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to sign download URL")
+		return
+	}
+
+	utils.Success(w, url)
+}
+
+// Download streams the file's contents
+// GET /api/files/{id}/download
+func (h *FileHandler) Download(w http.ResponseWriter, r *http.Request) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid file ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	file, contents, err := h.fileService.Open(r.Context(), tenantID, userID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to download file")
+		return
+	}
+	defer contents.Close()
+
+	serveFile(w, file, contents)
+}
+
+// DownloadSigned streams a file's contents for a signed download URL. The
+// token authorizes the request instead of a login.
+// GET /api/files/signed/{token}
+func (h *FileHandler) DownloadSigned(w http.ResponseWriter, r *http.Request) {
+	file, contents, err := h.fileService.OpenSigned(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		respondFileError(w, err, "Failed to download file")
+		return
+	}
+	defer contents.Close()
+
+	serveFile(w, file, contents)
+}
+
+// DownloadPublic streams a tenant's logo, which is public so email clients
+// can show it
+// GET /api/files/public/{tenant_id}/{id}
+func (h *FileHandler) DownloadPublic(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "tenant_id"))
+	if err != nil {
+		utils.NotFound(w, "File not found")
+		return
+	}
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.NotFound(w, "File not found")
+		return
+	}
+
+	file, contents, err := h.fileService.OpenPublic(r.Context(), tenantID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to download file")
+		return
+	}
+	defer contents.Close()
+
+	serveFile(w, file, contents)
+}
+
+// DeleteFile deletes a file; it can be restored until the retention passes
+// DELETE /api/files/{id}
+func (h *FileHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid file ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), fileID)
+
+	file, err := h.fileService.Delete(r.Context(), tenantID, userID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to delete file")
+		return
+	}
+
+	middleware.SetAuditBefore(r.Context(), file)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "File deleted successfully",
+	})
+}
+
+// RestoreFile undoes a file's delete
+// POST /api/files/{id}/restore
+func (h *FileHandler) RestoreFile(w http.ResponseWriter, r *http.Request) {
+	fileID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid file ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), fileID)
+
+	file, err := h.fileService.Restore(r.Context(), tenantID, userID, fileID)
+	if err != nil {
+		respondFileError(w, err, "Failed to restore file")
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), file)
+
+	utils.Success(w, map[string]interface{}{
+		"file":    file,
+		"message": "File restored successfully",
+	})
+}
+
+// serveFile writes a file's contents with headers that keep browsers from
+// second-guessing its type
+func serveFile(w http.ResponseWriter, file *models.File, contents io.Reader) {
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", file.ContentDisposition())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, contents)
+}
+
+// respondFileError maps file service errors to responses
+func respondFileError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "file not found":
+		utils.NotFound(w, "File not found")
+	case "file is not available":
+		utils.NotFound(w, "The file's contents are not available")
+	case "insufficient permissions":
+		utils.Forbidden(w, "Insufficient permissions")
+	case "invalid download link":
+		utils.Forbidden(w, "Invalid download link")
+	case "download link has expired":
+		utils.Forbidden(w, "Download link has expired")
+	case "file is not deleted":
+		utils.Conflict(w, err.Error())
+	case "invalid file category", "file is empty", "file is too large", "file type is not allowed",
+		"file content does not match its type":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"file": err.Error()})
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers file routes. Uploaders manage their own files;
+// files.* permissions cover everyone else's.
+func (h *FileHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/files", func(r chi.Router) {
+		// Signed download URLs and logos are public
+		r.Get("/signed/{token}", h.DownloadSigned)
+		r.Get("/public/{tenant_id}/{id}", h.DownloadPublic)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authMiddleware.Authenticate)
+
+			r.With(auditMiddleware.Record(models.ActionFileUploaded, models.ResourceFiles)).Post("/", h.Upload)
+			r.Get("/", h.ListFiles)
+			r.Get("/{id}", h.GetFile)
+			r.Get("/{id}/url", h.SignedURL)
+			r.Get("/{id}/download", h.Download)
+			r.With(auditMiddleware.Record(models.ActionFileDeleted, models.ResourceFiles)).Delete("/{id}", h.DeleteFile)
+			r.With(auditMiddleware.Record(models.ActionFileRestored, models.ResourceFiles)).Post("/{id}/restore", h.RestoreFile)
+		})
+	})
+}
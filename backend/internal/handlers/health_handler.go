@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/diagnostics"
+	"myerp-v2/internal/utils"
+)
+
+// HealthHandler serves the liveness and readiness probes of orchestrators
+// like Kubernetes. They are mounted at the root, without authentication.
+type HealthHandler struct {
+	probe *diagnostics.Probe
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(probe *diagnostics.Probe) *HealthHandler {
+	return &HealthHandler{probe: probe}
+}
+
+// Live reports the process is up and serving requests. It checks no
+// dependency: restarting the server would not bring a database back.
+// GET /health/live
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	utils.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready reports the status and latency of each dependency, answering 503
+// Service Unavailable while a required one is unreachable so the server is
+// taken out of rotation
+// GET /health/ready
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := h.probe.Ready(r.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	utils.JSON(w, status, readiness)
+}
+
+// RegisterRoutes registers the health routes. GET /health stays as a plain
+// liveness check for existing monitors.
+func (h *HealthHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	r.Get("/health/live", h.Live)
+	r.Get("/health/ready", h.Ready)
+}
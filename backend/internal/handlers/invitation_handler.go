@@ -2,23 +2,23 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"myerp-v2/internal/middleware"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
 	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
 )
 
 // InvitationHandler handles team invitation endpoints
 type InvitationHandler struct {
-	invitationService *services.InvitationService
+	invitationService services.InvitationManager
 }
 
 // NewInvitationHandler creates a new invitation handler
-func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+func NewInvitationHandler(invitationService services.InvitationManager) *InvitationHandler {
 	return &InvitationHandler{
 		invitationService: invitationService,
 	}
@@ -74,14 +74,13 @@ func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Requ
 		req.Message,
 	)
 	if err != nil {
-		if err.Error() == "user with this email already exists" {
-			utils.Conflict(w, err.Error())
-			return
-		}
-		utils.InternalServerError(w, "Failed to create invitation")
+		utils.WriteError(w, err, "Failed to create invitation")
 		return
 	}
 
+	middleware.SetAuditResourceID(r.Context(), invitation.ID)
+	middleware.SetAuditAfter(r.Context(), invitation)
+
 	utils.Created(w, map[string]interface{}{
 		"invitation": invitation,
 		"message":    "Invitation sent successfully",
@@ -127,18 +126,24 @@ func (h *InvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Requ
 		req.LastName,
 	)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to accept invitation")
 		return
 	}
 
+	// The new user is both the actor and the created resource
+	middleware.SetAuditActor(r.Context(), user.ID)
+	middleware.SetAuditResourceID(r.Context(), user.ID)
+	middleware.SetAuditAfter(r.Context(), user)
+
 	utils.Success(w, map[string]interface{}{
 		"user":    user,
 		"message": "Invitation accepted successfully. You can now log in.",
 	})
 }
 
-// ListInvitations lists all invitations with filtering
-// GET /api/invitations?status=pending&page=1&page_size=20
+// ListInvitations lists invitations with pagination, sorting and filtering
+// GET /api/invitations?status=pending&invited_by=...&invited_from=...&invited_to=...&page=1&page_size=20&sort=-invited_at
+// GET /api/invitations?cursor=...
 func (h *InvitationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
 	if err != nil {
@@ -146,38 +151,43 @@ func (h *InvitationHandler) ListInvitations(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get query parameters
-	status := r.URL.Query().Get("status")
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	page, err := pagination.Parse(r, services.InvitationListSpec)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list invitations")
+		return
 	}
 
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	filter, err := parseInvitationFilter(r)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list invitations")
+		return
 	}
 
-	offset := (page - 1) * pageSize
-
 	// List invitations
-	invitations, totalCount, err := h.invitationService.ListInvitations(
-		r.Context(),
-		tenantID,
-		status,
-		pageSize,
-		offset,
-	)
+	invitations, totalCount, nextCursor, err := h.invitationService.ListInvitations(r.Context(), tenantID, filter, page)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to list invitations")
 		return
 	}
 
-	meta := utils.NewMeta(page, pageSize, totalCount)
-
 	utils.SuccessWithMeta(w, map[string]interface{}{
 		"invitations": invitations,
-	}, meta)
+	}, page.Meta(totalCount, nextCursor))
+}
+
+// parseInvitationFilter reads the filters of the invitation list
+func parseInvitationFilter(r *http.Request) (models.InvitationFilter, error) {
+	var filter models.InvitationFilter
+	var err error
+
+	if filter.Status, err = pagination.Enum(r, "status", models.InvitationStatuses); err != nil {
+		return filter, err
+	}
+	if filter.InvitedBy, err = pagination.UUID(r, "invited_by"); err != nil {
+		return filter, err
+	}
+	filter.InvitedFrom, filter.InvitedTo, err = pagination.DateRange(r, "invited_from", "invited_to")
+	return filter, err
 }
 
 // GetInvitation retrieves a single invitation
@@ -223,9 +233,13 @@ func (h *InvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if invitation, err := h.invitationService.GetInvitation(r.Context(), tenantID, invitationID); err == nil {
+		middleware.SetAuditBefore(r.Context(), invitation)
+	}
+
 	err = h.invitationService.RevokeInvitation(r.Context(), tenantID, invitationID)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to revoke invitation")
 		return
 	}
 
@@ -258,7 +272,7 @@ func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Requ
 
 	err = h.invitationService.ResendInvitation(r.Context(), tenantID, invitationID, userID)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to resend invitation")
 		return
 	}
 
@@ -268,10 +282,10 @@ func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Requ
 }
 
 // RegisterRoutes registers all invitation routes
-func (h *InvitationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+func (h *InvitationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, planMiddleware *middleware.PlanMiddleware) {
 	r.Route("/invitations", func(r chi.Router) {
 		// Accept invitation (public endpoint - no auth required)
-		r.Post("/accept", h.AcceptInvitation)
+		r.With(auditMiddleware.Record(models.ActionInvitationAccepted, models.ResourceUsers)).Post("/accept", h.AcceptInvitation)
 
 		// All other routes require authentication
 		r.Group(func(r chi.Router) {
@@ -283,14 +297,24 @@ func (h *InvitationHandler) RegisterRoutes(r chi.Router, authMiddleware *middlew
 			// Get single invitation - requires view permission
 			r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView)).Get("/{id}", h.GetInvitation)
 
-			// Create invitation - requires create permission
-			r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate)).Post("/", h.CreateInvitation)
+			// Create invitation - requires create permission and a free seat on the plan
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate),
+				planMiddleware.RequireUserQuota,
+				auditMiddleware.Record(models.ActionInvitationCreated, models.AuditResourceInvitations),
+			).Post("/", h.CreateInvitation)
 
 			// Revoke invitation - requires delete permission
-			r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete)).Delete("/{id}", h.RevokeInvitation)
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete),
+				auditMiddleware.Record(models.ActionInvitationRevoked, models.AuditResourceInvitations),
+			).Delete("/{id}", h.RevokeInvitation)
 
 			// Resend invitation - requires create permission
-			r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate)).Post("/{id}/resend", h.ResendInvitation)
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate),
+				auditMiddleware.Record(models.ActionInvitationResent, models.AuditResourceInvitations),
+			).Post("/{id}/resend", h.ResendInvitation)
 		})
 	})
 }
@@ -0,0 +1,731 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// leaveColorPattern matches calendar colors such as #4F46E5
+var leaveColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// maxLeaveCalendarDays bounds the period of a calendar query
+const maxLeaveCalendarDays = 366
+
+// LeaveHandler handles leave type, balance, request and calendar endpoints
+type LeaveHandler struct {
+	leaveService services.LeaveManager
+}
+
+// NewLeaveHandler creates a new leave handler
+func NewLeaveHandler(leaveService services.LeaveManager) *LeaveHandler {
+	return &LeaveHandler{
+		leaveService: leaveService,
+	}
+}
+
+// ListTypes lists the tenant's leave types, active ones unless
+// include_inactive is set
+// GET /api/leave/types?include_inactive=true
+func (h *LeaveHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("include_inactive") != "true"
+
+	leaveTypes, err := h.leaveService.ListTypes(r.Context(), tenantID, activeOnly)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list leave types")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"leave_types": leaveTypes,
+	})
+}
+
+// CreateType creates a leave type
+// POST /api/leave/types
+func (h *LeaveHandler) CreateType(w http.ResponseWriter, r *http.Request) {
+	var req models.LeaveTypeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateLeaveTypeRequest(&req, true)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	creatorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	leaveType, err := h.leaveService.CreateType(r.Context(), tenantID, creatorID, &req)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to create leave type")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), leaveType.ID)
+	middleware.SetAuditAfter(r.Context(), leaveType)
+
+	utils.Created(w, map[string]interface{}{
+		"leave_type": leaveType,
+		"message":    "Leave type created successfully",
+	})
+}
+
+// UpdateType updates a leave type
+// PUT /api/leave/types/{id}
+func (h *LeaveHandler) UpdateType(w http.ResponseWriter, r *http.Request) {
+	typeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid leave type ID")
+		return
+	}
+
+	var req models.LeaveTypeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateLeaveTypeRequest(&req, false)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.leaveService.GetType(r.Context(), tenantID, typeID)
+	if err != nil {
+		utils.NotFound(w, "Leave type not found")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), typeID)
+	middleware.SetAuditBefore(r.Context(), before)
+
+	leaveType, err := h.leaveService.UpdateType(r.Context(), tenantID, typeID, &req)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to update leave type")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), leaveType)
+
+	utils.Success(w, map[string]interface{}{
+		"leave_type": leaveType,
+		"message":    "Leave type updated successfully",
+	})
+}
+
+// DeleteType deletes a leave type that was never requested
+// DELETE /api/leave/types/{id}
+func (h *LeaveHandler) DeleteType(w http.ResponseWriter, r *http.Request) {
+	typeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid leave type ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.leaveService.DeleteType(r.Context(), tenantID, typeID); err != nil {
+		respondLeaveError(w, err, "Failed to delete leave type")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), typeID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Leave type deleted successfully",
+	})
+}
+
+// Balances retrieves an employee's leave balances in a year (the current one
+// by default). Without employee_id, the current user's own balances.
+// GET /api/leave/balances?employee_id=uuid&year=2026
+func (h *LeaveHandler) Balances(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var employeeID *uuid.UUID
+	if value := r.URL.Query().Get("employee_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid employee ID")
+			return
+		}
+		employeeID = &parsed
+	}
+
+	year := time.Now().UTC().Year()
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || !validLeaveYear(parsed) {
+			utils.BadRequest(w, "Invalid year")
+			return
+		}
+		year = parsed
+	}
+
+	balances, err := h.leaveService.Balances(r.Context(), tenantID, userID, employeeID, year)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to retrieve leave balances")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"year":     year,
+		"balances": balances,
+	})
+}
+
+// AdjustBalance sets the manual adjustment of an employee's balance
+// PUT /api/leave/balances/adjustment
+func (h *LeaveHandler) AdjustBalance(w http.ResponseWriter, r *http.Request) {
+	var req models.LeaveBalanceAdjustRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.EmployeeID == uuid.Nil {
+		errors.Add("employee_id", "Employee is required")
+	}
+	if req.LeaveTypeID == uuid.Nil {
+		errors.Add("leave_type_id", "Leave type is required")
+	}
+	if !validLeaveYear(req.Year) {
+		errors.Add("year", "Year is invalid")
+	}
+	if req.Adjustment < -366 || req.Adjustment > 366 {
+		errors.Add("adjustment", "Adjustment must be between -366 and 366 days")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	balance, err := h.leaveService.AdjustBalance(r.Context(), tenantID, &req)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to adjust leave balance")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), balance.ID)
+	middleware.SetAuditAfter(r.Context(), balance)
+
+	utils.Success(w, map[string]interface{}{
+		"balance": balance,
+		"message": "Leave balance adjusted successfully",
+	})
+}
+
+// ListRequests lists leave requests. Users without leave.view see their own
+// requests and those of the employees they manage.
+// GET /api/leave/requests?page=1&page_size=20&employee_id=uuid&department_id=uuid&status=pending&from=2026-01-01&to=2026-12-31
+func (h *LeaveHandler) ListRequests(w http.ResponseWriter, r *http.Request) {
+	h.listRequests(w, r, h.leaveService.List)
+}
+
+// ListOwnRequests lists the current user's leave requests
+// GET /api/leave/requests/me?page=1&page_size=20&status=approved&from=2026-01-01&to=2026-12-31
+func (h *LeaveHandler) ListOwnRequests(w http.ResponseWriter, r *http.Request) {
+	h.listRequests(w, r, h.leaveService.ListOwn)
+}
+
+// ListApprovals lists the pending requests the current user may decide
+// GET /api/leave/requests/approvals?page=1&page_size=20
+func (h *LeaveHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	h.listRequests(w, r, func(ctx context.Context, tenantID, userID uuid.UUID, _ models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error) {
+		return h.leaveService.ListApprovals(ctx, tenantID, userID, limit, offset)
+	})
+}
+
+// listRequests parses the filters and pagination of a request list
+func (h *LeaveHandler) listRequests(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error)) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	filter := models.LeaveRequestFilter{
+		Status: r.URL.Query().Get("status"),
+	}
+	if filter.Status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", filter.Status, models.LeaveStatuses, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+	if value := r.URL.Query().Get("employee_id"); value != "" {
+		employeeID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid employee ID")
+			return
+		}
+		filter.EmployeeID = &employeeID
+	}
+	if value := r.URL.Query().Get("department_id"); value != "" {
+		departmentID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid department ID")
+			return
+		}
+		filter.DepartmentID = &departmentID
+	}
+	if value := r.URL.Query().Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid from date, expected YYYY-MM-DD")
+			return
+		}
+		filter.From = &from
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid to date, expected YYYY-MM-DD")
+			return
+		}
+		filter.To = &to
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	requests, totalCount, err := list(r.Context(), tenantID, userID, filter, pageSize, offset)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to list leave requests")
+		return
+	}
+
+	utils.SuccessWithMeta(w, requests, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetRequest retrieves a leave request
+// GET /api/leave/requests/{id}
+func (h *LeaveHandler) GetRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid leave request ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	request, err := h.leaveService.Get(r.Context(), tenantID, userID, requestID)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to retrieve leave request")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"leave_request": request,
+	})
+}
+
+// CreateRequest requests leave for the current user, or records it for
+// another employee with leave.manage
+// POST /api/leave/requests
+func (h *LeaveHandler) CreateRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.LeaveRequestCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.LeaveTypeID == uuid.Nil {
+		errors.Add("leave_type_id", "Leave type is required")
+	}
+	if req.StartDate.IsZero() {
+		errors.Add("start_date", "Start date is required")
+	}
+	if req.EndDate.IsZero() {
+		errors.Add("end_date", "End date is required")
+	}
+	if req.Reason != nil {
+		utils.ValidateStringLength("reason", *req.Reason, 0, 1000, "Reason", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	request, err := h.leaveService.Submit(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to request leave")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), request.ID)
+	middleware.SetAuditAfter(r.Context(), request)
+
+	message := "Leave requested successfully"
+	if !request.IsPending() {
+		message = "Leave recorded and approved"
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"leave_request": request,
+		"message":       message,
+	})
+}
+
+// Approve approves a pending leave request
+// POST /api/leave/requests/{id}/approve
+func (h *LeaveHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.leaveService.Approve, "Leave request approved")
+}
+
+// Reject rejects a pending leave request
+// POST /api/leave/requests/{id}/reject
+func (h *LeaveHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.leaveService.Reject, "Leave request rejected")
+}
+
+// decide records a decision with an optional note
+func (h *LeaveHandler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error), message string) {
+	requestID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid leave request ID")
+		return
+	}
+
+	var req models.LeaveDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSONBody(r, &req); err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	if req.Note != nil {
+		errors := utils.ValidationErrors{}
+		utils.ValidateStringLength("note", *req.Note, 0, 1000, "Note", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	request, err := decide(r.Context(), tenantID, userID, requestID, req.Note)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to decide on leave request")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), request.ID)
+	middleware.SetAuditAfter(r.Context(), request)
+
+	utils.Success(w, map[string]interface{}{
+		"leave_request": request,
+		"message":       message,
+	})
+}
+
+// CancelRequest cancels a pending request, or approved leave that has not
+// started
+// POST /api/leave/requests/{id}/cancel
+func (h *LeaveHandler) CancelRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid leave request ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	request, err := h.leaveService.Cancel(r.Context(), tenantID, userID, requestID)
+	if err != nil {
+		respondLeaveError(w, err, "Failed to cancel leave request")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), request.ID)
+	middleware.SetAuditAfter(r.Context(), request)
+
+	utils.Success(w, map[string]interface{}{
+		"leave_request": request,
+		"message":       "Leave request cancelled",
+	})
+}
+
+// Calendar lists the approved leave (and pending with include_pending)
+// overlapping a period of up to a year. Users without leave.view see their
+// department, their direct reports and themselves.
+// GET /api/leave/calendar?from=2026-03-01&to=2026-03-31&department_id=uuid&include_pending=true
+func (h *LeaveHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid from date, expected YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid to date, expected YYYY-MM-DD")
+		return
+	}
+	if to.Before(from) || to.Sub(from) > maxLeaveCalendarDays*24*time.Hour {
+		utils.BadRequest(w, "The period must end after it starts and span at most a year")
+		return
+	}
+
+	filter := models.LeaveCalendarFilter{
+		From:           from,
+		To:             to,
+		IncludePending: r.URL.Query().Get("include_pending") == "true",
+	}
+	if value := r.URL.Query().Get("department_id"); value != "" {
+		departmentID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid department ID")
+			return
+		}
+		filter.DepartmentID = &departmentID
+	}
+
+	entries, err := h.leaveService.Calendar(r.Context(), tenantID, userID, filter)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to load leave calendar")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"entries": entries,
+	})
+}
+
+// validateLeaveTypeRequest validates a leave type request. Code and name are
+// required on create.
+func validateLeaveTypeRequest(req *models.LeaveTypeRequest, create bool) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	if create {
+		if req.Code == nil {
+			errors.Add("code", "Code is required")
+		}
+		if req.Name == nil {
+			errors.Add("name", "Name is required")
+		}
+	}
+	if req.Code != nil {
+		utils.ValidateStringLength("code", *req.Code, 1, 30, "Code", &errors)
+	}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 100, "Name", &errors)
+	}
+	if req.Color != nil && !leaveColorPattern.MatchString(*req.Color) {
+		errors.Add("color", "Color must be a hex color such as #4F46E5")
+	}
+	if req.AnnualAllowance != nil && (*req.AnnualAllowance < 0 || *req.AnnualAllowance > 366) {
+		errors.Add("annual_allowance", "Annual allowance must be between 0 and 366 days")
+	}
+	if req.MaxCarryover != nil && *req.MaxCarryover < 0 {
+		errors.Add("max_carryover", "Maximum carryover cannot be negative")
+	}
+	return errors
+}
+
+// validLeaveYear checks that a balance year is plausible
+func validLeaveYear(year int) bool {
+	return year >= 2000 && year <= time.Now().UTC().Year()+1
+}
+
+// respondLeaveError maps leave service errors to responses
+func respondLeaveError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "leave type not found":
+		utils.NotFound(w, "Leave type not found")
+	case "leave request not found":
+		utils.NotFound(w, "Leave request not found")
+	case "employee not found":
+		utils.NotFound(w, "Employee not found")
+	case "no employee record":
+		utils.NotFound(w, "No employee record is linked to your account")
+	case "insufficient permissions":
+		utils.Forbidden(w, "Insufficient permissions")
+	case "cannot decide on own leave":
+		utils.Forbidden(w, "You cannot decide on your own leave")
+	case "leave type code already exists", "leave type is in use", "leave overlaps another request",
+		"leave request is not pending", "leave request cannot be cancelled", "leave has already started":
+		utils.Conflict(w, err.Error())
+	case "leave type is not active", "leave type does not track balances", "end date is before start date",
+		"leave cannot span two calendar years", "half days must be single-day requests",
+		"leave is outside the employment period", "leave must include at least one working day",
+		"insufficient leave balance":
+		utils.BadRequest(w, err.Error())
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers leave routes. Every employee may request leave and
+// managers decide for their direct reports; leave.* permissions are for HR.
+func (h *LeaveHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/leave", func(r chi.Router) {
+		// All leave routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Route("/types", func(r chi.Router) {
+			r.Get("/", h.ListTypes)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceLeave, models.ActionManageLeave),
+				auditMiddleware.Record(models.ActionLeaveTypeCreated, models.ResourceLeave),
+			).Post("/", h.CreateType)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceLeave, models.ActionManageLeave),
+				auditMiddleware.Record(models.ActionLeaveTypeUpdated, models.ResourceLeave),
+			).Put("/{id}", h.UpdateType)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceLeave, models.ActionManageLeave),
+				auditMiddleware.Record(models.ActionLeaveTypeDeleted, models.ResourceLeave),
+			).Delete("/{id}", h.DeleteType)
+		})
+
+		r.Get("/balances", h.Balances)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceLeave, models.ActionManageLeave),
+			auditMiddleware.Record(models.ActionLeaveBalanceAdjusted, models.ResourceLeave),
+		).Put("/balances/adjustment", h.AdjustBalance)
+
+		r.Route("/requests", func(r chi.Router) {
+			r.Get("/", h.ListRequests)
+			r.Get("/me", h.ListOwnRequests)
+			r.Get("/approvals", h.ListApprovals)
+			r.Get("/{id}", h.GetRequest)
+
+			r.With(auditMiddleware.Record(models.ActionLeaveRequested, models.ResourceLeave)).Post("/", h.CreateRequest)
+			r.With(auditMiddleware.Record(models.ActionLeaveApproved, models.ResourceLeave)).Post("/{id}/approve", h.Approve)
+			r.With(auditMiddleware.Record(models.ActionLeaveRejected, models.ResourceLeave)).Post("/{id}/reject", h.Reject)
+			r.With(auditMiddleware.Record(models.ActionLeaveCancelled, models.ResourceLeave)).Post("/{id}/cancel", h.CancelRequest)
+		})
+
+		r.Get("/calendar", h.Calendar)
+	})
+}
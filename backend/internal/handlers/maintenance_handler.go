@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// MaintenanceHandler handles the tenant's maintenance window
+type MaintenanceHandler struct {
+	maintenanceService services.MaintenanceManager
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(maintenanceService services.MaintenanceManager) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceService: maintenanceService,
+	}
+}
+
+// GetWindow returns the tenant's scheduled or in-progress maintenance window,
+// so every user can see it announced
+// GET /api/settings/maintenance
+func (h *MaintenanceHandler) GetWindow(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	window, err := h.maintenanceService.GetWindow(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get maintenance window")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"window": window,
+	})
+}
+
+// ScheduleWindow schedules the tenant's maintenance window, replacing any
+// scheduled one, and announces it to every active user
+// PUT /api/settings/maintenance
+func (h *MaintenanceHandler) ScheduleWindow(w http.ResponseWriter, r *http.Request) {
+	var req models.MaintenanceWindowRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.StartsAt.IsZero() {
+		errors.Add("starts_at", "Start is required")
+	}
+	if req.EndsAt.IsZero() {
+		errors.Add("ends_at", "End is required")
+	}
+	utils.ValidateStringLength("message", req.Message, 0, 1000, "Message", &errors)
+	for _, path := range req.AllowedPaths {
+		if !strings.HasPrefix(path, "/") {
+			errors.Add("allowed_paths", "Allowed paths must start with /")
+			break
+		}
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.maintenanceService.GetWindow(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get maintenance window")
+		return
+	}
+
+	window, err := h.maintenanceService.ScheduleWindow(r.Context(), tenantID, &req)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to schedule maintenance window")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	if before != nil {
+		middleware.SetAuditBefore(r.Context(), before)
+	}
+	middleware.SetAuditAfter(r.Context(), window)
+
+	utils.Success(w, map[string]interface{}{
+		"window": window,
+	})
+}
+
+// CancelWindow cancels the tenant's maintenance window, or ends it early if
+// it is in progress
+// DELETE /api/settings/maintenance
+func (h *MaintenanceHandler) CancelWindow(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	window, err := h.maintenanceService.CancelWindow(r.Context(), tenantID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to cancel maintenance window")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), window)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Maintenance window cancelled",
+	})
+}
+
+// RegisterRoutes registers maintenance window routes. Every user may see the
+// window; scheduling it requires settings.edit.
+func (h *MaintenanceHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/maintenance", func(r chi.Router) {
+		// All maintenance routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.GetWindow)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionMaintenanceScheduled, models.ResourceSettings),
+		).Put("/", h.ScheduleWindow)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionMaintenanceCancelled, models.ResourceSettings),
+		).Delete("/", h.CancelWindow)
+	})
+}
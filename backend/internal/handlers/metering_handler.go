@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// MeteringHandler handles the tenant's metered usage endpoint
+type MeteringHandler struct {
+	meteringService services.MeteringManager
+}
+
+// NewMeteringHandler creates a new metering handler
+func NewMeteringHandler(meteringService services.MeteringManager) *MeteringHandler {
+	return &MeteringHandler{
+		meteringService: meteringService,
+	}
+}
+
+// GetUsage retrieves the tenant's daily metered usage over a calendar month
+// (UTC) and its totals, this month unless ?month=YYYY-MM is given
+// GET /api/tenant/usage
+func (h *MeteringHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	month := time.Now().UTC()
+	if value := r.URL.Query().Get("month"); value != "" {
+		parsed, err := time.Parse("2006-01", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid month, expected YYYY-MM")
+			return
+		}
+		if parsed.After(month) {
+			utils.BadRequest(w, "Month cannot be in the future")
+			return
+		}
+		month = parsed
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	report, err := h.meteringService.GetUsage(r.Context(), tenantID, month)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get usage")
+		return
+	}
+
+	utils.Success(w, report)
+}
+
+// RegisterRoutes registers the metered usage routes
+func (h *MeteringHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/tenant/usage", func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+
+		// Metered usage - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetUsage)
+	})
+}
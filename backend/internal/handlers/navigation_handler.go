@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// NavigationHandler handles the current user's recently viewed entities and
+// favorites
+type NavigationHandler struct {
+	navigationService services.NavigationManager
+}
+
+// NewNavigationHandler creates a new navigation handler
+func NewNavigationHandler(navigationService services.NavigationManager) *NavigationHandler {
+	return &NavigationHandler{
+		navigationService: navigationService,
+	}
+}
+
+// ListRecent lists the entities the current user viewed recently, most
+// recent first
+// GET /api/recent
+func (h *NavigationHandler) ListRecent(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	views, err := h.navigationService.ListRecent(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list recently viewed")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"recent": views,
+	})
+}
+
+// ListFavorites lists the current user's favorites in the order they were
+// pinned
+// GET /api/favorites
+func (h *NavigationHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	favorites, err := h.navigationService.ListFavorites(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list favorites")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"favorites":    favorites,
+		"entity_types": h.navigationService.EntityTypes(),
+	})
+}
+
+// AddFavorite pins an entity. Pinning an entity twice is not an error.
+// PUT /api/favorites/{entity_type}/{entity_id}
+func (h *NavigationHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	entityType, entityID, ok := h.parseFavoriteEntity(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	favorite, created, err := h.navigationService.AddFavorite(r.Context(), tenantID, userID, entityType, entityID)
+	if err != nil {
+		respondNavigationError(w, err)
+		return
+	}
+
+	if created {
+		utils.Created(w, favorite)
+		return
+	}
+	utils.Success(w, favorite)
+}
+
+// RemoveFavorite unpins an entity
+// DELETE /api/favorites/{entity_type}/{entity_id}
+func (h *NavigationHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	entityType, entityID, ok := h.parseFavoriteEntity(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.navigationService.RemoveFavorite(r.Context(), tenantID, userID, entityType, entityID); err != nil {
+		respondNavigationError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Favorite removed successfully",
+	})
+}
+
+// parseFavoriteEntity reads the entity a favorite route is about
+func (h *NavigationHandler) parseFavoriteEntity(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	entityType := chi.URLParam(r, "entity_type")
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("entity_type", entityType, h.navigationService.EntityTypes(), "Entity type", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return "", uuid.Nil, false
+	}
+
+	entityID, err := uuid.Parse(chi.URLParam(r, "entity_id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid entity ID")
+		return "", uuid.Nil, false
+	}
+
+	return entityType, entityID, true
+}
+
+// navigationUser returns the current tenant and user
+func navigationUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tenantID, userID, true
+}
+
+func respondNavigationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "favorite not found", "entity not found":
+		utils.NotFound(w, err.Error())
+	case "insufficient permissions to view entity":
+		utils.Forbidden(w, err.Error())
+	case "favorite limit reached":
+		utils.Conflict(w, err.Error())
+	default:
+		utils.InternalServerError(w, "Favorite operation failed")
+	}
+}
+
+// RegisterRoutes registers recently viewed and favorite routes. Both are the
+// current user's own; entities they may not view are left out.
+func (h *NavigationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.With(authMiddleware.Authenticate).Get("/recent", h.ListRecent)
+
+	r.Route("/favorites", func(r chi.Router) {
+		// All favorite routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.ListFavorites)
+		r.Put("/{entity_type}/{entity_id}", h.AddFavorite)
+		r.Delete("/{entity_type}/{entity_id}", h.RemoveFavorite)
+	})
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+const notificationReadMaxIDs = 100 // Notifications one read request may mark
+
+// NotificationHandler handles the current user's in-app notifications and
+// notification preferences
+type NotificationHandler struct {
+	notificationService services.NotificationManager
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService services.NotificationManager) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List lists the current user's notifications, newest first
+// GET /api/notifications?unread=true&page=1&page_size=20
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	unreadOnly := false
+	if value := r.URL.Query().Get("unread"); value != "" {
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			utils.BadRequest(w, "Invalid unread value")
+			return
+		}
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	notifications, totalCount, err := h.notificationService.List(r.Context(), tenantID, userID, unreadOnly, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list notifications")
+		return
+	}
+
+	utils.SuccessWithMeta(w, notifications, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// UnreadCount counts the current user's unread notifications
+// GET /api/notifications/unread-count
+func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to count notifications")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"unread_count": unread,
+	})
+}
+
+// MarkRead marks notifications read: those in ids, or all of the current
+// user's notifications when ids is empty
+// POST /api/notifications/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	var req models.NotificationReadRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if len(req.IDs) > notificationReadMaxIDs {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"ids": fmt.Sprintf("At most %d notifications can be marked at once", notificationReadMaxIDs),
+		})
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	unread, err := h.notificationService.MarkRead(r.Context(), tenantID, userID, req.IDs)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to mark notifications read")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"unread_count": unread,
+	})
+}
+
+// MarkUnread marks a notification unread again
+// POST /api/notifications/{id}/unread
+func (h *NotificationHandler) MarkUnread(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid notification ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	unread, err := h.notificationService.MarkUnread(r.Context(), tenantID, userID, notificationID)
+	if err != nil {
+		if err.Error() == "notification not found" {
+			utils.NotFound(w, "Notification not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to mark notification unread")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"unread_count": unread,
+	})
+}
+
+// GetPreferences returns the channels the current user gets each
+// notification category on, and their digest frequency
+// GET /api/notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get notification preferences")
+		return
+	}
+
+	utils.Success(w, prefs)
+}
+
+// UpdatePreferences changes the current user's channels of the categories in
+// the request and their digest frequency
+// PATCH /api/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req models.NotificationPreferencesUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	for category := range req.Channels {
+		utils.ValidateEnum("channels."+category, category, models.NotificationCategories, "Category", &errors)
+	}
+	if req.Digest != nil {
+		utils.ValidateEnum("digest", *req.Digest, models.NotificationDigests, "Digest", &errors)
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to update notification preferences")
+		return
+	}
+
+	utils.Success(w, prefs)
+}
+
+// Stream pushes the current user's notification events as Server-Sent
+// Events: "notification" when one arrives and "unread_count" when the count
+// changes on another device. The first event is the current unread count.
+// GET /api/notifications/stream
+func (h *NotificationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.InternalServerError(w, "Streaming is not supported")
+		return
+	}
+
+	// Subscribe before counting, so no notification in between is missed
+	sub := h.notificationService.Subscribe(r.Context(), tenantID, userID)
+	defer sub.Close()
+
+	unread, err := h.notificationService.CountUnread(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to count notifications")
+		return
+	}
+
+	// Hold no database connection while streaming
+	database.ReleaseSession(r.Context())
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	payload, err := json.Marshal(&services.NotificationEvent{Event: services.NotificationEventUnreadCount, UnreadCount: unread})
+	if err != nil {
+		return
+	}
+	writeNotificationEvent(w, services.NotificationEventUnreadCount, payload)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(jobEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event services.NotificationEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			writeNotificationEvent(w, event.Event, []byte(msg.Payload))
+			flusher.Flush()
+		}
+	}
+}
+
+// writeNotificationEvent writes one Server-Sent Event of a notification stream
+func writeNotificationEvent(w http.ResponseWriter, event string, payload []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
+
+// RegisterRoutes registers the notification routes. Users only ever see
+// their own notifications, so no permission is required.
+func (h *NotificationHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/notifications", func(r chi.Router) {
+		// All notification routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.List)
+		r.Get("/unread-count", h.UnreadCount)
+		r.Get("/stream", h.Stream)
+		r.Get("/preferences", h.GetPreferences)
+		r.Patch("/preferences", h.UpdatePreferences)
+		r.Post("/read", h.MarkRead)
+		r.Post("/{id}/unread", h.MarkUnread)
+	})
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// OffboardingHandler handles the export of a tenant's data and the deletion
+// of the tenant
+type OffboardingHandler struct {
+	offboardingService services.OffboardingManager
+}
+
+// NewOffboardingHandler creates a new offboarding handler
+func NewOffboardingHandler(offboardingService services.OffboardingManager) *OffboardingHandler {
+	return &OffboardingHandler{
+		offboardingService: offboardingService,
+	}
+}
+
+// RequestExport queues the export of all of the tenant's data as a ZIP
+// archive. The response is 202 Accepted with the job; its result links the
+// archive.
+// POST /api/tenant/export
+func (h *OffboardingHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.offboardingService.RequestExport(r.Context(), tenantID, userID)
+	if err != nil {
+		if err.Error() == "job queue is full" {
+			utils.TooManyRequests(w, "Too many jobs are waiting to run, try again later")
+			return
+		}
+		utils.InternalServerError(w, "Failed to queue the export")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditMetadata(r.Context(), "job_id", job.ID)
+
+	utils.Accepted(w, map[string]interface{}{
+		"job":     job,
+		"message": "Export queued",
+	})
+}
+
+// GetExport retrieves an export job, with the archive once it has finished
+// GET /api/tenant/export/{jobID}
+func (h *OffboardingHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.offboardingService.GetExport(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"job": job,
+	})
+}
+
+// GetDeletion retrieves the tenant's requested deletion
+// GET /api/tenant/deletion
+func (h *OffboardingHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.offboardingService.GetDeletion(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get tenant deletion")
+		return
+	}
+	if deletion == nil {
+		utils.NotFound(w, "No tenant deletion requested")
+		return
+	}
+
+	utils.Success(w, deletion)
+}
+
+// RequestDeletion asks to delete the tenant and all of its data. The owner
+// enters their password again and confirms through an emailed link; the
+// deletion is then carried out after the grace period.
+// DELETE /api/tenant
+func (h *OffboardingHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	var req models.TenantDeletionRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+
+	deletion, err := h.offboardingService.RequestDeletion(r.Context(), tenantID, userID, req.Password)
+	if err != nil {
+		h.writeError(w, err, "Failed to request tenant deletion")
+		return
+	}
+
+	utils.Accepted(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "Check your email to confirm the deletion",
+	})
+}
+
+// ConfirmDeletion schedules the tenant's deletion with the emailed token
+// POST /api/tenant/deletion/confirm
+func (h *OffboardingHandler) ConfirmDeletion(w http.ResponseWriter, r *http.Request) {
+	var req models.TenantDeletionConfirmRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	token, err := uuid.Parse(req.Token)
+	if err != nil {
+		utils.BadRequest(w, "Invalid deletion token")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+
+	deletion, err := h.offboardingService.ConfirmDeletion(r.Context(), tenantID, token)
+	if err != nil {
+		h.writeError(w, err, "Failed to confirm tenant deletion")
+		return
+	}
+
+	middleware.SetAuditMetadata(r.Context(), "scheduled_at", deletion.ScheduledAt)
+
+	utils.Success(w, deletion)
+}
+
+// CancelDeletion cancels the tenant's requested or scheduled deletion
+// DELETE /api/tenant/deletion
+func (h *OffboardingHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+
+	if err := h.offboardingService.CancelDeletion(r.Context(), tenantID); err != nil {
+		h.writeError(w, err, "Failed to cancel tenant deletion")
+		return
+	}
+
+	utils.Success(w, map[string]string{
+		"message": "Tenant deletion canceled",
+	})
+}
+
+// writeError writes the response of a failed deletion action
+func (h *OffboardingHandler) writeError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "password is incorrect":
+		utils.BadRequest(w, "Password is incorrect")
+	case "invalid or expired deletion token":
+		utils.BadRequest(w, "Invalid or expired deletion token")
+	case "tenant deletion already scheduled":
+		utils.Conflict(w, "Tenant deletion is already scheduled")
+	case "no tenant deletion requested":
+		utils.NotFound(w, "No tenant deletion requested")
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers the tenant export and deletion routes, for owners
+// only
+func (h *OffboardingHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+		r.Use(permMiddleware.RequireOwner)
+
+		r.With(
+			auditMiddleware.Record(models.ActionTenantDeletionRequested, models.AuditResourceTenant),
+		).Delete("/tenant", h.RequestDeletion)
+
+		r.Route("/tenant/export", func(r chi.Router) {
+			r.With(
+				auditMiddleware.Record(models.ActionTenantExportRequested, models.AuditResourceTenant),
+			).Post("/", h.RequestExport)
+			r.Get("/{jobID}", h.GetExport)
+		})
+
+		r.Route("/tenant/deletion", func(r chi.Router) {
+			r.Get("/", h.GetDeletion)
+			r.With(
+				auditMiddleware.Record(models.ActionTenantDeletionScheduled, models.AuditResourceTenant),
+			).Post("/confirm", h.ConfirmDeletion)
+			r.With(
+				auditMiddleware.Record(models.ActionTenantDeletionCanceled, models.AuditResourceTenant),
+			).Delete("/", h.CancelDeletion)
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PerformanceHandler handles request cost endpoints: the Prometheus metrics of
+// this replica and the slowest endpoints/queries report
+type PerformanceHandler struct {
+	performanceService services.PerformanceManager
+	queueService       services.QueueManager
+	config             *config.MetricsConfig
+}
+
+// NewPerformanceHandler creates a new performance handler
+func NewPerformanceHandler(performanceService services.PerformanceManager, queueService services.QueueManager, cfg *config.MetricsConfig) *PerformanceHandler {
+	return &PerformanceHandler{
+		performanceService: performanceService,
+		queueService:       queueService,
+		config:             cfg,
+	}
+}
+
+// Metrics serves the request cost totals of this replica, followed by the
+// queue depths (read from the database, so the same on every replica), in the
+// Prometheus text format. When METRICS_TOKEN is set, scrapers must send it as
+// a bearer token.
+// GET /metrics
+func (h *PerformanceHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if h.config.Token != "" {
+		expected := "Bearer " + h.config.Token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			utils.Unauthorized(w, "Invalid metrics token")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.WritePrometheus(w)
+
+	// A scrape still gets the request totals when the database is down
+	gauges, err := h.queueService.Gauges(r.Context())
+	if err != nil {
+		log.Printf("⚠️  Failed to measure queues for metrics: %v", err)
+		return
+	}
+	metrics.WriteQueueGauges(w, gauges)
+}
+
+// Report ranks the slowest endpoints and queries over the last hours (24 by
+// default), across all replicas. Sort by total (cumulative time, default),
+// avg or max. Statistics are shared by all tenants.
+// GET /api/admin/performance?hours=24&sort=total&limit=20
+func (h *PerformanceHandler) Report(w http.ResponseWriter, r *http.Request) {
+	hours := 24
+	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+		parsed, err := strconv.Atoi(hoursStr)
+		if err != nil || parsed < 1 {
+			utils.BadRequest(w, "Invalid hours")
+			return
+		}
+		hours = parsed
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 100 {
+			utils.BadRequest(w, "Limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	report, err := h.performanceService.Report(r.Context(), time.Duration(hours)*time.Hour, r.URL.Query().Get("sort"), limit)
+	if err != nil {
+		switch err.Error() {
+		case "window exceeds retention":
+			utils.BadRequest(w, "Hours exceed the retention of request statistics")
+		case "invalid sort":
+			utils.BadRequest(w, "Sort must be one of: "+models.PerformanceSortTotal+", "+models.PerformanceSortAvg+", "+models.PerformanceSortMax)
+		default:
+			utils.InternalServerError(w, "Failed to build performance report")
+		}
+		return
+	}
+
+	utils.Success(w, report)
+}
+
+// RegisterRoutes registers the performance report route. /metrics is mounted
+// at the root by the router, next to /health.
+func (h *PerformanceHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/admin/performance", func(r chi.Router) {
+		// All performance routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.Report)
+	})
+}
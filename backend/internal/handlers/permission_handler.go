@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -12,11 +13,11 @@ import (
 
 // PermissionHandler handles permission endpoints
 type PermissionHandler struct {
-	permissionService *services.PermissionService
+	permissionService services.PermissionManager
 }
 
 // NewPermissionHandler creates a new permission handler
-func NewPermissionHandler(permissionService *services.PermissionService) *PermissionHandler {
+func NewPermissionHandler(permissionService services.PermissionManager) *PermissionHandler {
 	return &PermissionHandler{
 		permissionService: permissionService,
 	}
@@ -52,6 +53,21 @@ func (h *PermissionHandler) ListByCategory(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// GetRegistry retrieves the RBAC registry (modules -> resources -> actions)
+// GET /api/permissions/registry
+func (h *PermissionHandler) GetRegistry(w http.ResponseWriter, r *http.Request) {
+	modules, err := h.permissionService.GetRegistry(r.Context())
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get permission registry")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"modules": modules,
+		"count":   len(modules),
+	})
+}
+
 // Search searches for permissions
 // GET /api/permissions/search?q=keyword
 func (h *PermissionHandler) Search(w http.ResponseWriter, r *http.Request) {
@@ -113,12 +129,21 @@ func (h *PermissionHandler) GetMyPermissions(w http.ResponseWriter, r *http.Requ
 	})
 }
 
-// CheckPermission checks if current user has a specific permission
+// maxPermissionChecks bounds how many pairs one batch check may carry
+const maxPermissionChecks = 200
+
+// CheckPermission checks the current user's permissions. A body with a checks
+// list is answered in one call from the permission cache; a single
+// resource/action body is still accepted.
 // POST /api/permissions/check
 func (h *PermissionHandler) CheckPermission(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Resource string `json:"resource"`
 		Action   string `json:"action"`
+		Checks   []struct {
+			Resource string `json:"resource"`
+			Action   string `json:"action"`
+		} `json:"checks"`
 	}
 
 	if err := utils.ParseJSONBody(r, &req); err != nil {
@@ -126,11 +151,31 @@ func (h *PermissionHandler) CheckPermission(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if req.Resource == "" || req.Action == "" {
+	batch := req.Checks != nil
+	if !batch && (req.Resource == "" || req.Action == "") {
 		utils.BadRequest(w, "Resource and action are required")
 		return
 	}
 
+	errors := utils.ValidationErrors{}
+	if batch {
+		if len(req.Checks) == 0 {
+			errors.Add("checks", "At least one check is required")
+		}
+		if len(req.Checks) > maxPermissionChecks {
+			errors.Add("checks", fmt.Sprintf("At most %d checks are allowed", maxPermissionChecks))
+		}
+		for i, check := range req.Checks {
+			if check.Resource == "" || check.Action == "" {
+				errors.Add(fmt.Sprintf("checks[%d]", i), "Resource and action are required")
+			}
+		}
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
 	userID, err := middleware.GetUserIDFromContext(r.Context())
 	if err != nil {
 		utils.Unauthorized(w, "Authentication required")
@@ -143,16 +188,55 @@ func (h *PermissionHandler) CheckPermission(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	hasPermission, err := h.permissionService.HasPermission(r.Context(), tenantID, userID, req.Resource, req.Action)
+	if !batch {
+		hasPermission, err := h.permissionService.HasPermission(r.Context(), tenantID, userID, req.Resource, req.Action)
+		if err != nil {
+			utils.InternalServerError(w, "Failed to check permission")
+			return
+		}
+
+		utils.Success(w, map[string]interface{}{
+			"has_permission": hasPermission,
+			"resource":       req.Resource,
+			"action":         req.Action,
+		})
+		return
+	}
+
+	checks := make([]services.PermissionCheck, len(req.Checks))
+	for i, check := range req.Checks {
+		checks[i] = services.PermissionCheck{Resource: check.Resource, Action: check.Action}
+	}
+
+	allowed, err := h.permissionService.CheckPermissions(r.Context(), tenantID, userID, checks)
 	if err != nil {
-		utils.InternalServerError(w, "Failed to check permission")
+		utils.InternalServerError(w, "Failed to check permissions")
 		return
 	}
 
+	version, err := h.permissionService.GetPermissionsVersion(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to check permissions")
+		return
+	}
+
+	// Results come both in request order and keyed by "resource.action", so
+	// a frontend can look up a menu entry's permission directly
+	results := make([]map[string]interface{}, len(checks))
+	permissions := make(map[string]bool, len(checks))
+	for i, check := range checks {
+		results[i] = map[string]interface{}{
+			"resource": check.Resource,
+			"action":   check.Action,
+			"allowed":  allowed[i],
+		}
+		permissions[check.Resource+"."+check.Action] = allowed[i]
+	}
+
 	utils.Success(w, map[string]interface{}{
-		"has_permission": hasPermission,
-		"resource":       req.Resource,
-		"action":         req.Action,
+		"results":             results,
+		"permissions":         permissions,
+		"permissions_version": version,
 	})
 }
 
@@ -174,10 +258,13 @@ func (h *PermissionHandler) RegisterRoutes(r chi.Router, authMiddleware *middlew
 		// Permission stats - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/stats", h.GetStats)
 
+		// Registry - no special permission needed (used to build navigation)
+		r.Get("/registry", h.GetRegistry)
+
 		// Get current user's permissions - no special permission needed
 		r.Get("/me", h.GetMyPermissions)
 
-		// Check permission - no special permission needed
+		// Check one or many permissions - no special permission needed
 		r.Post("/check", h.CheckPermission)
 	})
 }
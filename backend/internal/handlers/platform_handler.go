@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PlatformHandler handles the platform administration endpoints, for
+// operators managing tenants across the platform
+type PlatformHandler struct {
+	platformService services.PlatformManager
+}
+
+// NewPlatformHandler creates a new platform handler
+func NewPlatformHandler(platformService services.PlatformManager) *PlatformHandler {
+	return &PlatformHandler{
+		platformService: platformService,
+	}
+}
+
+// ListTenants lists the tenants of the platform, newest first
+// GET /api/admin/tenants
+func (h *PlatformHandler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	filter := models.PlatformTenantFilter{
+		Status:   r.URL.Query().Get("status"),
+		PlanTier: r.URL.Query().Get("plan_tier"),
+		Search:   strings.TrimSpace(r.URL.Query().Get("search")),
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	tenants, total, err := h.platformService.ListTenants(r.Context(), filter, page, pageSize)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list tenants")
+		return
+	}
+
+	utils.SuccessWithMeta(w, tenants, utils.NewMeta(page, pageSize, total))
+}
+
+// GetTenantHealth retrieves a tenant's plan and usage, quota alerts and
+// email delivery
+// GET /api/admin/tenants/{id}/health
+func (h *PlatformHandler) GetTenantHealth(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid tenant ID")
+		return
+	}
+
+	health, err := h.platformService.GetTenantHealth(r.Context(), tenantID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to get tenant health")
+		return
+	}
+
+	utils.Success(w, health)
+}
+
+// SuspendTenant makes a tenant read-only
+// POST /api/admin/tenants/{id}/suspend
+func (h *PlatformHandler) SuspendTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid tenant ID")
+		return
+	}
+
+	var req models.PlatformSuspendRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	if !slices.Contains(models.TenantSuspensionReasons, req.Reason) {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"reason": "Reason must be payment_overdue, terms_violation or requested",
+		})
+		return
+	}
+
+	admin, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	tenant, err := h.platformService.SuspendTenant(r.Context(), admin, tenantID, req.Reason)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to suspend tenant")
+		return
+	}
+	tenant.Settings = nil
+
+	utils.Success(w, tenant)
+}
+
+// ActivateTenant lifts a tenant's suspension
+// POST /api/admin/tenants/{id}/activate
+func (h *PlatformHandler) ActivateTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid tenant ID")
+		return
+	}
+
+	admin, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	tenant, err := h.platformService.ActivateTenant(r.Context(), admin, tenantID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to activate tenant")
+		return
+	}
+	tenant.Settings = nil
+
+	utils.Success(w, tenant)
+}
+
+// ResendVerification sends a tenant awaiting email verification a new
+// verification link
+// POST /api/admin/tenants/{id}/resend-verification
+func (h *PlatformHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid tenant ID")
+		return
+	}
+
+	admin, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.platformService.ResendVerification(r.Context(), admin, tenantID); err != nil {
+		utils.WriteError(w, err, "Failed to resend verification email")
+		return
+	}
+
+	utils.Success(w, map[string]string{
+		"message": "Verification email sent",
+	})
+}
+
+// ForcePasswordReset makes a user of a tenant choose a new password: their
+// password stops working, their sessions are revoked and they are emailed
+// a reset link
+// POST /api/admin/tenants/{id}/password-resets
+func (h *PlatformHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid tenant ID")
+		return
+	}
+
+	var req models.PlatformPasswordResetRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+			"email": "Email is required",
+		})
+		return
+	}
+
+	admin, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.platformService.ForcePasswordReset(r.Context(), admin, tenantID, req.Email); err != nil {
+		utils.WriteError(w, err, "Failed to force password reset")
+		return
+	}
+
+	utils.Success(w, map[string]string{
+		"message": "Password reset email sent",
+	})
+}
+
+// GetStats retrieves statistics across all tenants
+// GET /api/admin/stats
+func (h *PlatformHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.platformService.GetStats(r.Context())
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get platform statistics")
+		return
+	}
+
+	utils.Success(w, stats)
+}
+
+// RegisterRoutes registers the platform administration routes. They are
+// not tenant-scoped: a platform admin signs in to their own tenant and acts
+// on any other.
+func (h *PlatformHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, platformMiddleware *middleware.PlatformMiddleware) {
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware.Authenticate)
+		r.Use(platformMiddleware.RequirePlatformAdmin)
+
+		r.Route("/admin/tenants", func(r chi.Router) {
+			r.Get("/", h.ListTenants)
+			r.Get("/{id}/health", h.GetTenantHealth)
+			r.Post("/{id}/suspend", h.SuspendTenant)
+			r.Post("/{id}/activate", h.ActivateTenant)
+			r.Post("/{id}/resend-verification", h.ResendVerification)
+			r.Post("/{id}/password-resets", h.ForcePasswordReset)
+		})
+		r.Get("/admin/stats", h.GetStats)
+	})
+}
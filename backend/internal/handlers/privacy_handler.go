@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PrivacyHandler handles the tenant's privacy settings
+type PrivacyHandler struct {
+	usageService services.UsageManager
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(usageService services.UsageManager) *PrivacyHandler {
+	return &PrivacyHandler{
+		usageService: usageService,
+	}
+}
+
+// GetSettings retrieves the tenant's privacy settings
+// GET /api/settings/privacy
+func (h *PrivacyHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.usageService.GetPrivacySettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get privacy settings")
+		return
+	}
+
+	utils.Success(w, settings)
+}
+
+// UpdateSettings changes whether the tenant shares its anonymized feature
+// usage. Opting out deletes the usage collected so far.
+// PUT /api/settings/privacy
+func (h *PrivacyHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.PrivacySettingsUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.usageService.GetPrivacySettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get privacy settings")
+		return
+	}
+
+	settings, err := h.usageService.UpdatePrivacySettings(r.Context(), tenantID, &req)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to update privacy settings")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), map[string]interface{}{"usage_analytics": before.UsageAnalytics})
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"usage_analytics": settings.UsageAnalytics})
+
+	utils.Success(w, settings)
+}
+
+// RegisterRoutes registers privacy settings routes
+func (h *PrivacyHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/privacy", func(r chi.Router) {
+		// All privacy settings routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetSettings)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionPrivacySettingsUpdated, models.ResourceSettings),
+		).Put("/", h.UpdateSettings)
+	})
+}
@@ -0,0 +1,657 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PurchaseOrderHandler handles purchase order, goods receipt and supplier invoice endpoints
+type PurchaseOrderHandler struct {
+	purchaseOrderService services.PurchaseOrderManager
+	escalationService    services.EscalationManager
+}
+
+// NewPurchaseOrderHandler creates a new purchase order handler
+func NewPurchaseOrderHandler(purchaseOrderService services.PurchaseOrderManager, escalationService services.EscalationManager) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		purchaseOrderService: purchaseOrderService,
+		escalationService:    escalationService,
+	}
+}
+
+// List lists purchase orders
+// GET /api/purchasing/orders?page=1&page_size=20&status=sent&supplier_id=...
+func (h *PurchaseOrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := purchasingPagination(r)
+
+	var supplierID *uuid.UUID
+	if value := r.URL.Query().Get("supplier_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid supplier ID")
+			return
+		}
+		supplierID = &id
+	}
+
+	orders, totalCount, err := h.purchaseOrderService.ListOrders(r.Context(), tenantID, supplierID, r.URL.Query().Get("status"), pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list purchase orders")
+		return
+	}
+
+	utils.SuccessWithMeta(w, orders, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// Get retrieves a purchase order with its lines
+// GET /api/purchasing/orders/{id}
+func (h *PurchaseOrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	po, err := h.purchaseOrderService.GetOrder(r.Context(), tenantID, poID)
+	if err != nil {
+		utils.NotFound(w, "Purchase order not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"purchase_order": po,
+	})
+}
+
+// Create creates a draft purchase order
+// POST /api/purchasing/orders
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.PurchaseOrderCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.SupplierID == uuid.Nil {
+		errors.Add("supplier_id", "Supplier is required")
+	}
+	if req.Currency != "" {
+		utils.ValidateStringLength("currency", req.Currency, 3, 3, "Currency", &errors)
+	}
+	validatePurchaseOrderLines(req.Lines, &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	po, err := h.purchaseOrderService.CreateOrder(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "supplier not found", "supplier is inactive":
+			utils.BadRequest(w, err.Error())
+		default:
+			utils.InternalServerError(w, "Failed to create purchase order")
+		}
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"purchase_order": po,
+		"message":        "Purchase order created successfully",
+	})
+}
+
+// Update updates a draft purchase order
+// PUT /api/purchasing/orders/{id}
+func (h *PurchaseOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	var req models.PurchaseOrderUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Currency != nil {
+		utils.ValidateStringLength("currency", *req.Currency, 3, 3, "Currency", &errors)
+	}
+	if req.Lines != nil {
+		validatePurchaseOrderLines(*req.Lines, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	po, err := h.purchaseOrderService.UpdateOrder(r.Context(), tenantID, userID, poID, &req)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"purchase_order": po,
+		"message":        "Purchase order updated successfully",
+	})
+}
+
+// Delete schedules a draft purchase order for deletion (undoable until the window passes)
+// DELETE /api/purchasing/orders/{id}
+func (h *PurchaseOrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.purchaseOrderService.DeleteOrder(r.Context(), tenantID, userID, poID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "Purchase order scheduled for deletion",
+	})
+}
+
+// Send marks a draft purchase order as sent to the supplier
+// POST /api/purchasing/orders/{id}/send
+func (h *PurchaseOrderHandler) Send(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.purchaseOrderService.SendOrder, "Purchase order sent")
+}
+
+// Close closes a purchase order
+// POST /api/purchasing/orders/{id}/close
+func (h *PurchaseOrderHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.purchaseOrderService.CloseOrder, "Purchase order closed")
+}
+
+// Cancel cancels a purchase order that has not received goods
+// POST /api/purchasing/orders/{id}/cancel
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.purchaseOrderService.CancelOrder, "Purchase order cancelled")
+}
+
+// ListReceipts lists goods receipts for a purchase order
+// GET /api/purchasing/orders/{id}/receipts
+func (h *PurchaseOrderHandler) ListReceipts(w http.ResponseWriter, r *http.Request) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	receipts, err := h.purchaseOrderService.ListReceipts(r.Context(), tenantID, poID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"receipts": receipts,
+	})
+}
+
+// Receive records goods received against a purchase order
+// POST /api/purchasing/orders/{id}/receipts
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	var req models.GoodsReceiptCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if len(req.Lines) == 0 {
+		errors.Add("lines", "At least one line is required")
+	}
+	for i, line := range req.Lines {
+		field := "lines." + strconv.Itoa(i)
+		if line.PurchaseOrderLineID == uuid.Nil {
+			errors.Add(field+".purchase_order_line_id", "Purchase order line is required")
+		}
+		if line.Quantity <= 0 {
+			errors.Add(field+".quantity", "Quantity must be greater than zero")
+		}
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	receipt, err := h.purchaseOrderService.ReceiveGoods(r.Context(), tenantID, userID, poID, &req)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"receipt": receipt,
+		"message": "Goods received successfully",
+	})
+}
+
+// ListInvoices lists supplier invoices
+// GET /api/purchasing/invoices?page=1&page_size=20&purchase_order_id=...&match_status=exception&status=pending
+func (h *PurchaseOrderHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := purchasingPagination(r)
+
+	var poID *uuid.UUID
+	if value := r.URL.Query().Get("purchase_order_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid purchase order ID")
+			return
+		}
+		poID = &id
+	}
+
+	invoices, totalCount, err := h.purchaseOrderService.ListSupplierInvoices(
+		r.Context(),
+		tenantID,
+		poID,
+		r.URL.Query().Get("match_status"),
+		r.URL.Query().Get("status"),
+		pageSize,
+		offset,
+	)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list supplier invoices")
+		return
+	}
+
+	utils.SuccessWithMeta(w, invoices, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetInvoice retrieves a supplier invoice with its lines, match details and
+// the escalations of its approval
+// GET /api/purchasing/invoices/{id}
+func (h *PurchaseOrderHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	invoiceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid invoice ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	invoice, err := h.purchaseOrderService.GetSupplierInvoice(r.Context(), tenantID, invoiceID)
+	if err != nil {
+		utils.NotFound(w, "Supplier invoice not found")
+		return
+	}
+
+	escalations, err := h.escalationService.ListForResource(r.Context(), tenantID, models.ApprovalResourceSupplierInvoice, invoice.ID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get supplier invoice")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"invoice":     invoice,
+		"escalations": escalations,
+	})
+}
+
+// CreateInvoice records a supplier invoice and three-way matches it
+// POST /api/purchasing/invoices
+func (h *PurchaseOrderHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	var req models.SupplierInvoiceCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.PurchaseOrderID == uuid.Nil {
+		errors.Add("purchase_order_id", "Purchase order is required")
+	}
+	req.InvoiceNumber = strings.TrimSpace(req.InvoiceNumber)
+	utils.ValidateRequired("invoice_number", req.InvoiceNumber, "Invoice number", &errors)
+	utils.ValidateStringLength("invoice_number", req.InvoiceNumber, 1, 100, "Invoice number", &errors)
+	if req.InvoiceDate.IsZero() {
+		errors.Add("invoice_date", "Invoice date is required")
+	}
+	if req.TaxTotal < 0 {
+		errors.Add("tax_total", "Tax total cannot be negative")
+	}
+	if len(req.Lines) == 0 {
+		errors.Add("lines", "At least one line is required")
+	}
+	for i, line := range req.Lines {
+		field := "lines." + strconv.Itoa(i)
+		if line.PurchaseOrderLineID == uuid.Nil {
+			errors.Add(field+".purchase_order_line_id", "Purchase order line is required")
+		}
+		if line.Quantity <= 0 {
+			errors.Add(field+".quantity", "Quantity must be greater than zero")
+		}
+		if line.UnitPrice < 0 {
+			errors.Add(field+".unit_price", "Unit price cannot be negative")
+		}
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	invoice, err := h.purchaseOrderService.RecordSupplierInvoice(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		if err.Error() == "invoice number already recorded for this supplier" {
+			utils.Conflict(w, err.Error())
+			return
+		}
+		respondPurchasingError(w, err)
+		return
+	}
+
+	message := "Supplier invoice recorded and matched"
+	if invoice.MatchStatus != models.MatchStatusMatched {
+		message = "Supplier invoice recorded with match exceptions"
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"invoice": invoice,
+		"message": message,
+	})
+}
+
+// ApproveInvoice approves a pending supplier invoice
+// POST /api/purchasing/invoices/{id}/approve?force=true
+func (h *PurchaseOrderHandler) ApproveInvoice(w http.ResponseWriter, r *http.Request) {
+	invoiceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid invoice ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	// force approves invoices that failed three-way matching
+	force := r.URL.Query().Get("force") == "true"
+
+	invoice, err := h.purchaseOrderService.ApproveSupplierInvoice(r.Context(), tenantID, userID, invoiceID, force)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"invoice": invoice,
+		"message": "Supplier invoice approved",
+	})
+}
+
+// RejectInvoice rejects a pending supplier invoice
+// POST /api/purchasing/invoices/{id}/reject
+func (h *PurchaseOrderHandler) RejectInvoice(w http.ResponseWriter, r *http.Request) {
+	invoiceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid invoice ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	invoice, err := h.purchaseOrderService.RejectSupplierInvoice(r.Context(), tenantID, userID, invoiceID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"invoice": invoice,
+		"message": "Supplier invoice rejected",
+	})
+}
+
+// changeStatus applies a purchase order status transition
+func (h *PurchaseOrderHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	transition func(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error),
+	message string,
+) {
+	poID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid purchase order ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	po, err := transition(r.Context(), tenantID, userID, poID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"purchase_order": po,
+		"message":        message,
+	})
+}
+
+// purchasingPagination reads page and page_size query parameters
+func purchasingPagination(r *http.Request) (page, pageSize, offset int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// validatePurchaseOrderLines validates request line items
+func validatePurchaseOrderLines(lines []models.PurchaseOrderLineRequest, errors *utils.ValidationErrors) {
+	for i, line := range lines {
+		field := "lines." + strconv.Itoa(i)
+		if line.Description == "" {
+			errors.Add(field+".description", "Description is required")
+		}
+		if line.Quantity <= 0 {
+			errors.Add(field+".quantity", "Quantity must be greater than zero")
+		}
+		if line.UnitPrice < 0 {
+			errors.Add(field+".unit_price", "Unit price cannot be negative")
+		}
+		if line.TaxRate < 0 {
+			errors.Add(field+".tax_rate", "Tax rate cannot be negative")
+		}
+	}
+}
+
+// respondPurchasingError maps purchasing service errors to HTTP responses
+func respondPurchasingError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Purchasing operation failed")
+}
+
+// RegisterRoutes registers purchase order and supplier invoice routes
+func (h *PurchaseOrderHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, navMiddleware *middleware.NavigationMiddleware) {
+	r.Route("/purchasing/orders", func(r chi.Router) {
+		// All purchase order routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/", h.List)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionCreate)).Post("/", h.Create)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView), navMiddleware.TrackView(models.NavigationEntityPurchaseOrder)).Get("/{id}", h.Get)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionEdit)).Put("/{id}", h.Update)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionDelete)).Delete("/{id}", h.Delete)
+
+		// Status transitions
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionManageStatus)).Post("/{id}/send", h.Send)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionManageStatus)).Post("/{id}/close", h.Close)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionManageStatus)).Post("/{id}/cancel", h.Cancel)
+
+		// Receiving
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/{id}/receipts", h.ListReceipts)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionReceive)).Post("/{id}/receipts", h.Receive)
+	})
+
+	r.Route("/purchasing/invoices", func(r chi.Router) {
+		// All supplier invoice routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/", h.ListInvoices)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionCreate)).Post("/", h.CreateInvoice)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView), navMiddleware.TrackView(models.NavigationEntitySupplierInvoice)).Get("/{id}", h.GetInvoice)
+
+		// Approval
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionApprove)).Post("/{id}/approve", h.ApproveInvoice)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionApprove)).Post("/{id}/reject", h.RejectInvoice)
+	})
+}
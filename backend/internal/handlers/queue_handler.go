@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// QueueHandler handles queue depth endpoints
+type QueueHandler struct {
+	queueService services.QueueManager
+}
+
+// NewQueueHandler creates a new queue handler
+func NewQueueHandler(queueService services.QueueManager) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+	}
+}
+
+// List retrieves the depth, dead letters and limits of the email, webhook and
+// async job queues. Queues are shared by all tenants.
+// GET /api/admin/queues
+func (h *QueueHandler) List(w http.ResponseWriter, r *http.Request) {
+	queues, err := h.queueService.Stats(r.Context())
+	if err != nil {
+		utils.InternalServerError(w, "Failed to measure queues")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"queues": queues,
+	})
+}
+
+// RegisterRoutes registers queue routes
+func (h *QueueHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/admin/queues", func(r chi.Router) {
+		// All queue routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.List)
+	})
+}
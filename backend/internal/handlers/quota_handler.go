@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// QuotaHandler handles plan quota usage and alert endpoints
+type QuotaHandler struct {
+	quotaService services.QuotaManager
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotaService services.QuotaManager) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// GetStatus retrieves the tenant's usage of its plan quotas and open alerts
+// GET /api/quotas
+func (h *QuotaHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status, err := h.quotaService.GetStatus(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get quotas")
+		return
+	}
+
+	utils.Success(w, status)
+}
+
+// AcknowledgeAlert stops the reminders of an alert. The alert stays open
+// until usage drops back under its threshold.
+// POST /api/quotas/alerts/{id}/acknowledge
+func (h *QuotaHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	alertID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid alert ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	alert, err := h.quotaService.AcknowledgeAlert(r.Context(), tenantID, alertID, userID)
+	if err != nil {
+		if err.Error() == "quota alert not found" {
+			utils.NotFound(w, err.Error())
+			return
+		}
+		utils.InternalServerError(w, "Failed to acknowledge quota alert")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), alert.ID)
+	middleware.SetAuditAfter(r.Context(), alert)
+
+	utils.Success(w, map[string]interface{}{
+		"alert": alert,
+	})
+}
+
+// RegisterRoutes registers the quota routes. Quotas are managed with the
+// settings permissions.
+func (h *QuotaHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/quotas", func(r chi.Router) {
+		// All quota routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Usage and alerts - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetStatus)
+
+		// Acknowledgment - requires settings edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionQuotaAlertAcknowledged, models.ResourceSettings),
+		).Post("/alerts/{id}/acknowledge", h.AcknowledgeAlert)
+	})
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -14,21 +20,27 @@ import (
 
 // RoleHandler handles role management endpoints
 type RoleHandler struct {
-	roleRepo         *repository.RoleRepository
-	userRoleRepo     *repository.UserRoleRepository
-	permissionService *services.PermissionService
+	roleRepo         repository.RoleStore
+	userRoleRepo     repository.UserRoleStore
+	permissionService services.PermissionManager
+	deletionService   services.DeletionManager
+	notifier          services.NotificationManager
 }
 
 // NewRoleHandler creates a new role handler
 func NewRoleHandler(
-	roleRepo *repository.RoleRepository,
-	userRoleRepo *repository.UserRoleRepository,
-	permissionService *services.PermissionService,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	permissionService services.PermissionManager,
+	deletionService services.DeletionManager,
+	notifier services.NotificationManager,
 ) *RoleHandler {
 	return &RoleHandler{
 		roleRepo:          roleRepo,
 		userRoleRepo:      userRoleRepo,
 		permissionService: permissionService,
+		deletionService:   deletionService,
+		notifier:          notifier,
 	}
 }
 
@@ -63,7 +75,8 @@ func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Get retrieves a single role by ID
+// Get retrieves a single role by ID. The ETag header carries its version, to
+// send back in If-Match when updating it.
 // GET /api/roles/{id}
 func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
@@ -86,6 +99,7 @@ func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	utils.SetVersion(w, role.UpdatedAt)
 	utils.Success(w, map[string]interface{}{
 		"role": role,
 	})
@@ -110,6 +124,9 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	if len(req.PermissionIDs) == 0 {
 		errors.Add("permission_ids", "At least one permission is required")
 	}
+	if permissionIDsOverlap(req.PermissionIDs, req.DeniedPermissionIDs) {
+		errors.Add("denied_permission_ids", "A permission cannot be both allowed and denied")
+	}
 
 	if errors.HasErrors() {
 		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
@@ -140,7 +157,7 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate permission IDs
-	valid, _, err := h.permissionService.ValidatePermissionIDs(r.Context(), req.PermissionIDs)
+	valid, _, err := h.permissionService.ValidatePermissionIDs(r.Context(), append(req.PermissionIDs, req.DeniedPermissionIDs...))
 	if err != nil {
 		utils.InternalServerError(w, "Failed to validate permissions")
 		return
@@ -152,13 +169,22 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Create role
 	role := &models.Role{
-		Name:         req.Name,
-		DisplayName:  req.DisplayName,
-		Description:  &req.Description,
-		ParentRoleID: req.ParentRoleID,
-		Level:        0, // Will be calculated based on parent if needed
-		IsSystem:     false,
-		CreatedBy:    &userID,
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Description: &req.Description,
+		Level:       0, // One below the parent, if any
+		IsSystem:    false,
+		CreatedBy:   &userID,
+	}
+
+	if req.ParentRoleID != nil && *req.ParentRoleID != uuid.Nil {
+		parent, err := h.permissionService.ValidateParentRole(r.Context(), tenantID, uuid.Nil, *req.ParentRoleID)
+		if err != nil {
+			utils.WriteError(w, err, "Failed to validate parent role")
+			return
+		}
+		role.ParentRoleID = &parent.ID
+		role.Level = parent.Level + 1
 	}
 
 	if err := h.roleRepo.Create(r.Context(), tenantID, role); err != nil {
@@ -167,7 +193,7 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Assign permissions
-	if err := h.roleRepo.AssignPermissions(r.Context(), tenantID, role.ID, req.PermissionIDs, userID); err != nil {
+	if err := h.roleRepo.AssignPermissions(r.Context(), tenantID, role.ID, req.PermissionIDs, req.DeniedPermissionIDs, userID); err != nil {
 		utils.InternalServerError(w, "Failed to assign permissions")
 		return
 	}
@@ -175,15 +201,54 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Get role with details
 	createdRole, _ := h.roleRepo.GetRoleWithDetails(r.Context(), tenantID, role.ID)
 
+	middleware.SetAuditResourceID(r.Context(), role.ID)
+	middleware.SetAuditAfter(r.Context(), createdRole)
+
 	utils.Created(w, map[string]interface{}{
 		"role":    createdRole,
 		"message": "Role created successfully",
 	})
 }
 
-// Update updates an existing role
+// Update updates an existing role. Fields left out or null keep their value.
+// The version the change is based on, sent in If-Match or as version, must
+// still be current, or the update fails with 409 and the current role.
 // PUT /api/roles/{id}
 func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req models.RoleUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.update(w, r, &req, nil)
+}
+
+// Patch applies a JSON merge patch to a role. Fields left out keep their
+// value; null clears the description, detaches the role from its parent or
+// removes all denied permissions.
+// PATCH /api/roles/{id}
+func (h *RoleHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var req models.RoleUpdateRequest
+	nulls, err := utils.ParseMergePatch(r, &req)
+	if err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// A null parent is the nil UUID and null denied permissions an empty list
+	if nulls["parent_role_id"] {
+		req.ParentRoleID = &uuid.Nil
+	}
+	if nulls["denied_permission_ids"] {
+		req.DeniedPermissionIDs = []uuid.UUID{}
+	}
+
+	h.update(w, r, &req, nulls)
+}
+
+// update applies an update to a role, clearing the fields named in nulls
+func (h *RoleHandler) update(w http.ResponseWriter, r *http.Request, req *models.RoleUpdateRequest, nulls map[string]bool) {
 	roleIDStr := chi.URLParam(r, "id")
 	roleID, err := uuid.Parse(roleIDStr)
 	if err != nil {
@@ -191,9 +256,21 @@ func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.RoleUpdateRequest
-	if err := utils.ParseJSONBody(r, &req); err != nil {
-		utils.BadRequest(w, "Invalid request body")
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateNotNull("display_name", nulls, "Display name", &errors)
+	utils.ValidateNotNull("permission_ids", nulls, "Permission IDs", &errors)
+	if req.DisplayName != nil {
+		utils.ValidateStringLength("display_name", *req.DisplayName, 2, 255, "Display name", &errors)
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	version, err := utils.ParseVersion(r, req.Version)
+	if err != nil {
+		utils.WriteError(w, err, "Invalid version")
 		return
 	}
 
@@ -222,27 +299,74 @@ func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if previousRole, err := h.roleRepo.GetRoleWithDetails(r.Context(), tenantID, roleID); err == nil {
+		middleware.SetAuditBefore(r.Context(), previousRole)
+	}
+
+	// The update only applies to the version the client read
+	if version != nil {
+		role.UpdatedAt = *version
+	}
+
 	// Update fields
 	if req.DisplayName != nil {
 		role.DisplayName = *req.DisplayName
 	}
-	if req.Description != nil {
+	if req.Description != nil || nulls["description"] {
 		role.Description = req.Description
 	}
-	if req.ParentRoleID != nil {
-		role.ParentRoleID = req.ParentRoleID
+
+	// The nil UUID detaches the role from its parent. A new parent changes
+	// the permissions the role inherits.
+	permissionsChanged := false
+	if req.ParentRoleID != nil && *req.ParentRoleID == uuid.Nil {
+		permissionsChanged = role.ParentRoleID != nil
+		role.ParentRoleID = nil
+		role.Level = 0
+	} else if req.ParentRoleID != nil {
+		parent, err := h.permissionService.ValidateParentRole(r.Context(), tenantID, roleID, *req.ParentRoleID)
+		if err != nil {
+			utils.WriteError(w, err, "Failed to validate parent role")
+			return
+		}
+		permissionsChanged = role.ParentRoleID == nil || *role.ParentRoleID != parent.ID
+		role.ParentRoleID = &parent.ID
+		role.Level = parent.Level + 1
 	}
 
 	// Update role
 	if err := h.roleRepo.Update(r.Context(), tenantID, role); err != nil {
-		utils.InternalServerError(w, "Failed to update role")
+		h.respondUpdateError(w, r, tenantID, roleID, err)
 		return
 	}
 
 	// Update permissions if provided
-	if req.PermissionIDs != nil && len(req.PermissionIDs) > 0 {
+	if len(req.PermissionIDs) > 0 || req.DeniedPermissionIDs != nil {
+		// Keep the current allowed or denied permissions left out of the request
+		permissionIDs, deniedPermissionIDs := req.PermissionIDs, req.DeniedPermissionIDs
+		if len(permissionIDs) == 0 || deniedPermissionIDs == nil {
+			current, err := h.roleRepo.GetPermissions(r.Context(), tenantID, roleID)
+			if err != nil {
+				utils.InternalServerError(w, "Failed to get permissions")
+				return
+			}
+			currentAllowed, currentDenied := splitPermissionIDs(current)
+			if len(permissionIDs) == 0 {
+				permissionIDs = currentAllowed
+			}
+			if deniedPermissionIDs == nil {
+				deniedPermissionIDs = currentDenied
+			}
+		}
+		if permissionIDsOverlap(permissionIDs, deniedPermissionIDs) {
+			utils.UnprocessableEntity(w, "Validation failed", map[string]string{
+				"denied_permission_ids": "A permission cannot be both allowed and denied",
+			})
+			return
+		}
+
 		// Validate permission IDs
-		valid, _, err := h.permissionService.ValidatePermissionIDs(r.Context(), req.PermissionIDs)
+		valid, _, err := h.permissionService.ValidatePermissionIDs(r.Context(), append(permissionIDs, deniedPermissionIDs...))
 		if err != nil {
 			utils.InternalServerError(w, "Failed to validate permissions")
 			return
@@ -252,25 +376,51 @@ func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if err := h.roleRepo.AssignPermissions(r.Context(), tenantID, roleID, req.PermissionIDs, userID); err != nil {
+		if err := h.roleRepo.AssignPermissions(r.Context(), tenantID, roleID, permissionIDs, deniedPermissionIDs, userID); err != nil {
 			utils.InternalServerError(w, "Failed to update permissions")
 			return
 		}
+		permissionsChanged = true
+	}
 
-		// Invalidate permission cache for all users with this role
+	// Invalidate permission cache for all users with this role or one
+	// inheriting from it
+	if permissionsChanged {
 		h.permissionService.InvalidateRolePermissions(r.Context(), tenantID, roleID)
 	}
 
 	// Get updated role with details
 	updatedRole, _ := h.roleRepo.GetRoleWithDetails(r.Context(), tenantID, roleID)
+	middleware.SetAuditAfter(r.Context(), updatedRole)
 
+	utils.SetVersion(w, role.UpdatedAt)
 	utils.Success(w, map[string]interface{}{
 		"role":    updatedRole,
 		"message": "Role updated successfully",
 	})
 }
 
-// Delete deletes a role
+// respondUpdateError writes the response for a failed role update. A version
+// conflict comes with the current role, for the client to reapply its change.
+func (h *RoleHandler) respondUpdateError(w http.ResponseWriter, r *http.Request, tenantID, roleID uuid.UUID, err error) {
+	if !errors.Is(err, utils.ErrConflict) {
+		utils.WriteError(w, err, "Failed to update role")
+		return
+	}
+
+	latest, err := h.roleRepo.GetRoleWithDetails(r.Context(), tenantID, roleID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to update role")
+		return
+	}
+
+	utils.SetVersion(w, latest.UpdatedAt)
+	utils.VersionConflict(w, "The role was changed in the meantime", map[string]interface{}{
+		"role": latest,
+	})
+}
+
+// Delete schedules a custom role for deletion (undoable until the window passes)
 // DELETE /api/roles/{id}
 func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	roleIDStr := chi.URLParam(r, "id")
@@ -286,6 +436,25 @@ func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	role, err := h.roleRepo.FindByID(r.Context(), tenantID, roleID)
+	if err != nil {
+		utils.NotFound(w, "Role not found")
+		return
+	}
+
+	middleware.SetAuditBefore(r.Context(), role)
+
+	if !role.CanDelete() {
+		utils.BadRequest(w, "System roles cannot be deleted")
+		return
+	}
+
 	// Check if role has users
 	userCount, err := h.roleRepo.CountUsers(r.Context(), tenantID, roleID)
 	if err != nil {
@@ -298,21 +467,44 @@ func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete role
-	if err := h.roleRepo.Delete(r.Context(), tenantID, roleID); err != nil {
-		utils.BadRequest(w, err.Error())
+	// Stage the deletion; it is executed once the undo window has passed
+	deletion, err := h.deletionService.Schedule(r.Context(), tenantID, userID, models.DeletionEntityRole, role.ID, role.DisplayName)
+	if err != nil {
+		respondDeletionError(w, err)
 		return
 	}
-
-	// Invalidate permission cache for users with this role
-	h.permissionService.InvalidateRolePermissions(r.Context(), tenantID, roleID)
+	middleware.SetAuditMetadata(r.Context(), "deletion_id", deletion.ID)
 
 	utils.Success(w, map[string]interface{}{
-		"message": "Role deleted successfully",
+		"deletion": deletion,
+		"message":  "Role scheduled for deletion",
 	})
 }
 
-// GetPermissions retrieves permissions for a role
+// permissionIDsOverlap returns true if a permission is both allowed and denied
+func permissionIDsOverlap(allowed, denied []uuid.UUID) bool {
+	for _, id := range denied {
+		if slices.Contains(allowed, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPermissionIDs splits a role's permissions into allowed and denied IDs
+func splitPermissionIDs(permissions []models.Permission) (allowed, denied []uuid.UUID) {
+	allowed, denied = []uuid.UUID{}, []uuid.UUID{}
+	for _, perm := range permissions {
+		if perm.IsDeny() {
+			denied = append(denied, perm.ID)
+		} else {
+			allowed = append(allowed, perm.ID)
+		}
+	}
+	return allowed, denied
+}
+
+// GetPermissions retrieves permissions for a role, with their effect
 // GET /api/roles/{id}/permissions
 func (h *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
 	roleIDStr := chi.URLParam(r, "id")
@@ -340,6 +532,37 @@ func (h *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetEffectivePermissions retrieves a role's permissions including those
+// inherited from its ancestors
+// GET /api/roles/{id}/effective-permissions
+func (h *RoleHandler) GetEffectivePermissions(w http.ResponseWriter, r *http.Request) {
+	roleIDStr := chi.URLParam(r, "id")
+	roleID, err := uuid.Parse(roleIDStr)
+	if err != nil {
+		utils.BadRequest(w, "Invalid role ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	effective, err := h.permissionService.GetEffectiveRolePermissions(r.Context(), tenantID, roleID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to get permissions")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"role":        effective.Role,
+		"ancestors":   effective.Ancestors,
+		"permissions": effective.Permissions,
+		"count":       len(effective.Permissions),
+	})
+}
+
 // GetUsers retrieves users assigned to a role
 // GET /api/roles/{id}/users
 func (h *RoleHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
@@ -404,6 +627,8 @@ func (h *RoleHandler) AssignToUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	middleware.SetAuditMetadata(r.Context(), "user_ids", req.UserIDs)
+
 	// Bulk assign role
 	if err := h.userRoleRepo.BulkAssignRole(r.Context(), tenantID, req.UserIDs, roleID, userID); err != nil {
 		utils.InternalServerError(w, "Failed to assign role")
@@ -415,14 +640,46 @@ func (h *RoleHandler) AssignToUsers(w http.ResponseWriter, r *http.Request) {
 		h.permissionService.InvalidateUserPermissions(r.Context(), tenantID, targetUserID)
 	}
 
+	if role, err := h.roleRepo.FindByID(r.Context(), tenantID, roleID); err == nil {
+		notifyRolesChanged(r.Context(), h.notifier, tenantID, userID, req.UserIDs, []models.Role{*role})
+	}
+
 	utils.Success(w, map[string]interface{}{
 		"message": "Role assigned successfully",
 		"count":   len(req.UserIDs),
 	})
 }
 
+// notifyRolesChanged tells users, other than the one who made the change,
+// which roles they were given
+func notifyRolesChanged(ctx context.Context, notifier services.NotificationManager, tenantID, changedBy uuid.UUID, userIDs []uuid.UUID, roles []models.Role) {
+	recipients := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != changedBy {
+			recipients = append(recipients, id)
+		}
+	}
+
+	names := make([]string, len(roles))
+	roleIDs := make([]uuid.UUID, len(roles))
+	for i, role := range roles {
+		names[i] = role.DisplayName
+		roleIDs[i] = role.ID
+	}
+
+	if err := notifier.Notify(ctx, tenantID, recipients, &models.NotificationRequest{
+		Type:  models.NotificationTypeRolesChanged,
+		Title: "Your roles have changed",
+		Body:  fmt.Sprintf("Your roles now include %s. Your access was updated accordingly.", strings.Join(names, ", ")),
+		Link:  "/profile",
+		Data:  map[string]interface{}{"role_ids": roleIDs, "changed_by": changedBy},
+	}); err != nil {
+		log.Printf("⚠️  Failed to notify users of role changes: %v", err)
+	}
+}
+
 // RegisterRoutes registers all role routes
-func (h *RoleHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+func (h *RoleHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, navMiddleware *middleware.NavigationMiddleware) {
 	r.Route("/roles", func(r chi.Router) {
 		// All role routes require authentication
 		r.Use(authMiddleware.Authenticate)
@@ -431,24 +688,41 @@ func (h *RoleHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.Au
 		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/", h.List)
 
 		// Get single role - requires view permission
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/{id}", h.Get)
+		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView), navMiddleware.TrackView(models.NavigationEntityRole)).Get("/{id}", h.Get)
 
 		// Create role - requires create permission
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionCreate)).Post("/", h.Create)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionCreate),
+			auditMiddleware.Record(models.ActionRoleCreated, models.ResourceRoles),
+		).Post("/", h.Create)
 
 		// Update role - requires edit permission
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionEdit)).Put("/{id}", h.Update)
+		edit := r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionEdit),
+			auditMiddleware.Record(models.ActionRoleUpdated, models.ResourceRoles),
+		)
+		edit.Put("/{id}", h.Update)
+		edit.Patch("/{id}", h.Patch)
 
 		// Delete role - requires delete permission
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionDelete)).Delete("/{id}", h.Delete)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionDelete),
+			auditMiddleware.Record(models.ActionRoleDeleted, models.ResourceRoles),
+		).Delete("/{id}", h.Delete)
 
 		// Role permissions - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/{id}/permissions", h.GetPermissions)
 
+		// Role permissions including inherited ones - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/{id}/effective-permissions", h.GetEffectivePermissions)
+
 		// Role users - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionView)).Get("/{id}/users", h.GetUsers)
 
 		// Assign role to users - requires assign permission
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign)).Post("/{id}/assign", h.AssignToUsers)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign),
+			auditMiddleware.Record(models.ActionRoleAssigned, models.ResourceRoles),
+		).Post("/{id}/assign", h.AssignToUsers)
 	})
 }
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// salesPathTypes maps URL segments to sales document types
+var salesPathTypes = map[string]string{
+	"quotes":   models.SalesDocumentQuote,
+	"orders":   models.SalesDocumentOrder,
+	"invoices": models.SalesDocumentInvoice,
+}
+
+// SalesHandler handles quotation, sales order and invoice endpoints
+type SalesHandler struct {
+	salesService services.SalesManager
+}
+
+// NewSalesHandler creates a new sales handler
+func NewSalesHandler(salesService services.SalesManager) *SalesHandler {
+	return &SalesHandler{
+		salesService: salesService,
+	}
+}
+
+// List lists sales documents of one type
+// GET /api/sales/{type}?page=1&page_size=20&status=draft&search=acme
+func (h *SalesHandler) List(w http.ResponseWriter, r *http.Request) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	filter := models.SalesDocumentFilter{
+		DocumentType: documentType,
+		Status:       r.URL.Query().Get("status"),
+		Search:       r.URL.Query().Get("search"),
+	}
+
+	docs, totalCount, err := h.salesService.ListDocuments(r.Context(), tenantID, filter, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list sales documents")
+		return
+	}
+
+	utils.SuccessWithMeta(w, docs, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// Get retrieves a sales document with its lines
+// GET /api/sales/{type}/{id}
+func (h *SalesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	doc, err := h.salesService.GetDocument(r.Context(), tenantID, documentType, docID)
+	if err != nil {
+		utils.NotFound(w, "Sales document not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"document": doc,
+	})
+}
+
+// Create creates a draft sales document
+// POST /api/sales/{type}
+func (h *SalesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SalesDocumentCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("customer_name", req.CustomerName, "Customer name", &errors)
+	utils.ValidateStringLength("customer_name", req.CustomerName, 1, 255, "Customer name", &errors)
+	if req.CustomerEmail != nil && *req.CustomerEmail != "" {
+		utils.ValidateEmail("customer_email", *req.CustomerEmail, &errors)
+	}
+	if req.Currency != "" {
+		utils.ValidateStringLength("currency", req.Currency, 3, 3, "Currency", &errors)
+	}
+	validateSalesLines(req.Lines, &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	doc, err := h.salesService.CreateDocument(r.Context(), tenantID, userID, documentType, &req)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to create sales document")
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"document": doc,
+		"message":  "Sales document created successfully",
+	})
+}
+
+// Update updates a draft sales document
+// PUT /api/sales/{type}/{id}
+func (h *SalesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	var req models.SalesDocumentUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.CustomerName != nil {
+		utils.ValidateStringLength("customer_name", *req.CustomerName, 1, 255, "Customer name", &errors)
+	}
+	if req.CustomerEmail != nil && *req.CustomerEmail != "" {
+		utils.ValidateEmail("customer_email", *req.CustomerEmail, &errors)
+	}
+	if req.Currency != nil {
+		utils.ValidateStringLength("currency", *req.Currency, 3, 3, "Currency", &errors)
+	}
+	if req.Lines != nil {
+		validateSalesLines(*req.Lines, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	doc, err := h.salesService.UpdateDocument(r.Context(), tenantID, userID, documentType, docID, &req)
+	if err != nil {
+		respondSalesError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"document": doc,
+		"message":  "Sales document updated successfully",
+	})
+}
+
+// Delete schedules a draft sales document for deletion (undoable until the window passes)
+// DELETE /api/sales/{type}/{id}
+func (h *SalesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	deletion, err := h.salesService.DeleteDocument(r.Context(), tenantID, userID, documentType, docID)
+	if err != nil {
+		respondSalesError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "Sales document scheduled for deletion",
+	})
+}
+
+// Confirm confirms a draft sales document
+// POST /api/sales/{type}/{id}/confirm
+func (h *SalesHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.SalesStatusConfirmed, "Sales document confirmed")
+}
+
+// Fulfill marks a confirmed sales document as fulfilled
+// POST /api/sales/{type}/{id}/fulfill
+func (h *SalesHandler) Fulfill(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.SalesStatusFulfilled, "Sales document fulfilled")
+}
+
+// Cancel cancels a draft or confirmed sales document
+// POST /api/sales/{type}/{id}/cancel
+func (h *SalesHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.SalesStatusCancelled, "Sales document cancelled")
+}
+
+// ConvertQuote converts a confirmed quotation into a draft sales order
+// POST /api/sales/quotes/{id}/convert
+func (h *SalesHandler) ConvertQuote(w http.ResponseWriter, r *http.Request) {
+	quoteID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	order, err := h.salesService.ConvertQuoteToOrder(r.Context(), tenantID, userID, quoteID)
+	if err != nil {
+		respondSalesError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"document": order,
+		"message":  "Quotation converted to sales order",
+	})
+}
+
+// InvoiceOrder creates a draft invoice for a sales order
+// POST /api/sales/orders/{id}/invoice
+func (h *SalesHandler) InvoiceOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	invoice, err := h.salesService.InvoiceOrder(r.Context(), tenantID, userID, orderID)
+	if err != nil {
+		respondSalesError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"document": invoice,
+		"message":  "Invoice created from sales order",
+	})
+}
+
+// changeStatus applies a status transition to the document in the URL
+func (h *SalesHandler) changeStatus(w http.ResponseWriter, r *http.Request, status, message string) {
+	documentType, ok := salesDocumentType(w, r)
+	if !ok {
+		return
+	}
+
+	docID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	doc, err := h.salesService.ChangeStatus(r.Context(), tenantID, userID, documentType, docID, status)
+	if err != nil {
+		respondSalesError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"document": doc,
+		"message":  message,
+	})
+}
+
+// salesDocumentType resolves the {type} URL segment, writing a 404 if unknown
+func salesDocumentType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	documentType, ok := salesPathTypes[chi.URLParam(r, "type")]
+	if !ok {
+		utils.NotFound(w, "Unknown sales document type")
+		return "", false
+	}
+	return documentType, true
+}
+
+// validateSalesLines validates request line items
+func validateSalesLines(lines []models.SalesLineRequest, errors *utils.ValidationErrors) {
+	for i, line := range lines {
+		field := "lines." + strconv.Itoa(i)
+		if line.Description == "" {
+			errors.Add(field+".description", "Description is required")
+		}
+		if line.Quantity <= 0 {
+			errors.Add(field+".quantity", "Quantity must be greater than zero")
+		}
+		if line.UnitPrice < 0 {
+			errors.Add(field+".unit_price", "Unit price cannot be negative")
+		}
+		if line.DiscountPercent < 0 || line.DiscountPercent > 100 {
+			errors.Add(field+".discount_percent", "Discount must be between 0 and 100")
+		}
+		if line.TaxRate < 0 {
+			errors.Add(field+".tax_rate", "Tax rate cannot be negative")
+		}
+	}
+}
+
+// respondSalesError maps sales service errors to HTTP responses
+func respondSalesError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Sales operation failed")
+}
+
+// RegisterRoutes registers sales routes
+func (h *SalesHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, navMiddleware *middleware.NavigationMiddleware) {
+	r.Route("/sales", func(r chi.Router) {
+		// All sales routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Conversions between document types
+		r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionCreate)).Post("/quotes/{id}/convert", h.ConvertQuote)
+		r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionCreate)).Post("/orders/{id}/invoice", h.InvoiceOrder)
+
+		// Quotes, orders and invoices share the same CRUD and status endpoints
+		r.Route("/{type}", func(r chi.Router) {
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionView)).Get("/", h.List)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionCreate)).Post("/", h.Create)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionView), navMiddleware.TrackView(models.NavigationEntitySalesDocument)).Get("/{id}", h.Get)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionEdit)).Put("/{id}", h.Update)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionDelete)).Delete("/{id}", h.Delete)
+
+			// Status transitions
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionManageStatus)).Post("/{id}/confirm", h.Confirm)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionManageStatus)).Post("/{id}/fulfill", h.Fulfill)
+			r.With(permMiddleware.RequirePermission(models.ResourceSales, models.ActionManageStatus)).Post("/{id}/cancel", h.Cancel)
+		})
+	})
+}
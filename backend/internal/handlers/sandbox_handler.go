@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// SandboxHandler handles tenant sandbox endpoints
+type SandboxHandler struct {
+	sandboxService services.SandboxManager
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler(sandboxService services.SandboxManager) *SandboxHandler {
+	return &SandboxHandler{
+		sandboxService: sandboxService,
+	}
+}
+
+// CreateSandbox requests a sandbox copy of the tenant's data
+// POST /api/sandboxes
+func (h *SandboxHandler) CreateSandbox(w http.ResponseWriter, r *http.Request) {
+	var req models.SandboxCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	req.Name = strings.TrimSpace(req.Name)
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	if req.ExpiresInDays < 0 {
+		errors.Add("expires_in_days", "Expiry must be a positive number of days")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	sandbox, err := h.sandboxService.CreateSandbox(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to create sandbox")
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"sandbox": sandbox,
+		"message": "Sandbox requested; it becomes available once the clone has finished",
+	})
+}
+
+// ListSandboxes lists the tenant's sandboxes
+// GET /api/sandboxes?include_deleted=true
+func (h *SandboxHandler) ListSandboxes(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+
+	sandboxes, totalCount, err := h.sandboxService.ListSandboxes(r.Context(), tenantID, includeDeleted, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list sandboxes")
+		return
+	}
+
+	utils.SuccessWithMeta(w, sandboxes, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetSandbox retrieves a sandbox and its clone status
+// GET /api/sandboxes/{id}
+func (h *SandboxHandler) GetSandbox(w http.ResponseWriter, r *http.Request) {
+	sandboxID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid sandbox ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	sandbox, err := h.sandboxService.GetSandbox(r.Context(), tenantID, sandboxID)
+	if err != nil {
+		utils.NotFound(w, "Sandbox not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"sandbox": sandbox,
+	})
+}
+
+// DeleteSandbox removes a sandbox and its data before it expires
+// DELETE /api/sandboxes/{id}
+func (h *SandboxHandler) DeleteSandbox(w http.ResponseWriter, r *http.Request) {
+	sandboxID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid sandbox ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.sandboxService.DeleteSandbox(r.Context(), tenantID, userID, sandboxID); err != nil {
+		utils.WriteError(w, err, "Failed to delete sandbox")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Sandbox deleted successfully",
+	})
+}
+
+// RegisterRoutes registers sandbox routes
+func (h *SandboxHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, planMiddleware *middleware.PlanMiddleware) {
+	r.Route("/sandboxes", func(r chi.Router) {
+		// All sandbox routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSandboxes, models.ActionView)).Get("/", h.ListSandboxes)
+		r.With(permMiddleware.RequirePermission(models.ResourceSandboxes, models.ActionCreate), planMiddleware.RequireFeature(models.PlanFeatureSandboxes)).Post("/", h.CreateSandbox)
+		r.With(permMiddleware.RequirePermission(models.ResourceSandboxes, models.ActionView)).Get("/{id}", h.GetSandbox)
+		r.With(permMiddleware.RequirePermission(models.ResourceSandboxes, models.ActionDelete)).Delete("/{id}", h.DeleteSandbox)
+	})
+}
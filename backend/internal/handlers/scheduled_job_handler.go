@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// ScheduledJobHandler handles background job inspection endpoints
+type ScheduledJobHandler struct {
+	runner *jobs.Runner
+}
+
+// NewScheduledJobHandler creates a new scheduled job handler
+func NewScheduledJobHandler(runner *jobs.Runner) *ScheduledJobHandler {
+	return &ScheduledJobHandler{
+		runner: runner,
+	}
+}
+
+// List retrieves the background jobs with their schedule, last run and run
+// totals. Jobs are shared by all tenants.
+// GET /api/admin/jobs
+func (h *ScheduledJobHandler) List(w http.ResponseWriter, r *http.Request) {
+	scheduledJobs, err := h.runner.Status(r.Context())
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list background jobs")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"jobs": scheduledJobs,
+	})
+}
+
+// RegisterRoutes registers background job routes
+func (h *ScheduledJobHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+	r.Route("/admin/jobs", func(r chi.Router) {
+		// All background job routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.List)
+	})
+}
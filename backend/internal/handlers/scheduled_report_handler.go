@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// ScheduledReportHandler handles the reports admins schedule to be emailed
+// on users, sign-ins and audit logs
+type ScheduledReportHandler struct {
+	reportService services.ScheduledReportManager
+}
+
+// NewScheduledReportHandler creates a new scheduled report handler
+func NewScheduledReportHandler(reportService services.ScheduledReportManager) *ScheduledReportHandler {
+	return &ScheduledReportHandler{
+		reportService: reportService,
+	}
+}
+
+// ListReports lists the tenant's scheduled reports by name
+// GET /api/reports?page=1&page_size=20
+func (h *ScheduledReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	reports, totalCount, err := h.reportService.ListReports(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list reports")
+		return
+	}
+
+	utils.SuccessWithMeta(w, reports, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetReport retrieves a scheduled report with its last run
+// GET /api/reports/{id}
+func (h *ScheduledReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid report ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	report, err := h.reportService.GetReport(r.Context(), tenantID, reportID)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"report": report,
+	})
+}
+
+// CreateReport schedules a report
+// POST /api/reports
+func (h *ScheduledReportHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	var req models.ScheduledReportRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateScheduledReportRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	report, err := h.reportService.CreateReport(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), report.ID)
+	middleware.SetAuditAfter(r.Context(), report)
+
+	utils.Created(w, map[string]interface{}{
+		"report":  report,
+		"message": "Report scheduled successfully",
+	})
+}
+
+// UpdateReport replaces a scheduled report's settings
+// PUT /api/reports/{id}
+func (h *ScheduledReportHandler) UpdateReport(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid report ID")
+		return
+	}
+
+	var req models.ScheduledReportRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateScheduledReportRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.reportService.GetReport(r.Context(), tenantID, reportID)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	report, err := h.reportService.UpdateReport(r.Context(), tenantID, reportID, &req)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), report)
+
+	utils.Success(w, map[string]interface{}{
+		"report":  report,
+		"message": "Report updated successfully",
+	})
+}
+
+// DeleteReport deletes a scheduled report
+// DELETE /api/reports/{id}
+func (h *ScheduledReportHandler) DeleteReport(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid report ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.reportService.GetReport(r.Context(), tenantID, reportID)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.reportService.DeleteReport(r.Context(), tenantID, reportID); err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Report deleted successfully",
+	})
+}
+
+// RunReport emails a report to its recipients right away, covering the time
+// since its previous run
+// POST /api/reports/{id}/run
+func (h *ScheduledReportHandler) RunReport(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid report ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), reportID)
+
+	run, err := h.reportService.RunReport(r.Context(), tenantID, reportID)
+	if err != nil {
+		respondScheduledReportError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), run)
+
+	utils.Success(w, map[string]interface{}{
+		"run":     run,
+		"message": "Report sent successfully",
+	})
+}
+
+// validateScheduledReportRequest checks the fields of a scheduled report
+// request; the service checks the schedule and recipients
+func validateScheduledReportRequest(req *models.ScheduledReportRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	utils.ValidateEnum("report_type", req.ReportType, models.ReportTypes, "Report type", &errors)
+	if req.Format == "" {
+		req.Format = models.ReportFormatCSV
+	}
+	utils.ValidateEnum("format", req.Format, models.ReportFormats, "Format", &errors)
+	utils.ValidateRequired("schedule", req.Schedule, "Schedule", &errors)
+	return errors
+}
+
+// respondScheduledReportError maps scheduled report service errors to HTTP
+// responses
+func respondScheduledReportError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Report operation failed")
+}
+
+// RegisterRoutes registers the scheduled report routes
+func (h *ScheduledReportHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/reports", func(r chi.Router) {
+		// All report routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceReports, models.ActionView)).Get("/", h.ListReports)
+		r.With(permMiddleware.RequirePermission(models.ResourceReports, models.ActionView)).Get("/{id}", h.GetReport)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceReports, models.ActionCreate),
+			auditMiddleware.Record(models.ActionScheduledReportCreated, models.ResourceReports),
+		).Post("/", h.CreateReport)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceReports, models.ActionEdit),
+			auditMiddleware.Record(models.ActionScheduledReportUpdated, models.ResourceReports),
+		).Put("/{id}", h.UpdateReport)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceReports, models.ActionDelete),
+			auditMiddleware.Record(models.ActionScheduledReportDeleted, models.ResourceReports),
+		).Delete("/{id}", h.DeleteReport)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceReports, models.ActionEdit),
+			auditMiddleware.Record(models.ActionScheduledReportRun, models.ResourceReports),
+		).Post("/{id}/run", h.RunReport)
+	})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// maxSearchQueryLength bounds search input
+const maxSearchQueryLength = 200
+
+// SearchHandler handles the multilingual search endpoint
+type SearchHandler struct {
+	searchService services.SearchManager
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService services.SearchManager) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search searches users, roles, departments, customers and products,
+// accent-insensitively and tolerating typos. Types the user has no
+// permission for are left out.
+// GET /api/search?q=jose&types=user,role,department,customer,product&lang=fr&limit=10
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		utils.BadRequest(w, "Search term is required")
+		return
+	}
+	if len(query) > maxSearchQueryLength {
+		utils.BadRequest(w, "Search term is too long")
+		return
+	}
+
+	types := models.SearchTypes
+	if value := r.URL.Query().Get("types"); value != "" {
+		types = strings.Split(value, ",")
+		errors := utils.ValidationErrors{}
+		for _, searchType := range types {
+			utils.ValidateEnum("types", searchType, models.SearchTypes, "Type", &errors)
+		}
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 50 {
+			utils.BadRequest(w, "Limit must be between 1 and 50")
+			return
+		}
+		limit = parsed
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	language := searchLanguage(r)
+	results, err := h.searchService.Search(r.Context(), tenantID, userID, query, language, types, limit)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to search")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"query":    query,
+		"language": language,
+		"results":  results,
+	})
+}
+
+// searchLanguage returns the language a search is stemmed in: the lang
+// query parameter, or else the first language of the Accept-Language header
+func searchLanguage(r *http.Request) string {
+	language := r.URL.Query().Get("lang")
+	if language == "" {
+		language = r.Header.Get("Accept-Language")
+	}
+
+	// "fr-CA,fr;q=0.9" -> "fr"
+	language, _, _ = strings.Cut(language, ",")
+	language, _, _ = strings.Cut(language, ";")
+	language, _, _ = strings.Cut(language, "-")
+	return strings.ToLower(strings.TrimSpace(language))
+}
+
+// RegisterRoutes registers the search route
+func (h *SearchHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/search", func(r chi.Router) {
+		// Search requires authentication; results are filtered by permission
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.Search)
+	})
+}
@@ -13,16 +13,16 @@ import (
 
 // SecurityHandler handles security monitoring endpoints
 type SecurityHandler struct {
-	auditService   *services.AuditService
-	sessionService *services.SessionService
-	twoFactorService *services.TwoFactorService
+	auditService   services.AuditManager
+	sessionService services.SessionManager
+	twoFactorService services.TwoFactorManager
 }
 
 // NewSecurityHandler creates a new security handler
 func NewSecurityHandler(
-	auditService *services.AuditService,
-	sessionService *services.SessionService,
-	twoFactorService *services.TwoFactorService,
+	auditService services.AuditManager,
+	sessionService services.SessionManager,
+	twoFactorService services.TwoFactorManager,
 ) *SecurityHandler {
 	return &SecurityHandler{
 		auditService:   auditService,
@@ -5,21 +5,23 @@ import (
 	"encoding/hex"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
 	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
 )
 
 // SessionHandler handles session management endpoints
 type SessionHandler struct {
-	sessionService *services.SessionService
+	sessionService services.SessionManager
 }
 
 // NewSessionHandler creates a new session handler
-func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+func NewSessionHandler(sessionService services.SessionManager) *SessionHandler {
 	return &SessionHandler{
 		sessionService: sessionService,
 	}
@@ -95,11 +97,66 @@ func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	middleware.SetAuditResourceID(r.Context(), sessionID)
+
 	utils.Success(w, map[string]interface{}{
 		"message": "Session revoked successfully",
 	})
 }
 
+// RenameSession gives one of the current user's sessions a friendly name
+// PATCH /api/sessions/{id}
+func (h *SessionHandler) RenameSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid session ID")
+		return
+	}
+
+	var req models.SessionUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateStringLength("name", strings.TrimSpace(req.Name), 0, 100, "Name", &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	session, err := h.sessionService.RenameSession(r.Context(), tenantID, userID, sessionID, req.Name)
+	if err != nil {
+		if err.Error() == "session not found or already revoked" {
+			utils.NotFound(w, "Session not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to rename session")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), sessionID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"name": session.Name})
+
+	utils.Success(w, map[string]interface{}{
+		"session": session,
+	})
+}
+
 // RevokeAllSessions revokes all sessions except the current one
 // POST /api/sessions/revoke-all
 func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
@@ -201,7 +258,7 @@ func (h *SessionHandler) GetRecentLogins(w http.ResponseWriter, r *http.Request)
 }
 
 // RegisterRoutes registers all session management routes
-func (h *SessionHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+func (h *SessionHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, auditMiddleware *middleware.AuditMiddleware) {
 	r.Route("/sessions", func(r chi.Router) {
 		// All session routes require authentication
 		r.Use(authMiddleware.Authenticate)
@@ -215,8 +272,15 @@ func (h *SessionHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware
 		// Recent logins
 		r.Get("/recent-logins", h.GetRecentLogins)
 
+		// Rename specific session
+		r.With(
+			auditMiddleware.Record(models.ActionSessionRenamed, models.AuditResourceSessions),
+		).Patch("/{id}", h.RenameSession)
+
 		// Revoke specific session
-		r.Delete("/{id}", h.RevokeSession)
+		r.With(
+			auditMiddleware.Record(models.ActionSessionRevoked, models.AuditResourceSessions),
+		).Delete("/{id}", h.RevokeSession)
 
 		// Revoke all other sessions
 		r.Post("/revoke-all", h.RevokeAllSessions)
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// SessionLimitHandler handles the tenant's concurrent session limit
+type SessionLimitHandler struct {
+	authService services.AuthManager
+}
+
+// NewSessionLimitHandler creates a new session limit handler
+func NewSessionLimitHandler(authService services.AuthManager) *SessionLimitHandler {
+	return &SessionLimitHandler{
+		authService: authService,
+	}
+}
+
+// GetLimit retrieves the concurrent session limit of the tenant's users
+// GET /api/settings/session-limit
+func (h *SessionLimitHandler) GetLimit(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	limit, err := h.authService.GetSessionLimit(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get session limit")
+		return
+	}
+
+	utils.Success(w, limit)
+}
+
+// UpdateLimit changes the concurrent session limit of the tenant's users
+// PUT /api/settings/session-limit
+func (h *SessionLimitHandler) UpdateLimit(w http.ResponseWriter, r *http.Request) {
+	var req models.SessionLimitUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.MaxSessions != nil && (*req.MaxSessions < 0 || *req.MaxSessions > models.MaxSessionLimit) {
+		errors.Add("max_sessions", "Maximum sessions must be between 0 (unlimited) and 100")
+	}
+	if req.Policy != nil && *req.Policy != models.SessionLimitPolicyEvictOldest && *req.Policy != models.SessionLimitPolicyReject {
+		errors.Add("policy", "Policy must be evict_oldest or reject")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.authService.GetSessionLimit(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get session limit")
+		return
+	}
+
+	limit, err := h.authService.UpdateSessionLimit(r.Context(), tenantID, &req)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to update session limit")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), before)
+	middleware.SetAuditAfter(r.Context(), limit)
+
+	utils.Success(w, limit)
+}
+
+// RegisterRoutes registers the session limit routes
+func (h *SessionLimitHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/session-limit", func(r chi.Router) {
+		// All session limit routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetLimit)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionSessionLimitUpdated, models.ResourceSettings),
+		).Put("/", h.UpdateLimit)
+	})
+}
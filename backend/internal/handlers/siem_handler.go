@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// SIEMHandler handles the forwarders streaming the tenant's audit log to a
+// SIEM
+type SIEMHandler struct {
+	siemService services.SIEMManager
+}
+
+// NewSIEMHandler creates a new SIEM handler
+func NewSIEMHandler(siemService services.SIEMManager) *SIEMHandler {
+	return &SIEMHandler{
+		siemService: siemService,
+	}
+}
+
+// ListForwarders lists the tenant's SIEM forwarders with their progress
+// GET /api/siem-forwarders
+func (h *SIEMHandler) ListForwarders(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	forwarders, err := h.siemService.ListForwarders(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list SIEM forwarders")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"forwarders": forwarders,
+	})
+}
+
+// GetForwarder retrieves a SIEM forwarder
+// GET /api/siem-forwarders/{id}
+func (h *SIEMHandler) GetForwarder(w http.ResponseWriter, r *http.Request) {
+	forwarderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid forwarder ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	forwarder, err := h.siemService.GetForwarder(r.Context(), tenantID, forwarderID)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"forwarder": forwarder,
+	})
+}
+
+// CreateForwarder adds a SIEM forwarder
+// POST /api/siem-forwarders
+func (h *SIEMHandler) CreateForwarder(w http.ResponseWriter, r *http.Request) {
+	var req models.SIEMForwarderRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateSIEMForwarderRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	forwarder, err := h.siemService.CreateForwarder(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), forwarder.ID)
+	middleware.SetAuditAfter(r.Context(), forwarder)
+
+	utils.Created(w, map[string]interface{}{
+		"forwarder": forwarder,
+		"message":   "SIEM forwarder created successfully",
+	})
+}
+
+// UpdateForwarder replaces a SIEM forwarder's settings
+// PUT /api/siem-forwarders/{id}
+func (h *SIEMHandler) UpdateForwarder(w http.ResponseWriter, r *http.Request) {
+	forwarderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid forwarder ID")
+		return
+	}
+
+	var req models.SIEMForwarderRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateSIEMForwarderRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.siemService.GetForwarder(r.Context(), tenantID, forwarderID)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	forwarder, err := h.siemService.UpdateForwarder(r.Context(), tenantID, forwarderID, &req)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), forwarder)
+
+	utils.Success(w, map[string]interface{}{
+		"forwarder": forwarder,
+		"message":   "SIEM forwarder updated successfully",
+	})
+}
+
+// DeleteForwarder deletes a SIEM forwarder
+// DELETE /api/siem-forwarders/{id}
+func (h *SIEMHandler) DeleteForwarder(w http.ResponseWriter, r *http.Request) {
+	forwarderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid forwarder ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.siemService.GetForwarder(r.Context(), tenantID, forwarderID)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.siemService.DeleteForwarder(r.Context(), tenantID, forwarderID); err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "SIEM forwarder deleted successfully",
+	})
+}
+
+// TestForwarder sends a test event through a SIEM forwarder
+// POST /api/siem-forwarders/{id}/test
+func (h *SIEMHandler) TestForwarder(w http.ResponseWriter, r *http.Request) {
+	forwarderID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid forwarder ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), forwarderID)
+
+	result, err := h.siemService.TestForwarder(r.Context(), tenantID, userID, forwarderID)
+	if err != nil {
+		respondSIEMError(w, err)
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), result)
+
+	utils.Success(w, map[string]interface{}{
+		"test": result,
+	})
+}
+
+// validateSIEMForwarderRequest checks the fields of a SIEM forwarder request;
+// the service checks the endpoint, topic and actions
+func validateSIEMForwarderRequest(req *models.SIEMForwarderRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	utils.ValidateEnum("transport", req.Transport, models.SIEMTransports, "Transport", &errors)
+	if req.Format == "" {
+		req.Format = models.SIEMFormatCEF
+	}
+	utils.ValidateEnum("format", req.Format, models.SIEMFormats, "Format", &errors)
+	utils.ValidateRequired("endpoint", req.Endpoint, "Endpoint", &errors)
+	utils.ValidateStringLength("endpoint", req.Endpoint, 1, 500, "Endpoint", &errors)
+	return errors
+}
+
+// respondSIEMError maps SIEM service errors to HTTP responses
+func respondSIEMError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "SIEM forwarder operation failed")
+}
+
+// RegisterRoutes registers the SIEM forwarder routes
+func (h *SIEMHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/siem-forwarders", func(r chi.Router) {
+		// All SIEM forwarder routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Viewing forwarders requires security.view_logs; changing where the
+		// audit log is sent requires security.export
+		r.With(permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionViewLogs)).Get("/", h.ListForwarders)
+		r.With(permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionViewLogs)).Get("/{id}", h.GetForwarder)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport),
+			auditMiddleware.Record(models.ActionSIEMForwarderCreated, models.ResourceSecurity),
+		).Post("/", h.CreateForwarder)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport),
+			auditMiddleware.Record(models.ActionSIEMForwarderUpdated, models.ResourceSecurity),
+		).Put("/{id}", h.UpdateForwarder)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport),
+			auditMiddleware.Record(models.ActionSIEMForwarderDeleted, models.ResourceSecurity),
+		).Delete("/{id}", h.DeleteForwarder)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSecurity, models.ActionExport),
+			auditMiddleware.Record(models.ActionSIEMForwarderTested, models.ResourceSecurity),
+		).Post("/{id}/test", h.TestForwarder)
+	})
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// SSOHandler handles the tenant's single sign-on settings and providers. The
+// sign-on itself is served by the auth handler.
+type SSOHandler struct {
+	authService services.AuthManager
+}
+
+// NewSSOHandler creates a new SSO handler
+func NewSSOHandler(authService services.AuthManager) *SSOHandler {
+	return &SSOHandler{
+		authService: authService,
+	}
+}
+
+// GetSettings retrieves the tenant's single sign-on settings and providers
+// GET /api/settings/sso
+func (h *SSOHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.authService.GetSSOSettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get sso settings")
+		return
+	}
+
+	utils.Success(w, settings)
+}
+
+// UpdateSettings changes whether the tenant's users must sign in with single
+// sign-on
+// PUT /api/settings/sso
+func (h *SSOHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.SSOSettingsUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.authService.UpdateSSOSettings(r.Context(), tenantID, &req)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"sso_required": settings.SSORequired})
+
+	utils.Success(w, settings)
+}
+
+// GetProvider retrieves a provider
+// GET /api/settings/sso/providers/{id}
+func (h *SSOHandler) GetProvider(w http.ResponseWriter, r *http.Request) {
+	providerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid provider ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	provider, err := h.authService.GetSSOProvider(r.Context(), tenantID, providerID)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"provider": provider,
+	})
+}
+
+// CreateProvider configures a provider. An OpenID Connect issuer must serve
+// a discovery document; a SAML identity provider is configured from its
+// metadata.
+// POST /api/settings/sso/providers
+func (h *SSOHandler) CreateProvider(w http.ResponseWriter, r *http.Request) {
+	var req models.SSOProviderCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateSlug("slug", req.Slug, &errors)
+	utils.ValidateStringLength("slug", req.Slug, 3, 50, "Slug", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 100, "Name", &errors)
+	if req.Protocol == models.SSOProtocolSAML {
+		utils.ValidateRequired("saml_metadata_xml", req.SAMLMetadataXML, "SAML metadata", &errors)
+	} else {
+		utils.ValidateRequired("issuer_url", req.IssuerURL, "Issuer URL", &errors)
+		utils.ValidateRequired("client_id", req.ClientID, "Client ID", &errors)
+	}
+	validateSSOScopes(req.Scopes, &errors)
+	validateSSODomains(req.AllowedDomains, &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	provider, err := h.authService.CreateSSOProvider(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), provider.ID)
+	middleware.SetAuditAfter(r.Context(), provider)
+
+	utils.Created(w, map[string]interface{}{
+		"provider": provider,
+	})
+}
+
+// UpdateProvider changes a provider
+// PUT /api/settings/sso/providers/{id}
+func (h *SSOHandler) UpdateProvider(w http.ResponseWriter, r *http.Request) {
+	providerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid provider ID")
+		return
+	}
+
+	var req models.SSOProviderUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 100, "Name", &errors)
+	}
+	if req.IssuerURL != nil {
+		utils.ValidateRequired("issuer_url", *req.IssuerURL, "Issuer URL", &errors)
+	}
+	if req.ClientID != nil {
+		utils.ValidateRequired("client_id", *req.ClientID, "Client ID", &errors)
+	}
+	if req.Scopes != nil {
+		validateSSOScopes(*req.Scopes, &errors)
+	}
+	if req.AllowedDomains != nil {
+		validateSSODomains(*req.AllowedDomains, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.authService.GetSSOProvider(r.Context(), tenantID, providerID)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	provider, err := h.authService.UpdateSSOProvider(r.Context(), tenantID, providerID, &req)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), provider)
+
+	utils.Success(w, map[string]interface{}{
+		"provider": provider,
+	})
+}
+
+// DeleteProvider removes a provider. Users linked through it keep their
+// accounts.
+// DELETE /api/settings/sso/providers/{id}
+func (h *SSOHandler) DeleteProvider(w http.ResponseWriter, r *http.Request) {
+	providerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid provider ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	provider, err := h.authService.DeleteSSOProvider(r.Context(), tenantID, providerID)
+	if err != nil {
+		respondSSOError(w, err)
+		return
+	}
+
+	middleware.SetAuditBefore(r.Context(), provider)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "SSO provider deleted successfully",
+	})
+}
+
+// validateSSOScopes checks the requested scopes fit in the provider
+func validateSSOScopes(scopes string, errors *utils.ValidationErrors) {
+	if len(scopes) > 255 {
+		errors.Add("scopes", "Scopes must be at most 255 characters")
+	}
+}
+
+// validateSSODomains checks allowed email domains look like domains
+func validateSSODomains(domains []string, errors *utils.ValidationErrors) {
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.TrimSpace(domain), "@")
+		if domain == "" || !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@/ ") {
+			errors.Add("allowed_domains", "Allowed domains must be email domains, e.g. example.com")
+			return
+		}
+	}
+}
+
+func respondSSOError(w http.ResponseWriter, err error) {
+	switch msg := err.Error(); {
+	case msg == "sso provider not found":
+		utils.NotFound(w, msg)
+	case msg == "sso provider slug already exists":
+		utils.Conflict(w, msg)
+	case msg == "an enabled sso provider is required",
+		msg == "the last sso provider cannot be disabled while sso is required":
+		utils.Conflict(w, msg)
+	case msg == "default role not found":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"default_role_id": "Default role not found"})
+	case msg == "mapped role not found":
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"saml_role_mapping": "Mapped role not found"})
+	case strings.HasPrefix(msg, "invalid saml metadata"):
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"saml_metadata_xml": msg})
+	case strings.HasPrefix(msg, "invalid protocol"):
+		utils.BadRequest(w, msg)
+	case msg == "saml single sign-on is not available on this plan":
+		utils.Forbidden(w, msg)
+	case strings.HasPrefix(msg, "invalid issuer"), strings.HasPrefix(msg, "issuer discovery failed"):
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"issuer_url": msg})
+	default:
+		utils.InternalServerError(w, "SSO operation failed")
+	}
+}
+
+// RegisterRoutes registers the single sign-on settings routes
+func (h *SSOHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/sso", func(r chi.Router) {
+		// All SSO settings routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Settings and providers - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetSettings)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/providers/{id}", h.GetProvider)
+
+		// Changes - require settings edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionSSOSettingsUpdated, models.ResourceSettings),
+		).Put("/", h.UpdateSettings)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionSSOProviderCreated, models.ResourceSettings),
+		).Post("/providers", h.CreateProvider)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionSSOProviderUpdated, models.ResourceSettings),
+		).Put("/providers/{id}", h.UpdateProvider)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionSSOProviderDeleted, models.ResourceSettings),
+		).Delete("/providers/{id}", h.DeleteProvider)
+	})
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// SupplierHandler handles supplier (vendor) endpoints
+type SupplierHandler struct {
+	purchaseOrderService services.PurchaseOrderManager
+}
+
+// NewSupplierHandler creates a new supplier handler
+func NewSupplierHandler(purchaseOrderService services.PurchaseOrderManager) *SupplierHandler {
+	return &SupplierHandler{
+		purchaseOrderService: purchaseOrderService,
+	}
+}
+
+// List lists suppliers
+// GET /api/purchasing/suppliers?page=1&page_size=20&status=active&search=acme
+func (h *SupplierHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	suppliers, totalCount, err := h.purchaseOrderService.ListSuppliers(
+		r.Context(),
+		tenantID,
+		r.URL.Query().Get("status"),
+		r.URL.Query().Get("search"),
+		pageSize,
+		offset,
+	)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list suppliers")
+		return
+	}
+
+	utils.SuccessWithMeta(w, suppliers, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// Get retrieves a supplier
+// GET /api/purchasing/suppliers/{id}
+func (h *SupplierHandler) Get(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	supplier, err := h.purchaseOrderService.GetSupplier(r.Context(), tenantID, supplierID)
+	if err != nil {
+		utils.NotFound(w, "Supplier not found")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"supplier": supplier,
+	})
+}
+
+// Create creates a supplier. A supplier that looks like existing ones is
+// refused with 409 Conflict and the likely duplicates, unless the request sets
+// allow_duplicate.
+// POST /api/purchasing/suppliers
+func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.SupplierCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", req.Name, "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 1, 255, "Name", &errors)
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+	if req.Currency != "" {
+		utils.ValidateStringLength("currency", req.Currency, 3, 3, "Currency", &errors)
+	}
+	if req.PaymentTermsDays != nil && *req.PaymentTermsDays < 0 {
+		errors.Add("payment_terms_days", "Payment terms cannot be negative")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	supplier, duplicates, err := h.purchaseOrderService.CreateSupplier(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		if err.Error() == "supplier name already exists" {
+			utils.Conflict(w, err.Error())
+			return
+		}
+		utils.InternalServerError(w, "Failed to create supplier")
+		return
+	}
+	if len(duplicates) > 0 {
+		utils.JSON(w, http.StatusConflict, utils.Response{
+			Success: false,
+			Data: map[string]interface{}{
+				"duplicates": duplicates,
+			},
+			Error: &utils.ErrorInfo{
+				Code:    "POSSIBLE_DUPLICATE",
+				Message: "Supplier looks like an existing supplier; set allow_duplicate to create it anyway",
+			},
+		})
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"supplier": supplier,
+		"message":  "Supplier created successfully",
+	})
+}
+
+// Update updates a supplier
+// PUT /api/purchasing/suppliers/{id}
+func (h *SupplierHandler) Update(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	var req models.SupplierUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+	if req.Email != nil && *req.Email != "" {
+		utils.ValidateEmail("email", *req.Email, &errors)
+	}
+	if req.Currency != nil {
+		utils.ValidateStringLength("currency", *req.Currency, 3, 3, "Currency", &errors)
+	}
+	if req.PaymentTermsDays != nil && *req.PaymentTermsDays < 0 {
+		errors.Add("payment_terms_days", "Payment terms cannot be negative")
+	}
+	if req.Status != nil {
+		utils.ValidateEnum("status", *req.Status, []string{models.SupplierStatusActive, models.SupplierStatusInactive}, "Status", &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	supplier, err := h.purchaseOrderService.UpdateSupplier(r.Context(), tenantID, userID, supplierID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "supplier not found":
+			utils.NotFound(w, "Supplier not found")
+		case "supplier name already exists":
+			utils.Conflict(w, err.Error())
+		default:
+			utils.InternalServerError(w, "Failed to update supplier")
+		}
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"supplier": supplier,
+		"message":  "Supplier updated successfully",
+	})
+}
+
+// Delete deletes a supplier without purchase orders
+// DELETE /api/purchasing/suppliers/{id}
+func (h *SupplierHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.purchaseOrderService.DeleteSupplier(r.Context(), tenantID, userID, supplierID); err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Supplier deleted successfully",
+	})
+}
+
+// FindDuplicates lists the suppliers that look like the same vendor as the
+// given details, e.g. while a supplier is being entered
+// GET /api/purchasing/suppliers/duplicates?name=acme&email=ap@acme.com&tax_id=FR123
+func (h *SupplierHandler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	duplicates, err := h.purchaseOrderService.FindSupplierDuplicates(r.Context(), tenantID, models.SupplierDuplicateQuery{
+		Name:  r.URL.Query().Get("name"),
+		Email: r.URL.Query().Get("email"),
+		TaxID: r.URL.Query().Get("tax_id"),
+	})
+	if err != nil {
+		utils.InternalServerError(w, "Failed to find duplicate suppliers")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"duplicates": duplicates,
+	})
+}
+
+// GetDuplicates lists the other suppliers that look like the same vendor as a
+// supplier
+// GET /api/purchasing/suppliers/{id}/duplicates
+func (h *SupplierHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	duplicates, err := h.purchaseOrderService.FindDuplicatesOfSupplier(r.Context(), tenantID, supplierID)
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			utils.NotFound(w, "Supplier not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to find duplicate suppliers")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"duplicates": duplicates,
+	})
+}
+
+// Merge merges a duplicate supplier (source_id) into this one. The duplicate's
+// documents move to this supplier and the duplicate is deleted.
+// POST /api/purchasing/suppliers/{id}/merge
+func (h *SupplierHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	var req models.SupplierMergeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.SourceID == uuid.Nil {
+		errors.Add("source_id", "Supplier to merge is required")
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	supplier, merge, err := h.purchaseOrderService.MergeSuppliers(r.Context(), tenantID, userID, supplierID, req.SourceID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"supplier": supplier,
+		"merge":    merge,
+		"message":  "Suppliers merged successfully",
+	})
+}
+
+// ListMerges lists the suppliers merged into a supplier, newest first
+// GET /api/purchasing/suppliers/{id}/merges
+func (h *SupplierHandler) ListMerges(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid supplier ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	merges, err := h.purchaseOrderService.ListSupplierMerges(r.Context(), tenantID, supplierID)
+	if err != nil {
+		respondPurchasingError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"merges": merges,
+	})
+}
+
+// RegisterRoutes registers supplier routes
+func (h *SupplierHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, navMiddleware *middleware.NavigationMiddleware) {
+	r.Route("/purchasing/suppliers", func(r chi.Router) {
+		// All supplier routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/", h.List)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionCreate)).Post("/", h.Create)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView), navMiddleware.TrackView(models.NavigationEntitySupplier)).Get("/{id}", h.Get)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionEdit)).Put("/{id}", h.Update)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionDelete)).Delete("/{id}", h.Delete)
+
+		// Duplicates - merging deletes the duplicate, so it also requires delete permission
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/duplicates", h.FindDuplicates)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/{id}/duplicates", h.GetDuplicates)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionEdit), permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionDelete)).Post("/{id}/merge", h.Merge)
+		r.With(permMiddleware.RequirePermission(models.ResourcePurchasing, models.ActionView)).Get("/{id}/merges", h.ListMerges)
+	})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// TenantDomainHandler handles the tenant's custom domains and slug. A domain
+// is added unverified; once its TXT record is in place, verifying it makes
+// requests to the domain resolve to the tenant.
+type TenantDomainHandler struct {
+	tenantDomainService services.TenantDomainManager
+}
+
+// NewTenantDomainHandler creates a new tenant domain handler
+func NewTenantDomainHandler(tenantDomainService services.TenantDomainManager) *TenantDomainHandler {
+	return &TenantDomainHandler{
+		tenantDomainService: tenantDomainService,
+	}
+}
+
+// ListDomains retrieves the tenant's custom domains with the TXT record each
+// needs
+// GET /api/settings/domains
+func (h *TenantDomainHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	domains, err := h.tenantDomainService.ListDomains(r.Context(), tenantID)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"domains": domains,
+	})
+}
+
+// GetDomain retrieves a custom domain
+// GET /api/settings/domains/{id}
+func (h *TenantDomainHandler) GetDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid domain ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	domain, err := h.tenantDomainService.GetDomain(r.Context(), tenantID, domainID)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"domain": domain,
+	})
+}
+
+// AddDomain adds a custom domain, returning the TXT record to create
+// POST /api/settings/domains
+func (h *TenantDomainHandler) AddDomain(w http.ResponseWriter, r *http.Request) {
+	var req models.TenantDomainCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("domain", req.Domain, "Domain", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	domain, err := h.tenantDomainService.AddDomain(r.Context(), tenantID, userID, req.Domain)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), domain.ID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"domain": domain.Domain})
+
+	utils.Created(w, map[string]interface{}{
+		"domain": domain,
+	})
+}
+
+// VerifyDomain checks the domain's TXT record. The domain is returned either
+// way; verified_at tells whether the record was found.
+// POST /api/settings/domains/{id}/verify
+func (h *TenantDomainHandler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid domain ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	domain, err := h.tenantDomainService.VerifyDomain(r.Context(), tenantID, domainID)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), domain.ID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"domain": domain.Domain, "verified": domain.IsVerified()})
+
+	utils.Success(w, map[string]interface{}{
+		"domain":   domain,
+		"verified": domain.IsVerified(),
+	})
+}
+
+// RemoveDomain removes a custom domain
+// DELETE /api/settings/domains/{id}
+func (h *TenantDomainHandler) RemoveDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid domain ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	domain, err := h.tenantDomainService.RemoveDomain(r.Context(), tenantID, domainID)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), domain.ID)
+	middleware.SetAuditBefore(r.Context(), map[string]interface{}{"domain": domain.Domain})
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Domain removed successfully",
+	})
+}
+
+// ChangeSlug renames the tenant's slug. The old slug keeps resolving to the
+// tenant until redirect_until.
+// PATCH /api/tenant/slug
+func (h *TenantDomainHandler) ChangeSlug(w http.ResponseWriter, r *http.Request) {
+	var req models.TenantSlugChangeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("slug", req.Slug, "Slug", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	change, err := h.tenantDomainService.ChangeSlug(r.Context(), tenantID, userID, req.Slug)
+	if err != nil {
+		respondTenantDomainError(w, err)
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), map[string]interface{}{"slug": change.PreviousSlug})
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"slug": change.Slug, "redirect_until": change.RedirectUntil})
+
+	utils.Success(w, change)
+}
+
+// respondTenantDomainError maps tenant domain service errors to HTTP responses
+func respondTenantDomainError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Domain operation failed")
+}
+
+// RegisterRoutes registers the custom domain and slug routes
+func (h *TenantDomainHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/domains", func(r chi.Router) {
+		// All domain routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// List and get domains - requires settings view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.ListDomains)
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/{id}", h.GetDomain)
+
+		// Changes - require settings edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDomainAdded, models.ResourceSettings),
+		).Post("/", h.AddDomain)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDomainVerified, models.ResourceSettings),
+		).Post("/{id}/verify", h.VerifyDomain)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionDomainRemoved, models.ResourceSettings),
+		).Delete("/{id}", h.RemoveDomain)
+	})
+
+	// Renaming the slug moves the tenant's subdomain - requires settings edit
+	// permission
+	r.With(
+		authMiddleware.Authenticate,
+		permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+		auditMiddleware.Record(models.ActionTenantSlugChanged, models.ResourceSettings),
+	).Patch("/tenant/slug", h.ChangeSlug)
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+var (
+	colorRegex       = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	currencyRegex    = regexp.MustCompile(`^[A-Z]{3}$`)
+	featureNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+)
+
+// TenantSettingsHandler handles the tenant's typed settings
+type TenantSettingsHandler struct {
+	service services.TenantSettingsManager
+}
+
+// NewTenantSettingsHandler creates a new tenant settings handler
+func NewTenantSettingsHandler(service services.TenantSettingsManager) *TenantSettingsHandler {
+	return &TenantSettingsHandler{service: service}
+}
+
+// GetSettings returns the tenant's settings. The ETag header carries their
+// version, to send back in If-Match when changing them.
+// GET /api/tenant/settings
+func (h *TenantSettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get tenant settings")
+		return
+	}
+
+	w.Header().Set("ETag", `"`+settings.Version+`"`)
+	utils.Success(w, settings)
+}
+
+// PatchSettings applies a JSON merge patch to the branding, locale and
+// features sections of the tenant's settings. The If-Match header must carry
+// the version the change is based on.
+// PATCH /api/tenant/settings
+func (h *TenantSettingsHandler) PatchSettings(w http.ResponseWriter, r *http.Request) {
+	version := strings.Trim(strings.TrimPrefix(r.Header.Get("If-Match"), "W/"), `"`)
+	if version == "" {
+		utils.JSON(w, http.StatusPreconditionRequired, utils.Response{
+			Success: false,
+			Error: &utils.ErrorInfo{
+				Code:    "PRECONDITION_REQUIRED",
+				Message: "Send the settings version in If-Match",
+			},
+		})
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := utils.ParseJSONBody(r, &patch); err != nil || patch == nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	for key := range patch {
+		if endpoint, ok := models.TenantSettingsSecurityKeys[key]; ok {
+			errors.Add(key, "Change it through "+endpoint)
+			continue
+		}
+		if !isTenantSettingsSection(key) {
+			errors.Add(key, "Unknown setting")
+		}
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.service.GetSettings(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get tenant settings")
+		return
+	}
+	if before.Version != version {
+		respondSettingsVersionMismatch(w)
+		return
+	}
+
+	updated, err := h.service.ApplyPatch(before, patch)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid ") {
+			section := strings.TrimPrefix(err.Error(), "invalid ")
+			utils.UnprocessableEntity(w, "Validation failed", map[string]string{section: "Does not match the settings schema"})
+			return
+		}
+		utils.InternalServerError(w, "Failed to update tenant settings")
+		return
+	}
+
+	validateTenantSettings(updated, &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	settings, err := h.service.SaveSettings(r.Context(), tenantID, version, updated)
+	if err != nil {
+		if err.Error() == "settings version mismatch" {
+			respondSettingsVersionMismatch(w)
+			return
+		}
+		utils.InternalServerError(w, "Failed to update tenant settings")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), before)
+	middleware.SetAuditAfter(r.Context(), settings)
+
+	w.Header().Set("ETag", `"`+settings.Version+`"`)
+	utils.Success(w, settings)
+}
+
+// UploadLogo replaces the tenant's logo with a PNG, JPEG, GIF or WebP image
+// sent in the file field of a multipart form
+// POST /api/tenant/settings/logo
+func (h *TenantSettingsHandler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	actorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	maxSize := h.service.MaxLogoSize()
+	tooLarge := map[string]string{"file": fmt.Sprintf("Image must not exceed %d MB", maxSize>>20)}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+avatarFormOverhead)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+			return
+		}
+		utils.BadRequest(w, "A PNG, JPEG, GIF or WebP image is required in the file field")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.BadRequest(w, "Failed to read the file")
+		return
+	}
+	if int64(len(data)) > maxSize {
+		utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+		return
+	}
+
+	settings, err := h.service.UploadLogo(r.Context(), tenantID, actorID, header.Filename, data)
+	if err != nil {
+		respondFileError(w, err, "Failed to upload logo")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"logo_url": settings.Branding.LogoURL})
+
+	w.Header().Set("ETag", `"`+settings.Version+`"`)
+	utils.Success(w, settings)
+}
+
+// RemoveLogo removes the tenant's logo
+// DELETE /api/tenant/settings/logo
+func (h *TenantSettingsHandler) RemoveLogo(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	actorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	settings, err := h.service.RemoveLogo(r.Context(), tenantID, actorID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to remove logo")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+
+	w.Header().Set("ETag", `"`+settings.Version+`"`)
+	utils.Success(w, settings)
+}
+
+// isTenantSettingsSection reports whether a settings key is changed through
+// the tenant settings API
+func isTenantSettingsSection(key string) bool {
+	for _, section := range models.TenantSettingsSections {
+		if key == section {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTenantSettings checks the sections of the settings the API changes
+func validateTenantSettings(settings *models.TenantSettings, errors *utils.ValidationErrors) {
+	branding := settings.Branding
+	if branding.Name != nil {
+		utils.ValidateStringLength("branding.name", *branding.Name, 1, models.MaxBrandingNameLength, "Brand name", errors)
+	}
+	if branding.Footer != nil {
+		utils.ValidateStringLength("branding.footer", *branding.Footer, 1, models.MaxBrandingFooterLength, "Footer", errors)
+	}
+	if branding.LogoURL != nil {
+		logoURL := *branding.LogoURL
+		if !strings.HasPrefix(logoURL, "https://") && !strings.HasPrefix(logoURL, "http://") {
+			errors.Add("branding.logo_url", "Logo URL must be an http or https URL")
+		}
+		utils.ValidateStringLength("branding.logo_url", logoURL, 1, 500, "Logo URL", errors)
+	}
+	if branding.PrimaryColor != nil && !colorRegex.MatchString(*branding.PrimaryColor) {
+		errors.Add("branding.primary_color", "Color must be a hex color like #1A73E8")
+	}
+	if branding.AccentColor != nil && !colorRegex.MatchString(*branding.AccentColor) {
+		errors.Add("branding.accent_color", "Color must be a hex color like #1A73E8")
+	}
+
+	locale := settings.Locale
+	utils.ValidateEnum("locale.language", locale.Language, models.TenantLanguages, "Language", errors)
+	if _, err := time.LoadLocation(locale.Timezone); err != nil {
+		errors.Add("locale.timezone", "Unknown timezone")
+	}
+	if !currencyRegex.MatchString(locale.Currency) {
+		errors.Add("locale.currency", "Currency must be an ISO 4217 code like DZD")
+	}
+	utils.ValidateEnum("locale.date_format", locale.DateFormat, models.TenantDateFormats, "Date format", errors)
+
+	if len(settings.Features) > models.MaxTenantFeatures {
+		errors.Add("features", fmt.Sprintf("At most %d feature toggles are allowed", models.MaxTenantFeatures))
+	}
+	for name := range settings.Features {
+		if !featureNameRegex.MatchString(name) {
+			errors.Add("features."+name, "Feature names must be lowercase letters, digits and underscores")
+		}
+	}
+}
+
+// respondSettingsVersionMismatch writes the response for a settings change
+// based on a version that is no longer current
+func respondSettingsVersionMismatch(w http.ResponseWriter) {
+	utils.JSON(w, http.StatusPreconditionFailed, utils.Response{
+		Success: false,
+		Error: &utils.ErrorInfo{
+			Code:    "PRECONDITION_FAILED",
+			Message: "The settings were changed in the meantime, reload them and try again",
+		},
+	})
+}
+
+// RegisterRoutes registers the tenant settings routes
+func (h *TenantSettingsHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/tenant/settings", func(r chi.Router) {
+		// All tenant settings routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetSettings)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionTenantSettingsUpdated, models.ResourceSettings),
+		).Patch("/", h.PatchSettings)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionTenantLogoUpdated, models.ResourceSettings),
+		).Post("/logo", h.UploadLogo)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionTenantLogoUpdated, models.ResourceSettings),
+		).Delete("/logo", h.RemoveLogo)
+	})
+}
@@ -0,0 +1,982 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// maxTimesheetReportDays bounds the period of a billing report
+const maxTimesheetReportDays = 366
+
+// TimesheetHandler handles timesheet project, entry, approval and report
+// endpoints
+type TimesheetHandler struct {
+	timesheetService services.TimesheetManager
+}
+
+// NewTimesheetHandler creates a new timesheet handler
+func NewTimesheetHandler(timesheetService services.TimesheetManager) *TimesheetHandler {
+	return &TimesheetHandler{
+		timesheetService: timesheetService,
+	}
+}
+
+// ListProjects lists the tenant's projects with their tasks, active ones
+// unless include_inactive is set
+// GET /api/timesheets/projects?include_inactive=true&customer_id=uuid
+func (h *TimesheetHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	activeOnly := r.URL.Query().Get("include_inactive") != "true"
+
+	var customerID *uuid.UUID
+	if value := r.URL.Query().Get("customer_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid customer ID")
+			return
+		}
+		customerID = &parsed
+	}
+
+	projects, err := h.timesheetService.ListProjects(r.Context(), tenantID, activeOnly, customerID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list projects")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// GetProject retrieves a project with all its tasks
+// GET /api/timesheets/projects/{id}
+func (h *TimesheetHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	project, err := h.timesheetService.GetProject(r.Context(), tenantID, projectID)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to retrieve project")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"project": project,
+	})
+}
+
+// CreateProject creates a project
+// POST /api/timesheets/projects
+func (h *TimesheetHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req models.TimesheetProjectRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetProjectRequest(&req, true)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	creatorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	project, err := h.timesheetService.CreateProject(r.Context(), tenantID, creatorID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to create project")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), project.ID)
+	middleware.SetAuditAfter(r.Context(), project)
+
+	utils.Created(w, map[string]interface{}{
+		"project": project,
+		"message": "Project created successfully",
+	})
+}
+
+// UpdateProject updates a project
+// PUT /api/timesheets/projects/{id}
+func (h *TimesheetHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	var req models.TimesheetProjectRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetProjectRequest(&req, false)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.timesheetService.GetProject(r.Context(), tenantID, projectID)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to retrieve project")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), projectID)
+	middleware.SetAuditBefore(r.Context(), before)
+
+	project, err := h.timesheetService.UpdateProject(r.Context(), tenantID, projectID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to update project")
+		return
+	}
+	middleware.SetAuditAfter(r.Context(), project)
+
+	utils.Success(w, map[string]interface{}{
+		"project": project,
+		"message": "Project updated successfully",
+	})
+}
+
+// DeleteProject deletes a project nobody logged time on
+// DELETE /api/timesheets/projects/{id}
+func (h *TimesheetHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.timesheetService.DeleteProject(r.Context(), tenantID, projectID); err != nil {
+		respondTimesheetError(w, err, "Failed to delete project")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), projectID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Project deleted successfully",
+	})
+}
+
+// CreateTask adds a task to a project
+// POST /api/timesheets/projects/{id}/tasks
+func (h *TimesheetHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	var req models.TimesheetTaskRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetTaskRequest(&req, true)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	task, err := h.timesheetService.CreateTask(r.Context(), tenantID, projectID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to create task")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), task.ID)
+	middleware.SetAuditAfter(r.Context(), task)
+
+	utils.Created(w, map[string]interface{}{
+		"task":    task,
+		"message": "Task created successfully",
+	})
+}
+
+// UpdateTask updates a project's task
+// PUT /api/timesheets/projects/{id}/tasks/{taskId}
+func (h *TimesheetHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, ok := timesheetTaskParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TimesheetTaskRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetTaskRequest(&req, false)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	task, err := h.timesheetService.UpdateTask(r.Context(), tenantID, projectID, taskID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to update task")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), task.ID)
+	middleware.SetAuditAfter(r.Context(), task)
+
+	utils.Success(w, map[string]interface{}{
+		"task":    task,
+		"message": "Task updated successfully",
+	})
+}
+
+// DeleteTask deletes a project's task nobody logged time on
+// DELETE /api/timesheets/projects/{id}/tasks/{taskId}
+func (h *TimesheetHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, ok := timesheetTaskParams(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.timesheetService.DeleteTask(r.Context(), tenantID, projectID, taskID); err != nil {
+		respondTimesheetError(w, err, "Failed to delete task")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), taskID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Task deleted successfully",
+	})
+}
+
+// Week retrieves the timesheet of the week containing date (today by
+// default) with its entries. Without employee_id, the current user's own.
+// GET /api/timesheets/week?date=2026-03-04&employee_id=uuid
+func (h *TimesheetHandler) Week(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	var employeeID *uuid.UUID
+	if value := r.URL.Query().Get("employee_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid employee ID")
+			return
+		}
+		employeeID = &parsed
+	}
+
+	day := time.Now().UTC()
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid date, expected YYYY-MM-DD")
+			return
+		}
+		day = parsed
+	}
+
+	timesheet, err := h.timesheetService.Week(r.Context(), tenantID, userID, employeeID, day)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to retrieve timesheet")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"timesheet": timesheet,
+	})
+}
+
+// ListTimesheets lists timesheets. Users without timesheets.view see their
+// own timesheets and those of the employees they manage.
+// GET /api/timesheets?page=1&page_size=20&employee_id=uuid&department_id=uuid&status=submitted&from=2026-01-05&to=2026-03-30
+func (h *TimesheetHandler) ListTimesheets(w http.ResponseWriter, r *http.Request) {
+	h.listTimesheets(w, r, h.timesheetService.List)
+}
+
+// ListOwnTimesheets lists the current user's timesheets
+// GET /api/timesheets/me?page=1&page_size=20&status=rejected&from=2026-01-05&to=2026-03-30
+func (h *TimesheetHandler) ListOwnTimesheets(w http.ResponseWriter, r *http.Request) {
+	h.listTimesheets(w, r, h.timesheetService.ListOwn)
+}
+
+// ListApprovals lists the submitted timesheets the current user may decide
+// GET /api/timesheets/approvals?page=1&page_size=20
+func (h *TimesheetHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	h.listTimesheets(w, r, func(ctx context.Context, tenantID, userID uuid.UUID, _ models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error) {
+		return h.timesheetService.ListApprovals(ctx, tenantID, userID, limit, offset)
+	})
+}
+
+// listTimesheets parses the filters and pagination of a timesheet list
+func (h *TimesheetHandler) listTimesheets(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error)) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	filter := models.TimesheetFilter{
+		Status: r.URL.Query().Get("status"),
+	}
+	if filter.Status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", filter.Status, models.TimesheetStatuses, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+	if value := r.URL.Query().Get("employee_id"); value != "" {
+		employeeID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid employee ID")
+			return
+		}
+		filter.EmployeeID = &employeeID
+	}
+	if value := r.URL.Query().Get("department_id"); value != "" {
+		departmentID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid department ID")
+			return
+		}
+		filter.DepartmentID = &departmentID
+	}
+	if value := r.URL.Query().Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid from date, expected YYYY-MM-DD")
+			return
+		}
+		filter.From = &from
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid to date, expected YYYY-MM-DD")
+			return
+		}
+		filter.To = &to
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	timesheets, totalCount, err := list(r.Context(), tenantID, userID, filter, pageSize, offset)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to list timesheets")
+		return
+	}
+
+	utils.SuccessWithMeta(w, timesheets, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetTimesheet retrieves a timesheet with its entries
+// GET /api/timesheets/{id}
+func (h *TimesheetHandler) GetTimesheet(w http.ResponseWriter, r *http.Request) {
+	timesheetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid timesheet ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	timesheet, err := h.timesheetService.Get(r.Context(), tenantID, userID, timesheetID)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to retrieve timesheet")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"timesheet": timesheet,
+	})
+}
+
+// CreateEntry logs time on the current user's timesheet for the week of the
+// work date
+// POST /api/timesheets/entries
+func (h *TimesheetHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req models.TimesheetEntryRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetEntryRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.timesheetService.AddEntry(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to log time")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), entry.ID)
+	middleware.SetAuditAfter(r.Context(), entry)
+
+	utils.Created(w, map[string]interface{}{
+		"entry":   entry,
+		"message": "Time logged successfully",
+	})
+}
+
+// UpdateEntry changes an entry of the current user's timesheet
+// PUT /api/timesheets/entries/{id}
+func (h *TimesheetHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid timesheet entry ID")
+		return
+	}
+
+	var req models.TimesheetEntryRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := validateTimesheetEntryRequest(&req)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.timesheetService.UpdateEntry(r.Context(), tenantID, userID, entryID, &req)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to update timesheet entry")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), entry.ID)
+	middleware.SetAuditAfter(r.Context(), entry)
+
+	utils.Success(w, map[string]interface{}{
+		"entry":   entry,
+		"message": "Timesheet entry updated successfully",
+	})
+}
+
+// DeleteEntry removes an entry from the current user's timesheet
+// DELETE /api/timesheets/entries/{id}
+func (h *TimesheetHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid timesheet entry ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	entry, err := h.timesheetService.DeleteEntry(r.Context(), tenantID, userID, entryID)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to delete timesheet entry")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), entry.ID)
+	middleware.SetAuditBefore(r.Context(), entry)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Timesheet entry deleted successfully",
+	})
+}
+
+// Submit sends the current user's timesheet for approval
+// POST /api/timesheets/{id}/submit
+func (h *TimesheetHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	timesheetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid timesheet ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	timesheet, err := h.timesheetService.Submit(r.Context(), tenantID, userID, timesheetID)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to submit timesheet")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), timesheet.ID)
+	middleware.SetAuditAfter(r.Context(), timesheet)
+
+	utils.Success(w, map[string]interface{}{
+		"timesheet": timesheet,
+		"message":   "Timesheet submitted for approval",
+	})
+}
+
+// Approve approves a submitted timesheet
+// POST /api/timesheets/{id}/approve
+func (h *TimesheetHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.timesheetService.Approve, "Timesheet approved")
+}
+
+// Reject sends a submitted timesheet back to the employee
+// POST /api/timesheets/{id}/reject
+func (h *TimesheetHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.timesheetService.Reject, "Timesheet rejected")
+}
+
+// Reopen returns an approved timesheet to draft
+// POST /api/timesheets/{id}/reopen
+func (h *TimesheetHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.timesheetService.Reopen, "Timesheet reopened")
+}
+
+// decide records a decision with an optional note
+func (h *TimesheetHandler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error), message string) {
+	timesheetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid timesheet ID")
+		return
+	}
+
+	var req models.TimesheetDecisionRequest
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSONBody(r, &req); err != nil {
+			utils.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	if req.Note != nil {
+		errors := utils.ValidationErrors{}
+		utils.ValidateStringLength("note", *req.Note, 0, 1000, "Note", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	timesheet, err := decide(r.Context(), tenantID, userID, timesheetID, req.Note)
+	if err != nil {
+		respondTimesheetError(w, err, "Failed to decide on timesheet")
+		return
+	}
+	middleware.SetAuditResourceID(r.Context(), timesheet.ID)
+	middleware.SetAuditAfter(r.Context(), timesheet)
+
+	utils.Success(w, map[string]interface{}{
+		"timesheet": timesheet,
+		"message":   message,
+	})
+}
+
+// ProjectReport sums the approved time of a period of up to a year per
+// project, with billable hours and amounts
+// GET /api/timesheets/reports/projects?from=2026-03-01&to=2026-03-31&project_id=uuid&customer_id=uuid&employee_id=uuid&include_unapproved=true
+func (h *TimesheetHandler) ProjectReport(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, h.timesheetService.ProjectReport)
+}
+
+// EmployeeReport sums the approved time of a period of up to a year per
+// employee, with billable hours and amounts
+// GET /api/timesheets/reports/employees?from=2026-03-01&to=2026-03-31&project_id=uuid&customer_id=uuid&employee_id=uuid&include_unapproved=true
+func (h *TimesheetHandler) EmployeeReport(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, h.timesheetService.EmployeeReport)
+}
+
+// report parses the period and filters of a billing report
+func (h *TimesheetHandler) report(w http.ResponseWriter, r *http.Request, build func(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error)) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", r.URL.Query().Get("from"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid from date, expected YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", r.URL.Query().Get("to"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid to date, expected YYYY-MM-DD")
+		return
+	}
+	if to.Before(from) || to.Sub(from) > maxTimesheetReportDays*24*time.Hour {
+		utils.BadRequest(w, "The period must end after it starts and span at most a year")
+		return
+	}
+
+	filter := models.TimesheetReportFilter{
+		From:              from,
+		To:                to,
+		IncludeUnapproved: r.URL.Query().Get("include_unapproved") == "true",
+	}
+	if value := r.URL.Query().Get("project_id"); value != "" {
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid project ID")
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+	if value := r.URL.Query().Get("customer_id"); value != "" {
+		customerID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid customer ID")
+			return
+		}
+		filter.CustomerID = &customerID
+	}
+	if value := r.URL.Query().Get("employee_id"); value != "" {
+		employeeID, err := uuid.Parse(value)
+		if err != nil {
+			utils.BadRequest(w, "Invalid employee ID")
+			return
+		}
+		filter.EmployeeID = &employeeID
+	}
+
+	rows, err := build(r.Context(), tenantID, filter)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to build timesheet report")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"from":               from.Format("2006-01-02"),
+		"to":                 to.Format("2006-01-02"),
+		"include_unapproved": filter.IncludeUnapproved,
+		"rows":               rows,
+	})
+}
+
+// timesheetTaskParams parses the project and task IDs of a task route
+func timesheetTaskParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid project ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskId"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid task ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return projectID, taskID, true
+}
+
+// validateTimesheetProjectRequest validates a project request. Code and name
+// are required on create.
+func validateTimesheetProjectRequest(req *models.TimesheetProjectRequest, create bool) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	if create {
+		if req.Code == nil {
+			errors.Add("code", "Code is required")
+		}
+		if req.Name == nil {
+			errors.Add("name", "Name is required")
+		}
+	}
+	if req.Code != nil {
+		utils.ValidateStringLength("code", *req.Code, 1, 30, "Code", &errors)
+	}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+	if req.HourlyRate != nil && *req.HourlyRate < 0 {
+		errors.Add("hourly_rate", "Hourly rate cannot be negative")
+	}
+	if req.Currency != nil {
+		utils.ValidateStringLength("currency", *req.Currency, 3, 3, "Currency", &errors)
+	}
+	return errors
+}
+
+// validateTimesheetTaskRequest validates a task request. Name is required on
+// create.
+func validateTimesheetTaskRequest(req *models.TimesheetTaskRequest, create bool) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	if create && req.Name == nil {
+		errors.Add("name", "Name is required")
+	}
+	if req.Name != nil {
+		utils.ValidateStringLength("name", *req.Name, 1, 255, "Name", &errors)
+	}
+	if req.HourlyRate != nil && *req.HourlyRate < 0 {
+		errors.Add("hourly_rate", "Hourly rate cannot be negative")
+	}
+	return errors
+}
+
+// validateTimesheetEntryRequest validates a time entry request
+func validateTimesheetEntryRequest(req *models.TimesheetEntryRequest) utils.ValidationErrors {
+	errors := utils.ValidationErrors{}
+	if req.ProjectID == uuid.Nil {
+		errors.Add("project_id", "Project is required")
+	}
+	if req.WorkDate.IsZero() {
+		errors.Add("work_date", "Work date is required")
+	}
+	if req.Hours <= 0 || req.Hours > 24 {
+		errors.Add("hours", "Hours must be more than 0 and at most 24")
+	}
+	if req.Description != nil {
+		utils.ValidateStringLength("description", *req.Description, 0, 1000, "Description", &errors)
+	}
+	return errors
+}
+
+// respondTimesheetError maps timesheet service errors to responses
+func respondTimesheetError(w http.ResponseWriter, err error, fallback string) {
+	switch err.Error() {
+	case "project not found":
+		utils.NotFound(w, "Project not found")
+	case "task not found":
+		utils.NotFound(w, "Task not found")
+	case "timesheet not found":
+		utils.NotFound(w, "Timesheet not found")
+	case "timesheet entry not found":
+		utils.NotFound(w, "Timesheet entry not found")
+	case "customer not found":
+		utils.NotFound(w, "Customer not found")
+	case "employee not found":
+		utils.NotFound(w, "Employee not found")
+	case "no employee record":
+		utils.NotFound(w, "No employee record is linked to your account")
+	case "insufficient permissions":
+		utils.Forbidden(w, "Insufficient permissions")
+	case "cannot decide on own timesheet":
+		utils.Forbidden(w, "You cannot decide on your own timesheet")
+	case "project code already exists", "task name already exists", "project is in use", "task is in use",
+		"timesheet is locked", "timesheet is already submitted", "timesheet is not submitted",
+		"timesheet is not approved":
+		utils.Conflict(w, err.Error())
+	case "project is not active", "task is not active", "work date is outside the employment period",
+		"entry cannot move to another week", "more than 24 hours logged on a day", "timesheet has no entries":
+		utils.BadRequest(w, err.Error())
+	default:
+		utils.InternalServerError(w, fallback)
+	}
+}
+
+// RegisterRoutes registers timesheet routes. Every employee logs and submits
+// their own time and managers decide for their direct reports; timesheets.*
+// permissions are for HR and billing.
+func (h *TimesheetHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/timesheets", func(r chi.Router) {
+		// All timesheet routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Route("/projects", func(r chi.Router) {
+			r.Get("/", h.ListProjects)
+			r.Get("/{id}", h.GetProject)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetProjectCreated, models.ResourceTimesheets),
+			).Post("/", h.CreateProject)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetProjectUpdated, models.ResourceTimesheets),
+			).Put("/{id}", h.UpdateProject)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetProjectDeleted, models.ResourceTimesheets),
+			).Delete("/{id}", h.DeleteProject)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetTaskCreated, models.ResourceTimesheets),
+			).Post("/{id}/tasks", h.CreateTask)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetTaskUpdated, models.ResourceTimesheets),
+			).Put("/{id}/tasks/{taskId}", h.UpdateTask)
+
+			r.With(
+				permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+				auditMiddleware.Record(models.ActionTimesheetTaskDeleted, models.ResourceTimesheets),
+			).Delete("/{id}/tasks/{taskId}", h.DeleteTask)
+		})
+
+		r.Route("/entries", func(r chi.Router) {
+			r.With(auditMiddleware.Record(models.ActionTimesheetEntryCreated, models.ResourceTimesheets)).Post("/", h.CreateEntry)
+			r.With(auditMiddleware.Record(models.ActionTimesheetEntryUpdated, models.ResourceTimesheets)).Put("/{id}", h.UpdateEntry)
+			r.With(auditMiddleware.Record(models.ActionTimesheetEntryDeleted, models.ResourceTimesheets)).Delete("/{id}", h.DeleteEntry)
+		})
+
+		r.Route("/reports", func(r chi.Router) {
+			r.Use(permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionView))
+
+			r.Get("/projects", h.ProjectReport)
+			r.Get("/employees", h.EmployeeReport)
+		})
+
+		r.Get("/", h.ListTimesheets)
+		r.Get("/me", h.ListOwnTimesheets)
+		r.Get("/approvals", h.ListApprovals)
+		r.Get("/week", h.Week)
+		r.Get("/{id}", h.GetTimesheet)
+
+		r.With(auditMiddleware.Record(models.ActionTimesheetSubmitted, models.ResourceTimesheets)).Post("/{id}/submit", h.Submit)
+		r.With(auditMiddleware.Record(models.ActionTimesheetApproved, models.ResourceTimesheets)).Post("/{id}/approve", h.Approve)
+		r.With(auditMiddleware.Record(models.ActionTimesheetRejected, models.ResourceTimesheets)).Post("/{id}/reject", h.Reject)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceTimesheets, models.ActionManageTimesheets),
+			auditMiddleware.Record(models.ActionTimesheetReopened, models.ResourceTimesheets),
+		).Post("/{id}/reopen", h.Reopen)
+	})
+}
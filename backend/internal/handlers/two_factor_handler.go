@@ -13,14 +13,14 @@ import (
 
 // TwoFactorHandler handles two-factor authentication endpoints
 type TwoFactorHandler struct {
-	twoFactorService *services.TwoFactorService
-	userRepo         *repository.UserRepository
+	twoFactorService services.TwoFactorManager
+	userRepo         repository.UserStore
 }
 
 // NewTwoFactorHandler creates a new two-factor authentication handler
 func NewTwoFactorHandler(
-	twoFactorService *services.TwoFactorService,
-	userRepo *repository.UserRepository,
+	twoFactorService services.TwoFactorManager,
+	userRepo repository.UserStore,
 ) *TwoFactorHandler {
 	return &TwoFactorHandler{
 		twoFactorService: twoFactorService,
@@ -229,7 +229,7 @@ func (h *TwoFactorHandler) Verify(w http.ResponseWriter, r *http.Request) {
 	// Verify TOTP code
 	valid, err := h.twoFactorService.VerifyTOTP(r.Context(), tenantID, userID, req.Code)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to verify code")
 		return
 	}
 
@@ -280,7 +280,7 @@ func (h *TwoFactorHandler) VerifyBackupCode(w http.ResponseWriter, r *http.Reque
 	// Verify backup code
 	valid, err := h.twoFactorService.VerifyBackupCode(r.Context(), tenantID, userID, req.Code)
 	if err != nil {
-		utils.BadRequest(w, err.Error())
+		utils.WriteError(w, err, "Failed to verify backup code")
 		return
 	}
 
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// TwoFactorPolicyHandler handles the tenant's 2FA policy. Setting up 2FA
+// when the policy blocks a sign-in is served by the auth handler.
+type TwoFactorPolicyHandler struct {
+	authService services.AuthManager
+}
+
+// NewTwoFactorPolicyHandler creates a new 2FA policy handler
+func NewTwoFactorPolicyHandler(authService services.AuthManager) *TwoFactorPolicyHandler {
+	return &TwoFactorPolicyHandler{
+		authService: authService,
+	}
+}
+
+// GetPolicy retrieves the tenant's 2FA policy
+// GET /api/settings/2fa-policy
+func (h *TwoFactorPolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	policy, err := h.authService.GetTwoFactorPolicy(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get 2FA policy")
+		return
+	}
+
+	utils.Success(w, policy)
+}
+
+// UpdatePolicy changes the tenant's 2FA policy
+// PUT /api/settings/2fa-policy
+func (h *TwoFactorPolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorPolicyUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.GracePeriodDays != nil && (*req.GracePeriodDays < 0 || *req.GracePeriodDays > models.MaxTwoFactorGracePeriodDays) {
+		errors.Add("grace_period_days", "Grace period must be between 0 and 90 days")
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.authService.GetTwoFactorPolicy(r.Context(), tenantID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get 2FA policy")
+		return
+	}
+
+	policy, err := h.authService.UpdateTwoFactorPolicy(r.Context(), tenantID, &req)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to update 2FA policy")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), tenantID)
+	middleware.SetAuditBefore(r.Context(), before)
+	middleware.SetAuditAfter(r.Context(), policy)
+
+	utils.Success(w, policy)
+}
+
+// RegisterRoutes registers the 2FA policy routes
+func (h *TwoFactorPolicyHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/settings/2fa-policy", func(r chi.Router) {
+		// All 2FA policy routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.With(permMiddleware.RequirePermission(models.ResourceSettings, models.ActionView)).Get("/", h.GetPolicy)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceSettings, models.ActionEdit),
+			auditMiddleware.Record(models.ActionTwoFactorPolicyUpdated, models.ResourceSettings),
+		).Put("/", h.UpdatePolicy)
+	})
+}
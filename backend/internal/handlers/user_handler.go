@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"myerp-v2/internal/middleware"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
 	"myerp-v2/internal/repository"
 	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
@@ -15,27 +20,58 @@ import (
 
 // UserHandler handles user management endpoints
 type UserHandler struct {
-	userRepo          *repository.UserRepository
-	userRoleRepo      *repository.UserRoleRepository
-	permissionService *services.PermissionService
-	config            interface{} // Will be *config.Config
+	userRepo            repository.UserStore
+	userRoleRepo        repository.UserRoleStore
+	permissionService   services.PermissionManager
+	deletionService     services.DeletionManager
+	userImportService   services.UserImportManager
+	quotaService        services.QuotaManager
+	notifier            services.NotificationManager
+	avatarService       services.AvatarManager
+	authService         services.AuthManager
+	personalDataService services.PersonalDataManager
+	customFieldService  services.CustomFieldManager
+	config              interface{} // Will be *config.Config
 }
 
+// userImportFormOverhead is what a multipart upload may add to the file size
+const userImportFormOverhead = 1 << 20
+
+// avatarFormOverhead is what a multipart upload may add to the avatar size
+const avatarFormOverhead = 64 << 10
+
 // NewUserHandler creates a new user handler
 func NewUserHandler(
-	userRepo *repository.UserRepository,
-	userRoleRepo *repository.UserRoleRepository,
-	permissionService *services.PermissionService,
+	userRepo repository.UserStore,
+	userRoleRepo repository.UserRoleStore,
+	permissionService services.PermissionManager,
+	deletionService services.DeletionManager,
+	userImportService services.UserImportManager,
+	quotaService services.QuotaManager,
+	notifier services.NotificationManager,
+	avatarService services.AvatarManager,
+	authService services.AuthManager,
+	personalDataService services.PersonalDataManager,
+	customFieldService services.CustomFieldManager,
 ) *UserHandler {
 	return &UserHandler{
-		userRepo:          userRepo,
-		userRoleRepo:      userRoleRepo,
-		permissionService: permissionService,
+		userRepo:            userRepo,
+		userRoleRepo:        userRoleRepo,
+		permissionService:   permissionService,
+		deletionService:     deletionService,
+		userImportService:   userImportService,
+		quotaService:        quotaService,
+		notifier:            notifier,
+		avatarService:       avatarService,
+		authService:         authService,
+		personalDataService: personalDataService,
+		customFieldService:  customFieldService,
 	}
 }
 
-// List retrieves all users with pagination
-// GET /api/users?page=1&page_size=20
+// List retrieves users with pagination, sorting and filtering
+// GET /api/users?page=1&page_size=20&sort=-created_at&status=active&role_id=...&department_id=...&created_from=...&created_to=...&cf.<key>=...
+// GET /api/users?cursor=...
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
 	if err != nil {
@@ -43,39 +79,68 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Pagination parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	page, err := pagination.Parse(r, repository.UserListSpec)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list users")
+		return
 	}
 
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	filter, err := parseUserFilter(r)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list users")
+		return
+	}
+	filter.CustomFields, err = h.customFieldService.ParseFilter(r.Context(), tenantID, models.NavigationEntityUser, r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, err, "Failed to list users")
+		return
 	}
 
-	offset := (page - 1) * pageSize
-
-	users, totalCount, err := h.userRepo.List(r.Context(), tenantID, pageSize, offset)
+	users, totalCount, nextCursor, err := h.userRepo.List(r.Context(), tenantID, filter, page)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to list users")
 		return
 	}
 
-	// Enrich users with roles
+	// Enrich users with roles, for the whole page at once
+	userIDs := make([]uuid.UUID, len(users))
 	for i := range users {
-		roles, _ := h.userRoleRepo.GetUserRoles(r.Context(), tenantID, users[i].ID)
-		users[i].Roles = roles
+		userIDs[i] = users[i].ID
+	}
+	rolesByUser, err := h.userRoleRepo.GetRolesForUsers(r.Context(), tenantID, userIDs)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list users")
+		return
+	}
+	for i := range users {
+		users[i].Roles = rolesByUser[users[i].ID]
 	}
-
-	meta := utils.NewMeta(page, pageSize, totalCount)
 
 	utils.SuccessWithMeta(w, map[string]interface{}{
 		"users": users,
-	}, meta)
+	}, page.Meta(totalCount, nextCursor))
+}
+
+// parseUserFilter reads the filters of the user list
+func parseUserFilter(r *http.Request) (models.UserFilter, error) {
+	var filter models.UserFilter
+	var err error
+
+	if filter.Status, err = pagination.Enum(r, "status", models.UserStatuses); err != nil {
+		return filter, err
+	}
+	if filter.RoleID, err = pagination.UUID(r, "role_id"); err != nil {
+		return filter, err
+	}
+	if filter.DepartmentID, err = pagination.UUID(r, "department_id"); err != nil {
+		return filter, err
+	}
+	filter.CreatedFrom, filter.CreatedTo, err = pagination.DateRange(r, "created_from", "created_to")
+	return filter, err
 }
 
-// Get retrieves a single user by ID
+// Get retrieves a single user by ID. The ETag header carries its version, to
+// send back in If-Match when updating it.
 // GET /api/users/{id}
 func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "id")
@@ -105,6 +170,7 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 	permissions, _ := h.permissionService.GetUserPermissions(r.Context(), tenantID, userID)
 	user.Permissions = permissions
 
+	utils.SetVersion(w, user.UpdatedAt)
 	utils.Success(w, map[string]interface{}{
 		"user": user,
 	})
@@ -162,6 +228,17 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.quotaService.CheckUserQuota(r.Context(), tenantID, 1); err != nil {
+		utils.WriteError(w, err, "Failed to check user quota")
+		return
+	}
+
+	customFields, err := h.customFieldService.ApplyValues(r.Context(), tenantID, models.NavigationEntityUser, nil, req.CustomFields, true)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to create user")
+		return
+	}
+
 	// Hash password
 	passwordHash, err := utils.HashPassword(req.Password, 10)
 	if err != nil {
@@ -180,6 +257,7 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Timezone:     "UTC",
 		Language:     "en",
 		Preferences:  []byte("{}"),
+		CustomFields: customFields,
 		CreatedBy:    &creatorID,
 	}
 
@@ -199,15 +277,45 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	roles, _ := h.userRoleRepo.GetUserRoles(r.Context(), tenantID, user.ID)
 	user.Roles = roles
 
+	middleware.SetAuditResourceID(r.Context(), user.ID)
+	middleware.SetAuditAfter(r.Context(), user)
+
 	utils.Created(w, map[string]interface{}{
 		"user":    user,
 		"message": "User created successfully",
 	})
 }
 
-// Update updates an existing user
+// Update updates an existing user. Fields left out or null keep their value.
+// The version the change is based on, sent in If-Match or as version, must
+// still be current, or the update fails with 409 and the current user.
 // PUT /api/users/{id}
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req models.UserUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.update(w, r, &req, nil)
+}
+
+// Patch applies a JSON merge patch to a user. Fields left out keep their
+// value and null clears the phone and avatar.
+// PATCH /api/users/{id}
+func (h *UserHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var req models.UserUpdateRequest
+	nulls, err := utils.ParseMergePatch(r, &req)
+	if err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.update(w, r, &req, nulls)
+}
+
+// update applies an update to a user, clearing the fields named in nulls
+func (h *UserHandler) update(w http.ResponseWriter, r *http.Request, req *models.UserUpdateRequest, nulls map[string]bool) {
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
@@ -215,9 +323,27 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.UserUpdateRequest
-	if err := utils.ParseJSONBody(r, &req); err != nil {
-		utils.BadRequest(w, "Invalid request body")
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateNotNull("first_name", nulls, "First name", &errors)
+	utils.ValidateNotNull("last_name", nulls, "Last name", &errors)
+	utils.ValidateNotNull("timezone", nulls, "Timezone", &errors)
+	utils.ValidateNotNull("language", nulls, "Language", &errors)
+	utils.ValidateNotNull("custom_fields", nulls, "Custom fields", &errors)
+	if req.FirstName != nil {
+		utils.ValidateName("first_name", *req.FirstName, "First name", &errors)
+	}
+	if req.LastName != nil {
+		utils.ValidateName("last_name", *req.LastName, "Last name", &errors)
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	version, err := utils.ParseVersion(r, req.Version)
+	if err != nil {
+		utils.WriteError(w, err, "Invalid version")
 		return
 	}
 
@@ -233,6 +359,12 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.NotFound(w, "User not found")
 		return
 	}
+	middleware.SetAuditBefore(r.Context(), user)
+
+	// The update only applies to the version the client read
+	if version != nil {
+		user.UpdatedAt = *version
+	}
 
 	// Update fields
 	if req.FirstName != nil {
@@ -241,10 +373,10 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.LastName != nil {
 		user.LastName = *req.LastName
 	}
-	if req.Phone != nil {
+	if req.Phone != nil || nulls["phone"] {
 		user.Phone = req.Phone
 	}
-	if req.AvatarURL != nil {
+	if req.AvatarURL != nil || nulls["avatar_url"] {
 		user.AvatarURL = req.AvatarURL
 	}
 	if req.Timezone != nil {
@@ -253,10 +385,15 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Language != nil {
 		user.Language = *req.Language
 	}
+	user.CustomFields, err = h.customFieldService.ApplyValues(r.Context(), tenantID, models.NavigationEntityUser, user.CustomFields, req.CustomFields, false)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to update user")
+		return
+	}
 
 	// Update user
 	if err := h.userRepo.Update(r.Context(), tenantID, user); err != nil {
-		utils.InternalServerError(w, "Failed to update user")
+		h.respondUpdateError(w, r, tenantID, userID, err)
 		return
 	}
 
@@ -264,13 +401,147 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	roles, _ := h.userRoleRepo.GetUserRoles(r.Context(), tenantID, userID)
 	user.Roles = roles
 
+	middleware.SetAuditAfter(r.Context(), user)
+
+	utils.SetVersion(w, user.UpdatedAt)
 	utils.Success(w, map[string]interface{}{
 		"user":    user,
 		"message": "User updated successfully",
 	})
 }
 
-// Delete deletes a user
+// respondUpdateError writes the response for a failed user update. A version
+// conflict comes with the current user, for the client to reapply its change.
+func (h *UserHandler) respondUpdateError(w http.ResponseWriter, r *http.Request, tenantID, userID uuid.UUID, err error) {
+	if !errors.Is(err, utils.ErrConflict) {
+		utils.WriteError(w, err, "Failed to update user")
+		return
+	}
+
+	latest, err := h.userRepo.FindByID(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to update user")
+		return
+	}
+	latest.Roles, _ = h.userRoleRepo.GetUserRoles(r.Context(), tenantID, userID)
+
+	utils.SetVersion(w, latest.UpdatedAt)
+	utils.VersionConflict(w, "The user was changed in the meantime", map[string]interface{}{
+		"user": latest,
+	})
+}
+
+// ChangeOwnEmail starts a change of the current user's email by emailing a
+// confirmation link to the new address. The current email stays in use until
+// the change is confirmed through POST /auth/confirm-email.
+// POST /api/users/me/email
+func (h *UserHandler) ChangeOwnEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.UserEmailChangeRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Validate
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("email", req.Email, "Email", &errors)
+	utils.ValidateEmail("email", req.Email, &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), userID)
+
+	user, err := h.authService.RequestEmailChange(r.Context(), tenantID, userID, req.Email, req.Password)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to change email")
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{
+		"email":         user.Email,
+		"pending_email": user.PendingEmail,
+	})
+
+	utils.Success(w, map[string]interface{}{
+		"email":         user.Email,
+		"pending_email": user.PendingEmail,
+		"message":       "A confirmation link has been sent to the new email address",
+	})
+}
+
+// ExportOwnData returns all the personal data held about the current user
+// GET /api/users/me/data-export
+func (h *UserHandler) ExportOwnData(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), userID)
+
+	export, err := h.personalDataService.Export(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to export personal data")
+		return
+	}
+
+	utils.Success(w, export)
+}
+
+// RequestErasure erases the current user's personal data. The user is
+// anonymized and signed out everywhere; this cannot be undone.
+// POST /api/users/me/erasure-request
+func (h *UserHandler) RequestErasure(w http.ResponseWriter, r *http.Request) {
+	var req models.PersonalDataErasureRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.personalDataService.RequestErasure(r.Context(), tenantID, userID, req.Password); err != nil {
+		utils.WriteError(w, err, "Failed to erase personal data")
+		return
+	}
+
+	utils.Success(w, map[string]string{
+		"message": "Your personal data has been erased",
+	})
+}
+
+// Delete schedules a user for deletion (undoable until the window passes)
 // DELETE /api/users/{id}
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	userIDStr := chi.URLParam(r, "id")
@@ -298,17 +569,188 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete user
-	if err := h.userRepo.Delete(r.Context(), tenantID, userID); err != nil {
-		utils.BadRequest(w, err.Error())
+	user, err := h.userRepo.FindByID(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.NotFound(w, "User not found")
 		return
 	}
+	middleware.SetAuditBefore(r.Context(), user)
 
-	// Invalidate permission cache
+	// Stage the deletion; it is executed once the undo window has passed
+	deletion, err := h.deletionService.Schedule(r.Context(), tenantID, currentUserID, models.DeletionEntityUser, user.ID, user.Email)
+	if err != nil {
+		respondDeletionError(w, err)
+		return
+	}
+	middleware.SetAuditMetadata(r.Context(), "deletion_id", deletion.ID)
+
+	utils.Success(w, map[string]interface{}{
+		"deletion": deletion,
+		"message":  "User scheduled for deletion",
+	})
+}
+
+// ListDeleted retrieves soft-deleted users that can still be restored
+// GET /api/users/deleted?page=1&page_size=20
+func (h *UserHandler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	users, totalCount, err := h.userRepo.ListDeleted(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list deleted users")
+		return
+	}
+
+	meta := utils.NewMeta(page, pageSize, totalCount)
+
+	utils.SuccessWithMeta(w, map[string]interface{}{
+		"users": users,
+	}, meta)
+}
+
+// Restore brings back a soft-deleted user with their roles
+// POST /api/users/{id}/restore
+func (h *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userIDStr := chi.URLParam(r, "id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	user, err := h.userRepo.Restore(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to restore user")
+		return
+	}
 	h.permissionService.InvalidateUserPermissions(r.Context(), tenantID, userID)
+	middleware.SetAuditAfter(r.Context(), user)
+
+	utils.Success(w, map[string]interface{}{
+		"user":    user,
+		"message": "User restored successfully",
+	})
+}
+
+// UploadAvatar replaces a user's avatar with an uploaded image (multipart
+// field file), resized to the standard sizes. Users change their own avatar;
+// changing someone else's requires users.edit.
+// POST /api/users/{id}/avatar
+func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	actorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	maxSize := h.avatarService.MaxFileSize()
+	tooLarge := map[string]string{"file": fmt.Sprintf("Image must not exceed %d MB", maxSize>>20)}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+avatarFormOverhead)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+			return
+		}
+		utils.BadRequest(w, "A PNG, JPEG or GIF image is required in the file field")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.BadRequest(w, "Failed to read the file")
+		return
+	}
+	if int64(len(data)) > maxSize {
+		utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+		return
+	}
+
+	user, avatar, err := h.avatarService.Upload(r.Context(), tenantID, actorID, userID, data)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to upload avatar")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), userID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"avatar_url": avatar.AvatarURL})
+
+	utils.Success(w, map[string]interface{}{
+		"user":    user,
+		"avatar":  avatar,
+		"message": "Avatar updated successfully",
+	})
+}
+
+// RemoveAvatar clears a user's avatar and deletes its files
+// DELETE /api/users/{id}/avatar
+func (h *UserHandler) RemoveAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	actorID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	user, err := h.avatarService.Remove(r.Context(), tenantID, actorID, userID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to remove avatar")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), userID)
 
 	utils.Success(w, map[string]interface{}{
-		"message": "User deleted successfully",
+		"user":    user,
+		"message": "Avatar removed successfully",
 	})
 }
 
@@ -341,11 +783,19 @@ func (h *UserHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, err := h.userRepo.FindByID(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.NotFound(w, "User not found")
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), map[string]string{"status": user.Status})
+
 	// Update status
 	if err := h.userRepo.UpdateStatus(r.Context(), tenantID, userID, req.Status); err != nil {
 		utils.InternalServerError(w, "Failed to update status")
 		return
 	}
+	middleware.SetAuditAfter(r.Context(), map[string]string{"status": req.Status})
 
 	utils.Success(w, map[string]interface{}{
 		"message": "User status updated successfully",
@@ -353,6 +803,42 @@ func (h *UserHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// IssueTwoFABypass emails a user who lost their authenticator a one-time
+// code to sign in without their second factor. The code goes to the user only.
+// POST /api/users/{id}/2fa-bypass
+func (h *UserHandler) IssueTwoFABypass(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	issuerID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	expiresAt, err := h.authService.IssueTwoFABypassCode(r.Context(), tenantID, userID, issuerID)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to send bypass code")
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"expires_at": expiresAt})
+
+	utils.Success(w, map[string]interface{}{
+		"message":    "A bypass code has been sent to the user's email address",
+		"expires_at": expiresAt,
+	})
+}
+
 // GetRoles retrieves roles for a user
 // GET /api/users/{id}/roles
 func (h *UserHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
@@ -417,6 +903,9 @@ func (h *UserHandler) AssignRoles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	previousRoles, _ := h.userRoleRepo.GetUserRoles(r.Context(), tenantID, userID)
+	middleware.SetAuditBefore(r.Context(), previousRoles)
+
 	// Assign roles
 	if err := h.userRoleRepo.AssignRoles(r.Context(), tenantID, userID, req.RoleIDs, assignerID); err != nil {
 		utils.InternalServerError(w, "Failed to assign roles")
@@ -428,6 +917,9 @@ func (h *UserHandler) AssignRoles(w http.ResponseWriter, r *http.Request) {
 
 	// Get updated roles
 	roles, _ := h.userRoleRepo.GetUserRoles(r.Context(), tenantID, userID)
+	middleware.SetAuditAfter(r.Context(), roles)
+
+	notifyRolesChanged(r.Context(), h.notifier, tenantID, assignerID, []uuid.UUID{userID}, roles)
 
 	utils.Success(w, map[string]interface{}{
 		"message": "Roles assigned successfully",
@@ -435,8 +927,258 @@ func (h *UserHandler) AssignRoles(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Search searches for users
-// GET /api/users/search?q=keyword
+// GetRoleAssignments retrieves a user's role assignments, including
+// temporary ones that are scheduled or expired with their period
+// GET /api/users/{id}/role-assignments
+func (h *UserHandler) GetRoleAssignments(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	assignments, err := h.userRoleRepo.GetRoleAssignmentDetails(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to get role assignments")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"assignments": assignments,
+	})
+}
+
+// GrantTemporaryRole assigns a role to a user for a limited period, e.g. to
+// cover for a colleague on vacation
+// POST /api/users/{id}/temporary-roles
+func (h *UserHandler) GrantTemporaryRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req models.TemporaryRoleRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	now := time.Now()
+	validFrom := now
+	if req.ValidFrom != nil {
+		validFrom = *req.ValidFrom
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.RoleID == uuid.Nil {
+		errors.Add("role_id", "Role is required")
+	}
+	switch {
+	case req.ValidUntil.IsZero():
+		errors.Add("valid_until", "End of the assignment is required")
+	case !req.ValidUntil.After(now):
+		errors.Add("valid_until", "End of the assignment must be in the future")
+	case !req.ValidUntil.After(validFrom):
+		errors.Add("valid_until", "End of the assignment must be after its start")
+	case req.ValidUntil.Sub(validFrom) > models.MaxTemporaryRoleDuration:
+		errors.Add("valid_until", "Temporary assignments cannot last longer than a year")
+	}
+	if len(req.Reason) > 500 {
+		errors.Add("reason", "Reason must be at most 500 characters")
+	}
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	assignerID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	role, err := h.permissionService.GrantTemporaryRole(r.Context(), tenantID, userID, assignerID, &req)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to grant temporary role")
+		return
+	}
+
+	middleware.SetAuditMetadata(r.Context(), "role_id", role.ID)
+	middleware.SetAuditMetadata(r.Context(), "valid_from", req.ValidFrom)
+	middleware.SetAuditMetadata(r.Context(), "valid_until", req.ValidUntil)
+	if req.DelegatedFrom != nil {
+		middleware.SetAuditMetadata(r.Context(), "delegated_from", *req.DelegatedFrom)
+	}
+
+	if !req.ValidFrom.After(now) {
+		notifyRolesChanged(r.Context(), h.notifier, tenantID, assignerID, []uuid.UUID{userID}, []models.Role{*role})
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"message":     "Temporary role granted successfully",
+		"role":        role,
+		"valid_from":  req.ValidFrom,
+		"valid_until": req.ValidUntil,
+	})
+}
+
+// RevokeTemporaryRole ends a user's temporary assignment of a role early
+// DELETE /api/users/{id}/temporary-roles/{roleId}
+func (h *UserHandler) RevokeTemporaryRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	roleID, err := uuid.Parse(chi.URLParam(r, "roleId"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid role ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.permissionService.RevokeTemporaryRole(r.Context(), tenantID, userID, roleID); err != nil {
+		utils.WriteError(w, err, "Failed to revoke temporary role")
+		return
+	}
+
+	middleware.SetAuditMetadata(r.Context(), "role_id", roleID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Temporary role revoked successfully",
+	})
+}
+
+// Import creates users in bulk from an uploaded CSV or XLSX file. With
+// dry_run=true the rows are only validated. Large files are imported by an
+// async job: the response is then 202 Accepted with the job.
+// POST /api/users/import?dry_run=true (multipart/form-data, "file" field)
+func (h *UserHandler) Import(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	maxSize := h.userImportService.MaxFileSize()
+	tooLarge := map[string]string{"file": fmt.Sprintf("File must not exceed %d MB", maxSize>>20)}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+userImportFormOverhead)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+			return
+		}
+		utils.BadRequest(w, "A CSV or XLSX file is required in the file field")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		utils.BadRequest(w, "Failed to read the file")
+		return
+	}
+	if int64(len(data)) > maxSize {
+		utils.UnprocessableEntity(w, "Validation failed", tooLarge)
+		return
+	}
+
+	dryRun := false
+	if value := r.FormValue("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			utils.BadRequest(w, "Invalid dry_run value")
+			return
+		}
+	}
+
+	rows, err := h.userImportService.ParseFile(header.Filename, data)
+	if err != nil {
+		utils.UnprocessableEntity(w, "Validation failed", map[string]string{"file": err.Error()})
+		return
+	}
+
+	report, job, err := h.userImportService.Import(r.Context(), tenantID, userID, header.Filename, rows, dryRun)
+	if err != nil {
+		utils.WriteError(w, err, "Failed to import users")
+		return
+	}
+
+	if job != nil {
+		utils.Accepted(w, map[string]interface{}{
+			"job":     job,
+			"message": "Import queued",
+		})
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"report": report,
+	})
+}
+
+// GetImport retrieves an import job, with its report once it has finished
+// GET /api/users/import/{jobID}
+func (h *UserHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	job, err := h.userImportService.GetImport(r.Context(), tenantID, userID, jobID)
+	if err != nil {
+		respondAsyncJobError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"job": job,
+	})
+}
+
+// Search searches for users by name or email, accent-insensitively and
+// tolerating typos
+// GET /api/users/search?q=keyword&lang=fr
 func (h *UserHandler) Search(w http.ResponseWriter, r *http.Request) {
 	searchTerm := r.URL.Query().Get("q")
 	if searchTerm == "" {
@@ -450,7 +1192,7 @@ func (h *UserHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.userRepo.Search(r.Context(), tenantID, searchTerm, 50)
+	users, err := h.userRepo.Search(r.Context(), tenantID, searchTerm, models.SearchConfig(searchLanguage(r)), 50)
 	if err != nil {
 		utils.InternalServerError(w, "Failed to search users")
 		return
@@ -463,8 +1205,55 @@ func (h *UserHandler) Search(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Export downloads every user as CSV, XLSX or JSON, streamed as it is read
+// GET /api/users/export?format=csv
+func (h *UserHandler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	format, ok := parseExportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := utils.NewExportWriter(w, format, "users", models.UserExportColumns)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to export users")
+		return
+	}
+
+	err = h.userRepo.StreamForExport(r.Context(), tenantID, func(user *models.UserExport) error {
+		return export.Write(user, []string{
+			utils.ExportValue(user.ID),
+			user.Email,
+			user.FirstName,
+			user.LastName,
+			utils.ExportValue(user.Phone),
+			utils.ExportValue(user.Roles),
+			utils.ExportValue(user.Department),
+			user.Status,
+			utils.ExportValue(user.EmailVerified),
+			utils.ExportValue(user.TwoFactorEnabled),
+			utils.ExportValue(user.LastLoginAt),
+			utils.ExportValue(user.CreatedAt),
+		})
+	})
+	if err == nil {
+		err = export.Close()
+	}
+	if err != nil {
+		export.Abort(err)
+	}
+
+	middleware.SetAuditMetadata(r.Context(), "format", format)
+	middleware.SetAuditMetadata(r.Context(), "records", export.Records())
+}
+
 // RegisterRoutes registers all user routes
-func (h *UserHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware) {
+func (h *UserHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, navMiddleware *middleware.NavigationMiddleware, planMiddleware *middleware.PlanMiddleware) {
 	r.Route("/users", func(r chi.Router) {
 		// All user routes require authentication
 		r.Use(authMiddleware.Authenticate)
@@ -475,25 +1264,113 @@ func (h *UserHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.Au
 		// Search users - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView)).Get("/search", h.Search)
 
+		// Export users as CSV/XLSX/JSON - requires view and export permissions
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView),
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionExport),
+			auditMiddleware.Record(models.ActionUserExported, models.ResourceUsers),
+		).Get("/export", h.Export)
+
+		// Import users from a CSV/XLSX file - requires create permission
+		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate)).Post("/import", h.Import)
+
+		// Get an import job - access is checked on the job
+		r.Get("/import/{jobID}", h.GetImport)
+
+		// Change one's own email - no permission needed, confirmed by email
+		r.With(auditMiddleware.Record(models.ActionUserEmailChangeRequested, models.ResourceUsers)).Post("/me/email", h.ChangeOwnEmail)
+
+		// Export or erase one's own personal data - no permission needed. The
+		// erasure is audited by the service, without the request's IP address.
+		r.With(auditMiddleware.Record(models.ActionUserDataExported, models.ResourceUsers)).Get("/me/data-export", h.ExportOwnData)
+		r.Post("/me/erasure-request", h.RequestErasure)
+
+		// List deleted users - requires delete permission
+		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete)).Get("/deleted", h.ListDeleted)
+
 		// Get single user - requires view permission
-		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView)).Get("/{id}", h.Get)
+		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView), navMiddleware.TrackView(models.NavigationEntityUser)).Get("/{id}", h.Get)
 
-		// Create user - requires create permission
-		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate)).Post("/", h.Create)
+		// Create user - requires create permission and a free seat on the plan
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionCreate),
+			planMiddleware.RequireUserQuota,
+			auditMiddleware.Record(models.ActionUserCreated, models.ResourceUsers),
+		).Post("/", h.Create)
 
 		// Update user - requires edit permission
-		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionEdit)).Put("/{id}", h.Update)
+		edit := r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionEdit),
+			auditMiddleware.Record(models.ActionUserUpdated, models.ResourceUsers),
+		)
+		edit.Put("/{id}", h.Update)
+		edit.Patch("/{id}", h.Patch)
 
 		// Delete user - requires delete permission
-		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete)).Delete("/{id}", h.Delete)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete),
+			auditMiddleware.Record(models.ActionUserDeleted, models.ResourceUsers),
+		).Delete("/{id}", h.Delete)
+
+		// Restore deleted user - requires delete permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionDelete),
+			auditMiddleware.Record(models.ActionUserRestored, models.ResourceUsers),
+		).Post("/{id}/restore", h.Restore)
+
+		// Upload or remove an avatar - own avatar, or users.edit (checked by the service)
+		r.With(auditMiddleware.Record(models.ActionUserAvatarUpdated, models.ResourceUsers)).Post("/{id}/avatar", h.UploadAvatar)
+		r.With(auditMiddleware.Record(models.ActionUserAvatarRemoved, models.ResourceUsers)).Delete("/{id}/avatar", h.RemoveAvatar)
 
 		// Update status - requires manage_status permission
-		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionManageStatus)).Patch("/{id}/status", h.UpdateStatus)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionManageStatus),
+			auditMiddleware.Record(models.ActionUserStatusChanged, models.ResourceUsers),
+		).Patch("/{id}/status", h.UpdateStatus)
+
+		// Email a 2FA bypass code to a user who lost their authenticator - requires manage_status permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceUsers, models.ActionManageStatus),
+			auditMiddleware.Record(models.Action2FABypassIssued, models.ResourceUsers),
+		).Post("/{id}/2fa-bypass", h.IssueTwoFABypass)
 
 		// Get user roles - requires view permission
 		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView)).Get("/{id}/roles", h.GetRoles)
 
 		// Assign roles - requires assign permission from roles resource
-		r.With(permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign)).Post("/{id}/roles", h.AssignRoles)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign),
+			auditMiddleware.Record(models.ActionUserRolesAssigned, models.ResourceUsers),
+		).Post("/{id}/roles", h.AssignRoles)
+
+		// Role assignments, including temporary ones - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceUsers, models.ActionView)).Get("/{id}/role-assignments", h.GetRoleAssignments)
+
+		// Grant or revoke a temporary role - requires assign permission from roles resource
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign),
+			auditMiddleware.Record(models.ActionRoleAssigned, models.ResourceUsers),
+		).Post("/{id}/temporary-roles", h.GrantTemporaryRole)
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceRoles, models.ActionAssign),
+			auditMiddleware.Record(models.ActionRoleUnassigned, models.ResourceUsers),
+		).Delete("/{id}/temporary-roles/{roleId}", h.RevokeTemporaryRole)
 	})
 }
+
+// parseExportFormat reads the format query parameter of an export, csv by
+// default. It responds with a validation error when the format is unknown.
+func parseExportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return utils.ExportFormatCSV, true
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("format", format, utils.ExportFormats, "Format", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return "", false
+	}
+	return format, true
+}
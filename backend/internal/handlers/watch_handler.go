@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// WatchHandler handles the records the current user watches
+type WatchHandler struct {
+	watchService services.WatchManager
+}
+
+// NewWatchHandler creates a new watch handler
+func NewWatchHandler(watchService services.WatchManager) *WatchHandler {
+	return &WatchHandler{
+		watchService: watchService,
+	}
+}
+
+// ListWatches lists the records the current user watches, most recent first
+// GET /api/watches?entity_type=customer&page=1&page_size=20
+func (h *WatchHandler) ListWatches(w http.ResponseWriter, r *http.Request) {
+	entityType := r.URL.Query().Get("entity_type")
+	if entityType != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("entity_type", entityType, h.watchService.EntityTypes(), "Entity type", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+
+	watches, totalCount, err := h.watchService.ListWatches(r.Context(), tenantID, userID, entityType, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list watched records")
+		return
+	}
+
+	utils.SuccessWithMeta(w, watches, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetWatchers lists the users watching a record, and whether the current
+// user is one of them
+// GET /api/watches/{entity_type}/{entity_id}
+func (h *WatchHandler) GetWatchers(w http.ResponseWriter, r *http.Request) {
+	entityType, entityID, ok := h.parseWatchedEntity(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	watchers, err := h.watchService.GetWatchers(r.Context(), tenantID, userID, entityType, entityID)
+	if err != nil {
+		respondWatchError(w, err)
+		return
+	}
+
+	utils.Success(w, watchers)
+}
+
+// Watch starts the current user watching a record. Watching a record twice
+// is not an error.
+// PUT /api/watches/{entity_type}/{entity_id}
+func (h *WatchHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	entityType, entityID, ok := h.parseWatchedEntity(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	watch, created, err := h.watchService.Watch(r.Context(), tenantID, userID, entityType, entityID)
+	if err != nil {
+		respondWatchError(w, err)
+		return
+	}
+
+	if created {
+		utils.Created(w, watch)
+		return
+	}
+	utils.Success(w, watch)
+}
+
+// Unwatch stops the current user watching a record
+// DELETE /api/watches/{entity_type}/{entity_id}
+func (h *WatchHandler) Unwatch(w http.ResponseWriter, r *http.Request) {
+	entityType, entityID, ok := h.parseWatchedEntity(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, userID, ok := navigationUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchService.Unwatch(r.Context(), tenantID, userID, entityType, entityID); err != nil {
+		respondWatchError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Stopped watching the record",
+	})
+}
+
+// parseWatchedEntity reads the record a watch route is about
+func (h *WatchHandler) parseWatchedEntity(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	entityType := chi.URLParam(r, "entity_type")
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateEnum("entity_type", entityType, h.watchService.EntityTypes(), "Entity type", &errors)
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return "", uuid.Nil, false
+	}
+
+	entityID, err := uuid.Parse(chi.URLParam(r, "entity_id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid entity ID")
+		return "", uuid.Nil, false
+	}
+
+	return entityType, entityID, true
+}
+
+// respondWatchError maps watch service errors to HTTP responses
+func respondWatchError(w http.ResponseWriter, err error) {
+	utils.WriteError(w, err, "Watch operation failed")
+}
+
+// RegisterRoutes registers the watch routes. Watches are the current user's
+// own; watching a record requires permission to view it.
+func (h *WatchHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware) {
+	r.Route("/watches", func(r chi.Router) {
+		// All watch routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Get("/", h.ListWatches)
+		r.Get("/{entity_type}/{entity_id}", h.GetWatchers)
+		r.Put("/{entity_type}/{entity_id}", h.Watch)
+		r.Delete("/{entity_type}/{entity_id}", h.Unwatch)
+	})
+}
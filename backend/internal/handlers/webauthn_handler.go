@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// WebAuthnHandler handles the current user's passkeys and security keys
+type WebAuthnHandler struct {
+	authService services.AuthManager
+}
+
+// NewWebAuthnHandler creates a new WebAuthn handler
+func NewWebAuthnHandler(authService services.AuthManager) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		authService: authService,
+	}
+}
+
+// BeginRegistration starts registering a passkey or security key and
+// returns the options to pass to navigator.credentials.create()
+// POST /api/webauthn/register/begin
+func (h *WebAuthnHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	options, err := h.authService.BeginWebAuthnRegistration(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to start passkey registration")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"options": options,
+	})
+}
+
+// FinishRegistration verifies the created credential and registers it
+// POST /api/webauthn/register/finish
+func (h *WebAuthnHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthnRegisterRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateStringLength("name", req.Name, 0, 100, "Name", &errors)
+	utils.ValidateRequired("credential.response.clientDataJSON", req.Credential.Response.ClientDataJSON, "Client data", &errors)
+	utils.ValidateRequired("credential.response.attestationObject", req.Credential.Response.AttestationObject, "Attestation object", &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	credential, err := h.authService.FinishWebAuthnRegistration(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "webauthn challenge expired":
+			utils.BadRequest(w, "Passkey registration expired, please try again")
+		case strings.HasPrefix(err.Error(), "invalid credential"):
+			utils.BadRequest(w, err.Error())
+		case err.Error() == "credential already registered":
+			utils.Conflict(w, "This passkey is already registered")
+		default:
+			utils.InternalServerError(w, "Failed to register passkey")
+		}
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), credential.ID)
+	middleware.SetAuditAfter(r.Context(), credential)
+
+	utils.Created(w, map[string]interface{}{
+		"credential": credential,
+	})
+}
+
+// ListCredentials lists the current user's passkeys and security keys
+// GET /api/webauthn/credentials
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	credentials, err := h.authService.ListWebAuthnCredentials(r.Context(), tenantID, userID)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list passkeys")
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"credentials": credentials,
+	})
+}
+
+// UpdateCredential renames one of the current user's credentials
+// PUT /api/webauthn/credentials/{id}
+func (h *WebAuthnHandler) UpdateCredential(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid credential ID")
+		return
+	}
+
+	var req models.WebAuthnCredentialUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("name", strings.TrimSpace(req.Name), "Name", &errors)
+	utils.ValidateStringLength("name", req.Name, 0, 100, "Name", &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	credential, err := h.authService.RenameWebAuthnCredential(r.Context(), tenantID, userID, id, req.Name)
+	if err != nil {
+		if err.Error() == "credential not found" {
+			utils.NotFound(w, "Passkey not found")
+			return
+		}
+		utils.InternalServerError(w, "Failed to rename passkey")
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), credential)
+
+	utils.Success(w, map[string]interface{}{
+		"credential": credential,
+	})
+}
+
+// DeleteCredential removes one of the current user's credentials
+// DELETE /api/webauthn/credentials/{id}
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid credential ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	credential, err := h.authService.DeleteWebAuthnCredential(r.Context(), tenantID, userID, id)
+	if err != nil {
+		switch err.Error() {
+		case "credential not found":
+			utils.NotFound(w, "Passkey not found")
+		case "cannot remove the last passkey of a passkey-only account":
+			utils.BadRequest(w, "Turn off passkey-only sign-in before removing your last passkey")
+		default:
+			utils.InternalServerError(w, "Failed to remove passkey")
+		}
+		return
+	}
+
+	middleware.SetAuditBefore(r.Context(), credential)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Passkey removed",
+	})
+}
+
+// SetPasskeyOnly turns passkey-only sign-in on or off for the current user.
+// While it is on, password sign-ins are refused.
+// PUT /api/webauthn/passkey-only
+func (h *WebAuthnHandler) SetPasskeyOnly(w http.ResponseWriter, r *http.Request) {
+	var req models.WebAuthnPasskeyOnlyRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.authService.SetPasskeyOnly(r.Context(), tenantID, userID, req.Enabled); err != nil {
+		if err.Error() == "register a passkey before turning on passkey-only sign-in" {
+			utils.BadRequest(w, "Register a passkey before turning on passkey-only sign-in")
+			return
+		}
+		utils.InternalServerError(w, "Failed to update passkey-only sign-in")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), userID)
+	middleware.SetAuditAfter(r.Context(), map[string]interface{}{"passkey_only": req.Enabled})
+
+	utils.Success(w, map[string]interface{}{
+		"passkey_only": req.Enabled,
+	})
+}
+
+// RegisterRoutes registers the current user's WebAuthn credential routes.
+// Sign-in with a passkey lives under /auth/webauthn.
+func (h *WebAuthnHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, auditMiddleware *middleware.AuditMiddleware) {
+	r.Route("/webauthn", func(r chi.Router) {
+		// All credential routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		r.Post("/register/begin", h.BeginRegistration)
+		r.With(
+			auditMiddleware.Record(models.ActionWebAuthnCredentialRegistered, models.AuditResourceWebAuthnCredentials),
+		).Post("/register/finish", h.FinishRegistration)
+
+		r.Get("/credentials", h.ListCredentials)
+		r.With(
+			auditMiddleware.Record(models.ActionWebAuthnCredentialRenamed, models.AuditResourceWebAuthnCredentials),
+		).Put("/credentials/{id}", h.UpdateCredential)
+		r.With(
+			auditMiddleware.Record(models.ActionWebAuthnCredentialRemoved, models.AuditResourceWebAuthnCredentials),
+		).Delete("/credentials/{id}", h.DeleteCredential)
+
+		r.With(
+			auditMiddleware.Record(models.ActionWebAuthnPasskeyOnlyChanged, models.ResourceUsers),
+		).Put("/passkey-only", h.SetPasskeyOnly)
+	})
+}
@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// WebhookHandler handles webhook registration and delivery log endpoints
+type WebhookHandler struct {
+	webhookService services.WebhookManager
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService services.WebhookManager) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListEventTypes lists the event types webhooks can subscribe to
+// GET /api/webhooks/event-types
+func (h *WebhookHandler) ListEventTypes(w http.ResponseWriter, r *http.Request) {
+	utils.Success(w, map[string]interface{}{
+		"event_types": models.EventTypes,
+	})
+}
+
+// List lists the tenant's webhooks
+// GET /api/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	page, pageSize, offset := webhookPagination(r)
+
+	webhooks, totalCount, err := h.webhookService.ListWebhooks(r.Context(), tenantID, pageSize, offset)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to list webhooks")
+		return
+	}
+
+	utils.SuccessWithMeta(w, webhooks, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// Get retrieves a webhook
+// GET /api/webhooks/{id}
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhook(r.Context(), tenantID, webhookID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"webhook": webhook,
+	})
+}
+
+// Create registers a webhook. The signing secret is only returned here and
+// when it is rotated.
+// POST /api/webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.WebhookCreateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	utils.ValidateRequired("url", req.URL, "URL", &errors)
+	if req.URL != "" {
+		h.validateURL(req.URL, &errors)
+	}
+	utils.ValidateStringLength("description", req.Description, 0, 500, "Description", &errors)
+	validateWebhookEventTypes(req.EventTypes, &errors)
+	validateWebhookRedactionRules(req.RedactionRules, &errors)
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	webhook, secret, err := h.webhookService.CreateWebhook(r.Context(), tenantID, userID, &req)
+	if err != nil {
+		utils.InternalServerError(w, "Failed to create webhook")
+		return
+	}
+
+	middleware.SetAuditResourceID(r.Context(), webhook.ID)
+	middleware.SetAuditAfter(r.Context(), webhook)
+
+	utils.Created(w, map[string]interface{}{
+		"webhook": webhook,
+		"secret":  secret,
+		"message": "Webhook created. Store the secret now, it will not be shown again",
+	})
+}
+
+// Update changes a webhook's URL, description, event types, active state or
+// redaction rules
+// PUT /api/webhooks/{id}
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	var req models.WebhookUpdateRequest
+	if err := utils.ParseJSONBody(r, &req); err != nil {
+		utils.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	errors := utils.ValidationErrors{}
+	if req.URL != nil {
+		utils.ValidateRequired("url", *req.URL, "URL", &errors)
+		if *req.URL != "" {
+			h.validateURL(*req.URL, &errors)
+		}
+	}
+	if req.Description != nil {
+		utils.ValidateStringLength("description", *req.Description, 0, 500, "Description", &errors)
+	}
+	if req.EventTypes != nil {
+		validateWebhookEventTypes(*req.EventTypes, &errors)
+	}
+	if req.RedactionRules != nil {
+		validateWebhookRedactionRules(*req.RedactionRules, &errors)
+	}
+
+	if errors.HasErrors() {
+		utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.webhookService.GetWebhook(r.Context(), tenantID, webhookID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	webhook, err := h.webhookService.UpdateWebhook(r.Context(), tenantID, webhookID, &req)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	middleware.SetAuditAfter(r.Context(), webhook)
+
+	utils.Success(w, map[string]interface{}{
+		"webhook": webhook,
+	})
+}
+
+// Delete removes a webhook and its delivery log
+// DELETE /api/webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	before, err := h.webhookService.GetWebhook(r.Context(), tenantID, webhookID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	middleware.SetAuditBefore(r.Context(), before)
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), tenantID, webhookID); err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Webhook deleted successfully",
+	})
+}
+
+// RotateSecret replaces a webhook's signing secret and returns the new one
+// POST /api/webhooks/{id}/rotate-secret
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	secret, err := h.webhookService.RotateSecret(r.Context(), tenantID, webhookID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"secret":  secret,
+		"message": "Secret rotated. Store the new secret now, it will not be shown again",
+	})
+}
+
+// SendTest queues a webhook.test event to the webhook
+// POST /api/webhooks/{id}/test
+func (h *WebhookHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	delivery, err := h.webhookService.SendTestEvent(r.Context(), tenantID, userID, webhookID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.Created(w, map[string]interface{}{
+		"delivery": delivery,
+		"message":  "Test event queued for delivery",
+	})
+}
+
+// ListDeliveries lists a webhook's delivery log, newest first
+// GET /api/webhooks/{id}/deliveries?status=failed
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		errors := utils.ValidationErrors{}
+		utils.ValidateEnum("status", status, []string{
+			models.WebhookDeliveryPending,
+			models.WebhookDeliveryDelivered,
+			models.WebhookDeliveryFailed,
+		}, "Status", &errors)
+		if errors.HasErrors() {
+			utils.UnprocessableEntity(w, "Validation failed", errors.ToMap())
+			return
+		}
+	}
+
+	page, pageSize, offset := webhookPagination(r)
+
+	deliveries, totalCount, err := h.webhookService.ListDeliveries(r.Context(), tenantID, webhookID, status, pageSize, offset)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.SuccessWithMeta(w, deliveries, utils.NewMeta(page, pageSize, totalCount))
+}
+
+// GetDelivery retrieves one delivery, with its payload and the endpoint's
+// last response
+// GET /api/webhooks/{id}/deliveries/{deliveryID}
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid delivery ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(r.Context(), tenantID, webhookID, deliveryID)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	utils.Success(w, map[string]interface{}{
+		"delivery": delivery,
+	})
+}
+
+// RetryDelivery requeues a failed delivery
+// POST /api/webhooks/{id}/deliveries/{deliveryID}/retry
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID")
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		utils.BadRequest(w, "Invalid delivery ID")
+		return
+	}
+
+	tenantID, err := middleware.GetTenantIDFromContext(r.Context())
+	if err != nil {
+		utils.Unauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.webhookService.RetryDelivery(r.Context(), tenantID, webhookID, deliveryID); err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+
+	middleware.SetAuditMetadata(r.Context(), "delivery_id", deliveryID)
+
+	utils.Success(w, map[string]interface{}{
+		"message": "Delivery queued for retry",
+	})
+}
+
+// validateURL adds an error if url cannot be used as a webhook endpoint
+func (h *WebhookHandler) validateURL(url string, errors *utils.ValidationErrors) {
+	if err := h.webhookService.ValidateURL(url); err != nil {
+		errors.Add("url", "URL "+err.Error())
+	}
+}
+
+// validateWebhookEventTypes checks a webhook's subscriptions
+func validateWebhookEventTypes(eventTypes []string, errors *utils.ValidationErrors) {
+	if len(eventTypes) == 0 {
+		errors.Add("event_types", "At least one event type is required")
+		return
+	}
+
+	allowed := append([]string{models.WebhookEventAll}, models.EventTypes...)
+	for _, eventType := range eventTypes {
+		utils.ValidateEnum("event_types", eventType, allowed, "Event type", errors)
+	}
+}
+
+// validateWebhookRedactionRules checks that each redaction rule is a category
+// or a field name
+func validateWebhookRedactionRules(rules []string, errors *utils.ValidationErrors) {
+	for _, rule := range rules {
+		if !models.IsValidRedactionRule(rule) {
+			errors.Add("redaction_rules", fmt.Sprintf("Redaction rule %q must be one of %s or a lowercase field name", rule, strings.Join(models.RedactionCategories, ", ")))
+			return
+		}
+	}
+}
+
+// webhookPagination reads the page and page_size query parameters
+func webhookPagination(r *http.Request) (int, int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// respondWebhookError maps webhook service errors to HTTP responses
+func respondWebhookError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "webhook not found", "webhook delivery not found":
+		utils.NotFound(w, err.Error())
+	case "webhook delivery not found or not failed":
+		utils.Conflict(w, "Only failed deliveries can be retried")
+	case "webhook queue is full":
+		utils.TooManyRequests(w, "Too many deliveries are waiting for this webhook, try again later")
+	default:
+		utils.InternalServerError(w, "Webhook operation failed")
+	}
+}
+
+// RegisterRoutes registers all webhook routes
+func (h *WebhookHandler) RegisterRoutes(r chi.Router, authMiddleware *middleware.AuthMiddleware, permMiddleware *middleware.PermissionMiddleware, auditMiddleware *middleware.AuditMiddleware, planMiddleware *middleware.PlanMiddleware) {
+	r.Route("/webhooks", func(r chi.Router) {
+		// All webhook routes require authentication
+		r.Use(authMiddleware.Authenticate)
+
+		// Subscribable event types - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionView)).Get("/event-types", h.ListEventTypes)
+
+		// List and get webhooks - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionView)).Get("/", h.List)
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionView)).Get("/{id}", h.Get)
+
+		// Register webhook - requires create permission and a plan with webhooks
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionCreate),
+			planMiddleware.RequireFeature(models.PlanFeatureWebhooks),
+			auditMiddleware.Record(models.ActionWebhookCreated, models.ResourceWebhooks),
+		).Post("/", h.Create)
+
+		// Update webhook - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionEdit),
+			auditMiddleware.Record(models.ActionWebhookUpdated, models.ResourceWebhooks),
+		).Put("/{id}", h.Update)
+
+		// Delete webhook - requires delete permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionDelete),
+			auditMiddleware.Record(models.ActionWebhookDeleted, models.ResourceWebhooks),
+		).Delete("/{id}", h.Delete)
+
+		// Rotate signing secret - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionEdit),
+			auditMiddleware.Record(models.ActionWebhookSecretRotated, models.ResourceWebhooks),
+		).Post("/{id}/rotate-secret", h.RotateSecret)
+
+		// Send test event - requires edit permission
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionEdit)).Post("/{id}/test", h.SendTest)
+
+		// Delivery log - requires view permission
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionView)).Get("/{id}/deliveries", h.ListDeliveries)
+		r.With(permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionView)).Get("/{id}/deliveries/{deliveryID}", h.GetDelivery)
+
+		// Retry failed delivery - requires edit permission
+		r.With(
+			permMiddleware.RequirePermission(models.ResourceWebhooks, models.ActionEdit),
+			auditMiddleware.Record(models.ActionWebhookDeliveryRetried, models.ResourceWebhooks),
+		).Post("/{id}/deliveries/{deliveryID}/retry", h.RetryDelivery)
+	})
+}
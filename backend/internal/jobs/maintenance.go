@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// pausedTenantsKey is the context key of the tenants under maintenance
+type pausedTenantsKey struct{}
+
+// PausedTenants returns the tenants whose maintenance window is in progress
+// when the current run started. Jobs that change tenant data skip their
+// work until the window ends (see repository queries taking paused tenants).
+func PausedTenants(ctx context.Context) []uuid.UUID {
+	ids, _ := ctx.Value(pausedTenantsKey{}).([]uuid.UUID)
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
+
+// IsPaused returns true if the tenant's maintenance window was in progress
+// when the current run started
+func IsPaused(ctx context.Context, tenantID uuid.UUID) bool {
+	for _, id := range PausedTenants(ctx) {
+		if id == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// withPausedTenants looks up the tenants under maintenance in the catalog
+// database and adds them to the context of a run
+func withPausedTenants(ctx context.Context, db *sqlx.DB) (context.Context, error) {
+	ids := []uuid.UUID{}
+	query := `
+		SELECT id FROM tenants
+		WHERE maintenance_starts_at <= NOW() AND maintenance_ends_at > NOW()
+	`
+	if err := db.SelectContext(ctx, &ids, query); err != nil {
+		return ctx, fmt.Errorf("failed to list tenants under maintenance: %w", err)
+	}
+
+	return context.WithValue(ctx, pausedTenantsKey{}, ids), nil
+}
@@ -0,0 +1,263 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// Func is the body of a periodic job. It returns the number of rows/items it
+// processed, which is only used for logging.
+type Func func(ctx context.Context) (int, error)
+
+// job is a registered periodic job
+type job struct {
+	name     string
+	schedule Schedule
+	timeout  time.Duration // 0: until the next occurrence
+	run      Func
+}
+
+// Runner executes periodic background jobs.
+//
+// Every replica of the server runs a Runner, but each run of a job is guarded by
+// a cluster-wide advisory lock ("job:<name>"), so at most one replica executes a
+// given job at a time. Replicas that lose the race simply skip that tick. As
+// schedules give every replica the same occurrences, the replica that runs one
+// claims it in job_runs, and replicas that get the lock afterwards skip it too.
+// job_runs also records each job's last run and run totals (see Status).
+//
+// Each run's context carries the tenants whose maintenance window is in
+// progress (see PausedTenants).
+type Runner struct {
+	db   *sqlx.DB
+	jobs []job
+	err  error // First registration error, returned by Start
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner creates a job runner; db is the catalog database used for locking
+func NewRunner(db *sqlx.DB) *Runner {
+	return &Runner{db: db}
+}
+
+// Register adds a job that runs every interval. Jobs must be registered
+// before Start is called.
+func (r *Runner) Register(name string, interval time.Duration, run Func) {
+	r.RegisterWithTimeout(name, interval, interval, run)
+}
+
+// RegisterWithTimeout adds a job whose runs may take longer than its interval
+// (e.g. a short poll interval for long-running work). Ticks that fire while a
+// run is in progress are skipped.
+func (r *Runner) RegisterWithTimeout(name string, interval, timeout time.Duration, run Func) {
+	r.jobs = append(r.jobs, job{name: name, schedule: Every(interval), timeout: timeout, run: run})
+}
+
+// RegisterCron adds a job that runs on a cron expression (see ParseCron). A
+// run may last until the next occurrence. An invalid expression makes Start
+// fail.
+func (r *Runner) RegisterCron(name, spec string, run Func) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("job %s: %w", name, err)
+		}
+		return
+	}
+	r.jobs = append(r.jobs, job{name: name, schedule: schedule, run: run})
+}
+
+// Start launches every registered job in its own goroutine. It fails, without
+// starting any job, if a job could not be registered.
+func (r *Runner) Start(ctx context.Context) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	for _, j := range r.jobs {
+		r.wg.Add(1)
+		go func(j job) {
+			defer r.wg.Done()
+			r.loop(ctx, j)
+		}(j)
+	}
+
+	log.Printf("⏱️  Started %d background jobs", len(r.jobs))
+	return nil
+}
+
+// Stop cancels all jobs and waits for in-flight runs to finish
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Status lists the registered jobs with their schedule, next occurrence and
+// the last run and run totals recorded by whichever replicas ran them
+func (r *Runner) Status(ctx context.Context) ([]models.ScheduledJob, error) {
+	var runs []models.ScheduledJob
+	query := `
+		SELECT name, last_due_at, last_started_at, last_finished_at, last_status,
+		       last_duration_ms, last_processed, runs, failures, processed
+		FROM job_runs
+	`
+	if err := r.db.SelectContext(ctx, &runs, query); err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	byName := make(map[string]models.ScheduledJob, len(runs))
+	for _, run := range runs {
+		byName[run.Name] = run
+	}
+
+	now := time.Now()
+	jobs := make([]models.ScheduledJob, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		status := byName[j.name]
+		status.Name = j.name
+		status.Schedule = j.schedule.String()
+		if next := j.schedule.Next(now); !next.IsZero() {
+			status.NextRunAt = &next
+		}
+		jobs = append(jobs, status)
+	}
+
+	return jobs, nil
+}
+
+// loop runs a job on its schedule until the context is cancelled
+func (r *Runner) loop(ctx context.Context, j job) {
+	for {
+		due := j.schedule.Next(time.Now())
+		if due.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			r.runOnce(ctx, j, due)
+		}
+	}
+}
+
+// runOnce executes the occurrence of a job due at due, unless another replica
+// holds the job's lock or already ran the occurrence
+func (r *Runner) runOnce(ctx context.Context, j job, due time.Time) {
+	lock, err := database.TryAdvisoryLock(ctx, r.db, "job:"+j.name)
+	if err != nil {
+		log.Printf("⚠️  Job %s: %v", j.name, err)
+		return
+	}
+	if lock == nil {
+		// Another replica is running this job
+		return
+	}
+	defer lock.Release()
+
+	claimed, err := r.claim(ctx, j.name, due)
+	if err != nil {
+		log.Printf("⚠️  Job %s: %v", j.name, err)
+		return
+	}
+	if !claimed {
+		// Another replica already ran this occurrence
+		return
+	}
+
+	// A run may not outlast its timeout (by default until the next
+	// occurrence), otherwise ticks would pile up
+	timeout := j.timeout
+	if timeout == 0 {
+		timeout = j.schedule.Next(due).Sub(due)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Tenants under maintenance are left alone by jobs changing their data
+	runCtx, err = withPausedTenants(runCtx, r.db)
+	if err != nil {
+		log.Printf("⚠️  Job %s: %v", j.name, err)
+		r.record(context.WithoutCancel(ctx), j.name, 0, 0, err)
+		return
+	}
+
+	start := time.Now()
+	processed, err := j.run(runCtx)
+	duration := time.Since(start)
+	r.record(context.WithoutCancel(ctx), j.name, duration, processed, err)
+
+	if err != nil {
+		log.Printf("⚠️  Job %s failed after %s: %v", j.name, duration.Round(time.Millisecond), err)
+		return
+	}
+	if processed > 0 {
+		log.Printf("✅ Job %s processed %d items in %s", j.name, processed, duration.Round(time.Millisecond))
+	}
+}
+
+// claim marks the occurrence of a job due at due as started. It returns false
+// if this or a later occurrence was already claimed.
+func (r *Runner) claim(ctx context.Context, name string, due time.Time) (bool, error) {
+	query := `
+		INSERT INTO job_runs (name, last_due_at, last_started_at, last_status)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (name) DO UPDATE SET
+			last_due_at = EXCLUDED.last_due_at,
+			last_started_at = EXCLUDED.last_started_at,
+			last_finished_at = NULL,
+			last_status = EXCLUDED.last_status,
+			last_duration_ms = NULL,
+			last_processed = NULL
+		WHERE job_runs.last_due_at < EXCLUDED.last_due_at
+	`
+
+	result, err := r.db.ExecContext(ctx, query, name, due, models.ScheduledJobRunning)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim run: %w", err)
+	}
+
+	claimed, _ := result.RowsAffected()
+	return claimed > 0, nil
+}
+
+// record stores the outcome of a run in the job's last run and totals
+func (r *Runner) record(ctx context.Context, name string, duration time.Duration, processed int, runErr error) {
+	status, failures := models.ScheduledJobSucceeded, 0
+	if runErr != nil {
+		status, failures = models.ScheduledJobFailed, 1
+	}
+
+	query := `
+		UPDATE job_runs SET
+			last_finished_at = NOW(),
+			last_status = $2,
+			last_duration_ms = $3,
+			last_processed = $4,
+			runs = runs + 1,
+			failures = failures + $5,
+			processed = processed + $4
+		WHERE name = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, name, status, duration.Milliseconds(), processed, failures); err != nil {
+		log.Printf("⚠️  Job %s: failed to record run: %v", name, err)
+	}
+}
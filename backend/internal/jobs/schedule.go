@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a job runs. Schedules work in UTC and give every
+// replica the same run times, which is what lets replicas tell whether an
+// occurrence has already been run elsewhere.
+type Schedule interface {
+	// Next returns the first run time after t, or the zero time if the job
+	// never runs again
+	Next(t time.Time) time.Time
+
+	// String describes the schedule, e.g. "every 1h0m0s" or "30 3 * * *"
+	String() string
+}
+
+// Every runs a job at each multiple of interval since the Unix epoch, e.g.
+// on the hour for one hour
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.UTC().Truncate(s.interval).Add(s.interval)
+}
+
+func (s intervalSchedule) String() string {
+	return "every " + s.interval.String()
+}
+
+// cronShorthands are the predefined cron schedules
+var cronShorthands = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type cronSchedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// ParseCron parses a standard five-field cron expression: minute, hour, day
+// of month, month and day of week (0 or 7 is Sunday), evaluated in UTC.
+// Fields accept *, values, ranges (1-5), lists (1,15) and steps (*/15,
+// 0-30/10). The @hourly, @daily, @midnight, @weekly and @monthly shorthands
+// are also accepted.
+func ParseCron(spec string) (Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if shorthand, ok := cronShorthands[expr]; ok {
+		expr = shorthand
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields", spec)
+	}
+
+	s := &cronSchedule{spec: strings.TrimSpace(spec)}
+	bounds := []struct {
+		name     string
+		min, max int
+		set      *uint64
+	}{
+		{"minute", 0, 59, &s.minute},
+		{"hour", 0, 23, &s.hour},
+		{"day of month", 1, 31, &s.dom},
+		{"month", 1, 12, &s.month},
+		{"day of week", 0, 7, &s.dow},
+	}
+	for i, b := range bounds {
+		set, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s: %v", spec, b.name, err)
+		}
+		*b.set = set
+	}
+
+	// Sunday can be written 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	// As in cron, a job restricted by both day fields runs on days matching
+	// either. Fields starting with * are not restrictions.
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid cron expression %q: never runs", spec)
+	}
+
+	return s, nil
+}
+
+// parseCronField parses one field into the bit set of values it matches
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = min, max
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			from, err1 = strconv.Atoi(lo)
+			to, err2 = strconv.Atoi(hi)
+			if err1 != nil || err2 != nil || from > to {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			from, to = value, value
+			if hasStep {
+				to = max
+			}
+		}
+
+		if from < min || to > max {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for value := from; value <= to; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+	return set, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+
+	// Expressions that match rarely (e.g. February 29) still match within
+	// a few years
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day fields match t's day
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (s *cronSchedule) String() string {
+	return s.spec
+}
@@ -0,0 +1,118 @@
+// Package mail delivers emails through a pluggable provider: an SMTP server,
+// SendGrid, Amazon SES or Mailgun. Providers with an API also report bounces
+// and complaints back through webhooks, which WebhookParser verifies and
+// decodes.
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/config"
+)
+
+// Provider names
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderSES      = "ses"
+	ProviderMailgun  = "mailgun"
+)
+
+// Delivery event types reported by provider webhooks
+const (
+	EventBounce    = "bounce"    // The address does not exist or permanently refuses mail
+	EventComplaint = "complaint" // The recipient marked the email as spam
+)
+
+// webhookTolerance is how old a signed webhook request may be, so captured
+// requests cannot be replayed later
+const webhookTolerance = 10 * time.Minute
+
+// ErrInvalidSignature is returned for webhook requests that are not signed
+// by the provider
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Message is an email to deliver. Text is the plaintext alternative of the
+// HTML body; empty sends the HTML body alone.
+type Message struct {
+	To          string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Event is a bounce or complaint a provider reported for an address
+type Event struct {
+	Type   string
+	Email  string
+	Reason string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	// Name returns the provider's name
+	Name() string
+	// Send delivers a message from the configured sender
+	Send(ctx context.Context, msg *Message) error
+}
+
+// WebhookParser is implemented by providers that report bounces and
+// complaints through webhooks
+type WebhookParser interface {
+	// ParseWebhook verifies a webhook request of the provider and returns the
+	// bounces and complaints it reports. Requests the provider did not sign
+	// fail with ErrInvalidSignature.
+	ParseWebhook(ctx context.Context, header http.Header, body []byte) ([]Event, error)
+}
+
+// New creates the mailer selected by EMAIL_PROVIDER
+func New(cfg *config.EmailConfig) (Mailer, error) {
+	switch cfg.Provider {
+	case ProviderSMTP:
+		return NewSMTP(cfg), nil
+	case ProviderSendGrid:
+		return NewSendGrid(cfg)
+	case ProviderSES:
+		return NewSES(cfg)
+	case ProviderMailgun:
+		return NewMailgun(cfg)
+	default:
+		return nil, fmt.Errorf("unknown email provider %q", cfg.Provider)
+	}
+}
+
+// do sends an API request and turns error responses into errors
+func do(client *http.Client, name string, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s request failed with status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// fresh reports whether a webhook signed at t is recent enough to accept
+func fresh(t time.Time) bool {
+	age := time.Since(t)
+	return age < webhookTolerance && age > -webhookTolerance
+}
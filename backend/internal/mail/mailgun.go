@@ -0,0 +1,177 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	netmail "net/mail"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+// Mailgun delivers emails through the Mailgun messages API. Permanent
+// failures and complaints come back through its signed webhooks.
+type Mailgun struct {
+	cfg      *config.EmailConfig
+	endpoint string
+	client   *http.Client
+}
+
+// NewMailgun creates a Mailgun mailer
+func NewMailgun(cfg *config.EmailConfig) (*Mailgun, error) {
+	if cfg.MailgunDomain == "" || cfg.MailgunAPIKey == "" {
+		return nil, fmt.Errorf("mailgun domain and API key are required")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.MailgunBaseURL, "/"))
+	if err != nil || base.Scheme != "https" || base.Host == "" {
+		return nil, fmt.Errorf("invalid mailgun base URL %q", cfg.MailgunBaseURL)
+	}
+
+	return &Mailgun{
+		cfg:      cfg,
+		endpoint: base.String() + "/v3/" + url.PathEscape(cfg.MailgunDomain) + "/messages",
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport(ProviderMailgun, nil),
+		},
+	}, nil
+}
+
+// Name returns the provider's name
+func (m *Mailgun) Name() string {
+	return ProviderMailgun
+}
+
+// Send delivers a message with a messages request
+func (m *Mailgun) Send(ctx context.Context, msg *Message) error {
+	form := url.Values{}
+	form.Set("from", (&netmail.Address{Name: m.cfg.FromName, Address: m.cfg.FromEmail}).String())
+	form.Set("to", msg.To)
+	form.Set("subject", msg.Subject)
+	form.Set("html", msg.HTML)
+	if msg.Text != "" {
+		form.Set("text", msg.Text)
+	}
+
+	body, contentType, err := mailgunBody(form, msg.Attachments)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to build mailgun request: %w", err)
+	}
+	req.SetBasicAuth("api", m.cfg.MailgunAPIKey)
+	req.Header.Set("Content-Type", contentType)
+
+	return do(m.client, ProviderMailgun, req)
+}
+
+// mailgunBody encodes a messages request: a urlencoded form, or a
+// multipart/form-data form with an attachment field per file when there are
+// attachments
+func mailgunBody(form url.Values, attachments []Attachment) (io.Reader, string, error) {
+	if len(attachments) == 0 {
+		return strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", nil
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, values := range form {
+		for _, value := range values {
+			if err := w.WriteField(name, value); err != nil {
+				return nil, "", fmt.Errorf("failed to encode mailgun request: %w", err)
+			}
+		}
+	}
+	for _, a := range attachments {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {mime.FormatMediaType("form-data", map[string]string{"name": "attachment", "filename": a.Filename})},
+			"Content-Type":        {a.ContentType},
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode mailgun request: %w", err)
+		}
+		if _, err := part.Write(a.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to encode mailgun request: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to encode mailgun request: %w", err)
+	}
+	return &buf, w.FormDataContentType(), nil
+}
+
+// ParseWebhook verifies a webhook request, signed with HMAC-SHA256 of its
+// timestamp and token under the webhook signing key, and returns the
+// permanent failure or complaint it reports
+func (m *Mailgun) ParseWebhook(ctx context.Context, header http.Header, body []byte) ([]Event, error) {
+	if m.cfg.MailgunWebhookKey == "" {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+		EventData struct {
+			Event          string `json:"event"`
+			Severity       string `json:"severity"`
+			Recipient      string `json:"recipient"`
+			Reason         string `json:"reason"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid mailgun webhook payload: %w", err)
+	}
+
+	sig := payload.Signature
+	seconds, err := strconv.ParseInt(sig.Timestamp, 10, 64)
+	if err != nil || !fresh(time.Unix(seconds, 0)) {
+		return nil, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(m.cfg.MailgunWebhookKey))
+	mac.Write([]byte(sig.Timestamp + sig.Token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(sig.Signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	data := payload.EventData
+	switch {
+	case data.Event == "failed" && data.Severity == "permanent":
+		reason := data.DeliveryStatus.Description
+		if reason == "" {
+			reason = data.DeliveryStatus.Message
+		}
+		if reason == "" {
+			reason = data.Reason
+		}
+		return []Event{{Type: EventBounce, Email: data.Recipient, Reason: reason}}, nil
+	case data.Event == "complained":
+		return []Event{{Type: EventComplaint, Email: data.Recipient, Reason: "spam complaint"}}, nil
+	default:
+		return []Event{}, nil
+	}
+}
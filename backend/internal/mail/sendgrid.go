@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGrid delivers emails through the SendGrid v3 API. Bounces and spam
+// reports come back through its signed Event Webhook.
+type SendGrid struct {
+	cfg       *config.EmailConfig
+	publicKey *ecdsa.PublicKey // Verifies Event Webhook requests; nil refuses them
+	client    *http.Client
+}
+
+// NewSendGrid creates a SendGrid mailer
+func NewSendGrid(cfg *config.EmailConfig) (*SendGrid, error) {
+	if cfg.SendGridAPIKey == "" {
+		return nil, fmt.Errorf("sendgrid API key is required")
+	}
+
+	s := &SendGrid{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport(ProviderSendGrid, nil),
+		},
+	}
+
+	if cfg.SendGridWebhookPublicKey != "" {
+		der, err := base64.StdEncoding.DecodeString(cfg.SendGridWebhookPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sendgrid webhook public key: %w", err)
+		}
+		key, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sendgrid webhook public key: %w", err)
+		}
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("sendgrid webhook public key must be an ECDSA key")
+		}
+		s.publicKey = publicKey
+	}
+
+	return s, nil
+}
+
+// Name returns the provider's name
+func (s *SendGrid) Name() string {
+	return ProviderSendGrid
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"` // Base64 encoded
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+// Send delivers a message with a mail/send request
+func (s *SendGrid) Send(ctx context.Context, msg *Message) error {
+	// SendGrid requires the plaintext content before the HTML content
+	content := []sendGridContent{}
+	if msg.Text != "" {
+		content = append(content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	}
+	content = append(content, sendGridContent{Type: "text/html", Value: msg.HTML})
+
+	request := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []sendGridAddress{{Email: msg.To}}},
+		},
+		"from":    sendGridAddress{Email: s.cfg.FromEmail, Name: s.cfg.FromName},
+		"subject": msg.Subject,
+		"content": content,
+	}
+	if len(msg.Attachments) > 0 {
+		attachments := make([]sendGridAttachment, 0, len(msg.Attachments))
+		for _, a := range msg.Attachments {
+			attachments = append(attachments, sendGridAttachment{
+				Content:     base64.StdEncoding.EncodeToString(a.Content),
+				Type:        a.ContentType,
+				Filename:    a.Filename,
+				Disposition: "attachment",
+			})
+		}
+		request["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode sendgrid request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build sendgrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.SendGridAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(s.client, ProviderSendGrid, req)
+}
+
+// ParseWebhook verifies an Event Webhook request, signed with ECDSA over the
+// timestamp and body, and returns its bounces and spam reports. Blocked
+// deliveries are temporary and not reported.
+func (s *SendGrid) ParseWebhook(ctx context.Context, header http.Header, body []byte) ([]Event, error) {
+	if s.publicKey == nil {
+		return nil, ErrInvalidSignature
+	}
+
+	timestamp := header.Get("X-Twilio-Email-Event-Webhook-Timestamp")
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Twilio-Email-Event-Webhook-Signature"))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !fresh(time.Unix(seconds, 0)) {
+		return nil, ErrInvalidSignature
+	}
+	hash := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(s.publicKey, hash[:], signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload []struct {
+		Email  string `json:"email"`
+		Event  string `json:"event"`
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid sendgrid webhook payload: %w", err)
+	}
+
+	events := []Event{}
+	for _, e := range payload {
+		switch {
+		case e.Event == "bounce" && e.Type != "blocked":
+			events = append(events, Event{Type: EventBounce, Email: e.Email, Reason: e.Reason})
+		case e.Event == "spamreport":
+			events = append(events, Event{Type: EventComplaint, Email: e.Email, Reason: "spam report"})
+		}
+	}
+	return events, nil
+}
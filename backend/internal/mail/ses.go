@@ -0,0 +1,399 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	netmail "net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+// snsHostRegex matches the hosts SNS signing certificates and subscription
+// confirmations are served from
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// SES delivers emails through the Amazon SES v2 API, signing requests with
+// AWS Signature Version 4. Bounces and complaints come back as SNS
+// notifications, whose signatures are verified against the SNS certificate.
+type SES struct {
+	cfg      *config.EmailConfig
+	endpoint *url.URL
+	topics   map[string]bool // Accepted SNS topics; empty accepts any
+	client   *http.Client
+
+	certsMu sync.Mutex
+	certs   map[string]*rsa.PublicKey // SNS signing keys by certificate URL
+}
+
+// NewSES creates an SES mailer
+func NewSES(cfg *config.EmailConfig) (*SES, error) {
+	if cfg.SESAccessKeyID == "" || cfg.SESSecretKey == "" {
+		return nil, fmt.Errorf("ses access key is required")
+	}
+
+	region := cfg.SESRegion
+	if region == "" {
+		region = "us-east-1"
+	}
+	raw := cfg.SESEndpoint
+	if raw == "" {
+		raw = "https://email." + region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid ses endpoint %q", raw)
+	}
+	endpoint.Path += "/v2/email/outbound-emails"
+
+	topics := make(map[string]bool, len(cfg.SESSNSTopicARNs))
+	for _, arn := range cfg.SESSNSTopicARNs {
+		topics[arn] = true
+	}
+
+	return &SES{
+		cfg:      cfg,
+		endpoint: endpoint,
+		topics:   topics,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport(ProviderSES, nil),
+		},
+		certs: map[string]*rsa.PublicKey{},
+	}, nil
+}
+
+// Name returns the provider's name
+func (s *SES) Name() string {
+	return ProviderSES
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+// Send delivers a message with a SendEmail request
+func (s *SES) Send(ctx context.Context, msg *Message) error {
+	content, err := s.content(msg)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": (&netmail.Address{Name: s.cfg.FromName, Address: s.cfg.FromEmail}).String(),
+		"Destination":      map[string][]string{"ToAddresses": {msg.To}},
+		"Content":          content,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode ses request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build ses request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, payload, time.Now().UTC())
+
+	return do(s.client, ProviderSES, req)
+}
+
+// content returns the Content of a SendEmail request: Simple content, or the
+// raw MIME message when there are attachments, which Simple content cannot
+// carry
+func (s *SES) content(msg *Message) (map[string]interface{}, error) {
+	if len(msg.Attachments) > 0 {
+		raw, err := composeMIME(s.cfg.FromName, s.cfg.FromEmail, msg)
+		if err != nil {
+			return nil, err
+		}
+		// Data is a blob, which encodes as base64
+		return map[string]interface{}{
+			"Raw": map[string][]byte{"Data": raw},
+		}, nil
+	}
+
+	body := map[string]interface{}{
+		"Html": sesContent{Data: msg.HTML, Charset: "UTF-8"},
+	}
+	if msg.Text != "" {
+		body["Text"] = sesContent{Data: msg.Text, Charset: "UTF-8"}
+	}
+	return map[string]interface{}{
+		"Simple": map[string]interface{}{
+			"Subject": sesContent{Data: msg.Subject, Charset: "UTF-8"},
+			"Body":    body,
+		},
+	}, nil
+}
+
+// sign adds the AWS Signature Version 4 headers of a request with payload,
+// made at t
+func (s *SES) sign(req *http.Request, payload []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	hash := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+			"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	region := s.cfg.SESRegion
+	if region == "" {
+		region = "us-east-1"
+	}
+	scope := t.Format("20060102") + "/" + region + "/ses/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SESSecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.SESAccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// snsMessage is an SNS HTTP(S) delivery
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// sesNotification is an SES bounce or complaint notification, published by
+// an identity's notification topic (notificationType) or a configuration
+// set's event destination (eventType)
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+	} `json:"complaint"`
+}
+
+// ParseWebhook verifies an SNS delivery and returns the permanent bounces
+// and complaints of the SES notification it carries. Subscription
+// confirmations are confirmed and report nothing.
+func (s *SES) ParseWebhook(ctx context.Context, header http.Header, body []byte) ([]Event, error) {
+	var msg snsMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid sns message: %w", err)
+	}
+	if len(s.topics) > 0 && !s.topics[msg.TopicArn] {
+		return nil, ErrInvalidSignature
+	}
+	if err := s.verifySNS(ctx, &msg); err != nil {
+		return nil, err
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		return []Event{}, s.confirmSubscription(ctx, msg.SubscribeURL)
+	case "Notification":
+	default:
+		return []Event{}, nil
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(msg.Message), &notification); err != nil {
+		return nil, fmt.Errorf("invalid ses notification: %w", err)
+	}
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType
+	}
+
+	events := []Event{}
+	switch kind {
+	case "Bounce":
+		// Transient bounces (full mailbox, ...) may succeed later
+		if notification.Bounce.BounceType != "Permanent" {
+			break
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			events = append(events, Event{Type: EventBounce, Email: recipient.EmailAddress, Reason: recipient.DiagnosticCode})
+		}
+	case "Complaint":
+		reason := notification.Complaint.ComplaintFeedbackType
+		if reason == "" {
+			reason = "spam complaint"
+		}
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			events = append(events, Event{Type: EventComplaint, Email: recipient.EmailAddress, Reason: reason})
+		}
+	}
+	return events, nil
+}
+
+// verifySNS checks the signature of an SNS delivery against the SNS signing
+// certificate it names
+func (s *SES) verifySNS(ctx context.Context, msg *snsMessage) error {
+	// No freshness check: SNS retries failed deliveries for a while with the
+	// original timestamp, and replaying a bounce only marks it again
+	var fields []string
+	switch msg.Type {
+	case "Notification":
+		fields = []string{"Message", msg.Message, "MessageId", msg.MessageID}
+		if msg.Subject != "" {
+			fields = append(fields, "Subject", msg.Subject)
+		}
+		fields = append(fields, "Timestamp", msg.Timestamp, "TopicArn", msg.TopicArn, "Type", msg.Type)
+	case "SubscriptionConfirmation", "UnsubscribeConfirmation":
+		fields = []string{
+			"Message", msg.Message, "MessageId", msg.MessageID, "SubscribeURL", msg.SubscribeURL,
+			"Timestamp", msg.Timestamp, "Token", msg.Token, "TopicArn", msg.TopicArn, "Type", msg.Type,
+		}
+	default:
+		return ErrInvalidSignature
+	}
+	stringToSign := strings.Join(fields, "\n") + "\n"
+
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	key, err := s.signingKey(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	switch msg.SignatureVersion {
+	case "1":
+		hash := sha1.Sum([]byte(stringToSign))
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA1, hash[:], signature)
+	case "2":
+		hash := sha256.Sum256([]byte(stringToSign))
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	default:
+		return ErrInvalidSignature
+	}
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signingKey returns the public key of an SNS signing certificate, fetched
+// once per URL. Only certificates served by SNS are trusted.
+func (s *SES) signingKey(ctx context.Context, certURL string) (*rsa.PublicKey, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !snsHostRegex.MatchString(u.Host) || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, ErrInvalidSignature
+	}
+
+	s.certsMu.Lock()
+	key, ok := s.certs[certURL]
+	s.certsMu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sns certificate request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sns certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch sns certificate: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sns certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid sns certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sns certificate: %w", err)
+	}
+	key, ok = cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid sns certificate: not an RSA key")
+	}
+
+	s.certsMu.Lock()
+	s.certs[certURL] = key
+	s.certsMu.Unlock()
+	return key, nil
+}
+
+// confirmSubscription confirms the subscription of the webhook to an SNS
+// topic by visiting its SubscribeURL
+func (s *SES) confirmSubscription(ctx context.Context, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil || u.Scheme != "https" || !snsHostRegex.MatchString(u.Host) {
+		return ErrInvalidSignature
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build sns subscription request: %w", err)
+	}
+	return do(s.client, ProviderSES, req)
+}
@@ -0,0 +1,217 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+// SMTP delivers emails through an SMTP server. Without credentials it
+// connects without authentication, as local servers like Mailpit expect.
+type SMTP struct {
+	cfg *config.EmailConfig
+}
+
+// NewSMTP creates an SMTP mailer
+func NewSMTP(cfg *config.EmailConfig) *SMTP {
+	return &SMTP{cfg: cfg}
+}
+
+// Name returns the provider's name
+func (s *SMTP) Name() string {
+	return ProviderSMTP
+}
+
+// Send delivers a message over SMTP
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	start := time.Now()
+	err := s.send(msg)
+	metrics.ObserveExternal(ctx, ProviderSMTP, time.Since(start), err)
+	return err
+}
+
+// send delivers a message over SMTP
+func (s *SMTP) send(msg *Message) error {
+	from := s.cfg.FromEmail
+
+	// Compose message
+	message, err := composeMIME(s.cfg.FromName, from, msg)
+	if err != nil {
+		return err
+	}
+
+	// Connect to SMTP server
+	addr := fmt.Sprintf("%s:%d", s.cfg.SMTPHost, s.cfg.SMTPPort)
+
+	// For local development (Mailpit), no authentication is needed
+	if s.cfg.SMTPUser == "" && s.cfg.SMTPPassword == "" {
+		client, err := smtp.Dial(addr)
+		if err != nil {
+			return fmt.Errorf("failed to connect to SMTP server: %w", err)
+		}
+		defer client.Close()
+
+		if err := client.Mail(from); err != nil {
+			return fmt.Errorf("failed to set sender: %w", err)
+		}
+
+		if err := client.Rcpt(msg.To); err != nil {
+			return fmt.Errorf("failed to set recipient: %w", err)
+		}
+
+		w, err := client.Data()
+		if err != nil {
+			return fmt.Errorf("failed to get data writer: %w", err)
+		}
+
+		_, err = w.Write(message)
+		if err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+
+		err = w.Close()
+		if err != nil {
+			return fmt.Errorf("failed to close data writer: %w", err)
+		}
+
+		return client.Quit()
+	}
+
+	// For production with authentication
+	auth := smtp.PlainAuth("", s.cfg.SMTPUser, s.cfg.SMTPPassword, s.cfg.SMTPHost)
+	if err := smtp.SendMail(addr, auth, from, []string{msg.To}, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// composeMIME builds a MIME message: a multipart/alternative message with
+// the plaintext and HTML parts, or the HTML part alone when there is no text.
+// Attachments wrap the body in a multipart/mixed message, after it.
+func composeMIME(fromName, from string, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", (&netmail.Address{Name: fromName, Address: from}).String())
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	header, body, err := composeBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			if value := header.Get(key); value != "" {
+				fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+			}
+		}
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", parts.Boundary())
+
+	w, err := parts.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose message: %w", err)
+	}
+	w.Write(body)
+
+	for _, a := range msg.Attachments {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compose message: %w", err)
+		}
+		writeBase64(w, a.Content)
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compose message: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// composeBody builds the body of a message and the headers describing it
+func composeBody(msg *Message) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+
+	if msg.Text == "" {
+		if err := writeQuotedPrintable(&buf, msg.HTML); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {"text/html; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+
+	// Clients show the last part they support, so the HTML part comes last
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", msg.Text},
+		{"text/html; charset=UTF-8", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compose message: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to compose message: %w", err)
+	}
+
+	return textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+	}, buf.Bytes(), nil
+}
+
+// writeBase64 writes content in the base64 encoding, in lines of 76
+// characters as MIME requires
+func writeBase64(w io.Writer, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
+
+// writeQuotedPrintable writes content in the quoted-printable encoding, which
+// keeps lines within the length SMTP allows
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to compose message: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to compose message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package metrics
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"myerp-v2/internal/tracing"
+)
+
+// Stat kinds
+const (
+	KindEndpoint = "endpoint"
+	KindQuery    = "query"
+)
+
+// Stat aggregates the requests to an endpoint, or the slow runs of a query,
+// within an hour
+type Stat struct {
+	Bucket time.Time // Start of the hour
+	Kind   string
+	Name   string // "GET /users/{id}", or normalized query text
+
+	Count  int64
+	Errors int64 // 5xx responses, failed queries
+	Total  time.Duration
+	Max    time.Duration
+
+	// Time spent in each layer (endpoints only)
+	DB            time.Duration
+	DBCalls       int64
+	Redis         time.Duration
+	RedisCalls    int64
+	External      time.Duration
+	ExternalCalls int64
+}
+
+// add adds one observation to the stat
+func (s *Stat) add(d time.Duration, failed bool, cost *Cost) {
+	s.Count++
+	if failed {
+		s.Errors++
+	}
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	if cost != nil {
+		db, dbCalls := cost.DB()
+		redis, redisCalls := cost.Redis()
+		external, externalCalls := cost.External()
+		s.DB += db
+		s.DBCalls += dbCalls
+		s.Redis += redis
+		s.RedisCalls += redisCalls
+		s.External += external
+		s.ExternalCalls += externalCalls
+	}
+}
+
+// layerTotals are the lifetime totals of an instrumented layer
+type layerTotals struct {
+	calls  int64
+	errors int64
+	time   time.Duration
+}
+
+func (t *layerTotals) add(d time.Duration, failed bool) {
+	t.calls++
+	if failed {
+		t.errors++
+	}
+	t.time += d
+}
+
+type statKey struct {
+	bucket time.Time
+	kind   string
+	name   string
+}
+
+// maxPendingStats bounds the stats kept between two drains, in case queries
+// slip through normalization with unbounded variety
+const maxPendingStats = 10000
+
+// defaultSlowQueryThreshold applies until SetSlowQueryThreshold is called
+const defaultSlowQueryThreshold = 200 * time.Millisecond
+
+// collector holds the process-wide aggregates
+type collector struct {
+	mu        sync.Mutex
+	slowQuery time.Duration
+
+	// Hourly stats since the last Drain
+	pending map[statKey]*Stat
+
+	// Lifetime totals, for WritePrometheus
+	endpoints   map[string]*Stat
+	db          layerTotals
+	slowQueries int64
+	redis       layerTotals
+	external    map[string]*layerTotals // By service
+	rejected    map[string]int64        // Items refused by a full queue, by queue
+	deadLetters map[string]int64        // Items given up on, by queue
+}
+
+var std = &collector{
+	slowQuery:   defaultSlowQueryThreshold,
+	pending:     make(map[statKey]*Stat),
+	endpoints:   make(map[string]*Stat),
+	external:    make(map[string]*layerTotals),
+	rejected:    make(map[string]int64),
+	deadLetters: make(map[string]int64),
+}
+
+// SetSlowQueryThreshold sets how slow a query must be to enter the slow-query
+// log (METRICS_SLOW_QUERY_THRESHOLD)
+func SetSlowQueryThreshold(d time.Duration) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.slowQuery = d
+}
+
+// ObserveRequest records a request to an endpoint with the cost it accrued
+func ObserveRequest(method, route string, status int, d time.Duration, cost *Cost) {
+	name := method + " " + route
+	failed := status >= 500
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	lifetime, ok := std.endpoints[name]
+	if !ok {
+		lifetime = &Stat{Kind: KindEndpoint, Name: name}
+		std.endpoints[name] = lifetime
+	}
+	lifetime.add(d, failed, cost)
+
+	if stat := std.pendingStat(KindEndpoint, name); stat != nil {
+		stat.add(d, failed, cost)
+	}
+}
+
+// ObserveExternal records a call to an external service (e.g. "smtp") made
+// on behalf of ctx, with a span when ctx is traced. HTTP clients use
+// Transport instead.
+func ObserveExternal(ctx context.Context, service string, d time.Duration, err error) {
+	tracing.Record(ctx, service, time.Now().Add(-d), err, attribute.String("peer.service", service))
+	observeExternal(ctx, service, d, err)
+}
+
+// observeExternal records a call to an external service
+func observeExternal(ctx context.Context, service string, d time.Duration, err error) {
+	if cost := CostFromContext(ctx); cost != nil {
+		cost.addExternal(d)
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	totals, ok := std.external[service]
+	if !ok {
+		totals = &layerTotals{}
+		std.external[service] = totals
+	}
+	totals.add(d, err != nil)
+}
+
+// observeQuery records a database statement, with a span when ctx is traced;
+// slow ones enter the slow-query log
+func observeQuery(ctx context.Context, query string, d time.Duration, err error) {
+	if tracing.Recording(ctx) {
+		normalized := normalizeQuery(query)
+		operation, _, _ := strings.Cut(normalized, " ")
+		operation = strings.ToUpper(operation)
+		tracing.Record(ctx, operation, time.Now().Add(-d), err,
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(normalized),
+		)
+	}
+
+	if cost := CostFromContext(ctx); cost != nil {
+		cost.addDB(d)
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.db.add(d, err != nil)
+	if d < std.slowQuery {
+		return
+	}
+
+	std.slowQueries++
+	if stat := std.pendingStat(KindQuery, normalizeQuery(query)); stat != nil {
+		stat.add(d, err != nil, nil)
+	}
+}
+
+// observeRedis records a Redis round trip (a command or a pipeline)
+func observeRedis(ctx context.Context, d time.Duration, err error) {
+	if cost := CostFromContext(ctx); cost != nil {
+		cost.addRedis(d)
+	}
+
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.redis.add(d, err != nil)
+}
+
+// pendingStat returns the stat of the current hour, or nil when too many
+// distinct stats are pending. Callers hold std.mu.
+func (c *collector) pendingStat(kind, name string) *Stat {
+	key := statKey{bucket: time.Now().UTC().Truncate(time.Hour), kind: kind, name: name}
+
+	stat, ok := c.pending[key]
+	if !ok {
+		if len(c.pending) >= maxPendingStats {
+			return nil
+		}
+		stat = &Stat{Bucket: key.bucket, Kind: kind, Name: name}
+		c.pending[key] = stat
+	}
+	return stat
+}
+
+// Drain returns the hourly stats recorded since the last call and resets them
+func Drain() []Stat {
+	std.mu.Lock()
+	pending := std.pending
+	std.pending = make(map[statKey]*Stat)
+	std.mu.Unlock()
+
+	stats := make([]Stat, 0, len(pending))
+	for _, stat := range pending {
+		stats = append(stats, *stat)
+	}
+	return stats
+}
+
+var (
+	whitespacePattern     = regexp.MustCompile(`\s+`)
+	stringLiteralPattern  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteralPattern = regexp.MustCompile(`(^|[\s,(=<>+\-*/])\d+(?:\.\d+)?\b`)
+)
+
+// maxQueryLength bounds the query text kept in the slow-query log
+const maxQueryLength = 2000
+
+// normalizeQuery collapses whitespace and replaces literals with ?, so runs
+// of the same statement aggregate together and no values are stored
+func normalizeQuery(query string) string {
+	query = stringLiteralPattern.ReplaceAllString(query, "?")
+	query = numericLiteralPattern.ReplaceAllString(query, "${1}?")
+	query = strings.TrimSpace(whitespacePattern.ReplaceAllString(query, " "))
+
+	if len(query) > maxQueryLength {
+		query = query[:maxQueryLength]
+		for !utf8.ValidString(query) {
+			query = query[:len(query)-1]
+		}
+		query += "…"
+	}
+	return query
+}
@@ -0,0 +1,73 @@
+// Package metrics accounts for where requests spend their time: in the
+// database, in Redis and in calls to external services (webhooks, identity
+// providers, SMTP).
+//
+// The instrumented layers (WrapConnector, RedisHook, Transport) report every
+// call to the process-wide collector and to the Cost carried by the request
+// context, if any, and add a span to traced contexts (see internal/tracing). The RequestCost middleware attaches that Cost and records
+// each request against its endpoint. Aggregates are exposed in Prometheus
+// format (WritePrometheus) and drained periodically into hourly rows (Drain).
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Cost is the time a single request spent in each instrumented layer. It is
+// safe for concurrent use, as handlers may query from several goroutines.
+type Cost struct {
+	dbNanos       atomic.Int64
+	dbCalls       atomic.Int64
+	redisNanos    atomic.Int64
+	redisCalls    atomic.Int64
+	externalNanos atomic.Int64
+	externalCalls atomic.Int64
+}
+
+type costKey struct{}
+
+// WithCost returns a context carrying a new, empty Cost
+func WithCost(ctx context.Context) (context.Context, *Cost) {
+	cost := &Cost{}
+	return context.WithValue(ctx, costKey{}, cost), cost
+}
+
+// CostFromContext returns the Cost of the request, or nil outside requests
+// (e.g. background jobs)
+func CostFromContext(ctx context.Context) *Cost {
+	cost, _ := ctx.Value(costKey{}).(*Cost)
+	return cost
+}
+
+// DB returns the time spent in the database and the number of statements
+func (c *Cost) DB() (time.Duration, int64) {
+	return time.Duration(c.dbNanos.Load()), c.dbCalls.Load()
+}
+
+// Redis returns the time spent in Redis and the number of round trips
+func (c *Cost) Redis() (time.Duration, int64) {
+	return time.Duration(c.redisNanos.Load()), c.redisCalls.Load()
+}
+
+// External returns the time spent calling external services and the number
+// of calls
+func (c *Cost) External() (time.Duration, int64) {
+	return time.Duration(c.externalNanos.Load()), c.externalCalls.Load()
+}
+
+func (c *Cost) addDB(d time.Duration) {
+	c.dbNanos.Add(int64(d))
+	c.dbCalls.Add(1)
+}
+
+func (c *Cost) addRedis(d time.Duration) {
+	c.redisNanos.Add(int64(d))
+	c.redisCalls.Add(1)
+}
+
+func (c *Cost) addExternal(d time.Duration) {
+	c.externalNanos.Add(int64(d))
+	c.externalCalls.Add(1)
+}
@@ -0,0 +1,303 @@
+package metrics
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+)
+
+// WrapConnector returns a connector whose connections time every statement,
+// including the time spent reading result rows, and report it as database
+// time. Open the pool with sql.OpenDB(WrapConnector(connector)).
+func WrapConnector(c driver.Connector) driver.Connector {
+	return &connector{Connector: c}
+}
+
+type connector struct {
+	driver.Connector
+}
+
+// Close closes the wrapped connector if it holds resources, e.g. a pool;
+// sql.DB.Close calls it
+func (c *connector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{conn: conn}, nil
+}
+
+// instrumentedConn wraps a driver connection. It implements the optional
+// interfaces of pgx connections and falls back to driver.ErrSkip (or a
+// no-op) when the wrapped connection lacks one.
+type instrumentedConn struct {
+	conn driver.Conn
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	observeQuery(ctx, query, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{stmt: stmt, query: query}, nil
+}
+
+func (c *instrumentedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *instrumentedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.conn.Begin()
+	}
+	observeQuery(ctx, "BEGIN", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{tx: tx, ctx: ctx}, nil
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		// ErrSkip only asks database/sql to take another code path
+		if !errors.Is(err, driver.ErrSkip) {
+			observeQuery(ctx, query, time.Since(start), err)
+		}
+		return nil, err
+	}
+	return &instrumentedRows{rows: rows, ctx: ctx, query: query, start: start}, nil
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		observeQuery(ctx, query, time.Since(start), err)
+	}
+	return result, err
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue lets the wrapped driver accept arguments database/sql
+// can't convert itself, e.g. slices pgx encodes as arrays
+func (c *instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// Unwrap returns the wrapped connection, for sql.Conn.Raw callers that need
+// the driver's own connection
+func (c *instrumentedConn) Unwrap() driver.Conn {
+	return c.conn
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// instrumentedTx times commits and rollbacks
+type instrumentedTx struct {
+	tx  driver.Tx
+	ctx context.Context
+}
+
+func (t *instrumentedTx) Commit() error {
+	start := time.Now()
+	err := t.tx.Commit()
+	observeQuery(t.ctx, "COMMIT", time.Since(start), err)
+	return err
+}
+
+func (t *instrumentedTx) Rollback() error {
+	start := time.Now()
+	err := t.tx.Rollback()
+	observeQuery(t.ctx, "ROLLBACK", time.Since(start), err)
+	return err
+}
+
+// instrumentedStmt times executions of a prepared statement
+type instrumentedStmt struct {
+	stmt  driver.Stmt
+	query string
+}
+
+func (s *instrumentedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *instrumentedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *instrumentedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *instrumentedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.stmt.Exec(values(args))
+	}
+	observeQuery(ctx, s.query, time.Since(start), err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.stmt.Query(values(args))
+	}
+	if err != nil {
+		observeQuery(ctx, s.query, time.Since(start), err)
+		return nil, err
+	}
+	return &instrumentedRows{rows: rows, ctx: ctx, query: s.query, start: start}, nil
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	plain := make([]driver.Value, len(args))
+	for i, arg := range args {
+		plain[i] = arg.Value
+	}
+	return plain
+}
+
+// instrumentedRows reports the query once its rows are closed, so the time
+// spent streaming results is included
+type instrumentedRows struct {
+	rows  driver.Rows
+	ctx   context.Context
+	query string
+	start time.Time
+	err   error
+}
+
+func (r *instrumentedRows) Columns() []string {
+	return r.rows.Columns()
+}
+
+func (r *instrumentedRows) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.rows.Close()
+	observeQuery(r.ctx, r.query, time.Since(r.start), r.err)
+	return err
+}
+
+// Column type information is passed through, so sql.Rows.ColumnTypes keeps
+// working with the wrapped driver
+
+func (r *instrumentedRows) ColumnTypeScanType(index int) reflect.Type {
+	if typed, ok := r.rows.(driver.RowsColumnTypeScanType); ok {
+		return typed.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *instrumentedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if typed, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return typed.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *instrumentedRows) ColumnTypeLength(index int) (int64, bool) {
+	if typed, ok := r.rows.(driver.RowsColumnTypeLength); ok {
+		return typed.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *instrumentedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if typed, ok := r.rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return typed.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WritePrometheus writes the lifetime totals of this process in the
+// Prometheus text exposition format. Every replica exposes its own totals;
+// sum them across instances in queries.
+func WritePrometheus(w io.Writer) error {
+	std.mu.Lock()
+	endpoints := make([]Stat, 0, len(std.endpoints))
+	for _, stat := range std.endpoints {
+		endpoints = append(endpoints, *stat)
+	}
+	db, slowQueries, redis := std.db, std.slowQueries, std.redis
+	external := make(map[string]layerTotals, len(std.external))
+	for service, totals := range std.external {
+		external[service] = *totals
+	}
+	std.mu.Unlock()
+
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Name < endpoints[j].Name })
+	services := make([]string, 0, len(external))
+	for service := range external {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	out := bufio.NewWriter(w)
+
+	endpointMetric := func(name, help, kind string, value func(Stat) string) {
+		writeHeader(out, name, help, kind)
+		for _, stat := range endpoints {
+			method, route, _ := strings.Cut(stat.Name, " ")
+			fmt.Fprintf(out, "%s{method=\"%s\",route=\"%s\"} %s\n", name, escapeLabel(method), escapeLabel(route), value(stat))
+		}
+	}
+	endpointMetric("myerp_http_requests_total", "Requests handled, by endpoint.", "counter",
+		func(s Stat) string { return fmt.Sprint(s.Count) })
+	endpointMetric("myerp_http_request_errors_total", "Requests that ended with a 5xx response, by endpoint.", "counter",
+		func(s Stat) string { return fmt.Sprint(s.Errors) })
+	endpointMetric("myerp_http_request_seconds_total", "Time spent handling requests, by endpoint.", "counter",
+		func(s Stat) string { return seconds(s.Total.Seconds()) })
+	endpointMetric("myerp_http_request_db_seconds_total", "Time requests spent in the database, by endpoint.", "counter",
+		func(s Stat) string { return seconds(s.DB.Seconds()) })
+	endpointMetric("myerp_http_request_redis_seconds_total", "Time requests spent in Redis, by endpoint.", "counter",
+		func(s Stat) string { return seconds(s.Redis.Seconds()) })
+	endpointMetric("myerp_http_request_external_seconds_total", "Time requests spent calling external services, by endpoint.", "counter",
+		func(s Stat) string { return seconds(s.External.Seconds()) })
+
+	writeHeader(out, "myerp_db_statements_total", "Database statements executed, including background work.", "counter")
+	fmt.Fprintf(out, "myerp_db_statements_total %d\n", db.calls)
+	writeHeader(out, "myerp_db_errors_total", "Database statements that failed.", "counter")
+	fmt.Fprintf(out, "myerp_db_errors_total %d\n", db.errors)
+	writeHeader(out, "myerp_db_seconds_total", "Time spent in database statements.", "counter")
+	fmt.Fprintf(out, "myerp_db_seconds_total %s\n", seconds(db.time.Seconds()))
+	writeHeader(out, "myerp_db_slow_statements_total", "Database statements slower than METRICS_SLOW_QUERY_THRESHOLD.", "counter")
+	fmt.Fprintf(out, "myerp_db_slow_statements_total %d\n", slowQueries)
+
+	writeHeader(out, "myerp_redis_commands_total", "Redis round trips (commands or pipelines), including background work.", "counter")
+	fmt.Fprintf(out, "myerp_redis_commands_total %d\n", redis.calls)
+	writeHeader(out, "myerp_redis_errors_total", "Redis round trips that failed.", "counter")
+	fmt.Fprintf(out, "myerp_redis_errors_total %d\n", redis.errors)
+	writeHeader(out, "myerp_redis_seconds_total", "Time spent in Redis round trips.", "counter")
+	fmt.Fprintf(out, "myerp_redis_seconds_total %s\n", seconds(redis.time.Seconds()))
+
+	externalMetric := func(name, help string, value func(layerTotals) string) {
+		writeHeader(out, name, help, "counter")
+		for _, service := range services {
+			fmt.Fprintf(out, "%s{service=\"%s\"} %s\n", name, escapeLabel(service), value(external[service]))
+		}
+	}
+	externalMetric("myerp_external_calls_total", "Calls to external services, by service.",
+		func(t layerTotals) string { return fmt.Sprint(t.calls) })
+	externalMetric("myerp_external_errors_total", "Calls to external services that failed, by service.",
+		func(t layerTotals) string { return fmt.Sprint(t.errors) })
+	externalMetric("myerp_external_seconds_total", "Time spent calling external services, by service.",
+		func(t layerTotals) string { return seconds(t.time.Seconds()) })
+
+	writeQueueCounters(out)
+
+	return out.Flush()
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.6f", s)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// QueueGauge is the current depth of a queue. Depths are read from the
+// database and so are the same on every replica; take the max across
+// instances in queries, not the sum.
+type QueueGauge struct {
+	Queue       string
+	Pending     int
+	Due         int
+	DeadLetters int
+	OldestDue   time.Duration // Wait of the oldest due item
+	Alert       bool
+}
+
+// ObserveQueueRejected records an item a full queue refused
+func ObserveQueueRejected(queue string, count int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.rejected[queue] += int64(count)
+}
+
+// ObserveDeadLetter records an item given up on after its last attempt
+func ObserveDeadLetter(queue string) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.deadLetters[queue]++
+}
+
+// writeQueueCounters writes the lifetime queue counters of this process
+func writeQueueCounters(out *bufio.Writer) {
+	std.mu.Lock()
+	rejected := make(map[string]int64, len(std.rejected))
+	for queue, count := range std.rejected {
+		rejected[queue] = count
+	}
+	deadLetters := make(map[string]int64, len(std.deadLetters))
+	for queue, count := range std.deadLetters {
+		deadLetters[queue] = count
+	}
+	std.mu.Unlock()
+
+	queueCounter := func(name, help string, counts map[string]int64) {
+		queues := make([]string, 0, len(counts))
+		for queue := range counts {
+			queues = append(queues, queue)
+		}
+		sort.Strings(queues)
+
+		writeHeader(out, name, help, "counter")
+		for _, queue := range queues {
+			fmt.Fprintf(out, "%s{queue=\"%s\"} %d\n", name, escapeLabel(queue), counts[queue])
+		}
+	}
+	queueCounter("myerp_queue_rejected_total", "Items refused because their queue was full, by queue.", rejected)
+	queueCounter("myerp_queue_dead_lettered_total", "Items given up on after their last attempt, by queue.", deadLetters)
+}
+
+// WriteQueueGauges writes queue depths in the Prometheus text exposition
+// format
+func WriteQueueGauges(w io.Writer, gauges []QueueGauge) error {
+	out := bufio.NewWriter(w)
+
+	queueMetric := func(name, help string, value func(QueueGauge) string) {
+		writeHeader(out, name, help, "gauge")
+		for _, gauge := range gauges {
+			fmt.Fprintf(out, "%s{queue=\"%s\"} %s\n", name, escapeLabel(gauge.Queue), value(gauge))
+		}
+	}
+	queueMetric("myerp_queue_pending", "Items waiting in a queue, including retries not yet due.",
+		func(g QueueGauge) string { return fmt.Sprint(g.Pending) })
+	queueMetric("myerp_queue_due", "Items waiting in a queue and due now.",
+		func(g QueueGauge) string { return fmt.Sprint(g.Due) })
+	queueMetric("myerp_queue_dead_letters", "Items given up on and kept for inspection or retry.",
+		func(g QueueGauge) string { return fmt.Sprint(g.DeadLetters) })
+	queueMetric("myerp_queue_oldest_due_seconds", "How long the oldest due item has been waiting.",
+		func(g QueueGauge) string { return seconds(g.OldestDue.Seconds()) })
+	queueMetric("myerp_queue_alert", "1 when a queue is over QUEUE_ALERT_DEPTH or its oldest due item waited longer than QUEUE_ALERT_AGE.",
+		func(g QueueGauge) string {
+			if g.Alert {
+				return "1"
+			}
+			return "0"
+		})
+
+	return out.Flush()
+}
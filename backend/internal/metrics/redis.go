@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"myerp-v2/internal/tracing"
+)
+
+// RedisHook times Redis commands and pipelines and reports them as Redis
+// time, and as spans of traced requests. Install it with client.AddHook(metrics.RedisHook{}). Pub/sub
+// receives are not commands and are not counted.
+type RedisHook struct{}
+
+// DialHook leaves connection dialing untimed
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook times a single command, with a span when ctx is traced
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		observeRedis(ctx, time.Since(start), redisFailure(err))
+		tracing.Record(ctx, cmd.Name(), start, redisFailure(err),
+			semconv.DBSystemNameRedis,
+			semconv.DBOperationName(cmd.Name()),
+		)
+		return err
+	}
+}
+
+// ProcessPipelineHook times a pipeline as one round trip, with one span
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		observeRedis(ctx, time.Since(start), redisFailure(err))
+		tracing.Record(ctx, "pipeline", start, redisFailure(err),
+			semconv.DBSystemNameRedis,
+			semconv.DBOperationName("pipeline"),
+			semconv.DBOperationBatchSize(len(cmds)),
+		)
+		return err
+	}
+}
+
+// redisFailure drops redis.Nil, which only reports a missing key
+func redisFailure(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
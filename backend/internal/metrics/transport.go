@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"myerp-v2/internal/tracing"
+)
+
+// Transport wraps an HTTP transport so that its calls count as external time
+// of the request that made them, under service (e.g. "webhook"). A call is
+// timed until the response headers arrive.
+func Transport(service string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{service: service, next: next}
+}
+
+type transport struct {
+	service string
+	next    http.RoundTripper
+}
+
+// RoundTrip records the call, with a span when the request's context is
+// traced. The trace context is not propagated: the services called are
+// third parties.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	observeExternal(req.Context(), t.service, time.Since(start), err)
+
+	if tracing.Recording(req.Context()) {
+		attrs := []attribute.KeyValue{
+			attribute.String("peer.service", t.service),
+			semconv.HTTPRequestMethodOriginal(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+		}
+		spanErr := err
+		if resp != nil {
+			attrs = append(attrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+			if resp.StatusCode >= 500 {
+				spanErr = fmt.Errorf("%s responded with status %d", t.service, resp.StatusCode)
+			}
+		}
+		tracing.Record(req.Context(), req.Method, start, spanErr, attrs...)
+	}
+	return resp, err
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"myerp-v2/internal/services"
+)
+
+// RouteAccess describes who may call a route, as declared by the auth and
+// permission middleware it is registered with. The API docs use it to show
+// users only the operations they can call.
+type RouteAccess struct {
+	Authenticated bool
+	Permissions   [][]services.PermissionCheck // Every entry must pass; an entry passes with any one of its checks
+	Role          string
+	Owner         bool
+	Admin         bool
+	PlatformAdmin bool
+}
+
+// Restricted reports whether the route requires more than being signed in
+func (a RouteAccess) Restricted() bool {
+	return len(a.Permissions) > 0 || a.Role != "" || a.Owner || a.Admin || a.PlatformAdmin
+}
+
+// accessHandler is a handler enforcing an access rule of its route
+type accessHandler struct {
+	http.Handler
+	describe func(*RouteAccess)
+}
+
+// withAccess marks h as enforcing the access rule applied by describe
+func withAccess(h http.HandlerFunc, describe func(*RouteAccess)) http.Handler {
+	return &accessHandler{Handler: h, describe: describe}
+}
+
+// DescribeAccess combines the access rules of a route's middlewares, as
+// listed by chi.Walk. Middlewares not enforcing one are ignored.
+func DescribeAccess(middlewares []func(http.Handler) http.Handler) RouteAccess {
+	var access RouteAccess
+	for _, mw := range middlewares {
+		if h, ok := mw(http.NotFoundHandler()).(*accessHandler); ok {
+			h.describe(&access)
+		}
+	}
+	return access
+}
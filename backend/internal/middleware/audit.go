@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// AuditEntry collects what a handler changed, so the audit middleware can
+// record it once the request has been served
+type AuditEntry struct {
+	ResourceID uuid.UUID
+	ActorID    uuid.UUID
+	Before     json.RawMessage
+	After      json.RawMessage
+	Metadata   map[string]interface{}
+}
+
+// AuditMiddleware records audit log entries for mutating endpoints and
+// publishes successful changes on the event bus
+type AuditMiddleware struct {
+	auditService services.AuditManager
+	eventBus     *services.EventBus
+}
+
+// NewAuditMiddleware creates a new audit middleware
+func NewAuditMiddleware(auditService services.AuditManager, eventBus *services.EventBus) *AuditMiddleware {
+	return &AuditMiddleware{
+		auditService: auditService,
+		eventBus:     eventBus,
+	}
+}
+
+// Record audits the wrapped route as action on resourceType. The resource ID
+// defaults to the {id} URL parameter; handlers add the before/after state with
+// SetAuditBefore/SetAuditAfter. Responses with a 4xx/5xx status are recorded
+// as failures; successful changes are also published as events.
+func (m *AuditMiddleware) Record(action, resourceType string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entry := &AuditEntry{Metadata: map[string]interface{}{}}
+			if id, err := uuid.Parse(chi.URLParam(r, "id")); err == nil {
+				entry.ResourceID = id
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ctx := context.WithValue(r.Context(), "audit_entry", entry)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			tenantID, err := GetTenantIDFromContext(ctx)
+			if err != nil {
+				return
+			}
+
+			actorID := entry.ActorID
+			if actorID == uuid.Nil {
+				actorID, _ = GetUserIDFromContext(ctx)
+			}
+
+			status := models.AuditStatusSuccess
+			if ww.Status() >= http.StatusBadRequest {
+				status = models.AuditStatusFailure
+			}
+
+			metadata := entry.Metadata
+			metadata["http_status"] = ww.Status()
+			if entry.Before != nil {
+				metadata["before"] = entry.Before
+			}
+			if entry.After != nil {
+				metadata["after"] = entry.After
+			}
+
+			// The response is written; record the entry even if the client
+			// has gone away
+			err = m.auditService.LogEvent(context.WithoutCancel(ctx), tenantID, actorID, action, resourceType, entry.ResourceID, status, utils.GetClientIP(r), r.UserAgent(), metadata)
+			if err != nil {
+				log.Printf("⚠️  Failed to record audit event %s: %v", action, err)
+			}
+
+			if status == models.AuditStatusSuccess {
+				m.publishEvent(context.WithoutCancel(ctx), tenantID, actorID, action, resourceType, entry)
+			}
+		})
+	}
+}
+
+// publishEvent publishes the change if action is an event type. The event
+// carries the resource after the change, or before it for deletions, and the
+// resource before an update.
+func (m *AuditMiddleware) publishEvent(ctx context.Context, tenantID, actorID uuid.UUID, action, resourceType string, entry *AuditEntry) {
+	if m.eventBus == nil || !models.IsEventType(action) {
+		return
+	}
+
+	event := &models.Event{
+		Type:         action,
+		TenantID:     tenantID,
+		ResourceType: resourceType,
+		Object:       entry.After,
+	}
+	if event.Object == nil {
+		event.Object = entry.Before
+	} else {
+		event.Previous = entry.Before
+	}
+	if entry.ResourceID != uuid.Nil {
+		resourceID := entry.ResourceID
+		event.ResourceID = &resourceID
+	}
+	if actorID != uuid.Nil {
+		event.ActorID = &actorID
+	}
+
+	m.eventBus.Publish(ctx, event)
+}
+
+// getAuditEntry returns the entry of the audited request, if any
+func getAuditEntry(ctx context.Context) *AuditEntry {
+	entry, _ := ctx.Value("audit_entry").(*AuditEntry)
+	return entry
+}
+
+// SetAuditResourceID sets the audited resource, e.g. once it was created
+func SetAuditResourceID(ctx context.Context, resourceID uuid.UUID) {
+	if entry := getAuditEntry(ctx); entry != nil {
+		entry.ResourceID = resourceID
+	}
+}
+
+// SetAuditActor sets who performed the change, for public routes without an
+// authenticated user
+func SetAuditActor(ctx context.Context, userID uuid.UUID) {
+	if entry := getAuditEntry(ctx); entry != nil {
+		entry.ActorID = userID
+	}
+}
+
+// SetAuditBefore snapshots the resource before it is changed (see
+// services.AuditSnapshot)
+func SetAuditBefore(ctx context.Context, v interface{}) {
+	if entry := getAuditEntry(ctx); entry != nil {
+		entry.Before = services.AuditSnapshot(v)
+	}
+}
+
+// SetAuditAfter snapshots the resource after it was changed
+func SetAuditAfter(ctx context.Context, v interface{}) {
+	if entry := getAuditEntry(ctx); entry != nil {
+		entry.After = services.AuditSnapshot(v)
+	}
+}
+
+// SetAuditMetadata adds a metadata field to the audit entry
+func SetAuditMetadata(ctx context.Context, key string, value interface{}) {
+	if entry := getAuditEntry(ctx); entry != nil {
+		entry.Metadata[key] = value
+	}
+}
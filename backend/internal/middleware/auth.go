@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
 	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
@@ -14,11 +15,11 @@ import (
 
 // AuthMiddleware handles authentication
 type AuthMiddleware struct {
-	authService *services.AuthService
+	authService services.AuthManager
 }
 
 // NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
+func NewAuthMiddleware(authService services.AuthManager) *AuthMiddleware {
 	return &AuthMiddleware{
 		authService: authService,
 	}
@@ -26,7 +27,7 @@ func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
 
 // Authenticate validates JWT token and adds user/tenant to context
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return withAccess(func(w http.ResponseWriter, r *http.Request) {
 		// Extract token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -50,6 +51,28 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		if tenant.IsSandbox() {
+			w.Header().Set(SandboxHeader, "true")
+		}
+
+		// Suspended tenants may read but not change their data
+		if !enforceReadOnly(w, r, tenant) {
+			return
+		}
+
+		// During a maintenance window changes wait until it ends
+		if !enforceMaintenance(w, r, tenant) {
+			return
+		}
+
+		// Meter the request, within the plan's monthly API calls
+		if !enforceAPIQuota(w, r, user, tenant) {
+			return
+		}
+
+		// Count the request in usage analytics
+		identifyUsage(r.Context(), user, tenant)
+
 		// Add user, tenant, and token to context
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, "user_id", user.ID)
@@ -58,9 +81,17 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		ctx = context.WithValue(ctx, "user", user)
 		ctx = context.WithValue(ctx, "tenant", tenant)
 		ctx = context.WithValue(ctx, "access_token", accessToken)
+		traceIdentity(ctx, user, tenant)
+
+		// The request's repository calls share one connection, set to the
+		// tenant once
+		ctx, release := database.WithSession(ctx, tenant.ID)
+		defer release()
 
 		// Continue with authenticated context
 		next.ServeHTTP(w, r.WithContext(ctx))
+	}, func(a *RouteAccess) {
+		a.Authenticated = true
 	})
 }
 
@@ -93,6 +124,24 @@ func (m *AuthMiddleware) Optional(next http.Handler) http.Handler {
 			return
 		}
 
+		// Suspended tenants may read but not change their data
+		if !enforceReadOnly(w, r, tenant) {
+			return
+		}
+
+		// During a maintenance window changes wait until it ends
+		if !enforceMaintenance(w, r, tenant) {
+			return
+		}
+
+		// Meter the request, within the plan's monthly API calls
+		if !enforceAPIQuota(w, r, user, tenant) {
+			return
+		}
+
+		// Count the request in usage analytics
+		identifyUsage(r.Context(), user, tenant)
+
 		// Add user, tenant, and token to context
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, "user_id", user.ID)
@@ -101,6 +150,12 @@ func (m *AuthMiddleware) Optional(next http.Handler) http.Handler {
 		ctx = context.WithValue(ctx, "user", user)
 		ctx = context.WithValue(ctx, "tenant", tenant)
 		ctx = context.WithValue(ctx, "access_token", accessToken)
+		traceIdentity(ctx, user, tenant)
+
+		// The request's repository calls share one connection, set to the
+		// tenant once
+		ctx, release := database.WithSession(ctx, tenant.ID)
+		defer release()
 
 		// Continue with authenticated context
 		next.ServeHTTP(w, r.WithContext(ctx))
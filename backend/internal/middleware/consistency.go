@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"myerp-v2/internal/database"
+)
+
+// ReadYourWritesHeader asks for a request's reads to be served by the
+// primary database rather than the read replica, e.g. right after a change
+// the client is about to show: the replica may lag behind
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
+// ReadYourWrites sends the reads of requests carrying ReadYourWritesHeader
+// to the primary database. Without a replica it changes nothing.
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if primary, _ := strconv.ParseBool(r.Header.Get(ReadYourWritesHeader)); primary {
+			r = r.WithContext(database.WithPrimary(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
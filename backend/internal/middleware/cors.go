@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"myerp-v2/internal/services"
+)
+
+// AllowOrigin is the origin check of the CORS middleware. An origin is allowed
+// when it matches one of origins, where one * matches any subdomain
+// (https://*.example.com), or is a verified custom domain of a tenant.
+func AllowOrigin(origins []string, tenantDomainService services.TenantDomainManager) func(r *http.Request, origin string) bool {
+	patterns := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if origin = strings.ToLower(strings.TrimSuffix(origin, "/")); origin != "" {
+			patterns = append(patterns, origin)
+		}
+	}
+
+	return func(r *http.Request, origin string) bool {
+		origin = strings.ToLower(origin)
+		for _, pattern := range patterns {
+			if matchOrigin(pattern, origin) {
+				return true
+			}
+		}
+		return tenantDomainService.IsTenantOrigin(r.Context(), origin)
+	}
+}
+
+// matchOrigin matches an origin against an allowed origin, with at most one *
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == origin
+	}
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+)
+
+// CostMiddleware accounts for the time each request spends in the database,
+// Redis and external services, per endpoint (see internal/metrics)
+type CostMiddleware struct {
+	config *config.MetricsConfig
+}
+
+// NewCostMiddleware creates a new request cost middleware
+func NewCostMiddleware(cfg *config.MetricsConfig) *CostMiddleware {
+	return &CostMiddleware{
+		config: cfg,
+	}
+}
+
+// Track attaches a metrics.Cost to the request and records the request
+// against its route pattern once handled. Requests slower than
+// METRICS_SLOW_REQUEST_THRESHOLD are logged with their cost. Event streams
+// stay open by design and are left out.
+func (m *CostMiddleware) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cost := metrics.WithCost(r.Context())
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		start := time.Now()
+		next.ServeHTTP(ww, r.WithContext(ctx))
+		duration := time.Since(start)
+
+		if strings.HasPrefix(ww.Header().Get("Content-Type"), "text/event-stream") {
+			return
+		}
+
+		// Routes are aggregated by pattern (/users/{id}), unknown paths together
+		route := "(unmatched)"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.ObserveRequest(r.Method, route, status, duration, cost)
+
+		if m.config.SlowRequestThreshold > 0 && duration >= m.config.SlowRequestThreshold {
+			db, dbCalls := cost.DB()
+			redis, redisCalls := cost.Redis()
+			external, externalCalls := cost.External()
+			log.Printf("🐢 Slow request %s %s (%d) took %s: db %s in %d statements, redis %s in %d commands, external %s in %d calls",
+				r.Method, route, status, duration.Round(time.Millisecond),
+				db.Round(time.Millisecond), dbCalls,
+				redis.Round(time.Millisecond), redisCalls,
+				external.Round(time.Millisecond), externalCalls)
+		}
+	})
+}
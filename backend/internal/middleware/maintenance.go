@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// MaintenanceHeader is set on responses served for a tenant with a scheduled
+// maintenance window, as "<starts_at>/<ends_at>", so clients can announce it
+const MaintenanceHeader = "X-Maintenance-Window"
+
+// maintenanceSettingsPath stays writable during a maintenance window, so the
+// window can be ended early
+const maintenanceSettingsPath = "/settings/maintenance"
+
+// enforceMaintenance refuses changes while the tenant's maintenance window is
+// in progress. It marks the response and returns false once it has written
+// the refusal.
+func enforceMaintenance(w http.ResponseWriter, r *http.Request, tenant *models.Tenant) bool {
+	window := tenant.MaintenanceWindow()
+	if window == nil {
+		return true
+	}
+	w.Header().Set(MaintenanceHeader, window.StartsAt.UTC().Format(time.RFC3339)+"/"+window.EndsAt.UTC().Format(time.RFC3339))
+
+	if !window.Active {
+		return true
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if strings.HasPrefix(r.URL.Path, maintenanceSettingsPath) {
+		return true
+	}
+	for _, path := range readOnlyAllowedPaths {
+		if strings.HasPrefix(r.URL.Path, path) {
+			return true
+		}
+	}
+	for _, path := range window.AllowedPaths {
+		if strings.HasPrefix(r.URL.Path, path) {
+			return true
+		}
+	}
+
+	retryAfter := int(math.Ceil(time.Until(window.EndsAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	details := map[string]string{
+		"starts_at":   window.StartsAt.UTC().Format(time.RFC3339),
+		"ends_at":     window.EndsAt.UTC().Format(time.RFC3339),
+		"retry_after": strconv.Itoa(retryAfter),
+	}
+	if window.Message != "" {
+		details["message"] = window.Message
+	}
+
+	utils.ErrorWithDetails(w, http.StatusServiceUnavailable, "MAINTENANCE_WINDOW",
+		fmt.Sprintf("Changes are paused for scheduled maintenance until %s", window.EndsAt.UTC().Format(time.RFC3339)), details)
+	return false
+}
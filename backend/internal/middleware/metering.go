@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// meteringKey is the context key of the metering service authentication
+// counts requests with
+type meteringKey struct{}
+
+// meteringExemptPaths are routes that stay available once the month's API
+// calls reach the plan's limit, besides those of read-only tenants: looking
+// at the usage that got the tenant there
+var meteringExemptPaths = []string{
+	"/tenant/usage",
+}
+
+// enforceAPIQuota counts an authenticated request against the tenant's API
+// calls and refuses it once the month's calls reach its plan's limit. It
+// returns false once it has written the refusal. Redis failures let the
+// request through: an outage must not take the API down.
+func enforceAPIQuota(w http.ResponseWriter, r *http.Request, user *models.User, tenant *models.Tenant) bool {
+	metering, ok := r.Context().Value(meteringKey{}).(services.MeteringManager)
+	if !ok {
+		return true
+	}
+
+	exempt := false
+	for _, paths := range [][]string{readOnlyAllowedPaths, meteringExemptPaths} {
+		for _, path := range paths {
+			if strings.HasPrefix(r.URL.Path, path) {
+				exempt = true
+			}
+		}
+	}
+
+	allowed, limit, err := metering.RecordAPICall(r.Context(), tenant, user.ID, exempt)
+	if err != nil {
+		log.Printf("⚠️  Metering of tenant %s failed: %v", tenant.ID, err)
+		return true
+	}
+	if allowed {
+		return true
+	}
+
+	utils.ErrorWithDetails(w, http.StatusPaymentRequired, "PLAN_LIMIT_REACHED", "Your plan's monthly API call limit is reached", map[string]string{
+		"metric":    models.QuotaMetricAPICalls,
+		"plan_tier": tenant.PlanTier,
+		"limit":     strconv.FormatInt(limit, 10),
+	})
+	return false
+}
+
+// MeteringMiddleware meters authenticated requests per tenant for plan
+// limits and billing
+type MeteringMiddleware struct {
+	meteringService services.MeteringManager
+}
+
+// NewMeteringMiddleware creates a new metering middleware
+func NewMeteringMiddleware(meteringService services.MeteringManager) *MeteringMiddleware {
+	return &MeteringMiddleware{
+		meteringService: meteringService,
+	}
+}
+
+// Track has authentication, further down the chain, count the request once
+// it knows the tenant and enforce the plan's monthly API call limit
+func (m *MeteringMiddleware) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), meteringKey{}, m.meteringService)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
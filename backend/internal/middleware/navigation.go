@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"myerp-v2/internal/services"
+)
+
+// NavigationMiddleware records the entities users open for their recently
+// viewed list
+type NavigationMiddleware struct {
+	navigationService services.NavigationManager
+}
+
+// NewNavigationMiddleware creates a new navigation middleware
+func NewNavigationMiddleware(navigationService services.NavigationManager) *NavigationMiddleware {
+	return &NavigationMiddleware{
+		navigationService: navigationService,
+	}
+}
+
+// TrackView records the entity of the {id} URL parameter as viewed by the
+// user when the wrapped route responds 200 OK
+func (m *NavigationMiddleware) TrackView(entityType string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			if ww.Status() != http.StatusOK {
+				return
+			}
+
+			entityID, err := uuid.Parse(chi.URLParam(r, "id"))
+			if err != nil {
+				return
+			}
+			tenantID, err := GetTenantIDFromContext(r.Context())
+			if err != nil {
+				return
+			}
+			userID, err := GetUserIDFromContext(r.Context())
+			if err != nil {
+				return
+			}
+
+			// The response is written; record the view even if the client
+			// has gone away
+			err = m.navigationService.RecordView(context.WithoutCancel(r.Context()), tenantID, userID, entityType, entityID)
+			if err != nil {
+				log.Printf("⚠️  Failed to record view of %s %s: %v", entityType, entityID, err)
+			}
+		})
+	}
+}
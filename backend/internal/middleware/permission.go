@@ -12,11 +12,11 @@ import (
 
 // PermissionMiddleware handles authorization
 type PermissionMiddleware struct {
-	permissionService *services.PermissionService
+	permissionService services.PermissionManager
 }
 
 // NewPermissionMiddleware creates a new permission middleware
-func NewPermissionMiddleware(permissionService *services.PermissionService) *PermissionMiddleware {
+func NewPermissionMiddleware(permissionService services.PermissionManager) *PermissionMiddleware {
 	return &PermissionMiddleware{
 		permissionService: permissionService,
 	}
@@ -25,7 +25,7 @@ func NewPermissionMiddleware(permissionService *services.PermissionService) *Per
 // RequirePermission checks if user has a specific permission
 func (m *PermissionMiddleware) RequirePermission(resource, action string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return withAccess(func(w http.ResponseWriter, r *http.Request) {
 			// Get user and tenant from context (set by auth middleware)
 			userID, err := GetUserIDFromContext(r.Context())
 			if err != nil {
@@ -52,6 +52,8 @@ func (m *PermissionMiddleware) RequirePermission(resource, action string) func(h
 			}
 
 			next.ServeHTTP(w, r)
+		}, func(a *RouteAccess) {
+			a.Permissions = append(a.Permissions, []services.PermissionCheck{{Resource: resource, Action: action}})
 		})
 	}
 }
@@ -59,7 +61,7 @@ func (m *PermissionMiddleware) RequirePermission(resource, action string) func(h
 // RequireAnyPermission checks if user has any of the specified permissions
 func (m *PermissionMiddleware) RequireAnyPermission(checks []services.PermissionCheck) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return withAccess(func(w http.ResponseWriter, r *http.Request) {
 			// Get user and tenant from context
 			userID, err := GetUserIDFromContext(r.Context())
 			if err != nil {
@@ -86,6 +88,8 @@ func (m *PermissionMiddleware) RequireAnyPermission(checks []services.Permission
 			}
 
 			next.ServeHTTP(w, r)
+		}, func(a *RouteAccess) {
+			a.Permissions = append(a.Permissions, checks)
 		})
 	}
 }
@@ -93,7 +97,7 @@ func (m *PermissionMiddleware) RequireAnyPermission(checks []services.Permission
 // RequireAllPermissions checks if user has all of the specified permissions
 func (m *PermissionMiddleware) RequireAllPermissions(checks []services.PermissionCheck) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return withAccess(func(w http.ResponseWriter, r *http.Request) {
 			// Get user and tenant from context
 			userID, err := GetUserIDFromContext(r.Context())
 			if err != nil {
@@ -120,6 +124,10 @@ func (m *PermissionMiddleware) RequireAllPermissions(checks []services.Permissio
 			}
 
 			next.ServeHTTP(w, r)
+		}, func(a *RouteAccess) {
+			for _, check := range checks {
+				a.Permissions = append(a.Permissions, []services.PermissionCheck{check})
+			}
 		})
 	}
 }
@@ -127,7 +135,7 @@ func (m *PermissionMiddleware) RequireAllPermissions(checks []services.Permissio
 // RequireRole checks if user has a specific role
 func (m *PermissionMiddleware) RequireRole(roleName string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		return withAccess(func(w http.ResponseWriter, r *http.Request) {
 			// Get user and tenant from context
 			userID, err := GetUserIDFromContext(r.Context())
 			if err != nil {
@@ -154,13 +162,15 @@ func (m *PermissionMiddleware) RequireRole(roleName string) func(http.Handler) h
 			}
 
 			next.ServeHTTP(w, r)
+		}, func(a *RouteAccess) {
+			a.Role = roleName
 		})
 	}
 }
 
 // RequireOwner ensures user is an owner
 func (m *PermissionMiddleware) RequireOwner(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return withAccess(func(w http.ResponseWriter, r *http.Request) {
 		// Get user and tenant from context
 		userID, err := GetUserIDFromContext(r.Context())
 		if err != nil {
@@ -187,12 +197,14 @@ func (m *PermissionMiddleware) RequireOwner(next http.Handler) http.Handler {
 		}
 
 		next.ServeHTTP(w, r)
+	}, func(a *RouteAccess) {
+		a.Owner = true
 	})
 }
 
 // RequireAdmin ensures user is an admin or owner
 func (m *PermissionMiddleware) RequireAdmin(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return withAccess(func(w http.ResponseWriter, r *http.Request) {
 		// Get user and tenant from context
 		userID, err := GetUserIDFromContext(r.Context())
 		if err != nil {
@@ -219,6 +231,8 @@ func (m *PermissionMiddleware) RequireAdmin(next http.Handler) http.Handler {
 		}
 
 		next.ServeHTTP(w, r)
+	}, func(a *RouteAccess) {
+		a.Admin = true
 	})
 }
 
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PlanMiddleware refuses actions the tenant's plan does not include or that
+// would take it over a plan limit, with 402 Payment Required so clients can
+// offer an upgrade
+type PlanMiddleware struct {
+	billingService services.BillingManager
+	quotaService   services.QuotaManager
+}
+
+// NewPlanMiddleware creates a new plan middleware
+func NewPlanMiddleware(billingService services.BillingManager, quotaService services.QuotaManager) *PlanMiddleware {
+	return &PlanMiddleware{
+		billingService: billingService,
+		quotaService:   quotaService,
+	}
+}
+
+// RequireFeature refuses the request unless the tenant's plan includes the
+// feature
+func (m *PlanMiddleware) RequireFeature(feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := GetTenantIDFromContext(r.Context())
+			if err != nil {
+				utils.Unauthorized(w, "Authentication required")
+				return
+			}
+
+			included, tier, err := m.billingService.HasFeature(r.Context(), tenantID, feature)
+			if err != nil {
+				utils.InternalServerError(w, "Failed to check plan")
+				return
+			}
+
+			if !included {
+				utils.ErrorWithDetails(w, http.StatusPaymentRequired, "PLAN_UPGRADE_REQUIRED", "Your plan does not include this feature", map[string]string{
+					"feature":   feature,
+					"plan_tier": tier,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUserQuota refuses the request if the tenant already has as many
+// users as its plan allows, e.g. inviting an 11th user on a 10-user plan
+func (m *PlanMiddleware) RequireUserQuota(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, err := GetTenantIDFromContext(r.Context())
+		if err != nil {
+			utils.Unauthorized(w, "Authentication required")
+			return
+		}
+
+		if err := m.quotaService.CheckUserQuota(r.Context(), tenantID, 1); err != nil {
+			if err.Error() == "plan user limit reached" {
+				tenant, _ := GetTenantFromContext(r.Context())
+				details := map[string]string{"metric": "users"}
+				if tenant != nil {
+					details["plan_tier"] = tenant.PlanTier
+					if limit := m.billingService.Plan(tenant.PlanTier).UserLimit; limit != nil {
+						details["limit"] = strconv.FormatInt(*limit, 10)
+					}
+				}
+				utils.ErrorWithDetails(w, http.StatusPaymentRequired, "PLAN_LIMIT_REACHED", "Your plan's user limit is reached", details)
+				return
+			}
+			utils.InternalServerError(w, "Failed to check plan")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
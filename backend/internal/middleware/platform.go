@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// PlatformMiddleware guards the platform administration routes
+type PlatformMiddleware struct {
+	platformService services.PlatformManager
+}
+
+// NewPlatformMiddleware creates a new platform middleware
+func NewPlatformMiddleware(platformService services.PlatformManager) *PlatformMiddleware {
+	return &PlatformMiddleware{
+		platformService: platformService,
+	}
+}
+
+// RequirePlatformAdmin ensures the user is a platform admin with a second
+// factor. Tenant roles grant nothing here: an owner of any tenant is refused.
+func (m *PlatformMiddleware) RequirePlatformAdmin(next http.Handler) http.Handler {
+	return withAccess(func(w http.ResponseWriter, r *http.Request) {
+		user, err := GetUserFromContext(r.Context())
+		if err != nil {
+			utils.Unauthorized(w, "Authentication required")
+			return
+		}
+
+		isAdmin, err := m.platformService.IsAdmin(r.Context(), user)
+		if err != nil {
+			utils.InternalServerError(w, "Failed to check platform admin status")
+			return
+		}
+
+		if !isAdmin {
+			utils.Forbidden(w, "Platform admin access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}, func(a *RouteAccess) {
+		a.PlatformAdmin = true
+	})
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+const rateLimitKeyPrefix = "ratelimit"
+
+// Rate limit response headers. When both the IP and the tenant bucket apply,
+// the headers describe whichever has fewer requests left.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+)
+
+// tokenBucketScript refills the bucket for the time elapsed since its last use
+// and takes one token if available. It runs atomically in Redis and uses the
+// Redis clock, so every replica shares the same bucket state.
+//
+// KEYS[1] = bucket key, ARGV[1] = capacity (burst), ARGV[2] = refill rate per ms
+// Returns {allowed (0|1), tokens remaining, ms until the next token}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now_parts = redis.call('TIME')
+local now = tonumber(now_parts[1]) * 1000 + math.floor(tonumber(now_parts[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry_ms = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_ms = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate))
+return {allowed, math.floor(tokens), retry_ms}
+`)
+
+// RateLimitMiddleware throttles API traffic with Redis-backed token buckets,
+// one per client IP and one per tenant
+type RateLimitMiddleware struct {
+	redis      *redis.Client
+	jwtService services.JWTManager
+	config     *config.SecurityConfig
+}
+
+// NewRateLimitMiddleware creates a new rate limit middleware
+func NewRateLimitMiddleware(redisClient *redis.Client, jwtService services.JWTManager, cfg *config.SecurityConfig) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		redis:      redisClient,
+		jwtService: jwtService,
+		config:     cfg,
+	}
+}
+
+// LimitByIP throttles requests per client IP. It must run after
+// middleware.RealIP so proxied requests are attributed to the real client.
+func (m *RateLimitMiddleware) LimitByIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.config.RateLimitEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := database.CacheKey(rateLimitKeyPrefix, "ip", clientIP(r))
+		if !m.allow(w, r, key, m.config.IPRateLimit, m.config.IPRateBurst) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitByTenant throttles requests per tenant, so one busy tenant cannot
+// starve the others. The tenant comes from the claims of a valid access
+// token only: the X-Tenant-Slug header and the host ResolveTenant reads are
+// chosen by the client, so anyone could drain another tenant's bucket with
+// them. Unauthenticated requests are left to the IP limit.
+func (m *RateLimitMiddleware) LimitByTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.config.RateLimitEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenantID, ok := m.requestTenant(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := database.CacheKey(rateLimitKeyPrefix, "tenant", tenantID.String())
+		if !m.allow(w, r, key, m.config.TenantRateLimit, m.config.TenantRateBurst) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token from the bucket and writes the rate limit headers. It
+// writes a 429 response and returns false when the bucket is empty. Redis
+// failures let the request through: an outage must not take the API down.
+func (m *RateLimitMiddleware) allow(w http.ResponseWriter, r *http.Request, key string, perMinute, burst int) bool {
+	allowed, remaining, retryMs, err := m.take(r.Context(), key, perMinute, burst)
+	if err != nil {
+		log.Printf("⚠️  Rate limit check for %s failed: %v", key, err)
+		return true
+	}
+
+	setRateLimitHeaders(w, perMinute, remaining)
+
+	if !allowed {
+		retryAfter := int(math.Ceil(float64(retryMs) / 1000))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		utils.TooManyRequests(w, fmt.Sprintf("Rate limit exceeded, please retry in %d seconds", retryAfter))
+		return false
+	}
+
+	return true
+}
+
+// take runs the token bucket script for key
+func (m *RateLimitMiddleware) take(ctx context.Context, key string, perMinute, burst int) (bool, int64, int64, error) {
+	ratePerMs := float64(perMinute) / 60000
+	result, err := tokenBucketScript.Run(ctx, m.redis, []string{key}, burst, strconv.FormatFloat(ratePerMs, 'f', -1, 64)).Int64Slice()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if len(result) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit script result %v", result)
+	}
+	return result[0] == 1, result[1], result[2], nil
+}
+
+// requestTenant returns the tenant of the request's access token, if it has
+// a valid one
+func (m *RateLimitMiddleware) requestTenant(r *http.Request) (uuid.UUID, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return uuid.Nil, false
+	}
+	claims, err := m.jwtService.ValidateAccessToken(token)
+	if err != nil || claims.TenantID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return claims.TenantID, true
+}
+
+// setRateLimitHeaders writes the limit headers unless an earlier limiter
+// already reported fewer remaining requests
+func setRateLimitHeaders(w http.ResponseWriter, perMinute int, remaining int64) {
+	if current, err := strconv.ParseInt(w.Header().Get(RateLimitRemainingHeader), 10, 64); err == nil && current <= remaining {
+		return
+	}
+	w.Header().Set(RateLimitLimitHeader, strconv.Itoa(perMinute))
+	w.Header().Set(RateLimitRemainingHeader, strconv.FormatInt(remaining, 10))
+}
+
+// clientIP returns the request's client IP without the port
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// ReadOnlyHeader is set on responses served for a read-only (suspended)
+// tenant, so clients can explain why changes are disabled
+const ReadOnlyHeader = "X-Tenant-Read-Only"
+
+// readOnlyAllowedPaths are routes that stay writable for read-only tenants:
+// signing in and out, securing the account, paying for the plan, taking the
+// tenant's data and leaving (along with DELETE /tenant), erasing one's own
+// personal data, and requests that only read
+var readOnlyAllowedPaths = []string{
+	"/auth/",
+	"/sessions",
+	"/2fa/",
+	"/webauthn/",
+	"/notifications",
+	"/permissions/check",
+	"/approval-links/preview",
+	"/billing/",
+	"/tenant/export",
+	"/tenant/deletion",
+	"/users/me/erasure-request",
+}
+
+// enforceReadOnly refuses changes for a read-only tenant. It marks the
+// response and returns false once it has written the refusal.
+func enforceReadOnly(w http.ResponseWriter, r *http.Request, tenant *models.Tenant) bool {
+	if !tenant.IsReadOnly() {
+		return true
+	}
+	w.Header().Set(ReadOnlyHeader, tenant.ReadOnlyReason())
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if r.Method == http.MethodDelete && r.URL.Path == "/tenant" {
+		return true
+	}
+	for _, path := range readOnlyAllowedPaths {
+		if strings.HasPrefix(r.URL.Path, path) {
+			return true
+		}
+	}
+
+	details := map[string]string{
+		"reason": tenant.ReadOnlyReason(),
+	}
+	if tenant.SuspendedAt != nil {
+		details["suspended_at"] = tenant.SuspendedAt.UTC().Format(time.RFC3339)
+	}
+
+	// Overdue payments get 402 so clients can send users to billing
+	status := http.StatusLocked
+	if tenant.ReadOnlyReason() == models.TenantSuspensionPaymentOverdue {
+		status = http.StatusPaymentRequired
+	}
+	utils.ErrorWithDetails(w, status, "TENANT_READ_ONLY", "Tenant account is suspended and read-only", details)
+	return false
+}
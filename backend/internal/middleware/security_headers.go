@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"myerp-v2/internal/config"
+)
+
+// SecurityHeadersMiddleware sets the security headers configured for the
+// environment on every response
+type SecurityHeadersMiddleware struct {
+	headers map[string]string
+}
+
+// NewSecurityHeadersMiddleware creates a new security headers middleware
+func NewSecurityHeadersMiddleware(cfg *config.SecurityHeadersConfig) *SecurityHeadersMiddleware {
+	headers := map[string]string{
+		// Never guess a content type from the body
+		"X-Content-Type-Options": "nosniff",
+	}
+	if cfg.HSTSMaxAge > 0 {
+		hsts := fmt.Sprintf("max-age=%d", int64(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	if cfg.ContentSecurityPolicy != "" {
+		headers["Content-Security-Policy"] = cfg.ContentSecurityPolicy
+	}
+	if cfg.FrameOptions != "" {
+		headers["X-Frame-Options"] = cfg.FrameOptions
+	}
+	if cfg.ReferrerPolicy != "" {
+		headers["Referrer-Policy"] = cfg.ReferrerPolicy
+	}
+
+	return &SecurityHeadersMiddleware{headers: headers}
+}
+
+// Apply sets the headers before the request is handled, so handlers serving
+// other content (e.g. file downloads) may override them
+func (m *SecurityHeadersMiddleware) Apply(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		for name, value := range m.headers {
+			header.Set(name, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
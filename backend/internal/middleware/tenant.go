@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/google/uuid"
-	"myerp-v2/internal/repository"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
 	"myerp-v2/internal/utils"
 )
 
@@ -15,65 +17,63 @@ var (
 	ErrNoTenantContext = errors.New("no tenant context found")
 )
 
+// SandboxHeader is set on responses served for a sandbox tenant, so clients
+// can clearly mark the environment
+const SandboxHeader = "X-Tenant-Sandbox"
+
+// TenantSlugMovedHeader carries the tenant's current slug on responses to
+// requests naming it by a former slug in X-Tenant-Slug
+const TenantSlugMovedHeader = "X-Tenant-Slug-Moved"
+
 // TenantMiddleware resolves tenant from request
 type TenantMiddleware struct {
-	tenantRepo *repository.TenantRepository
+	tenantDomainService services.TenantDomainManager
 }
 
 // NewTenantMiddleware creates a new tenant middleware
-func NewTenantMiddleware(tenantRepo *repository.TenantRepository) *TenantMiddleware {
+func NewTenantMiddleware(tenantDomainService services.TenantDomainManager) *TenantMiddleware {
 	return &TenantMiddleware{
-		tenantRepo: tenantRepo,
+		tenantDomainService: tenantDomainService,
 	}
 }
 
-// ResolveTenant resolves tenant from subdomain or X-Tenant-Slug header
+// ResolveTenant resolves the tenant from, in order, the X-Tenant-Slug header,
+// the request host (<slug>.<BASE_DOMAIN> or a verified custom domain) and the
+// Origin of browser requests (a frontend served from the tenant's domain
+// calling a shared API host)
 func (m *TenantMiddleware) ResolveTenant(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var tenantSlug string
-
-		// 1. Try X-Tenant-Slug header (for testing/development)
-		if slug := r.Header.Get("X-Tenant-Slug"); slug != "" {
-			tenantSlug = slug
-		} else {
-			// 2. Try subdomain (e.g., acme.myerp.local -> "acme")
-			host := r.Host
-			// Remove port if present
-			if colonIndex := strings.Index(host, ":"); colonIndex != -1 {
-				host = host[:colonIndex]
-			}
-
-			// Split by dots and check for subdomain
-			parts := strings.Split(host, ".")
-			if len(parts) >= 2 {
-				// If host is like "acme.myerp.local", tenant is "acme"
-				// Exclude system subdomains (single domain architecture)
-				systemSubdomains := []string{"localhost", "myerp", "app", "api", "www"}
-				isSystemSubdomain := false
-				for _, sys := range systemSubdomains {
-					if parts[0] == sys {
-						isSystemSubdomain = true
-						break
-					}
-				}
-				if !isSystemSubdomain {
-					tenantSlug = parts[0]
-				}
-			}
+		tenant, err := m.resolve(r)
+		if errors.Is(err, utils.ErrNotFound) {
+			utils.NotFound(w, "Tenant not found")
+			return
+		}
+		if err != nil {
+			utils.InternalServerError(w, "Failed to resolve tenant")
+			return
 		}
 
-		// If no tenant slug found, continue without setting context
+		// If no tenant found, continue without setting context
 		// (some endpoints like /register don't need tenant)
-		if tenantSlug == "" {
+		if tenant == nil {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Find tenant by slug
-		tenant, err := m.tenantRepo.FindBySlug(r.Context(), tenantSlug)
-		if err != nil {
-			utils.NotFound(w, "Tenant not found")
-			return
+		// A former slug of a renamed tenant: page loads move to the current
+		// subdomain, other requests are served and told the new slug
+		if slug := r.Header.Get("X-Tenant-Slug"); slug != "" {
+			if !strings.EqualFold(slug, tenant.Slug) {
+				w.Header().Set(TenantSlugMovedHeader, tenant.Slug)
+			}
+		} else if host, moved := m.tenantDomainService.CanonicalHost(r.Host, tenant); moved {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				target := *r.URL
+				target.Scheme, target.Host = requestScheme(r), host
+				http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+				return
+			}
+			w.Header().Set(TenantSlugMovedHeader, tenant.Slug)
 		}
 
 		// Check tenant status
@@ -82,6 +82,20 @@ func (m *TenantMiddleware) ResolveTenant(next http.Handler) http.Handler {
 			return
 		}
 
+		if tenant.IsSandbox() {
+			w.Header().Set(SandboxHeader, "true")
+		}
+
+		// Suspended tenants may read but not change their data
+		if !enforceReadOnly(w, r, tenant) {
+			return
+		}
+
+		// During a maintenance window changes wait until it ends
+		if !enforceMaintenance(w, r, tenant) {
+			return
+		}
+
 		// Add tenant to context (use same keys as auth.go)
 		ctx := context.WithValue(r.Context(), "tenant_id", tenant.ID)
 		ctx = context.WithValue(ctx, "tenant_slug", tenant.Slug)
@@ -90,6 +104,35 @@ func (m *TenantMiddleware) ResolveTenant(next http.Handler) http.Handler {
 	})
 }
 
+// resolve returns the tenant a request names, nil when it names none
+func (m *TenantMiddleware) resolve(r *http.Request) (*models.Tenant, error) {
+	// 1. X-Tenant-Slug header (API clients, development)
+	if slug := r.Header.Get("X-Tenant-Slug"); slug != "" {
+		return m.tenantDomainService.ResolveSlug(r.Context(), slug)
+	}
+
+	// 2. Request host
+	tenant, err := m.tenantDomainService.ResolveHost(r.Context(), r.Host)
+	if tenant != nil || err != nil {
+		return tenant, err
+	}
+
+	// 3. Origin of the calling frontend
+	if origin, err := url.Parse(r.Header.Get("Origin")); err == nil && origin.Host != "" {
+		return m.tenantDomainService.ResolveHost(r.Context(), origin.Host)
+	}
+	return nil, nil
+}
+
+// requestScheme returns the scheme the client used, behind a TLS-terminating
+// proxy too
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
+}
+
 // RequireTenant is a middleware that requires tenant context
 func (m *TenantMiddleware) RequireTenant(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/tracing"
+)
+
+// TraceIDHeader is the response header carrying the request's trace ID, to
+// find the trace of a reported problem
+const TraceIDHeader = "X-Trace-ID"
+
+// Tracing starts a server span for each request, continuing the trace of the
+// caller when it sends a traceparent header. The span is named after the
+// route pattern (GET /users/{id}) once the request is routed; 5xx responses
+// mark it failed.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodOriginal(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsSampled() {
+			w.Header().Set(TraceIDHeader, sc.TraceID().String())
+		}
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(semconv.HTTPRoute(rctx.RoutePattern()))
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("status %d", status))
+		}
+	})
+}
+
+// traceIdentity tags the request's span with the authenticated user and
+// tenant, to find the traces of one tenant
+func traceIdentity(ctx context.Context, user *models.User, tenant *models.Tenant) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("tenant.id", tenant.ID.String()),
+		attribute.String("tenant.slug", tenant.Slug),
+		semconv.EnduserID(user.ID.String()),
+	)
+}
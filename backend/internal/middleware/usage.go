@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/services"
+)
+
+// usageIdentityKey is the context key of the request's usageIdentity
+type usageIdentityKey struct{}
+
+// usageIdentity is who a request is counted for in usage analytics. It is
+// filled in by authentication, further down the chain than UsageMiddleware.
+type usageIdentity struct {
+	tenantID uuid.UUID
+	userID   uuid.UUID
+	counted  bool
+}
+
+// identifyUsage counts the request for the user, unless the tenant opted out
+// of usage analytics or is a sandbox
+func identifyUsage(ctx context.Context, user *models.User, tenant *models.Tenant) {
+	identity, ok := ctx.Value(usageIdentityKey{}).(*usageIdentity)
+	if !ok || tenant.IsSandbox() || tenant.UsageAnalyticsOptOut() {
+		return
+	}
+	identity.tenantID = tenant.ID
+	identity.userID = user.ID
+	identity.counted = true
+}
+
+// UsageMiddleware counts authenticated requests per endpoint for the
+// anonymized usage analytics
+type UsageMiddleware struct {
+	usageService services.UsageManager
+}
+
+// NewUsageMiddleware creates a new usage analytics middleware
+func NewUsageMiddleware(usageService services.UsageManager) *UsageMiddleware {
+	return &UsageMiddleware{
+		usageService: usageService,
+	}
+}
+
+// Track records successful authenticated requests against their route
+// pattern once handled
+func (m *UsageMiddleware) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := &usageIdentity{}
+		ctx := context.WithValue(r.Context(), usageIdentityKey{}, identity)
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if !identity.counted || ww.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		m.usageService.Record(identity.tenantID, identity.userID, r.Method+" "+rctx.RoutePattern())
+	})
+}
@@ -0,0 +1,307 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+)
+
+// Backfill is a long-running data migration executed in small batches outside
+// the schema migration, so it never holds locks for long and can be resumed.
+//
+// Backfills live in <migrations>/backfills/<name>.sql. The file contains a
+// single query that processes one batch: $1 is the cursor returned by the
+// previous batch (empty for the first one) and $2 the batch size. It must return
+// one row (cursor TEXT, processed INT); the backfill completes when a batch
+// processes zero rows. For example:
+//
+//	-- backfill:batch-size 500
+//	WITH batch AS (
+//	    SELECT tenant_id, id FROM users
+//	    WHERE id::text > $1 AND display_name IS NULL
+//	    ORDER BY id LIMIT $2
+//	), updated AS (
+//	    UPDATE users u SET display_name = u.first_name || ' ' || u.last_name
+//	    FROM batch b WHERE u.tenant_id = b.tenant_id AND u.id = b.id
+//	)
+//	SELECT COALESCE(MAX(id::text), $1), COUNT(*)::int FROM batch
+type Backfill struct {
+	Name      string
+	Path      string
+	SQL       string
+	BatchSize int
+}
+
+const defaultBackfillBatchSize = 1000
+
+// LoadBackfills reads every backfill definition in dir, ordered by name.
+// A missing directory means there are no backfills.
+func LoadBackfills(dir string) ([]Backfill, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Backfill{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backfills directory: %w", err)
+	}
+
+	backfills := []Backfill{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		backfill := Backfill{
+			Name:      strings.TrimSuffix(entry.Name(), ".sql"),
+			Path:      path,
+			SQL:       string(content),
+			BatchSize: defaultBackfillBatchSize,
+		}
+
+		for _, line := range strings.Split(backfill.SQL, "\n") {
+			fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "--"))
+			if len(fields) == 2 && fields[0] == "backfill:batch-size" {
+				size, err := strconv.Atoi(fields[1])
+				if err != nil || size <= 0 {
+					return nil, fmt.Errorf("%s: invalid backfill:batch-size", entry.Name())
+				}
+				backfill.BatchSize = size
+			}
+		}
+
+		backfills = append(backfills, backfill)
+	}
+
+	sort.Slice(backfills, func(i, j int) bool {
+		return backfills[i].Name < backfills[j].Name
+	})
+
+	return backfills, nil
+}
+
+// Backfill statuses
+const (
+	BackfillStatusRunning   = "running"
+	BackfillStatusFailed    = "failed"
+	BackfillStatusCompleted = "completed"
+)
+
+// BackfillProgress is the persisted state of a backfill
+type BackfillProgress struct {
+	Name          string         `db:"name"`
+	Cursor        string         `db:"last_cursor"`
+	RowsProcessed int64          `db:"rows_processed"`
+	Status        string         `db:"status"`
+	LastError     sql.NullString `db:"last_error"`
+	StartedAt     time.Time      `db:"started_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+	CompletedAt   sql.NullTime   `db:"completed_at"`
+}
+
+// BackfillOptions tunes a backfill run
+type BackfillOptions struct {
+	BatchSize  int           // Overrides the file's batch size when > 0
+	Pause      time.Duration // Sleep between batches to limit load
+	MaxBatches int           // Stop after this many batches (0 = run to completion)
+	Logf       func(format string, args ...interface{})
+}
+
+// BackfillRunner executes backfills and records their progress in the
+// schema_backfills table, next to golang-migrate's schema_migrations
+type BackfillRunner struct {
+	db *sqlx.DB
+}
+
+// NewBackfillRunner creates a backfill runner
+func NewBackfillRunner(db *sqlx.DB) *BackfillRunner {
+	return &BackfillRunner{db: db}
+}
+
+// EnsureTable creates the progress table if it does not exist
+func (r *BackfillRunner) EnsureTable(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_backfills (
+			name VARCHAR(255) PRIMARY KEY,
+			last_cursor TEXT NOT NULL DEFAULT '',
+			rows_processed BIGINT NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'running',
+			last_error TEXT,
+			started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			completed_at TIMESTAMPTZ
+		)
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_backfills table: %w", err)
+	}
+	return nil
+}
+
+// Progress returns the state of a backfill, or nil if it never ran
+func (r *BackfillRunner) Progress(ctx context.Context, name string) (*BackfillProgress, error) {
+	var progress BackfillProgress
+	err := r.db.GetContext(ctx, &progress, `SELECT * FROM schema_backfills WHERE name = $1`, name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+	return &progress, nil
+}
+
+// IsCompleted reports whether a backfill has finished
+func (r *BackfillRunner) IsCompleted(ctx context.Context, name string) (bool, error) {
+	progress, err := r.Progress(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return progress != nil && progress.Status == BackfillStatusCompleted, nil
+}
+
+// Reset forgets a backfill's progress so the next run starts from scratch
+func (r *BackfillRunner) Reset(ctx context.Context, name string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM schema_backfills WHERE name = $1`, name); err != nil {
+		return fmt.Errorf("failed to reset backfill: %w", err)
+	}
+	return nil
+}
+
+// Run executes a backfill from its last cursor until it completes, the
+// context is cancelled or MaxBatches is reached. Each batch and its cursor are
+// committed together, so an interrupted run resumes exactly where it stopped.
+// An advisory lock keeps two operators from running the same backfill.
+func (r *BackfillRunner) Run(ctx context.Context, backfill Backfill, opts BackfillOptions) error {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	batchSize := backfill.BatchSize
+	if opts.BatchSize > 0 {
+		batchSize = opts.BatchSize
+	}
+
+	lock, err := database.TryAdvisoryLock(ctx, r.db, "backfill:"+backfill.Name)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("backfill %s is already running", backfill.Name)
+	}
+	defer lock.Release()
+
+	progress, err := r.start(ctx, backfill.Name)
+	if err != nil {
+		return err
+	}
+	if progress.Status == BackfillStatusCompleted {
+		logf("Backfill %s already completed (%d rows)", backfill.Name, progress.RowsProcessed)
+		return nil
+	}
+
+	cursor := progress.Cursor
+	total := progress.RowsProcessed
+	for batch := 1; opts.MaxBatches == 0 || batch <= opts.MaxBatches; batch++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next, processed, err := r.runBatch(ctx, backfill, cursor, batchSize)
+		if err != nil {
+			r.fail(backfill.Name, err)
+			return err
+		}
+		if processed == 0 {
+			logf("✅ Backfill %s completed (%d rows)", backfill.Name, total)
+			return nil
+		}
+
+		cursor = next
+		total += int64(processed)
+		logf("Backfill %s: batch %d processed %d rows (total %d, cursor %q)", backfill.Name, batch, processed, total, cursor)
+
+		if opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+
+	logf("Backfill %s paused after %d batches; run it again to resume", backfill.Name, opts.MaxBatches)
+	return nil
+}
+
+// start records a run, keeping the cursor of any previous run
+func (r *BackfillRunner) start(ctx context.Context, name string) (*BackfillProgress, error) {
+	var progress BackfillProgress
+	query := `
+		INSERT INTO schema_backfills (name) VALUES ($1)
+		ON CONFLICT (name) DO UPDATE
+		SET status = CASE WHEN schema_backfills.status = 'completed' THEN 'completed' ELSE 'running' END,
+			last_error = NULL,
+			updated_at = NOW()
+		RETURNING *
+	`
+	if err := r.db.GetContext(ctx, &progress, query, name); err != nil {
+		return nil, fmt.Errorf("failed to start backfill: %w", err)
+	}
+	return &progress, nil
+}
+
+// runBatch processes one batch and advances the cursor in the same transaction
+func (r *BackfillRunner) runBatch(ctx context.Context, backfill Backfill, cursor string, batchSize int) (string, int, error) {
+	tx, err := database.WithBypassRLS(ctx, r.db)
+	if err != nil {
+		return "", 0, err
+	}
+	defer tx.Rollback()
+
+	// Fail fast instead of queueing behind application traffic
+	if _, err := tx.ExecContext(ctx, "SET LOCAL lock_timeout = '5s'"); err != nil {
+		return "", 0, fmt.Errorf("failed to set lock timeout: %w", err)
+	}
+
+	var next sql.NullString
+	var processed int
+	if err := tx.QueryRowContext(ctx, backfill.SQL, cursor, batchSize).Scan(&next, &processed); err != nil {
+		return "", 0, fmt.Errorf("backfill %s batch failed: %w", backfill.Name, err)
+	}
+
+	query := `
+		UPDATE schema_backfills
+		SET last_cursor = CASE WHEN $2 = 0 THEN last_cursor ELSE $1 END,
+			rows_processed = rows_processed + $2,
+			status = CASE WHEN $2 = 0 THEN 'completed' ELSE 'running' END,
+			completed_at = CASE WHEN $2 = 0 THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE name = $3
+	`
+	if _, err := tx.ExecContext(ctx, query, next.String, processed, backfill.Name); err != nil {
+		return "", 0, fmt.Errorf("failed to record backfill progress: %w", err)
+	}
+
+	return next.String, processed, tx.Commit()
+}
+
+// fail records the error of a failed batch; the cursor is left untouched
+func (r *BackfillRunner) fail(name string, cause error) {
+	query := `UPDATE schema_backfills SET status = 'failed', last_error = $1, updated_at = NOW() WHERE name = $2`
+	_, _ = r.db.ExecContext(context.Background(), query, cause.Error(), name)
+}
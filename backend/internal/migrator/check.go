@@ -0,0 +1,225 @@
+package migrator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity of a compatibility finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a statement that may break the application version currently
+// running while a migration is applied
+type Finding struct {
+	Version   uint
+	Name      string
+	Severity  Severity
+	Rule      string
+	Message   string
+	Statement string
+}
+
+// String formats the finding for CLI output
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %03d_%s [%s] %s\n    %s", strings.ToUpper(string(f.Severity)), f.Version, f.Name, f.Rule, f.Message, f.Statement)
+}
+
+// rule matches a normalized (upper-case, single-spaced) statement
+type rule struct {
+	name     string
+	breaking bool // Breaks old code: only allowed in post-deploy migrations
+	message  string
+	match    func(stmt string) bool
+}
+
+func pattern(expr string) func(string) bool {
+	re := regexp.MustCompile(expr)
+	return re.MatchString
+}
+
+var rules = []rule{
+	{
+		name:     "drop_table",
+		breaking: true,
+		message:  "drops a relation the running code may still query",
+		match:    pattern(`^DROP (TABLE|VIEW|MATERIALIZED VIEW|SCHEMA)\b`),
+	},
+	{
+		name:     "drop_column",
+		breaking: true,
+		message:  "drops a column the running code may still read or write",
+		match:    pattern(`\bDROP COLUMN\b`),
+	},
+	{
+		name:     "rename",
+		breaking: true,
+		message:  "renames an object; add the new name, dual-write, then drop the old one in a post-deploy migration",
+		match:    pattern(`^ALTER .*\bRENAME\b`),
+	},
+	{
+		name:     "alter_type",
+		breaking: true,
+		message:  "changes a column type, which rewrites the table and may break the running code",
+		match:    pattern(`\bALTER COLUMN \S+ (SET DATA )?TYPE\b`),
+	},
+	{
+		name:     "set_not_null",
+		breaking: true,
+		message:  "adds NOT NULL; the running code may still insert NULLs (backfill first, then tighten post-deploy)",
+		match:    pattern(`\bSET NOT NULL\b`),
+	},
+	{
+		name:     "add_required_column",
+		breaking: true,
+		message:  "adds a NOT NULL column without a default; inserts from the running code will fail",
+		match: func(stmt string) bool {
+			return strings.Contains(stmt, "ADD COLUMN") && strings.Contains(stmt, "NOT NULL") && !strings.Contains(stmt, "DEFAULT")
+		},
+	},
+	{
+		name:     "truncate",
+		breaking: true,
+		message:  "deletes all rows of a table",
+		match:    pattern(`^TRUNCATE\b`),
+	},
+	{
+		name:    "data_migration",
+		message: "rewrites rows inside the schema migration and holds locks until it finishes; move large data changes to a backfill",
+		match:   pattern(`^(UPDATE|DELETE FROM)\b`),
+	},
+}
+
+// Check flags statements that are unsafe while old and new application
+// versions run side by side. Breaking statements are errors in pre-deploy
+// migrations (warnings when the migration is annotated allow-destructive) and
+// are accepted in post-deploy migrations.
+func Check(migrations []Migration) []Finding {
+	findings := []Finding{}
+	for _, m := range migrations {
+		for _, stmt := range splitStatements(m.SQL) {
+			for _, r := range rules {
+				if !r.match(stmt) {
+					continue
+				}
+
+				severity := SeverityWarning
+				if r.breaking {
+					if m.Phase == PhasePost {
+						continue
+					}
+					if !m.AllowDestructive {
+						severity = SeverityError
+					}
+				}
+
+				findings = append(findings, Finding{
+					Version:   m.Version,
+					Name:      m.Name,
+					Severity:  severity,
+					Rule:      r.name,
+					Message:   r.message,
+					Statement: excerpt(stmt),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// HasErrors reports whether any finding is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements strips comments, string literals and dollar-quoted bodies,
+// then splits the SQL into upper-cased, whitespace-collapsed statements.
+// Function bodies are deliberately not inspected: CREATE OR REPLACE FUNCTION
+// is additive from the running code's point of view.
+func splitStatements(sql string) []string {
+	var b strings.Builder
+	for i := 0; i < len(sql); {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case sql[i] == '\'':
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			b.WriteString("''")
+			i = j + 1
+		case sql[i] == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				b.WriteByte(sql[i])
+				i++
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			b.WriteString("$$")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += len(tag) + end + len(tag)
+			}
+		default:
+			b.WriteByte(sql[i])
+			i++
+		}
+	}
+
+	statements := []string{}
+	for _, raw := range strings.Split(b.String(), ";") {
+		stmt := strings.ToUpper(strings.Join(strings.Fields(raw), " "))
+		if stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+var dollarTagPattern = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// dollarTag returns the opening dollar-quote tag ("$$" or "$name$") at the start of s
+func dollarTag(s string) string {
+	return dollarTagPattern.FindString(s)
+}
+
+func excerpt(stmt string) string {
+	const maxLen = 100
+	if len(stmt) <= maxLen {
+		return stmt
+	}
+	return stmt[:maxLen] + "..."
+}
@@ -0,0 +1,109 @@
+package migrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// VersionFormat is the layout of the version of a created migration: its
+// creation time in UTC, so migrations written on different branches don't
+// claim the same number
+const VersionFormat = "20060102150405"
+
+var nonWordPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up and down migration named after name to dir,
+// versioned with the time now, and returns their paths. A post-deploy
+// migration gets its phase annotation.
+func Create(dir, name string, phase Phase, now time.Time) ([]string, error) {
+	name = strings.Trim(nonWordPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+	if phase != PhasePre && phase != PhasePost {
+		return nil, fmt.Errorf("unknown phase %q (expected pre or post)", phase)
+	}
+
+	version := now.UTC().Format(VersionFormat)
+	existing, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range existing {
+		if fmt.Sprint(m.Version) == version {
+			return nil, fmt.Errorf("migration version %s already exists: %s", version, m.Path)
+		}
+	}
+
+	title := strings.ReplaceAll(name, "_", " ")
+	up := fmt.Sprintf("-- %s%s\n", strings.ToUpper(title[:1]), title[1:])
+	if phase == PhasePost {
+		up = "-- migrate:phase post\n" + up
+	}
+	files := []struct {
+		suffix  string
+		content string
+	}{
+		{"up", up + "\n"},
+		{"down", fmt.Sprintf("-- Revert %s\n\n", title)},
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, file.suffix))
+		// O_EXCL: never overwrite a migration
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		_, err = f.WriteString(file.content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// Applied returns the migrations at or below the current version, newest
+// first: the order they are rolled back in
+func Applied(migrations []Migration, current uint) []Migration {
+	applied := []Migration{}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version <= current {
+			applied = append(applied, migrations[i])
+		}
+	}
+	return applied
+}
+
+// Between returns the migrations a move from version from to version to
+// applies (to above from) or rolls back (to below from), in the order it
+// runs them
+func Between(migrations []Migration, from, to uint) []Migration {
+	if to >= from {
+		steps := []Migration{}
+		for _, m := range Pending(migrations, from) {
+			if m.Version <= to {
+				steps = append(steps, m)
+			}
+		}
+		return steps
+	}
+
+	steps := []Migration{}
+	for _, m := range Applied(migrations, from) {
+		if m.Version > to {
+			steps = append(steps, m)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,175 @@
+package migrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Phase says when a migration runs relative to an application deploy.
+//
+// Zero-downtime changes follow expand/contract: "pre" migrations only add
+// things the running (old) code can ignore and are applied before the new code
+// rolls out; "post" migrations remove or tighten things the old code still
+// relies on and are applied once every old replica is gone.
+type Phase string
+
+const (
+	PhasePre  Phase = "pre"  // Expand: applied before deploying new code (default)
+	PhasePost Phase = "post" // Contract: applied after the deploy has finished
+)
+
+// Migration is an up migration file together with its annotations.
+//
+// Annotations are SQL comments at the top of the .up.sql file:
+//
+//	-- migrate:phase post
+//	-- migrate:allow-destructive
+//	-- migrate:requires-backfill users_fill_display_name
+type Migration struct {
+	Version           uint
+	Name              string
+	Path              string
+	SQL               string
+	Phase             Phase
+	AllowDestructive  bool     // Acknowledges destructive statements in a pre migration
+	RequiresBackfills []string // Backfills that must be completed before this migration runs
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+// Load reads every up migration in dir, ordered by version
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	migrations := []Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		migration := Migration{
+			Version: uint(version),
+			Name:    match[2],
+			Path:    path,
+			SQL:     string(content),
+			Phase:   PhasePre,
+		}
+		if err := parseAnnotations(&migration); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parseAnnotations reads "-- migrate:" comments from the file header
+func parseAnnotations(m *Migration) error {
+	scanner := bufio.NewScanner(strings.NewReader(m.SQL))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			// Annotations must precede the first statement
+			break
+		}
+
+		directive := strings.TrimSpace(strings.TrimPrefix(line, "--"))
+		if !strings.HasPrefix(directive, "migrate:") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(directive, "migrate:"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "phase":
+			if len(fields) != 2 || (Phase(fields[1]) != PhasePre && Phase(fields[1]) != PhasePost) {
+				return fmt.Errorf("migrate:phase must be %q or %q", PhasePre, PhasePost)
+			}
+			m.Phase = Phase(fields[1])
+		case "allow-destructive":
+			m.AllowDestructive = true
+		case "requires-backfill":
+			if len(fields) != 2 {
+				return fmt.Errorf("migrate:requires-backfill takes exactly one backfill name")
+			}
+			m.RequiresBackfills = append(m.RequiresBackfills, fields[1])
+		default:
+			return fmt.Errorf("unknown annotation migrate:%s", fields[0])
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Pending returns the migrations newer than the current version
+func Pending(migrations []Migration, current uint) []Migration {
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Target works out how far "up" may go. With phase "pre" it stops before the
+// first post-deploy migration; in every case it stops before a migration whose
+// required backfills have not completed. It returns the version to migrate to
+// (current if nothing can run) and, when it stopped early, why.
+func Target(pending []Migration, current uint, phase Phase, backfillDone func(name string) (bool, error)) (uint, string, error) {
+	target := current
+	for _, m := range pending {
+		if phase == PhasePre && m.Phase == PhasePost {
+			return target, fmt.Sprintf("%03d_%s is a post-deploy migration; run 'migrate up' after the deploy", m.Version, m.Name), nil
+		}
+
+		for _, name := range m.RequiresBackfills {
+			done, err := backfillDone(name)
+			if err != nil {
+				return target, "", err
+			}
+			if !done {
+				return target, fmt.Sprintf("%03d_%s requires backfill %q to complete; run 'migrate backfill run %s'", m.Version, m.Name, name, name), nil
+			}
+		}
+
+		target = m.Version
+	}
+
+	return target, "", nil
+}
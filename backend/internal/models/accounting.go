@@ -0,0 +1,240 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account represents an account in a tenant's chart of accounts
+type Account struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Code        string  `json:"code" db:"code"`
+	Name        string  `json:"name" db:"name"`
+	Description *string `json:"description,omitempty" db:"description"`
+
+	AccountType string     `json:"account_type" db:"account_type"` // asset | liability | equity | revenue | expense
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+
+	IsActive bool `json:"is_active" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// AccountingPeriod represents a posting period
+type AccountingPeriod struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Name      string    `json:"name" db:"name"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+
+	Status   string     `json:"status" db:"status"` // open | closed
+	ClosedAt *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	ClosedBy *uuid.UUID `json:"closed_by,omitempty" db:"closed_by"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// JournalEntry represents a double-entry journal entry
+type JournalEntry struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TenantID       uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	EntryNumber    string     `json:"entry_number" db:"entry_number"`
+	SequenceNumber int        `json:"-" db:"sequence_number"`
+	EntryDate      time.Time  `json:"entry_date" db:"entry_date"`
+	PeriodID       *uuid.UUID `json:"period_id,omitempty" db:"period_id"`
+
+	Description string  `json:"description" db:"description"`
+	Reference   *string `json:"reference,omitempty" db:"reference"`
+
+	// Status
+	Status   string     `json:"status" db:"status"` // draft | posted
+	PostedAt *time.Time `json:"posted_at,omitempty" db:"posted_at"`
+	PostedBy *uuid.UUID `json:"posted_by,omitempty" db:"posted_by"`
+
+	// Reversals
+	ReversalOfID *uuid.UUID `json:"reversal_of_id,omitempty" db:"reversal_of_id"`
+	ReversedByID *uuid.UUID `json:"reversed_by_id,omitempty" db:"reversed_by_id"`
+
+	TotalDebit  float64 `json:"total_debit" db:"total_debit"`
+	TotalCredit float64 `json:"total_credit" db:"total_credit"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	Lines []JournalEntryLine `json:"lines,omitempty" db:"-"`
+}
+
+// JournalEntryLine represents a debit or credit to one account
+type JournalEntryLine struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	TenantID       uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	JournalEntryID uuid.UUID `json:"journal_entry_id" db:"journal_entry_id"`
+
+	LineNumber  int       `json:"line_number" db:"line_number"`
+	AccountID   uuid.UUID `json:"account_id" db:"account_id"`
+	Description *string   `json:"description,omitempty" db:"description"`
+
+	Debit  float64 `json:"debit" db:"debit"`
+	Credit float64 `json:"credit" db:"credit"`
+
+	// Computed fields (not in database)
+	AccountCode *string `json:"account_code,omitempty" db:"account_code"`
+	AccountName *string `json:"account_name,omitempty" db:"account_name"`
+}
+
+// TrialBalanceRow represents one account's totals in a trial balance
+type TrialBalanceRow struct {
+	AccountID   uuid.UUID `json:"account_id" db:"account_id"`
+	Code        string    `json:"code" db:"code"`
+	Name        string    `json:"name" db:"name"`
+	AccountType string    `json:"account_type" db:"account_type"`
+	TotalDebit  float64   `json:"total_debit" db:"total_debit"`
+	TotalCredit float64   `json:"total_credit" db:"total_credit"`
+	Balance     float64   `json:"balance" db:"balance"` // Debit minus credit
+}
+
+// TrialBalance represents the posted totals of all accounts over a date range
+type TrialBalance struct {
+	FromDate    *time.Time        `json:"from_date,omitempty"`
+	ToDate      time.Time         `json:"to_date"`
+	Rows        []TrialBalanceRow `json:"rows"`
+	TotalDebit  float64           `json:"total_debit"`
+	TotalCredit float64           `json:"total_credit"`
+	IsBalanced  bool              `json:"is_balanced"`
+}
+
+// Account type constants
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeRevenue   = "revenue"
+	AccountTypeExpense   = "expense"
+)
+
+// AccountTypes lists all valid account types
+var AccountTypes = []string{
+	AccountTypeAsset,
+	AccountTypeLiability,
+	AccountTypeEquity,
+	AccountTypeRevenue,
+	AccountTypeExpense,
+}
+
+// Accounting period status constants
+const (
+	PeriodStatusOpen   = "open"
+	PeriodStatusClosed = "closed"
+)
+
+// Journal entry status constants
+const (
+	JournalStatusDraft  = "draft"
+	JournalStatusPosted = "posted"
+)
+
+// Permission resource constant
+const (
+	ResourceAccounting = "accounting"
+)
+
+// Permission actions for accounting
+const (
+	ActionPost          = "post"
+	ActionManagePeriods = "manage_periods"
+)
+
+// Contains returns true if the date falls within the period (inclusive)
+func (p *AccountingPeriod) Contains(date time.Time) bool {
+	day := date.UTC().Truncate(24 * time.Hour)
+	return !day.Before(p.StartDate.UTC()) && !day.After(p.EndDate.UTC())
+}
+
+// IsOpen returns true if entries can be posted into the period
+func (p *AccountingPeriod) IsOpen() bool {
+	return p.Status == PeriodStatusOpen
+}
+
+// IsPosted returns true if the entry has been posted to the ledger
+func (e *JournalEntry) IsPosted() bool {
+	return e.Status == JournalStatusPosted
+}
+
+// AccountCreateRequest represents a request to create an account
+type AccountCreateRequest struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	AccountType string     `json:"account_type"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// AccountUpdateRequest represents a request to update an account.
+// The account type cannot change once the account exists.
+type AccountUpdateRequest struct {
+	Code        *string    `json:"code,omitempty"`
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	IsActive    *bool      `json:"is_active,omitempty"`
+}
+
+// AccountingPeriodCreateRequest represents a request to create a posting period
+type AccountingPeriodCreateRequest struct {
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// JournalLineRequest represents a line in a journal entry request
+type JournalLineRequest struct {
+	AccountID   uuid.UUID `json:"account_id"`
+	Description *string   `json:"description,omitempty"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+}
+
+// JournalEntryCreateRequest represents a request to create a draft journal entry
+type JournalEntryCreateRequest struct {
+	EntryDate   time.Time            `json:"entry_date"`
+	Description string               `json:"description"`
+	Reference   *string              `json:"reference,omitempty"`
+	Lines       []JournalLineRequest `json:"lines"`
+}
+
+// JournalEntryUpdateRequest represents a request to update a draft journal entry
+type JournalEntryUpdateRequest struct {
+	EntryDate   *time.Time            `json:"entry_date,omitempty"`
+	Description *string               `json:"description,omitempty"`
+	Reference   *string               `json:"reference,omitempty"`
+	Lines       *[]JournalLineRequest `json:"lines,omitempty"` // Replaces all lines when set
+}
+
+// JournalEntryReverseRequest represents a request to reverse a posted entry
+type JournalEntryReverseRequest struct {
+	EntryDate   *time.Time `json:"entry_date,omitempty"` // Defaults to today
+	Description *string    `json:"description,omitempty"`
+}
+
+// JournalEntryFilter represents filters for listing journal entries
+type JournalEntryFilter struct {
+	Status    string
+	AccountID *uuid.UUID
+	FromDate  *time.Time
+	ToDate    *time.Time
+	Search    string
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Announcement is a message from admins to all of a tenant's users or to
+// those with given roles or in given departments. It is shown from PublishAt
+// until ExpiresAt.
+type Announcement struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Title string `json:"title" db:"title"`
+	Body  string `json:"body" db:"body"`
+
+	// Audience: all | roles | departments
+	Audience      string         `json:"audience" db:"audience"`
+	RoleIDs       pq.StringArray `json:"role_ids" db:"role_ids"`
+	DepartmentIDs pq.StringArray `json:"department_ids" db:"department_ids"`
+
+	PublishAt  time.Time  `json:"publish_at" db:"publish_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty" db:"notified_at"` // When the audience was notified
+
+	// Status: scheduled | active | expired, from the publish and expiry times
+	Status string `json:"status" db:"status"`
+
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Users of the audience who read it
+	ReadCount int `json:"read_count" db:"read_count"`
+}
+
+// UserAnnouncement is a published announcement as shown to a user of its
+// audience
+type UserAnnouncement struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Title     string     `json:"title" db:"title"`
+	Body      string     `json:"body" db:"body"`
+	PublishAt time.Time  `json:"publish_at" db:"publish_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+}
+
+// AnnouncementRead is a user who read an announcement
+type AnnouncementRead struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	LastName  string    `json:"last_name" db:"last_name"`
+	ReadAt    time.Time `json:"read_at" db:"read_at"`
+}
+
+// AnnouncementReceipt records when a user read an announcement
+type AnnouncementReceipt struct {
+	AnnouncementID uuid.UUID `json:"announcement_id" db:"announcement_id"`
+	ReadAt         time.Time `json:"read_at" db:"read_at"`
+}
+
+// Announcement audiences
+const (
+	AnnouncementAudienceAll         = "all"
+	AnnouncementAudienceRoles       = "roles"
+	AnnouncementAudienceDepartments = "departments"
+)
+
+// AnnouncementAudiences lists the audiences an announcement can target
+var AnnouncementAudiences = []string{
+	AnnouncementAudienceAll,
+	AnnouncementAudienceRoles,
+	AnnouncementAudienceDepartments,
+}
+
+// Announcement statuses, from the publish and expiry times
+const (
+	AnnouncementStatusScheduled = "scheduled"
+	AnnouncementStatusActive    = "active"
+	AnnouncementStatusExpired   = "expired"
+)
+
+// Announcement permission resource and audit actions
+const (
+	ResourceAnnouncements = "announcements"
+
+	ActionAnnouncementCreated = "announcement.created"
+	ActionAnnouncementUpdated = "announcement.updated"
+	ActionAnnouncementDeleted = "announcement.deleted"
+)
+
+// AnnouncementRequest creates or replaces an announcement. PublishAt defaults
+// to now; RoleIDs and DepartmentIDs are required by their audience.
+type AnnouncementRequest struct {
+	Title         string      `json:"title"`
+	Body          string      `json:"body"`
+	Audience      string      `json:"audience"`
+	RoleIDs       []uuid.UUID `json:"role_ids,omitempty"`
+	DepartmentIDs []uuid.UUID `json:"department_ids,omitempty"`
+	PublishAt     *time.Time  `json:"publish_at,omitempty"`
+	ExpiresAt     *time.Time  `json:"expires_at,omitempty"`
+}
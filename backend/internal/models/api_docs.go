@@ -0,0 +1,89 @@
+package models
+
+// OpenAPIVersion is the OpenAPI specification version of the API docs
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIDocument is an OpenAPI document describing the API. It is built
+// from the registered routes, so operations carry their path parameters,
+// authentication and required permissions but no request or response
+// schemas.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers"`
+	Tags       []OpenAPITag                            `json:"tags"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"` // Path -> lowercase method -> operation
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo describes the API
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is a base URL the API is served at
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPITag groups operations, one per top-level path segment
+type OpenAPITag struct {
+	Name string `json:"name"`
+}
+
+// OpenAPIOperation describes an operation on a path
+type OpenAPIOperation struct {
+	OperationID         string                     `json:"operationId"`
+	Summary             string                     `json:"summary"`
+	Description         string                     `json:"description,omitempty"`
+	Tags                []string                   `json:"tags"`
+	Parameters          []OpenAPIParameter         `json:"parameters,omitempty"`
+	Security            []map[string][]string      `json:"security,omitempty"`
+	Responses           map[string]OpenAPIResponse `json:"responses"`
+	RequiredPermissions [][]string                 `json:"x-required-permissions,omitempty"` // Every entry must be held; an entry is held with any one of its "resource.action" permissions
+	RequiredRole        string                     `json:"x-required-role,omitempty"`
+}
+
+// OpenAPIParameter describes a path parameter
+type OpenAPIParameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+// OpenAPIResponse describes a response of an operation
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// OpenAPIComponents holds the security schemes operations refer to and the
+// schemas of entity types
+type OpenAPIComponents struct {
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+	Schemas         map[string]*OpenAPISchema        `json:"schemas,omitempty"`
+}
+
+// OpenAPISchema describes a JSON value
+type OpenAPISchema struct {
+	Type       string                    `json:"type,omitempty"` // Any value when empty
+	Format     string                    `json:"format,omitempty"`
+	Title      string                    `json:"title,omitempty"`
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Items      *OpenAPISchema            `json:"items,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	Enum       []string                  `json:"enum,omitempty"`
+	MaxLength  int                       `json:"maxLength,omitempty"`
+	ReadOnly   bool                      `json:"readOnly,omitempty"`
+	WriteOnly  bool                      `json:"writeOnly,omitempty"`
+	Custom     bool                      `json:"x-custom-field,omitempty"` // Defined by the tenant
+}
+
+// OpenAPISecurityScheme describes how operations are authenticated
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalLink is a one-time link an approver was emailed to approve or
+// reject something without logging in
+type ApprovalLink struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id" db:"resource_id"`
+	ApproverID   uuid.UUID `json:"approver_id" db:"approver_id"`
+
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	UsedAt         *time.Time `json:"used_at,omitempty" db:"used_at"`
+	Decision       *string    `json:"decision,omitempty" db:"decision"`
+	StepUp         *string    `json:"step_up,omitempty" db:"step_up"`
+	UsedIP         *string    `json:"used_ip,omitempty" db:"used_ip"`
+	UsedUserAgent  *string    `json:"used_user_agent,omitempty" db:"used_user_agent"`
+	FailedAttempts int        `json:"failed_attempts" db:"failed_attempts"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsUsable returns true if the link can still be used to decide
+func (l *ApprovalLink) IsUsable() bool {
+	return l.UsedAt == nil && l.RevokedAt == nil && time.Now().Before(l.ExpiresAt)
+}
+
+// Approval decisions
+const (
+	ApprovalDecisionApprove = "approve"
+	ApprovalDecisionReject  = "reject"
+)
+
+// Verification an approval link requires before its decision is applied
+const (
+	ApprovalStepUpNone  = "none"  // The link alone is enough
+	ApprovalStepUpTOTP  = "totp"  // High-value: the approver's authenticator code is required
+	ApprovalStepUpLogin = "login" // High-value and no 2FA: the approver must sign in to decide
+)
+
+// Resource types that can be approved through links
+const (
+	ApprovalResourceSupplierInvoice = "supplier_invoice"
+)
+
+// AuditResourceApprovalLinks is the audit resource type of link uses that
+// could not be tied to an approval (e.g. forged tokens)
+const AuditResourceApprovalLinks = "approval_links"
+
+// ApprovalSubject describes what an approval link decides on
+type ApprovalSubject struct {
+	Title     string  `json:"title"`
+	Details   string  `json:"details,omitempty"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency,omitempty"`
+	Pending   bool    `json:"pending"`    // Still waiting for a decision
+	ReviewURL string  `json:"review_url"` // Where to decide in the app
+}
+
+// ApprovalLinkPreview is what an approver sees before using a link
+type ApprovalLinkPreview struct {
+	Subject   *ApprovalSubject `json:"subject"`
+	Decision  string           `json:"decision"`
+	StepUp    string           `json:"step_up"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// ApprovalLinkRequest represents a request to preview or use an approval
+// link. Code is the approver's authenticator code when the link requires
+// step-up verification.
+type ApprovalLinkRequest struct {
+	Token    string `json:"token"`
+	Decision string `json:"decision"`
+	Code     string `json:"code,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AsyncJob is a long-running background job (import, export, report,
+// archival) and its progress. It is executed by the async job worker.
+type AsyncJob struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	JobType string          `json:"job_type" db:"job_type"`
+	Params  json.RawMessage `json:"params" db:"params"`
+
+	// Status: queued | running | succeeded | failed | cancelled
+	Status          string           `json:"status" db:"status"`
+	Progress        int              `json:"progress" db:"progress"` // Percent complete (0-100)
+	ProgressMessage *string          `json:"progress_message,omitempty" db:"progress_message"`
+	Result          *json.RawMessage `json:"result,omitempty" db:"result"`
+	Error           *string          `json:"error,omitempty" db:"error"`
+	CancelRequested bool             `json:"cancel_requested" db:"cancel_requested"`
+
+	RequestedBy uuid.UUID  `json:"requested_by" db:"requested_by"`
+	CancelledBy *uuid.UUID `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Async job status constants
+const (
+	AsyncJobStatusQueued    = "queued"
+	AsyncJobStatusRunning   = "running"
+	AsyncJobStatusSucceeded = "succeeded"
+	AsyncJobStatusFailed    = "failed"
+	AsyncJobStatusCancelled = "cancelled"
+)
+
+// IsFinished returns true once the job can no longer change
+func (j *AsyncJob) IsFinished() bool {
+	switch j.Status {
+	case AsyncJobStatusSucceeded, AsyncJobStatusFailed, AsyncJobStatusCancelled:
+		return true
+	}
+	return false
+}
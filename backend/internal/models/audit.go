@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +15,7 @@ type AuditLog struct {
 	UserID   *uuid.UUID `json:"user_id,omitempty" db:"user_id"` // Can be NULL for system events
 
 	// Event details
-	Action       string     `json:"action" db:"action"`               // e.g., user.login, user.created, role.assigned
+	Action       string     `json:"action" db:"action"`                         // e.g., user.login, user.created, role.assigned
 	ResourceType *string    `json:"resource_type,omitempty" db:"resource_type"` // e.g., user, role, session
 	ResourceID   *uuid.UUID `json:"resource_id,omitempty" db:"resource_id"`
 
@@ -25,7 +27,7 @@ type AuditLog struct {
 	Status string `json:"status" db:"status"`
 
 	// Additional context (flexible JSONB field)
-	Metadata map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	Metadata AuditMetadata `json:"metadata,omitempty" db:"metadata"`
 
 	// Timestamp
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -34,53 +36,184 @@ type AuditLog struct {
 	User *User `json:"user,omitempty" db:"-"`
 }
 
+// AuditLogExportColumns are the columns of CSV and XLSX audit log exports
+var AuditLogExportColumns = []string{
+	"id",
+	"created_at",
+	"user_id",
+	"action",
+	"resource_type",
+	"resource_id",
+	"status",
+	"ip_address",
+	"user_agent",
+	"metadata",
+}
+
 // AuditLog action constants
 const (
 	// Authentication events
-	ActionUserLogin        = "user.login"
-	ActionUserLoginFailed  = "user.login.failed"
-	ActionUserLogout       = "user.logout"
-	ActionUserRegistered   = "user.registered"
-	ActionUserVerified     = "user.verified"
+	ActionUserLogin       = "user.login"
+	ActionUserLoginFailed = "user.login.failed"
+	ActionUserLogout      = "user.logout"
+	ActionUserRegistered  = "user.registered"
+	ActionUserVerified    = "user.verified"
 
 	// User management events
-	ActionUserCreated      = "user.created"
-	ActionUserUpdated      = "user.updated"
-	ActionUserDeleted      = "user.deleted"
-	ActionUserSuspended    = "user.suspended"
-	ActionUserActivated    = "user.activated"
-	ActionUserPasswordReset = "user.password_reset"
+	ActionUserCreated         = "user.created"
+	ActionUserUpdated         = "user.updated"
+	ActionUserDeleted         = "user.deleted"
+	ActionUserRestored        = "user.restored"
+	ActionUserSuspended       = "user.suspended"
+	ActionUserActivated       = "user.activated"
+	ActionUserPasswordReset   = "user.password_reset"
 	ActionUserPasswordChanged = "user.password_changed"
+	ActionUserStatusChanged   = "user.status_changed"
+	ActionUserRolesAssigned   = "user.roles_assigned"
+	ActionUserImported        = "user.imported"
+	ActionUserExported        = "user.exported"
+	ActionUserAvatarUpdated   = "user.avatar_updated"
+	ActionUserAvatarRemoved   = "user.avatar_removed"
+
+	ActionUserEmailChangeRequested = "user.email_change_requested"
+	ActionUserEmailChanged         = "user.email_changed"
+	ActionUserEmailUndeliverable   = "user.email_undeliverable"
 
 	// Role events
-	ActionRoleCreated      = "role.created"
-	ActionRoleUpdated      = "role.updated"
-	ActionRoleDeleted      = "role.deleted"
-	ActionRoleAssigned     = "role.assigned"
-	ActionRoleUnassigned   = "role.unassigned"
+	ActionRoleCreated    = "role.created"
+	ActionRoleUpdated    = "role.updated"
+	ActionRoleDeleted    = "role.deleted"
+	ActionRoleAssigned   = "role.assigned"
+	ActionRoleUnassigned = "role.unassigned"
+
+	// Department events
+	ActionDepartmentCreated = "department.created"
+	ActionDepartmentUpdated = "department.updated"
+	ActionDepartmentDeleted = "department.deleted"
+
+	// Employee events
+	ActionEmployeeCreated = "employee.created"
+	ActionEmployeeUpdated = "employee.updated"
+	ActionEmployeeDeleted = "employee.deleted"
+
+	// Leave events
+	ActionLeaveTypeCreated     = "leave_type.created"
+	ActionLeaveTypeUpdated     = "leave_type.updated"
+	ActionLeaveTypeDeleted     = "leave_type.deleted"
+	ActionLeaveRequested       = "leave.requested"
+	ActionLeaveApproved        = "leave.approved"
+	ActionLeaveRejected        = "leave.rejected"
+	ActionLeaveCancelled       = "leave.cancelled"
+	ActionLeaveBalanceAdjusted = "leave.balance_adjusted"
+
+	// Timesheet events
+	ActionTimesheetProjectCreated = "timesheet_project.created"
+	ActionTimesheetProjectUpdated = "timesheet_project.updated"
+	ActionTimesheetProjectDeleted = "timesheet_project.deleted"
+	ActionTimesheetTaskCreated    = "timesheet_task.created"
+	ActionTimesheetTaskUpdated    = "timesheet_task.updated"
+	ActionTimesheetTaskDeleted    = "timesheet_task.deleted"
+	ActionTimesheetEntryCreated   = "timesheet_entry.created"
+	ActionTimesheetEntryUpdated   = "timesheet_entry.updated"
+	ActionTimesheetEntryDeleted   = "timesheet_entry.deleted"
+	ActionTimesheetSubmitted      = "timesheet.submitted"
+	ActionTimesheetApproved       = "timesheet.approved"
+	ActionTimesheetRejected       = "timesheet.rejected"
+	ActionTimesheetReopened       = "timesheet.reopened"
+
+	// File events
+	ActionFileUploaded = "file.uploaded"
+	ActionFileDeleted  = "file.deleted"
+	ActionFileRestored = "file.restored"
+
+	// CRM events
+	ActionCRMCustomerCreated    = "crm_customer.created"
+	ActionCRMCustomerUpdated    = "crm_customer.updated"
+	ActionCRMCustomerDeleted    = "crm_customer.deleted"
+	ActionCRMContactCreated     = "crm_contact.created"
+	ActionCRMContactUpdated     = "crm_contact.updated"
+	ActionCRMContactDeleted     = "crm_contact.deleted"
+	ActionCRMPipelineCreated    = "crm_pipeline.created"
+	ActionCRMPipelineUpdated    = "crm_pipeline.updated"
+	ActionCRMPipelineDeleted    = "crm_pipeline.deleted"
+	ActionCRMOpportunityCreated = "crm_opportunity.created"
+	ActionCRMOpportunityUpdated = "crm_opportunity.updated"
+	ActionCRMOpportunityDeleted = "crm_opportunity.deleted"
+	ActionCRMOpportunityStaged  = "crm_opportunity.stage_changed"
+	ActionCRMOwnerAssigned      = "crm.owner_assigned"
+	ActionCRMActivityLogged     = "crm_activity.created"
+	ActionCRMActivityUpdated    = "crm_activity.updated"
+	ActionCRMActivityDeleted    = "crm_activity.deleted"
+
+	// Invitation events
+	ActionInvitationCreated  = "invitation.created"
+	ActionInvitationAccepted = "invitation.accepted"
+	ActionInvitationRevoked  = "invitation.revoked"
+	ActionInvitationResent   = "invitation.resent"
+
+	// Webhook events
+	ActionWebhookCreated         = "webhook.created"
+	ActionWebhookUpdated         = "webhook.updated"
+	ActionWebhookDeleted         = "webhook.deleted"
+	ActionWebhookSecretRotated   = "webhook.secret_rotated"
+	ActionWebhookDeliveryRetried = "webhook.delivery_retried"
+
+	// Automation events
+	ActionAutomationRuleCreated  = "automation.rule_created"
+	ActionAutomationRuleUpdated  = "automation.rule_updated"
+	ActionAutomationRuleDeleted  = "automation.rule_deleted"
+	ActionAutomationRuleEnabled  = "automation.rule_enabled"
+	ActionAutomationRuleDisabled = "automation.rule_disabled"
+
+	// Escalation events
+	ActionEscalationPolicyCreated  = "escalation.policy_created"
+	ActionEscalationPolicyUpdated  = "escalation.policy_updated"
+	ActionEscalationPolicyDeleted  = "escalation.policy_deleted"
+	ActionEscalationPolicyEnabled  = "escalation.policy_enabled"
+	ActionEscalationPolicyDisabled = "escalation.policy_disabled"
+
+	// Data quality events
+	ActionDataQualityRuleCreated = "data_quality.rule_created"
+	ActionDataQualityRuleUpdated = "data_quality.rule_updated"
+	ActionDataQualityRuleDeleted = "data_quality.rule_deleted"
 
 	// Permission events
 	ActionPermissionGranted = "permission.granted"
 	ActionPermissionRevoked = "permission.revoked"
 
 	// 2FA events
-	Action2FAEnabled       = "2fa.enabled"
-	Action2FADisabled      = "2fa.disabled"
-	Action2FAVerified      = "2fa.verified"
-	Action2FAFailed        = "2fa.failed"
-	Action2FABackupCodeUsed = "2fa.backup_code_used"
+	Action2FAEnabled         = "2fa.enabled"
+	Action2FADisabled        = "2fa.disabled"
+	Action2FAVerified        = "2fa.verified"
+	Action2FAFailed          = "2fa.failed"
+	Action2FABackupCodeUsed  = "2fa.backup_code_used"
+	Action2FABypassRequested = "2fa.bypass_requested"
+	Action2FABypassIssued    = "2fa.bypass_issued"
+	Action2FABypassUsed      = "2fa.bypass_used"
 
 	// Session events
-	ActionSessionCreated   = "session.created"
-	ActionSessionRevoked   = "session.revoked"
-	ActionSessionExpired   = "session.expired"
+	ActionSessionCreated      = "session.created"
+	ActionSessionRevoked      = "session.revoked"
+	ActionSessionExpired      = "session.expired"
+	ActionSessionRenamed      = "session.renamed"
+	ActionSessionEvicted      = "session.evicted" // Signed out by the concurrent session limit
+	ActionSessionLimitUpdated = "session.limit_updated"
 
 	// Security events
 	ActionUnauthorizedAccess = "security.unauthorized_access"
 	ActionRateLimitExceeded  = "security.rate_limit_exceeded"
 	ActionSuspiciousActivity = "security.suspicious_activity"
+	ActionAuditLogsExported  = "security.audit_logs_exported"
 )
 
+// AuditResourceInvitations is the audit resource type of invitations, which
+// are managed with the users permissions
+const AuditResourceInvitations = "invitations"
+
+// AuditResourceSessions is the audit resource type of the current user's
+// sessions
+const AuditResourceSessions = "sessions"
+
 // AuditLog status constants
 const (
 	AuditStatusSuccess = "success"
@@ -112,3 +245,43 @@ type AuditLogFilter struct {
 	Limit        int
 	Offset       int
 }
+
+// AuditMetadata is the JSONB metadata of an audit log entry
+type AuditMetadata map[string]interface{}
+
+// Scan implements sql.Scanner for JSONB columns
+func (m *AuditMetadata) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported audit metadata type: %T", value)
+	}
+
+	return json.Unmarshal(data, m)
+}
+
+// AuditFieldChange is a single field changed between the before and after
+// snapshots of an audit log entry. Nested fields use dotted paths.
+type AuditFieldChange struct {
+	Field  string      `json:"field"`
+	Change string      `json:"change"` // added | removed | changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Audit field change kinds
+const (
+	AuditChangeAdded   = "added"
+	AuditChangeRemoved = "removed"
+	AuditChangeChanged = "changed"
+)
+
+// AuditDiff is the field-level diff of an audit log entry's snapshots
+type AuditDiff struct {
+	Log     *AuditLog          `json:"log"`
+	Changes []AuditFieldChange `json:"changes"`
+}
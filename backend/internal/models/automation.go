@@ -0,0 +1,224 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AutomationRule runs actions when an event matching its trigger and
+// conditions is published
+type AutomationRule struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Name         string               `json:"name" db:"name"`
+	Description  *string              `json:"description,omitempty" db:"description"`
+	TriggerEvent string               `json:"trigger_event" db:"trigger_event"`
+	Conditions   AutomationConditions `json:"conditions" db:"conditions"`
+	Actions      AutomationActions    `json:"actions" db:"actions"`
+	IsEnabled    bool                 `json:"is_enabled" db:"is_enabled"`
+
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty" db:"last_triggered_at"`
+	CreatedBy       uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// AutomationCondition tests a field of the changed resource. Nested fields
+// use dotted paths (e.g. "preferences.theme").
+type AutomationCondition struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+// AutomationAction is one step of a rule. Which parameters apply depends on
+// the type.
+type AutomationAction struct {
+	Type string `json:"type"`
+
+	// send_notification: recipients are "actor" (who made the change),
+	// "resource" (the user the event is about) or user IDs. Subject and body
+	// are templates over the event (see AutomationTemplateData).
+	Recipients []string `json:"recipients,omitempty"`
+	Subject    string   `json:"subject,omitempty"`
+	Body       string   `json:"body,omitempty"`
+
+	// assign_role: the role is assigned to the user the event is about
+	RoleID *uuid.UUID `json:"role_id,omitempty"`
+
+	// call_webhook: the event is delivered to this webhook, whatever it is
+	// subscribed to
+	WebhookID *uuid.UUID `json:"webhook_id,omitempty"`
+}
+
+// AutomationConditions is the JSONB list of a rule's conditions
+type AutomationConditions []AutomationCondition
+
+// Scan implements sql.Scanner for JSONB columns
+func (c *AutomationConditions) Scan(value interface{}) error {
+	return scanAutomationJSON(value, c)
+}
+
+// AutomationActions is the JSONB list of a rule's actions
+type AutomationActions []AutomationAction
+
+// Scan implements sql.Scanner for JSONB columns
+func (a *AutomationActions) Scan(value interface{}) error {
+	return scanAutomationJSON(value, a)
+}
+
+// AutomationActionResults is the JSONB outcome of an execution's actions
+type AutomationActionResults []AutomationActionResult
+
+// Scan implements sql.Scanner for JSONB columns
+func (r *AutomationActionResults) Scan(value interface{}) error {
+	return scanAutomationJSON(value, r)
+}
+
+// scanAutomationJSON decodes a JSONB column
+func scanAutomationJSON(value interface{}, dest interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported automation JSON type: %T", value)
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+// AutomationActionResult is the outcome of one action of an execution
+type AutomationActionResult struct {
+	Type   string `json:"type"`
+	Status string `json:"status"` // succeeded | failed
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AutomationExecution is a rule triggered by an event, queued for the
+// automation worker and kept as the rule's execution log
+type AutomationExecution struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	RuleID   uuid.UUID `json:"rule_id" db:"rule_id"`
+
+	EventID   uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType string          `json:"event_type" db:"event_type"`
+	Event     json.RawMessage `json:"event" db:"event"`
+
+	// Status: pending | succeeded | failed
+	Status        string                  `json:"status" db:"status"`
+	ActionResults AutomationActionResults `json:"action_results,omitempty" db:"action_results"`
+	StartedAt     *time.Time              `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt    *time.Time              `json:"finished_at,omitempty" db:"finished_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	RuleName string `json:"rule_name,omitempty" db:"rule_name"`
+}
+
+// ClaimedAutomationExecution is a pending execution with its rule's actions,
+// as claimed by the automation worker
+type ClaimedAutomationExecution struct {
+	AutomationExecution
+	Actions       AutomationActions `db:"actions"`
+	RuleEnabled   bool              `db:"rule_enabled"`
+	RuleCreatedBy uuid.UUID         `db:"rule_created_by"`
+}
+
+// AutomationEvent is the event stored with an execution
+type AutomationEvent struct {
+	ID           uuid.UUID       `json:"id"`
+	Type         string          `json:"type"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   *uuid.UUID      `json:"resource_id,omitempty"`
+	ActorID      *uuid.UUID      `json:"actor_id,omitempty"`
+	Object       json.RawMessage `json:"object,omitempty"`
+}
+
+// Automation execution status constants
+const (
+	AutomationExecutionPending   = "pending"
+	AutomationExecutionSucceeded = "succeeded"
+	AutomationExecutionFailed    = "failed"
+)
+
+// Automation action result status constants
+const (
+	AutomationActionSucceeded = "succeeded"
+	AutomationActionFailed    = "failed"
+)
+
+// Automation action types
+const (
+	AutomationActionSendNotification = "send_notification"
+	AutomationActionAssignRole       = "assign_role"
+	AutomationActionCallWebhook      = "call_webhook"
+)
+
+// AutomationActionTypes are the actions a rule can run
+var AutomationActionTypes = []string{
+	AutomationActionSendNotification,
+	AutomationActionAssignRole,
+	AutomationActionCallWebhook,
+}
+
+// Automation condition operators
+const (
+	AutomationOperatorEquals    = "equals"
+	AutomationOperatorNotEquals = "not_equals"
+	AutomationOperatorIn        = "in"
+	AutomationOperatorNotIn     = "not_in"
+	AutomationOperatorContains  = "contains"
+	AutomationOperatorExists    = "exists"
+	AutomationOperatorNotExists = "not_exists"
+)
+
+// AutomationOperators are the operators conditions can use
+var AutomationOperators = []string{
+	AutomationOperatorEquals,
+	AutomationOperatorNotEquals,
+	AutomationOperatorIn,
+	AutomationOperatorNotIn,
+	AutomationOperatorContains,
+	AutomationOperatorExists,
+	AutomationOperatorNotExists,
+}
+
+// Notification recipients resolved from the event
+const (
+	AutomationRecipientActor    = "actor"
+	AutomationRecipientResource = "resource"
+)
+
+// ResourceAutomation is the permission resource for automation rules
+const ResourceAutomation = "automation"
+
+// AutomationRuleRequest represents a request to create or replace a rule
+type AutomationRuleRequest struct {
+	Name         string                `json:"name"`
+	Description  string                `json:"description,omitempty"`
+	TriggerEvent string                `json:"trigger_event"`
+	Conditions   []AutomationCondition `json:"conditions"`
+	Actions      []AutomationAction    `json:"actions"`
+	IsEnabled    *bool                 `json:"is_enabled,omitempty"`
+}
+
+// AutomationTemplateData is what send_notification subject and body
+// templates can use, e.g. {{.Object.email}}
+type AutomationTemplateData struct {
+	CompanyName  string
+	EventType    string
+	ResourceType string
+	ResourceID   string
+	Object       map[string]interface{}
+}
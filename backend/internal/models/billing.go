@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+)
+
+// Plan features: modules only some plan tiers include
+const (
+	PlanFeatureWebhooks    = "webhooks"    // Registering outgoing webhooks
+	PlanFeatureAutomations = "automations" // Creating automation rules
+	PlanFeatureSandboxes   = "sandboxes"   // Creating sandbox copies of the tenant
+	PlanFeatureSAML        = "saml"        // SAML single sign-on (SSO_SAML_PLAN_TIERS)
+)
+
+// PlanTiers lists the plan tiers from the smallest to the largest
+var PlanTiers = []string{PlanTierFree, PlanTierStarter, PlanTierProfessional, PlanTierEnterprise}
+
+// Stripe subscription statuses
+const (
+	SubscriptionStatusActive     = "active"
+	SubscriptionStatusTrialing   = "trialing"
+	SubscriptionStatusPastDue    = "past_due"
+	SubscriptionStatusUnpaid     = "unpaid"
+	SubscriptionStatusCanceled   = "canceled"
+	SubscriptionStatusIncomplete = "incomplete"
+)
+
+// Plan is what a plan tier includes
+type Plan struct {
+	Tier         string   `json:"tier"`
+	UserLimit    *int64   `json:"user_limit"`     // nil = unlimited
+	StorageLimit *int64   `json:"storage_limit"`  // Bytes; nil = unlimited
+	APICallLimit *int64   `json:"api_call_limit"` // Per calendar month; nil = unlimited
+	Features     []string `json:"features"`
+	Purchasable  bool     `json:"purchasable"` // Can be subscribed to through checkout
+}
+
+// HasFeature returns true if the plan includes the feature
+func (p *Plan) HasFeature(feature string) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantSubscription is a tenant's Stripe subscription, as reported by Stripe
+type TenantSubscription struct {
+	CustomerID        string
+	SubscriptionID    *string // nil once the subscription ended
+	Status            string
+	PlanTier          string
+	PeriodEnd         *time.Time
+	CancelAtPeriodEnd bool
+}
+
+// BillingStatus is a tenant's plan and subscription
+type BillingStatus struct {
+	Plan              *Plan            `json:"plan"`
+	Status            *string          `json:"subscription_status"` // nil = never subscribed
+	PeriodEnd         *time.Time       `json:"period_end,omitempty"`
+	CancelAtPeriodEnd bool             `json:"cancel_at_period_end"`
+	Quotas            []QuotaUsage     `json:"quotas"`
+	Usage             map[string]int64 `json:"usage"`           // Metered this calendar month
+	BillingEnabled    bool             `json:"billing_enabled"` // Plans can be bought and changed
+}
+
+// BillingCheckoutRequest starts a subscription to a plan
+type BillingCheckoutRequest struct {
+	PlanTier string `json:"plan_tier"`
+}
+
+// BillingCheckout is a Stripe Checkout page to send the user to
+type BillingCheckout struct {
+	URL string `json:"url"`
+}
+
+// BillingPlanChangeRequest changes a subscribed tenant's plan. The free plan
+// cancels the subscription at the end of the paid period.
+type BillingPlanChangeRequest struct {
+	PlanTier string `json:"plan_tier"`
+}
+
+// AuditResourceBilling is the audit resource type of the tenant's plan and
+// subscription
+const AuditResourceBilling = "billing"
+
+// Billing audit actions
+const (
+	ActionBillingCheckoutStarted   = "billing.checkout_started"
+	ActionBillingPlanChanged       = "billing.plan_changed"
+	ActionBillingSubscriptionSync  = "billing.subscription_updated"
+	ActionBillingSubscriptionEnded = "billing.subscription_ended"
+)
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailBroadcast is an email composed for all or a filtered set of a
+// tenant's users
+type EmailBroadcast struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Subject and body are templates rendered per recipient
+	Subject   string          `json:"subject" db:"subject"`
+	Body      string          `json:"body" db:"body"`
+	Variables json.RawMessage `json:"variables" db:"variables"`
+	Filter    json.RawMessage `json:"filter" db:"filter"`
+
+	// Status: sending | completed | cancelled
+	Status          string `json:"status" db:"status"`
+	TotalRecipients int    `json:"total_recipients" db:"total_recipients"`
+
+	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Delivery counts (populated on demand)
+	Stats *BroadcastStats `json:"stats,omitempty" db:"-"`
+}
+
+// BroadcastRecipient is a user a broadcast is sent to. DeliveryStatus
+// combines the recipient's own status with that of its queued outbox email.
+type BroadcastRecipient struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TenantID    uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	BroadcastID uuid.UUID `json:"broadcast_id" db:"broadcast_id"`
+
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	LastName  string    `json:"last_name" db:"last_name"`
+
+	// Status: pending | queued | skipped | failed | cancelled
+	Status           string     `json:"status" db:"status"`
+	SkipReason       *string    `json:"skip_reason,omitempty" db:"skip_reason"`
+	OutboxEmailID    *uuid.UUID `json:"outbox_email_id,omitempty" db:"outbox_email_id"`
+	QueuedAt         *time.Time `json:"queued_at,omitempty" db:"queued_at"`
+	UnsubscribeToken uuid.UUID  `json:"-" db:"unsubscribe_token"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+
+	// DeliveryStatus: pending | sending | sent | failed | skipped | cancelled
+	DeliveryStatus string  `json:"delivery_status" db:"delivery_status"`
+	DeliveryError  *string `json:"delivery_error,omitempty" db:"delivery_error"`
+}
+
+// Broadcast status constants
+const (
+	BroadcastStatusSending   = "sending"
+	BroadcastStatusCompleted = "completed"
+	BroadcastStatusCancelled = "cancelled"
+)
+
+// Broadcast recipient status constants
+const (
+	RecipientStatusPending   = "pending"
+	RecipientStatusQueued    = "queued"
+	RecipientStatusSkipped   = "skipped"
+	RecipientStatusFailed    = "failed"
+	RecipientStatusCancelled = "cancelled"
+)
+
+// Broadcast delivery statuses (recipient status combined with the outbox)
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSending   = "sending"
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusFailed    = "failed"
+	DeliveryStatusSkipped   = "skipped"
+	DeliveryStatusCancelled = "cancelled"
+)
+
+// Broadcast permission resource and actions
+const (
+	ResourceBroadcasts = "broadcasts"
+	ActionCancel       = "cancel"
+)
+
+// BroadcastFilter selects the users a broadcast is sent to. Empty lists match
+// everyone; Statuses defaults to active users only.
+type BroadcastFilter struct {
+	RoleIDs       []uuid.UUID `json:"role_ids,omitempty"`
+	DepartmentIDs []uuid.UUID `json:"department_ids,omitempty"`
+	Statuses      []string    `json:"statuses,omitempty"`
+}
+
+// BroadcastStats counts a broadcast's recipients per delivery status
+type BroadcastStats struct {
+	Pending   int `json:"pending" db:"pending"`
+	Sending   int `json:"sending" db:"sending"`
+	Sent      int `json:"sent" db:"sent"`
+	Failed    int `json:"failed" db:"failed"`
+	Skipped   int `json:"skipped" db:"skipped"`
+	Cancelled int `json:"cancelled" db:"cancelled"`
+}
+
+// BroadcastCreateRequest represents a request to send a broadcast.
+// Subject and body are Go templates; besides Variables they can use
+// {{.FirstName}}, {{.LastName}}, {{.Email}} and {{.CompanyName}}.
+type BroadcastCreateRequest struct {
+	Subject   string            `json:"subject" validate:"required,min=1,max=500"`
+	Body      string            `json:"body" validate:"required"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Filter    BroadcastFilter   `json:"filter"`
+}
+
+// PendingBroadcastEmail is a pending recipient with everything needed to
+// render its email, as claimed by the broadcast worker
+type PendingBroadcastEmail struct {
+	BroadcastRecipient
+	Subject     string          `db:"subject"`
+	Body        string          `db:"body"`
+	Variables   json.RawMessage `db:"variables"`
+	CompanyName string          `db:"company_name"`
+	TenantSlug  string          `db:"tenant_slug"`
+	Language    string          `db:"language"` // The recipient's, for the email's texts
+	OptedOut    bool            `db:"opted_out"`
+}
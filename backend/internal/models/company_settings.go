@@ -93,3 +93,15 @@ type CompanySettingsUpdateRequest struct {
 	AINumber          *string          `json:"ai_number,omitempty"`
 	CapitalSocial     *float64         `json:"capital_social,omitempty"`
 }
+
+// CompanySettingsNullableFields lists the optional company settings, which
+// a merge patch clears with null. The others always keep a value.
+var CompanySettingsNullableFields = map[string]bool{
+	"legal_business_name": true, "industry": true, "speciality": true,
+	"company_size": true, "founded_date": true, "website_url": true,
+	"logo_url": true, "primary_email": true, "support_email": true,
+	"phone_number": true, "fax": true, "street_address": true,
+	"city": true, "state": true, "postal_code": true, "country": true,
+	"fiscal_year_start": true, "rc_number": true, "nif_number": true,
+	"nis_number": true, "ai_number": true, "capital_social": true,
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobTypeComplianceReport is the async job type compiling a compliance
+// evidence package
+const JobTypeComplianceReport = "compliance_report"
+
+// FileResourceComplianceReport is the resource type of stored evidence
+// packages; the resource is the job that compiled them
+const FileResourceComplianceReport = "compliance_report"
+
+// Compliance frameworks a report can be compiled for
+const (
+	ComplianceFrameworkSOC2     = "soc2"
+	ComplianceFrameworkISO27001 = "iso27001"
+)
+
+// ComplianceFrameworks lists the valid frameworks, for validation
+var ComplianceFrameworks = []string{ComplianceFrameworkSOC2, ComplianceFrameworkISO27001}
+
+// ComplianceFrameworkNames are the frameworks' names as printed in reports
+var ComplianceFrameworkNames = map[string]string{
+	ComplianceFrameworkSOC2:     "SOC 2 (Trust Services Criteria)",
+	ComplianceFrameworkISO27001: "ISO/IEC 27001:2022",
+}
+
+// ComplianceControl is a framework control and the sections of a report
+// that evidence it
+type ComplianceControl struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Evidence []string `json:"evidence"` // Files of the package
+}
+
+// ComplianceControls maps each framework to the controls a report evidences
+var ComplianceControls = map[string][]ComplianceControl{
+	ComplianceFrameworkSOC2: {
+		{ID: "CC6.1", Title: "Logical access security, including authentication", Evidence: []string{"evidence/password_policy.json", "evidence/user_access.csv"}},
+		{ID: "CC6.2", Title: "Registration and authorization of users", Evidence: []string{"evidence/admin_actions.csv"}},
+		{ID: "CC6.3", Title: "Role-based access, including changes and removal", Evidence: []string{"evidence/user_access.csv", "evidence/admin_actions.csv"}},
+		{ID: "CC7.2", Title: "Monitoring for anomalies and security events", Evidence: []string{"evidence/failed_logins.csv"}},
+	},
+	ComplianceFrameworkISO27001: {
+		{ID: "A.5.15", Title: "Access control", Evidence: []string{"evidence/user_access.csv"}},
+		{ID: "A.5.17", Title: "Authentication information", Evidence: []string{"evidence/password_policy.json"}},
+		{ID: "A.5.18", Title: "Access rights", Evidence: []string{"evidence/user_access.csv", "evidence/admin_actions.csv"}},
+		{ID: "A.8.5", Title: "Secure authentication", Evidence: []string{"evidence/user_access.csv", "evidence/failed_logins.csv"}},
+		{ID: "A.8.15", Title: "Logging", Evidence: []string{"evidence/admin_actions.csv", "evidence/failed_logins.csv"}},
+	},
+}
+
+// ComplianceReportRequest represents a request to compile an evidence
+// package
+type ComplianceReportRequest struct {
+	Framework   string `json:"framework"`
+	PeriodStart string `json:"period_start"` // YYYY-MM-DD
+	PeriodEnd   string `json:"period_end"`   // YYYY-MM-DD, included
+}
+
+// ComplianceReportJobParams are the parameters of a compliance report job.
+// The period runs from the start of PeriodStart to the end of PeriodEnd, in
+// UTC.
+type ComplianceReportJobParams struct {
+	Framework   string    `json:"framework"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// ComplianceReport is the result of a compliance report job: the stored
+// package and the figures it reports
+type ComplianceReport struct {
+	File        File              `json:"file"`
+	DownloadURL string            `json:"download_url"`
+	Summary     ComplianceSummary `json:"summary"`
+}
+
+// ComplianceSummary holds a report's figures; it is also summary.json in
+// the package
+type ComplianceSummary struct {
+	Framework      string                   `json:"framework"`
+	TenantName     string                   `json:"tenant_name"`
+	PeriodStart    string                   `json:"period_start"`
+	PeriodEnd      string                   `json:"period_end"`
+	GeneratedAt    time.Time                `json:"generated_at"`
+	GeneratedBy    uuid.UUID                `json:"generated_by"`
+	AccessReview   ComplianceAccessReview   `json:"access_review"`
+	AdminActions   ComplianceAdminActions   `json:"admin_actions"`
+	TwoFactor      ComplianceTwoFactor      `json:"two_factor"`
+	PasswordPolicy CompliancePasswordPolicy `json:"password_policy"`
+	FailedLogins   ComplianceFailedLogins   `json:"failed_logins"`
+	Controls       []ComplianceControl      `json:"controls"`
+}
+
+// ComplianceAccessReview is the user access as of the report, to be reviewed
+// against the users' duties, and the reviews of access done in the period:
+// changes of roles, permissions and user status
+type ComplianceAccessReview struct {
+	Users             int `json:"users"`
+	ActiveUsers       int `json:"active_users"`
+	PrivilegedUsers   int `json:"privileged_users"` // May manage users, roles, settings or security
+	DormantUsers      int `json:"dormant_users"`    // Active but not signed in for ComplianceDormantDays
+	UsersWithoutRoles int `json:"users_without_roles"`
+	RoleChanges       int `json:"role_changes"`
+	StatusChanges     int `json:"status_changes"`
+}
+
+// ComplianceDormantDays is how long an active user goes without signing in
+// before being reported as dormant
+const ComplianceDormantDays = 90
+
+// ComplianceAdminActions counts the administrative actions of the period
+type ComplianceAdminActions struct {
+	Total    int            `json:"total"`
+	Failures int            `json:"failures"`
+	Actors   int            `json:"actors"`
+	ByAction map[string]int `json:"by_action"`
+}
+
+// ComplianceTwoFactor is the adoption of two-factor authentication
+type ComplianceTwoFactor struct {
+	ActiveUsers        int     `json:"active_users"`
+	Enrolled           int     `json:"enrolled"`
+	AdoptionPercent    float64 `json:"adoption_percent"`
+	PrivilegedUsers    int     `json:"privileged_users"`
+	PrivilegedEnrolled int     `json:"privileged_enrolled"`
+	EnabledInPeriod    int     `json:"enabled_in_period"`
+	DisabledInPeriod   int     `json:"disabled_in_period"`
+	SSORequired        bool    `json:"sso_required"` // Users sign in through the identity provider, which handles MFA
+}
+
+// CompliancePasswordPolicy is the password and sign-in policy in force when
+// the report was compiled
+type CompliancePasswordPolicy struct {
+	MinLength              int    `json:"min_length"`
+	MaxLength              int    `json:"max_length"`
+	RequiresUppercase      bool   `json:"requires_uppercase"`
+	RequiresLowercase      bool   `json:"requires_lowercase"`
+	RequiresNumber         bool   `json:"requires_number"`
+	RequiresSpecial        bool   `json:"requires_special"`
+	HashAlgorithm          string `json:"hash_algorithm"`
+	HashCost               int    `json:"hash_cost"`
+	MaxLoginAttempts       int    `json:"max_login_attempts"`
+	LoginAttemptWindow     string `json:"login_attempt_window"`
+	ResetLinkExpiry        string `json:"reset_link_expiry"`
+	SessionInactivityLimit string `json:"session_inactivity_limit"`
+	SSORequired            bool   `json:"sso_required"`
+}
+
+// ComplianceFailedLogins are the statistics of failed sign-ins in the period
+type ComplianceFailedLogins struct {
+	Total       int                 `json:"total"`
+	Users       int                 `json:"users"`
+	IPAddresses int                 `json:"ip_addresses"`
+	ByReason    map[string]int      `json:"by_reason"`
+	ByDay       map[string]int      `json:"by_day"` // YYYY-MM-DD
+	TopIPs      []ComplianceIPCount `json:"top_ips"`
+}
+
+// ComplianceIPCount is the number of failed sign-ins from an IP address
+type ComplianceIPCount struct {
+	IPAddress string `json:"ip_address"`
+	Count     int    `json:"count"`
+}
+
+// ComplianceUserAccess is a user's access as listed for review
+type ComplianceUserAccess struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	Email            string     `json:"email" db:"email"`
+	Name             string     `json:"name" db:"name"`
+	Status           string     `json:"status" db:"status"`
+	Roles            string     `json:"roles" db:"roles"` // Role names separated by ";"
+	Privileged       bool       `json:"privileged" db:"privileged"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" db:"two_factor_enabled"`
+	LastLoginAt      *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Compliance audit actions
+const (
+	ActionComplianceReportRequested = "compliance.report_requested"
+)
@@ -0,0 +1,413 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CRMCustomer is a company or person the tenant sells to, from first lead
+// to active account
+type CRMCustomer struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Basic Info
+	Name         string  `json:"name" db:"name"`
+	CustomerType string  `json:"customer_type" db:"customer_type"`
+	Industry     *string `json:"industry,omitempty" db:"industry"`
+	Website      *string `json:"website,omitempty" db:"website"`
+	Email        *string `json:"email,omitempty" db:"email"`
+	Phone        *string `json:"phone,omitempty" db:"phone"`
+	Address      *string `json:"address,omitempty" db:"address"`
+	TaxID        *string `json:"tax_id,omitempty" db:"tax_id"`
+	Notes        *string `json:"notes,omitempty" db:"notes"`
+
+	// Status
+	Status string `json:"status" db:"status"`
+
+	// Assignment
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	OwnerName         *string `json:"owner_name,omitempty" db:"owner_name"`
+	ContactCount      int     `json:"contact_count" db:"contact_count"`
+	OpenOpportunities int     `json:"open_opportunities" db:"open_opportunities"`
+}
+
+// CRMContact is a person at a customer
+type CRMContact struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	CustomerID uuid.UUID `json:"customer_id" db:"customer_id"`
+
+	// Basic Info
+	FirstName string  `json:"first_name" db:"first_name"`
+	LastName  string  `json:"last_name" db:"last_name"`
+	Email     *string `json:"email,omitempty" db:"email"`
+	Phone     *string `json:"phone,omitempty" db:"phone"`
+	JobTitle  *string `json:"job_title,omitempty" db:"job_title"`
+	Notes     *string `json:"notes,omitempty" db:"notes"`
+	IsPrimary bool    `json:"is_primary" db:"is_primary"`
+
+	// Assignment
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	CustomerName string  `json:"customer_name" db:"customer_name"`
+	OwnerName    *string `json:"owner_name,omitempty" db:"owner_name"`
+}
+
+// FullName returns the contact's full name
+func (c *CRMContact) FullName() string {
+	return c.FirstName + " " + c.LastName
+}
+
+// CRMPipeline is a sequence of stages opportunities move through
+type CRMPipeline struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	TenantID  uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Name      string    `json:"name" db:"name"`
+	IsDefault bool      `json:"is_default" db:"is_default"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Loaded separately
+	Stages []CRMPipelineStage `json:"stages" db:"-"`
+}
+
+// CRMPipelineStage is a step of a pipeline. Its outcome sets the status of
+// the opportunities in it.
+type CRMPipelineStage struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TenantID    uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	PipelineID  uuid.UUID `json:"pipeline_id" db:"pipeline_id"`
+	Name        string    `json:"name" db:"name"`
+	Position    int       `json:"position" db:"position"`
+	Probability int       `json:"probability" db:"probability"` // Default win probability (%) on entering the stage
+	Outcome     string    `json:"outcome" db:"outcome"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsClosed returns true for the won and lost stages
+func (s *CRMPipelineStage) IsClosed() bool {
+	return s.Outcome != CRMOutcomeOpen
+}
+
+// CRMOpportunity is a deal with a customer worked through a pipeline
+type CRMOpportunity struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	CustomerID uuid.UUID  `json:"customer_id" db:"customer_id"`
+	ContactID  *uuid.UUID `json:"contact_id,omitempty" db:"contact_id"`
+	PipelineID uuid.UUID  `json:"pipeline_id" db:"pipeline_id"`
+	StageID    uuid.UUID  `json:"stage_id" db:"stage_id"`
+
+	// Deal
+	Name              string     `json:"name" db:"name"`
+	Amount            float64    `json:"amount" db:"amount"`
+	Currency          string     `json:"currency" db:"currency"`
+	Probability       int        `json:"probability" db:"probability"`
+	ExpectedCloseDate *time.Time `json:"expected_close_date,omitempty" db:"expected_close_date"`
+
+	// Status
+	Status     string     `json:"status" db:"status"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	LostReason *string    `json:"lost_reason,omitempty" db:"lost_reason"`
+
+	// Assignment
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	CustomerName string  `json:"customer_name" db:"customer_name"`
+	ContactName  *string `json:"contact_name,omitempty" db:"contact_name"`
+	PipelineName string  `json:"pipeline_name" db:"pipeline_name"`
+	StageName    string  `json:"stage_name" db:"stage_name"`
+	OwnerName    *string `json:"owner_name,omitempty" db:"owner_name"`
+}
+
+// CRMActivity is an entry of a customer's timeline
+type CRMActivity struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TenantID      uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	CustomerID    uuid.UUID  `json:"customer_id" db:"customer_id"`
+	ContactID     *uuid.UUID `json:"contact_id,omitempty" db:"contact_id"`
+	OpportunityID *uuid.UUID `json:"opportunity_id,omitempty" db:"opportunity_id"`
+
+	ActivityType string     `json:"activity_type" db:"activity_type"`
+	Subject      string     `json:"subject" db:"subject"`
+	Body         *string    `json:"body,omitempty" db:"body"`
+	DueAt        *time.Time `json:"due_at,omitempty" db:"due_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+
+	// Assignment
+	OwnerID *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	CustomerName    string  `json:"customer_name" db:"customer_name"`
+	ContactName     *string `json:"contact_name,omitempty" db:"contact_name"`
+	OpportunityName *string `json:"opportunity_name,omitempty" db:"opportunity_name"`
+	OwnerName       *string `json:"owner_name,omitempty" db:"owner_name"`
+	CreatedByName   *string `json:"created_by_name,omitempty" db:"created_by_name"`
+}
+
+// IsSystem returns true for entries written by the application
+func (a *CRMActivity) IsSystem() bool {
+	return a.ActivityType == CRMActivitySystem
+}
+
+// CRMStageSummary totals the opportunities in a pipeline stage
+type CRMStageSummary struct {
+	StageID        uuid.UUID `json:"stage_id" db:"stage_id"`
+	Name           string    `json:"name" db:"name"`
+	Position       int       `json:"position" db:"position"`
+	Outcome        string    `json:"outcome" db:"outcome"`
+	Count          int       `json:"count" db:"count"`
+	Amount         float64   `json:"amount" db:"amount"`
+	WeightedAmount float64   `json:"weighted_amount" db:"weighted_amount"` // Amount times probability
+}
+
+// CRM customer types
+const (
+	CRMCustomerCompany    = "company"
+	CRMCustomerIndividual = "individual"
+)
+
+// CRM customer status constants
+const (
+	CRMCustomerStatusLead     = "lead"
+	CRMCustomerStatusActive   = "active"
+	CRMCustomerStatusInactive = "inactive"
+)
+
+// CRM stage outcomes, also the opportunity statuses
+const (
+	CRMOutcomeOpen = "open"
+	CRMOutcomeWon  = "won"
+	CRMOutcomeLost = "lost"
+)
+
+// CRM activity types. System entries are written by the application.
+const (
+	CRMActivityCall    = "call"
+	CRMActivityEmail   = "email"
+	CRMActivityMeeting = "meeting"
+	CRMActivityNote    = "note"
+	CRMActivityTask    = "task"
+	CRMActivitySystem  = "system"
+)
+
+// CRMCustomerTypes, CRMCustomerStatuses, CRMOutcomes and CRMActivityTypes
+// list the valid values, for validation. CRMActivityTypes leaves out system
+// entries, which users cannot log.
+var (
+	CRMCustomerTypes    = []string{CRMCustomerCompany, CRMCustomerIndividual}
+	CRMCustomerStatuses = []string{CRMCustomerStatusLead, CRMCustomerStatusActive, CRMCustomerStatusInactive}
+	CRMOutcomes         = []string{CRMOutcomeOpen, CRMOutcomeWon, CRMOutcomeLost}
+	CRMActivityTypes    = []string{CRMActivityCall, CRMActivityEmail, CRMActivityMeeting, CRMActivityNote, CRMActivityTask}
+)
+
+// Permission resource constant
+const (
+	ResourceCRM = "crm"
+)
+
+// Permission actions for the CRM
+const (
+	ActionManagePipelines = "manage_pipelines"
+)
+
+// CRMCustomerFilter filters the customer list
+type CRMCustomerFilter struct {
+	OwnerID *uuid.UUID
+	Status  string
+	Search  string // Name or email, accent-insensitive
+}
+
+// CRMContactFilter filters the contact list
+type CRMContactFilter struct {
+	CustomerID *uuid.UUID
+	OwnerID    *uuid.UUID
+	Search     string // Name or email, accent-insensitive
+}
+
+// CRMOpportunityFilter filters the opportunity list
+type CRMOpportunityFilter struct {
+	CustomerID *uuid.UUID
+	PipelineID *uuid.UUID
+	StageID    *uuid.UUID
+	OwnerID    *uuid.UUID
+	Status     string
+	Search     string // Opportunity or customer name, accent-insensitive
+}
+
+// CRMActivityFilter filters the activity list
+type CRMActivityFilter struct {
+	CustomerID    *uuid.UUID
+	ContactID     *uuid.UUID
+	OpportunityID *uuid.UUID
+	OwnerID       *uuid.UUID
+	ActivityType  string
+	OpenTasks     bool // Only tasks not yet completed, by due date
+}
+
+// CRMCustomerCreateRequest represents a request to create a customer. The
+// owner defaults to the creator.
+type CRMCustomerCreateRequest struct {
+	Name         string     `json:"name" validate:"required,max=255"`
+	CustomerType string     `json:"customer_type"`
+	Industry     *string    `json:"industry,omitempty"`
+	Website      *string    `json:"website,omitempty"`
+	Email        *string    `json:"email,omitempty" validate:"omitempty,email"`
+	Phone        *string    `json:"phone,omitempty"`
+	Address      *string    `json:"address,omitempty"`
+	TaxID        *string    `json:"tax_id,omitempty"`
+	Notes        *string    `json:"notes,omitempty"`
+	Status       string     `json:"status"`
+	OwnerID      *uuid.UUID `json:"owner_id,omitempty"`
+}
+
+// CRMCustomerUpdateRequest represents a request to update a customer. The
+// owner is changed with the assign endpoint.
+type CRMCustomerUpdateRequest struct {
+	Name         *string `json:"name,omitempty"`
+	CustomerType *string `json:"customer_type,omitempty"`
+	Industry     *string `json:"industry,omitempty"`
+	Website      *string `json:"website,omitempty"`
+	Email        *string `json:"email,omitempty"`
+	Phone        *string `json:"phone,omitempty"`
+	Address      *string `json:"address,omitempty"`
+	TaxID        *string `json:"tax_id,omitempty"`
+	Notes        *string `json:"notes,omitempty"`
+	Status       *string `json:"status,omitempty"`
+}
+
+// CRMContactCreateRequest represents a request to create a contact. The
+// owner defaults to the customer's owner.
+type CRMContactCreateRequest struct {
+	CustomerID uuid.UUID  `json:"customer_id" validate:"required"`
+	FirstName  string     `json:"first_name" validate:"required,max=100"`
+	LastName   string     `json:"last_name" validate:"required,max=100"`
+	Email      *string    `json:"email,omitempty" validate:"omitempty,email"`
+	Phone      *string    `json:"phone,omitempty"`
+	JobTitle   *string    `json:"job_title,omitempty"`
+	Notes      *string    `json:"notes,omitempty"`
+	IsPrimary  bool       `json:"is_primary"`
+	OwnerID    *uuid.UUID `json:"owner_id,omitempty"`
+}
+
+// CRMContactUpdateRequest represents a request to update a contact
+type CRMContactUpdateRequest struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Email     *string `json:"email,omitempty"`
+	Phone     *string `json:"phone,omitempty"`
+	JobTitle  *string `json:"job_title,omitempty"`
+	Notes     *string `json:"notes,omitempty"`
+	IsPrimary *bool   `json:"is_primary,omitempty"`
+}
+
+// CRMPipelineRequest represents a request to create or rename a pipeline.
+// Stages are only read on creation; a new pipeline without stages gets the
+// default ones.
+type CRMPipelineRequest struct {
+	Name      string                    `json:"name"`
+	IsDefault bool                      `json:"is_default"`
+	Stages    []CRMPipelineStageRequest `json:"stages,omitempty"`
+}
+
+// CRMPipelineStageRequest represents a stage to add or update. A stage
+// without a position is added at the end.
+type CRMPipelineStageRequest struct {
+	Name        string `json:"name"`
+	Position    int    `json:"position"`
+	Probability int    `json:"probability"`
+	Outcome     string `json:"outcome"`
+}
+
+// CRMOpportunityCreateRequest represents a request to create an opportunity.
+// The pipeline defaults to the tenant's default pipeline and the stage to
+// its first open stage; the owner defaults to the customer's owner, then the
+// creator.
+type CRMOpportunityCreateRequest struct {
+	CustomerID        uuid.UUID  `json:"customer_id" validate:"required"`
+	ContactID         *uuid.UUID `json:"contact_id,omitempty"`
+	PipelineID        *uuid.UUID `json:"pipeline_id,omitempty"`
+	StageID           *uuid.UUID `json:"stage_id,omitempty"`
+	Name              string     `json:"name" validate:"required,max=255"`
+	Amount            float64    `json:"amount"`
+	Currency          string     `json:"currency" validate:"omitempty,len=3"`
+	ExpectedCloseDate *time.Time `json:"expected_close_date,omitempty"`
+	OwnerID           *uuid.UUID `json:"owner_id,omitempty"`
+}
+
+// CRMOpportunityUpdateRequest represents a request to update an
+// opportunity. Stage and owner have their own endpoints.
+type CRMOpportunityUpdateRequest struct {
+	ContactID         *uuid.UUID `json:"contact_id,omitempty"`
+	ClearContact      bool       `json:"clear_contact,omitempty"`
+	Name              *string    `json:"name,omitempty"`
+	Amount            *float64   `json:"amount,omitempty"`
+	Currency          *string    `json:"currency,omitempty"`
+	Probability       *int       `json:"probability,omitempty"`
+	ExpectedCloseDate *time.Time `json:"expected_close_date,omitempty"`
+}
+
+// CRMStageChangeRequest moves an opportunity to another stage of its
+// pipeline. lost_reason is kept when the stage is a lost one.
+type CRMStageChangeRequest struct {
+	StageID    uuid.UUID `json:"stage_id"`
+	LostReason *string   `json:"lost_reason,omitempty"`
+}
+
+// CRMAssignRequest gives a record to another owner; a nil owner unassigns it
+type CRMAssignRequest struct {
+	OwnerID *uuid.UUID `json:"owner_id"`
+}
+
+// CRMActivityCreateRequest represents a request to log an activity. The
+// customer may be left out when a contact or opportunity is given.
+type CRMActivityCreateRequest struct {
+	CustomerID    *uuid.UUID `json:"customer_id,omitempty"`
+	ContactID     *uuid.UUID `json:"contact_id,omitempty"`
+	OpportunityID *uuid.UUID `json:"opportunity_id,omitempty"`
+	ActivityType  string     `json:"activity_type"`
+	Subject       string     `json:"subject"`
+	Body          *string    `json:"body,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	Completed     bool       `json:"completed"`
+	OwnerID       *uuid.UUID `json:"owner_id,omitempty"`
+}
+
+// CRMActivityUpdateRequest represents a request to update an activity
+type CRMActivityUpdateRequest struct {
+	Subject   *string    `json:"subject,omitempty"`
+	Body      *string    `json:"body,omitempty"`
+	DueAt     *time.Time `json:"due_at,omitempty"`
+	Completed *bool      `json:"completed,omitempty"`
+	OwnerID   *uuid.UUID `json:"owner_id,omitempty"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CustomField is a field a tenant defines on an entity type. Its values are
+// kept in the custom_fields of the entities, keyed by Key.
+type CustomField struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Entity: user | department
+	Entity string `json:"entity" db:"entity"`
+	Key    string `json:"key" db:"key"`
+	Label  string `json:"label" db:"label"`
+
+	// FieldType: text | number | date | select
+	FieldType string         `json:"field_type" db:"field_type"`
+	Options   pq.StringArray `json:"options" db:"options"` // Allowed values of a select field
+	Required  bool           `json:"required" db:"required"`
+	SortOrder int            `json:"sort_order" db:"sort_order"`
+
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Custom field types. Numbers are JSON numbers, dates YYYY-MM-DD strings and
+// select values one of the field's options.
+const (
+	CustomFieldTypeText   = "text"
+	CustomFieldTypeNumber = "number"
+	CustomFieldTypeDate   = "date"
+	CustomFieldTypeSelect = "select"
+)
+
+// CustomFieldTypes lists the custom field types
+var CustomFieldTypes = []string{
+	CustomFieldTypeText,
+	CustomFieldTypeNumber,
+	CustomFieldTypeDate,
+	CustomFieldTypeSelect,
+}
+
+// CustomFieldEntities lists the entity types tenants can define fields on
+var CustomFieldEntities = []string{
+	NavigationEntityUser,
+	NavigationEntityDepartment,
+}
+
+// Custom field audit actions. Custom fields are tenant settings.
+const (
+	ActionCustomFieldCreated = "custom_field.created"
+	ActionCustomFieldUpdated = "custom_field.updated"
+	ActionCustomFieldDeleted = "custom_field.deleted"
+)
+
+// CustomFieldCreateRequest defines a custom field. The entity, key and type
+// cannot be changed afterwards.
+type CustomFieldCreateRequest struct {
+	Entity    string   `json:"entity"`
+	Key       string   `json:"key"`
+	Label     string   `json:"label"`
+	FieldType string   `json:"field_type"`
+	Options   []string `json:"options,omitempty"`
+	Required  bool     `json:"required"`
+	SortOrder int      `json:"sort_order"`
+}
+
+// CustomFieldUpdateRequest replaces a custom field's label, options and
+// validation
+type CustomFieldUpdateRequest struct {
+	Label     string   `json:"label"`
+	Options   []string `json:"options,omitempty"`
+	Required  bool     `json:"required"`
+	SortOrder int      `json:"sort_order"`
+}
+
+// CustomFieldValues are the custom field values sent when creating or
+// updating an entity, by key. A null value removes the field's value.
+type CustomFieldValues map[string]interface{}
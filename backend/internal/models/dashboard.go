@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// DashboardTrendDays is the number of days, today included, covered by the
+// dashboard trends
+const DashboardTrendDays = 30
+
+// DashboardStats are the analytics shown on the tenant dashboard
+type DashboardStats struct {
+	// Activity per day (UTC), oldest first, one entry for each of the last
+	// DashboardTrendDays days
+	Activity []DashboardDay `json:"activity"`
+
+	Invitations DashboardInvitations `json:"invitations"`
+
+	// Active sessions per device type: Desktop | Mobile | Tablet | Unknown
+	SessionsByDevice []DashboardDeviceSessions `json:"sessions_by_device"`
+
+	NewUsers DashboardNewUsers `json:"new_users"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// DashboardDay is a day of the dashboard trends
+type DashboardDay struct {
+	Day          time.Time `json:"day" db:"day"`
+	ActiveUsers  int       `json:"active_users" db:"active_users"` // Users with a successful audited action
+	Logins       int       `json:"logins" db:"logins"`
+	FailedLogins int       `json:"failed_logins" db:"failed_logins"`
+}
+
+// DashboardInvitations counts invitations by status. Pending invitations past
+// their expiry count as expired.
+type DashboardInvitations struct {
+	Pending  int `json:"pending" db:"pending"`
+	Accepted int `json:"accepted" db:"accepted"`
+	Expired  int `json:"expired" db:"expired"`
+	Revoked  int `json:"revoked" db:"revoked"`
+
+	AcceptedThisMonth int `json:"accepted_this_month" db:"accepted_this_month"`
+}
+
+// DashboardDeviceSessions counts the active sessions of a device type
+type DashboardDeviceSessions struct {
+	DeviceType string `json:"device_type" db:"device_type"`
+	Sessions   int    `json:"sessions" db:"sessions"`
+}
+
+// DashboardNewUsers counts the users created this calendar month (UTC) and
+// the previous one
+type DashboardNewUsers struct {
+	ThisMonth int `json:"this_month" db:"this_month"`
+	LastMonth int `json:"last_month" db:"last_month"`
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataQualityCheck is a built-in check data-quality rules can enable
+type DataQualityCheck struct {
+	Key         string `json:"key"`
+	Resource    string `json:"resource"` // Module the checked records belong to
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Data-quality check keys
+const (
+	DataQualitySupplierMissingTaxID   = "supplier_missing_tax_id"
+	DataQualitySupplierMissingContact = "supplier_missing_contact"
+	DataQualityCustomerMissingEmail   = "customer_missing_email"
+	DataQualityProductMissingPrice    = "product_missing_price"
+	DataQualityUserMissingDepartment  = "user_missing_department"
+)
+
+// DataQualityChecks are the checks rules can enable
+var DataQualityChecks = []DataQualityCheck{
+	{
+		Key:         DataQualitySupplierMissingTaxID,
+		Resource:    ResourcePurchasing,
+		Name:        "Suppliers without tax ID",
+		Description: "Active suppliers with no tax ID recorded",
+	},
+	{
+		Key:         DataQualitySupplierMissingContact,
+		Resource:    ResourcePurchasing,
+		Name:        "Suppliers without contact details",
+		Description: "Active suppliers with neither an email address nor a phone number",
+	},
+	{
+		Key:         DataQualityCustomerMissingEmail,
+		Resource:    ResourceSales,
+		Name:        "Customers without contact email",
+		Description: "Customers of open quotes, orders and invoices that have no email address on any document",
+	},
+	{
+		Key:         DataQualityProductMissingPrice,
+		Resource:    ResourceSales,
+		Name:        "Products without price",
+		Description: "Product codes on open sales documents that are only ever sold at a unit price of zero",
+	},
+	{
+		Key:         DataQualityUserMissingDepartment,
+		Resource:    ResourceUsers,
+		Name:        "Users without department",
+		Description: "Active users who are not assigned to a department",
+	},
+}
+
+// FindDataQualityCheck returns the check with the given key, or nil
+func FindDataQualityCheck(key string) *DataQualityCheck {
+	for i := range DataQualityChecks {
+		if DataQualityChecks[i].Key == key {
+			return &DataQualityChecks[i]
+		}
+	}
+	return nil
+}
+
+// DataQualityRule enables a check for a tenant. The scheduled run records the
+// check's result on the rule, and stewards (the users with NotifyRoleID) are
+// emailed when the number of issues grows.
+type DataQualityRule struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	CheckKey     string     `json:"check_key" db:"check_key"`
+	Severity     string     `json:"severity" db:"severity"`
+	NotifyRoleID *uuid.UUID `json:"notify_role_id,omitempty" db:"notify_role_id"`
+	IsEnabled    bool       `json:"is_enabled" db:"is_enabled"`
+
+	// Last run
+	LastCheckedAt *time.Time        `json:"last_checked_at,omitempty" db:"last_checked_at"`
+	IssueCount    *int              `json:"issue_count,omitempty" db:"issue_count"` // nil until the rule first ran
+	Samples       DataQualityIssues `json:"samples" db:"samples"`                   // First records with the issue
+
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Computed fields (not in database)
+	Check *DataQualityCheck `json:"check,omitempty" db:"-"`
+}
+
+// DataQualityIssue is a record a check found an issue with
+type DataQualityIssue struct {
+	ResourceID *uuid.UUID `json:"resource_id,omitempty" db:"resource_id"` // nil for records without an ID, e.g. customers
+	Label      string     `json:"label" db:"label"`
+	Path       string     `json:"path,omitempty" db:"path"` // Where to fix it in the app
+}
+
+// DataQualityIssues is the JSONB list of a rule's sample issues
+type DataQualityIssues []DataQualityIssue
+
+// Scan implements sql.Scanner for JSONB columns
+func (s *DataQualityIssues) Scan(value interface{}) error {
+	return scanAutomationJSON(value, s)
+}
+
+// DataQualityReport is a tenant's data-quality report, built from the last
+// run of each enabled rule
+type DataQualityReport struct {
+	GeneratedAt      *time.Time        `json:"generated_at,omitempty"` // Oldest last run of the rules
+	TotalIssues      int               `json:"total_issues"`
+	IssuesBySeverity map[string]int    `json:"issues_by_severity"`
+	Rules            []DataQualityRule `json:"rules"`
+}
+
+// DataQualityAlert is a rule whose run found more issues than the previous
+// one, as reported to data stewards
+type DataQualityAlert struct {
+	Name          string
+	Severity      string
+	IssueCount    int
+	PreviousCount int
+}
+
+// Data-quality rule severities
+const (
+	DataQualitySeverityInfo     = "info"
+	DataQualitySeverityWarning  = "warning"
+	DataQualitySeverityCritical = "critical"
+)
+
+// DataQualitySeverities are the severities a rule can have
+var DataQualitySeverities = []string{
+	DataQualitySeverityInfo,
+	DataQualitySeverityWarning,
+	DataQualitySeverityCritical,
+}
+
+// DataQualityRuleRequest represents a request to create or replace a rule.
+// The check of an existing rule cannot be changed; NotifyRoleID nil means
+// nobody is notified.
+type DataQualityRuleRequest struct {
+	CheckKey     string     `json:"check_key"`
+	Severity     string     `json:"severity,omitempty"` // Defaults to warning
+	NotifyRoleID *uuid.UUID `json:"notify_role_id,omitempty"`
+	IsEnabled    *bool      `json:"is_enabled,omitempty"`
+}
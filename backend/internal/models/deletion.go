@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingDeletion is a delete staged for an undo window. It is executed
+// permanently by the deletion worker once ExecuteAt has passed.
+type PendingDeletion struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id" db:"entity_id"`
+	Label      string    `json:"label" db:"label"`
+
+	// Status: pending | executed | undone | failed
+	Status    string    `json:"status" db:"status"`
+	Error     *string   `json:"error,omitempty" db:"error"`
+	ExecuteAt time.Time `json:"execute_at" db:"execute_at"`
+
+	RequestedBy uuid.UUID  `json:"requested_by" db:"requested_by"`
+	UndoneBy    *uuid.UUID `json:"undone_by,omitempty" db:"undone_by"`
+	UndoneAt    *time.Time `json:"undone_at,omitempty" db:"undone_at"`
+	ExecutedAt  *time.Time `json:"executed_at,omitempty" db:"executed_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Pending deletion status constants
+const (
+	DeletionStatusPending  = "pending"
+	DeletionStatusExecuted = "executed"
+	DeletionStatusUndone   = "undone"
+	DeletionStatusFailed   = "failed"
+)
+
+// Entity types that are deleted through the undo window
+const (
+	DeletionEntityUser          = "user"
+	DeletionEntityRole          = "role"
+	DeletionEntityDepartment    = "department"
+	DeletionEntityEmployee      = "employee"
+	DeletionEntitySalesDocument = "sales_document"
+	DeletionEntityPurchaseOrder = "purchase_order"
+)
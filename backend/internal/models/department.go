@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,8 +9,8 @@ import (
 
 // Department represents an organizational department
 type Department struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	TenantID    uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
 
 	// Basic Info
 	Name        string  `json:"name" db:"name"`
@@ -25,6 +26,9 @@ type Department struct {
 	// Status
 	Status string `json:"status" db:"status"`
 
+	// Values of the tenant's custom fields, by key (see CustomField)
+	CustomFields json.RawMessage `json:"custom_fields" db:"custom_fields"`
+
 	// Metadata
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
@@ -58,11 +62,13 @@ func (d *Department) IsActive() bool {
 
 // DepartmentCreateRequest represents a request to create a new department
 type DepartmentCreateRequest struct {
-	Name        string     `json:"name"`
+	Name        string     `json:"name" validate:"required,min=2,max=255"`
 	Description *string    `json:"description,omitempty"`
 	HeadUserID  *uuid.UUID `json:"head_user_id,omitempty"`
-	Color       string     `json:"color"`
-	Icon        string     `json:"icon"`
+	Color       string     `json:"color" validate:"required"`
+	Icon        string     `json:"icon" validate:"required"`
+
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 // DepartmentUpdateRequest represents a request to update a department
@@ -73,4 +79,13 @@ type DepartmentUpdateRequest struct {
 	Color       *string    `json:"color,omitempty"`
 	Icon        *string    `json:"icon,omitempty"`
 	Status      *string    `json:"status,omitempty"`
+
+	// Custom field values to set; fields left out keep their value
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
+}
+
+// DepartmentFilter narrows a list of departments
+type DepartmentFilter struct {
+	// Custom field values the departments must have, as a JSON object
+	CustomFields json.RawMessage
 }
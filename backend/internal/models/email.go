@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailMessage is a rendered email ready to be queued or sent. Body is the
+// HTML part and Text the plaintext alternative.
+type EmailMessage struct {
+	To       string
+	Subject  string
+	Body     string
+	Text     string
+	Template string
+
+	// Attachments are only delivered when the message is sent directly; the
+	// outbox does not keep them
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// OutboxEmail is an email queued in the transactional outbox
+type OutboxEmail struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TenantID      uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	ToEmail       string     `json:"to_email" db:"to_email"`
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"-" db:"body"`
+	TextBody      *string    `json:"-" db:"text_body"`
+	Template      *string    `json:"template,omitempty" db:"template"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	MaxAttempts   int        `json:"max_attempts" db:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	SentAt        *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Trace context of the request that queued it, if traced
+	TraceContext *json.RawMessage `json:"-" db:"trace_context"`
+}
+
+// Outbox email status constants
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// Email templates
+const (
+	EmailTemplateTenantVerification      = "tenant_verification"
+	EmailTemplatePasswordReset           = "password_reset"
+	EmailTemplateInvitation              = "invitation"
+	EmailTemplateWelcome                 = "welcome"
+	EmailTemplateDeletionScheduled       = "deletion_scheduled"
+	EmailTemplateBroadcast               = "broadcast"
+	EmailTemplateAutomation              = "automation"
+	EmailTemplateApprovalRequest         = "approval_request"
+	EmailTemplateAccountSetup            = "account_setup"
+	EmailTemplateEscalation              = "escalation"
+	EmailTemplateDataQualityAlert        = "data_quality_alert"
+	EmailTemplateQuotaAlert              = "quota_alert"
+	EmailTemplateLeaveRequest            = "leave_request"
+	EmailTemplateLeaveDecision           = "leave_decision"
+	EmailTemplateEmailChange             = "email_change"
+	EmailTemplateTwoFactorBypass         = "two_factor_bypass"
+	EmailTemplateTwoFactorSetupReminder  = "two_factor_setup_reminder"
+	EmailTemplateTenantDeletionConfirm   = "tenant_deletion_confirm"
+	EmailTemplateTenantDeletionScheduled = "tenant_deletion_scheduled"
+	EmailTemplateNotification            = "notification"
+	EmailTemplateNotificationDigest      = "notification_digest"
+	EmailTemplateScheduledReport         = "scheduled_report"
+)
+
+// EmailTemplates lists all email templates
+var EmailTemplates = []string{
+	EmailTemplateTenantVerification,
+	EmailTemplatePasswordReset,
+	EmailTemplateInvitation,
+	EmailTemplateWelcome,
+	EmailTemplateDeletionScheduled,
+	EmailTemplateBroadcast,
+	EmailTemplateAutomation,
+	EmailTemplateApprovalRequest,
+	EmailTemplateAccountSetup,
+	EmailTemplateEscalation,
+	EmailTemplateDataQualityAlert,
+	EmailTemplateQuotaAlert,
+	EmailTemplateLeaveRequest,
+	EmailTemplateLeaveDecision,
+	EmailTemplateEmailChange,
+	EmailTemplateTwoFactorBypass,
+	EmailTemplateTwoFactorSetupReminder,
+	EmailTemplateTenantDeletionConfirm,
+	EmailTemplateTenantDeletionScheduled,
+	EmailTemplateNotification,
+	EmailTemplateNotificationDigest,
+	EmailTemplateScheduledReport,
+}
+
+// EmailTemplatePreview is an email template rendered with sample data
+type EmailTemplatePreview struct {
+	Template string `json:"template"`
+	Language string `json:"language"`
+	Subject  string `json:"subject"`
+	HTML     string `json:"html"`
+	Text     string `json:"text"`
+}
+
+// EmailTemplateList lists the email templates and the languages they are
+// available in
+type EmailTemplateList struct {
+	Templates []string `json:"templates"`
+	Languages []string `json:"languages"`
+}
+
+// EmailQueueStats counts outbox messages per status
+type EmailQueueStats struct {
+	Pending int `json:"pending" db:"pending"`
+	Sent    int `json:"sent" db:"sent"`
+	Failed  int `json:"failed" db:"failed"`
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Employee is the HR record of a person working for the tenant. It may be
+// linked to a user account and reports to another employee (its manager).
+type Employee struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Links
+	UserID       *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	DepartmentID *uuid.UUID `json:"department_id,omitempty" db:"department_id"`
+	ManagerID    *uuid.UUID `json:"manager_id,omitempty" db:"manager_id"`
+
+	// Basic Info
+	EmployeeNumber *string `json:"employee_number,omitempty" db:"employee_number"`
+	FirstName      string  `json:"first_name" db:"first_name"`
+	LastName       string  `json:"last_name" db:"last_name"`
+	Email          *string `json:"email,omitempty" db:"email"`
+	Phone          *string `json:"phone,omitempty" db:"phone"`
+
+	// Employment
+	JobTitle        string     `json:"job_title" db:"job_title"`
+	EmploymentType  string     `json:"employment_type" db:"employment_type"`
+	HireDate        time.Time  `json:"hire_date" db:"hire_date"`
+	TerminationDate *time.Time `json:"termination_date,omitempty" db:"termination_date"`
+	SalaryBand      *string    `json:"salary_band,omitempty" db:"salary_band"` // Requires employees.view_compensation
+
+	// Status
+	Status string `json:"status" db:"status"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	ManagerName       *string `json:"manager_name,omitempty" db:"manager_name"`
+	DepartmentName    *string `json:"department_name,omitempty" db:"department_name"`
+	DirectReportCount int     `json:"direct_report_count" db:"direct_report_count"`
+}
+
+// FullName returns the employee's full name
+func (e *Employee) FullName() string {
+	return e.FirstName + " " + e.LastName
+}
+
+// IsTerminated returns true once the employee has left
+func (e *Employee) IsTerminated() bool {
+	return e.Status == EmployeeStatusTerminated
+}
+
+// Employee status constants
+const (
+	EmployeeStatusActive     = "active"
+	EmployeeStatusOnLeave    = "on_leave"
+	EmployeeStatusTerminated = "terminated"
+)
+
+// Employment type constants
+const (
+	EmploymentTypeFullTime   = "full_time"
+	EmploymentTypePartTime   = "part_time"
+	EmploymentTypeContractor = "contractor"
+	EmploymentTypeIntern     = "intern"
+)
+
+// EmployeeStatuses and EmploymentTypes list the valid values, for validation
+var (
+	EmployeeStatuses = []string{EmployeeStatusActive, EmployeeStatusOnLeave, EmployeeStatusTerminated}
+	EmploymentTypes  = []string{EmploymentTypeFullTime, EmploymentTypePartTime, EmploymentTypeContractor, EmploymentTypeIntern}
+)
+
+// Permission resource constant
+const (
+	ResourceEmployees = "employees"
+)
+
+// Permission actions for employees
+const (
+	ActionViewCompensation = "view_compensation"
+)
+
+// EmployeeFilter filters the employee list
+type EmployeeFilter struct {
+	DepartmentID *uuid.UUID
+	ManagerID    *uuid.UUID
+	Status       string
+	Search       string // Name, email, employee number or job title
+}
+
+// EmployeeCreateRequest represents a request to create an employee. Names and
+// email default to those of the linked user, and the department to the
+// user's department.
+type EmployeeCreateRequest struct {
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	DepartmentID   *uuid.UUID `json:"department_id,omitempty"`
+	ManagerID      *uuid.UUID `json:"manager_id,omitempty"`
+	EmployeeNumber *string    `json:"employee_number,omitempty"`
+	FirstName      string     `json:"first_name" validate:"required_without=UserID,max=100"`
+	LastName       string     `json:"last_name" validate:"required_without=UserID,max=100"`
+	Email          *string    `json:"email,omitempty" validate:"omitempty,email"`
+	Phone          *string    `json:"phone,omitempty"`
+	JobTitle       string     `json:"job_title" validate:"required,max=255"`
+	EmploymentType string     `json:"employment_type"`
+	HireDate       time.Time  `json:"hire_date" validate:"required"`
+	SalaryBand     *string    `json:"salary_band,omitempty"`
+}
+
+// EmployeeUpdateRequest represents a request to update an employee. Setting
+// clear_manager or clear_department removes the link; unlinking a user is
+// done with clear_user.
+type EmployeeUpdateRequest struct {
+	UserID          *uuid.UUID `json:"user_id,omitempty"`
+	ClearUser       bool       `json:"clear_user,omitempty"`
+	DepartmentID    *uuid.UUID `json:"department_id,omitempty"`
+	ClearDepartment bool       `json:"clear_department,omitempty"`
+	ManagerID       *uuid.UUID `json:"manager_id,omitempty"`
+	ClearManager    bool       `json:"clear_manager,omitempty"`
+	EmployeeNumber  *string    `json:"employee_number,omitempty"`
+	FirstName       *string    `json:"first_name,omitempty"`
+	LastName        *string    `json:"last_name,omitempty"`
+	Email           *string    `json:"email,omitempty"`
+	Phone           *string    `json:"phone,omitempty"`
+	JobTitle        *string    `json:"job_title,omitempty"`
+	EmploymentType  *string    `json:"employment_type,omitempty"`
+	HireDate        *time.Time `json:"hire_date,omitempty"`
+	TerminationDate *time.Time `json:"termination_date,omitempty"`
+	SalaryBand      *string    `json:"salary_band,omitempty"`
+	Status          *string    `json:"status,omitempty"`
+}
+
+// OrgChartNode is an employee in the org chart with their direct reports
+type OrgChartNode struct {
+	ID             uuid.UUID      `json:"id"`
+	UserID         *uuid.UUID     `json:"user_id,omitempty"`
+	Name           string         `json:"name"`
+	JobTitle       string         `json:"job_title"`
+	Status         string         `json:"status"`
+	DepartmentID   *uuid.UUID     `json:"department_id,omitempty"`
+	DepartmentName *string        `json:"department_name,omitempty"`
+	Reports        []OrgChartNode `json:"reports"`
+}
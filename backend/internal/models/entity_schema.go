@@ -0,0 +1,44 @@
+package models
+
+// Entity types described by the schema endpoint, besides the navigation
+// entity types
+const (
+	EntityCustomer    = "customer"
+	EntityContact     = "contact"
+	EntityOpportunity = "opportunity"
+)
+
+// Entity schema field types
+const (
+	FieldTypeString   = "string"
+	FieldTypeInteger  = "integer"
+	FieldTypeNumber   = "number"
+	FieldTypeBoolean  = "boolean"
+	FieldTypeUUID     = "uuid"
+	FieldTypeDateTime = "date-time"
+	FieldTypeArray    = "array"
+	FieldTypeObject   = "object"
+)
+
+// EntitySchema describes the fields of an entity type for a tenant, so
+// clients can render forms and columns without hard-coding them. Built-in
+// fields come first; fields a tenant defines are marked custom.
+type EntitySchema struct {
+	Entity   string        `json:"entity"`
+	Resource string        `json:"resource"` // Permission resource guarding the entity
+	Fields   []EntityField `json:"fields"`
+}
+
+// EntityField describes a field of an entity
+type EntityField struct {
+	Name      string   `json:"name"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type"`
+	Format    string   `json:"format,omitempty"` // e.g. email, for string fields
+	Required  bool     `json:"required"`         // Must be given on create
+	ReadOnly  bool     `json:"read_only"`        // Returned but not accepted on create
+	WriteOnly bool     `json:"write_only"`       // Accepted on create but never returned, e.g. password
+	MaxLength int      `json:"max_length,omitempty"`
+	Options   []string `json:"options,omitempty"` // Allowed values
+	Custom    bool     `json:"custom"`            // Defined by the tenant rather than built in; the value is in custom_fields
+}
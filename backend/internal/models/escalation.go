@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// EscalationPolicy escalates approvals of one resource type that stay
+// unanswered: each step notifies someone further up once the approval has
+// been pending for its number of hours
+type EscalationPolicy struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Name         string          `json:"name" db:"name"`
+	Description  *string         `json:"description,omitempty" db:"description"`
+	ResourceType string          `json:"resource_type" db:"resource_type"`
+	Steps        EscalationSteps `json:"steps" db:"steps"`
+	IsEnabled    bool            `json:"is_enabled" db:"is_enabled"`
+
+	// Slack incoming webhook used by steps with the slack channel. Stored
+	// encrypted and never returned.
+	SlackWebhookURL *string `json:"-" db:"slack_webhook_url"`
+	SlackConfigured bool    `json:"slack_configured" db:"slack_configured"`
+
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EscalationStep is one level of a policy. Which parameters apply depends on
+// the target.
+type EscalationStep struct {
+	AfterHours int    `json:"after_hours"` // Hours the approval has been pending
+	Target     string `json:"target"`
+
+	// role: every user with the role is notified
+	RoleID *uuid.UUID `json:"role_id,omitempty"`
+
+	// user: this user is notified
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+
+	Channels []string `json:"channels"`
+}
+
+// EscalationSteps is the JSONB list of a policy's steps, in escalation order
+type EscalationSteps []EscalationStep
+
+// Scan implements sql.Scanner for JSONB columns
+func (s *EscalationSteps) Scan(value interface{}) error {
+	return scanAutomationJSON(value, s)
+}
+
+// Escalation is a step of a policy that fired for a pending approval, kept as
+// the policy's escalation log and shown with the approval
+type Escalation struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	PolicyID uuid.UUID `json:"policy_id" db:"policy_id"`
+
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id" db:"resource_id"`
+	Title        string    `json:"title" db:"title"`
+
+	Step       int            `json:"step" db:"step"` // 1-based position in the policy's steps
+	AfterHours int            `json:"after_hours" db:"after_hours"`
+	Target     string         `json:"target" db:"target"`
+	Channels   pq.StringArray `json:"channels" db:"channels"`
+	Recipients pq.StringArray `json:"recipients" db:"recipients"` // Emails notified
+
+	// Slack: sent | failed, nil when the step does not post to Slack
+	SlackStatus *string `json:"slack_status,omitempty" db:"slack_status"`
+	SlackError  *string `json:"slack_error,omitempty" db:"slack_error"`
+
+	EscalatedAt time.Time `json:"escalated_at" db:"escalated_at"`
+
+	// Joined fields
+	PolicyName string `json:"policy_name,omitempty" db:"policy_name"`
+}
+
+// EscalationItem is a pending approval an escalation source reports to the
+// escalation worker
+type EscalationItem struct {
+	ResourceID   uuid.UUID
+	Title        string
+	Details      string
+	RequestedBy  *uuid.UUID // Whose manager the manager target notifies
+	PendingSince time.Time
+	ReviewURL    string // Path in the app, e.g. /purchasing/invoices/{id}
+}
+
+// Escalation step targets
+const (
+	EscalationTargetManager = "manager" // Head of the requester's department
+	EscalationTargetRole    = "role"
+	EscalationTargetUser    = "user"
+)
+
+// EscalationTargets are the targets a step can notify
+var EscalationTargets = []string{
+	EscalationTargetManager,
+	EscalationTargetRole,
+	EscalationTargetUser,
+}
+
+// Escalation notification channels
+const (
+	EscalationChannelEmail = "email"
+	EscalationChannelSlack = "slack"
+)
+
+// EscalationChannels are the channels a step can notify through
+var EscalationChannels = []string{
+	EscalationChannelEmail,
+	EscalationChannelSlack,
+}
+
+// Escalation Slack post status constants
+const (
+	EscalationSlackSent   = "sent"
+	EscalationSlackFailed = "failed"
+)
+
+// EscalationPolicyRequest represents a request to create or replace a
+// policy. SlackWebhookURL nil keeps the current URL on update; empty removes
+// it.
+type EscalationPolicyRequest struct {
+	Name            string           `json:"name"`
+	Description     string           `json:"description,omitempty"`
+	ResourceType    string           `json:"resource_type"`
+	Steps           []EscalationStep `json:"steps"`
+	SlackWebhookURL *string          `json:"slack_webhook_url,omitempty"`
+	IsEnabled       *bool            `json:"is_enabled,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a change to a tenant's data, published on the event bus once the
+// change has been made. Webhooks and automation rules react to events.
+type Event struct {
+	ID         uuid.UUID
+	Type       string // The audit action of the change, e.g. user.created
+	TenantID   uuid.UUID
+	OccurredAt time.Time
+
+	ResourceType string
+	ResourceID   *uuid.UUID
+	ActorID      *uuid.UUID
+	Object       json.RawMessage // The resource after the change (before it, for deletions)
+	Previous     json.RawMessage // The resource before an update, if known
+}
+
+// EventTypes are the changes published on the event bus. They are the audit
+// actions of the corresponding changes.
+var EventTypes = []string{
+	ActionUserCreated,
+	ActionUserUpdated,
+	ActionUserDeleted,
+	ActionUserRestored,
+	ActionUserStatusChanged,
+	ActionUserRolesAssigned,
+	ActionRoleCreated,
+	ActionRoleUpdated,
+	ActionRoleDeleted,
+	ActionRoleAssigned,
+	ActionDepartmentCreated,
+	ActionDepartmentUpdated,
+	ActionDepartmentDeleted,
+	ActionCRMCustomerCreated,
+	ActionCRMCustomerUpdated,
+	ActionCRMCustomerDeleted,
+	ActionCRMContactCreated,
+	ActionCRMContactUpdated,
+	ActionCRMContactDeleted,
+	ActionCRMOpportunityCreated,
+	ActionCRMOpportunityUpdated,
+	ActionCRMOpportunityDeleted,
+	ActionCRMOpportunityStaged,
+	ActionCRMOwnerAssigned,
+	ActionCRMActivityLogged,
+	ActionInvitationCreated,
+	ActionInvitationAccepted,
+	ActionInvitationRevoked,
+	ActionInvitationResent,
+}
+
+// IsEventType returns true if eventType is published on the event bus
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"mime"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File is an uploaded file. Its contents are stored on the storage backend
+// under StorageKey; the row holds the metadata.
+type File struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Storage
+	StorageBackend string `json:"-" db:"storage_backend"`
+	StorageKey     string `json:"-" db:"storage_key"`
+
+	// Contents
+	OriginalName   string `json:"original_name" db:"original_name"`
+	ContentType    string `json:"content_type" db:"content_type"`
+	SizeBytes      int64  `json:"size_bytes" db:"size_bytes"`
+	ChecksumSHA256 string `json:"checksum_sha256" db:"checksum_sha256"`
+
+	// Usage
+	Category     string     `json:"category" db:"category"`
+	ResourceType *string    `json:"resource_type,omitempty" db:"resource_type"`
+	ResourceID   *uuid.UUID `json:"resource_id,omitempty" db:"resource_id"`
+
+	// Metadata
+	UploadedBy *uuid.UUID `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy  *uuid.UUID `json:"deleted_by,omitempty" db:"deleted_by"`
+
+	// Lifecycle: set from STORAGE_LIFECYCLE for the file's category
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// IsDeleted returns true if the file was deleted and can only be restored
+func (f *File) IsDeleted() bool {
+	return f.DeletedAt != nil
+}
+
+// IsExpired returns true if the file's lifecycle has ended. Expired files
+// can no longer be downloaded and are purged with their contents.
+func (f *File) IsExpired() bool {
+	return f.ExpiresAt != nil && time.Now().After(*f.ExpiresAt)
+}
+
+// ContentDisposition returns the Content-Disposition a download of the file
+// is served with: avatars and logos are shown inline, anything else is saved
+func (f *File) ContentDisposition() string {
+	disposition := "attachment"
+	if f.Category == FileCategoryAvatar || f.Category == FileCategoryLogo {
+		disposition = "inline"
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": f.OriginalName})
+}
+
+// File category constants
+const (
+	FileCategoryAvatar     = "avatar"     // Profile pictures, shown inline and visible to every user
+	FileCategoryAttachment = "attachment" // Documents attached to records, e.g. invoices
+	FileCategoryArtifact   = "artifact"   // Files the server generated, e.g. reports, exports and archives
+	FileCategoryLogo       = "logo"       // Tenant logos, served without authentication so emails can show them
+)
+
+// FileCategories lists the valid file categories, for validation
+var FileCategories = []string{FileCategoryAvatar, FileCategoryAttachment, FileCategoryArtifact, FileCategoryLogo}
+
+// UploadFileCategories lists the categories users may upload files in;
+// artifacts are only stored by the server and logos through the tenant
+// settings
+var UploadFileCategories = []string{FileCategoryAvatar, FileCategoryAttachment}
+
+// Permission resource constant
+const (
+	ResourceFiles = "files"
+)
+
+// FileUpload describes a file being uploaded; the contents are passed
+// separately
+type FileUpload struct {
+	OriginalName string
+	Size         int64
+	Category     string
+	ResourceType *string
+	ResourceID   *uuid.UUID
+}
+
+// FileFilter filters file lists
+type FileFilter struct {
+	Category       string
+	ResourceType   string
+	ResourceID     *uuid.UUID
+	UploadedBy     *uuid.UUID
+	IncludeDeleted bool
+	Limit          int
+	Offset         int
+}
+
+// FileURL is a signed download URL of a file
+type FileURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileResourceUser is the resource type of files attached to a user, such as
+// their avatar
+const FileResourceUser = "user"
+
+// FileResourceTenant is the resource type of files attached to the tenant
+// itself, such as its logo
+const FileResourceTenant = "tenant"
+
+// AvatarSizes are the square sizes, in pixels, uploaded avatars are resized
+// to. The first is the one User.AvatarURL points at.
+var AvatarSizes = []int{256, 128, 64}
+
+// UserAvatar is a user's uploaded avatar in each of AvatarSizes
+type UserAvatar struct {
+	AvatarURL string          `json:"avatar_url"`
+	Files     map[string]File `json:"files"` // Keyed by size, e.g. "64"
+}
@@ -42,6 +42,17 @@ const (
 	InvitationStatusRevoked  = "revoked"
 )
 
+// InvitationStatuses lists the invitation statuses
+var InvitationStatuses = []string{InvitationStatusPending, InvitationStatusAccepted, InvitationStatusExpired, InvitationStatusRevoked}
+
+// InvitationFilter narrows a list of invitations
+type InvitationFilter struct {
+	Status      string
+	InvitedBy   *uuid.UUID
+	InvitedFrom *time.Time
+	InvitedTo   *time.Time
+}
+
 // IsExpired returns true if the invitation has expired
 func (i *Invitation) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveType is a kind of leave a tenant offers (annual leave, sick leave, ...)
+// with its allowance policy
+type LeaveType struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Basic Info
+	Code        string  `json:"code" db:"code"`
+	Name        string  `json:"name" db:"name"`
+	Description *string `json:"description,omitempty" db:"description"`
+	Color       *string `json:"color,omitempty" db:"color"`
+
+	// Policy
+	Paid             bool    `json:"paid" db:"paid"`
+	RequiresApproval bool    `json:"requires_approval" db:"requires_approval"`
+	TracksBalance    bool    `json:"tracks_balance" db:"tracks_balance"`     // False: requests are not limited by a balance
+	AnnualAllowance  float64 `json:"annual_allowance" db:"annual_allowance"` // Days per year, accrued monthly
+	MaxCarryover     float64 `json:"max_carryover" db:"max_carryover"`       // Unused days that move to the next year
+
+	// Status
+	IsActive bool `json:"is_active" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// LeaveBalance is an employee's days of a leave type in a calendar year
+type LeaveBalance struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TenantID    uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	EmployeeID  uuid.UUID `json:"employee_id" db:"employee_id"`
+	LeaveTypeID uuid.UUID `json:"leave_type_id" db:"leave_type_id"`
+	Year        int       `json:"year" db:"year"`
+
+	// Days
+	Accrued     float64 `json:"accrued" db:"accrued"`
+	CarriedOver float64 `json:"carried_over" db:"carried_over"`
+	Adjustment  float64 `json:"adjustment" db:"adjustment"`
+	Used        float64 `json:"used" db:"used"`
+	Pending     float64 `json:"pending" db:"pending"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Computed fields (not in database)
+	Available     float64 `json:"available" db:"-"`
+	LeaveTypeCode string  `json:"leave_type_code,omitempty" db:"leave_type_code"`
+	LeaveTypeName string  `json:"leave_type_name,omitempty" db:"leave_type_name"`
+	TracksBalance bool    `json:"tracks_balance" db:"tracks_balance"`
+}
+
+// Remaining returns the days left to request: granted days minus used and
+// pending ones
+func (b *LeaveBalance) Remaining() float64 {
+	return b.CarriedOver + b.Accrued + b.Adjustment - b.Used - b.Pending
+}
+
+// LeaveRequest is an employee's request for leave over a period
+type LeaveRequest struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	TenantID    uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	EmployeeID  uuid.UUID `json:"employee_id" db:"employee_id"`
+	LeaveTypeID uuid.UUID `json:"leave_type_id" db:"leave_type_id"`
+
+	// Period
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	HalfDay   bool      `json:"half_day" db:"half_day"`
+	Days      float64   `json:"days" db:"days"` // Working days, Monday to Friday
+	Reason    *string   `json:"reason,omitempty" db:"reason"`
+
+	// Workflow
+	Status       string     `json:"status" db:"status"`
+	ApproverID   *uuid.UUID `json:"approver_id,omitempty" db:"approver_id"` // User of the employee's manager
+	DecidedBy    *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	DecisionNote *string    `json:"decision_note,omitempty" db:"decision_note"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+
+	// Metadata
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	RequestedBy *uuid.UUID `json:"requested_by,omitempty" db:"requested_by"`
+
+	// Computed fields (not in database)
+	EmployeeName   string     `json:"employee_name" db:"employee_name"`
+	EmployeeUserID *uuid.UUID `json:"employee_user_id,omitempty" db:"employee_user_id"`
+	LeaveTypeName  string     `json:"leave_type_name" db:"leave_type_name"`
+	LeaveTypeColor *string    `json:"leave_type_color,omitempty" db:"leave_type_color"`
+}
+
+// IsPending returns true while the request awaits a decision
+func (r *LeaveRequest) IsPending() bool {
+	return r.Status == LeaveStatusPending
+}
+
+// Leave request status constants
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// LeaveStatuses lists the valid request statuses, for validation
+var LeaveStatuses = []string{LeaveStatusPending, LeaveStatusApproved, LeaveStatusRejected, LeaveStatusCancelled}
+
+// Permission resource constant
+const (
+	ResourceLeave = "leave"
+)
+
+// Permission actions for leave. Employees request their own leave and
+// managers decide for their direct reports without any permission.
+const (
+	ActionManageLeave = "manage" // Leave types, balance adjustments, leave recorded for others
+)
+
+// LeaveRequestFilter filters leave request lists
+type LeaveRequestFilter struct {
+	EmployeeID   *uuid.UUID
+	DepartmentID *uuid.UUID
+	ApproverID   *uuid.UUID // Requests routed to this user or to the employee's manager's user
+	Status       string
+	From         *time.Time // Requests ending on or after
+	To           *time.Time // Requests starting on or before
+}
+
+// LeaveTypeRequest represents a request to create or update a leave type.
+// On update, omitted fields are left unchanged.
+type LeaveTypeRequest struct {
+	Code             *string  `json:"code,omitempty"`
+	Name             *string  `json:"name,omitempty"`
+	Description      *string  `json:"description,omitempty"`
+	Color            *string  `json:"color,omitempty"`
+	Paid             *bool    `json:"paid,omitempty"`
+	RequiresApproval *bool    `json:"requires_approval,omitempty"`
+	TracksBalance    *bool    `json:"tracks_balance,omitempty"`
+	AnnualAllowance  *float64 `json:"annual_allowance,omitempty"`
+	MaxCarryover     *float64 `json:"max_carryover,omitempty"`
+	IsActive         *bool    `json:"is_active,omitempty"`
+}
+
+// LeaveRequestCreateRequest represents a request for leave. EmployeeID is
+// only set when recording leave for someone else (leave.manage).
+type LeaveRequestCreateRequest struct {
+	EmployeeID  *uuid.UUID `json:"employee_id,omitempty"`
+	LeaveTypeID uuid.UUID  `json:"leave_type_id"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+	HalfDay     bool       `json:"half_day,omitempty"`
+	Reason      *string    `json:"reason,omitempty"`
+}
+
+// LeaveDecisionRequest represents an approval or rejection of a leave request
+type LeaveDecisionRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// LeaveBalanceAdjustRequest sets the manual adjustment of a balance
+type LeaveBalanceAdjustRequest struct {
+	EmployeeID  uuid.UUID `json:"employee_id"`
+	LeaveTypeID uuid.UUID `json:"leave_type_id"`
+	Year        int       `json:"year"`
+	Adjustment  float64   `json:"adjustment"`
+}
+
+// LeaveCalendarEntry is approved or pending leave shown on the team calendar.
+// Reasons are not included.
+type LeaveCalendarEntry struct {
+	RequestID      uuid.UUID  `json:"request_id" db:"id"`
+	EmployeeID     uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName   string     `json:"employee_name" db:"employee_name"`
+	DepartmentID   *uuid.UUID `json:"department_id,omitempty" db:"department_id"`
+	LeaveTypeName  string     `json:"leave_type_name" db:"leave_type_name"`
+	LeaveTypeColor *string    `json:"leave_type_color,omitempty" db:"leave_type_color"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        time.Time  `json:"end_date" db:"end_date"`
+	HalfDay        bool       `json:"half_day" db:"half_day"`
+	Status         string     `json:"status" db:"status"`
+}
+
+// LeaveCalendarFilter filters the leave calendar
+type LeaveCalendarFilter struct {
+	From           time.Time
+	To             time.Time
+	DepartmentID   *uuid.UUID
+	IncludePending bool
+	// Restricts the calendar to these employees' colleagues: their
+	// department, direct reports and themselves (users without leave.view)
+	VisibleTo *uuid.UUID
+}
+
+// LeaveAccrualTarget is an employee and a balance-tracking leave type whose
+// yearly balance the leave_accrual job refreshes
+type LeaveAccrualTarget struct {
+	TenantID        uuid.UUID  `db:"tenant_id"`
+	EmployeeID      uuid.UUID  `db:"employee_id"`
+	HireDate        time.Time  `db:"hire_date"`
+	TerminationDate *time.Time `db:"termination_date"`
+	LeaveTypeID     uuid.UUID  `db:"leave_type_id"`
+	AnnualAllowance float64    `db:"annual_allowance"`
+	MaxCarryover    float64    `db:"max_carryover"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// MaintenanceWindow is a period during which a tenant's API is frozen, e.g.
+// to close the accounting year safely. Reads continue; changes are refused
+// with a Retry-After until the window ends, except on the allowed paths, and
+// background jobs that change the tenant's data are paused.
+type MaintenanceWindow struct {
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Message      string    `json:"message,omitempty"`
+	AllowedPaths []string  `json:"allowed_paths"` // API path prefixes that accept changes during the window, e.g. /accounting/periods
+	Active       bool      `json:"active"`        // The window has started
+}
+
+// MaintenanceWindowRequest represents a request to schedule a tenant's
+// maintenance window, replacing any scheduled one
+type MaintenanceWindowRequest struct {
+	StartsAt     time.Time `json:"starts_at" validate:"required"`
+	EndsAt       time.Time `json:"ends_at" validate:"required"`
+	Message      string    `json:"message,omitempty" validate:"omitempty,max=1000"`
+	AllowedPaths []string  `json:"allowed_paths,omitempty"`
+}
+
+// Maintenance audit actions
+const (
+	ActionMaintenanceScheduled = "maintenance.scheduled"
+	ActionMaintenanceCancelled = "maintenance.cancelled"
+)
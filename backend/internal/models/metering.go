@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metered usage metrics
+const (
+	MeterAPICalls     = "api_calls"     // Authenticated API requests
+	MeterActiveUsers  = "active_users"  // Distinct users who made API requests
+	MeterStorageBytes = "storage_bytes" // Bytes of tenant data, as last measured
+	MeterEmailsSent   = "emails_sent"   // Emails handed to the email provider
+)
+
+// MeterMetrics are the metered usage metrics
+var MeterMetrics = []string{MeterAPICalls, MeterActiveUsers, MeterStorageBytes, MeterEmailsSent}
+
+// TenantUsage is a tenant's metered usage of a metric over a UTC day
+type TenantUsage struct {
+	TenantID uuid.UUID `json:"-" db:"tenant_id"`
+	Day      time.Time `json:"day" db:"day"`
+	Metric   string    `json:"metric" db:"metric"`
+	Value    int64     `json:"value" db:"value"`
+}
+
+// TenantUsageDay is a tenant's metered usage of each metric over a UTC day
+type TenantUsageDay struct {
+	Day    string           `json:"day"` // YYYY-MM-DD
+	Values map[string]int64 `json:"values"`
+}
+
+// TenantUsageReport is a tenant's metered usage over a calendar month (UTC).
+// Totals add up API calls and emails, and hold the busiest day's active
+// users and the last storage measured.
+type TenantUsageReport struct {
+	Month  string           `json:"month"` // YYYY-MM
+	Totals map[string]int64 `json:"totals"`
+	Daily  []TenantUsageDay `json:"daily"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types tracked for quick navigation
+const (
+	NavigationEntityUser            = "user"
+	NavigationEntityRole            = "role"
+	NavigationEntityDepartment      = "department"
+	NavigationEntityEmployee        = "employee"
+	NavigationEntitySalesDocument   = "sales_document"
+	NavigationEntitySupplier        = "supplier"
+	NavigationEntityPurchaseOrder   = "purchase_order"
+	NavigationEntitySupplierInvoice = "supplier_invoice"
+	NavigationEntityAccount         = "account"
+	NavigationEntityJournalEntry    = "journal_entry"
+)
+
+// EntitySummary is how recently viewed entities, favorites and watched
+// records are shown
+type EntitySummary struct {
+	Title    string `json:"title"`              // e.g. "QT-2026-0042"
+	Subtitle string `json:"subtitle,omitempty"` // e.g. the customer's name
+	Path     string `json:"path"`               // Path in the app, e.g. /sales/quotes/{id}
+}
+
+// RecentView is an entity a user viewed recently
+type RecentView struct {
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id" db:"entity_id"`
+	ViewedAt   time.Time `json:"viewed_at" db:"viewed_at"`
+
+	// Computed fields
+	Entity *EntitySummary `json:"entity,omitempty" db:"-"`
+}
+
+// Favorite is an entity a user pinned for quick access
+type Favorite struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id" db:"entity_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	// Computed fields
+	Entity *EntitySummary `json:"entity,omitempty" db:"-"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an in-app notification of a user
+type Notification struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+
+	Type  string          `json:"type" db:"type"`
+	Title string          `json:"title" db:"title"`
+	Body  string          `json:"body" db:"body"`
+	Link  *string         `json:"link,omitempty" db:"link"` // Frontend path
+	Data  json.RawMessage `json:"data" db:"data"`           // Type-specific details
+
+	ReadAt *time.Time `json:"read_at,omitempty" db:"read_at"`
+
+	// Delivery, from the user's preferences for the category
+	InApp         bool `json:"-" db:"in_app"`         // Shown in the app; false when only emailed
+	DigestPending bool `json:"-" db:"digest_pending"` // To include in the next digest email
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsRead returns true once the user has read the notification
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// Notification types
+const (
+	NotificationTypeInvitationAccepted   = "invitation.accepted"   // To the user who sent the invitation
+	NotificationTypeRolesChanged         = "user.roles_changed"    // To the user whose roles changed
+	NotificationTypeQuotaAlert           = "quota.alert"           // To the tenant's owners
+	NotificationTypeRecordUpdated        = "record.updated"        // To the watchers of a record someone else changed
+	NotificationTypeRecordCommented      = "record.commented"      // To the watchers of a record someone else logged an activity on
+	NotificationTypeRecordDeleted        = "record.deleted"        // To the watchers of a record someone else deleted
+	NotificationTypeLeaveRequested       = "leave.requested"       // To whoever decides on the request
+	NotificationTypeLeaveDecided         = "leave.decided"         // To the employee who requested leave
+	NotificationTypeLeaveCancelled       = "leave.cancelled"       // To its approver, and to the employee if someone else cancelled
+	NotificationTypeTimesheetSubmitted   = "timesheet.submitted"   // To whoever decides on the timesheet
+	NotificationTypeTimesheetDecided     = "timesheet.decided"     // To the employee whose timesheet was approved, rejected or reopened
+	NotificationTypeCRMAssigned          = "crm.assigned"          // To the new owner of a customer, contact or opportunity
+	NotificationTypeCRMTaskAssigned      = "crm.task_assigned"     // To the owner of a CRM task someone else created
+	NotificationTypeMaintenanceScheduled = "maintenance.scheduled" // To every active user when a maintenance window is scheduled
+	NotificationTypeAnnouncement         = "announcement"          // To the audience of an announcement once it is published
+)
+
+// NotificationRequest is what a service notifies users of
+type NotificationRequest struct {
+	Type  string
+	Title string
+	Body  string
+	Link  string      // Optional frontend path
+	Data  interface{} // Optional type-specific details, encoded as JSON
+}
+
+// NotificationReadRequest marks notifications read
+type NotificationReadRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification categories users choose channels for
+const (
+	NotificationCategorySecurity    = "security"    // Changes to the user's access, emailed right away
+	NotificationCategoryInvitations = "invitations" // Invitations the user sent were accepted
+	NotificationCategoryMentions    = "mentions"    // Something was assigned to the user
+	NotificationCategoryApprovals   = "approvals"   // Leave and timesheets to decide on, and decisions
+	NotificationCategorySystem      = "system"      // Workspace notices: quota alerts, maintenance, announcements
+	NotificationCategoryWatching    = "watching"    // Changes to records the user watches
+)
+
+// NotificationCategories lists the notification categories
+var NotificationCategories = []string{
+	NotificationCategorySecurity,
+	NotificationCategoryInvitations,
+	NotificationCategoryMentions,
+	NotificationCategoryApprovals,
+	NotificationCategorySystem,
+	NotificationCategoryWatching,
+}
+
+// notificationTypeCategories maps notification types to their category;
+// types not listed are system notices
+var notificationTypeCategories = map[string]string{
+	NotificationTypeRolesChanged:         NotificationCategorySecurity,
+	NotificationTypeInvitationAccepted:   NotificationCategoryInvitations,
+	NotificationTypeCRMAssigned:          NotificationCategoryMentions,
+	NotificationTypeCRMTaskAssigned:      NotificationCategoryMentions,
+	NotificationTypeLeaveRequested:       NotificationCategoryApprovals,
+	NotificationTypeLeaveDecided:         NotificationCategoryApprovals,
+	NotificationTypeLeaveCancelled:       NotificationCategoryApprovals,
+	NotificationTypeTimesheetSubmitted:   NotificationCategoryApprovals,
+	NotificationTypeTimesheetDecided:     NotificationCategoryApprovals,
+	NotificationTypeQuotaAlert:           NotificationCategorySystem,
+	NotificationTypeMaintenanceScheduled: NotificationCategorySystem,
+	NotificationTypeAnnouncement:         NotificationCategorySystem,
+	NotificationTypeRecordUpdated:        NotificationCategoryWatching,
+	NotificationTypeRecordCommented:      NotificationCategoryWatching,
+	NotificationTypeRecordDeleted:        NotificationCategoryWatching,
+}
+
+// NotificationCategoryOf returns the category of a notification type
+func NotificationCategoryOf(notificationType string) string {
+	if category, ok := notificationTypeCategories[notificationType]; ok {
+		return category
+	}
+	return NotificationCategorySystem
+}
+
+// IsUrgentNotificationCategory reports whether a category's emails are sent
+// right away instead of in the digest
+func IsUrgentNotificationCategory(category string) bool {
+	return category == NotificationCategorySecurity
+}
+
+// Digest frequencies
+const (
+	NotificationDigestOff    = "off"
+	NotificationDigestDaily  = "daily"
+	NotificationDigestWeekly = "weekly"
+)
+
+// NotificationDigests lists the digest frequencies
+var NotificationDigests = []string{NotificationDigestOff, NotificationDigestDaily, NotificationDigestWeekly}
+
+// NotificationChannels are the channels a user gets a category on
+type NotificationChannels struct {
+	InApp bool `json:"in_app"`
+	Email bool `json:"email"`
+}
+
+// NotificationCategoryChannels is the JSONB map of categories to channels
+type NotificationCategoryChannels map[string]NotificationChannels
+
+// Scan implements sql.Scanner for JSONB columns
+func (c *NotificationCategoryChannels) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported notification channels type: %T", value)
+	}
+
+	return json.Unmarshal(data, c)
+}
+
+// NotificationPreferences are a user's notification channels per category
+// and digest frequency
+type NotificationPreferences struct {
+	TenantID uuid.UUID `json:"-" db:"tenant_id"`
+	UserID   uuid.UUID `json:"-" db:"user_id"`
+
+	Channels     NotificationCategoryChannels `json:"channels" db:"channels"`
+	Digest       string                       `json:"digest" db:"digest"`
+	LastDigestAt *time.Time                   `json:"last_digest_at,omitempty" db:"last_digest_at"`
+
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// DefaultNotificationPreferences are the preferences of a user who never
+// changed them: every category in the app and by email, with the given
+// digest frequency
+func DefaultNotificationPreferences(tenantID, userID uuid.UUID, digest string) *NotificationPreferences {
+	prefs := &NotificationPreferences{
+		TenantID: tenantID,
+		UserID:   userID,
+		Channels: NotificationCategoryChannels{},
+		Digest:   digest,
+	}
+	prefs.FillDefaults()
+	return prefs
+}
+
+// FillDefaults adds the default channels of categories missing from the
+// preferences, e.g. categories added after the user saved them
+func (p *NotificationPreferences) FillDefaults() {
+	if p.Channels == nil {
+		p.Channels = NotificationCategoryChannels{}
+	}
+	for _, category := range NotificationCategories {
+		if _, ok := p.Channels[category]; !ok {
+			p.Channels[category] = NotificationChannels{InApp: true, Email: true}
+		}
+	}
+}
+
+// ChannelsFor returns the channels the user gets a category on
+func (p *NotificationPreferences) ChannelsFor(category string) NotificationChannels {
+	p.FillDefaults()
+	return p.Channels[category]
+}
+
+// DigestPeriod returns how often the user gets a digest, 0 when never
+func (p *NotificationPreferences) DigestPeriod() time.Duration {
+	switch p.Digest {
+	case NotificationDigestDaily:
+		return 24 * time.Hour
+	case NotificationDigestWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// NotificationPreferencesUpdateRequest changes a user's notification
+// preferences. Categories left out keep their channels.
+type NotificationPreferencesUpdateRequest struct {
+	Channels map[string]NotificationChannels `json:"channels"`
+	Digest   *string                         `json:"digest"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ObjectACL restricts access to a single record on top of the resource/action
+// permissions. When Restricted, only the owner, the grantees and tenant
+// owners and administrators may access the record, and each of them still
+// needs the resource permission of the action. Records without an ACL are
+// not restricted.
+type ObjectACL struct {
+	TenantID     uuid.UUID  `json:"-" db:"tenant_id"`
+	ResourceType string     `json:"resource_type" db:"resource_type"`
+	ResourceID   uuid.UUID  `json:"resource_id" db:"resource_id"`
+	OwnerID      *uuid.UUID `json:"owner_id,omitempty" db:"owner_id"`
+	Restricted   bool       `json:"restricted" db:"restricted"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+
+	Grants []ObjectGrant `json:"grants" db:"-"`
+}
+
+// ObjectGrant gives a user, or every holder of a role, an action on a record
+type ObjectGrant struct {
+	ID     uuid.UUID  `json:"id" db:"id"`
+	UserID *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	RoleID *uuid.UUID `json:"role_id,omitempty" db:"role_id"`
+	Action string     `json:"action" db:"action"` // view | edit | delete | *
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// Covers returns true if the grant allows the action
+func (g *ObjectGrant) Covers(action string) bool {
+	return g.Action == action || g.Action == ActionAll
+}
+
+// ObjectGrantActions are the actions records can be granted
+var ObjectGrantActions = []string{ActionView, ActionEdit, ActionDelete, ActionAll}
+
+// ObjectACLUpdateRequest represents a request to replace a record's ACL.
+// Without an owner, the current owner is kept, or the requester becomes it.
+type ObjectACLUpdateRequest struct {
+	OwnerID    *uuid.UUID           `json:"owner_id,omitempty"`
+	Restricted bool                 `json:"restricted"`
+	Grants     []ObjectGrantRequest `json:"grants"`
+}
+
+// ObjectGrantRequest is a grant of an ACL update: either a user or a role
+type ObjectGrantRequest struct {
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+	RoleID *uuid.UUID `json:"role_id,omitempty"`
+	Action string     `json:"action"`
+}
+
+// Object ACL audit actions
+const (
+	ActionObjectACLUpdated = "permission.object_acl_updated"
+)
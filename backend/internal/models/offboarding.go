@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobTypeTenantExport is the async job type exporting all of a tenant's data
+// as an archive
+const JobTypeTenantExport = "tenant_export"
+
+// FileResourceTenantExport is the resource type of stored tenant exports
+const FileResourceTenantExport = "tenant_export"
+
+// TenantExport is the result of a tenant export job: the stored archive and
+// the number of records of each file in it
+type TenantExport struct {
+	File        File           `json:"file"`
+	DownloadURL string         `json:"download_url"`
+	Records     map[string]int `json:"records"`
+}
+
+// Tenant deletion status
+const (
+	TenantDeletionPendingConfirmation = "pending_confirmation" // Awaiting the emailed confirmation
+	TenantDeletionScheduled           = "scheduled"            // Confirmed; carried out at ScheduledAt unless canceled
+)
+
+// TenantDeletion is a tenant's requested deletion
+type TenantDeletion struct {
+	Status      string     `json:"status"` // pending_confirmation | scheduled
+	RequestedAt time.Time  `json:"requested_at"`
+	RequestedBy *uuid.UUID `json:"requested_by,omitempty"`
+	ExpiresAt   *time.Time `json:"confirmation_expires_at,omitempty"` // Pending confirmation only
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`            // Scheduled only
+}
+
+// TenantDeletionRequest asks to delete the tenant. The owner's password is
+// required again.
+type TenantDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+// TenantDeletionConfirmRequest confirms a deletion with the emailed token
+type TenantDeletionConfirmRequest struct {
+	Token string `json:"token"`
+}
+
+// Offboarding audit actions
+const (
+	ActionTenantExportRequested   = "tenant.export_requested"
+	ActionTenantDeletionRequested = "tenant.deletion_requested"
+	ActionTenantDeletionScheduled = "tenant.deletion_scheduled"
+	ActionTenantDeletionCanceled  = "tenant.deletion_canceled"
+)
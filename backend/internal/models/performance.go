@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// EndpointStat is an endpoint's request cost over the report window, across
+// all replicas. Layer figures are averages per request.
+type EndpointStat struct {
+	Endpoint   string  `json:"endpoint" db:"name"` // Method and route pattern, e.g. "GET /users/{id}"
+	Count      int64   `json:"count" db:"count"`
+	ErrorCount int64   `json:"error_count" db:"error_count"` // 5xx responses
+	TotalMs    float64 `json:"total_ms" db:"total_ms"`
+	AvgMs      float64 `json:"avg_ms" db:"avg_ms"`
+	MaxMs      float64 `json:"max_ms" db:"max_ms"`
+
+	// Average time and calls per request in each layer
+	AvgDBMs          float64 `json:"avg_db_ms" db:"avg_db_ms"`
+	AvgDBCalls       float64 `json:"avg_db_calls" db:"avg_db_calls"`
+	AvgRedisMs       float64 `json:"avg_redis_ms" db:"avg_redis_ms"`
+	AvgRedisCalls    float64 `json:"avg_redis_calls" db:"avg_redis_calls"`
+	AvgExternalMs    float64 `json:"avg_external_ms" db:"avg_external_ms"`
+	AvgExternalCalls float64 `json:"avg_external_calls" db:"avg_external_calls"`
+}
+
+// QueryStat is a query from the slow-query log over the report window. Only
+// runs slower than METRICS_SLOW_QUERY_THRESHOLD are counted.
+type QueryStat struct {
+	Query      string  `json:"query" db:"name"` // Literals replaced by ?
+	Count      int64   `json:"count" db:"count"`
+	ErrorCount int64   `json:"error_count" db:"error_count"`
+	TotalMs    float64 `json:"total_ms" db:"total_ms"`
+	AvgMs      float64 `json:"avg_ms" db:"avg_ms"`
+	MaxMs      float64 `json:"max_ms" db:"max_ms"`
+}
+
+// PerformanceReport ranks the slowest endpoints and queries since a time
+type PerformanceReport struct {
+	Since     time.Time      `json:"since"`
+	Sort      string         `json:"sort"`
+	Endpoints []EndpointStat `json:"endpoints"`
+	Queries   []QueryStat    `json:"queries"`
+}
+
+// Performance report sort orders
+const (
+	PerformanceSortTotal = "total" // Cumulative time: where optimizing saves the most
+	PerformanceSortAvg   = "avg"
+	PerformanceSortMax   = "max"
+)
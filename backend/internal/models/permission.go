@@ -15,8 +15,17 @@ type Permission struct {
 	Description *string   `json:"description,omitempty" db:"description"`
 	Category    *string   `json:"category,omitempty" db:"category"` // For UI grouping
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// Effect is set on a role's permissions only: allow or deny
+	Effect string `json:"effect,omitempty" db:"effect"`
 }
 
+// Permission effects. A matching deny overrides every allow.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
 // Permission categories
 const (
 	CategoryUserManagement = "User Management"
@@ -25,12 +34,14 @@ const (
 	CategorySecurity       = "Security"
 )
 
-// Common resources
+// Common resources. The authoritative list lives in the permission_resources
+// registry table; these constants only name the core resources used in code.
 const (
 	ResourceUsers    = "users"
 	ResourceRoles    = "roles"
 	ResourceSettings = "settings"
 	ResourceSecurity = "security"
+	ResourceAll      = "*" // Wildcard: matches every resource
 )
 
 // Common actions
@@ -45,6 +56,7 @@ const (
 	ActionViewLogs      = "view_logs"
 	ActionViewSessions  = "view_sessions"
 	ActionManageSessions = "manage_sessions"
+	ActionExport        = "export"
 )
 
 // String returns the permission in resource.action format
@@ -52,21 +64,68 @@ func (p *Permission) String() string {
 	return p.Resource + "." + p.Action
 }
 
-// IsWildcard returns true if this is a wildcard permission (action = *)
+// IsWildcard returns true if this is a wildcard permission (resource or action = *)
 func (p *Permission) IsWildcard() bool {
-	return p.Action == ActionAll
+	return p.Resource == ResourceAll || p.Action == ActionAll
 }
 
-// Matches checks if this permission matches the given resource and action
+// IsDeny returns true if this permission denies rather than allows
+func (p *Permission) IsDeny() bool {
+	return p.Effect == EffectDeny
+}
+
+// Matches checks if this permission matches the given resource and action,
+// regardless of its effect
 func (p *Permission) Matches(resource, action string) bool {
-	if p.Resource != resource {
+	if p.Resource != resource && p.Resource != ResourceAll {
 		return false
 	}
 	return p.Action == action || p.Action == ActionAll
 }
 
+// PermissionsAllow checks if a permission set allows an action on a resource.
+// Any matching deny overrides every matching allow, however specific, so the
+// result does not depend on the order of the set.
+func PermissionsAllow(permissions []Permission, resource, action string) bool {
+	allowed := false
+	for i := range permissions {
+		if !permissions[i].Matches(resource, action) {
+			continue
+		}
+		if permissions[i].IsDeny() {
+			return false
+		}
+		allowed = true
+	}
+	return allowed
+}
+
 // PermissionGroup represents a group of permissions for UI display
 type PermissionGroup struct {
 	Category    string       `json:"category"`
 	Permissions []Permission `json:"permissions"`
 }
+
+// PermissionModule is an ERP module in the RBAC registry
+type PermissionModule struct {
+	Key         string               `json:"key" db:"key"`
+	DisplayName string               `json:"display_name" db:"display_name"`
+	Description *string              `json:"description,omitempty" db:"description"`
+	Icon        *string              `json:"icon,omitempty" db:"icon"`
+	SortOrder   int                  `json:"sort_order" db:"sort_order"`
+	CreatedAt   time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at" db:"updated_at"`
+	Resources   []PermissionResource `json:"resources" db:"-"`
+}
+
+// PermissionResource is a resource registered by a module, with its actions
+type PermissionResource struct {
+	Resource    string       `json:"resource" db:"resource"`
+	ModuleKey   string       `json:"module_key" db:"module_key"`
+	DisplayName string       `json:"display_name" db:"display_name"`
+	Description *string      `json:"description,omitempty" db:"description"`
+	SortOrder   int          `json:"sort_order" db:"sort_order"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	Actions     []Permission `json:"actions" db:"-"`
+}
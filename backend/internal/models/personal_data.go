@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// PersonalDataExport is all the personal data held about a user, as returned
+// to the user themselves
+type PersonalDataExport struct {
+	GeneratedAt             time.Time                 `json:"generated_at"`
+	Profile                 *User                     `json:"profile"` // With roles
+	Employee                *Employee                 `json:"employee,omitempty"`
+	Sessions                []Session                 `json:"sessions"`
+	SecurityKeys            []WebAuthnCredential      `json:"security_keys"`
+	SSOIdentities           []SSOIdentity             `json:"sso_identities"`
+	Notifications           []Notification            `json:"notifications"`
+	NotificationPreferences []NotificationPreferences `json:"notification_preferences"` // Only when changed from the defaults
+	AnnouncementReads       []AnnouncementReceipt     `json:"announcement_reads"`
+	Favorites               []Favorite                `json:"favorites"`
+	RecentViews             []RecentView              `json:"recent_views"`
+	Files                   []File                    `json:"files"`      // Uploaded by the user; metadata only
+	AuditLogs               []AuditLog                `json:"audit_logs"` // Actions performed by the user
+}
+
+// PersonalDataErasureRequest asks to erase the requesting user's personal
+// data. The user's password is required again.
+//
+// Erasure anonymizes the user rather than deleting them: their name and
+// email are replaced by placeholders, their sign-in methods, sessions and
+// personal records are removed, and IP addresses and emails are scrubbed from
+// the audit logs. Their ID stays, so audit logs and the created_by of the
+// records they created still point at a (now anonymous) user.
+type PersonalDataErasureRequest struct {
+	Password string `json:"password"`
+}
+
+// Personal data audit actions
+const (
+	ActionUserDataExported = "user.data_exported"
+	ActionUserErased       = "user.erased"
+)
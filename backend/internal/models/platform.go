@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlatformAdmin is a user granted administration of the platform: managing
+// tenants across it through /admin/tenants. Granted with cmd/platform-admin.
+type PlatformAdmin struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	TenantID  uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PlatformTenantFilter filters the tenants listed to platform admins
+type PlatformTenantFilter struct {
+	Status   string
+	PlanTier string
+	Search   string // Slug, company name or email
+}
+
+// PlatformTenantHealth is a tenant's status as seen by platform admins: its
+// plan and usage, unresolved quota alerts and email delivery
+type PlatformTenantHealth struct {
+	Tenant      *Tenant          `json:"tenant"`
+	ReadOnly    bool             `json:"read_only"`
+	Billing     *BillingStatus   `json:"billing"`
+	QuotaAlerts []QuotaAlert     `json:"quota_alerts"`
+	EmailQueue  *EmailQueueStats `json:"email_queue"`
+}
+
+// PlatformStats are statistics across all tenants of the platform
+type PlatformStats struct {
+	Tenants            int              `json:"tenants"`
+	TenantsByStatus    map[string]int   `json:"tenants_by_status"`
+	TenantsByPlan      map[string]int   `json:"tenants_by_plan"`
+	SignupsLast30d     int              `json:"signups_last_30d"`
+	Users              int              `json:"users"`                 // Not deleted, across data regions
+	ActiveUsersLast30d int              `json:"active_users_last_30d"` // Signed in during the last 30 days
+	Usage              map[string]int64 `json:"usage"`                 // Metered this calendar month, all tenants
+}
+
+// PlatformSuspendRequest makes a tenant read-only
+type PlatformSuspendRequest struct {
+	Reason string `json:"reason"` // payment_overdue | terms_violation | requested
+}
+
+// PlatformPasswordResetRequest forces a user of a tenant to reset their
+// password
+type PlatformPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// Platform administration audit actions, logged in the target tenant
+const (
+	ActionPlatformTenantSuspended     = "platform.tenant_suspended"
+	ActionPlatformTenantActivated     = "platform.tenant_activated"
+	ActionPlatformVerificationResent  = "platform.verification_resent"
+	ActionPlatformPasswordResetForced = "platform.password_reset_forced"
+)
+
+// AuditResourceTenant is the audit log resource type of platform admins'
+// actions on a tenant
+const AuditResourceTenant = "tenant"
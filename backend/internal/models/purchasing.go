@@ -0,0 +1,316 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supplier represents a vendor the tenant buys from
+type Supplier struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Basic Info
+	Name    string  `json:"name" db:"name"`
+	Code    *string `json:"code,omitempty" db:"code"`
+	Email   *string `json:"email,omitempty" db:"email"`
+	Phone   *string `json:"phone,omitempty" db:"phone"`
+	Address *string `json:"address,omitempty" db:"address"`
+	TaxID   *string `json:"tax_id,omitempty" db:"tax_id"`
+
+	// Terms
+	PaymentTermsDays int    `json:"payment_terms_days" db:"payment_terms_days"`
+	Currency         string `json:"currency" db:"currency"`
+
+	Notes *string `json:"notes,omitempty" db:"notes"`
+
+	// Status
+	Status string `json:"status" db:"status"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+}
+
+// PurchaseOrder represents an order placed with a supplier
+type PurchaseOrder struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	TenantID       uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	PONumber       string    `json:"po_number" db:"po_number"`
+	SequenceNumber int       `json:"-" db:"sequence_number"`
+	SupplierID     uuid.UUID `json:"supplier_id" db:"supplier_id"`
+
+	// Status
+	Status string `json:"status" db:"status"`
+
+	// Dates
+	OrderDate    time.Time  `json:"order_date" db:"order_date"`
+	ExpectedDate *time.Time `json:"expected_date,omitempty" db:"expected_date"`
+
+	// Amounts
+	Currency string  `json:"currency" db:"currency"`
+	Subtotal float64 `json:"subtotal" db:"subtotal"`
+	TaxTotal float64 `json:"tax_total" db:"tax_total"`
+	Total    float64 `json:"total" db:"total"`
+
+	Notes *string `json:"notes,omitempty" db:"notes"`
+
+	// Status timestamps
+	SentAt      *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	SupplierName *string             `json:"supplier_name,omitempty" db:"supplier_name"`
+	Lines        []PurchaseOrderLine `json:"lines,omitempty" db:"-"`
+}
+
+// PurchaseOrderLine represents a line item on a purchase order
+type PurchaseOrderLine struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	TenantID        uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	PurchaseOrderID uuid.UUID `json:"purchase_order_id" db:"purchase_order_id"`
+
+	LineNumber  int     `json:"line_number" db:"line_number"`
+	ProductCode *string `json:"product_code,omitempty" db:"product_code"`
+	Description string  `json:"description" db:"description"`
+
+	Quantity  float64 `json:"quantity" db:"quantity"`
+	UnitPrice float64 `json:"unit_price" db:"unit_price"`
+	TaxRate   float64 `json:"tax_rate" db:"tax_rate"`
+	LineTotal float64 `json:"line_total" db:"line_total"`
+
+	QuantityReceived float64 `json:"quantity_received" db:"quantity_received"`
+	QuantityInvoiced float64 `json:"quantity_invoiced" db:"quantity_invoiced"`
+}
+
+// GoodsReceipt records goods received against a purchase order
+type GoodsReceipt struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	TenantID        uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	PurchaseOrderID uuid.UUID  `json:"purchase_order_id" db:"purchase_order_id"`
+	ReceivedAt      time.Time  `json:"received_at" db:"received_at"`
+	ReceivedBy      *uuid.UUID `json:"received_by,omitempty" db:"received_by"`
+	Reference       *string    `json:"reference,omitempty" db:"reference"`
+	Notes           *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Computed fields (not in database)
+	Lines []GoodsReceiptLine `json:"lines,omitempty" db:"-"`
+}
+
+// GoodsReceiptLine records the quantity received for one purchase order line
+type GoodsReceiptLine struct {
+	ID                  uuid.UUID `json:"id" db:"id"`
+	TenantID            uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	GoodsReceiptID      uuid.UUID `json:"goods_receipt_id" db:"goods_receipt_id"`
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id" db:"purchase_order_line_id"`
+	Quantity            float64   `json:"quantity" db:"quantity"`
+}
+
+// SupplierInvoice represents an invoice received from a supplier
+type SupplierInvoice struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	TenantID        uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	SupplierID      uuid.UUID `json:"supplier_id" db:"supplier_id"`
+	PurchaseOrderID uuid.UUID `json:"purchase_order_id" db:"purchase_order_id"`
+
+	InvoiceNumber string     `json:"invoice_number" db:"invoice_number"`
+	InvoiceDate   time.Time  `json:"invoice_date" db:"invoice_date"`
+	DueDate       *time.Time `json:"due_date,omitempty" db:"due_date"`
+
+	Currency string  `json:"currency" db:"currency"`
+	Subtotal float64 `json:"subtotal" db:"subtotal"`
+	TaxTotal float64 `json:"tax_total" db:"tax_total"`
+	Total    float64 `json:"total" db:"total"`
+
+	// Three-way match result
+	MatchStatus  string          `json:"match_status" db:"match_status"`
+	MatchDetails json.RawMessage `json:"match_details" db:"match_details"`
+
+	// Approval
+	Status     string     `json:"status" db:"status"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty" db:"approved_at"`
+	ApprovedBy *uuid.UUID `json:"approved_by,omitempty" db:"approved_by"`
+
+	Notes *string `json:"notes,omitempty" db:"notes"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	Lines []SupplierInvoiceLine `json:"lines,omitempty" db:"-"`
+}
+
+// SupplierInvoiceLine represents an invoiced quantity for one purchase order line
+type SupplierInvoiceLine struct {
+	ID                  uuid.UUID `json:"id" db:"id"`
+	TenantID            uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	SupplierInvoiceID   uuid.UUID `json:"supplier_invoice_id" db:"supplier_invoice_id"`
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id" db:"purchase_order_line_id"`
+	Quantity            float64   `json:"quantity" db:"quantity"`
+	UnitPrice           float64   `json:"unit_price" db:"unit_price"`
+	LineTotal           float64   `json:"line_total" db:"line_total"`
+}
+
+// MatchDiscrepancy describes a three-way match failure on one invoice line
+type MatchDiscrepancy struct {
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id"`
+	LineNumber          int       `json:"line_number"`
+	Type                string    `json:"type"` // quantity_exceeds_received | quantity_exceeds_ordered | price_mismatch
+	Expected            float64   `json:"expected"`
+	Actual              float64   `json:"actual"`
+}
+
+// Supplier status constants
+const (
+	SupplierStatusActive   = "active"
+	SupplierStatusInactive = "inactive"
+)
+
+// Purchase order status constants
+const (
+	POStatusDraft             = "draft"
+	POStatusSent              = "sent"
+	POStatusPartiallyReceived = "partially_received"
+	POStatusReceived          = "received"
+	POStatusClosed            = "closed"
+	POStatusCancelled         = "cancelled"
+)
+
+// Supplier invoice constants
+const (
+	MatchStatusMatched   = "matched"
+	MatchStatusException = "exception"
+
+	SupplierInvoiceStatusPending  = "pending"
+	SupplierInvoiceStatusApproved = "approved"
+	SupplierInvoiceStatusRejected = "rejected"
+)
+
+// Match discrepancy types
+const (
+	DiscrepancyExceedsReceived = "quantity_exceeds_received"
+	DiscrepancyExceedsOrdered  = "quantity_exceeds_ordered"
+	DiscrepancyPriceMismatch   = "price_mismatch"
+)
+
+// Permission resource constant
+const (
+	ResourcePurchasing = "purchasing"
+)
+
+// Permission actions for purchasing
+const (
+	ActionReceive = "receive"
+	ActionApprove = "approve"
+)
+
+// CanReceive returns true if goods can be received against the order
+func (po *PurchaseOrder) CanReceive() bool {
+	return po.Status == POStatusSent || po.Status == POStatusPartiallyReceived
+}
+
+// CanInvoice returns true if supplier invoices can be recorded against the order
+func (po *PurchaseOrder) CanInvoice() bool {
+	return po.Status == POStatusSent || po.Status == POStatusPartiallyReceived || po.Status == POStatusReceived
+}
+
+// SupplierCreateRequest represents a request to create a supplier
+type SupplierCreateRequest struct {
+	Name             string  `json:"name"`
+	Code             *string `json:"code,omitempty"`
+	Email            *string `json:"email,omitempty"`
+	Phone            *string `json:"phone,omitempty"`
+	Address          *string `json:"address,omitempty"`
+	TaxID            *string `json:"tax_id,omitempty"`
+	PaymentTermsDays *int    `json:"payment_terms_days,omitempty"`
+	Currency         string  `json:"currency"`
+	Notes            *string `json:"notes,omitempty"`
+
+	// Create the supplier even if it looks like an existing one
+	AllowDuplicate bool `json:"allow_duplicate,omitempty"`
+}
+
+// SupplierUpdateRequest represents a request to update a supplier
+type SupplierUpdateRequest struct {
+	Name             *string `json:"name,omitempty"`
+	Code             *string `json:"code,omitempty"`
+	Email            *string `json:"email,omitempty"`
+	Phone            *string `json:"phone,omitempty"`
+	Address          *string `json:"address,omitempty"`
+	TaxID            *string `json:"tax_id,omitempty"`
+	PaymentTermsDays *int    `json:"payment_terms_days,omitempty"`
+	Currency         *string `json:"currency,omitempty"`
+	Notes            *string `json:"notes,omitempty"`
+	Status           *string `json:"status,omitempty"`
+}
+
+// PurchaseOrderLineRequest represents a line in a purchase order request
+type PurchaseOrderLineRequest struct {
+	ProductCode *string `json:"product_code,omitempty"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	TaxRate     float64 `json:"tax_rate"`
+}
+
+// PurchaseOrderCreateRequest represents a request to create a purchase order
+type PurchaseOrderCreateRequest struct {
+	SupplierID   uuid.UUID                  `json:"supplier_id"`
+	OrderDate    *time.Time                 `json:"order_date,omitempty"`
+	ExpectedDate *time.Time                 `json:"expected_date,omitempty"`
+	Currency     string                     `json:"currency"`
+	Notes        *string                    `json:"notes,omitempty"`
+	Lines        []PurchaseOrderLineRequest `json:"lines"`
+}
+
+// PurchaseOrderUpdateRequest represents a request to update a draft purchase order
+type PurchaseOrderUpdateRequest struct {
+	ExpectedDate *time.Time                  `json:"expected_date,omitempty"`
+	Currency     *string                     `json:"currency,omitempty"`
+	Notes        *string                     `json:"notes,omitempty"`
+	Lines        *[]PurchaseOrderLineRequest `json:"lines,omitempty"` // Replaces all lines when set
+}
+
+// GoodsReceiptLineRequest represents a received quantity for one order line
+type GoodsReceiptLineRequest struct {
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id"`
+	Quantity            float64   `json:"quantity"`
+}
+
+// GoodsReceiptCreateRequest represents a request to receive goods
+type GoodsReceiptCreateRequest struct {
+	ReceivedAt *time.Time                `json:"received_at,omitempty"`
+	Reference  *string                   `json:"reference,omitempty"`
+	Notes      *string                   `json:"notes,omitempty"`
+	Lines      []GoodsReceiptLineRequest `json:"lines"`
+}
+
+// SupplierInvoiceLineRequest represents an invoiced quantity for one order line
+type SupplierInvoiceLineRequest struct {
+	PurchaseOrderLineID uuid.UUID `json:"purchase_order_line_id"`
+	Quantity            float64   `json:"quantity"`
+	UnitPrice           float64   `json:"unit_price"`
+}
+
+// SupplierInvoiceCreateRequest represents a request to record a supplier invoice
+type SupplierInvoiceCreateRequest struct {
+	PurchaseOrderID uuid.UUID                    `json:"purchase_order_id"`
+	InvoiceNumber   string                       `json:"invoice_number"`
+	InvoiceDate     time.Time                    `json:"invoice_date"`
+	DueDate         *time.Time                   `json:"due_date,omitempty"`
+	TaxTotal        float64                      `json:"tax_total"`
+	Notes           *string                      `json:"notes,omitempty"`
+	Lines           []SupplierInvoiceLineRequest `json:"lines"`
+}
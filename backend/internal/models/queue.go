@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Queues worked by background jobs
+const (
+	QueueEmail    = "email"
+	QueueWebhook  = "webhook"
+	QueueAsyncJob = "async_job"
+	QueueSIEM     = "siem" // Audit log forwarding; rejected items are events skipped for lagging
+)
+
+// QueueStats is the depth of a queue across tenants and data regions
+type QueueStats struct {
+	Queue       string     `json:"queue" db:"-"`
+	Pending     int        `json:"pending" db:"pending"`           // Waiting, including retries not yet due
+	Due         int        `json:"due" db:"due"`                   // Waiting and due now
+	DeadLetters int        `json:"dead_letters" db:"dead_letters"` // Given up on, kept for inspection or retry until the retention passes
+	OldestDueAt *time.Time `json:"oldest_due_at,omitempty" db:"oldest_due_at"`
+	Limit       int        `json:"limit" db:"-"`       // Most pending items one tenant (or webhook) may have; 0 for none
+	LimitScope  string     `json:"limit_scope" db:"-"` // tenant or webhook
+	Alert       bool       `json:"alert" db:"-"`       // Over the alert depth, or the oldest due item waited too long
+}
+
+// OldestDueAge returns how long the oldest due item has been waiting
+func (s *QueueStats) OldestDueAge() time.Duration {
+	if s.OldestDueAt == nil {
+		return 0
+	}
+	return time.Since(*s.OldestDueAt)
+}
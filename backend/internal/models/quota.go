@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Quota metrics
+const (
+	QuotaMetricUsers    = "users"     // Users that are not deleted
+	QuotaMetricStorage  = "storage"   // Bytes of tenant data
+	QuotaMetricAPICalls = "api_calls" // API requests this calendar month
+)
+
+// QuotaMetrics are the metrics plans limit
+var QuotaMetrics = []string{QuotaMetricUsers, QuotaMetricStorage, QuotaMetricAPICalls}
+
+// QuotaUsage is a tenant's usage of one quota
+type QuotaUsage struct {
+	Metric  string `json:"metric"`
+	Usage   int64  `json:"usage"`
+	Limit   *int64 `json:"limit"`   // nil = unlimited
+	Percent *int   `json:"percent"` // Of the limit, rounded down; nil = unlimited
+}
+
+// QuotaAlert records that a tenant's usage crossed a warning threshold of a
+// quota. It stays open until usage drops back under the threshold; owners
+// are reminded of it until one of them acknowledges it.
+type QuotaAlert struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Metric    string `json:"metric" db:"metric"`
+	Threshold int    `json:"threshold" db:"threshold"` // Percent of the limit
+	Usage     int64  `json:"usage" db:"usage"`
+	Limit     int64  `json:"limit" db:"quota_limit"`
+
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty" db:"last_notified_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty" db:"acknowledged_at"`
+	AcknowledgedBy *uuid.UUID `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsAcknowledged returns true if an owner acknowledged the alert
+func (a *QuotaAlert) IsAcknowledged() bool {
+	return a.AcknowledgedAt != nil
+}
+
+// QuotaStatus is a tenant's plan, usage of each quota and open alerts
+type QuotaStatus struct {
+	PlanTier string       `json:"plan_tier"`
+	Quotas   []QuotaUsage `json:"quotas"`
+	Alerts   []QuotaAlert `json:"alerts"`
+}
+
+// Quota audit actions
+const (
+	ActionQuotaAlertAcknowledged = "quota.alert_acknowledged"
+)
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Redaction rules applied to data sent to third parties. Besides these
+// categories, a rule can name a field, which is redacted wherever it appears.
+const (
+	RedactEmails  = "emails"  // Email addresses, in any field
+	RedactPhones  = "phones"  // Phone, mobile and fax numbers
+	RedactAmounts = "amounts" // Monetary amounts: prices, totals, salaries, ...
+)
+
+// RedactionCategories lists the redaction rule categories
+var RedactionCategories = []string{RedactEmails, RedactPhones, RedactAmounts}
+
+// RedactedValue replaces redacted values
+const RedactedValue = "[redacted]"
+
+var (
+	redactEmailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	redactFieldRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+	// Last word of the names of phone and monetary fields, e.g. mobile_phone
+	// or unit_price
+	redactPhoneWords  = map[string]bool{"phone": true, "mobile": true, "fax": true}
+	redactAmountWords = map[string]bool{
+		"amount": true, "price": true, "total": true, "subtotal": true, "cost": true,
+		"salary": true, "revenue": true, "balance": true, "tax": true,
+	}
+)
+
+// IsValidRedactionRule reports whether rule is a category or a field name
+func IsValidRedactionRule(rule string) bool {
+	for _, category := range RedactionCategories {
+		if rule == category {
+			return true
+		}
+	}
+	return redactFieldRegex.MatchString(rule)
+}
+
+// RedactJSON replaces the values matched by the rules in a JSON document with
+// RedactedValue, at any depth. Nulls are left as they are.
+func RedactJSON(data json.RawMessage, rules []string) (json.RawMessage, error) {
+	if len(rules) == 0 || len(data) == 0 {
+		return data, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redactValue(value, "", newRedaction(rules)))
+}
+
+// redaction is a parsed set of redaction rules
+type redaction struct {
+	emails, phones, amounts bool
+	fields                  map[string]bool
+}
+
+func newRedaction(rules []string) *redaction {
+	r := &redaction{fields: make(map[string]bool)}
+	for _, rule := range rules {
+		switch rule {
+		case RedactEmails:
+			r.emails = true
+		case RedactPhones:
+			r.phones = true
+		case RedactAmounts:
+			r.amounts = true
+		default:
+			r.fields[strings.ToLower(rule)] = true
+		}
+	}
+	return r
+}
+
+// redactValue redacts a decoded JSON value found under the field name. A
+// field named by a rule is redacted whole, even an object.
+func redactValue(value interface{}, name string, r *redaction) interface{} {
+	if value != nil && r.fields[strings.ToLower(name)] {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = redactValue(child, key, r)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child, name, r)
+		}
+		return v
+	}
+
+	if r.redactsField(strings.ToLower(name)) {
+		return RedactedValue
+	}
+	if s, ok := value.(string); ok && r.emails && redactEmailRegex.MatchString(s) {
+		return RedactedValue
+	}
+	return value
+}
+
+// redactsField reports whether a category redacts the values of a field
+func (r *redaction) redactsField(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	words := strings.Split(name, "_")
+	last := words[len(words)-1]
+	switch {
+	case r.emails && last == "email":
+		return true
+	case r.phones && redactPhoneWords[last]:
+		return true
+	case r.amounts && redactAmountWords[last]:
+		return true
+	}
+	return false
+}
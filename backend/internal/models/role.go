@@ -14,7 +14,8 @@ type Role struct {
 	DisplayName string     `json:"display_name" db:"display_name"`
 	Description *string    `json:"description,omitempty" db:"description"`
 
-	// Hierarchy support
+	// Hierarchy support: a role inherits the allowed and denied permissions
+	// of its parent chain
 	ParentRoleID *uuid.UUID `json:"parent_role_id,omitempty" db:"parent_role_id"`
 	Level        int        `json:"level" db:"level"` // 0 = root, higher = more restrictive
 
@@ -40,6 +41,9 @@ const (
 	RoleUser    = "user"
 )
 
+// MaxRoleHierarchyDepth is the most ancestors a role may have
+const MaxRoleHierarchyDepth = 10
+
 // IsOwner returns true if this is the owner role
 func (r *Role) IsOwner() bool {
 	return r.Name == RoleOwner
@@ -67,14 +71,41 @@ type RoleCreateRequest struct {
 	Description   string      `json:"description,omitempty"`
 	ParentRoleID  *uuid.UUID  `json:"parent_role_id,omitempty"`
 	PermissionIDs []uuid.UUID `json:"permission_ids" validate:"required,min=1"`
+
+	// Denied permissions override the allowed ones, e.g. *.* allowed with
+	// *.delete denied
+	DeniedPermissionIDs []uuid.UUID `json:"denied_permission_ids,omitempty"`
 }
 
 // RoleUpdateRequest represents a request to update a role
 type RoleUpdateRequest struct {
 	DisplayName   *string     `json:"display_name,omitempty" validate:"omitempty,min=2,max=255"`
 	Description   *string     `json:"description,omitempty"`
-	ParentRoleID  *uuid.UUID  `json:"parent_role_id,omitempty"`
+	ParentRoleID  *uuid.UUID  `json:"parent_role_id,omitempty"` // The nil UUID removes the parent
 	PermissionIDs []uuid.UUID `json:"permission_ids,omitempty"`
+
+	// Either list left out keeps the role's current allowed or denied permissions
+	DeniedPermissionIDs []uuid.UUID `json:"denied_permission_ids,omitempty"`
+
+	// The updated_at the change is based on, if not sent in If-Match
+	Version *string `json:"version,omitempty"`
+}
+
+// EffectivePermission is a permission a role has directly or inherits from
+// an ancestor
+type EffectivePermission struct {
+	Permission
+	SourceRoleID   uuid.UUID `json:"source_role_id"`
+	SourceRoleName string    `json:"source_role_name"`
+	Inherited      bool      `json:"inherited"`
+}
+
+// RoleEffectivePermissions is a role's permissions resolved through its
+// parent chain
+type RoleEffectivePermissions struct {
+	Role        *Role                 `json:"role"`
+	Ancestors   []Role                `json:"ancestors"` // Nearest first
+	Permissions []EffectivePermission `json:"permissions"`
 }
 
 // RoleAssignRequest represents a request to assign roles to a user
@@ -82,3 +113,31 @@ type RoleAssignRequest struct {
 	UserID  uuid.UUID   `json:"user_id" validate:"required"`
 	RoleIDs []uuid.UUID `json:"role_ids" validate:"required,min=1"`
 }
+
+// Role assignment states. Temporary assignments are scheduled until they
+// start and expired once they end, until the expiry job removes them.
+const (
+	RoleAssignmentActive    = "active"
+	RoleAssignmentScheduled = "scheduled"
+	RoleAssignmentExpired   = "expired"
+)
+
+// MaxTemporaryRoleDuration is the longest a role can be granted temporarily
+const MaxTemporaryRoleDuration = 366 * 24 * time.Hour
+
+// TemporaryRoleRequest represents a request to grant a role for a limited
+// period, e.g. to cover for a colleague on vacation
+type TemporaryRoleRequest struct {
+	RoleID        uuid.UUID  `json:"role_id" validate:"required"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"` // Defaults to now
+	ValidUntil    time.Time  `json:"valid_until" validate:"required"`
+	DelegatedFrom *uuid.UUID `json:"delegated_from,omitempty"` // User holding the role who is being covered for
+	Reason        string     `json:"reason,omitempty" validate:"max=500"`
+}
+
+// ExpiredRoleAssignment is a temporary assignment removed after it ended
+type ExpiredRoleAssignment struct {
+	TenantID uuid.UUID `db:"tenant_id"`
+	UserID   uuid.UUID `db:"user_id"`
+	RoleID   uuid.UUID `db:"role_id"`
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SalesDocument represents a quotation, sales order or invoice
+type SalesDocument struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Document identity
+	DocumentType     string     `json:"document_type" db:"document_type"` // quote | order | invoice
+	DocumentNumber   string     `json:"document_number" db:"document_number"`
+	SequenceNumber   int        `json:"-" db:"sequence_number"`
+	SourceDocumentID *uuid.UUID `json:"source_document_id,omitempty" db:"source_document_id"`
+
+	// Customer
+	CustomerName    string  `json:"customer_name" db:"customer_name"`
+	CustomerEmail   *string `json:"customer_email,omitempty" db:"customer_email"`
+	CustomerAddress *string `json:"customer_address,omitempty" db:"customer_address"`
+
+	// Status
+	Status string `json:"status" db:"status"`
+
+	// Dates
+	IssueDate time.Time  `json:"issue_date" db:"issue_date"`
+	DueDate   *time.Time `json:"due_date,omitempty" db:"due_date"`
+
+	// Amounts
+	Currency      string  `json:"currency" db:"currency"`
+	Subtotal      float64 `json:"subtotal" db:"subtotal"`
+	DiscountTotal float64 `json:"discount_total" db:"discount_total"`
+	TaxTotal      float64 `json:"tax_total" db:"tax_total"`
+	Total         float64 `json:"total" db:"total"`
+
+	Notes *string `json:"notes,omitempty" db:"notes"`
+
+	// Status timestamps
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty" db:"fulfilled_at"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	Lines []SalesDocumentLine `json:"lines,omitempty" db:"-"`
+}
+
+// SalesDocumentLine represents a line item on a sales document
+type SalesDocumentLine struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	DocumentID uuid.UUID `json:"document_id" db:"document_id"`
+
+	LineNumber  int     `json:"line_number" db:"line_number"`
+	ProductCode *string `json:"product_code,omitempty" db:"product_code"`
+	Description string  `json:"description" db:"description"`
+
+	Quantity        float64 `json:"quantity" db:"quantity"`
+	UnitPrice       float64 `json:"unit_price" db:"unit_price"`
+	DiscountPercent float64 `json:"discount_percent" db:"discount_percent"`
+	TaxRate         float64 `json:"tax_rate" db:"tax_rate"`
+	LineTotal       float64 `json:"line_total" db:"line_total"`
+}
+
+// Sales document type constants
+const (
+	SalesDocumentQuote   = "quote"
+	SalesDocumentOrder   = "order"
+	SalesDocumentInvoice = "invoice"
+)
+
+// Sales document status constants
+const (
+	SalesStatusDraft     = "draft"
+	SalesStatusConfirmed = "confirmed"
+	SalesStatusFulfilled = "fulfilled"
+	SalesStatusCancelled = "cancelled"
+)
+
+// Permission resource constant
+const (
+	ResourceSales = "sales"
+)
+
+// salesNumberPrefixes maps document types to their number prefix
+var salesNumberPrefixes = map[string]string{
+	SalesDocumentQuote:   "QT",
+	SalesDocumentOrder:   "SO",
+	SalesDocumentInvoice: "INV",
+}
+
+// salesStatusTransitions lists the statuses each status may move to
+var salesStatusTransitions = map[string][]string{
+	SalesStatusDraft:     {SalesStatusConfirmed, SalesStatusCancelled},
+	SalesStatusConfirmed: {SalesStatusFulfilled, SalesStatusCancelled},
+	SalesStatusFulfilled: {},
+	SalesStatusCancelled: {},
+}
+
+// IsValidSalesDocumentType returns true if the type is a known sales document type
+func IsValidSalesDocumentType(documentType string) bool {
+	_, ok := salesNumberPrefixes[documentType]
+	return ok
+}
+
+// SalesNumberPrefix returns the document number prefix for a document type
+func SalesNumberPrefix(documentType string) string {
+	return salesNumberPrefixes[documentType]
+}
+
+// IsDraft returns true if the document can still be edited
+func (d *SalesDocument) IsDraft() bool {
+	return d.Status == SalesStatusDraft
+}
+
+// CanTransitionTo returns true if the document may move to the given status
+func (d *SalesDocument) CanTransitionTo(status string) bool {
+	for _, allowed := range salesStatusTransitions[d.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SalesLineRequest represents a line item in a create/update request
+type SalesLineRequest struct {
+	ProductCode     *string `json:"product_code,omitempty"`
+	Description     string  `json:"description"`
+	Quantity        float64 `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	DiscountPercent float64 `json:"discount_percent"`
+	TaxRate         float64 `json:"tax_rate"`
+}
+
+// SalesDocumentCreateRequest represents a request to create a sales document
+type SalesDocumentCreateRequest struct {
+	CustomerName    string             `json:"customer_name"`
+	CustomerEmail   *string            `json:"customer_email,omitempty"`
+	CustomerAddress *string            `json:"customer_address,omitempty"`
+	IssueDate       *time.Time         `json:"issue_date,omitempty"`
+	DueDate         *time.Time         `json:"due_date,omitempty"`
+	Currency        string             `json:"currency"`
+	Notes           *string            `json:"notes,omitempty"`
+	Lines           []SalesLineRequest `json:"lines"`
+}
+
+// SalesDocumentUpdateRequest represents a request to update a draft sales document
+type SalesDocumentUpdateRequest struct {
+	CustomerName    *string             `json:"customer_name,omitempty"`
+	CustomerEmail   *string             `json:"customer_email,omitempty"`
+	CustomerAddress *string             `json:"customer_address,omitempty"`
+	IssueDate       *time.Time          `json:"issue_date,omitempty"`
+	DueDate         *time.Time          `json:"due_date,omitempty"`
+	Currency        *string             `json:"currency,omitempty"`
+	Notes           *string             `json:"notes,omitempty"`
+	Lines           *[]SalesLineRequest `json:"lines,omitempty"` // Replaces all lines when set
+}
+
+// SalesDocumentFilter represents filters for listing sales documents
+type SalesDocumentFilter struct {
+	DocumentType string
+	Status       string
+	Search       string // Matches document number or customer name
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantSandbox is a sandbox copy of a tenant's data and its clone status
+type TenantSandbox struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	SourceTenantID  uuid.UUID  `json:"source_tenant_id" db:"source_tenant_id"`
+	SandboxTenantID *uuid.UUID `json:"sandbox_tenant_id,omitempty" db:"sandbox_tenant_id"`
+	SandboxSlug     *string    `json:"sandbox_slug,omitempty" db:"sandbox_slug"` // Joined from tenants
+
+	Name      string `json:"name" db:"name"`
+	Anonymize bool   `json:"anonymize" db:"anonymize"`
+
+	// Status: pending | cloning | ready | failed | deleted
+	Status string  `json:"status" db:"status"`
+	Error  *string `json:"error,omitempty" db:"error"`
+
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	RequestedBy uuid.UUID `json:"requested_by" db:"requested_by"`
+
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// Sandbox status constants
+const (
+	SandboxStatusPending = "pending"
+	SandboxStatusCloning = "cloning"
+	SandboxStatusReady   = "ready"
+	SandboxStatusFailed  = "failed"
+	SandboxStatusDeleted = "deleted"
+)
+
+// ResourceSandboxes is the permission resource for sandbox management
+const ResourceSandboxes = "sandboxes"
+
+// SandboxCreateRequest represents a request to clone the tenant into a sandbox
+type SandboxCreateRequest struct {
+	Name          string `json:"name" validate:"required,min=1,max=100"`
+	Anonymize     *bool  `json:"anonymize,omitempty"`       // Defaults to true
+	ExpiresInDays int    `json:"expires_in_days,omitempty"` // Defaults to SANDBOX_DEFAULT_TTL
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// ScheduledJob is a periodic background job (see internal/jobs) with its last
+// run and run totals across all replicas. Jobs that never ran have no last run.
+type ScheduledJob struct {
+	Name      string     `json:"name" db:"name"`
+	Schedule  string     `json:"schedule" db:"-"` // e.g. "every 1h0m0s" or "30 3 * * *"
+	NextRunAt *time.Time `json:"next_run_at,omitempty" db:"-"`
+
+	// Last run. Status: running | succeeded | failed
+	LastDueAt      *time.Time `json:"last_due_at,omitempty" db:"last_due_at"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty" db:"last_started_at"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty" db:"last_finished_at"`
+	LastStatus     *string    `json:"last_status,omitempty" db:"last_status"`
+	LastDurationMs *int64     `json:"last_duration_ms,omitempty" db:"last_duration_ms"`
+	LastProcessed  *int       `json:"last_processed,omitempty" db:"last_processed"`
+
+	// Totals
+	Runs      int64 `json:"runs" db:"runs"`
+	Failures  int64 `json:"failures" db:"failures"`
+	Processed int64 `json:"processed" db:"processed"` // Items processed by all runs
+}
+
+// Scheduled job run status constants
+const (
+	ScheduledJobRunning   = "running"
+	ScheduledJobSucceeded = "succeeded"
+	ScheduledJobFailed    = "failed"
+)
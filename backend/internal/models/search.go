@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// SearchResult is a record matching a search. Full-text matches score above
+// 1 and come first; fuzzy (similarity only) matches score between 0 and 1.
+type SearchResult struct {
+	Type     string     `json:"type" db:"type"`
+	ID       *uuid.UUID `json:"id,omitempty" db:"id"` // nil for customers and products, which are names and codes on sales documents
+	Title    string     `json:"title" db:"title"`
+	Subtitle string     `json:"subtitle,omitempty" db:"subtitle"`
+	Path     string     `json:"path,omitempty" db:"path"`
+	Score    float64    `json:"score" db:"score"`
+	Fuzzy    bool       `json:"fuzzy" db:"fuzzy"` // Matched by similarity only, e.g. a typo
+}
+
+// Search result types
+const (
+	SearchTypeUser       = "user"
+	SearchTypeRole       = "role"
+	SearchTypeDepartment = "department"
+	SearchTypeCustomer   = "customer"
+	SearchTypeProduct    = "product"
+)
+
+// SearchTypes lists the searchable types, for validation
+var SearchTypes = []string{SearchTypeUser, SearchTypeRole, SearchTypeDepartment, SearchTypeCustomer, SearchTypeProduct}
+
+// searchConfigs maps language codes to the PostgreSQL text search
+// configurations queries are stemmed with. They match the stems indexed by
+// search_vector_multilingual (migration 048).
+var searchConfigs = map[string]string{
+	"en": "english",
+	"fr": "french",
+	"ar": "arabic",
+}
+
+// SearchConfig returns the text search configuration for a language code,
+// "simple" (no stemming) for other languages
+func SearchConfig(language string) string {
+	if config, ok := searchConfigs[language]; ok {
+		return config
+	}
+	return "simple"
+}
@@ -12,6 +12,9 @@ type Session struct {
 	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
 	UserID   uuid.UUID `json:"user_id" db:"user_id"`
 
+	// Friendly device name given by the user, e.g. "Work laptop"
+	Name *string `json:"name,omitempty" db:"name"`
+
 	// Token (hashed for security)
 	TokenHash string `json:"-" db:"token_hash"`
 
@@ -55,6 +58,53 @@ func (s *Session) DeviceString() string {
 	return s.Browser + " on " + s.OS + " (" + s.DeviceType + ")"
 }
 
+// DisplayName returns the session's friendly name, or its device description
+// when it has none
+func (s *Session) DisplayName() string {
+	if s.Name != nil && *s.Name != "" {
+		return *s.Name
+	}
+	return s.DeviceString()
+}
+
+// LocationString returns the session's city and country, e.g. "Paris, FR",
+// or an empty string when GeoIP did not locate it
+func (s *Session) LocationString() string {
+	switch {
+	case s.City != nil && *s.City != "" && s.CountryCode != nil && *s.CountryCode != "":
+		return *s.City + ", " + *s.CountryCode
+	case s.City != nil && *s.City != "":
+		return *s.City
+	case s.CountryCode != nil:
+		return *s.CountryCode
+	}
+	return ""
+}
+
+// Concurrent session limit policies, applied when a sign-in would exceed the
+// limit
+const (
+	SessionLimitPolicyEvictOldest = "evict_oldest" // Sign out the least recently active session
+	SessionLimitPolicyReject      = "reject"       // Refuse the sign-in
+)
+
+// SessionLimit caps the concurrent sessions of each user of a tenant (the
+// session_limit key of the tenant settings)
+type SessionLimit struct {
+	MaxSessions int    `json:"max_sessions"` // 0 = unlimited
+	Policy      string `json:"policy"`       // evict_oldest | reject
+}
+
+// SessionLimitUpdateRequest represents a request to change the tenant's
+// concurrent session limit
+type SessionLimitUpdateRequest struct {
+	MaxSessions *int    `json:"max_sessions,omitempty"`
+	Policy      *string `json:"policy,omitempty"`
+}
+
+// MaxSessionLimit is the highest concurrent session limit a tenant may set
+const MaxSessionLimit = 100
+
 // SessionCreateRequest represents a request to create a new session
 type SessionCreateRequest struct {
 	UserID     uuid.UUID
@@ -66,6 +116,12 @@ type SessionCreateRequest struct {
 	IPAddress  string
 	UserAgent  string
 	ExpiresAt  time.Time
+	Limit      SessionLimit // Concurrent sessions the user may have, this one included
+}
+
+// SessionUpdateRequest represents a request to rename a session
+type SessionUpdateRequest struct {
+	Name string `json:"name"` // Empty clears the name
 }
 
 // SessionListResponse represents a list of user sessions for display
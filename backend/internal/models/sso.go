@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SSOProvider is an OpenID Connect provider or SAML identity provider a
+// tenant's users sign in with
+type SSOProvider struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Slug     string    `json:"slug" db:"slug"`
+	Name     string    `json:"name" db:"name"`
+	Protocol string    `json:"protocol" db:"protocol"` // oidc | saml
+
+	IssuerURL    string  `json:"issuer_url" db:"issuer_url"`
+	ClientID     string  `json:"client_id" db:"client_id"`
+	ClientSecret *string `json:"-" db:"client_secret_encrypted"` // Encrypted; nil for public clients
+	Scopes       string  `json:"scopes" db:"scopes"`
+
+	SAMLIdPEntityID      *string              `json:"saml_idp_entity_id,omitempty" db:"saml_idp_entity_id"`
+	SAMLIdPSSOURL        *string              `json:"saml_idp_sso_url,omitempty" db:"saml_idp_sso_url"`
+	SAMLIdPCertificates  pq.StringArray       `json:"-" db:"saml_idp_certificates"` // PEM
+	SAMLAttributeMapping SAMLAttributeMapping `json:"saml_attribute_mapping" db:"saml_attribute_mapping"`
+	SAMLRoleMapping      SAMLRoleMapping      `json:"saml_role_mapping" db:"saml_role_mapping"`
+
+	AllowedDomains pq.StringArray `json:"allowed_domains" db:"allowed_domains"`
+	AutoProvision  bool           `json:"auto_provision" db:"auto_provision"`
+	DefaultRoleID  *uuid.UUID     `json:"default_role_id,omitempty" db:"default_role_id"`
+
+	IsEnabled bool `json:"is_enabled" db:"is_enabled"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	HasClientSecret bool   `json:"has_client_secret" db:"-"`
+	SAMLEntityID    string `json:"saml_sp_entity_id,omitempty" db:"-"` // Service provider entity ID and metadata URL
+	SAMLACSURL      string `json:"saml_sp_acs_url,omitempty" db:"-"`   // Assertion consumer service URL
+}
+
+// IsSAML reports whether the provider is a SAML identity provider
+func (p *SSOProvider) IsSAML() bool {
+	return p.Protocol == SSOProtocolSAML
+}
+
+// SAMLAttributeMapping names the assertion attributes holding a user's
+// details. Empty names fall back to the defaults.
+type SAMLAttributeMapping struct {
+	Email     string `json:"email,omitempty"`      // Default: the NameID if it is an email
+	FirstName string `json:"first_name,omitempty"` // Default: givenName
+	LastName  string `json:"last_name,omitempty"`  // Default: sn
+	Roles     string `json:"roles,omitempty"`      // No roles are synced without it
+}
+
+// Scan implements sql.Scanner
+func (m *SAMLAttributeMapping) Scan(value interface{}) error {
+	return scanAutomationJSON(value, m)
+}
+
+// SAMLRoleMapping maps the values of the roles attribute to tenant roles
+type SAMLRoleMapping map[string]uuid.UUID
+
+// Scan implements sql.Scanner
+func (m *SAMLRoleMapping) Scan(value interface{}) error {
+	return scanAutomationJSON(value, m)
+}
+
+// SSOIdentity links a provider's subject to a user
+type SSOIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	ProviderID  uuid.UUID  `json:"provider_id" db:"provider_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+}
+
+// SSOLoginProvider is a provider as listed on the login page
+type SSOLoginProvider struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// SSOSettings are a tenant's single sign-on settings
+type SSOSettings struct {
+	SSORequired bool          `json:"sso_required"` // Password logins are refused
+	Providers   []SSOProvider `json:"providers"`
+}
+
+// SSOSettingsUpdateRequest represents a request to change a tenant's single
+// sign-on settings
+type SSOSettingsUpdateRequest struct {
+	SSORequired *bool `json:"sso_required,omitempty"`
+}
+
+// SSOProviderCreateRequest represents a request to configure a provider.
+// OpenID Connect providers need an issuer and client; SAML identity
+// providers need their metadata.
+type SSOProviderCreateRequest struct {
+	Slug                 string                `json:"slug" validate:"required"`
+	Name                 string                `json:"name" validate:"required,max=100"`
+	Protocol             string                `json:"protocol,omitempty"` // oidc (default) | saml
+	IssuerURL            string                `json:"issuer_url,omitempty"`
+	ClientID             string                `json:"client_id,omitempty"`
+	ClientSecret         string                `json:"client_secret,omitempty"`
+	Scopes               string                `json:"scopes,omitempty"`
+	SAMLMetadataXML      string                `json:"saml_metadata_xml,omitempty"`
+	SAMLAttributeMapping *SAMLAttributeMapping `json:"saml_attribute_mapping,omitempty"`
+	SAMLRoleMapping      SAMLRoleMapping       `json:"saml_role_mapping,omitempty"`
+	AllowedDomains       []string              `json:"allowed_domains,omitempty"`
+	AutoProvision        bool                  `json:"auto_provision"`
+	DefaultRoleID        *uuid.UUID            `json:"default_role_id,omitempty"`
+}
+
+// SSOProviderUpdateRequest represents a request to change a provider. An
+// empty client_secret removes the secret; omit it to keep the current one.
+type SSOProviderUpdateRequest struct {
+	Name           *string    `json:"name,omitempty"`
+	IssuerURL      *string    `json:"issuer_url,omitempty"`
+	ClientID       *string    `json:"client_id,omitempty"`
+	ClientSecret   *string    `json:"client_secret,omitempty"`
+	Scopes         *string    `json:"scopes,omitempty"`
+	AllowedDomains *[]string  `json:"allowed_domains,omitempty"`
+	AutoProvision  *bool      `json:"auto_provision,omitempty"`
+	DefaultRoleID  *uuid.UUID `json:"default_role_id,omitempty"`
+	IsEnabled      *bool      `json:"is_enabled,omitempty"`
+
+	SAMLMetadataXML      *string               `json:"saml_metadata_xml,omitempty"` // Replaces the identity provider's settings and certificates
+	SAMLAttributeMapping *SAMLAttributeMapping `json:"saml_attribute_mapping,omitempty"`
+	SAMLRoleMapping      *SAMLRoleMapping      `json:"saml_role_mapping,omitempty"`
+}
+
+// SSO protocols
+const (
+	SSOProtocolOIDC = "oidc"
+	SSOProtocolSAML = "saml"
+)
+
+// DefaultSSOScopes are requested unless a provider configures its own
+const DefaultSSOScopes = "openid email profile"
+
+// SSO audit actions
+const (
+	ActionSSOProviderCreated = "sso.provider_created"
+	ActionSSOProviderUpdated = "sso.provider_updated"
+	ActionSSOProviderDeleted = "sso.provider_deleted"
+	ActionSSOSettingsUpdated = "sso.settings_updated"
+)
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a supplier is reported as a likely duplicate
+const (
+	DuplicateReasonName  = "name"   // Similar name
+	DuplicateReasonEmail = "email"  // Same or similar email address
+	DuplicateReasonTaxID = "tax_id" // Same tax ID, ignoring spaces and punctuation
+)
+
+// SupplierDuplicate is an existing supplier that looks like the same vendor
+// as another supplier
+type SupplierDuplicate struct {
+	Supplier `json:"supplier"`
+
+	Score   float64  `json:"score" db:"-"`   // 0-1, how likely the suppliers are the same vendor
+	Reasons []string `json:"reasons" db:"-"` // What matched, e.g. ["name", "tax_id"]
+
+	NameSimilarity  float64 `json:"-" db:"name_similarity"`
+	EmailSimilarity float64 `json:"-" db:"email_similarity"`
+	TaxIDMatch      bool    `json:"-" db:"tax_id_match"`
+}
+
+// SupplierDuplicateQuery describes the supplier to look for duplicates of
+type SupplierDuplicateQuery struct {
+	Name      string
+	Email     string
+	TaxID     string
+	ExcludeID *uuid.UUID // The supplier itself, when checking an existing one
+}
+
+// SupplierMerge records a supplier merged into another. The merged supplier
+// is deleted; its record at the time of the merge is kept in Source.
+type SupplierMerge struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	TargetID uuid.UUID `json:"target_id" db:"target_id"` // The supplier that was kept
+	SourceID uuid.UUID `json:"source_id" db:"source_id"` // The supplier that was merged and deleted
+
+	SourceName string          `json:"source_name" db:"source_name"`
+	Source     json.RawMessage `json:"source" db:"source"`
+
+	// Documents re-pointed from the source to the target
+	PurchaseOrdersMoved   int `json:"purchase_orders_moved" db:"purchase_orders_moved"`
+	SupplierInvoicesMoved int `json:"supplier_invoices_moved" db:"supplier_invoices_moved"`
+
+	MergedBy *uuid.UUID `json:"merged_by,omitempty" db:"merged_by"`
+	MergedAt time.Time  `json:"merged_at" db:"merged_at"`
+}
+
+// SupplierMergeRequest represents a request to merge a supplier into another
+type SupplierMergeRequest struct {
+	SourceID uuid.UUID `json:"source_id"`
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Tenant represents a tenant (company/organization) in the system
@@ -28,6 +29,21 @@ type Tenant struct {
 	PlanTier    string     `json:"plan_tier" db:"plan_tier"` // free | starter | professional | enterprise
 	TrialEndsAt *time.Time `json:"trial_ends_at,omitempty" db:"trial_ends_at"`
 
+	// Stripe billing (nil on tenants that never subscribed), see BillingStatus
+	StripeCustomerID              *string    `json:"-" db:"stripe_customer_id"`
+	StripeSubscriptionID          *string    `json:"-" db:"stripe_subscription_id"`
+	SubscriptionStatus            *string    `json:"-" db:"subscription_status"`
+	SubscriptionPeriodEnd         *time.Time `json:"-" db:"subscription_period_end"`
+	SubscriptionCancelAtPeriodEnd bool       `json:"-" db:"subscription_cancel_at_period_end"`
+
+	// Data residency (nil = default region)
+	DataRegion               *string    `json:"data_region,omitempty" db:"data_region"`
+	RegionMigrationStartedAt *time.Time `json:"-" db:"region_migration_started_at"`
+
+	// Sandbox (nil = production tenant)
+	SandboxOf        *uuid.UUID `json:"sandbox_of,omitempty" db:"sandbox_of"`
+	SandboxExpiresAt *time.Time `json:"sandbox_expires_at,omitempty" db:"sandbox_expires_at"`
+
 	// Settings (stored as JSONB in database)
 	Settings json.RawMessage `json:"settings,omitempty" db:"settings"`
 
@@ -36,6 +52,22 @@ type Tenant struct {
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	ActivatedAt *time.Time `json:"activated_at,omitempty" db:"activated_at"`
 	SuspendedAt *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+
+	// Suspension: payment_overdue | terms_violation | requested (nil unless suspended)
+	SuspensionReason *string `json:"suspension_reason,omitempty" db:"suspension_reason"`
+
+	// Scheduled maintenance window (nil = none scheduled), see MaintenanceWindow
+	MaintenanceStartsAt     *time.Time     `json:"-" db:"maintenance_starts_at"`
+	MaintenanceEndsAt       *time.Time     `json:"-" db:"maintenance_ends_at"`
+	MaintenanceMessage      *string        `json:"-" db:"maintenance_message"`
+	MaintenanceAllowedPaths pq.StringArray `json:"-" db:"maintenance_allowed_paths"`
+
+	// Requested deletion (nil = none), see TenantDeletion
+	DeletionRequestedAt    *time.Time `json:"-" db:"deletion_requested_at"`
+	DeletionRequestedBy    *uuid.UUID `json:"-" db:"deletion_requested_by"`
+	DeletionToken          *uuid.UUID `json:"-" db:"deletion_token"`
+	DeletionTokenExpiresAt *time.Time `json:"-" db:"deletion_token_expires_at"`
+	DeletionScheduledAt    *time.Time `json:"deletion_scheduled_at,omitempty" db:"deletion_scheduled_at"`
 }
 
 // TenantStatus constants
@@ -46,6 +78,20 @@ const (
 	TenantStatusCanceled            = "canceled"
 )
 
+// Tenant suspension reasons
+const (
+	TenantSuspensionPaymentOverdue = "payment_overdue"
+	TenantSuspensionTermsViolation = "terms_violation"
+	TenantSuspensionRequested      = "requested"
+)
+
+// TenantSuspensionReasons lists the valid suspension reasons
+var TenantSuspensionReasons = []string{
+	TenantSuspensionPaymentOverdue,
+	TenantSuspensionTermsViolation,
+	TenantSuspensionRequested,
+}
+
 // PlanTier constants
 const (
 	PlanTierFree         = "free"
@@ -69,9 +115,171 @@ func (t *Tenant) IsSuspended() bool {
 	return t.Status == TenantStatusSuspended
 }
 
-// CanAccess returns true if the tenant can access the system
+// IsSandbox returns true if the tenant is a sandbox copy of another tenant
+func (t *Tenant) IsSandbox() bool {
+	return t.SandboxOf != nil
+}
+
+// IsSandboxExpired returns true if the tenant is a sandbox past its expiry
+func (t *Tenant) IsSandboxExpired() bool {
+	return t.SandboxExpiresAt != nil && time.Now().After(*t.SandboxExpiresAt)
+}
+
+// CanAccess returns true if the tenant can access the system. Suspended
+// tenants can sign in, read-only.
 func (t *Tenant) CanAccess() bool {
-	return t.Status == TenantStatusActive
+	return (t.Status == TenantStatusActive || t.Status == TenantStatusSuspended) && !t.IsSandboxExpired()
+}
+
+// IsReadOnly returns true if the tenant may read but not change its data
+func (t *Tenant) IsReadOnly() bool {
+	return t.IsSuspended()
+}
+
+// ReadOnlyReason returns why the tenant is read-only
+func (t *Tenant) ReadOnlyReason() string {
+	if t.SuspensionReason == nil {
+		return TenantSuspensionRequested
+	}
+	return *t.SuspensionReason
+}
+
+// MaintenanceWindow returns the tenant's maintenance window, or nil when none
+// is scheduled or it has ended
+func (t *Tenant) MaintenanceWindow() *MaintenanceWindow {
+	if t.MaintenanceStartsAt == nil || t.MaintenanceEndsAt == nil || !time.Now().Before(*t.MaintenanceEndsAt) {
+		return nil
+	}
+
+	window := &MaintenanceWindow{
+		StartsAt:     *t.MaintenanceStartsAt,
+		EndsAt:       *t.MaintenanceEndsAt,
+		AllowedPaths: append([]string{}, t.MaintenanceAllowedPaths...),
+		Active:       !time.Now().Before(*t.MaintenanceStartsAt),
+	}
+	if t.MaintenanceMessage != nil {
+		window.Message = *t.MaintenanceMessage
+	}
+	return window
+}
+
+// Deletion returns the tenant's requested deletion, or nil when none is
+// pending confirmation or scheduled
+func (t *Tenant) Deletion() *TenantDeletion {
+	if t.DeletionRequestedAt == nil {
+		return nil
+	}
+
+	deletion := &TenantDeletion{
+		RequestedAt: *t.DeletionRequestedAt,
+		RequestedBy: t.DeletionRequestedBy,
+	}
+	if t.DeletionScheduledAt != nil {
+		deletion.Status = TenantDeletionScheduled
+		deletion.ScheduledAt = t.DeletionScheduledAt
+		return deletion
+	}
+	if t.DeletionTokenExpiresAt == nil || !time.Now().Before(*t.DeletionTokenExpiresAt) {
+		return nil
+	}
+	deletion.Status = TenantDeletionPendingConfirmation
+	deletion.ExpiresAt = t.DeletionTokenExpiresAt
+	return deletion
+}
+
+// SSORequired returns true if the tenant's users must sign in with single
+// sign-on (the sso_required key of the tenant settings)
+func (t *Tenant) SSORequired() bool {
+	var settings struct {
+		SSORequired bool `json:"sso_required"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil {
+		return false
+	}
+	return settings.SSORequired
+}
+
+// TwoFactorPolicy returns the tenant's 2FA policy (the two_factor_policy key
+// of the tenant settings); not required when unset
+func (t *Tenant) TwoFactorPolicy() *TwoFactorPolicy {
+	var settings struct {
+		TwoFactorPolicy *TwoFactorPolicy `json:"two_factor_policy"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil || settings.TwoFactorPolicy == nil {
+		return &TwoFactorPolicy{RoleIDs: []uuid.UUID{}}
+	}
+	if settings.TwoFactorPolicy.RoleIDs == nil {
+		settings.TwoFactorPolicy.RoleIDs = []uuid.UUID{}
+	}
+	return settings.TwoFactorPolicy
+}
+
+// SessionLimit returns the tenant's concurrent session limit (the
+// session_limit key of the tenant settings), or nil when the tenant uses the
+// server default
+func (t *Tenant) SessionLimit() *SessionLimit {
+	var settings struct {
+		SessionLimit *SessionLimit `json:"session_limit"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil {
+		return nil
+	}
+	return settings.SessionLimit
+}
+
+// Branding returns the tenant's branding (the branding key of the tenant
+// settings); empty when unset
+func (t *Tenant) Branding() *TenantBranding {
+	var settings struct {
+		Branding *TenantBranding `json:"branding"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil || settings.Branding == nil {
+		return &TenantBranding{}
+	}
+	return settings.Branding
+}
+
+// Locale returns the tenant's locale defaults (the locale key of the tenant
+// settings), with DefaultTenantLocale for the ones unset
+func (t *Tenant) Locale() *TenantLocale {
+	var settings struct {
+		Locale TenantLocale `json:"locale"`
+	}
+	if len(t.Settings) > 0 {
+		_ = json.Unmarshal(t.Settings, &settings)
+	}
+	locale := settings.Locale.WithDefaults()
+	return &locale
+}
+
+// Features returns the tenant's feature toggles (the features key of the
+// tenant settings)
+func (t *Tenant) Features() map[string]bool {
+	var settings struct {
+		Features map[string]bool `json:"features"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil || settings.Features == nil {
+		return map[string]bool{}
+	}
+	return settings.Features
+}
+
+// FeatureEnabled returns true if the tenant turned a feature toggle on
+func (t *Tenant) FeatureEnabled(feature string) bool {
+	return t.Features()[feature]
+}
+
+// UsageAnalyticsOptOut returns true if the tenant refused to share its
+// anonymized feature usage (the usage_analytics_opt_out key of the tenant
+// settings)
+func (t *Tenant) UsageAnalyticsOptOut() bool {
+	var settings struct {
+		UsageAnalyticsOptOut bool `json:"usage_analytics_opt_out"`
+	}
+	if len(t.Settings) == 0 || json.Unmarshal(t.Settings, &settings) != nil {
+		return false
+	}
+	return settings.UsageAnalyticsOptOut
 }
 
 // TenantCreateRequest represents a request to create a new tenant
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantDomain is a custom domain a tenant serves its workspace from. Requests
+// to it resolve to the tenant once the domain is verified.
+type TenantDomain struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Domain   string    `json:"domain" db:"domain"`
+
+	// Verification: a TXT record named VerificationRecord must hold
+	// VerificationValue
+	VerificationToken string     `json:"-" db:"verification_token"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty" db:"last_checked_at"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// DNS record to create, filled in for responses
+	VerificationRecord string `json:"verification_record" db:"-"`
+	VerificationValue  string `json:"verification_value" db:"-"`
+}
+
+// IsVerified reports whether requests to the domain resolve to its tenant
+func (d *TenantDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// TenantDomainVerificationPrefix names the TXT record proving control of a
+// domain: _myerp-verification.<domain>
+const TenantDomainVerificationPrefix = "_myerp-verification"
+
+// TenantDomainCreateRequest adds a custom domain
+type TenantDomainCreateRequest struct {
+	Domain string `json:"domain"`
+}
+
+// Custom domain audit actions
+const (
+	ActionDomainAdded    = "domain.added"
+	ActionDomainVerified = "domain.verified"
+	ActionDomainRemoved  = "domain.removed"
+)
+
+// TenantSlugChangeRequest renames the tenant's slug
+type TenantSlugChangeRequest struct {
+	Slug string `json:"slug"`
+}
+
+// TenantSlugChange is the result of a slug rename: the old slug keeps
+// resolving to the tenant until RedirectUntil
+type TenantSlugChange struct {
+	Slug          string    `json:"slug"`
+	PreviousSlug  string    `json:"previous_slug"`
+	RedirectUntil time.Time `json:"redirect_until"`
+}
+
+// ActionTenantSlugChanged is the audit action of a slug rename
+const ActionTenantSlugChanged = "tenant.slug_changed"
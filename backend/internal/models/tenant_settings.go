@@ -0,0 +1,101 @@
+package models
+
+// TenantSettings is the typed view of a tenant's settings (the settings
+// JSONB of the tenants table). Branding, locale defaults and feature toggles
+// are changed through the tenant settings API; the security policies have
+// endpoints of their own that enforce their rules.
+type TenantSettings struct {
+	Branding TenantBranding  `json:"branding"`
+	Locale   TenantLocale    `json:"locale"`
+	Features map[string]bool `json:"features"`
+	Security TenantSecurity  `json:"security"`
+	Version  string          `json:"version"` // Changes with every change of the settings, see If-Match
+}
+
+// TenantBranding is how the tenant's name appears in the app and in emails
+// (the branding key of the tenant settings)
+type TenantBranding struct {
+	Name         *string `json:"name,omitempty"` // Shown instead of the company name, e.g. a brand
+	LogoURL      *string `json:"logo_url,omitempty"`
+	PrimaryColor *string `json:"primary_color,omitempty"` // #RRGGBB
+	AccentColor  *string `json:"accent_color,omitempty"`  // #RRGGBB
+	Footer       *string `json:"footer,omitempty"`        // Plain text closing the tenant's emails
+}
+
+// Limits of the tenant branding texts
+const (
+	MaxBrandingNameLength   = 100
+	MaxBrandingFooterLength = 500
+)
+
+// ActionTenantLogoUpdated is the audit action of tenant logo uploads and
+// removals
+const ActionTenantLogoUpdated = "tenant.logo_updated"
+
+// TenantLocale holds the defaults of the tenant's users and documents (the
+// locale key of the tenant settings)
+type TenantLocale struct {
+	Language   string `json:"language"`    // ISO 639-1, e.g. en
+	Timezone   string `json:"timezone"`    // IANA, e.g. Africa/Algiers
+	Currency   string `json:"currency"`    // ISO 4217, e.g. DZD
+	DateFormat string `json:"date_format"` // e.g. DD/MM/YYYY
+}
+
+// TenantSecurity gathers the tenant's security policies, read-only here
+type TenantSecurity struct {
+	SSORequired          bool             `json:"sso_required"`
+	TwoFactorPolicy      *TwoFactorPolicy `json:"two_factor_policy"`
+	SessionLimit         *SessionLimit    `json:"session_limit,omitempty"` // nil = server default
+	UsageAnalyticsOptOut bool             `json:"usage_analytics_opt_out"`
+}
+
+// DefaultTenantLocale is the locale of tenants that did not set one
+var DefaultTenantLocale = TenantLocale{
+	Language:   "en",
+	Timezone:   "UTC",
+	Currency:   "USD",
+	DateFormat: "YYYY-MM-DD",
+}
+
+// WithDefaults fills the unset members of a locale from DefaultTenantLocale
+func (l TenantLocale) WithDefaults() TenantLocale {
+	if l.Language == "" {
+		l.Language = DefaultTenantLocale.Language
+	}
+	if l.Timezone == "" {
+		l.Timezone = DefaultTenantLocale.Timezone
+	}
+	if l.Currency == "" {
+		l.Currency = DefaultTenantLocale.Currency
+	}
+	if l.DateFormat == "" {
+		l.DateFormat = DefaultTenantLocale.DateFormat
+	}
+	return l
+}
+
+// TenantSettingsSections are the settings keys the tenant settings API
+// changes, each with JSON merge patch semantics
+var TenantSettingsSections = []string{"branding", "locale", "features"}
+
+// TenantSettingsSecurityKeys are the settings keys of the security policies,
+// changed through their own endpoints
+var TenantSettingsSecurityKeys = map[string]string{
+	"security":                "/settings/2fa-policy, /settings/session-limit, /settings/sso and /settings/privacy",
+	"sso_required":            "/settings/sso",
+	"two_factor_policy":       "/settings/2fa-policy",
+	"session_limit":           "/settings/session-limit",
+	"usage_analytics_opt_out": "/settings/privacy",
+}
+
+// TenantLanguages are the languages a tenant may default to
+var TenantLanguages = []string{"en", "fr", "ar"}
+
+// TenantDateFormats are the date formats a tenant may default to
+var TenantDateFormats = []string{"YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY", "DD.MM.YYYY"}
+
+// MaxTenantFeatures bounds the feature toggles of a tenant
+const MaxTenantFeatures = 100
+
+// ActionTenantSettingsUpdated is the audit action of tenant settings changes
+const ActionTenantSettingsUpdated = "tenant.settings_updated"
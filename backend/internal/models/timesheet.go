@@ -0,0 +1,211 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimesheetProject is a project time is logged against, optionally billed to
+// a CRM customer
+type TimesheetProject struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	// Basic Info
+	Code        string     `json:"code" db:"code"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	CustomerID  *uuid.UUID `json:"customer_id,omitempty" db:"customer_id"`
+
+	// Billing
+	Billable   bool     `json:"billable" db:"billable"`
+	HourlyRate *float64 `json:"hourly_rate,omitempty" db:"hourly_rate"` // Tasks may override it
+	Currency   string   `json:"currency" db:"currency"`
+
+	// Status
+	IsActive bool `json:"is_active" db:"is_active"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	CustomerName *string          `json:"customer_name,omitempty" db:"customer_name"`
+	Tasks        []*TimesheetTask `json:"tasks,omitempty" db:"-"`
+}
+
+// TimesheetTask is a task within a timesheet project
+type TimesheetTask struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	ProjectID  uuid.UUID `json:"project_id" db:"project_id"`
+	Name       string    `json:"name" db:"name"`
+	Billable   bool      `json:"billable" db:"billable"`
+	HourlyRate *float64  `json:"hourly_rate,omitempty" db:"hourly_rate"` // Nil: the project's rate applies
+	IsActive   bool      `json:"is_active" db:"is_active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Timesheet is an employee's time for one ISO week
+type Timesheet struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	EmployeeID uuid.UUID `json:"employee_id" db:"employee_id"`
+	WeekStart  time.Time `json:"week_start" db:"week_start"` // Monday
+	TotalHours float64   `json:"total_hours" db:"total_hours"`
+
+	// Workflow
+	Status       string     `json:"status" db:"status"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	ApproverID   *uuid.UUID `json:"approver_id,omitempty" db:"approver_id"` // User of the employee's manager
+	DecidedBy    *uuid.UUID `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+	DecisionNote *string    `json:"decision_note,omitempty" db:"decision_note"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Computed fields (not in database)
+	EmployeeName   string            `json:"employee_name" db:"employee_name"`
+	EmployeeUserID *uuid.UUID        `json:"employee_user_id,omitempty" db:"employee_user_id"`
+	Entries        []*TimesheetEntry `json:"entries,omitempty" db:"-"`
+}
+
+// IsEditable returns true while entries may still change: the timesheet is
+// a draft or was sent back
+func (t *Timesheet) IsEditable() bool {
+	return t.Status == TimesheetStatusDraft || t.Status == TimesheetStatusRejected
+}
+
+// WeekEnd returns the Sunday of the timesheet's week
+func (t *Timesheet) WeekEnd() time.Time {
+	return t.WeekStart.AddDate(0, 0, 6)
+}
+
+// TimesheetEntry is time logged on a day against a project and task
+type TimesheetEntry struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	TimesheetID uuid.UUID  `json:"timesheet_id" db:"timesheet_id"`
+	ProjectID   uuid.UUID  `json:"project_id" db:"project_id"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty" db:"task_id"`
+	WorkDate    time.Time  `json:"work_date" db:"work_date"`
+	Hours       float64    `json:"hours" db:"hours"`
+	Description *string    `json:"description,omitempty" db:"description"`
+
+	// Billing, fixed when the entry is written
+	Billable   bool     `json:"billable" db:"billable"`
+	HourlyRate *float64 `json:"hourly_rate,omitempty" db:"hourly_rate"`
+
+	// Metadata
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+
+	// Computed fields (not in database)
+	ProjectCode string  `json:"project_code" db:"project_code"`
+	ProjectName string  `json:"project_name" db:"project_name"`
+	TaskName    *string `json:"task_name,omitempty" db:"task_name"`
+}
+
+// Timesheet status constants
+const (
+	TimesheetStatusDraft     = "draft"
+	TimesheetStatusSubmitted = "submitted"
+	TimesheetStatusApproved  = "approved"
+	TimesheetStatusRejected  = "rejected"
+)
+
+// TimesheetStatuses lists the valid timesheet statuses, for validation
+var TimesheetStatuses = []string{TimesheetStatusDraft, TimesheetStatusSubmitted, TimesheetStatusApproved, TimesheetStatusRejected}
+
+// Permission resource constant
+const (
+	ResourceTimesheets = "timesheets"
+)
+
+// Permission actions for timesheets. Employees log and submit their own time
+// and managers decide for their direct reports without any permission.
+const (
+	ActionManageTimesheets = "manage" // Projects and tasks, reopening approved timesheets
+)
+
+// TimesheetFilter filters timesheet lists
+type TimesheetFilter struct {
+	EmployeeID   *uuid.UUID
+	DepartmentID *uuid.UUID
+	ApproverID   *uuid.UUID // Timesheets routed to this user or to the employee's manager's user
+	Status       string
+	From         *time.Time // Weeks starting on or after
+	To           *time.Time // Weeks starting on or before
+}
+
+// TimesheetProjectRequest represents a request to create or update a
+// project. On update, omitted fields are left unchanged.
+type TimesheetProjectRequest struct {
+	Code        *string    `json:"code,omitempty"`
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	CustomerID  *uuid.UUID `json:"customer_id,omitempty"`
+	Billable    *bool      `json:"billable,omitempty"`
+	HourlyRate  *float64   `json:"hourly_rate,omitempty"`
+	Currency    *string    `json:"currency,omitempty"`
+	IsActive    *bool      `json:"is_active,omitempty"`
+}
+
+// TimesheetTaskRequest represents a request to create or update a task.
+// On update, omitted fields are left unchanged.
+type TimesheetTaskRequest struct {
+	Name       *string  `json:"name,omitempty"`
+	Billable   *bool    `json:"billable,omitempty"`
+	HourlyRate *float64 `json:"hourly_rate,omitempty"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+// TimesheetEntryRequest represents a request to log or change time. The
+// entry goes on the caller's timesheet for the week of WorkDate.
+type TimesheetEntryRequest struct {
+	ProjectID   uuid.UUID  `json:"project_id"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty"`
+	WorkDate    time.Time  `json:"work_date"`
+	Hours       float64    `json:"hours"`
+	Description *string    `json:"description,omitempty"`
+}
+
+// TimesheetDecisionRequest represents an approval, rejection or reopening of
+// a timesheet
+type TimesheetDecisionRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// TimesheetReportFilter selects the time a billing report covers
+type TimesheetReportFilter struct {
+	From              time.Time
+	To                time.Time
+	ProjectID         *uuid.UUID
+	CustomerID        *uuid.UUID
+	EmployeeID        *uuid.UUID
+	IncludeUnapproved bool // Also count draft and submitted weeks
+}
+
+// TimesheetReportRow is the time of one project or one employee over a
+// report's period. Amounts are billable hours times the entries' rates, in
+// the project's currency; rows are split per currency where projects differ.
+type TimesheetReportRow struct {
+	ProjectID    *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+	ProjectCode  *string    `json:"project_code,omitempty" db:"project_code"`
+	ProjectName  *string    `json:"project_name,omitempty" db:"project_name"`
+	CustomerID   *uuid.UUID `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerName *string    `json:"customer_name,omitempty" db:"customer_name"`
+	EmployeeID   *uuid.UUID `json:"employee_id,omitempty" db:"employee_id"`
+	EmployeeName *string    `json:"employee_name,omitempty" db:"employee_name"`
+
+	Hours          float64 `json:"hours" db:"hours"`
+	BillableHours  float64 `json:"billable_hours" db:"billable_hours"`
+	BillableAmount float64 `json:"billable_amount" db:"billable_amount"`
+	Currency       string  `json:"currency" db:"currency"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactorPolicy is a tenant's requirement for its users to set up a
+// second factor (the two_factor_policy key of the tenant settings). Users it
+// applies to sign in without one until their grace period ends, then must
+// set one up to sign in.
+type TwoFactorPolicy struct {
+	Required        bool        `json:"required"`
+	RoleIDs         []uuid.UUID `json:"role_ids"` // Users with any of these roles; empty for every user
+	GracePeriodDays int         `json:"grace_period_days"`
+	EnforcedSince   *time.Time  `json:"enforced_since,omitempty"` // When it was turned on
+}
+
+// SetupDeadline is when a user's grace period ends: the grace period counts
+// from when the policy was turned on, or from when the user was created if
+// later
+func (p *TwoFactorPolicy) SetupDeadline(userCreatedAt time.Time) time.Time {
+	start := userCreatedAt
+	if p.EnforcedSince != nil && p.EnforcedSince.After(start) {
+		start = *p.EnforcedSince
+	}
+	return start.AddDate(0, 0, p.GracePeriodDays)
+}
+
+// TwoFactorStatus describes a user's second factors and what the tenant's
+// 2FA policy asks of them
+type TwoFactorStatus struct {
+	Enabled       bool       `json:"enabled"`
+	Methods       []string   `json:"methods"`                  // totp | webauthn
+	SetupDeadline *time.Time `json:"setup_deadline,omitempty"` // Set while the policy requires them to set one up
+	PasskeyOnly   bool       `json:"passkey_only"`
+}
+
+// TwoFactorPolicyUpdateRequest represents a request to change a tenant's 2FA
+// policy. Omitted fields are left unchanged.
+type TwoFactorPolicyUpdateRequest struct {
+	Required        *bool        `json:"required,omitempty"`
+	RoleIDs         *[]uuid.UUID `json:"role_ids,omitempty"`
+	GracePeriodDays *int         `json:"grace_period_days,omitempty" validate:"omitempty,min=0,max=90"`
+}
+
+// TwoFactorSetupBeginRequest starts setting up 2FA during a sign-in the
+// tenant's policy blocked
+type TwoFactorSetupBeginRequest struct {
+	TwoFactorToken string `json:"two_factor_token"`
+}
+
+// TwoFactorSetupFinishRequest enables 2FA and completes the blocked sign-in
+type TwoFactorSetupFinishRequest struct {
+	TwoFactorToken   string   `json:"two_factor_token"`
+	Secret           string   `json:"secret"`
+	VerificationCode string   `json:"verification_code"`
+	BackupCodes      []string `json:"backup_codes"`
+	RememberMe       bool     `json:"remember_me"`
+}
+
+// MaxTwoFactorGracePeriodDays is the longest grace period a policy may give
+const MaxTwoFactorGracePeriodDays = 90
+
+// ActionTwoFactorPolicyUpdated is the audit action of 2FA policy changes
+const ActionTwoFactorPolicyUpdated = "2fa.policy_updated"
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Usage stat kinds
+const (
+	UsageKindEndpoint    = "endpoint"     // Requests to an endpoint
+	UsageKindModule      = "module"       // Requests to any endpoint of a module
+	UsageKindActiveUsers = "active_users" // Distinct users active during the day
+)
+
+// UsageStat is a tenant's usage of a feature over a UTC day. Tenants are only
+// identified by a pseudonymous reference.
+type UsageStat struct {
+	Day       time.Time `json:"day" db:"day"`
+	TenantRef string    `json:"tenant_ref" db:"tenant_ref"`
+	Kind      string    `json:"kind" db:"kind"`
+	Name      string    `json:"name" db:"name"` // Endpoint, module or empty for active users
+	Count     int64     `json:"count" db:"count"`
+}
+
+// UsageExport is the payload sent to the usage analytics sink
+type UsageExport struct {
+	Day   string      `json:"day"` // YYYY-MM-DD
+	Stats []UsageStat `json:"stats"`
+}
+
+// PrivacySettings are a tenant's privacy settings
+type PrivacySettings struct {
+	UsageAnalytics bool `json:"usage_analytics"` // Anonymized feature usage is shared with the product team
+}
+
+// PrivacySettingsUpdateRequest represents a request to change a tenant's
+// privacy settings
+type PrivacySettingsUpdateRequest struct {
+	UsageAnalytics *bool `json:"usage_analytics,omitempty"`
+}
+
+// Privacy audit actions
+const (
+	ActionPrivacySettingsUpdated = "privacy.settings_updated"
+)
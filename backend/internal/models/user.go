@@ -34,6 +34,17 @@ type User struct {
 	ResetToken          *uuid.UUID `json:"-" db:"reset_token"`
 	ResetTokenExpiresAt *time.Time `json:"-" db:"reset_token_expires_at"`
 
+	// Email change: the new address is used once confirmed through the token
+	// emailed to it
+	PendingEmail         *string    `json:"pending_email,omitempty" db:"pending_email"`
+	EmailChangeToken     *uuid.UUID `json:"-" db:"email_change_token"`
+	EmailChangeExpiresAt *time.Time `json:"-" db:"email_change_expires_at"`
+
+	// Undeliverable email: the provider reported a permanent bounce or a spam
+	// complaint, so no more emails are sent to the address
+	EmailUndeliverableAt     *time.Time `json:"email_undeliverable_at,omitempty" db:"email_undeliverable_at"`
+	EmailUndeliverableReason *string    `json:"email_undeliverable_reason,omitempty" db:"email_undeliverable_reason"`
+
 	// Two-Factor Authentication
 	TwoFactorEnabled       bool           `json:"two_factor_enabled" db:"two_factor_enabled"`
 	TwoFactorSecret        *string        `json:"-" db:"two_factor_secret"` // Encrypted
@@ -41,6 +52,9 @@ type User struct {
 	TwoFactorEnabledAt     *time.Time     `json:"two_factor_enabled_at,omitempty" db:"two_factor_enabled_at"`
 	TwoFactorRecoveryEmail *string        `json:"two_factor_recovery_email,omitempty" db:"two_factor_recovery_email"`
 
+	// Passkeys: when set, the user can only sign in with a WebAuthn credential
+	PasskeyOnly bool `json:"passkey_only" db:"passkey_only"`
+
 	// Activity tracking
 	LastLoginAt   *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
 	LastLoginIP   *string    `json:"last_login_ip,omitempty" db:"last_login_ip"`
@@ -51,11 +65,22 @@ type User struct {
 	Language    string          `json:"language" db:"language"`
 	Preferences json.RawMessage `json:"preferences,omitempty" db:"preferences"`
 
+	// Values of the tenant's custom fields, by key (see CustomField)
+	CustomFields json.RawMessage `json:"custom_fields" db:"custom_fields"`
+
 	// Metadata
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
 
+	// Soft delete: deleted users are hidden and can be restored until purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty" db:"deleted_by"`
+
+	// Erasure: personal data anonymized at the user's request, see
+	// PersonalDataErasureRequest
+	ErasedAt *time.Time `json:"erased_at,omitempty" db:"erased_at"`
+
 	// Computed fields (not in database)
 	Roles       []Role       `json:"roles,omitempty" db:"-"`
 	Permissions []Permission `json:"permissions,omitempty" db:"-"`
@@ -69,6 +94,21 @@ const (
 	UserStatusPending     = "pending"
 )
 
+// UserStatuses lists the user statuses
+var UserStatuses = []string{UserStatusActive, UserStatusSuspended, UserStatusDeactivated, UserStatusPending}
+
+// UserFilter narrows a list of users
+type UserFilter struct {
+	Status       string
+	RoleID       *uuid.UUID
+	DepartmentID *uuid.UUID
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+
+	// Custom field values the users must have, as a JSON object
+	CustomFields json.RawMessage
+}
+
 // IsActive returns true if the user is active
 func (u *User) IsActive() bool {
 	return u.Status == UserStatusActive
@@ -97,6 +137,8 @@ type UserCreateRequest struct {
 	LastName  string `json:"last_name" validate:"required,min=1,max=100"`
 	Phone     string `json:"phone,omitempty" validate:"omitempty,phone"`
 	RoleIDs   []uuid.UUID `json:"role_ids,omitempty"` // Roles to assign
+
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 // UserUpdateRequest represents a request to update a user
@@ -107,6 +149,23 @@ type UserUpdateRequest struct {
 	AvatarURL *string `json:"avatar_url,omitempty"`
 	Timezone  *string `json:"timezone,omitempty"`
 	Language  *string `json:"language,omitempty"`
+
+	// Custom field values to set; fields left out keep their value
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
+
+	// The updated_at the change is based on, if not sent in If-Match
+	Version *string `json:"version,omitempty"`
+}
+
+// UserEmailChangeRequest represents a request to change one's own email
+type UserEmailChangeRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password"` // Current password, unless the user has none (single sign-on)
+}
+
+// UserEmailChangeConfirmRequest confirms an email change with the emailed token
+type UserEmailChangeConfirmRequest struct {
+	Token string `json:"token" validate:"required"`
 }
 
 // UserLoginRequest represents a login request
@@ -119,14 +178,17 @@ type UserLoginRequest struct {
 
 // UserLoginResponse represents a login response
 type UserLoginResponse struct {
-	User              *User      `json:"user,omitempty"`
-	Tenant            *Tenant    `json:"tenant,omitempty"`
-	Tenants           []*Tenant  `json:"tenants,omitempty"` // For multi-tenant selection
-	AccessToken       string     `json:"access_token,omitempty"`
-	RefreshToken      string     `json:"refresh_token,omitempty"`
-	ExpiresIn         int64      `json:"expires_in,omitempty"` // Seconds until expiration
-	RequiresTwoFactor bool       `json:"requires_two_factor,omitempty"`
-	TwoFactorToken    string     `json:"two_factor_token,omitempty"` // Temporary token for 2FA verification
+	User                   *User      `json:"user,omitempty"`
+	Tenant                 *Tenant    `json:"tenant,omitempty"`
+	Tenants                []*Tenant  `json:"tenants,omitempty"` // For multi-tenant selection
+	AccessToken            string     `json:"access_token,omitempty"`
+	RefreshToken           string     `json:"refresh_token,omitempty"`
+	ExpiresIn              int64      `json:"expires_in,omitempty"` // Seconds until expiration
+	RequiresTwoFactor      bool       `json:"requires_two_factor,omitempty"`
+	TwoFactorToken         string     `json:"two_factor_token,omitempty"`          // Temporary token for 2FA verification
+	TwoFactorMethods       []string   `json:"two_factor_methods,omitempty"`        // Second factors the user can use: totp | webauthn
+	RequiresTwoFactorSetup bool       `json:"requires_two_factor_setup,omitempty"` // The tenant's 2FA policy blocks sign-in until 2FA is set up with the two_factor_token
+	TwoFactorSetupDeadline *time.Time `json:"two_factor_setup_deadline,omitempty"` // When the grace period of the tenant's 2FA policy ends
 }
 
 // UserPasswordResetRequest represents a password reset request
@@ -145,3 +207,37 @@ type UserChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
+
+// UserExport is a user as downloaded from the user export. Roles and
+// department are names, as the user import reads them.
+type UserExport struct {
+	ID               uuid.UUID      `json:"id" db:"id"`
+	Email            string         `json:"email" db:"email"`
+	FirstName        string         `json:"first_name" db:"first_name"`
+	LastName         string         `json:"last_name" db:"last_name"`
+	Phone            *string        `json:"phone,omitempty" db:"phone"`
+	Roles            pq.StringArray `json:"roles" db:"roles"`
+	Department       *string        `json:"department,omitempty" db:"department"`
+	Status           string         `json:"status" db:"status"`
+	EmailVerified    bool           `json:"email_verified" db:"email_verified"`
+	TwoFactorEnabled bool           `json:"two_factor_enabled" db:"two_factor_enabled"`
+	LastLoginAt      *time.Time     `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+}
+
+// UserExportColumns are the columns of CSV and XLSX user exports. Those the
+// user import reads have the same names and format.
+var UserExportColumns = []string{
+	"id",
+	UserImportColumnEmail,
+	UserImportColumnFirstName,
+	UserImportColumnLastName,
+	UserImportColumnPhone,
+	UserImportColumnRoles,
+	UserImportColumnDepartment,
+	"status",
+	"email_verified",
+	"two_factor_enabled",
+	"last_login_at",
+	"created_at",
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// JobTypeUserImport is the async job type of user imports too large to run
+// within the request
+const JobTypeUserImport = "user_import"
+
+// Columns of a user import file. The header row names them, in any order and
+// case; email, first_name and last_name are required.
+const (
+	UserImportColumnEmail      = "email"
+	UserImportColumnFirstName  = "first_name"
+	UserImportColumnLastName   = "last_name"
+	UserImportColumnPhone      = "phone"
+	UserImportColumnRoles      = "roles"      // Role names separated by ";" or ","
+	UserImportColumnDepartment = "department" // Department name
+)
+
+// UserImportRow is one user read from an import file
+type UserImportRow struct {
+	Row        int      `json:"row"` // Line in the file; the header is line 1
+	Email      string   `json:"email"`
+	FirstName  string   `json:"first_name"`
+	LastName   string   `json:"last_name"`
+	Phone      string   `json:"phone,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
+	Department string   `json:"department,omitempty"`
+}
+
+// UserImportJobParams are the parameters of a user import job
+type UserImportJobParams struct {
+	FileName string          `json:"file_name"`
+	Rows     []UserImportRow `json:"rows"`
+}
+
+// Row statuses of a user import report
+const (
+	UserImportRowValid    = "valid"    // Dry run: the row would be imported
+	UserImportRowInvalid  = "invalid"  // The row has validation errors and was skipped
+	UserImportRowImported = "imported" // The user was created
+	UserImportRowFailed   = "failed"   // The row was valid but creating the user failed
+)
+
+// UserImportRowResult is the outcome of one row of an import
+type UserImportRowResult struct {
+	Row    int               `json:"row"`
+	Email  string            `json:"email"`
+	Status string            `json:"status"`
+	UserID *uuid.UUID        `json:"user_id,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"` // Column -> message
+}
+
+// UserImportReport is the row-by-row outcome of an import or dry run
+type UserImportReport struct {
+	FileName  string                `json:"file_name"`
+	DryRun    bool                  `json:"dry_run"`
+	TotalRows int                   `json:"total_rows"`
+	Valid     int                   `json:"valid"`
+	Invalid   int                   `json:"invalid"`
+	Imported  int                   `json:"imported"`
+	Failed    int                   `json:"failed"`
+	Rows      []UserImportRowResult `json:"rows"`
+}
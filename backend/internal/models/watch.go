@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordWatch is a user watching a record: they are notified when someone
+// else updates, comments on or deletes it. Records are identified like in
+// recently viewed lists (see NavigationEntityDepartment, EntityCustomer...).
+type RecordWatch struct {
+	TenantID   uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id" db:"entity_id"`
+	Reason     string    `json:"reason" db:"reason"` // manual | created | assigned
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	// Computed fields; nil when the record is gone or the user may no
+	// longer view it
+	Entity *EntitySummary `json:"entity,omitempty" db:"-"`
+}
+
+// RecordWatcher is a user watching a record
+type RecordWatcher struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	LastName  string    `json:"last_name" db:"last_name"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// RecordWatchers are the users watching a record, and whether the current
+// user is one of them
+type RecordWatchers struct {
+	EntityType string          `json:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id"`
+	Watching   bool            `json:"watching"`
+	Watchers   []RecordWatcher `json:"watchers"`
+}
+
+// Why a user watches a record
+const (
+	WatchReasonManual   = "manual"   // The user chose to watch it
+	WatchReasonCreated  = "created"  // The user created it
+	WatchReasonAssigned = "assigned" // The user was made its owner or head
+)
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WebAuthnCredential is a passkey or security key registered by a user
+type WebAuthnCredential struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	TenantID       uuid.UUID      `json:"-" db:"tenant_id"`
+	UserID         uuid.UUID      `json:"user_id" db:"user_id"`
+	CredentialID   []byte         `json:"-" db:"credential_id"`
+	PublicKey      []byte         `json:"-" db:"public_key"` // COSE_Key
+	SignCount      int64          `json:"-" db:"sign_count"`
+	AAGUID         *uuid.UUID     `json:"aaguid,omitempty" db:"aaguid"`
+	Transports     pq.StringArray `json:"transports" db:"transports"`
+	BackupEligible bool           `json:"backup_eligible" db:"backup_eligible"` // A synced passkey rather than a device-bound key
+	Name           string         `json:"name" db:"name"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	LastUsedAt     *time.Time     `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// Second factors a password sign-in can be completed with
+const (
+	TwoFactorMethodTOTP       = "totp"
+	TwoFactorMethodWebAuthn   = "webauthn"
+	TwoFactorMethodBackupCode = "backup_code" // One of the backup codes given when enabling TOTP
+	TwoFactorMethodBypassCode = "bypass_code" // Emailed one-time code, for users who lost their authenticator
+)
+
+// WebAuthnCredentialJSON is a credential returned by navigator.credentials
+// create() or get(), as serialized by PublicKeyCredential.toJSON(): binary
+// values are base64url
+type WebAuthnCredentialJSON struct {
+	ID       string                        `json:"id"`
+	RawID    string                        `json:"rawId"`
+	Type     string                        `json:"type"`
+	Response WebAuthnAuthenticatorResponse `json:"response"`
+}
+
+// WebAuthnAuthenticatorResponse is the authenticator's response of a
+// registration (attestationObject) or sign-in (authenticatorData, signature)
+type WebAuthnAuthenticatorResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject,omitempty"`
+	Transports        []string `json:"transports,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,omitempty"`
+	Signature         string   `json:"signature,omitempty"`
+	UserHandle        string   `json:"userHandle,omitempty"`
+}
+
+// WebAuthnRegisterRequest completes the registration of a credential
+type WebAuthnRegisterRequest struct {
+	Name       string                 `json:"name" validate:"max=100"` // Defaults to "Passkey"
+	Credential WebAuthnCredentialJSON `json:"credential"`
+}
+
+// WebAuthnCredentialUpdateRequest renames a credential
+type WebAuthnCredentialUpdateRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// WebAuthnPasskeyOnlyRequest turns passkey-only sign-in on or off
+type WebAuthnPasskeyOnlyRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// WebAuthnLoginBeginRequest starts a passkey sign-in. Without an email the
+// authenticator offers the passkeys it holds for the site.
+type WebAuthnLoginBeginRequest struct {
+	Email string `json:"email,omitempty"`
+}
+
+// WebAuthnLoginFinishRequest completes a passkey sign-in
+type WebAuthnLoginFinishRequest struct {
+	Credential WebAuthnCredentialJSON `json:"credential"`
+	RememberMe bool                   `json:"remember_me"`
+}
+
+// WebAuthn2FABeginRequest starts a security key second factor
+type WebAuthn2FABeginRequest struct {
+	TwoFactorToken string `json:"two_factor_token"`
+}
+
+// WebAuthn2FAFinishRequest completes a password sign-in with a security key
+type WebAuthn2FAFinishRequest struct {
+	TwoFactorToken string                 `json:"two_factor_token"`
+	Credential     WebAuthnCredentialJSON `json:"credential"`
+	RememberMe     bool                   `json:"remember_me"`
+}
+
+// WebAuthnCreationOptions are the options to pass to
+// navigator.credentials.create() (PublicKeyCredentialCreationOptions, with
+// base64url binary values, as PublicKeyCredential.parseCreationOptionsFromJSON
+// takes them)
+type WebAuthnCreationOptions struct {
+	RP                     WebAuthnRP                     `json:"rp"`
+	User                   WebAuthnUser                   `json:"user"`
+	Challenge              string                         `json:"challenge"`
+	PubKeyCredParams       []WebAuthnCredentialParam      `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"` // Milliseconds
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions are the options to pass to
+// navigator.credentials.get() (PublicKeyCredentialRequestOptions, with
+// base64url binary values)
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	Timeout          int64                          `json:"timeout"` // Milliseconds
+	RPID             string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+// WebAuthnRP is the relying party credentials are scoped to
+type WebAuthnRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WebAuthnUser is the account a credential is registered for
+type WebAuthnUser struct {
+	ID          string `json:"id"` // User handle
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// WebAuthnCredentialParam is an accepted credential type and algorithm
+type WebAuthnCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// WebAuthnCredentialDescriptor identifies a credential
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// WebAuthnAuthenticatorSelection tells which authenticators may register
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// WebAuthn audit actions
+const (
+	ActionWebAuthnCredentialRegistered = "webauthn.credential_registered"
+	ActionWebAuthnCredentialRenamed    = "webauthn.credential_renamed"
+	ActionWebAuthnCredentialRemoved    = "webauthn.credential_removed"
+	ActionWebAuthnPasskeyOnlyChanged   = "webauthn.passkey_only_changed"
+)
+
+// AuditResourceWebAuthnCredentials is the audit resource type of credentials
+const AuditResourceWebAuthnCredentials = "webauthn_credentials"
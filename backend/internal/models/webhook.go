@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Webhook is an endpoint a tenant registered to be notified of events
+type Webhook struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	URL         string         `json:"url" db:"url"`
+	Description *string        `json:"description,omitempty" db:"description"`
+	EventTypes  pq.StringArray `json:"event_types" db:"event_types"`
+	Secret      string         `json:"-" db:"secret"` // Encrypted signing secret
+	IsActive    bool           `json:"is_active" db:"is_active"`
+
+	// Categories or field names redacted from event objects before delivery
+	RedactionRules pq.StringArray `json:"redaction_rules" db:"redaction_rules"`
+
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDelivery is one event posted (or to be posted) to a webhook
+type WebhookDelivery struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	TenantID  uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	WebhookID uuid.UUID `json:"webhook_id" db:"webhook_id"`
+
+	EventID   uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType string          `json:"event_type" db:"event_type"`
+	Payload   json.RawMessage `json:"payload" db:"payload"`
+
+	// Status: pending | delivered | failed
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	MaxAttempts    int        `json:"max_attempts" db:"max_attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      *string    `json:"last_error,omitempty" db:"last_error"`
+	ResponseStatus *int       `json:"response_status,omitempty" db:"response_status"`
+	ResponseBody   *string    `json:"response_body,omitempty" db:"response_body"`
+	DurationMs     *int       `json:"duration_ms,omitempty" db:"duration_ms"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+
+	// Trace context of the request that queued it, if traced
+	TraceContext *json.RawMessage `json:"-" db:"trace_context"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ClaimedWebhookDelivery is a due delivery with its webhook's endpoint, as
+// claimed by the webhook worker
+type ClaimedWebhookDelivery struct {
+	WebhookDelivery
+	URL           string `db:"url"`
+	Secret        string `db:"secret"`
+	WebhookActive bool   `db:"webhook_active"`
+}
+
+// Webhook delivery status constants
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// ResourceWebhooks is the permission resource for webhook management
+const ResourceWebhooks = "webhooks"
+
+// WebhookEventAll subscribes a webhook to every event type
+const WebhookEventAll = "*"
+
+// WebhookEventTest is sent by the test endpoint, regardless of subscriptions
+const WebhookEventTest = "webhook.test"
+
+// WebhookEvent is the JSON body posted to webhook endpoints
+type WebhookEvent struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      string           `json:"type"`
+	TenantID  uuid.UUID        `json:"tenant_id"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData describes what changed. Object is the resource after the
+// change (before it, for deletions), when available.
+type WebhookEventData struct {
+	ResourceType string          `json:"resource_type"`
+	ResourceID   *uuid.UUID      `json:"resource_id,omitempty"`
+	ActorID      *uuid.UUID      `json:"actor_id,omitempty"`
+	Object       json.RawMessage `json:"object,omitempty"`
+}
+
+// Redacted returns a copy of the event whose object has the values matched
+// by the rules redacted
+func (e *WebhookEvent) Redacted(rules []string) (*WebhookEvent, error) {
+	object, err := RedactJSON(e.Data.Object, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := *e
+	redacted.Data.Object = object
+	return &redacted, nil
+}
+
+// WebhookCreateRequest represents a request to register a webhook
+type WebhookCreateRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description,omitempty"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1"`
+
+	// Optional: categories (emails, phones, amounts) or field names to redact
+	RedactionRules []string `json:"redaction_rules,omitempty"`
+}
+
+// WebhookUpdateRequest represents a request to change a webhook
+type WebhookUpdateRequest struct {
+	URL         *string   `json:"url,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	EventTypes  *[]string `json:"event_types,omitempty"`
+	IsActive    *bool     `json:"is_active,omitempty"`
+
+	RedactionRules *[]string `json:"redaction_rules,omitempty"` // Replaces the rules; empty removes them
+}
@@ -0,0 +1,144 @@
+package pagination
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/utils"
+)
+
+// Where builds the WHERE clause of a list query and its arguments. Conditions
+// number their placeholders with %d, e.g. "status = $%d", and Add fills in
+// the positions of their arguments.
+type Where struct {
+	conditions []string
+	args       []interface{}
+}
+
+// Add adds a condition with one argument per placeholder
+func (w *Where) Add(condition string, args ...interface{}) {
+	positions := make([]interface{}, len(args))
+	for i := range args {
+		positions[i] = len(w.args) + i + 1
+	}
+	w.conditions = append(w.conditions, fmt.Sprintf(condition, positions...))
+	w.args = append(w.args, args...)
+}
+
+// Equal adds column = value, unless value is empty
+func (w *Where) Equal(column, value string) {
+	if value != "" {
+		w.Add(column+" = $%d", value)
+	}
+}
+
+// EqualID adds column = id, unless id is nil
+func (w *Where) EqualID(column string, id *uuid.UUID) {
+	if id != nil {
+		w.Add(column+" = $%d", *id)
+	}
+}
+
+// Range adds the bounds of a date range on column that are set. Both are
+// inclusive.
+func (w *Where) Range(column string, from, to *time.Time) {
+	if from != nil {
+		w.Add(column+" >= $%d", *from)
+	}
+	if to != nil {
+		w.Add(column+" <= $%d", *to)
+	}
+}
+
+// SQL returns the conditions joined with AND, prefixed with WHERE, or "" when
+// there are none
+func (w *Where) SQL() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// Args returns the arguments of the conditions, then of Limit if called
+func (w *Where) Args() []interface{} {
+	return w.args
+}
+
+// Next returns the position of the next placeholder
+func (w *Where) Next() int {
+	return len(w.args) + 1
+}
+
+// Filter parameters are read from the query string. An invalid value is an
+// INVALID_FILTER error rather than being ignored, so a typo doesn't silently
+// widen a list.
+
+// Enum reads a filter that must be one of allowed
+func Enum(r *http.Request, name string, allowed []string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return value, nil
+		}
+	}
+	return "", invalidFilter(name)
+}
+
+// UUID reads an ID filter
+func UUID(r *http.Request, name string) (*uuid.UUID, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, invalidFilter(name)
+	}
+	return &id, nil
+}
+
+// Time reads a date filter, in RFC 3339 or as a plain date (YYYY-MM-DD)
+func Time(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, invalidFilter(name)
+		}
+	}
+	return &t, nil
+}
+
+// DateRange reads the two ends of a date range filter, e.g. created_from and
+// created_to. A plain date as the end includes that whole day.
+func DateRange(r *http.Request, fromName, toName string) (*time.Time, *time.Time, error) {
+	from, err := Time(r, fromName)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := Time(r, toName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if to != nil && len(r.URL.Query().Get(toName)) == len("2006-01-02") {
+		end := to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		to = &end
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return nil, nil, invalidFilter(toName)
+	}
+	return from, to, nil
+}
+
+func invalidFilter(name string) error {
+	return utils.NewBadRequestError("INVALID_FILTER", fmt.Sprintf("invalid %s filter", name))
+}
@@ -0,0 +1,202 @@
+// Package pagination implements the paging, sorting and filtering shared by
+// list endpoints. A list pages either by offset, with page and page_size, or
+// by cursor: a cursor names the last row of the previous page and the next
+// page starts after it (keyset pagination), so pages stay stable while rows
+// are added and deep pages cost no more than the first. Each list whitelists
+// the fields it can be sorted by; the row ID always breaks ties, which makes
+// the order total and a cursor unambiguous.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/utils"
+)
+
+// Page sizes
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Sort is the order of a list: a sort field, ascending unless Desc
+type Sort struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d,omitempty"`
+}
+
+// Spec describes how a list can be sorted. Columns maps each sort field
+// clients may use to its SQL column; sortable columns must be NOT NULL, as
+// cursors compare their values.
+type Spec struct {
+	Columns  map[string]string
+	Default  Sort
+	IDColumn string // Unique column breaking ties, e.g. "id"
+}
+
+// Request is the page of a list a client asked for
+type Request struct {
+	Page     int
+	PageSize int
+	Sort     Sort
+	Cursor   *Cursor
+
+	spec Spec
+}
+
+// Cursor points at the last row of a page: its value of the sort column and
+// its ID. It carries the sort it was issued for, as it means nothing in
+// another order.
+type Cursor struct {
+	Sort  Sort      `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Parse reads the page a list request asks for from its query parameters:
+// page and page_size, or cursor; and sort, a field of spec optionally
+// prefixed with "-" for descending order, e.g. sort=-created_at
+func Parse(r *http.Request, spec Spec) (*Request, error) {
+	query := r.URL.Query()
+	req := &Request{Sort: spec.Default, spec: spec}
+
+	req.Page, _ = strconv.Atoi(query.Get("page"))
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	req.PageSize, _ = strconv.Atoi(query.Get("page_size"))
+	if req.PageSize < 1 || req.PageSize > MaxPageSize {
+		req.PageSize = DefaultPageSize
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		field := strings.TrimPrefix(sort, "-")
+		if _, ok := spec.Columns[field]; !ok {
+			return nil, utils.NewBadRequestError("INVALID_SORT", fmt.Sprintf("invalid sort field: %s", field))
+		}
+		req.Sort = Sort{Field: field, Desc: strings.HasPrefix(sort, "-")}
+	}
+
+	if encoded := query.Get("cursor"); encoded != "" {
+		cursor, err := decodeCursor(encoded)
+		if err != nil {
+			return nil, utils.NewBadRequestError("INVALID_CURSOR", "invalid cursor")
+		}
+		// The cursor keeps its sort unless the request names the same one
+		if query.Get("sort") == "" {
+			req.Sort = cursor.Sort
+		}
+		if _, ok := spec.Columns[cursor.Sort.Field]; !ok || cursor.Sort != req.Sort {
+			return nil, utils.NewBadRequestError("INVALID_CURSOR", "invalid cursor")
+		}
+		req.Cursor = cursor
+	}
+
+	return req, nil
+}
+
+// Offset returns the number of rows to skip; none when paging by cursor
+func (p *Request) Offset() int {
+	if p.Cursor != nil {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// Apply adds the condition selecting the rows after the cursor, if any.
+// Call it after counting the rows matching the filters.
+func (p *Request) Apply(where *Where) {
+	if p.Cursor == nil {
+		return
+	}
+	op := ">"
+	if p.Sort.Desc {
+		op = "<"
+	}
+	where.Add(fmt.Sprintf("(%s, %s) %s ($%%d, $%%d)", p.column(), p.spec.IDColumn, op), p.Cursor.Value, p.Cursor.ID)
+}
+
+// OrderBy returns the ORDER BY clause of the requested sort
+func (p *Request) OrderBy() string {
+	dir := "ASC"
+	if p.Sort.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, %s %s", p.column(), dir, p.spec.IDColumn, dir)
+}
+
+// Limit returns the LIMIT and OFFSET clause, adding its arguments to where.
+// One row more than the page size is read to tell whether a next page exists;
+// Cut removes it.
+func (p *Request) Limit(where *Where) string {
+	n := where.Next()
+	where.args = append(where.args, p.PageSize+1, p.Offset())
+	return fmt.Sprintf("LIMIT $%d OFFSET $%d", n, n+1)
+}
+
+// Meta returns the pagination metadata of a response
+func (p *Request) Meta(totalCount int, nextCursor string) *utils.Meta {
+	meta := utils.NewMeta(p.Page, p.PageSize, totalCount)
+	if p.Cursor != nil {
+		// Page numbers don't apply when paging by cursor
+		meta.Page = 0
+		meta.TotalPages = 0
+	}
+	meta.NextCursor = nextCursor
+	return meta
+}
+
+func (p *Request) column() string {
+	return p.spec.Columns[p.Sort.Field]
+}
+
+// Cut trims the extra row read by Limit and returns the cursor to the next
+// page, or "" on the last page. key returns a row's value of the given sort
+// field and its ID.
+func Cut[T any](p *Request, rows []T, key func(row *T, field string) (interface{}, uuid.UUID)) ([]T, string) {
+	if len(rows) <= p.PageSize {
+		return rows, ""
+	}
+	rows = rows[:p.PageSize]
+
+	value, id := key(&rows[len(rows)-1], p.Sort.Field)
+	return rows, encodeCursor(&Cursor{Sort: p.Sort, Value: cursorValue(value), ID: id})
+}
+
+// cursorValue formats a sort value as Postgres parses it back
+func cursorValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func encodeCursor(cursor *Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
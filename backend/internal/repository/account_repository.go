@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// AccountRepository handles database operations for the chart of accounts
+type AccountRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountRepository creates a new account repository
+func NewAccountRepository(db *sqlx.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Create creates a new account with RLS
+func (r *AccountRepository) Create(ctx context.Context, tenantID uuid.UUID, account *models.Account) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO accounts (tenant_id, code, name, description, account_type, parent_id, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		account.Code,
+		account.Name,
+		account.Description,
+		account.AccountType,
+		account.ParentID,
+		account.IsActive,
+		account.CreatedBy,
+	).Scan(&account.ID, &account.CreatedAt, &account.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	account.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindByID retrieves an account by ID with RLS
+func (r *AccountRepository) FindByID(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var account models.Account
+	// Explicit tenant_id filter for defense in depth
+	query := `SELECT * FROM accounts WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &account, query, tenantID, accountID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ACCOUNT_NOT_FOUND", "account not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	return &account, nil
+}
+
+// FindByIDs retrieves the given accounts keyed by ID; unknown IDs are omitted
+func (r *AccountRepository) FindByIDs(ctx context.Context, tenantID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]models.Account, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]string, len(accountIDs))
+	for i, id := range accountIDs {
+		ids[i] = id.String()
+	}
+
+	accounts := []models.Account{}
+	query := `SELECT * FROM accounts WHERE tenant_id = $1 AND id = ANY($2::uuid[])`
+	if err := tx.SelectContext(ctx, &accounts, query, tenantID, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to find accounts: %w", err)
+	}
+
+	result := make(map[uuid.UUID]models.Account, len(accounts))
+	for _, account := range accounts {
+		result[account.ID] = account
+	}
+
+	return result, nil
+}
+
+// List retrieves accounts ordered by code, optionally filtered by type and active flag
+func (r *AccountRepository) List(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	accounts := []models.Account{}
+	query := `
+		SELECT * FROM accounts
+		WHERE tenant_id = $1
+		AND ($2 = '' OR account_type = $2)
+		AND (NOT $3 OR is_active = TRUE)
+		AND ($4 = '' OR code ILIKE $4 || '%' OR name ILIKE '%' || $4 || '%')
+		ORDER BY code ASC
+	`
+
+	if err := tx.SelectContext(ctx, &accounts, query, tenantID, accountType, activeOnly, search); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// Update updates an account's information
+func (r *AccountRepository) Update(ctx context.Context, tenantID uuid.UUID, account *models.Account) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE accounts
+		SET code = $1,
+			name = $2,
+			description = $3,
+			parent_id = $4,
+			is_active = $5,
+			updated_at = NOW()
+		WHERE tenant_id = $6 AND id = $7
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		account.Code,
+		account.Name,
+		account.Description,
+		account.ParentID,
+		account.IsActive,
+		tenantID,
+		account.ID,
+	).Scan(&account.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("ACCOUNT_NOT_FOUND", "account not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes an account that has no journal lines and no child accounts
+func (r *AccountRepository) Delete(ctx context.Context, tenantID, accountID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lineCount int
+	err = tx.GetContext(ctx, &lineCount, `SELECT COUNT(*) FROM journal_entry_lines WHERE tenant_id = $1 AND account_id = $2`, tenantID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to check account usage: %w", err)
+	}
+	if lineCount > 0 {
+		return utils.NewConflictError("ACCOUNT_IN_USE", "account has journal entries, deactivate it instead")
+	}
+
+	var childCount int
+	err = tx.GetContext(ctx, &childCount, `SELECT COUNT(*) FROM accounts WHERE tenant_id = $1 AND parent_id = $2`, tenantID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to check child accounts: %w", err)
+	}
+	if childCount > 0 {
+		return utils.NewConflictError("ACCOUNT_IN_USE", "account has child accounts")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM accounts WHERE tenant_id = $1 AND id = $2`, tenantID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ACCOUNT_NOT_FOUND", "account not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckCodeExists checks if an account code already exists for a tenant
+func (r *AccountRepository) CheckCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if excludeID != nil {
+		err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM accounts WHERE tenant_id = $1 AND code = $2 AND id != $3`, tenantID, code, *excludeID)
+	} else {
+		err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM accounts WHERE tenant_id = $1 AND code = $2`, tenantID, code)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check account code: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// IsDescendant returns true if candidateID is accountID itself or one of its
+// descendants. Used to prevent cycles when re-parenting accounts.
+func (r *AccountRepository) IsDescendant(ctx context.Context, tenantID, accountID, candidateID uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM accounts WHERE tenant_id = $1 AND id = $2
+			UNION
+			SELECT a.id FROM accounts a
+			JOIN subtree s ON a.parent_id = s.id
+			WHERE a.tenant_id = $1
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $3)
+	`
+
+	if err := tx.GetContext(ctx, &exists, query, tenantID, accountID, candidateID); err != nil {
+		return false, fmt.Errorf("failed to check account hierarchy: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// AccountingPeriodRepository handles database operations for posting periods
+type AccountingPeriodRepository struct {
+	db *sqlx.DB
+}
+
+// NewAccountingPeriodRepository creates a new accounting period repository
+func NewAccountingPeriodRepository(db *sqlx.DB) *AccountingPeriodRepository {
+	return &AccountingPeriodRepository{db: db}
+}
+
+// Create creates a posting period. Periods of a tenant may not overlap; the
+// check runs under a per-tenant advisory lock so concurrent creates cannot
+// both pass it.
+func (r *AccountingPeriodRepository) Create(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := database.AdvisoryXactLock(ctx, tx.Tx, tenantID.String()+":accounting_periods"); err != nil {
+		return fmt.Errorf("failed to lock accounting periods: %w", err)
+	}
+
+	var overlapping int
+	err = tx.GetContext(ctx, &overlapping, `
+		SELECT COUNT(*) FROM accounting_periods
+		WHERE tenant_id = $1 AND start_date <= $3 AND end_date >= $2
+	`, tenantID, period.StartDate, period.EndDate)
+	if err != nil {
+		return fmt.Errorf("failed to check overlapping periods: %w", err)
+	}
+	if overlapping > 0 {
+		return utils.NewConflictError("PERIOD_OVERLAP", "period overlaps an existing period")
+	}
+
+	query := `
+		INSERT INTO accounting_periods (tenant_id, name, start_date, end_date, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		period.Name,
+		period.StartDate,
+		period.EndDate,
+		period.Status,
+		period.CreatedBy,
+	).Scan(&period.ID, &period.CreatedAt, &period.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create accounting period: %w", err)
+	}
+
+	period.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindByID retrieves a posting period by ID
+func (r *AccountingPeriodRepository) FindByID(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var period models.AccountingPeriod
+	err = tx.GetContext(ctx, &period, `SELECT * FROM accounting_periods WHERE tenant_id = $1 AND id = $2 LIMIT 1`, tenantID, periodID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ACCOUNTING_PERIOD_NOT_FOUND", "accounting period not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	return &period, nil
+}
+
+// FindForDate retrieves the posting period containing the given date
+func (r *AccountingPeriodRepository) FindForDate(ctx context.Context, tenantID uuid.UUID, date time.Time) (*models.AccountingPeriod, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var period models.AccountingPeriod
+	query := `
+		SELECT * FROM accounting_periods
+		WHERE tenant_id = $1 AND start_date <= $2 AND end_date >= $2
+		LIMIT 1
+	`
+
+	err = tx.GetContext(ctx, &period, query, tenantID, date)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewBadRequestError("NO_ACCOUNTING_PERIOD", "no accounting period covers this date")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting period: %w", err)
+	}
+
+	return &period, nil
+}
+
+// List retrieves posting periods, most recent first
+func (r *AccountingPeriodRepository) List(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	periods := []models.AccountingPeriod{}
+	query := `
+		SELECT * FROM accounting_periods
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY start_date DESC
+	`
+
+	if err := tx.SelectContext(ctx, &periods, query, tenantID, status); err != nil {
+		return nil, fmt.Errorf("failed to list accounting periods: %w", err)
+	}
+
+	return periods, nil
+}
+
+// CountDraftEntries counts draft journal entries dated within the period
+func (r *AccountingPeriodRepository) CountDraftEntries(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) (int, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	query := `
+		SELECT COUNT(*) FROM journal_entries
+		WHERE tenant_id = $1 AND status = 'draft' AND entry_date BETWEEN $2 AND $3
+	`
+
+	if err := tx.GetContext(ctx, &count, query, tenantID, period.StartDate, period.EndDate); err != nil {
+		return 0, fmt.Errorf("failed to count draft journal entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateStatus closes or reopens a posting period
+func (r *AccountingPeriodRepository) UpdateStatus(ctx context.Context, tenantID, periodID, userID uuid.UUID, fromStatus, toStatus string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE accounting_periods
+		SET status = $1,
+			closed_at = CASE WHEN $1 = 'closed' THEN NOW() ELSE NULL END,
+			closed_by = CASE WHEN $1 = 'closed' THEN $2::uuid ELSE NULL END,
+			updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4 AND status = $5
+	`
+
+	result, err := tx.ExecContext(ctx, query, toStatus, userID, tenantID, periodID, fromStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update accounting period status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("STATUS_CHANGED", "accounting period status has changed, please reload")
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// AnnouncementRepository handles database operations for announcements and
+// their read receipts
+type AnnouncementRepository struct {
+	db *sqlx.DB
+}
+
+// NewAnnouncementRepository creates a new announcement repository
+func NewAnnouncementRepository(db *sqlx.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+// announcementColumns selects an announcement (a) with its status and the
+// number of users who read it
+const announcementColumns = `
+	a.*,
+	CASE
+		WHEN a.publish_at > NOW() THEN 'scheduled'
+		WHEN a.expires_at <= NOW() THEN 'expired'
+		ELSE 'active'
+	END AS status,
+	(SELECT COUNT(*) FROM announcement_reads ar WHERE ar.tenant_id = a.tenant_id AND ar.announcement_id = a.id) AS read_count
+`
+
+// announcementPublished matches announcements (a) shown right now
+const announcementPublished = `a.publish_at <= NOW() AND (a.expires_at IS NULL OR a.expires_at > NOW())`
+
+// announcementAudience matches the users (u) an announcement (a) is
+// addressed to
+const announcementAudience = `(
+	a.audience = 'all'
+	OR (a.audience = 'departments' AND u.department_id = ANY(a.department_ids))
+	OR (a.audience = 'roles' AND EXISTS (
+		SELECT 1 FROM user_roles ur
+		WHERE ur.tenant_id = u.tenant_id AND ur.user_id = u.id AND ur.role_id = ANY(a.role_ids)
+		  AND ` + activeUserRole + `
+	))
+)`
+
+// Create saves a new announcement
+func (r *AnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	tx, err := database.WithTenantContext(ctx, r.db, announcement.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO announcements (
+			tenant_id, title, body, audience, role_ids, department_ids, publish_at, expires_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		announcement.TenantID,
+		announcement.Title,
+		announcement.Body,
+		announcement.Audience,
+		announcement.RoleIDs,
+		announcement.DepartmentIDs,
+		announcement.PublishAt,
+		announcement.ExpiresAt,
+		announcement.CreatedBy,
+	).Scan(&announcement.ID, &announcement.CreatedAt, &announcement.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update replaces an announcement's content, audience and schedule. An
+// announcement moved into the future is notified again once it is published.
+func (r *AnnouncementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	tx, err := database.WithTenantContext(ctx, r.db, announcement.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE announcements
+		SET title = $1, body = $2, audience = $3, role_ids = $4, department_ids = $5,
+		    publish_at = $6, expires_at = $7,
+		    notified_at = CASE WHEN $6 > NOW() THEN NULL ELSE notified_at END
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		announcement.Title,
+		announcement.Body,
+		announcement.Audience,
+		announcement.RoleIDs,
+		announcement.DepartmentIDs,
+		announcement.PublishAt,
+		announcement.ExpiresAt,
+		announcement.TenantID,
+		announcement.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update announcement: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("ANNOUNCEMENT_NOT_FOUND", "announcement not found")
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes an announcement with its read receipts
+func (r *AnnouncementRepository) Delete(ctx context.Context, tenantID, announcementID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM announcements WHERE tenant_id = $1 AND id = $2`, tenantID, announcementID)
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("ANNOUNCEMENT_NOT_FOUND", "announcement not found")
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves an announcement
+func (r *AnnouncementRepository) FindByID(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var announcement models.Announcement
+	query := `SELECT ` + announcementColumns + ` FROM announcements a WHERE a.tenant_id = $1 AND a.id = $2`
+
+	err = tx.GetContext(ctx, &announcement, query, tenantID, announcementID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ANNOUNCEMENT_NOT_FOUND", "announcement not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find announcement: %w", err)
+	}
+
+	return &announcement, nil
+}
+
+// List retrieves a tenant's announcements, latest publish time first,
+// optionally only those with a status (scheduled, active or expired)
+func (r *AnnouncementRepository) List(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE a.tenant_id = $1
+		  AND ($2 = '' OR
+		       ($2 = 'scheduled' AND a.publish_at > NOW()) OR
+		       ($2 = 'active' AND ` + announcementPublished + `) OR
+		       ($2 = 'expired' AND a.expires_at <= NOW()))
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM announcements a `+where, tenantID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+
+	announcements := []models.Announcement{}
+	query := `
+		SELECT ` + announcementColumns + `
+		FROM announcements a
+		` + where + `
+		ORDER BY a.publish_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	if err := tx.SelectContext(ctx, &announcements, query, tenantID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+
+	return announcements, totalCount, nil
+}
+
+// ListReads retrieves the users who read an announcement, most recent first
+func (r *AnnouncementRepository) ListReads(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM announcement_reads WHERE tenant_id = $1 AND announcement_id = $2`
+	if err := tx.GetContext(ctx, &totalCount, countQuery, tenantID, announcementID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcement reads: %w", err)
+	}
+
+	reads := []models.AnnouncementRead{}
+	query := `
+		SELECT ar.user_id, u.email, u.first_name, u.last_name, ar.read_at
+		FROM announcement_reads ar
+		JOIN users u ON u.tenant_id = ar.tenant_id AND u.id = ar.user_id
+		WHERE ar.tenant_id = $1 AND ar.announcement_id = $2
+		ORDER BY ar.read_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	if err := tx.SelectContext(ctx, &reads, query, tenantID, announcementID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcement reads: %w", err)
+	}
+
+	return reads, totalCount, nil
+}
+
+// ListForUser retrieves the published announcements addressed to a user,
+// newest first, with when the user read them
+func (r *AnnouncementRepository) ListForUser(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	from := `
+		FROM announcements a
+		JOIN users u ON u.tenant_id = a.tenant_id AND u.id = $2
+		LEFT JOIN announcement_reads ar ON ar.tenant_id = a.tenant_id AND ar.announcement_id = a.id AND ar.user_id = u.id
+		WHERE a.tenant_id = $1
+		  AND ` + announcementPublished + `
+		  AND ` + announcementAudience + `
+		  AND (NOT $3 OR ar.read_at IS NULL)
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) `+from, tenantID, userID, unreadOnly); err != nil {
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+
+	announcements := []models.UserAnnouncement{}
+	query := `
+		SELECT a.id, a.title, a.body, a.publish_at, a.expires_at, ar.read_at
+		` + from + `
+		ORDER BY a.publish_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	if err := tx.SelectContext(ctx, &announcements, query, tenantID, userID, unreadOnly, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+
+	return announcements, totalCount, nil
+}
+
+// MarkRead records that a user read a published announcement addressed to
+// them. Reading it again keeps the first read time.
+func (r *AnnouncementRepository) MarkRead(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var visible bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM announcements a
+			JOIN users u ON u.tenant_id = a.tenant_id AND u.id = $2
+			WHERE a.tenant_id = $1 AND a.id = $3
+			  AND ` + announcementPublished + `
+			  AND ` + announcementAudience + `
+		)
+	`
+	if err := tx.GetContext(ctx, &visible, query, tenantID, userID, announcementID); err != nil {
+		return fmt.Errorf("failed to find announcement: %w", err)
+	}
+	if !visible {
+		return utils.NewNotFoundError("ANNOUNCEMENT_NOT_FOUND", "announcement not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO announcement_reads (tenant_id, announcement_id, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, announcement_id, user_id) DO NOTHING
+	`, tenantID, announcementID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark announcement read: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ClaimPublished marks up to limit published announcements whose audience was
+// not notified yet as notified, across tenants, and returns them. It runs in a
+// transaction bypassing RLS.
+func (r *AnnouncementRepository) ClaimPublished(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.Announcement, error) {
+	announcements := []models.Announcement{}
+	query := `
+		UPDATE announcements
+		SET notified_at = NOW()
+		WHERE (tenant_id, id) IN (
+			SELECT a.tenant_id, a.id FROM announcements a
+			WHERE a.notified_at IS NULL AND ` + announcementPublished + `
+			ORDER BY a.publish_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	if err := tx.SelectContext(ctx, &announcements, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim announcements: %w", err)
+	}
+
+	return announcements, nil
+}
+
+// ListAudienceIDs returns the active users an announcement is addressed to
+func (r *AnnouncementRepository) ListAudienceIDs(ctx context.Context, tx *sqlx.Tx, announcement *models.Announcement) ([]uuid.UUID, error) {
+	userIDs := []uuid.UUID{}
+	query := `
+		SELECT u.id
+		FROM announcements a
+		JOIN users u ON u.tenant_id = a.tenant_id
+		WHERE a.tenant_id = $1 AND a.id = $2
+		  AND u.status = $3 AND u.deleted_at IS NULL
+		  AND ` + announcementAudience + `
+	`
+
+	if err := tx.SelectContext(ctx, &userIDs, query, announcement.TenantID, announcement.ID, models.UserStatusActive); err != nil {
+		return nil, fmt.Errorf("failed to list announcement audience: %w", err)
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// ApprovalLinkRepository handles database operations for approval links
+type ApprovalLinkRepository struct {
+	db *sqlx.DB
+}
+
+// NewApprovalLinkRepository creates a new approval link repository
+func NewApprovalLinkRepository(db *sqlx.DB) *ApprovalLinkRepository {
+	return &ApprovalLinkRepository{db: db}
+}
+
+// Create saves a new link
+func (r *ApprovalLinkRepository) Create(ctx context.Context, link *models.ApprovalLink) error {
+	tx, err := database.WithTenantContext(ctx, r.db, link.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO approval_links (tenant_id, resource_type, resource_id, approver_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, failed_attempts, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		link.TenantID,
+		link.ResourceType,
+		link.ResourceID,
+		link.ApproverID,
+		link.ExpiresAt,
+	).Scan(&link.ID, &link.FailedAttempts, &link.CreatedAt, &link.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create approval link: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a link
+func (r *ApprovalLinkRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.ApprovalLink, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var link models.ApprovalLink
+	err = tx.GetContext(ctx, &link, `SELECT * FROM approval_links WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid approval link")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find approval link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// Lock starts a transaction holding the link's row lock, so concurrent uses
+// of the same link are decided one after the other. The caller must commit
+// or roll back the transaction.
+func (r *ApprovalLinkRepository) Lock(ctx context.Context, tenantID, id uuid.UUID) (*database.Tx, *models.ApprovalLink, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var link models.ApprovalLink
+	err = tx.GetContext(ctx, &link, `SELECT * FROM approval_links WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, id)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("invalid approval link")
+		}
+		return nil, nil, fmt.Errorf("failed to lock approval link: %w", err)
+	}
+
+	return tx, &link, nil
+}
+
+// MarkUsed records the decision made with a link and revokes the other links
+// to the same approval
+func (r *ApprovalLinkRepository) MarkUsed(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, decision, stepUp, ip, userAgent string) error {
+	query := `
+		UPDATE approval_links
+		SET used_at = NOW(), decision = $1, step_up = $2,
+			used_ip = NULLIF($3, '')::inet, used_user_agent = NULLIF($4, '')
+		WHERE tenant_id = $5 AND id = $6
+	`
+	if _, err := tx.ExecContext(ctx, query, decision, stepUp, ip, userAgent, link.TenantID, link.ID); err != nil {
+		return fmt.Errorf("failed to mark approval link used: %w", err)
+	}
+
+	query = `
+		UPDATE approval_links
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND resource_type = $2 AND resource_id = $3
+			AND id != $4 AND used_at IS NULL AND revoked_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, query, link.TenantID, link.ResourceType, link.ResourceID, link.ID); err != nil {
+		return fmt.Errorf("failed to revoke approval links: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailedAttempt counts a wrong verification code, revoking the link
+// once maxAttempts is reached. Returns true if the link was revoked.
+func (r *ApprovalLinkRepository) RecordFailedAttempt(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, maxAttempts int) (bool, error) {
+	var revoked bool
+	query := `
+		UPDATE approval_links
+		SET failed_attempts = failed_attempts + 1,
+			revoked_at = CASE WHEN failed_attempts + 1 >= $1 THEN NOW() ELSE revoked_at END
+		WHERE tenant_id = $2 AND id = $3
+		RETURNING revoked_at IS NOT NULL
+	`
+	if err := tx.QueryRowContext(ctx, query, maxAttempts, link.TenantID, link.ID).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to record approval link attempt: %w", err)
+	}
+	return revoked, nil
+}
+
+// Revoke makes a link unusable
+func (r *ApprovalLinkRepository) Revoke(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink) error {
+	query := `UPDATE approval_links SET revoked_at = NOW() WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, link.TenantID, link.ID); err != nil {
+		return fmt.Errorf("failed to revoke approval link: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredBefore removes links in db that expired before cutoff
+func (r *ApprovalLinkRepository) DeleteExpiredBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM approval_links WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete approval links: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete approval links: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
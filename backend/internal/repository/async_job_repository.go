@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// AsyncJobRepository handles database operations for async jobs
+type AsyncJobRepository struct {
+	db *sqlx.DB
+}
+
+// NewAsyncJobRepository creates a new async job repository
+func NewAsyncJobRepository(db *sqlx.DB) *AsyncJobRepository {
+	return &AsyncJobRepository{db: db}
+}
+
+// Create queues a job within the caller's transaction, so modules can queue
+// work atomically with the records it belongs to. The tenant may have at most
+// maxQueued queued jobs (0 for no limit).
+func (r *AsyncJobRepository) Create(ctx context.Context, tx *sqlx.Tx, job *models.AsyncJob, maxQueued int) error {
+	if len(job.Params) == 0 {
+		job.Params = json.RawMessage(`{}`)
+	}
+	job.Status = models.AsyncJobStatusQueued
+
+	query := `
+		INSERT INTO async_jobs (tenant_id, job_type, params, status, requested_by)
+		SELECT $1::uuid, $2::text, $3::jsonb, $4::text, $5::uuid
+		WHERE $6::int = 0 OR (
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM async_jobs
+				WHERE tenant_id = $1 AND status = 'queued'
+				LIMIT $6
+			) queued
+		) < $6
+		RETURNING id, progress, cancel_requested, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(ctx, query,
+		job.TenantID,
+		job.JobType,
+		job.Params,
+		job.Status,
+		job.RequestedBy,
+		maxQueued,
+	).Scan(&job.ID, &job.Progress, &job.CancelRequested, &job.CreatedAt, &job.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewTooManyRequestsError("JOB_QUEUE_FULL", "job queue is full")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a job
+func (r *AsyncJobRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AsyncJob, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var job models.AsyncJob
+	err = tx.GetContext(ctx, &job, `SELECT * FROM async_jobs WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("JOB_NOT_FOUND", "job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ListByRequester retrieves the jobs a user started, newest first. Empty
+// jobType and status match all jobs.
+func (r *AsyncJobRepository) ListByRequester(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND requested_by = $2 AND ($3 = '' OR job_type = $3) AND ($4 = '' OR status = $4)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM async_jobs `+where, tenantID, userID, jobType, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	jobs := []models.AsyncJob{}
+	query := `SELECT * FROM async_jobs ` + where + ` ORDER BY created_at DESC LIMIT $5 OFFSET $6`
+
+	if err := tx.SelectContext(ctx, &jobs, query, tenantID, userID, jobType, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, totalCount, nil
+}
+
+// RequestCancel cancels a queued job right away and flags a running one, so
+// the worker stops it at its next check. Returns the updated job.
+func (r *AsyncJobRepository) RequestCancel(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.AsyncJob, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var job models.AsyncJob
+	query := `
+		UPDATE async_jobs
+		SET cancel_requested = true,
+		    cancelled_by = $1,
+		    status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END,
+		    completed_at = CASE WHEN status = 'queued' THEN NOW() ELSE completed_at END,
+		    updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND status IN ('queued', 'running')
+		RETURNING *
+	`
+
+	err = tx.GetContext(ctx, &job, query, userID, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewConflictError("JOB_FINISHED", "job has already finished")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// ClaimNext marks the oldest queued job in db as running and returns it,
+// skipping rows another worker is claiming and jobs of paused tenants.
+// Returns nil when the queue is empty.
+func (r *AsyncJobRepository) ClaimNext(ctx context.Context, db *sqlx.DB, paused []uuid.UUID) (*models.AsyncJob, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var job models.AsyncJob
+	query := `
+		UPDATE async_jobs
+		SET status = $1, started_at = NOW(), updated_at = NOW()
+		WHERE (tenant_id, id) = (
+			SELECT tenant_id, id FROM async_jobs
+			WHERE status = 'queued' AND tenant_id != ALL($2::uuid[])
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	err = tx.GetContext(ctx, &job, query, models.AsyncJobStatusRunning, pq.Array(paused))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// UpdateProgress records a running job's progress and reports whether a
+// user has asked to cancel it
+func (r *AsyncJobRepository) UpdateProgress(ctx context.Context, job *models.AsyncJob, progress int, message string) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, job.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var cancelRequested bool
+	query := `
+		UPDATE async_jobs
+		SET progress = $1, progress_message = NULLIF($2, ''), updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4 AND status = 'running'
+		RETURNING cancel_requested
+	`
+
+	err = tx.GetContext(ctx, &cancelRequested, query, progress, message, job.TenantID, job.ID)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("job is no longer running")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update job progress: %w", err)
+	}
+
+	return cancelRequested, tx.Commit()
+}
+
+// IsCancelRequested reports whether a user has asked to cancel a job
+func (r *AsyncJobRepository) IsCancelRequested(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var cancelRequested bool
+	err = tx.GetContext(ctx, &cancelRequested, `SELECT cancel_requested FROM async_jobs WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to check job: %w", err)
+	}
+
+	return cancelRequested, nil
+}
+
+// Finish records a job's final status together with its result or error
+func (r *AsyncJobRepository) Finish(ctx context.Context, job *models.AsyncJob, status string, result json.RawMessage, cause string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, job.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var resultValue interface{}
+	if len(result) > 0 {
+		resultValue = result
+	}
+
+	query := `
+		UPDATE async_jobs
+		SET status = $1,
+		    progress = CASE WHEN $2 THEN 100 ELSE progress END,
+		    result = $3,
+		    error = NULLIF($4, ''),
+		    completed_at = NOW(),
+		    updated_at = NOW()
+		WHERE tenant_id = $5 AND id = $6
+	`
+	succeeded := status == models.AsyncJobStatusSucceeded
+	if _, err := tx.ExecContext(ctx, query, status, succeeded, resultValue, cause, job.TenantID, job.ID); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FailInterrupted fails jobs in db left running by a worker that stopped
+// (crash or restart). They are not retried, since their work may be partly
+// applied. Returns the number of jobs failed.
+func (r *AsyncJobRepository) FailInterrupted(ctx context.Context, db *sqlx.DB) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE async_jobs
+		SET status = CASE WHEN cancel_requested THEN 'cancelled' ELSE 'failed' END,
+		    error = CASE WHEN cancel_requested THEN NULL ELSE 'interrupted: the worker stopped while the job was running' END,
+		    completed_at = NOW(),
+		    updated_at = NOW()
+		WHERE status = 'running'
+	`
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// DeleteFinishedBefore removes jobs in db that finished before cutoff
+func (r *AsyncJobRepository) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM async_jobs
+		WHERE status IN ('succeeded', 'failed', 'cancelled') AND completed_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// QueueStats measures the queued and failed jobs of every tenant in db
+func (r *AsyncJobRepository) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Queued jobs are due as soon as they are queued
+	var stats models.QueueStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'queued') AS pending,
+			COUNT(*) FILTER (WHERE status = 'queued') AS due,
+			COUNT(*) FILTER (WHERE status = 'failed') AS dead_letters,
+			MIN(created_at) FILTER (WHERE status = 'queued') AS oldest_due_at
+		FROM async_jobs
+		WHERE status IN ('queued', 'failed')
+	`
+
+	if err := tx.GetContext(ctx, &stats, query); err != nil {
+		return nil, fmt.Errorf("failed to measure job queue: %w", err)
+	}
+
+	return &stats, nil
+}
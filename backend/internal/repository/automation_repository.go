@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// AutomationRepository handles database operations for automation rules and
+// their executions
+type AutomationRepository struct {
+	db *sqlx.DB
+}
+
+// NewAutomationRepository creates a new automation repository
+func NewAutomationRepository(db *sqlx.DB) *AutomationRepository {
+	return &AutomationRepository{db: db}
+}
+
+// Create saves a new rule
+func (r *AutomationRepository) Create(ctx context.Context, rule *models.AutomationRule) error {
+	conditions, actions, err := marshalAutomationRule(rule)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, rule.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO automation_rules (tenant_id, name, description, trigger_event, conditions, actions, is_enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		rule.TenantID,
+		rule.Name,
+		rule.Description,
+		rule.TriggerEvent,
+		conditions,
+		actions,
+		rule.IsEnabled,
+		rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create automation rule: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a rule
+func (r *AutomationRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AutomationRule, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rule models.AutomationRule
+	err = tx.GetContext(ctx, &rule, `SELECT * FROM automation_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("AUTOMATION_RULE_NOT_FOUND", "automation rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find automation rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// List retrieves a tenant's rules by name
+func (r *AutomationRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM automation_rules WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count automation rules: %w", err)
+	}
+
+	rules := []models.AutomationRule{}
+	query := `SELECT * FROM automation_rules WHERE tenant_id = $1 ORDER BY name LIMIT $2 OFFSET $3`
+	if err := tx.SelectContext(ctx, &rules, query, tenantID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list automation rules: %w", err)
+	}
+
+	return rules, totalCount, nil
+}
+
+// FindEnabledByTrigger retrieves the enabled rules triggered by eventType
+func (r *AutomationRepository) FindEnabledByTrigger(ctx context.Context, tenantID uuid.UUID, eventType string) ([]models.AutomationRule, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rules := []models.AutomationRule{}
+	query := `
+		SELECT * FROM automation_rules
+		WHERE tenant_id = $1 AND trigger_event = $2 AND is_enabled = true
+		ORDER BY created_at
+	`
+	if err := tx.SelectContext(ctx, &rules, query, tenantID, eventType); err != nil {
+		return nil, fmt.Errorf("failed to find automation rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// CheckNameExists checks if a rule name is already used in the tenant
+func (r *AutomationRepository) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM automation_rules
+			WHERE tenant_id = $1 AND name = $2 AND ($3::uuid IS NULL OR id != $3)
+		)
+	`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, name, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check automation rule name: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Update saves a rule's definition and state
+func (r *AutomationRepository) Update(ctx context.Context, rule *models.AutomationRule) error {
+	conditions, actions, err := marshalAutomationRule(rule)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, rule.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE automation_rules
+		SET name = $1, description = $2, trigger_event = $3, conditions = $4, actions = $5, is_enabled = $6, updated_at = NOW()
+		WHERE tenant_id = $7 AND id = $8
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		rule.Name,
+		rule.Description,
+		rule.TriggerEvent,
+		conditions,
+		actions,
+		rule.IsEnabled,
+		rule.TenantID,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("AUTOMATION_RULE_NOT_FOUND", "automation rule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update automation rule: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetEnabled enables or disables a rule
+func (r *AutomationRepository) SetEnabled(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.AutomationRule, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rule models.AutomationRule
+	query := `
+		UPDATE automation_rules
+		SET is_enabled = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+		RETURNING *
+	`
+	err = tx.GetContext(ctx, &rule, query, enabled, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("AUTOMATION_RULE_NOT_FOUND", "automation rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update automation rule: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update automation rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// Delete removes a rule and its execution log
+func (r *AutomationRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM automation_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("AUTOMATION_RULE_NOT_FOUND", "automation rule not found")
+	}
+
+	return tx.Commit()
+}
+
+// EnqueueExecutions queues one execution per triggered rule and stamps the
+// rules' last trigger time
+func (r *AutomationRepository) EnqueueExecutions(ctx context.Context, tenantID uuid.UUID, ruleIDs []uuid.UUID, event *models.AutomationEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode automation event: %w", err)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO automation_executions (tenant_id, rule_id, event_id, event_type, event)
+		SELECT $1, rule_id, $2, $3, $4
+		FROM unnest($5::uuid[]) AS rule_id
+	`
+	if _, err := tx.ExecContext(ctx, query, tenantID, event.ID, event.Type, string(payload), pq.Array(ruleIDs)); err != nil {
+		return fmt.Errorf("failed to queue automation executions: %w", err)
+	}
+
+	query = `UPDATE automation_rules SET last_triggered_at = NOW() WHERE tenant_id = $1 AND id = ANY($2::uuid[])`
+	if _, err := tx.ExecContext(ctx, query, tenantID, pq.Array(ruleIDs)); err != nil {
+		return fmt.Errorf("failed to update automation rules: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListExecutions retrieves executions, newest first, with their rule's name.
+// A nil ruleID lists every rule's executions; an empty status lists all.
+func (r *AutomationRepository) ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE e.tenant_id = $1 AND ($2::uuid IS NULL OR e.rule_id = $2) AND ($3 = '' OR e.status = $3)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM automation_executions e `+where, tenantID, ruleID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count automation executions: %w", err)
+	}
+
+	executions := []models.AutomationExecution{}
+	query := `
+		SELECT e.*, ru.name AS rule_name
+		FROM automation_executions e
+		JOIN automation_rules ru ON ru.tenant_id = e.tenant_id AND ru.id = e.rule_id
+		` + where + `
+		ORDER BY e.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	if err := tx.SelectContext(ctx, &executions, query, tenantID, ruleID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list automation executions: %w", err)
+	}
+
+	return executions, totalCount, nil
+}
+
+// FindExecution retrieves one execution with its rule's name
+func (r *AutomationRepository) FindExecution(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var execution models.AutomationExecution
+	query := `
+		SELECT e.*, ru.name AS rule_name
+		FROM automation_executions e
+		JOIN automation_rules ru ON ru.tenant_id = e.tenant_id AND ru.id = e.rule_id
+		WHERE e.tenant_id = $1 AND e.id = $2
+	`
+	err = tx.GetContext(ctx, &execution, query, tenantID, executionID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("AUTOMATION_EXECUTION_NOT_FOUND", "automation execution not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find automation execution: %w", err)
+	}
+
+	return &execution, nil
+}
+
+// ClaimPending locks the oldest pending execution together with its rule,
+// skipping rows another worker already holds and executions of paused
+// tenants. Returns nil when nothing is pending.
+func (r *AutomationRepository) ClaimPending(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ClaimedAutomationExecution, error) {
+	var execution models.ClaimedAutomationExecution
+	query := `
+		SELECT e.*, ru.name AS rule_name, ru.actions, ru.is_enabled AS rule_enabled, ru.created_by AS rule_created_by
+		FROM automation_executions e
+		JOIN automation_rules ru ON ru.tenant_id = e.tenant_id AND ru.id = e.rule_id
+		WHERE e.status = 'pending' AND e.tenant_id != ALL($1::uuid[])
+		ORDER BY e.created_at
+		LIMIT 1
+		FOR UPDATE OF e SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &execution, query, pq.Array(paused))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim automation execution: %w", err)
+	}
+
+	return &execution, nil
+}
+
+// Finish records the outcome of an execution's actions
+func (r *AutomationRepository) Finish(ctx context.Context, tx *sqlx.Tx, execution *models.ClaimedAutomationExecution, status string, results models.AutomationActionResults, startedAt time.Time) error {
+	payload, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode automation action results: %w", err)
+	}
+
+	query := `
+		UPDATE automation_executions
+		SET status = $1, action_results = $2, started_at = $3, finished_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $4 AND id = $5
+	`
+	if _, err := tx.ExecContext(ctx, query, status, string(payload), startedAt, execution.TenantID, execution.ID); err != nil {
+		return fmt.Errorf("failed to finish automation execution: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinishedBefore removes executions in db that finished before cutoff
+func (r *AutomationRepository) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM automation_executions
+		WHERE status IN ('succeeded', 'failed') AND finished_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete automation executions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete automation executions: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// marshalAutomationRule encodes a rule's JSONB columns
+func marshalAutomationRule(rule *models.AutomationRule) (string, string, error) {
+	if rule.Conditions == nil {
+		rule.Conditions = models.AutomationConditions{}
+	}
+
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode automation conditions: %w", err)
+	}
+
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode automation actions: %w", err)
+	}
+
+	return string(conditions), string(actions), nil
+}
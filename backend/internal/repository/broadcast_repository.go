@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// BroadcastRepository handles database operations for email broadcasts
+type BroadcastRepository struct {
+	db *sqlx.DB
+}
+
+// NewBroadcastRepository creates a new broadcast repository
+func NewBroadcastRepository(db *sqlx.DB) *BroadcastRepository {
+	return &BroadcastRepository{db: db}
+}
+
+// recipientDeliveryQuery selects recipients with their delivery status, taken
+// from the outbox email once the recipient has been queued
+const recipientDeliveryQuery = `
+	SELECT r.*,
+		CASE
+			WHEN r.status <> 'queued' THEN r.status
+			WHEN o.status = 'pending' THEN 'sending'
+			ELSE COALESCE(o.status, 'sent')
+		END AS delivery_status,
+		CASE WHEN r.status = 'queued' THEN o.last_error ELSE r.skip_reason END AS delivery_error
+	FROM email_broadcast_recipients r
+	LEFT JOIN email_outbox o ON o.tenant_id = r.tenant_id AND o.id = r.outbox_email_id
+	WHERE r.tenant_id = $1 AND r.broadcast_id = $2
+`
+
+// Create creates a broadcast and resolves its recipients from the filter.
+// Users who opted out are recorded as skipped.
+func (r *BroadcastRepository) Create(ctx context.Context, broadcast *models.EmailBroadcast, filter *models.BroadcastFilter) error {
+	tx, err := database.WithTenantContext(ctx, r.db, broadcast.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	broadcast.Status = models.BroadcastStatusSending
+
+	query := `
+		INSERT INTO email_broadcasts (tenant_id, subject, body, variables, filter, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		broadcast.TenantID,
+		broadcast.Subject,
+		broadcast.Body,
+		broadcast.Variables,
+		broadcast.Filter,
+		broadcast.Status,
+		broadcast.CreatedBy,
+	).Scan(&broadcast.ID, &broadcast.CreatedAt, &broadcast.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	roleIDs := make([]string, len(filter.RoleIDs))
+	for i, id := range filter.RoleIDs {
+		roleIDs[i] = id.String()
+	}
+	departmentIDs := make([]string, len(filter.DepartmentIDs))
+	for i, id := range filter.DepartmentIDs {
+		departmentIDs[i] = id.String()
+	}
+
+	recipientsQuery := `
+		INSERT INTO email_broadcast_recipients (
+			tenant_id, broadcast_id, user_id, email, first_name, last_name, status, skip_reason
+		)
+		SELECT u.tenant_id, $2, u.id, u.email, u.first_name, u.last_name,
+			CASE WHEN oo.user_id IS NULL THEN 'pending' ELSE 'skipped' END,
+			CASE WHEN oo.user_id IS NULL THEN NULL ELSE 'opted out' END
+		FROM users u
+		LEFT JOIN email_opt_outs oo ON oo.tenant_id = u.tenant_id AND oo.user_id = u.id
+		WHERE u.tenant_id = $1
+		  AND u.deleted_at IS NULL
+		  AND u.status = ANY($3)
+		  AND (cardinality($4::uuid[]) = 0 OR u.department_id = ANY($4::uuid[]))
+		  AND (cardinality($5::uuid[]) = 0 OR EXISTS (
+			SELECT 1 FROM user_roles ur
+			WHERE ur.tenant_id = u.tenant_id AND ur.user_id = u.id AND ur.role_id = ANY($5::uuid[])
+			  AND ` + activeUserRole + `
+		  ))
+	`
+
+	result, err := tx.ExecContext(ctx, recipientsQuery,
+		broadcast.TenantID, broadcast.ID, pq.Array(filter.Statuses), pq.Array(departmentIDs), pq.Array(roleIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to resolve broadcast recipients: %w", err)
+	}
+
+	total, _ := result.RowsAffected()
+	if total == 0 {
+		return utils.NewBadRequestError("NO_RECIPIENTS", "no users match the recipient filter")
+	}
+	broadcast.TotalRecipients = int(total)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE email_broadcasts SET total_recipients = $1 WHERE tenant_id = $2 AND id = $3
+	`, broadcast.TotalRecipients, broadcast.TenantID, broadcast.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update broadcast: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a broadcast
+func (r *BroadcastRepository) FindByID(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var broadcast models.EmailBroadcast
+	err = tx.GetContext(ctx, &broadcast, `SELECT * FROM email_broadcasts WHERE tenant_id = $1 AND id = $2`, tenantID, broadcastID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("BROADCAST_NOT_FOUND", "broadcast not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find broadcast: %w", err)
+	}
+
+	return &broadcast, nil
+}
+
+// List retrieves a tenant's broadcasts, newest first
+func (r *BroadcastRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM email_broadcasts WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count broadcasts: %w", err)
+	}
+
+	broadcasts := []models.EmailBroadcast{}
+	query := `SELECT * FROM email_broadcasts WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+
+	if err := tx.SelectContext(ctx, &broadcasts, query, tenantID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list broadcasts: %w", err)
+	}
+
+	return broadcasts, totalCount, nil
+}
+
+// Stats counts a broadcast's recipients per delivery status
+func (r *BroadcastRepository) Stats(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.BroadcastStats, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stats models.BroadcastStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE delivery_status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE delivery_status = 'sending') AS sending,
+			COUNT(*) FILTER (WHERE delivery_status = 'sent') AS sent,
+			COUNT(*) FILTER (WHERE delivery_status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE delivery_status = 'skipped') AS skipped,
+			COUNT(*) FILTER (WHERE delivery_status = 'cancelled') AS cancelled
+		FROM (` + recipientDeliveryQuery + `) d
+	`
+
+	if err := tx.GetContext(ctx, &stats, query, tenantID, broadcastID); err != nil {
+		return nil, fmt.Errorf("failed to get broadcast stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ListRecipients retrieves a broadcast's recipients with their delivery
+// status. An empty deliveryStatus returns all of them.
+func (r *BroadcastRepository) ListRecipients(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	filtered := `SELECT * FROM (` + recipientDeliveryQuery + `) d WHERE ($3 = '' OR delivery_status = $3)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM (`+filtered+`) c`, tenantID, broadcastID, deliveryStatus); err != nil {
+		return nil, 0, fmt.Errorf("failed to count broadcast recipients: %w", err)
+	}
+
+	recipients := []models.BroadcastRecipient{}
+	query := filtered + ` ORDER BY email LIMIT $4 OFFSET $5`
+
+	if err := tx.SelectContext(ctx, &recipients, query, tenantID, broadcastID, deliveryStatus, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list broadcast recipients: %w", err)
+	}
+
+	return recipients, totalCount, nil
+}
+
+// Cancel stops a broadcast that is still sending. Recipients already handed
+// to the outbox are not recalled.
+func (r *BroadcastRepository) Cancel(ctx context.Context, tenantID, broadcastID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE email_broadcasts
+		SET status = $1, cancelled_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND status = 'sending'
+	`, models.BroadcastStatusCancelled, tenantID, broadcastID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel broadcast: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("BROADCAST_NOT_SENDING", "broadcast is not sending")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE email_broadcast_recipients
+		SET status = $1
+		WHERE tenant_id = $2 AND broadcast_id = $3 AND status = 'pending'
+	`, models.RecipientStatusCancelled, tenantID, broadcastID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel broadcast recipients: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ClaimPending locks up to limit pending recipients of sending broadcasts,
+// skipping rows another worker already holds and tenants that already have
+// maxOutboxPending emails waiting in the outbox (0 for no limit)
+func (r *BroadcastRepository) ClaimPending(ctx context.Context, tx *sqlx.Tx, limit, maxOutboxPending int) ([]models.PendingBroadcastEmail, error) {
+	emails := []models.PendingBroadcastEmail{}
+	query := `
+		SELECT r.*, b.subject, b.body, b.variables, t.company_name, t.slug AS tenant_slug,
+			COALESCE(u.language, '') AS language,
+			EXISTS (
+				SELECT 1 FROM email_opt_outs oo WHERE oo.tenant_id = r.tenant_id AND oo.user_id = r.user_id
+			) AS opted_out
+		FROM email_broadcast_recipients r
+		JOIN email_broadcasts b ON b.tenant_id = r.tenant_id AND b.id = r.broadcast_id
+		JOIN tenants t ON t.id = r.tenant_id
+		LEFT JOIN users u ON u.tenant_id = r.tenant_id AND u.id = r.user_id
+		WHERE r.status = 'pending' AND b.status = 'sending'
+		  AND ($2::int = 0 OR r.tenant_id NOT IN (
+			SELECT tenant_id FROM email_outbox
+			WHERE status = 'pending'
+			GROUP BY tenant_id
+			HAVING COUNT(*) >= $2
+		  ))
+		ORDER BY r.created_at
+		LIMIT $1
+		FOR UPDATE OF r SKIP LOCKED
+	`
+
+	if err := tx.SelectContext(ctx, &emails, query, limit, maxOutboxPending); err != nil {
+		return nil, fmt.Errorf("failed to claim broadcast recipients: %w", err)
+	}
+
+	return emails, nil
+}
+
+// MarkQueued records that a recipient's email was handed to the outbox
+func (r *BroadcastRepository) MarkQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, outboxEmailID uuid.UUID) error {
+	query := `
+		UPDATE email_broadcast_recipients
+		SET status = $1, outbox_email_id = $2, queued_at = NOW()
+		WHERE tenant_id = $3 AND id = $4
+	`
+	if _, err := tx.ExecContext(ctx, query, models.RecipientStatusQueued, outboxEmailID, recipient.TenantID, recipient.ID); err != nil {
+		return fmt.Errorf("failed to mark recipient queued: %w", err)
+	}
+	return nil
+}
+
+// MarkNotQueued records why a recipient was not emailed (skipped or failed)
+func (r *BroadcastRepository) MarkNotQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, status, reason string) error {
+	query := `
+		UPDATE email_broadcast_recipients
+		SET status = $1, skip_reason = $2
+		WHERE tenant_id = $3 AND id = $4
+	`
+	if _, err := tx.ExecContext(ctx, query, status, reason, recipient.TenantID, recipient.ID); err != nil {
+		return fmt.Errorf("failed to update recipient: %w", err)
+	}
+	return nil
+}
+
+// CompleteFinished marks sending broadcasts without pending recipients as
+// completed
+func (r *BroadcastRepository) CompleteFinished(ctx context.Context, tx *sqlx.Tx) error {
+	query := `
+		UPDATE email_broadcasts b
+		SET status = $1, completed_at = NOW(), updated_at = NOW()
+		WHERE b.status = 'sending'
+		  AND NOT EXISTS (
+			SELECT 1 FROM email_broadcast_recipients r
+			WHERE r.tenant_id = b.tenant_id AND r.broadcast_id = b.id AND r.status = 'pending'
+		  )
+	`
+	if _, err := tx.ExecContext(ctx, query, models.BroadcastStatusCompleted); err != nil {
+		return fmt.Errorf("failed to complete broadcasts: %w", err)
+	}
+	return nil
+}
+
+// OptOut unsubscribes the recipient owning the token from future broadcasts.
+// Returns the user that was opted out.
+func (r *BroadcastRepository) OptOut(ctx context.Context, tenantID, token uuid.UUID) (uuid.UUID, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	var recipient models.BroadcastRecipient
+	err = tx.GetContext(ctx, &recipient, `
+		SELECT * FROM email_broadcast_recipients WHERE tenant_id = $1 AND unsubscribe_token = $2
+	`, tenantID, token)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, utils.NewNotFoundError("INVALID_UNSUBSCRIBE_LINK", "invalid unsubscribe link")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to find recipient: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO email_opt_outs (tenant_id, user_id, broadcast_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, user_id) DO NOTHING
+	`, tenantID, recipient.UserID, recipient.BroadcastID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to opt out: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+
+	return recipient.UserID, nil
+}
@@ -93,15 +93,16 @@ func (r *CompanySettingsRepository) Update(ctx context.Context, tenantID uuid.UU
 
 	// Whitelist of allowed column names to prevent SQL injection
 	allowedColumns := map[string]bool{
-		"company_name": true, "legal_name": true, "industry": true,
-		"employee_count": true, "founded_date": true, "website": true,
-		"description": true, "email": true, "phone": true, "fax": true,
+		"company_name": true, "legal_business_name": true, "industry": true,
+		"speciality": true, "company_size": true, "founded_date": true,
+		"website_url": true, "logo_url": true, "primary_email": true,
+		"support_email": true, "phone_number": true, "fax": true,
 		"street_address": true, "city": true, "state": true,
-		"postal_code": true, "country": true, "fiscal_year_start": true,
-		"default_currency": true, "date_format": true, "time_zone": true,
-		"language": true, "tax_id": true, "registration_number": true,
-		"vat_number": true, "nif_number": true, "ai_number": true,
-		"logo_url": true, "preferences": true,
+		"postal_code": true, "country": true, "timezone": true,
+		"working_days": true, "working_hours_start": true, "working_hours_end": true,
+		"fiscal_year_start": true, "default_currency": true, "date_format": true,
+		"number_format": true, "rc_number": true, "nif_number": true,
+		"nis_number": true, "ai_number": true, "capital_social": true,
 	}
 
 	// Build dynamic UPDATE query
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// ComplianceRepository reads the evidence compiled into compliance reports
+type ComplianceRepository struct {
+	db *sqlx.DB
+}
+
+// NewComplianceRepository creates a new compliance repository
+func NewComplianceRepository(db *sqlx.DB) *ComplianceRepository {
+	return &ComplianceRepository{db: db}
+}
+
+// ListUserAccess lists every live user with the roles in effect for them.
+// Users are privileged when one of their roles, or a role it inherits from,
+// grants more than viewing one of privilegedResources.
+func (r *ComplianceRepository) ListUserAccess(ctx context.Context, tenantID uuid.UUID, privilegedResources []string) ([]models.ComplianceUserAccess, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH RECURSIVE user_role_chain AS (
+			SELECT tenant_id, user_id, role_id FROM user_roles ur WHERE ` + activeUserRole + `
+			UNION
+			SELECT c.tenant_id, c.user_id, r.parent_role_id
+			FROM user_role_chain c
+			JOIN roles r ON r.tenant_id = c.tenant_id AND r.id = c.role_id
+			WHERE r.parent_role_id IS NOT NULL
+		)
+		SELECT
+			u.id, u.email, u.first_name || ' ' || u.last_name AS name, u.status,
+			COALESCE(string_agg(DISTINCT r.name, ';' ORDER BY r.name), '') AS roles,
+			EXISTS (
+				SELECT 1
+				FROM user_role_chain pur
+				JOIN role_permissions rp ON rp.tenant_id = pur.tenant_id AND rp.role_id = pur.role_id
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE pur.tenant_id = u.tenant_id AND pur.user_id = u.id
+				  AND rp.effect = 'allow'
+				  AND (p.resource = '*' OR p.resource = ANY($1))
+				  AND p.action <> 'view'
+			) AS privileged,
+			COALESCE(u.two_factor_enabled, false) AS two_factor_enabled,
+			u.last_login_at, u.created_at
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.tenant_id = u.tenant_id AND ur.user_id = u.id AND ` + activeUserRole + `
+		LEFT JOIN roles r ON r.tenant_id = ur.tenant_id AND r.id = ur.role_id
+		WHERE u.deleted_at IS NULL
+		GROUP BY u.tenant_id, u.id
+		ORDER BY u.email
+	`
+
+	users := []models.ComplianceUserAccess{}
+	if err := tx.SelectContext(ctx, &users, query, pq.Array(privilegedResources)); err != nil {
+		return nil, fmt.Errorf("failed to list user access: %w", err)
+	}
+
+	return users, tx.Commit()
+}
+
+// StreamAdminActions reads the audit logs of a period on any of
+// resourceTypes, except excludedActions, oldest first, and calls fn with each
+// one as it is read
+func (r *ComplianceRepository) StreamAdminActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time, resourceTypes, excludedActions []string, fn func(*models.AuditLog) error) error {
+	return r.streamAuditLogs(ctx, tenantID, `resource_type = ANY($3) AND action <> ALL($4)`,
+		[]interface{}{start, end, pq.Array(resourceTypes), pq.Array(excludedActions)}, fn)
+}
+
+// StreamActions reads the audit logs of a period with any of actions, oldest
+// first, and calls fn with each one as it is read
+func (r *ComplianceRepository) StreamActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time, actions []string, fn func(*models.AuditLog) error) error {
+	return r.streamAuditLogs(ctx, tenantID, `action = ANY($3)`,
+		[]interface{}{start, end, pq.Array(actions)}, fn)
+}
+
+// streamAuditLogs reads the audit logs created in [$1, $2) that match
+// condition
+func (r *ComplianceRepository) streamAuditLogs(ctx context.Context, tenantID uuid.UUID, condition string, args []interface{}, fn func(*models.AuditLog) error) error {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT
+			id, tenant_id, user_id, action, resource_type, resource_id,
+			status, ip_address, user_agent, metadata, created_at
+		FROM audit_logs
+		WHERE created_at >= $1 AND created_at < $2 AND ` + condition + `
+		ORDER BY created_at ASC
+	`
+
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to read audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log models.AuditLog
+		if err := rows.StructScan(&log); err != nil {
+			return fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read audit logs: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,1226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// crmCustomerSelect selects customers (c) with their owner's name and their
+// number of contacts and open opportunities
+const crmCustomerSelect = `
+	SELECT
+		c.*,
+		u.first_name || ' ' || u.last_name AS owner_name,
+		(SELECT COUNT(*) FROM crm_contacts ct WHERE ct.tenant_id = c.tenant_id AND ct.customer_id = c.id) AS contact_count,
+		(SELECT COUNT(*) FROM crm_opportunities o WHERE o.tenant_id = c.tenant_id AND o.customer_id = c.id AND o.status = 'open') AS open_opportunities
+	FROM crm_customers c
+	LEFT JOIN users u ON u.tenant_id = c.tenant_id AND u.id = c.owner_id
+`
+
+// crmContactSelect selects contacts (ct) with their customer's and owner's names
+const crmContactSelect = `
+	SELECT
+		ct.*,
+		c.name AS customer_name,
+		u.first_name || ' ' || u.last_name AS owner_name
+	FROM crm_contacts ct
+	JOIN crm_customers c ON c.tenant_id = ct.tenant_id AND c.id = ct.customer_id
+	LEFT JOIN users u ON u.tenant_id = ct.tenant_id AND u.id = ct.owner_id
+`
+
+// crmOpportunitySelect selects opportunities (o) with the names of their
+// customer (c), contact, pipeline, stage and owner
+const crmOpportunitySelect = `
+	SELECT
+		o.*,
+		c.name AS customer_name,
+		ct.first_name || ' ' || ct.last_name AS contact_name,
+		p.name AS pipeline_name,
+		s.name AS stage_name,
+		u.first_name || ' ' || u.last_name AS owner_name
+	FROM crm_opportunities o
+	JOIN crm_customers c ON c.tenant_id = o.tenant_id AND c.id = o.customer_id
+	JOIN crm_pipelines p ON p.tenant_id = o.tenant_id AND p.id = o.pipeline_id
+	JOIN crm_pipeline_stages s ON s.tenant_id = o.tenant_id AND s.id = o.stage_id
+	LEFT JOIN crm_contacts ct ON ct.tenant_id = o.tenant_id AND ct.id = o.contact_id
+	LEFT JOIN users u ON u.tenant_id = o.tenant_id AND u.id = o.owner_id
+`
+
+// crmActivitySelect selects activities (a) with the names of what they are
+// about, their owner and their author
+const crmActivitySelect = `
+	SELECT
+		a.*,
+		c.name AS customer_name,
+		ct.first_name || ' ' || ct.last_name AS contact_name,
+		o.name AS opportunity_name,
+		u.first_name || ' ' || u.last_name AS owner_name,
+		cb.first_name || ' ' || cb.last_name AS created_by_name
+	FROM crm_activities a
+	JOIN crm_customers c ON c.tenant_id = a.tenant_id AND c.id = a.customer_id
+	LEFT JOIN crm_contacts ct ON ct.tenant_id = a.tenant_id AND ct.id = a.contact_id
+	LEFT JOIN crm_opportunities o ON o.tenant_id = a.tenant_id AND o.id = a.opportunity_id
+	LEFT JOIN users u ON u.tenant_id = a.tenant_id AND u.id = a.owner_id
+	LEFT JOIN users cb ON cb.tenant_id = a.tenant_id AND cb.id = a.created_by
+`
+
+// crmOwnedTables maps the entities that have an owner to their tables
+var crmOwnedTables = map[string]string{
+	"customer":    "crm_customers",
+	"contact":     "crm_contacts",
+	"opportunity": "crm_opportunities",
+}
+
+// CRMRepository handles database operations for CRM customers, contacts,
+// pipelines, opportunities and activities
+type CRMRepository struct {
+	db *sqlx.DB
+}
+
+// NewCRMRepository creates a new CRM repository
+func NewCRMRepository(db *sqlx.DB) *CRMRepository {
+	return &CRMRepository{db: db}
+}
+
+// CreateCustomer creates a new customer with RLS
+func (r *CRMRepository) CreateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO crm_customers (
+			tenant_id, name, customer_type, industry, website, email, phone,
+			address, tax_id, notes, status, owner_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		customer.Name,
+		customer.CustomerType,
+		customer.Industry,
+		customer.Website,
+		customer.Email,
+		customer.Phone,
+		customer.Address,
+		customer.TaxID,
+		customer.Notes,
+		customer.Status,
+		customer.OwnerID,
+		customer.CreatedBy,
+	).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	customer.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindCustomerByID retrieves a customer with details by ID with RLS
+func (r *CRMRepository) FindCustomerByID(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var customer models.CRMCustomer
+	// Explicit tenant_id filter for defense in depth
+	query := crmCustomerSelect + ` WHERE c.tenant_id = $1 AND c.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &customer, query, tenantID, customerID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CUSTOMER_NOT_FOUND", "customer not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
+// ListCustomers retrieves customers with filters and pagination, sorted by name
+func (r *CRMRepository) ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// The search expression matches idx_crm_customers_search
+	where := `
+		WHERE c.tenant_id = $1
+		AND ($2::uuid IS NULL OR c.owner_id = $2)
+		AND ($3 = '' OR c.status = $3)
+		AND ($4 = '' OR search_normalize(c.name || ' ' || COALESCE(c.email, '')) LIKE '%' || search_normalize($4) || '%')
+	`
+	args := []interface{}{tenantID, filter.OwnerID, filter.Status, filter.Search}
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM crm_customers c `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	customers := []models.CRMCustomer{}
+	query := crmCustomerSelect + where + ` ORDER BY c.name ASC LIMIT $5 OFFSET $6`
+
+	if err := tx.SelectContext(ctx, &customers, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list customers: %w", err)
+	}
+
+	return customers, totalCount, nil
+}
+
+// UpdateCustomer updates a customer's information
+func (r *CRMRepository) UpdateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE crm_customers
+		SET name = $1,
+			customer_type = $2,
+			industry = $3,
+			website = $4,
+			email = $5,
+			phone = $6,
+			address = $7,
+			tax_id = $8,
+			notes = $9,
+			status = $10,
+			updated_at = NOW()
+		WHERE tenant_id = $11 AND id = $12
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		customer.Name,
+		customer.CustomerType,
+		customer.Industry,
+		customer.Website,
+		customer.Email,
+		customer.Phone,
+		customer.Address,
+		customer.TaxID,
+		customer.Notes,
+		customer.Status,
+		tenantID,
+		customer.ID,
+	).Scan(&customer.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("CUSTOMER_NOT_FOUND", "customer not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCustomer deletes a customer with its contacts and activities. A
+// customer with opportunities is kept, so deal history is not lost.
+func (r *CRMRepository) DeleteCustomer(ctx context.Context, tenantID, customerID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasOpportunities bool
+	query := `SELECT EXISTS(SELECT 1 FROM crm_opportunities WHERE tenant_id = $1 AND customer_id = $2)`
+	if err := tx.GetContext(ctx, &hasOpportunities, query, tenantID, customerID); err != nil {
+		return fmt.Errorf("failed to check opportunities: %w", err)
+	}
+	if hasOpportunities {
+		return fmt.Errorf("customer has opportunities")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_customers WHERE tenant_id = $1 AND id = $2`, tenantID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("CUSTOMER_NOT_FOUND", "customer not found")
+	}
+
+	return tx.Commit()
+}
+
+// CreateContact creates a new contact with RLS. A primary contact replaces
+// the customer's previous one.
+func (r *CRMRepository) CreateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if contact.IsPrimary {
+		if err := clearPrimaryContact(ctx, tx.Tx, tenantID, contact.CustomerID, nil); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO crm_contacts (
+			tenant_id, customer_id, first_name, last_name, email, phone,
+			job_title, notes, is_primary, owner_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		contact.CustomerID,
+		contact.FirstName,
+		contact.LastName,
+		contact.Email,
+		contact.Phone,
+		contact.JobTitle,
+		contact.Notes,
+		contact.IsPrimary,
+		contact.OwnerID,
+		contact.CreatedBy,
+	).Scan(&contact.ID, &contact.CreatedAt, &contact.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create contact: %w", err)
+	}
+
+	contact.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindContactByID retrieves a contact with details by ID with RLS
+func (r *CRMRepository) FindContactByID(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var contact models.CRMContact
+	query := crmContactSelect + ` WHERE ct.tenant_id = $1 AND ct.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &contact, query, tenantID, contactID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CONTACT_NOT_FOUND", "contact not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contact: %w", err)
+	}
+
+	return &contact, nil
+}
+
+// ListContacts retrieves contacts with filters and pagination. Primary
+// contacts come first, then by name.
+func (r *CRMRepository) ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// The search expression matches idx_crm_contacts_search
+	where := `
+		WHERE ct.tenant_id = $1
+		AND ($2::uuid IS NULL OR ct.customer_id = $2)
+		AND ($3::uuid IS NULL OR ct.owner_id = $3)
+		AND ($4 = '' OR search_normalize(ct.first_name || ' ' || ct.last_name || ' ' || COALESCE(ct.email, '')) LIKE '%' || search_normalize($4) || '%')
+	`
+	args := []interface{}{tenantID, filter.CustomerID, filter.OwnerID, filter.Search}
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM crm_contacts ct `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count contacts: %w", err)
+	}
+
+	contacts := []models.CRMContact{}
+	query := crmContactSelect + where + ` ORDER BY ct.is_primary DESC, ct.last_name ASC, ct.first_name ASC LIMIT $5 OFFSET $6`
+
+	if err := tx.SelectContext(ctx, &contacts, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	return contacts, totalCount, nil
+}
+
+// UpdateContact updates a contact's information. A contact made primary
+// replaces the customer's previous primary contact.
+func (r *CRMRepository) UpdateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if contact.IsPrimary {
+		if err := clearPrimaryContact(ctx, tx.Tx, tenantID, contact.CustomerID, &contact.ID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE crm_contacts
+		SET first_name = $1,
+			last_name = $2,
+			email = $3,
+			phone = $4,
+			job_title = $5,
+			notes = $6,
+			is_primary = $7,
+			updated_at = NOW()
+		WHERE tenant_id = $8 AND id = $9
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		contact.FirstName,
+		contact.LastName,
+		contact.Email,
+		contact.Phone,
+		contact.JobTitle,
+		contact.Notes,
+		contact.IsPrimary,
+		tenantID,
+		contact.ID,
+	).Scan(&contact.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("CONTACT_NOT_FOUND", "contact not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteContact deletes a contact. Opportunities and activities that named
+// the contact keep their customer.
+func (r *CRMRepository) DeleteContact(ctx context.Context, tenantID, contactID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_contacts WHERE tenant_id = $1 AND id = $2`, tenantID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("CONTACT_NOT_FOUND", "contact not found")
+	}
+
+	return tx.Commit()
+}
+
+// CreatePipeline creates a pipeline with its stages. A default pipeline
+// replaces the tenant's previous default.
+func (r *CRMRepository) CreatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if pipeline.IsDefault {
+		if err := clearDefaultPipeline(ctx, tx.Tx, tenantID, nil); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO crm_pipelines (tenant_id, name, is_default, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, tenantID, pipeline.Name, pipeline.IsDefault, pipeline.CreatedBy).
+		Scan(&pipeline.ID, &pipeline.CreatedAt, &pipeline.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pipeline: %w", err)
+	}
+	pipeline.TenantID = tenantID
+
+	for i := range pipeline.Stages {
+		stage := &pipeline.Stages[i]
+		stage.PipelineID = pipeline.ID
+		if err := insertStage(ctx, tx.Tx, tenantID, stage); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindPipelineByID retrieves a pipeline with its stages by ID with RLS
+func (r *CRMRepository) FindPipelineByID(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var pipeline models.CRMPipeline
+	query := `SELECT * FROM crm_pipelines WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &pipeline, query, tenantID, pipelineID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("PIPELINE_NOT_FOUND", "pipeline not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pipeline: %w", err)
+	}
+
+	pipeline.Stages = []models.CRMPipelineStage{}
+	query = `SELECT * FROM crm_pipeline_stages WHERE tenant_id = $1 AND pipeline_id = $2 ORDER BY position ASC`
+	if err := tx.SelectContext(ctx, &pipeline.Stages, query, tenantID, pipelineID); err != nil {
+		return nil, fmt.Errorf("failed to list stages: %w", err)
+	}
+
+	return &pipeline, nil
+}
+
+// ListPipelines retrieves every pipeline with its stages, the default first
+func (r *CRMRepository) ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pipelines := []models.CRMPipeline{}
+	query := `SELECT * FROM crm_pipelines WHERE tenant_id = $1 ORDER BY is_default DESC, name ASC`
+	if err := tx.SelectContext(ctx, &pipelines, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+
+	stages := []models.CRMPipelineStage{}
+	query = `SELECT * FROM crm_pipeline_stages WHERE tenant_id = $1 ORDER BY position ASC`
+	if err := tx.SelectContext(ctx, &stages, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list stages: %w", err)
+	}
+
+	byPipeline := make(map[uuid.UUID][]models.CRMPipelineStage)
+	for _, stage := range stages {
+		byPipeline[stage.PipelineID] = append(byPipeline[stage.PipelineID], stage)
+	}
+	for i := range pipelines {
+		pipelines[i].Stages = byPipeline[pipelines[i].ID]
+		if pipelines[i].Stages == nil {
+			pipelines[i].Stages = []models.CRMPipelineStage{}
+		}
+	}
+
+	return pipelines, nil
+}
+
+// UpdatePipeline renames a pipeline or makes it the default
+func (r *CRMRepository) UpdatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if pipeline.IsDefault {
+		if err := clearDefaultPipeline(ctx, tx.Tx, tenantID, &pipeline.ID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE crm_pipelines
+		SET name = $1, is_default = $2, updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, pipeline.Name, pipeline.IsDefault, tenantID, pipeline.ID).Scan(&pipeline.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("PIPELINE_NOT_FOUND", "pipeline not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update pipeline: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeletePipeline deletes a pipeline and its stages unless it has opportunities
+func (r *CRMRepository) DeletePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM crm_opportunities WHERE tenant_id = $1 AND pipeline_id = $2)`
+	if err := tx.GetContext(ctx, &inUse, query, tenantID, pipelineID); err != nil {
+		return fmt.Errorf("failed to check pipeline usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("pipeline is in use")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_pipelines WHERE tenant_id = $1 AND id = $2`, tenantID, pipelineID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pipeline: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("PIPELINE_NOT_FOUND", "pipeline not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckPipelineNameExists checks if a pipeline name is already used in a tenant
+func (r *CRMRepository) CheckPipelineNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM crm_pipelines WHERE tenant_id = $1 AND LOWER(name) = LOWER($2) AND ($3::uuid IS NULL OR id != $3))`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, name, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check pipeline name: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CreateStage adds a stage to a pipeline at its position, moving the
+// stages from that position down one place
+func (r *CRMRepository) CreateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE crm_pipeline_stages
+		SET position = position + 1, updated_at = NOW()
+		WHERE tenant_id = $1 AND pipeline_id = $2 AND position >= $3
+	`
+	if _, err := tx.ExecContext(ctx, query, tenantID, stage.PipelineID, stage.Position); err != nil {
+		return fmt.Errorf("failed to reorder stages: %w", err)
+	}
+
+	if err := insertStage(ctx, tx.Tx, tenantID, stage); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindStageByID retrieves a pipeline stage by ID with RLS
+func (r *CRMRepository) FindStageByID(ctx context.Context, tenantID, stageID uuid.UUID) (*models.CRMPipelineStage, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stage models.CRMPipelineStage
+	query := `SELECT * FROM crm_pipeline_stages WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &stage, query, tenantID, stageID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("STAGE_NOT_FOUND", "stage not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stage: %w", err)
+	}
+
+	return &stage, nil
+}
+
+// UpdateStage updates a stage, moving it to its new position. Opportunities
+// in the stage take the status of a changed outcome.
+func (r *CRMRepository) UpdateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage, oldPosition int) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var query string
+	switch {
+	case stage.Position < oldPosition:
+		query = `
+			UPDATE crm_pipeline_stages
+			SET position = position + 1, updated_at = NOW()
+			WHERE tenant_id = $1 AND pipeline_id = $2 AND position >= $3 AND position < $4
+		`
+	case stage.Position > oldPosition:
+		query = `
+			UPDATE crm_pipeline_stages
+			SET position = position - 1, updated_at = NOW()
+			WHERE tenant_id = $1 AND pipeline_id = $2 AND position <= $3 AND position > $4
+		`
+	}
+	if query != "" {
+		if _, err := tx.ExecContext(ctx, query, tenantID, stage.PipelineID, stage.Position, oldPosition); err != nil {
+			return fmt.Errorf("failed to reorder stages: %w", err)
+		}
+	}
+
+	query = `
+		UPDATE crm_pipeline_stages
+		SET name = $1, position = $2, probability = $3, outcome = $4, updated_at = NOW()
+		WHERE tenant_id = $5 AND id = $6
+		RETURNING updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, stage.Name, stage.Position, stage.Probability, stage.Outcome, tenantID, stage.ID).Scan(&stage.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("STAGE_NOT_FOUND", "stage not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update stage: %w", err)
+	}
+
+	query = `
+		UPDATE crm_opportunities
+		SET status = $1,
+			closed_at = CASE WHEN $1 = 'open' THEN NULL ELSE COALESCE(closed_at, NOW()) END,
+			updated_at = NOW()
+		WHERE tenant_id = $2 AND stage_id = $3 AND status != $1
+	`
+	if _, err := tx.ExecContext(ctx, query, stage.Outcome, tenantID, stage.ID); err != nil {
+		return fmt.Errorf("failed to update opportunity statuses: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStage deletes a stage that holds no opportunities and closes the
+// gap in the pipeline's positions
+func (r *CRMRepository) DeleteStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM crm_opportunities WHERE tenant_id = $1 AND stage_id = $2)`
+	if err := tx.GetContext(ctx, &inUse, query, tenantID, stage.ID); err != nil {
+		return fmt.Errorf("failed to check stage usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("stage is in use")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_pipeline_stages WHERE tenant_id = $1 AND id = $2`, tenantID, stage.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete stage: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("STAGE_NOT_FOUND", "stage not found")
+	}
+
+	query = `
+		UPDATE crm_pipeline_stages
+		SET position = position - 1, updated_at = NOW()
+		WHERE tenant_id = $1 AND pipeline_id = $2 AND position > $3
+	`
+	if _, err := tx.ExecContext(ctx, query, tenantID, stage.PipelineID, stage.Position); err != nil {
+		return fmt.Errorf("failed to reorder stages: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// PipelineSummary totals the opportunities in each stage of a pipeline
+func (r *CRMRepository) PipelineSummary(ctx context.Context, tenantID, pipelineID uuid.UUID) ([]models.CRMStageSummary, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	summary := []models.CRMStageSummary{}
+	query := `
+		SELECT
+			s.id AS stage_id,
+			s.name,
+			s.position,
+			s.outcome,
+			COUNT(o.id) AS count,
+			COALESCE(SUM(o.amount), 0) AS amount,
+			COALESCE(SUM(o.amount * o.probability / 100.0), 0) AS weighted_amount
+		FROM crm_pipeline_stages s
+		LEFT JOIN crm_opportunities o ON o.tenant_id = s.tenant_id AND o.stage_id = s.id
+		WHERE s.tenant_id = $1 AND s.pipeline_id = $2
+		GROUP BY s.id, s.name, s.position, s.outcome
+		ORDER BY s.position ASC
+	`
+
+	if err := tx.SelectContext(ctx, &summary, query, tenantID, pipelineID); err != nil {
+		return nil, fmt.Errorf("failed to summarize pipeline: %w", err)
+	}
+
+	return summary, nil
+}
+
+// CreateOpportunity creates a new opportunity with RLS
+func (r *CRMRepository) CreateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO crm_opportunities (
+			tenant_id, customer_id, contact_id, pipeline_id, stage_id, name,
+			amount, currency, probability, expected_close_date, status,
+			closed_at, owner_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		opportunity.CustomerID,
+		opportunity.ContactID,
+		opportunity.PipelineID,
+		opportunity.StageID,
+		opportunity.Name,
+		opportunity.Amount,
+		opportunity.Currency,
+		opportunity.Probability,
+		opportunity.ExpectedCloseDate,
+		opportunity.Status,
+		opportunity.ClosedAt,
+		opportunity.OwnerID,
+		opportunity.CreatedBy,
+	).Scan(&opportunity.ID, &opportunity.CreatedAt, &opportunity.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create opportunity: %w", err)
+	}
+
+	opportunity.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindOpportunityByID retrieves an opportunity with details by ID with RLS
+func (r *CRMRepository) FindOpportunityByID(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var opportunity models.CRMOpportunity
+	query := crmOpportunitySelect + ` WHERE o.tenant_id = $1 AND o.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &opportunity, query, tenantID, opportunityID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("OPPORTUNITY_NOT_FOUND", "opportunity not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find opportunity: %w", err)
+	}
+
+	return &opportunity, nil
+}
+
+// ListOpportunities retrieves opportunities with filters and pagination,
+// those closing soonest first
+func (r *CRMRepository) ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// The search expressions match idx_crm_opportunities_search and
+	// idx_crm_customers_search
+	where := `
+		WHERE o.tenant_id = $1
+		AND ($2::uuid IS NULL OR o.customer_id = $2)
+		AND ($3::uuid IS NULL OR o.pipeline_id = $3)
+		AND ($4::uuid IS NULL OR o.stage_id = $4)
+		AND ($5::uuid IS NULL OR o.owner_id = $5)
+		AND ($6 = '' OR o.status = $6)
+		AND ($7 = '' OR search_normalize(o.name) LIKE '%' || search_normalize($7) || '%'
+			OR search_normalize(c.name || ' ' || COALESCE(c.email, '')) LIKE '%' || search_normalize($7) || '%')
+	`
+	args := []interface{}{tenantID, filter.CustomerID, filter.PipelineID, filter.StageID, filter.OwnerID, filter.Status, filter.Search}
+
+	var totalCount int
+	countQuery := `
+		SELECT COUNT(*) FROM crm_opportunities o
+		JOIN crm_customers c ON c.tenant_id = o.tenant_id AND c.id = o.customer_id
+	` + where
+	if err := tx.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count opportunities: %w", err)
+	}
+
+	opportunities := []models.CRMOpportunity{}
+	query := crmOpportunitySelect + where + ` ORDER BY o.expected_close_date ASC NULLS LAST, o.created_at DESC LIMIT $8 OFFSET $9`
+
+	if err := tx.SelectContext(ctx, &opportunities, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list opportunities: %w", err)
+	}
+
+	return opportunities, totalCount, nil
+}
+
+// UpdateOpportunity updates an opportunity, its stage and status included.
+// A system activity describing the change, if given, is logged with it.
+func (r *CRMRepository) UpdateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity, activity *models.CRMActivity) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE crm_opportunities
+		SET contact_id = $1,
+			stage_id = $2,
+			name = $3,
+			amount = $4,
+			currency = $5,
+			probability = $6,
+			expected_close_date = $7,
+			status = $8,
+			closed_at = $9,
+			lost_reason = $10,
+			updated_at = NOW()
+		WHERE tenant_id = $11 AND id = $12
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		opportunity.ContactID,
+		opportunity.StageID,
+		opportunity.Name,
+		opportunity.Amount,
+		opportunity.Currency,
+		opportunity.Probability,
+		opportunity.ExpectedCloseDate,
+		opportunity.Status,
+		opportunity.ClosedAt,
+		opportunity.LostReason,
+		tenantID,
+		opportunity.ID,
+	).Scan(&opportunity.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("OPPORTUNITY_NOT_FOUND", "opportunity not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update opportunity: %w", err)
+	}
+
+	if activity != nil {
+		if err := insertActivity(ctx, tx.Tx, tenantID, activity); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteOpportunity deletes an opportunity and its activities
+func (r *CRMRepository) DeleteOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_opportunities WHERE tenant_id = $1 AND id = $2`, tenantID, opportunityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete opportunity: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("OPPORTUNITY_NOT_FOUND", "opportunity not found")
+	}
+
+	return tx.Commit()
+}
+
+// AssignOwner gives a customer, contact or opportunity to another owner (nil
+// unassigns it) and logs the system activity describing the change
+func (r *CRMRepository) AssignOwner(ctx context.Context, tenantID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID, activity *models.CRMActivity) error {
+	table, ok := crmOwnedTables[entity]
+	if !ok {
+		return fmt.Errorf("unknown crm entity %q", entity)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`UPDATE %s SET owner_id = $1, updated_at = NOW() WHERE tenant_id = $2 AND id = $3`, table)
+	result, err := tx.ExecContext(ctx, query, ownerID, tenantID, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to assign owner: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%s not found", entity)
+	}
+
+	if activity != nil {
+		if err := insertActivity(ctx, tx.Tx, tenantID, activity); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CreateActivity logs a new activity with RLS
+func (r *CRMRepository) CreateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertActivity(ctx, tx.Tx, tenantID, activity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindActivityByID retrieves an activity with details by ID with RLS
+func (r *CRMRepository) FindActivityByID(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var activity models.CRMActivity
+	query := crmActivitySelect + ` WHERE a.tenant_id = $1 AND a.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &activity, query, tenantID, activityID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ACTIVITY_NOT_FOUND", "activity not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find activity: %w", err)
+	}
+
+	return &activity, nil
+}
+
+// ListActivities retrieves activities with filters and pagination, newest
+// first, or open tasks by due date
+func (r *CRMRepository) ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE a.tenant_id = $1
+		AND ($2::uuid IS NULL OR a.customer_id = $2)
+		AND ($3::uuid IS NULL OR a.contact_id = $3)
+		AND ($4::uuid IS NULL OR a.opportunity_id = $4)
+		AND ($5::uuid IS NULL OR a.owner_id = $5)
+		AND ($6 = '' OR a.activity_type = $6)
+		AND (NOT $7 OR (a.activity_type = 'task' AND a.completed_at IS NULL))
+	`
+	args := []interface{}{tenantID, filter.CustomerID, filter.ContactID, filter.OpportunityID, filter.OwnerID, filter.ActivityType, filter.OpenTasks}
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM crm_activities a `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count activities: %w", err)
+	}
+
+	order := ` ORDER BY a.created_at DESC`
+	if filter.OpenTasks {
+		order = ` ORDER BY a.due_at ASC NULLS LAST, a.created_at ASC`
+	}
+
+	activities := []models.CRMActivity{}
+	query := crmActivitySelect + where + order + ` LIMIT $8 OFFSET $9`
+
+	if err := tx.SelectContext(ctx, &activities, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	return activities, totalCount, nil
+}
+
+// UpdateActivity updates an activity
+func (r *CRMRepository) UpdateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE crm_activities
+		SET subject = $1,
+			body = $2,
+			due_at = $3,
+			completed_at = $4,
+			owner_id = $5,
+			updated_at = NOW()
+		WHERE tenant_id = $6 AND id = $7
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		activity.Subject,
+		activity.Body,
+		activity.DueAt,
+		activity.CompletedAt,
+		activity.OwnerID,
+		tenantID,
+		activity.ID,
+	).Scan(&activity.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("ACTIVITY_NOT_FOUND", "activity not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update activity: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteActivity deletes an activity
+func (r *CRMRepository) DeleteActivity(ctx context.Context, tenantID, activityID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM crm_activities WHERE tenant_id = $1 AND id = $2`, tenantID, activityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete activity: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("ACTIVITY_NOT_FOUND", "activity not found")
+	}
+
+	return tx.Commit()
+}
+
+// insertActivity inserts an activity within a transaction
+func insertActivity(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, activity *models.CRMActivity) error {
+	query := `
+		INSERT INTO crm_activities (
+			tenant_id, customer_id, contact_id, opportunity_id, activity_type,
+			subject, body, due_at, completed_at, owner_id, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		activity.CustomerID,
+		activity.ContactID,
+		activity.OpportunityID,
+		activity.ActivityType,
+		activity.Subject,
+		activity.Body,
+		activity.DueAt,
+		activity.CompletedAt,
+		activity.OwnerID,
+		activity.CreatedBy,
+	).Scan(&activity.ID, &activity.CreatedAt, &activity.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to log activity: %w", err)
+	}
+
+	activity.TenantID = tenantID
+	return nil
+}
+
+// insertStage inserts a pipeline stage within a transaction
+func insertStage(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, stage *models.CRMPipelineStage) error {
+	query := `
+		INSERT INTO crm_pipeline_stages (tenant_id, pipeline_id, name, position, probability, outcome)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(ctx, query, tenantID, stage.PipelineID, stage.Name, stage.Position, stage.Probability, stage.Outcome).
+		Scan(&stage.ID, &stage.CreatedAt, &stage.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create stage: %w", err)
+	}
+
+	stage.TenantID = tenantID
+	return nil
+}
+
+// clearPrimaryContact unmarks a customer's primary contact, except the
+// contact being saved
+func clearPrimaryContact(ctx context.Context, tx *sqlx.Tx, tenantID, customerID uuid.UUID, contactID *uuid.UUID) error {
+	query := `
+		UPDATE crm_contacts
+		SET is_primary = FALSE, updated_at = NOW()
+		WHERE tenant_id = $1 AND customer_id = $2 AND is_primary AND ($3::uuid IS NULL OR id != $3)
+	`
+	if _, err := tx.ExecContext(ctx, query, tenantID, customerID, contactID); err != nil {
+		return fmt.Errorf("failed to update primary contact: %w", err)
+	}
+
+	return nil
+}
+
+// clearDefaultPipeline unmarks the tenant's default pipeline, except the
+// pipeline being saved
+func clearDefaultPipeline(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, pipelineID *uuid.UUID) error {
+	query := `
+		UPDATE crm_pipelines
+		SET is_default = FALSE, updated_at = NOW()
+		WHERE tenant_id = $1 AND is_default AND ($2::uuid IS NULL OR id != $2)
+	`
+	if _, err := tx.ExecContext(ctx, query, tenantID, pipelineID); err != nil {
+		return fmt.Errorf("failed to update default pipeline: %w", err)
+	}
+
+	return nil
+}
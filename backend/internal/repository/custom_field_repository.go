@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// customFieldTables maps the entity types with custom fields to the table
+// keeping their values
+var customFieldTables = map[string]string{
+	models.NavigationEntityUser:       "users",
+	models.NavigationEntityDepartment: "departments",
+}
+
+// CustomFieldRepository handles database operations for the custom fields
+// tenants define on entity types
+type CustomFieldRepository struct {
+	db *sqlx.DB
+}
+
+// NewCustomFieldRepository creates a new custom field repository
+func NewCustomFieldRepository(db *sqlx.DB) *CustomFieldRepository {
+	return &CustomFieldRepository{db: db}
+}
+
+// Create defines a custom field with RLS. Fails with a conflict when the
+// entity type already has a field with the key.
+func (r *CustomFieldRepository) Create(ctx context.Context, field *models.CustomField) error {
+	tx, err := database.WithTenantContext(ctx, r.db, field.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO custom_fields (
+			tenant_id, entity, key, label, field_type, options, required, sort_order, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id, entity, key) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		field.TenantID,
+		field.Entity,
+		field.Key,
+		field.Label,
+		field.FieldType,
+		field.Options,
+		field.Required,
+		field.SortOrder,
+		field.CreatedBy,
+	).Scan(&field.ID, &field.CreatedAt, &field.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewConflictError("CUSTOM_FIELD_EXISTS", fmt.Sprintf("%s already has a custom field %s", field.Entity, field.Key))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create custom field: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update updates a custom field's label, options and validation with RLS
+func (r *CustomFieldRepository) Update(ctx context.Context, field *models.CustomField) error {
+	tx, err := database.WithTenantContext(ctx, r.db, field.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE custom_fields
+		SET label = $1,
+			options = $2,
+			required = $3,
+			sort_order = $4,
+			updated_at = NOW()
+		WHERE tenant_id = $5 AND id = $6
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		field.Label,
+		field.Options,
+		field.Required,
+		field.SortOrder,
+		field.TenantID,
+		field.ID,
+	).Scan(&field.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("CUSTOM_FIELD_NOT_FOUND", "custom field not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update custom field: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a custom field and removes its values from the entities, in
+// one transaction with RLS
+func (r *CustomFieldRepository) Delete(ctx context.Context, tenantID, fieldID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var field models.CustomField
+	err = tx.GetContext(ctx, &field, `DELETE FROM custom_fields WHERE tenant_id = $1 AND id = $2 RETURNING *`, tenantID, fieldID)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("CUSTOM_FIELD_NOT_FOUND", "custom field not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+
+	table, ok := customFieldTables[field.Entity]
+	if !ok {
+		return fmt.Errorf("no table for custom fields of %s", field.Entity)
+	}
+
+	query := `UPDATE ` + table + ` SET custom_fields = custom_fields - $2::text WHERE tenant_id = $1 AND custom_fields ? $2::text`
+	if _, err := tx.ExecContext(ctx, query, tenantID, field.Key); err != nil {
+		return fmt.Errorf("failed to remove custom field values: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a custom field by ID with RLS
+func (r *CustomFieldRepository) FindByID(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var field models.CustomField
+	query := `SELECT * FROM custom_fields WHERE tenant_id = $1 AND id = $2`
+
+	err = tx.GetContext(ctx, &field, query, tenantID, fieldID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CUSTOM_FIELD_NOT_FOUND", "custom field not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find custom field: %w", err)
+	}
+
+	return &field, nil
+}
+
+// List retrieves a tenant's custom fields in display order with RLS, only
+// those of an entity type unless entity is empty
+func (r *CustomFieldRepository) List(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	fields := []models.CustomField{}
+	query := `
+		SELECT * FROM custom_fields
+		WHERE tenant_id = $1 AND ($2::text = '' OR entity = $2)
+		ORDER BY entity, sort_order, label
+	`
+
+	if err := tx.SelectContext(ctx, &fields, query, tenantID, entity); err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// DashboardRepository computes the aggregations shown on the tenant
+// dashboard. Each one is a single grouped query over the tenant's rows.
+type DashboardRepository struct {
+	db *sqlx.DB
+}
+
+// NewDashboardRepository creates a new dashboard repository
+func NewDashboardRepository(db *sqlx.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// ListActivityDays counts, per day (UTC) from start to today, the users with
+// a successful audited action, the sign-ins and the failed sign-ins. Days
+// without activity are included with zero counts.
+func (r *DashboardRepository) ListActivityDays(ctx context.Context, tenantID uuid.UUID, start time.Time) ([]models.DashboardDay, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	days := []models.DashboardDay{}
+	query := `
+		WITH activity AS (
+			SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,
+			       COUNT(DISTINCT user_id) FILTER (WHERE status = 'success') AS active_users,
+			       COUNT(*) FILTER (WHERE action = $3 AND status = 'success') AS logins,
+			       COUNT(*) FILTER (WHERE action = $4) AS failed_logins
+			FROM audit_logs
+			WHERE tenant_id = $1 AND created_at >= $2
+			GROUP BY 1
+		)
+		SELECT d.day,
+		       COALESCE(a.active_users, 0) AS active_users,
+		       COALESCE(a.logins, 0) AS logins,
+		       COALESCE(a.failed_logins, 0) AS failed_logins
+		FROM generate_series(
+			date_trunc('day', $2::timestamptz AT TIME ZONE 'UTC'),
+			date_trunc('day', NOW() AT TIME ZONE 'UTC'),
+			INTERVAL '1 day'
+		) AS d(day)
+		LEFT JOIN activity a ON a.day = d.day
+		ORDER BY d.day
+	`
+
+	if err := tx.SelectContext(ctx, &days, query, tenantID, start, models.ActionUserLogin, models.ActionUserLoginFailed); err != nil {
+		return nil, fmt.Errorf("failed to count activity per day: %w", err)
+	}
+
+	return days, nil
+}
+
+// CountInvitations counts the tenant's invitations by status, and those
+// accepted since monthStart
+func (r *DashboardRepository) CountInvitations(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardInvitations, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var counts models.DashboardInvitations
+	query := `
+		SELECT COUNT(*) FILTER (WHERE status = $3 AND expires_at > NOW()) AS pending,
+		       COUNT(*) FILTER (WHERE status = $4) AS accepted,
+		       COUNT(*) FILTER (WHERE status = $5 OR (status = $3 AND expires_at <= NOW())) AS expired,
+		       COUNT(*) FILTER (WHERE status = $6) AS revoked,
+		       COUNT(*) FILTER (WHERE status = $4 AND accepted_at >= $2) AS accepted_this_month
+		FROM invitations
+		WHERE tenant_id = $1
+	`
+
+	err = tx.GetContext(ctx, &counts, query, tenantID, monthStart,
+		models.InvitationStatusPending,
+		models.InvitationStatusAccepted,
+		models.InvitationStatusExpired,
+		models.InvitationStatusRevoked,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count invitations: %w", err)
+	}
+
+	return &counts, nil
+}
+
+// CountSessionsByDevice counts the tenant's active sessions per device type,
+// most sessions first. Sessions without a device type count as Unknown.
+func (r *DashboardRepository) CountSessionsByDevice(ctx context.Context, tenantID uuid.UUID) ([]models.DashboardDeviceSessions, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	devices := []models.DashboardDeviceSessions{}
+	query := `
+		SELECT COALESCE(NULLIF(device_type, ''), 'Unknown') AS device_type, COUNT(*) AS sessions
+		FROM sessions
+		WHERE tenant_id = $1 AND expires_at > NOW()
+		GROUP BY 1
+		ORDER BY sessions DESC, device_type
+	`
+
+	if err := tx.SelectContext(ctx, &devices, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to count sessions by device: %w", err)
+	}
+
+	return devices, nil
+}
+
+// CountNewUsers counts the users created since monthStart and in the month
+// before it, excluding deleted users
+func (r *DashboardRepository) CountNewUsers(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardNewUsers, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var counts models.DashboardNewUsers
+	query := `
+		SELECT COUNT(*) FILTER (WHERE created_at >= $2) AS this_month,
+		       COUNT(*) FILTER (WHERE created_at < $2) AS last_month
+		FROM users
+		WHERE tenant_id = $1 AND deleted_at IS NULL AND created_at >= $3
+	`
+
+	if err := tx.GetContext(ctx, &counts, query, tenantID, monthStart, monthStart.AddDate(0, -1, 0)); err != nil {
+		return nil, fmt.Errorf("failed to count new users: %w", err)
+	}
+
+	return &counts, nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// dataQualityQueries select the records each check finds an issue with, as
+// (resource_id, label, path) rows of the tenant $1
+var dataQualityQueries = map[string]string{
+	models.DataQualitySupplierMissingTaxID: `
+		SELECT id AS resource_id, name AS label, '/purchasing/suppliers/' || id AS path
+		FROM suppliers
+		WHERE tenant_id = $1 AND status = 'active' AND COALESCE(TRIM(tax_id), '') = ''
+	`,
+	models.DataQualitySupplierMissingContact: `
+		SELECT id AS resource_id, name AS label, '/purchasing/suppliers/' || id AS path
+		FROM suppliers
+		WHERE tenant_id = $1 AND status = 'active'
+		  AND COALESCE(TRIM(email), '') = '' AND COALESCE(TRIM(phone), '') = ''
+	`,
+	models.DataQualityCustomerMissingEmail: `
+		SELECT NULL::uuid AS resource_id, customer_name AS label, '' AS path
+		FROM sales_documents
+		WHERE tenant_id = $1
+		GROUP BY customer_name
+		HAVING bool_or(status IN ('draft', 'confirmed'))
+		   AND NOT bool_or(COALESCE(TRIM(customer_email), '') != '')
+	`,
+	models.DataQualityProductMissingPrice: `
+		SELECT NULL::uuid AS resource_id, l.product_code AS label, '' AS path
+		FROM sales_document_lines l
+		JOIN sales_documents d ON d.tenant_id = l.tenant_id AND d.id = l.document_id
+		WHERE l.tenant_id = $1 AND d.status != 'cancelled' AND COALESCE(TRIM(l.product_code), '') != ''
+		GROUP BY l.product_code
+		HAVING bool_or(d.status IN ('draft', 'confirmed')) AND MAX(l.unit_price) <= 0
+	`,
+	models.DataQualityUserMissingDepartment: `
+		SELECT id AS resource_id, first_name || ' ' || last_name || ' <' || email || '>' AS label, '/users/' || id AS path
+		FROM users
+		WHERE tenant_id = $1 AND status = 'active' AND deleted_at IS NULL AND department_id IS NULL
+	`,
+}
+
+// DataQualityRepository handles database operations for data-quality rules
+// and runs their checks
+type DataQualityRepository struct {
+	db *sqlx.DB
+}
+
+// NewDataQualityRepository creates a new data-quality repository
+func NewDataQualityRepository(db *sqlx.DB) *DataQualityRepository {
+	return &DataQualityRepository{db: db}
+}
+
+// Create saves a new rule
+func (r *DataQualityRepository) Create(ctx context.Context, rule *models.DataQualityRule) error {
+	tx, err := database.WithTenantContext(ctx, r.db, rule.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO data_quality_rules (tenant_id, check_key, severity, notify_role_id, is_enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		rule.TenantID,
+		rule.CheckKey,
+		rule.Severity,
+		rule.NotifyRoleID,
+		rule.IsEnabled,
+		rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create data quality rule: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a rule
+func (r *DataQualityRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.DataQualityRule, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rule models.DataQualityRule
+	err = tx.GetContext(ctx, &rule, `SELECT * FROM data_quality_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("DATA_QUALITY_RULE_NOT_FOUND", "data quality rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find data quality rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// List retrieves a tenant's rules by check
+func (r *DataQualityRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rules := []models.DataQualityRule{}
+	query := `SELECT * FROM data_quality_rules WHERE tenant_id = $1 ORDER BY check_key`
+	if err := tx.SelectContext(ctx, &rules, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list data quality rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// ListEnabled retrieves the enabled rules of every tenant in db
+func (r *DataQualityRepository) ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.DataQualityRule, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rules := []models.DataQualityRule{}
+	query := `SELECT * FROM data_quality_rules WHERE is_enabled = true ORDER BY tenant_id, check_key`
+	if err := tx.SelectContext(ctx, &rules, query); err != nil {
+		return nil, fmt.Errorf("failed to list data quality rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// CheckExists checks if the tenant already has a rule for a check
+func (r *DataQualityRepository) CheckExists(ctx context.Context, tenantID uuid.UUID, checkKey string) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM data_quality_rules WHERE tenant_id = $1 AND check_key = $2)`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, checkKey); err != nil {
+		return false, fmt.Errorf("failed to check data quality rule: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Update saves a rule's settings
+func (r *DataQualityRepository) Update(ctx context.Context, rule *models.DataQualityRule) error {
+	tx, err := database.WithTenantContext(ctx, r.db, rule.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE data_quality_rules
+		SET severity = $1, notify_role_id = $2, is_enabled = $3, updated_at = NOW()
+		WHERE tenant_id = $4 AND id = $5
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		rule.Severity,
+		rule.NotifyRoleID,
+		rule.IsEnabled,
+		rule.TenantID,
+		rule.ID,
+	).Scan(&rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("DATA_QUALITY_RULE_NOT_FOUND", "data quality rule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update data quality rule: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a rule
+func (r *DataQualityRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM data_quality_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete data quality rule: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("DATA_QUALITY_RULE_NOT_FOUND", "data quality rule not found")
+	}
+
+	return tx.Commit()
+}
+
+// RunCheck counts the tenant's records a check finds an issue with and
+// returns the first sampleSize of them by label
+func (r *DataQualityRepository) RunCheck(ctx context.Context, tenantID uuid.UUID, checkKey string, sampleSize int) (int, []models.DataQualityIssue, error) {
+	issuesQuery, ok := dataQualityQueries[checkKey]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown data quality check: %s", checkKey)
+	}
+
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM (`+issuesQuery+`) issues`, tenantID); err != nil {
+		return 0, nil, fmt.Errorf("failed to run data quality check %s: %w", checkKey, err)
+	}
+
+	issues := []models.DataQualityIssue{}
+	query := `SELECT * FROM (` + issuesQuery + `) issues ORDER BY label LIMIT $2`
+	if err := tx.SelectContext(ctx, &issues, query, tenantID, sampleSize); err != nil {
+		return 0, nil, fmt.Errorf("failed to run data quality check %s: %w", checkKey, err)
+	}
+
+	return count, issues, nil
+}
+
+// RecordResult saves the result of a rule's run
+func (r *DataQualityRepository) RecordResult(ctx context.Context, tenantID, id uuid.UUID, checkedAt time.Time, issueCount int, samples []models.DataQualityIssue) error {
+	encoded, err := json.Marshal(samples)
+	if err != nil {
+		return fmt.Errorf("failed to encode data quality samples: %w", err)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE data_quality_rules
+		SET last_checked_at = $1, issue_count = $2, samples = $3
+		WHERE tenant_id = $4 AND id = $5
+	`
+	if _, err := tx.ExecContext(ctx, query, checkedAt, issueCount, string(encoded), tenantID, id); err != nil {
+		return fmt.Errorf("failed to record data quality result: %w", err)
+	}
+
+	return tx.Commit()
+}
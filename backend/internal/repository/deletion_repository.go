@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// DeletionRepository handles database operations for staged deletions
+type DeletionRepository struct {
+	db *sqlx.DB
+}
+
+// NewDeletionRepository creates a new deletion repository
+func NewDeletionRepository(db *sqlx.DB) *DeletionRepository {
+	return &DeletionRepository{db: db}
+}
+
+// Create stages a deletion within the caller's transaction, so its
+// notification can be queued atomically with it
+func (r *DeletionRepository) Create(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error {
+	var exists bool
+	err := tx.GetContext(ctx, &exists, `
+		SELECT EXISTS(
+			SELECT 1 FROM pending_deletions
+			WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3 AND status = 'pending'
+		)
+	`, deletion.TenantID, deletion.EntityType, deletion.EntityID)
+	if err != nil {
+		return fmt.Errorf("failed to check pending deletion: %w", err)
+	}
+	if exists {
+		return utils.NewConflictError("ALREADY_SCHEDULED_FOR_DELETION", "already scheduled for deletion")
+	}
+
+	deletion.Status = models.DeletionStatusPending
+
+	query := `
+		INSERT INTO pending_deletions (tenant_id, entity_type, entity_id, label, status, execute_at, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		deletion.TenantID,
+		deletion.EntityType,
+		deletion.EntityID,
+		deletion.Label,
+		deletion.Status,
+		deletion.ExecuteAt,
+		deletion.RequestedBy,
+	).Scan(&deletion.ID, &deletion.CreatedAt, &deletion.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a staged deletion
+func (r *DeletionRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.PendingDeletion, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var deletion models.PendingDeletion
+	err = tx.GetContext(ctx, &deletion, `SELECT * FROM pending_deletions WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("DELETION_NOT_FOUND", "deletion not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deletion: %w", err)
+	}
+
+	return &deletion, nil
+}
+
+// ListByRequester retrieves the deletions a user staged, newest first.
+// An empty status returns all of them.
+func (r *DeletionRepository) ListByRequester(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND requested_by = $2 AND ($3 = '' OR status = $3)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM pending_deletions `+where, tenantID, userID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count deletions: %w", err)
+	}
+
+	deletions := []models.PendingDeletion{}
+	query := `SELECT * FROM pending_deletions ` + where + ` ORDER BY created_at DESC LIMIT $4 OFFSET $5`
+
+	if err := tx.SelectContext(ctx, &deletions, query, tenantID, userID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list deletions: %w", err)
+	}
+
+	return deletions, totalCount, nil
+}
+
+// MarkUndone cancels a pending deletion. A deletion the worker is executing
+// stays locked until it finishes and can then no longer be undone.
+func (r *DeletionRepository) MarkUndone(ctx context.Context, tenantID, id, userID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE pending_deletions
+		SET status = $1, undone_by = $2, undone_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4 AND status = 'pending'
+	`
+
+	result, err := tx.ExecContext(ctx, query, models.DeletionStatusUndone, userID, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to undo deletion: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("DELETION_NOT_UNDOABLE", "deletion can no longer be undone")
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue locks the next deletion whose undo window has passed, skipping
+// rows another worker already holds and deletions of paused tenants. Returns
+// nil when nothing is due.
+func (r *DeletionRepository) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.PendingDeletion, error) {
+	var deletion models.PendingDeletion
+	query := `
+		SELECT * FROM pending_deletions
+		WHERE status = 'pending' AND execute_at <= NOW() AND tenant_id != ALL($1::uuid[])
+		ORDER BY execute_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &deletion, query, pq.Array(paused))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deletion: %w", err)
+	}
+
+	return &deletion, nil
+}
+
+// MarkExecuted records that a deletion was carried out
+func (r *DeletionRepository) MarkExecuted(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error {
+	query := `
+		UPDATE pending_deletions
+		SET status = $1, error = NULL, executed_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+	`
+	if _, err := tx.ExecContext(ctx, query, models.DeletionStatusExecuted, deletion.TenantID, deletion.ID); err != nil {
+		return fmt.Errorf("failed to mark deletion executed: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records why a deletion could not be carried out (e.g. the
+// document was confirmed during the undo window)
+func (r *DeletionRepository) MarkFailed(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion, cause string) error {
+	query := `
+		UPDATE pending_deletions
+		SET status = $1, error = $2, updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4
+	`
+	if _, err := tx.ExecContext(ctx, query, models.DeletionStatusFailed, cause, deletion.TenantID, deletion.ID); err != nil {
+		return fmt.Errorf("failed to mark deletion failed: %w", err)
+	}
+	return nil
+}
@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/utils"
 )
 
 // DepartmentRepository handles database operations for departments
@@ -29,10 +32,14 @@ func (r *DepartmentRepository) Create(ctx context.Context, tenantID uuid.UUID, d
 	}
 	defer tx.Rollback()
 
+	if len(dept.CustomFields) == 0 {
+		dept.CustomFields = json.RawMessage(`{}`)
+	}
+
 	query := `
 		INSERT INTO departments (
-			tenant_id, name, description, head_user_id, color, icon, status, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			tenant_id, name, description, head_user_id, color, icon, status, custom_fields, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -45,6 +52,7 @@ func (r *DepartmentRepository) Create(ctx context.Context, tenantID uuid.UUID, d
 		dept.Color,
 		dept.Icon,
 		dept.Status,
+		dept.CustomFields,
 		dept.CreatedBy,
 	).Scan(&dept.ID, &dept.CreatedAt, &dept.UpdatedAt)
 
@@ -70,7 +78,7 @@ func (r *DepartmentRepository) FindByID(ctx context.Context, tenantID, deptID uu
 
 	err = tx.GetContext(ctx, &dept, query, tenantID, deptID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("department not found")
+		return nil, utils.NewNotFoundError("DEPARTMENT_NOT_FOUND", "department not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find department: %w", err)
@@ -92,7 +100,7 @@ func (r *DepartmentRepository) FindByName(ctx context.Context, tenantID uuid.UUI
 
 	err = tx.GetContext(ctx, &dept, query, tenantID, name)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("department not found")
+		return nil, utils.NewNotFoundError("DEPARTMENT_NOT_FOUND", "department not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find department: %w", err)
@@ -101,26 +109,33 @@ func (r *DepartmentRepository) FindByName(ctx context.Context, tenantID uuid.UUI
 	return &dept, nil
 }
 
-// List retrieves all departments for a tenant with enriched data
-func (r *DepartmentRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models.Department, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+// List retrieves the departments of a tenant matching filter with enriched
+// data
+func (r *DepartmentRepository) List(ctx context.Context, tenantID uuid.UUID, filter models.DepartmentFilter) ([]models.Department, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	where := &pagination.Where{}
+	if len(filter.CustomFields) > 0 {
+		where.Add("d.custom_fields @> $%d::jsonb", filter.CustomFields)
+	}
+
 	var departments []models.Department
 	query := `
 		SELECT
 			d.*,
 			u.first_name || ' ' || u.last_name AS head_user_name,
-			COALESCE((SELECT COUNT(*) FROM users WHERE department_id = d.id AND tenant_id = d.tenant_id), 0) AS member_count
+			COALESCE((SELECT COUNT(*) FROM users WHERE department_id = d.id AND tenant_id = d.tenant_id AND deleted_at IS NULL), 0) AS member_count
 		FROM departments d
-		LEFT JOIN users u ON d.head_user_id = u.id AND d.tenant_id = u.tenant_id
+		LEFT JOIN users u ON d.head_user_id = u.id AND d.tenant_id = u.tenant_id AND u.deleted_at IS NULL
+		` + where.SQL() + `
 		ORDER BY d.created_at DESC
 	`
 
-	err = tx.SelectContext(ctx, &departments, query)
+	err = tx.SelectContext(ctx, &departments, query, where.Args()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list departments: %w", err)
 	}
@@ -130,7 +145,7 @@ func (r *DepartmentRepository) List(ctx context.Context, tenantID uuid.UUID) ([]
 
 // ListActive retrieves only active departments
 func (r *DepartmentRepository) ListActive(ctx context.Context, tenantID uuid.UUID) ([]models.Department, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -164,16 +179,16 @@ func (r *DepartmentRepository) GetWithDetails(ctx context.Context, tenantID, dep
 		SELECT
 			d.*,
 			u.first_name || ' ' || u.last_name AS head_user_name,
-			COALESCE((SELECT COUNT(*) FROM users WHERE department_id = d.id AND tenant_id = d.tenant_id), 0) AS member_count
+			COALESCE((SELECT COUNT(*) FROM users WHERE department_id = d.id AND tenant_id = d.tenant_id AND deleted_at IS NULL), 0) AS member_count
 		FROM departments d
-		LEFT JOIN users u ON d.head_user_id = u.id AND d.tenant_id = u.tenant_id
+		LEFT JOIN users u ON d.head_user_id = u.id AND d.tenant_id = u.tenant_id AND u.deleted_at IS NULL
 		WHERE d.tenant_id = $1 AND d.id = $2
 		LIMIT 1
 	`
 
 	err = tx.GetContext(ctx, &dept, query, tenantID, deptID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("department not found")
+		return nil, utils.NewNotFoundError("DEPARTMENT_NOT_FOUND", "department not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get department: %w", err)
@@ -198,8 +213,9 @@ func (r *DepartmentRepository) Update(ctx context.Context, tenantID uuid.UUID, d
 			color = $4,
 			icon = $5,
 			status = $6,
+			custom_fields = $7,
 			updated_at = NOW()
-		WHERE id = $7
+		WHERE id = $8
 		RETURNING updated_at
 	`
 
@@ -211,6 +227,7 @@ func (r *DepartmentRepository) Update(ctx context.Context, tenantID uuid.UUID, d
 		dept.Color,
 		dept.Icon,
 		dept.Status,
+		dept.CustomFields,
 		dept.ID,
 	).Scan(&dept.UpdatedAt)
 
@@ -238,7 +255,7 @@ func (r *DepartmentRepository) Delete(ctx context.Context, tenantID, deptID uuid
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("department not found")
+		return utils.NewNotFoundError("DEPARTMENT_NOT_FOUND", "department not found")
 	}
 
 	return tx.Commit()
@@ -255,7 +272,7 @@ func (r *DepartmentRepository) GetMembers(ctx context.Context, tenantID, deptID
 	var users []models.User
 	query := `
 		SELECT u.* FROM users u
-		WHERE u.department_id = $1
+		WHERE u.department_id = $1 AND u.deleted_at IS NULL
 		ORDER BY u.first_name, u.last_name
 	`
 
@@ -302,7 +319,7 @@ func (r *DepartmentRepository) CountMembers(ctx context.Context, tenantID, deptI
 	defer tx.Rollback()
 
 	var count int
-	query := `SELECT COUNT(*) FROM users WHERE department_id = $1`
+	query := `SELECT COUNT(*) FROM users WHERE department_id = $1 AND deleted_at IS NULL`
 
 	err = tx.GetContext(ctx, &count, query, deptID)
 	if err != nil {
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/tracing"
+	"myerp-v2/internal/utils"
+)
+
+// EmailOutboxRepository handles database operations for the email outbox
+type EmailOutboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmailOutboxRepository creates a new email outbox repository
+func NewEmailOutboxRepository(db *sqlx.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+// Enqueue inserts a message within the caller's transaction, so it is only
+// queued if the triggering change commits. The tenant may have at most
+// maxPending pending emails (0 for no limit).
+func (r *EmailOutboxRepository) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage, maxPending int) (uuid.UUID, error) {
+	// The pending count stops at the limit, so a full queue costs no more
+	// to check than one at the limit
+	query := `
+		INSERT INTO email_outbox (tenant_id, to_email, subject, body, text_body, template, trace_context)
+		SELECT $1::uuid, $2::text, $3::text, $4::text, NULLIF($5::text, ''), NULLIF($6::text, ''), $8::jsonb
+		WHERE $7::int = 0 OR (
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM email_outbox
+				WHERE tenant_id = $1 AND status = 'pending'
+				LIMIT $7
+			) pending
+		) < $7
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, query, tenantID, msg.To, msg.Subject, msg.Body, msg.Text, msg.Template, maxPending, tracing.Inject(ctx)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, utils.NewTooManyRequestsError("EMAIL_QUEUE_FULL", "email queue is full")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	return id, nil
+}
+
+// QueueStats measures the outbox of every tenant in db
+func (r *EmailOutboxRepository) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stats models.QueueStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'pending' AND next_attempt_at <= NOW()) AS due,
+			COUNT(*) FILTER (WHERE status = 'failed') AS dead_letters,
+			MIN(next_attempt_at) FILTER (WHERE status = 'pending' AND next_attempt_at <= NOW()) AS oldest_due_at
+		FROM email_outbox
+		WHERE status IN ('pending', 'failed')
+	`
+
+	if err := tx.GetContext(ctx, &stats, query); err != nil {
+		return nil, fmt.Errorf("failed to measure email queue: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// List retrieves a tenant's queued emails, newest first
+func (r *EmailOutboxRepository) List(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND ($2 = '' OR status = $2)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM email_outbox `+where, tenantID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count queued emails: %w", err)
+	}
+
+	emails := []models.OutboxEmail{}
+	query := `SELECT * FROM email_outbox ` + where + ` ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+
+	if err := tx.SelectContext(ctx, &emails, query, tenantID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list queued emails: %w", err)
+	}
+
+	return emails, totalCount, nil
+}
+
+// Stats counts a tenant's queued emails per status
+func (r *EmailOutboxRepository) Stats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stats models.EmailQueueStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'sent') AS sent,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed
+		FROM email_outbox
+		WHERE tenant_id = $1
+	`
+
+	if err := tx.GetContext(ctx, &stats, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to get email queue stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// Retry puts a failed email back in the queue with a fresh set of attempts
+func (r *EmailOutboxRepository) Retry(ctx context.Context, tenantID, emailID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND status = 'failed'
+	`
+
+	result, err := tx.ExecContext(ctx, query, tenantID, emailID)
+	if err != nil {
+		return fmt.Errorf("failed to retry email: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("QUEUED_EMAIL_NOT_FOUND", "queued email not found or not failed")
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue locks the next due email for delivery, skipping rows another
+// worker already holds. Returns nil when nothing is due.
+func (r *EmailOutboxRepository) ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	query := `
+		SELECT * FROM email_outbox
+		WHERE status = 'pending' AND next_attempt_at <= NOW()
+		ORDER BY next_attempt_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &email, query)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim queued email: %w", err)
+	}
+
+	return &email, nil
+}
+
+// RecipientUndeliverable reports whether the email's recipient is a user of
+// its tenant whose address the email provider reported as undeliverable
+func (r *EmailOutboxRepository) RecipientUndeliverable(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) (bool, error) {
+	var undeliverable bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users
+			WHERE tenant_id = $1 AND LOWER(email) = LOWER($2) AND email_undeliverable_at IS NOT NULL
+		)
+	`
+	if err := tx.GetContext(ctx, &undeliverable, query, email.TenantID, email.ToEmail); err != nil {
+		return false, fmt.Errorf("failed to check email recipient: %w", err)
+	}
+	return undeliverable, nil
+}
+
+// MarkSent records a successful delivery
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', attempts = attempts + 1, sent_at = NOW(), last_error = NULL, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`
+	if _, err := tx.ExecContext(ctx, query, email.TenantID, email.ID); err != nil {
+		return fmt.Errorf("failed to mark email as sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed fails an email without attempting it again
+func (r *EmailOutboxRepository) MarkFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'failed', last_error = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+	`
+	if _, err := tx.ExecContext(ctx, query, cause, email.TenantID, email.ID); err != nil {
+		return fmt.Errorf("failed to mark email as failed: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery. The email is retried at
+// nextAttemptAt, or marked failed once its attempts are exhausted.
+func (r *EmailOutboxRepository) MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE email_outbox
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= max_attempts THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = $1,
+			last_error = $2,
+			updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4
+	`
+	if _, err := tx.ExecContext(ctx, query, nextAttemptAt, cause, email.TenantID, email.ID); err != nil {
+		return fmt.Errorf("failed to record email delivery failure: %w", err)
+	}
+	return nil
+}
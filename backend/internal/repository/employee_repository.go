@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// employeeSelect selects employees (e) with their manager's name, department
+// name and number of direct reports
+const employeeSelect = `
+	SELECT
+		e.*,
+		m.first_name || ' ' || m.last_name AS manager_name,
+		d.name AS department_name,
+		(SELECT COUNT(*) FROM employees r WHERE r.tenant_id = e.tenant_id AND r.manager_id = e.id) AS direct_report_count
+	FROM employees e
+	LEFT JOIN employees m ON m.tenant_id = e.tenant_id AND m.id = e.manager_id
+	LEFT JOIN departments d ON d.tenant_id = e.tenant_id AND d.id = e.department_id
+`
+
+// EmployeeRepository handles database operations for employees
+type EmployeeRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmployeeRepository creates a new employee repository
+func NewEmployeeRepository(db *sqlx.DB) *EmployeeRepository {
+	return &EmployeeRepository{db: db}
+}
+
+// Create creates a new employee with RLS. A linked user is moved to the
+// employee's department, so department members stay in step.
+func (r *EmployeeRepository) Create(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO employees (
+			tenant_id, user_id, department_id, manager_id, employee_number,
+			first_name, last_name, email, phone, job_title, employment_type,
+			hire_date, termination_date, salary_band, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		employee.UserID,
+		employee.DepartmentID,
+		employee.ManagerID,
+		employee.EmployeeNumber,
+		employee.FirstName,
+		employee.LastName,
+		employee.Email,
+		employee.Phone,
+		employee.JobTitle,
+		employee.EmploymentType,
+		employee.HireDate,
+		employee.TerminationDate,
+		employee.SalaryBand,
+		employee.Status,
+		employee.CreatedBy,
+	).Scan(&employee.ID, &employee.CreatedAt, &employee.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create employee: %w", err)
+	}
+
+	if err := syncUserDepartment(ctx, tx.Tx, tenantID, employee); err != nil {
+		return err
+	}
+
+	employee.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindByID retrieves an employee with details by ID with RLS
+func (r *EmployeeRepository) FindByID(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var employee models.Employee
+	// Explicit tenant_id filter for defense in depth
+	query := employeeSelect + ` WHERE e.tenant_id = $1 AND e.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &employee, query, tenantID, employeeID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("EMPLOYEE_NOT_FOUND", "employee not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+
+	return &employee, nil
+}
+
+// FindByUserID retrieves the employee linked to a user
+func (r *EmployeeRepository) FindByUserID(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var employee models.Employee
+	query := employeeSelect + ` WHERE e.tenant_id = $1 AND e.user_id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &employee, query, tenantID, userID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("EMPLOYEE_NOT_FOUND", "employee not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+
+	return &employee, nil
+}
+
+// List retrieves employees with filters and pagination, sorted by name
+func (r *EmployeeRepository) List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE e.tenant_id = $1
+		AND ($2::uuid IS NULL OR e.department_id = $2)
+		AND ($3::uuid IS NULL OR e.manager_id = $3)
+		AND ($4 = '' OR e.status = $4)
+		AND ($5 = '' OR e.first_name || ' ' || e.last_name ILIKE '%' || $5 || '%'
+			OR e.email ILIKE '%' || $5 || '%'
+			OR e.employee_number ILIKE '%' || $5 || '%'
+			OR e.job_title ILIKE '%' || $5 || '%')
+	`
+	args := []interface{}{tenantID, filter.DepartmentID, filter.ManagerID, filter.Status, filter.Search}
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM employees e `+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count employees: %w", err)
+	}
+
+	employees := []models.Employee{}
+	query := employeeSelect + where + ` ORDER BY e.last_name ASC, e.first_name ASC LIMIT $6 OFFSET $7`
+
+	if err := tx.SelectContext(ctx, &employees, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list employees: %w", err)
+	}
+
+	return employees, totalCount, nil
+}
+
+// ListCurrent retrieves every employee who has not left, for the org chart
+func (r *EmployeeRepository) ListCurrent(ctx context.Context, tenantID uuid.UUID) ([]models.Employee, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	employees := []models.Employee{}
+	query := employeeSelect + `
+		WHERE e.tenant_id = $1 AND e.status != $2
+		ORDER BY e.last_name ASC, e.first_name ASC
+	`
+
+	if err := tx.SelectContext(ctx, &employees, query, tenantID, models.EmployeeStatusTerminated); err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+
+	return employees, nil
+}
+
+// Update updates an employee's information, moving a linked user to the
+// employee's department
+func (r *EmployeeRepository) Update(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE employees
+		SET user_id = $1,
+			department_id = $2,
+			manager_id = $3,
+			employee_number = $4,
+			first_name = $5,
+			last_name = $6,
+			email = $7,
+			phone = $8,
+			job_title = $9,
+			employment_type = $10,
+			hire_date = $11,
+			termination_date = $12,
+			salary_band = $13,
+			status = $14,
+			updated_at = NOW()
+		WHERE tenant_id = $15 AND id = $16
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		employee.UserID,
+		employee.DepartmentID,
+		employee.ManagerID,
+		employee.EmployeeNumber,
+		employee.FirstName,
+		employee.LastName,
+		employee.Email,
+		employee.Phone,
+		employee.JobTitle,
+		employee.EmploymentType,
+		employee.HireDate,
+		employee.TerminationDate,
+		employee.SalaryBand,
+		employee.Status,
+		tenantID,
+		employee.ID,
+	).Scan(&employee.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("EMPLOYEE_NOT_FOUND", "employee not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update employee: %w", err)
+	}
+
+	if err := syncUserDepartment(ctx, tx.Tx, tenantID, employee); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes an employee. Their direct reports move up to the
+// employee's own manager, so the reporting line is not broken.
+func (r *EmployeeRepository) Delete(ctx context.Context, tenantID, employeeID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var managerID *uuid.UUID
+	err = tx.GetContext(ctx, &managerID, `SELECT manager_id FROM employees WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, employeeID)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("EMPLOYEE_NOT_FOUND", "employee not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find employee: %w", err)
+	}
+
+	query := `UPDATE employees SET manager_id = $1, updated_at = NOW() WHERE tenant_id = $2 AND manager_id = $3`
+	if _, err := tx.ExecContext(ctx, query, managerID, tenantID, employeeID); err != nil {
+		return fmt.Errorf("failed to reassign direct reports: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM employees WHERE tenant_id = $1 AND id = $2`, tenantID, employeeID); err != nil {
+		return fmt.Errorf("failed to delete employee: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReportsTo checks whether ancestorID is in the management chain above
+// employeeID, directly or indirectly
+func (r *EmployeeRepository) ReportsTo(ctx context.Context, tenantID, employeeID, ancestorID uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// UNION (not UNION ALL) stops at rows already visited
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT manager_id FROM employees WHERE tenant_id = $1 AND id = $2
+			UNION
+			SELECT e.manager_id FROM employees e
+			JOIN chain c ON e.id = c.manager_id
+			WHERE e.tenant_id = $1
+		)
+		SELECT EXISTS(SELECT 1 FROM chain WHERE manager_id = $3)
+	`
+
+	var reports bool
+	if err := tx.GetContext(ctx, &reports, query, tenantID, employeeID, ancestorID); err != nil {
+		return false, fmt.Errorf("failed to check reporting line: %w", err)
+	}
+
+	return reports, nil
+}
+
+// CheckNumberExists checks if an employee number is already used in a tenant
+func (r *EmployeeRepository) CheckNumberExists(ctx context.Context, tenantID uuid.UUID, number string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM employees WHERE tenant_id = $1 AND employee_number = $2 AND ($3::uuid IS NULL OR id != $3))`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, number, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check employee number: %w", err)
+	}
+
+	return exists, nil
+}
+
+// syncUserDepartment moves the employee's linked user, if any, to the
+// employee's department
+func syncUserDepartment(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, employee *models.Employee) error {
+	if employee.UserID == nil {
+		return nil
+	}
+
+	query := `UPDATE users SET department_id = $1, updated_at = NOW() WHERE tenant_id = $2 AND id = $3`
+	if _, err := tx.ExecContext(ctx, query, employee.DepartmentID, tenantID, *employee.UserID); err != nil {
+		return fmt.Errorf("failed to update user department: %w", err)
+	}
+
+	return nil
+}
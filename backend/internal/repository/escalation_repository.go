@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// EscalationRepository handles database operations for escalation policies
+// and their escalation log
+type EscalationRepository struct {
+	db *sqlx.DB
+}
+
+// NewEscalationRepository creates a new escalation repository
+func NewEscalationRepository(db *sqlx.DB) *EscalationRepository {
+	return &EscalationRepository{db: db}
+}
+
+// Create saves a new policy
+func (r *EscalationRepository) Create(ctx context.Context, policy *models.EscalationPolicy) error {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode escalation steps: %w", err)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, policy.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO escalation_policies (tenant_id, name, description, resource_type, steps, is_enabled, slack_webhook_url, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, slack_configured, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		policy.TenantID,
+		policy.Name,
+		policy.Description,
+		policy.ResourceType,
+		string(steps),
+		policy.IsEnabled,
+		policy.SlackWebhookURL,
+		policy.CreatedBy,
+	).Scan(&policy.ID, &policy.SlackConfigured, &policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create escalation policy: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a policy
+func (r *EscalationRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.EscalationPolicy, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var policy models.EscalationPolicy
+	err = tx.GetContext(ctx, &policy, `SELECT * FROM escalation_policies WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ESCALATION_POLICY_NOT_FOUND", "escalation policy not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find escalation policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// List retrieves a tenant's policies by name
+func (r *EscalationRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM escalation_policies WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count escalation policies: %w", err)
+	}
+
+	policies := []models.EscalationPolicy{}
+	query := `SELECT * FROM escalation_policies WHERE tenant_id = $1 ORDER BY name LIMIT $2 OFFSET $3`
+	if err := tx.SelectContext(ctx, &policies, query, tenantID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+
+	return policies, totalCount, nil
+}
+
+// ListEnabled retrieves the enabled policies of every tenant in db
+func (r *EscalationRepository) ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.EscalationPolicy, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	policies := []models.EscalationPolicy{}
+	query := `SELECT * FROM escalation_policies WHERE is_enabled = true ORDER BY tenant_id, created_at`
+	if err := tx.SelectContext(ctx, &policies, query); err != nil {
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// CheckNameExists checks if a policy name is already used in the tenant
+func (r *EscalationRepository) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM escalation_policies
+			WHERE tenant_id = $1 AND name = $2 AND ($3::uuid IS NULL OR id != $3)
+		)
+	`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, name, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check escalation policy name: %w", err)
+	}
+
+	return exists, nil
+}
+
+// Update saves a policy's definition and state
+func (r *EscalationRepository) Update(ctx context.Context, policy *models.EscalationPolicy) error {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode escalation steps: %w", err)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, policy.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE escalation_policies
+		SET name = $1, description = $2, resource_type = $3, steps = $4, is_enabled = $5, slack_webhook_url = $6, updated_at = NOW()
+		WHERE tenant_id = $7 AND id = $8
+		RETURNING slack_configured, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		policy.Name,
+		policy.Description,
+		policy.ResourceType,
+		string(steps),
+		policy.IsEnabled,
+		policy.SlackWebhookURL,
+		policy.TenantID,
+		policy.ID,
+	).Scan(&policy.SlackConfigured, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("ESCALATION_POLICY_NOT_FOUND", "escalation policy not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetEnabled enables or disables a policy
+func (r *EscalationRepository) SetEnabled(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.EscalationPolicy, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var policy models.EscalationPolicy
+	query := `
+		UPDATE escalation_policies
+		SET is_enabled = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+		RETURNING *
+	`
+	err = tx.GetContext(ctx, &policy, query, enabled, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("ESCALATION_POLICY_NOT_FOUND", "escalation policy not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// Delete removes a policy and its escalation log
+func (r *EscalationRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM escalation_policies WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete escalation policy: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ESCALATION_POLICY_NOT_FOUND", "escalation policy not found")
+	}
+
+	return tx.Commit()
+}
+
+// LastSteps returns, per resource, the last step of a policy that fired
+func (r *EscalationRepository) LastSteps(ctx context.Context, tenantID, policyID uuid.UUID) (map[uuid.UUID]int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		ResourceID uuid.UUID `db:"resource_id"`
+		Step       int       `db:"step"`
+	}
+	query := `
+		SELECT resource_id, MAX(step) AS step
+		FROM escalations
+		WHERE tenant_id = $1 AND policy_id = $2
+		GROUP BY resource_id
+	`
+	if err := tx.SelectContext(ctx, &rows, query, tenantID, policyID); err != nil {
+		return nil, fmt.Errorf("failed to find escalations: %w", err)
+	}
+
+	steps := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		steps[row.ResourceID] = row.Step
+	}
+	return steps, nil
+}
+
+// Record logs a step that fired. Returns false if the step already fired for
+// the resource (another worker run got there first).
+func (r *EscalationRepository) Record(ctx context.Context, tx *sqlx.Tx, escalation *models.Escalation) (bool, error) {
+	query := `
+		INSERT INTO escalations (tenant_id, policy_id, resource_type, resource_id, title, step, after_hours, target, channels, recipients)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id, policy_id, resource_type, resource_id, step) DO NOTHING
+		RETURNING id, escalated_at
+	`
+
+	err := tx.QueryRowContext(ctx, query,
+		escalation.TenantID,
+		escalation.PolicyID,
+		escalation.ResourceType,
+		escalation.ResourceID,
+		escalation.Title,
+		escalation.Step,
+		escalation.AfterHours,
+		escalation.Target,
+		escalation.Channels,
+		escalation.Recipients,
+	).Scan(&escalation.ID, &escalation.EscalatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record escalation: %w", err)
+	}
+
+	return true, nil
+}
+
+// SetSlackResult records the outcome of an escalation's Slack post
+func (r *EscalationRepository) SetSlackResult(ctx context.Context, tenantID, id uuid.UUID, status string, slackErr *string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE escalations SET slack_status = $1, slack_error = $2 WHERE tenant_id = $3 AND id = $4`
+	if _, err := tx.ExecContext(ctx, query, status, slackErr, tenantID, id); err != nil {
+		return fmt.Errorf("failed to update escalation: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListByPolicy retrieves a policy's escalation log, newest first
+func (r *EscalationRepository) ListByPolicy(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM escalations WHERE tenant_id = $1 AND policy_id = $2`, tenantID, policyID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count escalations: %w", err)
+	}
+
+	escalations := []models.Escalation{}
+	query := `
+		SELECT e.*, p.name AS policy_name
+		FROM escalations e
+		JOIN escalation_policies p ON p.tenant_id = e.tenant_id AND p.id = e.policy_id
+		WHERE e.tenant_id = $1 AND e.policy_id = $2
+		ORDER BY e.escalated_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	if err := tx.SelectContext(ctx, &escalations, query, tenantID, policyID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list escalations: %w", err)
+	}
+
+	return escalations, totalCount, nil
+}
+
+// ListForResource retrieves the escalations of one approval, oldest first
+func (r *EscalationRepository) ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	escalations := []models.Escalation{}
+	query := `
+		SELECT e.*, p.name AS policy_name
+		FROM escalations e
+		JOIN escalation_policies p ON p.tenant_id = e.tenant_id AND p.id = e.policy_id
+		WHERE e.tenant_id = $1 AND e.resource_type = $2 AND e.resource_id = $3
+		ORDER BY e.escalated_at
+	`
+	if err := tx.SelectContext(ctx, &escalations, query, tenantID, resourceType, resourceID); err != nil {
+		return nil, fmt.Errorf("failed to list escalations: %w", err)
+	}
+
+	return escalations, nil
+}
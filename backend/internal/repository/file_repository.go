@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// filePurgeBatchSize bounds the files purged per data region in one run
+const filePurgeBatchSize = 500
+
+// FileRepository handles database operations for uploaded files
+type FileRepository struct {
+	db *sqlx.DB
+}
+
+// NewFileRepository creates a new file repository
+func NewFileRepository(db *sqlx.DB) *FileRepository {
+	return &FileRepository{db: db}
+}
+
+// Create records an uploaded file with RLS. The file's ID is set by the
+// caller, since its storage key contains it.
+func (r *FileRepository) Create(ctx context.Context, tenantID uuid.UUID, file *models.File) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO files (
+			id, tenant_id, storage_backend, storage_key, original_name, content_type,
+			size_bytes, checksum_sha256, category, resource_type, resource_id, uploaded_by, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		file.ID,
+		tenantID,
+		file.StorageBackend,
+		file.StorageKey,
+		file.OriginalName,
+		file.ContentType,
+		file.SizeBytes,
+		file.ChecksumSHA256,
+		file.Category,
+		file.ResourceType,
+		file.ResourceID,
+		file.UploadedBy,
+		file.ExpiresAt,
+	).Scan(&file.CreatedAt, &file.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	file.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindByID retrieves a file by ID with RLS, including deleted files
+func (r *FileRepository) FindByID(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var file models.File
+	query := `SELECT * FROM files WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &file, query, tenantID, fileID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// List retrieves a tenant's files, newest first. With visibleTo set, only
+// the files that user uploaded and avatars not deleted are listed.
+func (r *FileRepository) List(ctx context.Context, tenantID uuid.UUID, filter models.FileFilter, visibleTo *uuid.UUID) ([]models.File, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE tenant_id = $1
+		AND ($2 = '' OR category = $2)
+		AND ($3 = '' OR resource_type = $3)
+		AND ($4::uuid IS NULL OR resource_id = $4)
+		AND ($5::uuid IS NULL OR uploaded_by = $5)
+		AND ($6 OR deleted_at IS NULL)
+		AND ($7::uuid IS NULL OR uploaded_by = $7 OR (category = 'avatar' AND deleted_at IS NULL))
+	`
+	args := []interface{}{tenantID, filter.Category, filter.ResourceType, filter.ResourceID, filter.UploadedBy, filter.IncludeDeleted, visibleTo}
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM files`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count files: %w", err)
+	}
+
+	files := []models.File{}
+	query := `SELECT * FROM files` + where + ` ORDER BY created_at DESC LIMIT $8 OFFSET $9`
+
+	if err := tx.SelectContext(ctx, &files, query, append(args, filter.Limit, filter.Offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return files, totalCount, nil
+}
+
+// ListAll retrieves all of a tenant's files with RLS, including deleted
+// files, oldest first
+func (r *FileRepository) ListAll(ctx context.Context, tenantID uuid.UUID) ([]models.File, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	files := []models.File{}
+	query := `SELECT * FROM files WHERE tenant_id = $1 ORDER BY created_at ASC`
+	if err := tx.SelectContext(ctx, &files, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return files, nil
+}
+
+// SoftDelete marks a file deleted with RLS
+func (r *FileRepository) SoftDelete(ctx context.Context, tenantID, fileID, deletedBy uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE files
+		SET deleted_at = NOW(), deleted_by = $3
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, tenantID, fileID, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	return tx.Commit()
+}
+
+// SoftDeleteByResource marks the files of a category attached to a resource
+// deleted with RLS, except those in keep. Returns the number deleted.
+func (r *FileRepository) SoftDeleteByResource(ctx context.Context, tenantID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID, deletedBy uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE files
+		SET deleted_at = NOW(), deleted_by = $6
+		WHERE tenant_id = $1 AND category = $2 AND resource_type = $3 AND resource_id = $4
+		  AND deleted_at IS NULL
+		  AND NOT (id = ANY($5::uuid[]))
+	`
+
+	if keep == nil {
+		keep = []uuid.UUID{} // A NULL array would match no file
+	}
+
+	result, err := tx.ExecContext(ctx, query, tenantID, category, resourceType, resourceID, pq.Array(keep), deletedBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete files: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
+
+// Restore undoes a file's soft delete with RLS
+func (r *FileRepository) Restore(ctx context.Context, tenantID, fileID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE files
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NOT NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, tenantID, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedOrExpired permanently removes files soft-deleted before cutoff
+// and files whose lifecycle has ended, across all tenants and data regions
+// (bypasses RLS). remove is called for each file first to delete its stored
+// contents; files it fails for are kept and retried on the next run.
+func (r *FileRepository) PurgeDeletedOrExpired(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, file *models.File) error) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(r.db) {
+		purged, err := r.purgeDeletedOrExpired(ctx, db, cutoff, remove)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// purgeDeletedOrExpired purges one batch of files of a data region
+func (r *FileRepository) purgeDeletedOrExpired(ctx context.Context, db *sqlx.DB, cutoff time.Time, remove func(ctx context.Context, file *models.File) error) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	files := []models.File{}
+	query := `
+		SELECT * FROM files
+		WHERE deleted_at < $1 OR expires_at < NOW()
+		ORDER BY COALESCE(deleted_at, expires_at) ASC
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	if err := tx.SelectContext(ctx, &files, query, cutoff, filePurgeBatchSize); err != nil {
+		return 0, fmt.Errorf("failed to list files to purge: %w", err)
+	}
+
+	purged := 0
+	for i := range files {
+		file := &files[i]
+		if err := remove(ctx, file); err != nil {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM files WHERE tenant_id = $1 AND id = $2`, file.TenantID, file.ID); err != nil {
+			return 0, fmt.Errorf("failed to purge file: %w", err)
+		}
+		purged++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,3 @@
+package repository
+
+//go:generate go run ../../cmd/genmocks -suffix Repository -iface Store
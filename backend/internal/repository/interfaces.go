@@ -0,0 +1,867 @@
+// Code generated by genmocks. DO NOT EDIT.
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+)
+
+// AccountStore is implemented by AccountRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AccountStore interface {
+	CheckCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, tenantID uuid.UUID, account *models.Account) error
+	Delete(ctx context.Context, tenantID, accountID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error)
+	FindByIDs(ctx context.Context, tenantID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]models.Account, error)
+	IsDescendant(ctx context.Context, tenantID, accountID, candidateID uuid.UUID) (bool, error)
+	List(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error)
+	Update(ctx context.Context, tenantID uuid.UUID, account *models.Account) error
+}
+
+// AccountingPeriodStore is implemented by AccountingPeriodRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AccountingPeriodStore interface {
+	CountDraftEntries(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) (int, error)
+	Create(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) error
+	FindByID(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	FindForDate(ctx context.Context, tenantID uuid.UUID, date time.Time) (*models.AccountingPeriod, error)
+	List(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error)
+	UpdateStatus(ctx context.Context, tenantID, periodID, userID uuid.UUID, fromStatus, toStatus string) error
+}
+
+// AnnouncementStore is implemented by AnnouncementRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AnnouncementStore interface {
+	ClaimPublished(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.Announcement, error)
+	Create(ctx context.Context, announcement *models.Announcement) error
+	Delete(ctx context.Context, tenantID, announcementID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error)
+	List(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error)
+	ListAudienceIDs(ctx context.Context, tx *sqlx.Tx, announcement *models.Announcement) ([]uuid.UUID, error)
+	ListForUser(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error)
+	ListReads(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error)
+	MarkRead(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error
+	Update(ctx context.Context, announcement *models.Announcement) error
+}
+
+// ApprovalLinkStore is implemented by ApprovalLinkRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ApprovalLinkStore interface {
+	Create(ctx context.Context, link *models.ApprovalLink) error
+	DeleteExpiredBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.ApprovalLink, error)
+	Lock(ctx context.Context, tenantID, id uuid.UUID) (*database.Tx, *models.ApprovalLink, error)
+	MarkUsed(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, decision, stepUp, ip, userAgent string) error
+	RecordFailedAttempt(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, maxAttempts int) (bool, error)
+	Revoke(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink) error
+}
+
+// AsyncJobStore is implemented by AsyncJobRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AsyncJobStore interface {
+	ClaimNext(ctx context.Context, db *sqlx.DB, paused []uuid.UUID) (*models.AsyncJob, error)
+	Create(ctx context.Context, tx *sqlx.Tx, job *models.AsyncJob, maxQueued int) error
+	DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FailInterrupted(ctx context.Context, db *sqlx.DB) (int, error)
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AsyncJob, error)
+	Finish(ctx context.Context, job *models.AsyncJob, status string, result json.RawMessage, cause string) error
+	IsCancelRequested(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+	ListByRequester(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error)
+	QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RequestCancel(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.AsyncJob, error)
+	UpdateProgress(ctx context.Context, job *models.AsyncJob, progress int, message string) (bool, error)
+}
+
+// AutomationStore is implemented by AutomationRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AutomationStore interface {
+	CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	ClaimPending(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ClaimedAutomationExecution, error)
+	Create(ctx context.Context, rule *models.AutomationRule) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	EnqueueExecutions(ctx context.Context, tenantID uuid.UUID, ruleIDs []uuid.UUID, event *models.AutomationEvent) error
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.AutomationRule, error)
+	FindEnabledByTrigger(ctx context.Context, tenantID uuid.UUID, eventType string) ([]models.AutomationRule, error)
+	FindExecution(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error)
+	Finish(ctx context.Context, tx *sqlx.Tx, execution *models.ClaimedAutomationExecution, status string, results models.AutomationActionResults, startedAt time.Time) error
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error)
+	ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error)
+	SetEnabled(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.AutomationRule, error)
+	Update(ctx context.Context, rule *models.AutomationRule) error
+}
+
+// BroadcastStore is implemented by BroadcastRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type BroadcastStore interface {
+	Cancel(ctx context.Context, tenantID, broadcastID uuid.UUID) error
+	ClaimPending(ctx context.Context, tx *sqlx.Tx, limit, maxOutboxPending int) ([]models.PendingBroadcastEmail, error)
+	CompleteFinished(ctx context.Context, tx *sqlx.Tx) error
+	Create(ctx context.Context, broadcast *models.EmailBroadcast, filter *models.BroadcastFilter) error
+	FindByID(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error)
+	ListRecipients(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error)
+	MarkNotQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, status, reason string) error
+	MarkQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, outboxEmailID uuid.UUID) error
+	OptOut(ctx context.Context, tenantID, token uuid.UUID) (uuid.UUID, error)
+	Stats(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.BroadcastStats, error)
+}
+
+// CRMStore is implemented by CRMRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CRMStore interface {
+	AssignOwner(ctx context.Context, tenantID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID, activity *models.CRMActivity) error
+	CheckPipelineNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error
+	CreateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error
+	CreateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error
+	CreateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity) error
+	CreatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error
+	CreateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error
+	DeleteActivity(ctx context.Context, tenantID, activityID uuid.UUID) error
+	DeleteContact(ctx context.Context, tenantID, contactID uuid.UUID) error
+	DeleteCustomer(ctx context.Context, tenantID, customerID uuid.UUID) error
+	DeleteOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) error
+	DeletePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) error
+	DeleteStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error
+	FindActivityByID(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error)
+	FindContactByID(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error)
+	FindCustomerByID(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error)
+	FindOpportunityByID(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error)
+	FindPipelineByID(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error)
+	FindStageByID(ctx context.Context, tenantID, stageID uuid.UUID) (*models.CRMPipelineStage, error)
+	ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error)
+	ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error)
+	ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error)
+	ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error)
+	ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error)
+	PipelineSummary(ctx context.Context, tenantID, pipelineID uuid.UUID) ([]models.CRMStageSummary, error)
+	UpdateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error
+	UpdateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error
+	UpdateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error
+	UpdateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity, activity *models.CRMActivity) error
+	UpdatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error
+	UpdateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage, oldPosition int) error
+}
+
+// CompanySettingsStore is implemented by CompanySettingsRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CompanySettingsStore interface {
+	Create(ctx context.Context, tenantID uuid.UUID, settings *models.CompanySettings) error
+	GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error)
+	Update(ctx context.Context, tenantID uuid.UUID, updates map[string]interface{}, updatedBy uuid.UUID) (*models.CompanySettings, error)
+}
+
+// ComplianceStore is implemented by ComplianceRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ComplianceStore interface {
+	ListUserAccess(ctx context.Context, tenantID uuid.UUID, privilegedResources []string) ([]models.ComplianceUserAccess, error)
+	StreamActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time, actions []string, fn func(*models.AuditLog) error) error
+	StreamAdminActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time, resourceTypes, excludedActions []string, fn func(*models.AuditLog) error) error
+}
+
+// CustomFieldStore is implemented by CustomFieldRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CustomFieldStore interface {
+	Create(ctx context.Context, field *models.CustomField) error
+	Delete(ctx context.Context, tenantID, fieldID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error)
+	List(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error)
+	Update(ctx context.Context, field *models.CustomField) error
+}
+
+// DashboardStore is implemented by DashboardRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DashboardStore interface {
+	CountInvitations(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardInvitations, error)
+	CountNewUsers(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardNewUsers, error)
+	CountSessionsByDevice(ctx context.Context, tenantID uuid.UUID) ([]models.DashboardDeviceSessions, error)
+	ListActivityDays(ctx context.Context, tenantID uuid.UUID, start time.Time) ([]models.DashboardDay, error)
+}
+
+// DataQualityStore is implemented by DataQualityRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DataQualityStore interface {
+	CheckExists(ctx context.Context, tenantID uuid.UUID, checkKey string) (bool, error)
+	Create(ctx context.Context, rule *models.DataQualityRule) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.DataQualityRule, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error)
+	ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.DataQualityRule, error)
+	RecordResult(ctx context.Context, tenantID, id uuid.UUID, checkedAt time.Time, issueCount int, samples []models.DataQualityIssue) error
+	RunCheck(ctx context.Context, tenantID uuid.UUID, checkKey string, sampleSize int) (int, []models.DataQualityIssue, error)
+	Update(ctx context.Context, rule *models.DataQualityRule) error
+}
+
+// DeletionStore is implemented by DeletionRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DeletionStore interface {
+	ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.PendingDeletion, error)
+	Create(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.PendingDeletion, error)
+	ListByRequester(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error)
+	MarkExecuted(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error
+	MarkFailed(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion, cause string) error
+	MarkUndone(ctx context.Context, tenantID, id, userID uuid.UUID) error
+}
+
+// DepartmentStore is implemented by DepartmentRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DepartmentStore interface {
+	CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CountMembers(ctx context.Context, tenantID, deptID uuid.UUID) (int, error)
+	Create(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error
+	Delete(ctx context.Context, tenantID, deptID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, deptID uuid.UUID) (*models.Department, error)
+	FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Department, error)
+	GetMembers(ctx context.Context, tenantID, deptID uuid.UUID) ([]models.User, error)
+	GetWithDetails(ctx context.Context, tenantID, deptID uuid.UUID) (*models.Department, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter models.DepartmentFilter) ([]models.Department, error)
+	ListActive(ctx context.Context, tenantID uuid.UUID) ([]models.Department, error)
+	Update(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error
+}
+
+// EmailOutboxStore is implemented by EmailOutboxRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EmailOutboxStore interface {
+	ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.OutboxEmail, error)
+	Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage, maxPending int) (uuid.UUID, error)
+	List(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error)
+	MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string, nextAttemptAt time.Time) error
+	MarkFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string) error
+	MarkSent(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) error
+	QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RecipientUndeliverable(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) (bool, error)
+	Retry(ctx context.Context, tenantID, emailID uuid.UUID) error
+	Stats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error)
+}
+
+// EmployeeStore is implemented by EmployeeRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EmployeeStore interface {
+	CheckNumberExists(ctx context.Context, tenantID uuid.UUID, number string, excludeID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error
+	Delete(ctx context.Context, tenantID, employeeID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error)
+	FindByUserID(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error)
+	ListCurrent(ctx context.Context, tenantID uuid.UUID) ([]models.Employee, error)
+	ReportsTo(ctx context.Context, tenantID, employeeID, ancestorID uuid.UUID) (bool, error)
+	Update(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error
+}
+
+// EscalationStore is implemented by EscalationRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EscalationStore interface {
+	CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, policy *models.EscalationPolicy) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.EscalationPolicy, error)
+	LastSteps(ctx context.Context, tenantID, policyID uuid.UUID) (map[uuid.UUID]int, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error)
+	ListByPolicy(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error)
+	ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.EscalationPolicy, error)
+	ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error)
+	Record(ctx context.Context, tx *sqlx.Tx, escalation *models.Escalation) (bool, error)
+	SetEnabled(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.EscalationPolicy, error)
+	SetSlackResult(ctx context.Context, tenantID, id uuid.UUID, status string, slackErr *string) error
+	Update(ctx context.Context, policy *models.EscalationPolicy) error
+}
+
+// FileStore is implemented by FileRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type FileStore interface {
+	Create(ctx context.Context, tenantID uuid.UUID, file *models.File) error
+	FindByID(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter models.FileFilter, visibleTo *uuid.UUID) ([]models.File, int, error)
+	ListAll(ctx context.Context, tenantID uuid.UUID) ([]models.File, error)
+	PurgeDeletedOrExpired(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, file *models.File) error) (int, error)
+	Restore(ctx context.Context, tenantID, fileID uuid.UUID) error
+	SoftDelete(ctx context.Context, tenantID, fileID, deletedBy uuid.UUID) error
+	SoftDeleteByResource(ctx context.Context, tenantID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID, deletedBy uuid.UUID) (int, error)
+}
+
+// JournalEntryStore is implemented by JournalEntryRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type JournalEntryStore interface {
+	Create(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error
+	CreateReversal(ctx context.Context, tenantID, originalID uuid.UUID, reversal *models.JournalEntry) error
+	Delete(ctx context.Context, tenantID, entryID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error)
+	List(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.JournalEntryFilter,
+		limit, offset int,
+	) ([]models.JournalEntry, int, error)
+	Lock(ctx context.Context, tenantID, entryID uuid.UUID) error
+	Post(ctx context.Context, tenantID, entryID, periodID, userID uuid.UUID) error
+	TrialBalance(ctx context.Context, tenantID uuid.UUID, fromDate *time.Time, toDate time.Time) ([]models.TrialBalanceRow, error)
+	Update(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error
+}
+
+// LeaveStore is implemented by LeaveRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type LeaveStore interface {
+	AddToBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, pending, used float64) error
+	Calendar(ctx context.Context, tenantID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error)
+	CheckTypeCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	CreateRequest(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error
+	CreateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error
+	DeleteType(ctx context.Context, tenantID, typeID uuid.UUID) error
+	FindBalance(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID, typeID uuid.UUID, year int) (*models.LeaveBalance, error)
+	FindRequestByID(ctx context.Context, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	FindTypeByID(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error)
+	HasOverlap(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, start, end time.Time) (bool, error)
+	ListAccrualTargets(ctx context.Context, db *sqlx.DB) ([]models.LeaveAccrualTarget, error)
+	ListBalances(ctx context.Context, tenantID, employeeID uuid.UUID, year int) ([]models.LeaveBalance, error)
+	ListRequests(ctx context.Context, tenantID uuid.UUID, filter models.LeaveRequestFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error)
+	LockRequest(ctx context.Context, tx *sqlx.Tx, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	SetAdjustment(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, adjustment float64) error
+	UpdateRequestStatus(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error
+	UpdateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error
+	UpsertBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance) error
+}
+
+// NavigationStore is implemented by NavigationRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type NavigationStore interface {
+	AddFavorite(ctx context.Context, favorite *models.Favorite) (bool, error)
+	CountFavorites(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	ListFavorites(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error)
+	ListRecent(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error)
+	RemoveFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	ReplaceRecent(ctx context.Context, tenantID, userID uuid.UUID, views []models.RecentView) error
+}
+
+// NotificationStore is implemented by NotificationRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type NotificationStore interface {
+	CountUnread(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	Create(ctx context.Context, tenantID uuid.UUID, notifications []*models.Notification) error
+	DeleteReadBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FinishDigest(ctx context.Context, tx *sqlx.Tx, prefs *models.NotificationPreferences, sentAt time.Time) error
+	GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	ListDigestPending(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID) ([]models.Notification, error)
+	ListDigestRecipients(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error)
+	ListPreferences(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]*models.NotificationPreferences, error)
+	MarkRead(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	MarkUnread(ctx context.Context, tenantID, userID, id uuid.UUID) error
+	SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error
+}
+
+// ObjectACLStore is implemented by ObjectACLRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ObjectACLStore interface {
+	Find(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error)
+	ListRestricted(ctx context.Context, tenantID uuid.UUID, resourceType string) ([]models.ObjectACL, error)
+	Save(ctx context.Context, tenantID uuid.UUID, acl *models.ObjectACL, updatedBy uuid.UUID) error
+}
+
+// PermissionStore is implemented by PermissionRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PermissionStore interface {
+	Count(ctx context.Context) (int, error)
+	CountByCategory(ctx context.Context) (map[string]int, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Permission, error)
+	FindByResourceAction(ctx context.Context, resource, action string) (*models.Permission, error)
+	GetActionsByResource(ctx context.Context, resource string) ([]string, error)
+	GetCategoryList(ctx context.Context) ([]string, error)
+	GetRegistry(ctx context.Context) ([]models.PermissionModule, error)
+	GetResourceList(ctx context.Context) ([]string, error)
+	List(ctx context.Context) ([]models.Permission, error)
+	ListByCategory(ctx context.Context) ([]models.PermissionGroup, error)
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Permission, error)
+	ListByResource(ctx context.Context, resource string) ([]models.Permission, error)
+	Search(ctx context.Context, searchTerm string) ([]models.Permission, error)
+	ValidatePermissionIDs(ctx context.Context, ids []uuid.UUID) (bool, []uuid.UUID, error)
+}
+
+// PersonalDataStore is implemented by PersonalDataRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PersonalDataStore interface {
+	Anonymize(ctx context.Context, tenantID, userID uuid.UUID, email string) error
+	Export(ctx context.Context, tenantID, userID uuid.UUID, export *models.PersonalDataExport) error
+}
+
+// PlatformStore is implemented by PlatformRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PlatformStore interface {
+	CountTenants(ctx context.Context, signedUpSince time.Time, stats *models.PlatformStats) error
+	CountUsers(ctx context.Context, activeSince time.Time) (int, int, error)
+	GrantAdmin(ctx context.Context, admin *models.PlatformAdmin) error
+	IsAdmin(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	ListAdmins(ctx context.Context) ([]models.PlatformAdmin, error)
+	ListTenants(ctx context.Context, filter models.PlatformTenantFilter, limit, offset int) ([]models.Tenant, int, error)
+	RevokeAdmin(ctx context.Context, userID uuid.UUID) error
+	SumUsage(ctx context.Context, from, to time.Time) (map[string]int64, error)
+}
+
+// PurchaseOrderStore is implemented by PurchaseOrderRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PurchaseOrderStore interface {
+	CheckInvoiceNumberExists(ctx context.Context, tenantID, supplierID uuid.UUID, invoiceNumber string) (bool, error)
+	Create(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error
+	CreateReceipt(ctx context.Context, tenantID uuid.UUID, receipt *models.GoodsReceipt) error
+	CreateSupplierInvoice(ctx context.Context, tenantID uuid.UUID, invoice *models.SupplierInvoice) error
+	Delete(ctx context.Context, tenantID, poID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	FindSupplierInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	List(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		supplierID *uuid.UUID,
+		status string,
+		limit, offset int,
+	) ([]models.PurchaseOrder, int, error)
+	ListPendingSupplierInvoices(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.SupplierInvoice, error)
+	ListReceipts(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error)
+	ListSupplierInvoices(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		poID *uuid.UUID,
+		matchStatus, status string,
+		limit, offset int,
+	) ([]models.SupplierInvoice, int, error)
+	Update(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error
+	UpdateStatus(ctx context.Context, tenantID, poID uuid.UUID, fromStatus, toStatus string) error
+	UpdateSupplierInvoiceStatus(ctx context.Context, tenantID, invoiceID, userID uuid.UUID, status string) error
+}
+
+// QuotaStore is implemented by QuotaRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type QuotaStore interface {
+	Acknowledge(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.QuotaAlert, error)
+	CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	FindAlert(ctx context.Context, tenantID, id uuid.UUID) (*models.QuotaAlert, error)
+	ListOpenAlerts(ctx context.Context, tenantID uuid.UUID) ([]models.QuotaAlert, error)
+	MarkNotified(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, alertIDs []uuid.UUID, notifiedAt time.Time) error
+	MeasureStorage(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	OpenAlert(ctx context.Context, alert *models.QuotaAlert) (bool, error)
+	ResolveAlerts(ctx context.Context, tenantID uuid.UUID, metric string, percent int) (int, error)
+}
+
+// RequestStatStore is implemented by RequestStatRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type RequestStatStore interface {
+	Add(ctx context.Context, stats []metrics.Stat) error
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	TopEndpoints(ctx context.Context, since time.Time, sort string, limit int) ([]models.EndpointStat, error)
+	TopQueries(ctx context.Context, since time.Time, sort string, limit int) ([]models.QueryStat, error)
+}
+
+// RoleStore is implemented by RoleRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type RoleStore interface {
+	AssignPermissions(ctx context.Context, tenantID, roleID uuid.UUID, permissionIDs, deniedPermissionIDs []uuid.UUID, assignedBy uuid.UUID) error
+	CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CountUsers(ctx context.Context, tenantID, roleID uuid.UUID) (int, error)
+	CountUsersByRole(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	Create(ctx context.Context, tenantID uuid.UUID, role *models.Role) error
+	Delete(ctx context.Context, tenantID, roleID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, roleID uuid.UUID) (*models.Role, error)
+	FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Role, error)
+	GetDescendantIDs(ctx context.Context, tenantID, roleID uuid.UUID) ([]uuid.UUID, error)
+	GetPermissions(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.Permission, error)
+	GetPermissionsForRoles(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID][]models.Permission, error)
+	GetRoleWithDetails(ctx context.Context, tenantID, roleID uuid.UUID) (*models.Role, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListCustomRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListSystemRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListWithDetails(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	Update(ctx context.Context, tenantID uuid.UUID, role *models.Role) error
+}
+
+// SIEMForwarderStore is implemented by SIEMForwarderRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SIEMForwarderStore interface {
+	ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID, dueBy time.Time) (*models.SIEMForwarder, error)
+	CountEventsBefore(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, cutoff time.Time) (int, error)
+	Create(ctx context.Context, forwarder *models.SIEMForwarder) error
+	Delete(ctx context.Context, tenantID, forwarderID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error)
+	ListEvents(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, until time.Time, limit int) ([]models.SIEMEvent, error)
+	SaveProgress(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder) error
+	Update(ctx context.Context, forwarder *models.SIEMForwarder) error
+}
+
+// SSOStore is implemented by SSORepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SSOStore interface {
+	CountEnabledProviders(ctx context.Context, tenantID uuid.UUID) (int, error)
+	CreateProvider(ctx context.Context, provider *models.SSOProvider) error
+	DeleteProvider(ctx context.Context, tenantID, id uuid.UUID) error
+	FindIdentity(ctx context.Context, tenantID, providerID uuid.UUID, subject string) (*models.SSOIdentity, error)
+	FindProvider(ctx context.Context, tenantID, id uuid.UUID) (*models.SSOProvider, error)
+	FindProviderBySlug(ctx context.Context, tenantID uuid.UUID, slug string) (*models.SSOProvider, error)
+	LinkIdentity(ctx context.Context, identity *models.SSOIdentity) error
+	ListProviders(ctx context.Context, tenantID uuid.UUID) ([]models.SSOProvider, error)
+	TouchIdentity(ctx context.Context, tenantID, identityID uuid.UUID, email string) error
+	UpdateProvider(ctx context.Context, provider *models.SSOProvider) error
+}
+
+// SalesStore is implemented by SalesRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SalesStore interface {
+	Create(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error
+	Delete(ctx context.Context, tenantID, docID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, docID uuid.UUID) (*models.SalesDocument, error)
+	FindBySource(ctx context.Context, tenantID, sourceID uuid.UUID) ([]models.SalesDocument, error)
+	List(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.SalesDocumentFilter,
+		limit, offset int,
+	) ([]models.SalesDocument, int, error)
+	Lock(ctx context.Context, tenantID, docID uuid.UUID) error
+	Update(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error
+	UpdateStatus(ctx context.Context, tenantID, docID uuid.UUID, fromStatus, toStatus string) error
+}
+
+// SandboxStore is implemented by SandboxRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SandboxStore interface {
+	Anonymize(ctx context.Context, tx *sqlx.Tx, tenantID, keepUserID uuid.UUID) error
+	ClaimNext(ctx context.Context) (*models.TenantSandbox, error)
+	CountActive(ctx context.Context, sourceTenantID uuid.UUID) (int, error)
+	Create(ctx context.Context, tenant *models.Tenant, sandbox *models.TenantSandbox) error
+	FindByID(ctx context.Context, sourceTenantID, id uuid.UUID) (*models.TenantSandbox, error)
+	List(ctx context.Context, sourceTenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error)
+	ListExpired(ctx context.Context, limit int) ([]models.TenantSandbox, error)
+	MarkDeleted(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error
+	MarkReady(ctx context.Context, sandbox *models.TenantSandbox) error
+}
+
+// ScheduledReportStore is implemented by ScheduledReportRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ScheduledReportStore interface {
+	ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ScheduledReport, error)
+	Create(ctx context.Context, report *models.ScheduledReport) error
+	Delete(ctx context.Context, tenantID, reportID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error)
+	ListActiveUsers(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportActiveUser, error)
+	ListAuditActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportAuditAction, error)
+	ListFailedLogins(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportFailedLogin, error)
+	ListLoginDays(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportLoginDay, error)
+	RecordRun(ctx context.Context, tenantID, reportID uuid.UUID, periodEnd time.Time, runErr error) error
+	Reschedule(ctx context.Context, tx *sqlx.Tx, report *models.ScheduledReport) error
+	Update(ctx context.Context, report *models.ScheduledReport) error
+}
+
+// SearchStore is implemented by SearchRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SearchStore interface {
+	SearchCustomers(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchDepartments(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchProducts(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchRoles(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchUsers(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+}
+
+// SessionStore is implemented by SessionRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SessionStore interface {
+	CountActiveSessions(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	Create(ctx context.Context, req *models.SessionCreateRequest) (*models.Session, []uuid.UUID, error)
+	Delete(ctx context.Context, tenantID, sessionID uuid.UUID) error
+	DeleteAllByUser(ctx context.Context, tenantID, userID uuid.UUID) error
+	DeleteByTokenHash(ctx context.Context, tenantID uuid.UUID, tokenHash string) error
+	DeleteExpiredSessions(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	DeleteInactiveSessions(ctx context.Context, tenantID uuid.UUID, inactivityDuration time.Duration) (int64, error)
+	FindByID(ctx context.Context, tenantID, sessionID uuid.UUID) (*models.Session, error)
+	FindByTokenHash(ctx context.Context, tenantID uuid.UUID, tokenHash string) (*models.Session, error)
+	GetSessionStats(ctx context.Context, tenantID uuid.UUID) (map[string]int, error)
+	ListActiveSessions(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error)
+	ListByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error)
+	UpdateActivity(ctx context.Context, tenantID, sessionID uuid.UUID) error
+	UpdateActivityBatch(ctx context.Context, tenantID uuid.UUID, activity map[uuid.UUID]time.Time) error
+}
+
+// SupplierStore is implemented by SupplierRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SupplierStore interface {
+	CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	Create(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error
+	Delete(ctx context.Context, tenantID, supplierID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error)
+	FindDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery, nameThreshold, emailThreshold float64, limit int) ([]models.SupplierDuplicate, error)
+	List(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error)
+	ListMerges(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error)
+	Merge(ctx context.Context, tenantID uuid.UUID, target *models.Supplier, merge *models.SupplierMerge) error
+	Update(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error
+}
+
+// TenantDomainStore is implemented by TenantDomainRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TenantDomainStore interface {
+	Create(ctx context.Context, domain *models.TenantDomain) error
+	Delete(ctx context.Context, tenantID, domainID uuid.UUID) error
+	FindByID(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	FindTenantIDByDomain(ctx context.Context, domain string) (uuid.UUID, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error)
+	RecordCheck(ctx context.Context, domain *models.TenantDomain, verified bool) error
+}
+
+// TenantStore is implemented by TenantRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TenantStore interface {
+	CancelDeletion(ctx context.Context, tenantID uuid.UUID) error
+	ChangeSlug(ctx context.Context, tenantID uuid.UUID, slug string, changedBy uuid.UUID, redirectUntil time.Time) (string, error)
+	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	CheckSlugAvailability(ctx context.Context, slug string) (bool, error)
+	CleanupExpiredSlugHistory(ctx context.Context) ([]string, error)
+	CleanupExpiredVerificationTokens(ctx context.Context) (int64, error)
+	ClearMaintenanceWindow(ctx context.Context, tenantID uuid.UUID) error
+	ConfirmDeletion(ctx context.Context, tenantID, token uuid.UUID, scheduledAt time.Time) error
+	Create(ctx context.Context, tenant *models.Tenant) error
+	Delete(ctx context.Context, tenantID uuid.UUID) error
+	FindByEmail(ctx context.Context, email string) (*models.Tenant, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	FindByStripeCustomer(ctx context.Context, customerID string) (*models.Tenant, error)
+	FindByVerificationToken(ctx context.Context, token uuid.UUID) (*models.Tenant, error)
+	FindTenantIDByFormerSlug(ctx context.Context, slug string) (uuid.UUID, error)
+	FindUsageAnalyticsOptOuts(ctx context.Context, tenantIDs []uuid.UUID) ([]uuid.UUID, error)
+	GetSettings(ctx context.Context, tenantID uuid.UUID) (json.RawMessage, string, error)
+	List(ctx context.Context, limit, offset int) ([]models.Tenant, int, error)
+	ListActive(ctx context.Context) ([]models.Tenant, error)
+	ListDueForDeletion(ctx context.Context, limit int) ([]uuid.UUID, error)
+	ProvisionSystemRoles(ctx context.Context, tenantID uuid.UUID) error
+	Reactivate(ctx context.Context, tenantID uuid.UUID) error
+	RegenerateVerificationToken(ctx context.Context, tenantID uuid.UUID, expiresIn time.Duration) (*uuid.UUID, error)
+	RequestDeletion(ctx context.Context, tenantID, requestedBy, token uuid.UUID, expiresAt time.Time) error
+	SetBrandingLogoURL(ctx context.Context, tenantID uuid.UUID, logoURL *string) error
+	SetMaintenanceWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) error
+	SetSSORequired(ctx context.Context, tenantID uuid.UUID, required bool) error
+	SetSessionLimit(ctx context.Context, tenantID uuid.UUID, limit *models.SessionLimit) error
+	SetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, policy *models.TwoFactorPolicy) error
+	SetUsageAnalyticsOptOut(ctx context.Context, tenantID uuid.UUID, optOut bool) error
+	Suspend(ctx context.Context, tenantID uuid.UUID, reason string) error
+	Update(ctx context.Context, tenant *models.Tenant) error
+	UpdateSettingsSections(ctx context.Context, tenantID uuid.UUID, sections map[string]json.RawMessage, version string) (string, error)
+	UpdateStatus(ctx context.Context, tenantID uuid.UUID, status string) error
+	UpdateSubscription(ctx context.Context, tenantID uuid.UUID, sub *models.TenantSubscription) error
+	VerifyEmail(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// TenantUsageStore is implemented by TenantUsageRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TenantUsageStore interface {
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	ListRange(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]models.TenantUsage, error)
+	Save(ctx context.Context, usage []models.TenantUsage) error
+	Sum(ctx context.Context, tenantID uuid.UUID, metric string, from, to time.Time) (int64, error)
+}
+
+// TimesheetStore is implemented by TimesheetRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TimesheetStore interface {
+	CheckProjectCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	CheckTaskNameExists(ctx context.Context, tenantID, projectID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error
+	CreateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error
+	CreateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error
+	DayHours(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID, day time.Time, excludeID *uuid.UUID) (float64, error)
+	DeleteEntry(ctx context.Context, tx *sqlx.Tx, tenantID, entryID uuid.UUID) error
+	DeleteProject(ctx context.Context, tenantID, projectID uuid.UUID) error
+	DeleteTask(ctx context.Context, tenantID, taskID uuid.UUID) error
+	FindEntryByID(ctx context.Context, tenantID, entryID uuid.UUID) (*models.TimesheetEntry, error)
+	FindProjectByID(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error)
+	FindTaskByID(ctx context.Context, tenantID, taskID uuid.UUID) (*models.TimesheetTask, error)
+	FindTimesheetByID(ctx context.Context, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	FindTimesheetByWeek(ctx context.Context, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error)
+	ListEntries(ctx context.Context, tenantID, timesheetID uuid.UUID) ([]*models.TimesheetEntry, error)
+	ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error)
+	ListTasks(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, activeOnly bool) ([]models.TimesheetTask, error)
+	ListTimesheets(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.Timesheet, int, error)
+	LockTimesheet(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	LockWeek(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error)
+	RefreshTotal(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error
+	Report(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter, byEmployee bool) ([]models.TimesheetReportRow, error)
+	UpdateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error
+	UpdateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error
+	UpdateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error
+	UpdateTimesheetStatus(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error
+}
+
+// UsageStatStore is implemented by UsageStatRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type UsageStatStore interface {
+	Add(ctx context.Context, stats []models.UsageStat) error
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+	DeleteTenant(ctx context.Context, tenantRef string) (int, error)
+	ListDay(ctx context.Context, day time.Time) ([]models.UsageStat, error)
+}
+
+// UserStore is implemented by UserRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type UserStore interface {
+	CheckEmailExists(ctx context.Context, tenantID uuid.UUID, email string) (bool, error)
+	ConfirmEmailChange(ctx context.Context, tenantID, userID uuid.UUID) error
+	CountByStatus(ctx context.Context, tenantID uuid.UUID, status string) (int, error)
+	Create(ctx context.Context, tenantID uuid.UUID, user *models.User) error
+	CreateImported(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, user *models.User, roleIDs []uuid.UUID, assignedBy uuid.UUID) error
+	Delete(ctx context.Context, tenantID, userID, deletedBy uuid.UUID) error
+	Disable2FA(ctx context.Context, tenantID, userID uuid.UUID) error
+	Enable2FA(ctx context.Context, tenantID, userID uuid.UUID, secret string, backupCodes []string) error
+	FindAllByEmail(ctx context.Context, email string) ([]models.User, error)
+	FindByEmail(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error)
+	FindByEmailChangeToken(ctx context.Context, tenantID, token uuid.UUID) (*models.User, error)
+	FindByID(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error)
+	FindByResetToken(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.User, error)
+	FindExistingEmails(ctx context.Context, tenantID uuid.UUID, emails []string) (map[string]bool, error)
+	GetPasswordHistory(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]string, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter models.UserFilter, page *pagination.Request) ([]models.User, int, string, error)
+	ListActiveIDs(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error)
+	ListDeleted(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.User, int, error)
+	ListWithoutSecondFactor(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]models.User, error)
+	MarkEmailUndeliverable(ctx context.Context, email, reason string) ([]models.User, error)
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error)
+	ReplacePasswordHash(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string) error
+	Restore(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error)
+	Search(ctx context.Context, tenantID uuid.UUID, searchTerm, config string, limit int) ([]models.User, error)
+	SetEmailChange(ctx context.Context, tenantID, userID uuid.UUID, pendingEmail string, token uuid.UUID, expiresAt time.Time) error
+	SetResetToken(ctx context.Context, tenantID, userID, token uuid.UUID, expiresAt string) error
+	StreamForExport(ctx context.Context, tenantID uuid.UUID, fn func(*models.UserExport) error) error
+	Update(ctx context.Context, tenantID uuid.UUID, user *models.User) error
+	UpdateLastLogin(ctx context.Context, tenantID, userID uuid.UUID, ipAddress string) error
+	UpdatePassword(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string, historyCount int) error
+	UpdateStatus(ctx context.Context, tenantID, userID uuid.UUID, status string) error
+	UseBackupCode(ctx context.Context, tenantID, userID uuid.UUID, remainingCodes []string) error
+	VerifyEmail(ctx context.Context, tenantID, userID uuid.UUID) error
+}
+
+// UserRoleStore is implemented by UserRoleRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type UserRoleStore interface {
+	AssignRole(ctx context.Context, tenantID, userID, roleID, assignedBy uuid.UUID) error
+	AssignRoles(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID, assignedBy uuid.UUID) error
+	BulkAssignRole(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID, assignedBy uuid.UUID) error
+	BulkUnassignRole(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID uuid.UUID) error
+	CountUsersByRole(ctx context.Context, tenantID, roleID uuid.UUID) (int, error)
+	DeleteExpired(ctx context.Context) ([]models.ExpiredRoleAssignment, error)
+	GetRoleAssignmentDetails(ctx context.Context, tenantID, userID uuid.UUID) ([]map[string]interface{}, error)
+	GetRolesForUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]models.Role, error)
+	GetUserRoles(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error)
+	GetUsersByRole(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.User, error)
+	GetUsersWithPermission(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]models.User, error)
+	GrantTemporaryRole(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) error
+	HasAnyRole(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) (bool, error)
+	HasRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) (bool, error)
+	NextAssignmentChange(ctx context.Context, tenantID, userID uuid.UUID) (*time.Time, error)
+	RevokeTemporaryRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+	UnassignAllRoles(ctx context.Context, tenantID, userID uuid.UUID) error
+	UnassignRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+}
+
+// WatchStore is implemented by WatchRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type WatchStore interface {
+	DeleteForEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error
+	ListByUser(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error)
+	ListWatchers(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]models.RecordWatcher, error)
+	Unwatch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	Watch(ctx context.Context, watch *models.RecordWatch) (bool, error)
+}
+
+// WebAuthnStore is implemented by WebAuthnRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type WebAuthnStore interface {
+	CountByUser(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	Create(ctx context.Context, credential *models.WebAuthnCredential) error
+	Delete(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error)
+	FindByCredentialID(ctx context.Context, tenantID uuid.UUID, credentialID []byte) (*models.WebAuthnCredential, error)
+	ListByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	RecordUse(ctx context.Context, tenantID, id uuid.UUID, previousCount, signCount int64) error
+	Rename(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error)
+	SetPasskeyOnly(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error
+}
+
+// WebhookStore is implemented by WebhookRepository. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type WebhookStore interface {
+	ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.ClaimedWebhookDelivery, error)
+	Create(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+	DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	EnqueueDelivery(ctx context.Context, webhook *models.Webhook, event *models.WebhookEvent, maxAttempts, maxPending int) (*models.WebhookDelivery, error)
+	EnqueueEvent(ctx context.Context, tenantID uuid.UUID, event *models.WebhookEvent, maxAttempts, maxPending int) (int, int, error)
+	FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error)
+	FindDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error)
+	ListDeliveries(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string, responseStatus int, responseBody string, duration time.Duration, nextAttemptAt time.Time) error
+	MarkDelivered(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, responseStatus int, responseBody string, duration time.Duration) error
+	MarkFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string) error
+	QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RetryDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error
+	Update(ctx context.Context, webhook *models.Webhook) error
+	UpdateSecret(ctx context.Context, tenantID, id uuid.UUID, secret string) error
+}
+
+var (
+	_ AccountStore          = (*AccountRepository)(nil)
+	_ AccountingPeriodStore = (*AccountingPeriodRepository)(nil)
+	_ AnnouncementStore     = (*AnnouncementRepository)(nil)
+	_ ApprovalLinkStore     = (*ApprovalLinkRepository)(nil)
+	_ AsyncJobStore         = (*AsyncJobRepository)(nil)
+	_ AutomationStore       = (*AutomationRepository)(nil)
+	_ BroadcastStore        = (*BroadcastRepository)(nil)
+	_ CRMStore              = (*CRMRepository)(nil)
+	_ CompanySettingsStore  = (*CompanySettingsRepository)(nil)
+	_ ComplianceStore       = (*ComplianceRepository)(nil)
+	_ CustomFieldStore      = (*CustomFieldRepository)(nil)
+	_ DashboardStore        = (*DashboardRepository)(nil)
+	_ DataQualityStore      = (*DataQualityRepository)(nil)
+	_ DeletionStore         = (*DeletionRepository)(nil)
+	_ DepartmentStore       = (*DepartmentRepository)(nil)
+	_ EmailOutboxStore      = (*EmailOutboxRepository)(nil)
+	_ EmployeeStore         = (*EmployeeRepository)(nil)
+	_ EscalationStore       = (*EscalationRepository)(nil)
+	_ FileStore             = (*FileRepository)(nil)
+	_ JournalEntryStore     = (*JournalEntryRepository)(nil)
+	_ LeaveStore            = (*LeaveRepository)(nil)
+	_ NavigationStore       = (*NavigationRepository)(nil)
+	_ NotificationStore     = (*NotificationRepository)(nil)
+	_ ObjectACLStore        = (*ObjectACLRepository)(nil)
+	_ PermissionStore       = (*PermissionRepository)(nil)
+	_ PersonalDataStore     = (*PersonalDataRepository)(nil)
+	_ PlatformStore         = (*PlatformRepository)(nil)
+	_ PurchaseOrderStore    = (*PurchaseOrderRepository)(nil)
+	_ QuotaStore            = (*QuotaRepository)(nil)
+	_ RequestStatStore      = (*RequestStatRepository)(nil)
+	_ RoleStore             = (*RoleRepository)(nil)
+	_ SIEMForwarderStore    = (*SIEMForwarderRepository)(nil)
+	_ SSOStore              = (*SSORepository)(nil)
+	_ SalesStore            = (*SalesRepository)(nil)
+	_ SandboxStore          = (*SandboxRepository)(nil)
+	_ ScheduledReportStore  = (*ScheduledReportRepository)(nil)
+	_ SearchStore           = (*SearchRepository)(nil)
+	_ SessionStore          = (*SessionRepository)(nil)
+	_ SupplierStore         = (*SupplierRepository)(nil)
+	_ TenantDomainStore     = (*TenantDomainRepository)(nil)
+	_ TenantStore           = (*TenantRepository)(nil)
+	_ TenantUsageStore      = (*TenantUsageRepository)(nil)
+	_ TimesheetStore        = (*TimesheetRepository)(nil)
+	_ UsageStatStore        = (*UsageStatRepository)(nil)
+	_ UserStore             = (*UserRepository)(nil)
+	_ UserRoleStore         = (*UserRoleRepository)(nil)
+	_ WatchStore            = (*WatchRepository)(nil)
+	_ WebAuthnStore         = (*WebAuthnRepository)(nil)
+	_ WebhookStore          = (*WebhookRepository)(nil)
+)
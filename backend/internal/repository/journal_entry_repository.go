@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// JournalEntryRepository handles database operations for journal entries and
+// ledger queries
+type JournalEntryRepository struct {
+	db *sqlx.DB
+}
+
+// NewJournalEntryRepository creates a new journal entry repository
+func NewJournalEntryRepository(db *sqlx.DB) *JournalEntryRepository {
+	return &JournalEntryRepository{db: db}
+}
+
+// Create creates a draft journal entry and its lines in a single transaction
+func (r *JournalEntryRepository) Create(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertJournalEntry(ctx, tx.Tx, tenantID, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a journal entry with its lines and account details
+func (r *JournalEntryRepository) FindByID(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var entry models.JournalEntry
+	// Explicit tenant_id filter for defense in depth
+	query := `SELECT * FROM journal_entries WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &entry, query, tenantID, entryID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("JOURNAL_ENTRY_NOT_FOUND", "journal entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find journal entry: %w", err)
+	}
+
+	lines := []models.JournalEntryLine{}
+	err = tx.SelectContext(ctx, &lines, `
+		SELECT l.*, a.code AS account_code, a.name AS account_name
+		FROM journal_entry_lines l
+		JOIN accounts a ON a.tenant_id = l.tenant_id AND a.id = l.account_id
+		WHERE l.tenant_id = $1 AND l.journal_entry_id = $2
+		ORDER BY l.line_number ASC
+	`, tenantID, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entry lines: %w", err)
+	}
+	entry.Lines = lines
+
+	return &entry, nil
+}
+
+// Lock locks a journal entry until the end of the unit of work ctx is part
+// of, so its lines cannot change while it is checked and posted. Outside a
+// unit of work the lock is released right away.
+func (r *JournalEntryRepository) Lock(ctx context.Context, tenantID, entryID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id uuid.UUID
+	err = tx.GetContext(ctx, &id, `SELECT id FROM journal_entries WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, entryID)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("JOURNAL_ENTRY_NOT_FOUND", "journal entry not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock journal entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// List retrieves journal entries (without lines) with filters and pagination
+func (r *JournalEntryRepository) List(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filter models.JournalEntryFilter,
+	limit, offset int,
+) ([]models.JournalEntry, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	argPos := 2
+
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, filter.Status)
+		argPos++
+	}
+	if filter.AccountID != nil {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM journal_entry_lines l
+			WHERE l.tenant_id = journal_entries.tenant_id AND l.journal_entry_id = journal_entries.id AND l.account_id = $%d
+		)`, argPos))
+		args = append(args, *filter.AccountID)
+		argPos++
+	}
+	if filter.FromDate != nil {
+		conditions = append(conditions, fmt.Sprintf("entry_date >= $%d", argPos))
+		args = append(args, *filter.FromDate)
+		argPos++
+	}
+	if filter.ToDate != nil {
+		conditions = append(conditions, fmt.Sprintf("entry_date <= $%d", argPos))
+		args = append(args, *filter.ToDate)
+		argPos++
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(entry_number ILIKE $%d OR description ILIKE $%d OR reference ILIKE $%d)", argPos, argPos, argPos))
+		args = append(args, "%"+filter.Search+"%")
+		argPos++
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM journal_entries WHERE ` + whereClause
+	if err := tx.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count journal entries: %w", err)
+	}
+
+	entries := []models.JournalEntry{}
+	query := fmt.Sprintf(`
+		SELECT * FROM journal_entries
+		WHERE %s
+		ORDER BY entry_date DESC, sequence_number DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	if err := tx.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list journal entries: %w", err)
+	}
+
+	return entries, totalCount, nil
+}
+
+// Update updates a draft journal entry and replaces its lines
+func (r *JournalEntryRepository) Update(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE journal_entries
+		SET entry_date = $1,
+			description = $2,
+			reference = $3,
+			total_debit = $4,
+			total_credit = $5,
+			updated_at = NOW()
+		WHERE tenant_id = $6 AND id = $7 AND status = 'draft'
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		entry.EntryDate,
+		entry.Description,
+		entry.Reference,
+		entry.TotalDebit,
+		entry.TotalCredit,
+		tenantID,
+		entry.ID,
+	).Scan(&entry.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("JOURNAL_ENTRY_NOT_DRAFT", "only draft journal entries can be edited")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update journal entry: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM journal_entry_lines WHERE tenant_id = $1 AND journal_entry_id = $2`, tenantID, entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear journal entry lines: %w", err)
+	}
+
+	if err := insertJournalLines(ctx, tx.Tx, tenantID, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a draft journal entry (lines cascade)
+func (r *JournalEntryRepository) Delete(ctx context.Context, tenantID, entryID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM journal_entries WHERE tenant_id = $1 AND id = $2 AND status = 'draft'`, tenantID, entryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete journal entry: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewBadRequestError("JOURNAL_ENTRY_NOT_DRAFT", "only draft journal entries can be deleted")
+	}
+
+	return tx.Commit()
+}
+
+// Post posts a balanced draft entry into an open period. The period row is
+// share-locked so it cannot be closed while the entry is being posted.
+func (r *JournalEntryRepository) Post(ctx context.Context, tenantID, entryID, periodID, userID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenPeriod(ctx, tx.Tx, tenantID, periodID); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE journal_entries
+		SET status = 'posted',
+			period_id = $1,
+			posted_at = NOW(),
+			posted_by = $2,
+			updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4 AND status = 'draft'
+	`
+
+	result, err := tx.ExecContext(ctx, query, periodID, userID, tenantID, entryID)
+	if err != nil {
+		return fmt.Errorf("failed to post journal entry: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("STATUS_CHANGED", "journal entry status has changed, please reload")
+	}
+
+	return tx.Commit()
+}
+
+// CreateReversal inserts an already-posted reversing entry and links it to the
+// original. An entry can only be reversed once.
+func (r *JournalEntryRepository) CreateReversal(ctx context.Context, tenantID, originalID uuid.UUID, reversal *models.JournalEntry) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if reversal.PeriodID == nil {
+		return utils.NewBadRequestError("NO_ACCOUNTING_PERIOD", "reversal requires a posting period")
+	}
+	if err := lockOpenPeriod(ctx, tx.Tx, tenantID, *reversal.PeriodID); err != nil {
+		return err
+	}
+
+	if err := insertJournalEntry(ctx, tx.Tx, tenantID, reversal); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE journal_entries
+		SET reversed_by_id = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND status = 'posted' AND reversed_by_id IS NULL
+	`, reversal.ID, tenantID, originalID)
+	if err != nil {
+		return fmt.Errorf("failed to link reversal: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("JOURNAL_ENTRY_ALREADY_REVERSED", "journal entry is already reversed")
+	}
+
+	return tx.Commit()
+}
+
+// TrialBalance sums posted lines per account for entries dated up to toDate
+// (and from fromDate, if given). Accounts without activity are omitted.
+func (r *JournalEntryRepository) TrialBalance(ctx context.Context, tenantID uuid.UUID, fromDate *time.Time, toDate time.Time) ([]models.TrialBalanceRow, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows := []models.TrialBalanceRow{}
+	query := `
+		SELECT
+			a.id AS account_id,
+			a.code,
+			a.name,
+			a.account_type,
+			SUM(l.debit) AS total_debit,
+			SUM(l.credit) AS total_credit,
+			SUM(l.debit) - SUM(l.credit) AS balance
+		FROM journal_entry_lines l
+		JOIN journal_entries e ON e.tenant_id = l.tenant_id AND e.id = l.journal_entry_id
+		JOIN accounts a ON a.tenant_id = l.tenant_id AND a.id = l.account_id
+		WHERE l.tenant_id = $1
+		  AND e.status = 'posted'
+		  AND e.entry_date <= $2
+		  AND ($3::date IS NULL OR e.entry_date >= $3)
+		GROUP BY a.id, a.code, a.name, a.account_type
+		ORDER BY a.code ASC
+	`
+
+	if err := tx.SelectContext(ctx, &rows, query, tenantID, toDate, fromDate); err != nil {
+		return nil, fmt.Errorf("failed to compute trial balance: %w", err)
+	}
+
+	return rows, nil
+}
+
+// lockOpenPeriod share-locks a period and fails unless it is open
+func lockOpenPeriod(ctx context.Context, tx *sqlx.Tx, tenantID, periodID uuid.UUID) error {
+	var status string
+	err := tx.GetContext(ctx, &status, `
+		SELECT status FROM accounting_periods
+		WHERE tenant_id = $1 AND id = $2
+		FOR SHARE
+	`, tenantID, periodID)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("ACCOUNTING_PERIOD_NOT_FOUND", "accounting period not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock accounting period: %w", err)
+	}
+	if status != models.PeriodStatusOpen {
+		return utils.NewBadRequestError("PERIOD_CLOSED", "accounting period is closed")
+	}
+	return nil
+}
+
+// insertJournalEntry allocates an entry number and inserts the entry and its
+// lines within an existing transaction
+func insertJournalEntry(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	seq, err := nextSequenceNumber(ctx, tx, tenantID, "journal_entries", `
+		SELECT COALESCE(MAX(sequence_number), 0) FROM journal_entries WHERE tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return err
+	}
+
+	entry.SequenceNumber = seq
+	entry.EntryNumber = fmt.Sprintf("JE-%06d", seq)
+
+	query := `
+		INSERT INTO journal_entries (
+			tenant_id, entry_number, sequence_number, entry_date, period_id, description, reference,
+			status, posted_at, posted_by, reversal_of_id, total_debit, total_credit, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		entry.EntryNumber,
+		entry.SequenceNumber,
+		entry.EntryDate,
+		entry.PeriodID,
+		entry.Description,
+		entry.Reference,
+		entry.Status,
+		entry.PostedAt,
+		entry.PostedBy,
+		entry.ReversalOfID,
+		entry.TotalDebit,
+		entry.TotalCredit,
+		entry.CreatedBy,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	entry.TenantID = tenantID
+
+	return insertJournalLines(ctx, tx, tenantID, entry)
+}
+
+// insertJournalLines inserts the entry's lines within an existing transaction
+func insertJournalLines(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	query := `
+		INSERT INTO journal_entry_lines (
+			tenant_id, journal_entry_id, line_number, account_id, description, debit, credit
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	for i := range entry.Lines {
+		line := &entry.Lines[i]
+		line.TenantID = tenantID
+		line.JournalEntryID = entry.ID
+		line.LineNumber = i + 1
+
+		err := tx.QueryRowContext(
+			ctx, query,
+			tenantID,
+			entry.ID,
+			line.LineNumber,
+			line.AccountID,
+			line.Description,
+			line.Debit,
+			line.Credit,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create journal entry line: %w", err)
+		}
+	}
+
+	return nil
+}
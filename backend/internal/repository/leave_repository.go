@@ -0,0 +1,536 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// leaveRequestSelect selects leave requests (lr) with the employee's name and
+// linked user, and the leave type's name and color
+const leaveRequestSelect = `
+	SELECT
+		lr.*,
+		e.first_name || ' ' || e.last_name AS employee_name,
+		e.user_id AS employee_user_id,
+		lt.name AS leave_type_name,
+		lt.color AS leave_type_color
+	FROM leave_requests lr
+	JOIN employees e ON e.tenant_id = lr.tenant_id AND e.id = lr.employee_id
+	JOIN leave_types lt ON lt.tenant_id = lr.tenant_id AND lt.id = lr.leave_type_id
+`
+
+// leaveBalanceSelect selects leave balances (b) with their leave type
+const leaveBalanceSelect = `
+	SELECT
+		b.*,
+		lt.code AS leave_type_code,
+		lt.name AS leave_type_name,
+		lt.tracks_balance
+	FROM leave_balances b
+	JOIN leave_types lt ON lt.tenant_id = b.tenant_id AND lt.id = b.leave_type_id
+`
+
+// LeaveRepository handles database operations for leave types, balances and
+// requests
+type LeaveRepository struct {
+	db *sqlx.DB
+}
+
+// NewLeaveRepository creates a new leave repository
+func NewLeaveRepository(db *sqlx.DB) *LeaveRepository {
+	return &LeaveRepository{db: db}
+}
+
+// CreateType creates a new leave type with RLS
+func (r *LeaveRepository) CreateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO leave_types (
+			tenant_id, code, name, description, color, paid, requires_approval,
+			tracks_balance, annual_allowance, max_carryover, is_active, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		leaveType.Code,
+		leaveType.Name,
+		leaveType.Description,
+		leaveType.Color,
+		leaveType.Paid,
+		leaveType.RequiresApproval,
+		leaveType.TracksBalance,
+		leaveType.AnnualAllowance,
+		leaveType.MaxCarryover,
+		leaveType.IsActive,
+		leaveType.CreatedBy,
+	).Scan(&leaveType.ID, &leaveType.CreatedAt, &leaveType.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create leave type: %w", err)
+	}
+
+	leaveType.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindTypeByID retrieves a leave type by ID with RLS
+func (r *LeaveRepository) FindTypeByID(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var leaveType models.LeaveType
+	query := `SELECT * FROM leave_types WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &leaveType, query, tenantID, typeID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("LEAVE_TYPE_NOT_FOUND", "leave type not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find leave type: %w", err)
+	}
+
+	return &leaveType, nil
+}
+
+// ListTypes retrieves a tenant's leave types, sorted by name
+func (r *LeaveRepository) ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	leaveTypes := []models.LeaveType{}
+	query := `SELECT * FROM leave_types WHERE tenant_id = $1 AND (NOT $2 OR is_active) ORDER BY name ASC`
+
+	if err := tx.SelectContext(ctx, &leaveTypes, query, tenantID, activeOnly); err != nil {
+		return nil, fmt.Errorf("failed to list leave types: %w", err)
+	}
+
+	return leaveTypes, nil
+}
+
+// UpdateType updates a leave type with RLS
+func (r *LeaveRepository) UpdateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE leave_types
+		SET code = $1, name = $2, description = $3, color = $4, paid = $5,
+			requires_approval = $6, tracks_balance = $7, annual_allowance = $8,
+			max_carryover = $9, is_active = $10
+		WHERE tenant_id = $11 AND id = $12
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		leaveType.Code,
+		leaveType.Name,
+		leaveType.Description,
+		leaveType.Color,
+		leaveType.Paid,
+		leaveType.RequiresApproval,
+		leaveType.TracksBalance,
+		leaveType.AnnualAllowance,
+		leaveType.MaxCarryover,
+		leaveType.IsActive,
+		tenantID,
+		leaveType.ID,
+	).Scan(&leaveType.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("LEAVE_TYPE_NOT_FOUND", "leave type not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update leave type: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteType deletes a leave type and its balances. Types with requests
+// cannot be deleted; deactivate them instead.
+func (r *LeaveRepository) DeleteType(ctx context.Context, tenantID, typeID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM leave_requests WHERE tenant_id = $1 AND leave_type_id = $2)`
+	if err := tx.GetContext(ctx, &inUse, query, tenantID, typeID); err != nil {
+		return fmt.Errorf("failed to check leave type usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("leave type is in use")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM leave_types WHERE tenant_id = $1 AND id = $2`, tenantID, typeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete leave type: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("LEAVE_TYPE_NOT_FOUND", "leave type not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckTypeCodeExists checks if a leave type code is already used in a tenant
+func (r *LeaveRepository) CheckTypeCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM leave_types WHERE tenant_id = $1 AND code = $2 AND ($3::uuid IS NULL OR id != $3))`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, code, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check leave type code: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindBalance retrieves an employee's balance of a leave type in a year
+// within tx. Returns nil if there is none.
+func (r *LeaveRepository) FindBalance(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID, typeID uuid.UUID, year int) (*models.LeaveBalance, error) {
+	var balance models.LeaveBalance
+	query := leaveBalanceSelect + ` WHERE b.tenant_id = $1 AND b.employee_id = $2 AND b.leave_type_id = $3 AND b.year = $4`
+
+	err := tx.GetContext(ctx, &balance, query, tenantID, employeeID, typeID, year)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find leave balance: %w", err)
+	}
+
+	return &balance, nil
+}
+
+// UpsertBalance sets the accrued and carried over days of a balance, creating
+// it if needed. The row stays locked until tx ends, so concurrent requests
+// against the same balance are serialized.
+func (r *LeaveRepository) UpsertBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance) error {
+	query := `
+		INSERT INTO leave_balances (tenant_id, employee_id, leave_type_id, year, accrued, carried_over)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, employee_id, leave_type_id, year) DO UPDATE SET
+			accrued = EXCLUDED.accrued,
+			carried_over = EXCLUDED.carried_over
+		RETURNING *
+	`
+
+	err := tx.QueryRowxContext(
+		ctx, query,
+		balance.TenantID,
+		balance.EmployeeID,
+		balance.LeaveTypeID,
+		balance.Year,
+		balance.Accrued,
+		balance.CarriedOver,
+	).StructScan(balance)
+	if err != nil {
+		return fmt.Errorf("failed to update leave balance: %w", err)
+	}
+
+	return nil
+}
+
+// AddToBalance adds days to the pending and used days of a balance within tx
+func (r *LeaveRepository) AddToBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, pending, used float64) error {
+	query := `
+		UPDATE leave_balances
+		SET pending = pending + $1, used = used + $2
+		WHERE tenant_id = $3 AND id = $4
+		RETURNING pending, used
+	`
+	if err := tx.QueryRowContext(ctx, query, pending, used, balance.TenantID, balance.ID).Scan(&balance.Pending, &balance.Used); err != nil {
+		return fmt.Errorf("failed to update leave balance: %w", err)
+	}
+	return nil
+}
+
+// SetAdjustment sets the manual adjustment of a balance within tx
+func (r *LeaveRepository) SetAdjustment(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, adjustment float64) error {
+	query := `UPDATE leave_balances SET adjustment = $1 WHERE tenant_id = $2 AND id = $3 RETURNING updated_at`
+	if err := tx.QueryRowContext(ctx, query, adjustment, balance.TenantID, balance.ID).Scan(&balance.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to adjust leave balance: %w", err)
+	}
+	balance.Adjustment = adjustment
+	return nil
+}
+
+// ListBalances retrieves an employee's balances in a year, sorted by leave
+// type name
+func (r *LeaveRepository) ListBalances(ctx context.Context, tenantID, employeeID uuid.UUID, year int) ([]models.LeaveBalance, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	balances := []models.LeaveBalance{}
+	query := leaveBalanceSelect + ` WHERE b.tenant_id = $1 AND b.employee_id = $2 AND b.year = $3 ORDER BY lt.name ASC`
+
+	if err := tx.SelectContext(ctx, &balances, query, tenantID, employeeID, year); err != nil {
+		return nil, fmt.Errorf("failed to list leave balances: %w", err)
+	}
+
+	return balances, nil
+}
+
+// CreateRequest creates a leave request within tx
+func (r *LeaveRepository) CreateRequest(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error {
+	query := `
+		INSERT INTO leave_requests (
+			tenant_id, employee_id, leave_type_id, start_date, end_date, half_day,
+			days, reason, status, approver_id, decided_by, decided_at, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		request.TenantID,
+		request.EmployeeID,
+		request.LeaveTypeID,
+		request.StartDate,
+		request.EndDate,
+		request.HalfDay,
+		request.Days,
+		request.Reason,
+		request.Status,
+		request.ApproverID,
+		request.DecidedBy,
+		request.DecidedAt,
+		request.RequestedBy,
+	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create leave request: %w", err)
+	}
+
+	return nil
+}
+
+// FindRequestByID retrieves a leave request with details by ID with RLS
+func (r *LeaveRepository) FindRequestByID(ctx context.Context, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var request models.LeaveRequest
+	query := leaveRequestSelect + ` WHERE lr.tenant_id = $1 AND lr.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &request, query, tenantID, requestID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("LEAVE_REQUEST_NOT_FOUND", "leave request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find leave request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// LockRequest retrieves a leave request with details and locks it until tx
+// ends
+func (r *LeaveRepository) LockRequest(ctx context.Context, tx *sqlx.Tx, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	query := leaveRequestSelect + ` WHERE lr.tenant_id = $1 AND lr.id = $2 FOR UPDATE OF lr`
+
+	err := tx.GetContext(ctx, &request, query, tenantID, requestID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("LEAVE_REQUEST_NOT_FOUND", "leave request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find leave request: %w", err)
+	}
+
+	return &request, nil
+}
+
+// UpdateRequestStatus records a request's status and decision within tx
+func (r *LeaveRepository) UpdateRequestStatus(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error {
+	query := `
+		UPDATE leave_requests
+		SET status = $1, decided_by = $2, decided_at = $3, decision_note = $4, cancelled_at = $5
+		WHERE tenant_id = $6 AND id = $7
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		request.Status,
+		request.DecidedBy,
+		request.DecidedAt,
+		request.DecisionNote,
+		request.CancelledAt,
+		request.TenantID,
+		request.ID,
+	).Scan(&request.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update leave request: %w", err)
+	}
+
+	return nil
+}
+
+// HasOverlap checks within tx whether an employee has pending or approved
+// leave overlapping a period
+func (r *LeaveRepository) HasOverlap(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, start, end time.Time) (bool, error) {
+	var overlaps bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM leave_requests
+			WHERE tenant_id = $1 AND employee_id = $2
+				AND status IN ('pending', 'approved')
+				AND start_date <= $4 AND end_date >= $3
+		)
+	`
+	if err := tx.GetContext(ctx, &overlaps, query, tenantID, employeeID, start, end); err != nil {
+		return false, fmt.Errorf("failed to check overlapping leave: %w", err)
+	}
+	return overlaps, nil
+}
+
+// ListRequests retrieves leave requests with filters and pagination, latest
+// start first. visibleTo, when set, restricts the list to the user's own
+// requests and those of the employees they manage or were asked to decide.
+func (r *LeaveRepository) ListRequests(ctx context.Context, tenantID uuid.UUID, filter models.LeaveRequestFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE lr.tenant_id = $1
+		AND ($2::uuid IS NULL OR lr.employee_id = $2)
+		AND ($3::uuid IS NULL OR e.department_id = $3)
+		AND ($4::uuid IS NULL OR lr.approver_id = $4
+			OR EXISTS(SELECT 1 FROM employees m WHERE m.tenant_id = e.tenant_id AND m.id = e.manager_id AND m.user_id = $4))
+		AND ($5 = '' OR lr.status = $5)
+		AND ($6::date IS NULL OR lr.end_date >= $6)
+		AND ($7::date IS NULL OR lr.start_date <= $7)
+		AND ($8::uuid IS NULL OR e.user_id = $8 OR lr.approver_id = $8
+			OR EXISTS(SELECT 1 FROM employees m WHERE m.tenant_id = e.tenant_id AND m.id = e.manager_id AND m.user_id = $8))
+	`
+	args := []interface{}{tenantID, filter.EmployeeID, filter.DepartmentID, filter.ApproverID, filter.Status, filter.From, filter.To, visibleTo}
+
+	from := `
+		FROM leave_requests lr
+		JOIN employees e ON e.tenant_id = lr.tenant_id AND e.id = lr.employee_id
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) `+from+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count leave requests: %w", err)
+	}
+
+	requests := []models.LeaveRequest{}
+	query := leaveRequestSelect + where + ` ORDER BY lr.start_date DESC, lr.created_at DESC LIMIT $9 OFFSET $10`
+
+	if err := tx.SelectContext(ctx, &requests, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list leave requests: %w", err)
+	}
+
+	return requests, totalCount, nil
+}
+
+// Calendar retrieves the approved (and optionally pending) leave overlapping
+// a period, sorted by start date
+func (r *LeaveRepository) Calendar(ctx context.Context, tenantID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The viewer's colleagues: same department, direct reports and themselves
+	query := `
+		SELECT
+			lr.id, lr.employee_id, e.first_name || ' ' || e.last_name AS employee_name,
+			e.department_id, lt.name AS leave_type_name, lt.color AS leave_type_color,
+			lr.start_date, lr.end_date, lr.half_day, lr.status
+		FROM leave_requests lr
+		JOIN employees e ON e.tenant_id = lr.tenant_id AND e.id = lr.employee_id
+		JOIN leave_types lt ON lt.tenant_id = lr.tenant_id AND lt.id = lr.leave_type_id
+		WHERE lr.tenant_id = $1
+			AND lr.start_date <= $3 AND lr.end_date >= $2
+			AND (lr.status = 'approved' OR ($4 AND lr.status = 'pending'))
+			AND ($5::uuid IS NULL OR e.department_id = $5)
+			AND ($6::uuid IS NULL OR EXISTS(
+				SELECT 1 FROM employees v
+				WHERE v.tenant_id = e.tenant_id AND v.user_id = $6
+					AND (v.id = e.id OR v.id = e.manager_id OR v.department_id = e.department_id)
+			))
+		ORDER BY lr.start_date ASC, employee_name ASC
+	`
+
+	entries := []models.LeaveCalendarEntry{}
+	err = tx.SelectContext(ctx, &entries, query, tenantID, filter.From, filter.To, filter.IncludePending, filter.DepartmentID, filter.VisibleTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load leave calendar: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ListAccrualTargets retrieves, across the tenants of db, each current
+// employee with each active balance-tracking leave type, ordered by tenant
+func (r *LeaveRepository) ListAccrualTargets(ctx context.Context, db *sqlx.DB) ([]models.LeaveAccrualTarget, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	targets := []models.LeaveAccrualTarget{}
+	query := `
+		SELECT
+			e.tenant_id, e.id AS employee_id, e.hire_date, e.termination_date,
+			lt.id AS leave_type_id, lt.annual_allowance, lt.max_carryover
+		FROM employees e
+		JOIN leave_types lt ON lt.tenant_id = e.tenant_id
+		WHERE e.status != $1 AND lt.is_active AND lt.tracks_balance
+		ORDER BY e.tenant_id, e.id, lt.id
+	`
+	if err := tx.SelectContext(ctx, &targets, query, models.EmployeeStatusTerminated); err != nil {
+		return nil, fmt.Errorf("failed to list leave accrual targets: %w", err)
+	}
+
+	return targets, nil
+}
@@ -0,0 +1,5077 @@
+// Code generated by genmocks. DO NOT EDIT.
+
+// Package mocks implements the interfaces of package repository with
+// function fields, for unit tests.
+package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/repository"
+)
+
+// AccountStore is a mock of repository.AccountStore
+type AccountStore struct {
+	CheckCodeExistsFunc func(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	CreateFunc          func(ctx context.Context, tenantID uuid.UUID, account *models.Account) error
+	DeleteFunc          func(ctx context.Context, tenantID, accountID uuid.UUID) error
+	FindByIDFunc        func(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error)
+	FindByIDsFunc       func(ctx context.Context, tenantID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]models.Account, error)
+	IsDescendantFunc    func(ctx context.Context, tenantID, accountID, candidateID uuid.UUID) (bool, error)
+	ListFunc            func(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error)
+	UpdateFunc          func(ctx context.Context, tenantID uuid.UUID, account *models.Account) error
+}
+
+// CheckCodeExists calls CheckCodeExistsFunc
+func (mock *AccountStore) CheckCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckCodeExistsFunc == nil {
+		panic("AccountStore.CheckCodeExists is not stubbed")
+	}
+	return mock.CheckCodeExistsFunc(ctx, tenantID, code, excludeID)
+}
+
+// Create calls CreateFunc
+func (mock *AccountStore) Create(ctx context.Context, tenantID uuid.UUID, account *models.Account) error {
+	if mock.CreateFunc == nil {
+		panic("AccountStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, account)
+}
+
+// Delete calls DeleteFunc
+func (mock *AccountStore) Delete(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("AccountStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, accountID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *AccountStore) FindByID(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID) (*models.Account, error) {
+	if mock.FindByIDFunc == nil {
+		panic("AccountStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, accountID)
+}
+
+// FindByIDs calls FindByIDsFunc
+func (mock *AccountStore) FindByIDs(ctx context.Context, tenantID uuid.UUID, accountIDs []uuid.UUID) (map[uuid.UUID]models.Account, error) {
+	if mock.FindByIDsFunc == nil {
+		panic("AccountStore.FindByIDs is not stubbed")
+	}
+	return mock.FindByIDsFunc(ctx, tenantID, accountIDs)
+}
+
+// IsDescendant calls IsDescendantFunc
+func (mock *AccountStore) IsDescendant(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID, candidateID uuid.UUID) (bool, error) {
+	if mock.IsDescendantFunc == nil {
+		panic("AccountStore.IsDescendant is not stubbed")
+	}
+	return mock.IsDescendantFunc(ctx, tenantID, accountID, candidateID)
+}
+
+// List calls ListFunc
+func (mock *AccountStore) List(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error) {
+	if mock.ListFunc == nil {
+		panic("AccountStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, accountType, activeOnly, search)
+}
+
+// Update calls UpdateFunc
+func (mock *AccountStore) Update(ctx context.Context, tenantID uuid.UUID, account *models.Account) error {
+	if mock.UpdateFunc == nil {
+		panic("AccountStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, account)
+}
+
+// AccountingPeriodStore is a mock of repository.AccountingPeriodStore
+type AccountingPeriodStore struct {
+	CountDraftEntriesFunc func(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) (int, error)
+	CreateFunc            func(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) error
+	FindByIDFunc          func(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	FindForDateFunc       func(ctx context.Context, tenantID uuid.UUID, date time.Time) (*models.AccountingPeriod, error)
+	ListFunc              func(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error)
+	UpdateStatusFunc      func(ctx context.Context, tenantID, periodID, userID uuid.UUID, fromStatus, toStatus string) error
+}
+
+// CountDraftEntries calls CountDraftEntriesFunc
+func (mock *AccountingPeriodStore) CountDraftEntries(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) (int, error) {
+	if mock.CountDraftEntriesFunc == nil {
+		panic("AccountingPeriodStore.CountDraftEntries is not stubbed")
+	}
+	return mock.CountDraftEntriesFunc(ctx, tenantID, period)
+}
+
+// Create calls CreateFunc
+func (mock *AccountingPeriodStore) Create(ctx context.Context, tenantID uuid.UUID, period *models.AccountingPeriod) error {
+	if mock.CreateFunc == nil {
+		panic("AccountingPeriodStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, period)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *AccountingPeriodStore) FindByID(ctx context.Context, tenantID uuid.UUID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	if mock.FindByIDFunc == nil {
+		panic("AccountingPeriodStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, periodID)
+}
+
+// FindForDate calls FindForDateFunc
+func (mock *AccountingPeriodStore) FindForDate(ctx context.Context, tenantID uuid.UUID, date time.Time) (*models.AccountingPeriod, error) {
+	if mock.FindForDateFunc == nil {
+		panic("AccountingPeriodStore.FindForDate is not stubbed")
+	}
+	return mock.FindForDateFunc(ctx, tenantID, date)
+}
+
+// List calls ListFunc
+func (mock *AccountingPeriodStore) List(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error) {
+	if mock.ListFunc == nil {
+		panic("AccountingPeriodStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, status)
+}
+
+// UpdateStatus calls UpdateStatusFunc
+func (mock *AccountingPeriodStore) UpdateStatus(ctx context.Context, tenantID uuid.UUID, periodID uuid.UUID, userID uuid.UUID, fromStatus string, toStatus string) error {
+	if mock.UpdateStatusFunc == nil {
+		panic("AccountingPeriodStore.UpdateStatus is not stubbed")
+	}
+	return mock.UpdateStatusFunc(ctx, tenantID, periodID, userID, fromStatus, toStatus)
+}
+
+// AnnouncementStore is a mock of repository.AnnouncementStore
+type AnnouncementStore struct {
+	ClaimPublishedFunc  func(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.Announcement, error)
+	CreateFunc          func(ctx context.Context, announcement *models.Announcement) error
+	DeleteFunc          func(ctx context.Context, tenantID, announcementID uuid.UUID) error
+	FindByIDFunc        func(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error)
+	ListFunc            func(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error)
+	ListAudienceIDsFunc func(ctx context.Context, tx *sqlx.Tx, announcement *models.Announcement) ([]uuid.UUID, error)
+	ListForUserFunc     func(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error)
+	ListReadsFunc       func(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error)
+	MarkReadFunc        func(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error
+	UpdateFunc          func(ctx context.Context, announcement *models.Announcement) error
+}
+
+// ClaimPublished calls ClaimPublishedFunc
+func (mock *AnnouncementStore) ClaimPublished(ctx context.Context, tx *sqlx.Tx, limit int) ([]models.Announcement, error) {
+	if mock.ClaimPublishedFunc == nil {
+		panic("AnnouncementStore.ClaimPublished is not stubbed")
+	}
+	return mock.ClaimPublishedFunc(ctx, tx, limit)
+}
+
+// Create calls CreateFunc
+func (mock *AnnouncementStore) Create(ctx context.Context, announcement *models.Announcement) error {
+	if mock.CreateFunc == nil {
+		panic("AnnouncementStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, announcement)
+}
+
+// Delete calls DeleteFunc
+func (mock *AnnouncementStore) Delete(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("AnnouncementStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, announcementID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *AnnouncementStore) FindByID(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID) (*models.Announcement, error) {
+	if mock.FindByIDFunc == nil {
+		panic("AnnouncementStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, announcementID)
+}
+
+// List calls ListFunc
+func (mock *AnnouncementStore) List(ctx context.Context, tenantID uuid.UUID, status string, limit int, offset int) ([]models.Announcement, int, error) {
+	if mock.ListFunc == nil {
+		panic("AnnouncementStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, status, limit, offset)
+}
+
+// ListAudienceIDs calls ListAudienceIDsFunc
+func (mock *AnnouncementStore) ListAudienceIDs(ctx context.Context, tx *sqlx.Tx, announcement *models.Announcement) ([]uuid.UUID, error) {
+	if mock.ListAudienceIDsFunc == nil {
+		panic("AnnouncementStore.ListAudienceIDs is not stubbed")
+	}
+	return mock.ListAudienceIDsFunc(ctx, tx, announcement)
+}
+
+// ListForUser calls ListForUserFunc
+func (mock *AnnouncementStore) ListForUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, unreadOnly bool, limit int, offset int) ([]models.UserAnnouncement, int, error) {
+	if mock.ListForUserFunc == nil {
+		panic("AnnouncementStore.ListForUser is not stubbed")
+	}
+	return mock.ListForUserFunc(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// ListReads calls ListReadsFunc
+func (mock *AnnouncementStore) ListReads(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID, limit int, offset int) ([]models.AnnouncementRead, int, error) {
+	if mock.ListReadsFunc == nil {
+		panic("AnnouncementStore.ListReads is not stubbed")
+	}
+	return mock.ListReadsFunc(ctx, tenantID, announcementID, limit, offset)
+}
+
+// MarkRead calls MarkReadFunc
+func (mock *AnnouncementStore) MarkRead(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, announcementID uuid.UUID) error {
+	if mock.MarkReadFunc == nil {
+		panic("AnnouncementStore.MarkRead is not stubbed")
+	}
+	return mock.MarkReadFunc(ctx, tenantID, userID, announcementID)
+}
+
+// Update calls UpdateFunc
+func (mock *AnnouncementStore) Update(ctx context.Context, announcement *models.Announcement) error {
+	if mock.UpdateFunc == nil {
+		panic("AnnouncementStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, announcement)
+}
+
+// ApprovalLinkStore is a mock of repository.ApprovalLinkStore
+type ApprovalLinkStore struct {
+	CreateFunc              func(ctx context.Context, link *models.ApprovalLink) error
+	DeleteExpiredBeforeFunc func(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FindByIDFunc            func(ctx context.Context, tenantID, id uuid.UUID) (*models.ApprovalLink, error)
+	LockFunc                func(ctx context.Context, tenantID, id uuid.UUID) (*database.Tx, *models.ApprovalLink, error)
+	MarkUsedFunc            func(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, decision, stepUp, ip, userAgent string) error
+	RecordFailedAttemptFunc func(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, maxAttempts int) (bool, error)
+	RevokeFunc              func(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink) error
+}
+
+// Create calls CreateFunc
+func (mock *ApprovalLinkStore) Create(ctx context.Context, link *models.ApprovalLink) error {
+	if mock.CreateFunc == nil {
+		panic("ApprovalLinkStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, link)
+}
+
+// DeleteExpiredBefore calls DeleteExpiredBeforeFunc
+func (mock *ApprovalLinkStore) DeleteExpiredBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	if mock.DeleteExpiredBeforeFunc == nil {
+		panic("ApprovalLinkStore.DeleteExpiredBefore is not stubbed")
+	}
+	return mock.DeleteExpiredBeforeFunc(ctx, db, cutoff)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *ApprovalLinkStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.ApprovalLink, error) {
+	if mock.FindByIDFunc == nil {
+		panic("ApprovalLinkStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// Lock calls LockFunc
+func (mock *ApprovalLinkStore) Lock(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*database.Tx, *models.ApprovalLink, error) {
+	if mock.LockFunc == nil {
+		panic("ApprovalLinkStore.Lock is not stubbed")
+	}
+	return mock.LockFunc(ctx, tenantID, id)
+}
+
+// MarkUsed calls MarkUsedFunc
+func (mock *ApprovalLinkStore) MarkUsed(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, decision string, stepUp string, ip string, userAgent string) error {
+	if mock.MarkUsedFunc == nil {
+		panic("ApprovalLinkStore.MarkUsed is not stubbed")
+	}
+	return mock.MarkUsedFunc(ctx, tx, link, decision, stepUp, ip, userAgent)
+}
+
+// RecordFailedAttempt calls RecordFailedAttemptFunc
+func (mock *ApprovalLinkStore) RecordFailedAttempt(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink, maxAttempts int) (bool, error) {
+	if mock.RecordFailedAttemptFunc == nil {
+		panic("ApprovalLinkStore.RecordFailedAttempt is not stubbed")
+	}
+	return mock.RecordFailedAttemptFunc(ctx, tx, link, maxAttempts)
+}
+
+// Revoke calls RevokeFunc
+func (mock *ApprovalLinkStore) Revoke(ctx context.Context, tx *sqlx.Tx, link *models.ApprovalLink) error {
+	if mock.RevokeFunc == nil {
+		panic("ApprovalLinkStore.Revoke is not stubbed")
+	}
+	return mock.RevokeFunc(ctx, tx, link)
+}
+
+// AsyncJobStore is a mock of repository.AsyncJobStore
+type AsyncJobStore struct {
+	ClaimNextFunc            func(ctx context.Context, db *sqlx.DB, paused []uuid.UUID) (*models.AsyncJob, error)
+	CreateFunc               func(ctx context.Context, tx *sqlx.Tx, job *models.AsyncJob, maxQueued int) error
+	DeleteFinishedBeforeFunc func(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FailInterruptedFunc      func(ctx context.Context, db *sqlx.DB) (int, error)
+	FindByIDFunc             func(ctx context.Context, tenantID, id uuid.UUID) (*models.AsyncJob, error)
+	FinishFunc               func(ctx context.Context, job *models.AsyncJob, status string, result json.RawMessage, cause string) error
+	IsCancelRequestedFunc    func(ctx context.Context, tenantID, id uuid.UUID) (bool, error)
+	ListByRequesterFunc      func(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error)
+	QueueStatsFunc           func(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RequestCancelFunc        func(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.AsyncJob, error)
+	UpdateProgressFunc       func(ctx context.Context, job *models.AsyncJob, progress int, message string) (bool, error)
+}
+
+// ClaimNext calls ClaimNextFunc
+func (mock *AsyncJobStore) ClaimNext(ctx context.Context, db *sqlx.DB, paused []uuid.UUID) (*models.AsyncJob, error) {
+	if mock.ClaimNextFunc == nil {
+		panic("AsyncJobStore.ClaimNext is not stubbed")
+	}
+	return mock.ClaimNextFunc(ctx, db, paused)
+}
+
+// Create calls CreateFunc
+func (mock *AsyncJobStore) Create(ctx context.Context, tx *sqlx.Tx, job *models.AsyncJob, maxQueued int) error {
+	if mock.CreateFunc == nil {
+		panic("AsyncJobStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tx, job, maxQueued)
+}
+
+// DeleteFinishedBefore calls DeleteFinishedBeforeFunc
+func (mock *AsyncJobStore) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	if mock.DeleteFinishedBeforeFunc == nil {
+		panic("AsyncJobStore.DeleteFinishedBefore is not stubbed")
+	}
+	return mock.DeleteFinishedBeforeFunc(ctx, db, cutoff)
+}
+
+// FailInterrupted calls FailInterruptedFunc
+func (mock *AsyncJobStore) FailInterrupted(ctx context.Context, db *sqlx.DB) (int, error) {
+	if mock.FailInterruptedFunc == nil {
+		panic("AsyncJobStore.FailInterrupted is not stubbed")
+	}
+	return mock.FailInterruptedFunc(ctx, db)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *AsyncJobStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.AsyncJob, error) {
+	if mock.FindByIDFunc == nil {
+		panic("AsyncJobStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// Finish calls FinishFunc
+func (mock *AsyncJobStore) Finish(ctx context.Context, job *models.AsyncJob, status string, result json.RawMessage, cause string) error {
+	if mock.FinishFunc == nil {
+		panic("AsyncJobStore.Finish is not stubbed")
+	}
+	return mock.FinishFunc(ctx, job, status, result, cause)
+}
+
+// IsCancelRequested calls IsCancelRequestedFunc
+func (mock *AsyncJobStore) IsCancelRequested(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (bool, error) {
+	if mock.IsCancelRequestedFunc == nil {
+		panic("AsyncJobStore.IsCancelRequested is not stubbed")
+	}
+	return mock.IsCancelRequestedFunc(ctx, tenantID, id)
+}
+
+// ListByRequester calls ListByRequesterFunc
+func (mock *AsyncJobStore) ListByRequester(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobType string, status string, limit int, offset int) ([]models.AsyncJob, int, error) {
+	if mock.ListByRequesterFunc == nil {
+		panic("AsyncJobStore.ListByRequester is not stubbed")
+	}
+	return mock.ListByRequesterFunc(ctx, tenantID, userID, jobType, status, limit, offset)
+}
+
+// QueueStats calls QueueStatsFunc
+func (mock *AsyncJobStore) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	if mock.QueueStatsFunc == nil {
+		panic("AsyncJobStore.QueueStats is not stubbed")
+	}
+	return mock.QueueStatsFunc(ctx, db)
+}
+
+// RequestCancel calls RequestCancelFunc
+func (mock *AsyncJobStore) RequestCancel(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, userID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.RequestCancelFunc == nil {
+		panic("AsyncJobStore.RequestCancel is not stubbed")
+	}
+	return mock.RequestCancelFunc(ctx, tenantID, id, userID)
+}
+
+// UpdateProgress calls UpdateProgressFunc
+func (mock *AsyncJobStore) UpdateProgress(ctx context.Context, job *models.AsyncJob, progress int, message string) (bool, error) {
+	if mock.UpdateProgressFunc == nil {
+		panic("AsyncJobStore.UpdateProgress is not stubbed")
+	}
+	return mock.UpdateProgressFunc(ctx, job, progress, message)
+}
+
+// AutomationStore is a mock of repository.AutomationStore
+type AutomationStore struct {
+	CheckNameExistsFunc      func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	ClaimPendingFunc         func(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ClaimedAutomationExecution, error)
+	CreateFunc               func(ctx context.Context, rule *models.AutomationRule) error
+	DeleteFunc               func(ctx context.Context, tenantID, id uuid.UUID) error
+	DeleteFinishedBeforeFunc func(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	EnqueueExecutionsFunc    func(ctx context.Context, tenantID uuid.UUID, ruleIDs []uuid.UUID, event *models.AutomationEvent) error
+	FindByIDFunc             func(ctx context.Context, tenantID, id uuid.UUID) (*models.AutomationRule, error)
+	FindEnabledByTriggerFunc func(ctx context.Context, tenantID uuid.UUID, eventType string) ([]models.AutomationRule, error)
+	FindExecutionFunc        func(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error)
+	FinishFunc               func(ctx context.Context, tx *sqlx.Tx, execution *models.ClaimedAutomationExecution, status string, results models.AutomationActionResults, startedAt time.Time) error
+	ListFunc                 func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error)
+	ListExecutionsFunc       func(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error)
+	SetEnabledFunc           func(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.AutomationRule, error)
+	UpdateFunc               func(ctx context.Context, rule *models.AutomationRule) error
+}
+
+// CheckNameExists calls CheckNameExistsFunc
+func (mock *AutomationStore) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNameExistsFunc == nil {
+		panic("AutomationStore.CheckNameExists is not stubbed")
+	}
+	return mock.CheckNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// ClaimPending calls ClaimPendingFunc
+func (mock *AutomationStore) ClaimPending(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ClaimedAutomationExecution, error) {
+	if mock.ClaimPendingFunc == nil {
+		panic("AutomationStore.ClaimPending is not stubbed")
+	}
+	return mock.ClaimPendingFunc(ctx, tx, paused)
+}
+
+// Create calls CreateFunc
+func (mock *AutomationStore) Create(ctx context.Context, rule *models.AutomationRule) error {
+	if mock.CreateFunc == nil {
+		panic("AutomationStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, rule)
+}
+
+// Delete calls DeleteFunc
+func (mock *AutomationStore) Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("AutomationStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, id)
+}
+
+// DeleteFinishedBefore calls DeleteFinishedBeforeFunc
+func (mock *AutomationStore) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	if mock.DeleteFinishedBeforeFunc == nil {
+		panic("AutomationStore.DeleteFinishedBefore is not stubbed")
+	}
+	return mock.DeleteFinishedBeforeFunc(ctx, db, cutoff)
+}
+
+// EnqueueExecutions calls EnqueueExecutionsFunc
+func (mock *AutomationStore) EnqueueExecutions(ctx context.Context, tenantID uuid.UUID, ruleIDs []uuid.UUID, event *models.AutomationEvent) error {
+	if mock.EnqueueExecutionsFunc == nil {
+		panic("AutomationStore.EnqueueExecutions is not stubbed")
+	}
+	return mock.EnqueueExecutionsFunc(ctx, tenantID, ruleIDs, event)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *AutomationStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.AutomationRule, error) {
+	if mock.FindByIDFunc == nil {
+		panic("AutomationStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// FindEnabledByTrigger calls FindEnabledByTriggerFunc
+func (mock *AutomationStore) FindEnabledByTrigger(ctx context.Context, tenantID uuid.UUID, eventType string) ([]models.AutomationRule, error) {
+	if mock.FindEnabledByTriggerFunc == nil {
+		panic("AutomationStore.FindEnabledByTrigger is not stubbed")
+	}
+	return mock.FindEnabledByTriggerFunc(ctx, tenantID, eventType)
+}
+
+// FindExecution calls FindExecutionFunc
+func (mock *AutomationStore) FindExecution(ctx context.Context, tenantID uuid.UUID, executionID uuid.UUID) (*models.AutomationExecution, error) {
+	if mock.FindExecutionFunc == nil {
+		panic("AutomationStore.FindExecution is not stubbed")
+	}
+	return mock.FindExecutionFunc(ctx, tenantID, executionID)
+}
+
+// Finish calls FinishFunc
+func (mock *AutomationStore) Finish(ctx context.Context, tx *sqlx.Tx, execution *models.ClaimedAutomationExecution, status string, results models.AutomationActionResults, startedAt time.Time) error {
+	if mock.FinishFunc == nil {
+		panic("AutomationStore.Finish is not stubbed")
+	}
+	return mock.FinishFunc(ctx, tx, execution, status, results, startedAt)
+}
+
+// List calls ListFunc
+func (mock *AutomationStore) List(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.AutomationRule, int, error) {
+	if mock.ListFunc == nil {
+		panic("AutomationStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, limit, offset)
+}
+
+// ListExecutions calls ListExecutionsFunc
+func (mock *AutomationStore) ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit int, offset int) ([]models.AutomationExecution, int, error) {
+	if mock.ListExecutionsFunc == nil {
+		panic("AutomationStore.ListExecutions is not stubbed")
+	}
+	return mock.ListExecutionsFunc(ctx, tenantID, ruleID, status, limit, offset)
+}
+
+// SetEnabled calls SetEnabledFunc
+func (mock *AutomationStore) SetEnabled(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, enabled bool) (*models.AutomationRule, error) {
+	if mock.SetEnabledFunc == nil {
+		panic("AutomationStore.SetEnabled is not stubbed")
+	}
+	return mock.SetEnabledFunc(ctx, tenantID, id, enabled)
+}
+
+// Update calls UpdateFunc
+func (mock *AutomationStore) Update(ctx context.Context, rule *models.AutomationRule) error {
+	if mock.UpdateFunc == nil {
+		panic("AutomationStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, rule)
+}
+
+// BroadcastStore is a mock of repository.BroadcastStore
+type BroadcastStore struct {
+	CancelFunc           func(ctx context.Context, tenantID, broadcastID uuid.UUID) error
+	ClaimPendingFunc     func(ctx context.Context, tx *sqlx.Tx, limit, maxOutboxPending int) ([]models.PendingBroadcastEmail, error)
+	CompleteFinishedFunc func(ctx context.Context, tx *sqlx.Tx) error
+	CreateFunc           func(ctx context.Context, broadcast *models.EmailBroadcast, filter *models.BroadcastFilter) error
+	FindByIDFunc         func(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	ListFunc             func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error)
+	ListRecipientsFunc   func(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error)
+	MarkNotQueuedFunc    func(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, status, reason string) error
+	MarkQueuedFunc       func(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, outboxEmailID uuid.UUID) error
+	OptOutFunc           func(ctx context.Context, tenantID, token uuid.UUID) (uuid.UUID, error)
+	StatsFunc            func(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.BroadcastStats, error)
+}
+
+// Cancel calls CancelFunc
+func (mock *BroadcastStore) Cancel(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID) error {
+	if mock.CancelFunc == nil {
+		panic("BroadcastStore.Cancel is not stubbed")
+	}
+	return mock.CancelFunc(ctx, tenantID, broadcastID)
+}
+
+// ClaimPending calls ClaimPendingFunc
+func (mock *BroadcastStore) ClaimPending(ctx context.Context, tx *sqlx.Tx, limit int, maxOutboxPending int) ([]models.PendingBroadcastEmail, error) {
+	if mock.ClaimPendingFunc == nil {
+		panic("BroadcastStore.ClaimPending is not stubbed")
+	}
+	return mock.ClaimPendingFunc(ctx, tx, limit, maxOutboxPending)
+}
+
+// CompleteFinished calls CompleteFinishedFunc
+func (mock *BroadcastStore) CompleteFinished(ctx context.Context, tx *sqlx.Tx) error {
+	if mock.CompleteFinishedFunc == nil {
+		panic("BroadcastStore.CompleteFinished is not stubbed")
+	}
+	return mock.CompleteFinishedFunc(ctx, tx)
+}
+
+// Create calls CreateFunc
+func (mock *BroadcastStore) Create(ctx context.Context, broadcast *models.EmailBroadcast, filter *models.BroadcastFilter) error {
+	if mock.CreateFunc == nil {
+		panic("BroadcastStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, broadcast, filter)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *BroadcastStore) FindByID(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	if mock.FindByIDFunc == nil {
+		panic("BroadcastStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, broadcastID)
+}
+
+// List calls ListFunc
+func (mock *BroadcastStore) List(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.EmailBroadcast, int, error) {
+	if mock.ListFunc == nil {
+		panic("BroadcastStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, limit, offset)
+}
+
+// ListRecipients calls ListRecipientsFunc
+func (mock *BroadcastStore) ListRecipients(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID, deliveryStatus string, limit int, offset int) ([]models.BroadcastRecipient, int, error) {
+	if mock.ListRecipientsFunc == nil {
+		panic("BroadcastStore.ListRecipients is not stubbed")
+	}
+	return mock.ListRecipientsFunc(ctx, tenantID, broadcastID, deliveryStatus, limit, offset)
+}
+
+// MarkNotQueued calls MarkNotQueuedFunc
+func (mock *BroadcastStore) MarkNotQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, status string, reason string) error {
+	if mock.MarkNotQueuedFunc == nil {
+		panic("BroadcastStore.MarkNotQueued is not stubbed")
+	}
+	return mock.MarkNotQueuedFunc(ctx, tx, recipient, status, reason)
+}
+
+// MarkQueued calls MarkQueuedFunc
+func (mock *BroadcastStore) MarkQueued(ctx context.Context, tx *sqlx.Tx, recipient *models.BroadcastRecipient, outboxEmailID uuid.UUID) error {
+	if mock.MarkQueuedFunc == nil {
+		panic("BroadcastStore.MarkQueued is not stubbed")
+	}
+	return mock.MarkQueuedFunc(ctx, tx, recipient, outboxEmailID)
+}
+
+// OptOut calls OptOutFunc
+func (mock *BroadcastStore) OptOut(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (uuid.UUID, error) {
+	if mock.OptOutFunc == nil {
+		panic("BroadcastStore.OptOut is not stubbed")
+	}
+	return mock.OptOutFunc(ctx, tenantID, token)
+}
+
+// Stats calls StatsFunc
+func (mock *BroadcastStore) Stats(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID) (*models.BroadcastStats, error) {
+	if mock.StatsFunc == nil {
+		panic("BroadcastStore.Stats is not stubbed")
+	}
+	return mock.StatsFunc(ctx, tenantID, broadcastID)
+}
+
+// CRMStore is a mock of repository.CRMStore
+type CRMStore struct {
+	AssignOwnerFunc             func(ctx context.Context, tenantID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID, activity *models.CRMActivity) error
+	CheckPipelineNameExistsFunc func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateActivityFunc          func(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error
+	CreateContactFunc           func(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error
+	CreateCustomerFunc          func(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error
+	CreateOpportunityFunc       func(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity) error
+	CreatePipelineFunc          func(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error
+	CreateStageFunc             func(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error
+	DeleteActivityFunc          func(ctx context.Context, tenantID, activityID uuid.UUID) error
+	DeleteContactFunc           func(ctx context.Context, tenantID, contactID uuid.UUID) error
+	DeleteCustomerFunc          func(ctx context.Context, tenantID, customerID uuid.UUID) error
+	DeleteOpportunityFunc       func(ctx context.Context, tenantID, opportunityID uuid.UUID) error
+	DeletePipelineFunc          func(ctx context.Context, tenantID, pipelineID uuid.UUID) error
+	DeleteStageFunc             func(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error
+	FindActivityByIDFunc        func(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error)
+	FindContactByIDFunc         func(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error)
+	FindCustomerByIDFunc        func(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error)
+	FindOpportunityByIDFunc     func(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error)
+	FindPipelineByIDFunc        func(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error)
+	FindStageByIDFunc           func(ctx context.Context, tenantID, stageID uuid.UUID) (*models.CRMPipelineStage, error)
+	ListActivitiesFunc          func(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error)
+	ListContactsFunc            func(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error)
+	ListCustomersFunc           func(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error)
+	ListOpportunitiesFunc       func(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error)
+	ListPipelinesFunc           func(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error)
+	PipelineSummaryFunc         func(ctx context.Context, tenantID, pipelineID uuid.UUID) ([]models.CRMStageSummary, error)
+	UpdateActivityFunc          func(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error
+	UpdateContactFunc           func(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error
+	UpdateCustomerFunc          func(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error
+	UpdateOpportunityFunc       func(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity, activity *models.CRMActivity) error
+	UpdatePipelineFunc          func(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error
+	UpdateStageFunc             func(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage, oldPosition int) error
+}
+
+// AssignOwner calls AssignOwnerFunc
+func (mock *CRMStore) AssignOwner(ctx context.Context, tenantID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID, activity *models.CRMActivity) error {
+	if mock.AssignOwnerFunc == nil {
+		panic("CRMStore.AssignOwner is not stubbed")
+	}
+	return mock.AssignOwnerFunc(ctx, tenantID, entity, entityID, ownerID, activity)
+}
+
+// CheckPipelineNameExists calls CheckPipelineNameExistsFunc
+func (mock *CRMStore) CheckPipelineNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckPipelineNameExistsFunc == nil {
+		panic("CRMStore.CheckPipelineNameExists is not stubbed")
+	}
+	return mock.CheckPipelineNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// CreateActivity calls CreateActivityFunc
+func (mock *CRMStore) CreateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error {
+	if mock.CreateActivityFunc == nil {
+		panic("CRMStore.CreateActivity is not stubbed")
+	}
+	return mock.CreateActivityFunc(ctx, tenantID, activity)
+}
+
+// CreateContact calls CreateContactFunc
+func (mock *CRMStore) CreateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error {
+	if mock.CreateContactFunc == nil {
+		panic("CRMStore.CreateContact is not stubbed")
+	}
+	return mock.CreateContactFunc(ctx, tenantID, contact)
+}
+
+// CreateCustomer calls CreateCustomerFunc
+func (mock *CRMStore) CreateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error {
+	if mock.CreateCustomerFunc == nil {
+		panic("CRMStore.CreateCustomer is not stubbed")
+	}
+	return mock.CreateCustomerFunc(ctx, tenantID, customer)
+}
+
+// CreateOpportunity calls CreateOpportunityFunc
+func (mock *CRMStore) CreateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity) error {
+	if mock.CreateOpportunityFunc == nil {
+		panic("CRMStore.CreateOpportunity is not stubbed")
+	}
+	return mock.CreateOpportunityFunc(ctx, tenantID, opportunity)
+}
+
+// CreatePipeline calls CreatePipelineFunc
+func (mock *CRMStore) CreatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error {
+	if mock.CreatePipelineFunc == nil {
+		panic("CRMStore.CreatePipeline is not stubbed")
+	}
+	return mock.CreatePipelineFunc(ctx, tenantID, pipeline)
+}
+
+// CreateStage calls CreateStageFunc
+func (mock *CRMStore) CreateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error {
+	if mock.CreateStageFunc == nil {
+		panic("CRMStore.CreateStage is not stubbed")
+	}
+	return mock.CreateStageFunc(ctx, tenantID, stage)
+}
+
+// DeleteActivity calls DeleteActivityFunc
+func (mock *CRMStore) DeleteActivity(ctx context.Context, tenantID uuid.UUID, activityID uuid.UUID) error {
+	if mock.DeleteActivityFunc == nil {
+		panic("CRMStore.DeleteActivity is not stubbed")
+	}
+	return mock.DeleteActivityFunc(ctx, tenantID, activityID)
+}
+
+// DeleteContact calls DeleteContactFunc
+func (mock *CRMStore) DeleteContact(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID) error {
+	if mock.DeleteContactFunc == nil {
+		panic("CRMStore.DeleteContact is not stubbed")
+	}
+	return mock.DeleteContactFunc(ctx, tenantID, contactID)
+}
+
+// DeleteCustomer calls DeleteCustomerFunc
+func (mock *CRMStore) DeleteCustomer(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID) error {
+	if mock.DeleteCustomerFunc == nil {
+		panic("CRMStore.DeleteCustomer is not stubbed")
+	}
+	return mock.DeleteCustomerFunc(ctx, tenantID, customerID)
+}
+
+// DeleteOpportunity calls DeleteOpportunityFunc
+func (mock *CRMStore) DeleteOpportunity(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID) error {
+	if mock.DeleteOpportunityFunc == nil {
+		panic("CRMStore.DeleteOpportunity is not stubbed")
+	}
+	return mock.DeleteOpportunityFunc(ctx, tenantID, opportunityID)
+}
+
+// DeletePipeline calls DeletePipelineFunc
+func (mock *CRMStore) DeletePipeline(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) error {
+	if mock.DeletePipelineFunc == nil {
+		panic("CRMStore.DeletePipeline is not stubbed")
+	}
+	return mock.DeletePipelineFunc(ctx, tenantID, pipelineID)
+}
+
+// DeleteStage calls DeleteStageFunc
+func (mock *CRMStore) DeleteStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage) error {
+	if mock.DeleteStageFunc == nil {
+		panic("CRMStore.DeleteStage is not stubbed")
+	}
+	return mock.DeleteStageFunc(ctx, tenantID, stage)
+}
+
+// FindActivityByID calls FindActivityByIDFunc
+func (mock *CRMStore) FindActivityByID(ctx context.Context, tenantID uuid.UUID, activityID uuid.UUID) (*models.CRMActivity, error) {
+	if mock.FindActivityByIDFunc == nil {
+		panic("CRMStore.FindActivityByID is not stubbed")
+	}
+	return mock.FindActivityByIDFunc(ctx, tenantID, activityID)
+}
+
+// FindContactByID calls FindContactByIDFunc
+func (mock *CRMStore) FindContactByID(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID) (*models.CRMContact, error) {
+	if mock.FindContactByIDFunc == nil {
+		panic("CRMStore.FindContactByID is not stubbed")
+	}
+	return mock.FindContactByIDFunc(ctx, tenantID, contactID)
+}
+
+// FindCustomerByID calls FindCustomerByIDFunc
+func (mock *CRMStore) FindCustomerByID(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID) (*models.CRMCustomer, error) {
+	if mock.FindCustomerByIDFunc == nil {
+		panic("CRMStore.FindCustomerByID is not stubbed")
+	}
+	return mock.FindCustomerByIDFunc(ctx, tenantID, customerID)
+}
+
+// FindOpportunityByID calls FindOpportunityByIDFunc
+func (mock *CRMStore) FindOpportunityByID(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID) (*models.CRMOpportunity, error) {
+	if mock.FindOpportunityByIDFunc == nil {
+		panic("CRMStore.FindOpportunityByID is not stubbed")
+	}
+	return mock.FindOpportunityByIDFunc(ctx, tenantID, opportunityID)
+}
+
+// FindPipelineByID calls FindPipelineByIDFunc
+func (mock *CRMStore) FindPipelineByID(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) (*models.CRMPipeline, error) {
+	if mock.FindPipelineByIDFunc == nil {
+		panic("CRMStore.FindPipelineByID is not stubbed")
+	}
+	return mock.FindPipelineByIDFunc(ctx, tenantID, pipelineID)
+}
+
+// FindStageByID calls FindStageByIDFunc
+func (mock *CRMStore) FindStageByID(ctx context.Context, tenantID uuid.UUID, stageID uuid.UUID) (*models.CRMPipelineStage, error) {
+	if mock.FindStageByIDFunc == nil {
+		panic("CRMStore.FindStageByID is not stubbed")
+	}
+	return mock.FindStageByIDFunc(ctx, tenantID, stageID)
+}
+
+// ListActivities calls ListActivitiesFunc
+func (mock *CRMStore) ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit int, offset int) ([]models.CRMActivity, int, error) {
+	if mock.ListActivitiesFunc == nil {
+		panic("CRMStore.ListActivities is not stubbed")
+	}
+	return mock.ListActivitiesFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListContacts calls ListContactsFunc
+func (mock *CRMStore) ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit int, offset int) ([]models.CRMContact, int, error) {
+	if mock.ListContactsFunc == nil {
+		panic("CRMStore.ListContacts is not stubbed")
+	}
+	return mock.ListContactsFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListCustomers calls ListCustomersFunc
+func (mock *CRMStore) ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit int, offset int) ([]models.CRMCustomer, int, error) {
+	if mock.ListCustomersFunc == nil {
+		panic("CRMStore.ListCustomers is not stubbed")
+	}
+	return mock.ListCustomersFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListOpportunities calls ListOpportunitiesFunc
+func (mock *CRMStore) ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit int, offset int) ([]models.CRMOpportunity, int, error) {
+	if mock.ListOpportunitiesFunc == nil {
+		panic("CRMStore.ListOpportunities is not stubbed")
+	}
+	return mock.ListOpportunitiesFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListPipelines calls ListPipelinesFunc
+func (mock *CRMStore) ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error) {
+	if mock.ListPipelinesFunc == nil {
+		panic("CRMStore.ListPipelines is not stubbed")
+	}
+	return mock.ListPipelinesFunc(ctx, tenantID)
+}
+
+// PipelineSummary calls PipelineSummaryFunc
+func (mock *CRMStore) PipelineSummary(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) ([]models.CRMStageSummary, error) {
+	if mock.PipelineSummaryFunc == nil {
+		panic("CRMStore.PipelineSummary is not stubbed")
+	}
+	return mock.PipelineSummaryFunc(ctx, tenantID, pipelineID)
+}
+
+// UpdateActivity calls UpdateActivityFunc
+func (mock *CRMStore) UpdateActivity(ctx context.Context, tenantID uuid.UUID, activity *models.CRMActivity) error {
+	if mock.UpdateActivityFunc == nil {
+		panic("CRMStore.UpdateActivity is not stubbed")
+	}
+	return mock.UpdateActivityFunc(ctx, tenantID, activity)
+}
+
+// UpdateContact calls UpdateContactFunc
+func (mock *CRMStore) UpdateContact(ctx context.Context, tenantID uuid.UUID, contact *models.CRMContact) error {
+	if mock.UpdateContactFunc == nil {
+		panic("CRMStore.UpdateContact is not stubbed")
+	}
+	return mock.UpdateContactFunc(ctx, tenantID, contact)
+}
+
+// UpdateCustomer calls UpdateCustomerFunc
+func (mock *CRMStore) UpdateCustomer(ctx context.Context, tenantID uuid.UUID, customer *models.CRMCustomer) error {
+	if mock.UpdateCustomerFunc == nil {
+		panic("CRMStore.UpdateCustomer is not stubbed")
+	}
+	return mock.UpdateCustomerFunc(ctx, tenantID, customer)
+}
+
+// UpdateOpportunity calls UpdateOpportunityFunc
+func (mock *CRMStore) UpdateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunity *models.CRMOpportunity, activity *models.CRMActivity) error {
+	if mock.UpdateOpportunityFunc == nil {
+		panic("CRMStore.UpdateOpportunity is not stubbed")
+	}
+	return mock.UpdateOpportunityFunc(ctx, tenantID, opportunity, activity)
+}
+
+// UpdatePipeline calls UpdatePipelineFunc
+func (mock *CRMStore) UpdatePipeline(ctx context.Context, tenantID uuid.UUID, pipeline *models.CRMPipeline) error {
+	if mock.UpdatePipelineFunc == nil {
+		panic("CRMStore.UpdatePipeline is not stubbed")
+	}
+	return mock.UpdatePipelineFunc(ctx, tenantID, pipeline)
+}
+
+// UpdateStage calls UpdateStageFunc
+func (mock *CRMStore) UpdateStage(ctx context.Context, tenantID uuid.UUID, stage *models.CRMPipelineStage, oldPosition int) error {
+	if mock.UpdateStageFunc == nil {
+		panic("CRMStore.UpdateStage is not stubbed")
+	}
+	return mock.UpdateStageFunc(ctx, tenantID, stage, oldPosition)
+}
+
+// CompanySettingsStore is a mock of repository.CompanySettingsStore
+type CompanySettingsStore struct {
+	CreateFunc        func(ctx context.Context, tenantID uuid.UUID, settings *models.CompanySettings) error
+	GetByTenantIDFunc func(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error)
+	UpdateFunc        func(ctx context.Context, tenantID uuid.UUID, updates map[string]interface{}, updatedBy uuid.UUID) (*models.CompanySettings, error)
+}
+
+// Create calls CreateFunc
+func (mock *CompanySettingsStore) Create(ctx context.Context, tenantID uuid.UUID, settings *models.CompanySettings) error {
+	if mock.CreateFunc == nil {
+		panic("CompanySettingsStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, settings)
+}
+
+// GetByTenantID calls GetByTenantIDFunc
+func (mock *CompanySettingsStore) GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error) {
+	if mock.GetByTenantIDFunc == nil {
+		panic("CompanySettingsStore.GetByTenantID is not stubbed")
+	}
+	return mock.GetByTenantIDFunc(ctx, tenantID)
+}
+
+// Update calls UpdateFunc
+func (mock *CompanySettingsStore) Update(ctx context.Context, tenantID uuid.UUID, updates map[string]interface{}, updatedBy uuid.UUID) (*models.CompanySettings, error) {
+	if mock.UpdateFunc == nil {
+		panic("CompanySettingsStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, updates, updatedBy)
+}
+
+// ComplianceStore is a mock of repository.ComplianceStore
+type ComplianceStore struct {
+	ListUserAccessFunc     func(ctx context.Context, tenantID uuid.UUID, privilegedResources []string) ([]models.ComplianceUserAccess, error)
+	StreamActionsFunc      func(ctx context.Context, tenantID uuid.UUID, start, end time.Time, actions []string, fn func(*models.AuditLog) error) error
+	StreamAdminActionsFunc func(ctx context.Context, tenantID uuid.UUID, start, end time.Time, resourceTypes, excludedActions []string, fn func(*models.AuditLog) error) error
+}
+
+// ListUserAccess calls ListUserAccessFunc
+func (mock *ComplianceStore) ListUserAccess(ctx context.Context, tenantID uuid.UUID, privilegedResources []string) ([]models.ComplianceUserAccess, error) {
+	if mock.ListUserAccessFunc == nil {
+		panic("ComplianceStore.ListUserAccess is not stubbed")
+	}
+	return mock.ListUserAccessFunc(ctx, tenantID, privilegedResources)
+}
+
+// StreamActions calls StreamActionsFunc
+func (mock *ComplianceStore) StreamActions(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time, actions []string, fn func(*models.AuditLog) error) error {
+	if mock.StreamActionsFunc == nil {
+		panic("ComplianceStore.StreamActions is not stubbed")
+	}
+	return mock.StreamActionsFunc(ctx, tenantID, start, end, actions, fn)
+}
+
+// StreamAdminActions calls StreamAdminActionsFunc
+func (mock *ComplianceStore) StreamAdminActions(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time, resourceTypes []string, excludedActions []string, fn func(*models.AuditLog) error) error {
+	if mock.StreamAdminActionsFunc == nil {
+		panic("ComplianceStore.StreamAdminActions is not stubbed")
+	}
+	return mock.StreamAdminActionsFunc(ctx, tenantID, start, end, resourceTypes, excludedActions, fn)
+}
+
+// CustomFieldStore is a mock of repository.CustomFieldStore
+type CustomFieldStore struct {
+	CreateFunc   func(ctx context.Context, field *models.CustomField) error
+	DeleteFunc   func(ctx context.Context, tenantID, fieldID uuid.UUID) error
+	FindByIDFunc func(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error)
+	ListFunc     func(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error)
+	UpdateFunc   func(ctx context.Context, field *models.CustomField) error
+}
+
+// Create calls CreateFunc
+func (mock *CustomFieldStore) Create(ctx context.Context, field *models.CustomField) error {
+	if mock.CreateFunc == nil {
+		panic("CustomFieldStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, field)
+}
+
+// Delete calls DeleteFunc
+func (mock *CustomFieldStore) Delete(ctx context.Context, tenantID uuid.UUID, fieldID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("CustomFieldStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, fieldID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *CustomFieldStore) FindByID(ctx context.Context, tenantID uuid.UUID, fieldID uuid.UUID) (*models.CustomField, error) {
+	if mock.FindByIDFunc == nil {
+		panic("CustomFieldStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, fieldID)
+}
+
+// List calls ListFunc
+func (mock *CustomFieldStore) List(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error) {
+	if mock.ListFunc == nil {
+		panic("CustomFieldStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, entity)
+}
+
+// Update calls UpdateFunc
+func (mock *CustomFieldStore) Update(ctx context.Context, field *models.CustomField) error {
+	if mock.UpdateFunc == nil {
+		panic("CustomFieldStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, field)
+}
+
+// DashboardStore is a mock of repository.DashboardStore
+type DashboardStore struct {
+	CountInvitationsFunc      func(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardInvitations, error)
+	CountNewUsersFunc         func(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardNewUsers, error)
+	CountSessionsByDeviceFunc func(ctx context.Context, tenantID uuid.UUID) ([]models.DashboardDeviceSessions, error)
+	ListActivityDaysFunc      func(ctx context.Context, tenantID uuid.UUID, start time.Time) ([]models.DashboardDay, error)
+}
+
+// CountInvitations calls CountInvitationsFunc
+func (mock *DashboardStore) CountInvitations(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardInvitations, error) {
+	if mock.CountInvitationsFunc == nil {
+		panic("DashboardStore.CountInvitations is not stubbed")
+	}
+	return mock.CountInvitationsFunc(ctx, tenantID, monthStart)
+}
+
+// CountNewUsers calls CountNewUsersFunc
+func (mock *DashboardStore) CountNewUsers(ctx context.Context, tenantID uuid.UUID, monthStart time.Time) (*models.DashboardNewUsers, error) {
+	if mock.CountNewUsersFunc == nil {
+		panic("DashboardStore.CountNewUsers is not stubbed")
+	}
+	return mock.CountNewUsersFunc(ctx, tenantID, monthStart)
+}
+
+// CountSessionsByDevice calls CountSessionsByDeviceFunc
+func (mock *DashboardStore) CountSessionsByDevice(ctx context.Context, tenantID uuid.UUID) ([]models.DashboardDeviceSessions, error) {
+	if mock.CountSessionsByDeviceFunc == nil {
+		panic("DashboardStore.CountSessionsByDevice is not stubbed")
+	}
+	return mock.CountSessionsByDeviceFunc(ctx, tenantID)
+}
+
+// ListActivityDays calls ListActivityDaysFunc
+func (mock *DashboardStore) ListActivityDays(ctx context.Context, tenantID uuid.UUID, start time.Time) ([]models.DashboardDay, error) {
+	if mock.ListActivityDaysFunc == nil {
+		panic("DashboardStore.ListActivityDays is not stubbed")
+	}
+	return mock.ListActivityDaysFunc(ctx, tenantID, start)
+}
+
+// DataQualityStore is a mock of repository.DataQualityStore
+type DataQualityStore struct {
+	CheckExistsFunc  func(ctx context.Context, tenantID uuid.UUID, checkKey string) (bool, error)
+	CreateFunc       func(ctx context.Context, rule *models.DataQualityRule) error
+	DeleteFunc       func(ctx context.Context, tenantID, id uuid.UUID) error
+	FindByIDFunc     func(ctx context.Context, tenantID, id uuid.UUID) (*models.DataQualityRule, error)
+	ListFunc         func(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error)
+	ListEnabledFunc  func(ctx context.Context, db *sqlx.DB) ([]models.DataQualityRule, error)
+	RecordResultFunc func(ctx context.Context, tenantID, id uuid.UUID, checkedAt time.Time, issueCount int, samples []models.DataQualityIssue) error
+	RunCheckFunc     func(ctx context.Context, tenantID uuid.UUID, checkKey string, sampleSize int) (int, []models.DataQualityIssue, error)
+	UpdateFunc       func(ctx context.Context, rule *models.DataQualityRule) error
+}
+
+// CheckExists calls CheckExistsFunc
+func (mock *DataQualityStore) CheckExists(ctx context.Context, tenantID uuid.UUID, checkKey string) (bool, error) {
+	if mock.CheckExistsFunc == nil {
+		panic("DataQualityStore.CheckExists is not stubbed")
+	}
+	return mock.CheckExistsFunc(ctx, tenantID, checkKey)
+}
+
+// Create calls CreateFunc
+func (mock *DataQualityStore) Create(ctx context.Context, rule *models.DataQualityRule) error {
+	if mock.CreateFunc == nil {
+		panic("DataQualityStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, rule)
+}
+
+// Delete calls DeleteFunc
+func (mock *DataQualityStore) Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("DataQualityStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, id)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *DataQualityStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.DataQualityRule, error) {
+	if mock.FindByIDFunc == nil {
+		panic("DataQualityStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// List calls ListFunc
+func (mock *DataQualityStore) List(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error) {
+	if mock.ListFunc == nil {
+		panic("DataQualityStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID)
+}
+
+// ListEnabled calls ListEnabledFunc
+func (mock *DataQualityStore) ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.DataQualityRule, error) {
+	if mock.ListEnabledFunc == nil {
+		panic("DataQualityStore.ListEnabled is not stubbed")
+	}
+	return mock.ListEnabledFunc(ctx, db)
+}
+
+// RecordResult calls RecordResultFunc
+func (mock *DataQualityStore) RecordResult(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, checkedAt time.Time, issueCount int, samples []models.DataQualityIssue) error {
+	if mock.RecordResultFunc == nil {
+		panic("DataQualityStore.RecordResult is not stubbed")
+	}
+	return mock.RecordResultFunc(ctx, tenantID, id, checkedAt, issueCount, samples)
+}
+
+// RunCheck calls RunCheckFunc
+func (mock *DataQualityStore) RunCheck(ctx context.Context, tenantID uuid.UUID, checkKey string, sampleSize int) (int, []models.DataQualityIssue, error) {
+	if mock.RunCheckFunc == nil {
+		panic("DataQualityStore.RunCheck is not stubbed")
+	}
+	return mock.RunCheckFunc(ctx, tenantID, checkKey, sampleSize)
+}
+
+// Update calls UpdateFunc
+func (mock *DataQualityStore) Update(ctx context.Context, rule *models.DataQualityRule) error {
+	if mock.UpdateFunc == nil {
+		panic("DataQualityStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, rule)
+}
+
+// DeletionStore is a mock of repository.DeletionStore
+type DeletionStore struct {
+	ClaimDueFunc        func(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.PendingDeletion, error)
+	CreateFunc          func(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error
+	FindByIDFunc        func(ctx context.Context, tenantID, id uuid.UUID) (*models.PendingDeletion, error)
+	ListByRequesterFunc func(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error)
+	MarkExecutedFunc    func(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error
+	MarkFailedFunc      func(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion, cause string) error
+	MarkUndoneFunc      func(ctx context.Context, tenantID, id, userID uuid.UUID) error
+}
+
+// ClaimDue calls ClaimDueFunc
+func (mock *DeletionStore) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.ClaimDueFunc == nil {
+		panic("DeletionStore.ClaimDue is not stubbed")
+	}
+	return mock.ClaimDueFunc(ctx, tx, paused)
+}
+
+// Create calls CreateFunc
+func (mock *DeletionStore) Create(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error {
+	if mock.CreateFunc == nil {
+		panic("DeletionStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tx, deletion)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *DeletionStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.FindByIDFunc == nil {
+		panic("DeletionStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// ListByRequester calls ListByRequesterFunc
+func (mock *DeletionStore) ListByRequester(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, status string, limit int, offset int) ([]models.PendingDeletion, int, error) {
+	if mock.ListByRequesterFunc == nil {
+		panic("DeletionStore.ListByRequester is not stubbed")
+	}
+	return mock.ListByRequesterFunc(ctx, tenantID, userID, status, limit, offset)
+}
+
+// MarkExecuted calls MarkExecutedFunc
+func (mock *DeletionStore) MarkExecuted(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion) error {
+	if mock.MarkExecutedFunc == nil {
+		panic("DeletionStore.MarkExecuted is not stubbed")
+	}
+	return mock.MarkExecutedFunc(ctx, tx, deletion)
+}
+
+// MarkFailed calls MarkFailedFunc
+func (mock *DeletionStore) MarkFailed(ctx context.Context, tx *sqlx.Tx, deletion *models.PendingDeletion, cause string) error {
+	if mock.MarkFailedFunc == nil {
+		panic("DeletionStore.MarkFailed is not stubbed")
+	}
+	return mock.MarkFailedFunc(ctx, tx, deletion, cause)
+}
+
+// MarkUndone calls MarkUndoneFunc
+func (mock *DeletionStore) MarkUndone(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	if mock.MarkUndoneFunc == nil {
+		panic("DeletionStore.MarkUndone is not stubbed")
+	}
+	return mock.MarkUndoneFunc(ctx, tenantID, id, userID)
+}
+
+// DepartmentStore is a mock of repository.DepartmentStore
+type DepartmentStore struct {
+	CheckNameExistsFunc func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CountMembersFunc    func(ctx context.Context, tenantID, deptID uuid.UUID) (int, error)
+	CreateFunc          func(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error
+	DeleteFunc          func(ctx context.Context, tenantID, deptID uuid.UUID) error
+	FindByIDFunc        func(ctx context.Context, tenantID, deptID uuid.UUID) (*models.Department, error)
+	FindByNameFunc      func(ctx context.Context, tenantID uuid.UUID, name string) (*models.Department, error)
+	GetMembersFunc      func(ctx context.Context, tenantID, deptID uuid.UUID) ([]models.User, error)
+	GetWithDetailsFunc  func(ctx context.Context, tenantID, deptID uuid.UUID) (*models.Department, error)
+	ListFunc            func(ctx context.Context, tenantID uuid.UUID, filter models.DepartmentFilter) ([]models.Department, error)
+	ListActiveFunc      func(ctx context.Context, tenantID uuid.UUID) ([]models.Department, error)
+	UpdateFunc          func(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error
+}
+
+// CheckNameExists calls CheckNameExistsFunc
+func (mock *DepartmentStore) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNameExistsFunc == nil {
+		panic("DepartmentStore.CheckNameExists is not stubbed")
+	}
+	return mock.CheckNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// CountMembers calls CountMembersFunc
+func (mock *DepartmentStore) CountMembers(ctx context.Context, tenantID uuid.UUID, deptID uuid.UUID) (int, error) {
+	if mock.CountMembersFunc == nil {
+		panic("DepartmentStore.CountMembers is not stubbed")
+	}
+	return mock.CountMembersFunc(ctx, tenantID, deptID)
+}
+
+// Create calls CreateFunc
+func (mock *DepartmentStore) Create(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error {
+	if mock.CreateFunc == nil {
+		panic("DepartmentStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, dept)
+}
+
+// Delete calls DeleteFunc
+func (mock *DepartmentStore) Delete(ctx context.Context, tenantID uuid.UUID, deptID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("DepartmentStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, deptID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *DepartmentStore) FindByID(ctx context.Context, tenantID uuid.UUID, deptID uuid.UUID) (*models.Department, error) {
+	if mock.FindByIDFunc == nil {
+		panic("DepartmentStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, deptID)
+}
+
+// FindByName calls FindByNameFunc
+func (mock *DepartmentStore) FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Department, error) {
+	if mock.FindByNameFunc == nil {
+		panic("DepartmentStore.FindByName is not stubbed")
+	}
+	return mock.FindByNameFunc(ctx, tenantID, name)
+}
+
+// GetMembers calls GetMembersFunc
+func (mock *DepartmentStore) GetMembers(ctx context.Context, tenantID uuid.UUID, deptID uuid.UUID) ([]models.User, error) {
+	if mock.GetMembersFunc == nil {
+		panic("DepartmentStore.GetMembers is not stubbed")
+	}
+	return mock.GetMembersFunc(ctx, tenantID, deptID)
+}
+
+// GetWithDetails calls GetWithDetailsFunc
+func (mock *DepartmentStore) GetWithDetails(ctx context.Context, tenantID uuid.UUID, deptID uuid.UUID) (*models.Department, error) {
+	if mock.GetWithDetailsFunc == nil {
+		panic("DepartmentStore.GetWithDetails is not stubbed")
+	}
+	return mock.GetWithDetailsFunc(ctx, tenantID, deptID)
+}
+
+// List calls ListFunc
+func (mock *DepartmentStore) List(ctx context.Context, tenantID uuid.UUID, filter models.DepartmentFilter) ([]models.Department, error) {
+	if mock.ListFunc == nil {
+		panic("DepartmentStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter)
+}
+
+// ListActive calls ListActiveFunc
+func (mock *DepartmentStore) ListActive(ctx context.Context, tenantID uuid.UUID) ([]models.Department, error) {
+	if mock.ListActiveFunc == nil {
+		panic("DepartmentStore.ListActive is not stubbed")
+	}
+	return mock.ListActiveFunc(ctx, tenantID)
+}
+
+// Update calls UpdateFunc
+func (mock *DepartmentStore) Update(ctx context.Context, tenantID uuid.UUID, dept *models.Department) error {
+	if mock.UpdateFunc == nil {
+		panic("DepartmentStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, dept)
+}
+
+// EmailOutboxStore is a mock of repository.EmailOutboxStore
+type EmailOutboxStore struct {
+	ClaimDueFunc               func(ctx context.Context, tx *sqlx.Tx) (*models.OutboxEmail, error)
+	EnqueueFunc                func(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage, maxPending int) (uuid.UUID, error)
+	ListFunc                   func(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error)
+	MarkAttemptFailedFunc      func(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string, nextAttemptAt time.Time) error
+	MarkFailedFunc             func(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string) error
+	MarkSentFunc               func(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) error
+	QueueStatsFunc             func(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RecipientUndeliverableFunc func(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) (bool, error)
+	RetryFunc                  func(ctx context.Context, tenantID, emailID uuid.UUID) error
+	StatsFunc                  func(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error)
+}
+
+// ClaimDue calls ClaimDueFunc
+func (mock *EmailOutboxStore) ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.OutboxEmail, error) {
+	if mock.ClaimDueFunc == nil {
+		panic("EmailOutboxStore.ClaimDue is not stubbed")
+	}
+	return mock.ClaimDueFunc(ctx, tx)
+}
+
+// Enqueue calls EnqueueFunc
+func (mock *EmailOutboxStore) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage, maxPending int) (uuid.UUID, error) {
+	if mock.EnqueueFunc == nil {
+		panic("EmailOutboxStore.Enqueue is not stubbed")
+	}
+	return mock.EnqueueFunc(ctx, tx, tenantID, msg, maxPending)
+}
+
+// List calls ListFunc
+func (mock *EmailOutboxStore) List(ctx context.Context, tenantID uuid.UUID, status string, limit int, offset int) ([]models.OutboxEmail, int, error) {
+	if mock.ListFunc == nil {
+		panic("EmailOutboxStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, status, limit, offset)
+}
+
+// MarkAttemptFailed calls MarkAttemptFailedFunc
+func (mock *EmailOutboxStore) MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string, nextAttemptAt time.Time) error {
+	if mock.MarkAttemptFailedFunc == nil {
+		panic("EmailOutboxStore.MarkAttemptFailed is not stubbed")
+	}
+	return mock.MarkAttemptFailedFunc(ctx, tx, email, cause, nextAttemptAt)
+}
+
+// MarkFailed calls MarkFailedFunc
+func (mock *EmailOutboxStore) MarkFailed(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail, cause string) error {
+	if mock.MarkFailedFunc == nil {
+		panic("EmailOutboxStore.MarkFailed is not stubbed")
+	}
+	return mock.MarkFailedFunc(ctx, tx, email, cause)
+}
+
+// MarkSent calls MarkSentFunc
+func (mock *EmailOutboxStore) MarkSent(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) error {
+	if mock.MarkSentFunc == nil {
+		panic("EmailOutboxStore.MarkSent is not stubbed")
+	}
+	return mock.MarkSentFunc(ctx, tx, email)
+}
+
+// QueueStats calls QueueStatsFunc
+func (mock *EmailOutboxStore) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	if mock.QueueStatsFunc == nil {
+		panic("EmailOutboxStore.QueueStats is not stubbed")
+	}
+	return mock.QueueStatsFunc(ctx, db)
+}
+
+// RecipientUndeliverable calls RecipientUndeliverableFunc
+func (mock *EmailOutboxStore) RecipientUndeliverable(ctx context.Context, tx *sqlx.Tx, email *models.OutboxEmail) (bool, error) {
+	if mock.RecipientUndeliverableFunc == nil {
+		panic("EmailOutboxStore.RecipientUndeliverable is not stubbed")
+	}
+	return mock.RecipientUndeliverableFunc(ctx, tx, email)
+}
+
+// Retry calls RetryFunc
+func (mock *EmailOutboxStore) Retry(ctx context.Context, tenantID uuid.UUID, emailID uuid.UUID) error {
+	if mock.RetryFunc == nil {
+		panic("EmailOutboxStore.Retry is not stubbed")
+	}
+	return mock.RetryFunc(ctx, tenantID, emailID)
+}
+
+// Stats calls StatsFunc
+func (mock *EmailOutboxStore) Stats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error) {
+	if mock.StatsFunc == nil {
+		panic("EmailOutboxStore.Stats is not stubbed")
+	}
+	return mock.StatsFunc(ctx, tenantID)
+}
+
+// EmployeeStore is a mock of repository.EmployeeStore
+type EmployeeStore struct {
+	CheckNumberExistsFunc func(ctx context.Context, tenantID uuid.UUID, number string, excludeID *uuid.UUID) (bool, error)
+	CreateFunc            func(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error
+	DeleteFunc            func(ctx context.Context, tenantID, employeeID uuid.UUID) error
+	FindByIDFunc          func(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error)
+	FindByUserIDFunc      func(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error)
+	ListFunc              func(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error)
+	ListCurrentFunc       func(ctx context.Context, tenantID uuid.UUID) ([]models.Employee, error)
+	ReportsToFunc         func(ctx context.Context, tenantID, employeeID, ancestorID uuid.UUID) (bool, error)
+	UpdateFunc            func(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error
+}
+
+// CheckNumberExists calls CheckNumberExistsFunc
+func (mock *EmployeeStore) CheckNumberExists(ctx context.Context, tenantID uuid.UUID, number string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNumberExistsFunc == nil {
+		panic("EmployeeStore.CheckNumberExists is not stubbed")
+	}
+	return mock.CheckNumberExistsFunc(ctx, tenantID, number, excludeID)
+}
+
+// Create calls CreateFunc
+func (mock *EmployeeStore) Create(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error {
+	if mock.CreateFunc == nil {
+		panic("EmployeeStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, employee)
+}
+
+// Delete calls DeleteFunc
+func (mock *EmployeeStore) Delete(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("EmployeeStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, employeeID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *EmployeeStore) FindByID(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID) (*models.Employee, error) {
+	if mock.FindByIDFunc == nil {
+		panic("EmployeeStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, employeeID)
+}
+
+// FindByUserID calls FindByUserIDFunc
+func (mock *EmployeeStore) FindByUserID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.Employee, error) {
+	if mock.FindByUserIDFunc == nil {
+		panic("EmployeeStore.FindByUserID is not stubbed")
+	}
+	return mock.FindByUserIDFunc(ctx, tenantID, userID)
+}
+
+// List calls ListFunc
+func (mock *EmployeeStore) List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit int, offset int) ([]models.Employee, int, error) {
+	if mock.ListFunc == nil {
+		panic("EmployeeStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListCurrent calls ListCurrentFunc
+func (mock *EmployeeStore) ListCurrent(ctx context.Context, tenantID uuid.UUID) ([]models.Employee, error) {
+	if mock.ListCurrentFunc == nil {
+		panic("EmployeeStore.ListCurrent is not stubbed")
+	}
+	return mock.ListCurrentFunc(ctx, tenantID)
+}
+
+// ReportsTo calls ReportsToFunc
+func (mock *EmployeeStore) ReportsTo(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID, ancestorID uuid.UUID) (bool, error) {
+	if mock.ReportsToFunc == nil {
+		panic("EmployeeStore.ReportsTo is not stubbed")
+	}
+	return mock.ReportsToFunc(ctx, tenantID, employeeID, ancestorID)
+}
+
+// Update calls UpdateFunc
+func (mock *EmployeeStore) Update(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) error {
+	if mock.UpdateFunc == nil {
+		panic("EmployeeStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, employee)
+}
+
+// EscalationStore is a mock of repository.EscalationStore
+type EscalationStore struct {
+	CheckNameExistsFunc func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateFunc          func(ctx context.Context, policy *models.EscalationPolicy) error
+	DeleteFunc          func(ctx context.Context, tenantID, id uuid.UUID) error
+	FindByIDFunc        func(ctx context.Context, tenantID, id uuid.UUID) (*models.EscalationPolicy, error)
+	LastStepsFunc       func(ctx context.Context, tenantID, policyID uuid.UUID) (map[uuid.UUID]int, error)
+	ListFunc            func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error)
+	ListByPolicyFunc    func(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error)
+	ListEnabledFunc     func(ctx context.Context, db *sqlx.DB) ([]models.EscalationPolicy, error)
+	ListForResourceFunc func(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error)
+	RecordFunc          func(ctx context.Context, tx *sqlx.Tx, escalation *models.Escalation) (bool, error)
+	SetEnabledFunc      func(ctx context.Context, tenantID, id uuid.UUID, enabled bool) (*models.EscalationPolicy, error)
+	SetSlackResultFunc  func(ctx context.Context, tenantID, id uuid.UUID, status string, slackErr *string) error
+	UpdateFunc          func(ctx context.Context, policy *models.EscalationPolicy) error
+}
+
+// CheckNameExists calls CheckNameExistsFunc
+func (mock *EscalationStore) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNameExistsFunc == nil {
+		panic("EscalationStore.CheckNameExists is not stubbed")
+	}
+	return mock.CheckNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// Create calls CreateFunc
+func (mock *EscalationStore) Create(ctx context.Context, policy *models.EscalationPolicy) error {
+	if mock.CreateFunc == nil {
+		panic("EscalationStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, policy)
+}
+
+// Delete calls DeleteFunc
+func (mock *EscalationStore) Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("EscalationStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, id)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *EscalationStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.EscalationPolicy, error) {
+	if mock.FindByIDFunc == nil {
+		panic("EscalationStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// LastSteps calls LastStepsFunc
+func (mock *EscalationStore) LastSteps(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID) (map[uuid.UUID]int, error) {
+	if mock.LastStepsFunc == nil {
+		panic("EscalationStore.LastSteps is not stubbed")
+	}
+	return mock.LastStepsFunc(ctx, tenantID, policyID)
+}
+
+// List calls ListFunc
+func (mock *EscalationStore) List(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.EscalationPolicy, int, error) {
+	if mock.ListFunc == nil {
+		panic("EscalationStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, limit, offset)
+}
+
+// ListByPolicy calls ListByPolicyFunc
+func (mock *EscalationStore) ListByPolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, limit int, offset int) ([]models.Escalation, int, error) {
+	if mock.ListByPolicyFunc == nil {
+		panic("EscalationStore.ListByPolicy is not stubbed")
+	}
+	return mock.ListByPolicyFunc(ctx, tenantID, policyID, limit, offset)
+}
+
+// ListEnabled calls ListEnabledFunc
+func (mock *EscalationStore) ListEnabled(ctx context.Context, db *sqlx.DB) ([]models.EscalationPolicy, error) {
+	if mock.ListEnabledFunc == nil {
+		panic("EscalationStore.ListEnabled is not stubbed")
+	}
+	return mock.ListEnabledFunc(ctx, db)
+}
+
+// ListForResource calls ListForResourceFunc
+func (mock *EscalationStore) ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error) {
+	if mock.ListForResourceFunc == nil {
+		panic("EscalationStore.ListForResource is not stubbed")
+	}
+	return mock.ListForResourceFunc(ctx, tenantID, resourceType, resourceID)
+}
+
+// Record calls RecordFunc
+func (mock *EscalationStore) Record(ctx context.Context, tx *sqlx.Tx, escalation *models.Escalation) (bool, error) {
+	if mock.RecordFunc == nil {
+		panic("EscalationStore.Record is not stubbed")
+	}
+	return mock.RecordFunc(ctx, tx, escalation)
+}
+
+// SetEnabled calls SetEnabledFunc
+func (mock *EscalationStore) SetEnabled(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, enabled bool) (*models.EscalationPolicy, error) {
+	if mock.SetEnabledFunc == nil {
+		panic("EscalationStore.SetEnabled is not stubbed")
+	}
+	return mock.SetEnabledFunc(ctx, tenantID, id, enabled)
+}
+
+// SetSlackResult calls SetSlackResultFunc
+func (mock *EscalationStore) SetSlackResult(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, status string, slackErr *string) error {
+	if mock.SetSlackResultFunc == nil {
+		panic("EscalationStore.SetSlackResult is not stubbed")
+	}
+	return mock.SetSlackResultFunc(ctx, tenantID, id, status, slackErr)
+}
+
+// Update calls UpdateFunc
+func (mock *EscalationStore) Update(ctx context.Context, policy *models.EscalationPolicy) error {
+	if mock.UpdateFunc == nil {
+		panic("EscalationStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, policy)
+}
+
+// FileStore is a mock of repository.FileStore
+type FileStore struct {
+	CreateFunc                func(ctx context.Context, tenantID uuid.UUID, file *models.File) error
+	FindByIDFunc              func(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, error)
+	ListFunc                  func(ctx context.Context, tenantID uuid.UUID, filter models.FileFilter, visibleTo *uuid.UUID) ([]models.File, int, error)
+	ListAllFunc               func(ctx context.Context, tenantID uuid.UUID) ([]models.File, error)
+	PurgeDeletedOrExpiredFunc func(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, file *models.File) error) (int, error)
+	RestoreFunc               func(ctx context.Context, tenantID, fileID uuid.UUID) error
+	SoftDeleteFunc            func(ctx context.Context, tenantID, fileID, deletedBy uuid.UUID) error
+	SoftDeleteByResourceFunc  func(ctx context.Context, tenantID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID, deletedBy uuid.UUID) (int, error)
+}
+
+// Create calls CreateFunc
+func (mock *FileStore) Create(ctx context.Context, tenantID uuid.UUID, file *models.File) error {
+	if mock.CreateFunc == nil {
+		panic("FileStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, file)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *FileStore) FindByID(ctx context.Context, tenantID uuid.UUID, fileID uuid.UUID) (*models.File, error) {
+	if mock.FindByIDFunc == nil {
+		panic("FileStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, fileID)
+}
+
+// List calls ListFunc
+func (mock *FileStore) List(ctx context.Context, tenantID uuid.UUID, filter models.FileFilter, visibleTo *uuid.UUID) ([]models.File, int, error) {
+	if mock.ListFunc == nil {
+		panic("FileStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, visibleTo)
+}
+
+// ListAll calls ListAllFunc
+func (mock *FileStore) ListAll(ctx context.Context, tenantID uuid.UUID) ([]models.File, error) {
+	if mock.ListAllFunc == nil {
+		panic("FileStore.ListAll is not stubbed")
+	}
+	return mock.ListAllFunc(ctx, tenantID)
+}
+
+// PurgeDeletedOrExpired calls PurgeDeletedOrExpiredFunc
+func (mock *FileStore) PurgeDeletedOrExpired(ctx context.Context, cutoff time.Time, remove func(ctx context.Context, file *models.File) error) (int, error) {
+	if mock.PurgeDeletedOrExpiredFunc == nil {
+		panic("FileStore.PurgeDeletedOrExpired is not stubbed")
+	}
+	return mock.PurgeDeletedOrExpiredFunc(ctx, cutoff, remove)
+}
+
+// Restore calls RestoreFunc
+func (mock *FileStore) Restore(ctx context.Context, tenantID uuid.UUID, fileID uuid.UUID) error {
+	if mock.RestoreFunc == nil {
+		panic("FileStore.Restore is not stubbed")
+	}
+	return mock.RestoreFunc(ctx, tenantID, fileID)
+}
+
+// SoftDelete calls SoftDeleteFunc
+func (mock *FileStore) SoftDelete(ctx context.Context, tenantID uuid.UUID, fileID uuid.UUID, deletedBy uuid.UUID) error {
+	if mock.SoftDeleteFunc == nil {
+		panic("FileStore.SoftDelete is not stubbed")
+	}
+	return mock.SoftDeleteFunc(ctx, tenantID, fileID, deletedBy)
+}
+
+// SoftDeleteByResource calls SoftDeleteByResourceFunc
+func (mock *FileStore) SoftDeleteByResource(ctx context.Context, tenantID uuid.UUID, category string, resourceType string, resourceID uuid.UUID, keep []uuid.UUID, deletedBy uuid.UUID) (int, error) {
+	if mock.SoftDeleteByResourceFunc == nil {
+		panic("FileStore.SoftDeleteByResource is not stubbed")
+	}
+	return mock.SoftDeleteByResourceFunc(ctx, tenantID, category, resourceType, resourceID, keep, deletedBy)
+}
+
+// JournalEntryStore is a mock of repository.JournalEntryStore
+type JournalEntryStore struct {
+	CreateFunc         func(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error
+	CreateReversalFunc func(ctx context.Context, tenantID, originalID uuid.UUID, reversal *models.JournalEntry) error
+	DeleteFunc         func(ctx context.Context, tenantID, entryID uuid.UUID) error
+	FindByIDFunc       func(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error)
+	ListFunc           func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.JournalEntryFilter,
+		limit, offset int,
+	) ([]models.JournalEntry, int, error)
+	LockFunc         func(ctx context.Context, tenantID, entryID uuid.UUID) error
+	PostFunc         func(ctx context.Context, tenantID, entryID, periodID, userID uuid.UUID) error
+	TrialBalanceFunc func(ctx context.Context, tenantID uuid.UUID, fromDate *time.Time, toDate time.Time) ([]models.TrialBalanceRow, error)
+	UpdateFunc       func(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error
+}
+
+// Create calls CreateFunc
+func (mock *JournalEntryStore) Create(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	if mock.CreateFunc == nil {
+		panic("JournalEntryStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, entry)
+}
+
+// CreateReversal calls CreateReversalFunc
+func (mock *JournalEntryStore) CreateReversal(ctx context.Context, tenantID uuid.UUID, originalID uuid.UUID, reversal *models.JournalEntry) error {
+	if mock.CreateReversalFunc == nil {
+		panic("JournalEntryStore.CreateReversal is not stubbed")
+	}
+	return mock.CreateReversalFunc(ctx, tenantID, originalID, reversal)
+}
+
+// Delete calls DeleteFunc
+func (mock *JournalEntryStore) Delete(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("JournalEntryStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, entryID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *JournalEntryStore) FindByID(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	if mock.FindByIDFunc == nil {
+		panic("JournalEntryStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, entryID)
+}
+
+// List calls ListFunc
+func (mock *JournalEntryStore) List(ctx context.Context, tenantID uuid.UUID, filter models.JournalEntryFilter, limit int, offset int) ([]models.JournalEntry, int, error) {
+	if mock.ListFunc == nil {
+		panic("JournalEntryStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// Lock calls LockFunc
+func (mock *JournalEntryStore) Lock(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) error {
+	if mock.LockFunc == nil {
+		panic("JournalEntryStore.Lock is not stubbed")
+	}
+	return mock.LockFunc(ctx, tenantID, entryID)
+}
+
+// Post calls PostFunc
+func (mock *JournalEntryStore) Post(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID, periodID uuid.UUID, userID uuid.UUID) error {
+	if mock.PostFunc == nil {
+		panic("JournalEntryStore.Post is not stubbed")
+	}
+	return mock.PostFunc(ctx, tenantID, entryID, periodID, userID)
+}
+
+// TrialBalance calls TrialBalanceFunc
+func (mock *JournalEntryStore) TrialBalance(ctx context.Context, tenantID uuid.UUID, fromDate *time.Time, toDate time.Time) ([]models.TrialBalanceRow, error) {
+	if mock.TrialBalanceFunc == nil {
+		panic("JournalEntryStore.TrialBalance is not stubbed")
+	}
+	return mock.TrialBalanceFunc(ctx, tenantID, fromDate, toDate)
+}
+
+// Update calls UpdateFunc
+func (mock *JournalEntryStore) Update(ctx context.Context, tenantID uuid.UUID, entry *models.JournalEntry) error {
+	if mock.UpdateFunc == nil {
+		panic("JournalEntryStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, entry)
+}
+
+// LeaveStore is a mock of repository.LeaveStore
+type LeaveStore struct {
+	AddToBalanceFunc        func(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, pending, used float64) error
+	CalendarFunc            func(ctx context.Context, tenantID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error)
+	CheckTypeCodeExistsFunc func(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	CreateRequestFunc       func(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error
+	CreateTypeFunc          func(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error
+	DeleteTypeFunc          func(ctx context.Context, tenantID, typeID uuid.UUID) error
+	FindBalanceFunc         func(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID, typeID uuid.UUID, year int) (*models.LeaveBalance, error)
+	FindRequestByIDFunc     func(ctx context.Context, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	FindTypeByIDFunc        func(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error)
+	HasOverlapFunc          func(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, start, end time.Time) (bool, error)
+	ListAccrualTargetsFunc  func(ctx context.Context, db *sqlx.DB) ([]models.LeaveAccrualTarget, error)
+	ListBalancesFunc        func(ctx context.Context, tenantID, employeeID uuid.UUID, year int) ([]models.LeaveBalance, error)
+	ListRequestsFunc        func(ctx context.Context, tenantID uuid.UUID, filter models.LeaveRequestFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListTypesFunc           func(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error)
+	LockRequestFunc         func(ctx context.Context, tx *sqlx.Tx, tenantID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	SetAdjustmentFunc       func(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, adjustment float64) error
+	UpdateRequestStatusFunc func(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error
+	UpdateTypeFunc          func(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error
+	UpsertBalanceFunc       func(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance) error
+}
+
+// AddToBalance calls AddToBalanceFunc
+func (mock *LeaveStore) AddToBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, pending float64, used float64) error {
+	if mock.AddToBalanceFunc == nil {
+		panic("LeaveStore.AddToBalance is not stubbed")
+	}
+	return mock.AddToBalanceFunc(ctx, tx, balance, pending, used)
+}
+
+// Calendar calls CalendarFunc
+func (mock *LeaveStore) Calendar(ctx context.Context, tenantID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error) {
+	if mock.CalendarFunc == nil {
+		panic("LeaveStore.Calendar is not stubbed")
+	}
+	return mock.CalendarFunc(ctx, tenantID, filter)
+}
+
+// CheckTypeCodeExists calls CheckTypeCodeExistsFunc
+func (mock *LeaveStore) CheckTypeCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckTypeCodeExistsFunc == nil {
+		panic("LeaveStore.CheckTypeCodeExists is not stubbed")
+	}
+	return mock.CheckTypeCodeExistsFunc(ctx, tenantID, code, excludeID)
+}
+
+// CreateRequest calls CreateRequestFunc
+func (mock *LeaveStore) CreateRequest(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error {
+	if mock.CreateRequestFunc == nil {
+		panic("LeaveStore.CreateRequest is not stubbed")
+	}
+	return mock.CreateRequestFunc(ctx, tx, request)
+}
+
+// CreateType calls CreateTypeFunc
+func (mock *LeaveStore) CreateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error {
+	if mock.CreateTypeFunc == nil {
+		panic("LeaveStore.CreateType is not stubbed")
+	}
+	return mock.CreateTypeFunc(ctx, tenantID, leaveType)
+}
+
+// DeleteType calls DeleteTypeFunc
+func (mock *LeaveStore) DeleteType(ctx context.Context, tenantID uuid.UUID, typeID uuid.UUID) error {
+	if mock.DeleteTypeFunc == nil {
+		panic("LeaveStore.DeleteType is not stubbed")
+	}
+	return mock.DeleteTypeFunc(ctx, tenantID, typeID)
+}
+
+// FindBalance calls FindBalanceFunc
+func (mock *LeaveStore) FindBalance(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, employeeID uuid.UUID, typeID uuid.UUID, year int) (*models.LeaveBalance, error) {
+	if mock.FindBalanceFunc == nil {
+		panic("LeaveStore.FindBalance is not stubbed")
+	}
+	return mock.FindBalanceFunc(ctx, tx, tenantID, employeeID, typeID, year)
+}
+
+// FindRequestByID calls FindRequestByIDFunc
+func (mock *LeaveStore) FindRequestByID(ctx context.Context, tenantID uuid.UUID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	if mock.FindRequestByIDFunc == nil {
+		panic("LeaveStore.FindRequestByID is not stubbed")
+	}
+	return mock.FindRequestByIDFunc(ctx, tenantID, requestID)
+}
+
+// FindTypeByID calls FindTypeByIDFunc
+func (mock *LeaveStore) FindTypeByID(ctx context.Context, tenantID uuid.UUID, typeID uuid.UUID) (*models.LeaveType, error) {
+	if mock.FindTypeByIDFunc == nil {
+		panic("LeaveStore.FindTypeByID is not stubbed")
+	}
+	return mock.FindTypeByIDFunc(ctx, tenantID, typeID)
+}
+
+// HasOverlap calls HasOverlapFunc
+func (mock *LeaveStore) HasOverlap(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, employeeID uuid.UUID, start time.Time, end time.Time) (bool, error) {
+	if mock.HasOverlapFunc == nil {
+		panic("LeaveStore.HasOverlap is not stubbed")
+	}
+	return mock.HasOverlapFunc(ctx, tx, tenantID, employeeID, start, end)
+}
+
+// ListAccrualTargets calls ListAccrualTargetsFunc
+func (mock *LeaveStore) ListAccrualTargets(ctx context.Context, db *sqlx.DB) ([]models.LeaveAccrualTarget, error) {
+	if mock.ListAccrualTargetsFunc == nil {
+		panic("LeaveStore.ListAccrualTargets is not stubbed")
+	}
+	return mock.ListAccrualTargetsFunc(ctx, db)
+}
+
+// ListBalances calls ListBalancesFunc
+func (mock *LeaveStore) ListBalances(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID, year int) ([]models.LeaveBalance, error) {
+	if mock.ListBalancesFunc == nil {
+		panic("LeaveStore.ListBalances is not stubbed")
+	}
+	return mock.ListBalancesFunc(ctx, tenantID, employeeID, year)
+}
+
+// ListRequests calls ListRequestsFunc
+func (mock *LeaveStore) ListRequests(ctx context.Context, tenantID uuid.UUID, filter models.LeaveRequestFilter, visibleTo *uuid.UUID, limit int, offset int) ([]models.LeaveRequest, int, error) {
+	if mock.ListRequestsFunc == nil {
+		panic("LeaveStore.ListRequests is not stubbed")
+	}
+	return mock.ListRequestsFunc(ctx, tenantID, filter, visibleTo, limit, offset)
+}
+
+// ListTypes calls ListTypesFunc
+func (mock *LeaveStore) ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error) {
+	if mock.ListTypesFunc == nil {
+		panic("LeaveStore.ListTypes is not stubbed")
+	}
+	return mock.ListTypesFunc(ctx, tenantID, activeOnly)
+}
+
+// LockRequest calls LockRequestFunc
+func (mock *LeaveStore) LockRequest(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	if mock.LockRequestFunc == nil {
+		panic("LeaveStore.LockRequest is not stubbed")
+	}
+	return mock.LockRequestFunc(ctx, tx, tenantID, requestID)
+}
+
+// SetAdjustment calls SetAdjustmentFunc
+func (mock *LeaveStore) SetAdjustment(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance, adjustment float64) error {
+	if mock.SetAdjustmentFunc == nil {
+		panic("LeaveStore.SetAdjustment is not stubbed")
+	}
+	return mock.SetAdjustmentFunc(ctx, tx, balance, adjustment)
+}
+
+// UpdateRequestStatus calls UpdateRequestStatusFunc
+func (mock *LeaveStore) UpdateRequestStatus(ctx context.Context, tx *sqlx.Tx, request *models.LeaveRequest) error {
+	if mock.UpdateRequestStatusFunc == nil {
+		panic("LeaveStore.UpdateRequestStatus is not stubbed")
+	}
+	return mock.UpdateRequestStatusFunc(ctx, tx, request)
+}
+
+// UpdateType calls UpdateTypeFunc
+func (mock *LeaveStore) UpdateType(ctx context.Context, tenantID uuid.UUID, leaveType *models.LeaveType) error {
+	if mock.UpdateTypeFunc == nil {
+		panic("LeaveStore.UpdateType is not stubbed")
+	}
+	return mock.UpdateTypeFunc(ctx, tenantID, leaveType)
+}
+
+// UpsertBalance calls UpsertBalanceFunc
+func (mock *LeaveStore) UpsertBalance(ctx context.Context, tx *sqlx.Tx, balance *models.LeaveBalance) error {
+	if mock.UpsertBalanceFunc == nil {
+		panic("LeaveStore.UpsertBalance is not stubbed")
+	}
+	return mock.UpsertBalanceFunc(ctx, tx, balance)
+}
+
+// NavigationStore is a mock of repository.NavigationStore
+type NavigationStore struct {
+	AddFavoriteFunc    func(ctx context.Context, favorite *models.Favorite) (bool, error)
+	CountFavoritesFunc func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	ListFavoritesFunc  func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error)
+	ListRecentFunc     func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error)
+	RemoveFavoriteFunc func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	ReplaceRecentFunc  func(ctx context.Context, tenantID, userID uuid.UUID, views []models.RecentView) error
+}
+
+// AddFavorite calls AddFavoriteFunc
+func (mock *NavigationStore) AddFavorite(ctx context.Context, favorite *models.Favorite) (bool, error) {
+	if mock.AddFavoriteFunc == nil {
+		panic("NavigationStore.AddFavorite is not stubbed")
+	}
+	return mock.AddFavoriteFunc(ctx, favorite)
+}
+
+// CountFavorites calls CountFavoritesFunc
+func (mock *NavigationStore) CountFavorites(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.CountFavoritesFunc == nil {
+		panic("NavigationStore.CountFavorites is not stubbed")
+	}
+	return mock.CountFavoritesFunc(ctx, tenantID, userID)
+}
+
+// ListFavorites calls ListFavoritesFunc
+func (mock *NavigationStore) ListFavorites(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Favorite, error) {
+	if mock.ListFavoritesFunc == nil {
+		panic("NavigationStore.ListFavorites is not stubbed")
+	}
+	return mock.ListFavoritesFunc(ctx, tenantID, userID)
+}
+
+// ListRecent calls ListRecentFunc
+func (mock *NavigationStore) ListRecent(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.RecentView, error) {
+	if mock.ListRecentFunc == nil {
+		panic("NavigationStore.ListRecent is not stubbed")
+	}
+	return mock.ListRecentFunc(ctx, tenantID, userID)
+}
+
+// RemoveFavorite calls RemoveFavoriteFunc
+func (mock *NavigationStore) RemoveFavorite(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.RemoveFavoriteFunc == nil {
+		panic("NavigationStore.RemoveFavorite is not stubbed")
+	}
+	return mock.RemoveFavoriteFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// ReplaceRecent calls ReplaceRecentFunc
+func (mock *NavigationStore) ReplaceRecent(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, views []models.RecentView) error {
+	if mock.ReplaceRecentFunc == nil {
+		panic("NavigationStore.ReplaceRecent is not stubbed")
+	}
+	return mock.ReplaceRecentFunc(ctx, tenantID, userID, views)
+}
+
+// NotificationStore is a mock of repository.NotificationStore
+type NotificationStore struct {
+	CountUnreadFunc          func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	CreateFunc               func(ctx context.Context, tenantID uuid.UUID, notifications []*models.Notification) error
+	DeleteReadBeforeFunc     func(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	FinishDigestFunc         func(ctx context.Context, tx *sqlx.Tx, prefs *models.NotificationPreferences, sentAt time.Time) error
+	GetPreferencesFunc       func(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error)
+	ListFunc                 func(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	ListDigestPendingFunc    func(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID) ([]models.Notification, error)
+	ListDigestRecipientsFunc func(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error)
+	ListPreferencesFunc      func(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]*models.NotificationPreferences, error)
+	MarkReadFunc             func(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	MarkUnreadFunc           func(ctx context.Context, tenantID, userID, id uuid.UUID) error
+	SavePreferencesFunc      func(ctx context.Context, prefs *models.NotificationPreferences) error
+}
+
+// CountUnread calls CountUnreadFunc
+func (mock *NotificationStore) CountUnread(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.CountUnreadFunc == nil {
+		panic("NotificationStore.CountUnread is not stubbed")
+	}
+	return mock.CountUnreadFunc(ctx, tenantID, userID)
+}
+
+// Create calls CreateFunc
+func (mock *NotificationStore) Create(ctx context.Context, tenantID uuid.UUID, notifications []*models.Notification) error {
+	if mock.CreateFunc == nil {
+		panic("NotificationStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, notifications)
+}
+
+// DeleteReadBefore calls DeleteReadBeforeFunc
+func (mock *NotificationStore) DeleteReadBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	if mock.DeleteReadBeforeFunc == nil {
+		panic("NotificationStore.DeleteReadBefore is not stubbed")
+	}
+	return mock.DeleteReadBeforeFunc(ctx, db, cutoff)
+}
+
+// FinishDigest calls FinishDigestFunc
+func (mock *NotificationStore) FinishDigest(ctx context.Context, tx *sqlx.Tx, prefs *models.NotificationPreferences, sentAt time.Time) error {
+	if mock.FinishDigestFunc == nil {
+		panic("NotificationStore.FinishDigest is not stubbed")
+	}
+	return mock.FinishDigestFunc(ctx, tx, prefs, sentAt)
+}
+
+// GetPreferences calls GetPreferencesFunc
+func (mock *NotificationStore) GetPreferences(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	if mock.GetPreferencesFunc == nil {
+		panic("NotificationStore.GetPreferences is not stubbed")
+	}
+	return mock.GetPreferencesFunc(ctx, tenantID, userID)
+}
+
+// List calls ListFunc
+func (mock *NotificationStore) List(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, unreadOnly bool, limit int, offset int) ([]models.Notification, int, error) {
+	if mock.ListFunc == nil {
+		panic("NotificationStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// ListDigestPending calls ListDigestPendingFunc
+func (mock *NotificationStore) ListDigestPending(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, userID uuid.UUID) ([]models.Notification, error) {
+	if mock.ListDigestPendingFunc == nil {
+		panic("NotificationStore.ListDigestPending is not stubbed")
+	}
+	return mock.ListDigestPendingFunc(ctx, tx, tenantID, userID)
+}
+
+// ListDigestRecipients calls ListDigestRecipientsFunc
+func (mock *NotificationStore) ListDigestRecipients(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	if mock.ListDigestRecipientsFunc == nil {
+		panic("NotificationStore.ListDigestRecipients is not stubbed")
+	}
+	return mock.ListDigestRecipientsFunc(ctx, tenantID)
+}
+
+// ListPreferences calls ListPreferencesFunc
+func (mock *NotificationStore) ListPreferences(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]*models.NotificationPreferences, error) {
+	if mock.ListPreferencesFunc == nil {
+		panic("NotificationStore.ListPreferences is not stubbed")
+	}
+	return mock.ListPreferencesFunc(ctx, tenantID, userIDs)
+}
+
+// MarkRead calls MarkReadFunc
+func (mock *NotificationStore) MarkRead(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	if mock.MarkReadFunc == nil {
+		panic("NotificationStore.MarkRead is not stubbed")
+	}
+	return mock.MarkReadFunc(ctx, tenantID, userID, ids)
+}
+
+// MarkUnread calls MarkUnreadFunc
+func (mock *NotificationStore) MarkUnread(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID) error {
+	if mock.MarkUnreadFunc == nil {
+		panic("NotificationStore.MarkUnread is not stubbed")
+	}
+	return mock.MarkUnreadFunc(ctx, tenantID, userID, id)
+}
+
+// SavePreferences calls SavePreferencesFunc
+func (mock *NotificationStore) SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	if mock.SavePreferencesFunc == nil {
+		panic("NotificationStore.SavePreferences is not stubbed")
+	}
+	return mock.SavePreferencesFunc(ctx, prefs)
+}
+
+// ObjectACLStore is a mock of repository.ObjectACLStore
+type ObjectACLStore struct {
+	FindFunc           func(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error)
+	ListRestrictedFunc func(ctx context.Context, tenantID uuid.UUID, resourceType string) ([]models.ObjectACL, error)
+	SaveFunc           func(ctx context.Context, tenantID uuid.UUID, acl *models.ObjectACL, updatedBy uuid.UUID) error
+}
+
+// Find calls FindFunc
+func (mock *ObjectACLStore) Find(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error) {
+	if mock.FindFunc == nil {
+		panic("ObjectACLStore.Find is not stubbed")
+	}
+	return mock.FindFunc(ctx, tenantID, resourceType, resourceID)
+}
+
+// ListRestricted calls ListRestrictedFunc
+func (mock *ObjectACLStore) ListRestricted(ctx context.Context, tenantID uuid.UUID, resourceType string) ([]models.ObjectACL, error) {
+	if mock.ListRestrictedFunc == nil {
+		panic("ObjectACLStore.ListRestricted is not stubbed")
+	}
+	return mock.ListRestrictedFunc(ctx, tenantID, resourceType)
+}
+
+// Save calls SaveFunc
+func (mock *ObjectACLStore) Save(ctx context.Context, tenantID uuid.UUID, acl *models.ObjectACL, updatedBy uuid.UUID) error {
+	if mock.SaveFunc == nil {
+		panic("ObjectACLStore.Save is not stubbed")
+	}
+	return mock.SaveFunc(ctx, tenantID, acl, updatedBy)
+}
+
+// PermissionStore is a mock of repository.PermissionStore
+type PermissionStore struct {
+	CountFunc                 func(ctx context.Context) (int, error)
+	CountByCategoryFunc       func(ctx context.Context) (map[string]int, error)
+	FindByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.Permission, error)
+	FindByResourceActionFunc  func(ctx context.Context, resource, action string) (*models.Permission, error)
+	GetActionsByResourceFunc  func(ctx context.Context, resource string) ([]string, error)
+	GetCategoryListFunc       func(ctx context.Context) ([]string, error)
+	GetRegistryFunc           func(ctx context.Context) ([]models.PermissionModule, error)
+	GetResourceListFunc       func(ctx context.Context) ([]string, error)
+	ListFunc                  func(ctx context.Context) ([]models.Permission, error)
+	ListByCategoryFunc        func(ctx context.Context) ([]models.PermissionGroup, error)
+	ListByIDsFunc             func(ctx context.Context, ids []uuid.UUID) ([]models.Permission, error)
+	ListByResourceFunc        func(ctx context.Context, resource string) ([]models.Permission, error)
+	SearchFunc                func(ctx context.Context, searchTerm string) ([]models.Permission, error)
+	ValidatePermissionIDsFunc func(ctx context.Context, ids []uuid.UUID) (bool, []uuid.UUID, error)
+}
+
+// Count calls CountFunc
+func (mock *PermissionStore) Count(ctx context.Context) (int, error) {
+	if mock.CountFunc == nil {
+		panic("PermissionStore.Count is not stubbed")
+	}
+	return mock.CountFunc(ctx)
+}
+
+// CountByCategory calls CountByCategoryFunc
+func (mock *PermissionStore) CountByCategory(ctx context.Context) (map[string]int, error) {
+	if mock.CountByCategoryFunc == nil {
+		panic("PermissionStore.CountByCategory is not stubbed")
+	}
+	return mock.CountByCategoryFunc(ctx)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *PermissionStore) FindByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
+	if mock.FindByIDFunc == nil {
+		panic("PermissionStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, id)
+}
+
+// FindByResourceAction calls FindByResourceActionFunc
+func (mock *PermissionStore) FindByResourceAction(ctx context.Context, resource string, action string) (*models.Permission, error) {
+	if mock.FindByResourceActionFunc == nil {
+		panic("PermissionStore.FindByResourceAction is not stubbed")
+	}
+	return mock.FindByResourceActionFunc(ctx, resource, action)
+}
+
+// GetActionsByResource calls GetActionsByResourceFunc
+func (mock *PermissionStore) GetActionsByResource(ctx context.Context, resource string) ([]string, error) {
+	if mock.GetActionsByResourceFunc == nil {
+		panic("PermissionStore.GetActionsByResource is not stubbed")
+	}
+	return mock.GetActionsByResourceFunc(ctx, resource)
+}
+
+// GetCategoryList calls GetCategoryListFunc
+func (mock *PermissionStore) GetCategoryList(ctx context.Context) ([]string, error) {
+	if mock.GetCategoryListFunc == nil {
+		panic("PermissionStore.GetCategoryList is not stubbed")
+	}
+	return mock.GetCategoryListFunc(ctx)
+}
+
+// GetRegistry calls GetRegistryFunc
+func (mock *PermissionStore) GetRegistry(ctx context.Context) ([]models.PermissionModule, error) {
+	if mock.GetRegistryFunc == nil {
+		panic("PermissionStore.GetRegistry is not stubbed")
+	}
+	return mock.GetRegistryFunc(ctx)
+}
+
+// GetResourceList calls GetResourceListFunc
+func (mock *PermissionStore) GetResourceList(ctx context.Context) ([]string, error) {
+	if mock.GetResourceListFunc == nil {
+		panic("PermissionStore.GetResourceList is not stubbed")
+	}
+	return mock.GetResourceListFunc(ctx)
+}
+
+// List calls ListFunc
+func (mock *PermissionStore) List(ctx context.Context) ([]models.Permission, error) {
+	if mock.ListFunc == nil {
+		panic("PermissionStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx)
+}
+
+// ListByCategory calls ListByCategoryFunc
+func (mock *PermissionStore) ListByCategory(ctx context.Context) ([]models.PermissionGroup, error) {
+	if mock.ListByCategoryFunc == nil {
+		panic("PermissionStore.ListByCategory is not stubbed")
+	}
+	return mock.ListByCategoryFunc(ctx)
+}
+
+// ListByIDs calls ListByIDsFunc
+func (mock *PermissionStore) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Permission, error) {
+	if mock.ListByIDsFunc == nil {
+		panic("PermissionStore.ListByIDs is not stubbed")
+	}
+	return mock.ListByIDsFunc(ctx, ids)
+}
+
+// ListByResource calls ListByResourceFunc
+func (mock *PermissionStore) ListByResource(ctx context.Context, resource string) ([]models.Permission, error) {
+	if mock.ListByResourceFunc == nil {
+		panic("PermissionStore.ListByResource is not stubbed")
+	}
+	return mock.ListByResourceFunc(ctx, resource)
+}
+
+// Search calls SearchFunc
+func (mock *PermissionStore) Search(ctx context.Context, searchTerm string) ([]models.Permission, error) {
+	if mock.SearchFunc == nil {
+		panic("PermissionStore.Search is not stubbed")
+	}
+	return mock.SearchFunc(ctx, searchTerm)
+}
+
+// ValidatePermissionIDs calls ValidatePermissionIDsFunc
+func (mock *PermissionStore) ValidatePermissionIDs(ctx context.Context, ids []uuid.UUID) (bool, []uuid.UUID, error) {
+	if mock.ValidatePermissionIDsFunc == nil {
+		panic("PermissionStore.ValidatePermissionIDs is not stubbed")
+	}
+	return mock.ValidatePermissionIDsFunc(ctx, ids)
+}
+
+// PersonalDataStore is a mock of repository.PersonalDataStore
+type PersonalDataStore struct {
+	AnonymizeFunc func(ctx context.Context, tenantID, userID uuid.UUID, email string) error
+	ExportFunc    func(ctx context.Context, tenantID, userID uuid.UUID, export *models.PersonalDataExport) error
+}
+
+// Anonymize calls AnonymizeFunc
+func (mock *PersonalDataStore) Anonymize(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, email string) error {
+	if mock.AnonymizeFunc == nil {
+		panic("PersonalDataStore.Anonymize is not stubbed")
+	}
+	return mock.AnonymizeFunc(ctx, tenantID, userID, email)
+}
+
+// Export calls ExportFunc
+func (mock *PersonalDataStore) Export(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, export *models.PersonalDataExport) error {
+	if mock.ExportFunc == nil {
+		panic("PersonalDataStore.Export is not stubbed")
+	}
+	return mock.ExportFunc(ctx, tenantID, userID, export)
+}
+
+// PlatformStore is a mock of repository.PlatformStore
+type PlatformStore struct {
+	CountTenantsFunc func(ctx context.Context, signedUpSince time.Time, stats *models.PlatformStats) error
+	CountUsersFunc   func(ctx context.Context, activeSince time.Time) (int, int, error)
+	GrantAdminFunc   func(ctx context.Context, admin *models.PlatformAdmin) error
+	IsAdminFunc      func(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	ListAdminsFunc   func(ctx context.Context) ([]models.PlatformAdmin, error)
+	ListTenantsFunc  func(ctx context.Context, filter models.PlatformTenantFilter, limit, offset int) ([]models.Tenant, int, error)
+	RevokeAdminFunc  func(ctx context.Context, userID uuid.UUID) error
+	SumUsageFunc     func(ctx context.Context, from, to time.Time) (map[string]int64, error)
+}
+
+// CountTenants calls CountTenantsFunc
+func (mock *PlatformStore) CountTenants(ctx context.Context, signedUpSince time.Time, stats *models.PlatformStats) error {
+	if mock.CountTenantsFunc == nil {
+		panic("PlatformStore.CountTenants is not stubbed")
+	}
+	return mock.CountTenantsFunc(ctx, signedUpSince, stats)
+}
+
+// CountUsers calls CountUsersFunc
+func (mock *PlatformStore) CountUsers(ctx context.Context, activeSince time.Time) (int, int, error) {
+	if mock.CountUsersFunc == nil {
+		panic("PlatformStore.CountUsers is not stubbed")
+	}
+	return mock.CountUsersFunc(ctx, activeSince)
+}
+
+// GrantAdmin calls GrantAdminFunc
+func (mock *PlatformStore) GrantAdmin(ctx context.Context, admin *models.PlatformAdmin) error {
+	if mock.GrantAdminFunc == nil {
+		panic("PlatformStore.GrantAdmin is not stubbed")
+	}
+	return mock.GrantAdminFunc(ctx, admin)
+}
+
+// IsAdmin calls IsAdminFunc
+func (mock *PlatformStore) IsAdmin(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (bool, error) {
+	if mock.IsAdminFunc == nil {
+		panic("PlatformStore.IsAdmin is not stubbed")
+	}
+	return mock.IsAdminFunc(ctx, tenantID, userID)
+}
+
+// ListAdmins calls ListAdminsFunc
+func (mock *PlatformStore) ListAdmins(ctx context.Context) ([]models.PlatformAdmin, error) {
+	if mock.ListAdminsFunc == nil {
+		panic("PlatformStore.ListAdmins is not stubbed")
+	}
+	return mock.ListAdminsFunc(ctx)
+}
+
+// ListTenants calls ListTenantsFunc
+func (mock *PlatformStore) ListTenants(ctx context.Context, filter models.PlatformTenantFilter, limit int, offset int) ([]models.Tenant, int, error) {
+	if mock.ListTenantsFunc == nil {
+		panic("PlatformStore.ListTenants is not stubbed")
+	}
+	return mock.ListTenantsFunc(ctx, filter, limit, offset)
+}
+
+// RevokeAdmin calls RevokeAdminFunc
+func (mock *PlatformStore) RevokeAdmin(ctx context.Context, userID uuid.UUID) error {
+	if mock.RevokeAdminFunc == nil {
+		panic("PlatformStore.RevokeAdmin is not stubbed")
+	}
+	return mock.RevokeAdminFunc(ctx, userID)
+}
+
+// SumUsage calls SumUsageFunc
+func (mock *PlatformStore) SumUsage(ctx context.Context, from time.Time, to time.Time) (map[string]int64, error) {
+	if mock.SumUsageFunc == nil {
+		panic("PlatformStore.SumUsage is not stubbed")
+	}
+	return mock.SumUsageFunc(ctx, from, to)
+}
+
+// PurchaseOrderStore is a mock of repository.PurchaseOrderStore
+type PurchaseOrderStore struct {
+	CheckInvoiceNumberExistsFunc func(ctx context.Context, tenantID, supplierID uuid.UUID, invoiceNumber string) (bool, error)
+	CreateFunc                   func(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error
+	CreateReceiptFunc            func(ctx context.Context, tenantID uuid.UUID, receipt *models.GoodsReceipt) error
+	CreateSupplierInvoiceFunc    func(ctx context.Context, tenantID uuid.UUID, invoice *models.SupplierInvoice) error
+	DeleteFunc                   func(ctx context.Context, tenantID, poID uuid.UUID) error
+	FindByIDFunc                 func(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	FindSupplierInvoiceFunc      func(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	ListFunc                     func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		supplierID *uuid.UUID,
+		status string,
+		limit, offset int,
+	) ([]models.PurchaseOrder, int, error)
+	ListPendingSupplierInvoicesFunc func(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.SupplierInvoice, error)
+	ListReceiptsFunc                func(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error)
+	ListSupplierInvoicesFunc        func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		poID *uuid.UUID,
+		matchStatus, status string,
+		limit, offset int,
+	) ([]models.SupplierInvoice, int, error)
+	UpdateFunc                      func(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error
+	UpdateStatusFunc                func(ctx context.Context, tenantID, poID uuid.UUID, fromStatus, toStatus string) error
+	UpdateSupplierInvoiceStatusFunc func(ctx context.Context, tenantID, invoiceID, userID uuid.UUID, status string) error
+}
+
+// CheckInvoiceNumberExists calls CheckInvoiceNumberExistsFunc
+func (mock *PurchaseOrderStore) CheckInvoiceNumberExists(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID, invoiceNumber string) (bool, error) {
+	if mock.CheckInvoiceNumberExistsFunc == nil {
+		panic("PurchaseOrderStore.CheckInvoiceNumberExists is not stubbed")
+	}
+	return mock.CheckInvoiceNumberExistsFunc(ctx, tenantID, supplierID, invoiceNumber)
+}
+
+// Create calls CreateFunc
+func (mock *PurchaseOrderStore) Create(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error {
+	if mock.CreateFunc == nil {
+		panic("PurchaseOrderStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, po)
+}
+
+// CreateReceipt calls CreateReceiptFunc
+func (mock *PurchaseOrderStore) CreateReceipt(ctx context.Context, tenantID uuid.UUID, receipt *models.GoodsReceipt) error {
+	if mock.CreateReceiptFunc == nil {
+		panic("PurchaseOrderStore.CreateReceipt is not stubbed")
+	}
+	return mock.CreateReceiptFunc(ctx, tenantID, receipt)
+}
+
+// CreateSupplierInvoice calls CreateSupplierInvoiceFunc
+func (mock *PurchaseOrderStore) CreateSupplierInvoice(ctx context.Context, tenantID uuid.UUID, invoice *models.SupplierInvoice) error {
+	if mock.CreateSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderStore.CreateSupplierInvoice is not stubbed")
+	}
+	return mock.CreateSupplierInvoiceFunc(ctx, tenantID, invoice)
+}
+
+// Delete calls DeleteFunc
+func (mock *PurchaseOrderStore) Delete(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("PurchaseOrderStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, poID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *PurchaseOrderStore) FindByID(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	if mock.FindByIDFunc == nil {
+		panic("PurchaseOrderStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, poID)
+}
+
+// FindSupplierInvoice calls FindSupplierInvoiceFunc
+func (mock *PurchaseOrderStore) FindSupplierInvoice(ctx context.Context, tenantID uuid.UUID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	if mock.FindSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderStore.FindSupplierInvoice is not stubbed")
+	}
+	return mock.FindSupplierInvoiceFunc(ctx, tenantID, invoiceID)
+}
+
+// List calls ListFunc
+func (mock *PurchaseOrderStore) List(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, status string, limit int, offset int) ([]models.PurchaseOrder, int, error) {
+	if mock.ListFunc == nil {
+		panic("PurchaseOrderStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, supplierID, status, limit, offset)
+}
+
+// ListPendingSupplierInvoices calls ListPendingSupplierInvoicesFunc
+func (mock *PurchaseOrderStore) ListPendingSupplierInvoices(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.SupplierInvoice, error) {
+	if mock.ListPendingSupplierInvoicesFunc == nil {
+		panic("PurchaseOrderStore.ListPendingSupplierInvoices is not stubbed")
+	}
+	return mock.ListPendingSupplierInvoicesFunc(ctx, tenantID, cutoff)
+}
+
+// ListReceipts calls ListReceiptsFunc
+func (mock *PurchaseOrderStore) ListReceipts(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) ([]models.GoodsReceipt, error) {
+	if mock.ListReceiptsFunc == nil {
+		panic("PurchaseOrderStore.ListReceipts is not stubbed")
+	}
+	return mock.ListReceiptsFunc(ctx, tenantID, poID)
+}
+
+// ListSupplierInvoices calls ListSupplierInvoicesFunc
+func (mock *PurchaseOrderStore) ListSupplierInvoices(ctx context.Context, tenantID uuid.UUID, poID *uuid.UUID, matchStatus string, status string, limit int, offset int) ([]models.SupplierInvoice, int, error) {
+	if mock.ListSupplierInvoicesFunc == nil {
+		panic("PurchaseOrderStore.ListSupplierInvoices is not stubbed")
+	}
+	return mock.ListSupplierInvoicesFunc(ctx, tenantID, poID, matchStatus, status, limit, offset)
+}
+
+// Update calls UpdateFunc
+func (mock *PurchaseOrderStore) Update(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error {
+	if mock.UpdateFunc == nil {
+		panic("PurchaseOrderStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, po)
+}
+
+// UpdateStatus calls UpdateStatusFunc
+func (mock *PurchaseOrderStore) UpdateStatus(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID, fromStatus string, toStatus string) error {
+	if mock.UpdateStatusFunc == nil {
+		panic("PurchaseOrderStore.UpdateStatus is not stubbed")
+	}
+	return mock.UpdateStatusFunc(ctx, tenantID, poID, fromStatus, toStatus)
+}
+
+// UpdateSupplierInvoiceStatus calls UpdateSupplierInvoiceStatusFunc
+func (mock *PurchaseOrderStore) UpdateSupplierInvoiceStatus(ctx context.Context, tenantID uuid.UUID, invoiceID uuid.UUID, userID uuid.UUID, status string) error {
+	if mock.UpdateSupplierInvoiceStatusFunc == nil {
+		panic("PurchaseOrderStore.UpdateSupplierInvoiceStatus is not stubbed")
+	}
+	return mock.UpdateSupplierInvoiceStatusFunc(ctx, tenantID, invoiceID, userID, status)
+}
+
+// QuotaStore is a mock of repository.QuotaStore
+type QuotaStore struct {
+	AcknowledgeFunc    func(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.QuotaAlert, error)
+	CountUsersFunc     func(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	FindAlertFunc      func(ctx context.Context, tenantID, id uuid.UUID) (*models.QuotaAlert, error)
+	ListOpenAlertsFunc func(ctx context.Context, tenantID uuid.UUID) ([]models.QuotaAlert, error)
+	MarkNotifiedFunc   func(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, alertIDs []uuid.UUID, notifiedAt time.Time) error
+	MeasureStorageFunc func(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	OpenAlertFunc      func(ctx context.Context, alert *models.QuotaAlert) (bool, error)
+	ResolveAlertsFunc  func(ctx context.Context, tenantID uuid.UUID, metric string, percent int) (int, error)
+}
+
+// Acknowledge calls AcknowledgeFunc
+func (mock *QuotaStore) Acknowledge(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, userID uuid.UUID) (*models.QuotaAlert, error) {
+	if mock.AcknowledgeFunc == nil {
+		panic("QuotaStore.Acknowledge is not stubbed")
+	}
+	return mock.AcknowledgeFunc(ctx, tenantID, id, userID)
+}
+
+// CountUsers calls CountUsersFunc
+func (mock *QuotaStore) CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	if mock.CountUsersFunc == nil {
+		panic("QuotaStore.CountUsers is not stubbed")
+	}
+	return mock.CountUsersFunc(ctx, tenantID)
+}
+
+// FindAlert calls FindAlertFunc
+func (mock *QuotaStore) FindAlert(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.QuotaAlert, error) {
+	if mock.FindAlertFunc == nil {
+		panic("QuotaStore.FindAlert is not stubbed")
+	}
+	return mock.FindAlertFunc(ctx, tenantID, id)
+}
+
+// ListOpenAlerts calls ListOpenAlertsFunc
+func (mock *QuotaStore) ListOpenAlerts(ctx context.Context, tenantID uuid.UUID) ([]models.QuotaAlert, error) {
+	if mock.ListOpenAlertsFunc == nil {
+		panic("QuotaStore.ListOpenAlerts is not stubbed")
+	}
+	return mock.ListOpenAlertsFunc(ctx, tenantID)
+}
+
+// MarkNotified calls MarkNotifiedFunc
+func (mock *QuotaStore) MarkNotified(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, alertIDs []uuid.UUID, notifiedAt time.Time) error {
+	if mock.MarkNotifiedFunc == nil {
+		panic("QuotaStore.MarkNotified is not stubbed")
+	}
+	return mock.MarkNotifiedFunc(ctx, tx, tenantID, alertIDs, notifiedAt)
+}
+
+// MeasureStorage calls MeasureStorageFunc
+func (mock *QuotaStore) MeasureStorage(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	if mock.MeasureStorageFunc == nil {
+		panic("QuotaStore.MeasureStorage is not stubbed")
+	}
+	return mock.MeasureStorageFunc(ctx, tenantID)
+}
+
+// OpenAlert calls OpenAlertFunc
+func (mock *QuotaStore) OpenAlert(ctx context.Context, alert *models.QuotaAlert) (bool, error) {
+	if mock.OpenAlertFunc == nil {
+		panic("QuotaStore.OpenAlert is not stubbed")
+	}
+	return mock.OpenAlertFunc(ctx, alert)
+}
+
+// ResolveAlerts calls ResolveAlertsFunc
+func (mock *QuotaStore) ResolveAlerts(ctx context.Context, tenantID uuid.UUID, metric string, percent int) (int, error) {
+	if mock.ResolveAlertsFunc == nil {
+		panic("QuotaStore.ResolveAlerts is not stubbed")
+	}
+	return mock.ResolveAlertsFunc(ctx, tenantID, metric, percent)
+}
+
+// RequestStatStore is a mock of repository.RequestStatStore
+type RequestStatStore struct {
+	AddFunc          func(ctx context.Context, stats []metrics.Stat) error
+	DeleteBeforeFunc func(ctx context.Context, before time.Time) (int, error)
+	TopEndpointsFunc func(ctx context.Context, since time.Time, sort string, limit int) ([]models.EndpointStat, error)
+	TopQueriesFunc   func(ctx context.Context, since time.Time, sort string, limit int) ([]models.QueryStat, error)
+}
+
+// Add calls AddFunc
+func (mock *RequestStatStore) Add(ctx context.Context, stats []metrics.Stat) error {
+	if mock.AddFunc == nil {
+		panic("RequestStatStore.Add is not stubbed")
+	}
+	return mock.AddFunc(ctx, stats)
+}
+
+// DeleteBefore calls DeleteBeforeFunc
+func (mock *RequestStatStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	if mock.DeleteBeforeFunc == nil {
+		panic("RequestStatStore.DeleteBefore is not stubbed")
+	}
+	return mock.DeleteBeforeFunc(ctx, before)
+}
+
+// TopEndpoints calls TopEndpointsFunc
+func (mock *RequestStatStore) TopEndpoints(ctx context.Context, since time.Time, sort string, limit int) ([]models.EndpointStat, error) {
+	if mock.TopEndpointsFunc == nil {
+		panic("RequestStatStore.TopEndpoints is not stubbed")
+	}
+	return mock.TopEndpointsFunc(ctx, since, sort, limit)
+}
+
+// TopQueries calls TopQueriesFunc
+func (mock *RequestStatStore) TopQueries(ctx context.Context, since time.Time, sort string, limit int) ([]models.QueryStat, error) {
+	if mock.TopQueriesFunc == nil {
+		panic("RequestStatStore.TopQueries is not stubbed")
+	}
+	return mock.TopQueriesFunc(ctx, since, sort, limit)
+}
+
+// RoleStore is a mock of repository.RoleStore
+type RoleStore struct {
+	AssignPermissionsFunc      func(ctx context.Context, tenantID, roleID uuid.UUID, permissionIDs, deniedPermissionIDs []uuid.UUID, assignedBy uuid.UUID) error
+	CheckNameExistsFunc        func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CountUsersFunc             func(ctx context.Context, tenantID, roleID uuid.UUID) (int, error)
+	CountUsersByRoleFunc       func(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	CreateFunc                 func(ctx context.Context, tenantID uuid.UUID, role *models.Role) error
+	DeleteFunc                 func(ctx context.Context, tenantID, roleID uuid.UUID) error
+	FindByIDFunc               func(ctx context.Context, tenantID, roleID uuid.UUID) (*models.Role, error)
+	FindByNameFunc             func(ctx context.Context, tenantID uuid.UUID, name string) (*models.Role, error)
+	GetDescendantIDsFunc       func(ctx context.Context, tenantID, roleID uuid.UUID) ([]uuid.UUID, error)
+	GetPermissionsFunc         func(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.Permission, error)
+	GetPermissionsForRolesFunc func(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID][]models.Permission, error)
+	GetRoleWithDetailsFunc     func(ctx context.Context, tenantID, roleID uuid.UUID) (*models.Role, error)
+	ListFunc                   func(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListCustomRolesFunc        func(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListSystemRolesFunc        func(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	ListWithDetailsFunc        func(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error)
+	UpdateFunc                 func(ctx context.Context, tenantID uuid.UUID, role *models.Role) error
+}
+
+// AssignPermissions calls AssignPermissionsFunc
+func (mock *RoleStore) AssignPermissions(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID, permissionIDs []uuid.UUID, deniedPermissionIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	if mock.AssignPermissionsFunc == nil {
+		panic("RoleStore.AssignPermissions is not stubbed")
+	}
+	return mock.AssignPermissionsFunc(ctx, tenantID, roleID, permissionIDs, deniedPermissionIDs, assignedBy)
+}
+
+// CheckNameExists calls CheckNameExistsFunc
+func (mock *RoleStore) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNameExistsFunc == nil {
+		panic("RoleStore.CheckNameExists is not stubbed")
+	}
+	return mock.CheckNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// CountUsers calls CountUsersFunc
+func (mock *RoleStore) CountUsers(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) (int, error) {
+	if mock.CountUsersFunc == nil {
+		panic("RoleStore.CountUsers is not stubbed")
+	}
+	return mock.CountUsersFunc(ctx, tenantID, roleID)
+}
+
+// CountUsersByRole calls CountUsersByRoleFunc
+func (mock *RoleStore) CountUsersByRole(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	if mock.CountUsersByRoleFunc == nil {
+		panic("RoleStore.CountUsersByRole is not stubbed")
+	}
+	return mock.CountUsersByRoleFunc(ctx, tenantID, roleIDs)
+}
+
+// Create calls CreateFunc
+func (mock *RoleStore) Create(ctx context.Context, tenantID uuid.UUID, role *models.Role) error {
+	if mock.CreateFunc == nil {
+		panic("RoleStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, role)
+}
+
+// Delete calls DeleteFunc
+func (mock *RoleStore) Delete(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("RoleStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, roleID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *RoleStore) FindByID(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) (*models.Role, error) {
+	if mock.FindByIDFunc == nil {
+		panic("RoleStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, roleID)
+}
+
+// FindByName calls FindByNameFunc
+func (mock *RoleStore) FindByName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Role, error) {
+	if mock.FindByNameFunc == nil {
+		panic("RoleStore.FindByName is not stubbed")
+	}
+	return mock.FindByNameFunc(ctx, tenantID, name)
+}
+
+// GetDescendantIDs calls GetDescendantIDsFunc
+func (mock *RoleStore) GetDescendantIDs(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) ([]uuid.UUID, error) {
+	if mock.GetDescendantIDsFunc == nil {
+		panic("RoleStore.GetDescendantIDs is not stubbed")
+	}
+	return mock.GetDescendantIDsFunc(ctx, tenantID, roleID)
+}
+
+// GetPermissions calls GetPermissionsFunc
+func (mock *RoleStore) GetPermissions(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) ([]models.Permission, error) {
+	if mock.GetPermissionsFunc == nil {
+		panic("RoleStore.GetPermissions is not stubbed")
+	}
+	return mock.GetPermissionsFunc(ctx, tenantID, roleID)
+}
+
+// GetPermissionsForRoles calls GetPermissionsForRolesFunc
+func (mock *RoleStore) GetPermissionsForRoles(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID][]models.Permission, error) {
+	if mock.GetPermissionsForRolesFunc == nil {
+		panic("RoleStore.GetPermissionsForRoles is not stubbed")
+	}
+	return mock.GetPermissionsForRolesFunc(ctx, tenantID, roleIDs)
+}
+
+// GetRoleWithDetails calls GetRoleWithDetailsFunc
+func (mock *RoleStore) GetRoleWithDetails(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) (*models.Role, error) {
+	if mock.GetRoleWithDetailsFunc == nil {
+		panic("RoleStore.GetRoleWithDetails is not stubbed")
+	}
+	return mock.GetRoleWithDetailsFunc(ctx, tenantID, roleID)
+}
+
+// List calls ListFunc
+func (mock *RoleStore) List(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
+	if mock.ListFunc == nil {
+		panic("RoleStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID)
+}
+
+// ListCustomRoles calls ListCustomRolesFunc
+func (mock *RoleStore) ListCustomRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
+	if mock.ListCustomRolesFunc == nil {
+		panic("RoleStore.ListCustomRoles is not stubbed")
+	}
+	return mock.ListCustomRolesFunc(ctx, tenantID)
+}
+
+// ListSystemRoles calls ListSystemRolesFunc
+func (mock *RoleStore) ListSystemRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
+	if mock.ListSystemRolesFunc == nil {
+		panic("RoleStore.ListSystemRoles is not stubbed")
+	}
+	return mock.ListSystemRolesFunc(ctx, tenantID)
+}
+
+// ListWithDetails calls ListWithDetailsFunc
+func (mock *RoleStore) ListWithDetails(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
+	if mock.ListWithDetailsFunc == nil {
+		panic("RoleStore.ListWithDetails is not stubbed")
+	}
+	return mock.ListWithDetailsFunc(ctx, tenantID)
+}
+
+// Update calls UpdateFunc
+func (mock *RoleStore) Update(ctx context.Context, tenantID uuid.UUID, role *models.Role) error {
+	if mock.UpdateFunc == nil {
+		panic("RoleStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, role)
+}
+
+// SIEMForwarderStore is a mock of repository.SIEMForwarderStore
+type SIEMForwarderStore struct {
+	ClaimDueFunc          func(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID, dueBy time.Time) (*models.SIEMForwarder, error)
+	CountEventsBeforeFunc func(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, cutoff time.Time) (int, error)
+	CreateFunc            func(ctx context.Context, forwarder *models.SIEMForwarder) error
+	DeleteFunc            func(ctx context.Context, tenantID, forwarderID uuid.UUID) error
+	FindByIDFunc          func(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error)
+	ListFunc              func(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error)
+	ListEventsFunc        func(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, until time.Time, limit int) ([]models.SIEMEvent, error)
+	SaveProgressFunc      func(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder) error
+	UpdateFunc            func(ctx context.Context, forwarder *models.SIEMForwarder) error
+}
+
+// ClaimDue calls ClaimDueFunc
+func (mock *SIEMForwarderStore) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID, dueBy time.Time) (*models.SIEMForwarder, error) {
+	if mock.ClaimDueFunc == nil {
+		panic("SIEMForwarderStore.ClaimDue is not stubbed")
+	}
+	return mock.ClaimDueFunc(ctx, tx, paused, dueBy)
+}
+
+// CountEventsBefore calls CountEventsBeforeFunc
+func (mock *SIEMForwarderStore) CountEventsBefore(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, cutoff time.Time) (int, error) {
+	if mock.CountEventsBeforeFunc == nil {
+		panic("SIEMForwarderStore.CountEventsBefore is not stubbed")
+	}
+	return mock.CountEventsBeforeFunc(ctx, tx, forwarder, cutoff)
+}
+
+// Create calls CreateFunc
+func (mock *SIEMForwarderStore) Create(ctx context.Context, forwarder *models.SIEMForwarder) error {
+	if mock.CreateFunc == nil {
+		panic("SIEMForwarderStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, forwarder)
+}
+
+// Delete calls DeleteFunc
+func (mock *SIEMForwarderStore) Delete(ctx context.Context, tenantID uuid.UUID, forwarderID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("SIEMForwarderStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, forwarderID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *SIEMForwarderStore) FindByID(ctx context.Context, tenantID uuid.UUID, forwarderID uuid.UUID) (*models.SIEMForwarder, error) {
+	if mock.FindByIDFunc == nil {
+		panic("SIEMForwarderStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, forwarderID)
+}
+
+// List calls ListFunc
+func (mock *SIEMForwarderStore) List(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error) {
+	if mock.ListFunc == nil {
+		panic("SIEMForwarderStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID)
+}
+
+// ListEvents calls ListEventsFunc
+func (mock *SIEMForwarderStore) ListEvents(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, until time.Time, limit int) ([]models.SIEMEvent, error) {
+	if mock.ListEventsFunc == nil {
+		panic("SIEMForwarderStore.ListEvents is not stubbed")
+	}
+	return mock.ListEventsFunc(ctx, tx, forwarder, until, limit)
+}
+
+// SaveProgress calls SaveProgressFunc
+func (mock *SIEMForwarderStore) SaveProgress(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder) error {
+	if mock.SaveProgressFunc == nil {
+		panic("SIEMForwarderStore.SaveProgress is not stubbed")
+	}
+	return mock.SaveProgressFunc(ctx, tx, forwarder)
+}
+
+// Update calls UpdateFunc
+func (mock *SIEMForwarderStore) Update(ctx context.Context, forwarder *models.SIEMForwarder) error {
+	if mock.UpdateFunc == nil {
+		panic("SIEMForwarderStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, forwarder)
+}
+
+// SSOStore is a mock of repository.SSOStore
+type SSOStore struct {
+	CountEnabledProvidersFunc func(ctx context.Context, tenantID uuid.UUID) (int, error)
+	CreateProviderFunc        func(ctx context.Context, provider *models.SSOProvider) error
+	DeleteProviderFunc        func(ctx context.Context, tenantID, id uuid.UUID) error
+	FindIdentityFunc          func(ctx context.Context, tenantID, providerID uuid.UUID, subject string) (*models.SSOIdentity, error)
+	FindProviderFunc          func(ctx context.Context, tenantID, id uuid.UUID) (*models.SSOProvider, error)
+	FindProviderBySlugFunc    func(ctx context.Context, tenantID uuid.UUID, slug string) (*models.SSOProvider, error)
+	LinkIdentityFunc          func(ctx context.Context, identity *models.SSOIdentity) error
+	ListProvidersFunc         func(ctx context.Context, tenantID uuid.UUID) ([]models.SSOProvider, error)
+	TouchIdentityFunc         func(ctx context.Context, tenantID, identityID uuid.UUID, email string) error
+	UpdateProviderFunc        func(ctx context.Context, provider *models.SSOProvider) error
+}
+
+// CountEnabledProviders calls CountEnabledProvidersFunc
+func (mock *SSOStore) CountEnabledProviders(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	if mock.CountEnabledProvidersFunc == nil {
+		panic("SSOStore.CountEnabledProviders is not stubbed")
+	}
+	return mock.CountEnabledProvidersFunc(ctx, tenantID)
+}
+
+// CreateProvider calls CreateProviderFunc
+func (mock *SSOStore) CreateProvider(ctx context.Context, provider *models.SSOProvider) error {
+	if mock.CreateProviderFunc == nil {
+		panic("SSOStore.CreateProvider is not stubbed")
+	}
+	return mock.CreateProviderFunc(ctx, provider)
+}
+
+// DeleteProvider calls DeleteProviderFunc
+func (mock *SSOStore) DeleteProvider(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error {
+	if mock.DeleteProviderFunc == nil {
+		panic("SSOStore.DeleteProvider is not stubbed")
+	}
+	return mock.DeleteProviderFunc(ctx, tenantID, id)
+}
+
+// FindIdentity calls FindIdentityFunc
+func (mock *SSOStore) FindIdentity(ctx context.Context, tenantID uuid.UUID, providerID uuid.UUID, subject string) (*models.SSOIdentity, error) {
+	if mock.FindIdentityFunc == nil {
+		panic("SSOStore.FindIdentity is not stubbed")
+	}
+	return mock.FindIdentityFunc(ctx, tenantID, providerID, subject)
+}
+
+// FindProvider calls FindProviderFunc
+func (mock *SSOStore) FindProvider(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.SSOProvider, error) {
+	if mock.FindProviderFunc == nil {
+		panic("SSOStore.FindProvider is not stubbed")
+	}
+	return mock.FindProviderFunc(ctx, tenantID, id)
+}
+
+// FindProviderBySlug calls FindProviderBySlugFunc
+func (mock *SSOStore) FindProviderBySlug(ctx context.Context, tenantID uuid.UUID, slug string) (*models.SSOProvider, error) {
+	if mock.FindProviderBySlugFunc == nil {
+		panic("SSOStore.FindProviderBySlug is not stubbed")
+	}
+	return mock.FindProviderBySlugFunc(ctx, tenantID, slug)
+}
+
+// LinkIdentity calls LinkIdentityFunc
+func (mock *SSOStore) LinkIdentity(ctx context.Context, identity *models.SSOIdentity) error {
+	if mock.LinkIdentityFunc == nil {
+		panic("SSOStore.LinkIdentity is not stubbed")
+	}
+	return mock.LinkIdentityFunc(ctx, identity)
+}
+
+// ListProviders calls ListProvidersFunc
+func (mock *SSOStore) ListProviders(ctx context.Context, tenantID uuid.UUID) ([]models.SSOProvider, error) {
+	if mock.ListProvidersFunc == nil {
+		panic("SSOStore.ListProviders is not stubbed")
+	}
+	return mock.ListProvidersFunc(ctx, tenantID)
+}
+
+// TouchIdentity calls TouchIdentityFunc
+func (mock *SSOStore) TouchIdentity(ctx context.Context, tenantID uuid.UUID, identityID uuid.UUID, email string) error {
+	if mock.TouchIdentityFunc == nil {
+		panic("SSOStore.TouchIdentity is not stubbed")
+	}
+	return mock.TouchIdentityFunc(ctx, tenantID, identityID, email)
+}
+
+// UpdateProvider calls UpdateProviderFunc
+func (mock *SSOStore) UpdateProvider(ctx context.Context, provider *models.SSOProvider) error {
+	if mock.UpdateProviderFunc == nil {
+		panic("SSOStore.UpdateProvider is not stubbed")
+	}
+	return mock.UpdateProviderFunc(ctx, provider)
+}
+
+// SalesStore is a mock of repository.SalesStore
+type SalesStore struct {
+	CreateFunc       func(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error
+	DeleteFunc       func(ctx context.Context, tenantID, docID uuid.UUID) error
+	FindByIDFunc     func(ctx context.Context, tenantID, docID uuid.UUID) (*models.SalesDocument, error)
+	FindBySourceFunc func(ctx context.Context, tenantID, sourceID uuid.UUID) ([]models.SalesDocument, error)
+	ListFunc         func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.SalesDocumentFilter,
+		limit, offset int,
+	) ([]models.SalesDocument, int, error)
+	LockFunc         func(ctx context.Context, tenantID, docID uuid.UUID) error
+	UpdateFunc       func(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error
+	UpdateStatusFunc func(ctx context.Context, tenantID, docID uuid.UUID, fromStatus, toStatus string) error
+}
+
+// Create calls CreateFunc
+func (mock *SalesStore) Create(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error {
+	if mock.CreateFunc == nil {
+		panic("SalesStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, doc)
+}
+
+// Delete calls DeleteFunc
+func (mock *SalesStore) Delete(ctx context.Context, tenantID uuid.UUID, docID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("SalesStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, docID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *SalesStore) FindByID(ctx context.Context, tenantID uuid.UUID, docID uuid.UUID) (*models.SalesDocument, error) {
+	if mock.FindByIDFunc == nil {
+		panic("SalesStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, docID)
+}
+
+// FindBySource calls FindBySourceFunc
+func (mock *SalesStore) FindBySource(ctx context.Context, tenantID uuid.UUID, sourceID uuid.UUID) ([]models.SalesDocument, error) {
+	if mock.FindBySourceFunc == nil {
+		panic("SalesStore.FindBySource is not stubbed")
+	}
+	return mock.FindBySourceFunc(ctx, tenantID, sourceID)
+}
+
+// List calls ListFunc
+func (mock *SalesStore) List(ctx context.Context, tenantID uuid.UUID, filter models.SalesDocumentFilter, limit int, offset int) ([]models.SalesDocument, int, error) {
+	if mock.ListFunc == nil {
+		panic("SalesStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// Lock calls LockFunc
+func (mock *SalesStore) Lock(ctx context.Context, tenantID uuid.UUID, docID uuid.UUID) error {
+	if mock.LockFunc == nil {
+		panic("SalesStore.Lock is not stubbed")
+	}
+	return mock.LockFunc(ctx, tenantID, docID)
+}
+
+// Update calls UpdateFunc
+func (mock *SalesStore) Update(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error {
+	if mock.UpdateFunc == nil {
+		panic("SalesStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, doc)
+}
+
+// UpdateStatus calls UpdateStatusFunc
+func (mock *SalesStore) UpdateStatus(ctx context.Context, tenantID uuid.UUID, docID uuid.UUID, fromStatus string, toStatus string) error {
+	if mock.UpdateStatusFunc == nil {
+		panic("SalesStore.UpdateStatus is not stubbed")
+	}
+	return mock.UpdateStatusFunc(ctx, tenantID, docID, fromStatus, toStatus)
+}
+
+// SandboxStore is a mock of repository.SandboxStore
+type SandboxStore struct {
+	AnonymizeFunc   func(ctx context.Context, tx *sqlx.Tx, tenantID, keepUserID uuid.UUID) error
+	ClaimNextFunc   func(ctx context.Context) (*models.TenantSandbox, error)
+	CountActiveFunc func(ctx context.Context, sourceTenantID uuid.UUID) (int, error)
+	CreateFunc      func(ctx context.Context, tenant *models.Tenant, sandbox *models.TenantSandbox) error
+	FindByIDFunc    func(ctx context.Context, sourceTenantID, id uuid.UUID) (*models.TenantSandbox, error)
+	ListFunc        func(ctx context.Context, sourceTenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error)
+	ListExpiredFunc func(ctx context.Context, limit int) ([]models.TenantSandbox, error)
+	MarkDeletedFunc func(ctx context.Context, id uuid.UUID) error
+	MarkFailedFunc  func(ctx context.Context, id uuid.UUID, errMsg string) error
+	MarkReadyFunc   func(ctx context.Context, sandbox *models.TenantSandbox) error
+}
+
+// Anonymize calls AnonymizeFunc
+func (mock *SandboxStore) Anonymize(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, keepUserID uuid.UUID) error {
+	if mock.AnonymizeFunc == nil {
+		panic("SandboxStore.Anonymize is not stubbed")
+	}
+	return mock.AnonymizeFunc(ctx, tx, tenantID, keepUserID)
+}
+
+// ClaimNext calls ClaimNextFunc
+func (mock *SandboxStore) ClaimNext(ctx context.Context) (*models.TenantSandbox, error) {
+	if mock.ClaimNextFunc == nil {
+		panic("SandboxStore.ClaimNext is not stubbed")
+	}
+	return mock.ClaimNextFunc(ctx)
+}
+
+// CountActive calls CountActiveFunc
+func (mock *SandboxStore) CountActive(ctx context.Context, sourceTenantID uuid.UUID) (int, error) {
+	if mock.CountActiveFunc == nil {
+		panic("SandboxStore.CountActive is not stubbed")
+	}
+	return mock.CountActiveFunc(ctx, sourceTenantID)
+}
+
+// Create calls CreateFunc
+func (mock *SandboxStore) Create(ctx context.Context, tenant *models.Tenant, sandbox *models.TenantSandbox) error {
+	if mock.CreateFunc == nil {
+		panic("SandboxStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenant, sandbox)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *SandboxStore) FindByID(ctx context.Context, sourceTenantID uuid.UUID, id uuid.UUID) (*models.TenantSandbox, error) {
+	if mock.FindByIDFunc == nil {
+		panic("SandboxStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, sourceTenantID, id)
+}
+
+// List calls ListFunc
+func (mock *SandboxStore) List(ctx context.Context, sourceTenantID uuid.UUID, includeDeleted bool, limit int, offset int) ([]models.TenantSandbox, int, error) {
+	if mock.ListFunc == nil {
+		panic("SandboxStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, sourceTenantID, includeDeleted, limit, offset)
+}
+
+// ListExpired calls ListExpiredFunc
+func (mock *SandboxStore) ListExpired(ctx context.Context, limit int) ([]models.TenantSandbox, error) {
+	if mock.ListExpiredFunc == nil {
+		panic("SandboxStore.ListExpired is not stubbed")
+	}
+	return mock.ListExpiredFunc(ctx, limit)
+}
+
+// MarkDeleted calls MarkDeletedFunc
+func (mock *SandboxStore) MarkDeleted(ctx context.Context, id uuid.UUID) error {
+	if mock.MarkDeletedFunc == nil {
+		panic("SandboxStore.MarkDeleted is not stubbed")
+	}
+	return mock.MarkDeletedFunc(ctx, id)
+}
+
+// MarkFailed calls MarkFailedFunc
+func (mock *SandboxStore) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error {
+	if mock.MarkFailedFunc == nil {
+		panic("SandboxStore.MarkFailed is not stubbed")
+	}
+	return mock.MarkFailedFunc(ctx, id, errMsg)
+}
+
+// MarkReady calls MarkReadyFunc
+func (mock *SandboxStore) MarkReady(ctx context.Context, sandbox *models.TenantSandbox) error {
+	if mock.MarkReadyFunc == nil {
+		panic("SandboxStore.MarkReady is not stubbed")
+	}
+	return mock.MarkReadyFunc(ctx, sandbox)
+}
+
+// ScheduledReportStore is a mock of repository.ScheduledReportStore
+type ScheduledReportStore struct {
+	ClaimDueFunc         func(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ScheduledReport, error)
+	CreateFunc           func(ctx context.Context, report *models.ScheduledReport) error
+	DeleteFunc           func(ctx context.Context, tenantID, reportID uuid.UUID) error
+	FindByIDFunc         func(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error)
+	ListFunc             func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error)
+	ListActiveUsersFunc  func(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportActiveUser, error)
+	ListAuditActionsFunc func(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportAuditAction, error)
+	ListFailedLoginsFunc func(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportFailedLogin, error)
+	ListLoginDaysFunc    func(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportLoginDay, error)
+	RecordRunFunc        func(ctx context.Context, tenantID, reportID uuid.UUID, periodEnd time.Time, runErr error) error
+	RescheduleFunc       func(ctx context.Context, tx *sqlx.Tx, report *models.ScheduledReport) error
+	UpdateFunc           func(ctx context.Context, report *models.ScheduledReport) error
+}
+
+// ClaimDue calls ClaimDueFunc
+func (mock *ScheduledReportStore) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ScheduledReport, error) {
+	if mock.ClaimDueFunc == nil {
+		panic("ScheduledReportStore.ClaimDue is not stubbed")
+	}
+	return mock.ClaimDueFunc(ctx, tx, paused)
+}
+
+// Create calls CreateFunc
+func (mock *ScheduledReportStore) Create(ctx context.Context, report *models.ScheduledReport) error {
+	if mock.CreateFunc == nil {
+		panic("ScheduledReportStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, report)
+}
+
+// Delete calls DeleteFunc
+func (mock *ScheduledReportStore) Delete(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("ScheduledReportStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, reportID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *ScheduledReportStore) FindByID(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID) (*models.ScheduledReport, error) {
+	if mock.FindByIDFunc == nil {
+		panic("ScheduledReportStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, reportID)
+}
+
+// List calls ListFunc
+func (mock *ScheduledReportStore) List(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.ScheduledReport, int, error) {
+	if mock.ListFunc == nil {
+		panic("ScheduledReportStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, limit, offset)
+}
+
+// ListActiveUsers calls ListActiveUsersFunc
+func (mock *ScheduledReportStore) ListActiveUsers(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time, limit int) ([]models.ReportActiveUser, error) {
+	if mock.ListActiveUsersFunc == nil {
+		panic("ScheduledReportStore.ListActiveUsers is not stubbed")
+	}
+	return mock.ListActiveUsersFunc(ctx, tenantID, start, end, limit)
+}
+
+// ListAuditActions calls ListAuditActionsFunc
+func (mock *ScheduledReportStore) ListAuditActions(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time) ([]models.ReportAuditAction, error) {
+	if mock.ListAuditActionsFunc == nil {
+		panic("ScheduledReportStore.ListAuditActions is not stubbed")
+	}
+	return mock.ListAuditActionsFunc(ctx, tenantID, start, end)
+}
+
+// ListFailedLogins calls ListFailedLoginsFunc
+func (mock *ScheduledReportStore) ListFailedLogins(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time, limit int) ([]models.ReportFailedLogin, error) {
+	if mock.ListFailedLoginsFunc == nil {
+		panic("ScheduledReportStore.ListFailedLogins is not stubbed")
+	}
+	return mock.ListFailedLoginsFunc(ctx, tenantID, start, end, limit)
+}
+
+// ListLoginDays calls ListLoginDaysFunc
+func (mock *ScheduledReportStore) ListLoginDays(ctx context.Context, tenantID uuid.UUID, start time.Time, end time.Time) ([]models.ReportLoginDay, error) {
+	if mock.ListLoginDaysFunc == nil {
+		panic("ScheduledReportStore.ListLoginDays is not stubbed")
+	}
+	return mock.ListLoginDaysFunc(ctx, tenantID, start, end)
+}
+
+// RecordRun calls RecordRunFunc
+func (mock *ScheduledReportStore) RecordRun(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID, periodEnd time.Time, runErr error) error {
+	if mock.RecordRunFunc == nil {
+		panic("ScheduledReportStore.RecordRun is not stubbed")
+	}
+	return mock.RecordRunFunc(ctx, tenantID, reportID, periodEnd, runErr)
+}
+
+// Reschedule calls RescheduleFunc
+func (mock *ScheduledReportStore) Reschedule(ctx context.Context, tx *sqlx.Tx, report *models.ScheduledReport) error {
+	if mock.RescheduleFunc == nil {
+		panic("ScheduledReportStore.Reschedule is not stubbed")
+	}
+	return mock.RescheduleFunc(ctx, tx, report)
+}
+
+// Update calls UpdateFunc
+func (mock *ScheduledReportStore) Update(ctx context.Context, report *models.ScheduledReport) error {
+	if mock.UpdateFunc == nil {
+		panic("ScheduledReportStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, report)
+}
+
+// SearchStore is a mock of repository.SearchStore
+type SearchStore struct {
+	SearchCustomersFunc   func(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchDepartmentsFunc func(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchProductsFunc    func(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchRolesFunc       func(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+	SearchUsersFunc       func(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error)
+}
+
+// SearchCustomers calls SearchCustomersFunc
+func (mock *SearchStore) SearchCustomers(ctx context.Context, tenantID uuid.UUID, query string, config string, limit int) ([]models.SearchResult, error) {
+	if mock.SearchCustomersFunc == nil {
+		panic("SearchStore.SearchCustomers is not stubbed")
+	}
+	return mock.SearchCustomersFunc(ctx, tenantID, query, config, limit)
+}
+
+// SearchDepartments calls SearchDepartmentsFunc
+func (mock *SearchStore) SearchDepartments(ctx context.Context, tenantID uuid.UUID, query string, config string, limit int) ([]models.SearchResult, error) {
+	if mock.SearchDepartmentsFunc == nil {
+		panic("SearchStore.SearchDepartments is not stubbed")
+	}
+	return mock.SearchDepartmentsFunc(ctx, tenantID, query, config, limit)
+}
+
+// SearchProducts calls SearchProductsFunc
+func (mock *SearchStore) SearchProducts(ctx context.Context, tenantID uuid.UUID, query string, config string, limit int) ([]models.SearchResult, error) {
+	if mock.SearchProductsFunc == nil {
+		panic("SearchStore.SearchProducts is not stubbed")
+	}
+	return mock.SearchProductsFunc(ctx, tenantID, query, config, limit)
+}
+
+// SearchRoles calls SearchRolesFunc
+func (mock *SearchStore) SearchRoles(ctx context.Context, tenantID uuid.UUID, query string, config string, limit int) ([]models.SearchResult, error) {
+	if mock.SearchRolesFunc == nil {
+		panic("SearchStore.SearchRoles is not stubbed")
+	}
+	return mock.SearchRolesFunc(ctx, tenantID, query, config, limit)
+}
+
+// SearchUsers calls SearchUsersFunc
+func (mock *SearchStore) SearchUsers(ctx context.Context, tenantID uuid.UUID, query string, config string, limit int) ([]models.SearchResult, error) {
+	if mock.SearchUsersFunc == nil {
+		panic("SearchStore.SearchUsers is not stubbed")
+	}
+	return mock.SearchUsersFunc(ctx, tenantID, query, config, limit)
+}
+
+// SessionStore is a mock of repository.SessionStore
+type SessionStore struct {
+	CountActiveSessionsFunc    func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	CreateFunc                 func(ctx context.Context, req *models.SessionCreateRequest) (*models.Session, []uuid.UUID, error)
+	DeleteFunc                 func(ctx context.Context, tenantID, sessionID uuid.UUID) error
+	DeleteAllByUserFunc        func(ctx context.Context, tenantID, userID uuid.UUID) error
+	DeleteByTokenHashFunc      func(ctx context.Context, tenantID uuid.UUID, tokenHash string) error
+	DeleteExpiredSessionsFunc  func(ctx context.Context, tenantID uuid.UUID) (int64, error)
+	DeleteInactiveSessionsFunc func(ctx context.Context, tenantID uuid.UUID, inactivityDuration time.Duration) (int64, error)
+	FindByIDFunc               func(ctx context.Context, tenantID, sessionID uuid.UUID) (*models.Session, error)
+	FindByTokenHashFunc        func(ctx context.Context, tenantID uuid.UUID, tokenHash string) (*models.Session, error)
+	GetSessionStatsFunc        func(ctx context.Context, tenantID uuid.UUID) (map[string]int, error)
+	ListActiveSessionsFunc     func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error)
+	ListByUserFunc             func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error)
+	UpdateActivityFunc         func(ctx context.Context, tenantID, sessionID uuid.UUID) error
+	UpdateActivityBatchFunc    func(ctx context.Context, tenantID uuid.UUID, activity map[uuid.UUID]time.Time) error
+}
+
+// CountActiveSessions calls CountActiveSessionsFunc
+func (mock *SessionStore) CountActiveSessions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.CountActiveSessionsFunc == nil {
+		panic("SessionStore.CountActiveSessions is not stubbed")
+	}
+	return mock.CountActiveSessionsFunc(ctx, tenantID, userID)
+}
+
+// Create calls CreateFunc
+func (mock *SessionStore) Create(ctx context.Context, req *models.SessionCreateRequest) (*models.Session, []uuid.UUID, error) {
+	if mock.CreateFunc == nil {
+		panic("SessionStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, req)
+}
+
+// Delete calls DeleteFunc
+func (mock *SessionStore) Delete(ctx context.Context, tenantID uuid.UUID, sessionID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("SessionStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, sessionID)
+}
+
+// DeleteAllByUser calls DeleteAllByUserFunc
+func (mock *SessionStore) DeleteAllByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.DeleteAllByUserFunc == nil {
+		panic("SessionStore.DeleteAllByUser is not stubbed")
+	}
+	return mock.DeleteAllByUserFunc(ctx, tenantID, userID)
+}
+
+// DeleteByTokenHash calls DeleteByTokenHashFunc
+func (mock *SessionStore) DeleteByTokenHash(ctx context.Context, tenantID uuid.UUID, tokenHash string) error {
+	if mock.DeleteByTokenHashFunc == nil {
+		panic("SessionStore.DeleteByTokenHash is not stubbed")
+	}
+	return mock.DeleteByTokenHashFunc(ctx, tenantID, tokenHash)
+}
+
+// DeleteExpiredSessions calls DeleteExpiredSessionsFunc
+func (mock *SessionStore) DeleteExpiredSessions(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	if mock.DeleteExpiredSessionsFunc == nil {
+		panic("SessionStore.DeleteExpiredSessions is not stubbed")
+	}
+	return mock.DeleteExpiredSessionsFunc(ctx, tenantID)
+}
+
+// DeleteInactiveSessions calls DeleteInactiveSessionsFunc
+func (mock *SessionStore) DeleteInactiveSessions(ctx context.Context, tenantID uuid.UUID, inactivityDuration time.Duration) (int64, error) {
+	if mock.DeleteInactiveSessionsFunc == nil {
+		panic("SessionStore.DeleteInactiveSessions is not stubbed")
+	}
+	return mock.DeleteInactiveSessionsFunc(ctx, tenantID, inactivityDuration)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *SessionStore) FindByID(ctx context.Context, tenantID uuid.UUID, sessionID uuid.UUID) (*models.Session, error) {
+	if mock.FindByIDFunc == nil {
+		panic("SessionStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, sessionID)
+}
+
+// FindByTokenHash calls FindByTokenHashFunc
+func (mock *SessionStore) FindByTokenHash(ctx context.Context, tenantID uuid.UUID, tokenHash string) (*models.Session, error) {
+	if mock.FindByTokenHashFunc == nil {
+		panic("SessionStore.FindByTokenHash is not stubbed")
+	}
+	return mock.FindByTokenHashFunc(ctx, tenantID, tokenHash)
+}
+
+// GetSessionStats calls GetSessionStatsFunc
+func (mock *SessionStore) GetSessionStats(ctx context.Context, tenantID uuid.UUID) (map[string]int, error) {
+	if mock.GetSessionStatsFunc == nil {
+		panic("SessionStore.GetSessionStats is not stubbed")
+	}
+	return mock.GetSessionStatsFunc(ctx, tenantID)
+}
+
+// ListActiveSessions calls ListActiveSessionsFunc
+func (mock *SessionStore) ListActiveSessions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Session, error) {
+	if mock.ListActiveSessionsFunc == nil {
+		panic("SessionStore.ListActiveSessions is not stubbed")
+	}
+	return mock.ListActiveSessionsFunc(ctx, tenantID, userID)
+}
+
+// ListByUser calls ListByUserFunc
+func (mock *SessionStore) ListByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Session, error) {
+	if mock.ListByUserFunc == nil {
+		panic("SessionStore.ListByUser is not stubbed")
+	}
+	return mock.ListByUserFunc(ctx, tenantID, userID)
+}
+
+// UpdateActivity calls UpdateActivityFunc
+func (mock *SessionStore) UpdateActivity(ctx context.Context, tenantID uuid.UUID, sessionID uuid.UUID) error {
+	if mock.UpdateActivityFunc == nil {
+		panic("SessionStore.UpdateActivity is not stubbed")
+	}
+	return mock.UpdateActivityFunc(ctx, tenantID, sessionID)
+}
+
+// UpdateActivityBatch calls UpdateActivityBatchFunc
+func (mock *SessionStore) UpdateActivityBatch(ctx context.Context, tenantID uuid.UUID, activity map[uuid.UUID]time.Time) error {
+	if mock.UpdateActivityBatchFunc == nil {
+		panic("SessionStore.UpdateActivityBatch is not stubbed")
+	}
+	return mock.UpdateActivityBatchFunc(ctx, tenantID, activity)
+}
+
+// SupplierStore is a mock of repository.SupplierStore
+type SupplierStore struct {
+	CheckNameExistsFunc func(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateFunc          func(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error
+	DeleteFunc          func(ctx context.Context, tenantID, supplierID uuid.UUID) error
+	FindByIDFunc        func(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error)
+	FindDuplicatesFunc  func(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery, nameThreshold, emailThreshold float64, limit int) ([]models.SupplierDuplicate, error)
+	ListFunc            func(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error)
+	ListMergesFunc      func(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error)
+	MergeFunc           func(ctx context.Context, tenantID uuid.UUID, target *models.Supplier, merge *models.SupplierMerge) error
+	UpdateFunc          func(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error
+}
+
+// CheckNameExists calls CheckNameExistsFunc
+func (mock *SupplierStore) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckNameExistsFunc == nil {
+		panic("SupplierStore.CheckNameExists is not stubbed")
+	}
+	return mock.CheckNameExistsFunc(ctx, tenantID, name, excludeID)
+}
+
+// Create calls CreateFunc
+func (mock *SupplierStore) Create(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error {
+	if mock.CreateFunc == nil {
+		panic("SupplierStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, supplier)
+}
+
+// Delete calls DeleteFunc
+func (mock *SupplierStore) Delete(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("SupplierStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, supplierID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *SupplierStore) FindByID(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) (*models.Supplier, error) {
+	if mock.FindByIDFunc == nil {
+		panic("SupplierStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, supplierID)
+}
+
+// FindDuplicates calls FindDuplicatesFunc
+func (mock *SupplierStore) FindDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery, nameThreshold float64, emailThreshold float64, limit int) ([]models.SupplierDuplicate, error) {
+	if mock.FindDuplicatesFunc == nil {
+		panic("SupplierStore.FindDuplicates is not stubbed")
+	}
+	return mock.FindDuplicatesFunc(ctx, tenantID, query, nameThreshold, emailThreshold, limit)
+}
+
+// List calls ListFunc
+func (mock *SupplierStore) List(ctx context.Context, tenantID uuid.UUID, status string, search string, limit int, offset int) ([]models.Supplier, int, error) {
+	if mock.ListFunc == nil {
+		panic("SupplierStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, status, search, limit, offset)
+}
+
+// ListMerges calls ListMergesFunc
+func (mock *SupplierStore) ListMerges(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) ([]models.SupplierMerge, error) {
+	if mock.ListMergesFunc == nil {
+		panic("SupplierStore.ListMerges is not stubbed")
+	}
+	return mock.ListMergesFunc(ctx, tenantID, supplierID)
+}
+
+// Merge calls MergeFunc
+func (mock *SupplierStore) Merge(ctx context.Context, tenantID uuid.UUID, target *models.Supplier, merge *models.SupplierMerge) error {
+	if mock.MergeFunc == nil {
+		panic("SupplierStore.Merge is not stubbed")
+	}
+	return mock.MergeFunc(ctx, tenantID, target, merge)
+}
+
+// Update calls UpdateFunc
+func (mock *SupplierStore) Update(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error {
+	if mock.UpdateFunc == nil {
+		panic("SupplierStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, supplier)
+}
+
+// TenantDomainStore is a mock of repository.TenantDomainStore
+type TenantDomainStore struct {
+	CreateFunc               func(ctx context.Context, domain *models.TenantDomain) error
+	DeleteFunc               func(ctx context.Context, tenantID, domainID uuid.UUID) error
+	FindByIDFunc             func(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	FindTenantIDByDomainFunc func(ctx context.Context, domain string) (uuid.UUID, error)
+	ListFunc                 func(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error)
+	RecordCheckFunc          func(ctx context.Context, domain *models.TenantDomain, verified bool) error
+}
+
+// Create calls CreateFunc
+func (mock *TenantDomainStore) Create(ctx context.Context, domain *models.TenantDomain) error {
+	if mock.CreateFunc == nil {
+		panic("TenantDomainStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, domain)
+}
+
+// Delete calls DeleteFunc
+func (mock *TenantDomainStore) Delete(ctx context.Context, tenantID uuid.UUID, domainID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("TenantDomainStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, domainID)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *TenantDomainStore) FindByID(ctx context.Context, tenantID uuid.UUID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	if mock.FindByIDFunc == nil {
+		panic("TenantDomainStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, domainID)
+}
+
+// FindTenantIDByDomain calls FindTenantIDByDomainFunc
+func (mock *TenantDomainStore) FindTenantIDByDomain(ctx context.Context, domain string) (uuid.UUID, error) {
+	if mock.FindTenantIDByDomainFunc == nil {
+		panic("TenantDomainStore.FindTenantIDByDomain is not stubbed")
+	}
+	return mock.FindTenantIDByDomainFunc(ctx, domain)
+}
+
+// List calls ListFunc
+func (mock *TenantDomainStore) List(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error) {
+	if mock.ListFunc == nil {
+		panic("TenantDomainStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID)
+}
+
+// RecordCheck calls RecordCheckFunc
+func (mock *TenantDomainStore) RecordCheck(ctx context.Context, domain *models.TenantDomain, verified bool) error {
+	if mock.RecordCheckFunc == nil {
+		panic("TenantDomainStore.RecordCheck is not stubbed")
+	}
+	return mock.RecordCheckFunc(ctx, domain, verified)
+}
+
+// TenantStore is a mock of repository.TenantStore
+type TenantStore struct {
+	CancelDeletionFunc                   func(ctx context.Context, tenantID uuid.UUID) error
+	ChangeSlugFunc                       func(ctx context.Context, tenantID uuid.UUID, slug string, changedBy uuid.UUID, redirectUntil time.Time) (string, error)
+	CheckEmailExistsFunc                 func(ctx context.Context, email string) (bool, error)
+	CheckSlugAvailabilityFunc            func(ctx context.Context, slug string) (bool, error)
+	CleanupExpiredSlugHistoryFunc        func(ctx context.Context) ([]string, error)
+	CleanupExpiredVerificationTokensFunc func(ctx context.Context) (int64, error)
+	ClearMaintenanceWindowFunc           func(ctx context.Context, tenantID uuid.UUID) error
+	ConfirmDeletionFunc                  func(ctx context.Context, tenantID, token uuid.UUID, scheduledAt time.Time) error
+	CreateFunc                           func(ctx context.Context, tenant *models.Tenant) error
+	DeleteFunc                           func(ctx context.Context, tenantID uuid.UUID) error
+	FindByEmailFunc                      func(ctx context.Context, email string) (*models.Tenant, error)
+	FindByIDFunc                         func(ctx context.Context, id uuid.UUID) (*models.Tenant, error)
+	FindBySlugFunc                       func(ctx context.Context, slug string) (*models.Tenant, error)
+	FindByStripeCustomerFunc             func(ctx context.Context, customerID string) (*models.Tenant, error)
+	FindByVerificationTokenFunc          func(ctx context.Context, token uuid.UUID) (*models.Tenant, error)
+	FindTenantIDByFormerSlugFunc         func(ctx context.Context, slug string) (uuid.UUID, error)
+	FindUsageAnalyticsOptOutsFunc        func(ctx context.Context, tenantIDs []uuid.UUID) ([]uuid.UUID, error)
+	GetSettingsFunc                      func(ctx context.Context, tenantID uuid.UUID) (json.RawMessage, string, error)
+	ListFunc                             func(ctx context.Context, limit, offset int) ([]models.Tenant, int, error)
+	ListActiveFunc                       func(ctx context.Context) ([]models.Tenant, error)
+	ListDueForDeletionFunc               func(ctx context.Context, limit int) ([]uuid.UUID, error)
+	ProvisionSystemRolesFunc             func(ctx context.Context, tenantID uuid.UUID) error
+	ReactivateFunc                       func(ctx context.Context, tenantID uuid.UUID) error
+	RegenerateVerificationTokenFunc      func(ctx context.Context, tenantID uuid.UUID, expiresIn time.Duration) (*uuid.UUID, error)
+	RequestDeletionFunc                  func(ctx context.Context, tenantID, requestedBy, token uuid.UUID, expiresAt time.Time) error
+	SetBrandingLogoURLFunc               func(ctx context.Context, tenantID uuid.UUID, logoURL *string) error
+	SetMaintenanceWindowFunc             func(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) error
+	SetSSORequiredFunc                   func(ctx context.Context, tenantID uuid.UUID, required bool) error
+	SetSessionLimitFunc                  func(ctx context.Context, tenantID uuid.UUID, limit *models.SessionLimit) error
+	SetTwoFactorPolicyFunc               func(ctx context.Context, tenantID uuid.UUID, policy *models.TwoFactorPolicy) error
+	SetUsageAnalyticsOptOutFunc          func(ctx context.Context, tenantID uuid.UUID, optOut bool) error
+	SuspendFunc                          func(ctx context.Context, tenantID uuid.UUID, reason string) error
+	UpdateFunc                           func(ctx context.Context, tenant *models.Tenant) error
+	UpdateSettingsSectionsFunc           func(ctx context.Context, tenantID uuid.UUID, sections map[string]json.RawMessage, version string) (string, error)
+	UpdateStatusFunc                     func(ctx context.Context, tenantID uuid.UUID, status string) error
+	UpdateSubscriptionFunc               func(ctx context.Context, tenantID uuid.UUID, sub *models.TenantSubscription) error
+	VerifyEmailFunc                      func(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// CancelDeletion calls CancelDeletionFunc
+func (mock *TenantStore) CancelDeletion(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.CancelDeletionFunc == nil {
+		panic("TenantStore.CancelDeletion is not stubbed")
+	}
+	return mock.CancelDeletionFunc(ctx, tenantID)
+}
+
+// ChangeSlug calls ChangeSlugFunc
+func (mock *TenantStore) ChangeSlug(ctx context.Context, tenantID uuid.UUID, slug string, changedBy uuid.UUID, redirectUntil time.Time) (string, error) {
+	if mock.ChangeSlugFunc == nil {
+		panic("TenantStore.ChangeSlug is not stubbed")
+	}
+	return mock.ChangeSlugFunc(ctx, tenantID, slug, changedBy, redirectUntil)
+}
+
+// CheckEmailExists calls CheckEmailExistsFunc
+func (mock *TenantStore) CheckEmailExists(ctx context.Context, email string) (bool, error) {
+	if mock.CheckEmailExistsFunc == nil {
+		panic("TenantStore.CheckEmailExists is not stubbed")
+	}
+	return mock.CheckEmailExistsFunc(ctx, email)
+}
+
+// CheckSlugAvailability calls CheckSlugAvailabilityFunc
+func (mock *TenantStore) CheckSlugAvailability(ctx context.Context, slug string) (bool, error) {
+	if mock.CheckSlugAvailabilityFunc == nil {
+		panic("TenantStore.CheckSlugAvailability is not stubbed")
+	}
+	return mock.CheckSlugAvailabilityFunc(ctx, slug)
+}
+
+// CleanupExpiredSlugHistory calls CleanupExpiredSlugHistoryFunc
+func (mock *TenantStore) CleanupExpiredSlugHistory(ctx context.Context) ([]string, error) {
+	if mock.CleanupExpiredSlugHistoryFunc == nil {
+		panic("TenantStore.CleanupExpiredSlugHistory is not stubbed")
+	}
+	return mock.CleanupExpiredSlugHistoryFunc(ctx)
+}
+
+// CleanupExpiredVerificationTokens calls CleanupExpiredVerificationTokensFunc
+func (mock *TenantStore) CleanupExpiredVerificationTokens(ctx context.Context) (int64, error) {
+	if mock.CleanupExpiredVerificationTokensFunc == nil {
+		panic("TenantStore.CleanupExpiredVerificationTokens is not stubbed")
+	}
+	return mock.CleanupExpiredVerificationTokensFunc(ctx)
+}
+
+// ClearMaintenanceWindow calls ClearMaintenanceWindowFunc
+func (mock *TenantStore) ClearMaintenanceWindow(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.ClearMaintenanceWindowFunc == nil {
+		panic("TenantStore.ClearMaintenanceWindow is not stubbed")
+	}
+	return mock.ClearMaintenanceWindowFunc(ctx, tenantID)
+}
+
+// ConfirmDeletion calls ConfirmDeletionFunc
+func (mock *TenantStore) ConfirmDeletion(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, scheduledAt time.Time) error {
+	if mock.ConfirmDeletionFunc == nil {
+		panic("TenantStore.ConfirmDeletion is not stubbed")
+	}
+	return mock.ConfirmDeletionFunc(ctx, tenantID, token, scheduledAt)
+}
+
+// Create calls CreateFunc
+func (mock *TenantStore) Create(ctx context.Context, tenant *models.Tenant) error {
+	if mock.CreateFunc == nil {
+		panic("TenantStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenant)
+}
+
+// Delete calls DeleteFunc
+func (mock *TenantStore) Delete(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("TenantStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID)
+}
+
+// FindByEmail calls FindByEmailFunc
+func (mock *TenantStore) FindByEmail(ctx context.Context, email string) (*models.Tenant, error) {
+	if mock.FindByEmailFunc == nil {
+		panic("TenantStore.FindByEmail is not stubbed")
+	}
+	return mock.FindByEmailFunc(ctx, email)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *TenantStore) FindByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	if mock.FindByIDFunc == nil {
+		panic("TenantStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, id)
+}
+
+// FindBySlug calls FindBySlugFunc
+func (mock *TenantStore) FindBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	if mock.FindBySlugFunc == nil {
+		panic("TenantStore.FindBySlug is not stubbed")
+	}
+	return mock.FindBySlugFunc(ctx, slug)
+}
+
+// FindByStripeCustomer calls FindByStripeCustomerFunc
+func (mock *TenantStore) FindByStripeCustomer(ctx context.Context, customerID string) (*models.Tenant, error) {
+	if mock.FindByStripeCustomerFunc == nil {
+		panic("TenantStore.FindByStripeCustomer is not stubbed")
+	}
+	return mock.FindByStripeCustomerFunc(ctx, customerID)
+}
+
+// FindByVerificationToken calls FindByVerificationTokenFunc
+func (mock *TenantStore) FindByVerificationToken(ctx context.Context, token uuid.UUID) (*models.Tenant, error) {
+	if mock.FindByVerificationTokenFunc == nil {
+		panic("TenantStore.FindByVerificationToken is not stubbed")
+	}
+	return mock.FindByVerificationTokenFunc(ctx, token)
+}
+
+// FindTenantIDByFormerSlug calls FindTenantIDByFormerSlugFunc
+func (mock *TenantStore) FindTenantIDByFormerSlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	if mock.FindTenantIDByFormerSlugFunc == nil {
+		panic("TenantStore.FindTenantIDByFormerSlug is not stubbed")
+	}
+	return mock.FindTenantIDByFormerSlugFunc(ctx, slug)
+}
+
+// FindUsageAnalyticsOptOuts calls FindUsageAnalyticsOptOutsFunc
+func (mock *TenantStore) FindUsageAnalyticsOptOuts(ctx context.Context, tenantIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if mock.FindUsageAnalyticsOptOutsFunc == nil {
+		panic("TenantStore.FindUsageAnalyticsOptOuts is not stubbed")
+	}
+	return mock.FindUsageAnalyticsOptOutsFunc(ctx, tenantIDs)
+}
+
+// GetSettings calls GetSettingsFunc
+func (mock *TenantStore) GetSettings(ctx context.Context, tenantID uuid.UUID) (json.RawMessage, string, error) {
+	if mock.GetSettingsFunc == nil {
+		panic("TenantStore.GetSettings is not stubbed")
+	}
+	return mock.GetSettingsFunc(ctx, tenantID)
+}
+
+// List calls ListFunc
+func (mock *TenantStore) List(ctx context.Context, limit int, offset int) ([]models.Tenant, int, error) {
+	if mock.ListFunc == nil {
+		panic("TenantStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, limit, offset)
+}
+
+// ListActive calls ListActiveFunc
+func (mock *TenantStore) ListActive(ctx context.Context) ([]models.Tenant, error) {
+	if mock.ListActiveFunc == nil {
+		panic("TenantStore.ListActive is not stubbed")
+	}
+	return mock.ListActiveFunc(ctx)
+}
+
+// ListDueForDeletion calls ListDueForDeletionFunc
+func (mock *TenantStore) ListDueForDeletion(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	if mock.ListDueForDeletionFunc == nil {
+		panic("TenantStore.ListDueForDeletion is not stubbed")
+	}
+	return mock.ListDueForDeletionFunc(ctx, limit)
+}
+
+// ProvisionSystemRoles calls ProvisionSystemRolesFunc
+func (mock *TenantStore) ProvisionSystemRoles(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.ProvisionSystemRolesFunc == nil {
+		panic("TenantStore.ProvisionSystemRoles is not stubbed")
+	}
+	return mock.ProvisionSystemRolesFunc(ctx, tenantID)
+}
+
+// Reactivate calls ReactivateFunc
+func (mock *TenantStore) Reactivate(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.ReactivateFunc == nil {
+		panic("TenantStore.Reactivate is not stubbed")
+	}
+	return mock.ReactivateFunc(ctx, tenantID)
+}
+
+// RegenerateVerificationToken calls RegenerateVerificationTokenFunc
+func (mock *TenantStore) RegenerateVerificationToken(ctx context.Context, tenantID uuid.UUID, expiresIn time.Duration) (*uuid.UUID, error) {
+	if mock.RegenerateVerificationTokenFunc == nil {
+		panic("TenantStore.RegenerateVerificationToken is not stubbed")
+	}
+	return mock.RegenerateVerificationTokenFunc(ctx, tenantID, expiresIn)
+}
+
+// RequestDeletion calls RequestDeletionFunc
+func (mock *TenantStore) RequestDeletion(ctx context.Context, tenantID uuid.UUID, requestedBy uuid.UUID, token uuid.UUID, expiresAt time.Time) error {
+	if mock.RequestDeletionFunc == nil {
+		panic("TenantStore.RequestDeletion is not stubbed")
+	}
+	return mock.RequestDeletionFunc(ctx, tenantID, requestedBy, token, expiresAt)
+}
+
+// SetBrandingLogoURL calls SetBrandingLogoURLFunc
+func (mock *TenantStore) SetBrandingLogoURL(ctx context.Context, tenantID uuid.UUID, logoURL *string) error {
+	if mock.SetBrandingLogoURLFunc == nil {
+		panic("TenantStore.SetBrandingLogoURL is not stubbed")
+	}
+	return mock.SetBrandingLogoURLFunc(ctx, tenantID, logoURL)
+}
+
+// SetMaintenanceWindow calls SetMaintenanceWindowFunc
+func (mock *TenantStore) SetMaintenanceWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) error {
+	if mock.SetMaintenanceWindowFunc == nil {
+		panic("TenantStore.SetMaintenanceWindow is not stubbed")
+	}
+	return mock.SetMaintenanceWindowFunc(ctx, tenantID, req)
+}
+
+// SetSSORequired calls SetSSORequiredFunc
+func (mock *TenantStore) SetSSORequired(ctx context.Context, tenantID uuid.UUID, required bool) error {
+	if mock.SetSSORequiredFunc == nil {
+		panic("TenantStore.SetSSORequired is not stubbed")
+	}
+	return mock.SetSSORequiredFunc(ctx, tenantID, required)
+}
+
+// SetSessionLimit calls SetSessionLimitFunc
+func (mock *TenantStore) SetSessionLimit(ctx context.Context, tenantID uuid.UUID, limit *models.SessionLimit) error {
+	if mock.SetSessionLimitFunc == nil {
+		panic("TenantStore.SetSessionLimit is not stubbed")
+	}
+	return mock.SetSessionLimitFunc(ctx, tenantID, limit)
+}
+
+// SetTwoFactorPolicy calls SetTwoFactorPolicyFunc
+func (mock *TenantStore) SetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, policy *models.TwoFactorPolicy) error {
+	if mock.SetTwoFactorPolicyFunc == nil {
+		panic("TenantStore.SetTwoFactorPolicy is not stubbed")
+	}
+	return mock.SetTwoFactorPolicyFunc(ctx, tenantID, policy)
+}
+
+// SetUsageAnalyticsOptOut calls SetUsageAnalyticsOptOutFunc
+func (mock *TenantStore) SetUsageAnalyticsOptOut(ctx context.Context, tenantID uuid.UUID, optOut bool) error {
+	if mock.SetUsageAnalyticsOptOutFunc == nil {
+		panic("TenantStore.SetUsageAnalyticsOptOut is not stubbed")
+	}
+	return mock.SetUsageAnalyticsOptOutFunc(ctx, tenantID, optOut)
+}
+
+// Suspend calls SuspendFunc
+func (mock *TenantStore) Suspend(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	if mock.SuspendFunc == nil {
+		panic("TenantStore.Suspend is not stubbed")
+	}
+	return mock.SuspendFunc(ctx, tenantID, reason)
+}
+
+// Update calls UpdateFunc
+func (mock *TenantStore) Update(ctx context.Context, tenant *models.Tenant) error {
+	if mock.UpdateFunc == nil {
+		panic("TenantStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenant)
+}
+
+// UpdateSettingsSections calls UpdateSettingsSectionsFunc
+func (mock *TenantStore) UpdateSettingsSections(ctx context.Context, tenantID uuid.UUID, sections map[string]json.RawMessage, version string) (string, error) {
+	if mock.UpdateSettingsSectionsFunc == nil {
+		panic("TenantStore.UpdateSettingsSections is not stubbed")
+	}
+	return mock.UpdateSettingsSectionsFunc(ctx, tenantID, sections, version)
+}
+
+// UpdateStatus calls UpdateStatusFunc
+func (mock *TenantStore) UpdateStatus(ctx context.Context, tenantID uuid.UUID, status string) error {
+	if mock.UpdateStatusFunc == nil {
+		panic("TenantStore.UpdateStatus is not stubbed")
+	}
+	return mock.UpdateStatusFunc(ctx, tenantID, status)
+}
+
+// UpdateSubscription calls UpdateSubscriptionFunc
+func (mock *TenantStore) UpdateSubscription(ctx context.Context, tenantID uuid.UUID, sub *models.TenantSubscription) error {
+	if mock.UpdateSubscriptionFunc == nil {
+		panic("TenantStore.UpdateSubscription is not stubbed")
+	}
+	return mock.UpdateSubscriptionFunc(ctx, tenantID, sub)
+}
+
+// VerifyEmail calls VerifyEmailFunc
+func (mock *TenantStore) VerifyEmail(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.VerifyEmailFunc == nil {
+		panic("TenantStore.VerifyEmail is not stubbed")
+	}
+	return mock.VerifyEmailFunc(ctx, tenantID)
+}
+
+// TenantUsageStore is a mock of repository.TenantUsageStore
+type TenantUsageStore struct {
+	DeleteBeforeFunc func(ctx context.Context, before time.Time) (int, error)
+	ListRangeFunc    func(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]models.TenantUsage, error)
+	SaveFunc         func(ctx context.Context, usage []models.TenantUsage) error
+	SumFunc          func(ctx context.Context, tenantID uuid.UUID, metric string, from, to time.Time) (int64, error)
+}
+
+// DeleteBefore calls DeleteBeforeFunc
+func (mock *TenantUsageStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	if mock.DeleteBeforeFunc == nil {
+		panic("TenantUsageStore.DeleteBefore is not stubbed")
+	}
+	return mock.DeleteBeforeFunc(ctx, before)
+}
+
+// ListRange calls ListRangeFunc
+func (mock *TenantUsageStore) ListRange(ctx context.Context, tenantID uuid.UUID, from time.Time, to time.Time) ([]models.TenantUsage, error) {
+	if mock.ListRangeFunc == nil {
+		panic("TenantUsageStore.ListRange is not stubbed")
+	}
+	return mock.ListRangeFunc(ctx, tenantID, from, to)
+}
+
+// Save calls SaveFunc
+func (mock *TenantUsageStore) Save(ctx context.Context, usage []models.TenantUsage) error {
+	if mock.SaveFunc == nil {
+		panic("TenantUsageStore.Save is not stubbed")
+	}
+	return mock.SaveFunc(ctx, usage)
+}
+
+// Sum calls SumFunc
+func (mock *TenantUsageStore) Sum(ctx context.Context, tenantID uuid.UUID, metric string, from time.Time, to time.Time) (int64, error) {
+	if mock.SumFunc == nil {
+		panic("TenantUsageStore.Sum is not stubbed")
+	}
+	return mock.SumFunc(ctx, tenantID, metric, from, to)
+}
+
+// TimesheetStore is a mock of repository.TimesheetStore
+type TimesheetStore struct {
+	CheckProjectCodeExistsFunc func(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error)
+	CheckTaskNameExistsFunc    func(ctx context.Context, tenantID, projectID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error)
+	CreateEntryFunc            func(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error
+	CreateProjectFunc          func(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error
+	CreateTaskFunc             func(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error
+	DayHoursFunc               func(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID, day time.Time, excludeID *uuid.UUID) (float64, error)
+	DeleteEntryFunc            func(ctx context.Context, tx *sqlx.Tx, tenantID, entryID uuid.UUID) error
+	DeleteProjectFunc          func(ctx context.Context, tenantID, projectID uuid.UUID) error
+	DeleteTaskFunc             func(ctx context.Context, tenantID, taskID uuid.UUID) error
+	FindEntryByIDFunc          func(ctx context.Context, tenantID, entryID uuid.UUID) (*models.TimesheetEntry, error)
+	FindProjectByIDFunc        func(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error)
+	FindTaskByIDFunc           func(ctx context.Context, tenantID, taskID uuid.UUID) (*models.TimesheetTask, error)
+	FindTimesheetByIDFunc      func(ctx context.Context, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	FindTimesheetByWeekFunc    func(ctx context.Context, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error)
+	ListEntriesFunc            func(ctx context.Context, tenantID, timesheetID uuid.UUID) ([]*models.TimesheetEntry, error)
+	ListProjectsFunc           func(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error)
+	ListTasksFunc              func(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, activeOnly bool) ([]models.TimesheetTask, error)
+	ListTimesheetsFunc         func(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.Timesheet, int, error)
+	LockTimesheetFunc          func(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	LockWeekFunc               func(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error)
+	RefreshTotalFunc           func(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error
+	ReportFunc                 func(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter, byEmployee bool) ([]models.TimesheetReportRow, error)
+	UpdateEntryFunc            func(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error
+	UpdateProjectFunc          func(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error
+	UpdateTaskFunc             func(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error
+	UpdateTimesheetStatusFunc  func(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error
+}
+
+// CheckProjectCodeExists calls CheckProjectCodeExistsFunc
+func (mock *TimesheetStore) CheckProjectCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckProjectCodeExistsFunc == nil {
+		panic("TimesheetStore.CheckProjectCodeExists is not stubbed")
+	}
+	return mock.CheckProjectCodeExistsFunc(ctx, tenantID, code, excludeID)
+}
+
+// CheckTaskNameExists calls CheckTaskNameExistsFunc
+func (mock *TimesheetStore) CheckTaskNameExists(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	if mock.CheckTaskNameExistsFunc == nil {
+		panic("TimesheetStore.CheckTaskNameExists is not stubbed")
+	}
+	return mock.CheckTaskNameExistsFunc(ctx, tenantID, projectID, name, excludeID)
+}
+
+// CreateEntry calls CreateEntryFunc
+func (mock *TimesheetStore) CreateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error {
+	if mock.CreateEntryFunc == nil {
+		panic("TimesheetStore.CreateEntry is not stubbed")
+	}
+	return mock.CreateEntryFunc(ctx, tx, entry)
+}
+
+// CreateProject calls CreateProjectFunc
+func (mock *TimesheetStore) CreateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error {
+	if mock.CreateProjectFunc == nil {
+		panic("TimesheetStore.CreateProject is not stubbed")
+	}
+	return mock.CreateProjectFunc(ctx, tenantID, project)
+}
+
+// CreateTask calls CreateTaskFunc
+func (mock *TimesheetStore) CreateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error {
+	if mock.CreateTaskFunc == nil {
+		panic("TimesheetStore.CreateTask is not stubbed")
+	}
+	return mock.CreateTaskFunc(ctx, tenantID, task)
+}
+
+// DayHours calls DayHoursFunc
+func (mock *TimesheetStore) DayHours(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, timesheetID uuid.UUID, day time.Time, excludeID *uuid.UUID) (float64, error) {
+	if mock.DayHoursFunc == nil {
+		panic("TimesheetStore.DayHours is not stubbed")
+	}
+	return mock.DayHoursFunc(ctx, tx, tenantID, timesheetID, day, excludeID)
+}
+
+// DeleteEntry calls DeleteEntryFunc
+func (mock *TimesheetStore) DeleteEntry(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, entryID uuid.UUID) error {
+	if mock.DeleteEntryFunc == nil {
+		panic("TimesheetStore.DeleteEntry is not stubbed")
+	}
+	return mock.DeleteEntryFunc(ctx, tx, tenantID, entryID)
+}
+
+// DeleteProject calls DeleteProjectFunc
+func (mock *TimesheetStore) DeleteProject(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID) error {
+	if mock.DeleteProjectFunc == nil {
+		panic("TimesheetStore.DeleteProject is not stubbed")
+	}
+	return mock.DeleteProjectFunc(ctx, tenantID, projectID)
+}
+
+// DeleteTask calls DeleteTaskFunc
+func (mock *TimesheetStore) DeleteTask(ctx context.Context, tenantID uuid.UUID, taskID uuid.UUID) error {
+	if mock.DeleteTaskFunc == nil {
+		panic("TimesheetStore.DeleteTask is not stubbed")
+	}
+	return mock.DeleteTaskFunc(ctx, tenantID, taskID)
+}
+
+// FindEntryByID calls FindEntryByIDFunc
+func (mock *TimesheetStore) FindEntryByID(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) (*models.TimesheetEntry, error) {
+	if mock.FindEntryByIDFunc == nil {
+		panic("TimesheetStore.FindEntryByID is not stubbed")
+	}
+	return mock.FindEntryByIDFunc(ctx, tenantID, entryID)
+}
+
+// FindProjectByID calls FindProjectByIDFunc
+func (mock *TimesheetStore) FindProjectByID(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID) (*models.TimesheetProject, error) {
+	if mock.FindProjectByIDFunc == nil {
+		panic("TimesheetStore.FindProjectByID is not stubbed")
+	}
+	return mock.FindProjectByIDFunc(ctx, tenantID, projectID)
+}
+
+// FindTaskByID calls FindTaskByIDFunc
+func (mock *TimesheetStore) FindTaskByID(ctx context.Context, tenantID uuid.UUID, taskID uuid.UUID) (*models.TimesheetTask, error) {
+	if mock.FindTaskByIDFunc == nil {
+		panic("TimesheetStore.FindTaskByID is not stubbed")
+	}
+	return mock.FindTaskByIDFunc(ctx, tenantID, taskID)
+}
+
+// FindTimesheetByID calls FindTimesheetByIDFunc
+func (mock *TimesheetStore) FindTimesheetByID(ctx context.Context, tenantID uuid.UUID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	if mock.FindTimesheetByIDFunc == nil {
+		panic("TimesheetStore.FindTimesheetByID is not stubbed")
+	}
+	return mock.FindTimesheetByIDFunc(ctx, tenantID, timesheetID)
+}
+
+// FindTimesheetByWeek calls FindTimesheetByWeekFunc
+func (mock *TimesheetStore) FindTimesheetByWeek(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error) {
+	if mock.FindTimesheetByWeekFunc == nil {
+		panic("TimesheetStore.FindTimesheetByWeek is not stubbed")
+	}
+	return mock.FindTimesheetByWeekFunc(ctx, tenantID, employeeID, weekStart)
+}
+
+// ListEntries calls ListEntriesFunc
+func (mock *TimesheetStore) ListEntries(ctx context.Context, tenantID uuid.UUID, timesheetID uuid.UUID) ([]*models.TimesheetEntry, error) {
+	if mock.ListEntriesFunc == nil {
+		panic("TimesheetStore.ListEntries is not stubbed")
+	}
+	return mock.ListEntriesFunc(ctx, tenantID, timesheetID)
+}
+
+// ListProjects calls ListProjectsFunc
+func (mock *TimesheetStore) ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error) {
+	if mock.ListProjectsFunc == nil {
+		panic("TimesheetStore.ListProjects is not stubbed")
+	}
+	return mock.ListProjectsFunc(ctx, tenantID, activeOnly, customerID)
+}
+
+// ListTasks calls ListTasksFunc
+func (mock *TimesheetStore) ListTasks(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, activeOnly bool) ([]models.TimesheetTask, error) {
+	if mock.ListTasksFunc == nil {
+		panic("TimesheetStore.ListTasks is not stubbed")
+	}
+	return mock.ListTasksFunc(ctx, tenantID, projectID, activeOnly)
+}
+
+// ListTimesheets calls ListTimesheetsFunc
+func (mock *TimesheetStore) ListTimesheets(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetFilter, visibleTo *uuid.UUID, limit int, offset int) ([]models.Timesheet, int, error) {
+	if mock.ListTimesheetsFunc == nil {
+		panic("TimesheetStore.ListTimesheets is not stubbed")
+	}
+	return mock.ListTimesheetsFunc(ctx, tenantID, filter, visibleTo, limit, offset)
+}
+
+// LockTimesheet calls LockTimesheetFunc
+func (mock *TimesheetStore) LockTimesheet(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	if mock.LockTimesheetFunc == nil {
+		panic("TimesheetStore.LockTimesheet is not stubbed")
+	}
+	return mock.LockTimesheetFunc(ctx, tx, tenantID, timesheetID)
+}
+
+// LockWeek calls LockWeekFunc
+func (mock *TimesheetStore) LockWeek(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error) {
+	if mock.LockWeekFunc == nil {
+		panic("TimesheetStore.LockWeek is not stubbed")
+	}
+	return mock.LockWeekFunc(ctx, tx, tenantID, employeeID, weekStart)
+}
+
+// RefreshTotal calls RefreshTotalFunc
+func (mock *TimesheetStore) RefreshTotal(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error {
+	if mock.RefreshTotalFunc == nil {
+		panic("TimesheetStore.RefreshTotal is not stubbed")
+	}
+	return mock.RefreshTotalFunc(ctx, tx, timesheet)
+}
+
+// Report calls ReportFunc
+func (mock *TimesheetStore) Report(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter, byEmployee bool) ([]models.TimesheetReportRow, error) {
+	if mock.ReportFunc == nil {
+		panic("TimesheetStore.Report is not stubbed")
+	}
+	return mock.ReportFunc(ctx, tenantID, filter, byEmployee)
+}
+
+// UpdateEntry calls UpdateEntryFunc
+func (mock *TimesheetStore) UpdateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error {
+	if mock.UpdateEntryFunc == nil {
+		panic("TimesheetStore.UpdateEntry is not stubbed")
+	}
+	return mock.UpdateEntryFunc(ctx, tx, entry)
+}
+
+// UpdateProject calls UpdateProjectFunc
+func (mock *TimesheetStore) UpdateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error {
+	if mock.UpdateProjectFunc == nil {
+		panic("TimesheetStore.UpdateProject is not stubbed")
+	}
+	return mock.UpdateProjectFunc(ctx, tenantID, project)
+}
+
+// UpdateTask calls UpdateTaskFunc
+func (mock *TimesheetStore) UpdateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error {
+	if mock.UpdateTaskFunc == nil {
+		panic("TimesheetStore.UpdateTask is not stubbed")
+	}
+	return mock.UpdateTaskFunc(ctx, tenantID, task)
+}
+
+// UpdateTimesheetStatus calls UpdateTimesheetStatusFunc
+func (mock *TimesheetStore) UpdateTimesheetStatus(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error {
+	if mock.UpdateTimesheetStatusFunc == nil {
+		panic("TimesheetStore.UpdateTimesheetStatus is not stubbed")
+	}
+	return mock.UpdateTimesheetStatusFunc(ctx, tx, timesheet)
+}
+
+// UsageStatStore is a mock of repository.UsageStatStore
+type UsageStatStore struct {
+	AddFunc          func(ctx context.Context, stats []models.UsageStat) error
+	DeleteBeforeFunc func(ctx context.Context, before time.Time) (int, error)
+	DeleteTenantFunc func(ctx context.Context, tenantRef string) (int, error)
+	ListDayFunc      func(ctx context.Context, day time.Time) ([]models.UsageStat, error)
+}
+
+// Add calls AddFunc
+func (mock *UsageStatStore) Add(ctx context.Context, stats []models.UsageStat) error {
+	if mock.AddFunc == nil {
+		panic("UsageStatStore.Add is not stubbed")
+	}
+	return mock.AddFunc(ctx, stats)
+}
+
+// DeleteBefore calls DeleteBeforeFunc
+func (mock *UsageStatStore) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	if mock.DeleteBeforeFunc == nil {
+		panic("UsageStatStore.DeleteBefore is not stubbed")
+	}
+	return mock.DeleteBeforeFunc(ctx, before)
+}
+
+// DeleteTenant calls DeleteTenantFunc
+func (mock *UsageStatStore) DeleteTenant(ctx context.Context, tenantRef string) (int, error) {
+	if mock.DeleteTenantFunc == nil {
+		panic("UsageStatStore.DeleteTenant is not stubbed")
+	}
+	return mock.DeleteTenantFunc(ctx, tenantRef)
+}
+
+// ListDay calls ListDayFunc
+func (mock *UsageStatStore) ListDay(ctx context.Context, day time.Time) ([]models.UsageStat, error) {
+	if mock.ListDayFunc == nil {
+		panic("UsageStatStore.ListDay is not stubbed")
+	}
+	return mock.ListDayFunc(ctx, day)
+}
+
+// UserStore is a mock of repository.UserStore
+type UserStore struct {
+	CheckEmailExistsFunc        func(ctx context.Context, tenantID uuid.UUID, email string) (bool, error)
+	ConfirmEmailChangeFunc      func(ctx context.Context, tenantID, userID uuid.UUID) error
+	CountByStatusFunc           func(ctx context.Context, tenantID uuid.UUID, status string) (int, error)
+	CreateFunc                  func(ctx context.Context, tenantID uuid.UUID, user *models.User) error
+	CreateImportedFunc          func(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, user *models.User, roleIDs []uuid.UUID, assignedBy uuid.UUID) error
+	DeleteFunc                  func(ctx context.Context, tenantID, userID, deletedBy uuid.UUID) error
+	Disable2FAFunc              func(ctx context.Context, tenantID, userID uuid.UUID) error
+	Enable2FAFunc               func(ctx context.Context, tenantID, userID uuid.UUID, secret string, backupCodes []string) error
+	FindAllByEmailFunc          func(ctx context.Context, email string) ([]models.User, error)
+	FindByEmailFunc             func(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error)
+	FindByEmailChangeTokenFunc  func(ctx context.Context, tenantID, token uuid.UUID) (*models.User, error)
+	FindByIDFunc                func(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error)
+	FindByResetTokenFunc        func(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.User, error)
+	FindExistingEmailsFunc      func(ctx context.Context, tenantID uuid.UUID, emails []string) (map[string]bool, error)
+	GetPasswordHistoryFunc      func(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]string, error)
+	ListFunc                    func(ctx context.Context, tenantID uuid.UUID, filter models.UserFilter, page *pagination.Request) ([]models.User, int, string, error)
+	ListActiveIDsFunc           func(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error)
+	ListDeletedFunc             func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.User, int, error)
+	ListWithoutSecondFactorFunc func(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]models.User, error)
+	MarkEmailUndeliverableFunc  func(ctx context.Context, email, reason string) ([]models.User, error)
+	PurgeDeletedBeforeFunc      func(ctx context.Context, cutoff time.Time) (int, error)
+	ReplacePasswordHashFunc     func(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string) error
+	RestoreFunc                 func(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error)
+	SearchFunc                  func(ctx context.Context, tenantID uuid.UUID, searchTerm, config string, limit int) ([]models.User, error)
+	SetEmailChangeFunc          func(ctx context.Context, tenantID, userID uuid.UUID, pendingEmail string, token uuid.UUID, expiresAt time.Time) error
+	SetResetTokenFunc           func(ctx context.Context, tenantID, userID, token uuid.UUID, expiresAt string) error
+	StreamForExportFunc         func(ctx context.Context, tenantID uuid.UUID, fn func(*models.UserExport) error) error
+	UpdateFunc                  func(ctx context.Context, tenantID uuid.UUID, user *models.User) error
+	UpdateLastLoginFunc         func(ctx context.Context, tenantID, userID uuid.UUID, ipAddress string) error
+	UpdatePasswordFunc          func(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string, historyCount int) error
+	UpdateStatusFunc            func(ctx context.Context, tenantID, userID uuid.UUID, status string) error
+	UseBackupCodeFunc           func(ctx context.Context, tenantID, userID uuid.UUID, remainingCodes []string) error
+	VerifyEmailFunc             func(ctx context.Context, tenantID, userID uuid.UUID) error
+}
+
+// CheckEmailExists calls CheckEmailExistsFunc
+func (mock *UserStore) CheckEmailExists(ctx context.Context, tenantID uuid.UUID, email string) (bool, error) {
+	if mock.CheckEmailExistsFunc == nil {
+		panic("UserStore.CheckEmailExists is not stubbed")
+	}
+	return mock.CheckEmailExistsFunc(ctx, tenantID, email)
+}
+
+// ConfirmEmailChange calls ConfirmEmailChangeFunc
+func (mock *UserStore) ConfirmEmailChange(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.ConfirmEmailChangeFunc == nil {
+		panic("UserStore.ConfirmEmailChange is not stubbed")
+	}
+	return mock.ConfirmEmailChangeFunc(ctx, tenantID, userID)
+}
+
+// CountByStatus calls CountByStatusFunc
+func (mock *UserStore) CountByStatus(ctx context.Context, tenantID uuid.UUID, status string) (int, error) {
+	if mock.CountByStatusFunc == nil {
+		panic("UserStore.CountByStatus is not stubbed")
+	}
+	return mock.CountByStatusFunc(ctx, tenantID, status)
+}
+
+// Create calls CreateFunc
+func (mock *UserStore) Create(ctx context.Context, tenantID uuid.UUID, user *models.User) error {
+	if mock.CreateFunc == nil {
+		panic("UserStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, user)
+}
+
+// CreateImported calls CreateImportedFunc
+func (mock *UserStore) CreateImported(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, user *models.User, roleIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	if mock.CreateImportedFunc == nil {
+		panic("UserStore.CreateImported is not stubbed")
+	}
+	return mock.CreateImportedFunc(ctx, tx, tenantID, user, roleIDs, assignedBy)
+}
+
+// Delete calls DeleteFunc
+func (mock *UserStore) Delete(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, deletedBy uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("UserStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, userID, deletedBy)
+}
+
+// Disable2FA calls Disable2FAFunc
+func (mock *UserStore) Disable2FA(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.Disable2FAFunc == nil {
+		panic("UserStore.Disable2FA is not stubbed")
+	}
+	return mock.Disable2FAFunc(ctx, tenantID, userID)
+}
+
+// Enable2FA calls Enable2FAFunc
+func (mock *UserStore) Enable2FA(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, secret string, backupCodes []string) error {
+	if mock.Enable2FAFunc == nil {
+		panic("UserStore.Enable2FA is not stubbed")
+	}
+	return mock.Enable2FAFunc(ctx, tenantID, userID, secret, backupCodes)
+}
+
+// FindAllByEmail calls FindAllByEmailFunc
+func (mock *UserStore) FindAllByEmail(ctx context.Context, email string) ([]models.User, error) {
+	if mock.FindAllByEmailFunc == nil {
+		panic("UserStore.FindAllByEmail is not stubbed")
+	}
+	return mock.FindAllByEmailFunc(ctx, email)
+}
+
+// FindByEmail calls FindByEmailFunc
+func (mock *UserStore) FindByEmail(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error) {
+	if mock.FindByEmailFunc == nil {
+		panic("UserStore.FindByEmail is not stubbed")
+	}
+	return mock.FindByEmailFunc(ctx, tenantID, email)
+}
+
+// FindByEmailChangeToken calls FindByEmailChangeTokenFunc
+func (mock *UserStore) FindByEmailChangeToken(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.User, error) {
+	if mock.FindByEmailChangeTokenFunc == nil {
+		panic("UserStore.FindByEmailChangeToken is not stubbed")
+	}
+	return mock.FindByEmailChangeTokenFunc(ctx, tenantID, token)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *UserStore) FindByID(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.User, error) {
+	if mock.FindByIDFunc == nil {
+		panic("UserStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, userID)
+}
+
+// FindByResetToken calls FindByResetTokenFunc
+func (mock *UserStore) FindByResetToken(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.User, error) {
+	if mock.FindByResetTokenFunc == nil {
+		panic("UserStore.FindByResetToken is not stubbed")
+	}
+	return mock.FindByResetTokenFunc(ctx, tenantID, token)
+}
+
+// FindExistingEmails calls FindExistingEmailsFunc
+func (mock *UserStore) FindExistingEmails(ctx context.Context, tenantID uuid.UUID, emails []string) (map[string]bool, error) {
+	if mock.FindExistingEmailsFunc == nil {
+		panic("UserStore.FindExistingEmails is not stubbed")
+	}
+	return mock.FindExistingEmailsFunc(ctx, tenantID, emails)
+}
+
+// GetPasswordHistory calls GetPasswordHistoryFunc
+func (mock *UserStore) GetPasswordHistory(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]string, error) {
+	if mock.GetPasswordHistoryFunc == nil {
+		panic("UserStore.GetPasswordHistory is not stubbed")
+	}
+	return mock.GetPasswordHistoryFunc(ctx, tenantID, userID, limit)
+}
+
+// List calls ListFunc
+func (mock *UserStore) List(ctx context.Context, tenantID uuid.UUID, filter models.UserFilter, page *pagination.Request) ([]models.User, int, string, error) {
+	if mock.ListFunc == nil {
+		panic("UserStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, page)
+}
+
+// ListActiveIDs calls ListActiveIDsFunc
+func (mock *UserStore) ListActiveIDs(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	if mock.ListActiveIDsFunc == nil {
+		panic("UserStore.ListActiveIDs is not stubbed")
+	}
+	return mock.ListActiveIDsFunc(ctx, tenantID)
+}
+
+// ListDeleted calls ListDeletedFunc
+func (mock *UserStore) ListDeleted(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.User, int, error) {
+	if mock.ListDeletedFunc == nil {
+		panic("UserStore.ListDeleted is not stubbed")
+	}
+	return mock.ListDeletedFunc(ctx, tenantID, limit, offset)
+}
+
+// ListWithoutSecondFactor calls ListWithoutSecondFactorFunc
+func (mock *UserStore) ListWithoutSecondFactor(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]models.User, error) {
+	if mock.ListWithoutSecondFactorFunc == nil {
+		panic("UserStore.ListWithoutSecondFactor is not stubbed")
+	}
+	return mock.ListWithoutSecondFactorFunc(ctx, tenantID, roleIDs)
+}
+
+// MarkEmailUndeliverable calls MarkEmailUndeliverableFunc
+func (mock *UserStore) MarkEmailUndeliverable(ctx context.Context, email string, reason string) ([]models.User, error) {
+	if mock.MarkEmailUndeliverableFunc == nil {
+		panic("UserStore.MarkEmailUndeliverable is not stubbed")
+	}
+	return mock.MarkEmailUndeliverableFunc(ctx, email, reason)
+}
+
+// PurgeDeletedBefore calls PurgeDeletedBeforeFunc
+func (mock *UserStore) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	if mock.PurgeDeletedBeforeFunc == nil {
+		panic("UserStore.PurgeDeletedBefore is not stubbed")
+	}
+	return mock.PurgeDeletedBeforeFunc(ctx, cutoff)
+}
+
+// ReplacePasswordHash calls ReplacePasswordHashFunc
+func (mock *UserStore) ReplacePasswordHash(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, passwordHash string) error {
+	if mock.ReplacePasswordHashFunc == nil {
+		panic("UserStore.ReplacePasswordHash is not stubbed")
+	}
+	return mock.ReplacePasswordHashFunc(ctx, tenantID, userID, passwordHash)
+}
+
+// Restore calls RestoreFunc
+func (mock *UserStore) Restore(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.User, error) {
+	if mock.RestoreFunc == nil {
+		panic("UserStore.Restore is not stubbed")
+	}
+	return mock.RestoreFunc(ctx, tenantID, userID)
+}
+
+// Search calls SearchFunc
+func (mock *UserStore) Search(ctx context.Context, tenantID uuid.UUID, searchTerm string, config string, limit int) ([]models.User, error) {
+	if mock.SearchFunc == nil {
+		panic("UserStore.Search is not stubbed")
+	}
+	return mock.SearchFunc(ctx, tenantID, searchTerm, config, limit)
+}
+
+// SetEmailChange calls SetEmailChangeFunc
+func (mock *UserStore) SetEmailChange(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, pendingEmail string, token uuid.UUID, expiresAt time.Time) error {
+	if mock.SetEmailChangeFunc == nil {
+		panic("UserStore.SetEmailChange is not stubbed")
+	}
+	return mock.SetEmailChangeFunc(ctx, tenantID, userID, pendingEmail, token, expiresAt)
+}
+
+// SetResetToken calls SetResetTokenFunc
+func (mock *UserStore) SetResetToken(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, token uuid.UUID, expiresAt string) error {
+	if mock.SetResetTokenFunc == nil {
+		panic("UserStore.SetResetToken is not stubbed")
+	}
+	return mock.SetResetTokenFunc(ctx, tenantID, userID, token, expiresAt)
+}
+
+// StreamForExport calls StreamForExportFunc
+func (mock *UserStore) StreamForExport(ctx context.Context, tenantID uuid.UUID, fn func(*models.UserExport) error) error {
+	if mock.StreamForExportFunc == nil {
+		panic("UserStore.StreamForExport is not stubbed")
+	}
+	return mock.StreamForExportFunc(ctx, tenantID, fn)
+}
+
+// Update calls UpdateFunc
+func (mock *UserStore) Update(ctx context.Context, tenantID uuid.UUID, user *models.User) error {
+	if mock.UpdateFunc == nil {
+		panic("UserStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, user)
+}
+
+// UpdateLastLogin calls UpdateLastLoginFunc
+func (mock *UserStore) UpdateLastLogin(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, ipAddress string) error {
+	if mock.UpdateLastLoginFunc == nil {
+		panic("UserStore.UpdateLastLogin is not stubbed")
+	}
+	return mock.UpdateLastLoginFunc(ctx, tenantID, userID, ipAddress)
+}
+
+// UpdatePassword calls UpdatePasswordFunc
+func (mock *UserStore) UpdatePassword(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, passwordHash string, historyCount int) error {
+	if mock.UpdatePasswordFunc == nil {
+		panic("UserStore.UpdatePassword is not stubbed")
+	}
+	return mock.UpdatePasswordFunc(ctx, tenantID, userID, passwordHash, historyCount)
+}
+
+// UpdateStatus calls UpdateStatusFunc
+func (mock *UserStore) UpdateStatus(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, status string) error {
+	if mock.UpdateStatusFunc == nil {
+		panic("UserStore.UpdateStatus is not stubbed")
+	}
+	return mock.UpdateStatusFunc(ctx, tenantID, userID, status)
+}
+
+// UseBackupCode calls UseBackupCodeFunc
+func (mock *UserStore) UseBackupCode(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, remainingCodes []string) error {
+	if mock.UseBackupCodeFunc == nil {
+		panic("UserStore.UseBackupCode is not stubbed")
+	}
+	return mock.UseBackupCodeFunc(ctx, tenantID, userID, remainingCodes)
+}
+
+// VerifyEmail calls VerifyEmailFunc
+func (mock *UserStore) VerifyEmail(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.VerifyEmailFunc == nil {
+		panic("UserStore.VerifyEmail is not stubbed")
+	}
+	return mock.VerifyEmailFunc(ctx, tenantID, userID)
+}
+
+// UserRoleStore is a mock of repository.UserRoleStore
+type UserRoleStore struct {
+	AssignRoleFunc               func(ctx context.Context, tenantID, userID, roleID, assignedBy uuid.UUID) error
+	AssignRolesFunc              func(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID, assignedBy uuid.UUID) error
+	BulkAssignRoleFunc           func(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID, assignedBy uuid.UUID) error
+	BulkUnassignRoleFunc         func(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID uuid.UUID) error
+	CountUsersByRoleFunc         func(ctx context.Context, tenantID, roleID uuid.UUID) (int, error)
+	DeleteExpiredFunc            func(ctx context.Context) ([]models.ExpiredRoleAssignment, error)
+	GetRoleAssignmentDetailsFunc func(ctx context.Context, tenantID, userID uuid.UUID) ([]map[string]interface{}, error)
+	GetRolesForUsersFunc         func(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]models.Role, error)
+	GetUserRolesFunc             func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error)
+	GetUsersByRoleFunc           func(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.User, error)
+	GetUsersWithPermissionFunc   func(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]models.User, error)
+	GrantTemporaryRoleFunc       func(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) error
+	HasAnyRoleFunc               func(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) (bool, error)
+	HasRoleFunc                  func(ctx context.Context, tenantID, userID, roleID uuid.UUID) (bool, error)
+	NextAssignmentChangeFunc     func(ctx context.Context, tenantID, userID uuid.UUID) (*time.Time, error)
+	RevokeTemporaryRoleFunc      func(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+	UnassignAllRolesFunc         func(ctx context.Context, tenantID, userID uuid.UUID) error
+	UnassignRoleFunc             func(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+}
+
+// AssignRole calls AssignRoleFunc
+func (mock *UserRoleStore) AssignRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleID uuid.UUID, assignedBy uuid.UUID) error {
+	if mock.AssignRoleFunc == nil {
+		panic("UserRoleStore.AssignRole is not stubbed")
+	}
+	return mock.AssignRoleFunc(ctx, tenantID, userID, roleID, assignedBy)
+}
+
+// AssignRoles calls AssignRolesFunc
+func (mock *UserRoleStore) AssignRoles(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	if mock.AssignRolesFunc == nil {
+		panic("UserRoleStore.AssignRoles is not stubbed")
+	}
+	return mock.AssignRolesFunc(ctx, tenantID, userID, roleIDs, assignedBy)
+}
+
+// BulkAssignRole calls BulkAssignRoleFunc
+func (mock *UserRoleStore) BulkAssignRole(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID uuid.UUID, assignedBy uuid.UUID) error {
+	if mock.BulkAssignRoleFunc == nil {
+		panic("UserRoleStore.BulkAssignRole is not stubbed")
+	}
+	return mock.BulkAssignRoleFunc(ctx, tenantID, userIDs, roleID, assignedBy)
+}
+
+// BulkUnassignRole calls BulkUnassignRoleFunc
+func (mock *UserRoleStore) BulkUnassignRole(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID uuid.UUID) error {
+	if mock.BulkUnassignRoleFunc == nil {
+		panic("UserRoleStore.BulkUnassignRole is not stubbed")
+	}
+	return mock.BulkUnassignRoleFunc(ctx, tenantID, userIDs, roleID)
+}
+
+// CountUsersByRole calls CountUsersByRoleFunc
+func (mock *UserRoleStore) CountUsersByRole(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) (int, error) {
+	if mock.CountUsersByRoleFunc == nil {
+		panic("UserRoleStore.CountUsersByRole is not stubbed")
+	}
+	return mock.CountUsersByRoleFunc(ctx, tenantID, roleID)
+}
+
+// DeleteExpired calls DeleteExpiredFunc
+func (mock *UserRoleStore) DeleteExpired(ctx context.Context) ([]models.ExpiredRoleAssignment, error) {
+	if mock.DeleteExpiredFunc == nil {
+		panic("UserRoleStore.DeleteExpired is not stubbed")
+	}
+	return mock.DeleteExpiredFunc(ctx)
+}
+
+// GetRoleAssignmentDetails calls GetRoleAssignmentDetailsFunc
+func (mock *UserRoleStore) GetRoleAssignmentDetails(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]map[string]interface{}, error) {
+	if mock.GetRoleAssignmentDetailsFunc == nil {
+		panic("UserRoleStore.GetRoleAssignmentDetails is not stubbed")
+	}
+	return mock.GetRoleAssignmentDetailsFunc(ctx, tenantID, userID)
+}
+
+// GetRolesForUsers calls GetRolesForUsersFunc
+func (mock *UserRoleStore) GetRolesForUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]models.Role, error) {
+	if mock.GetRolesForUsersFunc == nil {
+		panic("UserRoleStore.GetRolesForUsers is not stubbed")
+	}
+	return mock.GetRolesForUsersFunc(ctx, tenantID, userIDs)
+}
+
+// GetUserRoles calls GetUserRolesFunc
+func (mock *UserRoleStore) GetUserRoles(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Role, error) {
+	if mock.GetUserRolesFunc == nil {
+		panic("UserRoleStore.GetUserRoles is not stubbed")
+	}
+	return mock.GetUserRolesFunc(ctx, tenantID, userID)
+}
+
+// GetUsersByRole calls GetUsersByRoleFunc
+func (mock *UserRoleStore) GetUsersByRole(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) ([]models.User, error) {
+	if mock.GetUsersByRoleFunc == nil {
+		panic("UserRoleStore.GetUsersByRole is not stubbed")
+	}
+	return mock.GetUsersByRoleFunc(ctx, tenantID, roleID)
+}
+
+// GetUsersWithPermission calls GetUsersWithPermissionFunc
+func (mock *UserRoleStore) GetUsersWithPermission(ctx context.Context, tenantID uuid.UUID, resource string, action string) ([]models.User, error) {
+	if mock.GetUsersWithPermissionFunc == nil {
+		panic("UserRoleStore.GetUsersWithPermission is not stubbed")
+	}
+	return mock.GetUsersWithPermissionFunc(ctx, tenantID, resource, action)
+}
+
+// GrantTemporaryRole calls GrantTemporaryRoleFunc
+func (mock *UserRoleStore) GrantTemporaryRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) error {
+	if mock.GrantTemporaryRoleFunc == nil {
+		panic("UserRoleStore.GrantTemporaryRole is not stubbed")
+	}
+	return mock.GrantTemporaryRoleFunc(ctx, tenantID, userID, assignedBy, req)
+}
+
+// HasAnyRole calls HasAnyRoleFunc
+func (mock *UserRoleStore) HasAnyRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleIDs []uuid.UUID) (bool, error) {
+	if mock.HasAnyRoleFunc == nil {
+		panic("UserRoleStore.HasAnyRole is not stubbed")
+	}
+	return mock.HasAnyRoleFunc(ctx, tenantID, userID, roleIDs)
+}
+
+// HasRole calls HasRoleFunc
+func (mock *UserRoleStore) HasRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleID uuid.UUID) (bool, error) {
+	if mock.HasRoleFunc == nil {
+		panic("UserRoleStore.HasRole is not stubbed")
+	}
+	return mock.HasRoleFunc(ctx, tenantID, userID, roleID)
+}
+
+// NextAssignmentChange calls NextAssignmentChangeFunc
+func (mock *UserRoleStore) NextAssignmentChange(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*time.Time, error) {
+	if mock.NextAssignmentChangeFunc == nil {
+		panic("UserRoleStore.NextAssignmentChange is not stubbed")
+	}
+	return mock.NextAssignmentChangeFunc(ctx, tenantID, userID)
+}
+
+// RevokeTemporaryRole calls RevokeTemporaryRoleFunc
+func (mock *UserRoleStore) RevokeTemporaryRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleID uuid.UUID) error {
+	if mock.RevokeTemporaryRoleFunc == nil {
+		panic("UserRoleStore.RevokeTemporaryRole is not stubbed")
+	}
+	return mock.RevokeTemporaryRoleFunc(ctx, tenantID, userID, roleID)
+}
+
+// UnassignAllRoles calls UnassignAllRolesFunc
+func (mock *UserRoleStore) UnassignAllRoles(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.UnassignAllRolesFunc == nil {
+		panic("UserRoleStore.UnassignAllRoles is not stubbed")
+	}
+	return mock.UnassignAllRolesFunc(ctx, tenantID, userID)
+}
+
+// UnassignRole calls UnassignRoleFunc
+func (mock *UserRoleStore) UnassignRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleID uuid.UUID) error {
+	if mock.UnassignRoleFunc == nil {
+		panic("UserRoleStore.UnassignRole is not stubbed")
+	}
+	return mock.UnassignRoleFunc(ctx, tenantID, userID, roleID)
+}
+
+// WatchStore is a mock of repository.WatchStore
+type WatchStore struct {
+	DeleteForEntityFunc func(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error
+	ListByUserFunc      func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error)
+	ListWatchersFunc    func(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]models.RecordWatcher, error)
+	UnwatchFunc         func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	WatchFunc           func(ctx context.Context, watch *models.RecordWatch) (bool, error)
+}
+
+// DeleteForEntity calls DeleteForEntityFunc
+func (mock *WatchStore) DeleteForEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.DeleteForEntityFunc == nil {
+		panic("WatchStore.DeleteForEntity is not stubbed")
+	}
+	return mock.DeleteForEntityFunc(ctx, tenantID, entityType, entityID)
+}
+
+// ListByUser calls ListByUserFunc
+func (mock *WatchStore) ListByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, limit int, offset int) ([]models.RecordWatch, int, error) {
+	if mock.ListByUserFunc == nil {
+		panic("WatchStore.ListByUser is not stubbed")
+	}
+	return mock.ListByUserFunc(ctx, tenantID, userID, entityType, limit, offset)
+}
+
+// ListWatchers calls ListWatchersFunc
+func (mock *WatchStore) ListWatchers(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]models.RecordWatcher, error) {
+	if mock.ListWatchersFunc == nil {
+		panic("WatchStore.ListWatchers is not stubbed")
+	}
+	return mock.ListWatchersFunc(ctx, tenantID, entityType, entityID)
+}
+
+// Unwatch calls UnwatchFunc
+func (mock *WatchStore) Unwatch(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.UnwatchFunc == nil {
+		panic("WatchStore.Unwatch is not stubbed")
+	}
+	return mock.UnwatchFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// Watch calls WatchFunc
+func (mock *WatchStore) Watch(ctx context.Context, watch *models.RecordWatch) (bool, error) {
+	if mock.WatchFunc == nil {
+		panic("WatchStore.Watch is not stubbed")
+	}
+	return mock.WatchFunc(ctx, watch)
+}
+
+// WebAuthnStore is a mock of repository.WebAuthnStore
+type WebAuthnStore struct {
+	CountByUserFunc        func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	CreateFunc             func(ctx context.Context, credential *models.WebAuthnCredential) error
+	DeleteFunc             func(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error)
+	FindByCredentialIDFunc func(ctx context.Context, tenantID uuid.UUID, credentialID []byte) (*models.WebAuthnCredential, error)
+	ListByUserFunc         func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	RecordUseFunc          func(ctx context.Context, tenantID, id uuid.UUID, previousCount, signCount int64) error
+	RenameFunc             func(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error)
+	SetPasskeyOnlyFunc     func(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error
+}
+
+// CountByUser calls CountByUserFunc
+func (mock *WebAuthnStore) CountByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.CountByUserFunc == nil {
+		panic("WebAuthnStore.CountByUser is not stubbed")
+	}
+	return mock.CountByUserFunc(ctx, tenantID, userID)
+}
+
+// Create calls CreateFunc
+func (mock *WebAuthnStore) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	if mock.CreateFunc == nil {
+		panic("WebAuthnStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, credential)
+}
+
+// Delete calls DeleteFunc
+func (mock *WebAuthnStore) Delete(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID) (*models.WebAuthnCredential, error) {
+	if mock.DeleteFunc == nil {
+		panic("WebAuthnStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, userID, id)
+}
+
+// FindByCredentialID calls FindByCredentialIDFunc
+func (mock *WebAuthnStore) FindByCredentialID(ctx context.Context, tenantID uuid.UUID, credentialID []byte) (*models.WebAuthnCredential, error) {
+	if mock.FindByCredentialIDFunc == nil {
+		panic("WebAuthnStore.FindByCredentialID is not stubbed")
+	}
+	return mock.FindByCredentialIDFunc(ctx, tenantID, credentialID)
+}
+
+// ListByUser calls ListByUserFunc
+func (mock *WebAuthnStore) ListByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	if mock.ListByUserFunc == nil {
+		panic("WebAuthnStore.ListByUser is not stubbed")
+	}
+	return mock.ListByUserFunc(ctx, tenantID, userID)
+}
+
+// RecordUse calls RecordUseFunc
+func (mock *WebAuthnStore) RecordUse(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, previousCount int64, signCount int64) error {
+	if mock.RecordUseFunc == nil {
+		panic("WebAuthnStore.RecordUse is not stubbed")
+	}
+	return mock.RecordUseFunc(ctx, tenantID, id, previousCount, signCount)
+}
+
+// Rename calls RenameFunc
+func (mock *WebAuthnStore) Rename(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID, name string) (*models.WebAuthnCredential, error) {
+	if mock.RenameFunc == nil {
+		panic("WebAuthnStore.Rename is not stubbed")
+	}
+	return mock.RenameFunc(ctx, tenantID, userID, id, name)
+}
+
+// SetPasskeyOnly calls SetPasskeyOnlyFunc
+func (mock *WebAuthnStore) SetPasskeyOnly(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, enabled bool) error {
+	if mock.SetPasskeyOnlyFunc == nil {
+		panic("WebAuthnStore.SetPasskeyOnly is not stubbed")
+	}
+	return mock.SetPasskeyOnlyFunc(ctx, tenantID, userID, enabled)
+}
+
+// WebhookStore is a mock of repository.WebhookStore
+type WebhookStore struct {
+	ClaimDueFunc             func(ctx context.Context, tx *sqlx.Tx) (*models.ClaimedWebhookDelivery, error)
+	CreateFunc               func(ctx context.Context, webhook *models.Webhook) error
+	DeleteFunc               func(ctx context.Context, tenantID, id uuid.UUID) error
+	DeleteFinishedBeforeFunc func(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error)
+	EnqueueDeliveryFunc      func(ctx context.Context, webhook *models.Webhook, event *models.WebhookEvent, maxAttempts, maxPending int) (*models.WebhookDelivery, error)
+	EnqueueEventFunc         func(ctx context.Context, tenantID uuid.UUID, event *models.WebhookEvent, maxAttempts, maxPending int) (int, int, error)
+	FindByIDFunc             func(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error)
+	FindDeliveryFunc         func(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	ListFunc                 func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error)
+	ListDeliveriesFunc       func(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	MarkAttemptFailedFunc    func(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string, responseStatus int, responseBody string, duration time.Duration, nextAttemptAt time.Time) error
+	MarkDeliveredFunc        func(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, responseStatus int, responseBody string, duration time.Duration) error
+	MarkFailedFunc           func(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string) error
+	QueueStatsFunc           func(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error)
+	RetryDeliveryFunc        func(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error
+	UpdateFunc               func(ctx context.Context, webhook *models.Webhook) error
+	UpdateSecretFunc         func(ctx context.Context, tenantID, id uuid.UUID, secret string) error
+}
+
+// ClaimDue calls ClaimDueFunc
+func (mock *WebhookStore) ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.ClaimedWebhookDelivery, error) {
+	if mock.ClaimDueFunc == nil {
+		panic("WebhookStore.ClaimDue is not stubbed")
+	}
+	return mock.ClaimDueFunc(ctx, tx)
+}
+
+// Create calls CreateFunc
+func (mock *WebhookStore) Create(ctx context.Context, webhook *models.Webhook) error {
+	if mock.CreateFunc == nil {
+		panic("WebhookStore.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, webhook)
+}
+
+// Delete calls DeleteFunc
+func (mock *WebhookStore) Delete(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) error {
+	if mock.DeleteFunc == nil {
+		panic("WebhookStore.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, id)
+}
+
+// DeleteFinishedBefore calls DeleteFinishedBeforeFunc
+func (mock *WebhookStore) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	if mock.DeleteFinishedBeforeFunc == nil {
+		panic("WebhookStore.DeleteFinishedBefore is not stubbed")
+	}
+	return mock.DeleteFinishedBeforeFunc(ctx, db, cutoff)
+}
+
+// EnqueueDelivery calls EnqueueDeliveryFunc
+func (mock *WebhookStore) EnqueueDelivery(ctx context.Context, webhook *models.Webhook, event *models.WebhookEvent, maxAttempts int, maxPending int) (*models.WebhookDelivery, error) {
+	if mock.EnqueueDeliveryFunc == nil {
+		panic("WebhookStore.EnqueueDelivery is not stubbed")
+	}
+	return mock.EnqueueDeliveryFunc(ctx, webhook, event, maxAttempts, maxPending)
+}
+
+// EnqueueEvent calls EnqueueEventFunc
+func (mock *WebhookStore) EnqueueEvent(ctx context.Context, tenantID uuid.UUID, event *models.WebhookEvent, maxAttempts int, maxPending int) (int, int, error) {
+	if mock.EnqueueEventFunc == nil {
+		panic("WebhookStore.EnqueueEvent is not stubbed")
+	}
+	return mock.EnqueueEventFunc(ctx, tenantID, event, maxAttempts, maxPending)
+}
+
+// FindByID calls FindByIDFunc
+func (mock *WebhookStore) FindByID(ctx context.Context, tenantID uuid.UUID, id uuid.UUID) (*models.Webhook, error) {
+	if mock.FindByIDFunc == nil {
+		panic("WebhookStore.FindByID is not stubbed")
+	}
+	return mock.FindByIDFunc(ctx, tenantID, id)
+}
+
+// FindDelivery calls FindDeliveryFunc
+func (mock *WebhookStore) FindDelivery(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	if mock.FindDeliveryFunc == nil {
+		panic("WebhookStore.FindDelivery is not stubbed")
+	}
+	return mock.FindDeliveryFunc(ctx, tenantID, webhookID, deliveryID)
+}
+
+// List calls ListFunc
+func (mock *WebhookStore) List(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.Webhook, int, error) {
+	if mock.ListFunc == nil {
+		panic("WebhookStore.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, limit, offset)
+}
+
+// ListDeliveries calls ListDeliveriesFunc
+func (mock *WebhookStore) ListDeliveries(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, status string, limit int, offset int) ([]models.WebhookDelivery, int, error) {
+	if mock.ListDeliveriesFunc == nil {
+		panic("WebhookStore.ListDeliveries is not stubbed")
+	}
+	return mock.ListDeliveriesFunc(ctx, tenantID, webhookID, status, limit, offset)
+}
+
+// MarkAttemptFailed calls MarkAttemptFailedFunc
+func (mock *WebhookStore) MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string, responseStatus int, responseBody string, duration time.Duration, nextAttemptAt time.Time) error {
+	if mock.MarkAttemptFailedFunc == nil {
+		panic("WebhookStore.MarkAttemptFailed is not stubbed")
+	}
+	return mock.MarkAttemptFailedFunc(ctx, tx, delivery, cause, responseStatus, responseBody, duration, nextAttemptAt)
+}
+
+// MarkDelivered calls MarkDeliveredFunc
+func (mock *WebhookStore) MarkDelivered(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, responseStatus int, responseBody string, duration time.Duration) error {
+	if mock.MarkDeliveredFunc == nil {
+		panic("WebhookStore.MarkDelivered is not stubbed")
+	}
+	return mock.MarkDeliveredFunc(ctx, tx, delivery, responseStatus, responseBody, duration)
+}
+
+// MarkFailed calls MarkFailedFunc
+func (mock *WebhookStore) MarkFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string) error {
+	if mock.MarkFailedFunc == nil {
+		panic("WebhookStore.MarkFailed is not stubbed")
+	}
+	return mock.MarkFailedFunc(ctx, tx, delivery, cause)
+}
+
+// QueueStats calls QueueStatsFunc
+func (mock *WebhookStore) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	if mock.QueueStatsFunc == nil {
+		panic("WebhookStore.QueueStats is not stubbed")
+	}
+	return mock.QueueStatsFunc(ctx, db)
+}
+
+// RetryDelivery calls RetryDeliveryFunc
+func (mock *WebhookStore) RetryDelivery(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, deliveryID uuid.UUID) error {
+	if mock.RetryDeliveryFunc == nil {
+		panic("WebhookStore.RetryDelivery is not stubbed")
+	}
+	return mock.RetryDeliveryFunc(ctx, tenantID, webhookID, deliveryID)
+}
+
+// Update calls UpdateFunc
+func (mock *WebhookStore) Update(ctx context.Context, webhook *models.Webhook) error {
+	if mock.UpdateFunc == nil {
+		panic("WebhookStore.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, webhook)
+}
+
+// UpdateSecret calls UpdateSecretFunc
+func (mock *WebhookStore) UpdateSecret(ctx context.Context, tenantID uuid.UUID, id uuid.UUID, secret string) error {
+	if mock.UpdateSecretFunc == nil {
+		panic("WebhookStore.UpdateSecret is not stubbed")
+	}
+	return mock.UpdateSecretFunc(ctx, tenantID, id, secret)
+}
+
+var (
+	_ repository.AccountStore          = (*AccountStore)(nil)
+	_ repository.AccountingPeriodStore = (*AccountingPeriodStore)(nil)
+	_ repository.AnnouncementStore     = (*AnnouncementStore)(nil)
+	_ repository.ApprovalLinkStore     = (*ApprovalLinkStore)(nil)
+	_ repository.AsyncJobStore         = (*AsyncJobStore)(nil)
+	_ repository.AutomationStore       = (*AutomationStore)(nil)
+	_ repository.BroadcastStore        = (*BroadcastStore)(nil)
+	_ repository.CRMStore              = (*CRMStore)(nil)
+	_ repository.CompanySettingsStore  = (*CompanySettingsStore)(nil)
+	_ repository.ComplianceStore       = (*ComplianceStore)(nil)
+	_ repository.CustomFieldStore      = (*CustomFieldStore)(nil)
+	_ repository.DashboardStore        = (*DashboardStore)(nil)
+	_ repository.DataQualityStore      = (*DataQualityStore)(nil)
+	_ repository.DeletionStore         = (*DeletionStore)(nil)
+	_ repository.DepartmentStore       = (*DepartmentStore)(nil)
+	_ repository.EmailOutboxStore      = (*EmailOutboxStore)(nil)
+	_ repository.EmployeeStore         = (*EmployeeStore)(nil)
+	_ repository.EscalationStore       = (*EscalationStore)(nil)
+	_ repository.FileStore             = (*FileStore)(nil)
+	_ repository.JournalEntryStore     = (*JournalEntryStore)(nil)
+	_ repository.LeaveStore            = (*LeaveStore)(nil)
+	_ repository.NavigationStore       = (*NavigationStore)(nil)
+	_ repository.NotificationStore     = (*NotificationStore)(nil)
+	_ repository.ObjectACLStore        = (*ObjectACLStore)(nil)
+	_ repository.PermissionStore       = (*PermissionStore)(nil)
+	_ repository.PersonalDataStore     = (*PersonalDataStore)(nil)
+	_ repository.PlatformStore         = (*PlatformStore)(nil)
+	_ repository.PurchaseOrderStore    = (*PurchaseOrderStore)(nil)
+	_ repository.QuotaStore            = (*QuotaStore)(nil)
+	_ repository.RequestStatStore      = (*RequestStatStore)(nil)
+	_ repository.RoleStore             = (*RoleStore)(nil)
+	_ repository.SIEMForwarderStore    = (*SIEMForwarderStore)(nil)
+	_ repository.SSOStore              = (*SSOStore)(nil)
+	_ repository.SalesStore            = (*SalesStore)(nil)
+	_ repository.SandboxStore          = (*SandboxStore)(nil)
+	_ repository.ScheduledReportStore  = (*ScheduledReportStore)(nil)
+	_ repository.SearchStore           = (*SearchStore)(nil)
+	_ repository.SessionStore          = (*SessionStore)(nil)
+	_ repository.SupplierStore         = (*SupplierStore)(nil)
+	_ repository.TenantDomainStore     = (*TenantDomainStore)(nil)
+	_ repository.TenantStore           = (*TenantStore)(nil)
+	_ repository.TenantUsageStore      = (*TenantUsageStore)(nil)
+	_ repository.TimesheetStore        = (*TimesheetStore)(nil)
+	_ repository.UsageStatStore        = (*UsageStatStore)(nil)
+	_ repository.UserStore             = (*UserStore)(nil)
+	_ repository.UserRoleStore         = (*UserRoleStore)(nil)
+	_ repository.WatchStore            = (*WatchStore)(nil)
+	_ repository.WebAuthnStore         = (*WebAuthnStore)(nil)
+	_ repository.WebhookStore          = (*WebhookStore)(nil)
+)
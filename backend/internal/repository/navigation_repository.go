@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// NavigationRepository handles database operations for users' recently viewed
+// entities and favorites
+type NavigationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNavigationRepository creates a new navigation repository
+func NewNavigationRepository(db *sqlx.DB) *NavigationRepository {
+	return &NavigationRepository{db: db}
+}
+
+// ListRecent retrieves a user's saved recently viewed entities, most recent
+// first
+func (r *NavigationRepository) ListRecent(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	views := []models.RecentView{}
+	query := `
+		SELECT entity_type, entity_id, viewed_at
+		FROM recent_views
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY viewed_at DESC
+	`
+	if err := tx.SelectContext(ctx, &views, query, tenantID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list recent views: %w", err)
+	}
+
+	return views, nil
+}
+
+// ReplaceRecent replaces a user's saved recently viewed entities
+func (r *NavigationRepository) ReplaceRecent(ctx context.Context, tenantID, userID uuid.UUID, views []models.RecentView) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recent_views WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID); err != nil {
+		return fmt.Errorf("failed to clear recent views: %w", err)
+	}
+
+	for _, view := range views {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recent_views (tenant_id, user_id, entity_type, entity_id, viewed_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, tenantID, userID, view.EntityType, view.EntityID, view.ViewedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save recent view: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListFavorites retrieves a user's favorites in the order they were pinned
+func (r *NavigationRepository) ListFavorites(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	favorites := []models.Favorite{}
+	query := `
+		SELECT * FROM favorites
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY created_at, id
+	`
+	if err := tx.SelectContext(ctx, &favorites, query, tenantID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+
+	return favorites, nil
+}
+
+// CountFavorites counts a user's favorites
+func (r *NavigationRepository) CountFavorites(ctx context.Context, tenantID, userID uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM favorites WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count favorites: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddFavorite pins an entity. Returns false, and the existing favorite, if
+// the user already pinned it.
+func (r *NavigationRepository) AddFavorite(ctx context.Context, favorite *models.Favorite) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, favorite.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO favorites (tenant_id, user_id, entity_type, entity_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id, entity_type, entity_id) DO NOTHING
+		RETURNING id, created_at
+	`
+	created := true
+	err = tx.QueryRowContext(ctx, query,
+		favorite.TenantID,
+		favorite.UserID,
+		favorite.EntityType,
+		favorite.EntityID,
+	).Scan(&favorite.ID, &favorite.CreatedAt)
+	if err == sql.ErrNoRows {
+		created = false
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, created_at FROM favorites
+			WHERE tenant_id = $1 AND user_id = $2 AND entity_type = $3 AND entity_id = $4
+		`, favorite.TenantID, favorite.UserID, favorite.EntityType, favorite.EntityID).Scan(&favorite.ID, &favorite.CreatedAt)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to add favorite: %w", err)
+	}
+
+	return created, tx.Commit()
+}
+
+// RemoveFavorite unpins an entity
+func (r *NavigationRepository) RemoveFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM favorites
+		WHERE tenant_id = $1 AND user_id = $2 AND entity_type = $3 AND entity_id = $4
+	`, tenantID, userID, entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("FAVORITE_NOT_FOUND", "favorite not found")
+	}
+
+	return tx.Commit()
+}
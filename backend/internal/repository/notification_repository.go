@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// NotificationRepository handles database operations for in-app notifications
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create saves notifications of one tenant in one transaction, filling in
+// their IDs and creation times
+func (r *NotificationRepository) Create(ctx context.Context, tenantID uuid.UUID, notifications []*models.Notification) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notifications (tenant_id, user_id, type, title, body, link, data, in_app, digest_pending)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	for _, n := range notifications {
+		n.TenantID = tenantID
+		if err := tx.QueryRowContext(ctx, query,
+			tenantID,
+			n.UserID,
+			n.Type,
+			n.Title,
+			n.Body,
+			n.Link,
+			string(n.Data),
+			n.InApp,
+			n.DigestPending,
+		).Scan(&n.ID, &n.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create notification: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List retrieves a user's in-app notifications, newest first
+func (r *NotificationRepository) List(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND user_id = $2 AND in_app AND (NOT $3 OR read_at IS NULL)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM notifications `+where, tenantID, userID, unreadOnly); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	notifications := []models.Notification{}
+	query := `SELECT * FROM notifications ` + where + ` ORDER BY created_at DESC LIMIT $4 OFFSET $5`
+
+	if err := tx.SelectContext(ctx, &notifications, query, tenantID, userID, unreadOnly, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, totalCount, nil
+}
+
+// CountUnread counts a user's unread in-app notifications
+func (r *NotificationRepository) CountUnread(ctx context.Context, tenantID, userID uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE tenant_id = $1 AND user_id = $2 AND in_app AND read_at IS NULL`
+	if err := tx.GetContext(ctx, &count, query, tenantID, userID); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks a user's notifications read, or all of them when ids is
+// empty. Returns the number of notifications that were unread.
+func (r *NotificationRepository) MarkRead(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE notifications SET read_at = NOW()
+		WHERE tenant_id = $1 AND user_id = $2 AND in_app AND read_at IS NULL
+		  AND (cardinality($3::uuid[]) = 0 OR id = ANY($3))
+	`
+	result, err := tx.ExecContext(ctx, query, tenantID, userID, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	marked, _ := result.RowsAffected()
+	return int(marked), tx.Commit()
+}
+
+// MarkUnread marks one of a user's notifications unread again
+func (r *NotificationRepository) MarkUnread(ctx context.Context, tenantID, userID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE notifications SET read_at = NULL WHERE tenant_id = $1 AND user_id = $2 AND id = $3 AND in_app`
+	result, err := tx.ExecContext(ctx, query, tenantID, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification unread: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("NOTIFICATION_NOT_FOUND", "notification not found")
+	}
+
+	return tx.Commit()
+}
+
+// DeleteReadBefore removes notifications read before cutoff, across tenants
+// of a database
+func (r *NotificationRepository) DeleteReadBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM notifications WHERE read_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete read notifications: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete read notifications: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// GetPreferences retrieves a user's notification preferences. Fails with not
+// found when the user never changed them.
+func (r *NotificationRepository) GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var prefs models.NotificationPreferences
+	query := `SELECT * FROM notification_preferences WHERE tenant_id = $1 AND user_id = $2`
+	err = tx.GetContext(ctx, &prefs, query, tenantID, userID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("NOTIFICATION_PREFERENCES_NOT_FOUND", "notification preferences not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return &prefs, nil
+}
+
+// ListPreferences retrieves the notification preferences of users, by user;
+// users who never changed theirs are left out
+func (r *NotificationRepository) ListPreferences(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]*models.NotificationPreferences, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows := []models.NotificationPreferences{}
+	query := `SELECT * FROM notification_preferences WHERE tenant_id = $1 AND user_id = ANY($2)`
+	if err := tx.SelectContext(ctx, &rows, query, tenantID, pq.Array(userIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences: %w", err)
+	}
+
+	prefs := make(map[uuid.UUID]*models.NotificationPreferences, len(rows))
+	for i := range rows {
+		prefs[rows[i].UserID] = &rows[i]
+	}
+	return prefs, nil
+}
+
+// SavePreferences creates or replaces a user's notification preferences
+func (r *NotificationRepository) SavePreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	channels, err := json.Marshal(prefs.Channels)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification channels: %w", err)
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, prefs.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (tenant_id, user_id, channels, digest)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE
+		SET channels = EXCLUDED.channels, digest = EXCLUDED.digest, updated_at = NOW()
+		RETURNING last_digest_at, updated_at
+	`
+	if err := tx.QueryRowContext(ctx, query, prefs.TenantID, prefs.UserID, string(channels), prefs.Digest).
+		Scan(&prefs.LastDigestAt, &prefs.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListDigestRecipients returns the users of a tenant with notifications
+// waiting for their digest
+func (r *NotificationRepository) ListDigestRecipients(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	userIDs := []uuid.UUID{}
+	query := `SELECT DISTINCT user_id FROM notifications WHERE tenant_id = $1 AND digest_pending`
+	if err := tx.SelectContext(ctx, &userIDs, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// ListDigestPending retrieves a user's unread notifications waiting for their
+// digest, newest first
+func (r *NotificationRepository) ListDigestPending(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := `
+		SELECT * FROM notifications
+		WHERE tenant_id = $1 AND user_id = $2 AND digest_pending AND read_at IS NULL
+		ORDER BY created_at DESC
+	`
+	if err := tx.SelectContext(ctx, &notifications, query, tenantID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list digest notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// FinishDigest records that a user's digest was sent: their notifications
+// no longer wait for it, those only emailed count as read (so they are
+// cleaned up), and the next digest is due a period after sentAt
+func (r *NotificationRepository) FinishDigest(ctx context.Context, tx *sqlx.Tx, prefs *models.NotificationPreferences, sentAt time.Time) error {
+	query := `
+		UPDATE notifications
+		SET digest_pending = false,
+		    read_at = CASE WHEN in_app THEN read_at ELSE COALESCE(read_at, $3) END
+		WHERE tenant_id = $1 AND user_id = $2 AND digest_pending
+	`
+	if _, err := tx.ExecContext(ctx, query, prefs.TenantID, prefs.UserID, sentAt); err != nil {
+		return fmt.Errorf("failed to clear digest notifications: %w", err)
+	}
+
+	query = `
+		INSERT INTO notification_preferences (tenant_id, user_id, digest, last_digest_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET last_digest_at = EXCLUDED.last_digest_at
+	`
+	if _, err := tx.ExecContext(ctx, query, prefs.TenantID, prefs.UserID, prefs.Digest, sentAt); err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+
+	prefs.LastDigestAt = &sentAt
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// ObjectACLRepository handles the ownership, restriction and grants of single
+// records
+type ObjectACLRepository struct {
+	db *sqlx.DB
+}
+
+// NewObjectACLRepository creates a new object ACL repository
+func NewObjectACLRepository(db *sqlx.DB) *ObjectACLRepository {
+	return &ObjectACLRepository{db: db}
+}
+
+// Find retrieves a record's ACL with its grants, or nil if it has none
+func (r *ObjectACLRepository) Find(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var acl models.ObjectACL
+	query := `
+		SELECT tenant_id, resource_type, resource_id, owner_id, restricted, created_at, updated_at, updated_by
+		FROM object_acls
+		WHERE resource_type = $1 AND resource_id = $2
+	`
+	err = tx.GetContext(ctx, &acl, query, resourceType, resourceID)
+	if err == sql.ErrNoRows {
+		return nil, tx.Commit()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find object ACL: %w", err)
+	}
+
+	acl.Grants = []models.ObjectGrant{}
+	grantsQuery := `
+		SELECT id, user_id, role_id, action, created_at, created_by
+		FROM object_grants
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY created_at, id
+	`
+	if err := tx.SelectContext(ctx, &acl.Grants, grantsQuery, resourceType, resourceID); err != nil {
+		return nil, fmt.Errorf("failed to list object grants: %w", err)
+	}
+
+	return &acl, tx.Commit()
+}
+
+// ListRestricted retrieves the ACLs of the restricted records of a resource
+// type, with their grants
+func (r *ObjectACLRepository) ListRestricted(ctx context.Context, tenantID uuid.UUID, resourceType string) ([]models.ObjectACL, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	acls := []models.ObjectACL{}
+	query := `
+		SELECT tenant_id, resource_type, resource_id, owner_id, restricted, created_at, updated_at, updated_by
+		FROM object_acls
+		WHERE resource_type = $1 AND restricted
+	`
+	if err := tx.SelectContext(ctx, &acls, query, resourceType); err != nil {
+		return nil, fmt.Errorf("failed to list object ACLs: %w", err)
+	}
+	if len(acls) == 0 {
+		return acls, tx.Commit()
+	}
+
+	var grants []struct {
+		ResourceID uuid.UUID `db:"resource_id"`
+		models.ObjectGrant
+	}
+	grantsQuery := `
+		SELECT g.resource_id, g.id, g.user_id, g.role_id, g.action, g.created_at, g.created_by
+		FROM object_grants g
+		JOIN object_acls a ON a.tenant_id = g.tenant_id AND a.resource_type = g.resource_type AND a.resource_id = g.resource_id
+		WHERE g.resource_type = $1 AND a.restricted
+		ORDER BY g.created_at, g.id
+	`
+	if err := tx.SelectContext(ctx, &grants, grantsQuery, resourceType); err != nil {
+		return nil, fmt.Errorf("failed to list object grants: %w", err)
+	}
+
+	index := make(map[uuid.UUID]int, len(acls))
+	for i := range acls {
+		acls[i].Grants = []models.ObjectGrant{}
+		index[acls[i].ResourceID] = i
+	}
+	for _, grant := range grants {
+		if i, ok := index[grant.ResourceID]; ok {
+			acls[i].Grants = append(acls[i].Grants, grant.ObjectGrant)
+		}
+	}
+
+	return acls, tx.Commit()
+}
+
+// Save creates or replaces a record's ACL and its grants
+func (r *ObjectACLRepository) Save(ctx context.Context, tenantID uuid.UUID, acl *models.ObjectACL, updatedBy uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO object_acls (tenant_id, resource_type, resource_id, owner_id, restricted, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, resource_type, resource_id) DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			restricted = EXCLUDED.restricted,
+			updated_by = EXCLUDED.updated_by
+		RETURNING created_at, updated_at
+	`
+	err = tx.QueryRowxContext(ctx, query, tenantID, acl.ResourceType, acl.ResourceID, acl.OwnerID, acl.Restricted, updatedBy).
+		Scan(&acl.CreatedAt, &acl.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save object ACL: %w", err)
+	}
+	acl.TenantID = tenantID
+	acl.UpdatedBy = &updatedBy
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM object_grants WHERE resource_type = $1 AND resource_id = $2`, acl.ResourceType, acl.ResourceID); err != nil {
+		return fmt.Errorf("failed to replace object grants: %w", err)
+	}
+
+	grantQuery := `
+		INSERT INTO object_grants (tenant_id, resource_type, resource_id, user_id, role_id, action, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	for i := range acl.Grants {
+		grant := &acl.Grants[i]
+		grant.CreatedBy = &updatedBy
+		err := tx.QueryRowxContext(ctx, grantQuery, tenantID, acl.ResourceType, acl.ResourceID, grant.UserID, grant.RoleID, grant.Action, updatedBy).
+			Scan(&grant.ID, &grant.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save object grant: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
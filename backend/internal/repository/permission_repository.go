@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
 // PermissionRepository handles database operations for permissions
@@ -28,7 +29,7 @@ func (r *PermissionRepository) FindByID(ctx context.Context, id uuid.UUID) (*mod
 
 	err := r.db.GetContext(ctx, &permission, query, id)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("permission not found")
+		return nil, utils.NewNotFoundError("PERMISSION_NOT_FOUND", "permission not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find permission: %w", err)
@@ -44,7 +45,7 @@ func (r *PermissionRepository) FindByResourceAction(ctx context.Context, resourc
 
 	err := r.db.GetContext(ctx, &permission, query, resource, action)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("permission not found")
+		return nil, utils.NewNotFoundError("PERMISSION_NOT_FOUND", "permission not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find permission: %w", err)
@@ -284,3 +285,59 @@ func (r *PermissionRepository) GetCategoryList(ctx context.Context) ([]string, e
 
 	return categories, nil
 }
+
+// GetRegistry returns the RBAC registry: modules with their resources and each
+// resource's actions, in display order
+func (r *PermissionRepository) GetRegistry(ctx context.Context) ([]models.PermissionModule, error) {
+	modules := []models.PermissionModule{}
+	if err := r.db.SelectContext(ctx, &modules, `SELECT * FROM permission_modules ORDER BY sort_order, key`); err != nil {
+		return nil, fmt.Errorf("failed to list permission modules: %w", err)
+	}
+
+	resources := []models.PermissionResource{}
+	if err := r.db.SelectContext(ctx, &resources, `SELECT * FROM permission_resources ORDER BY sort_order, resource`); err != nil {
+		return nil, fmt.Errorf("failed to list permission resources: %w", err)
+	}
+
+	// Standard CRUD actions first, custom actions alphabetically, wildcard last
+	var permissions []models.Permission
+	query := `
+		SELECT * FROM permissions
+		ORDER BY resource,
+			CASE action
+				WHEN 'view' THEN 1
+				WHEN 'create' THEN 2
+				WHEN 'edit' THEN 3
+				WHEN 'delete' THEN 4
+				WHEN '*' THEN 6
+				ELSE 5
+			END,
+			action
+	`
+	if err := r.db.SelectContext(ctx, &permissions, query); err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	actionsByResource := make(map[string][]models.Permission)
+	for _, perm := range permissions {
+		actionsByResource[perm.Resource] = append(actionsByResource[perm.Resource], perm)
+	}
+
+	resourcesByModule := make(map[string][]models.PermissionResource)
+	for _, resource := range resources {
+		resource.Actions = actionsByResource[resource.Resource]
+		if resource.Actions == nil {
+			resource.Actions = []models.Permission{}
+		}
+		resourcesByModule[resource.ModuleKey] = append(resourcesByModule[resource.ModuleKey], resource)
+	}
+
+	for i := range modules {
+		modules[i].Resources = resourcesByModule[modules[i].Key]
+		if modules[i].Resources == nil {
+			modules[i].Resources = []models.PermissionResource{}
+		}
+	}
+
+	return modules, nil
+}
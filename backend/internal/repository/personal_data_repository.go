@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// PersonalDataRepository handles the personal data held about a user across
+// tables: its export and its erasure
+type PersonalDataRepository struct {
+	db *sqlx.DB
+}
+
+// NewPersonalDataRepository creates a new personal data repository
+func NewPersonalDataRepository(db *sqlx.DB) *PersonalDataRepository {
+	return &PersonalDataRepository{db: db}
+}
+
+// Export fills in the records held about a user: sessions, security keys,
+// single sign-on identities, notifications and notification preferences,
+// favorites, recent views and the files they uploaded. They are read in one
+// transaction so they are consistent with each other.
+func (r *PersonalDataRepository) Export(ctx context.Context, tenantID, userID uuid.UUID, export *models.PersonalDataExport) error {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	export.Sessions = []models.Session{}
+	export.SecurityKeys = []models.WebAuthnCredential{}
+	export.SSOIdentities = []models.SSOIdentity{}
+	export.Notifications = []models.Notification{}
+	export.NotificationPreferences = []models.NotificationPreferences{}
+	export.AnnouncementReads = []models.AnnouncementReceipt{}
+	export.Favorites = []models.Favorite{}
+	export.RecentViews = []models.RecentView{}
+	export.Files = []models.File{}
+
+	queries := []struct {
+		name  string
+		dest  interface{}
+		query string
+	}{
+		{"sessions", &export.Sessions, `
+			SELECT id, tenant_id, user_id, name, token_hash, device_type, browser, os,
+			       ip_address, user_agent, country_code, city, last_activity_at, expires_at, created_at
+			FROM sessions
+			WHERE tenant_id = $1 AND user_id = $2
+			ORDER BY created_at
+		`},
+		{"security keys", &export.SecurityKeys, `SELECT * FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2 ORDER BY created_at`},
+		{"sso identities", &export.SSOIdentities, `SELECT * FROM sso_identities WHERE tenant_id = $1 AND user_id = $2 ORDER BY created_at`},
+		{"notifications", &export.Notifications, `SELECT * FROM notifications WHERE tenant_id = $1 AND user_id = $2 ORDER BY created_at`},
+		{"notification preferences", &export.NotificationPreferences, `SELECT * FROM notification_preferences WHERE tenant_id = $1 AND user_id = $2`},
+		{"announcement reads", &export.AnnouncementReads, `SELECT announcement_id, read_at FROM announcement_reads WHERE tenant_id = $1 AND user_id = $2 ORDER BY read_at`},
+		{"favorites", &export.Favorites, `SELECT * FROM favorites WHERE tenant_id = $1 AND user_id = $2 ORDER BY created_at`},
+		{"recent views", &export.RecentViews, `
+			SELECT entity_type, entity_id, viewed_at
+			FROM recent_views
+			WHERE tenant_id = $1 AND user_id = $2
+			ORDER BY viewed_at DESC
+		`},
+		{"files", &export.Files, `SELECT * FROM files WHERE tenant_id = $1 AND uploaded_by = $2 ORDER BY created_at`},
+	}
+
+	for _, q := range queries {
+		if err := tx.SelectContext(ctx, q.dest, q.query, tenantID, userID); err != nil {
+			return fmt.Errorf("failed to export %s: %w", q.name, err)
+		}
+	}
+
+	return nil
+}
+
+// Anonymize erases a user's personal data in place. The user row is kept
+// with a placeholder identity and can no longer sign in, so the audit logs
+// and the created_by of their records keep pointing at it. Their sign-in
+// methods, sessions, roles and personal records are deleted; emails queued to
+// them are dropped; their email and names are scrubbed from broadcast
+// recipients; and IP addresses, user agents and emails are scrubbed from the
+// audit logs by and about them. email is the user's address before erasure.
+func (r *PersonalDataRepository) Anonymize(ctx context.Context, tenantID, userID uuid.UUID, email string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []struct {
+		table string
+		query string
+		args  []interface{}
+	}{
+		{"users", `
+			UPDATE users
+			SET email = 'erased-' || id || '@erased.invalid',
+			    email_verified = FALSE,
+			    first_name = 'Erased',
+			    last_name = 'User',
+			    phone = NULL,
+			    avatar_url = NULL,
+			    password_hash = '!',
+			    reset_token = NULL,
+			    reset_token_expires_at = NULL,
+			    pending_email = NULL,
+			    email_change_token = NULL,
+			    email_change_expires_at = NULL,
+			    email_undeliverable_at = NULL,
+			    email_undeliverable_reason = NULL,
+			    two_factor_enabled = FALSE,
+			    two_factor_secret = NULL,
+			    two_factor_backup_codes = NULL,
+			    two_factor_enabled_at = NULL,
+			    two_factor_recovery_email = NULL,
+			    passkey_only = FALSE,
+			    last_login_ip = NULL,
+			    preferences = '{}',
+			    custom_fields = '{}',
+			    status = 'deactivated',
+			    erased_at = NOW(),
+			    updated_at = NOW()
+			WHERE tenant_id = $1 AND id = $2
+		`, []interface{}{tenantID, userID}},
+		{"sessions", `DELETE FROM sessions WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"webauthn_credentials", `DELETE FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"sso_identities", `DELETE FROM sso_identities WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"password_history", `DELETE FROM password_history WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"user_roles", `DELETE FROM user_roles WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"notifications", `DELETE FROM notifications WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"notification_preferences", `DELETE FROM notification_preferences WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"announcement_reads", `DELETE FROM announcement_reads WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"favorites", `DELETE FROM favorites WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"recent_views", `DELETE FROM recent_views WHERE tenant_id = $1 AND user_id = $2`, []interface{}{tenantID, userID}},
+		{"email_outbox", `DELETE FROM email_outbox WHERE tenant_id = $1 AND lower(to_email) = lower($2) AND status = 'pending'`, []interface{}{tenantID, email}},
+		{"invitations", `DELETE FROM invitations WHERE tenant_id = $1 AND lower(email) = lower($2)`, []interface{}{tenantID, email}},
+		{"email_broadcast_recipients", `
+			UPDATE email_broadcast_recipients
+			SET email = 'erased-' || user_id || '@erased.invalid',
+			    first_name = 'Erased',
+			    last_name = 'User'
+			WHERE tenant_id = $1 AND user_id = $2
+		`, []interface{}{tenantID, userID}},
+		{"audit_logs", `
+			UPDATE audit_logs
+			SET ip_address = NULL,
+			    user_agent = NULL,
+			    metadata = metadata - 'email'
+			WHERE tenant_id = $1 AND user_id = $2
+		`, []interface{}{tenantID, userID}},
+		{"audit_logs", `
+			UPDATE audit_logs
+			SET metadata = metadata - ARRAY['email', 'pending_email', 'before', 'after']
+			WHERE tenant_id = $1 AND resource_id = $2
+		`, []interface{}{tenantID, userID}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("failed to anonymize %s: %w", stmt.table, err)
+		}
+	}
+
+	return tx.Commit()
+}
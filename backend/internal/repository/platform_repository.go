@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// PlatformRepository handles platform administrators and the queries they
+// run across tenants. Admins live in the catalog database with the tenants;
+// user statistics are read from every data region.
+type PlatformRepository struct {
+	db *sqlx.DB
+}
+
+// NewPlatformRepository creates a new platform repository
+func NewPlatformRepository(db *sqlx.DB) *PlatformRepository {
+	return &PlatformRepository{db: db}
+}
+
+// IsAdmin reports whether a user is a platform admin
+func (r *PlatformRepository) IsAdmin(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM platform_admins WHERE user_id = $1 AND tenant_id = $2)`
+	if err := r.db.GetContext(ctx, &exists, query, userID, tenantID); err != nil {
+		return false, fmt.Errorf("failed to check platform admin: %w", err)
+	}
+	return exists, nil
+}
+
+// GrantAdmin makes a user a platform admin
+func (r *PlatformRepository) GrantAdmin(ctx context.Context, admin *models.PlatformAdmin) error {
+	query := `
+		INSERT INTO platform_admins (user_id, tenant_id, email)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email
+		RETURNING created_at
+	`
+
+	if err := r.db.GetContext(ctx, &admin.CreatedAt, query, admin.UserID, admin.TenantID, admin.Email); err != nil {
+		return fmt.Errorf("failed to grant platform admin: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAdmin removes a user's platform administration
+func (r *PlatformRepository) RevokeAdmin(ctx context.Context, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM platform_admins WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke platform admin: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("PLATFORM_ADMIN_NOT_FOUND", "platform admin not found")
+	}
+
+	return nil
+}
+
+// ListAdmins retrieves every platform admin
+func (r *PlatformRepository) ListAdmins(ctx context.Context) ([]models.PlatformAdmin, error) {
+	admins := []models.PlatformAdmin{}
+	query := `SELECT user_id, tenant_id, email, created_at FROM platform_admins ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &admins, query); err != nil {
+		return nil, fmt.Errorf("failed to list platform admins: %w", err)
+	}
+	return admins, nil
+}
+
+// ListTenants retrieves a filtered, paginated list of tenants, newest first
+func (r *PlatformRepository) ListTenants(ctx context.Context, filter models.PlatformTenantFilter, limit, offset int) ([]models.Tenant, int, error) {
+	where := `
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR plan_tier = $2)
+		  AND ($3 = '' OR slug ILIKE '%' || $3 || '%'
+			OR company_name ILIKE '%' || $3 || '%'
+			OR email ILIKE '%' || $3 || '%')
+	`
+	args := []interface{}{filter.Status, filter.PlanTier, filter.Search}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM tenants`+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	tenants := []models.Tenant{}
+	query := `SELECT * FROM tenants` + where + `
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	if err := r.db.SelectContext(ctx, &tenants, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	return tenants, total, nil
+}
+
+// CountTenants counts the tenants by status and by plan, and those signed
+// up since a time
+func (r *PlatformRepository) CountTenants(ctx context.Context, signedUpSince time.Time, stats *models.PlatformStats) error {
+	rows := []struct {
+		Status   string `db:"status"`
+		PlanTier string `db:"plan_tier"`
+		Count    int    `db:"count"`
+		Signups  int    `db:"signups"`
+	}{}
+	query := `
+		SELECT status, plan_tier, COUNT(*) AS count,
+		       COUNT(*) FILTER (WHERE created_at >= $1) AS signups
+		FROM tenants
+		GROUP BY status, plan_tier
+	`
+	if err := r.db.SelectContext(ctx, &rows, query, signedUpSince); err != nil {
+		return fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	stats.TenantsByStatus = make(map[string]int)
+	stats.TenantsByPlan = make(map[string]int)
+	for _, row := range rows {
+		stats.Tenants += row.Count
+		stats.TenantsByStatus[row.Status] += row.Count
+		stats.TenantsByPlan[row.PlanTier] += row.Count
+		stats.SignupsLast30d += row.Signups
+	}
+
+	return nil
+}
+
+// CountUsers counts the users not deleted across every data region, and
+// those who signed in since a time. Only the totals leave the transaction
+// bypassing RLS.
+func (r *PlatformRepository) CountUsers(ctx context.Context, activeSince time.Time) (int, int, error) {
+	var users, active int
+	for _, db := range database.RegionDBs(r.db) {
+		regionUsers, regionActive, err := r.countUsersIn(ctx, db, activeSince)
+		if err != nil {
+			return 0, 0, err
+		}
+		users += regionUsers
+		active += regionActive
+	}
+	return users, active, nil
+}
+
+func (r *PlatformRepository) countUsersIn(ctx context.Context, db *sqlx.DB, activeSince time.Time) (int, int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var counts struct {
+		Users  int `db:"users"`
+		Active int `db:"active"`
+	}
+	query := `
+		SELECT COUNT(*) AS users,
+		       COUNT(*) FILTER (WHERE last_login_at >= $1) AS active
+		FROM users
+		WHERE deleted_at IS NULL
+	`
+	if err := tx.GetContext(ctx, &counts, query, activeSince); err != nil {
+		return 0, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return counts.Users, counts.Active, nil
+}
+
+// SumUsage adds up the metered daily usage of all tenants from one day up
+// to, but not including, another, per metric
+func (r *PlatformRepository) SumUsage(ctx context.Context, from, to time.Time) (map[string]int64, error) {
+	rows := []struct {
+		Metric string `db:"metric"`
+		Value  int64  `db:"value"`
+	}{}
+	query := `
+		SELECT metric, COALESCE(SUM(value), 0)::BIGINT AS value
+		FROM tenant_usage
+		WHERE day >= $1 AND day < $2 AND metric IN ($3, $4)
+		GROUP BY metric
+	`
+	if err := r.db.SelectContext(ctx, &rows, query, from, to, models.MeterAPICalls, models.MeterEmailsSent); err != nil {
+		return nil, fmt.Errorf("failed to sum tenant usage: %w", err)
+	}
+
+	usage := map[string]int64{
+		models.MeterAPICalls:   0,
+		models.MeterEmailsSent: 0,
+	}
+	for _, row := range rows {
+		usage[row.Metric] = row.Value
+	}
+
+	return usage, nil
+}
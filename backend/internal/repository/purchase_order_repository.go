@@ -0,0 +1,635 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// PurchaseOrderRepository handles database operations for purchase orders,
+// goods receipts and supplier invoices
+type PurchaseOrderRepository struct {
+	db *sqlx.DB
+}
+
+// NewPurchaseOrderRepository creates a new purchase order repository
+func NewPurchaseOrderRepository(db *sqlx.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+// Create creates a purchase order and its lines in a single transaction
+func (r *PurchaseOrderRepository) Create(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seq, err := nextSequenceNumber(ctx, tx.Tx, tenantID, "purchase_orders", `
+		SELECT COALESCE(MAX(sequence_number), 0) FROM purchase_orders WHERE tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return err
+	}
+
+	po.SequenceNumber = seq
+	po.PONumber = fmt.Sprintf("PO-%06d", seq)
+
+	query := `
+		INSERT INTO purchase_orders (
+			tenant_id, po_number, sequence_number, supplier_id, status,
+			order_date, expected_date, currency, subtotal, tax_total, total, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		po.PONumber,
+		po.SequenceNumber,
+		po.SupplierID,
+		po.Status,
+		po.OrderDate,
+		po.ExpectedDate,
+		po.Currency,
+		po.Subtotal,
+		po.TaxTotal,
+		po.Total,
+		po.Notes,
+		po.CreatedBy,
+	).Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	po.TenantID = tenantID
+
+	if err := insertPurchaseOrderLines(ctx, tx.Tx, tenantID, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a purchase order with its lines and supplier name
+func (r *PurchaseOrderRepository) FindByID(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var po models.PurchaseOrder
+	query := `
+		SELECT po.*, s.name AS supplier_name
+		FROM purchase_orders po
+		JOIN suppliers s ON s.tenant_id = po.tenant_id AND s.id = po.supplier_id
+		WHERE po.tenant_id = $1 AND po.id = $2
+		LIMIT 1
+	`
+
+	err = tx.GetContext(ctx, &po, query, tenantID, poID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("PURCHASE_ORDER_NOT_FOUND", "purchase order not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find purchase order: %w", err)
+	}
+
+	lines := []models.PurchaseOrderLine{}
+	err = tx.SelectContext(ctx, &lines, `
+		SELECT * FROM purchase_order_lines
+		WHERE tenant_id = $1 AND purchase_order_id = $2
+		ORDER BY line_number ASC
+	`, tenantID, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase order lines: %w", err)
+	}
+	po.Lines = lines
+
+	return &po, nil
+}
+
+// List retrieves purchase orders (without lines) with filters and pagination
+func (r *PurchaseOrderRepository) List(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	supplierID *uuid.UUID,
+	status string,
+	limit, offset int,
+) ([]models.PurchaseOrder, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE po.tenant_id = $1
+		AND ($2::uuid IS NULL OR po.supplier_id = $2)
+		AND ($3 = '' OR po.status = $3)
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM purchase_orders po `+where, tenantID, supplierID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count purchase orders: %w", err)
+	}
+
+	orders := []models.PurchaseOrder{}
+	query := `
+		SELECT po.*, s.name AS supplier_name
+		FROM purchase_orders po
+		JOIN suppliers s ON s.tenant_id = po.tenant_id AND s.id = po.supplier_id
+	` + where + `
+		ORDER BY po.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	if err := tx.SelectContext(ctx, &orders, query, tenantID, supplierID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list purchase orders: %w", err)
+	}
+
+	return orders, totalCount, nil
+}
+
+// Update updates a purchase order header and replaces its lines
+func (r *PurchaseOrderRepository) Update(ctx context.Context, tenantID uuid.UUID, po *models.PurchaseOrder) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE purchase_orders
+		SET expected_date = $1,
+			currency = $2,
+			subtotal = $3,
+			tax_total = $4,
+			total = $5,
+			notes = $6,
+			updated_at = NOW()
+		WHERE tenant_id = $7 AND id = $8 AND status = 'draft'
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		po.ExpectedDate,
+		po.Currency,
+		po.Subtotal,
+		po.TaxTotal,
+		po.Total,
+		po.Notes,
+		tenantID,
+		po.ID,
+	).Scan(&po.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be edited")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE tenant_id = $1 AND purchase_order_id = $2`, tenantID, po.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear purchase order lines: %w", err)
+	}
+
+	if err := insertPurchaseOrderLines(ctx, tx.Tx, tenantID, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus moves a purchase order from one status to another
+func (r *PurchaseOrderRepository) UpdateStatus(ctx context.Context, tenantID, poID uuid.UUID, fromStatus, toStatus string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE purchase_orders
+		SET status = $1,
+			sent_at = CASE WHEN $1 = 'sent' THEN NOW() ELSE sent_at END,
+			closed_at = CASE WHEN $1 = 'closed' THEN NOW() ELSE closed_at END,
+			cancelled_at = CASE WHEN $1 = 'cancelled' THEN NOW() ELSE cancelled_at END,
+			updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND status = $4
+	`
+
+	result, err := tx.ExecContext(ctx, query, toStatus, tenantID, poID, fromStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("STATUS_CHANGED", "purchase order status has changed, please reload")
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a draft purchase order (lines cascade)
+func (r *PurchaseOrderRepository) Delete(ctx context.Context, tenantID, poID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM purchase_orders WHERE tenant_id = $1 AND id = $2 AND status = 'draft'`, tenantID, poID)
+	if err != nil {
+		return fmt.Errorf("failed to delete purchase order: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be deleted")
+	}
+
+	return tx.Commit()
+}
+
+// CreateReceipt records a goods receipt, updates received quantities and
+// moves the order to partially_received or received
+func (r *PurchaseOrderRepository) CreateReceipt(ctx context.Context, tenantID uuid.UUID, receipt *models.GoodsReceipt) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO goods_receipts (tenant_id, purchase_order_id, received_at, received_by, reference, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		receipt.PurchaseOrderID,
+		receipt.ReceivedAt,
+		receipt.ReceivedBy,
+		receipt.Reference,
+		receipt.Notes,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create goods receipt: %w", err)
+	}
+
+	receipt.TenantID = tenantID
+
+	for i := range receipt.Lines {
+		line := &receipt.Lines[i]
+		line.TenantID = tenantID
+		line.GoodsReceiptID = receipt.ID
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO goods_receipt_lines (tenant_id, goods_receipt_id, purchase_order_line_id, quantity)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, tenantID, receipt.ID, line.PurchaseOrderLineID, line.Quantity).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create goods receipt line: %w", err)
+		}
+
+		// Guard against over-receiving under concurrent receipts
+		result, err := tx.ExecContext(ctx, `
+			UPDATE purchase_order_lines
+			SET quantity_received = quantity_received + $1
+			WHERE tenant_id = $2 AND id = $3 AND purchase_order_id = $4
+			  AND quantity_received + $1 <= quantity
+		`, line.Quantity, tenantID, line.PurchaseOrderLineID, receipt.PurchaseOrderID)
+		if err != nil {
+			return fmt.Errorf("failed to update received quantity: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return utils.NewBadRequestError("QUANTITY_EXCEEDS_ORDERED", "received quantity exceeds ordered quantity")
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET status = CASE
+				WHEN NOT EXISTS (
+					SELECT 1 FROM purchase_order_lines
+					WHERE tenant_id = $1 AND purchase_order_id = $2 AND quantity_received < quantity
+				) THEN 'received'
+				ELSE 'partially_received'
+			END,
+			updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, receipt.PurchaseOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListReceipts retrieves all goods receipts for a purchase order with their lines
+func (r *PurchaseOrderRepository) ListReceipts(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	receipts := []models.GoodsReceipt{}
+	err = tx.SelectContext(ctx, &receipts, `
+		SELECT * FROM goods_receipts
+		WHERE tenant_id = $1 AND purchase_order_id = $2
+		ORDER BY received_at ASC
+	`, tenantID, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goods receipts: %w", err)
+	}
+
+	lines := []models.GoodsReceiptLine{}
+	err = tx.SelectContext(ctx, &lines, `
+		SELECT grl.* FROM goods_receipt_lines grl
+		JOIN goods_receipts gr ON gr.tenant_id = grl.tenant_id AND gr.id = grl.goods_receipt_id
+		WHERE grl.tenant_id = $1 AND gr.purchase_order_id = $2
+	`, tenantID, poID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goods receipt lines: %w", err)
+	}
+
+	byReceipt := make(map[uuid.UUID][]models.GoodsReceiptLine)
+	for _, line := range lines {
+		byReceipt[line.GoodsReceiptID] = append(byReceipt[line.GoodsReceiptID], line)
+	}
+	for i := range receipts {
+		receipts[i].Lines = byReceipt[receipts[i].ID]
+	}
+
+	return receipts, nil
+}
+
+// CreateSupplierInvoice records a supplier invoice and updates invoiced quantities
+func (r *PurchaseOrderRepository) CreateSupplierInvoice(ctx context.Context, tenantID uuid.UUID, invoice *models.SupplierInvoice) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO supplier_invoices (
+			tenant_id, supplier_id, purchase_order_id, invoice_number, invoice_date, due_date,
+			currency, subtotal, tax_total, total, match_status, match_details, status, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		invoice.SupplierID,
+		invoice.PurchaseOrderID,
+		invoice.InvoiceNumber,
+		invoice.InvoiceDate,
+		invoice.DueDate,
+		invoice.Currency,
+		invoice.Subtotal,
+		invoice.TaxTotal,
+		invoice.Total,
+		invoice.MatchStatus,
+		string(invoice.MatchDetails),
+		invoice.Status,
+		invoice.Notes,
+		invoice.CreatedBy,
+	).Scan(&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create supplier invoice: %w", err)
+	}
+
+	invoice.TenantID = tenantID
+
+	for i := range invoice.Lines {
+		line := &invoice.Lines[i]
+		line.TenantID = tenantID
+		line.SupplierInvoiceID = invoice.ID
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO supplier_invoice_lines (
+				tenant_id, supplier_invoice_id, purchase_order_line_id, quantity, unit_price, line_total
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, tenantID, invoice.ID, line.PurchaseOrderLineID, line.Quantity, line.UnitPrice, line.LineTotal).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create supplier invoice line: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_order_lines
+			SET quantity_invoiced = quantity_invoiced + $1
+			WHERE tenant_id = $2 AND id = $3
+		`, line.Quantity, tenantID, line.PurchaseOrderLineID)
+		if err != nil {
+			return fmt.Errorf("failed to update invoiced quantity: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindSupplierInvoice retrieves a supplier invoice with its lines
+func (r *PurchaseOrderRepository) FindSupplierInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var invoice models.SupplierInvoice
+	err = tx.GetContext(ctx, &invoice, `SELECT * FROM supplier_invoices WHERE tenant_id = $1 AND id = $2 LIMIT 1`, tenantID, invoiceID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SUPPLIER_INVOICE_NOT_FOUND", "supplier invoice not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supplier invoice: %w", err)
+	}
+
+	lines := []models.SupplierInvoiceLine{}
+	err = tx.SelectContext(ctx, &lines, `
+		SELECT * FROM supplier_invoice_lines
+		WHERE tenant_id = $1 AND supplier_invoice_id = $2
+	`, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier invoice lines: %w", err)
+	}
+	invoice.Lines = lines
+
+	return &invoice, nil
+}
+
+// ListSupplierInvoices retrieves supplier invoices with filters and pagination
+func (r *PurchaseOrderRepository) ListSupplierInvoices(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	poID *uuid.UUID,
+	matchStatus, status string,
+	limit, offset int,
+) ([]models.SupplierInvoice, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE tenant_id = $1
+		AND ($2::uuid IS NULL OR purchase_order_id = $2)
+		AND ($3 = '' OR match_status = $3)
+		AND ($4 = '' OR status = $4)
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM supplier_invoices `+where, tenantID, poID, matchStatus, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count supplier invoices: %w", err)
+	}
+
+	invoices := []models.SupplierInvoice{}
+	query := `SELECT * FROM supplier_invoices ` + where + ` ORDER BY created_at DESC LIMIT $5 OFFSET $6`
+
+	if err := tx.SelectContext(ctx, &invoices, query, tenantID, poID, matchStatus, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list supplier invoices: %w", err)
+	}
+
+	return invoices, totalCount, nil
+}
+
+// ListPendingSupplierInvoices retrieves the invoices recorded before cutoff
+// that are still waiting for approval, oldest first
+func (r *PurchaseOrderRepository) ListPendingSupplierInvoices(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.SupplierInvoice, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoices := []models.SupplierInvoice{}
+	query := `
+		SELECT * FROM supplier_invoices
+		WHERE tenant_id = $1 AND status = 'pending' AND created_at < $2
+		ORDER BY created_at
+	`
+	if err := tx.SelectContext(ctx, &invoices, query, tenantID, cutoff); err != nil {
+		return nil, fmt.Errorf("failed to list pending supplier invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// UpdateSupplierInvoiceStatus approves or rejects a pending supplier invoice.
+// Rejecting an invoice releases its invoiced quantities on the order lines.
+func (r *PurchaseOrderRepository) UpdateSupplierInvoiceStatus(ctx context.Context, tenantID, invoiceID, userID uuid.UUID, status string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE supplier_invoices
+		SET status = $1,
+			approved_at = CASE WHEN $1 = 'approved' THEN NOW() ELSE NULL END,
+			approved_by = CASE WHEN $1 = 'approved' THEN $2::uuid ELSE NULL END,
+			updated_at = NOW()
+		WHERE tenant_id = $3 AND id = $4 AND status = 'pending'
+	`, status, userID, tenantID, invoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to update supplier invoice status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewBadRequestError("SUPPLIER_INVOICE_NOT_PENDING", "only pending supplier invoices can be approved or rejected")
+	}
+
+	if status == models.SupplierInvoiceStatusRejected {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_order_lines pol
+			SET quantity_invoiced = pol.quantity_invoiced - sil.quantity
+			FROM supplier_invoice_lines sil
+			WHERE sil.tenant_id = $1 AND sil.supplier_invoice_id = $2
+			  AND pol.tenant_id = sil.tenant_id AND pol.id = sil.purchase_order_line_id
+		`, tenantID, invoiceID)
+		if err != nil {
+			return fmt.Errorf("failed to release invoiced quantities: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CheckInvoiceNumberExists checks if a supplier already sent an invoice with this number
+func (r *PurchaseOrderRepository) CheckInvoiceNumberExists(ctx context.Context, tenantID, supplierID uuid.UUID, invoiceNumber string) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM supplier_invoices
+		WHERE tenant_id = $1 AND supplier_id = $2 AND invoice_number = $3
+	`, tenantID, supplierID, invoiceNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to check supplier invoice number: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// insertPurchaseOrderLines inserts the order's lines within an existing transaction
+func insertPurchaseOrderLines(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, po *models.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_order_lines (
+			tenant_id, purchase_order_id, line_number, product_code, description,
+			quantity, unit_price, tax_rate, line_total
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		line.TenantID = tenantID
+		line.PurchaseOrderID = po.ID
+		line.LineNumber = i + 1
+
+		err := tx.QueryRowContext(
+			ctx, query,
+			tenantID,
+			po.ID,
+			line.LineNumber,
+			line.ProductCode,
+			line.Description,
+			line.Quantity,
+			line.UnitPrice,
+			line.TaxRate,
+			line.LineTotal,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create purchase order line: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// quotaStorageTables are the tables whose rows count towards a tenant's
+// storage. Their size is the size of the tenant's rows, without indexes.
+var quotaStorageTables = []string{
+	"users",
+	"audit_logs",
+	"sales_documents",
+	"sales_document_lines",
+	"suppliers",
+	"purchase_orders",
+	"purchase_order_lines",
+	"goods_receipts",
+	"goods_receipt_lines",
+	"supplier_invoices",
+	"supplier_invoice_lines",
+	"accounts",
+	"journal_entries",
+	"journal_entry_lines",
+	"email_outbox",
+	"async_jobs",
+	"webhook_deliveries",
+	"automation_executions",
+}
+
+// QuotaRepository measures tenants' usage of their plan quotas and handles
+// database operations for quota alerts
+type QuotaRepository struct {
+	db *sqlx.DB
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *sqlx.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// CountUsers counts the tenant's users that are not deleted
+func (r *QuotaRepository) CountUsers(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int64
+	query := `SELECT COUNT(*) FROM users WHERE tenant_id = $1 AND deleted_at IS NULL`
+	if err := tx.GetContext(ctx, &count, query, tenantID); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return count, nil
+}
+
+// MeasureStorage sums the size in bytes of the tenant's rows and uploaded
+// files
+func (r *QuotaRepository) MeasureStorage(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	sizes := make([]string, len(quotaStorageTables))
+	for i, table := range quotaStorageTables {
+		sizes[i] = `SELECT COALESCE(SUM(pg_column_size(t.*)), 0) AS size FROM ` + table + ` t WHERE tenant_id = $1`
+	}
+
+	// Uploaded files count with their contents until they are purged
+	sizes = append(sizes, `SELECT COALESCE(SUM(size_bytes), 0) AS size FROM files WHERE tenant_id = $1`)
+
+	var size int64
+	query := `SELECT COALESCE(SUM(size), 0)::BIGINT FROM (` + strings.Join(sizes, " UNION ALL ") + `) sizes`
+	if err := tx.GetContext(ctx, &size, query, tenantID); err != nil {
+		return 0, fmt.Errorf("failed to measure storage: %w", err)
+	}
+
+	return size, nil
+}
+
+// ListOpenAlerts retrieves the tenant's open alerts by metric and threshold
+func (r *QuotaRepository) ListOpenAlerts(ctx context.Context, tenantID uuid.UUID) ([]models.QuotaAlert, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	alerts := []models.QuotaAlert{}
+	query := `
+		SELECT * FROM quota_alerts
+		WHERE tenant_id = $1 AND resolved_at IS NULL
+		ORDER BY metric, threshold
+	`
+	if err := tx.SelectContext(ctx, &alerts, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list quota alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// FindAlert retrieves an alert
+func (r *QuotaRepository) FindAlert(ctx context.Context, tenantID, id uuid.UUID) (*models.QuotaAlert, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var alert models.QuotaAlert
+	err = tx.GetContext(ctx, &alert, `SELECT * FROM quota_alerts WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("QUOTA_ALERT_NOT_FOUND", "quota alert not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find quota alert: %w", err)
+	}
+
+	return &alert, nil
+}
+
+// OpenAlert records a crossed threshold. Returns false if an alert for the
+// threshold is open already.
+func (r *QuotaRepository) OpenAlert(ctx context.Context, alert *models.QuotaAlert) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, alert.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO quota_alerts (tenant_id, metric, threshold, usage, quota_limit)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, metric, threshold) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		alert.TenantID,
+		alert.Metric,
+		alert.Threshold,
+		alert.Usage,
+		alert.Limit,
+	).Scan(&alert.ID, &alert.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open quota alert: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// ResolveAlerts resolves the tenant's open alerts of a metric whose threshold
+// usage is back under
+func (r *QuotaRepository) ResolveAlerts(ctx context.Context, tenantID uuid.UUID, metric string, percent int) (int, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE quota_alerts SET resolved_at = NOW()
+		WHERE tenant_id = $1 AND metric = $2 AND threshold > $3 AND resolved_at IS NULL
+	`
+	result, err := tx.ExecContext(ctx, query, tenantID, metric, percent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve quota alerts: %w", err)
+	}
+
+	resolved, _ := result.RowsAffected()
+	return int(resolved), tx.Commit()
+}
+
+// MarkNotified records when owners were emailed about alerts
+func (r *QuotaRepository) MarkNotified(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, alertIDs []uuid.UUID, notifiedAt time.Time) error {
+	query := `UPDATE quota_alerts SET last_notified_at = $1 WHERE tenant_id = $2 AND id = ANY($3)`
+	if _, err := tx.ExecContext(ctx, query, notifiedAt, tenantID, pq.Array(alertIDs)); err != nil {
+		return fmt.Errorf("failed to update quota alerts: %w", err)
+	}
+	return nil
+}
+
+// Acknowledge records that an owner saw an open alert, which stops its
+// reminders
+func (r *QuotaRepository) Acknowledge(ctx context.Context, tenantID, id, userID uuid.UUID) (*models.QuotaAlert, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var alert models.QuotaAlert
+	query := `
+		UPDATE quota_alerts
+		SET acknowledged_at = COALESCE(acknowledged_at, NOW()), acknowledged_by = COALESCE(acknowledged_by, $1)
+		WHERE tenant_id = $2 AND id = $3
+		RETURNING *
+	`
+	err = tx.GetContext(ctx, &alert, query, userID, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("QUOTA_ALERT_NOT_FOUND", "quota alert not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to acknowledge quota alert: %w", err)
+	}
+
+	return &alert, tx.Commit()
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+)
+
+// RequestStatRepository handles the hourly request cost aggregates. They are
+// cluster-wide and live in the catalog database.
+type RequestStatRepository struct {
+	db *sqlx.DB
+}
+
+// NewRequestStatRepository creates a new request stat repository
+func NewRequestStatRepository(db *sqlx.DB) *RequestStatRepository {
+	return &RequestStatRepository{db: db}
+}
+
+// requestStatOrder maps report sort orders to ORDER BY expressions
+var requestStatOrder = map[string]string{
+	models.PerformanceSortTotal: "total_ms DESC",
+	models.PerformanceSortAvg:   "avg_ms DESC",
+	models.PerformanceSortMax:   "max_ms DESC",
+}
+
+// Add adds the stats of a replica to the hourly rows
+func (r *RequestStatRepository) Add(ctx context.Context, stats []metrics.Stat) error {
+	return database.ExecInTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO request_stats (
+				bucket, kind, name, count, error_count, total_ms, max_ms,
+				db_ms, db_calls, redis_ms, redis_calls, external_ms, external_calls
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (bucket, kind, name) DO UPDATE SET
+				count = request_stats.count + EXCLUDED.count,
+				error_count = request_stats.error_count + EXCLUDED.error_count,
+				total_ms = request_stats.total_ms + EXCLUDED.total_ms,
+				max_ms = GREATEST(request_stats.max_ms, EXCLUDED.max_ms),
+				db_ms = request_stats.db_ms + EXCLUDED.db_ms,
+				db_calls = request_stats.db_calls + EXCLUDED.db_calls,
+				redis_ms = request_stats.redis_ms + EXCLUDED.redis_ms,
+				redis_calls = request_stats.redis_calls + EXCLUDED.redis_calls,
+				external_ms = request_stats.external_ms + EXCLUDED.external_ms,
+				external_calls = request_stats.external_calls + EXCLUDED.external_calls
+		`
+
+		for _, stat := range stats {
+			_, err := tx.ExecContext(
+				ctx, query,
+				stat.Bucket,
+				stat.Kind,
+				stat.Name,
+				stat.Count,
+				stat.Errors,
+				milliseconds(stat.Total),
+				milliseconds(stat.Max),
+				milliseconds(stat.DB),
+				stat.DBCalls,
+				milliseconds(stat.Redis),
+				stat.RedisCalls,
+				milliseconds(stat.External),
+				stat.ExternalCalls,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record request stats: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TopEndpoints ranks endpoints by request cost since a time
+func (r *RequestStatRepository) TopEndpoints(ctx context.Context, since time.Time, sort string, limit int) ([]models.EndpointStat, error) {
+	order, ok := requestStatOrder[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort")
+	}
+
+	query := `
+		SELECT
+			name,
+			SUM(count) AS count,
+			SUM(error_count) AS error_count,
+			SUM(total_ms) AS total_ms,
+			SUM(total_ms) / SUM(count) AS avg_ms,
+			MAX(max_ms) AS max_ms,
+			SUM(db_ms) / SUM(count) AS avg_db_ms,
+			SUM(db_calls)::float / SUM(count) AS avg_db_calls,
+			SUM(redis_ms) / SUM(count) AS avg_redis_ms,
+			SUM(redis_calls)::float / SUM(count) AS avg_redis_calls,
+			SUM(external_ms) / SUM(count) AS avg_external_ms,
+			SUM(external_calls)::float / SUM(count) AS avg_external_calls
+		FROM request_stats
+		WHERE kind = $1 AND bucket >= $2
+		GROUP BY name
+		HAVING SUM(count) > 0
+		ORDER BY ` + order + `, name
+		LIMIT $3
+	`
+
+	endpoints := []models.EndpointStat{}
+	if err := r.db.SelectContext(ctx, &endpoints, query, metrics.KindEndpoint, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to rank endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+// TopQueries ranks slow queries since a time
+func (r *RequestStatRepository) TopQueries(ctx context.Context, since time.Time, sort string, limit int) ([]models.QueryStat, error) {
+	order, ok := requestStatOrder[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort")
+	}
+
+	query := `
+		SELECT
+			name,
+			SUM(count) AS count,
+			SUM(error_count) AS error_count,
+			SUM(total_ms) AS total_ms,
+			SUM(total_ms) / SUM(count) AS avg_ms,
+			MAX(max_ms) AS max_ms
+		FROM request_stats
+		WHERE kind = $1 AND bucket >= $2
+		GROUP BY name
+		HAVING SUM(count) > 0
+		ORDER BY ` + order + `, name
+		LIMIT $3
+	`
+
+	queries := []models.QueryStat{}
+	if err := r.db.SelectContext(ctx, &queries, query, metrics.KindQuery, since, limit); err != nil {
+		return nil, fmt.Errorf("failed to rank slow queries: %w", err)
+	}
+
+	return queries, nil
+}
+
+// DeleteBefore deletes the hourly rows older than a time
+func (r *RequestStatRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM request_stats WHERE bucket < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete request stats: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
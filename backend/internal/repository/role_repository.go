@@ -7,8 +7,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
 // RoleRepository handles database operations for roles
@@ -70,7 +72,7 @@ func (r *RoleRepository) FindByID(ctx context.Context, tenantID, roleID uuid.UUI
 
 	err = tx.GetContext(ctx, &role, query, roleID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("role not found")
+		return nil, utils.NewNotFoundError("ROLE_NOT_FOUND", "role not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find role: %w", err)
@@ -92,7 +94,7 @@ func (r *RoleRepository) FindByName(ctx context.Context, tenantID uuid.UUID, nam
 
 	err = tx.GetContext(ctx, &role, query, name)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("role not found")
+		return nil, utils.NewNotFoundError("ROLE_NOT_FOUND", "role not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find role: %w", err)
@@ -103,7 +105,7 @@ func (r *RoleRepository) FindByName(ctx context.Context, tenantID uuid.UUID, nam
 
 // List retrieves all roles for a tenant
 func (r *RoleRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +127,7 @@ func (r *RoleRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models
 
 // ListSystemRoles retrieves all system roles for a tenant
 func (r *RoleRepository) ListSystemRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +150,7 @@ func (r *RoleRepository) ListSystemRoles(ctx context.Context, tenantID uuid.UUID
 
 // ListCustomRoles retrieves all custom (non-system) roles for a tenant
 func (r *RoleRepository) ListCustomRoles(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +171,9 @@ func (r *RoleRepository) ListCustomRoles(ctx context.Context, tenantID uuid.UUID
 	return roles, nil
 }
 
-// Update updates a role's information
+// Update updates a role's information. It fails with VERSION_CONFLICT when
+// the role changed since it was read, i.e. its updated_at is no longer the
+// one of role.
 func (r *RoleRepository) Update(ctx context.Context, tenantID uuid.UUID, role *models.Role) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -182,8 +186,9 @@ func (r *RoleRepository) Update(ctx context.Context, tenantID uuid.UUID, role *m
 		SET display_name = $1,
 		    description = $2,
 		    parent_role_id = $3,
+		    level = $4,
 		    updated_at = NOW()
-		WHERE id = $4 AND is_system = false
+		WHERE id = $5 AND is_system = false AND updated_at = $6
 		RETURNING updated_at
 	`
 
@@ -192,19 +197,77 @@ func (r *RoleRepository) Update(ctx context.Context, tenantID uuid.UUID, role *m
 		role.DisplayName,
 		role.Description,
 		role.ParentRoleID,
+		role.Level,
 		role.ID,
+		role.UpdatedAt,
 	).Scan(&role.UpdatedAt)
 
 	if err == sql.ErrNoRows {
-		return fmt.Errorf("role not found or is a system role")
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM roles WHERE id = $1 AND is_system = false)", role.ID); err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if exists {
+			return utils.NewConflictError("VERSION_CONFLICT", "role was changed in the meantime")
+		}
+		return utils.NewNotFoundError("ROLE_NOT_FOUND", "role not found or is a system role")
 	}
 	if err != nil {
 		return fmt.Errorf("failed to update role: %w", err)
 	}
 
+	// Descendants sit one level below their parent. The depth bound guards
+	// against a cycle that slipped past validation.
+	levelsQuery := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, level, 0 AS depth FROM roles WHERE id = $1
+			UNION ALL
+			SELECT c.id, d.level + 1, d.depth + 1
+			FROM roles c
+			INNER JOIN descendants d ON c.parent_role_id = d.id
+			WHERE c.is_system = false AND d.depth < $2
+		)
+		UPDATE roles r
+		SET level = d.level, updated_at = NOW()
+		FROM descendants d
+		WHERE r.id = d.id AND d.depth > 0 AND r.level != d.level
+	`
+	if _, err := tx.ExecContext(ctx, levelsQuery, role.ID, models.MaxRoleHierarchyDepth); err != nil {
+		return fmt.Errorf("failed to update role levels: %w", err)
+	}
+
 	return tx.Commit()
 }
 
+// GetDescendantIDs returns the IDs of the roles inheriting from a role,
+// directly or through other roles
+func (r *RoleRepository) GetDescendantIDs(ctx context.Context, tenantID, roleID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// UNION drops rows already found, which ends the recursion on cycles
+	var ids []uuid.UUID
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id FROM roles WHERE parent_role_id = $1
+			UNION
+			SELECT c.id
+			FROM roles c
+			INNER JOIN descendants d ON c.parent_role_id = d.id
+		)
+		SELECT id FROM descendants WHERE id != $1
+	`
+
+	if err := tx.SelectContext(ctx, &ids, query, roleID); err != nil {
+		return nil, fmt.Errorf("failed to get descendant roles: %w", err)
+	}
+
+	return ids, nil
+}
+
 // Delete deletes a role (only custom roles)
 func (r *RoleRepository) Delete(ctx context.Context, tenantID, roleID uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
@@ -222,14 +285,14 @@ func (r *RoleRepository) Delete(ctx context.Context, tenantID, roleID uuid.UUID)
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("role not found or is a system role")
+		return utils.NewNotFoundError("ROLE_NOT_FOUND", "role not found or is a system role")
 	}
 
 	return tx.Commit()
 }
 
-// AssignPermissions assigns permissions to a role
-func (r *RoleRepository) AssignPermissions(ctx context.Context, tenantID, roleID uuid.UUID, permissionIDs []uuid.UUID, assignedBy uuid.UUID) error {
+// AssignPermissions replaces a role's permissions with the allowed and denied ones
+func (r *RoleRepository) AssignPermissions(ctx context.Context, tenantID, roleID uuid.UUID, permissionIDs, deniedPermissionIDs []uuid.UUID, assignedBy uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
 		return err
@@ -245,21 +308,27 @@ func (r *RoleRepository) AssignPermissions(ctx context.Context, tenantID, roleID
 
 	// Insert new permissions
 	insertQuery := `
-		INSERT INTO role_permissions (tenant_id, role_id, permission_id, created_by)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO role_permissions (tenant_id, role_id, permission_id, effect, created_by)
+		VALUES ($1, $2, $3, $4, $5)
 	`
 
 	for _, permID := range permissionIDs {
-		_, err = tx.ExecContext(ctx, insertQuery, tenantID, roleID, permID, assignedBy)
+		_, err = tx.ExecContext(ctx, insertQuery, tenantID, roleID, permID, models.EffectAllow, assignedBy)
 		if err != nil {
 			return fmt.Errorf("failed to assign permission: %w", err)
 		}
 	}
+	for _, permID := range deniedPermissionIDs {
+		_, err = tx.ExecContext(ctx, insertQuery, tenantID, roleID, permID, models.EffectDeny, assignedBy)
+		if err != nil {
+			return fmt.Errorf("failed to deny permission: %w", err)
+		}
+	}
 
 	return tx.Commit()
 }
 
-// GetPermissions retrieves all permissions for a role
+// GetPermissions retrieves all permissions for a role, with their effect
 func (r *RoleRepository) GetPermissions(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.Permission, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -269,11 +338,11 @@ func (r *RoleRepository) GetPermissions(ctx context.Context, tenantID, roleID uu
 
 	var permissions []models.Permission
 	query := `
-		SELECT p.*
+		SELECT p.*, rp.effect
 		FROM permissions p
 		INNER JOIN role_permissions rp ON p.id = rp.permission_id
 		WHERE rp.role_id = $1
-		ORDER BY p.category, p.resource, p.action
+		ORDER BY p.category, p.resource, p.action, rp.effect
 	`
 
 	err = tx.SelectContext(ctx, &permissions, query, roleID)
@@ -293,7 +362,12 @@ func (r *RoleRepository) CountUsers(ctx context.Context, tenantID, roleID uuid.U
 	defer tx.Rollback()
 
 	var count int
-	query := `SELECT COUNT(DISTINCT user_id) FROM user_roles WHERE role_id = $1`
+	query := `
+		SELECT COUNT(DISTINCT ur.user_id)
+		FROM user_roles ur
+		JOIN users u ON u.tenant_id = ur.tenant_id AND u.id = ur.user_id
+		WHERE ur.role_id = $1 AND u.deleted_at IS NULL
+	`
 
 	err = tx.GetContext(ctx, &count, query, roleID)
 	if err != nil {
@@ -354,30 +428,104 @@ func (r *RoleRepository) GetRoleWithDetails(ctx context.Context, tenantID, roleI
 	return role, nil
 }
 
-// ListWithDetails retrieves all roles with permission and user counts
+// ListWithDetails retrieves all roles with permission and user counts. The
+// permissions and user counts of all roles are read in one query each.
 func (r *RoleRepository) ListWithDetails(ctx context.Context, tenantID uuid.UUID) ([]models.Role, error) {
 	roles, err := r.List(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
+	if len(roles) == 0 {
+		return roles, nil
+	}
 
-	// Enrich each role with details
+	roleIDs := make([]uuid.UUID, len(roles))
 	for i := range roles {
-		// Get permissions
-		permissions, err := r.GetPermissions(ctx, tenantID, roles[i].ID)
-		if err != nil {
-			continue // Skip on error
-		}
-		roles[i].Permissions = permissions
-		roles[i].PermissionCount = len(permissions)
+		roleIDs[i] = roles[i].ID
+	}
 
-		// Get user count
-		userCount, err := r.CountUsers(ctx, tenantID, roles[i].ID)
-		if err != nil {
-			continue // Skip on error
-		}
-		roles[i].UserCount = userCount
+	permissions, err := r.GetPermissionsForRoles(ctx, tenantID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	userCounts, err := r.CountUsersByRole(ctx, tenantID, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		roles[i].Permissions = permissions[roles[i].ID]
+		roles[i].PermissionCount = len(roles[i].Permissions)
+		roles[i].UserCount = userCounts[roles[i].ID]
 	}
 
 	return roles, nil
 }
+
+// GetPermissionsForRoles retrieves the permissions of each of the roles in
+// one query, keyed by role ID
+func (r *RoleRepository) GetPermissionsForRoles(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID][]models.Permission, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		RoleID uuid.UUID `db:"role_id"`
+		models.Permission
+	}
+	query := `
+		SELECT rp.role_id, p.*, rp.effect
+		FROM permissions p
+		INNER JOIN role_permissions rp ON p.id = rp.permission_id
+		WHERE rp.role_id = ANY($1)
+		ORDER BY p.category, p.resource, p.action, rp.effect
+	`
+
+	err = tx.SelectContext(ctx, &rows, query, pq.Array(roleIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	permissions := make(map[uuid.UUID][]models.Permission, len(roleIDs))
+	for _, row := range rows {
+		permissions[row.RoleID] = append(permissions[row.RoleID], row.Permission)
+	}
+
+	return permissions, nil
+}
+
+// CountUsersByRole counts the users assigned to each of the roles in one
+// query, keyed by role ID. Roles without users are missing from the map.
+func (r *RoleRepository) CountUsersByRole(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		RoleID uuid.UUID `db:"role_id"`
+		Count  int       `db:"count"`
+	}
+	query := `
+		SELECT ur.role_id, COUNT(DISTINCT ur.user_id) AS count
+		FROM user_roles ur
+		JOIN users u ON u.tenant_id = ur.tenant_id AND u.id = ur.user_id
+		WHERE ur.role_id = ANY($1) AND u.deleted_at IS NULL
+		GROUP BY ur.role_id
+	`
+
+	err = tx.SelectContext(ctx, &rows, query, pq.Array(roleIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.RoleID] = row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// SalesRepository handles database operations for quotations, sales orders and invoices
+type SalesRepository struct {
+	db *sqlx.DB
+}
+
+// NewSalesRepository creates a new sales repository
+func NewSalesRepository(db *sqlx.DB) *SalesRepository {
+	return &SalesRepository{db: db}
+}
+
+// Create creates a sales document and its lines in a single transaction.
+// The document number is allocated from the per-tenant, per-type sequence.
+func (r *SalesRepository) Create(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seq, err := nextSequenceNumber(ctx, tx.Tx, tenantID, "sales:"+doc.DocumentType, `
+		SELECT COALESCE(MAX(sequence_number), 0)
+		FROM sales_documents
+		WHERE tenant_id = $1 AND document_type = $2
+	`, tenantID, doc.DocumentType)
+	if err != nil {
+		return err
+	}
+
+	doc.SequenceNumber = seq
+	doc.DocumentNumber = fmt.Sprintf("%s-%06d", models.SalesNumberPrefix(doc.DocumentType), seq)
+
+	query := `
+		INSERT INTO sales_documents (
+			tenant_id, document_type, document_number, sequence_number, source_document_id,
+			customer_name, customer_email, customer_address, status, issue_date, due_date,
+			currency, subtotal, discount_total, tax_total, total, notes, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		doc.DocumentType,
+		doc.DocumentNumber,
+		doc.SequenceNumber,
+		doc.SourceDocumentID,
+		doc.CustomerName,
+		doc.CustomerEmail,
+		doc.CustomerAddress,
+		doc.Status,
+		doc.IssueDate,
+		doc.DueDate,
+		doc.Currency,
+		doc.Subtotal,
+		doc.DiscountTotal,
+		doc.TaxTotal,
+		doc.Total,
+		doc.Notes,
+		doc.CreatedBy,
+	).Scan(&doc.ID, &doc.CreatedAt, &doc.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create sales document: %w", err)
+	}
+
+	doc.TenantID = tenantID
+
+	if err := insertSalesLines(ctx, tx.Tx, tenantID, doc); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a sales document with its lines
+func (r *SalesRepository) FindByID(ctx context.Context, tenantID, docID uuid.UUID) (*models.SalesDocument, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var doc models.SalesDocument
+	// Explicit tenant_id filter for defense in depth
+	query := `SELECT * FROM sales_documents WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &doc, query, tenantID, docID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SALES_DOCUMENT_NOT_FOUND", "sales document not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sales document: %w", err)
+	}
+
+	lines := []models.SalesDocumentLine{}
+	err = tx.SelectContext(ctx, &lines, `
+		SELECT * FROM sales_document_lines
+		WHERE tenant_id = $1 AND document_id = $2
+		ORDER BY line_number ASC
+	`, tenantID, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales document lines: %w", err)
+	}
+	doc.Lines = lines
+
+	return &doc, nil
+}
+
+// List retrieves sales documents (without lines) with filters and pagination
+func (r *SalesRepository) List(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filter models.SalesDocumentFilter,
+	limit, offset int,
+) ([]models.SalesDocument, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	argPos := 2
+
+	if filter.DocumentType != "" {
+		conditions = append(conditions, fmt.Sprintf("document_type = $%d", argPos))
+		args = append(args, filter.DocumentType)
+		argPos++
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, filter.Status)
+		argPos++
+	}
+	if filter.Search != "" {
+		// Accent-insensitive on customer names (see migration 048)
+		conditions = append(conditions, fmt.Sprintf("(document_number ILIKE '%%' || $%d || '%%' OR search_normalize(customer_name) LIKE '%%' || search_normalize($%d) || '%%')", argPos, argPos))
+		args = append(args, filter.Search)
+		argPos++
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM sales_documents WHERE ` + whereClause
+	if err := tx.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sales documents: %w", err)
+	}
+
+	docs := []models.SalesDocument{}
+	query := fmt.Sprintf(`
+		SELECT * FROM sales_documents
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argPos, argPos+1)
+	args = append(args, limit, offset)
+
+	if err := tx.SelectContext(ctx, &docs, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list sales documents: %w", err)
+	}
+
+	return docs, totalCount, nil
+}
+
+// FindBySource retrieves documents created from the given source document
+func (r *SalesRepository) FindBySource(ctx context.Context, tenantID, sourceID uuid.UUID) ([]models.SalesDocument, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	docs := []models.SalesDocument{}
+	query := `
+		SELECT * FROM sales_documents
+		WHERE tenant_id = $1 AND source_document_id = $2
+		ORDER BY created_at ASC
+	`
+
+	if err := tx.SelectContext(ctx, &docs, query, tenantID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to find derived sales documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// Lock locks a sales document until the end of the unit of work ctx is part
+// of, so documents derived from it are created one at a time. Outside a unit
+// of work the lock is released right away.
+func (r *SalesRepository) Lock(ctx context.Context, tenantID, docID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id uuid.UUID
+	err = tx.GetContext(ctx, &id, `SELECT id FROM sales_documents WHERE tenant_id = $1 AND id = $2 FOR UPDATE`, tenantID, docID)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SALES_DOCUMENT_NOT_FOUND", "sales document not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock sales document: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update updates a sales document header and replaces its lines
+func (r *SalesRepository) Update(ctx context.Context, tenantID uuid.UUID, doc *models.SalesDocument) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sales_documents
+		SET customer_name = $1,
+			customer_email = $2,
+			customer_address = $3,
+			issue_date = $4,
+			due_date = $5,
+			currency = $6,
+			subtotal = $7,
+			discount_total = $8,
+			tax_total = $9,
+			total = $10,
+			notes = $11,
+			updated_at = NOW()
+		WHERE tenant_id = $12 AND id = $13
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		doc.CustomerName,
+		doc.CustomerEmail,
+		doc.CustomerAddress,
+		doc.IssueDate,
+		doc.DueDate,
+		doc.Currency,
+		doc.Subtotal,
+		doc.DiscountTotal,
+		doc.TaxTotal,
+		doc.Total,
+		doc.Notes,
+		tenantID,
+		doc.ID,
+	).Scan(&doc.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SALES_DOCUMENT_NOT_FOUND", "sales document not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update sales document: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM sales_document_lines WHERE tenant_id = $1 AND document_id = $2`, tenantID, doc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear sales document lines: %w", err)
+	}
+
+	if err := insertSalesLines(ctx, tx.Tx, tenantID, doc); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus moves a document to a new status and stamps the matching timestamp.
+// The update only applies if the document is still in fromStatus, so concurrent
+// transitions cannot both succeed.
+func (r *SalesRepository) UpdateStatus(ctx context.Context, tenantID, docID uuid.UUID, fromStatus, toStatus string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sales_documents
+		SET status = $1,
+			confirmed_at = CASE WHEN $1 = 'confirmed' THEN NOW() ELSE confirmed_at END,
+			fulfilled_at = CASE WHEN $1 = 'fulfilled' THEN NOW() ELSE fulfilled_at END,
+			cancelled_at = CASE WHEN $1 = 'cancelled' THEN NOW() ELSE cancelled_at END,
+			updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND status = $4
+	`
+
+	result, err := tx.ExecContext(ctx, query, toStatus, tenantID, docID, fromStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update sales document status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("STATUS_CHANGED", "sales document status has changed, please reload")
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a sales document (lines cascade)
+func (r *SalesRepository) Delete(ctx context.Context, tenantID, docID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM sales_documents WHERE tenant_id = $1 AND id = $2`, tenantID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sales document: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("SALES_DOCUMENT_NOT_FOUND", "sales document not found")
+	}
+
+	return tx.Commit()
+}
+
+// insertSalesLines inserts the document's lines within an existing transaction
+func insertSalesLines(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, doc *models.SalesDocument) error {
+	query := `
+		INSERT INTO sales_document_lines (
+			tenant_id, document_id, line_number, product_code, description,
+			quantity, unit_price, discount_percent, tax_rate, line_total
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	for i := range doc.Lines {
+		line := &doc.Lines[i]
+		line.TenantID = tenantID
+		line.DocumentID = doc.ID
+		line.LineNumber = i + 1
+
+		err := tx.QueryRowContext(
+			ctx, query,
+			tenantID,
+			doc.ID,
+			line.LineNumber,
+			line.ProductCode,
+			line.Description,
+			line.Quantity,
+			line.UnitPrice,
+			line.DiscountPercent,
+			line.TaxRate,
+			line.LineTotal,
+		).Scan(&line.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create sales document line: %w", err)
+		}
+	}
+
+	return nil
+}
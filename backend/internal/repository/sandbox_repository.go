@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// SandboxRepository handles database operations for tenant sandboxes.
+// tenant_sandboxes is a catalog table (no RLS), like tenants.
+type SandboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewSandboxRepository creates a new sandbox repository
+func NewSandboxRepository(db *sqlx.DB) *SandboxRepository {
+	return &SandboxRepository{db: db}
+}
+
+// sandboxColumns selects a sandbox with the slug of its tenant
+const sandboxColumns = `
+	s.id, s.source_tenant_id, s.sandbox_tenant_id, t.slug AS sandbox_slug,
+	s.name, s.anonymize, s.status, s.error, s.expires_at, s.requested_by,
+	s.created_at, s.updated_at, s.started_at, s.completed_at, s.deleted_at
+`
+
+// Create creates the sandbox tenant (not yet accessible) and its pending
+// clone request in one transaction
+func (r *SandboxRepository) Create(ctx context.Context, tenant *models.Tenant, sandbox *models.TenantSandbox) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	settings := "{}"
+	if len(tenant.Settings) > 0 {
+		settings = string(tenant.Settings)
+	}
+
+	// The sandbox tenant stays pending until its data has been cloned
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenants (
+			slug, company_name, email, status, email_verified, email_verified_at,
+			plan_tier, data_region, settings, sandbox_of, sandbox_expires_at
+		) VALUES ($1, $2, $3, $4, true, NOW(), $5, $6, $7::jsonb, $8, $9)
+		RETURNING id, created_at, updated_at
+	`,
+		tenant.Slug,
+		tenant.CompanyName,
+		tenant.Email,
+		models.TenantStatusPendingVerification,
+		tenant.PlanTier,
+		tenant.DataRegion,
+		settings,
+		tenant.SandboxOf,
+		tenant.SandboxExpiresAt,
+	).Scan(&tenant.ID, &tenant.CreatedAt, &tenant.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox tenant: %w", err)
+	}
+
+	sandbox.SandboxTenantID = &tenant.ID
+	sandbox.SandboxSlug = &tenant.Slug
+	sandbox.Status = models.SandboxStatusPending
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tenant_sandboxes (
+			source_tenant_id, sandbox_tenant_id, name, anonymize, status, expires_at, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`,
+		sandbox.SourceTenantID,
+		sandbox.SandboxTenantID,
+		sandbox.Name,
+		sandbox.Anonymize,
+		sandbox.Status,
+		sandbox.ExpiresAt,
+		sandbox.RequestedBy,
+	).Scan(&sandbox.ID, &sandbox.CreatedAt, &sandbox.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a sandbox of a source tenant
+func (r *SandboxRepository) FindByID(ctx context.Context, sourceTenantID, id uuid.UUID) (*models.TenantSandbox, error) {
+	var sandbox models.TenantSandbox
+	query := `
+		SELECT ` + sandboxColumns + `
+		FROM tenant_sandboxes s
+		LEFT JOIN tenants t ON t.id = s.sandbox_tenant_id
+		WHERE s.source_tenant_id = $1 AND s.id = $2
+	`
+
+	err := r.db.GetContext(ctx, &sandbox, query, sourceTenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SANDBOX_NOT_FOUND", "sandbox not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sandbox: %w", err)
+	}
+
+	return &sandbox, nil
+}
+
+// List retrieves a source tenant's sandboxes, newest first. Deleted
+// sandboxes are only included when includeDeleted is set.
+func (r *SandboxRepository) List(ctx context.Context, sourceTenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error) {
+	where := `WHERE s.source_tenant_id = $1 AND ($2 OR s.status <> 'deleted')`
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM tenant_sandboxes s `+where, sourceTenantID, includeDeleted); err != nil {
+		return nil, 0, fmt.Errorf("failed to count sandboxes: %w", err)
+	}
+
+	sandboxes := []models.TenantSandbox{}
+	query := `
+		SELECT ` + sandboxColumns + `
+		FROM tenant_sandboxes s
+		LEFT JOIN tenants t ON t.id = s.sandbox_tenant_id
+		` + where + `
+		ORDER BY s.created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	if err := r.db.SelectContext(ctx, &sandboxes, query, sourceTenantID, includeDeleted, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	return sandboxes, totalCount, nil
+}
+
+// CountActive counts a source tenant's sandboxes that hold (or will hold) data
+func (r *SandboxRepository) CountActive(ctx context.Context, sourceTenantID uuid.UUID) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM tenant_sandboxes
+		WHERE source_tenant_id = $1 AND status IN ('pending', 'cloning', 'ready')
+	`
+
+	if err := r.db.GetContext(ctx, &count, query, sourceTenantID); err != nil {
+		return 0, fmt.Errorf("failed to count sandboxes: %w", err)
+	}
+
+	return count, nil
+}
+
+// ClaimNext marks the oldest pending sandbox as cloning and returns it.
+// Sandboxes left in cloning by an interrupted run are picked up again; the
+// caller must make sure only one worker claims at a time.
+func (r *SandboxRepository) ClaimNext(ctx context.Context) (*models.TenantSandbox, error) {
+	var sandbox models.TenantSandbox
+	query := `
+		UPDATE tenant_sandboxes
+		SET status = $1, started_at = NOW(), error = NULL, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM tenant_sandboxes
+			WHERE status IN ('pending', 'cloning')
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	err := r.db.GetContext(ctx, &sandbox, query, models.SandboxStatusCloning)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sandbox: %w", err)
+	}
+
+	return &sandbox, nil
+}
+
+// MarkReady activates the sandbox tenant once its data has been cloned
+func (r *SandboxRepository) MarkReady(ctx context.Context, sandbox *models.TenantSandbox) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tenants
+		SET status = $1, activated_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`, models.TenantStatusActive, sandbox.SandboxTenantID)
+	if err != nil {
+		return fmt.Errorf("failed to activate sandbox tenant: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tenant_sandboxes
+		SET status = $1, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`, models.SandboxStatusReady, sandbox.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark sandbox ready: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// MarkFailed records why a clone failed
+func (r *SandboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, errMsg string) error {
+	query := `
+		UPDATE tenant_sandboxes
+		SET status = $1, error = $2, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $3
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, models.SandboxStatusFailed, errMsg, id); err != nil {
+		return fmt.Errorf("failed to mark sandbox failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDeleted records that a sandbox's tenant and data were removed
+func (r *SandboxRepository) MarkDeleted(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE tenant_sandboxes
+		SET status = $1, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, models.SandboxStatusDeleted, id); err != nil {
+		return fmt.Errorf("failed to mark sandbox deleted: %w", err)
+	}
+
+	return nil
+}
+
+// ListExpired retrieves expired sandboxes that still have to be removed.
+// Sandboxes that are being cloned are skipped until the clone has finished.
+func (r *SandboxRepository) ListExpired(ctx context.Context, limit int) ([]models.TenantSandbox, error) {
+	sandboxes := []models.TenantSandbox{}
+	query := `
+		SELECT * FROM tenant_sandboxes
+		WHERE expires_at < NOW() AND status IN ('pending', 'ready', 'failed')
+		ORDER BY expires_at
+		LIMIT $1
+	`
+
+	if err := r.db.SelectContext(ctx, &sandboxes, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list expired sandboxes: %w", err)
+	}
+
+	return sandboxes, nil
+}
+
+// Anonymize replaces personal data in a freshly cloned sandbox. It runs in the
+// clone transaction. keepUserID is left untouched so the requester can still
+// sign in; every other user gets a placeholder identity and can no longer sign
+// in with their production password. Customer and supplier names are replaced
+// by stable pseudonyms, so documents of the same customer stay grouped.
+func (r *SandboxRepository) Anonymize(ctx context.Context, tx *sqlx.Tx, tenantID, keepUserID uuid.UUID) error {
+	statements := []struct {
+		table string
+		query string
+		args  []interface{}
+	}{
+		{"users", `
+			UPDATE users
+			SET email = 'user-' || id || '@sandbox.invalid',
+			    first_name = 'Sandbox',
+			    last_name = 'User ' || upper(left(md5(id::text), 6)),
+			    phone = NULL,
+			    avatar_url = NULL,
+			    password_hash = '!',
+			    reset_token = NULL,
+			    reset_token_expires_at = NULL,
+			    last_login_ip = NULL,
+			    two_factor_enabled = FALSE,
+			    two_factor_secret = NULL,
+			    two_factor_backup_codes = NULL,
+			    two_factor_recovery_email = NULL,
+			    custom_fields = '{}'
+			WHERE tenant_id = $1 AND id <> $2
+		`, []interface{}{tenantID, keepUserID}},
+		{"suppliers", `
+			UPDATE suppliers
+			SET name = 'Supplier ' || upper(left(md5(name), 8)),
+			    email = CASE WHEN email IS NULL THEN NULL ELSE 'supplier-' || left(md5(lower(email)), 12) || '@sandbox.invalid' END,
+			    phone = NULL,
+			    address = NULL,
+			    tax_id = NULL,
+			    notes = NULL
+			WHERE tenant_id = $1
+		`, []interface{}{tenantID}},
+		{"sales_documents", `
+			UPDATE sales_documents
+			SET customer_name = 'Customer ' || upper(left(md5(customer_name), 8)),
+			    customer_email = CASE WHEN customer_email IS NULL THEN NULL ELSE 'customer-' || left(md5(lower(customer_email)), 12) || '@sandbox.invalid' END,
+			    customer_address = NULL,
+			    notes = NULL
+			WHERE tenant_id = $1
+		`, []interface{}{tenantID}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("failed to anonymize %s: %w", stmt.table, err)
+		}
+	}
+
+	return nil
+}
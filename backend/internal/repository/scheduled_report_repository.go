@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// ScheduledReportRepository handles database operations for scheduled
+// reports and reads the data they report on
+type ScheduledReportRepository struct {
+	db *sqlx.DB
+}
+
+// NewScheduledReportRepository creates a new scheduled report repository
+func NewScheduledReportRepository(db *sqlx.DB) *ScheduledReportRepository {
+	return &ScheduledReportRepository{db: db}
+}
+
+// Create saves a new scheduled report
+func (r *ScheduledReportRepository) Create(ctx context.Context, report *models.ScheduledReport) error {
+	tx, err := database.WithTenantContext(ctx, r.db, report.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO scheduled_reports (
+			tenant_id, name, report_type, format, schedule, recipients, enabled, next_run_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		report.TenantID,
+		report.Name,
+		report.ReportType,
+		report.Format,
+		report.Schedule,
+		report.Recipients,
+		report.Enabled,
+		report.NextRunAt,
+		report.CreatedBy,
+	).Scan(&report.ID, &report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled report: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update replaces a scheduled report's settings and next run time
+func (r *ScheduledReportRepository) Update(ctx context.Context, report *models.ScheduledReport) error {
+	tx, err := database.WithTenantContext(ctx, r.db, report.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE scheduled_reports
+		SET name = $1, report_type = $2, format = $3, schedule = $4, recipients = $5,
+		    enabled = $6, next_run_at = $7
+		WHERE tenant_id = $8 AND id = $9
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		report.Name,
+		report.ReportType,
+		report.Format,
+		report.Schedule,
+		report.Recipients,
+		report.Enabled,
+		report.NextRunAt,
+		report.TenantID,
+		report.ID,
+	).Scan(&report.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SCHEDULED_REPORT_NOT_FOUND", "scheduled report not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update scheduled report: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a scheduled report
+func (r *ScheduledReportRepository) Delete(ctx context.Context, tenantID, reportID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM scheduled_reports WHERE tenant_id = $1 AND id = $2`, tenantID, reportID)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled report: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("SCHEDULED_REPORT_NOT_FOUND", "scheduled report not found")
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a scheduled report
+func (r *ScheduledReportRepository) FindByID(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var report models.ScheduledReport
+	err = tx.GetContext(ctx, &report, `SELECT * FROM scheduled_reports WHERE tenant_id = $1 AND id = $2`, tenantID, reportID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SCHEDULED_REPORT_NOT_FOUND", "scheduled report not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scheduled report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// List retrieves a tenant's scheduled reports by name
+func (r *ScheduledReportRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM scheduled_reports WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count scheduled reports: %w", err)
+	}
+
+	reports := []models.ScheduledReport{}
+	query := `
+		SELECT * FROM scheduled_reports
+		WHERE tenant_id = $1
+		ORDER BY name, created_at
+		LIMIT $2 OFFSET $3
+	`
+
+	if err := tx.SelectContext(ctx, &reports, query, tenantID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list scheduled reports: %w", err)
+	}
+
+	return reports, totalCount, nil
+}
+
+// ClaimDue locks the next enabled report past its next run time, across
+// tenants, skipping rows another worker already holds and reports of paused
+// tenants. It runs in a transaction bypassing RLS. Returns nil when nothing
+// is due.
+func (r *ScheduledReportRepository) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID) (*models.ScheduledReport, error) {
+	var report models.ScheduledReport
+	query := `
+		SELECT * FROM scheduled_reports
+		WHERE enabled = TRUE AND next_run_at <= NOW() AND tenant_id != ALL($1::uuid[])
+		ORDER BY next_run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &report, query, pq.Array(paused))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim scheduled report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// Reschedule sets the next run time of a claimed report
+func (r *ScheduledReportRepository) Reschedule(ctx context.Context, tx *sqlx.Tx, report *models.ScheduledReport) error {
+	query := `UPDATE scheduled_reports SET next_run_at = $1 WHERE tenant_id = $2 AND id = $3`
+	if _, err := tx.ExecContext(ctx, query, report.NextRunAt, report.TenantID, report.ID); err != nil {
+		return fmt.Errorf("failed to reschedule report: %w", err)
+	}
+	return nil
+}
+
+// RecordRun records the outcome of a run. A successful run moves the start
+// of the next period to periodEnd; after a failure, the next run covers the
+// failed period too.
+func (r *ScheduledReportRepository) RecordRun(ctx context.Context, tenantID, reportID uuid.UUID, periodEnd time.Time, runErr error) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE scheduled_reports
+		SET last_status = $1, last_error = $2, last_run_at = COALESCE($3, last_run_at)
+		WHERE tenant_id = $4 AND id = $5
+	`
+
+	status, lastError, lastRunAt := models.ReportRunSucceeded, (*string)(nil), &periodEnd
+	if runErr != nil {
+		message := runErr.Error()
+		status, lastError, lastRunAt = models.ReportRunFailed, &message, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, query, status, lastError, lastRunAt, tenantID, reportID); err != nil {
+		return fmt.Errorf("failed to record scheduled report run: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListActiveUsers retrieves the users who signed in or were active between
+// start and end, by email, at most limit
+func (r *ScheduledReportRepository) ListActiveUsers(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportActiveUser, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	users := []models.ReportActiveUser{}
+	query := `
+		SELECT u.email, u.first_name, u.last_name, d.name AS department_name,
+		       u.last_login_at, u.last_active_at
+		FROM users u
+		LEFT JOIN departments d ON d.tenant_id = u.tenant_id AND d.id = u.department_id
+		WHERE u.tenant_id = $1 AND u.deleted_at IS NULL
+		  AND (u.last_login_at >= $2 OR u.last_active_at >= $2 OR EXISTS (
+		      SELECT 1 FROM audit_logs al
+		      WHERE al.tenant_id = u.tenant_id AND al.user_id = u.id
+		        AND al.action = $4 AND al.status = 'success'
+		        AND al.created_at >= $2 AND al.created_at < $3
+		  ))
+		ORDER BY u.email
+		LIMIT $5
+	`
+
+	if err := tx.SelectContext(ctx, &users, query, tenantID, start, end, models.ActionUserLogin, limit); err != nil {
+		return nil, fmt.Errorf("failed to list active users: %w", err)
+	}
+
+	return users, nil
+}
+
+// ListLoginDays counts the sign-ins and failed sign-ins between start and
+// end per day (UTC)
+func (r *ScheduledReportRepository) ListLoginDays(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportLoginDay, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	days := []models.ReportLoginDay{}
+	query := `
+		SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,
+		       COUNT(*) FILTER (WHERE action = $4 AND status = 'success') AS logins,
+		       COUNT(*) FILTER (WHERE action = $5) AS failed_logins,
+		       COUNT(DISTINCT user_id) FILTER (WHERE action = $4 AND status = 'success') AS users
+		FROM audit_logs
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+		  AND action IN ($4, $5)
+		GROUP BY day
+		ORDER BY day
+	`
+
+	if err := tx.SelectContext(ctx, &days, query, tenantID, start, end, models.ActionUserLogin, models.ActionUserLoginFailed); err != nil {
+		return nil, fmt.Errorf("failed to count sign-ins: %w", err)
+	}
+
+	return days, nil
+}
+
+// ListFailedLogins retrieves the failed sign-ins between start and end,
+// latest first, at most limit
+func (r *ScheduledReportRepository) ListFailedLogins(ctx context.Context, tenantID uuid.UUID, start, end time.Time, limit int) ([]models.ReportFailedLogin, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	logins := []models.ReportFailedLogin{}
+	query := `
+		SELECT al.created_at, u.email, al.metadata->>'reason' AS reason,
+		       host(al.ip_address) AS ip_address, al.user_agent
+		FROM audit_logs al
+		LEFT JOIN users u ON u.tenant_id = al.tenant_id AND u.id = al.user_id
+		WHERE al.tenant_id = $1 AND al.created_at >= $2 AND al.created_at < $3
+		  AND al.action = $4
+		ORDER BY al.created_at DESC
+		LIMIT $5
+	`
+
+	if err := tx.SelectContext(ctx, &logins, query, tenantID, start, end, models.ActionUserLoginFailed, limit); err != nil {
+		return nil, fmt.Errorf("failed to list failed sign-ins: %w", err)
+	}
+
+	return logins, nil
+}
+
+// ListAuditActions counts the audit log entries between start and end per
+// action, most frequent first
+func (r *ScheduledReportRepository) ListAuditActions(ctx context.Context, tenantID uuid.UUID, start, end time.Time) ([]models.ReportAuditAction, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	actions := []models.ReportAuditAction{}
+	query := `
+		SELECT action,
+		       COUNT(*) FILTER (WHERE status = 'success') AS successes,
+		       COUNT(*) FILTER (WHERE status = 'failure') AS failures,
+		       COUNT(DISTINCT user_id) AS actors
+		FROM audit_logs
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY action
+		ORDER BY COUNT(*) DESC, action
+	`
+
+	if err := tx.SelectContext(ctx, &actions, query, tenantID, start, end); err != nil {
+		return nil, fmt.Errorf("failed to count audit actions: %w", err)
+	}
+
+	return actions, nil
+}
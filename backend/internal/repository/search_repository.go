@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// searchSimilarityThreshold is the pg_trgm word similarity from which a
+// record matches a query it does not contain, e.g. with a typo
+const searchSimilarityThreshold = "0.4"
+
+// The expressions below must stay identical to the indexes of migrations 048
+// and 073
+const (
+	userSearchText       = `u.first_name || ' ' || u.last_name || ' ' || u.email`
+	productSearchText    = `COALESCE(l.product_code, '') || ' ' || l.description`
+	roleSearchText       = `r.display_name || ' ' || COALESCE(r.description, '')`
+	departmentSearchText = `d.name || ' ' || COALESCE(d.description, '')`
+)
+
+// SearchRepository runs multilingual searches: prefix full-text matches on
+// normalized text first, then pg_trgm similarity matches
+type SearchRepository struct {
+	db *sqlx.DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *sqlx.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SearchUsers searches live users by name and email
+func (r *SearchRepository) SearchUsers(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error) {
+	sql := `
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT
+			'` + models.SearchTypeUser + `' AS type,
+			u.id,
+			u.first_name || ' ' || u.last_name AS title,
+			u.email AS subtitle,
+			'/users/' || u.id AS path,
+			CASE WHEN search_vector(` + userSearchText + `) @@ q.ts
+				THEN 1 + ts_rank(search_vector(` + userSearchText + `), q.ts)
+				ELSE word_similarity(q.text, search_normalize(` + userSearchText + `))
+			END AS score,
+			NOT search_vector(` + userSearchText + `) @@ q.ts AS fuzzy
+		FROM users u, q
+		WHERE u.tenant_id = $1 AND u.deleted_at IS NULL
+			AND (search_vector(` + userSearchText + `) @@ q.ts
+				OR search_normalize(` + userSearchText + `) %> q.text)
+		ORDER BY score DESC, title ASC
+		LIMIT $4
+	`
+
+	return r.search(ctx, tenantID, "users", sql, query, config, limit)
+}
+
+// SearchCustomers searches the customer names of sales documents, one result
+// per name
+func (r *SearchRepository) SearchCustomers(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error) {
+	sql := `
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT
+			'` + models.SearchTypeCustomer + `' AS type,
+			NULL::uuid AS id,
+			d.customer_name AS title,
+			COALESCE(MAX(d.customer_email), '') AS subtitle,
+			'' AS path,
+			MAX(CASE WHEN search_vector(d.customer_name) @@ q.ts
+				THEN 1 + ts_rank(search_vector(d.customer_name), q.ts)
+				ELSE word_similarity(q.text, search_normalize(d.customer_name))
+			END) AS score,
+			NOT bool_or(search_vector(d.customer_name) @@ q.ts) AS fuzzy
+		FROM sales_documents d, q
+		WHERE d.tenant_id = $1
+			AND (search_vector(d.customer_name) @@ q.ts
+				OR search_normalize(d.customer_name) %> q.text)
+		GROUP BY d.customer_name
+		ORDER BY score DESC, title ASC
+		LIMIT $4
+	`
+
+	return r.search(ctx, tenantID, "customers", sql, query, config, limit)
+}
+
+// SearchProducts searches the product codes and descriptions of sales lines,
+// one result per product code with its latest description
+func (r *SearchRepository) SearchProducts(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error) {
+	sql := `
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT
+			'` + models.SearchTypeProduct + `' AS type,
+			NULL::uuid AS id,
+			l.product_code AS title,
+			(array_agg(l.description ORDER BY d.created_at DESC))[1] AS subtitle,
+			'' AS path,
+			MAX(CASE WHEN search_vector_multilingual(` + productSearchText + `) @@ q.ts
+				THEN 1 + ts_rank(search_vector_multilingual(` + productSearchText + `), q.ts)
+				ELSE word_similarity(q.text, search_normalize(` + productSearchText + `))
+			END) AS score,
+			NOT bool_or(search_vector_multilingual(` + productSearchText + `) @@ q.ts) AS fuzzy
+		FROM sales_document_lines l
+		JOIN sales_documents d ON d.tenant_id = l.tenant_id AND d.id = l.document_id
+		CROSS JOIN q
+		WHERE l.tenant_id = $1 AND COALESCE(TRIM(l.product_code), '') != ''
+			AND (search_vector_multilingual(` + productSearchText + `) @@ q.ts
+				OR search_normalize(` + productSearchText + `) %> q.text)
+		GROUP BY l.product_code
+		ORDER BY score DESC, title ASC
+		LIMIT $4
+	`
+
+	return r.search(ctx, tenantID, "products", sql, query, config, limit)
+}
+
+// SearchRoles searches roles by display name and description
+func (r *SearchRepository) SearchRoles(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error) {
+	sql := `
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT
+			'` + models.SearchTypeRole + `' AS type,
+			r.id,
+			r.display_name AS title,
+			COALESCE(r.description, '') AS subtitle,
+			'/roles/' || r.id AS path,
+			CASE WHEN search_vector_multilingual(` + roleSearchText + `) @@ q.ts
+				THEN 1 + ts_rank(search_vector_multilingual(` + roleSearchText + `), q.ts)
+				ELSE word_similarity(q.text, search_normalize(` + roleSearchText + `))
+			END AS score,
+			NOT search_vector_multilingual(` + roleSearchText + `) @@ q.ts AS fuzzy
+		FROM roles r, q
+		WHERE r.tenant_id = $1
+			AND (search_vector_multilingual(` + roleSearchText + `) @@ q.ts
+				OR search_normalize(` + roleSearchText + `) %> q.text)
+		ORDER BY score DESC, title ASC
+		LIMIT $4
+	`
+
+	return r.search(ctx, tenantID, "roles", sql, query, config, limit)
+}
+
+// SearchDepartments searches departments by name and description
+func (r *SearchRepository) SearchDepartments(ctx context.Context, tenantID uuid.UUID, query, config string, limit int) ([]models.SearchResult, error) {
+	sql := `
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT
+			'` + models.SearchTypeDepartment + `' AS type,
+			d.id,
+			d.name AS title,
+			COALESCE(d.description, '') AS subtitle,
+			'/departments/' || d.id AS path,
+			CASE WHEN search_vector_multilingual(` + departmentSearchText + `) @@ q.ts
+				THEN 1 + ts_rank(search_vector_multilingual(` + departmentSearchText + `), q.ts)
+				ELSE word_similarity(q.text, search_normalize(` + departmentSearchText + `))
+			END AS score,
+			NOT search_vector_multilingual(` + departmentSearchText + `) @@ q.ts AS fuzzy
+		FROM departments d, q
+		WHERE d.tenant_id = $1
+			AND (search_vector_multilingual(` + departmentSearchText + `) @@ q.ts
+				OR search_normalize(` + departmentSearchText + `) %> q.text)
+		ORDER BY score DESC, title ASC
+		LIMIT $4
+	`
+
+	return r.search(ctx, tenantID, "departments", sql, query, config, limit)
+}
+
+// search runs a search query with the similarity threshold set for its
+// transaction
+func (r *SearchRepository) search(ctx context.Context, tenantID uuid.UUID, what, sql, query, config string, limit int) ([]models.SearchResult, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, searchSimilarityThreshold); err != nil {
+		return nil, fmt.Errorf("failed to set search threshold: %w", err)
+	}
+
+	results := []models.SearchResult{}
+	if err := tx.SelectContext(ctx, &results, sql, tenantID, query, config, limit); err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", what, err)
+	}
+
+	return results, nil
+}
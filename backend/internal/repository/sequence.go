@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+)
+
+// nextSequenceNumber allocates the next per-tenant document number within a
+// tenant transaction. scope identifies the counter (e.g. "sales:quote") and
+// query must return the current maximum for that counter.
+//
+// A transaction-scoped advisory lock serializes allocation per tenant and scope,
+// so concurrent creates cannot pick the same number; the lock is released on
+// commit or rollback.
+func nextSequenceNumber(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, scope, query string, args ...interface{}) (int, error) {
+	if err := database.AdvisoryXactLock(ctx, tx, tenantID.String()+":"+scope); err != nil {
+		return 0, fmt.Errorf("failed to lock document sequence: %w", err)
+	}
+
+	var current int
+	if err := tx.GetContext(ctx, &current, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to allocate document number: %w", err)
+	}
+
+	return current + 1, nil
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
 // SessionRepository handles database operations for sessions
@@ -22,14 +24,21 @@ func NewSessionRepository(db *sqlx.DB) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
-// Create creates a new session with RLS
-func (r *SessionRepository) Create(ctx context.Context, req *models.SessionCreateRequest) (*models.Session, error) {
+// Create creates a new session with RLS, enforcing the user's concurrent
+// session limit: past req.Limit.MaxSessions it either refuses the session or
+// deletes the least recently active ones, whose IDs it returns.
+func (r *SessionRepository) Create(ctx context.Context, req *models.SessionCreateRequest) (*models.Session, []uuid.UUID, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, req.TenantID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer tx.Rollback()
 
+	evicted, err := r.enforceSessionLimit(ctx, tx.Tx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	session := &models.Session{
 		TenantID:       req.TenantID,
 		UserID:         req.UserID,
@@ -66,14 +75,54 @@ func (r *SessionRepository) Create(ctx context.Context, req *models.SessionCreat
 	).Scan(&session.ID, &session.CreatedAt)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return session, evicted, nil
+}
+
+// enforceSessionLimit makes room for a new session of a user within their
+// concurrent session limit, returning the IDs of the sessions it deleted
+func (r *SessionRepository) enforceSessionLimit(ctx context.Context, tx *sqlx.Tx, req *models.SessionCreateRequest) ([]uuid.UUID, error) {
+	if req.Limit.MaxSessions <= 0 {
+		return nil, nil
+	}
+
+	// Concurrent sign-ins of the user wait for each other, so they cannot
+	// both take the last free slot
+	if err := database.AdvisoryXactLock(ctx, tx, req.TenantID.String()+":sessions:"+req.UserID.String()); err != nil {
 		return nil, err
 	}
 
-	return session, nil
+	var active []uuid.UUID
+	query := `
+		SELECT id FROM sessions
+		WHERE user_id = $1
+		  AND expires_at > NOW()
+		ORDER BY last_activity_at ASC, created_at ASC
+	`
+	if err := tx.SelectContext(ctx, &active, query, req.UserID); err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	excess := len(active) - req.Limit.MaxSessions + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+	if req.Limit.Policy == models.SessionLimitPolicyReject {
+		return nil, utils.NewConflictError("SESSION_LIMIT_REACHED", "You are signed in on too many devices. Sign out on one of them and try again.")
+	}
+
+	evicted := active[:excess]
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE id = ANY($1)`, pq.Array(evicted)); err != nil {
+		return nil, fmt.Errorf("failed to evict sessions: %w", err)
+	}
+
+	return evicted, nil
 }
 
 // FindByTokenHash retrieves a session by token hash
@@ -94,7 +143,7 @@ func (r *SessionRepository) FindByTokenHash(ctx context.Context, tenantID uuid.U
 
 	err = tx.GetContext(ctx, &session, query, tokenHash)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("session not found or expired")
+		return nil, utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found or expired")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find session: %w", err)
@@ -116,7 +165,7 @@ func (r *SessionRepository) FindByID(ctx context.Context, tenantID, sessionID uu
 
 	err = tx.GetContext(ctx, &session, query, sessionID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("session not found")
+		return nil, utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find session: %w", err)
@@ -127,7 +176,7 @@ func (r *SessionRepository) FindByID(ctx context.Context, tenantID, sessionID uu
 
 // ListByUser retrieves all sessions for a user
 func (r *SessionRepository) ListByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +199,7 @@ func (r *SessionRepository) ListByUser(ctx context.Context, tenantID, userID uui
 
 // ListActiveSessions retrieves all active (non-expired) sessions for a user
 func (r *SessionRepository) ListActiveSessions(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Session, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +243,41 @@ func (r *SessionRepository) UpdateActivity(ctx context.Context, tenantID, sessio
 	return tx.Commit()
 }
 
+// UpdateActivityBatch sets the last activity time of a tenant's sessions
+// from buffered heartbeats. Times older than the stored one are ignored.
+func (r *SessionRepository) UpdateActivityBatch(ctx context.Context, tenantID uuid.UUID, activity map[uuid.UUID]time.Time) error {
+	if len(activity) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(activity))
+	times := make([]int64, 0, len(activity))
+	for id, at := range activity {
+		ids = append(ids, id.String())
+		times = append(times, at.UnixMilli())
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sessions s
+		SET last_activity_at = to_timestamp(v.at_ms / 1000.0)
+		FROM unnest($1::uuid[], $2::bigint[]) AS v(id, at_ms)
+		WHERE s.id = v.id
+		  AND s.last_activity_at < to_timestamp(v.at_ms / 1000.0)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, pq.Array(ids), pq.Array(times)); err != nil {
+		return fmt.Errorf("failed to update activity: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 // Delete deletes a specific session (logout)
 func (r *SessionRepository) Delete(ctx context.Context, tenantID, sessionID uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
@@ -211,7 +295,7 @@ func (r *SessionRepository) Delete(ctx context.Context, tenantID, sessionID uuid
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("session not found")
+		return utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found")
 	}
 
 	return tx.Commit()
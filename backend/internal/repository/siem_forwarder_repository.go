@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// SIEMForwarderRepository handles database operations for SIEM forwarders
+// and reads the audit log events they forward
+type SIEMForwarderRepository struct {
+	db *sqlx.DB
+}
+
+// NewSIEMForwarderRepository creates a new SIEM forwarder repository
+func NewSIEMForwarderRepository(db *sqlx.DB) *SIEMForwarderRepository {
+	return &SIEMForwarderRepository{db: db}
+}
+
+// Create saves a new SIEM forwarder. Its cursor starts now, so it forwards
+// the events logged from then on.
+func (r *SIEMForwarderRepository) Create(ctx context.Context, forwarder *models.SIEMForwarder) error {
+	tx, err := database.WithTenantContext(ctx, r.db, forwarder.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO siem_forwarders (
+			tenant_id, name, transport, format, endpoint, topic, auth_header, actions, enabled, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, cursor_at, next_attempt_at, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		forwarder.TenantID,
+		forwarder.Name,
+		forwarder.Transport,
+		forwarder.Format,
+		forwarder.Endpoint,
+		forwarder.Topic,
+		forwarder.AuthHeader,
+		forwarder.Actions,
+		forwarder.Enabled,
+		forwarder.CreatedBy,
+	).Scan(&forwarder.ID, &forwarder.CursorAt, &forwarder.NextAttemptAt, &forwarder.CreatedAt, &forwarder.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create SIEM forwarder: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update replaces a SIEM forwarder's settings. Changing them retries sending
+// at once; the cursor is kept.
+func (r *SIEMForwarderRepository) Update(ctx context.Context, forwarder *models.SIEMForwarder) error {
+	tx, err := database.WithTenantContext(ctx, r.db, forwarder.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE siem_forwarders
+		SET name = $1, transport = $2, format = $3, endpoint = $4, topic = $5,
+		    auth_header = $6, actions = $7, enabled = $8, next_attempt_at = NOW()
+		WHERE tenant_id = $9 AND id = $10
+		RETURNING next_attempt_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		forwarder.Name,
+		forwarder.Transport,
+		forwarder.Format,
+		forwarder.Endpoint,
+		forwarder.Topic,
+		forwarder.AuthHeader,
+		forwarder.Actions,
+		forwarder.Enabled,
+		forwarder.TenantID,
+		forwarder.ID,
+	).Scan(&forwarder.NextAttemptAt, &forwarder.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SIEM_FORWARDER_NOT_FOUND", "SIEM forwarder not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update SIEM forwarder: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a SIEM forwarder
+func (r *SIEMForwarderRepository) Delete(ctx context.Context, tenantID, forwarderID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM siem_forwarders WHERE tenant_id = $1 AND id = $2`, tenantID, forwarderID)
+	if err != nil {
+		return fmt.Errorf("failed to delete SIEM forwarder: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return utils.NewNotFoundError("SIEM_FORWARDER_NOT_FOUND", "SIEM forwarder not found")
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a SIEM forwarder
+func (r *SIEMForwarderRepository) FindByID(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var forwarder models.SIEMForwarder
+	err = tx.GetContext(ctx, &forwarder, `SELECT * FROM siem_forwarders WHERE tenant_id = $1 AND id = $2`, tenantID, forwarderID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SIEM_FORWARDER_NOT_FOUND", "SIEM forwarder not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find SIEM forwarder: %w", err)
+	}
+
+	forwarder.HasAuthHeader = forwarder.AuthHeader != nil
+	return &forwarder, nil
+}
+
+// List retrieves a tenant's SIEM forwarders by name
+func (r *SIEMForwarderRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	forwarders := []models.SIEMForwarder{}
+	query := `
+		SELECT * FROM siem_forwarders
+		WHERE tenant_id = $1
+		ORDER BY name, created_at
+	`
+
+	if err := tx.SelectContext(ctx, &forwarders, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list SIEM forwarders: %w", err)
+	}
+
+	for i := range forwarders {
+		forwarders[i].HasAuthHeader = forwarders[i].AuthHeader != nil
+	}
+	return forwarders, nil
+}
+
+// ClaimDue locks the next enabled forwarder due for an attempt at or before
+// dueBy, across tenants, skipping rows another worker already holds and
+// forwarders of paused tenants. It runs in a transaction bypassing RLS.
+// Returns nil when nothing is due.
+func (r *SIEMForwarderRepository) ClaimDue(ctx context.Context, tx *sqlx.Tx, paused []uuid.UUID, dueBy time.Time) (*models.SIEMForwarder, error) {
+	var forwarder models.SIEMForwarder
+	query := `
+		SELECT * FROM siem_forwarders
+		WHERE enabled = TRUE AND next_attempt_at <= $1 AND tenant_id != ALL($2::uuid[])
+		ORDER BY next_attempt_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &forwarder, query, dueBy, pq.Array(paused))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim SIEM forwarder: %w", err)
+	}
+
+	forwarder.HasAuthHeader = forwarder.AuthHeader != nil
+	return &forwarder, nil
+}
+
+// ListEvents retrieves the next audit log events matching a claimed
+// forwarder's actions after its cursor and created before until, oldest
+// first, at most limit
+func (r *SIEMForwarderRepository) ListEvents(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, until time.Time, limit int) ([]models.SIEMEvent, error) {
+	events := []models.SIEMEvent{}
+	query := `
+		SELECT al.id, al.tenant_id, al.created_at, al.action, al.status, al.user_id,
+		       u.email AS user_email, al.resource_type, al.resource_id,
+		       host(al.ip_address) AS ip_address, al.user_agent, al.metadata
+		FROM audit_logs al
+		LEFT JOIN users u ON u.tenant_id = al.tenant_id AND u.id = al.user_id
+		WHERE al.tenant_id = $1
+		  AND al.created_at >= $2 AND (al.created_at, al.id) > ($2, $3)
+		  AND al.created_at < $4
+		  AND (cardinality($5::text[]) = 0 OR al.action LIKE ANY($5::text[]))
+		ORDER BY al.created_at, al.id
+		LIMIT $6
+	`
+
+	err := tx.SelectContext(ctx, &events, query,
+		forwarder.TenantID, forwarder.CursorAt, forwarder.CursorID, until,
+		pq.Array(actionPatterns(forwarder.Actions)), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events to forward: %w", err)
+	}
+
+	return events, nil
+}
+
+// CountEventsBefore counts the audit log events matching a claimed
+// forwarder's actions after its cursor and created before cutoff
+func (r *SIEMForwarderRepository) CountEventsBefore(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder, cutoff time.Time) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM audit_logs
+		WHERE tenant_id = $1
+		  AND created_at >= $2 AND (created_at, id) > ($2, $3)
+		  AND created_at < $4
+		  AND (cardinality($5::text[]) = 0 OR action LIKE ANY($5::text[]))
+	`
+
+	err := tx.GetContext(ctx, &count, query,
+		forwarder.TenantID, forwarder.CursorAt, forwarder.CursorID, cutoff,
+		pq.Array(actionPatterns(forwarder.Actions)),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count lagging audit events: %w", err)
+	}
+
+	return count, nil
+}
+
+// SaveProgress stores a claimed forwarder's cursor, next attempt, failures
+// and totals
+func (r *SIEMForwarderRepository) SaveProgress(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder) error {
+	query := `
+		UPDATE siem_forwarders
+		SET cursor_at = $1, cursor_id = $2, next_attempt_at = $3, failures = $4,
+		    last_forwarded_at = $5, last_error = $6, events_forwarded = $7, events_dropped = $8
+		WHERE tenant_id = $9 AND id = $10
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		forwarder.CursorAt,
+		forwarder.CursorID,
+		forwarder.NextAttemptAt,
+		forwarder.Failures,
+		forwarder.LastForwardedAt,
+		forwarder.LastError,
+		forwarder.EventsForwarded,
+		forwarder.EventsDropped,
+		forwarder.TenantID,
+		forwarder.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save SIEM forwarder progress: %w", err)
+	}
+
+	return nil
+}
+
+// actionPatterns turns action prefixes into LIKE patterns
+func actionPatterns(prefixes []string) []string {
+	escape := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	patterns := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		patterns[i] = escape.Replace(prefix) + "%"
+	}
+	return patterns
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// SSORepository handles database operations for single sign-on providers and
+// the identities linked to users
+type SSORepository struct {
+	db *sqlx.DB
+}
+
+// NewSSORepository creates a new SSO repository
+func NewSSORepository(db *sqlx.DB) *SSORepository {
+	return &SSORepository{db: db}
+}
+
+// CreateProvider configures a provider
+func (r *SSORepository) CreateProvider(ctx context.Context, provider *models.SSOProvider) error {
+	attributeMapping, roleMapping, err := encodeSAMLMappings(provider)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, provider.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO sso_providers (
+			tenant_id, slug, name, protocol, issuer_url, client_id, client_secret_encrypted, scopes,
+			saml_idp_entity_id, saml_idp_sso_url, saml_idp_certificates, saml_attribute_mapping, saml_role_mapping,
+			allowed_domains, auto_provision, default_role_id, is_enabled, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		provider.TenantID,
+		provider.Slug,
+		provider.Name,
+		provider.Protocol,
+		provider.IssuerURL,
+		provider.ClientID,
+		provider.ClientSecret,
+		provider.Scopes,
+		provider.SAMLIdPEntityID,
+		provider.SAMLIdPSSOURL,
+		provider.SAMLIdPCertificates,
+		attributeMapping,
+		roleMapping,
+		provider.AllowedDomains,
+		provider.AutoProvision,
+		provider.DefaultRoleID,
+		provider.IsEnabled,
+		provider.CreatedBy,
+	).Scan(&provider.ID, &provider.CreatedAt, &provider.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create sso provider: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindProvider retrieves a provider
+func (r *SSORepository) FindProvider(ctx context.Context, tenantID, id uuid.UUID) (*models.SSOProvider, error) {
+	return r.findProvider(ctx, tenantID, `SELECT * FROM sso_providers WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+}
+
+// FindProviderBySlug retrieves a provider by the slug used in its login URL
+func (r *SSORepository) FindProviderBySlug(ctx context.Context, tenantID uuid.UUID, slug string) (*models.SSOProvider, error) {
+	return r.findProvider(ctx, tenantID, `SELECT * FROM sso_providers WHERE tenant_id = $1 AND slug = $2`, tenantID, slug)
+}
+
+func (r *SSORepository) findProvider(ctx context.Context, tenantID uuid.UUID, query string, args ...interface{}) (*models.SSOProvider, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var provider models.SSOProvider
+	err = tx.GetContext(ctx, &provider, query, args...)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SSO_PROVIDER_NOT_FOUND", "sso provider not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sso provider: %w", err)
+	}
+
+	return &provider, nil
+}
+
+// ListProviders retrieves a tenant's providers by name
+func (r *SSORepository) ListProviders(ctx context.Context, tenantID uuid.UUID) ([]models.SSOProvider, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	providers := []models.SSOProvider{}
+	query := `SELECT * FROM sso_providers WHERE tenant_id = $1 ORDER BY name, slug`
+	if err := tx.SelectContext(ctx, &providers, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list sso providers: %w", err)
+	}
+
+	return providers, nil
+}
+
+// CountEnabledProviders counts a tenant's enabled providers
+func (r *SSORepository) CountEnabledProviders(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM sso_providers WHERE tenant_id = $1 AND is_enabled = TRUE`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sso providers: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateProvider saves a provider's configuration. Its protocol cannot change.
+func (r *SSORepository) UpdateProvider(ctx context.Context, provider *models.SSOProvider) error {
+	attributeMapping, roleMapping, err := encodeSAMLMappings(provider)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, provider.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE sso_providers
+		SET name = $1, issuer_url = $2, client_id = $3, client_secret_encrypted = $4, scopes = $5,
+		    saml_idp_entity_id = $6, saml_idp_sso_url = $7, saml_idp_certificates = $8,
+		    saml_attribute_mapping = $9, saml_role_mapping = $10,
+		    allowed_domains = $11, auto_provision = $12, default_role_id = $13, is_enabled = $14,
+		    updated_at = NOW()
+		WHERE tenant_id = $15 AND id = $16
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		provider.Name,
+		provider.IssuerURL,
+		provider.ClientID,
+		provider.ClientSecret,
+		provider.Scopes,
+		provider.SAMLIdPEntityID,
+		provider.SAMLIdPSSOURL,
+		provider.SAMLIdPCertificates,
+		attributeMapping,
+		roleMapping,
+		provider.AllowedDomains,
+		provider.AutoProvision,
+		provider.DefaultRoleID,
+		provider.IsEnabled,
+		provider.TenantID,
+		provider.ID,
+	).Scan(&provider.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SSO_PROVIDER_NOT_FOUND", "sso provider not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update sso provider: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// encodeSAMLMappings encodes a provider's SAML attribute and role mappings
+func encodeSAMLMappings(provider *models.SSOProvider) (string, string, error) {
+	attributeMapping, err := json.Marshal(provider.SAMLAttributeMapping)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode saml attribute mapping: %w", err)
+	}
+
+	roleMapping := provider.SAMLRoleMapping
+	if roleMapping == nil {
+		roleMapping = models.SAMLRoleMapping{}
+	}
+	encodedRoles, err := json.Marshal(roleMapping)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode saml role mapping: %w", err)
+	}
+
+	return string(attributeMapping), string(encodedRoles), nil
+}
+
+// DeleteProvider removes a provider and the identities linked through it
+func (r *SSORepository) DeleteProvider(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM sso_providers WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sso provider: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("SSO_PROVIDER_NOT_FOUND", "sso provider not found")
+	}
+
+	return tx.Commit()
+}
+
+// FindIdentity retrieves the identity a provider's subject is linked to
+func (r *SSORepository) FindIdentity(ctx context.Context, tenantID, providerID uuid.UUID, subject string) (*models.SSOIdentity, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var identity models.SSOIdentity
+	query := `SELECT * FROM sso_identities WHERE tenant_id = $1 AND provider_id = $2 AND subject = $3`
+	err = tx.GetContext(ctx, &identity, query, tenantID, providerID, subject)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SSO_IDENTITY_NOT_FOUND", "sso identity not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sso identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// LinkIdentity links a provider's subject to a user, replacing the user's
+// previous identity at that provider
+func (r *SSORepository) LinkIdentity(ctx context.Context, identity *models.SSOIdentity) error {
+	tx, err := database.WithTenantContext(ctx, r.db, identity.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO sso_identities (tenant_id, provider_id, user_id, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (tenant_id, provider_id, user_id)
+		DO UPDATE SET subject = EXCLUDED.subject, email = EXCLUDED.email, last_login_at = NOW()
+		RETURNING id, created_at, last_login_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		identity.TenantID,
+		identity.ProviderID,
+		identity.UserID,
+		identity.Subject,
+		identity.Email,
+	).Scan(&identity.ID, &identity.CreatedAt, &identity.LastLoginAt)
+	if err != nil {
+		return fmt.Errorf("failed to link sso identity: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// TouchIdentity records a sign-on through an identity
+func (r *SSORepository) TouchIdentity(ctx context.Context, tenantID, identityID uuid.UUID, email string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE sso_identities SET email = $1, last_login_at = NOW() WHERE tenant_id = $2 AND id = $3`
+	if _, err := tx.ExecContext(ctx, query, email, tenantID, identityID); err != nil {
+		return fmt.Errorf("failed to update sso identity: %w", err)
+	}
+
+	return tx.Commit()
+}
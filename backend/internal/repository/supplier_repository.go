@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// SupplierRepository handles database operations for suppliers
+type SupplierRepository struct {
+	db *sqlx.DB
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db *sqlx.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+// Create creates a new supplier with RLS
+func (r *SupplierRepository) Create(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO suppliers (
+			tenant_id, name, code, email, phone, address, tax_id,
+			payment_terms_days, currency, notes, status, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		supplier.Name,
+		supplier.Code,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Address,
+		supplier.TaxID,
+		supplier.PaymentTermsDays,
+		supplier.Currency,
+		supplier.Notes,
+		supplier.Status,
+		supplier.CreatedBy,
+	).Scan(&supplier.ID, &supplier.CreatedAt, &supplier.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	supplier.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindByID retrieves a supplier by ID with RLS
+func (r *SupplierRepository) FindByID(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var supplier models.Supplier
+	// Explicit tenant_id filter for defense in depth
+	query := `SELECT * FROM suppliers WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &supplier, query, tenantID, supplierID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SUPPLIER_NOT_FOUND", "supplier not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supplier: %w", err)
+	}
+
+	return &supplier, nil
+}
+
+// List retrieves suppliers with optional status/search filters and pagination
+func (r *SupplierRepository) List(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE tenant_id = $1
+		AND ($2 = '' OR status = $2)
+		AND ($3 = '' OR name ILIKE '%' || $3 || '%' OR code ILIKE '%' || $3 || '%')
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM suppliers `+where, tenantID, status, search); err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	suppliers := []models.Supplier{}
+	query := `SELECT * FROM suppliers ` + where + ` ORDER BY name ASC LIMIT $4 OFFSET $5`
+
+	if err := tx.SelectContext(ctx, &suppliers, query, tenantID, status, search, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list suppliers: %w", err)
+	}
+
+	return suppliers, totalCount, nil
+}
+
+// Update updates a supplier's information
+func (r *SupplierRepository) Update(ctx context.Context, tenantID uuid.UUID, supplier *models.Supplier) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE suppliers
+		SET name = $1,
+			code = $2,
+			email = $3,
+			phone = $4,
+			address = $5,
+			tax_id = $6,
+			payment_terms_days = $7,
+			currency = $8,
+			notes = $9,
+			status = $10,
+			updated_at = NOW()
+		WHERE tenant_id = $11 AND id = $12
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		supplier.Name,
+		supplier.Code,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Address,
+		supplier.TaxID,
+		supplier.PaymentTermsDays,
+		supplier.Currency,
+		supplier.Notes,
+		supplier.Status,
+		tenantID,
+		supplier.ID,
+	).Scan(&supplier.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("SUPPLIER_NOT_FOUND", "supplier not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update supplier: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a supplier (fails if purchase orders reference it)
+func (r *SupplierRepository) Delete(ctx context.Context, tenantID, supplierID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var orderCount int
+	err = tx.GetContext(ctx, &orderCount, `SELECT COUNT(*) FROM purchase_orders WHERE tenant_id = $1 AND supplier_id = $2`, tenantID, supplierID)
+	if err != nil {
+		return fmt.Errorf("failed to check supplier usage: %w", err)
+	}
+	if orderCount > 0 {
+		return utils.NewConflictError("SUPPLIER_IN_USE", "supplier has purchase orders, deactivate it instead")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM suppliers WHERE tenant_id = $1 AND id = $2`, tenantID, supplierID)
+	if err != nil {
+		return fmt.Errorf("failed to delete supplier: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("SUPPLIER_NOT_FOUND", "supplier not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckNameExists checks if a supplier name already exists for a tenant
+func (r *SupplierRepository) CheckNameExists(ctx context.Context, tenantID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if excludeID != nil {
+		err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM suppliers WHERE tenant_id = $1 AND name = $2 AND id != $3`, tenantID, name, *excludeID)
+	} else {
+		err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM suppliers WHERE tenant_id = $1 AND name = $2`, tenantID, name)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check supplier name: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// FindDuplicates retrieves suppliers whose name is at least nameThreshold
+// similar (trigram similarity, case-insensitive), whose email is at least
+// emailThreshold similar, or whose tax ID matches. query.TaxID must already
+// be normalized (see services.normalizeTaxID).
+func (r *SupplierRepository) FindDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery, nameThreshold, emailThreshold float64, limit int) ([]models.SupplierDuplicate, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	duplicates := []models.SupplierDuplicate{}
+	sqlQuery := `
+		SELECT * FROM (
+			SELECT s.*,
+				similarity(lower(s.name), lower($2)) AS name_similarity,
+				CASE WHEN $3 = '' OR s.email IS NULL THEN 0 ELSE similarity(lower(s.email), lower($3)) END AS email_similarity,
+				($4 <> '' AND regexp_replace(upper(COALESCE(s.tax_id, '')), '[^A-Z0-9]', '', 'g') = $4) AS tax_id_match
+			FROM suppliers s
+			WHERE s.tenant_id = $1 AND ($5::uuid IS NULL OR s.id <> $5)
+		) candidates
+		WHERE tax_id_match OR name_similarity >= $6 OR email_similarity >= $7
+		ORDER BY tax_id_match DESC, GREATEST(name_similarity, email_similarity) DESC, name ASC
+		LIMIT $8
+	`
+	err = tx.SelectContext(ctx, &duplicates, sqlQuery,
+		tenantID,
+		query.Name,
+		query.Email,
+		query.TaxID,
+		query.ExcludeID,
+		nameThreshold,
+		emailThreshold,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate suppliers: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// Merge moves the source supplier's purchase orders and supplier invoices to
+// the target, deletes the source and saves the target's merged details, all
+// in one transaction. merge carries the source's snapshot and who merged;
+// the moved counts are filled in.
+func (r *SupplierRepository) Merge(ctx context.Context, tenantID uuid.UUID, target *models.Supplier, merge *models.SupplierMerge) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock both suppliers so no documents are added to the source meanwhile
+	var locked int
+	err = tx.GetContext(ctx, &locked, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM suppliers WHERE tenant_id = $1 AND id IN ($2, $3) FOR UPDATE
+		) s
+	`, tenantID, target.ID, merge.SourceID)
+	if err != nil {
+		return fmt.Errorf("failed to lock suppliers: %w", err)
+	}
+	if locked != 2 {
+		return utils.NewNotFoundError("SUPPLIER_NOT_FOUND", "supplier not found")
+	}
+
+	// Invoice numbers are unique per supplier
+	var conflict string
+	err = tx.GetContext(ctx, &conflict, `
+		SELECT s.invoice_number
+		FROM supplier_invoices s
+		JOIN supplier_invoices t ON t.tenant_id = s.tenant_id AND t.invoice_number = s.invoice_number
+		WHERE s.tenant_id = $1 AND s.supplier_id = $2 AND t.supplier_id = $3
+		LIMIT 1
+	`, tenantID, merge.SourceID, target.ID)
+	if err == nil {
+		return utils.NewConflictError("INVOICE_NUMBER_EXISTS", fmt.Sprintf("both suppliers have a supplier invoice numbered %s", conflict))
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check supplier invoice numbers: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE purchase_orders SET supplier_id = $3 WHERE tenant_id = $1 AND supplier_id = $2`, tenantID, merge.SourceID, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to move purchase orders: %w", err)
+	}
+	moved, _ := result.RowsAffected()
+	merge.PurchaseOrdersMoved = int(moved)
+
+	result, err = tx.ExecContext(ctx, `UPDATE supplier_invoices SET supplier_id = $3 WHERE tenant_id = $1 AND supplier_id = $2`, tenantID, merge.SourceID, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to move supplier invoices: %w", err)
+	}
+	moved, _ = result.RowsAffected()
+	merge.SupplierInvoicesMoved = int(moved)
+
+	// Delete the source first: the target may take over its code
+	if _, err := tx.ExecContext(ctx, `DELETE FROM suppliers WHERE tenant_id = $1 AND id = $2`, tenantID, merge.SourceID); err != nil {
+		return fmt.Errorf("failed to delete merged supplier: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE suppliers
+		SET code = $1, email = $2, phone = $3, address = $4, tax_id = $5, notes = $6, updated_at = NOW()
+		WHERE tenant_id = $7 AND id = $8
+		RETURNING updated_at
+	`,
+		target.Code,
+		target.Email,
+		target.Phone,
+		target.Address,
+		target.TaxID,
+		target.Notes,
+		tenantID,
+		target.ID,
+	).Scan(&target.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update supplier: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO supplier_merges (tenant_id, target_id, source_id, source_name, source, purchase_orders_moved, supplier_invoices_moved, merged_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, merged_at
+	`,
+		tenantID,
+		target.ID,
+		merge.SourceID,
+		merge.SourceName,
+		string(merge.Source),
+		merge.PurchaseOrdersMoved,
+		merge.SupplierInvoicesMoved,
+		merge.MergedBy,
+	).Scan(&merge.ID, &merge.MergedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record supplier merge: %w", err)
+	}
+
+	merge.TenantID = tenantID
+	merge.TargetID = target.ID
+	return tx.Commit()
+}
+
+// ListMerges retrieves the suppliers merged into a supplier, newest first
+func (r *SupplierRepository) ListMerges(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	merges := []models.SupplierMerge{}
+	query := `
+		SELECT * FROM supplier_merges
+		WHERE tenant_id = $1 AND target_id = $2
+		ORDER BY merged_at DESC
+	`
+	if err := tx.SelectContext(ctx, &merges, query, tenantID, supplierID); err != nil {
+		return nil, fmt.Errorf("failed to list supplier merges: %w", err)
+	}
+
+	return merges, nil
+}
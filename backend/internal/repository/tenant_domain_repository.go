@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// TenantDomainRepository handles database operations for the custom domains
+// of tenants. Domains are resolved before the tenant is known, so they live
+// in the catalog database (no RLS); every tenant-facing query filters by
+// tenant itself.
+type TenantDomainRepository struct {
+	db *sqlx.DB
+}
+
+// NewTenantDomainRepository creates a new tenant domain repository
+func NewTenantDomainRepository(db *sqlx.DB) *TenantDomainRepository {
+	return &TenantDomainRepository{db: db}
+}
+
+// Create adds a domain. Fails with a conflict when any tenant already added
+// it, verified or not.
+func (r *TenantDomainRepository) Create(ctx context.Context, domain *models.TenantDomain) error {
+	query := `
+		INSERT INTO tenant_domains (tenant_id, domain, verification_token, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (domain) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, domain.TenantID, domain.Domain, domain.VerificationToken, domain.CreatedBy).
+		Scan(&domain.ID, &domain.CreatedAt, &domain.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewConflictError("DOMAIN_TAKEN", "domain is already added")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add domain: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a tenant's domains, verified ones first
+func (r *TenantDomainRepository) List(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error) {
+	domains := []models.TenantDomain{}
+	query := `
+		SELECT * FROM tenant_domains
+		WHERE tenant_id = $1
+		ORDER BY verified_at IS NULL, domain
+	`
+
+	if err := r.db.SelectContext(ctx, &domains, query, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// FindByID retrieves one of a tenant's domains
+func (r *TenantDomainRepository) FindByID(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	var domain models.TenantDomain
+	query := `SELECT * FROM tenant_domains WHERE tenant_id = $1 AND id = $2`
+
+	err := r.db.GetContext(ctx, &domain, query, tenantID, domainID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("DOMAIN_NOT_FOUND", "domain not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+// FindTenantIDByDomain returns the tenant a verified domain belongs to
+func (r *TenantDomainRepository) FindTenantIDByDomain(ctx context.Context, domain string) (uuid.UUID, error) {
+	var tenantID uuid.UUID
+	query := `SELECT tenant_id FROM tenant_domains WHERE domain = $1 AND verified_at IS NOT NULL`
+
+	err := r.db.GetContext(ctx, &tenantID, query, domain)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, utils.NewNotFoundError("DOMAIN_NOT_FOUND", "domain not found")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+
+	return tenantID, nil
+}
+
+// RecordCheck records a verification attempt, marking the domain verified
+// when it succeeded. A verified domain stays verified.
+func (r *TenantDomainRepository) RecordCheck(ctx context.Context, domain *models.TenantDomain, verified bool) error {
+	query := `
+		UPDATE tenant_domains
+		SET last_checked_at = NOW(),
+		    verified_at = CASE WHEN $3 THEN COALESCE(verified_at, NOW()) ELSE verified_at END
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING verified_at, last_checked_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, domain.TenantID, domain.ID, verified).
+		Scan(&domain.VerifiedAt, &domain.LastCheckedAt, &domain.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("DOMAIN_NOT_FOUND", "domain not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record domain check: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes one of a tenant's domains
+func (r *TenantDomainRepository) Delete(ctx context.Context, tenantID, domainID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tenant_domains WHERE tenant_id = $1 AND id = $2`, tenantID, domainID)
+	if err != nil {
+		return fmt.Errorf("failed to remove domain: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("DOMAIN_NOT_FOUND", "domain not found")
+	}
+
+	return nil
+}
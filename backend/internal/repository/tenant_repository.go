@@ -3,12 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
 // TenantRepository handles database operations for tenants
@@ -23,17 +27,24 @@ func NewTenantRepository(db *sqlx.DB) *TenantRepository {
 
 // Create creates a new tenant with verification token
 func (r *TenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
-	// Note: Tenants table does NOT have RLS, so no need to set tenant context
+	// Note: Tenants table does NOT have RLS, so no need to set tenant context.
+	// The ID is chosen upfront, for a unit of work to create the tenant's
+	// first records with it.
+	if tenant.ID == uuid.Nil {
+		tenant.ID = uuid.New()
+	}
+
 	query := `
 		INSERT INTO tenants (
-			slug, company_name, email, status, verification_token,
+			id, slug, company_name, email, status, verification_token,
 			verification_token_expires_at, plan_tier, settings
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(
+	err := database.Conn(ctx, r.db).QueryRowContext(
 		ctx, query,
+		tenant.ID,
 		tenant.Slug,
 		tenant.CompanyName,
 		tenant.Email,
@@ -42,7 +53,7 @@ func (r *TenantRepository) Create(ctx context.Context, tenant *models.Tenant) er
 		tenant.VerificationTokenExpiresAt,
 		tenant.PlanTier,
 		tenant.Settings,
-	).Scan(&tenant.ID, &tenant.CreatedAt, &tenant.UpdatedAt)
+	).Scan(&tenant.CreatedAt, &tenant.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create tenant: %w", err)
@@ -58,7 +69,7 @@ func (r *TenantRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.
 
 	err := r.db.GetContext(ctx, &tenant, query, id)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tenant not found")
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tenant: %w", err)
@@ -74,7 +85,7 @@ func (r *TenantRepository) FindBySlug(ctx context.Context, slug string) (*models
 
 	err := r.db.GetContext(ctx, &tenant, query, slug)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tenant not found")
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tenant: %w", err)
@@ -90,7 +101,7 @@ func (r *TenantRepository) FindByEmail(ctx context.Context, email string) (*mode
 
 	err := r.db.GetContext(ctx, &tenant, query, email)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tenant not found")
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tenant: %w", err)
@@ -111,7 +122,7 @@ func (r *TenantRepository) FindByVerificationToken(ctx context.Context, token uu
 
 	err := r.db.GetContext(ctx, &tenant, query, token, models.TenantStatusPendingVerification)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("invalid or expired verification token")
+		return nil, utils.NewBadRequestError("INVALID_VERIFICATION_TOKEN", "invalid or expired verification token")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find tenant: %w", err)
@@ -165,14 +176,14 @@ func (r *TenantRepository) VerifyEmail(ctx context.Context, tenantID uuid.UUID)
 		WHERE id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, query, models.TenantStatusActive, tenantID)
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, models.TenantStatusActive, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to verify email: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("tenant not found")
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	return nil
@@ -194,35 +205,469 @@ func (r *TenantRepository) UpdateStatus(ctx context.Context, tenantID uuid.UUID,
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("tenant not found")
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	return nil
 }
 
-// Suspend suspends a tenant
-func (r *TenantRepository) Suspend(ctx context.Context, tenantID uuid.UUID) error {
+// SetSSORequired sets whether a tenant's users must sign in with single
+// sign-on, keeping the other tenant settings
+func (r *TenantRepository) SetSSORequired(ctx context.Context, tenantID uuid.UUID, required bool) error {
 	query := `
 		UPDATE tenants
-		SET status = $1,
-		    suspended_at = NOW(),
+		SET settings = jsonb_set(COALESCE(settings, '{}'::jsonb), '{sso_required}', to_jsonb($1::boolean)),
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, required, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update sso setting: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// GetSettings returns a tenant's settings with their version, an MD5 of the
+// settings computed by the database so concurrent updates can be detected
+func (r *TenantRepository) GetSettings(ctx context.Context, tenantID uuid.UUID) (json.RawMessage, string, error) {
+	var row struct {
+		Settings json.RawMessage `db:"settings"`
+		Version  string          `db:"version"`
+	}
+	query := `
+		SELECT COALESCE(settings, '{}'::jsonb) AS settings,
+		       md5(COALESCE(settings, '{}'::jsonb)::text) AS version
+		FROM tenants
+		WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, &row, query, tenantID)
+	if err == sql.ErrNoRows {
+		return nil, "", utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tenant settings: %w", err)
+	}
+
+	return row.Settings, row.Version, nil
+}
+
+// UpdateSettingsSections replaces top-level keys of a tenant's settings,
+// keeping the others, if the settings are still at the given version.
+// Returns the new version.
+func (r *TenantRepository) UpdateSettingsSections(ctx context.Context, tenantID uuid.UUID, sections map[string]json.RawMessage, version string) (string, error) {
+	data, err := json.Marshal(sections)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tenant settings: %w", err)
+	}
+
+	query := `
+		UPDATE tenants
+		SET settings = COALESCE(settings, '{}'::jsonb) || $1::jsonb,
+		    updated_at = NOW()
+		WHERE id = $2
+		  AND md5(COALESCE(settings, '{}'::jsonb)::text) = $3
+		RETURNING md5(settings::text)
+	`
+
+	var newVersion string
+	err = r.db.GetContext(ctx, &newVersion, query, string(data), tenantID, version)
+	if err == sql.ErrNoRows {
+		if _, err := r.FindByID(ctx, tenantID); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("settings version mismatch")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update tenant settings: %w", err)
+	}
+
+	return newVersion, nil
+}
+
+// SetSessionLimit sets a tenant's concurrent session limit, keeping the other
+// tenant settings
+func (r *TenantRepository) SetSessionLimit(ctx context.Context, tenantID uuid.UUID, limit *models.SessionLimit) error {
+	data, err := json.Marshal(limit)
+	if err != nil {
+		return fmt.Errorf("failed to encode session limit: %w", err)
+	}
+
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(COALESCE(settings, '{}'::jsonb), '{session_limit}', $1::jsonb),
 		    updated_at = NOW()
 		WHERE id = $2
 	`
 
-	result, err := r.db.ExecContext(ctx, query, models.TenantStatusSuspended, tenantID)
+	result, err := r.db.ExecContext(ctx, query, string(data), tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update session limit: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// SetBrandingLogoURL sets the logo URL of a tenant's branding, nil removing it
+func (r *TenantRepository) SetBrandingLogoURL(ctx context.Context, tenantID uuid.UUID, logoURL *string) error {
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(
+		        COALESCE(settings, '{}'::jsonb), '{branding}',
+		        CASE WHEN $1::text IS NULL THEN COALESCE(settings->'branding', '{}'::jsonb) - 'logo_url'
+		             ELSE COALESCE(settings->'branding', '{}'::jsonb) || jsonb_build_object('logo_url', $1::text)
+		        END),
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, logoURL, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update branding logo: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// SetTwoFactorPolicy sets a tenant's 2FA policy, keeping the other tenant
+// settings
+func (r *TenantRepository) SetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, policy *models.TwoFactorPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode 2fa policy: %w", err)
+	}
+
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(COALESCE(settings, '{}'::jsonb), '{two_factor_policy}', $1::jsonb),
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(data), tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update 2fa policy: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// SetUsageAnalyticsOptOut sets whether a tenant refuses to share its
+// anonymized feature usage, keeping the other tenant settings
+func (r *TenantRepository) SetUsageAnalyticsOptOut(ctx context.Context, tenantID uuid.UUID, optOut bool) error {
+	query := `
+		UPDATE tenants
+		SET settings = jsonb_set(COALESCE(settings, '{}'::jsonb), '{usage_analytics_opt_out}', to_jsonb($1::boolean)),
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, optOut, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update usage analytics setting: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// FindUsageAnalyticsOptOuts returns which of the given tenants refuse to
+// share their anonymized feature usage
+func (r *TenantRepository) FindUsageAnalyticsOptOuts(ctx context.Context, tenantIDs []uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM tenants
+		WHERE id = ANY($1::uuid[])
+		AND COALESCE((settings->>'usage_analytics_opt_out')::boolean, false)
+	`
+
+	ids := []uuid.UUID{}
+	if err := r.db.SelectContext(ctx, &ids, query, pq.Array(tenantIDs)); err != nil {
+		return nil, fmt.Errorf("failed to find usage analytics opt-outs: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Suspend makes an active tenant read-only for the given reason
+func (r *TenantRepository) Suspend(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	query := `
+		UPDATE tenants
+		SET status = $1,
+		    suspension_reason = $2,
+		    suspended_at = COALESCE(suspended_at, NOW()),
+		    updated_at = NOW()
+		WHERE id = $3
+		  AND status IN ($1, $4)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, models.TenantStatusSuspended, reason, tenantID, models.TenantStatusActive)
 	if err != nil {
 		return fmt.Errorf("failed to suspend tenant: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("tenant not found")
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found or not active")
+	}
+
+	return nil
+}
+
+// Reactivate lifts a tenant's suspension
+func (r *TenantRepository) Reactivate(ctx context.Context, tenantID uuid.UUID) error {
+	query := `
+		UPDATE tenants
+		SET status = $1,
+		    suspension_reason = NULL,
+		    suspended_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $2
+		  AND status = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, models.TenantStatusActive, tenantID, models.TenantStatusSuspended)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate tenant: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found or not suspended")
 	}
 
 	return nil
 }
 
+// FindByStripeCustomer retrieves the tenant billed as a Stripe customer
+func (r *TenantRepository) FindByStripeCustomer(ctx context.Context, customerID string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	query := `SELECT * FROM tenants WHERE stripe_customer_id = $1`
+
+	err := r.db.GetContext(ctx, &tenant, query, customerID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tenant: %w", err)
+	}
+
+	return &tenant, nil
+}
+
+// UpdateSubscription records a tenant's Stripe subscription and the plan
+// tier it pays for
+func (r *TenantRepository) UpdateSubscription(ctx context.Context, tenantID uuid.UUID, sub *models.TenantSubscription) error {
+	query := `
+		UPDATE tenants
+		SET stripe_customer_id = $1,
+		    stripe_subscription_id = $2,
+		    subscription_status = $3,
+		    subscription_period_end = $4,
+		    subscription_cancel_at_period_end = $5,
+		    plan_tier = $6,
+		    updated_at = NOW()
+		WHERE id = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		sub.CustomerID,
+		sub.SubscriptionID,
+		sub.Status,
+		sub.PeriodEnd,
+		sub.CancelAtPeriodEnd,
+		sub.PlanTier,
+		tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// SetMaintenanceWindow schedules a tenant's maintenance window, replacing any
+// scheduled one
+func (r *TenantRepository) SetMaintenanceWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) error {
+	query := `
+		UPDATE tenants
+		SET maintenance_starts_at = $1,
+		    maintenance_ends_at = $2,
+		    maintenance_message = NULLIF($3, ''),
+		    maintenance_allowed_paths = $4,
+		    updated_at = NOW()
+		WHERE id = $5
+	`
+
+	result, err := r.db.ExecContext(ctx, query, req.StartsAt, req.EndsAt, req.Message, pq.Array(req.AllowedPaths), tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to schedule maintenance window: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// ClearMaintenanceWindow cancels a tenant's maintenance window, or ends it
+// if it has started
+func (r *TenantRepository) ClearMaintenanceWindow(ctx context.Context, tenantID uuid.UUID) error {
+	query := `
+		UPDATE tenants
+		SET maintenance_starts_at = NULL,
+		    maintenance_ends_at = NULL,
+		    maintenance_message = NULL,
+		    maintenance_allowed_paths = '{}',
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel maintenance window: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return nil
+}
+
+// RequestDeletion records an owner's request to delete a tenant, awaiting
+// confirmation with token until expiresAt. It replaces an unconfirmed
+// request; a scheduled deletion must be canceled first.
+func (r *TenantRepository) RequestDeletion(ctx context.Context, tenantID, requestedBy, token uuid.UUID, expiresAt time.Time) error {
+	query := `
+		UPDATE tenants
+		SET deletion_requested_at = NOW(),
+		    deletion_requested_by = $1,
+		    deletion_token = $2,
+		    deletion_token_expires_at = $3,
+		    updated_at = NOW()
+		WHERE id = $4
+		  AND deletion_scheduled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, requestedBy, token, expiresAt, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to request tenant deletion: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("tenant deletion already scheduled")
+	}
+
+	return nil
+}
+
+// ConfirmDeletion schedules a tenant's requested deletion for scheduledAt if
+// token is its unexpired confirmation token
+func (r *TenantRepository) ConfirmDeletion(ctx context.Context, tenantID, token uuid.UUID, scheduledAt time.Time) error {
+	query := `
+		UPDATE tenants
+		SET deletion_scheduled_at = $1,
+		    deletion_token = NULL,
+		    deletion_token_expires_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $2
+		  AND deletion_token = $3
+		  AND deletion_token_expires_at > NOW()
+		  AND deletion_scheduled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, scheduledAt, tenantID, token)
+	if err != nil {
+		return fmt.Errorf("failed to confirm tenant deletion: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewBadRequestError("INVALID_DELETION_TOKEN", "invalid or expired deletion token")
+	}
+
+	return nil
+}
+
+// CancelDeletion cancels a tenant's requested or scheduled deletion
+func (r *TenantRepository) CancelDeletion(ctx context.Context, tenantID uuid.UUID) error {
+	query := `
+		UPDATE tenants
+		SET deletion_requested_at = NULL,
+		    deletion_requested_by = NULL,
+		    deletion_token = NULL,
+		    deletion_token_expires_at = NULL,
+		    deletion_scheduled_at = NULL,
+		    updated_at = NOW()
+		WHERE id = $1
+		  AND deletion_requested_at IS NOT NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel tenant deletion: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("no tenant deletion requested")
+	}
+
+	return nil
+}
+
+// ListDueForDeletion retrieves the IDs of the tenants whose scheduled
+// deletion is due, oldest first
+func (r *TenantRepository) ListDueForDeletion(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `
+		SELECT id FROM tenants
+		WHERE deletion_scheduled_at <= NOW()
+		ORDER BY deletion_scheduled_at ASC
+		LIMIT $1
+	`
+	if err := r.db.SelectContext(ctx, &ids, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list tenants due for deletion: %w", err)
+	}
+
+	return ids, nil
+}
+
 // Delete deletes a tenant (soft delete by setting status to canceled)
 func (r *TenantRepository) Delete(ctx context.Context, tenantID uuid.UUID) error {
 	query := `
@@ -239,16 +684,20 @@ func (r *TenantRepository) Delete(ctx context.Context, tenantID uuid.UUID) error
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("tenant not found")
+		return utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	return nil
 }
 
-// CheckSlugAvailability checks if a slug is available
+// CheckSlugAvailability checks if a slug is available: no tenant has it, and
+// it is no renamed tenant's former slug still redirecting to it
 func (r *TenantRepository) CheckSlugAvailability(ctx context.Context, slug string) (bool, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM tenants WHERE slug = $1`
+	query := `
+		SELECT (SELECT COUNT(*) FROM tenants WHERE slug = $1)
+		     + (SELECT COUNT(*) FROM tenant_slug_history WHERE old_slug = $1 AND expires_at > NOW())
+	`
 
 	err := r.db.GetContext(ctx, &count, query, slug)
 	if err != nil {
@@ -258,6 +707,95 @@ func (r *TenantRepository) CheckSlugAvailability(ctx context.Context, slug strin
 	return count == 0, nil
 }
 
+// ChangeSlug renames a tenant's slug and keeps the old one resolving to the
+// tenant until redirectUntil. The tenant may take back one of its own former
+// slugs; anyone else's, while it redirects, is taken. Returns the old slug.
+func (r *TenantRepository) ChangeSlug(ctx context.Context, tenantID uuid.UUID, slug string, changedBy uuid.UUID, redirectUntil time.Time) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldSlug string
+	err = tx.GetContext(ctx, &oldSlug, `SELECT slug FROM tenants WHERE id = $1 FOR UPDATE`, tenantID)
+	if err == sql.ErrNoRows {
+		return "", utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find tenant: %w", err)
+	}
+	if oldSlug == slug {
+		return "", utils.NewValidationError("SLUG_UNCHANGED", "slug is already the tenant's slug")
+	}
+
+	// Free the slug if it is the tenant's own former slug or has expired
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM tenant_slug_history
+		WHERE old_slug = $1 AND (tenant_id = $2 OR expires_at <= NOW())
+	`, slug, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("failed to release former slug: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tenants
+		SET slug = $2, updated_at = NOW()
+		WHERE id = $1
+		  AND NOT EXISTS (SELECT 1 FROM tenants WHERE slug = $2)
+		  AND NOT EXISTS (SELECT 1 FROM tenant_slug_history WHERE old_slug = $2)
+	`, tenantID, slug)
+	if err != nil {
+		return "", fmt.Errorf("failed to change slug: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return "", utils.NewConflictError("SLUG_TAKEN", "slug is already taken")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tenant_slug_history (old_slug, tenant_id, expires_at, changed_by)
+		VALUES ($1, $2, $3, $4)
+	`, oldSlug, tenantID, redirectUntil, changedBy)
+	if err != nil {
+		return "", fmt.Errorf("failed to record former slug: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return oldSlug, nil
+}
+
+// FindTenantIDByFormerSlug returns the tenant a former slug still redirects to
+func (r *TenantRepository) FindTenantIDByFormerSlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	var tenantID uuid.UUID
+	query := `SELECT tenant_id FROM tenant_slug_history WHERE old_slug = $1 AND expires_at > NOW()`
+
+	err := r.db.GetContext(ctx, &tenantID, query, slug)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to find former slug: %w", err)
+	}
+
+	return tenantID, nil
+}
+
+// CleanupExpiredSlugHistory removes former slugs whose redirect expired,
+// returning them
+func (r *TenantRepository) CleanupExpiredSlugHistory(ctx context.Context) ([]string, error) {
+	slugs := []string{}
+	query := `DELETE FROM tenant_slug_history WHERE expires_at <= NOW() RETURNING old_slug`
+
+	if err := r.db.SelectContext(ctx, &slugs, query); err != nil {
+		return nil, fmt.Errorf("failed to cleanup former slugs: %w", err)
+	}
+
+	return slugs, nil
+}
+
 // CheckEmailExists checks if an email is already registered
 func (r *TenantRepository) CheckEmailExists(ctx context.Context, email string) (bool, error) {
 	var count int
@@ -298,6 +836,16 @@ func (r *TenantRepository) List(ctx context.Context, limit, offset int) ([]model
 	return tenants, totalCount, nil
 }
 
+// ListActive retrieves every active tenant
+func (r *TenantRepository) ListActive(ctx context.Context) ([]models.Tenant, error) {
+	tenants := []models.Tenant{}
+	query := `SELECT * FROM tenants WHERE status = $1 ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &tenants, query, models.TenantStatusActive); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
 // CleanupExpiredVerificationTokens removes expired verification tokens
 func (r *TenantRepository) CleanupExpiredVerificationTokens(ctx context.Context) (int64, error) {
 	query := `
@@ -322,7 +870,7 @@ func (r *TenantRepository) ProvisionSystemRoles(ctx context.Context, tenantID uu
 	// Call the PostgreSQL function to provision system roles
 	query := `SELECT provision_tenant_system_roles($1)`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID)
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to provision system roles: %w", err)
 	}
@@ -350,7 +898,7 @@ func (r *TenantRepository) RegenerateVerificationToken(ctx context.Context, tena
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("tenant not found")
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	return &token, nil
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// TenantUsageRepository handles the metered daily usage of tenants. It is
+// billing data and lives in the catalog database with the tenants.
+type TenantUsageRepository struct {
+	db *sqlx.DB
+}
+
+// NewTenantUsageRepository creates a new tenant usage repository
+func NewTenantUsageRepository(db *sqlx.DB) *TenantUsageRepository {
+	return &TenantUsageRepository{db: db}
+}
+
+// Save writes the day's values read from the live counters. Counters only
+// grow during a day, so a lower value (e.g. after Redis lost its data) never
+// replaces a higher one; storage is a measurement and always replaced.
+// Tenants deleted since are skipped.
+func (r *TenantUsageRepository) Save(ctx context.Context, usage []models.TenantUsage) error {
+	return database.ExecInTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO tenant_usage (tenant_id, day, metric, value, updated_at)
+			SELECT $1::uuid, $2::date, $3, $4::bigint, NOW()
+			WHERE EXISTS (SELECT 1 FROM tenants WHERE id = $1)
+			ON CONFLICT (tenant_id, day, metric) DO UPDATE SET
+				value = CASE
+					WHEN tenant_usage.metric = 'storage_bytes' THEN EXCLUDED.value
+					ELSE GREATEST(tenant_usage.value, EXCLUDED.value)
+				END,
+				updated_at = NOW()
+		`
+
+		for _, u := range usage {
+			if _, err := tx.ExecContext(ctx, query, u.TenantID, u.Day, u.Metric, u.Value); err != nil {
+				return fmt.Errorf("failed to record tenant usage: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListRange retrieves a tenant's daily usage from one day up to, but not
+// including, another
+func (r *TenantUsageRepository) ListRange(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]models.TenantUsage, error) {
+	query := `
+		SELECT tenant_id, day, metric, value
+		FROM tenant_usage
+		WHERE tenant_id = $1 AND day >= $2 AND day < $3
+		ORDER BY day, metric
+	`
+
+	usage := []models.TenantUsage{}
+	if err := r.db.SelectContext(ctx, &usage, query, tenantID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list tenant usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// Sum adds up a tenant's daily values of a metric from one day up to, but
+// not including, another
+func (r *TenantUsageRepository) Sum(ctx context.Context, tenantID uuid.UUID, metric string, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(value), 0)::BIGINT
+		FROM tenant_usage
+		WHERE tenant_id = $1 AND metric = $2 AND day >= $3 AND day < $4
+	`
+
+	var sum int64
+	if err := r.db.GetContext(ctx, &sum, query, tenantID, metric, from, to); err != nil {
+		return 0, fmt.Errorf("failed to sum tenant usage: %w", err)
+	}
+
+	return sum, nil
+}
+
+// DeleteBefore deletes the daily rows older than a day
+func (r *TenantUsageRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tenant_usage WHERE day < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tenant usage: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
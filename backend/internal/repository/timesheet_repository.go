@@ -0,0 +1,747 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// timesheetProjectSelect selects timesheet projects (p) with their customer's
+// name
+const timesheetProjectSelect = `
+	SELECT
+		p.*,
+		c.name AS customer_name
+	FROM timesheet_projects p
+	LEFT JOIN crm_customers c ON c.tenant_id = p.tenant_id AND c.id = p.customer_id
+`
+
+// timesheetSelect selects timesheets (ts) with the employee's name and linked
+// user
+const timesheetSelect = `
+	SELECT
+		ts.*,
+		e.first_name || ' ' || e.last_name AS employee_name,
+		e.user_id AS employee_user_id
+	FROM timesheets ts
+	JOIN employees e ON e.tenant_id = ts.tenant_id AND e.id = ts.employee_id
+`
+
+// timesheetEntrySelect selects timesheet entries (te) with their project's
+// code and name and their task's name
+const timesheetEntrySelect = `
+	SELECT
+		te.*,
+		p.code AS project_code,
+		p.name AS project_name,
+		tt.name AS task_name
+	FROM timesheet_entries te
+	JOIN timesheet_projects p ON p.tenant_id = te.tenant_id AND p.id = te.project_id
+	LEFT JOIN timesheet_tasks tt ON tt.tenant_id = te.tenant_id AND tt.id = te.task_id
+`
+
+// TimesheetRepository handles database operations for timesheet projects,
+// tasks, timesheets and their entries
+type TimesheetRepository struct {
+	db *sqlx.DB
+}
+
+// NewTimesheetRepository creates a new timesheet repository
+func NewTimesheetRepository(db *sqlx.DB) *TimesheetRepository {
+	return &TimesheetRepository{db: db}
+}
+
+// CreateProject creates a new timesheet project with RLS
+func (r *TimesheetRepository) CreateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO timesheet_projects (
+			tenant_id, code, name, description, customer_id, billable,
+			hourly_rate, currency, is_active, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		project.Code,
+		project.Name,
+		project.Description,
+		project.CustomerID,
+		project.Billable,
+		project.HourlyRate,
+		project.Currency,
+		project.IsActive,
+		project.CreatedBy,
+	).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create timesheet project: %w", err)
+	}
+
+	project.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindProjectByID retrieves a timesheet project by ID with RLS
+func (r *TimesheetRepository) FindProjectByID(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var project models.TimesheetProject
+	query := timesheetProjectSelect + ` WHERE p.tenant_id = $1 AND p.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &project, query, tenantID, projectID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("PROJECT_NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet project: %w", err)
+	}
+
+	return &project, nil
+}
+
+// ListProjects retrieves a tenant's timesheet projects, sorted by code
+func (r *TimesheetRepository) ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	projects := []models.TimesheetProject{}
+	query := timesheetProjectSelect + `
+		WHERE p.tenant_id = $1 AND (NOT $2 OR p.is_active) AND ($3::uuid IS NULL OR p.customer_id = $3)
+		ORDER BY p.code ASC
+	`
+
+	if err := tx.SelectContext(ctx, &projects, query, tenantID, activeOnly, customerID); err != nil {
+		return nil, fmt.Errorf("failed to list timesheet projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// UpdateProject updates a timesheet project with RLS
+func (r *TimesheetRepository) UpdateProject(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE timesheet_projects
+		SET code = $1, name = $2, description = $3, customer_id = $4, billable = $5,
+			hourly_rate = $6, currency = $7, is_active = $8
+		WHERE tenant_id = $9 AND id = $10
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		project.Code,
+		project.Name,
+		project.Description,
+		project.CustomerID,
+		project.Billable,
+		project.HourlyRate,
+		project.Currency,
+		project.IsActive,
+		tenantID,
+		project.ID,
+	).Scan(&project.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("PROJECT_NOT_FOUND", "project not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update timesheet project: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteProject deletes a timesheet project and its tasks. Projects with
+// logged time cannot be deleted; deactivate them instead.
+func (r *TimesheetRepository) DeleteProject(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM timesheet_entries WHERE tenant_id = $1 AND project_id = $2)`
+	if err := tx.GetContext(ctx, &inUse, query, tenantID, projectID); err != nil {
+		return fmt.Errorf("failed to check timesheet project usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("project is in use")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM timesheet_projects WHERE tenant_id = $1 AND id = $2`, tenantID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete timesheet project: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("PROJECT_NOT_FOUND", "project not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckProjectCodeExists checks if a project code is already used in a tenant
+func (r *TimesheetRepository) CheckProjectCodeExists(ctx context.Context, tenantID uuid.UUID, code string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM timesheet_projects WHERE tenant_id = $1 AND code = $2 AND ($3::uuid IS NULL OR id != $3))`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, code, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check timesheet project code: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CreateTask creates a new task of a timesheet project with RLS
+func (r *TimesheetRepository) CreateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO timesheet_tasks (tenant_id, project_id, name, billable, hourly_rate, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		task.ProjectID,
+		task.Name,
+		task.Billable,
+		task.HourlyRate,
+		task.IsActive,
+	).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create timesheet task: %w", err)
+	}
+
+	task.TenantID = tenantID
+	return tx.Commit()
+}
+
+// FindTaskByID retrieves a timesheet task by ID with RLS
+func (r *TimesheetRepository) FindTaskByID(ctx context.Context, tenantID, taskID uuid.UUID) (*models.TimesheetTask, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var task models.TimesheetTask
+	query := `SELECT * FROM timesheet_tasks WHERE tenant_id = $1 AND id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &task, query, tenantID, taskID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("TASK_NOT_FOUND", "task not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet task: %w", err)
+	}
+
+	return &task, nil
+}
+
+// ListTasks retrieves the tasks of one project, or of all projects when
+// projectID is nil, sorted by name
+func (r *TimesheetRepository) ListTasks(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, activeOnly bool) ([]models.TimesheetTask, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tasks := []models.TimesheetTask{}
+	query := `
+		SELECT * FROM timesheet_tasks
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR project_id = $2) AND (NOT $3 OR is_active)
+		ORDER BY name ASC
+	`
+
+	if err := tx.SelectContext(ctx, &tasks, query, tenantID, projectID, activeOnly); err != nil {
+		return nil, fmt.Errorf("failed to list timesheet tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// UpdateTask updates a timesheet task with RLS
+func (r *TimesheetRepository) UpdateTask(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE timesheet_tasks
+		SET name = $1, billable = $2, hourly_rate = $3, is_active = $4
+		WHERE tenant_id = $5 AND id = $6
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx, query,
+		task.Name,
+		task.Billable,
+		task.HourlyRate,
+		task.IsActive,
+		tenantID,
+		task.ID,
+	).Scan(&task.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("TASK_NOT_FOUND", "task not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update timesheet task: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTask deletes a timesheet task. Tasks with logged time cannot be
+// deleted; deactivate them instead.
+func (r *TimesheetRepository) DeleteTask(ctx context.Context, tenantID, taskID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	query := `SELECT EXISTS(SELECT 1 FROM timesheet_entries WHERE tenant_id = $1 AND task_id = $2)`
+	if err := tx.GetContext(ctx, &inUse, query, tenantID, taskID); err != nil {
+		return fmt.Errorf("failed to check timesheet task usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("task is in use")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM timesheet_tasks WHERE tenant_id = $1 AND id = $2`, tenantID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete timesheet task: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("TASK_NOT_FOUND", "task not found")
+	}
+
+	return tx.Commit()
+}
+
+// CheckTaskNameExists checks if a task name is already used in a project
+func (r *TimesheetRepository) CheckTaskNameExists(ctx context.Context, tenantID, projectID uuid.UUID, name string, excludeID *uuid.UUID) (bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM timesheet_tasks
+			WHERE tenant_id = $1 AND project_id = $2 AND name = $3 AND ($4::uuid IS NULL OR id != $4)
+		)
+	`
+	if err := tx.GetContext(ctx, &exists, query, tenantID, projectID, name, excludeID); err != nil {
+		return false, fmt.Errorf("failed to check timesheet task name: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindTimesheetByID retrieves a timesheet with details by ID with RLS
+func (r *TimesheetRepository) FindTimesheetByID(ctx context.Context, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var timesheet models.Timesheet
+	query := timesheetSelect + ` WHERE ts.tenant_id = $1 AND ts.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &timesheet, query, tenantID, timesheetID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("TIMESHEET_NOT_FOUND", "timesheet not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// FindTimesheetByWeek retrieves an employee's timesheet for the week starting
+// on weekStart. Returns nil if none was started.
+func (r *TimesheetRepository) FindTimesheetByWeek(ctx context.Context, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var timesheet models.Timesheet
+	query := timesheetSelect + ` WHERE ts.tenant_id = $1 AND ts.employee_id = $2 AND ts.week_start = $3 LIMIT 1`
+
+	err = tx.GetContext(ctx, &timesheet, query, tenantID, employeeID, weekStart)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// LockWeek retrieves an employee's timesheet for the week starting on
+// weekStart, creating it as a draft if needed, and locks it until tx ends
+func (r *TimesheetRepository) LockWeek(ctx context.Context, tx *sqlx.Tx, tenantID, employeeID uuid.UUID, weekStart time.Time) (*models.Timesheet, error) {
+	insert := `
+		INSERT INTO timesheets (tenant_id, employee_id, week_start)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, employee_id, week_start) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, insert, tenantID, employeeID, weekStart); err != nil {
+		return nil, fmt.Errorf("failed to create timesheet: %w", err)
+	}
+
+	var timesheet models.Timesheet
+	query := timesheetSelect + ` WHERE ts.tenant_id = $1 AND ts.employee_id = $2 AND ts.week_start = $3 FOR UPDATE OF ts`
+
+	if err := tx.GetContext(ctx, &timesheet, query, tenantID, employeeID, weekStart); err != nil {
+		return nil, fmt.Errorf("failed to find timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// LockTimesheet retrieves a timesheet with details and locks it until tx
+// ends
+func (r *TimesheetRepository) LockTimesheet(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	var timesheet models.Timesheet
+	query := timesheetSelect + ` WHERE ts.tenant_id = $1 AND ts.id = $2 FOR UPDATE OF ts`
+
+	err := tx.GetContext(ctx, &timesheet, query, tenantID, timesheetID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("TIMESHEET_NOT_FOUND", "timesheet not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet: %w", err)
+	}
+
+	return &timesheet, nil
+}
+
+// UpdateTimesheetStatus records a timesheet's status, submission and decision
+// within tx
+func (r *TimesheetRepository) UpdateTimesheetStatus(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error {
+	query := `
+		UPDATE timesheets
+		SET status = $1, submitted_at = $2, approver_id = $3, decided_by = $4, decided_at = $5, decision_note = $6
+		WHERE tenant_id = $7 AND id = $8
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		timesheet.Status,
+		timesheet.SubmittedAt,
+		timesheet.ApproverID,
+		timesheet.DecidedBy,
+		timesheet.DecidedAt,
+		timesheet.DecisionNote,
+		timesheet.TenantID,
+		timesheet.ID,
+	).Scan(&timesheet.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update timesheet: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshTotal recomputes a timesheet's total hours from its entries within
+// tx
+func (r *TimesheetRepository) RefreshTotal(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet) error {
+	query := `
+		UPDATE timesheets
+		SET total_hours = COALESCE((
+			SELECT SUM(hours) FROM timesheet_entries WHERE tenant_id = $1 AND timesheet_id = $2
+		), 0)
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING total_hours, updated_at
+	`
+	if err := tx.QueryRowContext(ctx, query, timesheet.TenantID, timesheet.ID).Scan(&timesheet.TotalHours, &timesheet.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update timesheet total: %w", err)
+	}
+	return nil
+}
+
+// ListTimesheets retrieves timesheets with filters and pagination, latest
+// week first. visibleTo, when set, restricts the list to the user's own
+// timesheets and those of the employees they manage or were asked to decide.
+func (r *TimesheetRepository) ListTimesheets(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetFilter, visibleTo *uuid.UUID, limit, offset int) ([]models.Timesheet, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `
+		WHERE ts.tenant_id = $1
+		AND ($2::uuid IS NULL OR ts.employee_id = $2)
+		AND ($3::uuid IS NULL OR e.department_id = $3)
+		AND ($4::uuid IS NULL OR ts.approver_id = $4
+			OR EXISTS(SELECT 1 FROM employees m WHERE m.tenant_id = e.tenant_id AND m.id = e.manager_id AND m.user_id = $4))
+		AND ($5 = '' OR ts.status = $5)
+		AND ($6::date IS NULL OR ts.week_start >= $6)
+		AND ($7::date IS NULL OR ts.week_start <= $7)
+		AND ($8::uuid IS NULL OR e.user_id = $8 OR ts.approver_id = $8
+			OR EXISTS(SELECT 1 FROM employees m WHERE m.tenant_id = e.tenant_id AND m.id = e.manager_id AND m.user_id = $8))
+	`
+	args := []interface{}{tenantID, filter.EmployeeID, filter.DepartmentID, filter.ApproverID, filter.Status, filter.From, filter.To, visibleTo}
+
+	from := `
+		FROM timesheets ts
+		JOIN employees e ON e.tenant_id = ts.tenant_id AND e.id = ts.employee_id
+	`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) `+from+where, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count timesheets: %w", err)
+	}
+
+	timesheets := []models.Timesheet{}
+	query := timesheetSelect + where + ` ORDER BY ts.week_start DESC, employee_name ASC LIMIT $9 OFFSET $10`
+
+	if err := tx.SelectContext(ctx, &timesheets, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list timesheets: %w", err)
+	}
+
+	return timesheets, totalCount, nil
+}
+
+// ListEntries retrieves the entries of a timesheet, by day
+func (r *TimesheetRepository) ListEntries(ctx context.Context, tenantID, timesheetID uuid.UUID) ([]*models.TimesheetEntry, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entries := []*models.TimesheetEntry{}
+	query := timesheetEntrySelect + ` WHERE te.tenant_id = $1 AND te.timesheet_id = $2 ORDER BY te.work_date ASC, te.created_at ASC`
+
+	if err := tx.SelectContext(ctx, &entries, query, tenantID, timesheetID); err != nil {
+		return nil, fmt.Errorf("failed to list timesheet entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// FindEntryByID retrieves a timesheet entry with details by ID with RLS
+func (r *TimesheetRepository) FindEntryByID(ctx context.Context, tenantID, entryID uuid.UUID) (*models.TimesheetEntry, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var entry models.TimesheetEntry
+	query := timesheetEntrySelect + ` WHERE te.tenant_id = $1 AND te.id = $2 LIMIT 1`
+
+	err = tx.GetContext(ctx, &entry, query, tenantID, entryID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("TIMESHEET_ENTRY_NOT_FOUND", "timesheet entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find timesheet entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// CreateEntry creates a timesheet entry within tx
+func (r *TimesheetRepository) CreateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error {
+	query := `
+		INSERT INTO timesheet_entries (
+			tenant_id, timesheet_id, project_id, task_id, work_date, hours,
+			description, billable, hourly_rate, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		entry.TenantID,
+		entry.TimesheetID,
+		entry.ProjectID,
+		entry.TaskID,
+		entry.WorkDate,
+		entry.Hours,
+		entry.Description,
+		entry.Billable,
+		entry.HourlyRate,
+		entry.CreatedBy,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create timesheet entry: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEntry updates a timesheet entry within tx
+func (r *TimesheetRepository) UpdateEntry(ctx context.Context, tx *sqlx.Tx, entry *models.TimesheetEntry) error {
+	query := `
+		UPDATE timesheet_entries
+		SET project_id = $1, task_id = $2, work_date = $3, hours = $4,
+			description = $5, billable = $6, hourly_rate = $7
+		WHERE tenant_id = $8 AND id = $9
+		RETURNING updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		entry.ProjectID,
+		entry.TaskID,
+		entry.WorkDate,
+		entry.Hours,
+		entry.Description,
+		entry.Billable,
+		entry.HourlyRate,
+		entry.TenantID,
+		entry.ID,
+	).Scan(&entry.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("TIMESHEET_ENTRY_NOT_FOUND", "timesheet entry not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update timesheet entry: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEntry deletes a timesheet entry within tx
+func (r *TimesheetRepository) DeleteEntry(ctx context.Context, tx *sqlx.Tx, tenantID, entryID uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM timesheet_entries WHERE tenant_id = $1 AND id = $2`, tenantID, entryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete timesheet entry: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return utils.NewNotFoundError("TIMESHEET_ENTRY_NOT_FOUND", "timesheet entry not found")
+	}
+
+	return nil
+}
+
+// DayHours sums within tx the hours a timesheet has on a day, leaving out
+// excludeID
+func (r *TimesheetRepository) DayHours(ctx context.Context, tx *sqlx.Tx, tenantID, timesheetID uuid.UUID, day time.Time, excludeID *uuid.UUID) (float64, error) {
+	var hours float64
+	query := `
+		SELECT COALESCE(SUM(hours), 0) FROM timesheet_entries
+		WHERE tenant_id = $1 AND timesheet_id = $2 AND work_date = $3 AND ($4::uuid IS NULL OR id != $4)
+	`
+	if err := tx.GetContext(ctx, &hours, query, tenantID, timesheetID, day, excludeID); err != nil {
+		return 0, fmt.Errorf("failed to sum timesheet hours: %w", err)
+	}
+	return hours, nil
+}
+
+// Report sums the time logged over a period per project, or per employee
+// when byEmployee is set. Rows are split by the projects' currency.
+func (r *TimesheetRepository) Report(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter, byEmployee bool) ([]models.TimesheetReportRow, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	columns := `p.id AS project_id, p.code AS project_code, p.name AS project_name, p.customer_id, c.name AS customer_name,`
+	groupBy := `GROUP BY p.id, p.code, p.name, p.customer_id, c.name, p.currency ORDER BY p.code ASC, p.currency ASC`
+	if byEmployee {
+		columns = `e.id AS employee_id, e.first_name || ' ' || e.last_name AS employee_name,`
+		groupBy = `GROUP BY e.id, e.first_name, e.last_name, p.currency ORDER BY employee_name ASC, p.currency ASC`
+	}
+
+	query := `
+		SELECT
+			` + columns + `
+			SUM(te.hours) AS hours,
+			COALESCE(SUM(te.hours) FILTER (WHERE te.billable), 0) AS billable_hours,
+			ROUND(COALESCE(SUM(te.hours * te.hourly_rate) FILTER (WHERE te.billable), 0), 2) AS billable_amount,
+			p.currency
+		FROM timesheet_entries te
+		JOIN timesheets ts ON ts.tenant_id = te.tenant_id AND ts.id = te.timesheet_id
+		JOIN employees e ON e.tenant_id = ts.tenant_id AND e.id = ts.employee_id
+		JOIN timesheet_projects p ON p.tenant_id = te.tenant_id AND p.id = te.project_id
+		LEFT JOIN crm_customers c ON c.tenant_id = p.tenant_id AND c.id = p.customer_id
+		WHERE te.tenant_id = $1
+			AND te.work_date >= $2 AND te.work_date <= $3
+			AND ($4::uuid IS NULL OR te.project_id = $4)
+			AND ($5::uuid IS NULL OR p.customer_id = $5)
+			AND ($6::uuid IS NULL OR ts.employee_id = $6)
+			AND ($7 OR ts.status = 'approved')
+		` + groupBy
+
+	rows := []models.TimesheetReportRow{}
+	err = tx.SelectContext(
+		ctx, &rows, query,
+		tenantID, filter.From, filter.To, filter.ProjectID, filter.CustomerID, filter.EmployeeID, filter.IncludeUnapproved,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build timesheet report: %w", err)
+	}
+
+	return rows, nil
+}
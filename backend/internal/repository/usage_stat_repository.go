@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+)
+
+// UsageStatRepository handles the anonymized daily usage analytics. They are
+// cluster-wide and live in the catalog database.
+type UsageStatRepository struct {
+	db *sqlx.DB
+}
+
+// NewUsageStatRepository creates a new usage stat repository
+func NewUsageStatRepository(db *sqlx.DB) *UsageStatRepository {
+	return &UsageStatRepository{db: db}
+}
+
+// Add adds the counters of a replica to the daily rows. Active user counts
+// are already cluster-wide, so the highest count of the day is kept instead.
+func (r *UsageStatRepository) Add(ctx context.Context, stats []models.UsageStat) error {
+	return database.ExecInTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO usage_stats (day, tenant_ref, kind, name, count)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (day, tenant_ref, kind, name) DO UPDATE SET
+				count = CASE
+					WHEN usage_stats.kind = 'active_users' THEN GREATEST(usage_stats.count, EXCLUDED.count)
+					ELSE usage_stats.count + EXCLUDED.count
+				END
+		`
+
+		for _, stat := range stats {
+			if _, err := tx.ExecContext(ctx, query, stat.Day, stat.TenantRef, stat.Kind, stat.Name, stat.Count); err != nil {
+				return fmt.Errorf("failed to record usage stats: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListDay retrieves all usage rows of a day
+func (r *UsageStatRepository) ListDay(ctx context.Context, day time.Time) ([]models.UsageStat, error) {
+	query := `
+		SELECT day, tenant_ref, kind, name, count
+		FROM usage_stats
+		WHERE day = $1
+		ORDER BY tenant_ref, kind, name
+	`
+
+	stats := []models.UsageStat{}
+	if err := r.db.SelectContext(ctx, &stats, query, day); err != nil {
+		return nil, fmt.Errorf("failed to list usage stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// DeleteTenant deletes all usage rows of a tenant reference
+func (r *UsageStatRepository) DeleteTenant(ctx context.Context, tenantRef string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM usage_stats WHERE tenant_ref = $1`, tenantRef)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete usage stats: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
+
+// DeleteBefore deletes the daily rows older than a day
+func (r *UsageStatRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM usage_stats WHERE day < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete usage stats: %w", err)
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
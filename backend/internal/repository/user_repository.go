@@ -3,12 +3,18 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/utils"
 )
 
 // UserRepository handles database operations for users
@@ -29,11 +35,15 @@ func (r *UserRepository) Create(ctx context.Context, tenantID uuid.UUID, user *m
 	}
 	defer tx.Rollback()
 
+	if len(user.CustomFields) == 0 {
+		user.CustomFields = json.RawMessage(`{}`)
+	}
+
 	query := `
 		INSERT INTO users (
 			tenant_id, email, password_hash, first_name, last_name,
-			phone, status, timezone, language, preferences, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			phone, status, timezone, language, preferences, custom_fields, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -49,6 +59,7 @@ func (r *UserRepository) Create(ctx context.Context, tenantID uuid.UUID, user *m
 		user.Timezone,
 		user.Language,
 		user.Preferences,
+		user.CustomFields,
 		user.CreatedBy,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
@@ -60,7 +71,7 @@ func (r *UserRepository) Create(ctx context.Context, tenantID uuid.UUID, user *m
 	return tx.Commit()
 }
 
-// FindByID retrieves a user by ID with RLS
+// FindByID retrieves a live (not deleted) user by ID with RLS
 func (r *UserRepository) FindByID(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -69,11 +80,11 @@ func (r *UserRepository) FindByID(ctx context.Context, tenantID, userID uuid.UUI
 	defer tx.Rollback()
 
 	var user models.User
-	query := `SELECT * FROM users WHERE id = $1 LIMIT 1`
+	query := `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
 
 	err = tx.GetContext(ctx, &user, query, userID)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
@@ -82,7 +93,7 @@ func (r *UserRepository) FindByID(ctx context.Context, tenantID, userID uuid.UUI
 	return &user, nil
 }
 
-// FindByEmail retrieves a user by email with RLS
+// FindByEmail retrieves a live user by email with RLS
 func (r *UserRepository) FindByEmail(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -91,11 +102,11 @@ func (r *UserRepository) FindByEmail(ctx context.Context, tenantID uuid.UUID, em
 	defer tx.Rollback()
 
 	var user models.User
-	query := `SELECT * FROM users WHERE email = $1 LIMIT 1`
+	query := `SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT 1`
 
 	err = tx.GetContext(ctx, &user, query, email)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
@@ -105,10 +116,34 @@ func (r *UserRepository) FindByEmail(ctx context.Context, tenantID uuid.UUID, em
 }
 
 // FindAllByEmail finds all users with the given email across all tenants (bypasses RLS)
-// This is used for login when user can belong to multiple tenants
+// This is used for login when user can belong to multiple tenants.
+// In multi-region deployments every region is searched.
 func (r *UserRepository) FindAllByEmail(ctx context.Context, email string) ([]models.User, error) {
+	var users []models.User
+	seen := make(map[uuid.UUID]bool)
+
+	for _, db := range database.RegionDBs(r.db) {
+		regionUsers, err := r.findAllByEmailIn(ctx, db, email)
+		if err != nil {
+			return nil, err
+		}
+
+		// A tenant being moved between regions can briefly exist in both
+		for _, user := range regionUsers {
+			if !seen[user.ID] {
+				seen[user.ID] = true
+				users = append(users, user)
+			}
+		}
+	}
+
+	return users, nil
+}
+
+// findAllByEmailIn runs the cross-tenant email lookup against one database
+func (r *UserRepository) findAllByEmailIn(ctx context.Context, db *sqlx.DB, email string) ([]models.User, error) {
 	// Use WithBypassRLS to search across all tenants
-	tx, err := database.WithBypassRLS(ctx, r.db)
+	tx, err := database.WithBypassRLS(ctx, db)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +155,7 @@ func (r *UserRepository) FindAllByEmail(ctx context.Context, email string) ([]mo
 		JOIN tenants t ON u.tenant_id = t.id
 		WHERE u.email = $1
 		AND u.status = 'active'
+		AND u.deleted_at IS NULL
 		AND t.status = 'active'
 		ORDER BY u.created_at ASC
 	`
@@ -149,12 +185,13 @@ func (r *UserRepository) FindByResetToken(ctx context.Context, tenantID uuid.UUI
 		SELECT * FROM users
 		WHERE reset_token = $1
 		  AND reset_token_expires_at > NOW()
+		  AND deleted_at IS NULL
 		LIMIT 1
 	`
 
 	err = tx.GetContext(ctx, &user, query, token)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("invalid or expired reset token")
+		return nil, utils.NewBadRequestError("INVALID_RESET_TOKEN", "invalid or expired reset token")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
@@ -163,7 +200,9 @@ func (r *UserRepository) FindByResetToken(ctx context.Context, tenantID uuid.UUI
 	return &user, nil
 }
 
-// Update updates a user's information
+// Update updates a user's information. It fails with VERSION_CONFLICT when
+// the user changed since it was read, i.e. its updated_at is no longer the
+// one of user.
 func (r *UserRepository) Update(ctx context.Context, tenantID uuid.UUID, user *models.User) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -180,8 +219,9 @@ func (r *UserRepository) Update(ctx context.Context, tenantID uuid.UUID, user *m
 		    timezone = $5,
 		    language = $6,
 		    preferences = $7,
+		    custom_fields = $8,
 		    updated_at = NOW()
-		WHERE id = $8
+		WHERE id = $9 AND updated_at = $10
 		RETURNING updated_at
 	`
 
@@ -194,9 +234,21 @@ func (r *UserRepository) Update(ctx context.Context, tenantID uuid.UUID, user *m
 		user.Timezone,
 		user.Language,
 		user.Preferences,
+		user.CustomFields,
 		user.ID,
+		user.UpdatedAt,
 	).Scan(&user.UpdatedAt)
 
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", user.ID); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if exists {
+			return utils.NewConflictError("VERSION_CONFLICT", "user was changed in the meantime")
+		}
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -204,14 +256,65 @@ func (r *UserRepository) Update(ctx context.Context, tenantID uuid.UUID, user *m
 	return tx.Commit()
 }
 
-// UpdatePassword updates a user's password
-func (r *UserRepository) UpdatePassword(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string) error {
+// GetPasswordHistory returns the hashes of the passwords a user replaced,
+// most recent first
+func (r *UserRepository) GetPasswordHistory(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]string, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT password_hash FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	var hashes []string
+	if err := tx.SelectContext(ctx, &hashes, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get password history: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// UpdatePassword updates a user's password. The replaced password is kept
+// in the password history, which is pruned to the historyCount most recent
+// passwords, the new one included.
+func (r *UserRepository) UpdatePassword(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string, historyCount int) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if historyCount > 1 {
+		historyQuery := `
+			INSERT INTO password_history (tenant_id, user_id, password_hash)
+			SELECT tenant_id, id, password_hash FROM users
+			WHERE id = $1 AND password_hash <> ''
+		`
+		if _, err := tx.ExecContext(ctx, historyQuery, userID); err != nil {
+			return fmt.Errorf("failed to record password history: %w", err)
+		}
+	}
+
+	pruneQuery := `
+		DELETE FROM password_history
+		WHERE user_id = $1
+		  AND id NOT IN (
+		    SELECT id FROM password_history
+		    WHERE user_id = $1
+		    ORDER BY created_at DESC
+		    LIMIT $2
+		  )
+	`
+	if _, err := tx.ExecContext(ctx, pruneQuery, userID, max(historyCount-1, 0)); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
 	query := `
 		UPDATE users
 		SET password_hash = $1,
@@ -228,7 +331,7 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, tenantID, userID uu
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	return tx.Commit()
@@ -256,7 +359,7 @@ func (r *UserRepository) UpdateStatus(ctx context.Context, tenantID, userID uuid
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	return tx.Commit()
@@ -308,7 +411,7 @@ func (r *UserRepository) VerifyEmail(ctx context.Context, tenantID, userID uuid.
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	return tx.Commit()
@@ -337,7 +440,131 @@ func (r *UserRepository) SetResetToken(ctx context.Context, tenantID, userID, to
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	return tx.Commit()
+}
+
+// ReplacePasswordHash replaces a user's password without recording it in
+// their password history: used to lock out a password nobody should use
+// any more until the user resets it
+func (r *UserRepository) ReplacePasswordHash(ctx context.Context, tenantID, userID uuid.UUID, passwordHash string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET password_hash = $1,
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := tx.ExecContext(ctx, query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to replace password: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	return tx.Commit()
+}
+
+// SetEmailChange records the email a user asked to change to, with the token
+// confirming it. It replaces any change awaiting confirmation.
+func (r *UserRepository) SetEmailChange(ctx context.Context, tenantID, userID uuid.UUID, pendingEmail string, token uuid.UUID, expiresAt time.Time) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET pending_email = $1,
+		    email_change_token = $2,
+		    email_change_expires_at = $3,
+		    updated_at = NOW()
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, pendingEmail, token, expiresAt, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set email change: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	return tx.Commit()
+}
+
+// FindByEmailChangeToken retrieves a user by email change token
+func (r *UserRepository) FindByEmailChangeToken(ctx context.Context, tenantID, token uuid.UUID) (*models.User, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var user models.User
+	query := `
+		SELECT * FROM users
+		WHERE email_change_token = $1
+		  AND email_change_expires_at > NOW()
+		  AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	err = tx.GetContext(ctx, &user, query, token)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewBadRequestError("INVALID_EMAIL_CHANGE_TOKEN", "invalid or expired email change token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// ConfirmEmailChange makes a user's pending email their email. The address
+// was proven theirs by the emailed token, so it counts as verified.
+func (r *UserRepository) ConfirmEmailChange(ctx context.Context, tenantID, userID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET email = pending_email,
+		    email_verified = true,
+		    pending_email = NULL,
+		    email_change_token = NULL,
+		    email_change_expires_at = NULL,
+		    email_undeliverable_at = NULL,
+		    email_undeliverable_reason = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND pending_email IS NOT NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to change email: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	return tx.Commit()
@@ -418,60 +645,103 @@ func (r *UserRepository) UseBackupCode(ctx context.Context, tenantID, userID uui
 	return tx.Commit()
 }
 
-// List retrieves a paginated list of users with RLS
-func (r *UserRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.User, int, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+// UserListSpec lists the fields users can be sorted by
+var UserListSpec = pagination.Spec{
+	Columns: map[string]string{
+		"created_at": "created_at",
+		"email":      "email",
+		"first_name": "first_name",
+		"last_name":  "last_name",
+	},
+	Default:  pagination.Sort{Field: "created_at", Desc: true},
+	IDColumn: "id",
+}
+
+// List retrieves a page of live users matching filter with RLS, with their
+// total count and the cursor to the next page
+func (r *UserRepository) List(ctx context.Context, tenantID uuid.UUID, filter models.UserFilter, page *pagination.Request) ([]models.User, int, string, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 	defer tx.Rollback()
 
-	var users []models.User
-	var totalCount int
+	where := &pagination.Where{}
+	where.Add("deleted_at IS NULL")
+	where.Equal("status", filter.Status)
+	where.EqualID("department_id", filter.DepartmentID)
+	if filter.RoleID != nil {
+		where.Add("EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = users.id AND ur.role_id = $%d)", *filter.RoleID)
+	}
+	where.Range("created_at", filter.CreatedFrom, filter.CreatedTo)
+	if len(filter.CustomFields) > 0 {
+		where.Add("custom_fields @> $%d::jsonb", filter.CustomFields)
+	}
 
 	// Get total count
-	countQuery := `SELECT COUNT(*) FROM users`
-	err = tx.GetContext(ctx, &totalCount, countQuery)
+	var totalCount int
+	err = tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM users `+where.SQL(), where.Args()...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+		return nil, 0, "", fmt.Errorf("failed to count users: %w", err)
 	}
 
-	// Get paginated results
-	query := `
-		SELECT * FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+	// Get the page
+	page.Apply(where)
+	query := `SELECT * FROM users ` + where.SQL() + ` ` + page.OrderBy() + ` ` + page.Limit(where)
 
-	err = tx.SelectContext(ctx, &users, query, limit, offset)
+	var users []models.User
+	err = tx.SelectContext(ctx, &users, query, where.Args()...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return nil, 0, "", fmt.Errorf("failed to list users: %w", err)
 	}
 
-	return users, totalCount, nil
+	users, nextCursor := pagination.Cut(page, users, func(user *models.User, field string) (interface{}, uuid.UUID) {
+		switch field {
+		case "email":
+			return user.Email, user.ID
+		case "first_name":
+			return user.FirstName, user.ID
+		case "last_name":
+			return user.LastName, user.ID
+		default:
+			return user.CreatedAt, user.ID
+		}
+	})
+
+	return users, totalCount, nextCursor, nil
 }
 
-// Search searches for users by name or email
-func (r *UserRepository) Search(ctx context.Context, tenantID uuid.UUID, searchTerm string, limit int) ([]models.User, error) {
-	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+// Search searches live users by name or email, accent-insensitively: prefix
+// full-text matches first, then similar names and emails for typos. config is
+// the searcher's text search configuration (see models.SearchConfig).
+func (r *UserRepository) Search(ctx context.Context, tenantID uuid.UUID, searchTerm, config string, limit int) ([]models.User, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, searchSimilarityThreshold); err != nil {
+		return nil, fmt.Errorf("failed to set search threshold: %w", err)
+	}
+
 	var users []models.User
 	query := `
-		SELECT * FROM users
-		WHERE email ILIKE $1
-		   OR first_name ILIKE $1
-		   OR last_name ILIKE $1
-		   OR (first_name || ' ' || last_name) ILIKE $1
-		ORDER BY created_at DESC
-		LIMIT $2
+		WITH q AS (SELECT search_query($2, $3::regconfig) AS ts, search_normalize($2) AS text)
+		SELECT u.* FROM users u, q
+		WHERE u.tenant_id = $1 AND u.deleted_at IS NULL
+		  AND (search_vector(` + userSearchText + `) @@ q.ts
+		   OR search_normalize(` + userSearchText + `) %> q.text)
+		ORDER BY
+			CASE WHEN search_vector(` + userSearchText + `) @@ q.ts
+				THEN 1 + ts_rank(search_vector(` + userSearchText + `), q.ts)
+				ELSE word_similarity(q.text, search_normalize(` + userSearchText + `))
+			END DESC,
+			u.created_at DESC
+		LIMIT $4
 	`
 
-	searchPattern := "%" + searchTerm + "%"
-	err = tx.SelectContext(ctx, &users, query, searchPattern, limit)
+	err = tx.SelectContext(ctx, &users, query, tenantID, searchTerm, config, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
@@ -479,29 +749,229 @@ func (r *UserRepository) Search(ctx context.Context, tenantID uuid.UUID, searchT
 	return users, nil
 }
 
-// Delete deletes a user (hard delete)
-func (r *UserRepository) Delete(ctx context.Context, tenantID, userID uuid.UUID) error {
+// StreamForExport reads every live user for an export, in list order, and
+// calls fn with each one as it is read rather than loading them all
+func (r *UserRepository) StreamForExport(ctx context.Context, tenantID uuid.UUID, fn func(*models.UserExport) error) error {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT u.id, u.email, u.first_name, u.last_name, u.phone,
+		       COALESCE(array_agg(r.name ORDER BY r.level, r.name) FILTER (WHERE r.id IS NOT NULL), '{}') AS roles,
+		       d.name AS department,
+		       u.status, u.email_verified,
+		       COALESCE(u.two_factor_enabled, FALSE) AS two_factor_enabled,
+		       u.last_login_at, u.created_at
+		FROM users u
+		LEFT JOIN departments d ON d.tenant_id = u.tenant_id AND d.id = u.department_id
+		LEFT JOIN user_roles ur ON ur.tenant_id = u.tenant_id AND ur.user_id = u.id AND ` + activeUserRole + `
+		LEFT JOIN roles r ON r.tenant_id = ur.tenant_id AND r.id = ur.role_id
+		WHERE u.deleted_at IS NULL
+		GROUP BY u.tenant_id, u.id, d.name
+		ORDER BY u.created_at DESC
+	`
+
+	rows, err := tx.QueryxContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.UserExport
+		if err := rows.StructScan(&user); err != nil {
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete soft-deletes a user: the row is kept for audit history and can be
+// restored, but the user is hidden and their sessions are revoked
+func (r *UserRepository) Delete(ctx context.Context, tenantID, userID, deletedBy uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	query := `DELETE FROM users WHERE id = $1`
+	query := `
+		UPDATE users
+		SET deleted_at = NOW(), deleted_by = $2, reset_token = NULL, reset_token_expires_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
 
-	result, err := tx.ExecContext(ctx, query, userID)
+	result, err := tx.ExecContext(ctx, query, userID, deletedBy)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
 	}
 
 	return tx.Commit()
 }
 
+// Restore brings back a soft-deleted user. It fails if another live user
+// has taken the email in the meantime.
+func (r *UserRepository) Restore(ctx context.Context, tenantID, userID uuid.UUID) (*models.User, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var emailTaken bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM users live
+			JOIN users deleted ON deleted.email = live.email AND deleted.tenant_id = live.tenant_id
+			WHERE deleted.id = $1 AND live.deleted_at IS NULL
+		)
+	`
+	if err := tx.GetContext(ctx, &emailTaken, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
+	}
+	if emailTaken {
+		return nil, utils.NewConflictError("EMAIL_IN_USE", "another user already uses this email")
+	}
+
+	var user models.User
+	query = `
+		UPDATE users
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING *
+	`
+
+	err = tx.GetContext(ctx, &user, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("DELETED_USER_NOT_FOUND", "deleted user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &user, nil
+}
+
+// ListDeleted retrieves a paginated list of soft-deleted users, most
+// recently deleted first
+func (r *UserRepository) ListDeleted(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.User, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var users []models.User
+	var totalCount int
+
+	countQuery := `SELECT COUNT(*) FROM users WHERE deleted_at IS NOT NULL`
+	err = tx.GetContext(ctx, &totalCount, countQuery)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted users: %w", err)
+	}
+
+	query := `
+		SELECT * FROM users
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err = tx.SelectContext(ctx, &users, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted users: %w", err)
+	}
+
+	return users, totalCount, nil
+}
+
+// PurgeDeletedBefore permanently removes users soft-deleted before cutoff,
+// across all tenants and data regions (bypasses RLS). Their audit log
+// entries are kept without the user reference.
+func (r *UserRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(r.db) {
+		tx, err := database.WithBypassRLS(ctx, db)
+		if err != nil {
+			return total, err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE deleted_at < $1`, cutoff)
+		if err != nil {
+			tx.Rollback()
+			return total, fmt.Errorf("failed to purge deleted users: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return total, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		purged, _ := result.RowsAffected()
+		total += int(purged)
+	}
+
+	return total, nil
+}
+
+// MarkEmailUndeliverable marks the address as undeliverable for every user
+// with it, across all tenants and data regions (bypasses RLS), and returns
+// the users it newly marked. Users already marked keep their first reason.
+func (r *UserRepository) MarkEmailUndeliverable(ctx context.Context, email, reason string) ([]models.User, error) {
+	var marked []models.User
+	for _, db := range database.RegionDBs(r.db) {
+		tx, err := database.WithBypassRLS(ctx, db)
+		if err != nil {
+			return marked, err
+		}
+
+		var users []models.User
+		query := `
+			UPDATE users
+			SET email_undeliverable_at = NOW(),
+			    email_undeliverable_reason = $2,
+			    updated_at = NOW()
+			WHERE LOWER(email) = LOWER($1)
+			AND email_undeliverable_at IS NULL
+			RETURNING *
+		`
+		if err := tx.SelectContext(ctx, &users, query, email, reason); err != nil {
+			tx.Rollback()
+			return marked, fmt.Errorf("failed to mark email undeliverable: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return marked, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		marked = append(marked, users...)
+	}
+
+	return marked, nil
+}
+
 // CheckEmailExists checks if an email is already registered in the tenant
 func (r *UserRepository) CheckEmailExists(ctx context.Context, tenantID uuid.UUID, email string) (bool, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
@@ -511,7 +981,7 @@ func (r *UserRepository) CheckEmailExists(ctx context.Context, tenantID uuid.UUI
 	defer tx.Rollback()
 
 	var count int
-	query := `SELECT COUNT(*) FROM users WHERE email = $1`
+	query := `SELECT COUNT(*) FROM users WHERE email = $1 AND deleted_at IS NULL`
 
 	err = tx.GetContext(ctx, &count, query, email)
 	if err != nil {
@@ -521,6 +991,137 @@ func (r *UserRepository) CheckEmailExists(ctx context.Context, tenantID uuid.UUI
 	return count > 0, nil
 }
 
+// FindExistingEmails returns which of emails belong to a live user, compared
+// case-insensitively, as a set of lowercased emails
+func (r *UserRepository) FindExistingEmails(ctx context.Context, tenantID uuid.UUID, emails []string) (map[string]bool, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	var existing []string
+	query := `SELECT LOWER(email) FROM users WHERE LOWER(email) = ANY($1) AND deleted_at IS NULL`
+
+	if err := tx.SelectContext(ctx, &existing, query, pq.Array(lowered)); err != nil {
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
+	}
+
+	result := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		result[email] = true
+	}
+	return result, nil
+}
+
+// CreateImported creates an imported user with their department, roles and
+// set-password token, within the caller's tenant transaction. The user has no
+// password (and cannot sign in) until they set one with the token. Returns
+// "email already registered" if a live user has taken the email meanwhile.
+func (r *UserRepository) CreateImported(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, user *models.User, roleIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	query := `
+		INSERT INTO users (
+			tenant_id, email, password_hash, first_name, last_name, phone, department_id,
+			status, timezone, language, preferences, reset_token, reset_token_expires_at, created_by
+		) VALUES ($1, $2, '!', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tenant_id, email) WHERE deleted_at IS NULL DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(
+		ctx, query,
+		tenantID,
+		user.Email,
+		user.FirstName,
+		user.LastName,
+		user.Phone,
+		user.DepartmentID,
+		user.Status,
+		user.Timezone,
+		user.Language,
+		user.Preferences,
+		user.ResetToken,
+		user.ResetTokenExpiresAt,
+		user.CreatedBy,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewConflictError("EMAIL_ALREADY_REGISTERED", "email already registered")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	user.TenantID = tenantID
+
+	insertRole := `
+		INSERT INTO user_roles (tenant_id, user_id, role_id, assigned_by)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, roleID := range roleIDs {
+		if _, err := tx.ExecContext(ctx, insertRole, tenantID, user.ID, roleID, assignedBy); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListActiveIDs returns the IDs of a tenant's active users
+func (r *UserRepository) ListActiveIDs(ctx context.Context, tenantID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := []uuid.UUID{}
+	query := `SELECT id FROM users WHERE status = $1 AND deleted_at IS NULL`
+
+	if err := tx.SelectContext(ctx, &ids, query, models.UserStatusActive); err != nil {
+		return nil, fmt.Errorf("failed to list active users: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListWithoutSecondFactor retrieves the active users who have neither TOTP
+// nor a security key. With role IDs, only users with one of the roles are
+// listed.
+func (r *UserRepository) ListWithoutSecondFactor(ctx context.Context, tenantID uuid.UUID, roleIDs []uuid.UUID) ([]models.User, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if roleIDs == nil {
+		roleIDs = []uuid.UUID{}
+	}
+
+	users := []models.User{}
+	query := `
+		SELECT u.* FROM users u
+		WHERE u.tenant_id = $1 AND u.status = $2 AND u.deleted_at IS NULL
+		  AND NOT u.two_factor_enabled
+		  AND NOT EXISTS (SELECT 1 FROM webauthn_credentials c WHERE c.tenant_id = u.tenant_id AND c.user_id = u.id)
+		  AND (cardinality($3::uuid[]) = 0 OR EXISTS (
+			SELECT 1 FROM user_roles ur
+			WHERE ur.user_id = u.id AND ur.role_id = ANY($3::uuid[]) AND ` + activeUserRole + `
+		  ))
+		ORDER BY u.created_at
+	`
+
+	if err := tx.SelectContext(ctx, &users, query, tenantID, models.UserStatusActive, pq.Array(roleIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list users without a second factor: %w", err)
+	}
+
+	return users, nil
+}
+
 // CountByStatus counts users by status
 func (r *UserRepository) CountByStatus(ctx context.Context, tenantID uuid.UUID, status string) (int, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
@@ -530,7 +1131,7 @@ func (r *UserRepository) CountByStatus(ctx context.Context, tenantID uuid.UUID,
 	defer tx.Rollback()
 
 	var count int
-	query := `SELECT COUNT(*) FROM users WHERE status = $1`
+	query := `SELECT COUNT(*) FROM users WHERE status = $1 AND deleted_at IS NULL`
 
 	err = tx.GetContext(ctx, &count, query, status)
 	if err != nil {
@@ -3,13 +3,20 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
+// activeUserRole restricts user_roles (as ur) to the assignments in effect
+// now, leaving out temporary ones not started yet or already ended
+const activeUserRole = `(ur.valid_from IS NULL OR ur.valid_from <= NOW()) AND (ur.valid_until IS NULL OR ur.valid_until > NOW())`
+
 // UserRoleRepository handles database operations for user-role assignments
 type UserRoleRepository struct {
 	db *sqlx.DB
@@ -20,7 +27,8 @@ func NewUserRoleRepository(db *sqlx.DB) *UserRoleRepository {
 	return &UserRoleRepository{db: db}
 }
 
-// AssignRole assigns a role to a user
+// AssignRole assigns a role to a user, making a temporary assignment of it
+// permanent
 func (r *UserRoleRepository) AssignRole(ctx context.Context, tenantID, userID, roleID, assignedBy uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -31,7 +39,10 @@ func (r *UserRoleRepository) AssignRole(ctx context.Context, tenantID, userID, r
 	query := `
 		INSERT INTO user_roles (tenant_id, user_id, role_id, assigned_by)
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (tenant_id, user_id, role_id) DO NOTHING
+		ON CONFLICT (tenant_id, user_id, role_id) DO UPDATE
+		SET valid_from = NULL, valid_until = NULL, delegated_from = NULL, reason = NULL,
+			assigned_by = EXCLUDED.assigned_by, assigned_at = NOW()
+		WHERE user_roles.valid_until IS NOT NULL
 	`
 
 	_, err = tx.ExecContext(ctx, query, tenantID, userID, roleID, assignedBy)
@@ -42,7 +53,9 @@ func (r *UserRoleRepository) AssignRole(ctx context.Context, tenantID, userID, r
 	return tx.Commit()
 }
 
-// AssignRoles assigns multiple roles to a user (replaces existing roles)
+// AssignRoles assigns multiple roles to a user (replaces existing permanent
+// roles). Temporary assignments are kept, unless one of roleIDs makes them
+// permanent.
 func (r *UserRoleRepository) AssignRoles(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID, assignedBy uuid.UUID) error {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -51,7 +64,7 @@ func (r *UserRoleRepository) AssignRoles(ctx context.Context, tenantID, userID u
 	defer tx.Rollback()
 
 	// Delete existing roles
-	deleteQuery := `DELETE FROM user_roles WHERE user_id = $1`
+	deleteQuery := `DELETE FROM user_roles WHERE user_id = $1 AND valid_until IS NULL`
 	_, err = tx.ExecContext(ctx, deleteQuery, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete existing roles: %w", err)
@@ -61,6 +74,9 @@ func (r *UserRoleRepository) AssignRoles(ctx context.Context, tenantID, userID u
 	insertQuery := `
 		INSERT INTO user_roles (tenant_id, user_id, role_id, assigned_by)
 		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id, role_id) DO UPDATE
+		SET valid_from = NULL, valid_until = NULL, delegated_from = NULL, reason = NULL,
+			assigned_by = EXCLUDED.assigned_by, assigned_at = NOW()
 	`
 
 	for _, roleID := range roleIDs {
@@ -90,7 +106,7 @@ func (r *UserRoleRepository) UnassignRole(ctx context.Context, tenantID, userID,
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("role assignment not found")
+		return utils.NewNotFoundError("ROLE_ASSIGNMENT_NOT_FOUND", "role assignment not found")
 	}
 
 	return tx.Commit()
@@ -114,7 +130,8 @@ func (r *UserRoleRepository) UnassignAllRoles(ctx context.Context, tenantID, use
 	return tx.Commit()
 }
 
-// GetUserRoles retrieves all roles assigned to a user
+// GetUserRoles retrieves the roles in effect for a user, leaving out
+// temporary assignments not started yet or already ended
 func (r *UserRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -127,7 +144,7 @@ func (r *UserRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID
 		SELECT r.*
 		FROM roles r
 		INNER JOIN user_roles ur ON r.id = ur.role_id
-		WHERE ur.user_id = $1
+		WHERE ur.user_id = $1 AND ` + activeUserRole + `
 		ORDER BY r.level ASC, r.name ASC
 	`
 
@@ -139,7 +156,45 @@ func (r *UserRoleRepository) GetUserRoles(ctx context.Context, tenantID, userID
 	return roles, nil
 }
 
-// GetUsersByRole retrieves all users assigned to a role
+// GetRolesForUsers retrieves the roles in effect for each of the users in one
+// query, keyed by user ID. Users without roles are missing from the map.
+func (r *UserRoleRepository) GetRolesForUsers(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID][]models.Role, error) {
+	rolesByUser := make(map[uuid.UUID][]models.Role, len(userIDs))
+	if len(userIDs) == 0 {
+		return rolesByUser, nil
+	}
+
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		UserID uuid.UUID `db:"user_id"`
+		models.Role
+	}
+	query := `
+		SELECT ur.user_id, r.*
+		FROM roles r
+		INNER JOIN user_roles ur ON r.id = ur.role_id
+		WHERE ur.user_id = ANY($1) AND ` + activeUserRole + `
+		ORDER BY r.level ASC, r.name ASC
+	`
+
+	err = tx.SelectContext(ctx, &rows, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles for users: %w", err)
+	}
+
+	for _, row := range rows {
+		rolesByUser[row.UserID] = append(rolesByUser[row.UserID], row.Role)
+	}
+
+	return rolesByUser, nil
+}
+
+// GetUsersByRole retrieves all users the role is in effect for
 func (r *UserRoleRepository) GetUsersByRole(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.User, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -152,7 +207,7 @@ func (r *UserRoleRepository) GetUsersByRole(ctx context.Context, tenantID, roleI
 		SELECT u.*
 		FROM users u
 		INNER JOIN user_roles ur ON u.id = ur.user_id
-		WHERE ur.role_id = $1
+		WHERE ur.role_id = $1 AND u.deleted_at IS NULL AND ` + activeUserRole + `
 		ORDER BY u.first_name, u.last_name
 	`
 
@@ -164,7 +219,59 @@ func (r *UserRoleRepository) GetUsersByRole(ctx context.Context, tenantID, roleI
 	return users, nil
 }
 
-// HasRole checks if a user has a specific role
+// GetUsersWithPermission retrieves the active users granted a permission
+// through any of their roles or the roles those inherit from, directly or by
+// wildcard, and denied it by none
+func (r *UserRoleRepository) GetUsersWithPermission(ctx context.Context, tenantID uuid.UUID, resource, action string) ([]models.User, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var users []models.User
+	// UNION drops rows already found, which ends the recursion on cycles
+	query := `
+		WITH RECURSIVE user_role_chain AS (
+			SELECT user_id, role_id FROM user_roles ur WHERE ` + activeUserRole + `
+			UNION
+			SELECT c.user_id, r.parent_role_id
+			FROM user_role_chain c
+			INNER JOIN roles r ON r.id = c.role_id
+			WHERE r.parent_role_id IS NOT NULL
+		)
+		SELECT u.*
+		FROM users u
+		WHERE u.status = $1 AND u.deleted_at IS NULL
+			AND EXISTS (
+				SELECT 1
+				FROM user_role_chain ur
+				INNER JOIN role_permissions rp ON rp.role_id = ur.role_id
+				INNER JOIN permissions p ON p.id = rp.permission_id
+				WHERE ur.user_id = u.id AND rp.effect = $6
+					AND p.resource IN ($2, $5) AND p.action IN ($3, $4)
+			)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM user_role_chain ur
+				INNER JOIN role_permissions rp ON rp.role_id = ur.role_id
+				INNER JOIN permissions p ON p.id = rp.permission_id
+				WHERE ur.user_id = u.id AND rp.effect = $7
+					AND p.resource IN ($2, $5) AND p.action IN ($3, $4)
+			)
+		ORDER BY u.first_name, u.last_name
+	`
+
+	err = tx.SelectContext(ctx, &users, query, models.UserStatusActive, resource, action, models.ActionAll,
+		models.ResourceAll, models.EffectAllow, models.EffectDeny)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with permission: %w", err)
+	}
+
+	return users, nil
+}
+
+// HasRole checks if a specific role is in effect for a user
 func (r *UserRoleRepository) HasRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) (bool, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -175,8 +282,8 @@ func (r *UserRoleRepository) HasRole(ctx context.Context, tenantID, userID, role
 	var count int
 	query := `
 		SELECT COUNT(*)
-		FROM user_roles
-		WHERE user_id = $1 AND role_id = $2
+		FROM user_roles ur
+		WHERE ur.user_id = $1 AND ur.role_id = $2 AND ` + activeUserRole + `
 	`
 
 	err = tx.GetContext(ctx, &count, query, userID, roleID)
@@ -187,7 +294,7 @@ func (r *UserRoleRepository) HasRole(ctx context.Context, tenantID, userID, role
 	return count > 0, nil
 }
 
-// HasAnyRole checks if a user has any of the specified roles
+// HasAnyRole checks if any of the specified roles is in effect for a user
 func (r *UserRoleRepository) HasAnyRole(ctx context.Context, tenantID, userID uuid.UUID, roleIDs []uuid.UUID) (bool, error) {
 	if len(roleIDs) == 0 {
 		return false, nil
@@ -202,8 +309,8 @@ func (r *UserRoleRepository) HasAnyRole(ctx context.Context, tenantID, userID uu
 	var count int
 	query := `
 		SELECT COUNT(*)
-		FROM user_roles
-		WHERE user_id = $1 AND role_id = ANY($2)
+		FROM user_roles ur
+		WHERE ur.user_id = $1 AND ur.role_id = ANY($2) AND ` + activeUserRole + `
 	`
 
 	err = tx.GetContext(ctx, &count, query, userID, roleIDs)
@@ -214,7 +321,8 @@ func (r *UserRoleRepository) HasAnyRole(ctx context.Context, tenantID, userID uu
 	return count > 0, nil
 }
 
-// CountUsersByRole counts the number of users assigned to a role
+// CountUsersByRole counts the number of users assigned to a role, including
+// temporary assignments
 func (r *UserRoleRepository) CountUsersByRole(ctx context.Context, tenantID, roleID uuid.UUID) (int, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -237,7 +345,9 @@ func (r *UserRoleRepository) CountUsersByRole(ctx context.Context, tenantID, rol
 	return count, nil
 }
 
-// GetRoleAssignmentDetails retrieves detailed role assignment information
+// GetRoleAssignmentDetails retrieves detailed role assignment information.
+// Temporary assignments carry their period, whom they cover for and their
+// status (active, scheduled or expired).
 func (r *UserRoleRepository) GetRoleAssignmentDetails(ctx context.Context, tenantID, userID uuid.UUID) ([]map[string]interface{}, error) {
 	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
 	if err != nil {
@@ -246,13 +356,19 @@ func (r *UserRoleRepository) GetRoleAssignmentDetails(ctx context.Context, tenan
 	defer tx.Rollback()
 
 	type AssignmentDetail struct {
-		RoleID       uuid.UUID `db:"role_id"`
-		RoleName     string    `db:"role_name"`
-		DisplayName  string    `db:"display_name"`
-		IsSystem     bool      `db:"is_system"`
-		AssignedAt   string    `db:"assigned_at"`
-		AssignedBy   uuid.UUID `db:"assigned_by"`
-		AssignerName string    `db:"assigner_name"`
+		RoleID            uuid.UUID  `db:"role_id"`
+		RoleName          string     `db:"role_name"`
+		DisplayName       string     `db:"display_name"`
+		IsSystem          bool       `db:"is_system"`
+		AssignedAt        string     `db:"assigned_at"`
+		AssignedBy        uuid.UUID  `db:"assigned_by"`
+		AssignerName      string     `db:"assigner_name"`
+		ValidFrom         *time.Time `db:"valid_from"`
+		ValidUntil        *time.Time `db:"valid_until"`
+		DelegatedFrom     *uuid.UUID `db:"delegated_from"`
+		DelegatedFromName *string    `db:"delegated_from_name"`
+		Reason            *string    `db:"reason"`
+		Status            string     `db:"status"`
 	}
 
 	var details []AssignmentDetail
@@ -264,15 +380,27 @@ func (r *UserRoleRepository) GetRoleAssignmentDetails(ctx context.Context, tenan
 			r.is_system,
 			ur.assigned_at,
 			ur.assigned_by,
-			COALESCE(u.first_name || ' ' || u.last_name, 'System') as assigner_name
+			COALESCE(u.first_name || ' ' || u.last_name, 'System') as assigner_name,
+			ur.valid_from,
+			ur.valid_until,
+			ur.delegated_from,
+			d.first_name || ' ' || d.last_name as delegated_from_name,
+			ur.reason,
+			CASE
+				WHEN ur.valid_until IS NOT NULL AND ur.valid_until <= NOW() THEN $2
+				WHEN ur.valid_from IS NOT NULL AND ur.valid_from > NOW() THEN $3
+				ELSE $4
+			END as status
 		FROM user_roles ur
 		INNER JOIN roles r ON ur.role_id = r.id
 		LEFT JOIN users u ON ur.assigned_by = u.id
+		LEFT JOIN users d ON ur.delegated_from = d.id
 		WHERE ur.user_id = $1
-		ORDER BY r.level ASC
+		ORDER BY r.level ASC, ur.valid_from NULLS FIRST
 	`
 
-	err = tx.SelectContext(ctx, &details, query, userID)
+	err = tx.SelectContext(ctx, &details, query, userID,
+		models.RoleAssignmentExpired, models.RoleAssignmentScheduled, models.RoleAssignmentActive)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assignment details: %w", err)
 	}
@@ -288,12 +416,131 @@ func (r *UserRoleRepository) GetRoleAssignmentDetails(ctx context.Context, tenan
 			"assigned_at":  detail.AssignedAt,
 			"assigned_by":  detail.AssignedBy,
 			"assigner_name": detail.AssignerName,
+			"temporary":    detail.ValidUntil != nil,
+			"status":       detail.Status,
+		}
+		if detail.ValidUntil != nil {
+			result[i]["valid_from"] = detail.ValidFrom
+			result[i]["valid_until"] = detail.ValidUntil
+			result[i]["delegated_from"] = detail.DelegatedFrom
+			result[i]["delegated_from_name"] = detail.DelegatedFromName
+			result[i]["reason"] = detail.Reason
 		}
 	}
 
 	return result, nil
 }
 
+// GrantTemporaryRole assigns a role to a user for req's period, replacing
+// an earlier temporary assignment of it. req.ValidFrom must be set. Fails
+// with a conflict when the role is assigned permanently.
+func (r *UserRoleRepository) GrantTemporaryRole(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO user_roles (tenant_id, user_id, role_id, assigned_by, valid_from, valid_until, delegated_from, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+		ON CONFLICT (tenant_id, user_id, role_id) DO UPDATE
+		SET assigned_by = EXCLUDED.assigned_by, assigned_at = NOW(),
+			valid_from = EXCLUDED.valid_from, valid_until = EXCLUDED.valid_until,
+			delegated_from = EXCLUDED.delegated_from, reason = EXCLUDED.reason
+		WHERE user_roles.valid_until IS NOT NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, tenantID, userID, req.RoleID, assignedBy,
+		req.ValidFrom, req.ValidUntil, req.DelegatedFrom, req.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to grant temporary role: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewConflictError("ROLE_ALREADY_ASSIGNED", "user already has this role permanently")
+	}
+
+	return tx.Commit()
+}
+
+// RevokeTemporaryRole ends a temporary assignment of a role before its
+// period is over
+func (r *UserRoleRepository) RevokeTemporaryRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2 AND valid_until IS NOT NULL`
+
+	result, err := tx.ExecContext(ctx, query, userID, roleID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke temporary role: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("TEMPORARY_ROLE_ASSIGNMENT_NOT_FOUND", "temporary role assignment not found")
+	}
+
+	return tx.Commit()
+}
+
+// NextAssignmentChange returns when a temporary assignment of the user next
+// starts or ends, or nil when none will
+func (r *UserRoleRepository) NextAssignmentChange(ctx context.Context, tenantID, userID uuid.UUID) (*time.Time, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var next *time.Time
+	query := `
+		SELECT MIN(change_at) FROM (
+			SELECT valid_from AS change_at FROM user_roles WHERE user_id = $1 AND valid_from > NOW()
+			UNION ALL
+			SELECT valid_until FROM user_roles WHERE user_id = $1 AND valid_until > NOW()
+		) changes
+	`
+
+	if err := tx.GetContext(ctx, &next, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to get next role assignment change: %w", err)
+	}
+
+	return next, nil
+}
+
+// DeleteExpired removes the temporary assignments that ended, across all
+// tenants and data regions (bypasses RLS)
+func (r *UserRoleRepository) DeleteExpired(ctx context.Context) ([]models.ExpiredRoleAssignment, error) {
+	var expired []models.ExpiredRoleAssignment
+	for _, db := range database.RegionDBs(r.db) {
+		tx, err := database.WithBypassRLS(ctx, db)
+		if err != nil {
+			return expired, err
+		}
+
+		var regionExpired []models.ExpiredRoleAssignment
+		query := `DELETE FROM user_roles WHERE valid_until <= NOW() RETURNING tenant_id, user_id, role_id`
+		if err := tx.SelectContext(ctx, &regionExpired, query); err != nil {
+			tx.Rollback()
+			return expired, fmt.Errorf("failed to delete expired role assignments: %w", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return expired, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+
+		expired = append(expired, regionExpired...)
+	}
+
+	return expired, nil
+}
+
 // BulkAssignRole assigns a role to multiple users
 func (r *UserRoleRepository) BulkAssignRole(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, roleID, assignedBy uuid.UUID) error {
 	if len(userIDs) == 0 {
@@ -309,7 +556,10 @@ func (r *UserRoleRepository) BulkAssignRole(ctx context.Context, tenantID uuid.U
 	query := `
 		INSERT INTO user_roles (tenant_id, user_id, role_id, assigned_by)
 		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (tenant_id, user_id, role_id) DO NOTHING
+		ON CONFLICT (tenant_id, user_id, role_id) DO UPDATE
+		SET valid_from = NULL, valid_until = NULL, delegated_from = NULL, reason = NULL,
+			assigned_by = EXCLUDED.assigned_by, assigned_at = NOW()
+		WHERE user_roles.valid_until IS NOT NULL
 	`
 
 	for _, userID := range userIDs {
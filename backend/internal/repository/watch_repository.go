@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// WatchRepository handles database operations for record watches
+type WatchRepository struct {
+	db *sqlx.DB
+}
+
+// NewWatchRepository creates a new watch repository
+func NewWatchRepository(db *sqlx.DB) *WatchRepository {
+	return &WatchRepository{db: db}
+}
+
+// Watch saves a watch. Returns false, with the existing watch's reason and
+// creation time, if the user already watched the record.
+func (r *WatchRepository) Watch(ctx context.Context, watch *models.RecordWatch) (bool, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, watch.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO record_watches (tenant_id, user_id, entity_type, entity_id, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, user_id, entity_type, entity_id) DO NOTHING
+		RETURNING created_at
+	`
+	created := true
+	err = tx.QueryRowContext(ctx, query,
+		watch.TenantID,
+		watch.UserID,
+		watch.EntityType,
+		watch.EntityID,
+		watch.Reason,
+	).Scan(&watch.CreatedAt)
+	if err == sql.ErrNoRows {
+		created = false
+		err = tx.QueryRowContext(ctx, `
+			SELECT reason, created_at FROM record_watches
+			WHERE tenant_id = $1 AND user_id = $2 AND entity_type = $3 AND entity_id = $4
+		`, watch.TenantID, watch.UserID, watch.EntityType, watch.EntityID).Scan(&watch.Reason, &watch.CreatedAt)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to watch record: %w", err)
+	}
+
+	return created, tx.Commit()
+}
+
+// Unwatch stops a user watching a record
+func (r *WatchRepository) Unwatch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM record_watches
+		WHERE tenant_id = $1 AND user_id = $2 AND entity_type = $3 AND entity_id = $4
+	`, tenantID, userID, entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to unwatch record: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WATCH_NOT_FOUND", "not watching this record")
+	}
+
+	return tx.Commit()
+}
+
+// DeleteForEntity deletes every watch of a record, once it is deleted
+func (r *WatchRepository) DeleteForEntity(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM record_watches
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+	`, tenantID, entityType, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete record watches: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListByUser retrieves the records a user watches, most recent first,
+// optionally of one entity type
+func (r *WatchRepository) ListByUser(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND user_id = $2 AND ($3 = '' OR entity_type = $3)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM record_watches `+where, tenantID, userID, entityType); err != nil {
+		return nil, 0, fmt.Errorf("failed to count watches: %w", err)
+	}
+
+	watches := []models.RecordWatch{}
+	query := `
+		SELECT * FROM record_watches
+		` + where + `
+		ORDER BY created_at DESC, entity_id
+		LIMIT $4 OFFSET $5
+	`
+	if err := tx.SelectContext(ctx, &watches, query, tenantID, userID, entityType, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list watches: %w", err)
+	}
+
+	return watches, totalCount, nil
+}
+
+// ListWatchers retrieves the active users watching a record, in the order
+// they started watching
+func (r *WatchRepository) ListWatchers(ctx context.Context, tenantID uuid.UUID, entityType string, entityID uuid.UUID) ([]models.RecordWatcher, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	watchers := []models.RecordWatcher{}
+	query := `
+		SELECT rw.user_id, u.email, u.first_name, u.last_name, rw.reason, rw.created_at
+		FROM record_watches rw
+		JOIN users u ON u.tenant_id = rw.tenant_id AND u.id = rw.user_id
+		WHERE rw.tenant_id = $1 AND rw.entity_type = $2 AND rw.entity_id = $3
+		  AND u.deleted_at IS NULL AND u.status = $4
+		ORDER BY rw.created_at, rw.user_id
+	`
+	if err := tx.SelectContext(ctx, &watchers, query, tenantID, entityType, entityID, models.UserStatusActive); err != nil {
+		return nil, fmt.Errorf("failed to list record watchers: %w", err)
+	}
+
+	return watchers, nil
+}
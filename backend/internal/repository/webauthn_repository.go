@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// WebAuthnRepository handles database operations for users' passkeys and
+// security keys
+type WebAuthnRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebAuthnRepository creates a new WebAuthn repository
+func NewWebAuthnRepository(db *sqlx.DB) *WebAuthnRepository {
+	return &WebAuthnRepository{db: db}
+}
+
+// Create registers a credential
+func (r *WebAuthnRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	tx, err := database.WithTenantContext(ctx, r.db, credential.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webauthn_credentials (
+			tenant_id, user_id, credential_id, public_key, sign_count, aaguid, transports, backup_eligible, name
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id, credential_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		credential.TenantID,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.SignCount,
+		credential.AAGUID,
+		credential.Transports,
+		credential.BackupEligible,
+		credential.Name,
+	).Scan(&credential.ID, &credential.CreatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("credential already registered")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to register credential: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListByUser retrieves a user's credentials, oldest first
+func (r *WebAuthnRepository) ListByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	credentials := []models.WebAuthnCredential{}
+	query := `SELECT * FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2 ORDER BY created_at`
+	if err := tx.SelectContext(ctx, &credentials, query, tenantID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// CountByUser counts a user's credentials
+func (r *WebAuthnRepository) CountByUser(ctx context.Context, tenantID, userID uuid.UUID) (int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	query := `SELECT COUNT(*) FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2`
+	if err := tx.GetContext(ctx, &count, query, tenantID, userID); err != nil {
+		return 0, fmt.Errorf("failed to count credentials: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindByCredentialID retrieves a credential by the ID its authenticator gave it
+func (r *WebAuthnRepository) FindByCredentialID(ctx context.Context, tenantID uuid.UUID, credentialID []byte) (*models.WebAuthnCredential, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var credential models.WebAuthnCredential
+	query := `SELECT * FROM webauthn_credentials WHERE tenant_id = $1 AND credential_id = $2`
+	err = tx.GetContext(ctx, &credential, query, tenantID, credentialID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CREDENTIAL_NOT_FOUND", "credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find credential: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// RecordUse stores the signature counter of a sign-in. The update only
+// applies if the counter was not changed by a concurrent sign-in.
+func (r *WebAuthnRepository) RecordUse(ctx context.Context, tenantID, id uuid.UUID, previousCount, signCount int64) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE webauthn_credentials
+		SET sign_count = $1, last_used_at = NOW()
+		WHERE tenant_id = $2 AND id = $3 AND sign_count = $4
+	`
+
+	result, err := tx.ExecContext(ctx, query, signCount, tenantID, id, previousCount)
+	if err != nil {
+		return fmt.Errorf("failed to record credential use: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("credential signature counter did not increase")
+	}
+
+	return tx.Commit()
+}
+
+// Rename renames one of a user's credentials
+func (r *WebAuthnRepository) Rename(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var credential models.WebAuthnCredential
+	query := `
+		UPDATE webauthn_credentials SET name = $1
+		WHERE tenant_id = $2 AND user_id = $3 AND id = $4
+		RETURNING *
+	`
+	err = tx.GetContext(ctx, &credential, query, name, tenantID, userID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CREDENTIAL_NOT_FOUND", "credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename credential: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to rename credential: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// Delete removes one of a user's credentials. The last credential of a
+// passkey-only user cannot be removed, or they could not sign in.
+func (r *WebAuthnRepository) Delete(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the user so a concurrent removal cannot also see another credential
+	var passkeyOnly bool
+	query := `SELECT passkey_only FROM users WHERE tenant_id = $1 AND id = $2 FOR UPDATE`
+	if err := tx.GetContext(ctx, &passkeyOnly, query, tenantID, userID); err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	var credential models.WebAuthnCredential
+	query = `DELETE FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2 AND id = $3 RETURNING *`
+	err = tx.GetContext(ctx, &credential, query, tenantID, userID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("CREDENTIAL_NOT_FOUND", "credential not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove credential: %w", err)
+	}
+
+	if passkeyOnly {
+		var remaining int
+		query = `SELECT COUNT(*) FROM webauthn_credentials WHERE tenant_id = $1 AND user_id = $2`
+		if err := tx.GetContext(ctx, &remaining, query, tenantID, userID); err != nil {
+			return nil, fmt.Errorf("failed to count credentials: %w", err)
+		}
+		if remaining == 0 {
+			return nil, fmt.Errorf("cannot remove the last passkey of a passkey-only account")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to remove credential: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// SetPasskeyOnly turns passkey-only sign-in on or off for a user. It can
+// only be turned on for users with a credential.
+func (r *WebAuthnRepository) SetPasskeyOnly(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users SET passkey_only = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+		  AND (NOT $1 OR EXISTS (
+			SELECT 1 FROM webauthn_credentials c WHERE c.tenant_id = users.tenant_id AND c.user_id = users.id
+		  ))
+	`
+
+	result, err := tx.ExecContext(ctx, query, enabled, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update passkey-only sign-in: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("register a passkey before turning on passkey-only sign-in")
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,509 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/tracing"
+	"myerp-v2/internal/utils"
+)
+
+// WebhookRepository handles database operations for webhooks and their
+// deliveries
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create registers a webhook
+func (r *WebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	tx, err := database.WithTenantContext(ctx, r.db, webhook.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhooks (tenant_id, url, description, event_types, secret, is_active, redaction_rules, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		webhook.TenantID,
+		webhook.URL,
+		webhook.Description,
+		webhook.EventTypes,
+		webhook.Secret,
+		webhook.IsActive,
+		webhook.RedactionRules,
+		webhook.CreatedBy,
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByID retrieves a webhook
+func (r *WebhookRepository) FindByID(ctx context.Context, tenantID, id uuid.UUID) (*models.Webhook, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var webhook models.Webhook
+	err = tx.GetContext(ctx, &webhook, `SELECT * FROM webhooks WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("WEBHOOK_NOT_FOUND", "webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// List retrieves a tenant's webhooks, newest first
+func (r *WebhookRepository) List(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM webhooks WHERE tenant_id = $1`, tenantID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+
+	webhooks := []models.Webhook{}
+	query := `SELECT * FROM webhooks WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	if err := tx.SelectContext(ctx, &webhooks, query, tenantID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return webhooks, totalCount, nil
+}
+
+// Update saves a webhook's endpoint, subscriptions and state
+func (r *WebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	tx, err := database.WithTenantContext(ctx, r.db, webhook.TenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE webhooks
+		SET url = $1, description = $2, event_types = $3, is_active = $4, redaction_rules = $5, updated_at = NOW()
+		WHERE tenant_id = $6 AND id = $7
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		webhook.URL,
+		webhook.Description,
+		webhook.EventTypes,
+		webhook.IsActive,
+		webhook.RedactionRules,
+		webhook.TenantID,
+		webhook.ID,
+	).Scan(&webhook.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return utils.NewNotFoundError("WEBHOOK_NOT_FOUND", "webhook not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateSecret replaces a webhook's (encrypted) signing secret
+func (r *WebhookRepository) UpdateSecret(ctx context.Context, tenantID, id uuid.UUID, secret string) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE webhooks SET secret = $1, updated_at = NOW() WHERE tenant_id = $2 AND id = $3`, secret, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WEBHOOK_NOT_FOUND", "webhook not found")
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a webhook and its delivery log
+func (r *WebhookRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WEBHOOK_NOT_FOUND", "webhook not found")
+	}
+
+	return tx.Commit()
+}
+
+// EnqueueEvent queues a delivery of an event to every active webhook of the
+// tenant subscribed to its type, redacted by the webhook's rules. A webhook
+// that already has maxPending deliveries waiting (0 for no limit) does not
+// get the event. Returns the number of deliveries queued and of webhooks
+// skipped because they were full.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, tenantID uuid.UUID, event *models.WebhookEvent, maxAttempts, maxPending int) (int, int, error) {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT w.id, w.redaction_rules,
+		       $3::int = 0 OR (
+		           SELECT COUNT(*) FROM (
+		               SELECT 1 FROM webhook_deliveries d
+		               WHERE d.webhook_id = w.id AND d.status = 'pending'
+		               LIMIT $3
+		           ) pending
+		       ) < $3 AS has_room
+		FROM webhooks w
+		WHERE w.tenant_id = $1
+		  AND w.is_active = true
+		  AND ($2::text = ANY(w.event_types) OR '*' = ANY(w.event_types))
+	`
+
+	var targets []struct {
+		ID             uuid.UUID      `db:"id"`
+		RedactionRules pq.StringArray `db:"redaction_rules"`
+		HasRoom        bool           `db:"has_room"`
+	}
+	if err := tx.SelectContext(ctx, &targets, query, tenantID, event.Type, maxPending); err != nil {
+		return 0, 0, fmt.Errorf("failed to find subscribed webhooks: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO webhook_deliveries (tenant_id, webhook_id, event_id, event_type, payload, max_attempts, trace_context)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	traceContext := tracing.Inject(ctx)
+	var queued, rejected int
+	for _, target := range targets {
+		if !target.HasRoom {
+			rejected++
+			continue
+		}
+
+		payload, err := webhookPayload(event, target.RedactionRules)
+		if err != nil {
+			return 0, 0, err
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery, tenantID, target.ID, event.ID, event.Type, payload, maxAttempts, traceContext); err != nil {
+			return 0, 0, fmt.Errorf("failed to queue webhook deliveries: %w", err)
+		}
+		queued++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+
+	return queued, rejected, nil
+}
+
+// EnqueueDelivery queues a delivery of an event to one webhook, regardless of
+// its subscriptions (used for test events). Fails with "webhook queue is full"
+// once the webhook has maxPending deliveries waiting (0 for no limit). The
+// event is redacted by the webhook's rules.
+func (r *WebhookRepository) EnqueueDelivery(ctx context.Context, webhook *models.Webhook, event *models.WebhookEvent, maxAttempts, maxPending int) (*models.WebhookDelivery, error) {
+	payload, err := webhookPayload(event, webhook.RedactionRules)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, r.db, webhook.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var delivery models.WebhookDelivery
+	query := `
+		INSERT INTO webhook_deliveries (tenant_id, webhook_id, event_id, event_type, payload, max_attempts, trace_context)
+		SELECT $1::uuid, $2::uuid, $3::uuid, $4::text, $5::jsonb, $6::int, $8::jsonb
+		WHERE $7::int = 0 OR (
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM webhook_deliveries
+				WHERE webhook_id = $2 AND status = 'pending'
+				LIMIT $7
+			) pending
+		) < $7
+		RETURNING *
+	`
+	err = tx.GetContext(ctx, &delivery, query, webhook.TenantID, webhook.ID, event.ID, event.Type, payload, maxAttempts, maxPending, tracing.Inject(ctx))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook queue is full")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// ListDeliveries retrieves a webhook's delivery log, newest first. An empty
+// status returns all deliveries.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	where := `WHERE tenant_id = $1 AND webhook_id = $2 AND ($3 = '' OR status = $3)`
+
+	var totalCount int
+	if err := tx.GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM webhook_deliveries `+where, tenantID, webhookID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	query := `SELECT * FROM webhook_deliveries ` + where + ` ORDER BY created_at DESC LIMIT $4 OFFSET $5`
+	if err := tx.SelectContext(ctx, &deliveries, query, tenantID, webhookID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, totalCount, nil
+}
+
+// FindDelivery retrieves one delivery of a webhook
+func (r *WebhookRepository) FindDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, r.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var delivery models.WebhookDelivery
+	query := `SELECT * FROM webhook_deliveries WHERE tenant_id = $1 AND webhook_id = $2 AND id = $3`
+	err = tx.GetContext(ctx, &delivery, query, tenantID, webhookID, deliveryID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// RetryDelivery puts a failed delivery back in the queue with a fresh set of
+// attempts
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error {
+	tx, err := database.WithTenantContext(ctx, r.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE tenant_id = $1 AND webhook_id = $2 AND id = $3 AND status = 'failed'
+	`
+
+	result, err := tx.ExecContext(ctx, query, tenantID, webhookID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WEBHOOK_DELIVERY_NOT_FOUND", "webhook delivery not found or not failed")
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue locks the next due delivery together with its webhook's endpoint,
+// skipping rows another worker already holds. Returns nil when nothing is due.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, tx *sqlx.Tx) (*models.ClaimedWebhookDelivery, error) {
+	var delivery models.ClaimedWebhookDelivery
+	query := `
+		SELECT d.*, w.url, w.secret, w.is_active AS webhook_active
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.tenant_id = d.tenant_id AND w.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+		ORDER BY d.next_attempt_at
+		LIMIT 1
+		FOR UPDATE OF d SKIP LOCKED
+	`
+
+	err := tx.GetContext(ctx, &delivery, query)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// MarkDelivered records a successful delivery
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, responseStatus int, responseBody string, duration time.Duration) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered',
+			attempts = attempts + 1,
+			last_error = NULL,
+			response_status = $1,
+			response_body = $2,
+			duration_ms = $3,
+			delivered_at = NOW(),
+			updated_at = NOW()
+		WHERE tenant_id = $4 AND id = $5
+	`
+	_, err := tx.ExecContext(ctx, query, responseStatus, responseBody, duration.Milliseconds(), delivery.TenantID, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery attempt. The delivery is
+// retried at nextAttemptAt, or marked failed once its attempts are exhausted.
+// responseStatus is 0 when no response was received.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string, responseStatus int, responseBody string, duration time.Duration, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= max_attempts THEN 'failed' ELSE 'pending' END,
+			next_attempt_at = $1,
+			last_error = $2,
+			response_status = NULLIF($3, 0),
+			response_body = NULLIF($4, ''),
+			duration_ms = $5,
+			updated_at = NOW()
+		WHERE tenant_id = $6 AND id = $7
+	`
+	_, err := tx.ExecContext(ctx, query, nextAttemptAt, cause, responseStatus, responseBody, duration.Milliseconds(), delivery.TenantID, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery failure: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed gives up on a delivery right away (e.g. its webhook was
+// deactivated)
+func (r *WebhookRepository) MarkFailed(ctx context.Context, tx *sqlx.Tx, delivery *models.ClaimedWebhookDelivery, cause string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'failed', last_error = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND id = $3
+	`
+	if _, err := tx.ExecContext(ctx, query, cause, delivery.TenantID, delivery.ID); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as failed: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinishedBefore removes delivered and failed deliveries in db last
+// updated before cutoff
+func (r *WebhookRepository) DeleteFinishedBefore(ctx context.Context, db *sqlx.DB, cutoff time.Time) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM webhook_deliveries
+		WHERE status IN ('delivered', 'failed') AND updated_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// QueueStats measures the deliveries of every tenant in db
+func (r *WebhookRepository) QueueStats(ctx context.Context, db *sqlx.DB) (*models.QueueStats, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stats models.QueueStats
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'pending' AND next_attempt_at <= NOW()) AS due,
+			COUNT(*) FILTER (WHERE status = 'failed') AS dead_letters,
+			MIN(next_attempt_at) FILTER (WHERE status = 'pending' AND next_attempt_at <= NOW()) AS oldest_due_at
+		FROM webhook_deliveries
+		WHERE status IN ('pending', 'failed')
+	`
+
+	if err := tx.GetContext(ctx, &stats, query); err != nil {
+		return nil, fmt.Errorf("failed to measure webhook queue: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// webhookPayload encodes an event for a webhook, redacted by its rules
+func webhookPayload(event *models.WebhookEvent, redactionRules []string) ([]byte, error) {
+	redacted, err := event.Redacted(redactionRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact webhook event: %w", err)
+	}
+
+	payload, err := json.Marshal(redacted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+	return payload, nil
+}
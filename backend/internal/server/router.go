@@ -1,20 +1,29 @@
 package server
 
 import (
-	"net/http"
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 
+	"myerp-v2/internal/billing"
 	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/diagnostics"
 	"myerp-v2/internal/handlers"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/mail"
 	appMiddleware "myerp-v2/internal/middleware"
+	"myerp-v2/internal/models"
 	"myerp-v2/internal/repository"
 	"myerp-v2/internal/services"
+	"myerp-v2/internal/storage"
 )
 
 // Router creates and configures the HTTP router
@@ -23,15 +32,24 @@ type Router struct {
 	db     *sqlx.DB
 	redis  *redis.Client
 	config *config.Config
+	jobs   *jobs.Runner
+	files  storage.Backend
+	mailer mail.Mailer
+
+	performance services.PerformanceManager
+	usage       services.UsageManager
 }
 
 // NewRouter creates a new router instance
-func NewRouter(db *sqlx.DB, redis *redis.Client, cfg *config.Config) *Router {
+func NewRouter(db *sqlx.DB, redis *redis.Client, files storage.Backend, mailer mail.Mailer, cfg *config.Config) *Router {
 	return &Router{
 		router: chi.NewRouter(),
 		db:     db,
 		redis:  redis,
 		config: cfg,
+		jobs:   jobs.NewRunner(db),
+		files:  files,
+		mailer: mailer,
 	}
 }
 
@@ -41,99 +59,591 @@ func (s *Router) Setup() *chi.Mux {
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.RealIP)
 	s.router.Use(middleware.Logger)
+	// Outside cost tracking and recovery, so every span of a request joins its trace
+	s.router.Use(appMiddleware.Tracing)
+	s.router.Use(appMiddleware.NewCostMiddleware(&s.config.Metrics).Track) // Outside Recoverer, so panics count as 500s
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(middleware.Timeout(60 * time.Second))
+	s.router.Use(appMiddleware.ReadYourWrites)
 
-	// CORS middleware
-	allowedOrigins := []string{s.config.App.FrontendURL, s.config.App.BaseURL}
-	s.router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	// Security headers (HSTS, CSP, X-Frame-Options, Referrer-Policy)
+	s.router.Use(appMiddleware.NewSecurityHeadersMiddleware(&s.config.Headers).Apply)
+
+	// Units of work spanning repositories
+	txManager := database.NewTxManager(s.db)
 
 	// Initialize repositories
 	tenantRepo := repository.NewTenantRepository(s.db)
+	tenantDomainRepo := repository.NewTenantDomainRepository(s.db)
 	userRepo := repository.NewUserRepository(s.db)
 	sessionRepo := repository.NewSessionRepository(s.db)
 	roleRepo := repository.NewRoleRepository(s.db)
 	permissionRepo := repository.NewPermissionRepository(s.db)
 	userRoleRepo := repository.NewUserRoleRepository(s.db)
+	objectACLRepo := repository.NewObjectACLRepository(s.db)
 	companySettingsRepo := repository.NewCompanySettingsRepository(s.db)
 	departmentRepo := repository.NewDepartmentRepository(s.db)
+	employeeRepo := repository.NewEmployeeRepository(s.db)
+	leaveRepo := repository.NewLeaveRepository(s.db)
+	crmRepo := repository.NewCRMRepository(s.db)
+	timesheetRepo := repository.NewTimesheetRepository(s.db)
+	fileRepo := repository.NewFileRepository(s.db)
+	searchRepo := repository.NewSearchRepository(s.db)
+	salesRepo := repository.NewSalesRepository(s.db)
+	supplierRepo := repository.NewSupplierRepository(s.db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(s.db)
+	accountRepo := repository.NewAccountRepository(s.db)
+	accountingPeriodRepo := repository.NewAccountingPeriodRepository(s.db)
+	journalEntryRepo := repository.NewJournalEntryRepository(s.db)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(s.db)
+	sandboxRepo := repository.NewSandboxRepository(s.db)
+	deletionRepo := repository.NewDeletionRepository(s.db)
+	broadcastRepo := repository.NewBroadcastRepository(s.db)
+	announcementRepo := repository.NewAnnouncementRepository(s.db)
+	customFieldRepo := repository.NewCustomFieldRepository(s.db)
+	scheduledReportRepo := repository.NewScheduledReportRepository(s.db)
+	dashboardRepo := repository.NewDashboardRepository(s.db)
+	siemForwarderRepo := repository.NewSIEMForwarderRepository(s.db)
+	asyncJobRepo := repository.NewAsyncJobRepository(s.db)
+	webhookRepo := repository.NewWebhookRepository(s.db)
+	automationRepo := repository.NewAutomationRepository(s.db)
+	approvalLinkRepo := repository.NewApprovalLinkRepository(s.db)
+	escalationRepo := repository.NewEscalationRepository(s.db)
+	watchRepo := repository.NewWatchRepository(s.db)
+	navigationRepo := repository.NewNavigationRepository(s.db)
+	personalDataRepo := repository.NewPersonalDataRepository(s.db)
+	ssoRepo := repository.NewSSORepository(s.db)
+	webauthnRepo := repository.NewWebAuthnRepository(s.db)
+	dataQualityRepo := repository.NewDataQualityRepository(s.db)
+	quotaRepo := repository.NewQuotaRepository(s.db)
+	notificationRepo := repository.NewNotificationRepository(s.db)
+	requestStatRepo := repository.NewRequestStatRepository(s.db)
+	usageStatRepo := repository.NewUsageStatRepository(s.db)
+	tenantUsageRepo := repository.NewTenantUsageRepository(s.db)
+	complianceRepo := repository.NewComplianceRepository(s.db)
+	platformRepo := repository.NewPlatformRepository(s.db)
 
 	// Initialize services
 	jwtService := services.NewJWTService(&s.config.JWT)
-	emailService := services.NewEmailService(&s.config.Email, &s.config.App)
-	authService := services.NewAuthService(tenantRepo, userRepo, sessionRepo, roleRepo, userRoleRepo, jwtService, emailService, s.config)
-	permissionService := services.NewPermissionService(permissionRepo, userRoleRepo, roleRepo, s.redis)
-	twoFactorService := services.NewTwoFactorService(s.db, s.redis, s.config)
-	sessionService := services.NewSessionService(s.db)
-	invitationService := services.NewInvitationService(s.db, userRepo, userRoleRepo, emailService)
+	emailService := services.NewEmailService(s.mailer, &s.config.Email, &s.config.App)
 	auditService := services.NewAuditService(s.db)
+	meteringService := services.NewMeteringService(tenantUsageRepo, s.redis, s.config)
+	emailQueueService := services.NewEmailQueueService(s.db, emailOutboxRepo, userRepo, emailService, auditService, meteringService, &s.config.Jobs, &s.config.Queues)
+	notificationService := services.NewNotificationService(s.db, notificationRepo, userRepo, tenantRepo, emailService, emailQueueService, s.redis, &s.config.Notifications)
+	quotaService := services.NewQuotaService(s.db, quotaRepo, tenantRepo, roleRepo, userRoleRepo, emailService, emailQueueService, notificationService, meteringService, s.config)
+	billingService := services.NewBillingService(tenantRepo, quotaService, meteringService, auditService, billing.NewStripe(&s.config.Billing), s.config)
+	twoFactorService := services.NewTwoFactorService(s.db, s.redis, s.config)
+	authService := services.NewAuthService(tenantRepo, userRepo, sessionRepo, roleRepo, userRoleRepo, ssoRepo, webauthnRepo, jwtService, twoFactorService, emailService, emailQueueService, quotaService, auditService, txManager, s.redis, s.config)
+	permissionService := services.NewPermissionService(permissionRepo, userRoleRepo, roleRepo, userRepo, objectACLRepo, s.redis)
+	sessionService := services.NewSessionService(s.db, s.redis)
+	tenantDomainService := services.NewTenantDomainService(tenantDomainRepo, tenantRepo, s.redis, s.config)
+	invitationService := services.NewInvitationService(s.db, txManager, userRepo, userRoleRepo, emailService, emailQueueService, quotaService, notificationService)
 	companySettingsService := services.NewCompanySettingsService(companySettingsRepo, tenantRepo, auditService)
+	deletionService := services.NewDeletionService(s.db, deletionRepo, userRepo, emailService, emailQueueService, permissionService, auditService, &s.config.Deletion)
+	employeeService := services.NewEmployeeService(employeeRepo, userRepo, departmentRepo, deletionService)
+	searchService := services.NewSearchService(searchRepo, permissionService)
+	leaveService := services.NewLeaveService(s.db, leaveRepo, employeeRepo, userRepo, userRoleRepo, tenantRepo, permissionService, emailService, emailQueueService, notificationService, s.config)
+	crmService := services.NewCRMService(crmRepo, userRepo, permissionService, notificationService)
+	timesheetService := services.NewTimesheetService(s.db, timesheetRepo, employeeRepo, userRepo, userRoleRepo, crmRepo, permissionService, notificationService)
+	fileService := services.NewFileService(fileRepo, tenantRepo, s.files, permissionService, s.config)
+	tenantSettingsService := services.NewTenantSettingsService(tenantRepo, fileService)
+	avatarService := services.NewAvatarService(userRepo, fileService, permissionService, &s.config.Storage)
+	personalDataService := services.NewPersonalDataService(personalDataRepo, userRepo, roleRepo, userRoleRepo, employeeRepo, auditService, sessionService, permissionService, fileService)
+	s.performance = services.NewPerformanceService(requestStatRepo, &s.config.Metrics)
+	s.usage = services.NewUsageService(usageStatRepo, tenantRepo, s.redis, &s.config.Usage)
+	watchService := services.NewWatchService(watchRepo, userRepo, permissionService, notificationService)
+	salesService := services.NewSalesService(salesRepo, txManager, auditService, deletionService, watchService)
+	approvalLinkService := services.NewApprovalLinkService(s.db, approvalLinkRepo, userRepo, userRoleRepo, tenantRepo, emailService, emailQueueService, twoFactorService, permissionService, auditService, s.config)
+	purchaseOrderService := services.NewPurchaseOrderService(supplierRepo, purchaseOrderRepo, auditService, deletionService, approvalLinkService)
+	accountingService := services.NewAccountingService(accountRepo, accountingPeriodRepo, journalEntryRepo, txManager, auditService)
+	sandboxService := services.NewSandboxService(s.db, sandboxRepo, tenantRepo, auditService, &s.config.Sandbox)
+	broadcastService := services.NewBroadcastService(s.db, broadcastRepo, emailOutboxRepo, emailService, auditService, &s.config.Broadcast, &s.config.Queues)
+	asyncJobService := services.NewAsyncJobService(s.db, s.redis, asyncJobRepo, permissionService, auditService, &s.config.Jobs, &s.config.Queues)
+	userImportService := services.NewUserImportService(s.db, userRepo, roleRepo, departmentRepo, tenantRepo, emailService, emailQueueService, asyncJobService, permissionService, auditService, quotaService, &s.config.UserImport)
+	webhookService := services.NewWebhookService(s.db, webhookRepo, s.config)
+	queueService := services.NewQueueService(s.db, emailOutboxRepo, webhookRepo, asyncJobRepo, &s.config.Queues)
+	automationService := services.NewAutomationService(s.db, automationRepo, userRepo, roleRepo, userRoleRepo, tenantRepo, emailOutboxRepo, emailService, webhookService, permissionService, s.config)
+	escalationService := services.NewEscalationService(s.db, escalationRepo, userRepo, roleRepo, userRoleRepo, departmentRepo, tenantRepo, emailService, emailQueueService, webhookService, auditService, s.config)
+	navigationService := services.NewNavigationService(s.redis, navigationRepo, permissionService)
+	entitySchemaService := services.NewEntitySchemaService(permissionService, customFieldRepo)
+	customFieldService := services.NewCustomFieldService(customFieldRepo)
+	dataQualityService := services.NewDataQualityService(s.db, dataQualityRepo, roleRepo, userRoleRepo, tenantRepo, emailService, emailQueueService, s.config)
+	complianceService := services.NewComplianceService(complianceRepo, tenantRepo, fileService, asyncJobService, s.config)
+	maintenanceService := services.NewMaintenanceService(tenantRepo, userRepo, notificationService)
+	announcementService := services.NewAnnouncementService(s.db, announcementRepo, roleRepo, departmentRepo, notificationService)
+	scheduledReportService := services.NewScheduledReportService(s.db, scheduledReportRepo, userRepo, tenantRepo, permissionService, emailService)
+	dashboardService := services.NewDashboardService(dashboardRepo, s.redis)
+	siemService := services.NewSIEMService(s.db, siemForwarderRepo, s.config)
+	offboardingService := services.NewOffboardingService(s.db, tenantRepo, userRepo, roleRepo, userRoleRepo, departmentRepo, auditService, fileService, sandboxService, asyncJobService, emailService, emailQueueService, s.config)
+	platformService := services.NewPlatformService(platformRepo, tenantRepo, quotaRepo, authService, billingService, emailQueueService, auditService)
+
+	// Changes published by the audit middleware reach webhooks, automation
+	// rules and the watchers of the records changed
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe("webhooks", webhookService.Dispatch)
+	eventBus.Subscribe("automation", automationService.HandleEvent)
+	eventBus.Subscribe("watches", watchService.HandleEvent)
 
 	// Initialize middleware
-	tenantMiddleware := appMiddleware.NewTenantMiddleware(tenantRepo)
+	tenantMiddleware := appMiddleware.NewTenantMiddleware(tenantDomainService)
 	authMiddleware := appMiddleware.NewAuthMiddleware(authService)
 	permMiddleware := appMiddleware.NewPermissionMiddleware(permissionService)
+	auditMiddleware := appMiddleware.NewAuditMiddleware(auditService, eventBus)
+	rateLimitMiddleware := appMiddleware.NewRateLimitMiddleware(s.redis, jwtService, &s.config.Security)
+	navMiddleware := appMiddleware.NewNavigationMiddleware(navigationService)
+	usageMiddleware := appMiddleware.NewUsageMiddleware(s.usage)
+	meteringMiddleware := appMiddleware.NewMeteringMiddleware(meteringService)
+	planMiddleware := appMiddleware.NewPlanMiddleware(billingService, quotaService)
+	platformMiddleware := appMiddleware.NewPlatformMiddleware(platformService)
+
+	// CORS middleware: the frontend, the API itself, the configured origins
+	// and the verified custom domains of tenants
+	allowedOrigins := append([]string{s.config.App.FrontendURL, s.config.App.BaseURL}, s.config.CORS.AllowedOrigins...)
+	s.router.Use(cors.Handler(cors.Options{
+		AllowOriginFunc:  appMiddleware.AllowOrigin(allowedOrigins, tenantDomainService),
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-CSRF-Token", appMiddleware.ReadYourWritesHeader},
+		ExposedHeaders:   []string{"Link", appMiddleware.SandboxHeader, appMiddleware.RateLimitLimitHeader, appMiddleware.RateLimitRemainingHeader, "Retry-After", "ETag", appMiddleware.TraceIDHeader, appMiddleware.TenantSlugMovedHeader},
+		AllowCredentials: true,
+		MaxAge:           int(s.config.CORS.MaxAge.Seconds()),
+	}))
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	userHandler := handlers.NewUserHandler(userRepo, userRoleRepo, permissionService)
-	roleHandler := handlers.NewRoleHandler(roleRepo, userRoleRepo, permissionService)
+	authHandler := handlers.NewAuthHandler(authService, permissionService)
+	userHandler := handlers.NewUserHandler(userRepo, userRoleRepo, permissionService, deletionService, userImportService, quotaService, notificationService, avatarService, authService, personalDataService, customFieldService)
+	roleHandler := handlers.NewRoleHandler(roleRepo, userRoleRepo, permissionService, deletionService, notificationService)
 	permissionHandler := handlers.NewPermissionHandler(permissionService)
 	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, userRepo)
+	webauthnHandler := handlers.NewWebAuthnHandler(authService)
 	sessionHandler := handlers.NewSessionHandler(sessionService)
 	invitationHandler := handlers.NewInvitationHandler(invitationService)
 	auditHandler := handlers.NewAuditHandler(auditService)
 	securityHandler := handlers.NewSecurityHandler(auditService, sessionService, twoFactorService)
 	companySettingsHandler := handlers.NewCompanySettingsHandler(companySettingsService)
-	departmentHandler := handlers.NewDepartmentHandler(departmentRepo, userRepo)
+	departmentHandler := handlers.NewDepartmentHandler(departmentRepo, userRepo, deletionService, permissionService, customFieldService)
+	employeeHandler := handlers.NewEmployeeHandler(employeeService, permissionService)
+	leaveHandler := handlers.NewLeaveHandler(leaveService)
+	crmHandler := handlers.NewCRMHandler(crmService)
+	timesheetHandler := handlers.NewTimesheetHandler(timesheetService)
+	fileHandler := handlers.NewFileHandler(fileService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	salesHandler := handlers.NewSalesHandler(salesService)
+	supplierHandler := handlers.NewSupplierHandler(purchaseOrderService)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService, escalationService)
+	accountingHandler := handlers.NewAccountingHandler(accountingService)
+	emailQueueHandler := handlers.NewEmailQueueHandler(emailQueueService)
+	sandboxHandler := handlers.NewSandboxHandler(sandboxService)
+	deletionHandler := handlers.NewDeletionHandler(deletionService)
+	broadcastHandler := handlers.NewBroadcastHandler(broadcastService)
+	asyncJobHandler := handlers.NewAsyncJobHandler(asyncJobService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	automationHandler := handlers.NewAutomationHandler(automationService)
+	escalationHandler := handlers.NewEscalationHandler(escalationService)
+	approvalLinkHandler := handlers.NewApprovalLinkHandler(approvalLinkService)
+	scheduledJobHandler := handlers.NewScheduledJobHandler(s.jobs)
+	watchHandler := handlers.NewWatchHandler(watchService)
+	queueHandler := handlers.NewQueueHandler(queueService)
+	performanceHandler := handlers.NewPerformanceHandler(s.performance, queueService, &s.config.Metrics)
+	navigationHandler := handlers.NewNavigationHandler(navigationService)
+	ssoHandler := handlers.NewSSOHandler(authService)
+	tenantDomainHandler := handlers.NewTenantDomainHandler(tenantDomainService)
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldService)
+	twoFactorPolicyHandler := handlers.NewTwoFactorPolicyHandler(authService)
+	sessionLimitHandler := handlers.NewSessionLimitHandler(authService)
+	tenantSettingsHandler := handlers.NewTenantSettingsHandler(tenantSettingsService)
+	emailTemplateHandler := handlers.NewEmailTemplateHandler(emailService, tenantSettingsService)
+	emailWebhookHandler := handlers.NewEmailWebhookHandler(s.mailer, emailQueueService)
+	privacyHandler := handlers.NewPrivacyHandler(s.usage)
+	complianceHandler := handlers.NewComplianceHandler(complianceService)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+	dataQualityHandler := handlers.NewDataQualityHandler(dataQualityService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	billingHandler := handlers.NewBillingHandler(billingService)
+	meteringHandler := handlers.NewMeteringHandler(meteringService)
+	offboardingHandler := handlers.NewOffboardingHandler(offboardingService)
+	platformHandler := handlers.NewPlatformHandler(platformService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	announcementHandler := handlers.NewAnnouncementHandler(announcementService)
+	scheduledReportHandler := handlers.NewScheduledReportHandler(scheduledReportService)
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
+	siemHandler := handlers.NewSIEMHandler(siemService)
+	entityHandler := handlers.NewEntityHandler(entitySchemaService)
+	docsHandler := handlers.NewDocsHandler(s.router, permissionService, entitySchemaService, &s.config.App)
+
+	// Register what happens once a staged delete's undo window has passed
+	deletionService.RegisterTarget(models.DeletionEntityUser, models.ResourceUsers, func(ctx context.Context, tenantID, requestedBy, userID uuid.UUID) error {
+		// Soft delete: restorable until purged by the user_purge job
+		if err := userRepo.Delete(ctx, tenantID, userID, requestedBy); err != nil {
+			return err
+		}
+		permissionService.InvalidateUserPermissions(ctx, tenantID, userID)
+		return nil
+	})
+	deletionService.RegisterTarget(models.DeletionEntityRole, models.ResourceRoles, func(ctx context.Context, tenantID, _, roleID uuid.UUID) error {
+		// Users may have been assigned during the undo window
+		userCount, err := roleRepo.CountUsers(ctx, tenantID, roleID)
+		if err != nil {
+			return err
+		}
+		if userCount > 0 {
+			return fmt.Errorf("cannot delete role with assigned users")
+		}
+		// Roles inheriting from this one lose its permissions; find them
+		// before the delete detaches them
+		descendantIDs, err := roleRepo.GetDescendantIDs(ctx, tenantID, roleID)
+		if err != nil {
+			return err
+		}
+		if err := roleRepo.Delete(ctx, tenantID, roleID); err != nil {
+			return err
+		}
+		for _, id := range append(descendantIDs, roleID) {
+			permissionService.InvalidateRolePermissions(ctx, tenantID, id)
+		}
+		return nil
+	})
+	deletionService.RegisterTarget(models.DeletionEntityDepartment, models.ResourceDepartments, func(ctx context.Context, tenantID, _, deptID uuid.UUID) error {
+		return departmentRepo.Delete(ctx, tenantID, deptID)
+	})
+	deletionService.RegisterTarget(models.DeletionEntityEmployee, models.ResourceEmployees, employeeService.Purge)
+	deletionService.RegisterTarget(models.DeletionEntitySalesDocument, models.ResourceSales, salesService.PurgeDocument)
+	deletionService.RegisterTarget(models.DeletionEntityPurchaseOrder, models.ResourcePurchasing, purchaseOrderService.PurgeOrder)
+
+	// Register what can be approved from emailed Approve/Reject links
+	approvalLinkService.RegisterTarget(models.ApprovalResourceSupplierInvoice, models.ResourcePurchasing, purchaseOrderService.DescribeInvoiceApproval, purchaseOrderService.DecideInvoiceApproval)
+
+	// Register approvals escalation policies can escalate
+	escalationService.RegisterSource(models.ApprovalResourceSupplierInvoice, purchaseOrderService.ListPendingInvoiceApprovals)
+
+	// Register the records users can watch, with the field holding who they
+	// are assigned to
+	watchService.RegisterType(models.NavigationEntityDepartment, models.ResourceDepartments, "head_user_id", func(ctx context.Context, tenantID, deptID uuid.UUID) (*models.EntitySummary, error) {
+		dept, err := departmentRepo.FindByID(ctx, tenantID, deptID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.EntitySummary{Title: dept.Name, Path: fmt.Sprintf("/departments/%s", dept.ID)}, nil
+	})
+	watchService.RegisterType(models.EntityCustomer, models.ResourceCRM, "owner_id", crmService.DescribeCustomer)
+	watchService.RegisterType(models.EntityContact, models.ResourceCRM, "owner_id", crmService.DescribeContact)
+	watchService.RegisterType(models.EntityOpportunity, models.ResourceCRM, "owner_id", crmService.DescribeOpportunity)
+	watchService.RegisterType(models.NavigationEntitySalesDocument, models.ResourceSales, "", salesService.DescribeDocument)
+	deletionService.Subscribe("watches", watchService.HandleDeletion)
+
+	// Register the work async jobs can run
+	asyncJobService.RegisterType(models.JobTypeUserImport, models.ResourceUsers, userImportService.RunImportJob)
+	asyncJobService.RegisterType(models.JobTypeComplianceReport, models.ResourceSecurity, complianceService.RunReportJob)
+	asyncJobService.RegisterType(models.JobTypeTenantExport, models.ResourceSettings, offboardingService.RunExportJob)
+
+	// Register what can be shown in recently viewed and favorites lists
+	navigationService.RegisterType(models.NavigationEntityUser, models.ResourceUsers, func(ctx context.Context, tenantID, userID uuid.UUID) (*models.EntitySummary, error) {
+		user, err := userRepo.FindByID(ctx, tenantID, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.EntitySummary{Title: user.FullName(), Subtitle: user.Email, Path: fmt.Sprintf("/users/%s", user.ID)}, nil
+	})
+	navigationService.RegisterType(models.NavigationEntityRole, models.ResourceRoles, func(ctx context.Context, tenantID, roleID uuid.UUID) (*models.EntitySummary, error) {
+		role, err := roleRepo.FindByID(ctx, tenantID, roleID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.EntitySummary{Title: role.DisplayName, Path: fmt.Sprintf("/roles/%s", role.ID)}, nil
+	})
+	navigationService.RegisterType(models.NavigationEntityDepartment, models.ResourceDepartments, func(ctx context.Context, tenantID, deptID uuid.UUID) (*models.EntitySummary, error) {
+		dept, err := departmentRepo.FindByID(ctx, tenantID, deptID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.EntitySummary{Title: dept.Name, Path: fmt.Sprintf("/departments/%s", dept.ID)}, nil
+	})
+	navigationService.RegisterType(models.NavigationEntityEmployee, models.ResourceEmployees, employeeService.Describe)
+	navigationService.RegisterType(models.NavigationEntitySalesDocument, models.ResourceSales, salesService.DescribeDocument)
+	navigationService.RegisterType(models.NavigationEntitySupplier, models.ResourcePurchasing, purchaseOrderService.DescribeSupplier)
+	navigationService.RegisterType(models.NavigationEntityPurchaseOrder, models.ResourcePurchasing, purchaseOrderService.DescribeOrder)
+	navigationService.RegisterType(models.NavigationEntitySupplierInvoice, models.ResourcePurchasing, purchaseOrderService.DescribeInvoice)
+	navigationService.RegisterType(models.NavigationEntityAccount, models.ResourceAccounting, accountingService.DescribeAccount)
+	navigationService.RegisterType(models.NavigationEntityJournalEntry, models.ResourceAccounting, accountingService.DescribeEntry)
+
+	// Register the entity types whose fields clients can look up to render
+	// forms and columns
+	entitySchemaService.RegisterType(models.NavigationEntityUser, models.ResourceUsers, models.User{}, models.UserCreateRequest{}, map[string][]string{
+		"status": {models.UserStatusActive, models.UserStatusSuspended, models.UserStatusDeactivated, models.UserStatusPending},
+	})
+	entitySchemaService.RegisterType(models.NavigationEntityRole, models.ResourceRoles, models.Role{}, models.RoleCreateRequest{}, nil)
+	entitySchemaService.RegisterType(models.NavigationEntityDepartment, models.ResourceDepartments, models.Department{}, models.DepartmentCreateRequest{}, map[string][]string{
+		"status": {models.DepartmentStatusActive, models.DepartmentStatusInactive},
+	})
+	entitySchemaService.RegisterType(models.NavigationEntityEmployee, models.ResourceEmployees, models.Employee{}, models.EmployeeCreateRequest{}, map[string][]string{
+		"employment_type": models.EmploymentTypes,
+		"status":          models.EmployeeStatuses,
+	})
+	entitySchemaService.RegisterType(models.EntityCustomer, models.ResourceCRM, models.CRMCustomer{}, models.CRMCustomerCreateRequest{}, map[string][]string{
+		"customer_type": models.CRMCustomerTypes,
+		"status":        models.CRMCustomerStatuses,
+	})
+	entitySchemaService.RegisterType(models.EntityContact, models.ResourceCRM, models.CRMContact{}, models.CRMContactCreateRequest{}, nil)
+	entitySchemaService.RegisterType(models.EntityOpportunity, models.ResourceCRM, models.CRMOpportunity{}, models.CRMOpportunityCreateRequest{}, map[string][]string{
+		"status": models.CRMOutcomes,
+	})
+
+	// Register background jobs (single-leader per run, see internal/jobs).
+	// Cleanups run every JOBS_CLEANUP_INTERVAL, or on the
+	// JOBS_CLEANUP_SCHEDULE cron expression when it is set.
+	registerCleanup := func(name string, run jobs.Func) {
+		if spec := s.config.Jobs.CleanupSchedule; spec != "" {
+			s.jobs.RegisterCron(name, spec, run)
+			return
+		}
+		s.jobs.Register(name, s.config.Jobs.CleanupInterval, run)
+	}
+	registerCleanup("session_cleanup", sessionService.CleanupExpiredSessions)
+	registerCleanup("invitation_cleanup", invitationService.CleanupExpiredInvitations)
+	registerCleanup("verification_token_cleanup", func(ctx context.Context) (int, error) {
+		cleared, err := tenantRepo.CleanupExpiredVerificationTokens(ctx)
+		return int(cleared), err
+	})
+	registerCleanup("tenant_slug_history_cleanup", tenantDomainService.CleanupSlugHistory)
+	s.jobs.Register("email_outbox", s.config.Jobs.EmailPollInterval, emailQueueService.ProcessQueue)
+	s.jobs.RegisterWithTimeout("sandbox_clone", s.config.Jobs.SandboxPollInterval, s.config.Sandbox.CloneTimeout, sandboxService.ProcessPending)
+	registerCleanup("sandbox_expiry", sandboxService.ExpireSandboxes)
+	s.jobs.Register("pending_deletions", s.config.Jobs.DeletionPollInterval, deletionService.ProcessDue)
+	registerCleanup("tenant_purge", offboardingService.PurgeDue)
+	registerCleanup("user_purge", func(ctx context.Context) (int, error) {
+		return userRepo.PurgeDeletedBefore(ctx, time.Now().Add(-s.config.Deletion.UserRetention))
+	})
+	s.jobs.Register("email_broadcasts", s.config.Jobs.BroadcastPollInterval, broadcastService.ProcessQueue)
+	s.jobs.Register("announcements", s.config.Jobs.AnnouncementPollInterval, announcementService.PublishDue)
+	s.jobs.RegisterWithTimeout("async_jobs", s.config.Jobs.AsyncPollInterval, s.config.Jobs.AsyncTimeout, asyncJobService.ProcessQueue)
+	registerCleanup("async_job_cleanup", asyncJobService.CleanupFinishedJobs)
+	s.jobs.Register("webhook_deliveries", s.config.Jobs.WebhookPollInterval, webhookService.ProcessQueue)
+	registerCleanup("webhook_delivery_cleanup", webhookService.CleanupDeliveries)
+	s.jobs.Register("queue_monitor", s.config.Jobs.QueueMonitorInterval, queueService.Monitor)
+	s.jobs.Register("automation_executions", s.config.Jobs.AutomationPollInterval, automationService.ProcessQueue)
+	registerCleanup("automation_execution_cleanup", automationService.CleanupExecutions)
+	registerCleanup("approval_link_cleanup", approvalLinkService.CleanupExpired)
+	s.jobs.Register("approval_escalations", s.config.Jobs.EscalationPollInterval, escalationService.ProcessDue)
+	s.jobs.Register("recent_views_flush", s.config.Jobs.RecentViewsFlushInterval, navigationService.FlushRecent)
+	s.jobs.Register("session_activity_flush", s.config.Jobs.SessionActivityFlushInterval, authService.FlushSessionActivity)
+	s.jobs.RegisterCron("data_quality", s.config.Jobs.DataQualitySchedule, dataQualityService.RunScheduled)
+	s.jobs.Register("quota_check", s.config.Jobs.QuotaCheckInterval, quotaService.CheckScheduled)
+	s.jobs.Register("metering_rollup", s.config.Jobs.MeteringRollupInterval, meteringService.Rollup)
+	s.jobs.RegisterCron("leave_accrual", s.config.Jobs.LeaveAccrualSchedule, leaveService.RunAccrual)
+	s.jobs.RegisterCron("two_factor_reminders", s.config.Jobs.TwoFactorReminderSchedule, authService.RemindTwoFactorSetup)
+	s.jobs.RegisterCron("notification_digest", s.config.Jobs.NotificationDigestSchedule, notificationService.SendDigests)
+	s.jobs.Register("scheduled_reports", s.config.Jobs.ReportPollInterval, scheduledReportService.RunDue)
+	// A run may send every forwarder its SIEM_MAX_BATCHES_PER_RUN batches
+	s.jobs.RegisterWithTimeout("siem_forwarding", s.config.Jobs.SIEMPollInterval, time.Duration(s.config.SIEM.MaxBatchesPerRun)*s.config.SIEM.Timeout, siemService.ForwardDue)
+	s.jobs.Register("file_purge", s.config.Jobs.FilePurgeInterval, fileService.PurgeDeleted)
+	s.jobs.Register("role_assignment_expiry", s.config.Jobs.RoleExpiryInterval, permissionService.ExpireRoleAssignments)
+	registerCleanup("notification_cleanup", notificationService.CleanupRead)
+	registerCleanup("request_stats_cleanup", s.performance.Cleanup)
+	registerCleanup("usage_stats_cleanup", s.usage.Cleanup)
+	registerCleanup("tenant_usage_cleanup", meteringService.Cleanup)
+	if s.config.Usage.Enabled && s.config.Usage.SinkURL != "" {
+		s.jobs.RegisterCron("usage_export", s.config.Jobs.UsageExportSchedule, s.usage.Export)
+	}
+
+	// Health checks: liveness and readiness (databases, Redis, SMTP) probes
+	handlers.NewHealthHandler(diagnostics.NewProbe(s.db, s.redis, s.config)).RegisterRoutes(s.router)
+
+	// Prometheus metrics (request cost totals of this replica)
+	if s.config.Metrics.Enabled {
+		s.router.Get("/metrics", performanceHandler.Metrics)
+	}
+
+	// Email provider bounce and complaint webhooks and Stripe webhooks (signed
+	// by the provider, not tied to a tenant)
+	s.router.Group(func(r chi.Router) {
+		r.Use(rateLimitMiddleware.LimitByIP)
+		emailWebhookHandler.RegisterRoutes(r)
+		billingHandler.RegisterWebhookRoutes(r)
+	})
 
-	// Health check endpoint
-	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	// Platform administration (across tenants, so no tenant resolution,
+	// tenant rate limit or metering)
+	s.router.Group(func(r chi.Router) {
+		r.Use(rateLimitMiddleware.LimitByIP)
+		platformHandler.RegisterRoutes(r, authMiddleware, platformMiddleware)
 	})
 
-	// Apply tenant resolution middleware to all routes (except /health)
+	// Apply rate limiting and tenant resolution middleware to all routes (except health checks)
 	s.router.Group(func(r chi.Router) {
+		r.Use(rateLimitMiddleware.LimitByIP)
+		r.Use(rateLimitMiddleware.LimitByTenant)
 		r.Use(tenantMiddleware.ResolveTenant)
+		r.Use(usageMiddleware.Track)
+		r.Use(meteringMiddleware.Track)
 
 		// Public routes (no authentication required)
 		authHandler.RegisterRoutes(r, authMiddleware, tenantMiddleware) // Includes login, register, verify-email, etc.
-		invitationHandler.RegisterRoutes(r, authMiddleware, permMiddleware) // Accept invitation is public
+		invitationHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, planMiddleware) // Accept invitation is public
+		approvalLinkHandler.RegisterRoutes(r)                                                                 // Emailed Approve/Reject links
 
 		// Protected routes (authentication required)
 		// Week 2: Authentication Core
 		// (Auth routes are already registered above)
 
 		// Week 3: RBAC System
-		userHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
-		roleHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		userHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, navMiddleware, planMiddleware)
+		roleHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, navMiddleware)
 		permissionHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
 
 		// Week 4: Advanced Features
 		twoFactorHandler.RegisterRoutes(r, authMiddleware)
-		sessionHandler.RegisterRoutes(r, authMiddleware)
+		webauthnHandler.RegisterRoutes(r, authMiddleware, auditMiddleware)
+		sessionHandler.RegisterRoutes(r, authMiddleware, auditMiddleware)
 		// Invitation routes already registered above
-		auditHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		auditHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
 		securityHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
 
 		// Company Settings
 		companySettingsHandler.RegisterRoutes(r, authMiddleware)
 
+		// Single sign-on settings (OpenID Connect providers, SSO-only login)
+		ssoHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+		twoFactorPolicyHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+		sessionLimitHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+		tenantSettingsHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Custom domains (add, verify by DNS TXT record, remove)
+		tenantDomainHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Custom fields on users and departments
+		customFieldHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Email template previews (localized, in the tenant's branding)
+		emailTemplateHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// Privacy settings (usage analytics opt-out)
+		privacyHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Maintenance window (API freeze, paused jobs)
+		maintenanceHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Compliance evidence reports for SOC 2 and ISO 27001 audits
+		complianceHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Tenant data export and deletion (owners only)
+		offboardingHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
 		// Departments
-		departmentHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		departmentHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, navMiddleware)
+
+		// HR (employees, org chart)
+		employeeHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, navMiddleware)
+
+		// Leave (types, balances, requests, team calendar)
+		leaveHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Timesheets (projects, weekly time entries, approvals, billing reports)
+		timesheetHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Files (avatars and attachments, signed downloads)
+		fileHandler.RegisterRoutes(r, authMiddleware, auditMiddleware)
+
+		// CRM (customers, contacts, pipelines, opportunities, activities)
+		crmHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Search (users, customers, products)
+		searchHandler.RegisterRoutes(r, authMiddleware)
+
+		// Sales (quotes, orders, invoices)
+		salesHandler.RegisterRoutes(r, authMiddleware, permMiddleware, navMiddleware)
+
+		// Purchasing (suppliers, purchase orders, receiving, supplier invoices)
+		supplierHandler.RegisterRoutes(r, authMiddleware, permMiddleware, navMiddleware)
+		purchaseOrderHandler.RegisterRoutes(r, authMiddleware, permMiddleware, navMiddleware)
+
+		// Accounting (chart of accounts, periods, journal entries, trial balance)
+		accountingHandler.RegisterRoutes(r, authMiddleware, permMiddleware, navMiddleware)
+
+		// Administration (email outbox, background jobs, slowest endpoints/queries)
+		emailQueueHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		scheduledJobHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		queueHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+		performanceHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// Sandboxes (cloned copies of the tenant for testing)
+		sandboxHandler.RegisterRoutes(r, authMiddleware, permMiddleware, planMiddleware)
+
+		// Staged deletions (undo window)
+		deletionHandler.RegisterRoutes(r, authMiddleware)
+
+		// Email broadcasts (unsubscribe is public)
+		broadcastHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// Long-running jobs (status, cancellation, progress events)
+		asyncJobHandler.RegisterRoutes(r, authMiddleware)
+
+		// Webhooks (event notifications to tenant endpoints, delivery log)
+		webhookHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, planMiddleware)
+
+		// Automation (if-this-then-that rules, execution log)
+		automationHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware, planMiddleware)
+
+		// Escalation policies (overdue approvals, escalation log)
+		escalationHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Records the current user watches
+		watchHandler.RegisterRoutes(r, authMiddleware)
+		// Quick navigation (recently viewed entities, favorites)
+		navigationHandler.RegisterRoutes(r, authMiddleware)
+
+		// Data quality (checks, scheduled report, data steward alerts)
+		dataQualityHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Plan quotas (usage, warning alerts)
+		quotaHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Plans and Stripe subscriptions
+		billingHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// Metered usage (API calls, active users, storage, emails)
+		meteringHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// In-app notifications (list, read state, live stream)
+		notificationHandler.RegisterRoutes(r, authMiddleware)
+
+		// Announcements (addressed to the current user, management by admins)
+		announcementHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Scheduled reports emailed to admins
+		scheduledReportHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Dashboard analytics
+		dashboardHandler.RegisterRoutes(r, authMiddleware, permMiddleware)
+
+		// Audit log forwarding to SIEMs
+		siemHandler.RegisterRoutes(r, authMiddleware, permMiddleware, auditMiddleware)
+
+		// Entity schemas (fields for forms, columns and import templates)
+		entityHandler.RegisterRoutes(r, authMiddleware)
+
+		// OpenAPI docs (every operation, or those the current user can call)
+		if s.config.App.EnableSwagger {
+			docsHandler.RegisterRoutes(r, authMiddleware)
+		}
 	})
 
 	return s.router
 }
 
+// Jobs returns the background job runner populated by Setup
+func (s *Router) Jobs() *jobs.Runner {
+	return s.jobs
+}
+
+// Performance returns the request stats service created by Setup
+func (s *Router) Performance() services.PerformanceManager {
+	return s.performance
+}
+
+// Usage returns the usage analytics service created by Setup
+func (s *Router) Usage() services.UsageManager {
+	return s.usage
+}
+
 // GetRouter returns the configured router
 func (s *Router) GetRouter() *chi.Mux {
 	return s.router
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Accounting audit actions
+const (
+	accountingAuditAccountCreated = "accounting.account_created"
+	accountingAuditAccountUpdated = "accounting.account_updated"
+	accountingAuditAccountDeleted = "accounting.account_deleted"
+	accountingAuditPeriodCreated  = "accounting.period_created"
+	accountingAuditPeriodClosed   = "accounting.period_closed"
+	accountingAuditPeriodReopened = "accounting.period_reopened"
+	accountingAuditEntryCreated   = "accounting.entry_created"
+	accountingAuditEntryUpdated   = "accounting.entry_updated"
+	accountingAuditEntryDeleted   = "accounting.entry_deleted"
+	accountingAuditEntryPosted    = "accounting.entry_posted"
+	accountingAuditEntryReversed  = "accounting.entry_reversed"
+)
+
+// AccountingService handles the chart of accounts, posting periods, journal
+// entries and trial balance
+type AccountingService struct {
+	accountRepo  repository.AccountStore
+	periodRepo   repository.AccountingPeriodStore
+	journalRepo  repository.JournalEntryStore
+	txManager    *database.TxManager
+	auditService AuditManager
+}
+
+// NewAccountingService creates a new accounting service
+func NewAccountingService(
+	accountRepo repository.AccountStore,
+	periodRepo repository.AccountingPeriodStore,
+	journalRepo repository.JournalEntryStore,
+	txManager *database.TxManager,
+	auditService AuditManager,
+) *AccountingService {
+	return &AccountingService{
+		accountRepo:  accountRepo,
+		periodRepo:   periodRepo,
+		journalRepo:  journalRepo,
+		txManager:    txManager,
+		auditService: auditService,
+	}
+}
+
+// CreateAccount adds an account to the chart of accounts
+func (s *AccountingService) CreateAccount(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountCreateRequest) (*models.Account, error) {
+	exists, err := s.accountRepo.CheckCodeExists(ctx, tenantID, req.Code, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.NewConflictError("ACCOUNT_CODE_EXISTS", "account code already exists")
+	}
+
+	if req.ParentID != nil {
+		if err := s.validateParent(ctx, tenantID, req.AccountType, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	account := &models.Account{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		AccountType: req.AccountType,
+		ParentID:    req.ParentID,
+		IsActive:    true,
+		CreatedBy:   &userID,
+	}
+
+	if err := s.accountRepo.Create(ctx, tenantID, account); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditAccountCreated, models.ResourceAccounting, account.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"code":         account.Code,
+		"name":         account.Name,
+		"account_type": account.AccountType,
+	})
+
+	return account, nil
+}
+
+// GetAccount retrieves an account
+func (s *AccountingService) GetAccount(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error) {
+	return s.accountRepo.FindByID(ctx, tenantID, accountID)
+}
+
+// DescribeAccount describes an account for recently viewed and favorites
+// lists
+func (s *AccountingService) DescribeAccount(ctx context.Context, tenantID, accountID uuid.UUID) (*models.EntitySummary, error) {
+	account, err := s.accountRepo.FindByID(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    account.Code + " " + account.Name,
+		Subtitle: account.AccountType,
+		Path:     fmt.Sprintf("/accounting/accounts/%s", account.ID),
+	}, nil
+}
+
+// ListAccounts lists the chart of accounts
+func (s *AccountingService) ListAccounts(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error) {
+	return s.accountRepo.List(ctx, tenantID, accountType, activeOnly, search)
+}
+
+// UpdateAccount updates an account
+func (s *AccountingService) UpdateAccount(
+	ctx context.Context,
+	tenantID, userID, accountID uuid.UUID,
+	req *models.AccountUpdateRequest,
+) (*models.Account, error) {
+	account, err := s.accountRepo.FindByID(ctx, tenantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code != nil && *req.Code != account.Code {
+		exists, err := s.accountRepo.CheckCodeExists(ctx, tenantID, *req.Code, &accountID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, utils.NewConflictError("ACCOUNT_CODE_EXISTS", "account code already exists")
+		}
+		account.Code = *req.Code
+	}
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Description != nil {
+		account.Description = req.Description
+	}
+	if req.ParentID != nil {
+		// Moving an account under itself or one of its children would create a cycle
+		circular, err := s.accountRepo.IsDescendant(ctx, tenantID, accountID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if circular {
+			return nil, utils.NewBadRequestError("INVALID_PARENT_ACCOUNT", "account cannot be its own parent")
+		}
+		if err := s.validateParent(ctx, tenantID, account.AccountType, *req.ParentID); err != nil {
+			return nil, err
+		}
+		account.ParentID = req.ParentID
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	if err := s.accountRepo.Update(ctx, tenantID, account); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditAccountUpdated, models.ResourceAccounting, account.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"code":      account.Code,
+		"name":      account.Name,
+		"is_active": account.IsActive,
+	})
+
+	return account, nil
+}
+
+// DeleteAccount deletes an account that was never used
+func (s *AccountingService) DeleteAccount(ctx context.Context, tenantID, userID, accountID uuid.UUID) error {
+	account, err := s.accountRepo.FindByID(ctx, tenantID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.Delete(ctx, tenantID, accountID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditAccountDeleted, models.ResourceAccounting, account.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"code": account.Code,
+		"name": account.Name,
+	})
+
+	return nil
+}
+
+// validateParent checks that a parent account exists and has the same type
+func (s *AccountingService) validateParent(ctx context.Context, tenantID uuid.UUID, accountType string, parentID uuid.UUID) error {
+	parent, err := s.accountRepo.FindByID(ctx, tenantID, parentID)
+	if err != nil {
+		if err.Error() == "account not found" {
+			return utils.NewBadRequestError("INVALID_PARENT_ACCOUNT", "parent account not found")
+		}
+		return err
+	}
+	if parent.AccountType != accountType {
+		return utils.NewBadRequestError("INVALID_PARENT_ACCOUNT", "parent account must have the same account type")
+	}
+	return nil
+}
+
+// CreatePeriod creates an open posting period
+func (s *AccountingService) CreatePeriod(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountingPeriodCreateRequest) (*models.AccountingPeriod, error) {
+	period := &models.AccountingPeriod{
+		Name:      req.Name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Status:    models.PeriodStatusOpen,
+		CreatedBy: &userID,
+	}
+
+	if err := s.periodRepo.Create(ctx, tenantID, period); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditPeriodCreated, models.ResourceAccounting, period.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name":       period.Name,
+		"start_date": period.StartDate.Format("2006-01-02"),
+		"end_date":   period.EndDate.Format("2006-01-02"),
+	})
+
+	return period, nil
+}
+
+// GetPeriod retrieves a posting period
+func (s *AccountingService) GetPeriod(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	return s.periodRepo.FindByID(ctx, tenantID, periodID)
+}
+
+// ListPeriods lists posting periods
+func (s *AccountingService) ListPeriods(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error) {
+	return s.periodRepo.List(ctx, tenantID, status)
+}
+
+// ClosePeriod closes a posting period. Draft entries dated in the period must
+// be posted or deleted first so nothing is silently left out of the period.
+func (s *AccountingService) ClosePeriod(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	period, err := s.periodRepo.FindByID(ctx, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !period.IsOpen() {
+		return nil, utils.NewConflictError("PERIOD_ALREADY_CLOSED", "accounting period is already closed")
+	}
+
+	drafts, err := s.periodRepo.CountDraftEntries(ctx, tenantID, period)
+	if err != nil {
+		return nil, err
+	}
+	if drafts > 0 {
+		return nil, utils.NewBadRequestError("PERIOD_HAS_DRAFT_ENTRIES", fmt.Sprintf("accounting period has %d draft journal entries", drafts))
+	}
+
+	if err := s.periodRepo.UpdateStatus(ctx, tenantID, periodID, userID, models.PeriodStatusOpen, models.PeriodStatusClosed); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditPeriodClosed, models.ResourceAccounting, period.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name": period.Name,
+	})
+
+	return s.periodRepo.FindByID(ctx, tenantID, periodID)
+}
+
+// ReopenPeriod reopens a closed posting period
+func (s *AccountingService) ReopenPeriod(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	period, err := s.periodRepo.FindByID(ctx, tenantID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if period.IsOpen() {
+		return nil, utils.NewConflictError("PERIOD_ALREADY_OPEN", "accounting period is already open")
+	}
+
+	if err := s.periodRepo.UpdateStatus(ctx, tenantID, periodID, userID, models.PeriodStatusClosed, models.PeriodStatusOpen); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditPeriodReopened, models.ResourceAccounting, period.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name": period.Name,
+	})
+
+	return s.periodRepo.FindByID(ctx, tenantID, periodID)
+}
+
+// CreateEntry creates a draft journal entry. Drafts may be unbalanced; balance
+// is enforced when the entry is posted.
+func (s *AccountingService) CreateEntry(ctx context.Context, tenantID, userID uuid.UUID, req *models.JournalEntryCreateRequest) (*models.JournalEntry, error) {
+	lines := buildJournalLines(req.Lines)
+	if err := s.validateLineAccounts(ctx, tenantID, lines); err != nil {
+		return nil, err
+	}
+
+	entry := &models.JournalEntry{
+		EntryDate:   req.EntryDate,
+		Description: req.Description,
+		Reference:   req.Reference,
+		Status:      models.JournalStatusDraft,
+		Lines:       lines,
+		CreatedBy:   &userID,
+	}
+	applyJournalTotals(entry)
+
+	if err := s.journalRepo.Create(ctx, tenantID, entry); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditEntryCreated, models.ResourceAccounting, entry.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"entry_number": entry.EntryNumber,
+		"total_debit":  entry.TotalDebit,
+		"total_credit": entry.TotalCredit,
+	})
+
+	return entry, nil
+}
+
+// GetEntry retrieves a journal entry with its lines
+func (s *AccountingService) GetEntry(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	return s.journalRepo.FindByID(ctx, tenantID, entryID)
+}
+
+// DescribeEntry describes a journal entry for recently viewed and favorites
+// lists
+func (s *AccountingService) DescribeEntry(ctx context.Context, tenantID, entryID uuid.UUID) (*models.EntitySummary, error) {
+	entry, err := s.journalRepo.FindByID(ctx, tenantID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    entry.EntryNumber,
+		Subtitle: entry.Description,
+		Path:     fmt.Sprintf("/accounting/journal-entries/%s", entry.ID),
+	}, nil
+}
+
+// ListEntries lists journal entries with filters and pagination
+func (s *AccountingService) ListEntries(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filter models.JournalEntryFilter,
+	limit, offset int,
+) ([]models.JournalEntry, int, error) {
+	return s.journalRepo.List(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateEntry updates a draft journal entry
+func (s *AccountingService) UpdateEntry(
+	ctx context.Context,
+	tenantID, userID, entryID uuid.UUID,
+	req *models.JournalEntryUpdateRequest,
+) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindByID(ctx, tenantID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.IsPosted() {
+		return nil, utils.NewBadRequestError("JOURNAL_ENTRY_NOT_DRAFT", "only draft journal entries can be edited")
+	}
+
+	if req.EntryDate != nil {
+		entry.EntryDate = *req.EntryDate
+	}
+	if req.Description != nil {
+		entry.Description = *req.Description
+	}
+	if req.Reference != nil {
+		entry.Reference = req.Reference
+	}
+	if req.Lines != nil {
+		lines := buildJournalLines(*req.Lines)
+		if err := s.validateLineAccounts(ctx, tenantID, lines); err != nil {
+			return nil, err
+		}
+		entry.Lines = lines
+	}
+	applyJournalTotals(entry)
+
+	if err := s.journalRepo.Update(ctx, tenantID, entry); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditEntryUpdated, models.ResourceAccounting, entry.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"entry_number": entry.EntryNumber,
+		"total_debit":  entry.TotalDebit,
+		"total_credit": entry.TotalCredit,
+	})
+
+	return s.journalRepo.FindByID(ctx, tenantID, entryID)
+}
+
+// DeleteEntry deletes a draft journal entry
+func (s *AccountingService) DeleteEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) error {
+	entry, err := s.journalRepo.FindByID(ctx, tenantID, entryID)
+	if err != nil {
+		return err
+	}
+
+	if entry.IsPosted() {
+		return utils.NewBadRequestError("JOURNAL_ENTRY_NOT_DRAFT", "posted journal entries cannot be deleted, reverse it instead")
+	}
+
+	if err := s.journalRepo.Delete(ctx, tenantID, entryID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditEntryDeleted, models.ResourceAccounting, entry.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"entry_number": entry.EntryNumber,
+	})
+
+	return nil
+}
+
+// PostEntry validates double-entry rules and posts a draft entry into the
+// open period covering its date. The entry is locked while its lines are
+// checked and posted, so an edit cannot unbalance it in between.
+func (s *AccountingService) PostEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	var entry *models.JournalEntry
+	var period *models.AccountingPeriod
+	err := s.txManager.InTenantTx(ctx, tenantID, func(ctx context.Context) error {
+		if err := s.journalRepo.Lock(ctx, tenantID, entryID); err != nil {
+			return err
+		}
+
+		var err error
+		entry, err = s.journalRepo.FindByID(ctx, tenantID, entryID)
+		if err != nil {
+			return err
+		}
+
+		if entry.IsPosted() {
+			return utils.NewConflictError("JOURNAL_ENTRY_ALREADY_POSTED", "journal entry is already posted")
+		}
+
+		if err := validateDoubleEntry(entry); err != nil {
+			return err
+		}
+		if err := s.validateLineAccounts(ctx, tenantID, entry.Lines); err != nil {
+			return err
+		}
+
+		period, err = s.openPeriodFor(ctx, tenantID, entry.EntryDate)
+		if err != nil {
+			return err
+		}
+
+		return s.journalRepo.Post(ctx, tenantID, entryID, period.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditEntryPosted, models.ResourceAccounting, entry.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"entry_number": entry.EntryNumber,
+		"period":       period.Name,
+		"total":        entry.TotalDebit,
+	})
+
+	return s.journalRepo.FindByID(ctx, tenantID, entryID)
+}
+
+// ReverseEntry posts a new entry that swaps the debits and credits of a posted
+// entry, dated today unless another date is given
+func (s *AccountingService) ReverseEntry(
+	ctx context.Context,
+	tenantID, userID, entryID uuid.UUID,
+	req *models.JournalEntryReverseRequest,
+) (*models.JournalEntry, error) {
+	original, err := s.journalRepo.FindByID(ctx, tenantID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !original.IsPosted() {
+		return nil, utils.NewBadRequestError("JOURNAL_ENTRY_NOT_POSTED", "only posted journal entries can be reversed")
+	}
+	if original.ReversedByID != nil {
+		return nil, utils.NewConflictError("JOURNAL_ENTRY_ALREADY_REVERSED", "journal entry is already reversed")
+	}
+	if original.ReversalOfID != nil {
+		return nil, utils.NewBadRequestError("JOURNAL_ENTRY_IS_REVERSAL", "a reversing entry cannot be reversed")
+	}
+
+	entryDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.EntryDate != nil {
+		entryDate = *req.EntryDate
+	}
+
+	description := "Reversal of " + original.EntryNumber
+	if req.Description != nil && *req.Description != "" {
+		description = *req.Description
+	}
+
+	period, err := s.openPeriodFor(ctx, tenantID, entryDate)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]models.JournalEntryLine, len(original.Lines))
+	for i, line := range original.Lines {
+		lines[i] = models.JournalEntryLine{
+			AccountID:   line.AccountID,
+			Description: line.Description,
+			Debit:       line.Credit,
+			Credit:      line.Debit,
+		}
+	}
+
+	now := time.Now().UTC()
+	originalID := original.ID
+	reversal := &models.JournalEntry{
+		EntryDate:    entryDate,
+		PeriodID:     &period.ID,
+		Description:  description,
+		Reference:    &original.EntryNumber,
+		Status:       models.JournalStatusPosted,
+		PostedAt:     &now,
+		PostedBy:     &userID,
+		ReversalOfID: &originalID,
+		Lines:        lines,
+		CreatedBy:    &userID,
+	}
+	applyJournalTotals(reversal)
+
+	if err := s.journalRepo.CreateReversal(ctx, tenantID, original.ID, reversal); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, accountingAuditEntryReversed, models.ResourceAccounting, original.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"entry_number":    original.EntryNumber,
+		"reversal_number": reversal.EntryNumber,
+		"period":          period.Name,
+	})
+
+	return s.journalRepo.FindByID(ctx, tenantID, reversal.ID)
+}
+
+// TrialBalance computes the trial balance for a period, or for all posted
+// entries up to asOf (default today) when no period is given
+func (s *AccountingService) TrialBalance(ctx context.Context, tenantID uuid.UUID, periodID *uuid.UUID, asOf *time.Time) (*models.TrialBalance, error) {
+	balance := &models.TrialBalance{
+		ToDate: time.Now().UTC().Truncate(24 * time.Hour),
+	}
+
+	if periodID != nil {
+		period, err := s.periodRepo.FindByID(ctx, tenantID, *periodID)
+		if err != nil {
+			return nil, err
+		}
+		balance.FromDate = &period.StartDate
+		balance.ToDate = period.EndDate
+	} else if asOf != nil {
+		balance.ToDate = *asOf
+	}
+
+	rows, err := s.journalRepo.TrialBalance(ctx, tenantID, balance.FromDate, balance.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	balance.Rows = rows
+
+	var totalDebit, totalCredit float64
+	for _, row := range rows {
+		totalDebit += row.TotalDebit
+		totalCredit += row.TotalCredit
+	}
+	balance.TotalDebit = roundMoney(totalDebit)
+	balance.TotalCredit = roundMoney(totalCredit)
+	balance.IsBalanced = toCents(balance.TotalDebit) == toCents(balance.TotalCredit)
+
+	return balance, nil
+}
+
+// openPeriodFor finds the period covering date and checks that it is open
+func (s *AccountingService) openPeriodFor(ctx context.Context, tenantID uuid.UUID, date time.Time) (*models.AccountingPeriod, error) {
+	period, err := s.periodRepo.FindForDate(ctx, tenantID, date)
+	if err != nil {
+		return nil, err
+	}
+	if !period.IsOpen() {
+		return nil, utils.NewBadRequestError("PERIOD_CLOSED", "accounting period is closed")
+	}
+	return period, nil
+}
+
+// validateLineAccounts checks that every line references an active account
+func (s *AccountingService) validateLineAccounts(ctx context.Context, tenantID uuid.UUID, lines []models.JournalEntryLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.AccountID)
+	}
+
+	accounts, err := s.accountRepo.FindByIDs(ctx, tenantID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		account, ok := accounts[line.AccountID]
+		if !ok {
+			return utils.NewNotFoundError("ACCOUNT_NOT_FOUND", "account not found")
+		}
+		if !account.IsActive {
+			return utils.NewBadRequestError("ACCOUNT_INACTIVE", fmt.Sprintf("account %s is inactive", account.Code))
+		}
+	}
+
+	return nil
+}
+
+// validateDoubleEntry checks that an entry can be posted: at least two lines,
+// each either a debit or a credit, and debits equal to credits
+func validateDoubleEntry(entry *models.JournalEntry) error {
+	if len(entry.Lines) < 2 {
+		return utils.NewBadRequestError("JOURNAL_ENTRY_TOO_FEW_LINES", "journal entry needs at least two lines")
+	}
+
+	var debit, credit int64
+	for _, line := range entry.Lines {
+		d, c := toCents(line.Debit), toCents(line.Credit)
+		if d < 0 || c < 0 || (d == 0) == (c == 0) {
+			return utils.NewBadRequestError("INVALID_JOURNAL_LINE", "each line must have either a debit or a credit amount")
+		}
+		debit += d
+		credit += c
+	}
+
+	if debit != credit {
+		return utils.NewBadRequestError("JOURNAL_ENTRY_NOT_BALANCED", fmt.Sprintf("journal entry is not balanced: debits %.2f, credits %.2f", float64(debit)/100, float64(credit)/100))
+	}
+
+	return nil
+}
+
+// buildJournalLines converts request lines into journal entry lines
+func buildJournalLines(reqLines []models.JournalLineRequest) []models.JournalEntryLine {
+	lines := make([]models.JournalEntryLine, len(reqLines))
+	for i, l := range reqLines {
+		lines[i] = models.JournalEntryLine{
+			AccountID:   l.AccountID,
+			Description: l.Description,
+			Debit:       roundMoney(l.Debit),
+			Credit:      roundMoney(l.Credit),
+		}
+	}
+	return lines
+}
+
+// applyJournalTotals computes the entry's debit and credit totals
+func applyJournalTotals(entry *models.JournalEntry) {
+	var debit, credit int64
+	for _, line := range entry.Lines {
+		debit += toCents(line.Debit)
+		credit += toCents(line.Credit)
+	}
+	entry.TotalDebit = float64(debit) / 100
+	entry.TotalCredit = float64(credit) / 100
+}
+
+// toCents converts an amount to integer cents so sums compare exactly
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
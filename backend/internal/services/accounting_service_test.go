@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+func TestValidateDoubleEntry(t *testing.T) {
+	debit := func(amount float64) models.JournalEntryLine { return models.JournalEntryLine{Debit: amount} }
+	credit := func(amount float64) models.JournalEntryLine { return models.JournalEntryLine{Credit: amount} }
+
+	tests := []struct {
+		name     string
+		lines    []models.JournalEntryLine
+		wantCode string
+	}{
+		{
+			name:  "Balanced",
+			lines: []models.JournalEntryLine{debit(100), credit(60), credit(40)},
+		},
+		{
+			// 0.1 + 0.2 is not 0.3 in floating point, but is in cents
+			name:  "Balanced in cents",
+			lines: []models.JournalEntryLine{debit(0.1), debit(0.2), credit(0.3)},
+		},
+		{
+			name:     "Unbalanced by a cent",
+			lines:    []models.JournalEntryLine{debit(100), credit(99.99)},
+			wantCode: "JOURNAL_ENTRY_NOT_BALANCED",
+		},
+		{
+			name:     "Line with both debit and credit",
+			lines:    []models.JournalEntryLine{{Debit: 50, Credit: 50}, debit(10), credit(10)},
+			wantCode: "INVALID_JOURNAL_LINE",
+		},
+		{
+			name:     "Line with neither debit nor credit",
+			lines:    []models.JournalEntryLine{debit(10), credit(10), {}},
+			wantCode: "INVALID_JOURNAL_LINE",
+		},
+		{
+			name:     "Amount rounding to zero",
+			lines:    []models.JournalEntryLine{debit(10), credit(10), debit(0.004)},
+			wantCode: "INVALID_JOURNAL_LINE",
+		},
+		{
+			name:     "Negative amount",
+			lines:    []models.JournalEntryLine{debit(-10), credit(-10)},
+			wantCode: "INVALID_JOURNAL_LINE",
+		},
+		{
+			name:     "Single line",
+			lines:    []models.JournalEntryLine{debit(10)},
+			wantCode: "JOURNAL_ENTRY_TOO_FEW_LINES",
+		},
+		{
+			name:     "No lines",
+			wantCode: "JOURNAL_ENTRY_TOO_FEW_LINES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDoubleEntry(&models.JournalEntry{Lines: tt.lines})
+
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var domainErr *utils.DomainError
+			require.True(t, errors.As(err, &domainErr), "expected a domain error, got %v", err)
+			assert.Equal(t, tt.wantCode, domainErr.Code)
+			assert.True(t, errors.Is(err, utils.ErrBadRequest))
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// announcementBatchSize is how many published announcements are notified
+// per database per run of the announcements job
+const announcementBatchSize = 50
+
+// announcementPreviewLength bounds the part of an announcement's body shown
+// in its notification
+const announcementPreviewLength = 280
+
+// AnnouncementService manages announcements from admins to a tenant's users.
+// Users see the published announcements addressed to them; the announcements
+// job (PublishDue) adds them to their audience's notifications once their
+// publish time has come.
+type AnnouncementService struct {
+	db               *sqlx.DB
+	announcementRepo repository.AnnouncementStore
+	roleRepo         repository.RoleStore
+	departmentRepo   repository.DepartmentStore
+	notifier         NotificationManager
+}
+
+// NewAnnouncementService creates a new announcement service
+func NewAnnouncementService(
+	db *sqlx.DB,
+	announcementRepo repository.AnnouncementStore,
+	roleRepo repository.RoleStore,
+	departmentRepo repository.DepartmentStore,
+	notifier NotificationManager,
+) *AnnouncementService {
+	return &AnnouncementService{
+		db:               db,
+		announcementRepo: announcementRepo,
+		roleRepo:         roleRepo,
+		departmentRepo:   departmentRepo,
+		notifier:         notifier,
+	}
+}
+
+// CreateAnnouncement posts an announcement, published right away unless it
+// is scheduled for later
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, tenantID, userID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	announcement := &models.Announcement{
+		TenantID:  tenantID,
+		CreatedBy: userID,
+	}
+	if err := s.apply(ctx, announcement, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	return s.announcementRepo.FindByID(ctx, tenantID, announcement.ID)
+}
+
+// UpdateAnnouncement replaces an announcement's content, audience and
+// schedule
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	announcement, err := s.announcementRepo.FindByID(ctx, tenantID, announcementID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, announcement, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.announcementRepo.Update(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	return s.announcementRepo.FindByID(ctx, tenantID, announcementID)
+}
+
+// DeleteAnnouncement deletes an announcement. Notifications already added
+// for it are kept.
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID) error {
+	return s.announcementRepo.Delete(ctx, tenantID, announcementID)
+}
+
+// GetAnnouncement retrieves an announcement
+func (s *AnnouncementService) GetAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error) {
+	return s.announcementRepo.FindByID(ctx, tenantID, announcementID)
+}
+
+// ListAnnouncements lists a tenant's announcements, optionally only those
+// with a status
+func (s *AnnouncementService) ListAnnouncements(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error) {
+	return s.announcementRepo.List(ctx, tenantID, status, limit, offset)
+}
+
+// ListReads lists the users who read an announcement
+func (s *AnnouncementService) ListReads(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error) {
+	if _, err := s.announcementRepo.FindByID(ctx, tenantID, announcementID); err != nil {
+		return nil, 0, err
+	}
+	return s.announcementRepo.ListReads(ctx, tenantID, announcementID, limit, offset)
+}
+
+// ListForUser lists the published announcements addressed to a user
+func (s *AnnouncementService) ListForUser(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error) {
+	return s.announcementRepo.ListForUser(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// MarkRead records that a user read an announcement addressed to them
+func (s *AnnouncementService) MarkRead(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error {
+	return s.announcementRepo.MarkRead(ctx, tenantID, userID, announcementID)
+}
+
+// PublishDue notifies the audiences of announcements whose publish time has
+// come, in every data region. It returns the number of announcements
+// notified.
+func (s *AnnouncementService) PublishDue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		if err := ctx.Err(); err != nil {
+			return total, nil
+		}
+
+		published, err := s.publishBatch(ctx, db)
+		total += published
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// publishBatch claims a batch of published announcements and resolves their
+// audiences in one transaction, then notifies the audiences. Announcements
+// are claimed before they are notified, so a failed notification is logged
+// rather than repeated.
+func (s *AnnouncementService) publishBatch(ctx context.Context, db *sqlx.DB) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	announcements, err := s.announcementRepo.ClaimPublished(ctx, tx, announcementBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	audiences := make([][]uuid.UUID, len(announcements))
+	for i := range announcements {
+		audiences[i], err = s.announcementRepo.ListAudienceIDs(ctx, tx, &announcements[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit announcements: %w", err)
+	}
+
+	for i := range announcements {
+		announcement := &announcements[i]
+		if len(audiences[i]) == 0 {
+			continue
+		}
+
+		if err := s.notifier.Notify(ctx, announcement.TenantID, audiences[i], &models.NotificationRequest{
+			Type:  models.NotificationTypeAnnouncement,
+			Title: announcement.Title,
+			Body:  announcementPreview(announcement.Body),
+			Link:  "/announcements",
+			Data: map[string]interface{}{
+				"announcement_id": announcement.ID,
+			},
+		}); err != nil {
+			log.Printf("⚠️  Failed to notify the audience of announcement %s of tenant %s: %v", announcement.ID, announcement.TenantID, err)
+		}
+	}
+
+	return len(announcements), nil
+}
+
+// apply sets an announcement's content, audience and schedule from a
+// request, checking that the targeted roles and departments exist
+func (s *AnnouncementService) apply(ctx context.Context, announcement *models.Announcement, req *models.AnnouncementRequest) error {
+	publishAt := time.Now()
+	if req.PublishAt != nil {
+		publishAt = *req.PublishAt
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(publishAt) {
+		return utils.NewValidationError("INVALID_EXPIRY", "announcement must expire after it is published")
+	}
+
+	roleIDs := []string{}
+	departmentIDs := []string{}
+	switch req.Audience {
+	case models.AnnouncementAudienceRoles:
+		if len(req.RoleIDs) == 0 {
+			return utils.NewValidationError("AUDIENCE_REQUIRED", "role_ids are required for a roles audience")
+		}
+		for _, roleID := range req.RoleIDs {
+			if _, err := s.roleRepo.FindByID(ctx, announcement.TenantID, roleID); err != nil {
+				if errors.Is(err, utils.ErrNotFound) {
+					return utils.NewValidationError("ROLE_NOT_FOUND", fmt.Sprintf("role %s not found", roleID))
+				}
+				return err
+			}
+			roleIDs = append(roleIDs, roleID.String())
+		}
+	case models.AnnouncementAudienceDepartments:
+		if len(req.DepartmentIDs) == 0 {
+			return utils.NewValidationError("AUDIENCE_REQUIRED", "department_ids are required for a departments audience")
+		}
+		for _, departmentID := range req.DepartmentIDs {
+			if _, err := s.departmentRepo.FindByID(ctx, announcement.TenantID, departmentID); err != nil {
+				if errors.Is(err, utils.ErrNotFound) {
+					return utils.NewValidationError("DEPARTMENT_NOT_FOUND", fmt.Sprintf("department %s not found", departmentID))
+				}
+				return err
+			}
+			departmentIDs = append(departmentIDs, departmentID.String())
+		}
+	}
+
+	announcement.Title = strings.TrimSpace(req.Title)
+	announcement.Body = strings.TrimSpace(req.Body)
+	announcement.Audience = req.Audience
+	announcement.RoleIDs = roleIDs
+	announcement.DepartmentIDs = departmentIDs
+	announcement.PublishAt = publishAt
+	announcement.ExpiresAt = req.ExpiresAt
+
+	return nil
+}
+
+// announcementPreview shortens an announcement's body for its notification
+func announcementPreview(body string) string {
+	runes := []rune(body)
+	if len(runes) <= announcementPreviewLength {
+		return body
+	}
+	return strings.TrimSpace(string(runes[:announcementPreviewLength])) + "…"
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Approval link audit actions
+const (
+	approvalAuditLinksSent   = "approval.links_sent"
+	approvalAuditLinkUsed    = "approval.link_used"
+	approvalAuditLinkRefused = "approval.link_refused"
+)
+
+// approvalLinkMaxFailedAttempts is how many wrong verification codes revoke
+// a link
+const approvalLinkMaxFailedAttempts = 5
+
+// ApprovalDescriber describes a pending approval for emails and link pages
+type ApprovalDescriber func(ctx context.Context, tenantID, resourceID uuid.UUID) (*models.ApprovalSubject, error)
+
+// ApprovalDecider applies an approver's decision
+type ApprovalDecider func(ctx context.Context, tenantID, userID, resourceID uuid.UUID, decision string) error
+
+// approvalTarget is a resource type that can be approved through links
+type approvalTarget struct {
+	resource string // Permission resource whose approve action makes a user an approver
+	describe ApprovalDescriber
+	decide   ApprovalDecider
+}
+
+// ApprovalLinkService lets approvers decide from their inbox. Approvers are
+// emailed one-time Approve/Reject links signed with the encryption key;
+// decisions on amounts above the step-up threshold also need the approver's
+// authenticator code, or signing in when they have no 2FA. Every use of a
+// link, successful or not, is audited.
+type ApprovalLinkService struct {
+	db                *sqlx.DB
+	linkRepo          repository.ApprovalLinkStore
+	userRepo          repository.UserStore
+	userRoleRepo      repository.UserRoleStore
+	tenantRepo        repository.TenantStore
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	twoFactorService  TwoFactorManager
+	permissionService PermissionManager
+	auditService      AuditManager
+	config            *config.Config
+	targets           map[string]approvalTarget
+}
+
+// NewApprovalLinkService creates a new approval link service
+func NewApprovalLinkService(
+	db *sqlx.DB,
+	linkRepo repository.ApprovalLinkStore,
+	userRepo repository.UserStore,
+	userRoleRepo repository.UserRoleStore,
+	tenantRepo repository.TenantStore,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	twoFactorService TwoFactorManager,
+	permissionService PermissionManager,
+	auditService AuditManager,
+	cfg *config.Config,
+) *ApprovalLinkService {
+	return &ApprovalLinkService{
+		db:                db,
+		linkRepo:          linkRepo,
+		userRepo:          userRepo,
+		userRoleRepo:      userRoleRepo,
+		tenantRepo:        tenantRepo,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		twoFactorService:  twoFactorService,
+		permissionService: permissionService,
+		auditService:      auditService,
+		config:            cfg,
+		targets:           make(map[string]approvalTarget),
+	}
+}
+
+// RegisterTarget registers a resource type that can be approved through
+// links. Users holding the approve permission on resource are its approvers.
+func (s *ApprovalLinkService) RegisterTarget(resourceType, resource string, describe ApprovalDescriber, decide ApprovalDecider) {
+	s.targets[resourceType] = approvalTarget{resource: resource, describe: describe, decide: decide}
+}
+
+// RequestApproval emails every approver of a pending approval their own
+// Approve/Reject links
+func (s *ApprovalLinkService) RequestApproval(ctx context.Context, tenantID, requestedBy uuid.UUID, resourceType string, resourceID uuid.UUID) error {
+	target, ok := s.targets[resourceType]
+	if !ok {
+		return fmt.Errorf("unsupported approval type: %s", resourceType)
+	}
+
+	subject, err := target.describe(ctx, tenantID, resourceID)
+	if err != nil {
+		return err
+	}
+	if !subject.Pending {
+		return nil
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	approvers, err := s.userRoleRepo.GetUsersWithPermission(ctx, tenantID, target.resource, models.ActionApprove)
+	if err != nil {
+		return err
+	}
+
+	stepUp := s.aboveStepUpAmount(subject)
+	sent := 0
+	for _, approver := range approvers {
+		link := &models.ApprovalLink{
+			TenantID:     tenantID,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			ApproverID:   approver.ID,
+			ExpiresAt:    time.Now().Add(s.config.Approvals.LinkTTL),
+		}
+		if err := s.linkRepo.Create(ctx, link); err != nil {
+			return err
+		}
+
+		msg, err := s.emailService.ApprovalRequestEmail(
+			approver.Email,
+			approver.Language,
+			approver.FirstName,
+			tenant.CompanyName,
+			subject.Title,
+			subject.Details,
+			s.emailService.ApprovalLinkURL(tenant.Slug, s.sign(link, models.ApprovalDecisionApprove), models.ApprovalDecisionApprove),
+			s.emailService.ApprovalLinkURL(tenant.Slug, s.sign(link, models.ApprovalDecisionReject), models.ApprovalDecisionReject),
+			s.config.App.FrontendURL+subject.ReviewURL,
+			stepUp,
+			link.ExpiresAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := s.emailQueueService.EnqueueStandalone(ctx, tenantID, msg); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, requestedBy, approvalAuditLinksSent, resourceType, resourceID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"approvers":        sent,
+		"step_up_required": stepUp,
+	})
+
+	return nil
+}
+
+// Preview describes what a link decides and the verification it needs,
+// without using it
+func (s *ApprovalLinkService) Preview(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest) (*models.ApprovalLinkPreview, error) {
+	linkID, err := s.verify(tenantID, req.Token, req.Decision)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.FindByID(ctx, tenantID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsUsable() {
+		return nil, fmt.Errorf("approval link has expired or was already used")
+	}
+
+	target, ok := s.targets[link.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported approval type: %s", link.ResourceType)
+	}
+
+	subject, err := target.describe(ctx, tenantID, link.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if !subject.Pending {
+		return nil, fmt.Errorf("approval was already decided")
+	}
+
+	stepUp, err := s.stepUpFor(ctx, tenantID, link, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	subject.ReviewURL = s.config.App.FrontendURL + subject.ReviewURL
+
+	return &models.ApprovalLinkPreview{
+		Subject:   subject,
+		Decision:  req.Decision,
+		StepUp:    stepUp,
+		ExpiresAt: link.ExpiresAt,
+	}, nil
+}
+
+// Decide uses a link to apply its decision in the approver's name. The link
+// stays locked while deciding, so it cannot be used twice.
+func (s *ApprovalLinkService) Decide(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest, ipAddress, userAgent string) (*models.ApprovalSubject, error) {
+	linkID, err := s.verify(tenantID, req.Token, req.Decision)
+	if err != nil {
+		s.auditService.LogEvent(ctx, tenantID, uuid.Nil, approvalAuditLinkRefused, models.AuditResourceApprovalLinks, uuid.Nil, models.AuditStatusFailure, ipAddress, userAgent, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	tx, link, err := s.linkRepo.Lock(ctx, tenantID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// A refusal is recorded before returning, committing the link's
+	// failed attempts with it
+	refuse := func(err error) (*models.ApprovalSubject, error) {
+		if commitErr := tx.Commit(); commitErr != nil {
+			log.Printf("⚠️  Failed to record approval link attempt %s: %v", link.ID, commitErr)
+		}
+		s.auditService.LogEvent(ctx, tenantID, link.ApproverID, approvalAuditLinkRefused, link.ResourceType, link.ResourceID, models.AuditStatusFailure, ipAddress, userAgent, map[string]interface{}{
+			"link_id":  link.ID,
+			"decision": req.Decision,
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	if !link.IsUsable() {
+		return refuse(fmt.Errorf("approval link has expired or was already used"))
+	}
+
+	target, ok := s.targets[link.ResourceType]
+	if !ok {
+		return refuse(fmt.Errorf("unsupported approval type: %s", link.ResourceType))
+	}
+
+	subject, err := target.describe(ctx, tenantID, link.ResourceID)
+	if err != nil {
+		return refuse(err)
+	}
+	if !subject.Pending {
+		if err := s.linkRepo.Revoke(ctx, tx.Tx, link); err != nil {
+			return nil, err
+		}
+		return refuse(fmt.Errorf("approval was already decided"))
+	}
+
+	// The approver may have lost the permission since the link was sent
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, link.ApproverID, target.resource, models.ActionApprove)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return refuse(fmt.Errorf("approver is no longer allowed to decide"))
+	}
+
+	stepUp, err := s.stepUpFor(ctx, tenantID, link, subject)
+	if err != nil {
+		return refuse(err)
+	}
+
+	switch stepUp {
+	case models.ApprovalStepUpLogin:
+		return refuse(fmt.Errorf("sign in to decide on this approval"))
+
+	case models.ApprovalStepUpTOTP:
+		if strings.TrimSpace(req.Code) == "" {
+			return refuse(fmt.Errorf("verification code required"))
+		}
+		valid, err := s.twoFactorService.VerifyTOTP(ctx, tenantID, link.ApproverID, req.Code)
+		if err != nil {
+			return refuse(err)
+		}
+		if !valid {
+			revoked, err := s.linkRepo.RecordFailedAttempt(ctx, tx.Tx, link, approvalLinkMaxFailedAttempts)
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				return refuse(fmt.Errorf("too many invalid verification codes, the link was revoked"))
+			}
+			return refuse(utils.NewBadRequestError("INVALID_VERIFICATION_CODE", "invalid verification code"))
+		}
+	}
+
+	if err := target.decide(ctx, tenantID, link.ApproverID, link.ResourceID, req.Decision); err != nil {
+		return refuse(err)
+	}
+
+	// The decision is made: record it even if the request goes away
+	ctx = context.WithoutCancel(ctx)
+
+	if err := s.linkRepo.MarkUsed(ctx, tx.Tx, link, req.Decision, stepUp, ipAddress, userAgent); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to record approval link use: %w", err)
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, link.ApproverID, approvalAuditLinkUsed, link.ResourceType, link.ResourceID, models.AuditStatusSuccess, ipAddress, userAgent, map[string]interface{}{
+		"link_id":  link.ID,
+		"decision": req.Decision,
+		"step_up":  stepUp,
+	})
+
+	subject, err = target.describe(ctx, tenantID, link.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	subject.ReviewURL = s.config.App.FrontendURL + subject.ReviewURL
+
+	return subject, nil
+}
+
+// CleanupExpired removes links that expired more than the link lifetime ago,
+// in every data region. Decisions made with them stay in the audit log.
+func (s *ApprovalLinkService) CleanupExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Approvals.LinkTTL)
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.linkRepo.DeleteExpiredBefore(ctx, db, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// stepUpFor returns the verification a link needs for subject
+func (s *ApprovalLinkService) stepUpFor(ctx context.Context, tenantID uuid.UUID, link *models.ApprovalLink, subject *models.ApprovalSubject) (string, error) {
+	if !s.aboveStepUpAmount(subject) {
+		return models.ApprovalStepUpNone, nil
+	}
+
+	approver, err := s.userRepo.FindByID(ctx, tenantID, link.ApproverID)
+	if err != nil {
+		return "", err
+	}
+	if !approver.TwoFactorEnabled {
+		return models.ApprovalStepUpLogin, nil
+	}
+	return models.ApprovalStepUpTOTP, nil
+}
+
+// aboveStepUpAmount reports whether subject's amount needs step-up
+// verification
+func (s *ApprovalLinkService) aboveStepUpAmount(subject *models.ApprovalSubject) bool {
+	threshold := s.config.Approvals.StepUpAmount
+	return threshold > 0 && subject.Amount >= threshold
+}
+
+// sign builds the token of a link for one decision. The signature binds the
+// tenant, link and decision, so an Approve link cannot be turned into a
+// Reject one or replayed in another tenant.
+func (s *ApprovalLinkService) sign(link *models.ApprovalLink, decision string) string {
+	return link.ID.String() + "." + s.signature(link.TenantID, link.ID, decision)
+}
+
+// signature computes the HMAC-SHA256 of a link's decision
+func (s *ApprovalLinkService) signature(tenantID, linkID uuid.UUID, decision string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Security.EncryptionKey))
+	mac.Write([]byte("approval-link:" + tenantID.String() + ":" + linkID.String() + ":" + decision))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a token's signature for decision and returns its link ID
+func (s *ApprovalLinkService) verify(tenantID uuid.UUID, token, decision string) (uuid.UUID, error) {
+	if decision != models.ApprovalDecisionApprove && decision != models.ApprovalDecisionReject {
+		return uuid.Nil, fmt.Errorf("invalid approval link")
+	}
+
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, fmt.Errorf("invalid approval link")
+	}
+	linkID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid approval link")
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(s.signature(tenantID, linkID, decision))) {
+		return uuid.Nil, fmt.Errorf("invalid approval link")
+	}
+
+	return linkID, nil
+}
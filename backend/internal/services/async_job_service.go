@@ -0,0 +1,446 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Async job audit actions
+const (
+	asyncJobAuditQueued    = "job.queued"
+	asyncJobAuditCancelled = "job.cancelled"
+)
+
+const (
+	asyncJobEventsKeyPrefix   = "jobs:events"
+	asyncJobCancelCheckPeriod = 2 * time.Second // How often running jobs are checked for cancellation
+)
+
+// ErrJobCancelled is returned by JobProgress.Update once a user has cancelled
+// the job; job functions should stop and return it
+var ErrJobCancelled = errors.New("job cancelled")
+
+// AsyncJobFunc executes a job. It reports progress through progress, must
+// stop when ctx is cancelled, and returns the job's result payload, which is
+// stored as JSON.
+type AsyncJobFunc func(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error)
+
+// asyncJobType is a kind of job modules can queue
+type asyncJobType struct {
+	resource string // Permission resource whose view permission allows following any job of the type
+	run      AsyncJobFunc
+}
+
+// AsyncJobService runs long-running work (imports, exports, reports,
+// archival) in the background. Modules register their job types and queue
+// jobs; the async job worker (ProcessQueue) executes them, recording progress,
+// results and errors. Progress is published on Redis so every replica can
+// stream it to clients.
+type AsyncJobService struct {
+	db                *sqlx.DB
+	redis             *redis.Client
+	jobRepo           repository.AsyncJobStore
+	permissionService PermissionManager
+	auditService      AuditManager
+	config            *config.JobsConfig
+	queueConfig       *config.QueueConfig
+	types             map[string]asyncJobType
+}
+
+// NewAsyncJobService creates a new async job service
+func NewAsyncJobService(
+	db *sqlx.DB,
+	redisClient *redis.Client,
+	jobRepo repository.AsyncJobStore,
+	permissionService PermissionManager,
+	auditService AuditManager,
+	cfg *config.JobsConfig,
+	queueConfig *config.QueueConfig,
+) *AsyncJobService {
+	return &AsyncJobService{
+		db:                db,
+		redis:             redisClient,
+		jobRepo:           jobRepo,
+		permissionService: permissionService,
+		auditService:      auditService,
+		config:            cfg,
+		queueConfig:       queueConfig,
+		types:             make(map[string]asyncJobType),
+	}
+}
+
+// RegisterType registers how a job type is executed. Users holding the view
+// permission on resource may follow any job of that type; the requester can
+// always follow and cancel their own.
+func (s *AsyncJobService) RegisterType(jobType, resource string, run AsyncJobFunc) {
+	s.types[jobType] = asyncJobType{resource: resource, run: run}
+}
+
+// Submit queues a job for the worker. params is stored as JSON and handed
+// back to the job function in job.Params.
+func (s *AsyncJobService) Submit(ctx context.Context, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error) {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	job, err := s.Enqueue(ctx, tx.Tx, tenantID, userID, jobType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, asyncJobAuditQueued, s.types[jobType].resource, job.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"job_type": jobType,
+	})
+
+	return job, nil
+}
+
+// Enqueue queues a job within the caller's tenant transaction, so it is only
+// run if the transaction commits. The caller audits the action that queued it.
+// Fails with a JOB_QUEUE_FULL error once the tenant has QUEUE_ASYNC_MAX_QUEUED
+// jobs waiting.
+func (s *AsyncJobService) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error) {
+	if _, ok := s.types[jobType]; !ok {
+		return nil, utils.NewBadRequestError("UNSUPPORTED_JOB_TYPE", fmt.Sprintf("unsupported job type: %s", jobType))
+	}
+
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job parameters: %w", err)
+	}
+
+	job := &models.AsyncJob{
+		TenantID:    tenantID,
+		JobType:     jobType,
+		Params:      encodedParams,
+		RequestedBy: userID,
+	}
+	if err := s.jobRepo.Create(ctx, tx, job, s.queueConfig.AsyncMaxQueued); err != nil {
+		if errors.Is(err, utils.ErrTooManyRequests) {
+			metrics.ObserveQueueRejected(models.QueueAsyncJob, 1)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ListJobs lists the jobs a user started
+func (s *AsyncJobService) ListJobs(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error) {
+	return s.jobRepo.ListByRequester(ctx, tenantID, userID, jobType, status, limit, offset)
+}
+
+// GetJob retrieves a job the user may follow
+func (s *AsyncJobService) GetJob(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	job, err := s.jobRepo.FindByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.RequestedBy != userID {
+		jobTypeDef, ok := s.types[job.JobType]
+		if !ok {
+			return nil, utils.NewForbiddenError("JOB_FORBIDDEN", "not allowed to access this job")
+		}
+		allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, jobTypeDef.resource, models.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("JOB_FORBIDDEN", "not allowed to access this job")
+		}
+	}
+
+	return job, nil
+}
+
+// Cancel stops a job. A queued job is cancelled right away; a running job is
+// stopped by the worker at its next progress update or cancellation check.
+func (s *AsyncJobService) Cancel(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	job, err := s.jobRepo.FindByID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.RequestedBy != userID {
+		return nil, utils.NewForbiddenError("JOB_FORBIDDEN", "only the user who started a job can cancel it")
+	}
+
+	job, err = s.jobRepo.RequestCancel(ctx, tenantID, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, job)
+
+	s.auditService.LogEvent(ctx, tenantID, userID, asyncJobAuditCancelled, s.types[job.JobType].resource, job.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"job_type": job.JobType,
+		"status":   job.Status,
+	})
+
+	return job, nil
+}
+
+// Subscribe listens for updates of a job. Each message is the job encoded as
+// JSON. The caller must close the subscription.
+func (s *AsyncJobService) Subscribe(ctx context.Context, tenantID, jobID uuid.UUID) *redis.PubSub {
+	return s.redis.Subscribe(ctx, asyncJobEventsChannel(tenantID, jobID))
+}
+
+// ProcessQueue executes queued jobs, up to AsyncConcurrency at a time, until
+// the queue is empty. It is run periodically by the background job runner and
+// returns the number of jobs executed.
+func (s *AsyncJobService) ProcessQueue(ctx context.Context) (int, error) {
+	// The runner lets one worker run at a time, so anything still running was
+	// left behind by a worker that stopped
+	for _, db := range database.RegionDBs(s.db) {
+		interrupted, err := s.jobRepo.FailInterrupted(ctx, db)
+		if err != nil {
+			return 0, err
+		}
+		if interrupted > 0 {
+			log.Printf("⚠️  Failed %d async jobs interrupted by a worker restart", interrupted)
+		}
+	}
+
+	slots := make(chan struct{}, s.config.AsyncConcurrency)
+	var wg sync.WaitGroup
+	var claimErr error
+	total := 0
+
+	for ctx.Err() == nil {
+		slots <- struct{}{}
+
+		job, err := s.claimNext(ctx)
+		if err != nil || job == nil {
+			<-slots
+			claimErr = err
+			break
+		}
+
+		total++
+		wg.Add(1)
+		go func(job *models.AsyncJob) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.execute(ctx, job)
+		}(job)
+	}
+
+	wg.Wait()
+	return total, claimErr
+}
+
+// CleanupFinishedJobs removes jobs that finished longer ago than the
+// retention period, in every data region
+func (s *AsyncJobService) CleanupFinishedJobs(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.AsyncRetention)
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.jobRepo.DeleteFinishedBefore(ctx, db, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// claimNext claims the oldest queued job in any data region
+func (s *AsyncJobService) claimNext(ctx context.Context) (*models.AsyncJob, error) {
+	for _, db := range database.RegionDBs(s.db) {
+		job, err := s.jobRepo.ClaimNext(ctx, db, jobs.PausedTenants(ctx))
+		if err != nil || job != nil {
+			return job, err
+		}
+	}
+	return nil, nil
+}
+
+// execute runs a claimed job and records its outcome
+func (s *AsyncJobService) execute(ctx context.Context, job *models.AsyncJob) {
+	s.publish(ctx, job)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := &JobProgress{service: s, job: job, cancel: cancel}
+	go s.watchCancellation(jobCtx, progress)
+
+	result, runErr := s.run(jobCtx, job, progress)
+
+	// Record the outcome even if the worker is shutting down
+	outcomeCtx := context.WithoutCancel(ctx)
+
+	status := models.AsyncJobStatusSucceeded
+	var encodedResult json.RawMessage
+	cause := ""
+	if runErr == nil && result != nil {
+		encodedResult, runErr = json.Marshal(result)
+		if runErr != nil {
+			runErr = fmt.Errorf("failed to encode job result: %w", runErr)
+		}
+	}
+	if runErr != nil {
+		switch {
+		case progress.isCancelled() || errors.Is(runErr, ErrJobCancelled):
+			status = models.AsyncJobStatusCancelled
+		case ctx.Err() != nil:
+			status, cause = models.AsyncJobStatusFailed, "interrupted: the worker stopped while the job was running"
+		default:
+			status, cause = models.AsyncJobStatusFailed, runErr.Error()
+		}
+		if status == models.AsyncJobStatusFailed {
+			metrics.ObserveDeadLetter(models.QueueAsyncJob)
+			log.Printf("⚠️  Async job %s (%s) failed: %v", job.ID, job.JobType, runErr)
+		}
+	}
+
+	if err := s.jobRepo.Finish(outcomeCtx, job, status, encodedResult, cause); err != nil {
+		log.Printf("⚠️  Async job %s: %v", job.ID, err)
+		return
+	}
+
+	if finished, err := s.jobRepo.FindByID(outcomeCtx, job.TenantID, job.ID); err == nil {
+		s.publish(outcomeCtx, finished)
+	}
+}
+
+// run calls the job's function, turning a panic into a job failure
+func (s *AsyncJobService) run(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (result interface{}, err error) {
+	jobTypeDef, ok := s.types[job.JobType]
+	if !ok {
+		return nil, utils.NewBadRequestError("UNSUPPORTED_JOB_TYPE", fmt.Sprintf("unsupported job type: %s", job.JobType))
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	return jobTypeDef.run(ctx, job, progress)
+}
+
+// watchCancellation cancels a running job's context once a user has asked to
+// cancel it, for job functions that report progress rarely
+func (s *AsyncJobService) watchCancellation(ctx context.Context, progress *JobProgress) {
+	ticker := time.NewTicker(asyncJobCancelCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requested, err := s.jobRepo.IsCancelRequested(ctx, progress.job.TenantID, progress.job.ID)
+			if err == nil && requested {
+				progress.markCancelled()
+				return
+			}
+		}
+	}
+}
+
+// publish sends a job's current state to subscribers. Delivery is best
+// effort; clients can always fall back to GET /jobs/{id}.
+func (s *AsyncJobService) publish(ctx context.Context, job *models.AsyncJob) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Publish(ctx, asyncJobEventsChannel(job.TenantID, job.ID), payload).Err(); err != nil {
+		log.Printf("⚠️  Failed to publish async job %s update: %v", job.ID, err)
+	}
+}
+
+// asyncJobEventsChannel is the Redis channel a job's updates are published on
+func asyncJobEventsChannel(tenantID, jobID uuid.UUID) string {
+	return database.CacheKey(asyncJobEventsKeyPrefix, tenantID.String(), jobID.String())
+}
+
+// JobProgress lets a running job report how far along it is
+type JobProgress struct {
+	service *AsyncJobService
+	job     *models.AsyncJob
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	cancelled bool
+}
+
+// Update records the job's progress (percent complete, clamped to 0-99 until
+// the job succeeds) and an optional status message. It returns
+// ErrJobCancelled once a user has cancelled the job, or ctx's error when the
+// worker is stopping; the job function should then return that error.
+func (p *JobProgress) Update(ctx context.Context, percent int, message string) error {
+	if p.isCancelled() {
+		return ErrJobCancelled
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 99 {
+		percent = 99
+	}
+
+	cancelRequested, err := p.service.jobRepo.UpdateProgress(ctx, p.job, percent, message)
+	if err != nil {
+		return err
+	}
+	if cancelRequested {
+		p.markCancelled()
+		return ErrJobCancelled
+	}
+
+	p.job.Progress = percent
+	if message != "" {
+		p.job.ProgressMessage = &message
+	} else {
+		p.job.ProgressMessage = nil
+	}
+	p.service.publish(ctx, p.job)
+
+	return nil
+}
+
+// markCancelled stops the job after a user cancelled it
+func (p *JobProgress) markCancelled() {
+	p.mu.Lock()
+	p.cancelled = true
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// isCancelled reports whether the job was cancelled by a user
+func (p *JobProgress) isCancelled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cancelled
+}
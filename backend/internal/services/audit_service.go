@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,6 +14,8 @@ import (
 	"github.com/jmoiron/sqlx"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/utils"
 )
 
 // AuditService handles security audit logging and querying
@@ -54,15 +59,24 @@ func (s *AuditService) LogEvent(
 
 	metadataJSON, _ := json.Marshal(metadata)
 
+	// System events and failed public requests have no user or resource
+	var actor, resource interface{}
+	if userID != uuid.Nil {
+		actor = userID
+	}
+	if resourceID != uuid.Nil {
+		resource = resourceID
+	}
+
 	query := `
 		INSERT INTO audit_logs (
 			tenant_id, user_id, action, resource_type, resource_id,
 			status, ip_address, user_agent, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::inet, NULLIF($8, ''), $9)
 	`
 
 	_, err = tx.ExecContext(ctx, query,
-		tenantID, userID, action, resourceType, resourceID,
+		tenantID, actor, action, resourceType, resource,
 		status, ipAddress, userAgent, metadataJSON,
 	)
 	if err != nil {
@@ -72,94 +86,128 @@ func (s *AuditService) LogEvent(
 	return tx.Commit()
 }
 
-// Query retrieves audit logs with filters
+// AuditListSpec lists the fields audit logs can be sorted by
+var AuditListSpec = pagination.Spec{
+	Columns: map[string]string{
+		"created_at": "created_at",
+		"action":     "action",
+		"status":     "status",
+	},
+	Default:  pagination.Sort{Field: "created_at", Desc: true},
+	IDColumn: "id",
+}
+
+// Query retrieves a page of audit logs matching filters, with their total
+// count and the cursor to the next page
 func (s *AuditService) Query(
 	ctx context.Context,
 	tenantID uuid.UUID,
 	filters AuditFilters,
-	limit, offset int,
-) ([]models.AuditLog, int, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	page *pagination.Request,
+) ([]models.AuditLog, int, string, error) {
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 	defer tx.Rollback()
 
-	// Build query
-	baseQuery := `FROM audit_logs WHERE 1=1`
-	var args []interface{}
-	argIndex := 1
-
-	// Apply filters
-	if filters.UserID != nil {
-		baseQuery += fmt.Sprintf(" AND user_id = $%d", argIndex)
-		args = append(args, *filters.UserID)
-		argIndex++
-	}
+	where := auditFilterWhere(filters)
 
-	if filters.Action != "" {
-		baseQuery += fmt.Sprintf(" AND action = $%d", argIndex)
-		args = append(args, filters.Action)
-		argIndex++
-	}
-
-	if filters.ResourceType != "" {
-		baseQuery += fmt.Sprintf(" AND resource_type = $%d", argIndex)
-		args = append(args, filters.ResourceType)
-		argIndex++
+	// Get total count
+	var totalCount int
+	err = tx.GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM audit_logs "+where.SQL(), where.Args()...)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	if filters.ResourceID != nil {
-		baseQuery += fmt.Sprintf(" AND resource_id = $%d", argIndex)
-		args = append(args, *filters.ResourceID)
-		argIndex++
-	}
+	// Get audit logs
+	page.Apply(where)
+	selectQuery := `
+		SELECT
+			id, tenant_id, user_id, action, resource_type, resource_id,
+			status, ip_address, user_agent, metadata, created_at
+		FROM audit_logs
+		` + where.SQL() + `
+		` + page.OrderBy() + `
+		` + page.Limit(where)
 
-	if filters.Status != "" {
-		baseQuery += fmt.Sprintf(" AND status = $%d", argIndex)
-		args = append(args, filters.Status)
-		argIndex++
+	var logs []models.AuditLog
+	err = tx.SelectContext(ctx, &logs, selectQuery, where.Args()...)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	if filters.StartDate != nil {
-		baseQuery += fmt.Sprintf(" AND created_at >= $%d", argIndex)
-		args = append(args, *filters.StartDate)
-		argIndex++
-	}
+	logs, nextCursor := pagination.Cut(page, logs, func(log *models.AuditLog, field string) (interface{}, uuid.UUID) {
+		switch field {
+		case "action":
+			return log.Action, log.ID
+		case "status":
+			return log.Status, log.ID
+		default:
+			return log.CreatedAt, log.ID
+		}
+	})
 
-	if filters.EndDate != nil {
-		baseQuery += fmt.Sprintf(" AND created_at <= $%d", argIndex)
-		args = append(args, *filters.EndDate)
-		argIndex++
-	}
+	return logs, totalCount, nextCursor, tx.Commit()
+}
 
-	// Get total count
-	var totalCount int
-	countQuery := "SELECT COUNT(*) " + baseQuery
-	err = tx.GetContext(ctx, &totalCount, countQuery, args...)
+// Export reads every audit log matching filters, newest first, and calls fn
+// with each one as it is read rather than loading them all
+func (s *AuditService) Export(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filters AuditFilters,
+	fn func(*models.AuditLog) error,
+) error {
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
+	defer tx.Rollback()
 
-	// Get audit logs
-	selectQuery := fmt.Sprintf(`
+	where := auditFilterWhere(filters)
+	selectQuery := `
 		SELECT
 			id, tenant_id, user_id, action, resource_type, resource_id,
 			status, ip_address, user_agent, metadata, created_at
-		%s
+		FROM audit_logs
+		` + where.SQL() + `
 		ORDER BY created_at DESC
-		LIMIT $%d OFFSET $%d
-	`, baseQuery, argIndex, argIndex+1)
-
-	args = append(args, limit, offset)
+	`
 
-	var logs []models.AuditLog
-	err = tx.SelectContext(ctx, &logs, selectQuery, args...)
+	rows, err := tx.QueryxContext(ctx, selectQuery, where.Args()...)
 	if err != nil {
-		return nil, 0, err
+		return fmt.Errorf("failed to export audit logs: %w", err)
 	}
+	defer rows.Close()
 
-	return logs, totalCount, tx.Commit()
+	for rows.Next() {
+		var log models.AuditLog
+		if err := rows.StructScan(&log); err != nil {
+			return fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export audit logs: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// auditFilterWhere builds the WHERE clause selecting the audit logs that
+// match filters
+func auditFilterWhere(filters AuditFilters) *pagination.Where {
+	where := &pagination.Where{}
+	where.EqualID("user_id", filters.UserID)
+	where.Equal("action", filters.Action)
+	where.Equal("resource_type", filters.ResourceType)
+	where.EqualID("resource_id", filters.ResourceID)
+	where.Equal("status", filters.Status)
+	where.Range("created_at", filters.StartDate, filters.EndDate)
+	return where
 }
 
 // GetUserActivity retrieves recent activity for a specific user
@@ -168,7 +216,7 @@ func (s *AuditService) GetUserActivity(
 	tenantID, userID uuid.UUID,
 	limit int,
 ) ([]models.AuditLog, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -195,7 +243,7 @@ func (s *AuditService) GetUserActivity(
 
 // GetActionStats retrieves statistics by action type
 func (s *AuditService) GetActionStats(ctx context.Context, tenantID uuid.UUID, startDate, endDate time.Time) (map[string]int, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +283,7 @@ func (s *AuditService) GetFailedAttempts(
 	userID *uuid.UUID,
 	since time.Time,
 ) ([]models.AuditLog, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +296,7 @@ func (s *AuditService) GetFailedAttempts(
 		FROM audit_logs
 		WHERE status = 'failure'
 		  AND created_at >= $1
-		  AND action IN ('login', '2fa.verify', 'password.reset')
+		  AND action IN ('user.login.failed', '2fa.failed', 'user.password_reset')
 	`
 
 	var args []interface{}
@@ -272,7 +320,7 @@ func (s *AuditService) GetFailedAttempts(
 
 // GetSuspiciousActivity detects potentially suspicious activity patterns
 func (s *AuditService) GetSuspiciousActivity(ctx context.Context, tenantID uuid.UUID, since time.Time) ([]map[string]interface{}, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +335,7 @@ func (s *AuditService) GetSuspiciousActivity(ctx context.Context, tenantID uuid.
 			MAX(created_at) as last_attempt
 		FROM audit_logs
 		WHERE status = 'failure'
-		  AND action = 'login'
+		  AND action = 'user.login.failed'
 		  AND created_at >= $1
 		GROUP BY ip_address, user_id
 		HAVING COUNT(*) >= 3
@@ -389,7 +437,7 @@ func (s *AuditService) GetRecentActions(
 	resourceID uuid.UUID,
 	limit int,
 ) ([]models.AuditLog, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -422,7 +470,7 @@ func (s *AuditService) Search(
 	keyword string,
 	limit, offset int,
 ) ([]models.AuditLog, int, error) {
-	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	tx, err := database.WithTenantContextReadOnly(ctx, s.db, tenantID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -466,6 +514,153 @@ func (s *AuditService) Search(
 	return logs, totalCount, tx.Commit()
 }
 
+// AuditSnapshot encodes the state of a resource for the before/after metadata
+// of an audit entry. It uses the JSON representation, which leaves out
+// secrets such as password hashes, and is taken right away so later changes to
+// v are not picked up.
+func AuditSnapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// GetByID retrieves a single audit log entry
+func (s *AuditService) GetByID(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditLog, error) {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT
+			id, tenant_id, user_id, action, resource_type, resource_id,
+			status, ip_address, user_agent, metadata, created_at
+		FROM audit_logs
+		WHERE id = $1
+	`
+
+	var log models.AuditLog
+	err = tx.GetContext(ctx, &log, query, logID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("AUDIT_LOG_NOT_FOUND", "audit log not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	return &log, tx.Commit()
+}
+
+// Diff compares the before and after snapshots of an audit log entry field by
+// field. Values of sensitive fields (passwords, tokens, secrets) are redacted.
+func (s *AuditService) Diff(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditDiff, error) {
+	log, err := s.GetByID(ctx, tenantID, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, hasBefore := log.Metadata["before"]
+	after, hasAfter := log.Metadata["after"]
+	if !hasBefore && !hasAfter {
+		return nil, fmt.Errorf("audit log has no snapshots")
+	}
+
+	changes := []models.AuditFieldChange{}
+	diffAuditValues("", before, after, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	// The snapshots are rendered as changes; don't repeat them
+	delete(log.Metadata, "before")
+	delete(log.Metadata, "after")
+
+	return &models.AuditDiff{Log: log, Changes: changes}, nil
+}
+
+// auditSensitiveFields are redacted in diffs (matched as substrings of the
+// field name)
+var auditSensitiveFields = []string{"password", "token", "secret", "backup_code"}
+
+// diffAuditValues appends the changes between before and after to changes.
+// Objects are compared field by field; any other values, including arrays,
+// are compared as a whole.
+func diffAuditValues(path string, before, after interface{}, changes *[]models.AuditFieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+
+	if beforeIsMap && afterIsMap {
+		for key, value := range beforeMap {
+			diffAuditValues(joinAuditPath(path, key), value, afterMap[key], changes)
+		}
+		for key, value := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				diffAuditValues(joinAuditPath(path, key), nil, value, changes)
+			}
+		}
+		return
+	}
+
+	// A missing snapshot (create/delete) lists every field of the other one
+	if before == nil && afterIsMap {
+		for key, value := range afterMap {
+			diffAuditValues(joinAuditPath(path, key), nil, value, changes)
+		}
+		return
+	}
+	if after == nil && beforeIsMap {
+		for key, value := range beforeMap {
+			diffAuditValues(joinAuditPath(path, key), value, nil, changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	change := models.AuditFieldChange{Field: path, Before: before, After: after}
+	switch {
+	case before == nil:
+		change.Change = models.AuditChangeAdded
+	case after == nil:
+		change.Change = models.AuditChangeRemoved
+	default:
+		change.Change = models.AuditChangeChanged
+	}
+
+	if isAuditSensitiveField(path) {
+		if change.Before != nil {
+			change.Before = "[redacted]"
+		}
+		if change.After != nil {
+			change.After = "[redacted]"
+		}
+	}
+
+	*changes = append(*changes, change)
+}
+
+// joinAuditPath builds the dotted path of a nested field
+func joinAuditPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isAuditSensitiveField reports whether a field's values must not be shown
+func isAuditSensitiveField(path string) bool {
+	lower := strings.ToLower(path)
+	for _, field := range auditSensitiveFields {
+		if strings.Contains(lower, field) {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper function to determine severity level
 func getSeverityLevel(attemptCount int) string {
 	if attemptCount >= 10 {
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// Redis keys of 2FA bypass codes: the code's hash, the failed attempts at
+// it, and the codes sent to the user in the last 24 hours
+const (
+	twoFABypassKeyPrefix         = "2fa_bypass"
+	twoFABypassAttemptsKeyPrefix = "2fa_bypass_attempts"
+	twoFABypassIssuedKeyPrefix   = "2fa_bypass_issued"
+	twoFABypassIssuedWindow      = 24 * time.Hour
+	twoFABypassCodeDigits        = 8
+)
+
+// hasSecondFactor reports whether a user is asked for a second factor when
+// signing in with their password
+func (s *AuthService) hasSecondFactor(ctx context.Context, user *models.User) (bool, error) {
+	if user.TwoFactorEnabled {
+		return true, nil
+	}
+	keys, err := s.webauthnRepo.CountByUser(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return keys > 0, nil
+}
+
+// twoFABypassHash hashes a bypass code for storage, keyed with the encryption
+// key so a copy of Redis does not give the codes away
+func (s *AuthService) twoFABypassHash(tenantID, userID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Security.EncryptionKey))
+	mac.Write([]byte(tenantID.String() + ":" + userID.String() + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendTwoFABypassCode emails a user a one-time code that replaces their
+// second factor once, replacing any code sent before. At most
+// TwoFABypassDailyLimit codes are sent per user in 24 hours.
+func (s *AuthService) sendTwoFABypassCode(ctx context.Context, user *models.User, issuedBy string) (time.Time, error) {
+	issuedKey := database.CacheKey(twoFABypassIssuedKeyPrefix, user.TenantID.String(), user.ID.String())
+	issued, err := s.redis.Incr(ctx, issuedKey).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to count bypass codes: %w", err)
+	}
+	if issued == 1 {
+		s.redis.Expire(ctx, issuedKey, twoFABypassIssuedWindow)
+	}
+	if issued > int64(s.config.Security.TwoFABypassDailyLimit) {
+		return time.Time{}, utils.NewTooManyRequestsError("BYPASS_CODE_LIMIT_REACHED", "too many bypass codes requested, please try again tomorrow")
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate bypass code: %w", err)
+	}
+	code := fmt.Sprintf("%0*d", twoFABypassCodeDigits, n)
+
+	ttl := s.config.Security.TwoFABypassCodeTTL
+	expiresAt := time.Now().Add(ttl)
+	codeKey := database.CacheKey(twoFABypassKeyPrefix, user.TenantID.String(), user.ID.String())
+	attemptsKey := database.CacheKey(twoFABypassAttemptsKeyPrefix, user.TenantID.String(), user.ID.String())
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, codeKey, s.twoFABypassHash(user.TenantID, user.ID, code), ttl)
+	pipe.Del(ctx, attemptsKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save bypass code: %w", err)
+	}
+
+	msg, err := s.emailService.TwoFactorBypassEmail(user.Email, user.Language, user.FirstName, code, issuedBy, expiresAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to render bypass code email: %w", err)
+	}
+	if err := s.emailQueue.EnqueueStandalone(ctx, user.TenantID, msg); err != nil {
+		return time.Time{}, fmt.Errorf("failed to queue bypass code email: %w", err)
+	}
+
+	return expiresAt, nil
+}
+
+// consumeTwoFABypassCode checks a bypass code and uses it up. After
+// Max2FAAttempts wrong codes the code is revoked.
+func (s *AuthService) consumeTwoFABypassCode(ctx context.Context, tenantID, userID uuid.UUID, code string) error {
+	codeKey := database.CacheKey(twoFABypassKeyPrefix, tenantID.String(), userID.String())
+	attemptsKey := database.CacheKey(twoFABypassAttemptsKeyPrefix, tenantID.String(), userID.String())
+
+	stored, err := s.redis.Get(ctx, codeKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return utils.NewUnauthorizedError("INVALID_BYPASS_CODE", "invalid or expired bypass code")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load bypass code: %w", err)
+	}
+
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if !hmac.Equal([]byte(stored), []byte(s.twoFABypassHash(tenantID, userID, code))) {
+		attempts, err := s.redis.Incr(ctx, attemptsKey).Result()
+		if err == nil {
+			if attempts == 1 {
+				s.redis.Expire(ctx, attemptsKey, s.config.Security.TwoFABypassCodeTTL)
+			}
+			if attempts >= int64(s.config.Security.Max2FAAttempts) {
+				s.redis.Del(ctx, codeKey, attemptsKey)
+				return utils.NewTooManyRequestsError("TOO_MANY_INVALID_BYPASS_CODES", "too many invalid bypass codes, please request a new one")
+			}
+		}
+		return utils.NewUnauthorizedError("INVALID_BYPASS_CODE", "invalid or expired bypass code")
+	}
+
+	// Deleting the code uses it up; a concurrent use of it finds it gone
+	deleted, err := s.redis.Del(ctx, codeKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to use bypass code: %w", err)
+	}
+	if deleted == 0 {
+		return utils.NewUnauthorizedError("INVALID_BYPASS_CODE", "invalid or expired bypass code")
+	}
+	s.redis.Del(ctx, attemptsKey)
+
+	return nil
+}
+
+// RequestTwoFABypassCode emails a user who lost their authenticator a
+// one-time code to complete their password sign-in with. The 2FA token
+// proves they entered their password.
+func (s *AuthService) RequestTwoFABypassCode(ctx context.Context, twoFactorToken string, deviceInfo utils.DeviceInfo, ipAddress string) (time.Time, error) {
+	claims, err := s.jwtService.Validate2FAToken(twoFactorToken)
+	if err != nil {
+		return time.Time{}, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return time.Time{}, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	if !user.CanLogin() {
+		return time.Time{}, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	expiresAt, err := s.sendTwoFABypassCode(ctx, user, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.auditLogin(ctx, user, models.Action2FABypassRequested, models.AuditStatusSuccess, deviceInfo, ipAddress, nil)
+
+	return expiresAt, nil
+}
+
+// IssueTwoFABypassCode emails a user of the tenant a one-time code to sign
+// in without their second factor, on an administrator's behalf. The code
+// only goes to the user, so the administrator cannot sign in as them.
+func (s *AuthService) IssueTwoFABypassCode(ctx context.Context, tenantID, userID, issuerID uuid.UUID) (time.Time, error) {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return time.Time{}, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	if !user.CanLogin() {
+		return time.Time{}, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	required, err := s.hasSecondFactor(ctx, user)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !required {
+		return time.Time{}, utils.NewBadRequestError("NO_SECOND_FACTOR", "user has no second factor")
+	}
+
+	issuedBy := "An administrator"
+	if issuer, err := s.userRepo.FindByID(ctx, tenantID, issuerID); err == nil {
+		issuedBy = issuer.FullName()
+	}
+
+	return s.sendTwoFABypassCode(ctx, user, issuedBy)
+}
+
+// VerifyTwoFABypassCode completes a password sign-in with an emailed bypass
+// code instead of the second factor
+func (s *AuthService) VerifyTwoFABypassCode(ctx context.Context, twoFactorToken, code string, rememberMe bool, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	claims, err := s.jwtService.Validate2FAToken(twoFactorToken)
+	if err != nil {
+		return nil, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	if err := s.consumeTwoFABypassCode(ctx, user.TenantID, user.ID, code); err != nil {
+		s.auditLogin(ctx, user, models.Action2FAFailed, models.AuditStatusFailure, deviceInfo, ipAddress, map[string]interface{}{
+			"method": models.TwoFactorMethodBypassCode,
+		})
+		return nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if !tenant.CanAccess() {
+		return nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+	if !user.CanLogin() {
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	s.auditLogin(ctx, user, models.Action2FABypassUsed, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"method": models.TwoFactorMethodBypassCode,
+	})
+
+	return s.createSessionAndTokens(ctx, user, tenant, rememberMe, deviceInfo, ipAddress)
+}
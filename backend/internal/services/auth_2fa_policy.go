@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// twoFASetupReminderKeyPrefix marks the setup reminders sent to a user, one
+// per reminder stage
+const twoFASetupReminderKeyPrefix = "2fa_setup_reminder"
+
+// twoFASetupReminderDays are the days before the end of their grace period
+// users are reminded to set up 2FA, besides once when the grace period starts
+var twoFASetupReminderDays = []int{7, 3, 1}
+
+// twoFactorSetupDeadline returns when a user must have set up a second
+// factor by, or nil if the tenant's 2FA policy does not require one of them
+// or they have one
+func (s *AuthService) twoFactorSetupDeadline(ctx context.Context, tenant *models.Tenant, user *models.User) (*time.Time, error) {
+	policy := tenant.TwoFactorPolicy()
+	if !policy.Required {
+		return nil, nil
+	}
+
+	if len(policy.RoleIDs) > 0 {
+		applies, err := s.userRoleRepo.HasAnyRole(ctx, tenant.ID, user.ID, policy.RoleIDs)
+		if err != nil {
+			return nil, err
+		}
+		if !applies {
+			return nil, nil
+		}
+	}
+
+	hasFactor, err := s.hasSecondFactor(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if hasFactor {
+		return nil, nil
+	}
+
+	deadline := policy.SetupDeadline(user.CreatedAt)
+	return &deadline, nil
+}
+
+// GetTwoFactorStatus returns a user's second factors and the deadline the
+// tenant's 2FA policy gives them to set one up
+func (s *AuthService) GetTwoFactorStatus(ctx context.Context, tenant *models.Tenant, user *models.User) (*models.TwoFactorStatus, error) {
+	methods, err := s.secondFactorMethods(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, err := s.twoFactorSetupDeadline(ctx, tenant, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorStatus{
+		Enabled:       len(methods) > 0,
+		Methods:       methods,
+		SetupDeadline: deadline,
+		PasskeyOnly:   user.PasskeyOnly,
+	}, nil
+}
+
+// requireTwoFactorSetup refuses a sign-in the tenant's 2FA policy blocks. The
+// 2FA token returned lets the user set up 2FA and complete the sign-in.
+func (s *AuthService) requireTwoFactorSetup(ctx context.Context, user *models.User, tenant *models.Tenant, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	s.auditLoginFailure(ctx, user, loginFailureTwoFactorSetupRequired, deviceInfo, ipAddress)
+
+	twoFactorToken, err := s.jwtService.Generate2FAToken(user.ID, tenant.ID, tenant.Slug, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate 2FA token: %w", err)
+	}
+
+	return &models.UserLoginResponse{
+		User:                   user,
+		RequiresTwoFactorSetup: true,
+		TwoFactorToken:         twoFactorToken,
+	}, nil
+}
+
+// loadTwoFactorSetup loads the user and tenant of a sign-in blocked until
+// 2FA is set up
+func (s *AuthService) loadTwoFactorSetup(ctx context.Context, twoFactorToken string) (*models.User, *models.Tenant, error) {
+	claims, err := s.jwtService.Validate2FAToken(twoFactorToken)
+	if err != nil {
+		return nil, nil, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	if !user.CanLogin() {
+		return nil, nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
+	if err != nil {
+		return nil, nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if !tenant.CanAccess() {
+		return nil, nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+
+	// Users with a second factor verify it instead
+	hasFactor, err := s.hasSecondFactor(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hasFactor {
+		return nil, nil, utils.NewConflictError("TWO_FACTOR_ALREADY_SET_UP", "2FA is already set up")
+	}
+
+	return user, tenant, nil
+}
+
+// BeginTwoFactorSetup generates the TOTP secret and backup codes for a user
+// whose sign-in the tenant's 2FA policy blocked
+func (s *AuthService) BeginTwoFactorSetup(ctx context.Context, twoFactorToken string) (*TwoFactorSetup, error) {
+	user, _, err := s.loadTwoFactorSetup(ctx, twoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.twoFactor.GenerateSecret(ctx, user.Email, user.FullName())
+}
+
+// FinishTwoFactorSetup enables 2FA for a user whose sign-in the tenant's 2FA
+// policy blocked, after verifying a code of the new secret, and completes
+// the sign-in
+func (s *AuthService) FinishTwoFactorSetup(ctx context.Context, req *models.TwoFactorSetupFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	user, tenant, err := s.loadTwoFactorSetup(ctx, req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactor.EnableTwoFactor(ctx, user.TenantID, user.ID, req.Secret, req.VerificationCode, req.BackupCodes); err != nil {
+		return nil, err
+	}
+	user.TwoFactorEnabled = true
+
+	s.auditLogin(ctx, user, models.Action2FAEnabled, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"method": models.TwoFactorMethodTOTP,
+		"policy": true,
+	})
+
+	return s.createSessionAndTokens(ctx, user, tenant, req.RememberMe, deviceInfo, ipAddress)
+}
+
+// GetTwoFactorPolicy returns a tenant's 2FA policy
+func (s *AuthService) GetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID) (*models.TwoFactorPolicy, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return tenant.TwoFactorPolicy(), nil
+}
+
+// UpdateTwoFactorPolicy changes a tenant's 2FA policy. Grace periods count
+// from when the policy is turned on, so turning it off and on again gives
+// users a new grace period.
+func (s *AuthService) UpdateTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, req *models.TwoFactorPolicyUpdateRequest) (*models.TwoFactorPolicy, error) {
+	policy, err := s.GetTwoFactorPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RoleIDs != nil {
+		for _, roleID := range *req.RoleIDs {
+			if _, err := s.roleRepo.FindByID(ctx, tenantID, roleID); err != nil {
+				return nil, utils.NewFieldError("ROLE_NOT_FOUND", "role_ids", "role not found")
+			}
+		}
+		policy.RoleIDs = append([]uuid.UUID{}, *req.RoleIDs...)
+	}
+	if req.GracePeriodDays != nil {
+		policy.GracePeriodDays = *req.GracePeriodDays
+	}
+	if req.Required != nil && *req.Required != policy.Required {
+		policy.Required = *req.Required
+		policy.EnforcedSince = nil
+		if policy.Required {
+			now := time.Now().UTC()
+			policy.EnforcedSince = &now
+		}
+	}
+
+	if err := s.tenantRepo.SetTwoFactorPolicy(ctx, tenantID, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// RemindTwoFactorSetup emails the users of tenants requiring 2FA who have
+// not set it up yet, while their grace period runs: once when it starts,
+// then 7, 3 and 1 days before it ends. Returns the number of reminders sent.
+func (s *AuthService) RemindTwoFactorSetup(ctx context.Context) (int, error) {
+	tenants, err := s.tenantRepo.ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range tenants {
+		if err := ctx.Err(); err != nil {
+			return sent, nil
+		}
+
+		policy := tenants[i].TwoFactorPolicy()
+		if !policy.Required {
+			continue
+		}
+
+		n, err := s.remindTenantTwoFactorSetup(ctx, &tenants[i], policy)
+		sent += n
+		if err != nil {
+			log.Printf("⚠️  2FA setup reminders of tenant %s failed: %v", tenants[i].ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// remindTenantTwoFactorSetup sends a tenant's users the setup reminders due
+func (s *AuthService) remindTenantTwoFactorSetup(ctx context.Context, tenant *models.Tenant, policy *models.TwoFactorPolicy) (int, error) {
+	users, err := s.userRepo.ListWithoutSecondFactor(ctx, tenant.ID, policy.RoleIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	now := time.Now()
+	for i := range users {
+		user := &users[i]
+		deadline := policy.SetupDeadline(user.CreatedAt)
+		if !now.Before(deadline) {
+			continue // Blocked at sign-in until they set it up
+		}
+
+		// The stage is the closest reminder day not yet passed, 0 for the
+		// reminder when the grace period starts
+		daysLeft := int(deadline.Sub(now).Hours() / 24)
+		stage := 0
+		for _, days := range twoFASetupReminderDays {
+			if daysLeft < days {
+				stage = days
+			}
+		}
+
+		key := database.CacheKey(twoFASetupReminderKeyPrefix, tenant.ID.String(), user.ID.String(), fmt.Sprint(stage))
+		first, err := s.redis.SetNX(ctx, key, 1, deadline.Sub(now)+24*time.Hour).Result()
+		if err != nil {
+			return sent, fmt.Errorf("failed to mark reminder: %w", err)
+		}
+		if !first {
+			continue
+		}
+
+		msg, err := s.emailService.TwoFactorSetupReminderEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, deadline)
+		if err != nil {
+			return sent, fmt.Errorf("failed to render reminder email: %w", err)
+		}
+		if err := s.emailQueue.EnqueueStandalone(ctx, tenant.ID, msg); err != nil {
+			s.redis.Del(ctx, key)
+			return sent, fmt.Errorf("failed to queue reminder email: %w", err)
+		}
+		sent++
+	}
+
+	return sent, nil
+}
@@ -4,37 +4,61 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
 	"myerp-v2/internal/repository"
 	"myerp-v2/internal/utils"
 )
 
-// AuthService handles authentication operations
+// AuthService handles authentication operations: password logins, and
+// single sign-on with the tenant's OpenID Connect providers
 type AuthService struct {
-	tenantRepo   *repository.TenantRepository
-	userRepo     *repository.UserRepository
-	sessionRepo  *repository.SessionRepository
-	roleRepo     *repository.RoleRepository
-	userRoleRepo *repository.UserRoleRepository
-	jwtService   *JWTService
-	emailService *EmailService
+	tenantRepo   repository.TenantStore
+	userRepo     repository.UserStore
+	sessionRepo  repository.SessionStore
+	roleRepo     repository.RoleStore
+	userRoleRepo repository.UserRoleStore
+	ssoRepo      repository.SSOStore
+	webauthnRepo repository.WebAuthnStore
+	jwtService   JWTManager
+	twoFactor    TwoFactorManager
+	emailService EmailManager
+	emailQueue   EmailQueueManager
+	quotaService QuotaManager
+	auditService AuditManager
+	txManager    *database.TxManager
+	redis        *redis.Client
+	oidc         *oidcClient
 	config       *config.Config
 }
 
 // NewAuthService creates a new auth service
 func NewAuthService(
-	tenantRepo *repository.TenantRepository,
-	userRepo *repository.UserRepository,
-	sessionRepo *repository.SessionRepository,
-	roleRepo *repository.RoleRepository,
-	userRoleRepo *repository.UserRoleRepository,
-	jwtService *JWTService,
-	emailService *EmailService,
+	tenantRepo repository.TenantStore,
+	userRepo repository.UserStore,
+	sessionRepo repository.SessionStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	ssoRepo repository.SSOStore,
+	webauthnRepo repository.WebAuthnStore,
+	jwtService JWTManager,
+	twoFactorService TwoFactorManager,
+	emailService EmailManager,
+	emailQueue EmailQueueManager,
+	quotaService QuotaManager,
+	auditService AuditManager,
+	txManager *database.TxManager,
+	redisClient *redis.Client,
 	cfg *config.Config,
 ) *AuthService {
 	return &AuthService{
@@ -43,12 +67,37 @@ func NewAuthService(
 		sessionRepo:  sessionRepo,
 		roleRepo:     roleRepo,
 		userRoleRepo: userRoleRepo,
+		ssoRepo:      ssoRepo,
+		webauthnRepo: webauthnRepo,
 		jwtService:   jwtService,
+		twoFactor:    twoFactorService,
 		emailService: emailService,
+		emailQueue:   emailQueue,
+		quotaService: quotaService,
+		auditService: auditService,
+		txManager:    txManager,
+		redis:        redisClient,
+		oidc:         newOIDCClient(&cfg.SSO),
 		config:       cfg,
 	}
 }
 
+const (
+	sessionActivityTTL       = 24 * time.Hour // Buffered heartbeats not flushed by then are dropped
+	sessionActivityBatchSize = 500            // Sessions read from Redis at a time
+	sessionActivityFlushSize = 20000          // Max sessions written per flush
+)
+
+// sessionActivityDirtyKey is the Redis set of sessions active since the last
+// flush, as "tenantID:sessionID"
+const sessionActivityDirtyKey = "session:activity:dirty"
+
+// sessionActivityKey is the Redis key holding a session's last activity time
+// in Unix milliseconds
+func sessionActivityKey(sessionID uuid.UUID) string {
+	return "session:activity:" + sessionID.String()
+}
+
 // RegisterTenant registers a new tenant with email verification
 func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCreateRequest) (*models.Tenant, error) {
 	// Check if email is already registered
@@ -57,7 +106,7 @@ func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCrea
 		return nil, err
 	}
 	if exists {
-		return nil, fmt.Errorf("email already registered")
+		return nil, utils.NewConflictError("EMAIL_ALREADY_REGISTERED", "email already registered")
 	}
 
 	// Generate slug from company name if not provided
@@ -66,8 +115,11 @@ func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCrea
 		slug = utils.GenerateSlug(req.CompanyName)
 	}
 
-	// Check if slug is available
-	available, err := s.tenantRepo.CheckSlugAvailability(ctx, slug)
+	// Check if slug is available (reserved subdomains never are)
+	available := false
+	if !s.config.Domains.IsReserved(slug) {
+		available, err = s.tenantRepo.CheckSlugAvailability(ctx, slug)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +142,7 @@ func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCrea
 	expiresAt := time.Now().Add(s.config.Security.VerificationExpiry)
 
 	tenant := &models.Tenant{
+		ID:                         uuid.New(),
 		Slug:                       slug,
 		CompanyName:                req.CompanyName,
 		Email:                      req.Email,
@@ -100,11 +153,6 @@ func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCrea
 		Settings:                   []byte("{}"),
 	}
 
-	// Create tenant
-	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
-		return nil, fmt.Errorf("failed to create tenant: %w", err)
-	}
-
 	// Hash password for initial admin user
 	hashedPassword, err := utils.HashPassword(req.Password, s.config.Security.BcryptCost)
 	if err != nil {
@@ -124,18 +172,28 @@ func (s *AuthService) RegisterTenant(ctx context.Context, req *models.TenantCrea
 		Preferences:  []byte("{}"),
 	}
 
-	if err := s.userRepo.Create(ctx, tenant.ID, user); err != nil {
-		// If user creation fails, we should probably rollback tenant creation
-		// For now, log error and continue (tenant can re-register or admin can fix)
-		fmt.Printf("Failed to create initial admin user: %v\n", err)
-		return nil, fmt.Errorf("failed to create initial admin user: %w", err)
+	msg, err := s.emailService.TenantVerificationEmail(tenant.Email, tenant.Locale().Language, tenant.CompanyName, tenant.Branding(), verificationToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render verification email: %w", err)
 	}
 
-	// Send verification email
-	if err := s.emailService.SendTenantVerificationEmail(tenant.Email, tenant.CompanyName, verificationToken); err != nil {
-		// Log error but don't fail registration
-		// In production, you might want to retry sending or queue it
-		fmt.Printf("Failed to send verification email: %v\n", err)
+	// The tenant, its initial admin user and the verification email are
+	// created together, or not at all
+	err = s.txManager.InTenantTx(ctx, tenant.ID, func(ctx context.Context) error {
+		if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+			return fmt.Errorf("failed to create tenant: %w", err)
+		}
+		if err := s.userRepo.Create(ctx, tenant.ID, user); err != nil {
+			return fmt.Errorf("failed to create initial admin user: %w", err)
+		}
+		// Delivered with retry by the email worker
+		if err := s.emailQueue.EnqueueStandalone(ctx, tenant.ID, msg); err != nil {
+			return fmt.Errorf("failed to queue verification email: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return tenant, nil
@@ -149,43 +207,52 @@ func (s *AuthService) VerifyTenantEmail(ctx context.Context, token uuid.UUID) (*
 		return nil, err
 	}
 
-	// Verify email
-	if err := s.tenantRepo.VerifyEmail(ctx, tenant.ID); err != nil {
-		return nil, fmt.Errorf("failed to verify email: %w", err)
-	}
+	// Verification, role provisioning and the activation of the initial
+	// admin user succeed together, so a failure leaves the link usable
+	err = s.txManager.InTenantTx(ctx, tenant.ID, func(ctx context.Context) error {
+		// Verify email
+		if err := s.tenantRepo.VerifyEmail(ctx, tenant.ID); err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
 
-	// Provision system roles
-	if err := s.tenantRepo.ProvisionSystemRoles(ctx, tenant.ID); err != nil {
-		return nil, fmt.Errorf("failed to provision system roles: %w", err)
-	}
+		// Provision system roles
+		if err := s.tenantRepo.ProvisionSystemRoles(ctx, tenant.ID); err != nil {
+			return fmt.Errorf("failed to provision system roles: %w", err)
+		}
 
-	// Activate the initial admin user
-	user, err := s.userRepo.FindByEmail(ctx, tenant.ID, tenant.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find initial admin user: %w", err)
-	}
+		// Activate the initial admin user
+		user, err := s.userRepo.FindByEmail(ctx, tenant.ID, tenant.Email)
+		if err != nil {
+			return fmt.Errorf("failed to find initial admin user: %w", err)
+		}
 
-	// Update user status to active
-	if err := s.userRepo.UpdateStatus(ctx, tenant.ID, user.ID, "active"); err != nil {
-		return nil, fmt.Errorf("failed to activate user: %w", err)
-	}
+		// Update user status to active
+		if err := s.userRepo.UpdateStatus(ctx, tenant.ID, user.ID, "active"); err != nil {
+			return fmt.Errorf("failed to activate user: %w", err)
+		}
 
-	// Mark user's email as verified
-	if err := s.userRepo.VerifyEmail(ctx, tenant.ID, user.ID); err != nil {
-		return nil, fmt.Errorf("failed to verify user email: %w", err)
-	}
+		// Mark user's email as verified
+		if err := s.userRepo.VerifyEmail(ctx, tenant.ID, user.ID); err != nil {
+			return fmt.Errorf("failed to verify user email: %w", err)
+		}
+
+		// Assign owner role to the initial admin user
+		// First, find the owner role for this tenant
+		ownerRole, err := s.roleRepo.FindByName(ctx, tenant.ID, "owner")
+		if err != nil {
+			// If owner role doesn't exist, log warning but continue
+			fmt.Printf("Warning: Owner role not found for tenant %s: %v\n", tenant.ID, err)
+			return nil
+		}
 
-	// Assign owner role to the initial admin user
-	// First, find the owner role for this tenant
-	ownerRole, err := s.roleRepo.FindByName(ctx, tenant.ID, "owner")
-	if err != nil {
-		// If owner role doesn't exist, log warning but continue
-		fmt.Printf("Warning: Owner role not found for tenant %s: %v\n", tenant.ID, err)
-	} else {
 		// Assign owner role to user (assigned_by = user.ID since they're the first user)
 		if err := s.userRoleRepo.AssignRole(ctx, tenant.ID, user.ID, ownerRole.ID, user.ID); err != nil {
-			return nil, fmt.Errorf("failed to assign owner role: %w", err)
+			return fmt.Errorf("failed to assign owner role: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Update tenant status
@@ -198,19 +265,49 @@ func (s *AuthService) VerifyTenantEmail(ctx context.Context, token uuid.UUID) (*
 	return tenant, nil
 }
 
+// ResendTenantVerification sends a tenant awaiting email verification a new
+// verification link, the previous one no longer working
+func (s *AuthService) ResendTenantVerification(ctx context.Context, tenantID uuid.UUID) (*models.Tenant, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.IsPendingVerification() {
+		return nil, utils.NewConflictError("TENANT_ALREADY_VERIFIED", "tenant is already verified")
+	}
+
+	verificationToken, err := s.tenantRepo.RegenerateVerificationToken(ctx, tenant.ID, s.config.Security.VerificationExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.emailService.TenantVerificationEmail(tenant.Email, tenant.Locale().Language, tenant.CompanyName, tenant.Branding(), *verificationToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render verification email: %w", err)
+	}
+	if err := s.emailQueue.EnqueueStandalone(ctx, tenant.ID, msg); err != nil {
+		return nil, fmt.Errorf("failed to queue verification email: %w", err)
+	}
+
+	return tenant, nil
+}
+
 // Login authenticates a user and creates a session
 func (s *AuthService) Login(ctx context.Context, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
 	// Find all users with this email across all tenants
 	users, err := s.userRepo.FindAllByEmail(ctx, req.Email)
-	if err != nil || len(users) == 0 {
-		return nil, fmt.Errorf("invalid email or password")
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, utils.NewUnauthorizedError("INVALID_CREDENTIALS", "invalid email or password")
 	}
 
 	// If tenant_id is specified (second step of multi-tenant login)
 	if req.TenantID != "" {
 		tenantUUID, err := uuid.Parse(req.TenantID)
 		if err != nil {
-			return nil, fmt.Errorf("invalid tenant ID")
+			return nil, utils.NewBadRequestError("INVALID_TENANT_ID", "invalid tenant ID")
 		}
 
 		// Find the specific user in that tenant
@@ -218,25 +315,31 @@ func (s *AuthService) Login(ctx context.Context, req *models.UserLoginRequest, d
 			if user.TenantID == tenantUUID {
 				// Verify password
 				if !utils.VerifyPassword(req.Password, user.PasswordHash) {
-					return nil, fmt.Errorf("invalid email or password")
+					s.auditLoginFailure(ctx, &user, loginFailureInvalidPassword, deviceInfo, ipAddress)
+					return nil, utils.NewUnauthorizedError("INVALID_CREDENTIALS", "invalid email or password")
 				}
 
 				// Check user status
 				if !user.CanLogin() {
-					return nil, fmt.Errorf("user account is not active")
+					s.auditLoginFailure(ctx, &user, loginFailureAccountInactive, deviceInfo, ipAddress)
+					return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
 				}
 
 				// Authenticate this specific user
 				return s.LoginWithTenant(ctx, user.TenantID, req, deviceInfo, ipAddress)
 			}
 		}
-		return nil, fmt.Errorf("invalid tenant selection")
+		return nil, utils.NewBadRequestError("INVALID_TENANT_SELECTION", "invalid tenant selection")
 	}
 
 	// First login attempt - verify password with first user found
 	// (all users with same email should have same password)
 	if !utils.VerifyPassword(req.Password, users[0].PasswordHash) {
-		return nil, fmt.Errorf("invalid email or password")
+		// Each tenant the email belongs to sees the attempt
+		for i := range users {
+			s.auditLoginFailure(ctx, &users[i], loginFailureInvalidPassword, deviceInfo, ipAddress)
+		}
+		return nil, utils.NewUnauthorizedError("INVALID_CREDENTIALS", "invalid email or password")
 	}
 
 	// Check how many tenants user belongs to
@@ -261,7 +364,8 @@ func (s *AuthService) Login(ctx context.Context, req *models.UserLoginRequest, d
 
 	// Check user status
 	if !user.CanLogin() {
-		return nil, fmt.Errorf("user account is not active")
+		s.auditLoginFailure(ctx, user, loginFailureAccountInactive, deviceInfo, ipAddress)
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
 	}
 
 	return s.LoginWithTenant(ctx, user.TenantID, req, deviceInfo, ipAddress)
@@ -272,34 +376,105 @@ func (s *AuthService) LoginWithTenant(ctx context.Context, tenantID uuid.UUID, r
 	// Find tenant
 	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("tenant not found")
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	// Check tenant status
 	if !tenant.CanAccess() {
-		return nil, fmt.Errorf("tenant account is not active")
+		return nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+
+	// Tenants can require their users to sign in with single sign-on
+	if tenant.SSORequired() {
+		return nil, utils.NewForbiddenError("SSO_REQUIRED", "This organization requires single sign-on")
 	}
 
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(ctx, tenantID, req.Email)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, utils.NewUnauthorizedError("INVALID_CREDENTIALS", "invalid email or password")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, err
 	}
 
 	// Verify password
 	if !utils.VerifyPassword(req.Password, user.PasswordHash) {
-		return nil, fmt.Errorf("invalid email or password")
+		s.auditLoginFailure(ctx, user, loginFailureInvalidPassword, deviceInfo, ipAddress)
+		return nil, utils.NewUnauthorizedError("INVALID_CREDENTIALS", "invalid email or password")
 	}
 
 	// Check user status
 	if !user.CanLogin() {
-		return nil, fmt.Errorf("user account is not active")
+		s.auditLoginFailure(ctx, user, loginFailureAccountInactive, deviceInfo, ipAddress)
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	// Passkey-only users sign in with their passkey, never their password
+	if user.PasskeyOnly {
+		return nil, utils.NewForbiddenError("PASSKEY_REQUIRED", "This account signs in with a passkey")
+	}
+
+	// Tenants can require 2FA; past their grace period, users without it
+	// must set it up to sign in
+	deadline, err := s.twoFactorSetupDeadline(ctx, tenant, user)
+	if err != nil {
+		return nil, err
+	}
+	if deadline != nil && !time.Now().Before(*deadline) {
+		return s.requireTwoFactorSetup(ctx, user, tenant, deviceInfo, ipAddress)
 	}
 
-	// Check if 2FA is enabled
+	response, err := s.completeLogin(ctx, user, tenant, req.RememberMe, deviceInfo, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	response.TwoFactorSetupDeadline = deadline
+
+	return response, nil
+}
+
+// secondFactorMethods returns the second factors a user can verify a
+// password sign-in with; empty if they have none
+func (s *AuthService) secondFactorMethods(ctx context.Context, user *models.User) ([]string, error) {
+	methods := []string{}
 	if user.TwoFactorEnabled {
+		methods = append(methods, models.TwoFactorMethodTOTP)
+	}
+	keys, err := s.webauthnRepo.CountByUser(ctx, user.TenantID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if keys > 0 {
+		methods = append(methods, models.TwoFactorMethodWebAuthn)
+	}
+	return methods, nil
+}
+
+// completeLogin logs an authenticated user in: it asks for their second
+// factor if 2FA is enabled or they registered a security key, and creates
+// the session otherwise
+func (s *AuthService) completeLogin(
+	ctx context.Context,
+	user *models.User,
+	tenant *models.Tenant,
+	rememberMe bool,
+	deviceInfo utils.DeviceInfo,
+	ipAddress string,
+) (*models.UserLoginResponse, error) {
+	methods, err := s.secondFactorMethods(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditLogin(ctx, user, models.ActionUserLogin, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"two_factor_required": len(methods) > 0,
+	})
+
+	// Check if a second factor is required
+	if len(methods) > 0 {
 		// Generate temporary 2FA token
-		twoFactorToken, err := s.jwtService.Generate2FAToken(user.ID, tenantID, tenant.Slug, user.Email)
+		twoFactorToken, err := s.jwtService.Generate2FAToken(user.ID, tenant.ID, tenant.Slug, user.Email)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate 2FA token: %w", err)
 		}
@@ -308,41 +483,129 @@ func (s *AuthService) LoginWithTenant(ctx context.Context, tenantID uuid.UUID, r
 			User:              user,
 			RequiresTwoFactor: true,
 			TwoFactorToken:    twoFactorToken,
+			TwoFactorMethods:  methods,
 		}, nil
 	}
 
 	// Create session and generate tokens
-	return s.createSessionAndTokens(ctx, user, tenant, req.RememberMe, deviceInfo, ipAddress)
+	return s.createSessionAndTokens(ctx, user, tenant, rememberMe, deviceInfo, ipAddress)
+}
+
+// Reasons recorded with failed sign-ins
+const (
+	loginFailureInvalidPassword        = "invalid_password"
+	loginFailureAccountInactive        = "account_inactive"
+	loginFailureTwoFactorSetupRequired = "two_factor_setup_required"
+	loginFailureSessionLimitReached    = "session_limit_reached"
+)
+
+// auditLoginFailure records a failed sign-in of a known user in their
+// tenant's audit log. Attempts with an unknown email belong to no tenant and
+// are not recorded.
+func (s *AuthService) auditLoginFailure(ctx context.Context, user *models.User, reason string, deviceInfo utils.DeviceInfo, ipAddress string) {
+	s.auditLogin(ctx, user, models.ActionUserLoginFailed, models.AuditStatusFailure, deviceInfo, ipAddress, map[string]interface{}{
+		"reason": reason,
+	})
+}
+
+// auditLogin records a sign-in attempt; failing to record it does not fail
+// the sign-in
+func (s *AuthService) auditLogin(ctx context.Context, user *models.User, action, status string, deviceInfo utils.DeviceInfo, ipAddress string, metadata map[string]interface{}) {
+	err := s.auditService.LogEvent(ctx, user.TenantID, user.ID, action, models.ResourceUsers, user.ID, status, ipAddress, deviceInfo.UserAgent, metadata)
+	if err != nil {
+		log.Printf("⚠️  Failed to audit sign-in of user %s: %v", user.ID, err)
+	}
 }
 
-// Verify2FAAndLogin verifies 2FA code and completes login
+// Verify2FAAndLogin completes a password sign-in with a code of the user's
+// authenticator app, or one of their backup codes, which is consumed
 func (s *AuthService) Verify2FAAndLogin(ctx context.Context, twoFactorToken, code string, deviceInfo utils.DeviceInfo, ipAddress string, rememberMe bool) (*models.UserLoginResponse, error) {
-	// Validate 2FA token
 	claims, err := s.jwtService.Validate2FAToken(twoFactorToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid or expired 2FA token")
+		return nil, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
 	}
 
-	// Find user
-	_, err = s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
+	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return nil, utils.NewBadRequestError("TWO_FACTOR_NOT_ENABLED", "2FA not enabled")
 	}
 
-	// Verify 2FA code
-	// TODO: Implement TOTP verification using user.TwoFactorSecret
-	// For now, return error
-	return nil, fmt.Errorf("2FA verification not yet implemented")
+	method, err := s.verifyTwoFactorCode(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if method == "" {
+		s.auditLogin(ctx, user, models.Action2FAFailed, models.AuditStatusFailure, deviceInfo, ipAddress, map[string]interface{}{
+			"method": models.TwoFactorMethodTOTP,
+		})
+		return nil, utils.NewUnauthorizedError("INVALID_2FA_CODE", "invalid 2FA code")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if !tenant.CanAccess() {
+		return nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+	if !user.CanLogin() {
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	s.auditLogin(ctx, user, models.Action2FAVerified, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"method": method,
+	})
+
+	return s.createSessionAndTokens(ctx, user, tenant, rememberMe, deviceInfo, ipAddress)
 }
 
-// Logout logs out a user by deleting their session
-func (s *AuthService) Logout(ctx context.Context, tenantID uuid.UUID, tokenHash string) error {
-	return s.sessionRepo.DeleteByTokenHash(ctx, tenantID, tokenHash)
+// verifyTwoFactorCode checks a code against the user's TOTP secret, then
+// their backup codes. It returns the method the code matched, or "" if it
+// matched neither.
+func (s *AuthService) verifyTwoFactorCode(ctx context.Context, user *models.User, code string) (string, error) {
+	valid, err := s.twoFactor.VerifyTOTP(ctx, user.TenantID, user.ID, code)
+	if err != nil {
+		return "", err
+	}
+	if valid {
+		return models.TwoFactorMethodTOTP, nil
+	}
+
+	valid, err = s.twoFactor.VerifyBackupCode(ctx, user.TenantID, user.ID, code)
+	if err != nil {
+		var domainErr *utils.DomainError
+		if errors.As(err, &domainErr) && domainErr.Code == "NO_BACKUP_CODES" {
+			return "", nil
+		}
+		return "", err
+	}
+	if valid {
+		return models.TwoFactorMethodBackupCode, nil
+	}
+
+	return "", nil
+}
+
+// Logout logs out a user by deleting the session of their access token
+func (s *AuthService) Logout(ctx context.Context, tenantID, userID uuid.UUID, accessToken string) error {
+	hash := sha256.Sum256([]byte(accessToken))
+	if err := s.sessionRepo.DeleteByTokenHash(ctx, tenantID, hex.EncodeToString(hash[:])); err != nil {
+		return err
+	}
+	invalidateSessionCache(ctx, s.redis, tenantID, userID)
+	return nil
 }
 
 // LogoutAll logs out a user from all devices
 func (s *AuthService) LogoutAll(ctx context.Context, tenantID, userID uuid.UUID) error {
-	return s.sessionRepo.DeleteAllByUser(ctx, tenantID, userID)
+	if err := s.sessionRepo.DeleteAllByUser(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	invalidateSessionCache(ctx, s.redis, tenantID, userID)
+	return nil
 }
 
 // RefreshToken generates new access token from refresh token
@@ -350,23 +613,30 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 	// Validate refresh token
 	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, utils.NewUnauthorizedError("INVALID_REFRESH_TOKEN", "invalid refresh token")
 	}
 
 	// Find user
 	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	// Check if user is still active
 	if !user.CanLogin() {
-		return nil, fmt.Errorf("user account is not active")
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	// The tenant may have been renamed since: the new token carries its
+	// current slug
+	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
 	}
 
 	// Generate new access token
 	accessToken, expiresIn, err := s.jwtService.GenerateAccessToken(
-		user.ID, claims.TenantID, claims.TenantSlug, user.Email, false,
+		user.ID, tenant.ID, tenant.Slug, user.Email, claims.Sandbox, false,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
@@ -389,6 +659,45 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, tenantID uuid.UU
 		return nil
 	}
 
+	return s.sendPasswordReset(ctx, tenantID, user)
+}
+
+// ForcePasswordReset makes a user of a tenant choose a new password: their
+// current one stops working, their sessions are revoked and the reset email
+// is sent to them. Used by platform admins, e.g. on a leaked password.
+func (s *AuthService) ForcePasswordReset(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error) {
+	user, err := s.userRepo.FindByEmail(ctx, tenantID, email)
+	if err != nil {
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	// Nobody knows the replacement: like SSO-provisioned users, the user
+	// has no usable password until they reset it
+	password, err := utils.GenerateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := utils.HashPassword(password, s.config.Security.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.ReplacePasswordHash(ctx, tenantID, user.ID, passwordHash); err != nil {
+		return nil, fmt.Errorf("failed to replace password: %w", err)
+	}
+
+	s.sessionRepo.DeleteAllByUser(ctx, tenantID, user.ID)
+	invalidateSessionCache(ctx, s.redis, tenantID, user.ID)
+
+	if err := s.sendPasswordReset(ctx, tenantID, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// sendPasswordReset gives a user a new reset token and queues the email
+// with it
+func (s *AuthService) sendPasswordReset(ctx context.Context, tenantID uuid.UUID, user *models.User) error {
 	// Generate reset token
 	resetToken := uuid.New()
 	expiresAt := time.Now().Add(s.config.Security.PasswordResetExpiry).Format(time.RFC3339)
@@ -398,9 +707,18 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, tenantID uuid.UU
 		return fmt.Errorf("failed to set reset token: %w", err)
 	}
 
-	// Send reset email
-	if err := s.emailService.SendPasswordResetEmail(user.Email, user.FirstName, resetToken); err != nil {
-		fmt.Printf("Failed to send password reset email: %v\n", err)
+	// Queue reset email (delivered with retry by the email worker), in the
+	// tenant's branding
+	var branding *models.TenantBranding
+	if tenant, err := s.tenantRepo.FindByID(ctx, tenantID); err == nil {
+		branding = tenant.Branding()
+	}
+	msg, err := s.emailService.PasswordResetEmail(user.Email, user.Language, user.FirstName, branding, resetToken)
+	if err != nil {
+		return fmt.Errorf("failed to render password reset email: %w", err)
+	}
+	if err := s.emailQueue.EnqueueStandalone(ctx, tenantID, msg); err != nil {
+		return fmt.Errorf("failed to queue password reset email: %w", err)
 	}
 
 	return nil
@@ -410,13 +728,17 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, tenantID uuid.UU
 func (s *AuthService) ResetPassword(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, newPassword string) error {
 	// Validate password
 	if valid, msg := utils.IsValidPassword(newPassword); !valid {
-		return fmt.Errorf(msg)
+		return utils.NewBadRequestError("WEAK_PASSWORD", msg)
 	}
 
 	// Find user by reset token
 	user, err := s.userRepo.FindByResetToken(ctx, tenantID, token)
 	if err != nil {
-		return fmt.Errorf("invalid or expired reset token")
+		return utils.NewBadRequestError("INVALID_RESET_TOKEN", "invalid or expired reset token")
+	}
+
+	if err := s.checkPasswordHistory(ctx, tenantID, user, newPassword); err != nil {
+		return err
 	}
 
 	// Hash new password
@@ -426,12 +748,21 @@ func (s *AuthService) ResetPassword(ctx context.Context, tenantID uuid.UUID, tok
 	}
 
 	// Update password
-	if err := s.userRepo.UpdatePassword(ctx, tenantID, user.ID, hashedPassword); err != nil {
+	if err := s.userRepo.UpdatePassword(ctx, tenantID, user.ID, hashedPassword, s.config.Security.PasswordHistoryCount); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	// The emailed token proves the address is theirs (imported users set their
+	// first password this way)
+	if !user.EmailVerified {
+		if err := s.userRepo.VerifyEmail(ctx, tenantID, user.ID); err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+	}
+
 	// Revoke all existing sessions for security
 	s.sessionRepo.DeleteAllByUser(ctx, tenantID, user.ID)
+	invalidateSessionCache(ctx, s.redis, tenantID, user.ID)
 
 	return nil
 }
@@ -440,18 +771,22 @@ func (s *AuthService) ResetPassword(ctx context.Context, tenantID uuid.UUID, tok
 func (s *AuthService) ChangePassword(ctx context.Context, tenantID, userID uuid.UUID, currentPassword, newPassword string) error {
 	// Validate new password
 	if valid, msg := utils.IsValidPassword(newPassword); !valid {
-		return fmt.Errorf(msg)
+		return utils.NewBadRequestError("WEAK_PASSWORD", msg)
 	}
 
 	// Find user
 	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	// Verify current password
 	if !utils.VerifyPassword(currentPassword, user.PasswordHash) {
-		return fmt.Errorf("current password is incorrect")
+		return utils.NewBadRequestError("CURRENT_PASSWORD_INCORRECT", "current password is incorrect")
+	}
+
+	if err := s.checkPasswordHistory(ctx, tenantID, user, newPassword); err != nil {
+		return err
 	}
 
 	// Hash new password
@@ -461,13 +796,125 @@ func (s *AuthService) ChangePassword(ctx context.Context, tenantID, userID uuid.
 	}
 
 	// Update password
-	if err := s.userRepo.UpdatePassword(ctx, tenantID, userID, hashedPassword); err != nil {
+	if err := s.userRepo.UpdatePassword(ctx, tenantID, userID, hashedPassword, s.config.Security.PasswordHistoryCount); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
 	return nil
 }
 
+// RequestEmailChange emails a link confirming a new email address to it. The
+// user keeps signing in with their current email until they confirm.
+func (s *AuthService) RequestEmailChange(ctx context.Context, tenantID, userID uuid.UUID, newEmail, password string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	// Users signing in with single sign-on have no password to confirm
+	if user.PasswordHash != "" && !utils.VerifyPassword(password, user.PasswordHash) {
+		return nil, utils.NewBadRequestError("PASSWORD_INCORRECT", "password is incorrect")
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, utils.NewValidationError("SAME_EMAIL", "new email is the same as the current one")
+	}
+
+	exists, err := s.userRepo.CheckEmailExists(ctx, tenantID, newEmail)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.NewConflictError("EMAIL_IN_USE", "email already in use")
+	}
+
+	token := uuid.New()
+	expiresAt := time.Now().Add(s.config.Security.VerificationExpiry)
+	if err := s.userRepo.SetEmailChange(ctx, tenantID, userID, newEmail, token, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to set email change: %w", err)
+	}
+
+	msg, err := s.emailService.EmailChangeEmail(newEmail, user.Language, user.FirstName, user.Email, token, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render email change email: %w", err)
+	}
+	if err := s.emailQueue.EnqueueStandalone(ctx, tenantID, msg); err != nil {
+		return nil, fmt.Errorf("failed to queue email change email: %w", err)
+	}
+
+	user.PendingEmail = &newEmail
+	return user, nil
+}
+
+// ConfirmEmailChange makes the email a user asked to change to theirs, using
+// the emailed token. Every session is revoked, so the user signs in again
+// with the new email, and the change is audited.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, tenantID, token uuid.UUID, ipAddress, userAgent string) (*models.User, error) {
+	user, err := s.userRepo.FindByEmailChangeToken(ctx, tenantID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The address may have been taken since the change was asked for
+	exists, err := s.userRepo.CheckEmailExists(ctx, tenantID, *user.PendingEmail)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.NewConflictError("EMAIL_IN_USE", "email already in use")
+	}
+
+	if err := s.userRepo.ConfirmEmailChange(ctx, tenantID, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+
+	// Revoke all existing sessions for security
+	s.sessionRepo.DeleteAllByUser(ctx, tenantID, user.ID)
+	invalidateSessionCache(ctx, s.redis, tenantID, user.ID)
+
+	oldEmail := user.Email
+	user.Email = *user.PendingEmail
+	user.EmailVerified = true
+	user.PendingEmail = nil
+
+	err = s.auditService.LogEvent(ctx, tenantID, user.ID, models.ActionUserEmailChanged, models.ResourceUsers, user.ID, models.AuditStatusSuccess, ipAddress, userAgent, map[string]interface{}{
+		"old_email": oldEmail,
+		"new_email": user.Email,
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to audit email change of user %s: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// checkPasswordHistory rejects a new password matching the user's current
+// password or one of the passwords they replaced recently
+func (s *AuthService) checkPasswordHistory(ctx context.Context, tenantID uuid.UUID, user *models.User, newPassword string) error {
+	count := s.config.Security.PasswordHistoryCount
+	if count <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	if count > 1 {
+		history, err := s.userRepo.GetPasswordHistory(ctx, tenantID, user.ID, count-1)
+		if err != nil {
+			return fmt.Errorf("failed to check password history: %w", err)
+		}
+		hashes = append(hashes, history...)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && utils.VerifyPassword(newPassword, hash) {
+			return utils.NewBadRequestError("PASSWORD_REUSED", "password was used recently, choose a different password")
+		}
+	}
+
+	return nil
+}
+
 // createSessionAndTokens creates a session and generates JWT tokens
 func (s *AuthService) createSessionAndTokens(
 	ctx context.Context,
@@ -479,14 +926,14 @@ func (s *AuthService) createSessionAndTokens(
 ) (*models.UserLoginResponse, error) {
 	// Generate tokens
 	accessToken, expiresIn, err := s.jwtService.GenerateAccessToken(
-		user.ID, tenant.ID, tenant.Slug, user.Email, rememberMe,
+		user.ID, tenant.ID, tenant.Slug, user.Email, tenant.IsSandbox(), rememberMe,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	refreshToken, err := s.jwtService.GenerateRefreshToken(
-		user.ID, tenant.ID, tenant.Slug, user.Email,
+		user.ID, tenant.ID, tenant.Slug, user.Email, tenant.IsSandbox(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
@@ -515,12 +962,21 @@ func (s *AuthService) createSessionAndTokens(
 		IPAddress:  ipAddress,
 		UserAgent:  deviceInfo.UserAgent,
 		ExpiresAt:  time.Now().Add(sessionExpiry),
+		Limit:      s.sessionLimit(tenant),
 	}
 
-	_, err = s.sessionRepo.Create(ctx, sessionReq)
+	_, evicted, err := s.sessionRepo.Create(ctx, sessionReq)
 	if err != nil {
+		if errors.Is(err, utils.ErrConflict) {
+			s.auditLoginFailure(ctx, user, loginFailureSessionLimitReached, deviceInfo, ipAddress)
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	if len(evicted) > 0 {
+		invalidateSessionCache(ctx, s.redis, tenant.ID, user.ID)
+		s.auditSessionsEvicted(ctx, user, evicted, sessionReq.Limit, deviceInfo, ipAddress)
+	}
 
 	// Update last login
 	if err := s.userRepo.UpdateLastLogin(ctx, tenant.ID, user.ID, ipAddress); err != nil {
@@ -550,28 +1006,124 @@ func (s *AuthService) ValidateSession(ctx context.Context, accessToken string) (
 	tokenHash := hex.EncodeToString(hash[:])
 
 	// Find session
-	session, err := s.sessionRepo.FindByTokenHash(ctx, claims.TenantID, tokenHash)
+	session, err := s.findSession(ctx, claims.TenantID, claims.UserID, tokenHash)
 	if err != nil {
-		return nil, nil, fmt.Errorf("session not found or expired")
+		return nil, nil, utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found or expired")
 	}
 
-	// Update session activity
-	if err := s.sessionRepo.UpdateActivity(ctx, claims.TenantID, session.ID); err != nil {
-		// Log error but don't fail validation
-		fmt.Printf("Failed to update session activity: %v\n", err)
-	}
+	// Record session activity; it reaches the database on the next flush
+	s.recordActivity(ctx, claims.TenantID, session.ID)
 
 	// Find user
 	user, err := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("user not found")
+		return nil, nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	// Find tenant
 	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("tenant not found")
+		return nil, nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	// Expired sandboxes are refused until the expiry job removes them
+	if tenant.IsSandboxExpired() {
+		return nil, nil, utils.NewForbiddenError("SANDBOX_EXPIRED", "sandbox has expired")
 	}
 
 	return user, tenant, nil
 }
+
+// recordActivity buffers a session heartbeat in Redis. Writing it to the
+// database on every request is left to FlushSessionActivity, so each session
+// row is written at most once per flush interval. If Redis is unavailable the
+// heartbeat is written directly.
+func (s *AuthService) recordActivity(ctx context.Context, tenantID, sessionID uuid.UUID) {
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionActivityKey(sessionID), time.Now().UnixMilli(), sessionActivityTTL)
+		pipe.SAdd(ctx, sessionActivityDirtyKey, tenantID.String()+":"+sessionID.String())
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	if err := s.sessionRepo.UpdateActivity(ctx, tenantID, sessionID); err != nil {
+		// Log error but don't fail validation
+		fmt.Printf("Failed to update session activity: %v\n", err)
+	}
+}
+
+// FlushSessionActivity writes the session heartbeats buffered since the last
+// flush to the database, one update per tenant. Returns the number of
+// sessions written.
+func (s *AuthService) FlushSessionActivity(ctx context.Context) (int, error) {
+	flushed := 0
+	for flushed < sessionActivityFlushSize {
+		members, err := s.redis.SPopN(ctx, sessionActivityDirtyKey, sessionActivityBatchSize).Result()
+		if err != nil {
+			return flushed, fmt.Errorf("failed to read session activity: %w", err)
+		}
+		if len(members) == 0 {
+			break
+		}
+
+		keys := make([]string, len(members))
+		sessionIDs := make([]uuid.UUID, len(members))
+		tenantIDs := make([]uuid.UUID, len(members))
+		for i, member := range members {
+			tenantPart, sessionPart, _ := strings.Cut(member, ":")
+			tenantIDs[i], _ = uuid.Parse(tenantPart)
+			sessionIDs[i], _ = uuid.Parse(sessionPart)
+			keys[i] = sessionActivityKey(sessionIDs[i])
+		}
+
+		values, err := s.redis.MGet(ctx, keys...).Result()
+		if err != nil {
+			s.redis.SAdd(ctx, sessionActivityDirtyKey, toInterfaces(members)...)
+			return flushed, fmt.Errorf("failed to read session activity: %w", err)
+		}
+
+		byTenant := make(map[uuid.UUID]map[uuid.UUID]time.Time)
+		for i, value := range values {
+			raw, ok := value.(string)
+			if !ok || tenantIDs[i] == uuid.Nil || sessionIDs[i] == uuid.Nil {
+				// Expired or malformed entries have nothing to write
+				continue
+			}
+			ms, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				continue
+			}
+			if byTenant[tenantIDs[i]] == nil {
+				byTenant[tenantIDs[i]] = make(map[uuid.UUID]time.Time)
+			}
+			byTenant[tenantIDs[i]][sessionIDs[i]] = time.UnixMilli(ms)
+		}
+
+		for tenantID, activity := range byTenant {
+			if err := s.sessionRepo.UpdateActivityBatch(ctx, tenantID, activity); err != nil {
+				// Retry the tenant's sessions on the next flush
+				retry := make([]interface{}, 0, len(activity))
+				for sessionID := range activity {
+					retry = append(retry, tenantID.String()+":"+sessionID.String())
+				}
+				s.redis.SAdd(ctx, sessionActivityDirtyKey, retry...)
+				log.Printf("⚠️  Failed to flush session activity for tenant %s: %v", tenantID, err)
+				continue
+			}
+			flushed += len(activity)
+		}
+	}
+
+	return flushed, nil
+}
+
+// toInterfaces converts strings to arguments for variadic Redis commands
+func toInterfaces(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// sessionLimit returns the concurrent session limit of a tenant's users:
+// the tenant's own, or the server default
+func (s *AuthService) sessionLimit(tenant *models.Tenant) models.SessionLimit {
+	if limit := tenant.SessionLimit(); limit != nil {
+		return *limit
+	}
+	return models.SessionLimit{
+		MaxSessions: s.config.Security.MaxConcurrentSessions,
+		Policy:      s.config.Security.SessionLimitPolicy,
+	}
+}
+
+// auditSessionsEvicted records the sessions a sign-in signed out to stay
+// within the concurrent session limit
+func (s *AuthService) auditSessionsEvicted(ctx context.Context, user *models.User, sessionIDs []uuid.UUID, limit models.SessionLimit, deviceInfo utils.DeviceInfo, ipAddress string) {
+	for _, sessionID := range sessionIDs {
+		err := s.auditService.LogEvent(ctx, user.TenantID, user.ID, models.ActionSessionEvicted, models.AuditResourceSessions, sessionID, models.AuditStatusSuccess, ipAddress, deviceInfo.UserAgent, map[string]interface{}{
+			"max_sessions": limit.MaxSessions,
+		})
+		if err != nil {
+			log.Printf("⚠️  Failed to audit evicted session %s: %v", sessionID, err)
+		}
+	}
+}
+
+// GetSessionLimit returns the concurrent session limit of a tenant's users
+func (s *AuthService) GetSessionLimit(ctx context.Context, tenantID uuid.UUID) (*models.SessionLimit, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	limit := s.sessionLimit(tenant)
+	return &limit, nil
+}
+
+// UpdateSessionLimit changes the concurrent session limit of a tenant's
+// users. Users already past a lowered limit keep their sessions until they
+// sign in again.
+func (s *AuthService) UpdateSessionLimit(ctx context.Context, tenantID uuid.UUID, req *models.SessionLimitUpdateRequest) (*models.SessionLimit, error) {
+	limit, err := s.GetSessionLimit(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MaxSessions != nil {
+		limit.MaxSessions = *req.MaxSessions
+	}
+	if req.Policy != nil {
+		limit.Policy = *req.Policy
+	}
+
+	if err := s.tenantRepo.SetSessionLimit(ctx, tenantID, limit); err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
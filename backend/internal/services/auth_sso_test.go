@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository/mocks"
+	"myerp-v2/internal/utils"
+)
+
+func TestSSOUser_NewIdentity(t *testing.T) {
+	tenant := &models.Tenant{ID: uuid.New()}
+	existing := &models.User{ID: uuid.New(), TenantID: tenant.ID, Email: "jane@acme.com"}
+
+	tests := []struct {
+		name           string
+		profile        *ssoProfile
+		allowedDomains []string
+		autoProvision  bool
+		existingUser   bool
+		wantCode       string
+	}{
+		{
+			name:           "Unverified email of an allowed domain cannot link to an existing user",
+			profile:        &ssoProfile{Subject: "idp-1", Email: "jane@acme.com"},
+			allowedDomains: []string{"acme.com"},
+			existingUser:   true,
+			wantCode:       "SSO_EMAIL_NOT_VERIFIED",
+		},
+		{
+			name:           "Unverified email of an allowed domain is not provisioned",
+			profile:        &ssoProfile{Subject: "idp-1", Email: "new@acme.com"},
+			allowedDomains: []string{"acme.com"},
+			autoProvision:  true,
+			wantCode:       "SSO_EMAIL_NOT_VERIFIED",
+		},
+		{
+			name:     "Unverified email cannot link to an existing user",
+			profile:  &ssoProfile{Subject: "idp-1", Email: "jane@acme.com"},
+			wantCode: "SSO_EMAIL_NOT_VERIFIED",
+		},
+		{
+			name:           "Verified email of another domain",
+			profile:        &ssoProfile{Subject: "idp-1", Email: "jane@other.com", EmailVerified: true},
+			allowedDomains: []string{"acme.com"},
+			existingUser:   true,
+			wantCode:       "SSO_EMAIL_DOMAIN_NOT_ALLOWED",
+		},
+		{
+			name:     "Verified email without an account",
+			profile:  &ssoProfile{Subject: "idp-1", Email: "new@acme.com", EmailVerified: true},
+			wantCode: "SSO_NO_ACCOUNT",
+		},
+		{
+			name:           "Verified email links to the existing user",
+			profile:        &ssoProfile{Subject: "idp-1", Email: "Jane@Acme.com", EmailVerified: true},
+			allowedDomains: []string{"acme.com"},
+			existingUser:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &models.SSOProvider{ID: uuid.New(), AllowedDomains: tt.allowedDomains, AutoProvision: tt.autoProvision}
+
+			var linked *models.SSOIdentity
+			verified := false
+			ssoRepo := &mocks.SSOStore{
+				FindIdentityFunc: func(ctx context.Context, tenantID, providerID uuid.UUID, subject string) (*models.SSOIdentity, error) {
+					return nil, utils.NewNotFoundError("SSO_IDENTITY_NOT_FOUND", "sso identity not found")
+				},
+				LinkIdentityFunc: func(ctx context.Context, identity *models.SSOIdentity) error {
+					linked = identity
+					return nil
+				},
+			}
+			userRepo := &mocks.UserStore{
+				FindByEmailFunc: func(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error) {
+					if tt.existingUser && email == existing.Email {
+						user := *existing
+						return &user, nil
+					}
+					return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+				},
+				VerifyEmailFunc: func(ctx context.Context, tenantID, userID uuid.UUID) error {
+					verified = true
+					return nil
+				},
+			}
+			s := &AuthService{ssoRepo: ssoRepo, userRepo: userRepo}
+
+			user, err := s.ssoUser(context.Background(), tenant, provider, tt.profile)
+
+			if tt.wantCode != "" {
+				var domainErr *utils.DomainError
+				require.True(t, errors.As(err, &domainErr), "expected a domain error, got %v", err)
+				assert.Equal(t, tt.wantCode, domainErr.Code)
+				assert.Nil(t, linked, "identity must not be linked")
+				assert.False(t, verified, "email must not be verified")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, existing.ID, user.ID)
+			require.NotNil(t, linked)
+			assert.Equal(t, existing.ID, linked.UserID)
+			assert.Equal(t, "idp-1", linked.Subject)
+			assert.True(t, verified, "the provider vouches for the email")
+			assert.True(t, user.EmailVerified)
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// WebAuthn ceremonies a challenge is issued for
+const (
+	webauthnCeremonyRegister = "register"
+	webauthnCeremonyLogin    = "login"
+	webauthnCeremony2FA      = "2fa"
+)
+
+// webauthnDefaultCredentialName names credentials registered without a name
+const webauthnDefaultCredentialName = "Passkey"
+
+// webauthnTransports are the transports stored with a credential
+var webauthnTransports = map[string]bool{"usb": true, "nfc": true, "ble": true, "internal": true, "hybrid": true, "smart-card": true}
+
+// webauthnCeremony is what a started ceremony keeps until the browser
+// answers its challenge, stored in Redis under the challenge. The browser
+// signs the challenge into its client data, which is how the answer finds it.
+type webauthnCeremony struct {
+	Type     string    `json:"type"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"` // uuid.Nil for sign-ins with any passkey
+}
+
+func webauthnChallengeKey(challenge string) string {
+	return "webauthn:challenge:" + challenge
+}
+
+// startWebAuthnCeremony issues a challenge for a ceremony
+func (s *AuthService) startWebAuthnCeremony(ctx context.Context, ceremony *webauthnCeremony) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := encodeWebAuthnBytes(b)
+
+	data, err := json.Marshal(ceremony)
+	if err != nil {
+		return "", err
+	}
+	if err := s.redis.Set(ctx, webauthnChallengeKey(challenge), data, s.config.WebAuthn.ChallengeTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to save webauthn challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// resumeWebAuthnCeremony consumes the ceremony whose challenge the client
+// data answers. Challenges are single use, so answers cannot be replayed.
+func (s *AuthService) resumeWebAuthnCeremony(ctx context.Context, clientDataJSON []byte, ceremonyType string, tenantID uuid.UUID) (*webauthnCeremony, string, error) {
+	challenge, err := webauthnClientDataChallenge(clientDataJSON)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := s.redis.GetDel(ctx, webauthnChallengeKey(challenge)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, "", utils.NewBadRequestError("WEBAUTHN_CHALLENGE_EXPIRED", "webauthn challenge expired")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load webauthn challenge: %w", err)
+	}
+
+	var ceremony webauthnCeremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, "", fmt.Errorf("failed to load webauthn challenge: %w", err)
+	}
+	if ceremony.Type != ceremonyType || ceremony.TenantID != tenantID {
+		return nil, "", utils.NewBadRequestError("WEBAUTHN_CHALLENGE_EXPIRED", "webauthn challenge expired")
+	}
+
+	return &ceremony, challenge, nil
+}
+
+// webauthnDescriptors lists credentials for allowCredentials and
+// excludeCredentials
+func webauthnDescriptors(credentials []models.WebAuthnCredential) []models.WebAuthnCredentialDescriptor {
+	descriptors := make([]models.WebAuthnCredentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		descriptors[i] = models.WebAuthnCredentialDescriptor{
+			Type:       "public-key",
+			ID:         encodeWebAuthnBytes(credential.CredentialID),
+			Transports: credential.Transports,
+		}
+	}
+	return descriptors
+}
+
+// webauthnTimeout is the ceremony timeout browsers are given, in milliseconds
+func (s *AuthService) webauthnTimeout() int64 {
+	return s.config.WebAuthn.ChallengeTTL.Milliseconds()
+}
+
+// BeginWebAuthnRegistration starts registering a passkey or security key for
+// a user and returns the options to create it with
+func (s *AuthService) BeginWebAuthnRegistration(ctx context.Context, tenantID, userID uuid.UUID) (*models.WebAuthnCreationOptions, error) {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.webauthnRepo.ListByUser(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.startWebAuthnCeremony(ctx, &webauthnCeremony{Type: webauthnCeremonyRegister, TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]models.WebAuthnCredentialParam, len(webauthnAlgorithms))
+	for i, alg := range webauthnAlgorithms {
+		params[i] = models.WebAuthnCredentialParam{Type: "public-key", Alg: alg}
+	}
+
+	return &models.WebAuthnCreationOptions{
+		RP: models.WebAuthnRP{ID: s.config.WebAuthn.RPID, Name: s.config.WebAuthn.RPName},
+		User: models.WebAuthnUser{
+			ID:          encodeWebAuthnBytes(webauthnUserHandle(user.ID)),
+			Name:        user.Email,
+			DisplayName: user.FullName(),
+		},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            s.webauthnTimeout(),
+		ExcludeCredentials: webauthnDescriptors(existing),
+		AuthenticatorSelection: models.WebAuthnAuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "preferred",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishWebAuthnRegistration verifies the created credential and registers it
+func (s *AuthService) FinishWebAuthnRegistration(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error) {
+	clientDataJSON, err := decodeWebAuthnBytes(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed client data")
+	}
+	attestationObject, err := decodeWebAuthnBytes(req.Credential.Response.AttestationObject)
+	if err != nil {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed attestation object")
+	}
+
+	ceremony, challenge, err := s.resumeWebAuthnCeremony(ctx, clientDataJSON, webauthnCeremonyRegister, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID != userID {
+		return nil, utils.NewBadRequestError("WEBAUTHN_CHALLENGE_EXPIRED", "webauthn challenge expired")
+	}
+
+	verified, err := verifyWebAuthnRegistration(clientDataJSON, attestationObject, challenge, s.config.WebAuthn.RPID, s.config.WebAuthn.Origins)
+	if err != nil {
+		return nil, err
+	}
+
+	transports := pq.StringArray{}
+	for _, transport := range req.Credential.Response.Transports {
+		if webauthnTransports[transport] {
+			transports = append(transports, transport)
+		}
+	}
+
+	credential := &models.WebAuthnCredential{
+		TenantID:       tenantID,
+		UserID:         userID,
+		CredentialID:   verified.ID,
+		PublicKey:      verified.PublicKey,
+		SignCount:      int64(verified.SignCount),
+		Transports:     transports,
+		BackupEligible: verified.BackupEligible,
+		Name:           strings.TrimSpace(req.Name),
+	}
+	if verified.AAGUID != uuid.Nil {
+		credential.AAGUID = &verified.AAGUID
+	}
+	if credential.Name == "" {
+		credential.Name = webauthnDefaultCredentialName
+	}
+
+	if err := s.webauthnRepo.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// ListWebAuthnCredentials lists a user's passkeys and security keys
+func (s *AuthService) ListWebAuthnCredentials(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	return s.webauthnRepo.ListByUser(ctx, tenantID, userID)
+}
+
+// RenameWebAuthnCredential renames one of a user's credentials
+func (s *AuthService) RenameWebAuthnCredential(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error) {
+	return s.webauthnRepo.Rename(ctx, tenantID, userID, id, strings.TrimSpace(name))
+}
+
+// DeleteWebAuthnCredential removes one of a user's credentials
+func (s *AuthService) DeleteWebAuthnCredential(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error) {
+	return s.webauthnRepo.Delete(ctx, tenantID, userID, id)
+}
+
+// SetPasskeyOnly turns passkey-only sign-in on or off for a user. While it
+// is on, password sign-ins are refused.
+func (s *AuthService) SetPasskeyOnly(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error {
+	return s.webauthnRepo.SetPasskeyOnly(ctx, tenantID, userID, enabled)
+}
+
+// BeginWebAuthnLogin starts a passkey sign-in to a tenant. With an email the
+// user's credentials are allowed; without, the authenticator offers the
+// passkeys it holds for the site. Unknown emails get the same answer as
+// users without credentials, so the endpoint does not reveal who has an
+// account.
+func (s *AuthService) BeginWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, email string) (*models.WebAuthnRequestOptions, error) {
+	ceremony := &webauthnCeremony{Type: webauthnCeremonyLogin, TenantID: tenantID}
+	allowed := []models.WebAuthnCredentialDescriptor{}
+
+	if email != "" {
+		if user, err := s.userRepo.FindByEmail(ctx, tenantID, email); err == nil {
+			credentials, err := s.webauthnRepo.ListByUser(ctx, tenantID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			allowed = webauthnDescriptors(credentials)
+			ceremony.UserID = user.ID
+		}
+	}
+
+	challenge, err := s.startWebAuthnCeremony(ctx, ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		Timeout:          s.webauthnTimeout(),
+		RPID:             s.config.WebAuthn.RPID,
+		AllowCredentials: allowed,
+		UserVerification: "required",
+	}, nil
+}
+
+// FinishWebAuthnLogin verifies a passkey sign-in and creates the session. A
+// passkey verifies the user itself (PIN or biometrics), so no second factor
+// is asked.
+func (s *AuthService) FinishWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, req *models.WebAuthnLoginFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	credential, user, err := s.verifyWebAuthnAssertion(ctx, tenantID, uuid.Nil, webauthnCeremonyLogin, &req.Credential, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if !tenant.CanAccess() {
+		return nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+	if tenant.SSORequired() {
+		return nil, utils.NewForbiddenError("SSO_REQUIRED", "This organization requires single sign-on")
+	}
+
+	if !user.CanLogin() {
+		s.auditLoginFailure(ctx, user, loginFailureAccountInactive, deviceInfo, ipAddress)
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	s.auditLogin(ctx, user, models.ActionUserLogin, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"method":        models.TwoFactorMethodWebAuthn,
+		"credential_id": credential.ID,
+	})
+
+	return s.createSessionAndTokens(ctx, user, tenant, req.RememberMe, deviceInfo, ipAddress)
+}
+
+// BeginWebAuthn2FA starts the security key second factor of a password
+// sign-in and returns the options to sign with one of the user's keys
+func (s *AuthService) BeginWebAuthn2FA(ctx context.Context, twoFactorToken string) (*models.WebAuthnRequestOptions, error) {
+	claims, err := s.jwtService.Validate2FAToken(twoFactorToken)
+	if err != nil {
+		return nil, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
+	}
+
+	credentials, err := s.webauthnRepo.ListByUser(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) == 0 {
+		return nil, utils.NewBadRequestError("NO_SECURITY_KEYS", "no security keys registered")
+	}
+
+	challenge, err := s.startWebAuthnCeremony(ctx, &webauthnCeremony{Type: webauthnCeremony2FA, TenantID: claims.TenantID, UserID: claims.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		Timeout:          s.webauthnTimeout(),
+		RPID:             s.config.WebAuthn.RPID,
+		AllowCredentials: webauthnDescriptors(credentials),
+		UserVerification: "discouraged",
+	}, nil
+}
+
+// FinishWebAuthn2FA verifies the security key second factor of a password
+// sign-in and creates the session
+func (s *AuthService) FinishWebAuthn2FA(ctx context.Context, req *models.WebAuthn2FAFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	claims, err := s.jwtService.Validate2FAToken(req.TwoFactorToken)
+	if err != nil {
+		return nil, utils.NewUnauthorizedError("INVALID_2FA_TOKEN", "invalid or expired 2FA token")
+	}
+
+	credential, user, err := s.verifyWebAuthnAssertion(ctx, claims.TenantID, claims.UserID, webauthnCeremony2FA, &req.Credential, false)
+	if err != nil {
+		if user, findErr := s.userRepo.FindByID(ctx, claims.TenantID, claims.UserID); findErr == nil {
+			s.auditLogin(ctx, user, models.Action2FAFailed, models.AuditStatusFailure, deviceInfo, ipAddress, map[string]interface{}{
+				"method": models.TwoFactorMethodWebAuthn,
+			})
+		}
+		return nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, claims.TenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+	if !tenant.CanAccess() {
+		return nil, utils.NewForbiddenError("TENANT_INACTIVE", "tenant account is not active")
+	}
+	if !user.CanLogin() {
+		return nil, utils.NewForbiddenError("USER_INACTIVE", "user account is not active")
+	}
+
+	s.auditLogin(ctx, user, models.Action2FAVerified, models.AuditStatusSuccess, deviceInfo, ipAddress, map[string]interface{}{
+		"method":        models.TwoFactorMethodWebAuthn,
+		"credential_id": credential.ID,
+	})
+
+	return s.createSessionAndTokens(ctx, user, tenant, req.RememberMe, deviceInfo, ipAddress)
+}
+
+// verifyWebAuthnAssertion verifies a sign-in answer to a ceremony of a
+// tenant and returns the credential used and its user. userID, unless
+// uuid.Nil, is the user the credential must belong to. The signature counter
+// must increase, unless the authenticator keeps none, so answers from a
+// cloned authenticator are refused.
+func (s *AuthService) verifyWebAuthnAssertion(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	ceremonyType string,
+	answer *models.WebAuthnCredentialJSON,
+	requireUserVerification bool,
+) (*models.WebAuthnCredential, *models.User, error) {
+	clientDataJSON, err := decodeWebAuthnBytes(answer.Response.ClientDataJSON)
+	if err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed client data")
+	}
+	authenticatorData, err := decodeWebAuthnBytes(answer.Response.AuthenticatorData)
+	if err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed authenticator data")
+	}
+	signature, err := decodeWebAuthnBytes(answer.Response.Signature)
+	if err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed signature")
+	}
+	rawID, err := decodeWebAuthnBytes(answer.RawID)
+	if err != nil || len(rawID) == 0 {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed credential id")
+	}
+
+	ceremony, challenge, err := s.resumeWebAuthnCeremony(ctx, clientDataJSON, ceremonyType, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ceremony.UserID != userID && userID != uuid.Nil {
+		return nil, nil, utils.NewBadRequestError("WEBAUTHN_CHALLENGE_EXPIRED", "webauthn challenge expired")
+	}
+
+	credential, err := s.webauthnRepo.FindByCredentialID(ctx, tenantID, rawID)
+	if err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: unknown credential")
+	}
+	if ceremony.UserID != uuid.Nil && credential.UserID != ceremony.UserID {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: unknown credential")
+	}
+	if answer.Response.UserHandle != "" {
+		handle, err := decodeWebAuthnBytes(answer.Response.UserHandle)
+		if id, ok := webauthnUserFromHandle(handle); err != nil || !ok || id != credential.UserID {
+			return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: user handle mismatch")
+		}
+	}
+
+	authData, err := verifyWebAuthnAssertion(
+		clientDataJSON, authenticatorData, signature, credential.PublicKey, challenge, s.config.WebAuthn.RPID, s.config.WebAuthn.Origins,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if requireUserVerification && authData.flags&webauthnFlagUserVerified == 0 {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: user not verified")
+	}
+
+	signCount := int64(authData.signCount)
+	if !webauthnSignCountValid(credential.SignCount, signCount) {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: signature counter did not increase")
+	}
+	if err := s.webauthnRepo.RecordUse(ctx, tenantID, credential.ID, credential.SignCount, signCount); err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: signature counter did not increase")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenantID, credential.UserID)
+	if err != nil {
+		return nil, nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: unknown credential")
+	}
+
+	return credential, user, nil
+}
@@ -0,0 +1,646 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	automationBatchSize     = 50 // Max executions run per database per worker run
+	automationMaxConditions = 20
+	automationMaxActions    = 10
+)
+
+// AutomationService manages a tenant's automation rules. Rules are matched
+// against events when they are published (HandleEvent) and queued as
+// executions; the automation worker (ProcessQueue) then runs their actions in
+// order and records the outcome of each in the execution log.
+type AutomationService struct {
+	db                *sqlx.DB
+	automationRepo    repository.AutomationStore
+	userRepo          repository.UserStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	tenantRepo        repository.TenantStore
+	emailOutboxRepo   repository.EmailOutboxStore
+	emailService      EmailManager
+	webhookService    WebhookManager
+	permissionService PermissionManager
+	config            *config.Config
+}
+
+// NewAutomationService creates a new automation service
+func NewAutomationService(
+	db *sqlx.DB,
+	automationRepo repository.AutomationStore,
+	userRepo repository.UserStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	tenantRepo repository.TenantStore,
+	emailOutboxRepo repository.EmailOutboxStore,
+	emailService EmailManager,
+	webhookService WebhookManager,
+	permissionService PermissionManager,
+	cfg *config.Config,
+) *AutomationService {
+	return &AutomationService{
+		db:                db,
+		automationRepo:    automationRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		tenantRepo:        tenantRepo,
+		emailOutboxRepo:   emailOutboxRepo,
+		emailService:      emailService,
+		webhookService:    webhookService,
+		permissionService: permissionService,
+		config:            cfg,
+	}
+}
+
+// CreateRule creates a rule. Rules are enabled unless requested otherwise.
+func (s *AutomationService) CreateRule(ctx context.Context, tenantID, userID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error) {
+	if err := s.validateRule(ctx, tenantID, req, nil); err != nil {
+		return nil, err
+	}
+
+	rule := &models.AutomationRule{
+		TenantID:  tenantID,
+		IsEnabled: true,
+		CreatedBy: userID,
+	}
+	applyAutomationRuleRequest(rule, req)
+
+	if err := s.automationRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// ListRules lists a tenant's rules
+func (s *AutomationService) ListRules(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error) {
+	return s.automationRepo.List(ctx, tenantID, limit, offset)
+}
+
+// GetRule retrieves a rule
+func (s *AutomationService) GetRule(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.AutomationRule, error) {
+	return s.automationRepo.FindByID(ctx, tenantID, ruleID)
+}
+
+// UpdateRule replaces a rule's definition. Executions already queued run
+// with the updated actions.
+func (s *AutomationService) UpdateRule(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error) {
+	rule, err := s.automationRepo.FindByID(ctx, tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateRule(ctx, tenantID, req, &ruleID); err != nil {
+		return nil, err
+	}
+
+	applyAutomationRuleRequest(rule, req)
+
+	if err := s.automationRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// SetRuleEnabled enables or disables a rule. Executions of a disabled rule
+// that are still queued are not run.
+func (s *AutomationService) SetRuleEnabled(ctx context.Context, tenantID, ruleID uuid.UUID, enabled bool) (*models.AutomationRule, error) {
+	return s.automationRepo.SetEnabled(ctx, tenantID, ruleID, enabled)
+}
+
+// DeleteRule deletes a rule and its execution log
+func (s *AutomationService) DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error {
+	return s.automationRepo.Delete(ctx, tenantID, ruleID)
+}
+
+// ListExecutions lists executions, of one rule or of all rules when ruleID is
+// nil, optionally filtered by status
+func (s *AutomationService) ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error) {
+	if ruleID != nil {
+		if _, err := s.automationRepo.FindByID(ctx, tenantID, *ruleID); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.automationRepo.ListExecutions(ctx, tenantID, ruleID, status, limit, offset)
+}
+
+// GetExecution retrieves an execution with its action results
+func (s *AutomationService) GetExecution(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error) {
+	return s.automationRepo.FindExecution(ctx, tenantID, executionID)
+}
+
+// HandleEvent queues an execution for every enabled rule whose trigger and
+// conditions match the event. It is subscribed to the event bus.
+func (s *AutomationService) HandleEvent(ctx context.Context, event *models.Event) error {
+	rules, err := s.automationRepo.FindEnabledByTrigger(ctx, event.TenantID, event.Type)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	object := automationObject(event.Object)
+
+	var matched []uuid.UUID
+	for _, rule := range rules {
+		if automationConditionsMatch(rule.Conditions, object) {
+			matched = append(matched, rule.ID)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	return s.automationRepo.EnqueueExecutions(ctx, event.TenantID, matched, &models.AutomationEvent{
+		ID:           event.ID,
+		Type:         event.Type,
+		OccurredAt:   event.OccurredAt,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		ActorID:      event.ActorID,
+		Object:       event.Object,
+	})
+}
+
+// ProcessQueue runs pending executions in every data region. Returns the
+// number of executions run.
+func (s *AutomationService) ProcessQueue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		for i := 0; i < automationBatchSize; i++ {
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+
+			ran, err := s.runNext(ctx, db)
+			if err != nil {
+				return total, err
+			}
+			if !ran {
+				break
+			}
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// CleanupExecutions removes finished executions older than the retention
+// period from the execution log, in every data region
+func (s *AutomationService) CleanupExecutions(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Automation.ExecutionRetention)
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.automationRepo.DeleteFinishedBefore(ctx, db, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// runNext claims one pending execution, runs its rule's actions and records
+// their outcome. The row stays locked while running, so concurrent workers
+// never run it twice. Actions do not publish events, so rules cannot trigger
+// each other.
+func (s *AutomationService) runNext(ctx context.Context, db *sqlx.DB) (bool, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	execution, err := s.automationRepo.ClaimPending(ctx, tx, jobs.PausedTenants(ctx))
+	if err != nil || execution == nil {
+		return false, err
+	}
+
+	startedAt := time.Now()
+
+	// Once actions have run their outcome must be recorded, even if the
+	// worker is shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	status := models.AutomationExecutionSucceeded
+	results := models.AutomationActionResults{}
+
+	if !execution.RuleEnabled {
+		status = models.AutomationExecutionFailed
+		results = append(results, models.AutomationActionResult{Status: models.AutomationActionFailed, Error: "rule is disabled"})
+	} else {
+		var event models.AutomationEvent
+		if err := json.Unmarshal(execution.Event, &event); err != nil {
+			return false, fmt.Errorf("invalid automation event: %w", err)
+		}
+
+		// Actions run in order; a failing action stops the rest
+		for _, action := range execution.Actions {
+			result := models.AutomationActionResult{Type: action.Type, Status: models.AutomationActionSucceeded}
+
+			detail, err := s.runAction(ctx, tx, execution, &event, &action)
+			if err != nil {
+				result.Status = models.AutomationActionFailed
+				result.Error = err.Error()
+			}
+			result.Detail = detail
+			results = append(results, result)
+
+			if err != nil {
+				status = models.AutomationExecutionFailed
+				break
+			}
+		}
+	}
+
+	if status == models.AutomationExecutionFailed {
+		log.Printf("⚠️  Automation rule %q failed on %s event %s", execution.RuleName, execution.EventType, execution.EventID)
+	}
+
+	if err := s.automationRepo.Finish(ctx, tx, execution, status, results, startedAt); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record automation execution: %w", err)
+	}
+
+	return true, nil
+}
+
+// runAction runs one action of an execution and describes what it did
+func (s *AutomationService) runAction(ctx context.Context, tx *sqlx.Tx, execution *models.ClaimedAutomationExecution, event *models.AutomationEvent, action *models.AutomationAction) (string, error) {
+	switch action.Type {
+	case models.AutomationActionSendNotification:
+		return s.sendNotification(ctx, tx, execution.TenantID, event, action)
+
+	case models.AutomationActionAssignRole:
+		userID, err := automationResourceUser(event)
+		if err != nil {
+			return "", err
+		}
+		if _, err := s.userRepo.FindByID(ctx, execution.TenantID, userID); err != nil {
+			return "", err
+		}
+		if err := s.userRoleRepo.AssignRole(ctx, execution.TenantID, userID, *action.RoleID, execution.RuleCreatedBy); err != nil {
+			return "", err
+		}
+		if err := s.permissionService.InvalidateUserPermissions(ctx, execution.TenantID, userID); err != nil {
+			log.Printf("⚠️  Failed to invalidate permissions of user %s: %v", userID, err)
+		}
+		return fmt.Sprintf("role %s assigned to user %s", action.RoleID, userID), nil
+
+	case models.AutomationActionCallWebhook:
+		delivery, err := s.webhookService.DeliverTo(ctx, execution.TenantID, *action.WebhookID, &models.Event{
+			ID:           event.ID,
+			Type:         event.Type,
+			TenantID:     execution.TenantID,
+			OccurredAt:   event.OccurredAt,
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			ActorID:      event.ActorID,
+			Object:       event.Object,
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("webhook delivery %s queued", delivery.ID), nil
+	}
+
+	return "", fmt.Errorf("unsupported action type: %s", action.Type)
+}
+
+// sendNotification renders the action's templates and queues an email to
+// each recipient in the execution's transaction
+func (s *AutomationService) sendNotification(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, event *models.AutomationEvent, action *models.AutomationAction) (string, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	data := models.AutomationTemplateData{
+		CompanyName:  tenant.CompanyName,
+		EventType:    event.Type,
+		ResourceType: event.ResourceType,
+		Object:       automationObject(event.Object),
+	}
+	if event.ResourceID != nil {
+		data.ResourceID = event.ResourceID.String()
+	}
+
+	subject, err := renderAutomationSubject(action.Subject, data)
+	if err != nil {
+		return "", fmt.Errorf("invalid subject template: %v", err)
+	}
+	content, err := renderAutomationBody(action.Body, data)
+	if err != nil {
+		return "", fmt.Errorf("invalid body template: %v", err)
+	}
+
+	var sentTo []string
+	for _, recipient := range action.Recipients {
+		userID, err := automationRecipient(recipient, event)
+		if err != nil {
+			return "", err
+		}
+		user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+		if err != nil {
+			return "", fmt.Errorf("recipient %s: %v", recipient, err)
+		}
+
+		msg, err := s.emailService.AutomationEmail(user.Email, user.Language, tenant.CompanyName, subject, content)
+		if err != nil {
+			return "", err
+		}
+		if _, err := s.emailOutboxRepo.Enqueue(ctx, tx, tenantID, msg, s.config.Queues.EmailMaxPending); err != nil {
+			return "", err
+		}
+		sentTo = append(sentTo, user.Email)
+	}
+
+	return "email queued to " + strings.Join(sentTo, ", "), nil
+}
+
+// validateRule checks a rule definition, including that the roles and
+// webhooks its actions use exist
+func (s *AutomationService) validateRule(ctx context.Context, tenantID uuid.UUID, req *models.AutomationRuleRequest, excludeID *uuid.UUID) error {
+	exists, err := s.automationRepo.CheckNameExists(ctx, tenantID, req.Name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("AUTOMATION_RULE_NAME_EXISTS", "automation rule name already exists")
+	}
+
+	if !models.IsEventType(req.TriggerEvent) {
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid trigger event: %s", req.TriggerEvent))
+	}
+
+	if len(req.Conditions) > automationMaxConditions {
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("a rule can have at most %d conditions", automationMaxConditions))
+	}
+	for i, condition := range req.Conditions {
+		if err := validateAutomationCondition(&condition); err != nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("condition %d: %v", i+1, err))
+		}
+	}
+
+	if len(req.Actions) == 0 {
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "a rule needs at least one action")
+	}
+	if len(req.Actions) > automationMaxActions {
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("a rule can have at most %d actions", automationMaxActions))
+	}
+	for i := range req.Actions {
+		if err := s.validateAction(ctx, tenantID, &req.Actions[i]); err != nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("action %d: %v", i+1, err))
+		}
+	}
+
+	return nil
+}
+
+// validateAction checks an action's parameters for its type
+func (s *AutomationService) validateAction(ctx context.Context, tenantID uuid.UUID, action *models.AutomationAction) error {
+	switch action.Type {
+	case models.AutomationActionSendNotification:
+		if len(action.Recipients) == 0 {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "recipients are required")
+		}
+		for _, recipient := range action.Recipients {
+			if recipient == models.AutomationRecipientActor || recipient == models.AutomationRecipientResource {
+				continue
+			}
+			if _, err := uuid.Parse(recipient); err != nil {
+				return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid recipient: %s", recipient))
+			}
+		}
+		if strings.TrimSpace(action.Subject) == "" || strings.TrimSpace(action.Body) == "" {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "subject and body are required")
+		}
+
+		// Render with sample data so template errors are reported now rather
+		// than when the rule runs
+		sample := models.AutomationTemplateData{Object: map[string]interface{}{}}
+		if _, err := renderAutomationSubject(action.Subject, sample); err != nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid subject template: %v", err))
+		}
+		if _, err := renderAutomationBody(action.Body, sample); err != nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid body template: %v", err))
+		}
+
+	case models.AutomationActionAssignRole:
+		if action.RoleID == nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "role_id is required")
+		}
+		if _, err := s.roleRepo.FindByID(ctx, tenantID, *action.RoleID); err != nil {
+			return err
+		}
+
+	case models.AutomationActionCallWebhook:
+		if action.WebhookID == nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "webhook_id is required")
+		}
+		if _, err := s.webhookService.GetWebhook(ctx, tenantID, *action.WebhookID); err != nil {
+			return err
+		}
+
+	default:
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid action type: %s", action.Type))
+	}
+
+	return nil
+}
+
+// validateAutomationCondition checks a condition's field, operator and value
+func validateAutomationCondition(condition *models.AutomationCondition) error {
+	if strings.TrimSpace(condition.Field) == "" {
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "field is required")
+	}
+
+	switch condition.Operator {
+	case models.AutomationOperatorEquals, models.AutomationOperatorNotEquals, models.AutomationOperatorContains:
+		if condition.Value == nil {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "value is required")
+		}
+	case models.AutomationOperatorIn, models.AutomationOperatorNotIn:
+		if _, ok := condition.Value.([]interface{}); !ok {
+			return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", "value must be a list")
+		}
+	case models.AutomationOperatorExists, models.AutomationOperatorNotExists:
+	default:
+		return utils.NewBadRequestError("INVALID_AUTOMATION_RULE", fmt.Sprintf("invalid operator: %s", condition.Operator))
+	}
+
+	return nil
+}
+
+// applyAutomationRuleRequest copies a request onto a rule
+func applyAutomationRuleRequest(rule *models.AutomationRule, req *models.AutomationRuleRequest) {
+	rule.Name = req.Name
+	rule.Description = nil
+	if req.Description != "" {
+		description := req.Description
+		rule.Description = &description
+	}
+	rule.TriggerEvent = req.TriggerEvent
+	rule.Conditions = req.Conditions
+	rule.Actions = req.Actions
+	if req.IsEnabled != nil {
+		rule.IsEnabled = *req.IsEnabled
+	}
+}
+
+// automationObject decodes an event's resource for conditions and templates
+func automationObject(raw json.RawMessage) map[string]interface{} {
+	object := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &object)
+	}
+	return object
+}
+
+// automationConditionsMatch reports whether every condition holds for object
+func automationConditionsMatch(conditions models.AutomationConditions, object map[string]interface{}) bool {
+	for _, condition := range conditions {
+		if !automationConditionHolds(&condition, object) {
+			return false
+		}
+	}
+	return true
+}
+
+// automationConditionHolds evaluates one condition. Values are compared as
+// decoded from JSON, so numbers compare equal whatever their notation.
+func automationConditionHolds(condition *models.AutomationCondition, object map[string]interface{}) bool {
+	value, found := automationField(object, condition.Field)
+
+	switch condition.Operator {
+	case models.AutomationOperatorExists:
+		return found && value != nil
+	case models.AutomationOperatorNotExists:
+		return !found || value == nil
+	case models.AutomationOperatorEquals:
+		return found && reflect.DeepEqual(value, condition.Value)
+	case models.AutomationOperatorNotEquals:
+		return !found || !reflect.DeepEqual(value, condition.Value)
+	case models.AutomationOperatorIn, models.AutomationOperatorNotIn:
+		in := false
+		if options, ok := condition.Value.([]interface{}); ok && found {
+			for _, option := range options {
+				if reflect.DeepEqual(value, option) {
+					in = true
+					break
+				}
+			}
+		}
+		return in == (condition.Operator == models.AutomationOperatorIn)
+	case models.AutomationOperatorContains:
+		switch v := value.(type) {
+		case string:
+			needle, ok := condition.Value.(string)
+			return ok && strings.Contains(v, needle)
+		case []interface{}:
+			for _, item := range v {
+				if reflect.DeepEqual(item, condition.Value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// automationField looks up a dotted path in object
+func automationField(object map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = object
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// automationResourceUser returns the user an event is about
+func automationResourceUser(event *models.AutomationEvent) (uuid.UUID, error) {
+	if event.ResourceType != models.ResourceUsers || event.ResourceID == nil {
+		return uuid.Nil, fmt.Errorf("event is not about a user")
+	}
+	return *event.ResourceID, nil
+}
+
+// automationRecipient resolves a notification recipient to a user ID
+func automationRecipient(recipient string, event *models.AutomationEvent) (uuid.UUID, error) {
+	switch recipient {
+	case models.AutomationRecipientActor:
+		if event.ActorID == nil {
+			return uuid.Nil, fmt.Errorf("event has no actor")
+		}
+		return *event.ActorID, nil
+	case models.AutomationRecipientResource:
+		return automationResourceUser(event)
+	}
+	return uuid.Parse(recipient)
+}
+
+// renderAutomationSubject renders a plain-text subject template
+func renderAutomationSubject(tmplStr string, data models.AutomationTemplateData) (string, error) {
+	tmpl, err := texttemplate.New("subject").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// renderAutomationBody renders an HTML body template, escaping the data
+func renderAutomationBody(tmplStr string, data models.AutomationTemplateData) (template.HTML, error) {
+	tmpl, err := template.New("body").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"log"
+	"strconv"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	maxAvatarPixels    = 25_000_000 // Larger images are refused before decoding, as decoding them could exhaust memory
+	minAvatarDimension = 16         // Smaller images are refused
+	avatarJPEGQuality  = 90
+)
+
+// AvatarService handles users' uploaded avatars. An upload is cropped to a
+// square, resized to each of models.AvatarSizes and stored through the file
+// service, attached to the user. The previous avatar's files are deleted and
+// purged with other deleted files.
+type AvatarService struct {
+	userRepo          repository.UserStore
+	fileService       FileManager
+	permissionService PermissionManager
+	config            *config.StorageConfig
+}
+
+// NewAvatarService creates a new avatar service
+func NewAvatarService(userRepo repository.UserStore, fileService FileManager, permissionService PermissionManager, cfg *config.StorageConfig) *AvatarService {
+	return &AvatarService{
+		userRepo:          userRepo,
+		fileService:       fileService,
+		permissionService: permissionService,
+		config:            cfg,
+	}
+}
+
+// MaxFileSize returns the largest avatar image accepted, in bytes
+func (s *AvatarService) MaxFileSize() int64 {
+	return s.config.MaxAvatarSize
+}
+
+// Upload replaces a user's avatar with an uploaded PNG, JPEG or GIF image.
+// Users change their own avatar; changing someone else's requires users.edit.
+func (s *AvatarService) Upload(ctx context.Context, tenantID, actorID, userID uuid.UUID, data []byte) (*models.User, *models.UserAvatar, error) {
+	user, err := s.authorize(ctx, tenantID, actorID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil, utils.NewFieldError("INVALID_AVATAR", "file", "file is empty")
+	}
+	if int64(len(data)) > s.config.MaxAvatarSize {
+		return nil, nil, utils.NewFieldError("INVALID_AVATAR", "file", "file is too large")
+	}
+
+	src, format, err := decodeAvatar(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	avatar := &models.UserAvatar{Files: make(map[string]models.File, len(models.AvatarSizes))}
+	stored := make([]uuid.UUID, 0, len(models.AvatarSizes))
+	square := cropSquare(src)
+	for i, size := range models.AvatarSizes {
+		encoded, ext, err := encodeAvatar(resizeImage(square, size), format)
+		if err != nil {
+			s.discard(ctx, tenantID, actorID, stored)
+			return nil, nil, err
+		}
+
+		resourceType := models.FileResourceUser
+		file, err := s.fileService.Upload(ctx, tenantID, actorID, &models.FileUpload{
+			OriginalName: fmt.Sprintf("avatar-%d%s", size, ext),
+			Size:         int64(len(encoded)),
+			Category:     models.FileCategoryAvatar,
+			ResourceType: &resourceType,
+			ResourceID:   &user.ID,
+		}, bytes.NewReader(encoded))
+		if err != nil {
+			s.discard(ctx, tenantID, actorID, stored)
+			return nil, nil, err
+		}
+
+		stored = append(stored, file.ID)
+		avatar.Files[strconv.Itoa(size)] = *file
+		if i == 0 {
+			avatar.AvatarURL = s.fileService.DownloadURL(file)
+		}
+	}
+
+	user.AvatarURL = &avatar.AvatarURL
+	if err := s.userRepo.Update(ctx, tenantID, user); err != nil {
+		s.discard(ctx, tenantID, actorID, stored)
+		return nil, nil, err
+	}
+
+	// The previous avatar stays restorable until deleted files are purged
+	if _, err := s.fileService.DeleteResourceFiles(ctx, tenantID, actorID, models.FileCategoryAvatar, models.FileResourceUser, user.ID, stored); err != nil {
+		log.Printf("⚠️  Failed to delete previous avatar of user %s: %v", user.ID, err)
+	}
+
+	return user, avatar, nil
+}
+
+// Remove clears a user's avatar and deletes its files
+func (s *AvatarService) Remove(ctx context.Context, tenantID, actorID, userID uuid.UUID) (*models.User, error) {
+	user, err := s.authorize(ctx, tenantID, actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.AvatarURL = nil
+	if err := s.userRepo.Update(ctx, tenantID, user); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.fileService.DeleteResourceFiles(ctx, tenantID, actorID, models.FileCategoryAvatar, models.FileResourceUser, user.ID, nil); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// authorize returns the user whose avatar the actor changes, if allowed
+func (s *AvatarService) authorize(ctx context.Context, tenantID, actorID, userID uuid.UUID) (*models.User, error) {
+	if actorID != userID {
+		allowed, err := s.permissionService.HasPermission(ctx, tenantID, actorID, models.ResourceUsers, models.ActionEdit)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+		}
+	}
+
+	return s.userRepo.FindByID(ctx, tenantID, userID)
+}
+
+// discard deletes the files of an avatar that could not be saved completely
+func (s *AvatarService) discard(ctx context.Context, tenantID, actorID uuid.UUID, fileIDs []uuid.UUID) {
+	for _, fileID := range fileIDs {
+		if _, err := s.fileService.Delete(ctx, tenantID, actorID, fileID); err != nil {
+			log.Printf("⚠️  Failed to delete incomplete avatar file %s: %v", fileID, err)
+		}
+	}
+}
+
+// decodeAvatar decodes an uploaded image, checking its dimensions from the
+// header first
+func decodeAvatar(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", utils.NewFieldError("INVALID_AVATAR", "file", "file is not a supported image")
+	}
+	if cfg.Width < minAvatarDimension || cfg.Height < minAvatarDimension {
+		return nil, "", utils.NewFieldError("INVALID_AVATAR", "file", "image is too small")
+	}
+	if cfg.Width*cfg.Height > maxAvatarPixels {
+		return nil, "", utils.NewFieldError("INVALID_AVATAR", "file", "image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", utils.NewFieldError("INVALID_AVATAR", "file", "file is not a supported image")
+	}
+	return img, format, nil
+}
+
+// encodeAvatar encodes a resized avatar: photos stay JPEG, anything else
+// becomes PNG so transparency is kept. Returns the file extension.
+func encodeAvatar(img image.Image, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarJPEGQuality}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		return buf.Bytes(), ".jpg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	return buf.Bytes(), ".png", nil
+}
+
+// cropSquare crops the centered square of an image
+func cropSquare(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	origin := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, origin, draw.Src)
+	return square
+}
+
+// resizeImage scales a square image to size×size. Each target pixel averages
+// the source pixels it covers (premultiplied), which keeps downscaled photos
+// smooth; enlarging repeats pixels.
+func resizeImage(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	side := src.Bounds().Dx()
+
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, (y+1)*side/size
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, (x+1)*side/size
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r, g, b, a = r+uint64(p[0]), g+uint64(p[1]), b+uint64(p[2]), a+uint64(p[3])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/billing"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+// BillingService manages tenants' plans. Plans combine the quotas of
+// QuotaConfig with the features of PLAN_FEATURES; paid plans are Stripe
+// subscriptions, bought through Stripe Checkout and kept in sync through
+// Stripe webhooks.
+type BillingService struct {
+	tenantRepo   repository.TenantStore
+	quotaService QuotaManager
+	metering     MeteringManager
+	auditService AuditManager
+	stripe       *billing.Stripe
+	config       *config.Config
+}
+
+// NewBillingService creates a new billing service
+func NewBillingService(
+	tenantRepo repository.TenantStore,
+	quotaService QuotaManager,
+	metering MeteringManager,
+	auditService AuditManager,
+	stripe *billing.Stripe,
+	cfg *config.Config,
+) *BillingService {
+	return &BillingService{
+		tenantRepo:   tenantRepo,
+		quotaService: quotaService,
+		metering:     metering,
+		auditService: auditService,
+		stripe:       stripe,
+		config:       cfg,
+	}
+}
+
+// Plans lists every plan tier, from the smallest to the largest
+func (s *BillingService) Plans() []models.Plan {
+	plans := make([]models.Plan, 0, len(models.PlanTiers))
+	for _, tier := range models.PlanTiers {
+		plans = append(plans, *s.Plan(tier))
+	}
+	return plans
+}
+
+// Plan returns what a plan tier includes
+func (s *BillingService) Plan(tier string) *models.Plan {
+	plan := &models.Plan{
+		Tier:     tier,
+		Features: []string{},
+	}
+	if limit, ok := s.config.Quotas.UserLimits[tier]; ok {
+		plan.UserLimit = &limit
+	}
+	if limit, ok := s.config.Quotas.StorageLimits[tier]; ok {
+		plan.StorageLimit = &limit
+	}
+	if limit, ok := s.config.Quotas.APICallLimits[tier]; ok {
+		plan.APICallLimit = &limit
+	}
+
+	for _, feature := range []string{models.PlanFeatureWebhooks, models.PlanFeatureAutomations, models.PlanFeatureSandboxes} {
+		if slices.Contains(s.config.Billing.FeatureTiers[feature], tier) {
+			plan.Features = append(plan.Features, feature)
+		}
+	}
+	if slices.Contains(s.config.SSO.SAMLPlanTiers, tier) {
+		plan.Features = append(plan.Features, models.PlanFeatureSAML)
+	}
+
+	_, priced := s.config.Billing.StripePriceIDs[tier]
+	plan.Purchasable = priced && s.stripe.Enabled()
+
+	return plan
+}
+
+// HasFeature reports whether the tenant's plan includes a feature, and the
+// tenant's plan tier
+func (s *BillingService) HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, string, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return false, "", err
+	}
+	return s.Plan(tenant.PlanTier).HasFeature(feature), tenant.PlanTier, nil
+}
+
+// GetStatus retrieves the tenant's plan, subscription, quota usage and
+// metered usage this month
+func (s *BillingService) GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.BillingStatus, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := s.quotaService.GetStatus(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.metering.GetUsage(ctx, tenantID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BillingStatus{
+		Plan:              s.Plan(tenant.PlanTier),
+		Status:            tenant.SubscriptionStatus,
+		PeriodEnd:         tenant.SubscriptionPeriodEnd,
+		CancelAtPeriodEnd: tenant.SubscriptionCancelAtPeriodEnd,
+		Quotas:            quotas.Quotas,
+		Usage:             usage.Totals,
+		BillingEnabled:    s.stripe.Enabled(),
+	}, nil
+}
+
+// Checkout starts a Stripe Checkout session subscribing a tenant without a
+// subscription to a paid plan. The plan applies once Stripe reports the
+// payment through the webhook.
+func (s *BillingService) Checkout(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingCheckout, error) {
+	if !s.stripe.Enabled() {
+		return nil, fmt.Errorf("billing is not configured")
+	}
+	priceID, ok := s.config.Billing.StripePriceIDs[tier]
+	if !ok {
+		return nil, fmt.Errorf("plan cannot be purchased")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.SandboxOf != nil {
+		return nil, fmt.Errorf("sandboxes cannot be billed")
+	}
+	if tenant.StripeSubscriptionID != nil {
+		return nil, fmt.Errorf("tenant already has a subscription")
+	}
+	if err := s.quotaService.CheckPlanFits(ctx, tenant, tier); err != nil {
+		return nil, err
+	}
+
+	params := &billing.CheckoutParams{
+		TenantID:   tenant.ID.String(),
+		PlanTier:   tier,
+		PriceID:    priceID,
+		Email:      tenant.Email,
+		SuccessURL: s.config.App.FrontendURL + "/settings/billing?checkout=success",
+		CancelURL:  s.config.App.FrontendURL + "/settings/billing?checkout=canceled",
+	}
+	if tenant.StripeCustomerID != nil {
+		params.CustomerID = *tenant.StripeCustomerID
+	}
+
+	session, err := s.stripe.CreateCheckoutSession(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, models.ActionBillingCheckoutStarted, models.AuditResourceBilling, uuid.Nil, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"plan_tier":  tier,
+		"session_id": session.ID,
+	})
+
+	return &models.BillingCheckout{URL: session.URL}, nil
+}
+
+// ChangePlan moves a subscribed tenant to another paid plan right away,
+// prorating the current period, or to the free plan at the end of the paid
+// period. Moving down requires the tenant's usage to fit the new plan.
+func (s *BillingService) ChangePlan(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingStatus, error) {
+	if !s.stripe.Enabled() {
+		return nil, fmt.Errorf("billing is not configured")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.StripeSubscriptionID == nil || tenant.StripeCustomerID == nil {
+		return nil, fmt.Errorf("tenant has no subscription")
+	}
+
+	var subscription *billing.Subscription
+	if tier == models.PlanTierFree {
+		subscription, err = s.stripe.CancelAtPeriodEnd(ctx, *tenant.StripeSubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		priceID, ok := s.config.Billing.StripePriceIDs[tier]
+		if !ok {
+			return nil, fmt.Errorf("plan cannot be purchased")
+		}
+		if tier == tenant.PlanTier && !tenant.SubscriptionCancelAtPeriodEnd {
+			return nil, fmt.Errorf("plan is already active")
+		}
+		if err := s.quotaService.CheckPlanFits(ctx, tenant, tier); err != nil {
+			return nil, err
+		}
+
+		current, err := s.stripe.GetSubscription(ctx, *tenant.StripeSubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		subscription, err = s.stripe.ChangePrice(ctx, current, priceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previousTier := tenant.PlanTier
+	if err := s.syncSubscription(ctx, tenant, subscription); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, models.ActionBillingPlanChanged, models.AuditResourceBilling, uuid.Nil, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"from_plan_tier":       previousTier,
+		"to_plan_tier":         tier,
+		"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+	})
+
+	return s.GetStatus(ctx, tenantID)
+}
+
+// HandleWebhook verifies a Stripe webhook request and syncs the
+// subscription it reports to its tenant. Events of unknown customers and
+// other types are ignored.
+func (s *BillingService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	event, err := s.stripe.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case billing.EventCheckoutCompleted:
+		var session billing.CheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return fmt.Errorf("invalid stripe event: %w", err)
+		}
+		if session.Subscription == "" {
+			return nil
+		}
+
+		tenantID, err := uuid.Parse(session.ClientReferenceID)
+		if err != nil {
+			log.Printf("⚠️  Stripe checkout %s has no tenant reference", session.ID)
+			return nil
+		}
+		tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+		if err != nil {
+			log.Printf("⚠️  Stripe checkout %s is for unknown tenant %s", session.ID, tenantID)
+			return nil
+		}
+
+		subscription, err := s.stripe.GetSubscription(ctx, session.Subscription)
+		if err != nil {
+			return err
+		}
+		return s.syncSubscription(ctx, tenant, subscription)
+
+	case billing.EventSubscriptionCreated, billing.EventSubscriptionUpdated, billing.EventSubscriptionDeleted:
+		var subscription billing.Subscription
+		if err := json.Unmarshal(event.Data.Object, &subscription); err != nil {
+			return fmt.Errorf("invalid stripe event: %w", err)
+		}
+
+		tenant := s.subscriptionTenant(ctx, &subscription)
+		if tenant == nil {
+			log.Printf("⚠️  Stripe subscription %s is for an unknown customer %s", subscription.ID, subscription.Customer)
+			return nil
+		}
+
+		// Events of a subscription the tenant replaced are stale
+		if tenant.StripeSubscriptionID != nil && *tenant.StripeSubscriptionID != subscription.ID {
+			if event.Type == billing.EventSubscriptionDeleted || subscription.Status == models.SubscriptionStatusCanceled {
+				return nil
+			}
+		}
+
+		if event.Type == billing.EventSubscriptionDeleted {
+			subscription.Status = models.SubscriptionStatusCanceled
+		}
+		return s.syncSubscription(ctx, tenant, &subscription)
+	}
+
+	return nil
+}
+
+// subscriptionTenant finds the tenant a subscription bills, by customer or
+// by the tenant ID Checkout stored on it
+func (s *BillingService) subscriptionTenant(ctx context.Context, subscription *billing.Subscription) *models.Tenant {
+	if tenant, err := s.tenantRepo.FindByStripeCustomer(ctx, subscription.Customer); err == nil {
+		return tenant
+	}
+	if tenantID, err := uuid.Parse(subscription.Metadata["tenant_id"]); err == nil {
+		if tenant, err := s.tenantRepo.FindByID(ctx, tenantID); err == nil {
+			return tenant
+		}
+	}
+	return nil
+}
+
+// syncSubscription records the state of a tenant's subscription. Paying
+// subscriptions set the plan tier of their price; ended ones return the
+// tenant to the free plan. A subscription Stripe gave up collecting makes
+// the tenant read-only until it is paid.
+func (s *BillingService) syncSubscription(ctx context.Context, tenant *models.Tenant, subscription *billing.Subscription) error {
+	sub := &models.TenantSubscription{
+		CustomerID:        subscription.Customer,
+		SubscriptionID:    &subscription.ID,
+		Status:            subscription.Status,
+		PlanTier:          tenant.PlanTier,
+		PeriodEnd:         subscription.PeriodEnd(),
+		CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
+	}
+
+	switch subscription.Status {
+	case models.SubscriptionStatusActive, models.SubscriptionStatusTrialing, models.SubscriptionStatusPastDue:
+		if tier := s.priceTier(subscription.PriceID()); tier != "" {
+			sub.PlanTier = tier
+		} else {
+			log.Printf("⚠️  Stripe subscription %s has unknown price %s", subscription.ID, subscription.PriceID())
+		}
+	case models.SubscriptionStatusCanceled, "incomplete_expired":
+		sub.SubscriptionID = nil
+		sub.PlanTier = models.PlanTierFree
+		sub.PeriodEnd = nil
+		sub.CancelAtPeriodEnd = false
+	}
+
+	if err := s.tenantRepo.UpdateSubscription(ctx, tenant.ID, sub); err != nil {
+		return err
+	}
+
+	// Overdue payments make the tenant read-only; paying or ending the
+	// subscription lifts that
+	overdue := tenant.IsSuspended() && tenant.ReadOnlyReason() == models.TenantSuspensionPaymentOverdue
+	switch {
+	case subscription.Status == models.SubscriptionStatusUnpaid && tenant.IsActive():
+		if err := s.tenantRepo.Suspend(ctx, tenant.ID, models.TenantSuspensionPaymentOverdue); err != nil {
+			return err
+		}
+	case subscription.Status != models.SubscriptionStatusUnpaid && overdue:
+		if err := s.tenantRepo.Reactivate(ctx, tenant.ID); err != nil {
+			return err
+		}
+	}
+
+	action := models.ActionBillingSubscriptionSync
+	if sub.SubscriptionID == nil {
+		action = models.ActionBillingSubscriptionEnded
+	}
+	s.auditService.LogEvent(ctx, tenant.ID, uuid.Nil, action, models.AuditResourceBilling, uuid.Nil, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"subscription_id": subscription.ID,
+		"status":          subscription.Status,
+		"plan_tier":       sub.PlanTier,
+	})
+
+	return nil
+}
+
+// priceTier returns the plan tier a Stripe price is configured for, or ""
+func (s *BillingService) priceTier(priceID string) string {
+	for tier, id := range s.config.Billing.StripePriceIDs {
+		if id == priceID {
+			return tier
+		}
+	}
+	return ""
+}
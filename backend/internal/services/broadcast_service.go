@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	texttemplate "text/template"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Broadcast audit actions
+const (
+	broadcastAuditCreated      = "broadcast.created"
+	broadcastAuditCancelled    = "broadcast.cancelled"
+	broadcastAuditUnsubscribed = "broadcast.unsubscribed"
+)
+
+// BroadcastService sends bulk emails to a tenant's users. Recipients are
+// resolved when the broadcast is created; the broadcast worker (ProcessQueue)
+// then renders and queues their emails in throttled batches through the
+// email outbox.
+type BroadcastService struct {
+	db              *sqlx.DB
+	broadcastRepo   repository.BroadcastStore
+	emailOutboxRepo repository.EmailOutboxStore
+	emailService    EmailManager
+	auditService    AuditManager
+	config          *config.BroadcastConfig
+	queueConfig     *config.QueueConfig
+}
+
+// NewBroadcastService creates a new broadcast service
+func NewBroadcastService(
+	db *sqlx.DB,
+	broadcastRepo repository.BroadcastStore,
+	emailOutboxRepo repository.EmailOutboxStore,
+	emailService EmailManager,
+	auditService AuditManager,
+	cfg *config.BroadcastConfig,
+	queueConfig *config.QueueConfig,
+) *BroadcastService {
+	return &BroadcastService{
+		db:              db,
+		broadcastRepo:   broadcastRepo,
+		emailOutboxRepo: emailOutboxRepo,
+		emailService:    emailService,
+		auditService:    auditService,
+		config:          cfg,
+		queueConfig:     queueConfig,
+	}
+}
+
+// CreateBroadcast validates the templates and starts sending a broadcast to
+// the users matching its filter
+func (s *BroadcastService) CreateBroadcast(ctx context.Context, tenantID, userID uuid.UUID, req *models.BroadcastCreateRequest) (*models.EmailBroadcast, error) {
+	if len(req.Filter.Statuses) == 0 {
+		req.Filter.Statuses = []string{models.UserStatusActive}
+	}
+
+	// Render once with placeholder data so template errors and unknown
+	// variables are reported now rather than per recipient
+	sample := broadcastTemplateData(req.Variables, "Jane", "Doe", "jane@example.com", "Example")
+	if _, err := renderBroadcastSubject(req.Subject, sample); err != nil {
+		return nil, utils.NewBadRequestError("INVALID_TEMPLATE", fmt.Sprintf("invalid subject template: %v", err))
+	}
+	if _, err := renderBroadcastBody(req.Body, sample); err != nil {
+		return nil, utils.NewBadRequestError("INVALID_TEMPLATE", fmt.Sprintf("invalid body template: %v", err))
+	}
+
+	variables, err := json.Marshal(req.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode variables: %w", err)
+	}
+	filter, err := json.Marshal(req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filter: %w", err)
+	}
+
+	broadcast := &models.EmailBroadcast{
+		TenantID:  tenantID,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		Variables: variables,
+		Filter:    filter,
+		CreatedBy: userID,
+	}
+
+	if err := s.broadcastRepo.Create(ctx, broadcast, &req.Filter); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, broadcastAuditCreated, models.ResourceBroadcasts, broadcast.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"subject":          broadcast.Subject,
+		"total_recipients": broadcast.TotalRecipients,
+	})
+
+	return s.GetBroadcast(ctx, tenantID, broadcast.ID)
+}
+
+// ListBroadcasts lists a tenant's broadcasts
+func (s *BroadcastService) ListBroadcasts(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error) {
+	return s.broadcastRepo.List(ctx, tenantID, limit, offset)
+}
+
+// GetBroadcast retrieves a broadcast with its delivery counts
+func (s *BroadcastService) GetBroadcast(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	broadcast, err := s.broadcastRepo.FindByID(ctx, tenantID, broadcastID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.broadcastRepo.Stats(ctx, tenantID, broadcastID)
+	if err != nil {
+		return nil, err
+	}
+	broadcast.Stats = stats
+
+	return broadcast, nil
+}
+
+// ListRecipients lists a broadcast's recipients with their delivery status
+func (s *BroadcastService) ListRecipients(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error) {
+	if _, err := s.broadcastRepo.FindByID(ctx, tenantID, broadcastID); err != nil {
+		return nil, 0, err
+	}
+	return s.broadcastRepo.ListRecipients(ctx, tenantID, broadcastID, deliveryStatus, limit, offset)
+}
+
+// CancelBroadcast stops queueing a broadcast's remaining emails
+func (s *BroadcastService) CancelBroadcast(ctx context.Context, tenantID, userID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	if _, err := s.broadcastRepo.FindByID(ctx, tenantID, broadcastID); err != nil {
+		return nil, err
+	}
+
+	if err := s.broadcastRepo.Cancel(ctx, tenantID, broadcastID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, broadcastAuditCancelled, models.ResourceBroadcasts, broadcastID, models.AuditStatusSuccess, "", "", nil)
+
+	return s.GetBroadcast(ctx, tenantID, broadcastID)
+}
+
+// Unsubscribe opts the owner of an unsubscribe token out of future broadcasts
+func (s *BroadcastService) Unsubscribe(ctx context.Context, tenantID, token uuid.UUID) error {
+	userID, err := s.broadcastRepo.OptOut(ctx, tenantID, token)
+	if err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, broadcastAuditUnsubscribed, models.ResourceBroadcasts, userID, models.AuditStatusSuccess, "", "", nil)
+
+	return nil
+}
+
+// ProcessQueue renders and queues the next batch of broadcast emails in every
+// data region. It is run periodically by the background job runner; the batch
+// size throttles how fast broadcasts reach the outbox. Returns the number of
+// emails queued.
+func (s *BroadcastService) ProcessQueue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		if err := ctx.Err(); err != nil {
+			return total, nil
+		}
+
+		queued, err := s.queueBatch(ctx, db)
+		total += queued
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// queueBatch claims up to the batch size of pending recipients and hands
+// their emails to the outbox in the same transaction. Broadcasts may only fill
+// half of a tenant's email queue, leaving room for transactional emails;
+// recipients of a tenant whose queue is that full stay pending for a later
+// run.
+func (s *BroadcastService) queueBatch(ctx context.Context, db *sqlx.DB) (int, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	maxPending := s.queueConfig.EmailMaxPending / 2
+	if s.queueConfig.EmailMaxPending > 0 && maxPending == 0 {
+		maxPending = 1
+	}
+
+	pending, err := s.broadcastRepo.ClaimPending(ctx, tx, s.config.BatchSize, maxPending)
+	if err != nil {
+		return 0, err
+	}
+
+	// A tenant's queue can still fill up within the batch
+	fullTenants := make(map[uuid.UUID]bool)
+
+	queued := 0
+	for i := range pending {
+		email := &pending[i]
+
+		// Users may opt out after the broadcast was created
+		if email.OptedOut {
+			if err := s.broadcastRepo.MarkNotQueued(ctx, tx, &email.BroadcastRecipient, models.RecipientStatusSkipped, "opted out"); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if fullTenants[email.TenantID] {
+			continue
+		}
+
+		msg, err := s.buildEmail(email)
+		if err != nil {
+			if err := s.broadcastRepo.MarkNotQueued(ctx, tx, &email.BroadcastRecipient, models.RecipientStatusFailed, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		outboxID, err := s.emailOutboxRepo.Enqueue(ctx, tx, email.TenantID, msg, maxPending)
+		if errors.Is(err, utils.ErrTooManyRequests) {
+			fullTenants[email.TenantID] = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := s.broadcastRepo.MarkQueued(ctx, tx, &email.BroadcastRecipient, outboxID); err != nil {
+			return 0, err
+		}
+		queued++
+	}
+
+	if err := s.broadcastRepo.CompleteFinished(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to queue broadcast emails: %w", err)
+	}
+
+	return queued, nil
+}
+
+// buildEmail renders a recipient's broadcast email
+func (s *BroadcastService) buildEmail(email *models.PendingBroadcastEmail) (*models.EmailMessage, error) {
+	variables := map[string]string{}
+	if len(email.Variables) > 0 {
+		if err := json.Unmarshal(email.Variables, &variables); err != nil {
+			return nil, fmt.Errorf("invalid broadcast variables: %w", err)
+		}
+	}
+
+	data := broadcastTemplateData(variables, email.FirstName, email.LastName, email.Email, email.CompanyName)
+
+	subject, err := renderBroadcastSubject(email.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderBroadcastBody(email.Body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribeURL := s.emailService.BroadcastUnsubscribeURL(email.TenantSlug, email.UnsubscribeToken)
+	return s.emailService.BroadcastEmail(email.Email, email.Language, email.CompanyName, subject, content, unsubscribeURL)
+}
+
+// broadcastTemplateData merges the broadcast's variables with the recipient
+// fields every broadcast can use. Recipient fields win over variables.
+func broadcastTemplateData(variables map[string]string, firstName, lastName, email, companyName string) map[string]string {
+	data := make(map[string]string, len(variables)+4)
+	for k, v := range variables {
+		data[k] = v
+	}
+	data["FirstName"] = firstName
+	data["LastName"] = lastName
+	data["Email"] = email
+	data["CompanyName"] = companyName
+	return data
+}
+
+// renderBroadcastSubject renders a plain-text subject template
+func renderBroadcastSubject(tmplStr string, data map[string]string) (string, error) {
+	tmpl, err := texttemplate.New("subject").Option("missingkey=error").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// renderBroadcastBody renders an HTML body template, escaping the data
+func renderBroadcastBody(tmplStr string, data map[string]string) (template.HTML, error) {
+	tmpl, err := template.New("body").Option("missingkey=error").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil
+}
@@ -11,16 +11,16 @@ import (
 
 // CompanySettingsService handles company settings business logic
 type CompanySettingsService struct {
-	repo         *repository.CompanySettingsRepository
-	tenantRepo   *repository.TenantRepository
-	auditService *AuditService
+	repo         repository.CompanySettingsStore
+	tenantRepo   repository.TenantStore
+	auditService AuditManager
 }
 
 // NewCompanySettingsService creates a new company settings service
 func NewCompanySettingsService(
-	repo *repository.CompanySettingsRepository,
-	tenantRepo *repository.TenantRepository,
-	auditService *AuditService,
+	repo repository.CompanySettingsStore,
+	tenantRepo repository.TenantStore,
+	auditService AuditManager,
 ) *CompanySettingsService {
 	return &CompanySettingsService{
 		repo:         repo,
@@ -40,11 +40,13 @@ func (s *CompanySettingsService) GetSettings(ctx context.Context, tenantID uuid.
 	return settings, nil
 }
 
-// UpdateSettings updates company settings (creates if doesn't exist)
+// UpdateSettings updates company settings (creates if doesn't exist). The
+// optional fields named in nulls are cleared.
 func (s *CompanySettingsService) UpdateSettings(
 	ctx context.Context,
 	tenantID, userID uuid.UUID,
 	req *models.CompanySettingsUpdateRequest,
+	nulls map[string]bool,
 ) (*models.CompanySettings, error) {
 	// Check if settings exist
 	existing, err := s.repo.GetByTenantID(ctx, tenantID)
@@ -191,6 +193,13 @@ func (s *CompanySettingsService) UpdateSettings(
 		updates["capital_social"] = *req.CapitalSocial
 	}
 
+	// Clear the optional fields set to null
+	for field := range nulls {
+		if models.CompanySettingsNullableFields[field] {
+			updates[field] = nil
+		}
+	}
+
 	// If no updates, return existing settings
 	if len(updates) == 0 {
 		return existing, nil
@@ -0,0 +1,598 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const complianceTopIPs = 10 // IP addresses listed in the failed sign-in statistics
+
+// complianceAdminResources are the resource types whose audit logs are
+// administrative actions; roles granting more than viewing them make users
+// privileged
+var complianceAdminResources = []string{
+	models.ResourceUsers,
+	models.ResourceRoles,
+	models.ResourceSettings,
+	models.ResourceSecurity,
+	models.AuditResourceInvitations,
+	models.ResourceWebhooks,
+	models.ResourceSandboxes,
+}
+
+// complianceSignInActions are the sign-in events, reported apart from the
+// administrative actions
+var complianceSignInActions = []string{
+	models.ActionUserLogin,
+	models.ActionUserLoginFailed,
+	models.ActionUserLogout,
+	models.Action2FAVerified,
+	models.Action2FAFailed,
+}
+
+// Administrative actions counted as access reviews
+var (
+	complianceRoleChangeActions = map[string]bool{
+		models.ActionUserRolesAssigned: true,
+		models.ActionRoleCreated:       true,
+		models.ActionRoleUpdated:       true,
+		models.ActionRoleDeleted:       true,
+		models.ActionRoleAssigned:      true,
+		models.ActionRoleUnassigned:    true,
+		models.ActionPermissionGranted: true,
+		models.ActionPermissionRevoked: true,
+	}
+	complianceStatusChangeActions = map[string]bool{
+		models.ActionUserCreated:       true,
+		models.ActionUserDeleted:       true,
+		models.ActionUserRestored:      true,
+		models.ActionUserSuspended:     true,
+		models.ActionUserActivated:     true,
+		models.ActionUserStatusChanged: true,
+	}
+)
+
+// complianceUserAccessColumns are the columns of evidence/user_access.csv
+var complianceUserAccessColumns = []string{
+	"id", "email", "name", "status", "roles", "privileged", "two_factor_enabled", "last_login_at", "created_at",
+}
+
+// ComplianceService compiles evidence packages for SOC 2 and ISO 27001
+// audits. A package is a ZIP archive holding a PDF report for auditors, the
+// figures as JSON, the evidence they were computed from as CSV files and a
+// manifest of SHA-256 hashes. Packages are compiled by an async job and
+// stored as files of the requester.
+type ComplianceService struct {
+	complianceRepo  repository.ComplianceStore
+	tenantRepo      repository.TenantStore
+	fileService     FileManager
+	asyncJobService AsyncJobManager
+	config          *config.Config
+}
+
+// NewComplianceService creates a new compliance service
+func NewComplianceService(
+	complianceRepo repository.ComplianceStore,
+	tenantRepo repository.TenantStore,
+	fileService FileManager,
+	asyncJobService AsyncJobManager,
+	cfg *config.Config,
+) *ComplianceService {
+	return &ComplianceService{
+		complianceRepo:  complianceRepo,
+		tenantRepo:      tenantRepo,
+		fileService:     fileService,
+		asyncJobService: asyncJobService,
+		config:          cfg,
+	}
+}
+
+// RequestReport queues the compilation of an evidence package
+func (s *ComplianceService) RequestReport(ctx context.Context, tenantID, userID uuid.UUID, params models.ComplianceReportJobParams) (*models.AsyncJob, error) {
+	return s.asyncJobService.Submit(ctx, tenantID, userID, models.JobTypeComplianceReport, params)
+}
+
+// GetReport retrieves a report job the user may follow. Its result is the
+// stored package once it has succeeded.
+func (s *ComplianceService) GetReport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	job, err := s.asyncJobService.GetJob(ctx, tenantID, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.JobType != models.JobTypeComplianceReport {
+		return nil, utils.NewNotFoundError("JOB_NOT_FOUND", "job not found")
+	}
+	return job, nil
+}
+
+// RunReportJob compiles and stores an evidence package (the
+// compliance_report job type)
+func (s *ComplianceService) RunReportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error) {
+	var params models.ComplianceReportJobParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid report parameters: %w", err)
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, job.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The package is built on disk: evidence of a busy year can be large
+	tmp, err := os.CreateTemp("", "compliance-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	summary, err := s.compile(ctx, job, tenant, params, tmp, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 95, "Storing the package"); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read report file: %w", err)
+	}
+
+	resourceType := models.FileResourceComplianceReport
+	file, err := s.fileService.StoreGenerated(ctx, job.TenantID, job.RequestedBy, &models.FileUpload{
+		OriginalName: fmt.Sprintf("compliance-%s-%s-%s.zip", params.Framework, summary.PeriodStart, summary.PeriodEnd),
+		Size:         size,
+		Category:     models.FileCategoryArtifact,
+		ResourceType: &resourceType,
+		ResourceID:   &job.ID,
+	}, "application/zip", tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ComplianceReport{
+		File:        *file,
+		DownloadURL: s.fileService.DownloadURL(file),
+		Summary:     *summary,
+	}, nil
+}
+
+// compile writes the package to w and returns its figures
+func (s *ComplianceService) compile(ctx context.Context, job *models.AsyncJob, tenant *models.Tenant, params models.ComplianceReportJobParams, w io.Writer, progress *JobProgress) (*models.ComplianceSummary, error) {
+	start, end := params.PeriodStart, params.PeriodEnd.AddDate(0, 0, 1)
+	summary := &models.ComplianceSummary{
+		Framework:   params.Framework,
+		TenantName:  tenant.CompanyName,
+		PeriodStart: params.PeriodStart.Format("2006-01-02"),
+		PeriodEnd:   params.PeriodEnd.Format("2006-01-02"),
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: job.RequestedBy,
+		Controls:    models.ComplianceControls[params.Framework],
+	}
+	archive := &complianceArchive{zip: zip.NewWriter(w), modified: summary.GeneratedAt}
+
+	// Access as of today, for review
+	if err := progress.Update(ctx, 0, "Listing user access"); err != nil {
+		return nil, err
+	}
+	users, err := s.complianceRepo.ListUserAccess(ctx, job.TenantID, complianceAdminResources)
+	if err != nil {
+		return nil, err
+	}
+	requester := job.RequestedBy.String()
+	err = archive.csv("evidence/user_access.csv", complianceUserAccessColumns, func(write func([]string) error) error {
+		for _, user := range users {
+			if user.ID == job.RequestedBy {
+				requester = fmt.Sprintf("%s <%s>", user.Name, user.Email)
+			}
+			s.countUserAccess(summary, &user)
+			if err := write([]string{
+				utils.ExportValue(user.ID),
+				user.Email,
+				user.Name,
+				user.Status,
+				user.Roles,
+				utils.ExportValue(user.Privileged),
+				utils.ExportValue(user.TwoFactorEnabled),
+				utils.ExportValue(user.LastLoginAt),
+				utils.ExportValue(user.CreatedAt),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if summary.TwoFactor.ActiveUsers > 0 {
+		percent := float64(summary.TwoFactor.Enrolled) * 100 / float64(summary.TwoFactor.ActiveUsers)
+		summary.TwoFactor.AdoptionPercent = math.Round(percent*10) / 10
+	}
+	summary.TwoFactor.SSORequired = tenant.SSORequired()
+
+	// Administrative actions, including the access reviews done
+	if err := progress.Update(ctx, 20, "Reading administrative actions"); err != nil {
+		return nil, err
+	}
+	admin := &summary.AdminActions
+	admin.ByAction = map[string]int{}
+	actors := map[uuid.UUID]bool{}
+	err = archive.csv("evidence/admin_actions.csv", models.AuditLogExportColumns, func(write func([]string) error) error {
+		return s.complianceRepo.StreamAdminActions(ctx, job.TenantID, start, end, complianceAdminResources, complianceSignInActions, func(log *models.AuditLog) error {
+			admin.Total++
+			admin.ByAction[log.Action]++
+			if log.Status == models.AuditStatusFailure {
+				admin.Failures++
+			} else if complianceRoleChangeActions[log.Action] {
+				summary.AccessReview.RoleChanges++
+			} else if complianceStatusChangeActions[log.Action] {
+				summary.AccessReview.StatusChanges++
+			}
+			if log.UserID != nil {
+				actors[*log.UserID] = true
+			}
+			return write(complianceAuditRow(log))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	admin.Actors = len(actors)
+
+	if err := progress.Update(ctx, 50, "Reading failed sign-ins"); err != nil {
+		return nil, err
+	}
+	if err := s.compileFailedLogins(ctx, job.TenantID, start, end, summary, archive); err != nil {
+		return nil, err
+	}
+
+	err = s.complianceRepo.StreamActions(ctx, job.TenantID, start, end, []string{models.Action2FAEnabled, models.Action2FADisabled}, func(log *models.AuditLog) error {
+		if log.Status != models.AuditStatusSuccess {
+			return nil
+		}
+		if log.Action == models.Action2FAEnabled {
+			summary.TwoFactor.EnabledInPeriod++
+		} else {
+			summary.TwoFactor.DisabledInPeriod++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Policy in force today
+	summary.PasswordPolicy = models.CompliancePasswordPolicy{
+		MinLength:              utils.PasswordMinLength,
+		MaxLength:              utils.PasswordMaxLength,
+		RequiresUppercase:      true,
+		RequiresLowercase:      true,
+		RequiresNumber:         true,
+		RequiresSpecial:        true,
+		HashAlgorithm:          "bcrypt",
+		HashCost:               s.config.Security.BcryptCost,
+		MaxLoginAttempts:       s.config.Security.MaxLoginAttempts,
+		LoginAttemptWindow:     s.config.Security.LoginRateLimitWindow.String(),
+		ResetLinkExpiry:        s.config.Security.PasswordResetExpiry.String(),
+		SessionInactivityLimit: s.config.Security.SessionInactivityLimit.String(),
+		SSORequired:            tenant.SSORequired(),
+	}
+	if err := archive.json("evidence/password_policy.json", summary.PasswordPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 80, "Writing the report"); err != nil {
+		return nil, err
+	}
+	if err := archive.json("summary.json", summary); err != nil {
+		return nil, err
+	}
+	err = archive.file("report.pdf", func(w io.Writer) error {
+		_, err := complianceReportPDF(summary, requester).WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The manifest lets auditors check nothing was altered
+	if err := archive.json("manifest.json", map[string]interface{}{
+		"generated_at": summary.GeneratedAt,
+		"files":        archive.files,
+	}); err != nil {
+		return nil, err
+	}
+	if err := archive.zip.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write report package: %w", err)
+	}
+
+	return summary, nil
+}
+
+// countUserAccess adds a user to the access review and 2FA figures
+func (s *ComplianceService) countUserAccess(summary *models.ComplianceSummary, user *models.ComplianceUserAccess) {
+	review, twoFactor := &summary.AccessReview, &summary.TwoFactor
+	review.Users++
+	if user.Roles == "" {
+		review.UsersWithoutRoles++
+	}
+	if user.Status != models.UserStatusActive {
+		return
+	}
+
+	review.ActiveUsers++
+	twoFactor.ActiveUsers++
+	dormantSince := summary.GeneratedAt.AddDate(0, 0, -models.ComplianceDormantDays)
+	if user.LastLoginAt == nil || user.LastLoginAt.Before(dormantSince) {
+		review.DormantUsers++
+	}
+	if user.TwoFactorEnabled {
+		twoFactor.Enrolled++
+	}
+	if user.Privileged {
+		review.PrivilegedUsers++
+		twoFactor.PrivilegedUsers++
+		if user.TwoFactorEnabled {
+			twoFactor.PrivilegedEnrolled++
+		}
+	}
+}
+
+// compileFailedLogins writes the failed sign-ins of the period and counts
+// them by reason, day and IP address
+func (s *ComplianceService) compileFailedLogins(ctx context.Context, tenantID uuid.UUID, start, end time.Time, summary *models.ComplianceSummary, archive *complianceArchive) error {
+	stats := &summary.FailedLogins
+	stats.ByReason = map[string]int{}
+	stats.ByDay = map[string]int{}
+	users := map[uuid.UUID]bool{}
+	ips := map[string]int{}
+
+	err := archive.csv("evidence/failed_logins.csv", models.AuditLogExportColumns, func(write func([]string) error) error {
+		return s.complianceRepo.StreamActions(ctx, tenantID, start, end, []string{models.ActionUserLoginFailed}, func(log *models.AuditLog) error {
+			stats.Total++
+			stats.ByDay[log.CreatedAt.UTC().Format("2006-01-02")]++
+			reason, _ := log.Metadata["reason"].(string)
+			if reason == "" {
+				reason = "unknown"
+			}
+			stats.ByReason[reason]++
+			if log.UserID != nil {
+				users[*log.UserID] = true
+			}
+			if log.IPAddress != nil {
+				ips[*log.IPAddress]++
+			}
+			return write(complianceAuditRow(log))
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	stats.Users = len(users)
+	stats.IPAddresses = len(ips)
+	stats.TopIPs = []models.ComplianceIPCount{}
+	for ip, count := range ips {
+		stats.TopIPs = append(stats.TopIPs, models.ComplianceIPCount{IPAddress: ip, Count: count})
+	}
+	sort.Slice(stats.TopIPs, func(i, j int) bool {
+		if stats.TopIPs[i].Count != stats.TopIPs[j].Count {
+			return stats.TopIPs[i].Count > stats.TopIPs[j].Count
+		}
+		return stats.TopIPs[i].IPAddress < stats.TopIPs[j].IPAddress
+	})
+	if len(stats.TopIPs) > complianceTopIPs {
+		stats.TopIPs = stats.TopIPs[:complianceTopIPs]
+	}
+	return nil
+}
+
+// complianceAuditRow formats an audit log as a row of
+// models.AuditLogExportColumns
+func complianceAuditRow(log *models.AuditLog) []string {
+	return []string{
+		utils.ExportValue(log.ID),
+		utils.ExportValue(log.CreatedAt),
+		utils.ExportValue(log.UserID),
+		log.Action,
+		utils.ExportValue(log.ResourceType),
+		utils.ExportValue(log.ResourceID),
+		log.Status,
+		utils.ExportValue(log.IPAddress),
+		utils.ExportValue(log.UserAgent),
+		utils.ExportValue(log.Metadata),
+	}
+}
+
+// complianceReportPDF lays out the report handed to auditors
+func complianceReportPDF(summary *models.ComplianceSummary, requester string) *utils.PDFDocument {
+	doc := utils.NewPDFDocument("Compliance evidence report")
+	doc.Field("Organization", summary.TenantName)
+	doc.Field("Framework", models.ComplianceFrameworkNames[summary.Framework])
+	doc.Field("Period", fmt.Sprintf("%s to %s (UTC)", summary.PeriodStart, summary.PeriodEnd))
+	doc.Field("Generated", summary.GeneratedAt.Format("2006-01-02 15:04 UTC"))
+	doc.Field("Generated by", requester)
+	doc.Space()
+	doc.Text("This package holds the evidence behind each figure of this report as CSV and JSON files. " +
+		"manifest.json lists the SHA-256 hash of every file so the package can be checked for alterations.")
+
+	review := summary.AccessReview
+	doc.Heading("1. Access review")
+	doc.Text("User access as of the report date, for review against each user's duties. " +
+		"evidence/user_access.csv lists every user with their roles.")
+	doc.Field("Users", strconv.Itoa(review.Users))
+	doc.Field("Active users", strconv.Itoa(review.ActiveUsers))
+	doc.Field("Privileged users", strconv.Itoa(review.PrivilegedUsers))
+	doc.Field("Dormant users", fmt.Sprintf("%d (active, no sign-in for %d days)", review.DormantUsers, models.ComplianceDormantDays))
+	doc.Field("Users without roles", strconv.Itoa(review.UsersWithoutRoles))
+	doc.Field("Role changes in period", strconv.Itoa(review.RoleChanges))
+	doc.Field("User status changes", strconv.Itoa(review.StatusChanges))
+
+	admin := summary.AdminActions
+	doc.Heading("2. Administrative actions")
+	doc.Text("Changes to users, roles, invitations, settings, security, webhooks and sandboxes during the period. " +
+		"Every action is listed in evidence/admin_actions.csv.")
+	doc.Field("Actions", strconv.Itoa(admin.Total))
+	doc.Field("Failed actions", strconv.Itoa(admin.Failures))
+	doc.Field("Administrators", strconv.Itoa(admin.Actors))
+	for _, action := range sortedCounts(admin.ByAction) {
+		doc.Field("  "+action, strconv.Itoa(admin.ByAction[action]))
+	}
+
+	twoFactor := summary.TwoFactor
+	doc.Heading("3. Two-factor authentication")
+	doc.Field("Adoption", fmt.Sprintf("%d of %d active users (%.1f%%)", twoFactor.Enrolled, twoFactor.ActiveUsers, twoFactor.AdoptionPercent))
+	doc.Field("Privileged users", fmt.Sprintf("%d of %d enrolled", twoFactor.PrivilegedEnrolled, twoFactor.PrivilegedUsers))
+	doc.Field("Enabled in period", strconv.Itoa(twoFactor.EnabledInPeriod))
+	doc.Field("Disabled in period", strconv.Itoa(twoFactor.DisabledInPeriod))
+	if twoFactor.SSORequired {
+		doc.Text("Users must sign in through the organization's identity provider, which enforces its own multi-factor policy.")
+	}
+
+	policy := summary.PasswordPolicy
+	doc.Heading("4. Password policy")
+	doc.Field("Length", fmt.Sprintf("%d to %d characters", policy.MinLength, policy.MaxLength))
+	doc.Field("Complexity", "Uppercase and lowercase letters, a number and a special character")
+	doc.Field("Storage", fmt.Sprintf("%s, cost %d", policy.HashAlgorithm, policy.HashCost))
+	doc.Field("Sign-in attempts", fmt.Sprintf("%d per %s", policy.MaxLoginAttempts, policy.LoginAttemptWindow))
+	doc.Field("Reset link expiry", policy.ResetLinkExpiry)
+	doc.Field("Session inactivity", policy.SessionInactivityLimit)
+	doc.Field("Single sign-on required", utils.ExportValue(policy.SSORequired))
+
+	failed := summary.FailedLogins
+	doc.Heading("5. Failed sign-ins")
+	doc.Text("Failed sign-ins of known users during the period, listed in evidence/failed_logins.csv.")
+	doc.Field("Failed sign-ins", strconv.Itoa(failed.Total))
+	doc.Field("Users", strconv.Itoa(failed.Users))
+	doc.Field("IP addresses", strconv.Itoa(failed.IPAddresses))
+	for _, reason := range sortedCounts(failed.ByReason) {
+		doc.Field("  "+reason, strconv.Itoa(failed.ByReason[reason]))
+	}
+	if len(failed.TopIPs) > 0 {
+		doc.Space()
+		doc.Text("Most frequent IP addresses:")
+		for _, ip := range failed.TopIPs {
+			doc.Field("  "+ip.IPAddress, strconv.Itoa(ip.Count))
+		}
+	}
+
+	doc.Heading("6. Control mapping")
+	for _, control := range summary.Controls {
+		doc.Field(control.ID, control.Title)
+		for _, evidence := range control.Evidence {
+			doc.Field("", evidence)
+		}
+	}
+
+	return doc
+}
+
+// sortedCounts returns the keys of counts, largest count first
+func sortedCounts(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// complianceManifestFile is a file of a package as listed in its manifest
+type complianceManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// complianceArchive writes the files of a package, recording their sizes
+// and hashes for the manifest
+type complianceArchive struct {
+	zip      *zip.Writer
+	modified time.Time
+	files    []complianceManifestFile
+}
+
+// file adds a file written by write
+func (a *complianceArchive) file(name string, write func(io.Writer) error) error {
+	w, err := a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.modified})
+	if err != nil {
+		return fmt.Errorf("failed to write report package: %w", err)
+	}
+
+	out := &hashingWriter{w: w, hash: sha256.New()}
+	if err := write(out); err != nil {
+		return err
+	}
+
+	a.files = append(a.files, complianceManifestFile{Name: name, Size: out.n, SHA256: hex.EncodeToString(out.hash.Sum(nil))})
+	return nil
+}
+
+// csv adds a CSV file with a header row of columns
+func (a *complianceArchive) csv(name string, columns []string, rows func(write func([]string) error) error) error {
+	return a.file(name, func(w io.Writer) error {
+		out := csv.NewWriter(w)
+		if err := out.Write(columns); err != nil {
+			return err
+		}
+		if err := rows(func(row []string) error { return out.Write(utils.SpreadsheetSafeRow(row)) }); err != nil {
+			return err
+		}
+		out.Flush()
+		return out.Error()
+	})
+}
+
+// json adds an indented JSON file
+func (a *complianceArchive) json(name string, v interface{}) error {
+	return a.file(name, func(w io.Writer) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// hashingWriter hashes and counts what it writes
+type hashingWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	n    int64
+}
+
+func (h *hashingWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	h.hash.Write(p[:n])
+	h.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,1123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// defaultPipelineName names the pipeline created with the first use of the CRM
+const defaultPipelineName = "Sales"
+
+// defaultPipelineStages are the stages of the default pipeline, and of a new
+// pipeline created without stages
+var defaultPipelineStages = []models.CRMPipelineStageRequest{
+	{Name: "Qualification", Probability: 10, Outcome: models.CRMOutcomeOpen},
+	{Name: "Needs Analysis", Probability: 25, Outcome: models.CRMOutcomeOpen},
+	{Name: "Proposal", Probability: 50, Outcome: models.CRMOutcomeOpen},
+	{Name: "Negotiation", Probability: 75, Outcome: models.CRMOutcomeOpen},
+	{Name: "Won", Probability: 100, Outcome: models.CRMOutcomeWon},
+	{Name: "Lost", Probability: 0, Outcome: models.CRMOutcomeLost},
+}
+
+// CRMService handles CRM customers, contacts, pipelines, opportunities and
+// the activity timeline, and who owns each record
+type CRMService struct {
+	crmRepo           repository.CRMStore
+	userRepo          repository.UserStore
+	permissionService PermissionManager
+	notifier          NotificationManager
+}
+
+// NewCRMService creates a new CRM service
+func NewCRMService(
+	crmRepo repository.CRMStore,
+	userRepo repository.UserStore,
+	permissionService PermissionManager,
+	notifier NotificationManager,
+) *CRMService {
+	return &CRMService{
+		crmRepo:           crmRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
+		notifier:          notifier,
+	}
+}
+
+// CreateCustomer creates a customer owned by the creator unless another
+// owner is given, which requires crm.assign
+func (s *CRMService) CreateCustomer(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMCustomerCreateRequest) (*models.CRMCustomer, error) {
+	ownerID, err := s.initialOwner(ctx, tenantID, userID, req.OwnerID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := &models.CRMCustomer{
+		Name:         req.Name,
+		CustomerType: req.CustomerType,
+		Industry:     req.Industry,
+		Website:      req.Website,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		Address:      req.Address,
+		TaxID:        req.TaxID,
+		Notes:        req.Notes,
+		Status:       req.Status,
+		OwnerID:      ownerID,
+		CreatedBy:    &userID,
+	}
+	if customer.CustomerType == "" {
+		customer.CustomerType = models.CRMCustomerCompany
+	}
+	if customer.Status == "" {
+		customer.Status = models.CRMCustomerStatusLead
+	}
+
+	if err := s.crmRepo.CreateCustomer(ctx, tenantID, customer); err != nil {
+		return nil, err
+	}
+
+	s.notifyAssigned(ctx, tenantID, userID, ownerID, "customer", customer.Name, fmt.Sprintf("/crm/customers/%s", customer.ID))
+
+	return s.crmRepo.FindCustomerByID(ctx, tenantID, customer.ID)
+}
+
+// GetCustomer retrieves a customer
+func (s *CRMService) GetCustomer(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error) {
+	return s.crmRepo.FindCustomerByID(ctx, tenantID, customerID)
+}
+
+// DescribeCustomer describes a customer for watch lists and notifications
+func (s *CRMService) DescribeCustomer(ctx context.Context, tenantID, customerID uuid.UUID) (*models.EntitySummary, error) {
+	customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title: customer.Name,
+		Path:  fmt.Sprintf("/crm/customers/%s", customer.ID),
+	}, nil
+}
+
+// ListCustomers lists customers with filters and pagination
+func (s *CRMService) ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error) {
+	return s.crmRepo.ListCustomers(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateCustomer updates a customer
+func (s *CRMService) UpdateCustomer(ctx context.Context, tenantID, customerID uuid.UUID, req *models.CRMCustomerUpdateRequest) (*models.CRMCustomer, error) {
+	customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		customer.Name = *req.Name
+	}
+	if req.CustomerType != nil {
+		customer.CustomerType = *req.CustomerType
+	}
+	if req.Industry != nil {
+		customer.Industry = req.Industry
+	}
+	if req.Website != nil {
+		customer.Website = req.Website
+	}
+	if req.Email != nil {
+		customer.Email = req.Email
+	}
+	if req.Phone != nil {
+		customer.Phone = req.Phone
+	}
+	if req.Address != nil {
+		customer.Address = req.Address
+	}
+	if req.TaxID != nil {
+		customer.TaxID = req.TaxID
+	}
+	if req.Notes != nil {
+		customer.Notes = req.Notes
+	}
+	if req.Status != nil {
+		customer.Status = *req.Status
+	}
+
+	if err := s.crmRepo.UpdateCustomer(ctx, tenantID, customer); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindCustomerByID(ctx, tenantID, customer.ID)
+}
+
+// DeleteCustomer deletes a customer with its contacts and activities. A
+// customer with opportunities cannot be deleted; mark it inactive instead.
+func (s *CRMService) DeleteCustomer(ctx context.Context, tenantID, customerID uuid.UUID) error {
+	return s.crmRepo.DeleteCustomer(ctx, tenantID, customerID)
+}
+
+// CreateContact creates a contact at a customer. The owner defaults to the
+// customer's owner; naming another owner than oneself requires crm.assign.
+func (s *CRMService) CreateContact(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMContactCreateRequest) (*models.CRMContact, error) {
+	customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer")
+	}
+
+	fallback := customer.OwnerID
+	if fallback == nil {
+		fallback = &userID
+	}
+	ownerID, err := s.initialOwner(ctx, tenantID, userID, req.OwnerID, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	contact := &models.CRMContact{
+		CustomerID: customer.ID,
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		JobTitle:   req.JobTitle,
+		Notes:      req.Notes,
+		IsPrimary:  req.IsPrimary,
+		OwnerID:    ownerID,
+		CreatedBy:  &userID,
+	}
+
+	if err := s.crmRepo.CreateContact(ctx, tenantID, contact); err != nil {
+		return nil, err
+	}
+
+	s.notifyAssigned(ctx, tenantID, userID, ownerID, "contact", contact.FullName(), fmt.Sprintf("/crm/contacts/%s", contact.ID))
+
+	return s.crmRepo.FindContactByID(ctx, tenantID, contact.ID)
+}
+
+// GetContact retrieves a contact
+func (s *CRMService) GetContact(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error) {
+	return s.crmRepo.FindContactByID(ctx, tenantID, contactID)
+}
+
+// DescribeContact describes a contact for watch lists and notifications
+func (s *CRMService) DescribeContact(ctx context.Context, tenantID, contactID uuid.UUID) (*models.EntitySummary, error) {
+	contact, err := s.crmRepo.FindContactByID(ctx, tenantID, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    contact.FullName(),
+		Subtitle: contact.CustomerName,
+		Path:     fmt.Sprintf("/crm/contacts/%s", contact.ID),
+	}, nil
+}
+
+// ListContacts lists contacts with filters and pagination
+func (s *CRMService) ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error) {
+	return s.crmRepo.ListContacts(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateContact updates a contact
+func (s *CRMService) UpdateContact(ctx context.Context, tenantID, contactID uuid.UUID, req *models.CRMContactUpdateRequest) (*models.CRMContact, error) {
+	contact, err := s.crmRepo.FindContactByID(ctx, tenantID, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != nil {
+		contact.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		contact.LastName = *req.LastName
+	}
+	if req.Email != nil {
+		contact.Email = req.Email
+	}
+	if req.Phone != nil {
+		contact.Phone = req.Phone
+	}
+	if req.JobTitle != nil {
+		contact.JobTitle = req.JobTitle
+	}
+	if req.Notes != nil {
+		contact.Notes = req.Notes
+	}
+	if req.IsPrimary != nil {
+		contact.IsPrimary = *req.IsPrimary
+	}
+
+	if err := s.crmRepo.UpdateContact(ctx, tenantID, contact); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindContactByID(ctx, tenantID, contact.ID)
+}
+
+// DeleteContact deletes a contact
+func (s *CRMService) DeleteContact(ctx context.Context, tenantID, contactID uuid.UUID) error {
+	return s.crmRepo.DeleteContact(ctx, tenantID, contactID)
+}
+
+// ListPipelines lists the tenant's pipelines with their stages. The default
+// pipeline is created on first use.
+func (s *CRMService) ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error) {
+	pipelines, err := s.crmRepo.ListPipelines(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelines) > 0 {
+		return pipelines, nil
+	}
+
+	pipeline := &models.CRMPipeline{Name: defaultPipelineName, IsDefault: true, Stages: buildStages(defaultPipelineStages)}
+	if err := s.crmRepo.CreatePipeline(ctx, tenantID, pipeline); err != nil {
+		// Another request may have created it first
+		pipelines, listErr := s.crmRepo.ListPipelines(ctx, tenantID)
+		if listErr != nil || len(pipelines) == 0 {
+			return nil, err
+		}
+		return pipelines, nil
+	}
+
+	return []models.CRMPipeline{*pipeline}, nil
+}
+
+// GetPipeline retrieves a pipeline with its stages
+func (s *CRMService) GetPipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error) {
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+}
+
+// CreatePipeline creates a pipeline. Stages keep the order they are given
+// in; without stages the pipeline gets the default ones. The tenant's first
+// pipeline is always the default.
+func (s *CRMService) CreatePipeline(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error) {
+	pipelines, err := s.ListPipelines(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPipelineName(ctx, tenantID, req.Name, nil); err != nil {
+		return nil, err
+	}
+
+	stages := req.Stages
+	if len(stages) == 0 {
+		stages = defaultPipelineStages
+	}
+	if err := checkStages(stages); err != nil {
+		return nil, err
+	}
+
+	pipeline := &models.CRMPipeline{
+		Name:      req.Name,
+		IsDefault: req.IsDefault || len(pipelines) == 0,
+		CreatedBy: &userID,
+		Stages:    buildStages(stages),
+	}
+
+	if err := s.crmRepo.CreatePipeline(ctx, tenantID, pipeline); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipeline.ID)
+}
+
+// UpdatePipeline renames a pipeline or makes it the default. The default is
+// changed by making another pipeline the default, never unset.
+func (s *CRMService) UpdatePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error) {
+	pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != pipeline.Name {
+		if err := s.checkPipelineName(ctx, tenantID, req.Name, &pipelineID); err != nil {
+			return nil, err
+		}
+		pipeline.Name = req.Name
+	}
+	if req.IsDefault {
+		pipeline.IsDefault = true
+	}
+
+	if err := s.crmRepo.UpdatePipeline(ctx, tenantID, pipeline); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipeline.ID)
+}
+
+// DeletePipeline deletes a pipeline without opportunities. The default
+// pipeline cannot be deleted.
+func (s *CRMService) DeletePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) error {
+	pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+	if err != nil {
+		return err
+	}
+	if pipeline.IsDefault {
+		return fmt.Errorf("cannot delete the default pipeline")
+	}
+
+	return s.crmRepo.DeletePipeline(ctx, tenantID, pipelineID)
+}
+
+// AddStage adds a stage to a pipeline, at the end unless a position is given
+func (s *CRMService) AddStage(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error) {
+	pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stage := range pipeline.Stages {
+		if strings.EqualFold(stage.Name, req.Name) {
+			return nil, utils.NewConflictError("STAGE_NAME_EXISTS", "stage name already exists")
+		}
+	}
+
+	stage := &models.CRMPipelineStage{
+		PipelineID:  pipeline.ID,
+		Name:        req.Name,
+		Position:    req.Position,
+		Probability: req.Probability,
+		Outcome:     req.Outcome,
+	}
+	if stage.Outcome == "" {
+		stage.Outcome = models.CRMOutcomeOpen
+	}
+	if stage.Position < 1 || stage.Position > len(pipeline.Stages)+1 {
+		stage.Position = len(pipeline.Stages) + 1
+	}
+
+	if err := s.crmRepo.CreateStage(ctx, tenantID, stage); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipeline.ID)
+}
+
+// UpdateStage renames, moves or reconfigures a stage. Changing the outcome
+// changes the status of the opportunities in the stage.
+func (s *CRMService) UpdateStage(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error) {
+	pipeline, stage, err := s.findStage(ctx, tenantID, pipelineID, stageID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPosition := stage.Position
+	if req.Name != "" && !strings.EqualFold(req.Name, stage.Name) {
+		for _, other := range pipeline.Stages {
+			if strings.EqualFold(other.Name, req.Name) {
+				return nil, utils.NewConflictError("STAGE_NAME_EXISTS", "stage name already exists")
+			}
+		}
+	}
+	if req.Name != "" {
+		stage.Name = req.Name
+	}
+	if req.Position >= 1 && req.Position <= len(pipeline.Stages) {
+		stage.Position = req.Position
+	}
+	stage.Probability = req.Probability
+	if req.Outcome != "" && req.Outcome != stage.Outcome {
+		if stage.Outcome == models.CRMOutcomeOpen && countOpenStages(pipeline.Stages) == 1 {
+			return nil, fmt.Errorf("pipeline needs an open stage")
+		}
+		stage.Outcome = req.Outcome
+	}
+
+	if err := s.crmRepo.UpdateStage(ctx, tenantID, stage, oldPosition); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipeline.ID)
+}
+
+// DeleteStage deletes a stage without opportunities. A pipeline keeps at
+// least one open stage.
+func (s *CRMService) DeleteStage(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID) (*models.CRMPipeline, error) {
+	pipeline, stage, err := s.findStage(ctx, tenantID, pipelineID, stageID)
+	if err != nil {
+		return nil, err
+	}
+	if stage.Outcome == models.CRMOutcomeOpen && countOpenStages(pipeline.Stages) == 1 {
+		return nil, fmt.Errorf("pipeline needs an open stage")
+	}
+
+	if err := s.crmRepo.DeleteStage(ctx, tenantID, stage); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindPipelineByID(ctx, tenantID, pipeline.ID)
+}
+
+// PipelineSummary totals a pipeline's opportunities by stage
+func (s *CRMService) PipelineSummary(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, []models.CRMStageSummary, error) {
+	pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary, err := s.crmRepo.PipelineSummary(ctx, tenantID, pipelineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pipeline, summary, nil
+}
+
+// CreateOpportunity creates an opportunity in the given or default pipeline,
+// in the given stage or the first open one. The owner defaults to the
+// customer's owner, then the creator.
+func (s *CRMService) CreateOpportunity(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMOpportunityCreateRequest) (*models.CRMOpportunity, error) {
+	customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer")
+	}
+	if req.ContactID != nil {
+		if err := s.checkContact(ctx, tenantID, customer.ID, *req.ContactID); err != nil {
+			return nil, err
+		}
+	}
+
+	pipeline, err := s.resolvePipeline(ctx, tenantID, req.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	var stage *models.CRMPipelineStage
+	for i := range pipeline.Stages {
+		candidate := &pipeline.Stages[i]
+		if req.StageID != nil && candidate.ID == *req.StageID {
+			stage = candidate
+			break
+		}
+		if req.StageID == nil && !candidate.IsClosed() {
+			stage = candidate
+			break
+		}
+	}
+	if stage == nil {
+		return nil, fmt.Errorf("invalid stage")
+	}
+
+	fallback := customer.OwnerID
+	if fallback == nil {
+		fallback = &userID
+	}
+	ownerID, err := s.initialOwner(ctx, tenantID, userID, req.OwnerID, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = "USD"
+	}
+
+	opportunity := &models.CRMOpportunity{
+		CustomerID:        customer.ID,
+		ContactID:         req.ContactID,
+		PipelineID:        pipeline.ID,
+		StageID:           stage.ID,
+		Name:              req.Name,
+		Amount:            req.Amount,
+		Currency:          currency,
+		Probability:       stage.Probability,
+		ExpectedCloseDate: req.ExpectedCloseDate,
+		Status:            stage.Outcome,
+		OwnerID:           ownerID,
+		CreatedBy:         &userID,
+	}
+	if stage.IsClosed() {
+		now := time.Now().UTC()
+		opportunity.ClosedAt = &now
+	}
+
+	if err := s.crmRepo.CreateOpportunity(ctx, tenantID, opportunity); err != nil {
+		return nil, err
+	}
+
+	s.notifyAssigned(ctx, tenantID, userID, ownerID, "opportunity", opportunity.Name, fmt.Sprintf("/crm/opportunities/%s", opportunity.ID))
+
+	return s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunity.ID)
+}
+
+// GetOpportunity retrieves an opportunity
+func (s *CRMService) GetOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error) {
+	return s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunityID)
+}
+
+// DescribeOpportunity describes an opportunity for watch lists and
+// notifications
+func (s *CRMService) DescribeOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.EntitySummary, error) {
+	opportunity, err := s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunityID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    opportunity.Name,
+		Subtitle: opportunity.CustomerName,
+		Path:     fmt.Sprintf("/crm/opportunities/%s", opportunity.ID),
+	}, nil
+}
+
+// ListOpportunities lists opportunities with filters and pagination
+func (s *CRMService) ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error) {
+	return s.crmRepo.ListOpportunities(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateOpportunity updates an opportunity's details
+func (s *CRMService) UpdateOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID, req *models.CRMOpportunityUpdateRequest) (*models.CRMOpportunity, error) {
+	opportunity, err := s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunityID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.ClearContact:
+		opportunity.ContactID = nil
+	case req.ContactID != nil:
+		if err := s.checkContact(ctx, tenantID, opportunity.CustomerID, *req.ContactID); err != nil {
+			return nil, err
+		}
+		opportunity.ContactID = req.ContactID
+	}
+
+	if req.Name != nil {
+		opportunity.Name = *req.Name
+	}
+	if req.Amount != nil {
+		opportunity.Amount = *req.Amount
+	}
+	if req.Currency != nil {
+		opportunity.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Probability != nil {
+		opportunity.Probability = *req.Probability
+	}
+	if req.ExpectedCloseDate != nil {
+		opportunity.ExpectedCloseDate = req.ExpectedCloseDate
+	}
+
+	if err := s.crmRepo.UpdateOpportunity(ctx, tenantID, opportunity, nil); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunity.ID)
+}
+
+// MoveStage moves an opportunity to another stage of its pipeline. The
+// opportunity takes the stage's probability and outcome, and the move is
+// logged on its timeline.
+func (s *CRMService) MoveStage(ctx context.Context, tenantID, userID, opportunityID uuid.UUID, req *models.CRMStageChangeRequest) (*models.CRMOpportunity, error) {
+	opportunity, err := s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunityID)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := s.crmRepo.FindStageByID(ctx, tenantID, req.StageID)
+	if err != nil || stage.PipelineID != opportunity.PipelineID {
+		return nil, fmt.Errorf("invalid stage")
+	}
+	if stage.ID == opportunity.StageID {
+		return opportunity, nil
+	}
+
+	subject := fmt.Sprintf("Stage changed from %s to %s", opportunity.StageName, stage.Name)
+	opportunity.StageID = stage.ID
+	opportunity.Probability = stage.Probability
+	opportunity.Status = stage.Outcome
+	opportunity.LostReason = nil
+
+	switch stage.Outcome {
+	case models.CRMOutcomeOpen:
+		opportunity.ClosedAt = nil
+	default:
+		if opportunity.ClosedAt == nil {
+			now := time.Now().UTC()
+			opportunity.ClosedAt = &now
+		}
+		if stage.Outcome == models.CRMOutcomeLost && req.LostReason != nil && *req.LostReason != "" {
+			opportunity.LostReason = req.LostReason
+		}
+	}
+
+	activity := &models.CRMActivity{
+		CustomerID:    opportunity.CustomerID,
+		OpportunityID: &opportunity.ID,
+		ActivityType:  models.CRMActivitySystem,
+		Subject:       subject,
+		Body:          opportunity.LostReason,
+		CompletedAt:   ptrTime(time.Now().UTC()),
+		CreatedBy:     &userID,
+	}
+
+	if err := s.crmRepo.UpdateOpportunity(ctx, tenantID, opportunity, activity); err != nil {
+		return nil, err
+	}
+
+	return s.crmRepo.FindOpportunityByID(ctx, tenantID, opportunity.ID)
+}
+
+// DeleteOpportunity deletes an opportunity and its activities
+func (s *CRMService) DeleteOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) error {
+	return s.crmRepo.DeleteOpportunity(ctx, tenantID, opportunityID)
+}
+
+// Assign gives a customer, contact or opportunity to another active user,
+// or unassigns it when ownerID is nil. The change is logged on the
+// customer's timeline and the new owner is notified.
+func (s *CRMService) Assign(ctx context.Context, tenantID, userID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID) error {
+	var (
+		customerID    uuid.UUID
+		contactID     *uuid.UUID
+		opportunityID *uuid.UUID
+		currentOwner  *uuid.UUID
+		name, link    string
+	)
+
+	switch entity {
+	case "customer":
+		customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, entityID)
+		if err != nil {
+			return err
+		}
+		customerID, currentOwner, name = customer.ID, customer.OwnerID, customer.Name
+		link = fmt.Sprintf("/crm/customers/%s", customer.ID)
+	case "contact":
+		contact, err := s.crmRepo.FindContactByID(ctx, tenantID, entityID)
+		if err != nil {
+			return err
+		}
+		customerID, contactID, currentOwner, name = contact.CustomerID, &contact.ID, contact.OwnerID, contact.FullName()
+		link = fmt.Sprintf("/crm/contacts/%s", contact.ID)
+	case "opportunity":
+		opportunity, err := s.crmRepo.FindOpportunityByID(ctx, tenantID, entityID)
+		if err != nil {
+			return err
+		}
+		customerID, opportunityID, currentOwner, name = opportunity.CustomerID, &opportunity.ID, opportunity.OwnerID, opportunity.Name
+		link = fmt.Sprintf("/crm/opportunities/%s", opportunity.ID)
+	default:
+		return fmt.Errorf("unknown crm entity %q", entity)
+	}
+
+	if sameOwner(currentOwner, ownerID) {
+		return nil
+	}
+
+	subject := fmt.Sprintf("%s unassigned", capitalizeEntity(entity))
+	if ownerID != nil {
+		owner, err := s.checkOwner(ctx, tenantID, *ownerID)
+		if err != nil {
+			return err
+		}
+		subject = fmt.Sprintf("%s assigned to %s", capitalizeEntity(entity), owner.FullName())
+	}
+
+	activity := &models.CRMActivity{
+		CustomerID:    customerID,
+		ContactID:     contactID,
+		OpportunityID: opportunityID,
+		ActivityType:  models.CRMActivitySystem,
+		Subject:       subject,
+		CompletedAt:   ptrTime(time.Now().UTC()),
+		CreatedBy:     &userID,
+	}
+
+	if err := s.crmRepo.AssignOwner(ctx, tenantID, entity, entityID, ownerID, activity); err != nil {
+		return err
+	}
+
+	s.notifyAssigned(ctx, tenantID, userID, ownerID, entity, name, link)
+	return nil
+}
+
+// LogActivity logs an activity. The customer is taken from the opportunity
+// or contact when left out. Giving the activity to someone else requires
+// crm.assign, and they are notified of tasks.
+func (s *CRMService) LogActivity(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMActivityCreateRequest) (*models.CRMActivity, error) {
+	customerID, err := s.resolveActivityCustomer(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := s.initialOwner(ctx, tenantID, userID, req.OwnerID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &models.CRMActivity{
+		CustomerID:    customerID,
+		ContactID:     req.ContactID,
+		OpportunityID: req.OpportunityID,
+		ActivityType:  req.ActivityType,
+		Subject:       req.Subject,
+		Body:          req.Body,
+		DueAt:         req.DueAt,
+		OwnerID:       ownerID,
+		CreatedBy:     &userID,
+	}
+	if req.Completed {
+		activity.CompletedAt = ptrTime(time.Now().UTC())
+	}
+
+	if err := s.crmRepo.CreateActivity(ctx, tenantID, activity); err != nil {
+		return nil, err
+	}
+
+	if activity.ActivityType == models.CRMActivityTask && activity.CompletedAt == nil {
+		s.notifyTask(ctx, tenantID, userID, activity)
+	}
+
+	return s.crmRepo.FindActivityByID(ctx, tenantID, activity.ID)
+}
+
+// GetActivity retrieves an activity
+func (s *CRMService) GetActivity(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error) {
+	return s.crmRepo.FindActivityByID(ctx, tenantID, activityID)
+}
+
+// ListActivities lists activities with filters and pagination
+func (s *CRMService) ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error) {
+	return s.crmRepo.ListActivities(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateActivity updates an activity, completing or reopening it. System
+// entries cannot be changed.
+func (s *CRMService) UpdateActivity(ctx context.Context, tenantID, userID, activityID uuid.UUID, req *models.CRMActivityUpdateRequest) (*models.CRMActivity, error) {
+	activity, err := s.crmRepo.FindActivityByID(ctx, tenantID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if activity.IsSystem() {
+		return nil, fmt.Errorf("system activities cannot be changed")
+	}
+
+	reassigned := false
+	if req.OwnerID != nil && !sameOwner(activity.OwnerID, req.OwnerID) {
+		if *req.OwnerID != userID {
+			if err := s.requireAssign(ctx, tenantID, userID); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := s.checkOwner(ctx, tenantID, *req.OwnerID); err != nil {
+			return nil, err
+		}
+		activity.OwnerID = req.OwnerID
+		reassigned = true
+	}
+
+	if req.Subject != nil {
+		activity.Subject = *req.Subject
+	}
+	if req.Body != nil {
+		activity.Body = req.Body
+	}
+	if req.DueAt != nil {
+		activity.DueAt = req.DueAt
+	}
+	if req.Completed != nil {
+		switch {
+		case *req.Completed && activity.CompletedAt == nil:
+			activity.CompletedAt = ptrTime(time.Now().UTC())
+		case !*req.Completed:
+			activity.CompletedAt = nil
+		}
+	}
+
+	if err := s.crmRepo.UpdateActivity(ctx, tenantID, activity); err != nil {
+		return nil, err
+	}
+
+	if reassigned && activity.ActivityType == models.CRMActivityTask && activity.CompletedAt == nil {
+		s.notifyTask(ctx, tenantID, userID, activity)
+	}
+
+	return s.crmRepo.FindActivityByID(ctx, tenantID, activity.ID)
+}
+
+// DeleteActivity deletes an activity. System entries cannot be deleted.
+func (s *CRMService) DeleteActivity(ctx context.Context, tenantID, activityID uuid.UUID) error {
+	activity, err := s.crmRepo.FindActivityByID(ctx, tenantID, activityID)
+	if err != nil {
+		return err
+	}
+	if activity.IsSystem() {
+		return fmt.Errorf("system activities cannot be changed")
+	}
+
+	return s.crmRepo.DeleteActivity(ctx, tenantID, activityID)
+}
+
+// initialOwner resolves the owner of a new record: the requested owner, or
+// the fallback. Naming someone other than oneself requires crm.assign.
+func (s *CRMService) initialOwner(ctx context.Context, tenantID, userID uuid.UUID, requested, fallback *uuid.UUID) (*uuid.UUID, error) {
+	if requested == nil {
+		return fallback, nil
+	}
+
+	if *requested != userID {
+		if err := s.requireAssign(ctx, tenantID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.checkOwner(ctx, tenantID, *requested); err != nil {
+		return nil, err
+	}
+
+	return requested, nil
+}
+
+// requireAssign checks that the user may give CRM records to others
+func (s *CRMService) requireAssign(ctx context.Context, tenantID, userID uuid.UUID) error {
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceCRM, models.ActionAssign)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	return nil
+}
+
+// checkOwner checks that a user exists and is active
+func (s *CRMService) checkOwner(ctx context.Context, tenantID, ownerID uuid.UUID) (*models.User, error) {
+	owner, err := s.userRepo.FindByID(ctx, tenantID, ownerID)
+	if err != nil || !owner.IsActive() {
+		return nil, fmt.Errorf("invalid owner")
+	}
+
+	return owner, nil
+}
+
+// checkContact checks that a contact belongs to the customer
+func (s *CRMService) checkContact(ctx context.Context, tenantID, customerID, contactID uuid.UUID) error {
+	contact, err := s.crmRepo.FindContactByID(ctx, tenantID, contactID)
+	if err != nil || contact.CustomerID != customerID {
+		return fmt.Errorf("invalid contact")
+	}
+
+	return nil
+}
+
+// checkPipelineName checks that a pipeline name is not taken
+func (s *CRMService) checkPipelineName(ctx context.Context, tenantID uuid.UUID, name string, pipelineID *uuid.UUID) error {
+	exists, err := s.crmRepo.CheckPipelineNameExists(ctx, tenantID, name, pipelineID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("PIPELINE_NAME_EXISTS", "pipeline name already exists")
+	}
+
+	return nil
+}
+
+// resolvePipeline returns the requested pipeline or the tenant's default
+func (s *CRMService) resolvePipeline(ctx context.Context, tenantID uuid.UUID, pipelineID *uuid.UUID) (*models.CRMPipeline, error) {
+	if pipelineID != nil {
+		pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, *pipelineID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pipeline")
+		}
+		return pipeline, nil
+	}
+
+	pipelines, err := s.ListPipelines(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pipelines {
+		if pipelines[i].IsDefault {
+			return &pipelines[i], nil
+		}
+	}
+
+	return &pipelines[0], nil
+}
+
+// findStage loads a pipeline and one of its stages
+func (s *CRMService) findStage(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID) (*models.CRMPipeline, *models.CRMPipelineStage, error) {
+	pipeline, err := s.crmRepo.FindPipelineByID(ctx, tenantID, pipelineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range pipeline.Stages {
+		if pipeline.Stages[i].ID == stageID {
+			stage := pipeline.Stages[i]
+			return pipeline, &stage, nil
+		}
+	}
+
+	return nil, nil, utils.NewNotFoundError("STAGE_NOT_FOUND", "stage not found")
+}
+
+// resolveActivityCustomer finds the customer an activity is about and checks
+// that its contact and opportunity belong to that customer
+func (s *CRMService) resolveActivityCustomer(ctx context.Context, tenantID uuid.UUID, req *models.CRMActivityCreateRequest) (uuid.UUID, error) {
+	var customerID *uuid.UUID
+	if req.CustomerID != nil {
+		if _, err := s.crmRepo.FindCustomerByID(ctx, tenantID, *req.CustomerID); err != nil {
+			return uuid.Nil, fmt.Errorf("invalid customer")
+		}
+		customerID = req.CustomerID
+	}
+
+	if req.OpportunityID != nil {
+		opportunity, err := s.crmRepo.FindOpportunityByID(ctx, tenantID, *req.OpportunityID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid opportunity")
+		}
+		if customerID != nil && *customerID != opportunity.CustomerID {
+			return uuid.Nil, fmt.Errorf("activity links belong to different customers")
+		}
+		customerID = &opportunity.CustomerID
+	}
+
+	if req.ContactID != nil {
+		contact, err := s.crmRepo.FindContactByID(ctx, tenantID, *req.ContactID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid contact")
+		}
+		if customerID != nil && *customerID != contact.CustomerID {
+			return uuid.Nil, fmt.Errorf("activity links belong to different customers")
+		}
+		customerID = &contact.CustomerID
+	}
+
+	if customerID == nil {
+		return uuid.Nil, fmt.Errorf("invalid customer")
+	}
+
+	return *customerID, nil
+}
+
+// notifyAssigned tells a new owner about a record someone else gave them.
+// Failures are only logged: the change is already committed.
+func (s *CRMService) notifyAssigned(ctx context.Context, tenantID, actorID uuid.UUID, ownerID *uuid.UUID, entity, name, link string) {
+	if ownerID == nil || *ownerID == actorID {
+		return
+	}
+
+	req := &models.NotificationRequest{
+		Type:  models.NotificationTypeCRMAssigned,
+		Title: fmt.Sprintf("%s assigned to you", capitalizeEntity(entity)),
+		Body:  name,
+		Link:  link,
+		Data:  map[string]interface{}{"entity": entity},
+	}
+	if err := s.notifier.Notify(ctx, tenantID, []uuid.UUID{*ownerID}, req); err != nil {
+		log.Printf("⚠️  Failed to send %s notification in tenant %s: %v", req.Type, tenantID, err)
+	}
+}
+
+// notifyTask tells the owner of an open task someone else gave them
+func (s *CRMService) notifyTask(ctx context.Context, tenantID, actorID uuid.UUID, activity *models.CRMActivity) {
+	if activity.OwnerID == nil || *activity.OwnerID == actorID {
+		return
+	}
+
+	body := activity.Subject
+	if activity.DueAt != nil {
+		body = fmt.Sprintf("%s - due %s", activity.Subject, activity.DueAt.UTC().Format("Jan 2, 2006 15:04 UTC"))
+	}
+
+	req := &models.NotificationRequest{
+		Type:  models.NotificationTypeCRMTaskAssigned,
+		Title: "New CRM task",
+		Body:  body,
+		Link:  fmt.Sprintf("/crm/customers/%s", activity.CustomerID),
+		Data:  map[string]interface{}{"activity_id": activity.ID},
+	}
+	if err := s.notifier.Notify(ctx, tenantID, []uuid.UUID{*activity.OwnerID}, req); err != nil {
+		log.Printf("⚠️  Failed to send %s notification in tenant %s: %v", req.Type, tenantID, err)
+	}
+}
+
+// checkStages checks the stages of a new pipeline: unique names and at
+// least one open stage
+func checkStages(stages []models.CRMPipelineStageRequest) error {
+	seen := make(map[string]bool, len(stages))
+	open := false
+	for _, stage := range stages {
+		key := strings.ToLower(stage.Name)
+		if seen[key] {
+			return utils.NewConflictError("STAGE_NAME_EXISTS", "stage name already exists")
+		}
+		seen[key] = true
+		if stage.Outcome == "" || stage.Outcome == models.CRMOutcomeOpen {
+			open = true
+		}
+	}
+	if !open {
+		return fmt.Errorf("pipeline needs an open stage")
+	}
+
+	return nil
+}
+
+// buildStages turns stage requests into stages positioned in request order
+func buildStages(requests []models.CRMPipelineStageRequest) []models.CRMPipelineStage {
+	stages := make([]models.CRMPipelineStage, len(requests))
+	for i, req := range requests {
+		outcome := req.Outcome
+		if outcome == "" {
+			outcome = models.CRMOutcomeOpen
+		}
+		stages[i] = models.CRMPipelineStage{
+			Name:        req.Name,
+			Position:    i + 1,
+			Probability: req.Probability,
+			Outcome:     outcome,
+		}
+	}
+
+	return stages
+}
+
+// countOpenStages counts the open stages of a pipeline
+func countOpenStages(stages []models.CRMPipelineStage) int {
+	count := 0
+	for _, stage := range stages {
+		if !stage.IsClosed() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// sameOwner reports whether two optional owners are the same
+func sameOwner(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+// capitalizeEntity returns a CRM entity name for messages ("Customer")
+func capitalizeEntity(entity string) string {
+	if entity == "" {
+		return entity
+	}
+
+	return strings.ToUpper(entity[:1]) + entity[1:]
+}
+
+// ptrTime returns a pointer to a time
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
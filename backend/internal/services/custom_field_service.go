@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// maxCustomFieldsPerEntity bounds how many custom fields a tenant defines on
+// an entity type
+const maxCustomFieldsPerEntity = 50
+
+// customFieldTextMaxLength bounds the value of a text field, in characters
+const customFieldTextMaxLength = 1000
+
+// customFieldFilterPrefix prefixes the list query parameters filtering on a
+// custom field, e.g. cf.cost_center=R%26D
+const customFieldFilterPrefix = "cf."
+
+// customFieldKeyPattern is the format of custom field keys
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// CustomFieldService manages the fields tenants define on users and
+// departments, and validates their values. Values are kept in the entity's
+// custom_fields object by key: text and select values as strings, numbers as
+// JSON numbers and dates as YYYY-MM-DD strings.
+type CustomFieldService struct {
+	customFieldRepo repository.CustomFieldStore
+}
+
+// NewCustomFieldService creates a new custom field service
+func NewCustomFieldService(customFieldRepo repository.CustomFieldStore) *CustomFieldService {
+	return &CustomFieldService{
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+// ListFields lists a tenant's custom fields in display order, only those of
+// an entity type unless entity is empty
+func (s *CustomFieldService) ListFields(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error) {
+	return s.customFieldRepo.List(ctx, tenantID, entity)
+}
+
+// GetField retrieves a custom field
+func (s *CustomFieldService) GetField(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error) {
+	return s.customFieldRepo.FindByID(ctx, tenantID, fieldID)
+}
+
+// CreateField defines a custom field on an entity type
+func (s *CustomFieldService) CreateField(ctx context.Context, tenantID, userID uuid.UUID, req *models.CustomFieldCreateRequest) (*models.CustomField, error) {
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		return nil, utils.NewValidationError("INVALID_CUSTOM_FIELD_KEY", "key must start with a lowercase letter and contain only lowercase letters, digits and underscores (at most 50)")
+	}
+
+	existing, err := s.customFieldRepo.List(ctx, tenantID, req.Entity)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxCustomFieldsPerEntity {
+		return nil, utils.NewValidationError("CUSTOM_FIELD_LIMIT", fmt.Sprintf("at most %d custom fields can be defined on %s", maxCustomFieldsPerEntity, req.Entity))
+	}
+
+	options, err := customFieldOptions(req.FieldType, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	field := &models.CustomField{
+		TenantID:  tenantID,
+		Entity:    req.Entity,
+		Key:       req.Key,
+		Label:     strings.TrimSpace(req.Label),
+		FieldType: req.FieldType,
+		Options:   options,
+		Required:  req.Required,
+		SortOrder: req.SortOrder,
+		CreatedBy: &userID,
+	}
+	if err := s.customFieldRepo.Create(ctx, field); err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// UpdateField replaces a custom field's label, options and validation. Values
+// set before keep, even when no longer among a select field's options.
+func (s *CustomFieldService) UpdateField(ctx context.Context, tenantID, fieldID uuid.UUID, req *models.CustomFieldUpdateRequest) (*models.CustomField, error) {
+	field, err := s.customFieldRepo.FindByID(ctx, tenantID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := customFieldOptions(field.FieldType, req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	field.Label = strings.TrimSpace(req.Label)
+	field.Options = options
+	field.Required = req.Required
+	field.SortOrder = req.SortOrder
+	if err := s.customFieldRepo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// DeleteField deletes a custom field along with its values
+func (s *CustomFieldService) DeleteField(ctx context.Context, tenantID, fieldID uuid.UUID) error {
+	return s.customFieldRepo.Delete(ctx, tenantID, fieldID)
+}
+
+// ApplyValues validates the custom field values sent for an entity and merges
+// them into its current custom_fields. A null value removes a value. When
+// creating, every required field must be given a value.
+func (s *CustomFieldService) ApplyValues(ctx context.Context, tenantID uuid.UUID, entity string, current json.RawMessage, values models.CustomFieldValues, creating bool) (json.RawMessage, error) {
+	if len(values) == 0 && !creating {
+		return current, nil
+	}
+
+	fields, err := s.customFieldRepo.List(ctx, tenantID, entity)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.CustomField, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	merged := map[string]interface{}{}
+	if len(current) > 0 {
+		if err := json.Unmarshal(current, &merged); err != nil {
+			return nil, fmt.Errorf("failed to read custom fields: %w", err)
+		}
+	}
+
+	for key, value := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, utils.NewValidationError("UNKNOWN_CUSTOM_FIELD", fmt.Sprintf("%s has no custom field %s", entity, key))
+		}
+		if value == nil {
+			if field.Required {
+				return nil, customFieldRequired(field)
+			}
+			delete(merged, key)
+			continue
+		}
+		if merged[key], err = customFieldValue(field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if creating {
+		for i := range fields {
+			if _, ok := merged[fields[i].Key]; !ok && fields[i].Required {
+				return nil, customFieldRequired(&fields[i])
+			}
+		}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write custom fields: %w", err)
+	}
+	return data, nil
+}
+
+// ParseFilter reads the cf.<key> query parameters of an entity list into the
+// custom field values the entities must have, as a JSON object. Returns nil
+// when there are none.
+func (s *CustomFieldService) ParseFilter(ctx context.Context, tenantID uuid.UUID, entity string, query url.Values) (json.RawMessage, error) {
+	filters := map[string]string{}
+	for name, values := range query {
+		if strings.HasPrefix(name, customFieldFilterPrefix) && len(values) > 0 && values[0] != "" {
+			filters[strings.TrimPrefix(name, customFieldFilterPrefix)] = values[0]
+		}
+	}
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	fields, err := s.customFieldRepo.List(ctx, tenantID, entity)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.CustomField, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	contained := make(map[string]interface{}, len(filters))
+	for key, raw := range filters {
+		invalid := utils.NewBadRequestError("INVALID_FILTER", fmt.Sprintf("invalid %s%s filter", customFieldFilterPrefix, key))
+
+		field, ok := byKey[key]
+		if !ok {
+			return nil, invalid
+		}
+
+		var value interface{} = raw
+		if field.FieldType == models.CustomFieldTypeNumber {
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, invalid
+			}
+			value = number
+		}
+		if contained[key], err = customFieldValue(field, value); err != nil {
+			return nil, invalid
+		}
+	}
+
+	data, err := json.Marshal(contained)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write custom field filter: %w", err)
+	}
+	return data, nil
+}
+
+// customFieldValue checks a value against its field's type, returning it as
+// stored
+func customFieldValue(field *models.CustomField, value interface{}) (interface{}, error) {
+	invalid := func(expected string) error {
+		return utils.NewValidationError("INVALID_CUSTOM_FIELD", fmt.Sprintf("%s must be %s", field.Label, expected))
+	}
+
+	switch field.FieldType {
+	case models.CustomFieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, invalid("a number")
+		}
+		return number, nil
+	case models.CustomFieldTypeDate:
+		date, ok := value.(string)
+		if !ok {
+			return nil, invalid("a date (YYYY-MM-DD)")
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, invalid("a date (YYYY-MM-DD)")
+		}
+		return date, nil
+	case models.CustomFieldTypeSelect:
+		option, ok := value.(string)
+		if ok {
+			for _, o := range field.Options {
+				if option == o {
+					return option, nil
+				}
+			}
+		}
+		return nil, invalid("one of " + strings.Join(field.Options, ", "))
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("text")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" && field.Required {
+			return nil, customFieldRequired(field)
+		}
+		if len([]rune(text)) > customFieldTextMaxLength {
+			return nil, invalid(fmt.Sprintf("at most %d characters", customFieldTextMaxLength))
+		}
+		return text, nil
+	}
+}
+
+// customFieldRequired is the error for a required field left without a value
+func customFieldRequired(field *models.CustomField) error {
+	return utils.NewValidationError("CUSTOM_FIELD_REQUIRED", fmt.Sprintf("%s is required", field.Label))
+}
+
+// customFieldOptions checks the options of a field: select fields need at
+// least one, distinct, and other types take none
+func customFieldOptions(fieldType string, options []string) ([]string, error) {
+	if fieldType != models.CustomFieldTypeSelect {
+		if len(options) > 0 {
+			return nil, utils.NewValidationError("INVALID_CUSTOM_FIELD_OPTIONS", "only select fields have options")
+		}
+		return []string{}, nil
+	}
+
+	seen := make(map[string]bool, len(options))
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			return nil, utils.NewValidationError("INVALID_CUSTOM_FIELD_OPTIONS", "options must be distinct and not empty")
+		}
+		seen[option] = true
+		cleaned = append(cleaned, option)
+	}
+	if len(cleaned) == 0 {
+		return nil, utils.NewValidationError("INVALID_CUSTOM_FIELD_OPTIONS", "select fields need at least one option")
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+const (
+	dashboardCacheTTL       = 5 * time.Minute
+	dashboardCacheKeyPrefix = "dashboard:"
+)
+
+// DashboardService computes the tenant dashboard analytics. The results are
+// cached in Redis per tenant for dashboardCacheTTL, so the dashboard lags
+// behind by at most that long.
+type DashboardService struct {
+	dashboardRepo repository.DashboardStore
+	redis         *redis.Client
+}
+
+// NewDashboardService creates a new dashboard service
+func NewDashboardService(dashboardRepo repository.DashboardStore, redisClient *redis.Client) *DashboardService {
+	return &DashboardService{
+		dashboardRepo: dashboardRepo,
+		redis:         redisClient,
+	}
+}
+
+// GetStats retrieves the tenant's dashboard analytics (with caching)
+func (s *DashboardService) GetStats(ctx context.Context, tenantID uuid.UUID) (*models.DashboardStats, error) {
+	// Try cache first
+	cacheKey := database.CacheKey(dashboardCacheKeyPrefix, tenantID.String())
+	cachedData, err := s.redis.Get(ctx, cacheKey).Result()
+
+	if err == nil {
+		// Cache hit
+		var stats models.DashboardStats
+		if err := json.Unmarshal([]byte(cachedData), &stats); err == nil {
+			return &stats, nil
+		}
+	}
+
+	// Cache miss - query database
+	stats, err := s.getStatsFromDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := json.Marshal(stats)
+	s.redis.Set(ctx, cacheKey, data, dashboardCacheTTL)
+
+	return stats, nil
+}
+
+// getStatsFromDB runs the dashboard aggregations
+func (s *DashboardService) getStatsFromDB(ctx context.Context, tenantID uuid.UUID) (*models.DashboardStats, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	activity, err := s.dashboardRepo.ListActivityDays(ctx, tenantID, today.AddDate(0, 0, 1-models.DashboardTrendDays))
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.dashboardRepo.CountInvitations(ctx, tenantID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.dashboardRepo.CountSessionsByDevice(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	newUsers, err := s.dashboardRepo.CountNewUsers(ctx, tenantID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DashboardStats{
+		Activity:         activity,
+		Invitations:      *invitations,
+		SessionsByDevice: devices,
+		NewUsers:         *newUsers,
+		GeneratedAt:      now,
+	}, nil
+}
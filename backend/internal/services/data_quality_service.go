@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// dataQualitySampleSize is how many records with an issue a run keeps per rule
+const dataQualitySampleSize = 20
+
+// DataQualityService manages a tenant's data-quality rules. The data_quality
+// job (RunScheduled) runs every enabled rule, keeps its result on the rule
+// for the report, and emails each rule's data stewards when its number of
+// issues grew since the previous run.
+type DataQualityService struct {
+	db                *sqlx.DB
+	dataQualityRepo   repository.DataQualityStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	tenantRepo        repository.TenantStore
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	config            *config.Config
+}
+
+// NewDataQualityService creates a new data-quality service
+func NewDataQualityService(
+	db *sqlx.DB,
+	dataQualityRepo repository.DataQualityStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	tenantRepo repository.TenantStore,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	cfg *config.Config,
+) *DataQualityService {
+	return &DataQualityService{
+		db:                db,
+		dataQualityRepo:   dataQualityRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		tenantRepo:        tenantRepo,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		config:            cfg,
+	}
+}
+
+// ListRules lists a tenant's rules
+func (s *DataQualityService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error) {
+	rules, err := s.dataQualityRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].Check = models.FindDataQualityCheck(rules[i].CheckKey)
+	}
+	return rules, nil
+}
+
+// GetRule retrieves a rule
+func (s *DataQualityService) GetRule(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.DataQualityRule, error) {
+	rule, err := s.dataQualityRepo.FindByID(ctx, tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	rule.Check = models.FindDataQualityCheck(rule.CheckKey)
+	return rule, nil
+}
+
+// CreateRule enables a check for the tenant. A tenant has at most one rule
+// per check. The rule first runs with the next scheduled run, or when the
+// report is run on demand.
+func (s *DataQualityService) CreateRule(ctx context.Context, tenantID, userID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error) {
+	check := models.FindDataQualityCheck(req.CheckKey)
+	if check == nil {
+		return nil, fmt.Errorf("invalid check: %s", req.CheckKey)
+	}
+
+	exists, err := s.dataQualityRepo.CheckExists(ctx, tenantID, req.CheckKey)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.NewConflictError("DATA_QUALITY_RULE_EXISTS", "a rule for this check already exists")
+	}
+
+	rule := &models.DataQualityRule{
+		TenantID:  tenantID,
+		CheckKey:  check.Key,
+		IsEnabled: true,
+		Samples:   models.DataQualityIssues{},
+		CreatedBy: userID,
+		Check:     check,
+	}
+
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.dataQualityRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// UpdateRule replaces a rule's severity, data stewards and state. Its check
+// cannot be changed.
+func (s *DataQualityService) UpdateRule(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error) {
+	rule, err := s.GetRule(ctx, tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CheckKey != "" && req.CheckKey != rule.CheckKey {
+		return nil, fmt.Errorf("the check of a rule cannot be changed")
+	}
+
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.dataQualityRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// DeleteRule deletes a rule and its last result
+func (s *DataQualityService) DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error {
+	return s.dataQualityRepo.Delete(ctx, tenantID, ruleID)
+}
+
+// GetReport builds the tenant's data-quality report from the last run of its
+// enabled rules. Rules that have not run yet are listed without counts.
+func (s *DataQualityService) GetReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error) {
+	rules, err := s.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DataQualityReport{
+		IssuesBySeverity: make(map[string]int, len(models.DataQualitySeverities)),
+		Rules:            []models.DataQualityRule{},
+	}
+	for _, severity := range models.DataQualitySeverities {
+		report.IssuesBySeverity[severity] = 0
+	}
+
+	for _, rule := range rules {
+		if !rule.IsEnabled {
+			continue
+		}
+		report.Rules = append(report.Rules, rule)
+
+		if rule.LastCheckedAt != nil && (report.GeneratedAt == nil || rule.LastCheckedAt.Before(*report.GeneratedAt)) {
+			report.GeneratedAt = rule.LastCheckedAt
+		}
+		if rule.IssueCount != nil {
+			report.TotalIssues += *rule.IssueCount
+			report.IssuesBySeverity[rule.Severity] += *rule.IssueCount
+		}
+	}
+
+	return report, nil
+}
+
+// RunReport runs the tenant's enabled rules now and returns the refreshed
+// report. Data stewards are only notified by scheduled runs.
+func (s *DataQualityService) RunReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error) {
+	rules, err := s.dataQualityRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if !rules[i].IsEnabled {
+			continue
+		}
+		if _, err := s.runRule(ctx, &rules[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetReport(ctx, tenantID)
+}
+
+// RunScheduled runs the enabled rules of every tenant in every data region
+// and notifies data stewards of rules that found more issues than before.
+// Returns the number of rules run. A tenant that fails is logged and skipped
+// so it does not hold up the others.
+func (s *DataQualityService) RunScheduled(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		rules, err := s.dataQualityRepo.ListEnabled(ctx, db)
+		if err != nil {
+			return total, err
+		}
+
+		// Rules are ordered by tenant
+		for start := 0; start < len(rules); {
+			end := start
+			for end < len(rules) && rules[end].TenantID == rules[start].TenantID {
+				end++
+			}
+
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+
+			ran, err := s.runTenant(ctx, rules[start].TenantID, rules[start:end])
+			total += ran
+			if err != nil {
+				log.Printf("⚠️  Data quality run of tenant %s failed: %v", rules[start].TenantID, err)
+			}
+
+			start = end
+		}
+	}
+
+	return total, nil
+}
+
+// runTenant runs one tenant's rules, then emails each data steward one
+// message listing the rules of theirs that found more issues
+func (s *DataQualityService) runTenant(ctx context.Context, tenantID uuid.UUID, rules []models.DataQualityRule) (int, error) {
+	alertsByRole := make(map[uuid.UUID][]models.DataQualityAlert)
+
+	ran := 0
+	for i := range rules {
+		rule := &rules[i]
+
+		previous := 0
+		if rule.IssueCount != nil {
+			previous = *rule.IssueCount
+		}
+
+		count, err := s.runRule(ctx, rule)
+		if err != nil {
+			return ran, err
+		}
+		ran++
+
+		if count > previous && rule.NotifyRoleID != nil {
+			name := rule.CheckKey
+			if check := models.FindDataQualityCheck(rule.CheckKey); check != nil {
+				name = check.Name
+			}
+			alertsByRole[*rule.NotifyRoleID] = append(alertsByRole[*rule.NotifyRoleID], models.DataQualityAlert{
+				Name:          name,
+				Severity:      rule.Severity,
+				IssueCount:    count,
+				PreviousCount: previous,
+			})
+		}
+	}
+
+	if len(alertsByRole) > 0 {
+		if err := s.notifyStewards(ctx, tenantID, alertsByRole); err != nil {
+			return ran, err
+		}
+	}
+
+	return ran, nil
+}
+
+// runRule runs a rule's check and records the result on the rule
+func (s *DataQualityService) runRule(ctx context.Context, rule *models.DataQualityRule) (int, error) {
+	count, samples, err := s.dataQualityRepo.RunCheck(ctx, rule.TenantID, rule.CheckKey, dataQualitySampleSize)
+	if err != nil {
+		return 0, err
+	}
+
+	checkedAt := time.Now()
+	if err := s.dataQualityRepo.RecordResult(ctx, rule.TenantID, rule.ID, checkedAt, count, samples); err != nil {
+		return 0, err
+	}
+
+	rule.LastCheckedAt = &checkedAt
+	rule.IssueCount = &count
+	rule.Samples = samples
+
+	return count, nil
+}
+
+// notifyStewards queues the alert emails of a tenant's data stewards in one
+// transaction. A steward in several notified roles gets one email listing
+// all their alerts; only active users are notified.
+func (s *DataQualityService) notifyStewards(ctx context.Context, tenantID uuid.UUID, alertsByRole map[uuid.UUID][]models.DataQualityAlert) error {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	stewards := make(map[uuid.UUID]models.User)
+	alertsByUser := make(map[uuid.UUID][]models.DataQualityAlert)
+	for roleID, alerts := range alertsByRole {
+		users, err := s.userRoleRepo.GetUsersByRole(ctx, tenantID, roleID)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if !user.IsActive() {
+				continue
+			}
+			stewards[user.ID] = user
+			alertsByUser[user.ID] = append(alertsByUser[user.ID], alerts...)
+		}
+	}
+
+	if len(stewards) == 0 {
+		log.Printf("⚠️  Data quality checks of tenant %s found new issues but no one to notify", tenantID)
+		return nil
+	}
+
+	reportURL := s.config.App.FrontendURL + "/settings/data-quality"
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for userID, user := range stewards {
+		msg, err := s.emailService.DataQualityAlertEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, alertsByUser[userID], reportURL)
+		if err != nil {
+			return err
+		}
+		if err := s.emailQueueService.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to queue data quality alerts: %w", err)
+	}
+
+	return nil
+}
+
+// applyRuleRequest validates a request's severity and data stewards and
+// applies them to rule
+func (s *DataQualityService) applyRuleRequest(ctx context.Context, rule *models.DataQualityRule, req *models.DataQualityRuleRequest) error {
+	severity := req.Severity
+	if severity == "" {
+		severity = models.DataQualitySeverityWarning
+	}
+	valid := false
+	for _, allowed := range models.DataQualitySeverities {
+		if severity == allowed {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid severity: %s", severity)
+	}
+
+	if req.NotifyRoleID != nil {
+		if _, err := s.roleRepo.FindByID(ctx, rule.TenantID, *req.NotifyRoleID); err != nil {
+			return err
+		}
+	}
+
+	rule.Severity = severity
+	rule.NotifyRoleID = req.NotifyRoleID
+	if req.IsEnabled != nil {
+		rule.IsEnabled = *req.IsEnabled
+	}
+
+	return nil
+}
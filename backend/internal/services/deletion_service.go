@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Deletion audit actions
+const (
+	deletionAuditScheduled = "deletion.scheduled"
+	deletionAuditUndone    = "deletion.undone"
+	deletionAuditExecuted  = "deletion.executed"
+	deletionAuditFailed    = "deletion.failed"
+)
+
+const deletionBatchSize = 50 // Max deletions executed per database per worker run
+
+// DeletionExecutor carries out a staged delete. userID is the user who
+// requested the deletion.
+type DeletionExecutor func(ctx context.Context, tenantID, userID, entityID uuid.UUID) error
+
+// DeletionHandler reacts to a staged deletion once it has been executed
+type DeletionHandler func(ctx context.Context, deletion *models.PendingDeletion) error
+
+// deletionSubscription is a named handler of executed deletions
+type deletionSubscription struct {
+	name    string
+	handler DeletionHandler
+}
+
+// deletionTarget is an entity type that is deleted through the undo window
+type deletionTarget struct {
+	resource string // Permission resource whose delete permission allows undoing
+	execute  DeletionExecutor
+}
+
+// DeletionService stages deletes for an undo window. The requester is emailed
+// an undo link, and the deletion worker (ProcessDue) executes the delete once
+// the window has passed.
+type DeletionService struct {
+	db                *sqlx.DB
+	deletionRepo      repository.DeletionStore
+	userRepo          repository.UserStore
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	permissionService PermissionManager
+	auditService      AuditManager
+	config            *config.DeletionConfig
+	targets           map[string]deletionTarget
+	subscriptions     []deletionSubscription
+}
+
+// NewDeletionService creates a new deletion service
+func NewDeletionService(
+	db *sqlx.DB,
+	deletionRepo repository.DeletionStore,
+	userRepo repository.UserStore,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	permissionService PermissionManager,
+	auditService AuditManager,
+	cfg *config.DeletionConfig,
+) *DeletionService {
+	return &DeletionService{
+		db:                db,
+		deletionRepo:      deletionRepo,
+		userRepo:          userRepo,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		permissionService: permissionService,
+		auditService:      auditService,
+		config:            cfg,
+		targets:           make(map[string]deletionTarget),
+	}
+}
+
+// RegisterTarget registers how an entity type is deleted once its undo window
+// has passed. Users holding the delete permission on resource may undo any
+// deletion of that type; the requester can always undo their own.
+func (s *DeletionService) RegisterTarget(entityType, resource string, execute DeletionExecutor) {
+	s.targets[entityType] = deletionTarget{resource: resource, execute: execute}
+}
+
+// Subscribe registers a handler run after each staged deletion is executed,
+// for subsystems that should only react once the undo window has passed.
+// Subscriptions are registered during setup, before the worker runs.
+func (s *DeletionService) Subscribe(name string, handler DeletionHandler) {
+	s.subscriptions = append(s.subscriptions, deletionSubscription{name: name, handler: handler})
+}
+
+// Schedule stages the deletion of an entity and emails the requester an undo
+// link. label is the human-readable name used in the notification.
+func (s *DeletionService) Schedule(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID, label string) (*models.PendingDeletion, error) {
+	if _, ok := s.targets[entityType]; !ok {
+		return nil, utils.NewBadRequestError("UNSUPPORTED_DELETION_TYPE", fmt.Sprintf("unsupported deletion type: %s", entityType))
+	}
+
+	requester, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	deletion := &models.PendingDeletion{
+		TenantID:    tenantID,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Label:       label,
+		ExecuteAt:   time.Now().Add(s.config.UndoWindow),
+		RequestedBy: userID,
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.deletionRepo.Create(ctx, tx.Tx, deletion); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.emailService.DeletionScheduledEmail(requester.Email, requester.Language, requester.FirstName, label, deletion.ID, deletion.ExecuteAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emailQueueService.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, deletionAuditScheduled, s.targets[entityType].resource, entityID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"deletion_id": deletion.ID,
+		"entity_type": entityType,
+		"label":       label,
+		"execute_at":  deletion.ExecuteAt,
+	})
+
+	return deletion, nil
+}
+
+// ListDeletions lists the deletions a user staged
+func (s *DeletionService) ListDeletions(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error) {
+	return s.deletionRepo.ListByRequester(ctx, tenantID, userID, status, limit, offset)
+}
+
+// GetDeletion retrieves a staged deletion the user may manage
+func (s *DeletionService) GetDeletion(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error) {
+	deletion, err := s.deletionRepo.FindByID(ctx, tenantID, deletionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAccess(ctx, tenantID, userID, deletion); err != nil {
+		return nil, err
+	}
+
+	return deletion, nil
+}
+
+// Undo cancels a pending deletion before its undo window has passed
+func (s *DeletionService) Undo(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error) {
+	deletion, err := s.GetDeletion(ctx, tenantID, userID, deletionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletion.Status != models.DeletionStatusPending {
+		return nil, utils.NewConflictError("DELETION_NOT_UNDOABLE", "deletion can no longer be undone")
+	}
+
+	target := s.targets[deletion.EntityType]
+	if err := s.deletionRepo.MarkUndone(ctx, tenantID, deletionID, userID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, deletionAuditUndone, target.resource, deletion.EntityID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"deletion_id": deletion.ID,
+		"entity_type": deletion.EntityType,
+		"label":       deletion.Label,
+	})
+
+	return s.deletionRepo.FindByID(ctx, tenantID, deletionID)
+}
+
+// checkAccess allows the requester and users holding the delete permission on
+// the deleted entity's resource
+func (s *DeletionService) checkAccess(ctx context.Context, tenantID, userID uuid.UUID, deletion *models.PendingDeletion) error {
+	if deletion.RequestedBy == userID {
+		return nil
+	}
+
+	target, ok := s.targets[deletion.EntityType]
+	if !ok {
+		return utils.NewForbiddenError("DELETION_FORBIDDEN", "not allowed to manage this deletion")
+	}
+
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, target.resource, models.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return utils.NewForbiddenError("DELETION_FORBIDDEN", "not allowed to manage this deletion")
+	}
+
+	return nil
+}
+
+// ProcessDue executes deletions whose undo window has passed, in every data
+// region. It is run periodically by the background job runner and returns the
+// number of deletions processed.
+func (s *DeletionService) ProcessDue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		for i := 0; i < deletionBatchSize; i++ {
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+
+			processed, err := s.executeNext(ctx, db)
+			if err != nil {
+				return total, err
+			}
+			if !processed {
+				break
+			}
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// executeNext claims one due deletion and executes it. The row stays locked
+// while executing, so an undo racing with the worker waits and then fails.
+func (s *DeletionService) executeNext(ctx context.Context, db *sqlx.DB) (bool, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	deletion, err := s.deletionRepo.ClaimDue(ctx, tx, jobs.PausedTenants(ctx))
+	if err != nil || deletion == nil {
+		return false, err
+	}
+
+	target, ok := s.targets[deletion.EntityType]
+	execErr := utils.NewBadRequestError("UNSUPPORTED_DELETION_TYPE", fmt.Sprintf("unsupported deletion type: %s", deletion.EntityType))
+	if ok {
+		execErr = target.execute(ctx, deletion.TenantID, deletion.RequestedBy, deletion.EntityID)
+	}
+
+	// Once the delete ran its outcome must be recorded, even if the worker is
+	// shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	action := deletionAuditExecuted
+	status := models.AuditStatusSuccess
+	metadata := map[string]interface{}{
+		"deletion_id": deletion.ID,
+		"entity_type": deletion.EntityType,
+		"label":       deletion.Label,
+	}
+	if execErr != nil {
+		log.Printf("⚠️  Deletion %s of %s %s failed: %v", deletion.ID, deletion.EntityType, deletion.EntityID, execErr)
+		action, status = deletionAuditFailed, models.AuditStatusFailure
+		metadata["error"] = execErr.Error()
+		if err := s.deletionRepo.MarkFailed(ctx, tx, deletion, execErr.Error()); err != nil {
+			return false, err
+		}
+	} else if err := s.deletionRepo.MarkExecuted(ctx, tx, deletion); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record deletion: %w", err)
+	}
+
+	s.auditService.LogEvent(ctx, deletion.TenantID, deletion.RequestedBy, action, target.resource, deletion.EntityID, status, "", "", metadata)
+
+	// A failing subscriber is logged and does not fail the deletion, which
+	// is already committed
+	if execErr == nil {
+		for _, sub := range s.subscriptions {
+			if err := sub.handler(ctx, deletion); err != nil {
+				log.Printf("⚠️  Deletion subscriber %s failed on %s %s: %v", sub.name, deletion.EntityType, deletion.EntityID, err)
+			}
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/tracing"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	emailQueueAuditRetried = "email.retried"
+)
+
+const (
+	emailRetryBaseDelay = 30 * time.Second
+	emailRetryMaxDelay  = 1 * time.Hour
+	emailBatchSize      = 50 // Max emails delivered per database per worker run
+)
+
+// EmailQueueService queues outgoing emails in the transactional outbox and
+// delivers them with retry
+type EmailQueueService struct {
+	db           *sqlx.DB
+	outboxRepo   repository.EmailOutboxStore
+	userRepo     repository.UserStore
+	emailService EmailManager
+	auditService AuditManager
+	metering     MeteringManager
+	jobsConfig   *config.JobsConfig
+	queueConfig  *config.QueueConfig
+}
+
+// NewEmailQueueService creates a new email queue service
+func NewEmailQueueService(
+	db *sqlx.DB,
+	outboxRepo repository.EmailOutboxStore,
+	userRepo repository.UserStore,
+	emailService EmailManager,
+	auditService AuditManager,
+	metering MeteringManager,
+	jobsConfig *config.JobsConfig,
+	queueConfig *config.QueueConfig,
+) *EmailQueueService {
+	return &EmailQueueService{
+		db:           db,
+		outboxRepo:   outboxRepo,
+		userRepo:     userRepo,
+		emailService: emailService,
+		auditService: auditService,
+		metering:     metering,
+		jobsConfig:   jobsConfig,
+		queueConfig:  queueConfig,
+	}
+}
+
+// Enqueue queues an email inside the caller's transaction; it is delivered
+// only if that transaction commits. Fails with an EMAIL_QUEUE_FULL error once the
+// tenant has QUEUE_EMAIL_MAX_PENDING emails waiting.
+func (s *EmailQueueService) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage) error {
+	_, err := s.outboxRepo.Enqueue(ctx, tx, tenantID, msg, s.queueConfig.EmailMaxPending)
+	if errors.Is(err, utils.ErrTooManyRequests) {
+		metrics.ObserveQueueRejected(models.QueueEmail, 1)
+	}
+	return err
+}
+
+// EnqueueStandalone queues an email in its own transaction, for callers whose
+// triggering change is not transactional
+func (s *EmailQueueService) EnqueueStandalone(ctx context.Context, tenantID uuid.UUID, msg *models.EmailMessage) error {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ProcessQueue delivers due emails in every data region with
+// JOBS_EMAIL_CONCURRENCY workers. It is run periodically by the background
+// job runner and returns the number of delivery attempts made.
+func (s *EmailQueueService) ProcessQueue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		attempted, err := drainQueue(ctx, s.jobsConfig.EmailConcurrency, emailBatchSize, func(ctx context.Context) (bool, error) {
+			return s.deliverNext(ctx, db)
+		})
+		total += attempted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// deliverNext claims one due email, sends it and records the outcome. The row
+// stays locked while sending, so concurrent workers never send it twice.
+func (s *EmailQueueService) deliverNext(ctx context.Context, db *sqlx.DB) (bool, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	email, err := s.outboxRepo.ClaimDue(ctx, tx)
+	if err != nil || email == nil {
+		return false, err
+	}
+
+	// Once the email went out its outcome must be recorded, even if the
+	// worker is shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	// The delivery continues the trace of the request that queued the email
+	ctx, span := tracing.StartFromStored(ctx, "email.deliver", email.TraceContext,
+		attribute.String("tenant.id", email.TenantID.String()),
+		attribute.String("email.id", email.ID.String()),
+		attribute.Int("email.attempt", email.Attempts+1),
+	)
+	var sendErr error
+	defer func() { tracing.End(span, sendErr) }()
+
+	// The provider reported the address as undeliverable; sending again
+	// would only hurt the sender's reputation
+	undeliverable, err := s.outboxRepo.RecipientUndeliverable(ctx, tx, email)
+	if err != nil {
+		return false, err
+	}
+
+	sent := false
+	if undeliverable {
+		if err := s.outboxRepo.MarkFailed(ctx, tx, email, "recipient address is undeliverable"); err != nil {
+			return false, err
+		}
+	} else if sendErr = s.emailService.SendEmail(email.ToEmail, email.Subject, email.Body, stringValue(email.TextBody)); sendErr != nil {
+		nextAttemptAt := time.Now().Add(emailRetryDelay(email.Attempts + 1))
+		if err := s.outboxRepo.MarkAttemptFailed(ctx, tx, email, sendErr.Error(), nextAttemptAt); err != nil {
+			return false, err
+		}
+		if email.Attempts+1 >= email.MaxAttempts {
+			metrics.ObserveDeadLetter(models.QueueEmail)
+			log.Printf("⚠️  Email %s to %s failed permanently after %d attempts: %v", email.ID, email.ToEmail, email.Attempts+1, sendErr)
+		}
+	} else if err := s.outboxRepo.MarkSent(ctx, tx, email); err != nil {
+		return false, err
+	} else {
+		sent = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record email delivery: %w", err)
+	}
+
+	if sent {
+		s.metering.RecordEmailSent(ctx, email.TenantID)
+	}
+
+	return true, nil
+}
+
+// HandleDeliveryEvents marks the addresses of bounces and complaints reported
+// by the email provider as undeliverable, for every user with them
+func (s *EmailQueueService) HandleDeliveryEvents(ctx context.Context, events []mail.Event) error {
+	for _, event := range events {
+		if event.Email == "" {
+			continue
+		}
+
+		users, err := s.userRepo.MarkEmailUndeliverable(ctx, event.Email, event.Reason)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			s.auditService.LogEvent(ctx, user.TenantID, uuid.Nil, models.ActionUserEmailUndeliverable, models.ResourceUsers, user.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+				"event":  event.Type,
+				"reason": event.Reason,
+			})
+		}
+	}
+
+	return nil
+}
+
+// emailRetryDelay returns the exponential backoff before the given attempt
+// (30s, 1m, 2m, 4m, ... capped at 1h)
+func emailRetryDelay(attempt int) time.Duration {
+	delay := emailRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= emailRetryMaxDelay {
+			return emailRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// ListEmails lists a tenant's queued emails
+func (s *EmailQueueService) ListEmails(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error) {
+	return s.outboxRepo.List(ctx, tenantID, status, limit, offset)
+}
+
+// GetStats counts a tenant's queued emails per status
+func (s *EmailQueueService) GetStats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error) {
+	return s.outboxRepo.Stats(ctx, tenantID)
+}
+
+// RetryEmail requeues a permanently failed email
+func (s *EmailQueueService) RetryEmail(ctx context.Context, tenantID, userID, emailID uuid.UUID) error {
+	if err := s.outboxRepo.Retry(ctx, tenantID, emailID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, emailQueueAuditRetried, models.ResourceSettings, emailID, models.AuditStatusSuccess, "", "", nil)
+
+	return nil
+}
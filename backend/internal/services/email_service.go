@@ -1,361 +1,474 @@
 package services
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"html/template"
-	"net/smtp"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"myerp-v2/internal/config"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/models"
 )
 
-// EmailService handles sending emails
+// EmailService builds emails and hands them to the configured mailer
 type EmailService struct {
+	mailer mail.Mailer
 	config *config.EmailConfig
 	app    *config.AppConfig
 }
 
 // NewEmailService creates a new email service
-func NewEmailService(emailConfig *config.EmailConfig, appConfig *config.AppConfig) *EmailService {
+func NewEmailService(mailer mail.Mailer, emailConfig *config.EmailConfig, appConfig *config.AppConfig) *EmailService {
 	return &EmailService{
+		mailer: mailer,
 		config: emailConfig,
 		app:    appConfig,
 	}
 }
 
-// SendEmail sends a plain text email
-func (s *EmailService) SendEmail(to, subject, body string) error {
-	from := s.config.FromEmail
-
-	// Compose message
-	message := []byte(fmt.Sprintf(
-		"From: %s <%s>\r\n"+
-			"To: %s\r\n"+
-			"Subject: %s\r\n"+
-			"Content-Type: text/html; charset=UTF-8\r\n"+
-			"\r\n"+
-			"%s\r\n",
-		s.config.FromName, from, to, subject, body,
-	))
-
-	// Connect to SMTP server
-	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
-
-	// For local development (Mailpit), no authentication is needed
-	if s.config.SMTPUser == "" && s.config.SMTPPassword == "" {
-		client, err := smtp.Dial(addr)
-		if err != nil {
-			return fmt.Errorf("failed to connect to SMTP server: %w", err)
-		}
-		defer client.Close()
-
-		if err := client.Mail(from); err != nil {
-			return fmt.Errorf("failed to set sender: %w", err)
-		}
-
-		if err := client.Rcpt(to); err != nil {
-			return fmt.Errorf("failed to set recipient: %w", err)
-		}
+// SendEmail sends an HTML email with its plaintext alternative immediately
+// through the mailer. An empty text sends the HTML part alone.
+// Application code should enqueue messages with EmailQueueService instead, so
+// failures are retried; this is what the outbox worker calls.
+func (s *EmailService) SendEmail(to, subject, body, text string) error {
+	return s.mailer.Send(context.Background(), &mail.Message{
+		To:      to,
+		Subject: subject,
+		HTML:    body,
+		Text:    text,
+	})
+}
 
-		w, err := client.Data()
-		if err != nil {
-			return fmt.Errorf("failed to get data writer: %w", err)
+// SendMessage sends a rendered email with its attachments immediately through
+// the mailer. The outbox does not keep attachments, so emails with
+// attachments are sent this way and their failures are not retried.
+func (s *EmailService) SendMessage(ctx context.Context, msg *models.EmailMessage) error {
+	attachments := make([]mail.Attachment, len(msg.Attachments))
+	for i, a := range msg.Attachments {
+		attachments[i] = mail.Attachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Content:     a.Content,
 		}
+	}
 
-		_, err = w.Write(message)
-		if err != nil {
-			return fmt.Errorf("failed to write message: %w", err)
-		}
+	return s.mailer.Send(ctx, &mail.Message{
+		To:          msg.To,
+		Subject:     msg.Subject,
+		HTML:        msg.Body,
+		Text:        msg.Text,
+		Attachments: attachments,
+	})
+}
 
-		err = w.Close()
-		if err != nil {
-			return fmt.Errorf("failed to close data writer: %w", err)
-		}
+// defaultEmailColor is the primary and accent color of unbranded emails
+const defaultEmailColor = "#4F46E5"
+
+// emailBrand is how a tenant's branding appears in its emails. An empty
+// Footer is the localized copyright notice.
+type emailBrand struct {
+	Name         string
+	LogoURL      string
+	PrimaryColor string
+	AccentColor  string
+	Footer       string
+}
 
-		return client.Quit()
+// brand resolves a tenant's branding for its emails; whatever the tenant did
+// not set falls back to the app's name and colors. A nil branding is the
+// app's own.
+func (s *EmailService) brand(branding *models.TenantBranding) emailBrand {
+	brand := emailBrand{
+		Name:         s.app.Name,
+		PrimaryColor: defaultEmailColor,
+		AccentColor:  defaultEmailColor,
+	}
+	if branding == nil {
+		return brand
 	}
 
-	// For production with authentication
-	auth := smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, s.config.SMTPHost)
-	err := smtp.SendMail(addr, auth, from, []string{to}, message)
-	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+	if branding.Name != nil {
+		brand.Name = *branding.Name
+	}
+	if branding.LogoURL != nil {
+		brand.LogoURL = *branding.LogoURL
+	}
+	if branding.PrimaryColor != nil {
+		brand.PrimaryColor = *branding.PrimaryColor
+		brand.AccentColor = *branding.PrimaryColor
 	}
+	if branding.AccentColor != nil {
+		brand.AccentColor = *branding.AccentColor
+	}
+	if branding.Footer != nil {
+		brand.Footer = *branding.Footer
+	}
+	return brand
+}
 
-	return nil
-}
-
-// SendTenantVerificationEmail sends a verification email for tenant registration
-func (s *EmailService) SendTenantVerificationEmail(email, companyName string, token uuid.UUID) error {
-	verifyURL := fmt.Sprintf("%s/auth/verify?token=%s", s.app.FrontendURL, token)
-
-	tmpl := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 30px 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 30px; background-color: #4F46E5; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>{{.AppName}}</h1>
-        </div>
-        <div class="content">
-            <h2>Welcome to {{.AppName}}!</h2>
-            <p>Hi {{.CompanyName}},</p>
-            <p>Thank you for registering with {{.AppName}}. To complete your registration and activate your account, please verify your email address by clicking the button below:</p>
-            <p style="text-align: center;">
-                <a href="{{.VerifyURL}}" class="button">Verify Email Address</a>
-            </p>
-            <p>Or copy and paste this link into your browser:</p>
-            <p style="word-break: break-all; color: #4F46E5;">{{.VerifyURL}}</p>
-            <p><strong>This link will expire in 24 hours.</strong></p>
-            <p>If you didn't create an account with {{.AppName}}, you can safely ignore this email.</p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2026 {{.AppName}}. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>
-`
-
-	data := map[string]string{
-		"AppName":     s.app.Name,
+// TenantVerificationEmail builds the verification email for tenant
+// registration, in the tenant's branding
+func (s *EmailService) TenantVerificationEmail(email, lang, companyName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateTenantVerification, lang, branding, map[string]interface{}{
 		"CompanyName": companyName,
-		"VerifyURL":   verifyURL,
-	}
+		"VerifyURL":   fmt.Sprintf("%s/auth/verify?token=%s", s.app.FrontendURL, token),
+	})
+}
 
-	body, err := s.renderTemplate(tmpl, data)
-	if err != nil {
-		return err
-	}
+// PasswordResetEmail builds a password reset email in the tenant's branding
+func (s *EmailService) PasswordResetEmail(email, lang, firstName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplatePasswordReset, lang, branding, map[string]interface{}{
+		"FirstName": firstName,
+		"ResetURL":  fmt.Sprintf("%s/reset-password?token=%s", s.app.FrontendURL, token),
+	})
+}
+
+// InvitationEmail builds a team invitation email in the tenant's branding
+func (s *EmailService) InvitationEmail(email, lang, companyName, inviterName string, branding *models.TenantBranding, token uuid.UUID, message string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateInvitation, lang, branding, map[string]interface{}{
+		"CompanyName": companyName,
+		"InviterName": inviterName,
+		"Message":     message,
+		"AcceptURL":   fmt.Sprintf("%s/accept-invitation?token=%s", s.app.FrontendURL, token),
+	})
+}
+
+// WelcomeEmail builds a welcome email sent after an invitation is accepted
+func (s *EmailService) WelcomeEmail(email, lang, firstName string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateWelcome, lang, nil, map[string]interface{}{
+		"FirstName":    firstName,
+		"DashboardURL": fmt.Sprintf("%s/dashboard", s.app.FrontendURL),
+	})
+}
+
+// AccountSetupEmail builds the email sent to a user whose account was created
+// for them (e.g. by a bulk import), with a link to choose their password
+func (s *EmailService) AccountSetupEmail(email, lang, firstName, companyName, creatorName string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateAccountSetup, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"CreatorName": creatorName,
+		"SetupURL":    fmt.Sprintf("%s/reset-password?token=%s", s.app.FrontendURL, token),
+		"ExpiresAt":   expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
 
-	subject := fmt.Sprintf("Verify your %s account", s.app.Name)
-	return s.SendEmail(email, subject, body)
-}
-
-// SendPasswordResetEmail sends a password reset email
-func (s *EmailService) SendPasswordResetEmail(email, firstName string, token uuid.UUID) error {
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.app.FrontendURL, token)
-
-	tmpl := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 30px 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 30px; background-color: #4F46E5; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
-        .warning { background-color: #FEF3C7; padding: 15px; border-left: 4px solid #F59E0B; margin: 20px 0; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>{{.AppName}}</h1>
-        </div>
-        <div class="content">
-            <h2>Password Reset Request</h2>
-            <p>Hi {{.FirstName}},</p>
-            <p>We received a request to reset your password. Click the button below to create a new password:</p>
-            <p style="text-align: center;">
-                <a href="{{.ResetURL}}" class="button">Reset Password</a>
-            </p>
-            <p>Or copy and paste this link into your browser:</p>
-            <p style="word-break: break-all; color: #4F46E5;">{{.ResetURL}}</p>
-            <p><strong>This link will expire in 1 hour.</strong></p>
-            <div class="warning">
-                <strong>Security Notice:</strong> If you didn't request a password reset, please ignore this email. Your password will remain unchanged.
-            </div>
-        </div>
-        <div class="footer">
-            <p>&copy; 2026 {{.AppName}}. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>
-`
-
-	data := map[string]string{
-		"AppName":   s.app.Name,
+// EmailChangeEmail builds the email sent to the address a user asked to
+// change their email to, with a link confirming it
+func (s *EmailService) EmailChangeEmail(email, lang, firstName, currentEmail string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateEmailChange, lang, nil, map[string]interface{}{
+		"FirstName":    firstName,
+		"CurrentEmail": currentEmail,
+		"ConfirmURL":   fmt.Sprintf("%s/confirm-email?token=%s", s.app.FrontendURL, token),
+		"ExpiresAt":    expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// TwoFactorBypassEmail builds the email with a one-time code that signs a user
+// in without their second factor, sent when they lost their authenticator.
+// issuedBy names the administrator who sent it; empty when the user asked.
+func (s *EmailService) TwoFactorBypassEmail(email, lang, firstName, code, issuedBy string, expiresAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateTwoFactorBypass, lang, nil, map[string]interface{}{
 		"FirstName": firstName,
-		"ResetURL":  resetURL,
-	}
+		"Code":      code,
+		"IssuedBy":  issuedBy,
+		"ExpiresAt": expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
 
-	body, err := s.renderTemplate(tmpl, data)
-	if err != nil {
-		return err
-	}
+// TwoFactorSetupReminderEmail builds the reminder sent to users who must set
+// up 2FA before their tenant's grace period ends
+func (s *EmailService) TwoFactorSetupReminderEmail(email, lang, firstName, companyName string, deadline time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateTwoFactorSetupReminder, lang, nil, map[string]interface{}{
+		"FirstName":   firstName,
+		"CompanyName": companyName,
+		"SetupURL":    fmt.Sprintf("%s/settings/security", s.app.FrontendURL),
+		"Deadline":    deadline.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
 
-	subject := fmt.Sprintf("Reset your %s password", s.app.Name)
-	return s.SendEmail(email, subject, body)
+// DeletionScheduledEmail builds the notification sent when a delete is staged,
+// with a link to undo it before it becomes permanent
+func (s *EmailService) DeletionScheduledEmail(email, lang, firstName, label string, deletionID uuid.UUID, executeAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateDeletionScheduled, lang, nil, map[string]interface{}{
+		"FirstName": firstName,
+		"Label":     label,
+		"UndoURL":   fmt.Sprintf("%s/undo-deletion?id=%s", s.app.FrontendURL, deletionID),
+		"ExecuteAt": executeAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
 }
 
-// SendInvitationEmail sends a team invitation email
-func (s *EmailService) SendInvitationEmail(email, companyName, inviterName string, token uuid.UUID, message string) error {
-	acceptURL := fmt.Sprintf("%s/accept-invitation?token=%s", s.app.FrontendURL, token)
+// TenantDeletionConfirmEmail builds the email sent to an owner who asked to
+// delete their tenant, with a link confirming the deletion
+func (s *EmailService) TenantDeletionConfirmEmail(email, lang, firstName, companyName string, branding *models.TenantBranding, token uuid.UUID, expiresAt time.Time, gracePeriod time.Duration) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateTenantDeletionConfirm, lang, branding, map[string]interface{}{
+		"FirstName":   firstName,
+		"CompanyName": companyName,
+		"ConfirmURL":  fmt.Sprintf("%s/confirm-tenant-deletion?token=%s", s.app.FrontendURL, token),
+		"ExpiresAt":   expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+		"GraceDays":   int(gracePeriod.Hours() / 24),
+	})
+}
 
-	customMessage := ""
-	if message != "" {
-		customMessage = fmt.Sprintf(`<div style="background-color: #EFF6FF; padding: 15px; border-left: 4px solid #3B82F6; margin: 20px 0;">
-            <p><strong>Message from %s:</strong></p>
-            <p>%s</p>
-        </div>`, inviterName, template.HTMLEscapeString(message))
-	}
+// TenantDeletionScheduledEmail builds the notification sent to the owners of
+// a tenant whose deletion was confirmed, with a link to cancel it
+func (s *EmailService) TenantDeletionScheduledEmail(email, lang, firstName, companyName string, branding *models.TenantBranding, deleteAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateTenantDeletionScheduled, lang, branding, map[string]interface{}{
+		"FirstName":   firstName,
+		"CompanyName": companyName,
+		"CancelURL":   fmt.Sprintf("%s/settings/account/deletion", s.app.FrontendURL),
+		"DeleteAt":    deleteAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
 
-	tmpl := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 30px 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 30px; background-color: #4F46E5; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>{{.AppName}}</h1>
-        </div>
-        <div class="content">
-            <h2>You've been invited to join {{.CompanyName}}</h2>
-            <p>Hi there,</p>
-            <p>{{.InviterName}} has invited you to join their team on {{.AppName}}.</p>
-            {{.CustomMessage}}
-            <p>Click the button below to accept the invitation and create your account:</p>
-            <p style="text-align: center;">
-                <a href="{{.AcceptURL}}" class="button">Accept Invitation</a>
-            </p>
-            <p>Or copy and paste this link into your browser:</p>
-            <p style="word-break: break-all; color: #4F46E5;">{{.AcceptURL}}</p>
-            <p><strong>This invitation will expire in 7 days.</strong></p>
-            <p>If you don't want to join this team, you can safely ignore this email.</p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2026 {{.AppName}}. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>
-`
-
-	data := map[string]interface{}{
-		"AppName":       s.app.Name,
-		"CompanyName":   companyName,
-		"InviterName":   inviterName,
-		"AcceptURL":     acceptURL,
-		"CustomMessage": template.HTML(customMessage),
-	}
+// BroadcastEmail wraps an already rendered broadcast body in the standard
+// layout, with a link to unsubscribe from further broadcasts
+func (s *EmailService) BroadcastEmail(email, lang, companyName, subject string, content template.HTML, unsubscribeURL string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateBroadcast, lang, nil, map[string]interface{}{
+		"CompanyName":    companyName,
+		"Subject":        subject,
+		"Content":        content,
+		"ContentText":    htmlToText(string(content)),
+		"UnsubscribeURL": unsubscribeURL,
+	})
+}
 
-	body, err := s.renderTemplateInterface(tmpl, data)
-	if err != nil {
-		return err
-	}
+// AutomationEmail wraps an already rendered automation notification body in
+// the standard layout
+func (s *EmailService) AutomationEmail(email, lang, companyName, subject string, content template.HTML) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateAutomation, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"Subject":     subject,
+		"Content":     content,
+		"ContentText": htmlToText(string(content)),
+	})
+}
+
+// BroadcastUnsubscribeURL builds the link a broadcast recipient follows to opt
+// out of further broadcasts
+func (s *EmailService) BroadcastUnsubscribeURL(tenantSlug string, token uuid.UUID) string {
+	return fmt.Sprintf("%s/unsubscribe?tenant=%s&token=%s", s.app.FrontendURL, url.QueryEscape(tenantSlug), token)
+}
 
-	subject := fmt.Sprintf("You've been invited to join %s on %s", companyName, s.app.Name)
-	return s.SendEmail(email, subject, body)
-}
-
-// SendWelcomeEmail sends a welcome email after successful registration
-func (s *EmailService) SendWelcomeEmail(email, firstName string) error {
-	dashboardURL := fmt.Sprintf("%s/dashboard", s.app.FrontendURL)
-
-	tmpl := `
-<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="UTF-8">
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #4F46E5; color: white; padding: 20px; text-align: center; }
-        .content { padding: 30px 20px; background-color: #f9f9f9; }
-        .button { display: inline-block; padding: 12px 30px; background-color: #4F46E5; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>Welcome to {{.AppName}}!</h1>
-        </div>
-        <div class="content">
-            <h2>Your account is ready</h2>
-            <p>Hi {{.FirstName}},</p>
-            <p>Your {{.AppName}} account has been successfully activated! You're all set to start using our platform.</p>
-            <p style="text-align: center;">
-                <a href="{{.DashboardURL}}" class="button">Go to Dashboard</a>
-            </p>
-            <p>If you have any questions or need assistance, feel free to reach out to our support team.</p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2026 {{.AppName}}. All rights reserved.</p>
-        </div>
-    </div>
-</body>
-</html>
-`
-
-	data := map[string]string{
-		"AppName":      s.app.Name,
+// ApprovalRequestEmail builds the email asking an approver for a decision,
+// with one-time Approve and Reject links. stepUp tells the approver their
+// authenticator code will be asked for.
+func (s *EmailService) ApprovalRequestEmail(email, lang, firstName, companyName, title, details, approveURL, rejectURL, reviewURL string, stepUp bool, expiresAt time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateApprovalRequest, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"Title":       title,
+		"Details":     details,
+		"ApproveURL":  approveURL,
+		"RejectURL":   rejectURL,
+		"ReviewURL":   reviewURL,
+		"StepUp":      stepUp,
+		"ExpiresAt":   expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// ApprovalLinkURL builds the page an approval link opens. The page shows what
+// is being decided and submits the decision, so mail scanners following the
+// link do not use it.
+func (s *EmailService) ApprovalLinkURL(tenantSlug, token, decision string) string {
+	return fmt.Sprintf("%s/approval-link?tenant=%s&token=%s&decision=%s", s.app.FrontendURL, url.QueryEscape(tenantSlug), url.QueryEscape(token), decision)
+}
+
+// EscalationEmail tells someone further up that an approval has been waiting
+// too long
+func (s *EmailService) EscalationEmail(email, lang, firstName, companyName, title, details, reviewURL string, pendingSince time.Time) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateEscalation, lang, nil, map[string]interface{}{
+		"CompanyName":  companyName,
 		"FirstName":    firstName,
-		"DashboardURL": dashboardURL,
+		"Title":        title,
+		"Details":      details,
+		"ReviewURL":    reviewURL,
+		"PendingSince": pendingSince.UTC().Format("2006-01-02 15:04 MST"),
+	})
+}
+
+// DataQualityAlertEmail tells a data steward that data-quality checks found
+// new issues
+func (s *EmailService) DataQualityAlertEmail(email, lang, firstName, companyName string, alerts []models.DataQualityAlert, reportURL string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateDataQualityAlert, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"Alerts":      alerts,
+		"ReportURL":   reportURL,
+	})
+}
+
+// QuotaAlertEmail warns a tenant owner that usage crossed warning thresholds
+// of the plan's quotas
+func (s *EmailService) QuotaAlertEmail(email, lang, firstName, companyName string, alerts []models.QuotaAlert, quotasURL string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateQuotaAlert, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"Alerts":      quotaAlertRows(alerts),
+		"Highest":     highestQuotaThreshold(alerts),
+		"QuotasURL":   quotasURL,
+	})
+}
+
+// quotaAlertRows formats quota alerts for the quota alert email
+func quotaAlertRows(alerts []models.QuotaAlert) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(alerts))
+	for i, alert := range alerts {
+		rows[i] = map[string]interface{}{
+			"Metric":    alert.Metric,
+			"Threshold": alert.Threshold,
+			"Usage":     formatQuotaAmount(alert.Metric, alert.Usage),
+			"Limit":     formatQuotaAmount(alert.Metric, alert.Limit),
+		}
 	}
+	return rows
+}
 
-	body, err := s.renderTemplate(tmpl, data)
-	if err != nil {
-		return err
+// highestQuotaThreshold returns the highest threshold the alerts crossed
+func highestQuotaThreshold(alerts []models.QuotaAlert) int {
+	highest := 0
+	for _, alert := range alerts {
+		if alert.Threshold > highest {
+			highest = alert.Threshold
+		}
 	}
+	return highest
+}
 
-	subject := fmt.Sprintf("Welcome to %s!", s.app.Name)
-	return s.SendEmail(email, subject, body)
+// LeaveRequestEmail asks a manager or HR approver to decide on a leave request
+func (s *EmailService) LeaveRequestEmail(email, lang, firstName, companyName, employeeName, leaveType, period string, days float64, reason, reviewURL string) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateLeaveRequest, lang, nil, map[string]interface{}{
+		"CompanyName":  companyName,
+		"FirstName":    firstName,
+		"EmployeeName": employeeName,
+		"LeaveType":    leaveType,
+		"Period":       period,
+		"Days":         days,
+		"Reason":       reason,
+		"ReviewURL":    reviewURL,
+	})
 }
 
-// renderTemplate renders an HTML template with string data
-func (s *EmailService) renderTemplate(tmplStr string, data map[string]string) (string, error) {
-	tmpl, err := template.New("email").Parse(tmplStr)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+// LeaveDecisionEmail tells an employee that their leave request was approved
+// or rejected
+func (s *EmailService) LeaveDecisionEmail(email, lang, firstName, companyName, leaveType, period string, days float64, approved bool, deciderName, note, requestURL string) (*models.EmailMessage, error) {
+	decision := models.LeaveStatusRejected
+	if approved {
+		decision = models.LeaveStatusApproved
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	return s.renderEmail(email, models.EmailTemplateLeaveDecision, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"LeaveType":   leaveType,
+		"Period":      period,
+		"Days":        days,
+		"Decision":    decision,
+		"DeciderName": deciderName,
+		"Note":        note,
+		"RequestURL":  requestURL,
+	})
+}
+
+// NotificationEmail emails a notification of a category the user gets by
+// email right away
+func (s *EmailService) NotificationEmail(email, lang, firstName, companyName string, n *models.Notification) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateNotification, lang, nil, map[string]interface{}{
+		"CompanyName":    companyName,
+		"FirstName":      firstName,
+		"Title":          n.Title,
+		"Body":           n.Body,
+		"URL":            s.notificationURL(n),
+		"PreferencesURL": s.app.FrontendURL + "/settings/notifications",
+	})
+}
+
+// notificationDigestMaxItems is how many notifications a digest lists; the
+// rest are counted
+const notificationDigestMaxItems = 50
+
+// NotificationDigestEmail sums up a user's unread notifications, newest
+// first, in their daily or weekly digest
+func (s *EmailService) NotificationDigestEmail(email, lang, firstName, companyName, digest string, notifications []models.Notification) (*models.EmailMessage, error) {
+	return s.renderEmail(email, models.EmailTemplateNotificationDigest, lang, nil, map[string]interface{}{
+		"CompanyName":      companyName,
+		"FirstName":        firstName,
+		"Digest":           digest,
+		"Count":            len(notifications),
+		"Items":            s.notificationDigestItems(notifications),
+		"More":             len(notifications) - min(len(notifications), notificationDigestMaxItems),
+		"NotificationsURL": s.app.FrontendURL + "/notifications",
+		"PreferencesURL":   s.app.FrontendURL + "/settings/notifications",
+	})
+}
+
+// notificationDigestItems formats the notifications a digest lists
+func (s *EmailService) notificationDigestItems(notifications []models.Notification) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, notificationDigestMaxItems)
+	for i := range notifications {
+		if len(items) == notificationDigestMaxItems {
+			break
+		}
+		items = append(items, map[string]interface{}{
+			"Title":     notifications[i].Title,
+			"Body":      notifications[i].Body,
+			"URL":       s.notificationURL(&notifications[i]),
+			"CreatedAt": notifications[i].CreatedAt.UTC().Format("2006-01-02 15:04"),
+		})
 	}
+	return items
+}
 
-	return buf.String(), nil
+// notificationURL returns the frontend URL a notification links to, empty
+// when it has no link
+func (s *EmailService) notificationURL(n *models.Notification) string {
+	if n.Link == nil || *n.Link == "" {
+		return ""
+	}
+	return s.app.FrontendURL + *n.Link
 }
 
-// renderTemplateInterface renders an HTML template with interface{} data
-func (s *EmailService) renderTemplateInterface(tmplStr string, data map[string]interface{}) (string, error) {
-	tmpl, err := template.New("email").Parse(tmplStr)
+// ScheduledReportEmail sends a scheduled report to one of its recipients,
+// with the report attached. The period is in UTC.
+func (s *EmailService) ScheduledReportEmail(email, lang, firstName, companyName, name string, periodStart, periodEnd time.Time, attachment models.EmailAttachment) (*models.EmailMessage, error) {
+	msg, err := s.renderEmail(email, models.EmailTemplateScheduledReport, lang, nil, map[string]interface{}{
+		"CompanyName": companyName,
+		"FirstName":   firstName,
+		"Name":        name,
+		"PeriodStart": periodStart.UTC().Format("2006-01-02 15:04"),
+		"PeriodEnd":   periodEnd.UTC().Format("2006-01-02 15:04"),
+		"Format":      strings.ToUpper(strings.TrimPrefix(path.Ext(attachment.Filename), ".")),
+		"Filename":    attachment.Filename,
+		"ReportsURL":  s.app.FrontendURL + "/settings/reports",
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return nil, err
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	msg.Attachments = []models.EmailAttachment{attachment}
+	return msg, nil
+}
+
+// formatLeaveDays formats a number of leave days for people, e.g. "1 day",
+// "2.5 days"
+func formatLeaveDays(days float64) string {
+	if days == 1 {
+		return "1 day"
 	}
+	return strconv.FormatFloat(days, 'f', -1, 64) + " days"
+}
 
-	return buf.String(), nil
+// formatQuotaAmount formats a quota usage or limit for people, storage in
+// megabytes
+func formatQuotaAmount(metric string, amount int64) string {
+	if metric == models.QuotaMetricStorage {
+		return fmt.Sprintf("%.1f MB", float64(amount)/(1<<20))
+	}
+	return fmt.Sprint(amount)
 }
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// Email templates live in templates/email: every template has an HTML and a
+// plaintext file, each defining a "content" block rendered inside the shared
+// layout.html or layout.txt, and the plaintext file defines the "subject".
+// Texts come from the locale catalogs in templates/email/locales, looked up
+// with {{t "key" args...}}.
+//
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// defaultEmailLanguage is the language of emails to users whose language has
+// no catalog, and of texts missing from a catalog
+const defaultEmailLanguage = "en"
+
+// emailTemplate is an email template parsed with its layouts
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var (
+	emailTemplates = mustParseEmailTemplates()
+	emailCatalogs  = mustLoadEmailCatalogs()
+)
+
+// emailFuncs are the functions available to email templates, translating
+// into the given language
+func emailFuncs(lang string) map[string]interface{} {
+	translate := func(key string, args ...interface{}) string {
+		format, ok := emailCatalogs[lang][key]
+		if !ok {
+			format, ok = emailCatalogs[defaultEmailLanguage][key]
+		}
+		if !ok {
+			return key
+		}
+		if len(args) == 0 {
+			return format
+		}
+		return fmt.Sprintf(format, args...)
+	}
+
+	return map[string]interface{}{
+		"t": translate,
+		"days": func(days float64) string {
+			if days == 1 {
+				return translate("common.one_day")
+			}
+			return translate("common.days", strconv.FormatFloat(days, 'f', -1, 64))
+		},
+	}
+}
+
+// mustParseEmailTemplates parses every email template with its layouts
+func mustParseEmailTemplates() map[string]*emailTemplate {
+	funcs := emailFuncs(defaultEmailLanguage)
+	templates := make(map[string]*emailTemplate, len(models.EmailTemplates))
+	for _, name := range models.EmailTemplates {
+		htmlTmpl, err := htmltemplate.New("layout.html").Funcs(funcs).ParseFS(emailTemplateFS,
+			"templates/email/layout.html", "templates/email/"+name+".html")
+		if err != nil {
+			panic(fmt.Sprintf("failed to parse email template %s: %v", name, err))
+		}
+		textTmpl, err := texttemplate.New("layout.txt").Funcs(funcs).ParseFS(emailTemplateFS,
+			"templates/email/layout.txt", "templates/email/"+name+".txt")
+		if err != nil {
+			panic(fmt.Sprintf("failed to parse email template %s: %v", name, err))
+		}
+		templates[name] = &emailTemplate{html: htmlTmpl, text: textTmpl}
+	}
+	return templates
+}
+
+// mustLoadEmailCatalogs loads the texts of every language emails are
+// available in
+func mustLoadEmailCatalogs() map[string]map[string]string {
+	catalogs := make(map[string]map[string]string, len(models.TenantLanguages))
+	for _, lang := range models.TenantLanguages {
+		data, err := emailTemplateFS.ReadFile("templates/email/locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("failed to read email locale %s: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("failed to parse email locale %s: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+	return catalogs
+}
+
+// emailLanguage resolves a user's language (e.g. "fr", "fr-CA") to the
+// language their emails are written in
+func emailLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := emailCatalogs[lang]; ok {
+		return lang
+	}
+	return defaultEmailLanguage
+}
+
+// renderEmail renders an email template in the given language and branding,
+// with its plaintext alternative
+func (s *EmailService) renderEmail(to, name, lang string, branding *models.TenantBranding, data map[string]interface{}) (*models.EmailMessage, error) {
+	tmpl, ok := emailTemplates[name]
+	if !ok {
+		return nil, utils.NewNotFoundError("EMAIL_TEMPLATE_NOT_FOUND", "email template not found")
+	}
+
+	lang = emailLanguage(lang)
+	dir := "ltr"
+	if lang == "ar" {
+		dir = "rtl"
+	}
+
+	data["AppName"] = s.app.Name
+	data["Brand"] = s.brand(branding)
+	data["Year"] = time.Now().Year()
+	data["Lang"] = lang
+	data["Dir"] = dir
+
+	funcs := emailFuncs(lang)
+	htmlTmpl, err := tmpl.html.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	textTmpl, err := tmpl.text.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	htmlTmpl.Funcs(funcs)
+	textTmpl.Funcs(funcs)
+
+	var subject, text, body bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	if err := textTmpl.ExecuteTemplate(&text, "layout.txt", data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	if err := htmlTmpl.ExecuteTemplate(&body, "layout.html", data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &models.EmailMessage{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		Body:     body.String(),
+		Text:     strings.TrimSpace(text.String()) + "\n",
+		Template: name,
+	}, nil
+}
+
+var (
+	htmlLinkRegex     = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlBreakRegex    = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|table|ul|ol)>`)
+	htmlTagRegex      = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRegex   = regexp.MustCompile(`\n\s*\n\s*\n+`)
+	lineIndentRegex   = regexp.MustCompile(`(?m)^[ \t]+|[ \t]+$`)
+	htmlCommentsRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// htmlToText turns an HTML fragment (a broadcast or automation body) into
+// plaintext; links keep their URL in parentheses
+func htmlToText(fragment string) string {
+	text := htmlCommentsRegex.ReplaceAllString(fragment, "")
+	text = htmlLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+		match := htmlLinkRegex.FindStringSubmatch(link)
+		label := strings.TrimSpace(htmlTagRegex.ReplaceAllString(match[2], ""))
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+		return label + " (" + match[1] + ")"
+	})
+	text = htmlBreakRegex.ReplaceAllString(text, "$0\n")
+	text = htmlTagRegex.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = lineIndentRegex.ReplaceAllString(text, "")
+	text = blankLinesRegex.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// Preview renders an email template with sample data, in the given language
+// and branding
+func (s *EmailService) Preview(name, lang string, branding *models.TenantBranding) (*models.EmailTemplatePreview, error) {
+	data, ok := s.previewData(name)
+	if !ok {
+		return nil, utils.NewNotFoundError("EMAIL_TEMPLATE_NOT_FOUND", "email template not found")
+	}
+
+	message, err := s.renderEmail("jane.doe@example.com", name, lang, branding, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EmailTemplatePreview{
+		Template: name,
+		Language: emailLanguage(lang),
+		Subject:  message.Subject,
+		HTML:     message.Body,
+		Text:     message.Text,
+	}, nil
+}
+
+// previewData is the sample data an email template is previewed with
+func (s *EmailService) previewData(name string) (map[string]interface{}, bool) {
+	token := uuid.Nil
+	expiresAt := time.Now().Add(72 * time.Hour).UTC().Format("2006-01-02 15:04 MST")
+	reviewURL := fmt.Sprintf("%s/approvals", s.app.FrontendURL)
+	content := htmltemplate.HTML("<p>We are moving to the new office on Monday.</p><p>See the <a href=\"https://example.com/office\">directions</a>.</p>")
+
+	data := map[string]interface{}{
+		"FirstName":   "Jane",
+		"CompanyName": "Acme Corp",
+	}
+	switch name {
+	case models.EmailTemplateTenantVerification:
+		data["VerifyURL"] = fmt.Sprintf("%s/auth/verify?token=%s", s.app.FrontendURL, token)
+	case models.EmailTemplatePasswordReset:
+		data["ResetURL"] = fmt.Sprintf("%s/reset-password?token=%s", s.app.FrontendURL, token)
+	case models.EmailTemplateInvitation:
+		data["InviterName"] = "John Smith"
+		data["Message"] = "Welcome aboard!"
+		data["AcceptURL"] = fmt.Sprintf("%s/accept-invitation?token=%s", s.app.FrontendURL, token)
+	case models.EmailTemplateWelcome:
+		data["DashboardURL"] = fmt.Sprintf("%s/dashboard", s.app.FrontendURL)
+	case models.EmailTemplateAccountSetup:
+		data["CreatorName"] = "John Smith"
+		data["SetupURL"] = fmt.Sprintf("%s/reset-password?token=%s", s.app.FrontendURL, token)
+		data["ExpiresAt"] = expiresAt
+	case models.EmailTemplateEmailChange:
+		data["CurrentEmail"] = "jane@example.com"
+		data["ConfirmURL"] = fmt.Sprintf("%s/confirm-email?token=%s", s.app.FrontendURL, token)
+		data["ExpiresAt"] = expiresAt
+	case models.EmailTemplateTwoFactorBypass:
+		data["Code"] = "12345678"
+		data["IssuedBy"] = "John Smith"
+		data["ExpiresAt"] = expiresAt
+	case models.EmailTemplateTwoFactorSetupReminder:
+		data["SetupURL"] = fmt.Sprintf("%s/settings/security", s.app.FrontendURL)
+		data["Deadline"] = expiresAt
+	case models.EmailTemplateDeletionScheduled:
+		data["Label"] = "Invoice INV-0042"
+		data["UndoURL"] = fmt.Sprintf("%s/undo-deletion?id=%s", s.app.FrontendURL, token)
+		data["ExecuteAt"] = expiresAt
+	case models.EmailTemplateTenantDeletionConfirm:
+		data["ConfirmURL"] = fmt.Sprintf("%s/confirm-tenant-deletion?token=%s", s.app.FrontendURL, token)
+		data["ExpiresAt"] = expiresAt
+		data["GraceDays"] = 30
+	case models.EmailTemplateTenantDeletionScheduled:
+		data["CancelURL"] = fmt.Sprintf("%s/settings/account/deletion", s.app.FrontendURL)
+		data["DeleteAt"] = expiresAt
+	case models.EmailTemplateBroadcast:
+		data["Subject"] = "Office move"
+		data["Content"] = content
+		data["ContentText"] = htmlToText(string(content))
+		data["UnsubscribeURL"] = s.BroadcastUnsubscribeURL("acme", token)
+	case models.EmailTemplateAutomation:
+		data["Subject"] = "Office move"
+		data["Content"] = content
+		data["ContentText"] = htmlToText(string(content))
+	case models.EmailTemplateApprovalRequest:
+		data["Title"] = "Purchase order PO-0042"
+		data["Details"] = "Office furniture, 12,500.00 DZD"
+		data["ApproveURL"] = s.ApprovalLinkURL("acme", token.String(), "approve")
+		data["RejectURL"] = s.ApprovalLinkURL("acme", token.String(), "reject")
+		data["ReviewURL"] = reviewURL
+		data["StepUp"] = true
+		data["ExpiresAt"] = expiresAt
+	case models.EmailTemplateEscalation:
+		data["Title"] = "Purchase order PO-0042"
+		data["Details"] = "Office furniture, 12,500.00 DZD"
+		data["ReviewURL"] = reviewURL
+		data["PendingSince"] = expiresAt
+	case models.EmailTemplateDataQualityAlert:
+		data["Alerts"] = []models.DataQualityAlert{
+			{Name: "Customers without email", Severity: models.DataQualitySeverityWarning, IssueCount: 12, PreviousCount: 4},
+		}
+		data["ReportURL"] = s.app.FrontendURL + "/settings/data-quality"
+	case models.EmailTemplateQuotaAlert:
+		alerts := []models.QuotaAlert{
+			{Metric: models.QuotaMetricStorage, Threshold: 90, Usage: 945 << 20, Limit: 1 << 30},
+		}
+		data["Alerts"] = quotaAlertRows(alerts)
+		data["Highest"] = highestQuotaThreshold(alerts)
+		data["QuotasURL"] = s.app.FrontendURL + "/settings/quotas"
+	case models.EmailTemplateLeaveRequest:
+		data["EmployeeName"] = "John Smith"
+		data["LeaveType"] = "Annual leave"
+		data["Period"] = "2026-08-03 - 2026-08-07"
+		data["Days"] = 5.0
+		data["Reason"] = "Family holiday"
+		data["ReviewURL"] = s.app.FrontendURL + "/leave/approvals"
+	case models.EmailTemplateLeaveDecision:
+		data["LeaveType"] = "Annual leave"
+		data["Period"] = "2026-08-03 - 2026-08-07"
+		data["Days"] = 5.0
+		data["Decision"] = models.LeaveStatusApproved
+		data["DeciderName"] = "John Smith"
+		data["Note"] = "Enjoy your holiday!"
+		data["RequestURL"] = s.app.FrontendURL + "/leave/requests/" + token.String()
+	case models.EmailTemplateNotification:
+		data["Title"] = "Your roles have changed"
+		data["Body"] = "Your roles now include Accountant. Your access was updated accordingly."
+		data["URL"] = s.app.FrontendURL + "/profile"
+		data["PreferencesURL"] = s.app.FrontendURL + "/settings/notifications"
+	case models.EmailTemplateNotificationDigest:
+		link := "/leave/approvals"
+		notifications := []models.Notification{
+			{Title: "John Smith requested leave", Body: "Annual leave, 2026-08-03 - 2026-08-07", Link: &link, CreatedAt: time.Now()},
+			{Title: "Jane Roe accepted your invitation", Body: "jane.roe@example.com has joined the team.", CreatedAt: time.Now().Add(-26 * time.Hour)},
+		}
+		data["Digest"] = models.NotificationDigestWeekly
+		data["Count"] = len(notifications)
+		data["Items"] = s.notificationDigestItems(notifications)
+		data["More"] = 0
+		data["NotificationsURL"] = s.app.FrontendURL + "/notifications"
+		data["PreferencesURL"] = s.app.FrontendURL + "/settings/notifications"
+	case models.EmailTemplateScheduledReport:
+		data["Name"] = "Weekly sign-ins"
+		data["PeriodStart"] = time.Now().AddDate(0, 0, -7).UTC().Format("2006-01-02 15:04")
+		data["PeriodEnd"] = time.Now().UTC().Format("2006-01-02 15:04")
+		data["Format"] = "CSV"
+		data["Filename"] = "weekly-sign-ins-2026-10-17.csv"
+		data["ReportsURL"] = s.app.FrontendURL + "/settings/reports"
+	default:
+		return nil, false
+	}
+	return data, true
+}
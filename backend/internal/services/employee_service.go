@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// EmployeeService handles HR employee records, their links to users and
+// departments, and the manager hierarchy behind the org chart
+type EmployeeService struct {
+	employeeRepo    repository.EmployeeStore
+	userRepo        repository.UserStore
+	departmentRepo  repository.DepartmentStore
+	deletionService DeletionManager
+}
+
+// NewEmployeeService creates a new employee service
+func NewEmployeeService(
+	employeeRepo repository.EmployeeStore,
+	userRepo repository.UserStore,
+	departmentRepo repository.DepartmentStore,
+	deletionService DeletionManager,
+) *EmployeeService {
+	return &EmployeeService{
+		employeeRepo:    employeeRepo,
+		userRepo:        userRepo,
+		departmentRepo:  departmentRepo,
+		deletionService: deletionService,
+	}
+}
+
+// Create creates an active employee. A linked user provides the names,
+// email and department the request leaves out.
+func (s *EmployeeService) Create(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.EmployeeCreateRequest) (*models.Employee, error) {
+	employee := &models.Employee{
+		DepartmentID:   req.DepartmentID,
+		EmployeeNumber: req.EmployeeNumber,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		JobTitle:       req.JobTitle,
+		EmploymentType: req.EmploymentType,
+		HireDate:       req.HireDate,
+		SalaryBand:     req.SalaryBand,
+		Status:         models.EmployeeStatusActive,
+		CreatedBy:      &creatorID,
+	}
+	if employee.EmploymentType == "" {
+		employee.EmploymentType = models.EmploymentTypeFullTime
+	}
+
+	if req.UserID != nil {
+		user, err := s.linkUser(ctx, tenantID, *req.UserID, nil)
+		if err != nil {
+			return nil, err
+		}
+		employee.UserID = &user.ID
+		if employee.FirstName == "" {
+			employee.FirstName = user.FirstName
+		}
+		if employee.LastName == "" {
+			employee.LastName = user.LastName
+		}
+		if employee.Email == nil {
+			employee.Email = &user.Email
+		}
+		if employee.DepartmentID == nil {
+			employee.DepartmentID = user.DepartmentID
+		}
+	}
+	if employee.FirstName == "" || employee.LastName == "" {
+		return nil, fmt.Errorf("first and last name are required")
+	}
+
+	if err := s.checkNumber(ctx, tenantID, employee.EmployeeNumber, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkDepartment(ctx, tenantID, employee.DepartmentID); err != nil {
+		return nil, err
+	}
+	if req.ManagerID != nil {
+		if err := s.checkManager(ctx, tenantID, nil, *req.ManagerID); err != nil {
+			return nil, err
+		}
+		employee.ManagerID = req.ManagerID
+	}
+
+	if err := s.employeeRepo.Create(ctx, tenantID, employee); err != nil {
+		return nil, err
+	}
+
+	return s.employeeRepo.FindByID(ctx, tenantID, employee.ID)
+}
+
+// Get retrieves an employee
+func (s *EmployeeService) Get(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error) {
+	return s.employeeRepo.FindByID(ctx, tenantID, employeeID)
+}
+
+// GetByUser retrieves the employee linked to a user
+func (s *EmployeeService) GetByUser(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error) {
+	return s.employeeRepo.FindByUserID(ctx, tenantID, userID)
+}
+
+// List lists employees with filters and pagination
+func (s *EmployeeService) List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error) {
+	return s.employeeRepo.List(ctx, tenantID, filter, limit, offset)
+}
+
+// Update updates an employee. Terminating an employee without a termination
+// date uses today.
+func (s *EmployeeService) Update(ctx context.Context, tenantID, employeeID uuid.UUID, req *models.EmployeeUpdateRequest) (*models.Employee, error) {
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.ClearUser:
+		employee.UserID = nil
+	case req.UserID != nil:
+		if _, err := s.linkUser(ctx, tenantID, *req.UserID, &employeeID); err != nil {
+			return nil, err
+		}
+		employee.UserID = req.UserID
+	}
+
+	switch {
+	case req.ClearDepartment:
+		employee.DepartmentID = nil
+	case req.DepartmentID != nil:
+		if err := s.checkDepartment(ctx, tenantID, req.DepartmentID); err != nil {
+			return nil, err
+		}
+		employee.DepartmentID = req.DepartmentID
+	}
+
+	switch {
+	case req.ClearManager:
+		employee.ManagerID = nil
+	case req.ManagerID != nil:
+		if err := s.checkManager(ctx, tenantID, &employeeID, *req.ManagerID); err != nil {
+			return nil, err
+		}
+		employee.ManagerID = req.ManagerID
+	}
+
+	if req.EmployeeNumber != nil {
+		if *req.EmployeeNumber == "" {
+			employee.EmployeeNumber = nil
+		} else {
+			if err := s.checkNumber(ctx, tenantID, req.EmployeeNumber, &employeeID); err != nil {
+				return nil, err
+			}
+			employee.EmployeeNumber = req.EmployeeNumber
+		}
+	}
+	if req.FirstName != nil {
+		employee.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		employee.LastName = *req.LastName
+	}
+	if req.Email != nil {
+		employee.Email = req.Email
+	}
+	if req.Phone != nil {
+		employee.Phone = req.Phone
+	}
+	if req.JobTitle != nil {
+		employee.JobTitle = *req.JobTitle
+	}
+	if req.EmploymentType != nil {
+		employee.EmploymentType = *req.EmploymentType
+	}
+	if req.HireDate != nil {
+		employee.HireDate = *req.HireDate
+	}
+	if req.TerminationDate != nil {
+		employee.TerminationDate = req.TerminationDate
+	}
+	if req.SalaryBand != nil {
+		employee.SalaryBand = req.SalaryBand
+	}
+	if req.Status != nil {
+		employee.Status = *req.Status
+	}
+
+	if employee.IsTerminated() {
+		if employee.TerminationDate == nil {
+			today := time.Now().UTC().Truncate(24 * time.Hour)
+			employee.TerminationDate = &today
+		}
+	} else {
+		employee.TerminationDate = nil
+	}
+	if employee.TerminationDate != nil && employee.TerminationDate.Before(employee.HireDate) {
+		return nil, fmt.Errorf("termination date cannot be before hire date")
+	}
+
+	if err := s.employeeRepo.Update(ctx, tenantID, employee); err != nil {
+		return nil, err
+	}
+
+	return s.employeeRepo.FindByID(ctx, tenantID, employee.ID)
+}
+
+// Delete schedules an employee for deletion. It is deleted by Purge once the
+// undo window has passed.
+func (s *EmployeeService) Delete(ctx context.Context, tenantID, userID, employeeID uuid.UUID) (*models.PendingDeletion, error) {
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deletionService.Schedule(ctx, tenantID, userID, models.DeletionEntityEmployee, employee.ID, employee.FullName())
+}
+
+// Purge permanently deletes an employee staged for deletion. Their direct
+// reports move up to the employee's manager.
+func (s *EmployeeService) Purge(ctx context.Context, tenantID, _, employeeID uuid.UUID) error {
+	return s.employeeRepo.Delete(ctx, tenantID, employeeID)
+}
+
+// OrgChart builds the reporting hierarchy of current employees. Without a
+// root it returns every top of the hierarchy: employees without a manager,
+// or whose manager has left. depth limits the levels returned (0 means all).
+func (s *EmployeeService) OrgChart(ctx context.Context, tenantID uuid.UUID, rootID *uuid.UUID, depth int) ([]models.OrgChartNode, error) {
+	employees, err := s.employeeRepo.ListCurrent(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[uuid.UUID]bool, len(employees))
+	for _, employee := range employees {
+		current[employee.ID] = true
+	}
+
+	reports := make(map[uuid.UUID][]models.Employee)
+	roots := []models.Employee{}
+	for _, employee := range employees {
+		switch {
+		case rootID != nil && employee.ID == *rootID:
+			roots = append(roots, employee)
+		case employee.ManagerID != nil && current[*employee.ManagerID]:
+			reports[*employee.ManagerID] = append(reports[*employee.ManagerID], employee)
+		case rootID == nil:
+			roots = append(roots, employee)
+		}
+	}
+	if rootID != nil && len(roots) == 0 {
+		return nil, utils.NewNotFoundError("EMPLOYEE_NOT_FOUND", "employee not found")
+	}
+
+	var build func(employee models.Employee, level int) models.OrgChartNode
+	build = func(employee models.Employee, level int) models.OrgChartNode {
+		node := models.OrgChartNode{
+			ID:             employee.ID,
+			UserID:         employee.UserID,
+			Name:           employee.FullName(),
+			JobTitle:       employee.JobTitle,
+			Status:         employee.Status,
+			DepartmentID:   employee.DepartmentID,
+			DepartmentName: employee.DepartmentName,
+			Reports:        []models.OrgChartNode{},
+		}
+		if depth > 0 && level >= depth {
+			return node
+		}
+		for _, report := range reports[employee.ID] {
+			node.Reports = append(node.Reports, build(report, level+1))
+		}
+		return node
+	}
+
+	chart := make([]models.OrgChartNode, len(roots))
+	for i, root := range roots {
+		chart[i] = build(root, 1)
+	}
+
+	return chart, nil
+}
+
+// Describe describes an employee for recently viewed and favorites lists
+func (s *EmployeeService) Describe(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.EntitySummary, error) {
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    employee.FullName(),
+		Subtitle: employee.JobTitle,
+		Path:     fmt.Sprintf("/employees/%s", employee.ID),
+	}, nil
+}
+
+// linkUser checks that a user exists and has no other employee record
+func (s *EmployeeService) linkUser(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user")
+	}
+
+	linked, err := s.employeeRepo.FindByUserID(ctx, tenantID, userID)
+	if err == nil && (employeeID == nil || linked.ID != *employeeID) {
+		return nil, fmt.Errorf("user already has an employee record")
+	}
+	if err != nil && err.Error() != "employee not found" {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkNumber checks that an employee number is not used by another employee
+func (s *EmployeeService) checkNumber(ctx context.Context, tenantID uuid.UUID, number *string, employeeID *uuid.UUID) error {
+	if number == nil || *number == "" {
+		return nil
+	}
+
+	exists, err := s.employeeRepo.CheckNumberExists(ctx, tenantID, *number, employeeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("EMPLOYEE_NUMBER_EXISTS", "employee number already exists")
+	}
+
+	return nil
+}
+
+// checkDepartment checks that a department exists
+func (s *EmployeeService) checkDepartment(ctx context.Context, tenantID uuid.UUID, departmentID *uuid.UUID) error {
+	if departmentID == nil {
+		return nil
+	}
+
+	if _, err := s.departmentRepo.FindByID(ctx, tenantID, *departmentID); err != nil {
+		return fmt.Errorf("invalid department")
+	}
+
+	return nil
+}
+
+// checkManager checks that an employee can report to a manager: the manager
+// exists, has not left and does not report to the employee
+func (s *EmployeeService) checkManager(ctx context.Context, tenantID uuid.UUID, employeeID *uuid.UUID, managerID uuid.UUID) error {
+	manager, err := s.employeeRepo.FindByID(ctx, tenantID, managerID)
+	if err != nil || manager.IsTerminated() {
+		return fmt.Errorf("invalid manager")
+	}
+
+	if employeeID == nil {
+		return nil
+	}
+	if managerID == *employeeID {
+		return fmt.Errorf("manager would create a reporting cycle")
+	}
+
+	cycle, err := s.employeeRepo.ReportsTo(ctx, tenantID, managerID, *employeeID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return fmt.Errorf("manager would create a reporting cycle")
+	}
+
+	return nil
+}
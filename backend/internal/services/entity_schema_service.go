@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+var (
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	byteSliceType = reflect.TypeOf([]byte{})
+)
+
+// entitySchemaType is an entity type whose fields can be described
+type entitySchemaType struct {
+	resource string // Permission resource whose view permission shows the schema
+	fields   []models.EntityField
+}
+
+// EntitySchemaService describes the fields of entity types for clients
+// rendering forms, list columns and import templates. Built-in fields are
+// read once from the entity's model and create request: json tags name them
+// and validate tags tell which are required. A tenant's custom fields follow
+// them.
+type EntitySchemaService struct {
+	permissionService PermissionManager
+	customFieldRepo   repository.CustomFieldStore
+	types             map[string]entitySchemaType
+}
+
+// NewEntitySchemaService creates a new entity schema service
+func NewEntitySchemaService(permissionService PermissionManager, customFieldRepo repository.CustomFieldStore) *EntitySchemaService {
+	return &EntitySchemaService{
+		permissionService: permissionService,
+		customFieldRepo:   customFieldRepo,
+		types:             make(map[string]entitySchemaType),
+	}
+}
+
+// RegisterType registers an entity type. model is the entity as returned by
+// the API and input the request creating it; fields only in model are read
+// only and fields only in input write only. options lists the values allowed
+// for enumerated fields.
+func (s *EntitySchemaService) RegisterType(entityType, resource string, model, input interface{}, options map[string][]string) {
+	inputFields := make(map[string]models.EntityField)
+	var writeOnly []models.EntityField
+	for _, field := range schemaFields(reflect.TypeOf(input)) {
+		inputFields[field.Name] = field
+		writeOnly = append(writeOnly, field)
+	}
+
+	// Fields in the model's order, with the validation of their input field,
+	// then those never returned
+	var fields []models.EntityField
+	for _, field := range schemaFields(reflect.TypeOf(model)) {
+		if accepted, ok := inputFields[field.Name]; ok {
+			field = accepted
+			delete(inputFields, field.Name)
+		} else {
+			field.ReadOnly = true
+			field.Required = false
+		}
+		fields = append(fields, field)
+	}
+	for _, field := range writeOnly {
+		if _, ok := inputFields[field.Name]; ok {
+			field.WriteOnly = true
+			fields = append(fields, field)
+		}
+	}
+
+	for i := range fields {
+		fields[i].Options = options[fields[i].Name]
+	}
+
+	s.types[entityType] = entitySchemaType{resource: resource, fields: fields}
+}
+
+// EntityTypes lists the entity types that have a schema
+func (s *EntitySchemaService) EntityTypes() []string {
+	types := make([]string, 0, len(s.types))
+	for entityType := range s.types {
+		types = append(types, entityType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// GetSchema describes an entity type's fields for a tenant. The user must
+// be allowed to view the entity type.
+func (s *EntitySchemaService) GetSchema(ctx context.Context, tenantID, userID uuid.UUID, entityType string) (*models.EntitySchema, error) {
+	t, ok := s.types[entityType]
+	if !ok {
+		return nil, utils.NewNotFoundError("ENTITY_TYPE_NOT_FOUND", "entity type not found")
+	}
+
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, t.resource, models.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions to view entity")
+	}
+
+	customFields, err := s.customFieldRepo.List(ctx, tenantID, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := s.schema(entityType, t)
+	schema.Fields = append(schema.Fields, customSchemaFields(customFields)...)
+	return schema, nil
+}
+
+// ListSchemas describes the entity types the user is allowed to view, for
+// a tenant
+func (s *EntitySchemaService) ListSchemas(ctx context.Context, tenantID, userID uuid.UUID) ([]models.EntitySchema, error) {
+	permissions, err := s.permissionService.GetUserPermissions(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields, err := s.customFieldRepo.List(ctx, tenantID, "")
+	if err != nil {
+		return nil, err
+	}
+	customByEntity := make(map[string][]models.CustomField)
+	for _, field := range customFields {
+		customByEntity[field.Entity] = append(customByEntity[field.Entity], field)
+	}
+
+	var schemas []models.EntitySchema
+	for _, entityType := range s.EntityTypes() {
+		t := s.types[entityType]
+		if models.PermissionsAllow(permissions, t.resource, models.ActionView) {
+			schema := s.schema(entityType, t)
+			schema.Fields = append(schema.Fields, customSchemaFields(customByEntity[entityType])...)
+			schemas = append(schemas, *schema)
+		}
+	}
+	return schemas, nil
+}
+
+// BuiltInSchemas describes the built-in fields of every entity type, as
+// shared by all tenants
+func (s *EntitySchemaService) BuiltInSchemas() []models.EntitySchema {
+	schemas := make([]models.EntitySchema, 0, len(s.types))
+	for _, entityType := range s.EntityTypes() {
+		schemas = append(schemas, *s.schema(entityType, s.types[entityType]))
+	}
+	return schemas
+}
+
+// schema copies an entity type's fields, so callers can add to them
+func (s *EntitySchemaService) schema(entityType string, t entitySchemaType) *models.EntitySchema {
+	return &models.EntitySchema{
+		Entity:   entityType,
+		Resource: t.resource,
+		Fields:   append([]models.EntityField(nil), t.fields...),
+	}
+}
+
+// customSchemaFields describes a tenant's custom fields. Their values are
+// read and written in the entity's custom_fields, by name.
+func customSchemaFields(customFields []models.CustomField) []models.EntityField {
+	fields := make([]models.EntityField, 0, len(customFields))
+	for _, custom := range customFields {
+		field := models.EntityField{
+			Name:     custom.Key,
+			Label:    custom.Label,
+			Type:     models.FieldTypeString,
+			Required: custom.Required,
+			Custom:   true,
+		}
+		switch custom.FieldType {
+		case models.CustomFieldTypeText:
+			field.MaxLength = customFieldTextMaxLength
+		case models.CustomFieldTypeNumber:
+			field.Type = models.FieldTypeNumber
+		case models.CustomFieldTypeDate:
+			field.Format = "date"
+		case models.CustomFieldTypeSelect:
+			field.Options = custom.Options
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// schemaFields describes the JSON fields of a struct type, including those
+// of embedded structs. Fields hidden from JSON are left out.
+func schemaFields(t reflect.Type) []models.EntityField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []models.EntityField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, schemaFields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		field := models.EntityField{
+			Name:  name,
+			Label: fieldLabel(name),
+			Type:  fieldType(f.Type),
+		}
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				field.Required = true
+			case "email":
+				field.Format = "email"
+			case "max", "len":
+				if field.Type == models.FieldTypeString {
+					field.MaxLength, _ = strconv.Atoi(value)
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// fieldType maps a Go type to an entity field type
+func fieldType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == uuidType:
+		return models.FieldTypeUUID
+	case t == timeType:
+		return models.FieldTypeDateTime
+	case t == rawJSONType || t == byteSliceType:
+		return models.FieldTypeObject
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return models.FieldTypeString
+	case reflect.Bool:
+		return models.FieldTypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return models.FieldTypeInteger
+	case reflect.Float32, reflect.Float64:
+		return models.FieldTypeNumber
+	case reflect.Slice, reflect.Array:
+		return models.FieldTypeArray
+	default:
+		return models.FieldTypeObject
+	}
+}
+
+// fieldLabel turns a field name into a label, e.g. "first_name" into
+// "First name"
+func fieldLabel(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+	label := strings.ReplaceAll(name, "_", " ")
+	label = strings.TrimSuffix(label, " id")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
@@ -0,0 +1,564 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// escalationAuditTriggered is the audit action of a step that fired
+const escalationAuditTriggered = "escalation.triggered"
+
+const escalationMaxSteps = 10
+
+// EscalationSource lists a resource type's approvals that have been pending
+// since before cutoff
+type EscalationSource func(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.EscalationItem, error)
+
+// EscalationService manages a tenant's escalation policies. The escalation
+// worker (ProcessDue) checks the approvals each enabled policy covers and,
+// for those pending longer than a step's hours, notifies the step's target by
+// email and/or Slack. Each step fires at most once per approval and is kept
+// in the escalation log shown with the approval.
+type EscalationService struct {
+	db                *sqlx.DB
+	escalationRepo    repository.EscalationStore
+	userRepo          repository.UserStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	departmentRepo    repository.DepartmentStore
+	tenantRepo        repository.TenantStore
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	webhookService    WebhookManager
+	auditService      AuditManager
+	config            *config.Config
+	sources           map[string]EscalationSource
+}
+
+// NewEscalationService creates a new escalation service
+func NewEscalationService(
+	db *sqlx.DB,
+	escalationRepo repository.EscalationStore,
+	userRepo repository.UserStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	departmentRepo repository.DepartmentStore,
+	tenantRepo repository.TenantStore,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	webhookService WebhookManager,
+	auditService AuditManager,
+	cfg *config.Config,
+) *EscalationService {
+	return &EscalationService{
+		db:                db,
+		escalationRepo:    escalationRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		departmentRepo:    departmentRepo,
+		tenantRepo:        tenantRepo,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		webhookService:    webhookService,
+		auditService:      auditService,
+		config:            cfg,
+		sources:           make(map[string]EscalationSource),
+	}
+}
+
+// RegisterSource registers a resource type whose pending approvals policies
+// can escalate
+func (s *EscalationService) RegisterSource(resourceType string, source EscalationSource) {
+	s.sources[resourceType] = source
+}
+
+// ResourceTypes lists the resource types policies can escalate
+func (s *EscalationService) ResourceTypes() []string {
+	types := make([]string, 0, len(s.sources))
+	for resourceType := range s.sources {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CreatePolicy creates a policy. Policies are enabled unless requested
+// otherwise.
+func (s *EscalationService) CreatePolicy(ctx context.Context, tenantID, userID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	policy := &models.EscalationPolicy{
+		TenantID:  tenantID,
+		IsEnabled: true,
+		CreatedBy: userID,
+	}
+
+	if err := s.applyPolicyRequest(ctx, policy, req, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.escalationRepo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ListPolicies lists a tenant's policies
+func (s *EscalationService) ListPolicies(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error) {
+	return s.escalationRepo.List(ctx, tenantID, limit, offset)
+}
+
+// GetPolicy retrieves a policy
+func (s *EscalationService) GetPolicy(ctx context.Context, tenantID, policyID uuid.UUID) (*models.EscalationPolicy, error) {
+	return s.escalationRepo.FindByID(ctx, tenantID, policyID)
+}
+
+// UpdatePolicy replaces a policy's definition. Steps that already fired are
+// not sent again, even if the steps changed.
+func (s *EscalationService) UpdatePolicy(ctx context.Context, tenantID, policyID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	policy, err := s.escalationRepo.FindByID(ctx, tenantID, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyPolicyRequest(ctx, policy, req, &policyID); err != nil {
+		return nil, err
+	}
+
+	if err := s.escalationRepo.Update(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// SetPolicyEnabled enables or disables a policy
+func (s *EscalationService) SetPolicyEnabled(ctx context.Context, tenantID, policyID uuid.UUID, enabled bool) (*models.EscalationPolicy, error) {
+	return s.escalationRepo.SetEnabled(ctx, tenantID, policyID, enabled)
+}
+
+// DeletePolicy deletes a policy and its escalation log
+func (s *EscalationService) DeletePolicy(ctx context.Context, tenantID, policyID uuid.UUID) error {
+	return s.escalationRepo.Delete(ctx, tenantID, policyID)
+}
+
+// ListEscalations lists a policy's escalation log, newest first
+func (s *EscalationService) ListEscalations(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error) {
+	if _, err := s.escalationRepo.FindByID(ctx, tenantID, policyID); err != nil {
+		return nil, 0, err
+	}
+	return s.escalationRepo.ListByPolicy(ctx, tenantID, policyID, limit, offset)
+}
+
+// ListForResource lists the escalations of one approval, oldest first
+func (s *EscalationService) ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error) {
+	return s.escalationRepo.ListForResource(ctx, tenantID, resourceType, resourceID)
+}
+
+// ProcessDue escalates overdue approvals in every data region. Returns the
+// number of steps that fired. A policy that fails is logged and skipped so
+// it does not hold up the others.
+func (s *EscalationService) ProcessDue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		policies, err := s.escalationRepo.ListEnabled(ctx, db)
+		if err != nil {
+			return total, err
+		}
+
+		for i := range policies {
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+			if jobs.IsPaused(ctx, policies[i].TenantID) {
+				continue
+			}
+
+			fired, err := s.escalatePolicy(ctx, &policies[i])
+			total += fired
+			if err != nil {
+				log.Printf("⚠️  Escalation policy %q of tenant %s failed: %v", policies[i].Name, policies[i].TenantID, err)
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// escalatePolicy fires the steps of a policy that are due. For each approval
+// only the highest due step fires: an approval that was already long pending
+// when the policy was created goes straight to the matching level.
+func (s *EscalationService) escalatePolicy(ctx context.Context, policy *models.EscalationPolicy) (int, error) {
+	source, ok := s.sources[policy.ResourceType]
+	if !ok || len(policy.Steps) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	items, err := source(ctx, policy.TenantID, now.Add(-escalationStepDelay(&policy.Steps[0])))
+	if err != nil || len(items) == 0 {
+		return 0, err
+	}
+
+	lastSteps, err := s.escalationRepo.LastSteps(ctx, policy.TenantID, policy.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, policy.TenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	for i := range items {
+		item := &items[i]
+
+		step := 0
+		for j := range policy.Steps {
+			if now.Sub(item.PendingSince) >= escalationStepDelay(&policy.Steps[j]) {
+				step = j + 1
+			}
+		}
+		if step <= lastSteps[item.ResourceID] {
+			continue
+		}
+
+		ok, err := s.escalate(ctx, tenant, policy, item, step)
+		if err != nil {
+			return fired, err
+		}
+		if ok {
+			fired++
+		}
+	}
+
+	return fired, nil
+}
+
+// escalate fires one step for an approval. The step is logged in the
+// transaction that queues its emails; Slack is posted once that committed.
+// Returns false if the step had already fired.
+func (s *EscalationService) escalate(ctx context.Context, tenant *models.Tenant, policy *models.EscalationPolicy, item *models.EscalationItem, step int) (bool, error) {
+	stepDef := &policy.Steps[step-1]
+
+	escalation := &models.Escalation{
+		TenantID:     policy.TenantID,
+		PolicyID:     policy.ID,
+		ResourceType: policy.ResourceType,
+		ResourceID:   item.ResourceID,
+		Title:        item.Title,
+		Step:         step,
+		AfterHours:   stepDef.AfterHours,
+		Target:       stepDef.Target,
+		Channels:     pq.StringArray(stepDef.Channels),
+		Recipients:   pq.StringArray{},
+	}
+
+	var recipients []models.User
+	if escalationHasChannel(stepDef, models.EscalationChannelEmail) {
+		var err error
+		if recipients, err = s.recipients(ctx, policy.TenantID, stepDef, item); err != nil {
+			return false, err
+		}
+		for _, user := range recipients {
+			escalation.Recipients = append(escalation.Recipients, user.Email)
+		}
+	}
+
+	reviewURL := s.config.App.FrontendURL + item.ReviewURL
+
+	tx, err := database.WithTenantContext(ctx, s.db, policy.TenantID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	recorded, err := s.escalationRepo.Record(ctx, tx.Tx, escalation)
+	if err != nil || !recorded {
+		return false, err
+	}
+
+	for _, user := range recipients {
+		msg, err := s.emailService.EscalationEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, item.Title, item.Details, reviewURL, item.PendingSince)
+		if err != nil {
+			return false, err
+		}
+		if err := s.emailQueueService.Enqueue(ctx, tx.Tx, policy.TenantID, msg); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record escalation: %w", err)
+	}
+
+	// The step fired: its outcome must be recorded even if the worker is
+	// shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	if escalationHasChannel(stepDef, models.EscalationChannelSlack) {
+		s.postToSlack(ctx, policy, item, escalation, reviewURL)
+	}
+
+	if len(recipients) == 0 && escalationHasChannel(stepDef, models.EscalationChannelEmail) {
+		log.Printf("⚠️  Escalation policy %q step %d found no one to email for %s %s", policy.Name, step, policy.ResourceType, item.ResourceID)
+	}
+
+	s.auditService.LogEvent(ctx, policy.TenantID, uuid.Nil, escalationAuditTriggered, policy.ResourceType, item.ResourceID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"policy_id":   policy.ID,
+		"policy_name": policy.Name,
+		"step":        step,
+		"target":      stepDef.Target,
+		"channels":    stepDef.Channels,
+		"recipients":  escalation.Recipients,
+	})
+
+	return true, nil
+}
+
+// recipients resolves who a step notifies by email. Only active users are
+// notified; a requester without a department head resolves to no one.
+func (s *EscalationService) recipients(ctx context.Context, tenantID uuid.UUID, step *models.EscalationStep, item *models.EscalationItem) ([]models.User, error) {
+	var users []models.User
+
+	switch step.Target {
+	case models.EscalationTargetManager:
+		if item.RequestedBy == nil {
+			return nil, nil
+		}
+		requester, err := s.userRepo.FindByID(ctx, tenantID, *item.RequestedBy)
+		if err != nil || requester.DepartmentID == nil {
+			return nil, nil
+		}
+		dept, err := s.departmentRepo.FindByID(ctx, tenantID, *requester.DepartmentID)
+		if err != nil || dept.HeadUserID == nil || *dept.HeadUserID == requester.ID {
+			return nil, nil
+		}
+		head, err := s.userRepo.FindByID(ctx, tenantID, *dept.HeadUserID)
+		if err != nil {
+			return nil, nil
+		}
+		users = []models.User{*head}
+
+	case models.EscalationTargetRole:
+		if step.RoleID == nil {
+			return nil, nil
+		}
+		var err error
+		if users, err = s.userRoleRepo.GetUsersByRole(ctx, tenantID, *step.RoleID); err != nil {
+			return nil, err
+		}
+
+	case models.EscalationTargetUser:
+		if step.UserID == nil {
+			return nil, nil
+		}
+		user, err := s.userRepo.FindByID(ctx, tenantID, *step.UserID)
+		if err != nil {
+			return nil, nil
+		}
+		users = []models.User{*user}
+	}
+
+	active := make([]models.User, 0, len(users))
+	for _, user := range users {
+		if user.IsActive() {
+			active = append(active, user)
+		}
+	}
+	return active, nil
+}
+
+// postToSlack posts an escalation to the policy's Slack webhook and records
+// the outcome. Failures are not retried: the email is the reliable channel.
+func (s *EscalationService) postToSlack(ctx context.Context, policy *models.EscalationPolicy, item *models.EscalationItem, escalation *models.Escalation, reviewURL string) {
+	status := models.EscalationSlackSent
+	var slackErr *string
+
+	err := s.sendSlack(ctx, policy, item, escalation, reviewURL)
+	if err != nil {
+		status = models.EscalationSlackFailed
+		msg := err.Error()
+		slackErr = &msg
+		log.Printf("⚠️  Escalation %s could not be posted to Slack: %v", escalation.ID, err)
+	}
+
+	if err := s.escalationRepo.SetSlackResult(ctx, policy.TenantID, escalation.ID, status, slackErr); err != nil {
+		log.Printf("⚠️  Failed to record Slack result of escalation %s: %v", escalation.ID, err)
+	}
+}
+
+// sendSlack posts the escalation message to the policy's Slack webhook
+func (s *EscalationService) sendSlack(ctx context.Context, policy *models.EscalationPolicy, item *models.EscalationItem, escalation *models.Escalation, reviewURL string) error {
+	if policy.SlackWebhookURL == nil {
+		return fmt.Errorf("no Slack webhook configured")
+	}
+
+	webhookURL, err := utils.Decrypt(*policy.SlackWebhookURL, []byte(s.config.Security.EncryptionKey))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt Slack webhook URL: %w", err)
+	}
+
+	text := fmt.Sprintf(":rotating_light: *Approval overdue:* <%s|%s> has been waiting since %s (escalation %q, step %d).",
+		reviewURL,
+		escapeSlackText(item.Title),
+		item.PendingSince.UTC().Format("2006-01-02 15:04 MST"),
+		policy.Name,
+		escalation.Step,
+	)
+	if len(escalation.Recipients) > 0 {
+		text += " Escalated to " + escapeSlackText(strings.Join(escalation.Recipients, ", ")) + "."
+	}
+
+	return s.webhookService.PostJSON(ctx, webhookURL, map[string]string{"text": text})
+}
+
+// applyPolicyRequest validates a request and applies it to policy. The Slack
+// webhook URL is encrypted; nil keeps the current one.
+func (s *EscalationService) applyPolicyRequest(ctx context.Context, policy *models.EscalationPolicy, req *models.EscalationPolicyRequest, excludeID *uuid.UUID) error {
+	exists, err := s.escalationRepo.CheckNameExists(ctx, policy.TenantID, req.Name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("ESCALATION_POLICY_NAME_EXISTS", "escalation policy name already exists")
+	}
+
+	if _, ok := s.sources[req.ResourceType]; !ok {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("invalid resource type: %s", req.ResourceType))
+	}
+
+	slackURL := policy.SlackWebhookURL
+	if req.SlackWebhookURL != nil {
+		slackURL = nil
+		if rawURL := strings.TrimSpace(*req.SlackWebhookURL); rawURL != "" {
+			if err := s.webhookService.ValidateURL(rawURL); err != nil {
+				return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("invalid Slack webhook URL: %v", err))
+			}
+			encrypted, err := utils.Encrypt(rawURL, []byte(s.config.Security.EncryptionKey))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt Slack webhook URL: %w", err)
+			}
+			slackURL = &encrypted
+		}
+	}
+
+	if len(req.Steps) == 0 {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "a policy needs at least one step")
+	}
+	if len(req.Steps) > escalationMaxSteps {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("a policy can have at most %d steps", escalationMaxSteps))
+	}
+	previousHours := 0
+	for i := range req.Steps {
+		if err := s.validateStep(ctx, policy.TenantID, &req.Steps[i], previousHours, slackURL != nil); err != nil {
+			return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("step %d: %v", i+1, err))
+		}
+		previousHours = req.Steps[i].AfterHours
+	}
+
+	policy.Name = req.Name
+	policy.Description = nil
+	if req.Description != "" {
+		policy.Description = &req.Description
+	}
+	policy.ResourceType = req.ResourceType
+	policy.Steps = req.Steps
+	policy.SlackWebhookURL = slackURL
+	if req.IsEnabled != nil {
+		policy.IsEnabled = *req.IsEnabled
+	}
+
+	return nil
+}
+
+// validateStep checks a step's hours, target and channels. Steps must come
+// strictly later than the previous one.
+func (s *EscalationService) validateStep(ctx context.Context, tenantID uuid.UUID, step *models.EscalationStep, previousHours int, slackConfigured bool) error {
+	if step.AfterHours < 1 {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "after_hours must be at least 1")
+	}
+	if step.AfterHours <= previousHours {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "after_hours must be greater than the previous step's")
+	}
+
+	switch step.Target {
+	case models.EscalationTargetManager:
+		step.RoleID, step.UserID = nil, nil
+
+	case models.EscalationTargetRole:
+		if step.RoleID == nil {
+			return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "role_id is required")
+		}
+		if _, err := s.roleRepo.FindByID(ctx, tenantID, *step.RoleID); err != nil {
+			return err
+		}
+		step.UserID = nil
+
+	case models.EscalationTargetUser:
+		if step.UserID == nil {
+			return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "user_id is required")
+		}
+		if _, err := s.userRepo.FindByID(ctx, tenantID, *step.UserID); err != nil {
+			return err
+		}
+		step.RoleID = nil
+
+	default:
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("invalid target: %s", step.Target))
+	}
+
+	if len(step.Channels) == 0 {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "at least one channel is required")
+	}
+	seen := make(map[string]bool, len(step.Channels))
+	for _, channel := range step.Channels {
+		if channel != models.EscalationChannelEmail && channel != models.EscalationChannelSlack {
+			return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("invalid channel: %s", channel))
+		}
+		if seen[channel] {
+			return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", fmt.Sprintf("duplicate channel: %s", channel))
+		}
+		seen[channel] = true
+	}
+	if seen[models.EscalationChannelSlack] && !slackConfigured {
+		return utils.NewBadRequestError("INVALID_ESCALATION_POLICY", "slack_webhook_url is required to notify through Slack")
+	}
+
+	return nil
+}
+
+// escalationStepDelay is how long an approval is pending before a step fires
+func escalationStepDelay(step *models.EscalationStep) time.Duration {
+	return time.Duration(step.AfterHours) * time.Hour
+}
+
+// escalationHasChannel returns true if a step notifies through channel
+func escalationHasChannel(step *models.EscalationStep, channel string) bool {
+	for _, c := range step.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeSlackText escapes the characters Slack treats as markup
+func escapeSlackText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+)
+
+// EventHandler reacts to a published event. Handlers run in the request that
+// made the change, so they should only queue work (webhook deliveries,
+// automation executions) and leave the rest to background jobs.
+type EventHandler func(ctx context.Context, event *models.Event) error
+
+// eventSubscription is a named handler on the event bus
+type eventSubscription struct {
+	name    string
+	handler EventHandler
+}
+
+// EventBus fans out change events to the subsystems that react to them.
+// Subscriptions are registered during setup, before events are published.
+type EventBus struct {
+	subscriptions []eventSubscription
+}
+
+// NewEventBus creates a new event bus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers a handler for every published event
+func (b *EventBus) Subscribe(name string, handler EventHandler) {
+	b.subscriptions = append(b.subscriptions, eventSubscription{name: name, handler: handler})
+}
+
+// Publish hands an event to every subscriber. A failing subscriber is logged
+// and does not keep the others from receiving the event.
+func (b *EventBus) Publish(ctx context.Context, event *models.Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	for _, sub := range b.subscriptions {
+		if err := sub.handler(ctx, event); err != nil {
+			log.Printf("⚠️  Event subscriber %s failed on %s: %v", sub.name, event.Type, err)
+		}
+	}
+}
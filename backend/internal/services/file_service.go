@@ -0,0 +1,583 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/storage"
+	"myerp-v2/internal/utils"
+)
+
+// fileType is a file extension accepted for upload: the content type files
+// are served with and the types content sniffing may detect for them
+type fileType struct {
+	contentType string
+	sniffed     []string
+}
+
+// imageFileTypes are accepted for avatars, logos and attachments
+var imageFileTypes = map[string]fileType{
+	".png":  {"image/png", []string{"image/png"}},
+	".jpg":  {"image/jpeg", []string{"image/jpeg"}},
+	".jpeg": {"image/jpeg", []string{"image/jpeg"}},
+	".gif":  {"image/gif", []string{"image/gif"}},
+	".webp": {"image/webp", []string{"image/webp"}},
+}
+
+// documentFileTypes are accepted for attachments. Office Open XML and
+// OpenDocument files sniff as zip archives, legacy Office files are not
+// recognized at all. HTML and SVG are never accepted: served from the API's
+// origin they could run scripts.
+var documentFileTypes = map[string]fileType{
+	".pdf":  {"application/pdf", []string{"application/pdf"}},
+	".txt":  {"text/plain", []string{"text/plain"}},
+	".csv":  {"text/csv", []string{"text/plain"}},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"application/zip"}},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"application/zip"}},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", []string{"application/zip"}},
+	".odt":  {"application/vnd.oasis.opendocument.text", []string{"application/zip"}},
+	".ods":  {"application/vnd.oasis.opendocument.spreadsheet", []string{"application/zip"}},
+	".doc":  {"application/msword", []string{"application/octet-stream"}},
+	".xls":  {"application/vnd.ms-excel", []string{"application/octet-stream"}},
+}
+
+// maxFileNameLength matches files.original_name
+const maxFileNameLength = 255
+
+// FileService handles uploaded and generated files; every subsystem storing
+// files goes through it. Contents are stored on the configured backend under
+// tenants/{tenant_id}/, metadata in the files table. Uploaders always see and
+// delete their own files, avatars and logos are visible to every user and
+// logos are also served without authentication; files.view
+// and files.delete cover everyone else's files. Files of categories with a
+// lifecycle in STORAGE_LIFECYCLE expire and are purged.
+type FileService struct {
+	fileRepo          repository.FileStore
+	tenantRepo        repository.TenantStore
+	backend           storage.Backend
+	permissionService PermissionManager
+	config            *config.Config
+}
+
+// NewFileService creates a new file service
+func NewFileService(
+	fileRepo repository.FileStore,
+	tenantRepo repository.TenantStore,
+	backend storage.Backend,
+	permissionService PermissionManager,
+	cfg *config.Config,
+) *FileService {
+	return &FileService{
+		fileRepo:          fileRepo,
+		tenantRepo:        tenantRepo,
+		backend:           backend,
+		permissionService: permissionService,
+		config:            cfg,
+	}
+}
+
+// MaxFileSize returns the largest file accepted in any category, in bytes
+func (s *FileService) MaxFileSize() int64 {
+	return max(s.config.Storage.MaxUploadSize, s.config.Storage.MaxAvatarSize)
+}
+
+// Upload validates and stores a file read from body. The type is taken from
+// the file name's extension and must be allowed for the category and match
+// the sniffed content.
+func (s *FileService) Upload(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, body io.Reader) (*models.File, error) {
+	var types map[string]fileType
+	var maxSize int64
+	switch upload.Category {
+	case models.FileCategoryAvatar, models.FileCategoryLogo:
+		types, maxSize = imageFileTypes, s.config.Storage.MaxAvatarSize
+	case models.FileCategoryAttachment:
+		types, maxSize = documentFileTypes, s.config.Storage.MaxUploadSize
+	default:
+		return nil, fmt.Errorf("invalid file category")
+	}
+
+	if upload.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if upload.Size > maxSize {
+		return nil, fmt.Errorf("file is too large")
+	}
+
+	name := cleanFileName(upload.OriginalName)
+	ext := strings.ToLower(path.Ext(name))
+	kind, ok := types[ext]
+	if !ok && upload.Category == models.FileCategoryAttachment {
+		kind, ok = imageFileTypes[ext]
+	}
+	if !ok {
+		return nil, fmt.Errorf("file type is not allowed")
+	}
+
+	// Sniff the first bytes, then store them followed by the rest
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+	if !sniffMatches(head, kind.sniffed) {
+		return nil, fmt.Errorf("file content does not match its type")
+	}
+
+	return s.store(ctx, tenantID, userID, upload, name, kind.contentType, io.MultiReader(bytes.NewReader(head), body))
+}
+
+// StoreGenerated stores a file the server produced for a user, such as a
+// report, usually as an artifact. Its type is trusted rather than checked
+// against the types accepted for upload.
+func (s *FileService) StoreGenerated(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, contentType string, body io.Reader) (*models.File, error) {
+	if upload.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	return s.store(ctx, tenantID, userID, upload, cleanFileName(upload.OriginalName), contentType, body)
+}
+
+// store writes a file's contents to the backend and records its metadata
+func (s *FileService) store(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, name, contentType string, body io.Reader) (*models.File, error) {
+	ext := strings.ToLower(path.Ext(name))
+	now := time.Now().UTC()
+	file := &models.File{
+		ID:             uuid.New(),
+		StorageBackend: s.backend.Name(),
+		OriginalName:   name,
+		ContentType:    contentType,
+		SizeBytes:      upload.Size,
+		Category:       upload.Category,
+		ResourceType:   upload.ResourceType,
+		ResourceID:     upload.ResourceID,
+		UploadedBy:     &userID,
+	}
+	file.StorageKey = storage.TenantKey(tenantID, upload.Category, now.Format("2006/01"), file.ID.String()+ext)
+	if retention, ok := s.config.Storage.Lifecycle[upload.Category]; ok {
+		expiresAt := now.Add(retention)
+		file.ExpiresAt = &expiresAt
+	}
+
+	hash := sha256.New()
+	contents := io.TeeReader(body, hash)
+	if err := s.backend.Put(ctx, file.StorageKey, contents, upload.Size, file.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	file.ChecksumSHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.fileRepo.Create(ctx, tenantID, file); err != nil {
+		if delErr := s.backend.Delete(ctx, file.StorageKey); delErr != nil {
+			log.Printf("⚠️  Failed to remove stored file %s of tenant %s: %v", file.ID, tenantID, delErr)
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Get retrieves a file the user may see, including deleted files
+func (s *FileService) Get(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error) {
+	file, err := s.fileRepo.FindByID(ctx, tenantID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.canView(ctx, tenantID, userID, file)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	return file, nil
+}
+
+// List lists the files the user may see: all of them with files.view,
+// otherwise their own uploads and avatars
+func (s *FileService) List(ctx context.Context, tenantID, userID uuid.UUID, filter models.FileFilter) ([]models.File, int, error) {
+	canViewAll, err := s.can(ctx, tenantID, userID, models.ActionView)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var visibleTo *uuid.UUID
+	if !canViewAll {
+		visibleTo = &userID
+	}
+
+	return s.fileRepo.List(ctx, tenantID, filter, visibleTo)
+}
+
+// Open returns a file the user may see with its contents; the caller closes
+// them
+func (s *FileService) Open(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, io.ReadCloser, error) {
+	file, err := s.Get(ctx, tenantID, userID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.IsDeleted() || file.IsExpired() {
+		return nil, nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	contents, err := s.open(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, contents, nil
+}
+
+// DownloadURL returns the authenticated URL a file is downloaded from
+func (s *FileService) DownloadURL(file *models.File) string {
+	return strings.TrimSuffix(s.config.Storage.DownloadBaseURL, "/") + "/files/" + file.ID.String() + "/download"
+}
+
+// SignedURL returns a URL downloading a file without authentication until it
+// expires. S3 serves presigned URLs itself; for local storage the API serves
+// /files/signed/{token}.
+func (s *FileService) SignedURL(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.FileURL, error) {
+	file, err := s.Get(ctx, tenantID, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDeleted() || file.IsExpired() {
+		return nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	ttl := s.config.Storage.SignedURLTTL
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	if presigner, ok := s.backend.(storage.Presigner); ok && file.StorageBackend == s.backend.Name() {
+		url, err := presigner.PresignGet(file.StorageKey, file.ContentType, file.ContentDisposition(), ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign download URL: %w", err)
+		}
+		return &models.FileURL{URL: url, ExpiresAt: expiresAt}, nil
+	}
+
+	token := s.sign(tenantID, fileID, expiresAt)
+	return &models.FileURL{
+		URL:       strings.TrimSuffix(s.config.Storage.DownloadBaseURL, "/") + "/files/signed/" + token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PublicURL returns the URL a logo is downloaded from without
+// authentication, e.g. by email clients
+func (s *FileService) PublicURL(file *models.File) string {
+	return strings.TrimSuffix(s.config.Storage.DownloadBaseURL, "/") + "/files/public/" + file.TenantID.String() + "/" + file.ID.String()
+}
+
+// OpenPublic returns a logo with its contents. Other files are never public
+// and are reported as not found.
+func (s *FileService) OpenPublic(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, io.ReadCloser, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil || !tenant.CanAccess() {
+		return nil, nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	file, err := s.fileRepo.FindByID(ctx, tenantID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.Category != models.FileCategoryLogo || file.IsDeleted() || file.IsExpired() {
+		return nil, nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	contents, err := s.open(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, contents, nil
+}
+
+// OpenSigned returns the file a signed download token grants with its
+// contents. The token names the tenant, so the request needs no tenant
+// context.
+func (s *FileService) OpenSigned(ctx context.Context, token string) (*models.File, io.ReadCloser, error) {
+	tenantID, fileID, err := s.verify(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil || !tenant.CanAccess() {
+		return nil, nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	file, err := s.fileRepo.FindByID(ctx, tenantID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.IsDeleted() || file.IsExpired() {
+		return nil, nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	contents, err := s.open(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, contents, nil
+}
+
+// Delete soft-deletes a file. It can be restored until
+// STORAGE_DELETED_RETENTION passes.
+func (s *FileService) Delete(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error) {
+	file, err := s.fileRepo.FindByID(ctx, tenantID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.IsDeleted() {
+		return nil, utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+
+	if err := s.requireDelete(ctx, tenantID, userID, file); err != nil {
+		return nil, err
+	}
+
+	if err := s.fileRepo.SoftDelete(ctx, tenantID, fileID, userID); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// DeleteResourceFiles soft-deletes the files of a category attached to a
+// resource, except those in keep. Callers check access to the resource.
+func (s *FileService) DeleteResourceFiles(ctx context.Context, tenantID, userID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID) (int, error) {
+	return s.fileRepo.SoftDeleteByResource(ctx, tenantID, category, resourceType, resourceID, keep, userID)
+}
+
+// Restore undoes a file's delete
+func (s *FileService) Restore(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error) {
+	file, err := s.fileRepo.FindByID(ctx, tenantID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.requireDelete(ctx, tenantID, userID, file); err != nil {
+		return nil, err
+	}
+	if !file.IsDeleted() {
+		return nil, fmt.Errorf("file is not deleted")
+	}
+
+	if err := s.fileRepo.Restore(ctx, tenantID, fileID); err != nil {
+		return nil, err
+	}
+
+	return s.fileRepo.FindByID(ctx, tenantID, fileID)
+}
+
+// PurgeDeleted removes files deleted longer ago than the retention and files
+// that expired, with their stored contents. It is run periodically by the
+// background job runner and returns the number of files purged.
+func (s *FileService) PurgeDeleted(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Storage.DeletedRetention)
+	return s.fileRepo.PurgeDeletedOrExpired(ctx, cutoff, func(ctx context.Context, file *models.File) error {
+		if file.StorageBackend != s.backend.Name() {
+			// Stored before the backend was switched: leave the object to
+			// whoever migrates the old storage
+			log.Printf("⚠️  Purging file %s of tenant %s stored on the %s backend, which is not configured; its contents are left in place", file.ID, file.TenantID, file.StorageBackend)
+			return nil
+		}
+
+		if err := s.backend.Delete(ctx, file.StorageKey); err != nil {
+			log.Printf("⚠️  Failed to remove stored file %s of tenant %s: %v", file.ID, file.TenantID, err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ListTenantFiles retrieves all of a tenant's files, including deleted
+// files, without checking permissions
+func (s *FileService) ListTenantFiles(ctx context.Context, tenantID uuid.UUID) ([]models.File, error) {
+	return s.fileRepo.ListAll(ctx, tenantID)
+}
+
+// RemoveTenantFiles deletes the stored contents of all of a tenant's files,
+// before the tenant itself is deleted. Their rows are left to be deleted
+// with the tenant.
+func (s *FileService) RemoveTenantFiles(ctx context.Context, tenantID uuid.UUID) error {
+	files, err := s.fileRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.StorageBackend != s.backend.Name() {
+			log.Printf("⚠️  Deleting file %s of tenant %s stored on the %s backend, which is not configured; its contents are left in place", file.ID, tenantID, file.StorageBackend)
+			continue
+		}
+
+		if err := s.backend.Delete(ctx, file.StorageKey); err != nil {
+			return fmt.Errorf("failed to remove stored file %s: %w", file.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// open opens a file's stored contents
+func (s *FileService) open(ctx context.Context, file *models.File) (io.ReadCloser, error) {
+	if file.StorageBackend != s.backend.Name() {
+		return nil, fmt.Errorf("file is not available")
+	}
+
+	contents, err := s.backend.Open(ctx, file.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, fmt.Errorf("file is not available")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return contents, nil
+}
+
+// canView reports whether the user may see and download a file. Deleted
+// avatars and logos are no longer visible to everyone.
+func (s *FileService) canView(ctx context.Context, tenantID, userID uuid.UUID, file *models.File) (bool, error) {
+	public := file.Category == models.FileCategoryAvatar || file.Category == models.FileCategoryLogo
+	if (public && !file.IsDeleted()) || isUploader(file, userID) {
+		return true, nil
+	}
+	return s.can(ctx, tenantID, userID, models.ActionView)
+}
+
+// canDelete reports whether the user may delete and restore a file
+func (s *FileService) canDelete(ctx context.Context, tenantID, userID uuid.UUID, file *models.File) (bool, error) {
+	if isUploader(file, userID) {
+		return true, nil
+	}
+	return s.can(ctx, tenantID, userID, models.ActionDelete)
+}
+
+// requireDelete fails unless the user may delete and restore a file. Users
+// who cannot even see it are told it does not exist.
+func (s *FileService) requireDelete(ctx context.Context, tenantID, userID uuid.UUID, file *models.File) error {
+	allowed, err := s.canDelete(ctx, tenantID, userID, file)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+
+	visible, err := s.canView(ctx, tenantID, userID, file)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return utils.NewNotFoundError("FILE_NOT_FOUND", "file not found")
+	}
+	return utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+}
+
+// can checks a files permission of the user
+func (s *FileService) can(ctx context.Context, tenantID, userID uuid.UUID, action string) (bool, error) {
+	return s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceFiles, action)
+}
+
+// sign builds a download token for a file, valid until expiresAt. The
+// signature binds the tenant, file and expiry.
+func (s *FileService) sign(tenantID, fileID uuid.UUID, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return tenantID.String() + "." + fileID.String() + "." + expires + "." + s.signature(tenantID, fileID, expires)
+}
+
+// signature computes the HMAC-SHA256 of a download token
+func (s *FileService) signature(tenantID, fileID uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Storage.SigningSecret))
+	mac.Write([]byte("file-download:" + tenantID.String() + ":" + fileID.String() + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a download token and returns its tenant and file
+func (s *FileService) verify(token string) (uuid.UUID, uuid.UUID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid download link")
+	}
+
+	tenantID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid download link")
+	}
+	fileID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid download link")
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid download link")
+	}
+
+	if !hmac.Equal([]byte(parts[3]), []byte(s.signature(tenantID, fileID, parts[2]))) {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid download link")
+	}
+	if time.Now().Unix() > expires {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("download link has expired")
+	}
+
+	return tenantID, fileID, nil
+}
+
+// isUploader reports whether the user uploaded a file
+func isUploader(file *models.File, userID uuid.UUID) bool {
+	return file.UploadedBy != nil && *file.UploadedBy == userID
+}
+
+// sniffMatches reports whether the sniffed type of content is one of
+// accepted, ignoring parameters such as the charset
+func sniffMatches(content []byte, accepted []string) bool {
+	sniffed, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	for _, contentType := range accepted {
+		if sniffed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFileName keeps the base name of an uploaded file's name, without
+// control characters, and shortens it to fit
+func cleanFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		ext := []rune(path.Ext(name))
+		if len(ext) > 16 {
+			ext = nil
+		}
+		name = string(runes[:maxFileNameLength-len(ext)]) + string(ext)
+	}
+	return name
+}
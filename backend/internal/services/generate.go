@@ -0,0 +1,3 @@
+package services
+
+//go:generate go run ../../cmd/genmocks -suffix Service -iface Manager
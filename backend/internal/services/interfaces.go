@@ -0,0 +1,1010 @@
+// Code generated by genmocks. DO NOT EDIT.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/utils"
+)
+
+// AccountingManager is implemented by AccountingService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AccountingManager interface {
+	ClosePeriod(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	CreateAccount(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountCreateRequest) (*models.Account, error)
+	CreateEntry(ctx context.Context, tenantID, userID uuid.UUID, req *models.JournalEntryCreateRequest) (*models.JournalEntry, error)
+	CreatePeriod(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountingPeriodCreateRequest) (*models.AccountingPeriod, error)
+	DeleteAccount(ctx context.Context, tenantID, userID, accountID uuid.UUID) error
+	DeleteEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) error
+	DescribeAccount(ctx context.Context, tenantID, accountID uuid.UUID) (*models.EntitySummary, error)
+	DescribeEntry(ctx context.Context, tenantID, entryID uuid.UUID) (*models.EntitySummary, error)
+	GetAccount(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error)
+	GetEntry(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error)
+	GetPeriod(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	ListAccounts(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error)
+	ListEntries(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.JournalEntryFilter,
+		limit, offset int,
+	) ([]models.JournalEntry, int, error)
+	ListPeriods(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error)
+	PostEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.JournalEntry, error)
+	ReopenPeriod(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	ReverseEntry(
+		ctx context.Context,
+		tenantID, userID, entryID uuid.UUID,
+		req *models.JournalEntryReverseRequest,
+	) (*models.JournalEntry, error)
+	TrialBalance(ctx context.Context, tenantID uuid.UUID, periodID *uuid.UUID, asOf *time.Time) (*models.TrialBalance, error)
+	UpdateAccount(
+		ctx context.Context,
+		tenantID, userID, accountID uuid.UUID,
+		req *models.AccountUpdateRequest,
+	) (*models.Account, error)
+	UpdateEntry(
+		ctx context.Context,
+		tenantID, userID, entryID uuid.UUID,
+		req *models.JournalEntryUpdateRequest,
+	) (*models.JournalEntry, error)
+}
+
+// AnnouncementManager is implemented by AnnouncementService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AnnouncementManager interface {
+	CreateAnnouncement(ctx context.Context, tenantID, userID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error)
+	DeleteAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID) error
+	GetAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error)
+	ListAnnouncements(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error)
+	ListForUser(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error)
+	ListReads(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error)
+	MarkRead(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error
+	PublishDue(ctx context.Context) (int, error)
+	UpdateAnnouncement(ctx context.Context, tenantID, announcementID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error)
+}
+
+// ApprovalLinkManager is implemented by ApprovalLinkService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ApprovalLinkManager interface {
+	CleanupExpired(ctx context.Context) (int, error)
+	Decide(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest, ipAddress, userAgent string) (*models.ApprovalSubject, error)
+	Preview(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest) (*models.ApprovalLinkPreview, error)
+	RegisterTarget(resourceType, resource string, describe ApprovalDescriber, decide ApprovalDecider)
+	RequestApproval(ctx context.Context, tenantID, requestedBy uuid.UUID, resourceType string, resourceID uuid.UUID) error
+}
+
+// AsyncJobManager is implemented by AsyncJobService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AsyncJobManager interface {
+	Cancel(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	CleanupFinishedJobs(ctx context.Context) (int, error)
+	Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error)
+	GetJob(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	ListJobs(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error)
+	ProcessQueue(ctx context.Context) (int, error)
+	RegisterType(jobType, resource string, run AsyncJobFunc)
+	Submit(ctx context.Context, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error)
+	Subscribe(ctx context.Context, tenantID, jobID uuid.UUID) *redis.PubSub
+}
+
+// AuditManager is implemented by AuditService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AuditManager interface {
+	Diff(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditDiff, error)
+	Export(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filters AuditFilters,
+		fn func(*models.AuditLog) error,
+	) error
+	GetActionStats(ctx context.Context, tenantID uuid.UUID, startDate, endDate time.Time) (map[string]int, error)
+	GetByID(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditLog, error)
+	GetFailedAttempts(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		userID *uuid.UUID,
+		since time.Time,
+	) ([]models.AuditLog, error)
+	GetRecentActions(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		resourceType string,
+		resourceID uuid.UUID,
+		limit int,
+	) ([]models.AuditLog, error)
+	GetSuspiciousActivity(ctx context.Context, tenantID uuid.UUID, since time.Time) ([]map[string]interface{}, error)
+	GetUserActivity(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		limit int,
+	) ([]models.AuditLog, error)
+	LogEvent(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		action, resourceType string,
+		resourceID uuid.UUID,
+		status string,
+		ipAddress, userAgent string,
+		metadata map[string]interface{},
+	) error
+	Query(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filters AuditFilters,
+		page *pagination.Request,
+	) ([]models.AuditLog, int, string, error)
+	Search(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		keyword string,
+		limit, offset int,
+	) ([]models.AuditLog, int, error)
+}
+
+// AuthManager is implemented by AuthService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AuthManager interface {
+	BeginTwoFactorSetup(ctx context.Context, twoFactorToken string) (*TwoFactorSetup, error)
+	BeginWebAuthn2FA(ctx context.Context, twoFactorToken string) (*models.WebAuthnRequestOptions, error)
+	BeginWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, email string) (*models.WebAuthnRequestOptions, error)
+	BeginWebAuthnRegistration(ctx context.Context, tenantID, userID uuid.UUID) (*models.WebAuthnCreationOptions, error)
+	ChangePassword(ctx context.Context, tenantID, userID uuid.UUID, currentPassword, newPassword string) error
+	CompleteSAML(ctx context.Context, providerSlug, relayState, samlResponse string) (string, string, error)
+	CompleteSSO(ctx context.Context, providerSlug, stateParam, code string) (string, string, error)
+	ConfirmEmailChange(ctx context.Context, tenantID, token uuid.UUID, ipAddress, userAgent string) (*models.User, error)
+	CreateSSOProvider(ctx context.Context, tenantID, userID uuid.UUID, req *models.SSOProviderCreateRequest) (*models.SSOProvider, error)
+	DeleteSSOProvider(ctx context.Context, tenantID, providerID uuid.UUID) (*models.SSOProvider, error)
+	DeleteWebAuthnCredential(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error)
+	ExchangeSSOCode(ctx context.Context, code string, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishTwoFactorSetup(ctx context.Context, req *models.TwoFactorSetupFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthn2FA(ctx context.Context, req *models.WebAuthn2FAFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, req *models.WebAuthnLoginFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthnRegistration(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error)
+	FlushSessionActivity(ctx context.Context) (int, error)
+	ForcePasswordReset(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error)
+	GetSSOProvider(ctx context.Context, tenantID, providerID uuid.UUID) (*models.SSOProvider, error)
+	GetSSOSettings(ctx context.Context, tenantID uuid.UUID) (*models.SSOSettings, error)
+	GetSessionLimit(ctx context.Context, tenantID uuid.UUID) (*models.SessionLimit, error)
+	GetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID) (*models.TwoFactorPolicy, error)
+	GetTwoFactorStatus(ctx context.Context, tenant *models.Tenant, user *models.User) (*models.TwoFactorStatus, error)
+	IssueTwoFABypassCode(ctx context.Context, tenantID, userID, issuerID uuid.UUID) (time.Time, error)
+	ListSSOLoginProviders(ctx context.Context, tenantSlug string) ([]models.SSOLoginProvider, bool, error)
+	ListWebAuthnCredentials(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	Login(ctx context.Context, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	LoginWithTenant(ctx context.Context, tenantID uuid.UUID, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	Logout(ctx context.Context, tenantID, userID uuid.UUID, accessToken string) error
+	LogoutAll(ctx context.Context, tenantID, userID uuid.UUID) error
+	RefreshToken(ctx context.Context, refreshToken string) (*models.UserLoginResponse, error)
+	RegisterTenant(ctx context.Context, req *models.TenantCreateRequest) (*models.Tenant, error)
+	RemindTwoFactorSetup(ctx context.Context) (int, error)
+	RenameWebAuthnCredential(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error)
+	RequestEmailChange(ctx context.Context, tenantID, userID uuid.UUID, newEmail, password string) (*models.User, error)
+	RequestPasswordReset(ctx context.Context, tenantID uuid.UUID, email string) error
+	RequestTwoFABypassCode(ctx context.Context, twoFactorToken string, deviceInfo utils.DeviceInfo, ipAddress string) (time.Time, error)
+	ResendTenantVerification(ctx context.Context, tenantID uuid.UUID) (*models.Tenant, error)
+	ResetPassword(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, newPassword string) error
+	SAMLMetadata(ctx context.Context, tenantSlug, providerSlug string) ([]byte, error)
+	SSOFrontendURL(query url.Values) string
+	SetPasskeyOnly(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error
+	StartSSO(ctx context.Context, tenantSlug, providerSlug string, rememberMe bool, redirect string) (string, error)
+	UpdateSSOProvider(ctx context.Context, tenantID, providerID uuid.UUID, req *models.SSOProviderUpdateRequest) (*models.SSOProvider, error)
+	UpdateSSOSettings(ctx context.Context, tenantID uuid.UUID, req *models.SSOSettingsUpdateRequest) (*models.SSOSettings, error)
+	UpdateSessionLimit(ctx context.Context, tenantID uuid.UUID, req *models.SessionLimitUpdateRequest) (*models.SessionLimit, error)
+	UpdateTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, req *models.TwoFactorPolicyUpdateRequest) (*models.TwoFactorPolicy, error)
+	ValidateSession(ctx context.Context, accessToken string) (*models.User, *models.Tenant, error)
+	Verify2FAAndLogin(ctx context.Context, twoFactorToken, code string, deviceInfo utils.DeviceInfo, ipAddress string, rememberMe bool) (*models.UserLoginResponse, error)
+	VerifyTenantEmail(ctx context.Context, token uuid.UUID) (*models.Tenant, error)
+	VerifyTwoFABypassCode(ctx context.Context, twoFactorToken, code string, rememberMe bool, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+}
+
+// AutomationManager is implemented by AutomationService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AutomationManager interface {
+	CleanupExecutions(ctx context.Context) (int, error)
+	CreateRule(ctx context.Context, tenantID, userID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error)
+	DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error
+	GetExecution(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error)
+	GetRule(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.AutomationRule, error)
+	HandleEvent(ctx context.Context, event *models.Event) error
+	ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error)
+	ProcessQueue(ctx context.Context) (int, error)
+	SetRuleEnabled(ctx context.Context, tenantID, ruleID uuid.UUID, enabled bool) (*models.AutomationRule, error)
+	UpdateRule(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error)
+}
+
+// AvatarManager is implemented by AvatarService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type AvatarManager interface {
+	MaxFileSize() int64
+	Remove(ctx context.Context, tenantID, actorID, userID uuid.UUID) (*models.User, error)
+	Upload(ctx context.Context, tenantID, actorID, userID uuid.UUID, data []byte) (*models.User, *models.UserAvatar, error)
+}
+
+// BillingManager is implemented by BillingService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type BillingManager interface {
+	ChangePlan(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingStatus, error)
+	Checkout(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingCheckout, error)
+	GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.BillingStatus, error)
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+	HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, string, error)
+	Plan(tier string) *models.Plan
+	Plans() []models.Plan
+}
+
+// BroadcastManager is implemented by BroadcastService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type BroadcastManager interface {
+	CancelBroadcast(ctx context.Context, tenantID, userID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	CreateBroadcast(ctx context.Context, tenantID, userID uuid.UUID, req *models.BroadcastCreateRequest) (*models.EmailBroadcast, error)
+	GetBroadcast(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	ListBroadcasts(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error)
+	ListRecipients(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error)
+	ProcessQueue(ctx context.Context) (int, error)
+	Unsubscribe(ctx context.Context, tenantID, token uuid.UUID) error
+}
+
+// CRMManager is implemented by CRMService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CRMManager interface {
+	AddStage(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error)
+	Assign(ctx context.Context, tenantID, userID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID) error
+	CreateContact(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMContactCreateRequest) (*models.CRMContact, error)
+	CreateCustomer(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMCustomerCreateRequest) (*models.CRMCustomer, error)
+	CreateOpportunity(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMOpportunityCreateRequest) (*models.CRMOpportunity, error)
+	CreatePipeline(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error)
+	DeleteActivity(ctx context.Context, tenantID, activityID uuid.UUID) error
+	DeleteContact(ctx context.Context, tenantID, contactID uuid.UUID) error
+	DeleteCustomer(ctx context.Context, tenantID, customerID uuid.UUID) error
+	DeleteOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) error
+	DeletePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) error
+	DeleteStage(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID) (*models.CRMPipeline, error)
+	DescribeContact(ctx context.Context, tenantID, contactID uuid.UUID) (*models.EntitySummary, error)
+	DescribeCustomer(ctx context.Context, tenantID, customerID uuid.UUID) (*models.EntitySummary, error)
+	DescribeOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.EntitySummary, error)
+	GetActivity(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error)
+	GetContact(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error)
+	GetCustomer(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error)
+	GetOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error)
+	GetPipeline(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error)
+	ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error)
+	ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error)
+	ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error)
+	ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error)
+	ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error)
+	LogActivity(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMActivityCreateRequest) (*models.CRMActivity, error)
+	MoveStage(ctx context.Context, tenantID, userID, opportunityID uuid.UUID, req *models.CRMStageChangeRequest) (*models.CRMOpportunity, error)
+	PipelineSummary(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, []models.CRMStageSummary, error)
+	UpdateActivity(ctx context.Context, tenantID, userID, activityID uuid.UUID, req *models.CRMActivityUpdateRequest) (*models.CRMActivity, error)
+	UpdateContact(ctx context.Context, tenantID, contactID uuid.UUID, req *models.CRMContactUpdateRequest) (*models.CRMContact, error)
+	UpdateCustomer(ctx context.Context, tenantID, customerID uuid.UUID, req *models.CRMCustomerUpdateRequest) (*models.CRMCustomer, error)
+	UpdateOpportunity(ctx context.Context, tenantID, opportunityID uuid.UUID, req *models.CRMOpportunityUpdateRequest) (*models.CRMOpportunity, error)
+	UpdatePipeline(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error)
+	UpdateStage(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error)
+}
+
+// CompanySettingsManager is implemented by CompanySettingsService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CompanySettingsManager interface {
+	GetSettings(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error)
+	UpdateSettings(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		req *models.CompanySettingsUpdateRequest,
+		nulls map[string]bool,
+	) (*models.CompanySettings, error)
+}
+
+// ComplianceManager is implemented by ComplianceService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ComplianceManager interface {
+	GetReport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	RequestReport(ctx context.Context, tenantID, userID uuid.UUID, params models.ComplianceReportJobParams) (*models.AsyncJob, error)
+	RunReportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error)
+}
+
+// CustomFieldManager is implemented by CustomFieldService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type CustomFieldManager interface {
+	ApplyValues(ctx context.Context, tenantID uuid.UUID, entity string, current json.RawMessage, values models.CustomFieldValues, creating bool) (json.RawMessage, error)
+	CreateField(ctx context.Context, tenantID, userID uuid.UUID, req *models.CustomFieldCreateRequest) (*models.CustomField, error)
+	DeleteField(ctx context.Context, tenantID, fieldID uuid.UUID) error
+	GetField(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error)
+	ListFields(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error)
+	ParseFilter(ctx context.Context, tenantID uuid.UUID, entity string, query url.Values) (json.RawMessage, error)
+	UpdateField(ctx context.Context, tenantID, fieldID uuid.UUID, req *models.CustomFieldUpdateRequest) (*models.CustomField, error)
+}
+
+// DashboardManager is implemented by DashboardService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DashboardManager interface {
+	GetStats(ctx context.Context, tenantID uuid.UUID) (*models.DashboardStats, error)
+}
+
+// DataQualityManager is implemented by DataQualityService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DataQualityManager interface {
+	CreateRule(ctx context.Context, tenantID, userID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error)
+	DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error
+	GetReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error)
+	GetRule(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.DataQualityRule, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error)
+	RunReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error)
+	RunScheduled(ctx context.Context) (int, error)
+	UpdateRule(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error)
+}
+
+// DeletionManager is implemented by DeletionService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type DeletionManager interface {
+	GetDeletion(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error)
+	ListDeletions(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error)
+	ProcessDue(ctx context.Context) (int, error)
+	RegisterTarget(entityType, resource string, execute DeletionExecutor)
+	Schedule(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID, label string) (*models.PendingDeletion, error)
+	Subscribe(name string, handler DeletionHandler)
+	Undo(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error)
+}
+
+// EmailQueueManager is implemented by EmailQueueService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EmailQueueManager interface {
+	Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage) error
+	EnqueueStandalone(ctx context.Context, tenantID uuid.UUID, msg *models.EmailMessage) error
+	GetStats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error)
+	HandleDeliveryEvents(ctx context.Context, events []mail.Event) error
+	ListEmails(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error)
+	ProcessQueue(ctx context.Context) (int, error)
+	RetryEmail(ctx context.Context, tenantID, userID, emailID uuid.UUID) error
+}
+
+// EmailManager is implemented by EmailService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EmailManager interface {
+	AccountSetupEmail(email, lang, firstName, companyName, creatorName string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error)
+	ApprovalLinkURL(tenantSlug, token, decision string) string
+	ApprovalRequestEmail(email, lang, firstName, companyName, title, details, approveURL, rejectURL, reviewURL string, stepUp bool, expiresAt time.Time) (*models.EmailMessage, error)
+	AutomationEmail(email, lang, companyName, subject string, content template.HTML) (*models.EmailMessage, error)
+	BroadcastEmail(email, lang, companyName, subject string, content template.HTML, unsubscribeURL string) (*models.EmailMessage, error)
+	BroadcastUnsubscribeURL(tenantSlug string, token uuid.UUID) string
+	DataQualityAlertEmail(email, lang, firstName, companyName string, alerts []models.DataQualityAlert, reportURL string) (*models.EmailMessage, error)
+	DeletionScheduledEmail(email, lang, firstName, label string, deletionID uuid.UUID, executeAt time.Time) (*models.EmailMessage, error)
+	EmailChangeEmail(email, lang, firstName, currentEmail string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error)
+	EscalationEmail(email, lang, firstName, companyName, title, details, reviewURL string, pendingSince time.Time) (*models.EmailMessage, error)
+	InvitationEmail(email, lang, companyName, inviterName string, branding *models.TenantBranding, token uuid.UUID, message string) (*models.EmailMessage, error)
+	LeaveDecisionEmail(email, lang, firstName, companyName, leaveType, period string, days float64, approved bool, deciderName, note, requestURL string) (*models.EmailMessage, error)
+	LeaveRequestEmail(email, lang, firstName, companyName, employeeName, leaveType, period string, days float64, reason, reviewURL string) (*models.EmailMessage, error)
+	NotificationDigestEmail(email, lang, firstName, companyName, digest string, notifications []models.Notification) (*models.EmailMessage, error)
+	NotificationEmail(email, lang, firstName, companyName string, n *models.Notification) (*models.EmailMessage, error)
+	PasswordResetEmail(email, lang, firstName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error)
+	Preview(name, lang string, branding *models.TenantBranding) (*models.EmailTemplatePreview, error)
+	QuotaAlertEmail(email, lang, firstName, companyName string, alerts []models.QuotaAlert, quotasURL string) (*models.EmailMessage, error)
+	ScheduledReportEmail(email, lang, firstName, companyName, name string, periodStart, periodEnd time.Time, attachment models.EmailAttachment) (*models.EmailMessage, error)
+	SendEmail(to, subject, body, text string) error
+	SendMessage(ctx context.Context, msg *models.EmailMessage) error
+	TenantDeletionConfirmEmail(email, lang, firstName, companyName string, branding *models.TenantBranding, token uuid.UUID, expiresAt time.Time, gracePeriod time.Duration) (*models.EmailMessage, error)
+	TenantDeletionScheduledEmail(email, lang, firstName, companyName string, branding *models.TenantBranding, deleteAt time.Time) (*models.EmailMessage, error)
+	TenantVerificationEmail(email, lang, companyName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error)
+	TwoFactorBypassEmail(email, lang, firstName, code, issuedBy string, expiresAt time.Time) (*models.EmailMessage, error)
+	TwoFactorSetupReminderEmail(email, lang, firstName, companyName string, deadline time.Time) (*models.EmailMessage, error)
+	WelcomeEmail(email, lang, firstName string) (*models.EmailMessage, error)
+}
+
+// EmployeeManager is implemented by EmployeeService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EmployeeManager interface {
+	Create(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.EmployeeCreateRequest) (*models.Employee, error)
+	Delete(ctx context.Context, tenantID, userID, employeeID uuid.UUID) (*models.PendingDeletion, error)
+	Describe(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.EntitySummary, error)
+	Get(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error)
+	GetByUser(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error)
+	List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error)
+	OrgChart(ctx context.Context, tenantID uuid.UUID, rootID *uuid.UUID, depth int) ([]models.OrgChartNode, error)
+	Purge(ctx context.Context, tenantID, _, employeeID uuid.UUID) error
+	Update(ctx context.Context, tenantID, employeeID uuid.UUID, req *models.EmployeeUpdateRequest) (*models.Employee, error)
+}
+
+// EntitySchemaManager is implemented by EntitySchemaService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EntitySchemaManager interface {
+	BuiltInSchemas() []models.EntitySchema
+	EntityTypes() []string
+	GetSchema(ctx context.Context, tenantID, userID uuid.UUID, entityType string) (*models.EntitySchema, error)
+	ListSchemas(ctx context.Context, tenantID, userID uuid.UUID) ([]models.EntitySchema, error)
+	RegisterType(entityType, resource string, model, input interface{}, options map[string][]string)
+}
+
+// EscalationManager is implemented by EscalationService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type EscalationManager interface {
+	CreatePolicy(ctx context.Context, tenantID, userID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error)
+	DeletePolicy(ctx context.Context, tenantID, policyID uuid.UUID) error
+	GetPolicy(ctx context.Context, tenantID, policyID uuid.UUID) (*models.EscalationPolicy, error)
+	ListEscalations(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error)
+	ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error)
+	ListPolicies(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error)
+	ProcessDue(ctx context.Context) (int, error)
+	RegisterSource(resourceType string, source EscalationSource)
+	ResourceTypes() []string
+	SetPolicyEnabled(ctx context.Context, tenantID, policyID uuid.UUID, enabled bool) (*models.EscalationPolicy, error)
+	UpdatePolicy(ctx context.Context, tenantID, policyID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error)
+}
+
+// FileManager is implemented by FileService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type FileManager interface {
+	Delete(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	DeleteResourceFiles(ctx context.Context, tenantID, userID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID) (int, error)
+	DownloadURL(file *models.File) string
+	Get(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, filter models.FileFilter) ([]models.File, int, error)
+	ListTenantFiles(ctx context.Context, tenantID uuid.UUID) ([]models.File, error)
+	MaxFileSize() int64
+	Open(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, io.ReadCloser, error)
+	OpenPublic(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, io.ReadCloser, error)
+	OpenSigned(ctx context.Context, token string) (*models.File, io.ReadCloser, error)
+	PublicURL(file *models.File) string
+	PurgeDeleted(ctx context.Context) (int, error)
+	RemoveTenantFiles(ctx context.Context, tenantID uuid.UUID) error
+	Restore(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	SignedURL(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.FileURL, error)
+	StoreGenerated(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, contentType string, body io.Reader) (*models.File, error)
+	Upload(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, body io.Reader) (*models.File, error)
+}
+
+// InvitationManager is implemented by InvitationService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type InvitationManager interface {
+	AcceptInvitation(
+		ctx context.Context,
+		token, password, firstName, lastName string,
+	) (*models.User, error)
+	CleanupExpiredInvitations(ctx context.Context) (int, error)
+	CreateInvitation(
+		ctx context.Context,
+		tenantID, invitedBy uuid.UUID,
+		email string,
+		roleIDs []uuid.UUID,
+		message string,
+	) (*models.Invitation, error)
+	GetInvitation(ctx context.Context, tenantID, invitationID uuid.UUID) (*models.Invitation, error)
+	ListInvitations(ctx context.Context, tenantID uuid.UUID, filter models.InvitationFilter, page *pagination.Request) ([]models.Invitation, int, string, error)
+	ResendInvitation(ctx context.Context, tenantID, invitationID, resendBy uuid.UUID) error
+	RevokeInvitation(ctx context.Context, tenantID, invitationID uuid.UUID) error
+}
+
+// JWTManager is implemented by JWTService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type JWTManager interface {
+	ExtractClaims(tokenString string) (*Claims, error)
+	Generate2FAToken(userID, tenantID uuid.UUID, tenantSlug, email string) (string, error)
+	GenerateAccessToken(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox, rememberMe bool) (string, int64, error)
+	GenerateRefreshToken(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox bool) (string, error)
+	GetTokenExpiry(tokenString string) (time.Time, error)
+	IsTokenExpired(tokenString string) bool
+	RefreshAccessToken(refreshToken string, rememberMe bool) (string, int64, error)
+	Validate2FAToken(tokenString string) (*Claims, error)
+	ValidateAccessToken(tokenString string) (*Claims, error)
+	ValidateRefreshToken(tokenString string) (*Claims, error)
+}
+
+// LeaveManager is implemented by LeaveService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type LeaveManager interface {
+	AdjustBalance(ctx context.Context, tenantID uuid.UUID, req *models.LeaveBalanceAdjustRequest) (*models.LeaveBalance, error)
+	Approve(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error)
+	Balances(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, year int) ([]models.LeaveBalance, error)
+	Calendar(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error)
+	Cancel(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	CreateType(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error)
+	DeleteType(ctx context.Context, tenantID, typeID uuid.UUID) error
+	Get(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	GetType(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListApprovals(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListOwn(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error)
+	Reject(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error)
+	RunAccrual(ctx context.Context) (int, error)
+	Submit(ctx context.Context, tenantID, userID uuid.UUID, req *models.LeaveRequestCreateRequest) (*models.LeaveRequest, error)
+	UpdateType(ctx context.Context, tenantID, typeID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error)
+}
+
+// MaintenanceManager is implemented by MaintenanceService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type MaintenanceManager interface {
+	CancelWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error)
+	GetWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error)
+	ScheduleWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) (*models.MaintenanceWindow, error)
+}
+
+// MeteringManager is implemented by MeteringService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type MeteringManager interface {
+	Cleanup(ctx context.Context) (int, error)
+	GetUsage(ctx context.Context, tenantID uuid.UUID, month time.Time) (*models.TenantUsageReport, error)
+	MonthToDate(ctx context.Context, tenantID uuid.UUID, metric string) (int64, error)
+	RecordAPICall(ctx context.Context, tenant *models.Tenant, userID uuid.UUID, exempt bool) (bool, int64, error)
+	RecordEmailSent(ctx context.Context, tenantID uuid.UUID)
+	RecordStorage(ctx context.Context, tenantID uuid.UUID, bytes int64)
+	Rollup(ctx context.Context) (int, error)
+}
+
+// NavigationManager is implemented by NavigationService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type NavigationManager interface {
+	AddFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.Favorite, bool, error)
+	EntityTypes() []string
+	FlushRecent(ctx context.Context) (int, error)
+	ListFavorites(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error)
+	ListRecent(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error)
+	RecordView(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	RegisterType(entityType, resource string, describe EntityDescriber)
+	RemoveFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+}
+
+// NotificationManager is implemented by NotificationService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type NotificationManager interface {
+	CleanupRead(ctx context.Context) (int, error)
+	CountUnread(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	MarkRead(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	MarkUnread(ctx context.Context, tenantID, userID, id uuid.UUID) (int, error)
+	Notify(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) error
+	SendDigests(ctx context.Context) (int, error)
+	Subscribe(ctx context.Context, tenantID, userID uuid.UUID) *redis.PubSub
+	UpdatePreferences(ctx context.Context, tenantID, userID uuid.UUID, req *models.NotificationPreferencesUpdateRequest) (*models.NotificationPreferences, error)
+}
+
+// OffboardingManager is implemented by OffboardingService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type OffboardingManager interface {
+	CancelDeletion(ctx context.Context, tenantID uuid.UUID) error
+	ConfirmDeletion(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.TenantDeletion, error)
+	GetDeletion(ctx context.Context, tenantID uuid.UUID) (*models.TenantDeletion, error)
+	GetExport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	PurgeDue(ctx context.Context) (int, error)
+	RequestDeletion(ctx context.Context, tenantID, userID uuid.UUID, password string) (*models.TenantDeletion, error)
+	RequestExport(ctx context.Context, tenantID, userID uuid.UUID) (*models.AsyncJob, error)
+	RunExportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error)
+}
+
+// PerformanceManager is implemented by PerformanceService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PerformanceManager interface {
+	Cleanup(ctx context.Context) (int, error)
+	Flush(ctx context.Context) (int, error)
+	Report(ctx context.Context, window time.Duration, sort string, limit int) (*models.PerformanceReport, error)
+	Start(ctx context.Context)
+	Stop()
+}
+
+// PermissionManager is implemented by PermissionService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PermissionManager interface {
+	CanAccessObject(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, action string) (bool, error)
+	CheckPermissions(ctx context.Context, tenantID, userID uuid.UUID, checks []PermissionCheck) ([]bool, error)
+	CheckUserRole(ctx context.Context, tenantID, userID uuid.UUID, roleName string) (bool, error)
+	ComparePermissions(ctx context.Context, tenantID, user1ID, user2ID uuid.UUID) (map[string]interface{}, error)
+	ExpireRoleAssignments(ctx context.Context) (int, error)
+	GetEffectiveRolePermissions(ctx context.Context, tenantID, roleID uuid.UUID) (*models.RoleEffectivePermissions, error)
+	GetObjectACL(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error)
+	GetPermissionStats(ctx context.Context) (map[string]interface{}, error)
+	GetPermissionsVersion(ctx context.Context, tenantID, userID uuid.UUID) (string, error)
+	GetRegistry(ctx context.Context) ([]models.PermissionModule, error)
+	GetRolePermissions(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.Permission, error)
+	GetUserPermissions(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Permission, error)
+	GetUserRoleNames(ctx context.Context, tenantID, userID uuid.UUID) ([]string, error)
+	GetUserRoles(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error)
+	GrantTemporaryRole(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) (*models.Role, error)
+	HasAllPermissions(ctx context.Context, tenantID, userID uuid.UUID, checks []PermissionCheck) (bool, error)
+	HasAnyPermission(ctx context.Context, tenantID, userID uuid.UUID, checks []PermissionCheck) (bool, error)
+	HasPermission(ctx context.Context, tenantID, userID uuid.UUID, resource, action string) (bool, error)
+	InaccessibleObjects(ctx context.Context, tenantID, userID uuid.UUID, resourceType, action string) (map[uuid.UUID]bool, error)
+	InvalidateRolePermissions(ctx context.Context, tenantID, roleID uuid.UUID) error
+	InvalidateTenantPermissions(ctx context.Context, tenantID uuid.UUID) error
+	InvalidateUserPermissions(ctx context.Context, tenantID, userID uuid.UUID) error
+	IsAdmin(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	IsOwner(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	ListAllPermissions(ctx context.Context) ([]models.Permission, error)
+	ListPermissionsByCategory(ctx context.Context) ([]models.PermissionGroup, error)
+	RevokeTemporaryRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+	SearchPermissions(ctx context.Context, searchTerm string) ([]models.Permission, error)
+	SetObjectACL(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, req *models.ObjectACLUpdateRequest) (*models.ObjectACL, error)
+	ValidateParentRole(ctx context.Context, tenantID, roleID, parentID uuid.UUID) (*models.Role, error)
+	ValidatePermissionIDs(ctx context.Context, permissionIDs []uuid.UUID) (bool, []uuid.UUID, error)
+}
+
+// PersonalDataManager is implemented by PersonalDataService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PersonalDataManager interface {
+	Export(ctx context.Context, tenantID, userID uuid.UUID) (*models.PersonalDataExport, error)
+	RequestErasure(ctx context.Context, tenantID, userID uuid.UUID, password string) error
+}
+
+// PlatformManager is implemented by PlatformService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PlatformManager interface {
+	ActivateTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID) (*models.Tenant, error)
+	ForcePasswordReset(ctx context.Context, admin *models.User, tenantID uuid.UUID, email string) error
+	GetStats(ctx context.Context) (*models.PlatformStats, error)
+	GetTenantHealth(ctx context.Context, tenantID uuid.UUID) (*models.PlatformTenantHealth, error)
+	IsAdmin(ctx context.Context, user *models.User) (bool, error)
+	ListTenants(ctx context.Context, filter models.PlatformTenantFilter, page, pageSize int) ([]models.Tenant, int, error)
+	ResendVerification(ctx context.Context, admin *models.User, tenantID uuid.UUID) error
+	SuspendTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID, reason string) (*models.Tenant, error)
+}
+
+// PurchaseOrderManager is implemented by PurchaseOrderService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type PurchaseOrderManager interface {
+	ApproveSupplierInvoice(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, force bool) (*models.SupplierInvoice, error)
+	CancelOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	CloseOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	CreateOrder(ctx context.Context, tenantID, userID uuid.UUID, req *models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error)
+	CreateSupplier(ctx context.Context, tenantID, userID uuid.UUID, req *models.SupplierCreateRequest) (*models.Supplier, []models.SupplierDuplicate, error)
+	DecideInvoiceApproval(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, decision string) error
+	DeleteOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PendingDeletion, error)
+	DeleteSupplier(ctx context.Context, tenantID, userID, supplierID uuid.UUID) error
+	DescribeInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.EntitySummary, error)
+	DescribeInvoiceApproval(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.ApprovalSubject, error)
+	DescribeOrder(ctx context.Context, tenantID, poID uuid.UUID) (*models.EntitySummary, error)
+	DescribeSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.EntitySummary, error)
+	FindDuplicatesOfSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierDuplicate, error)
+	FindSupplierDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery) ([]models.SupplierDuplicate, error)
+	GetOrder(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	GetSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error)
+	GetSupplierInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	ListOrders(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		supplierID *uuid.UUID,
+		status string,
+		limit, offset int,
+	) ([]models.PurchaseOrder, int, error)
+	ListPendingInvoiceApprovals(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.EscalationItem, error)
+	ListReceipts(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error)
+	ListSupplierInvoices(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		poID *uuid.UUID,
+		matchStatus, status string,
+		limit, offset int,
+	) ([]models.SupplierInvoice, int, error)
+	ListSupplierMerges(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error)
+	ListSuppliers(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error)
+	MergeSuppliers(ctx context.Context, tenantID, userID, targetID, sourceID uuid.UUID) (*models.Supplier, *models.SupplierMerge, error)
+	PurgeOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) error
+	ReceiveGoods(
+		ctx context.Context,
+		tenantID, userID, poID uuid.UUID,
+		req *models.GoodsReceiptCreateRequest,
+	) (*models.GoodsReceipt, error)
+	RecordSupplierInvoice(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		req *models.SupplierInvoiceCreateRequest,
+	) (*models.SupplierInvoice, error)
+	RejectSupplierInvoice(ctx context.Context, tenantID, userID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	SendOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	UpdateOrder(
+		ctx context.Context,
+		tenantID, userID, poID uuid.UUID,
+		req *models.PurchaseOrderUpdateRequest,
+	) (*models.PurchaseOrder, error)
+	UpdateSupplier(
+		ctx context.Context,
+		tenantID, userID, supplierID uuid.UUID,
+		req *models.SupplierUpdateRequest,
+	) (*models.Supplier, error)
+}
+
+// QueueManager is implemented by QueueService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type QueueManager interface {
+	Gauges(ctx context.Context) ([]metrics.QueueGauge, error)
+	Monitor(ctx context.Context) (int, error)
+	Stats(ctx context.Context) ([]models.QueueStats, error)
+}
+
+// QuotaManager is implemented by QuotaService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type QuotaManager interface {
+	AcknowledgeAlert(ctx context.Context, tenantID, alertID, userID uuid.UUID) (*models.QuotaAlert, error)
+	CheckPlanFits(ctx context.Context, tenant *models.Tenant, tier string) error
+	CheckScheduled(ctx context.Context) (int, error)
+	CheckUserQuota(ctx context.Context, tenantID uuid.UUID, adding int) error
+	GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.QuotaStatus, error)
+}
+
+// SIEMManager is implemented by SIEMService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SIEMManager interface {
+	CreateForwarder(ctx context.Context, tenantID, userID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error)
+	DeleteForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID) error
+	ForwardDue(ctx context.Context) (int, error)
+	GetForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error)
+	ListForwarders(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error)
+	TestForwarder(ctx context.Context, tenantID, userID, forwarderID uuid.UUID) (*models.SIEMForwarderTest, error)
+	UpdateForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error)
+}
+
+// SalesManager is implemented by SalesService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SalesManager interface {
+	ChangeStatus(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		docID uuid.UUID,
+		status string,
+	) (*models.SalesDocument, error)
+	ConvertQuoteToOrder(ctx context.Context, tenantID, userID, quoteID uuid.UUID) (*models.SalesDocument, error)
+	CreateDocument(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		req *models.SalesDocumentCreateRequest,
+	) (*models.SalesDocument, error)
+	DeleteDocument(ctx context.Context, tenantID, userID uuid.UUID, documentType string, docID uuid.UUID) (*models.PendingDeletion, error)
+	DescribeDocument(ctx context.Context, tenantID, docID uuid.UUID) (*models.EntitySummary, error)
+	GetDocument(ctx context.Context, tenantID uuid.UUID, documentType string, docID uuid.UUID) (*models.SalesDocument, error)
+	InvoiceOrder(ctx context.Context, tenantID, userID, orderID uuid.UUID) (*models.SalesDocument, error)
+	ListDocuments(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.SalesDocumentFilter,
+		limit, offset int,
+	) ([]models.SalesDocument, int, error)
+	PurgeDocument(ctx context.Context, tenantID, userID, docID uuid.UUID) error
+	UpdateDocument(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		docID uuid.UUID,
+		req *models.SalesDocumentUpdateRequest,
+	) (*models.SalesDocument, error)
+}
+
+// SandboxManager is implemented by SandboxService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SandboxManager interface {
+	CreateSandbox(ctx context.Context, tenantID, userID uuid.UUID, req *models.SandboxCreateRequest) (*models.TenantSandbox, error)
+	DeleteSandbox(ctx context.Context, tenantID, userID, sandboxID uuid.UUID) error
+	ExpireSandboxes(ctx context.Context) (int, error)
+	GetSandbox(ctx context.Context, tenantID, sandboxID uuid.UUID) (*models.TenantSandbox, error)
+	ListSandboxes(ctx context.Context, tenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error)
+	ProcessPending(ctx context.Context) (int, error)
+	RemoveTenantSandboxes(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// ScheduledReportManager is implemented by ScheduledReportService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type ScheduledReportManager interface {
+	CreateReport(ctx context.Context, tenantID, userID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error)
+	DeleteReport(ctx context.Context, tenantID, reportID uuid.UUID) error
+	GetReport(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error)
+	ListReports(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error)
+	RunDue(ctx context.Context) (int, error)
+	RunReport(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReportRun, error)
+	UpdateReport(ctx context.Context, tenantID, reportID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error)
+}
+
+// SearchManager is implemented by SearchService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SearchManager interface {
+	Search(ctx context.Context, tenantID, userID uuid.UUID, query, language string, types []string, limit int) (map[string][]models.SearchResult, error)
+}
+
+// SessionManager is implemented by SessionService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type SessionManager interface {
+	CleanupExpiredSessions(ctx context.Context) (int, error)
+	GetRecentLogins(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]models.Session, error)
+	GetSessionByToken(ctx context.Context, tokenHash string) (*models.Session, error)
+	GetSessionStats(ctx context.Context, tenantID, userID uuid.UUID) (map[string]interface{}, error)
+	ListUserSessions(ctx context.Context, tenantID, userID uuid.UUID, currentTokenHash string) ([]SessionInfo, error)
+	RenameSession(ctx context.Context, tenantID, userID, sessionID uuid.UUID, name string) (*models.Session, error)
+	RevokeAllSessions(ctx context.Context, tenantID, userID uuid.UUID, exceptTokenHash string) (int, error)
+	RevokeAllUserSessions(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	RevokeSession(ctx context.Context, tenantID, userID, sessionID uuid.UUID) error
+	UpdateSessionActivity(ctx context.Context, tenantID, sessionID uuid.UUID) error
+}
+
+// TenantDomainManager is implemented by TenantDomainService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TenantDomainManager interface {
+	AddDomain(ctx context.Context, tenantID, userID uuid.UUID, name string) (*models.TenantDomain, error)
+	CanonicalHost(host string, tenant *models.Tenant) (string, bool)
+	ChangeSlug(ctx context.Context, tenantID, userID uuid.UUID, slug string) (*models.TenantSlugChange, error)
+	CleanupSlugHistory(ctx context.Context) (int, error)
+	GetDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	IsTenantOrigin(ctx context.Context, origin string) bool
+	ListDomains(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error)
+	RemoveDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	ResolveHost(ctx context.Context, host string) (*models.Tenant, error)
+	ResolveSlug(ctx context.Context, slug string) (*models.Tenant, error)
+	VerifyDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+}
+
+// TenantSettingsManager is implemented by TenantSettingsService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TenantSettingsManager interface {
+	ApplyPatch(current *models.TenantSettings, patch map[string]json.RawMessage) (*models.TenantSettings, error)
+	GetSettings(ctx context.Context, tenantID uuid.UUID) (*models.TenantSettings, error)
+	MaxLogoSize() int64
+	RemoveLogo(ctx context.Context, tenantID, actorID uuid.UUID) (*models.TenantSettings, error)
+	SaveSettings(ctx context.Context, tenantID uuid.UUID, version string, settings *models.TenantSettings) (*models.TenantSettings, error)
+	UploadLogo(ctx context.Context, tenantID, actorID uuid.UUID, name string, data []byte) (*models.TenantSettings, error)
+}
+
+// TimesheetManager is implemented by TimesheetService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TimesheetManager interface {
+	AddEntry(ctx context.Context, tenantID, userID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error)
+	Approve(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	CreateProject(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error)
+	CreateTask(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error)
+	DeleteEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.TimesheetEntry, error)
+	DeleteProject(ctx context.Context, tenantID, projectID uuid.UUID) error
+	DeleteTask(ctx context.Context, tenantID, projectID, taskID uuid.UUID) error
+	EmployeeReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error)
+	Get(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	GetProject(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error)
+	List(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error)
+	ListApprovals(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.Timesheet, int, error)
+	ListOwn(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error)
+	ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error)
+	ProjectReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error)
+	Reject(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	Reopen(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	Submit(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	UpdateEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error)
+	UpdateProject(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error)
+	UpdateTask(ctx context.Context, tenantID, projectID, taskID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error)
+	Week(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, day time.Time) (*models.Timesheet, error)
+}
+
+// TwoFactorManager is implemented by TwoFactorService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type TwoFactorManager interface {
+	DisableTwoFactor(ctx context.Context, tenantID, userID uuid.UUID) error
+	EnableTwoFactor(ctx context.Context, tenantID, userID uuid.UUID, secret string, verificationCode string, backupCodes []string) error
+	GenerateSecret(ctx context.Context, email, accountName string) (*TwoFactorSetup, error)
+	GetRemainingBackupCodes(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	IsDeviceTrusted(ctx context.Context, tenantID, userID uuid.UUID, deviceFingerprint, deviceToken string) bool
+	RegenerateBackupCodes(ctx context.Context, tenantID, userID uuid.UUID) ([]string, error)
+	RememberDevice(ctx context.Context, tenantID, userID uuid.UUID, deviceFingerprint string) (string, error)
+	VerifyBackupCode(ctx context.Context, tenantID, userID uuid.UUID, code string) (bool, error)
+	VerifyTOTP(ctx context.Context, tenantID, userID uuid.UUID, code string) (bool, error)
+}
+
+// UsageManager is implemented by UsageService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type UsageManager interface {
+	Cleanup(ctx context.Context) (int, error)
+	Export(ctx context.Context) (int, error)
+	Flush(ctx context.Context) (int, error)
+	GetPrivacySettings(ctx context.Context, tenantID uuid.UUID) (*models.PrivacySettings, error)
+	Record(tenantID, userID uuid.UUID, endpoint string)
+	Start(ctx context.Context)
+	Stop()
+	TenantRef(tenantID uuid.UUID) string
+	UpdatePrivacySettings(ctx context.Context, tenantID uuid.UUID, req *models.PrivacySettingsUpdateRequest) (*models.PrivacySettings, error)
+}
+
+// UserImportManager is implemented by UserImportService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type UserImportManager interface {
+	GetImport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	Import(ctx context.Context, tenantID, userID uuid.UUID, fileName string, rows []models.UserImportRow, dryRun bool) (*models.UserImportReport, *models.AsyncJob, error)
+	MaxFileSize() int64
+	ParseFile(fileName string, data []byte) ([]models.UserImportRow, error)
+	RunImportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error)
+}
+
+// WatchManager is implemented by WatchService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type WatchManager interface {
+	EntityTypes() []string
+	GetWatchers(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatchers, error)
+	HandleDeletion(ctx context.Context, deletion *models.PendingDeletion) error
+	HandleEvent(ctx context.Context, event *models.Event) error
+	ListWatches(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error)
+	RegisterType(entityType, resource, assigneeField string, describe EntityDescriber)
+	Unwatch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	Watch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatch, bool, error)
+}
+
+// WebhookManager is implemented by WebhookService. Depend on it rather than
+// on the struct, to substitute a mock in tests.
+type WebhookManager interface {
+	CleanupDeliveries(ctx context.Context) (int, error)
+	CreateWebhook(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebhookCreateRequest) (*models.Webhook, string, error)
+	DeleteWebhook(ctx context.Context, tenantID, webhookID uuid.UUID) error
+	DeliverTo(ctx context.Context, tenantID, webhookID uuid.UUID, event *models.Event) (*models.WebhookDelivery, error)
+	Dispatch(ctx context.Context, event *models.Event) error
+	GetDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	GetWebhook(ctx context.Context, tenantID, webhookID uuid.UUID) (*models.Webhook, error)
+	ListDeliveries(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	ListWebhooks(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error)
+	PostJSON(ctx context.Context, rawURL string, payload interface{}) error
+	ProcessQueue(ctx context.Context) (int, error)
+	RetryDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error
+	RotateSecret(ctx context.Context, tenantID, webhookID uuid.UUID) (string, error)
+	SendTestEvent(ctx context.Context, tenantID, userID, webhookID uuid.UUID) (*models.WebhookDelivery, error)
+	UpdateWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, req *models.WebhookUpdateRequest) (*models.Webhook, error)
+	ValidateURL(rawURL string) error
+}
+
+var (
+	_ AccountingManager      = (*AccountingService)(nil)
+	_ AnnouncementManager    = (*AnnouncementService)(nil)
+	_ ApprovalLinkManager    = (*ApprovalLinkService)(nil)
+	_ AsyncJobManager        = (*AsyncJobService)(nil)
+	_ AuditManager           = (*AuditService)(nil)
+	_ AuthManager            = (*AuthService)(nil)
+	_ AutomationManager      = (*AutomationService)(nil)
+	_ AvatarManager          = (*AvatarService)(nil)
+	_ BillingManager         = (*BillingService)(nil)
+	_ BroadcastManager       = (*BroadcastService)(nil)
+	_ CRMManager             = (*CRMService)(nil)
+	_ CompanySettingsManager = (*CompanySettingsService)(nil)
+	_ ComplianceManager      = (*ComplianceService)(nil)
+	_ CustomFieldManager     = (*CustomFieldService)(nil)
+	_ DashboardManager       = (*DashboardService)(nil)
+	_ DataQualityManager     = (*DataQualityService)(nil)
+	_ DeletionManager        = (*DeletionService)(nil)
+	_ EmailQueueManager      = (*EmailQueueService)(nil)
+	_ EmailManager           = (*EmailService)(nil)
+	_ EmployeeManager        = (*EmployeeService)(nil)
+	_ EntitySchemaManager    = (*EntitySchemaService)(nil)
+	_ EscalationManager      = (*EscalationService)(nil)
+	_ FileManager            = (*FileService)(nil)
+	_ InvitationManager      = (*InvitationService)(nil)
+	_ JWTManager             = (*JWTService)(nil)
+	_ LeaveManager           = (*LeaveService)(nil)
+	_ MaintenanceManager     = (*MaintenanceService)(nil)
+	_ MeteringManager        = (*MeteringService)(nil)
+	_ NavigationManager      = (*NavigationService)(nil)
+	_ NotificationManager    = (*NotificationService)(nil)
+	_ OffboardingManager     = (*OffboardingService)(nil)
+	_ PerformanceManager     = (*PerformanceService)(nil)
+	_ PermissionManager      = (*PermissionService)(nil)
+	_ PersonalDataManager    = (*PersonalDataService)(nil)
+	_ PlatformManager        = (*PlatformService)(nil)
+	_ PurchaseOrderManager   = (*PurchaseOrderService)(nil)
+	_ QueueManager           = (*QueueService)(nil)
+	_ QuotaManager           = (*QuotaService)(nil)
+	_ SIEMManager            = (*SIEMService)(nil)
+	_ SalesManager           = (*SalesService)(nil)
+	_ SandboxManager         = (*SandboxService)(nil)
+	_ ScheduledReportManager = (*ScheduledReportService)(nil)
+	_ SearchManager          = (*SearchService)(nil)
+	_ SessionManager         = (*SessionService)(nil)
+	_ TenantDomainManager    = (*TenantDomainService)(nil)
+	_ TenantSettingsManager  = (*TenantSettingsService)(nil)
+	_ TimesheetManager       = (*TimesheetService)(nil)
+	_ TwoFactorManager       = (*TwoFactorService)(nil)
+	_ UsageManager           = (*UsageService)(nil)
+	_ UserImportManager      = (*UserImportService)(nil)
+	_ WatchManager           = (*WatchService)(nil)
+	_ WebhookManager         = (*WebhookService)(nil)
+)
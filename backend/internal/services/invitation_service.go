@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,7 +11,9 @@ import (
 	"github.com/lib/pq"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
 	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
 )
 
 const (
@@ -20,23 +23,35 @@ const (
 // InvitationService handles team invitation operations
 type InvitationService struct {
 	db           *sqlx.DB
-	userRepo     *repository.UserRepository
-	userRoleRepo *repository.UserRoleRepository
-	emailService *EmailService
+	txManager    *database.TxManager
+	userRepo     repository.UserStore
+	userRoleRepo repository.UserRoleStore
+	emailService EmailManager
+	emailQueue   EmailQueueManager
+	quotaService QuotaManager
+	notifier     NotificationManager
 }
 
 // NewInvitationService creates a new invitation service
 func NewInvitationService(
 	db *sqlx.DB,
-	userRepo *repository.UserRepository,
-	userRoleRepo *repository.UserRoleRepository,
-	emailService *EmailService,
+	txManager *database.TxManager,
+	userRepo repository.UserStore,
+	userRoleRepo repository.UserRoleStore,
+	emailService EmailManager,
+	emailQueue EmailQueueManager,
+	quotaService QuotaManager,
+	notifier NotificationManager,
 ) *InvitationService {
 	return &InvitationService{
 		db:           db,
+		txManager:    txManager,
 		userRepo:     userRepo,
 		userRoleRepo: userRoleRepo,
 		emailService: emailService,
+		emailQueue:   emailQueue,
+		quotaService: quotaService,
+		notifier:     notifier,
 	}
 }
 
@@ -57,7 +72,11 @@ func (s *InvitationService) CreateInvitation(
 	// Check if user already exists
 	existingUser, err := s.userRepo.FindByEmail(ctx, tenantID, email)
 	if err == nil && existingUser != nil {
-		return nil, fmt.Errorf("user with this email already exists")
+		return nil, utils.NewConflictError("USER_EMAIL_EXISTS", "user with this email already exists")
+	}
+
+	if err := s.quotaService.CheckUserQuota(ctx, tenantID, 1); err != nil {
+		return nil, err
 	}
 
 	// Check if there's already a pending invitation
@@ -106,36 +125,18 @@ func (s *InvitationService) CreateInvitation(
 		return nil, fmt.Errorf("failed to create invitation: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
+	// Queue the invitation email in the same transaction
+	msg, err := s.invitationEmail(ctx, tenantID, invitedBy, email, invitation.Token, message)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emailQueue.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
 		return nil, err
 	}
 
-	// Send invitation email (async - don't fail if email fails)
-	go func() {
-		ctx := context.Background()
-
-		// Get tenant company name
-		var companyName string
-		_ = s.db.QueryRowContext(ctx, "SELECT company_name FROM tenants WHERE id = $1", tenantID).Scan(&companyName)
-		if companyName == "" {
-			companyName = "MyERP"
-		}
-
-		// Get inviter info
-		inviter, _ := s.userRepo.FindByID(ctx, tenantID, invitedBy)
-		inviterName := "Team member"
-		if inviter != nil {
-			inviterName = fmt.Sprintf("%s %s", inviter.FirstName, inviter.LastName)
-		}
-
-		_ = s.emailService.SendInvitationEmail(
-			email,
-			companyName,
-			inviterName,
-			invitation.Token,
-			message,
-		)
-	}()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return invitation, nil
 }
@@ -164,12 +165,12 @@ func (s *InvitationService) AcceptInvitation(
 
 	err = tx.GetContext(ctx, &invitation, query, token)
 	if err != nil {
-		return nil, fmt.Errorf("invitation not found")
+		return nil, utils.NewNotFoundError("INVITATION_NOT_FOUND", "invitation not found")
 	}
 
 	// Validate invitation status
 	if invitation.Status != models.InvitationStatusPending {
-		return nil, fmt.Errorf("invitation has already been %s", invitation.Status)
+		return nil, utils.NewBadRequestError("INVITATION_ALREADY_PROCESSED", fmt.Sprintf("invitation has already been %s", invitation.Status))
 	}
 
 	// Check expiry
@@ -177,7 +178,7 @@ func (s *InvitationService) AcceptInvitation(
 		// Mark as expired
 		updateQuery := `UPDATE invitations SET status = 'expired' WHERE id = $1`
 		_, _ = tx.ExecContext(ctx, updateQuery, invitation.ID)
-		return nil, fmt.Errorf("invitation has expired")
+		return nil, utils.NewBadRequestError("INVITATION_EXPIRED", "invitation has expired")
 	}
 
 	// Create user account (switch to tenant context)
@@ -185,6 +186,11 @@ func (s *InvitationService) AcceptInvitation(
 		return nil, err
 	}
 
+	// The tenant may have reached its user limit since the invitation was sent
+	if err := s.quotaService.CheckUserQuota(ctx, invitation.TenantID, 1); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	passwordHash, err := hashPassword(password, 10)
 	if err != nil {
@@ -203,44 +209,65 @@ func (s *InvitationService) AcceptInvitation(
 		Preferences:  []byte("{}"),
 	}
 
-	err = s.userRepo.Create(ctx, invitation.TenantID, user)
+	msg, err := s.emailService.WelcomeEmail(user.Email, user.Language, user.FirstName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, fmt.Errorf("failed to render welcome email: %w", err)
 	}
 
-	// Assign roles
-	err = s.userRoleRepo.AssignRoles(ctx, invitation.TenantID, user.ID, invitation.RoleIDs, invitation.InvitedBy)
-	if err != nil {
-		return nil, fmt.Errorf("failed to assign roles: %w", err)
-	}
+	// The user, their roles, the acceptance and the welcome email are created
+	// together, or not at all
+	err = s.txManager.InTenantTx(ctx, invitation.TenantID, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, invitation.TenantID, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	// Mark invitation as accepted
-	txAccept, err := database.WithBypassRLS(ctx, s.db)
-	if err != nil {
-		return nil, err
-	}
-	defer txAccept.Rollback()
+		// Assign roles
+		if err := s.userRoleRepo.AssignRoles(ctx, invitation.TenantID, user.ID, invitation.RoleIDs, invitation.InvitedBy); err != nil {
+			return fmt.Errorf("failed to assign roles: %w", err)
+		}
 
-	updateQuery := `
-		UPDATE invitations
-		SET status = 'accepted',
-		    accepted_at = NOW()
-		WHERE id = $1
-	`
-	_, err = txAccept.ExecContext(ctx, updateQuery, invitation.ID)
+		tx, err := database.WithTenantContext(ctx, s.db, invitation.TenantID)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		// Mark invitation as accepted, unless accepted concurrently
+		updateQuery := `
+			UPDATE invitations
+			SET status = 'accepted',
+			    accepted_at = NOW()
+			WHERE id = $1 AND status = 'pending'
+		`
+		result, err := tx.ExecContext(ctx, updateQuery, invitation.ID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return utils.NewBadRequestError("INVITATION_ALREADY_PROCESSED", "invitation has already been accepted")
+		}
+
+		// Queue welcome email together with the acceptance
+		if err := s.emailQueue.Enqueue(ctx, tx.Tx, invitation.TenantID, msg); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := txAccept.Commit(); err != nil {
-		return nil, err
+	if err := s.notifier.Notify(ctx, invitation.TenantID, []uuid.UUID{invitation.InvitedBy}, &models.NotificationRequest{
+		Type:  models.NotificationTypeInvitationAccepted,
+		Title: fmt.Sprintf("%s accepted your invitation", user.FullName()),
+		Body:  fmt.Sprintf("%s has joined the team.", user.Email),
+		Link:  fmt.Sprintf("/users/%s", user.ID),
+		Data:  map[string]interface{}{"invitation_id": invitation.ID, "user_id": user.ID},
+	}); err != nil {
+		log.Printf("⚠️  Failed to notify user %s of accepted invitation: %v", invitation.InvitedBy, err)
 	}
 
-	// Send welcome email (async)
-	go func() {
-		_ = s.emailService.SendWelcomeEmail(user.Email, user.FirstName)
-	}()
-
 	return user, nil
 }
 
@@ -265,58 +292,71 @@ func (s *InvitationService) RevokeInvitation(ctx context.Context, tenantID, invi
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("invitation not found or already processed")
+		return utils.NewNotFoundError("INVITATION_NOT_FOUND", "invitation not found or already processed")
 	}
 
 	return tx.Commit()
 }
 
-// ListInvitations lists all invitations for a tenant
-func (s *InvitationService) ListInvitations(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Invitation, int, error) {
+// InvitationListSpec lists the fields invitations can be sorted by
+var InvitationListSpec = pagination.Spec{
+	Columns: map[string]string{
+		"invited_at": "invited_at",
+		"expires_at": "expires_at",
+		"email":      "email",
+	},
+	Default:  pagination.Sort{Field: "invited_at", Desc: true},
+	IDColumn: "id",
+}
+
+// ListInvitations lists a page of a tenant's invitations matching filter,
+// with their total count and the cursor to the next page
+func (s *InvitationService) ListInvitations(ctx context.Context, tenantID uuid.UUID, filter models.InvitationFilter, page *pagination.Request) ([]models.Invitation, int, string, error) {
 	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 	defer tx.Rollback()
 
-	// Build query
-	baseQuery := `
-		FROM invitations
-		WHERE 1=1
-	`
-	var args []interface{}
-	argIndex := 1
-
-	if status != "" {
-		baseQuery += fmt.Sprintf(" AND status = $%d", argIndex)
-		args = append(args, status)
-		argIndex++
-	}
+	where := &pagination.Where{}
+	where.Equal("status", filter.Status)
+	where.EqualID("invited_by", filter.InvitedBy)
+	where.Range("invited_at", filter.InvitedFrom, filter.InvitedTo)
 
 	// Get total count
 	var totalCount int
-	countQuery := "SELECT COUNT(*) " + baseQuery
-	err = tx.GetContext(ctx, &totalCount, countQuery, args...)
+	err = tx.GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM invitations "+where.SQL(), where.Args()...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
 	// Get invitations
+	page.Apply(where)
 	selectQuery := `
 		SELECT
 			id, tenant_id, email, token, role_ids, status,
 			message, invited_by, invited_at, accepted_at, expires_at
-	` + baseQuery + ` ORDER BY invited_at DESC LIMIT $` + fmt.Sprintf("%d", argIndex) + ` OFFSET $` + fmt.Sprintf("%d", argIndex+1)
-
-	args = append(args, limit, offset)
+		FROM invitations
+	` + where.SQL() + ` ` + page.OrderBy() + ` ` + page.Limit(where)
 
 	var invitations []models.Invitation
-	err = tx.SelectContext(ctx, &invitations, selectQuery, args...)
+	err = tx.SelectContext(ctx, &invitations, selectQuery, where.Args()...)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	return invitations, totalCount, tx.Commit()
+	invitations, nextCursor := pagination.Cut(page, invitations, func(invitation *models.Invitation, field string) (interface{}, uuid.UUID) {
+		switch field {
+		case "expires_at":
+			return invitation.ExpiresAt, invitation.ID
+		case "email":
+			return invitation.Email, invitation.ID
+		default:
+			return invitation.InvitedAt, invitation.ID
+		}
+	})
+
+	return invitations, totalCount, nextCursor, tx.Commit()
 }
 
 // GetInvitation retrieves a single invitation
@@ -339,7 +379,7 @@ func (s *InvitationService) GetInvitation(ctx context.Context, tenantID, invitat
 
 	err = tx.GetContext(ctx, &invitation, query, invitationID)
 	if err != nil {
-		return nil, fmt.Errorf("invitation not found")
+		return nil, utils.NewNotFoundError("INVITATION_NOT_FOUND", "invitation not found")
 	}
 
 	return &invitation, tx.Commit()
@@ -353,46 +393,72 @@ func (s *InvitationService) ResendInvitation(ctx context.Context, tenantID, invi
 	}
 
 	if invitation.Status != models.InvitationStatusPending {
-		return fmt.Errorf("can only resend pending invitations")
+		return utils.NewBadRequestError("INVITATION_NOT_PENDING", "can only resend pending invitations")
 	}
 
 	if time.Now().After(invitation.ExpiresAt) {
-		return fmt.Errorf("invitation has expired")
+		return utils.NewBadRequestError("INVITATION_EXPIRED", "invitation has expired")
+	}
+
+	message := ""
+	if invitation.Message != nil {
+		message = *invitation.Message
 	}
 
-	// Get tenant company name
+	// Queue invitation email
+	msg, err := s.invitationEmail(ctx, tenantID, resendBy, invitation.Email, invitation.Token, message)
+	if err != nil {
+		return err
+	}
+	return s.emailQueue.EnqueueStandalone(ctx, tenantID, msg)
+}
+
+// invitationEmail renders the invitation email with the tenant and inviter
+// names, in the tenant's branding
+func (s *InvitationService) invitationEmail(ctx context.Context, tenantID, inviterID uuid.UUID, email string, token uuid.UUID, message string) (*models.EmailMessage, error) {
+	// Get tenant company name and branding
 	var companyName string
-	_ = s.db.QueryRowContext(ctx, "SELECT company_name FROM tenants WHERE id = $1", tenantID).Scan(&companyName)
+	var settings []byte
+	_ = s.db.QueryRowContext(ctx, "SELECT company_name, settings FROM tenants WHERE id = $1", tenantID).Scan(&companyName, &settings)
+	tenant := &models.Tenant{Settings: settings}
 	if companyName == "" {
 		companyName = "MyERP"
 	}
 
 	// Get inviter info
-	inviter, _ := s.userRepo.FindByID(ctx, tenantID, resendBy)
+	inviter, _ := s.userRepo.FindByID(ctx, tenantID, inviterID)
 	inviterName := "Team member"
 	if inviter != nil {
 		inviterName = fmt.Sprintf("%s %s", inviter.FirstName, inviter.LastName)
 	}
 
-	message := ""
-	if invitation.Message != nil {
-		message = *invitation.Message
+	msg, err := s.emailService.InvitationEmail(email, tenant.Locale().Language, companyName, inviterName, tenant.Branding(), token, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render invitation email: %w", err)
 	}
 
-	// Send invitation email
-	return s.emailService.SendInvitationEmail(
-		invitation.Email,
-		companyName,
-		inviterName,
-		invitation.Token,
-		message,
-	)
+	return msg, nil
 }
 
-// CleanupExpiredInvitations marks expired invitations as expired (should be run periodically)
+// CleanupExpiredInvitations marks expired invitations as expired in every data
+// region (should be run periodically, see internal/jobs)
 func (s *InvitationService) CleanupExpiredInvitations(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		expired, err := s.cleanupExpiredInvitationsIn(ctx, db)
+		if err != nil {
+			return total, err
+		}
+		total += expired
+	}
+
+	return total, nil
+}
+
+// cleanupExpiredInvitationsIn expires pending invitations in a single database
+func (s *InvitationService) cleanupExpiredInvitationsIn(ctx context.Context, db *sqlx.DB) (int, error) {
 	// Use bypass RLS for cleanup task (system operation)
-	tx, err := database.WithBypassRLS(ctx, s.db)
+	tx, err := database.WithBypassRLS(ctx, db)
 	if err != nil {
 		return 0, err
 	}
@@ -27,7 +27,8 @@ type Claims struct {
 	TenantID   uuid.UUID `json:"tenant_id"`
 	TenantSlug string    `json:"tenant_slug"`
 	Email      string    `json:"email"`
-	TokenType  string    `json:"token_type"` // access | refresh | 2fa
+	Sandbox    bool      `json:"sandbox,omitempty"` // Tenant is a sandbox copy, not production
+	TokenType  string    `json:"token_type"`        // access | refresh | 2fa
 	jwt.RegisteredClaims
 }
 
@@ -39,7 +40,7 @@ const (
 )
 
 // GenerateAccessToken generates an access token for a user
-func (s *JWTService) GenerateAccessToken(userID, tenantID uuid.UUID, tenantSlug, email string, rememberMe bool) (string, int64, error) {
+func (s *JWTService) GenerateAccessToken(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox, rememberMe bool) (string, int64, error) {
 	// Determine expiry based on rememberMe
 	var expiresIn time.Duration
 	if rememberMe {
@@ -55,6 +56,7 @@ func (s *JWTService) GenerateAccessToken(userID, tenantID uuid.UUID, tenantSlug,
 		TenantID:   tenantID,
 		TenantSlug: tenantSlug,
 		Email:      email,
+		Sandbox:    sandbox,
 		TokenType:  TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    s.config.Issuer,
@@ -75,7 +77,7 @@ func (s *JWTService) GenerateAccessToken(userID, tenantID uuid.UUID, tenantSlug,
 }
 
 // GenerateRefreshToken generates a refresh token for a user
-func (s *JWTService) GenerateRefreshToken(userID, tenantID uuid.UUID, tenantSlug, email string) (string, error) {
+func (s *JWTService) GenerateRefreshToken(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox bool) (string, error) {
 	expiresAt := time.Now().Add(s.config.RefreshTokenExpiry)
 
 	claims := Claims{
@@ -83,6 +85,7 @@ func (s *JWTService) GenerateRefreshToken(userID, tenantID uuid.UUID, tenantSlug
 		TenantID:   tenantID,
 		TenantSlug: tenantSlug,
 		Email:      email,
+		Sandbox:    sandbox,
 		TokenType:  TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    s.config.Issuer,
@@ -187,7 +190,7 @@ func (s *JWTService) RefreshAccessToken(refreshToken string, rememberMe bool) (s
 	}
 
 	// Generate new access token
-	return s.GenerateAccessToken(claims.UserID, claims.TenantID, claims.TenantSlug, claims.Email, rememberMe)
+	return s.GenerateAccessToken(claims.UserID, claims.TenantID, claims.TenantSlug, claims.Email, claims.Sandbox, rememberMe)
 }
 
 // ExtractClaims extracts claims from a token without full validation (for debugging)
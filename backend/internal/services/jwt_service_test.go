@@ -7,101 +7,107 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"myerp-v2/internal/config"
 )
 
+// newTestJWTService creates a JWT service with test secrets and expiries
+func newTestJWTService(secret string) *JWTService {
+	return NewJWTService(&config.JWTConfig{
+		Secret:             secret,
+		RefreshSecret:      secret + "-refresh",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 7 * 24 * time.Hour,
+		RememberMeExpiry:   30 * 24 * time.Hour,
+		Issuer:             "myerp-test",
+	})
+}
+
 func TestJWTService_GenerateAccessToken(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
-	email := "test@example.com"
-	tenantSlug := "test-tenant"
-	roles := []string{"admin", "user"}
 
 	// Test: Generate access token
-	token, err := service.GenerateAccessToken(tenantID, userID, email, tenantSlug, roles)
+	token, expiresIn, err := service.GenerateAccessToken(userID, tenantID, "test-tenant", "test@example.com", false, false)
 
 	require.NoError(t, err, "GenerateAccessToken should not return error")
 	assert.NotEmpty(t, token, "Token should not be empty")
 	assert.Greater(t, len(token), 50, "Token should be a valid JWT string")
+	assert.Equal(t, int64((15 * time.Minute).Seconds()), expiresIn, "Token should expire after the access token expiry")
 }
 
-func TestJWTService_ValidateToken(t *testing.T) {
+func TestJWTService_ValidateAccessToken(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
 	email := "test@example.com"
 	tenantSlug := "test-tenant"
-	roles := []string{"admin"}
 
 	// Generate token
-	token, err := service.GenerateAccessToken(tenantID, userID, email, tenantSlug, roles)
+	token, _, err := service.GenerateAccessToken(userID, tenantID, tenantSlug, email, true, false)
 	require.NoError(t, err)
 
 	// Test: Validate valid token
-	claims, err := service.ValidateToken(token)
-	require.NoError(t, err, "ValidateToken should not return error for valid token")
+	claims, err := service.ValidateAccessToken(token)
+	require.NoError(t, err, "ValidateAccessToken should not return error for valid token")
 
 	assert.Equal(t, userID, claims.UserID, "UserID should match")
 	assert.Equal(t, tenantID, claims.TenantID, "TenantID should match")
 	assert.Equal(t, email, claims.Email, "Email should match")
 	assert.Equal(t, tenantSlug, claims.TenantSlug, "TenantSlug should match")
-	assert.Equal(t, roles, claims.Roles, "Roles should match")
-	assert.Equal(t, "access", claims.TokenType, "TokenType should be 'access'")
+	assert.True(t, claims.Sandbox, "Sandbox should match")
+	assert.Equal(t, TokenTypeAccess, claims.TokenType, "TokenType should be 'access'")
 
 	// Test: Validate invalid token
 	invalidToken := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.invalid.signature"
-	_, err = service.ValidateToken(invalidToken)
-	assert.Error(t, err, "ValidateToken should return error for invalid token")
+	_, err = service.ValidateAccessToken(invalidToken)
+	assert.Error(t, err, "ValidateAccessToken should return error for invalid token")
 }
 
 func TestJWTService_GenerateRefreshToken(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
 
 	// Test: Generate refresh token
-	token, err := service.GenerateRefreshToken(tenantID, userID)
+	token, err := service.GenerateRefreshToken(userID, tenantID, "test-tenant", "test@example.com", false)
 
 	require.NoError(t, err, "GenerateRefreshToken should not return error")
 	assert.NotEmpty(t, token, "Refresh token should not be empty")
 
 	// Validate token claims
-	claims, err := service.ValidateToken(token)
+	claims, err := service.ValidateRefreshToken(token)
 	require.NoError(t, err)
 
-	assert.Equal(t, "refresh", claims.TokenType, "TokenType should be 'refresh'")
+	assert.Equal(t, TokenTypeRefresh, claims.TokenType, "TokenType should be 'refresh'")
 	assert.Equal(t, userID, claims.UserID)
 	assert.Equal(t, tenantID, claims.TenantID)
 }
 
 func TestJWTService_Generate2FAToken(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
 
 	// Test: Generate 2FA token
-	token, err := service.Generate2FAToken(tenantID, userID)
+	token, err := service.Generate2FAToken(userID, tenantID, "test-tenant", "test@example.com")
 
 	require.NoError(t, err, "Generate2FAToken should not return error")
 	assert.NotEmpty(t, token, "2FA token should not be empty")
 
 	// Validate token claims
-	claims, err := service.ValidateToken(token)
+	claims, err := service.Validate2FAToken(token)
 	require.NoError(t, err)
 
-	assert.Equal(t, "2fa", claims.TokenType, "TokenType should be '2fa'")
+	assert.Equal(t, TokenType2FA, claims.TokenType, "TokenType should be '2fa'")
 	assert.Equal(t, userID, claims.UserID)
 	assert.Equal(t, tenantID, claims.TenantID)
 
@@ -119,57 +125,46 @@ func TestJWTService_ExpiredToken(t *testing.T) {
 
 func TestJWTService_InvalidSecret(t *testing.T) {
 	// Setup with different secrets
-	jwtSecret1 := "test-secret-key-minimum-32-characters-long-for-security-1"
-	jwtSecret2 := "test-secret-key-minimum-32-characters-long-for-security-2"
-
-	service1 := NewJWTService(jwtSecret1, nil)
-	service2 := NewJWTService(jwtSecret2, nil)
+	service1 := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security-1")
+	service2 := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security-2")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
 
 	// Generate token with service1
-	token, err := service1.GenerateAccessToken(tenantID, userID, "test@example.com", "test-tenant", []string{"user"})
+	token, _, err := service1.GenerateAccessToken(userID, tenantID, "test-tenant", "test@example.com", false, false)
 	require.NoError(t, err)
 
 	// Test: Validate with different secret should fail
-	_, err = service2.ValidateToken(token)
-	assert.Error(t, err, "ValidateToken should fail when using different secret")
+	_, err = service2.ValidateAccessToken(token)
+	assert.Error(t, err, "ValidateAccessToken should fail when using different secret")
 }
 
-func TestJWTService_EmptyRoles(t *testing.T) {
+func TestJWTService_WrongTokenType(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
 	tenantID := uuid.New()
 	userID := uuid.New()
 
-	// Test: Generate token with empty roles
-	token, err := service.GenerateAccessToken(tenantID, userID, "test@example.com", "test-tenant", []string{})
+	// A 2FA token is signed with the access token secret but is no access token
+	token, err := service.Generate2FAToken(userID, tenantID, "test-tenant", "test@example.com")
 	require.NoError(t, err)
 
-	// Validate
-	claims, err := service.ValidateToken(token)
-	require.NoError(t, err)
-	assert.Empty(t, claims.Roles, "Roles should be empty array")
+	_, err = service.ValidateAccessToken(token)
+	assert.Error(t, err, "ValidateAccessToken should reject a 2FA token")
 }
 
-func TestJWTService_MultipleRoles(t *testing.T) {
+func TestJWTService_RememberMe(t *testing.T) {
 	// Setup
-	jwtSecret := "test-secret-key-minimum-32-characters-long-for-security"
-	service := NewJWTService(jwtSecret, nil)
-
-	tenantID := uuid.New()
-	userID := uuid.New()
-	roles := []string{"admin", "manager", "user", "developer"}
+	service := newTestJWTService("test-secret-key-minimum-32-characters-long-for-security")
 
-	// Test: Generate token with multiple roles
-	token, err := service.GenerateAccessToken(tenantID, userID, "test@example.com", "test-tenant", roles)
+	// Test: Remember me uses the extended expiry
+	token, expiresIn, err := service.GenerateAccessToken(uuid.New(), uuid.New(), "test-tenant", "test@example.com", false, true)
 	require.NoError(t, err)
+	assert.Equal(t, int64((30 * 24 * time.Hour).Seconds()), expiresIn, "Remember me should use the extended expiry")
 
-	// Validate
-	claims, err := service.ValidateToken(token)
+	claims, err := service.ValidateAccessToken(token)
 	require.NoError(t, err)
-	assert.ElementsMatch(t, roles, claims.Roles, "All roles should be present")
+	assert.InDelta(t, time.Now().Add(30*24*time.Hour).Unix(), claims.ExpiresAt.Unix(), 10)
 }
@@ -0,0 +1,1052 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// LeaveService handles leave types, yearly balances and the leave request
+// workflow. Requests are routed to the user of the employee's manager; users
+// with leave.approve decide when there is none, and for anyone.
+type LeaveService struct {
+	db                *sqlx.DB
+	leaveRepo         repository.LeaveStore
+	employeeRepo      repository.EmployeeStore
+	userRepo          repository.UserStore
+	userRoleRepo      repository.UserRoleStore
+	tenantRepo        repository.TenantStore
+	permissionService PermissionManager
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	notifier          NotificationManager
+	config            *config.Config
+}
+
+// NewLeaveService creates a new leave service
+func NewLeaveService(
+	db *sqlx.DB,
+	leaveRepo repository.LeaveStore,
+	employeeRepo repository.EmployeeStore,
+	userRepo repository.UserStore,
+	userRoleRepo repository.UserRoleStore,
+	tenantRepo repository.TenantStore,
+	permissionService PermissionManager,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	notifier NotificationManager,
+	cfg *config.Config,
+) *LeaveService {
+	return &LeaveService{
+		db:                db,
+		leaveRepo:         leaveRepo,
+		employeeRepo:      employeeRepo,
+		userRepo:          userRepo,
+		userRoleRepo:      userRoleRepo,
+		tenantRepo:        tenantRepo,
+		permissionService: permissionService,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		notifier:          notifier,
+		config:            cfg,
+	}
+}
+
+// ListTypes retrieves the tenant's leave types
+func (s *LeaveService) ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error) {
+	return s.leaveRepo.ListTypes(ctx, tenantID, activeOnly)
+}
+
+// GetType retrieves a leave type
+func (s *LeaveService) GetType(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error) {
+	return s.leaveRepo.FindTypeByID(ctx, tenantID, typeID)
+}
+
+// CreateType creates a leave type. Code and name are required.
+func (s *LeaveService) CreateType(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error) {
+	leaveType := &models.LeaveType{
+		Paid:             true,
+		RequiresApproval: true,
+		TracksBalance:    true,
+		IsActive:         true,
+		CreatedBy:        &creatorID,
+	}
+	applyLeaveTypeRequest(leaveType, req)
+
+	if err := s.checkTypeCode(ctx, tenantID, leaveType.Code, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.leaveRepo.CreateType(ctx, tenantID, leaveType); err != nil {
+		return nil, err
+	}
+
+	return leaveType, nil
+}
+
+// UpdateType updates the fields of a leave type set in the request. Balances
+// follow a new allowance the next time they are refreshed.
+func (s *LeaveService) UpdateType(ctx context.Context, tenantID, typeID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error) {
+	leaveType, err := s.leaveRepo.FindTypeByID(ctx, tenantID, typeID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyLeaveTypeRequest(leaveType, req)
+
+	if req.Code != nil {
+		if err := s.checkTypeCode(ctx, tenantID, leaveType.Code, &leaveType.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.leaveRepo.UpdateType(ctx, tenantID, leaveType); err != nil {
+		return nil, err
+	}
+
+	return leaveType, nil
+}
+
+// DeleteType deletes a leave type that was never requested
+func (s *LeaveService) DeleteType(ctx context.Context, tenantID, typeID uuid.UUID) error {
+	return s.leaveRepo.DeleteType(ctx, tenantID, typeID)
+}
+
+// Balances retrieves an employee's balances of the active leave types in a
+// year, brought up to date first. employeeID nil means the user's own
+// employee record; others' balances require leave.view or being their
+// manager.
+func (s *LeaveService) Balances(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, year int) ([]models.LeaveBalance, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if employeeID != nil && !ownsEmployee(employee, userID) {
+		allowed, err := s.can(ctx, tenantID, userID, models.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			allowed, err = s.isManager(ctx, tenantID, userID, employee)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+		}
+	}
+
+	leaveTypes, err := s.leaveRepo.ListTypes(ctx, tenantID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i := range leaveTypes {
+		if !leaveTypes[i].TracksBalance {
+			continue
+		}
+		if _, err := s.refreshBalance(ctx, tx.Tx, accrualTarget(employee, &leaveTypes[i]), year); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	balances, err := s.leaveRepo.ListBalances(ctx, tenantID, employee.ID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range balances {
+		balances[i].Available = balances[i].Remaining()
+	}
+
+	return balances, nil
+}
+
+// AdjustBalance sets the manual adjustment of an employee's balance, e.g.
+// days granted for overtime or an opening balance
+func (s *LeaveService) AdjustBalance(ctx context.Context, tenantID uuid.UUID, req *models.LeaveBalanceAdjustRequest) (*models.LeaveBalance, error) {
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveType, err := s.leaveRepo.FindTypeByID(ctx, tenantID, req.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !leaveType.TracksBalance {
+		return nil, fmt.Errorf("leave type does not track balances")
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	balance, err := s.refreshBalance(ctx, tx.Tx, accrualTarget(employee, leaveType), req.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.leaveRepo.SetAdjustment(ctx, tx.Tx, balance, req.Adjustment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	balance.LeaveTypeCode = leaveType.Code
+	balance.LeaveTypeName = leaveType.Name
+	balance.TracksBalance = leaveType.TracksBalance
+	balance.Available = balance.Remaining()
+	return balance, nil
+}
+
+// Submit requests leave for the user's own employee record, or records it
+// for another employee (leave.manage), in which case it is approved at once.
+// Requests of leave types without approval are approved at once too.
+func (s *LeaveService) Submit(ctx context.Context, tenantID, userID uuid.UUID, req *models.LeaveRequestCreateRequest) (*models.LeaveRequest, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	onBehalf := !ownsEmployee(employee, userID)
+	if onBehalf {
+		allowed, err := s.can(ctx, tenantID, userID, models.ActionManageLeave)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+		}
+	}
+
+	leaveType, err := s.leaveRepo.FindTypeByID(ctx, tenantID, req.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if !leaveType.IsActive {
+		return nil, fmt.Errorf("leave type is not active")
+	}
+
+	start, end := dateOnly(req.StartDate), dateOnly(req.EndDate)
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date is before start date")
+	}
+	if start.Year() != end.Year() {
+		return nil, fmt.Errorf("leave cannot span two calendar years")
+	}
+	if req.HalfDay && !start.Equal(end) {
+		return nil, fmt.Errorf("half days must be single-day requests")
+	}
+	if employee.IsTerminated() || start.Before(dateOnly(employee.HireDate)) ||
+		(employee.TerminationDate != nil && end.After(*employee.TerminationDate)) {
+		return nil, fmt.Errorf("leave is outside the employment period")
+	}
+
+	days := workingDays(start, end)
+	if days == 0 {
+		return nil, fmt.Errorf("leave must include at least one working day")
+	}
+	if req.HalfDay {
+		days = 0.5
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	approverID, err := s.managerUserID(ctx, tenantID, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &models.LeaveRequest{
+		TenantID:       tenantID,
+		EmployeeID:     employee.ID,
+		LeaveTypeID:    leaveType.ID,
+		StartDate:      start,
+		EndDate:        end,
+		HalfDay:        req.HalfDay,
+		Days:           days,
+		Reason:         req.Reason,
+		Status:         models.LeaveStatusPending,
+		ApproverID:     approverID,
+		RequestedBy:    &userID,
+		EmployeeName:   employee.FullName(),
+		EmployeeUserID: employee.UserID,
+		LeaveTypeName:  leaveType.Name,
+		LeaveTypeColor: leaveType.Color,
+	}
+	if onBehalf || !leaveType.RequiresApproval {
+		now := time.Now()
+		request.Status = models.LeaveStatusApproved
+		request.DecidedAt = &now
+		if onBehalf {
+			request.DecidedBy = &userID
+		}
+	}
+
+	// Users to ask for a decision: the manager, or else the leave approvers
+	var deciders []models.User
+	if request.IsPending() {
+		deciders, err = s.deciders(ctx, tenantID, employee, approverID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	overlaps, err := s.leaveRepo.HasOverlap(ctx, tx.Tx, tenantID, employee.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, fmt.Errorf("leave overlaps another request")
+	}
+
+	target := accrualTarget(employee, leaveType)
+	balance, err := s.refreshBalance(ctx, tx.Tx, target, start.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	if leaveType.TracksBalance {
+		// Leave later in the year may use the days accrued by its start
+		available := balance.Remaining()
+		if start.After(today()) {
+			available += accruedDays(target, start.Year(), start) - balance.Accrued
+		}
+		if days > available+0.001 {
+			return nil, fmt.Errorf("insufficient leave balance")
+		}
+	}
+
+	if err := s.leaveRepo.CreateRequest(ctx, tx.Tx, request); err != nil {
+		return nil, err
+	}
+
+	if request.IsPending() {
+		err = s.leaveRepo.AddToBalance(ctx, tx.Tx, balance, days, 0)
+	} else {
+		err = s.leaveRepo.AddToBalance(ctx, tx.Tx, balance, 0, days)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reviewURL := s.config.App.FrontendURL + "/leave/approvals"
+	for _, decider := range deciders {
+		msg, err := s.emailService.LeaveRequestEmail(
+			decider.Email, decider.Language, decider.FirstName, tenant.CompanyName, request.EmployeeName, leaveType.Name,
+			formatLeavePeriod(request), days, stringValue(request.Reason), reviewURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render leave request email: %w", err)
+		}
+		if err := s.emailQueueService.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+			return nil, fmt.Errorf("failed to queue leave request email: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	deciderIDs := make([]uuid.UUID, len(deciders))
+	for i, decider := range deciders {
+		deciderIDs[i] = decider.ID
+	}
+	s.notifyInApp(ctx, tenantID, deciderIDs, &models.NotificationRequest{
+		Type:  models.NotificationTypeLeaveRequested,
+		Title: fmt.Sprintf("%s requested %s", request.EmployeeName, leaveType.Name),
+		Body:  fmt.Sprintf("%s (%s)", formatLeavePeriod(request), formatLeaveDays(days)),
+		Link:  "/leave/approvals",
+		Data:  map[string]interface{}{"leave_request_id": request.ID},
+	})
+
+	return request, nil
+}
+
+// Approve approves a pending request, moving its days from pending to used
+func (s *LeaveService) Approve(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error) {
+	return s.decide(ctx, tenantID, userID, requestID, true, note)
+}
+
+// Reject rejects a pending request, releasing its days
+func (s *LeaveService) Reject(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error) {
+	return s.decide(ctx, tenantID, userID, requestID, false, note)
+}
+
+// decide records the decision on a pending request and tells the employee.
+// The request's approver, the employee's current manager and users with
+// leave.approve may decide, but never on their own leave.
+func (s *LeaveService) decide(ctx context.Context, tenantID, userID, requestID uuid.UUID, approve bool, note *string) (*models.LeaveRequest, error) {
+	request, err := s.leaveRepo.FindRequestByID(ctx, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, request.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownsEmployee(employee, userID) {
+		return nil, fmt.Errorf("cannot decide on own leave")
+	}
+	allowed, err := s.canDecide(ctx, tenantID, userID, request, employee)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	leaveType, err := s.leaveRepo.FindTypeByID(ctx, tenantID, request.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	decider, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	request, err = s.leaveRepo.LockRequest(ctx, tx.Tx, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !request.IsPending() {
+		return nil, fmt.Errorf("leave request is not pending")
+	}
+
+	balance, err := s.refreshBalance(ctx, tx.Tx, accrualTarget(employee, leaveType), request.StartDate.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.DecidedBy = &userID
+	request.DecidedAt = &now
+	request.DecisionNote = note
+	if approve {
+		request.Status = models.LeaveStatusApproved
+		err = s.leaveRepo.AddToBalance(ctx, tx.Tx, balance, -request.Days, request.Days)
+	} else {
+		request.Status = models.LeaveStatusRejected
+		err = s.leaveRepo.AddToBalance(ctx, tx.Tx, balance, -request.Days, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.leaveRepo.UpdateRequestStatus(ctx, tx.Tx, request); err != nil {
+		return nil, err
+	}
+
+	if email := s.employeeEmail(ctx, tenantID, employee); email != "" {
+		msg, err := s.emailService.LeaveDecisionEmail(
+			email, tenant.Locale().Language, employee.FirstName, tenant.CompanyName, leaveType.Name, formatLeavePeriod(request),
+			request.Days, approve, decider.FullName(), stringValue(note),
+			s.config.App.FrontendURL+"/leave/requests/"+request.ID.String(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render leave decision email: %w", err)
+		}
+		if err := s.emailQueueService.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+			return nil, fmt.Errorf("failed to queue leave decision email: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if employee.UserID != nil {
+		s.notifyInApp(ctx, tenantID, []uuid.UUID{*employee.UserID}, &models.NotificationRequest{
+			Type:  models.NotificationTypeLeaveDecided,
+			Title: fmt.Sprintf("Your %s request was %s", leaveType.Name, request.Status),
+			Body:  formatLeavePeriod(request),
+			Link:  "/leave/requests/" + request.ID.String(),
+			Data:  map[string]interface{}{"leave_request_id": request.ID, "status": request.Status},
+		})
+	}
+
+	return request, nil
+}
+
+// Cancel cancels a pending request, or approved leave that has not started,
+// giving its days back. Users with leave.manage can cancel any pending or
+// approved leave.
+func (s *LeaveService) Cancel(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	request, err := s.leaveRepo.FindRequestByID(ctx, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	canManage, err := s.can(ctx, tenantID, userID, models.ActionManageLeave)
+	if err != nil {
+		return nil, err
+	}
+	own := request.EmployeeUserID != nil && *request.EmployeeUserID == userID
+	if !own && !canManage {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, request.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveType, err := s.leaveRepo.FindTypeByID(ctx, tenantID, request.LeaveTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	request, err = s.leaveRepo.LockRequest(ctx, tx.Tx, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, used := 0.0, 0.0
+	switch request.Status {
+	case models.LeaveStatusPending:
+		pending = -request.Days
+	case models.LeaveStatusApproved:
+		if !canManage && !request.StartDate.After(today()) {
+			return nil, fmt.Errorf("leave has already started")
+		}
+		used = -request.Days
+	default:
+		return nil, fmt.Errorf("leave request cannot be cancelled")
+	}
+
+	balance, err := s.refreshBalance(ctx, tx.Tx, accrualTarget(employee, leaveType), request.StartDate.Year())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.leaveRepo.AddToBalance(ctx, tx.Tx, balance, pending, used); err != nil {
+		return nil, err
+	}
+
+	// The approver of pending leave, or whoever approved it, is told
+	recipient := request.ApproverID
+	if request.Status == models.LeaveStatusApproved {
+		recipient = request.DecidedBy
+	}
+
+	now := time.Now()
+	request.Status = models.LeaveStatusCancelled
+	request.CancelledAt = &now
+	if err := s.leaveRepo.UpdateRequestStatus(ctx, tx.Tx, request); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	var recipients []uuid.UUID
+	if recipient != nil && *recipient != userID {
+		recipients = append(recipients, *recipient)
+	}
+	if !own && request.EmployeeUserID != nil {
+		recipients = append(recipients, *request.EmployeeUserID)
+	}
+	s.notifyInApp(ctx, tenantID, recipients, &models.NotificationRequest{
+		Type:  models.NotificationTypeLeaveCancelled,
+		Title: fmt.Sprintf("%s leave of %s was cancelled", leaveType.Name, request.EmployeeName),
+		Body:  formatLeavePeriod(request),
+		Link:  "/leave/requests/" + request.ID.String(),
+		Data:  map[string]interface{}{"leave_request_id": request.ID},
+	})
+
+	return request, nil
+}
+
+// Get retrieves a leave request the user may see: their own, one they may
+// decide, or any with leave.view
+func (s *LeaveService) Get(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	request, err := s.leaveRepo.FindRequestByID(ctx, tenantID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.EmployeeUserID != nil && *request.EmployeeUserID == userID {
+		return request, nil
+	}
+
+	allowed, err := s.can(ctx, tenantID, userID, models.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		employee, err := s.employeeRepo.FindByID(ctx, tenantID, request.EmployeeID)
+		if err != nil {
+			return nil, err
+		}
+		allowed, err = s.canDecide(ctx, tenantID, userID, request, employee)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !allowed {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	return request, nil
+}
+
+// List retrieves leave requests with filters. Without leave.view, users only
+// see their own requests and those of the employees they manage.
+func (s *LeaveService) List(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error) {
+	canView, err := s.can(ctx, tenantID, userID, models.ActionView)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var visibleTo *uuid.UUID
+	if !canView {
+		visibleTo = &userID
+	}
+
+	return s.leaveRepo.ListRequests(ctx, tenantID, filter, visibleTo, limit, offset)
+}
+
+// ListOwn retrieves the requests of the user's employee record
+func (s *LeaveService) ListOwn(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter.EmployeeID = &employee.ID
+	return s.leaveRepo.ListRequests(ctx, tenantID, filter, nil, limit, offset)
+}
+
+// ListApprovals retrieves the pending requests the user may decide: all of
+// them with leave.approve, otherwise those of the employees they manage
+func (s *LeaveService) ListApprovals(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error) {
+	canApprove, err := s.can(ctx, tenantID, userID, models.ActionApprove)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := models.LeaveRequestFilter{Status: models.LeaveStatusPending}
+	if !canApprove {
+		filter.ApproverID = &userID
+	}
+
+	return s.leaveRepo.ListRequests(ctx, tenantID, filter, nil, limit, offset)
+}
+
+// Calendar retrieves the leave overlapping a period. Without leave.view,
+// users only see their department, their direct reports and themselves.
+func (s *LeaveService) Calendar(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error) {
+	canView, err := s.can(ctx, tenantID, userID, models.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		filter.VisibleTo = &userID
+	}
+
+	return s.leaveRepo.Calendar(ctx, tenantID, filter)
+}
+
+// RunAccrual brings the current year's balances of every current employee up
+// to date, adding the month's accrual and the carryover from last year.
+// Returns the number of balances refreshed.
+func (s *LeaveService) RunAccrual(ctx context.Context) (int, error) {
+	year := today().Year()
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		targets, err := s.leaveRepo.ListAccrualTargets(ctx, db)
+		if err != nil {
+			return total, err
+		}
+
+		// Targets are ordered by tenant
+		for start := 0; start < len(targets); {
+			end := start
+			for end < len(targets) && targets[end].TenantID == targets[start].TenantID {
+				end++
+			}
+
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+
+			// Tenants under maintenance accrue on the first run after it
+			if jobs.IsPaused(ctx, targets[start].TenantID) {
+				start = end
+				continue
+			}
+
+			if err := s.accrueTenant(ctx, targets[start].TenantID, targets[start:end], year); err != nil {
+				log.Printf("⚠️  Leave accrual of tenant %s failed: %v", targets[start].TenantID, err)
+			} else {
+				total += end - start
+			}
+
+			start = end
+		}
+	}
+
+	return total, nil
+}
+
+// accrueTenant refreshes one tenant's balances in a single transaction
+func (s *LeaveService) accrueTenant(ctx context.Context, tenantID uuid.UUID, targets []models.LeaveAccrualTarget, year int) error {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range targets {
+		if _, err := s.refreshBalance(ctx, tx.Tx, &targets[i], year); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// refreshBalance recomputes the accrued and carried over days of a balance
+// as of today and returns it locked until tx ends
+func (s *LeaveService) refreshBalance(ctx context.Context, tx *sqlx.Tx, target *models.LeaveAccrualTarget, year int) (*models.LeaveBalance, error) {
+	balance := &models.LeaveBalance{
+		TenantID:    target.TenantID,
+		EmployeeID:  target.EmployeeID,
+		LeaveTypeID: target.LeaveTypeID,
+		Year:        year,
+		Accrued:     accruedDays(target, year, today()),
+	}
+
+	if target.MaxCarryover > 0 {
+		previous, err := s.leaveRepo.FindBalance(ctx, tx, target.TenantID, target.EmployeeID, target.LeaveTypeID, year-1)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			// Last year has fully accrued, even if its row was not refreshed
+			previous.Accrued = accruedDays(target, year-1, today())
+			balance.CarriedOver = math.Min(target.MaxCarryover, math.Max(0, previous.Remaining()))
+		}
+	}
+
+	if err := s.leaveRepo.UpsertBalance(ctx, tx, balance); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// resolveEmployee returns the employee with employeeID, or the user's own
+// employee record when nil
+func (s *LeaveService) resolveEmployee(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID) (*models.Employee, error) {
+	if employeeID != nil {
+		return s.employeeRepo.FindByID(ctx, tenantID, *employeeID)
+	}
+
+	employee, err := s.employeeRepo.FindByUserID(ctx, tenantID, userID)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			return nil, fmt.Errorf("no employee record")
+		}
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// managerUserID returns the active user of the employee's manager, to route
+// the employee's requests to. Returns nil if there is none.
+func (s *LeaveService) managerUserID(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) (*uuid.UUID, error) {
+	if employee.ManagerID == nil {
+		return nil, nil
+	}
+
+	manager, err := s.employeeRepo.FindByID(ctx, tenantID, *employee.ManagerID)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if manager.UserID == nil || manager.IsTerminated() || ownsEmployee(employee, *manager.UserID) {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenantID, *manager.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, nil
+	}
+
+	return &user.ID, nil
+}
+
+// deciders returns the users asked to decide on an employee's request: the
+// manager's user, or else every user with leave.approve
+func (s *LeaveService) deciders(ctx context.Context, tenantID uuid.UUID, employee *models.Employee, approverID *uuid.UUID) ([]models.User, error) {
+	if approverID != nil {
+		approver, err := s.userRepo.FindByID(ctx, tenantID, *approverID)
+		if err != nil {
+			return nil, err
+		}
+		return []models.User{*approver}, nil
+	}
+
+	approvers, err := s.userRoleRepo.GetUsersWithPermission(ctx, tenantID, models.ResourceLeave, models.ActionApprove)
+	if err != nil {
+		return nil, err
+	}
+
+	deciders := make([]models.User, 0, len(approvers))
+	for _, approver := range approvers {
+		if !ownsEmployee(employee, approver.ID) {
+			deciders = append(deciders, approver)
+		}
+	}
+
+	return deciders, nil
+}
+
+// canDecide checks whether the user may decide on a request: its approver,
+// the employee's current manager, or a user with leave.approve
+func (s *LeaveService) canDecide(ctx context.Context, tenantID, userID uuid.UUID, request *models.LeaveRequest, employee *models.Employee) (bool, error) {
+	if request.ApproverID != nil && *request.ApproverID == userID {
+		return true, nil
+	}
+
+	isManager, err := s.isManager(ctx, tenantID, userID, employee)
+	if err != nil || isManager {
+		return isManager, err
+	}
+
+	return s.can(ctx, tenantID, userID, models.ActionApprove)
+}
+
+// isManager checks whether the user is the employee's manager
+func (s *LeaveService) isManager(ctx context.Context, tenantID, userID uuid.UUID, employee *models.Employee) (bool, error) {
+	managerUserID, err := s.managerUserID(ctx, tenantID, employee)
+	if err != nil {
+		return false, err
+	}
+	return managerUserID != nil && *managerUserID == userID, nil
+}
+
+// can checks a leave permission of the user
+func (s *LeaveService) can(ctx context.Context, tenantID, userID uuid.UUID, action string) (bool, error) {
+	return s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceLeave, action)
+}
+
+// employeeEmail returns the employee's email, or their user's
+func (s *LeaveService) employeeEmail(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) string {
+	if employee.Email != nil && *employee.Email != "" {
+		return *employee.Email
+	}
+	if employee.UserID != nil {
+		if user, err := s.userRepo.FindByID(ctx, tenantID, *employee.UserID); err == nil {
+			return user.Email
+		}
+	}
+	return ""
+}
+
+// notifyInApp adds an in-app notification for the users. Failures are only
+// logged: the change is already committed.
+func (s *LeaveService) notifyInApp(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) {
+	if err := s.notifier.Notify(ctx, tenantID, userIDs, req); err != nil {
+		log.Printf("⚠️  Failed to send %s notification in tenant %s: %v", req.Type, tenantID, err)
+	}
+}
+
+// checkTypeCode checks that a leave type code is not taken
+func (s *LeaveService) checkTypeCode(ctx context.Context, tenantID uuid.UUID, code string, typeID *uuid.UUID) error {
+	exists, err := s.leaveRepo.CheckTypeCodeExists(ctx, tenantID, code, typeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("LEAVE_TYPE_CODE_EXISTS", "leave type code already exists")
+	}
+	return nil
+}
+
+// applyLeaveTypeRequest copies the fields set in a request to a leave type.
+// Codes are stored upper case.
+func applyLeaveTypeRequest(leaveType *models.LeaveType, req *models.LeaveTypeRequest) {
+	if req.Code != nil {
+		leaveType.Code = strings.ToUpper(strings.TrimSpace(*req.Code))
+	}
+	if req.Name != nil {
+		leaveType.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		leaveType.Description = req.Description
+	}
+	if req.Color != nil {
+		leaveType.Color = req.Color
+	}
+	if req.Paid != nil {
+		leaveType.Paid = *req.Paid
+	}
+	if req.RequiresApproval != nil {
+		leaveType.RequiresApproval = *req.RequiresApproval
+	}
+	if req.TracksBalance != nil {
+		leaveType.TracksBalance = *req.TracksBalance
+	}
+	if req.AnnualAllowance != nil {
+		leaveType.AnnualAllowance = *req.AnnualAllowance
+	}
+	if req.MaxCarryover != nil {
+		leaveType.MaxCarryover = *req.MaxCarryover
+	}
+	if req.IsActive != nil {
+		leaveType.IsActive = *req.IsActive
+	}
+}
+
+// accrualTarget pairs an employee with a leave type for balance refreshes
+func accrualTarget(employee *models.Employee, leaveType *models.LeaveType) *models.LeaveAccrualTarget {
+	return &models.LeaveAccrualTarget{
+		TenantID:        employee.TenantID,
+		EmployeeID:      employee.ID,
+		HireDate:        employee.HireDate,
+		TerminationDate: employee.TerminationDate,
+		LeaveTypeID:     leaveType.ID,
+		AnnualAllowance: leaveType.AnnualAllowance,
+		MaxCarryover:    leaveType.MaxCarryover,
+	}
+}
+
+// accruedDays returns the allowance accrued in year as of asOf: a twelfth
+// for each month started while employed, the hire and leaving months
+// included
+func accruedDays(target *models.LeaveAccrualTarget, year int, asOf time.Time) float64 {
+	months := 0
+	for month := time.January; month <= time.December; month++ {
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		if first.After(asOf) {
+			break
+		}
+		if target.TerminationDate != nil && target.TerminationDate.Before(first) {
+			break
+		}
+		if target.HireDate.After(first.AddDate(0, 1, -1)) {
+			continue
+		}
+		months++
+	}
+
+	return math.Round(target.AnnualAllowance*float64(months)/12*100) / 100
+}
+
+// workingDays counts the days from start to end, both included, that fall
+// Monday to Friday
+func workingDays(start, end time.Time) float64 {
+	days := 0.0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if weekday := day.Weekday(); weekday != time.Saturday && weekday != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// formatLeavePeriod formats a request's period for people, e.g.
+// "2026-03-02 to 2026-03-06"
+func formatLeavePeriod(request *models.LeaveRequest) string {
+	start := request.StartDate.Format("2006-01-02")
+	if request.HalfDay {
+		return start + " (half day)"
+	}
+	if request.EndDate.Equal(request.StartDate) {
+		return start
+	}
+	return start + " to " + request.EndDate.Format("2006-01-02")
+}
+
+// ownsEmployee checks whether the user is the employee's linked user
+func ownsEmployee(employee *models.Employee, userID uuid.UUID) bool {
+	return employee.UserID != nil && *employee.UserID == userID
+}
+
+// dateOnly returns the UTC calendar date of t
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// stringValue returns the value of an optional string, or ""
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// today returns the current UTC date
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
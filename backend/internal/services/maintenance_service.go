@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// MaintenanceService schedules tenants' maintenance windows. The window is
+// kept on the tenant, so the middleware that loads the tenant on every
+// request freezes changes while it is in progress, and the job runner pauses
+// jobs changing the tenant's data.
+type MaintenanceService struct {
+	tenantRepo repository.TenantStore
+	userRepo   repository.UserStore
+	notifier   NotificationManager
+}
+
+// NewMaintenanceService creates a new maintenance service
+func NewMaintenanceService(
+	tenantRepo repository.TenantStore,
+	userRepo repository.UserStore,
+	notifier NotificationManager,
+) *MaintenanceService {
+	return &MaintenanceService{
+		tenantRepo: tenantRepo,
+		userRepo:   userRepo,
+		notifier:   notifier,
+	}
+}
+
+// GetWindow returns the tenant's scheduled or in-progress maintenance
+// window, or nil when there is none
+func (s *MaintenanceService) GetWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return tenant.MaintenanceWindow(), nil
+}
+
+// ScheduleWindow schedules the tenant's maintenance window, replacing any
+// scheduled one, and announces it to every active user
+func (s *MaintenanceService) ScheduleWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, utils.NewFieldError("INVALID_MAINTENANCE_WINDOW", "ends_at", "maintenance window must end after it starts")
+	}
+	if !req.EndsAt.After(time.Now()) {
+		return nil, utils.NewFieldError("INVALID_MAINTENANCE_WINDOW", "ends_at", "maintenance window must end in the future")
+	}
+
+	paths := make([]string, 0, len(req.AllowedPaths))
+	for _, path := range req.AllowedPaths {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	req.AllowedPaths = paths
+	req.Message = strings.TrimSpace(req.Message)
+
+	if err := s.tenantRepo.SetMaintenanceWindow(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+
+	window, err := s.GetWindow(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if window != nil {
+		s.announce(ctx, tenantID, window)
+	}
+
+	return window, nil
+}
+
+// CancelWindow cancels the tenant's maintenance window, or ends it early if
+// it is in progress. It returns the cancelled window.
+func (s *MaintenanceService) CancelWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error) {
+	window, err := s.GetWindow(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, utils.NewNotFoundError("MAINTENANCE_WINDOW_NOT_FOUND", "no maintenance window scheduled")
+	}
+
+	if err := s.tenantRepo.ClearMaintenanceWindow(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+// announce adds an in-app notification of the window for every active user
+func (s *MaintenanceService) announce(ctx context.Context, tenantID uuid.UUID, window *models.MaintenanceWindow) {
+	userIDs, err := s.userRepo.ListActiveIDs(ctx, tenantID)
+	if err != nil {
+		log.Printf("⚠️  Failed to announce maintenance window of tenant %s: %v", tenantID, err)
+		return
+	}
+
+	body := fmt.Sprintf("Changes will be paused from %s to %s (UTC); you can still view your data.",
+		window.StartsAt.UTC().Format("2006-01-02 15:04"), window.EndsAt.UTC().Format("2006-01-02 15:04"))
+	if window.Message != "" {
+		body += " " + window.Message
+	}
+
+	if err := s.notifier.Notify(ctx, tenantID, userIDs, &models.NotificationRequest{
+		Type:  models.NotificationTypeMaintenanceScheduled,
+		Title: "Scheduled maintenance",
+		Body:  body,
+		Data:  window,
+	}); err != nil {
+		log.Printf("⚠️  Failed to announce maintenance window of tenant %s: %v", tenantID, err)
+	}
+}
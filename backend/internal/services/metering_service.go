@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+const (
+	meterDayTTL   = 72 * time.Hour      // Keeps a day's counters until the rollups after the day are done
+	meterMonthTTL = 35 * 24 * time.Hour // Keeps a month's counters until the month is over
+)
+
+// meterDayKey is the Redis hash of a tenant's counters of a day
+func meterDayKey(day time.Time, tenantID uuid.UUID) string {
+	return database.CacheKey("meter", "day", day.Format("2006-01-02"), tenantID.String())
+}
+
+// meterMonthKey is the Redis hash of a tenant's counters of a month
+func meterMonthKey(month time.Time, tenantID uuid.UUID) string {
+	return database.CacheKey("meter", "month", month.Format("2006-01"), tenantID.String())
+}
+
+// meterActiveKey is the Redis HyperLogLog counting a tenant's distinct
+// active users of a day
+func meterActiveKey(day time.Time, tenantID uuid.UUID) string {
+	return database.CacheKey("meter", "active", day.Format("2006-01-02"), tenantID.String())
+}
+
+// meterTenantsKey is the Redis set of the tenants metered during a day
+func meterTenantsKey(day time.Time) string {
+	return database.CacheKey("meter", "tenants", day.Format("2006-01-02"))
+}
+
+// MeteringService meters the usage of each tenant that plans limit and
+// billing reports: API calls, active users, storage and emails sent.
+// Requests and emails are counted in Redis as they happen, so every replica
+// sees the month's API calls at once to enforce the plan's limit. The
+// metering_rollup job copies the daily counters to the database, where they
+// are kept for METERING_RETENTION.
+type MeteringService struct {
+	usageRepo repository.TenantUsageStore
+	redis     *redis.Client
+	config    *config.Config
+}
+
+// NewMeteringService creates a new metering service
+func NewMeteringService(usageRepo repository.TenantUsageStore, redis *redis.Client, cfg *config.Config) *MeteringService {
+	return &MeteringService{
+		usageRepo: usageRepo,
+		redis:     redis,
+		config:    cfg,
+	}
+}
+
+// RecordAPICall counts an authenticated request of a user. Unless exempt,
+// the request is refused once the tenant made as many API calls this month
+// as its plan allows: it returns false and the plan's limit, and the request
+// is not counted.
+func (s *MeteringService) RecordAPICall(ctx context.Context, tenant *models.Tenant, userID uuid.UUID, exempt bool) (bool, int64, error) {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	dayKey := meterDayKey(day, tenant.ID)
+	monthKey := meterMonthKey(now, tenant.ID)
+	activeKey := meterActiveKey(day, tenant.ID)
+	tenantsKey := meterTenantsKey(day)
+
+	var calls *redis.IntCmd
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, dayKey, models.MeterAPICalls, 1)
+		pipe.Expire(ctx, dayKey, meterDayTTL)
+		calls = pipe.HIncrBy(ctx, monthKey, models.MeterAPICalls, 1)
+		pipe.Expire(ctx, monthKey, meterMonthTTL)
+		pipe.PFAdd(ctx, activeKey, userID.String())
+		pipe.Expire(ctx, activeKey, meterDayTTL)
+		pipe.SAdd(ctx, tenantsKey, tenant.ID.String())
+		pipe.Expire(ctx, tenantsKey, meterDayTTL)
+		return nil
+	})
+	if err != nil {
+		return true, 0, err
+	}
+
+	limit, ok := s.config.Quotas.APICallLimits[tenant.PlanTier]
+	if exempt || !ok || calls.Val() <= limit {
+		return true, limit, nil
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, dayKey, models.MeterAPICalls, -1)
+		pipe.HIncrBy(ctx, monthKey, models.MeterAPICalls, -1)
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to uncount refused API call of tenant %s: %v", tenant.ID, err)
+	}
+
+	return false, limit, nil
+}
+
+// RecordEmailSent counts an email handed to the email provider. Failures are
+// logged: metering must not hold up delivery.
+func (s *MeteringService) RecordEmailSent(ctx context.Context, tenantID uuid.UUID) {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	dayKey := meterDayKey(day, tenantID)
+	monthKey := meterMonthKey(now, tenantID)
+	tenantsKey := meterTenantsKey(day)
+
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, dayKey, models.MeterEmailsSent, 1)
+		pipe.Expire(ctx, dayKey, meterDayTTL)
+		pipe.HIncrBy(ctx, monthKey, models.MeterEmailsSent, 1)
+		pipe.Expire(ctx, monthKey, meterMonthTTL)
+		pipe.SAdd(ctx, tenantsKey, tenantID.String())
+		pipe.Expire(ctx, tenantsKey, meterDayTTL)
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to meter email of tenant %s: %v", tenantID, err)
+	}
+}
+
+// RecordStorage records the tenant's storage as just measured. Failures are
+// logged.
+func (s *MeteringService) RecordStorage(ctx context.Context, tenantID uuid.UUID, bytes int64) {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	dayKey := meterDayKey(day, tenantID)
+	tenantsKey := meterTenantsKey(day)
+
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, dayKey, models.MeterStorageBytes, bytes)
+		pipe.Expire(ctx, dayKey, meterDayTTL)
+		pipe.SAdd(ctx, tenantsKey, tenantID.String())
+		pipe.Expire(ctx, tenantsKey, meterDayTTL)
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to meter storage of tenant %s: %v", tenantID, err)
+	}
+}
+
+// MonthToDate returns the tenant's usage of a counted metric (API calls or
+// emails sent) this month
+func (s *MeteringService) MonthToDate(ctx context.Context, tenantID uuid.UUID, metric string) (int64, error) {
+	now := time.Now().UTC()
+	value, err := s.redis.HGet(ctx, meterMonthKey(now, tenantID), metric).Int64()
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	// Nothing counted yet this month, or Redis lost the counters: the
+	// rollups are the best known
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return s.usageRepo.Sum(ctx, tenantID, metric, start, start.AddDate(0, 1, 0))
+}
+
+// GetUsage retrieves the tenant's daily usage over a calendar month (UTC) and
+// its totals. The counters of today and yesterday are read live, as they
+// are more recent than the last rollup.
+func (s *MeteringService) GetUsage(ctx context.Context, tenantID uuid.UUID, month time.Time) (*models.TenantUsageReport, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	rows, err := s.usageRepo.ListRange(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]map[string]int64)
+	for _, row := range rows {
+		key := row.Day.UTC().Format("2006-01-02")
+		if days[key] == nil {
+			days[key] = make(map[string]int64)
+		}
+		days[key][row.Metric] = row.Value
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		if day.Before(start) || !day.Before(end) {
+			continue
+		}
+		live, err := s.readDay(ctx, tenantID, day)
+		if err != nil {
+			log.Printf("⚠️  Failed to read live usage of tenant %s: %v", tenantID, err)
+			continue
+		}
+		if len(live) == 0 {
+			continue
+		}
+
+		key := day.Format("2006-01-02")
+		if days[key] == nil {
+			days[key] = make(map[string]int64)
+		}
+		for metric, value := range live {
+			if metric == models.MeterStorageBytes || value > days[key][metric] {
+				days[key][metric] = value
+			}
+		}
+	}
+
+	report := &models.TenantUsageReport{
+		Month:  start.Format("2006-01"),
+		Totals: make(map[string]int64, len(models.MeterMetrics)),
+		Daily:  make([]models.TenantUsageDay, 0, len(days)),
+	}
+	for _, metric := range models.MeterMetrics {
+		report.Totals[metric] = 0
+	}
+
+	for key, values := range days {
+		report.Daily = append(report.Daily, models.TenantUsageDay{Day: key, Values: values})
+	}
+	sort.Slice(report.Daily, func(i, j int) bool {
+		return report.Daily[i].Day < report.Daily[j].Day
+	})
+
+	for _, day := range report.Daily {
+		report.Totals[models.MeterAPICalls] += day.Values[models.MeterAPICalls]
+		report.Totals[models.MeterEmailsSent] += day.Values[models.MeterEmailsSent]
+		if day.Values[models.MeterActiveUsers] > report.Totals[models.MeterActiveUsers] {
+			report.Totals[models.MeterActiveUsers] = day.Values[models.MeterActiveUsers]
+		}
+		if storage, ok := day.Values[models.MeterStorageBytes]; ok {
+			report.Totals[models.MeterStorageBytes] = storage
+		}
+	}
+
+	return report, nil
+}
+
+// Rollup copies the counters of yesterday and today from Redis to the
+// database. Returns the number of daily values written.
+func (s *MeteringService) Rollup(ctx context.Context) (int, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	usage := []models.TenantUsage{}
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		ids, err := s.redis.SMembers(ctx, meterTenantsKey(day)).Result()
+		if err != nil {
+			return 0, err
+		}
+
+		for _, id := range ids {
+			tenantID, err := uuid.Parse(id)
+			if err != nil {
+				continue
+			}
+
+			values, err := s.readDay(ctx, tenantID, day)
+			if err != nil {
+				return 0, err
+			}
+			for metric, value := range values {
+				usage = append(usage, models.TenantUsage{
+					TenantID: tenantID,
+					Day:      day,
+					Metric:   metric,
+					Value:    value,
+				})
+			}
+		}
+	}
+
+	if len(usage) == 0 {
+		return 0, nil
+	}
+	if err := s.usageRepo.Save(ctx, usage); err != nil {
+		return 0, err
+	}
+
+	return len(usage), nil
+}
+
+// readDay reads a tenant's counters of a day from Redis
+func (s *MeteringService) readDay(ctx context.Context, tenantID uuid.UUID, day time.Time) (map[string]int64, error) {
+	var counters *redis.MapStringStringCmd
+	var active *redis.IntCmd
+	_, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		counters = pipe.HGetAll(ctx, meterDayKey(day, tenantID))
+		active = pipe.PFCount(ctx, meterActiveKey(day, tenantID))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]int64)
+	for metric, value := range counters.Val() {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			values[metric] = n
+		}
+	}
+	if active.Val() > 0 {
+		values[models.MeterActiveUsers] = active.Val()
+	}
+
+	return values, nil
+}
+
+// Cleanup deletes daily usage older than METERING_RETENTION
+func (s *MeteringService) Cleanup(ctx context.Context) (int, error) {
+	return s.usageRepo.DeleteBefore(ctx, time.Now().Add(-s.config.Metering.Retention).UTC().Truncate(24*time.Hour))
+}
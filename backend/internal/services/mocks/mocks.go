@@ -0,0 +1,5391 @@
+// Code generated by genmocks. DO NOT EDIT.
+
+// Package mocks implements the interfaces of package services with
+// function fields, for unit tests.
+package mocks
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/pagination"
+	"myerp-v2/internal/services"
+	"myerp-v2/internal/utils"
+)
+
+// AccountingManager is a mock of services.AccountingManager
+type AccountingManager struct {
+	ClosePeriodFunc     func(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	CreateAccountFunc   func(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountCreateRequest) (*models.Account, error)
+	CreateEntryFunc     func(ctx context.Context, tenantID, userID uuid.UUID, req *models.JournalEntryCreateRequest) (*models.JournalEntry, error)
+	CreatePeriodFunc    func(ctx context.Context, tenantID, userID uuid.UUID, req *models.AccountingPeriodCreateRequest) (*models.AccountingPeriod, error)
+	DeleteAccountFunc   func(ctx context.Context, tenantID, userID, accountID uuid.UUID) error
+	DeleteEntryFunc     func(ctx context.Context, tenantID, userID, entryID uuid.UUID) error
+	DescribeAccountFunc func(ctx context.Context, tenantID, accountID uuid.UUID) (*models.EntitySummary, error)
+	DescribeEntryFunc   func(ctx context.Context, tenantID, entryID uuid.UUID) (*models.EntitySummary, error)
+	GetAccountFunc      func(ctx context.Context, tenantID, accountID uuid.UUID) (*models.Account, error)
+	GetEntryFunc        func(ctx context.Context, tenantID, entryID uuid.UUID) (*models.JournalEntry, error)
+	GetPeriodFunc       func(ctx context.Context, tenantID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	ListAccountsFunc    func(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error)
+	ListEntriesFunc     func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.JournalEntryFilter,
+		limit, offset int,
+	) ([]models.JournalEntry, int, error)
+	ListPeriodsFunc  func(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error)
+	PostEntryFunc    func(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.JournalEntry, error)
+	ReopenPeriodFunc func(ctx context.Context, tenantID, userID, periodID uuid.UUID) (*models.AccountingPeriod, error)
+	ReverseEntryFunc func(
+		ctx context.Context,
+		tenantID, userID, entryID uuid.UUID,
+		req *models.JournalEntryReverseRequest,
+	) (*models.JournalEntry, error)
+	TrialBalanceFunc  func(ctx context.Context, tenantID uuid.UUID, periodID *uuid.UUID, asOf *time.Time) (*models.TrialBalance, error)
+	UpdateAccountFunc func(
+		ctx context.Context,
+		tenantID, userID, accountID uuid.UUID,
+		req *models.AccountUpdateRequest,
+	) (*models.Account, error)
+	UpdateEntryFunc func(
+		ctx context.Context,
+		tenantID, userID, entryID uuid.UUID,
+		req *models.JournalEntryUpdateRequest,
+	) (*models.JournalEntry, error)
+}
+
+// ClosePeriod calls ClosePeriodFunc
+func (mock *AccountingManager) ClosePeriod(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	if mock.ClosePeriodFunc == nil {
+		panic("AccountingManager.ClosePeriod is not stubbed")
+	}
+	return mock.ClosePeriodFunc(ctx, tenantID, userID, periodID)
+}
+
+// CreateAccount calls CreateAccountFunc
+func (mock *AccountingManager) CreateAccount(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.AccountCreateRequest) (*models.Account, error) {
+	if mock.CreateAccountFunc == nil {
+		panic("AccountingManager.CreateAccount is not stubbed")
+	}
+	return mock.CreateAccountFunc(ctx, tenantID, userID, req)
+}
+
+// CreateEntry calls CreateEntryFunc
+func (mock *AccountingManager) CreateEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.JournalEntryCreateRequest) (*models.JournalEntry, error) {
+	if mock.CreateEntryFunc == nil {
+		panic("AccountingManager.CreateEntry is not stubbed")
+	}
+	return mock.CreateEntryFunc(ctx, tenantID, userID, req)
+}
+
+// CreatePeriod calls CreatePeriodFunc
+func (mock *AccountingManager) CreatePeriod(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.AccountingPeriodCreateRequest) (*models.AccountingPeriod, error) {
+	if mock.CreatePeriodFunc == nil {
+		panic("AccountingManager.CreatePeriod is not stubbed")
+	}
+	return mock.CreatePeriodFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteAccount calls DeleteAccountFunc
+func (mock *AccountingManager) DeleteAccount(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, accountID uuid.UUID) error {
+	if mock.DeleteAccountFunc == nil {
+		panic("AccountingManager.DeleteAccount is not stubbed")
+	}
+	return mock.DeleteAccountFunc(ctx, tenantID, userID, accountID)
+}
+
+// DeleteEntry calls DeleteEntryFunc
+func (mock *AccountingManager) DeleteEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID) error {
+	if mock.DeleteEntryFunc == nil {
+		panic("AccountingManager.DeleteEntry is not stubbed")
+	}
+	return mock.DeleteEntryFunc(ctx, tenantID, userID, entryID)
+}
+
+// DescribeAccount calls DescribeAccountFunc
+func (mock *AccountingManager) DescribeAccount(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeAccountFunc == nil {
+		panic("AccountingManager.DescribeAccount is not stubbed")
+	}
+	return mock.DescribeAccountFunc(ctx, tenantID, accountID)
+}
+
+// DescribeEntry calls DescribeEntryFunc
+func (mock *AccountingManager) DescribeEntry(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeEntryFunc == nil {
+		panic("AccountingManager.DescribeEntry is not stubbed")
+	}
+	return mock.DescribeEntryFunc(ctx, tenantID, entryID)
+}
+
+// GetAccount calls GetAccountFunc
+func (mock *AccountingManager) GetAccount(ctx context.Context, tenantID uuid.UUID, accountID uuid.UUID) (*models.Account, error) {
+	if mock.GetAccountFunc == nil {
+		panic("AccountingManager.GetAccount is not stubbed")
+	}
+	return mock.GetAccountFunc(ctx, tenantID, accountID)
+}
+
+// GetEntry calls GetEntryFunc
+func (mock *AccountingManager) GetEntry(ctx context.Context, tenantID uuid.UUID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	if mock.GetEntryFunc == nil {
+		panic("AccountingManager.GetEntry is not stubbed")
+	}
+	return mock.GetEntryFunc(ctx, tenantID, entryID)
+}
+
+// GetPeriod calls GetPeriodFunc
+func (mock *AccountingManager) GetPeriod(ctx context.Context, tenantID uuid.UUID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	if mock.GetPeriodFunc == nil {
+		panic("AccountingManager.GetPeriod is not stubbed")
+	}
+	return mock.GetPeriodFunc(ctx, tenantID, periodID)
+}
+
+// ListAccounts calls ListAccountsFunc
+func (mock *AccountingManager) ListAccounts(ctx context.Context, tenantID uuid.UUID, accountType string, activeOnly bool, search string) ([]models.Account, error) {
+	if mock.ListAccountsFunc == nil {
+		panic("AccountingManager.ListAccounts is not stubbed")
+	}
+	return mock.ListAccountsFunc(ctx, tenantID, accountType, activeOnly, search)
+}
+
+// ListEntries calls ListEntriesFunc
+func (mock *AccountingManager) ListEntries(ctx context.Context, tenantID uuid.UUID, filter models.JournalEntryFilter, limit int, offset int) ([]models.JournalEntry, int, error) {
+	if mock.ListEntriesFunc == nil {
+		panic("AccountingManager.ListEntries is not stubbed")
+	}
+	return mock.ListEntriesFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListPeriods calls ListPeriodsFunc
+func (mock *AccountingManager) ListPeriods(ctx context.Context, tenantID uuid.UUID, status string) ([]models.AccountingPeriod, error) {
+	if mock.ListPeriodsFunc == nil {
+		panic("AccountingManager.ListPeriods is not stubbed")
+	}
+	return mock.ListPeriodsFunc(ctx, tenantID, status)
+}
+
+// PostEntry calls PostEntryFunc
+func (mock *AccountingManager) PostEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID) (*models.JournalEntry, error) {
+	if mock.PostEntryFunc == nil {
+		panic("AccountingManager.PostEntry is not stubbed")
+	}
+	return mock.PostEntryFunc(ctx, tenantID, userID, entryID)
+}
+
+// ReopenPeriod calls ReopenPeriodFunc
+func (mock *AccountingManager) ReopenPeriod(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, periodID uuid.UUID) (*models.AccountingPeriod, error) {
+	if mock.ReopenPeriodFunc == nil {
+		panic("AccountingManager.ReopenPeriod is not stubbed")
+	}
+	return mock.ReopenPeriodFunc(ctx, tenantID, userID, periodID)
+}
+
+// ReverseEntry calls ReverseEntryFunc
+func (mock *AccountingManager) ReverseEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID, req *models.JournalEntryReverseRequest) (*models.JournalEntry, error) {
+	if mock.ReverseEntryFunc == nil {
+		panic("AccountingManager.ReverseEntry is not stubbed")
+	}
+	return mock.ReverseEntryFunc(ctx, tenantID, userID, entryID, req)
+}
+
+// TrialBalance calls TrialBalanceFunc
+func (mock *AccountingManager) TrialBalance(ctx context.Context, tenantID uuid.UUID, periodID *uuid.UUID, asOf *time.Time) (*models.TrialBalance, error) {
+	if mock.TrialBalanceFunc == nil {
+		panic("AccountingManager.TrialBalance is not stubbed")
+	}
+	return mock.TrialBalanceFunc(ctx, tenantID, periodID, asOf)
+}
+
+// UpdateAccount calls UpdateAccountFunc
+func (mock *AccountingManager) UpdateAccount(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, accountID uuid.UUID, req *models.AccountUpdateRequest) (*models.Account, error) {
+	if mock.UpdateAccountFunc == nil {
+		panic("AccountingManager.UpdateAccount is not stubbed")
+	}
+	return mock.UpdateAccountFunc(ctx, tenantID, userID, accountID, req)
+}
+
+// UpdateEntry calls UpdateEntryFunc
+func (mock *AccountingManager) UpdateEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID, req *models.JournalEntryUpdateRequest) (*models.JournalEntry, error) {
+	if mock.UpdateEntryFunc == nil {
+		panic("AccountingManager.UpdateEntry is not stubbed")
+	}
+	return mock.UpdateEntryFunc(ctx, tenantID, userID, entryID, req)
+}
+
+// AnnouncementManager is a mock of services.AnnouncementManager
+type AnnouncementManager struct {
+	CreateAnnouncementFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error)
+	DeleteAnnouncementFunc func(ctx context.Context, tenantID, announcementID uuid.UUID) error
+	GetAnnouncementFunc    func(ctx context.Context, tenantID, announcementID uuid.UUID) (*models.Announcement, error)
+	ListAnnouncementsFunc  func(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.Announcement, int, error)
+	ListForUserFunc        func(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.UserAnnouncement, int, error)
+	ListReadsFunc          func(ctx context.Context, tenantID, announcementID uuid.UUID, limit, offset int) ([]models.AnnouncementRead, int, error)
+	MarkReadFunc           func(ctx context.Context, tenantID, userID, announcementID uuid.UUID) error
+	PublishDueFunc         func(ctx context.Context) (int, error)
+	UpdateAnnouncementFunc func(ctx context.Context, tenantID, announcementID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error)
+}
+
+// CreateAnnouncement calls CreateAnnouncementFunc
+func (mock *AnnouncementManager) CreateAnnouncement(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	if mock.CreateAnnouncementFunc == nil {
+		panic("AnnouncementManager.CreateAnnouncement is not stubbed")
+	}
+	return mock.CreateAnnouncementFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteAnnouncement calls DeleteAnnouncementFunc
+func (mock *AnnouncementManager) DeleteAnnouncement(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID) error {
+	if mock.DeleteAnnouncementFunc == nil {
+		panic("AnnouncementManager.DeleteAnnouncement is not stubbed")
+	}
+	return mock.DeleteAnnouncementFunc(ctx, tenantID, announcementID)
+}
+
+// GetAnnouncement calls GetAnnouncementFunc
+func (mock *AnnouncementManager) GetAnnouncement(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID) (*models.Announcement, error) {
+	if mock.GetAnnouncementFunc == nil {
+		panic("AnnouncementManager.GetAnnouncement is not stubbed")
+	}
+	return mock.GetAnnouncementFunc(ctx, tenantID, announcementID)
+}
+
+// ListAnnouncements calls ListAnnouncementsFunc
+func (mock *AnnouncementManager) ListAnnouncements(ctx context.Context, tenantID uuid.UUID, status string, limit int, offset int) ([]models.Announcement, int, error) {
+	if mock.ListAnnouncementsFunc == nil {
+		panic("AnnouncementManager.ListAnnouncements is not stubbed")
+	}
+	return mock.ListAnnouncementsFunc(ctx, tenantID, status, limit, offset)
+}
+
+// ListForUser calls ListForUserFunc
+func (mock *AnnouncementManager) ListForUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, unreadOnly bool, limit int, offset int) ([]models.UserAnnouncement, int, error) {
+	if mock.ListForUserFunc == nil {
+		panic("AnnouncementManager.ListForUser is not stubbed")
+	}
+	return mock.ListForUserFunc(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// ListReads calls ListReadsFunc
+func (mock *AnnouncementManager) ListReads(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID, limit int, offset int) ([]models.AnnouncementRead, int, error) {
+	if mock.ListReadsFunc == nil {
+		panic("AnnouncementManager.ListReads is not stubbed")
+	}
+	return mock.ListReadsFunc(ctx, tenantID, announcementID, limit, offset)
+}
+
+// MarkRead calls MarkReadFunc
+func (mock *AnnouncementManager) MarkRead(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, announcementID uuid.UUID) error {
+	if mock.MarkReadFunc == nil {
+		panic("AnnouncementManager.MarkRead is not stubbed")
+	}
+	return mock.MarkReadFunc(ctx, tenantID, userID, announcementID)
+}
+
+// PublishDue calls PublishDueFunc
+func (mock *AnnouncementManager) PublishDue(ctx context.Context) (int, error) {
+	if mock.PublishDueFunc == nil {
+		panic("AnnouncementManager.PublishDue is not stubbed")
+	}
+	return mock.PublishDueFunc(ctx)
+}
+
+// UpdateAnnouncement calls UpdateAnnouncementFunc
+func (mock *AnnouncementManager) UpdateAnnouncement(ctx context.Context, tenantID uuid.UUID, announcementID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	if mock.UpdateAnnouncementFunc == nil {
+		panic("AnnouncementManager.UpdateAnnouncement is not stubbed")
+	}
+	return mock.UpdateAnnouncementFunc(ctx, tenantID, announcementID, req)
+}
+
+// ApprovalLinkManager is a mock of services.ApprovalLinkManager
+type ApprovalLinkManager struct {
+	CleanupExpiredFunc  func(ctx context.Context) (int, error)
+	DecideFunc          func(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest, ipAddress, userAgent string) (*models.ApprovalSubject, error)
+	PreviewFunc         func(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest) (*models.ApprovalLinkPreview, error)
+	RegisterTargetFunc  func(resourceType, resource string, describe services.ApprovalDescriber, decide services.ApprovalDecider)
+	RequestApprovalFunc func(ctx context.Context, tenantID, requestedBy uuid.UUID, resourceType string, resourceID uuid.UUID) error
+}
+
+// CleanupExpired calls CleanupExpiredFunc
+func (mock *ApprovalLinkManager) CleanupExpired(ctx context.Context) (int, error) {
+	if mock.CleanupExpiredFunc == nil {
+		panic("ApprovalLinkManager.CleanupExpired is not stubbed")
+	}
+	return mock.CleanupExpiredFunc(ctx)
+}
+
+// Decide calls DecideFunc
+func (mock *ApprovalLinkManager) Decide(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest, ipAddress string, userAgent string) (*models.ApprovalSubject, error) {
+	if mock.DecideFunc == nil {
+		panic("ApprovalLinkManager.Decide is not stubbed")
+	}
+	return mock.DecideFunc(ctx, tenantID, req, ipAddress, userAgent)
+}
+
+// Preview calls PreviewFunc
+func (mock *ApprovalLinkManager) Preview(ctx context.Context, tenantID uuid.UUID, req *models.ApprovalLinkRequest) (*models.ApprovalLinkPreview, error) {
+	if mock.PreviewFunc == nil {
+		panic("ApprovalLinkManager.Preview is not stubbed")
+	}
+	return mock.PreviewFunc(ctx, tenantID, req)
+}
+
+// RegisterTarget calls RegisterTargetFunc
+func (mock *ApprovalLinkManager) RegisterTarget(resourceType string, resource string, describe services.ApprovalDescriber, decide services.ApprovalDecider) {
+	if mock.RegisterTargetFunc == nil {
+		panic("ApprovalLinkManager.RegisterTarget is not stubbed")
+	}
+	mock.RegisterTargetFunc(resourceType, resource, describe, decide)
+}
+
+// RequestApproval calls RequestApprovalFunc
+func (mock *ApprovalLinkManager) RequestApproval(ctx context.Context, tenantID uuid.UUID, requestedBy uuid.UUID, resourceType string, resourceID uuid.UUID) error {
+	if mock.RequestApprovalFunc == nil {
+		panic("ApprovalLinkManager.RequestApproval is not stubbed")
+	}
+	return mock.RequestApprovalFunc(ctx, tenantID, requestedBy, resourceType, resourceID)
+}
+
+// AsyncJobManager is a mock of services.AsyncJobManager
+type AsyncJobManager struct {
+	CancelFunc              func(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	CleanupFinishedJobsFunc func(ctx context.Context) (int, error)
+	EnqueueFunc             func(ctx context.Context, tx *sqlx.Tx, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error)
+	GetJobFunc              func(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	ListJobsFunc            func(ctx context.Context, tenantID, userID uuid.UUID, jobType, status string, limit, offset int) ([]models.AsyncJob, int, error)
+	ProcessQueueFunc        func(ctx context.Context) (int, error)
+	RegisterTypeFunc        func(jobType, resource string, run services.AsyncJobFunc)
+	SubmitFunc              func(ctx context.Context, tenantID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error)
+	SubscribeFunc           func(ctx context.Context, tenantID, jobID uuid.UUID) *redis.PubSub
+}
+
+// Cancel calls CancelFunc
+func (mock *AsyncJobManager) Cancel(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.CancelFunc == nil {
+		panic("AsyncJobManager.Cancel is not stubbed")
+	}
+	return mock.CancelFunc(ctx, tenantID, userID, jobID)
+}
+
+// CleanupFinishedJobs calls CleanupFinishedJobsFunc
+func (mock *AsyncJobManager) CleanupFinishedJobs(ctx context.Context) (int, error) {
+	if mock.CleanupFinishedJobsFunc == nil {
+		panic("AsyncJobManager.CleanupFinishedJobs is not stubbed")
+	}
+	return mock.CleanupFinishedJobsFunc(ctx)
+}
+
+// Enqueue calls EnqueueFunc
+func (mock *AsyncJobManager) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error) {
+	if mock.EnqueueFunc == nil {
+		panic("AsyncJobManager.Enqueue is not stubbed")
+	}
+	return mock.EnqueueFunc(ctx, tx, tenantID, userID, jobType, params)
+}
+
+// GetJob calls GetJobFunc
+func (mock *AsyncJobManager) GetJob(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.GetJobFunc == nil {
+		panic("AsyncJobManager.GetJob is not stubbed")
+	}
+	return mock.GetJobFunc(ctx, tenantID, userID, jobID)
+}
+
+// ListJobs calls ListJobsFunc
+func (mock *AsyncJobManager) ListJobs(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobType string, status string, limit int, offset int) ([]models.AsyncJob, int, error) {
+	if mock.ListJobsFunc == nil {
+		panic("AsyncJobManager.ListJobs is not stubbed")
+	}
+	return mock.ListJobsFunc(ctx, tenantID, userID, jobType, status, limit, offset)
+}
+
+// ProcessQueue calls ProcessQueueFunc
+func (mock *AsyncJobManager) ProcessQueue(ctx context.Context) (int, error) {
+	if mock.ProcessQueueFunc == nil {
+		panic("AsyncJobManager.ProcessQueue is not stubbed")
+	}
+	return mock.ProcessQueueFunc(ctx)
+}
+
+// RegisterType calls RegisterTypeFunc
+func (mock *AsyncJobManager) RegisterType(jobType string, resource string, run services.AsyncJobFunc) {
+	if mock.RegisterTypeFunc == nil {
+		panic("AsyncJobManager.RegisterType is not stubbed")
+	}
+	mock.RegisterTypeFunc(jobType, resource, run)
+}
+
+// Submit calls SubmitFunc
+func (mock *AsyncJobManager) Submit(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobType string, params interface{}) (*models.AsyncJob, error) {
+	if mock.SubmitFunc == nil {
+		panic("AsyncJobManager.Submit is not stubbed")
+	}
+	return mock.SubmitFunc(ctx, tenantID, userID, jobType, params)
+}
+
+// Subscribe calls SubscribeFunc
+func (mock *AsyncJobManager) Subscribe(ctx context.Context, tenantID uuid.UUID, jobID uuid.UUID) *redis.PubSub {
+	if mock.SubscribeFunc == nil {
+		panic("AsyncJobManager.Subscribe is not stubbed")
+	}
+	return mock.SubscribeFunc(ctx, tenantID, jobID)
+}
+
+// AuditManager is a mock of services.AuditManager
+type AuditManager struct {
+	DiffFunc   func(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditDiff, error)
+	ExportFunc func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filters services.AuditFilters, fn func(*models.AuditLog) error,
+	) error
+	GetActionStatsFunc    func(ctx context.Context, tenantID uuid.UUID, startDate, endDate time.Time) (map[string]int, error)
+	GetByIDFunc           func(ctx context.Context, tenantID, logID uuid.UUID) (*models.AuditLog, error)
+	GetFailedAttemptsFunc func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		userID *uuid.UUID,
+		since time.Time,
+	) ([]models.AuditLog, error)
+	GetRecentActionsFunc func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		resourceType string,
+		resourceID uuid.UUID,
+		limit int,
+	) ([]models.AuditLog, error)
+	GetSuspiciousActivityFunc func(ctx context.Context, tenantID uuid.UUID, since time.Time) ([]map[string]interface{}, error)
+	GetUserActivityFunc       func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		limit int,
+	) ([]models.AuditLog, error)
+	LogEventFunc func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		action, resourceType string,
+		resourceID uuid.UUID,
+		status string,
+		ipAddress, userAgent string,
+		metadata map[string]interface{},
+	) error
+	QueryFunc func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filters services.AuditFilters, page *pagination.Request,
+	) ([]models.AuditLog, int, string, error)
+	SearchFunc func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		keyword string,
+		limit, offset int,
+	) ([]models.AuditLog, int, error)
+}
+
+// Diff calls DiffFunc
+func (mock *AuditManager) Diff(ctx context.Context, tenantID uuid.UUID, logID uuid.UUID) (*models.AuditDiff, error) {
+	if mock.DiffFunc == nil {
+		panic("AuditManager.Diff is not stubbed")
+	}
+	return mock.DiffFunc(ctx, tenantID, logID)
+}
+
+// Export calls ExportFunc
+func (mock *AuditManager) Export(ctx context.Context, tenantID uuid.UUID, filters services.AuditFilters, fn func(*models.AuditLog) error) error {
+	if mock.ExportFunc == nil {
+		panic("AuditManager.Export is not stubbed")
+	}
+	return mock.ExportFunc(ctx, tenantID, filters, fn)
+}
+
+// GetActionStats calls GetActionStatsFunc
+func (mock *AuditManager) GetActionStats(ctx context.Context, tenantID uuid.UUID, startDate time.Time, endDate time.Time) (map[string]int, error) {
+	if mock.GetActionStatsFunc == nil {
+		panic("AuditManager.GetActionStats is not stubbed")
+	}
+	return mock.GetActionStatsFunc(ctx, tenantID, startDate, endDate)
+}
+
+// GetByID calls GetByIDFunc
+func (mock *AuditManager) GetByID(ctx context.Context, tenantID uuid.UUID, logID uuid.UUID) (*models.AuditLog, error) {
+	if mock.GetByIDFunc == nil {
+		panic("AuditManager.GetByID is not stubbed")
+	}
+	return mock.GetByIDFunc(ctx, tenantID, logID)
+}
+
+// GetFailedAttempts calls GetFailedAttemptsFunc
+func (mock *AuditManager) GetFailedAttempts(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, since time.Time) ([]models.AuditLog, error) {
+	if mock.GetFailedAttemptsFunc == nil {
+		panic("AuditManager.GetFailedAttempts is not stubbed")
+	}
+	return mock.GetFailedAttemptsFunc(ctx, tenantID, userID, since)
+}
+
+// GetRecentActions calls GetRecentActionsFunc
+func (mock *AuditManager) GetRecentActions(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID, limit int) ([]models.AuditLog, error) {
+	if mock.GetRecentActionsFunc == nil {
+		panic("AuditManager.GetRecentActions is not stubbed")
+	}
+	return mock.GetRecentActionsFunc(ctx, tenantID, resourceType, resourceID, limit)
+}
+
+// GetSuspiciousActivity calls GetSuspiciousActivityFunc
+func (mock *AuditManager) GetSuspiciousActivity(ctx context.Context, tenantID uuid.UUID, since time.Time) ([]map[string]interface{}, error) {
+	if mock.GetSuspiciousActivityFunc == nil {
+		panic("AuditManager.GetSuspiciousActivity is not stubbed")
+	}
+	return mock.GetSuspiciousActivityFunc(ctx, tenantID, since)
+}
+
+// GetUserActivity calls GetUserActivityFunc
+func (mock *AuditManager) GetUserActivity(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]models.AuditLog, error) {
+	if mock.GetUserActivityFunc == nil {
+		panic("AuditManager.GetUserActivity is not stubbed")
+	}
+	return mock.GetUserActivityFunc(ctx, tenantID, userID, limit)
+}
+
+// LogEvent calls LogEventFunc
+func (mock *AuditManager) LogEvent(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, action string, resourceType string, resourceID uuid.UUID, status string, ipAddress string, userAgent string, metadata map[string]interface{}) error {
+	if mock.LogEventFunc == nil {
+		panic("AuditManager.LogEvent is not stubbed")
+	}
+	return mock.LogEventFunc(ctx, tenantID, userID, action, resourceType, resourceID, status, ipAddress, userAgent, metadata)
+}
+
+// Query calls QueryFunc
+func (mock *AuditManager) Query(ctx context.Context, tenantID uuid.UUID, filters services.AuditFilters, page *pagination.Request) ([]models.AuditLog, int, string, error) {
+	if mock.QueryFunc == nil {
+		panic("AuditManager.Query is not stubbed")
+	}
+	return mock.QueryFunc(ctx, tenantID, filters, page)
+}
+
+// Search calls SearchFunc
+func (mock *AuditManager) Search(ctx context.Context, tenantID uuid.UUID, keyword string, limit int, offset int) ([]models.AuditLog, int, error) {
+	if mock.SearchFunc == nil {
+		panic("AuditManager.Search is not stubbed")
+	}
+	return mock.SearchFunc(ctx, tenantID, keyword, limit, offset)
+}
+
+// AuthManager is a mock of services.AuthManager
+type AuthManager struct {
+	BeginTwoFactorSetupFunc        func(ctx context.Context, twoFactorToken string) (*services.TwoFactorSetup, error)
+	BeginWebAuthn2FAFunc           func(ctx context.Context, twoFactorToken string) (*models.WebAuthnRequestOptions, error)
+	BeginWebAuthnLoginFunc         func(ctx context.Context, tenantID uuid.UUID, email string) (*models.WebAuthnRequestOptions, error)
+	BeginWebAuthnRegistrationFunc  func(ctx context.Context, tenantID, userID uuid.UUID) (*models.WebAuthnCreationOptions, error)
+	ChangePasswordFunc             func(ctx context.Context, tenantID, userID uuid.UUID, currentPassword, newPassword string) error
+	CompleteSAMLFunc               func(ctx context.Context, providerSlug, relayState, samlResponse string) (string, string, error)
+	CompleteSSOFunc                func(ctx context.Context, providerSlug, stateParam, code string) (string, string, error)
+	ConfirmEmailChangeFunc         func(ctx context.Context, tenantID, token uuid.UUID, ipAddress, userAgent string) (*models.User, error)
+	CreateSSOProviderFunc          func(ctx context.Context, tenantID, userID uuid.UUID, req *models.SSOProviderCreateRequest) (*models.SSOProvider, error)
+	DeleteSSOProviderFunc          func(ctx context.Context, tenantID, providerID uuid.UUID) (*models.SSOProvider, error)
+	DeleteWebAuthnCredentialFunc   func(ctx context.Context, tenantID, userID, id uuid.UUID) (*models.WebAuthnCredential, error)
+	ExchangeSSOCodeFunc            func(ctx context.Context, code string, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishTwoFactorSetupFunc       func(ctx context.Context, req *models.TwoFactorSetupFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthn2FAFunc          func(ctx context.Context, req *models.WebAuthn2FAFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthnLoginFunc        func(ctx context.Context, tenantID uuid.UUID, req *models.WebAuthnLoginFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	FinishWebAuthnRegistrationFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error)
+	FlushSessionActivityFunc       func(ctx context.Context) (int, error)
+	ForcePasswordResetFunc         func(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error)
+	GetSSOProviderFunc             func(ctx context.Context, tenantID, providerID uuid.UUID) (*models.SSOProvider, error)
+	GetSSOSettingsFunc             func(ctx context.Context, tenantID uuid.UUID) (*models.SSOSettings, error)
+	GetSessionLimitFunc            func(ctx context.Context, tenantID uuid.UUID) (*models.SessionLimit, error)
+	GetTwoFactorPolicyFunc         func(ctx context.Context, tenantID uuid.UUID) (*models.TwoFactorPolicy, error)
+	GetTwoFactorStatusFunc         func(ctx context.Context, tenant *models.Tenant, user *models.User) (*models.TwoFactorStatus, error)
+	IssueTwoFABypassCodeFunc       func(ctx context.Context, tenantID, userID, issuerID uuid.UUID) (time.Time, error)
+	ListSSOLoginProvidersFunc      func(ctx context.Context, tenantSlug string) ([]models.SSOLoginProvider, bool, error)
+	ListWebAuthnCredentialsFunc    func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.WebAuthnCredential, error)
+	LoginFunc                      func(ctx context.Context, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	LoginWithTenantFunc            func(ctx context.Context, tenantID uuid.UUID, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+	LogoutFunc                     func(ctx context.Context, tenantID, userID uuid.UUID, accessToken string) error
+	LogoutAllFunc                  func(ctx context.Context, tenantID, userID uuid.UUID) error
+	RefreshTokenFunc               func(ctx context.Context, refreshToken string) (*models.UserLoginResponse, error)
+	RegisterTenantFunc             func(ctx context.Context, req *models.TenantCreateRequest) (*models.Tenant, error)
+	RemindTwoFactorSetupFunc       func(ctx context.Context) (int, error)
+	RenameWebAuthnCredentialFunc   func(ctx context.Context, tenantID, userID, id uuid.UUID, name string) (*models.WebAuthnCredential, error)
+	RequestEmailChangeFunc         func(ctx context.Context, tenantID, userID uuid.UUID, newEmail, password string) (*models.User, error)
+	RequestPasswordResetFunc       func(ctx context.Context, tenantID uuid.UUID, email string) error
+	RequestTwoFABypassCodeFunc     func(ctx context.Context, twoFactorToken string, deviceInfo utils.DeviceInfo, ipAddress string) (time.Time, error)
+	ResendTenantVerificationFunc   func(ctx context.Context, tenantID uuid.UUID) (*models.Tenant, error)
+	ResetPasswordFunc              func(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, newPassword string) error
+	SAMLMetadataFunc               func(ctx context.Context, tenantSlug, providerSlug string) ([]byte, error)
+	SSOFrontendURLFunc             func(query url.Values) string
+	SetPasskeyOnlyFunc             func(ctx context.Context, tenantID, userID uuid.UUID, enabled bool) error
+	StartSSOFunc                   func(ctx context.Context, tenantSlug, providerSlug string, rememberMe bool, redirect string) (string, error)
+	UpdateSSOProviderFunc          func(ctx context.Context, tenantID, providerID uuid.UUID, req *models.SSOProviderUpdateRequest) (*models.SSOProvider, error)
+	UpdateSSOSettingsFunc          func(ctx context.Context, tenantID uuid.UUID, req *models.SSOSettingsUpdateRequest) (*models.SSOSettings, error)
+	UpdateSessionLimitFunc         func(ctx context.Context, tenantID uuid.UUID, req *models.SessionLimitUpdateRequest) (*models.SessionLimit, error)
+	UpdateTwoFactorPolicyFunc      func(ctx context.Context, tenantID uuid.UUID, req *models.TwoFactorPolicyUpdateRequest) (*models.TwoFactorPolicy, error)
+	ValidateSessionFunc            func(ctx context.Context, accessToken string) (*models.User, *models.Tenant, error)
+	Verify2FAAndLoginFunc          func(ctx context.Context, twoFactorToken, code string, deviceInfo utils.DeviceInfo, ipAddress string, rememberMe bool) (*models.UserLoginResponse, error)
+	VerifyTenantEmailFunc          func(ctx context.Context, token uuid.UUID) (*models.Tenant, error)
+	VerifyTwoFABypassCodeFunc      func(ctx context.Context, twoFactorToken, code string, rememberMe bool, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error)
+}
+
+// BeginTwoFactorSetup calls BeginTwoFactorSetupFunc
+func (mock *AuthManager) BeginTwoFactorSetup(ctx context.Context, twoFactorToken string) (*services.TwoFactorSetup, error) {
+	if mock.BeginTwoFactorSetupFunc == nil {
+		panic("AuthManager.BeginTwoFactorSetup is not stubbed")
+	}
+	return mock.BeginTwoFactorSetupFunc(ctx, twoFactorToken)
+}
+
+// BeginWebAuthn2FA calls BeginWebAuthn2FAFunc
+func (mock *AuthManager) BeginWebAuthn2FA(ctx context.Context, twoFactorToken string) (*models.WebAuthnRequestOptions, error) {
+	if mock.BeginWebAuthn2FAFunc == nil {
+		panic("AuthManager.BeginWebAuthn2FA is not stubbed")
+	}
+	return mock.BeginWebAuthn2FAFunc(ctx, twoFactorToken)
+}
+
+// BeginWebAuthnLogin calls BeginWebAuthnLoginFunc
+func (mock *AuthManager) BeginWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, email string) (*models.WebAuthnRequestOptions, error) {
+	if mock.BeginWebAuthnLoginFunc == nil {
+		panic("AuthManager.BeginWebAuthnLogin is not stubbed")
+	}
+	return mock.BeginWebAuthnLoginFunc(ctx, tenantID, email)
+}
+
+// BeginWebAuthnRegistration calls BeginWebAuthnRegistrationFunc
+func (mock *AuthManager) BeginWebAuthnRegistration(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.WebAuthnCreationOptions, error) {
+	if mock.BeginWebAuthnRegistrationFunc == nil {
+		panic("AuthManager.BeginWebAuthnRegistration is not stubbed")
+	}
+	return mock.BeginWebAuthnRegistrationFunc(ctx, tenantID, userID)
+}
+
+// ChangePassword calls ChangePasswordFunc
+func (mock *AuthManager) ChangePassword(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, currentPassword string, newPassword string) error {
+	if mock.ChangePasswordFunc == nil {
+		panic("AuthManager.ChangePassword is not stubbed")
+	}
+	return mock.ChangePasswordFunc(ctx, tenantID, userID, currentPassword, newPassword)
+}
+
+// CompleteSAML calls CompleteSAMLFunc
+func (mock *AuthManager) CompleteSAML(ctx context.Context, providerSlug string, relayState string, samlResponse string) (string, string, error) {
+	if mock.CompleteSAMLFunc == nil {
+		panic("AuthManager.CompleteSAML is not stubbed")
+	}
+	return mock.CompleteSAMLFunc(ctx, providerSlug, relayState, samlResponse)
+}
+
+// CompleteSSO calls CompleteSSOFunc
+func (mock *AuthManager) CompleteSSO(ctx context.Context, providerSlug string, stateParam string, code string) (string, string, error) {
+	if mock.CompleteSSOFunc == nil {
+		panic("AuthManager.CompleteSSO is not stubbed")
+	}
+	return mock.CompleteSSOFunc(ctx, providerSlug, stateParam, code)
+}
+
+// ConfirmEmailChange calls ConfirmEmailChangeFunc
+func (mock *AuthManager) ConfirmEmailChange(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, ipAddress string, userAgent string) (*models.User, error) {
+	if mock.ConfirmEmailChangeFunc == nil {
+		panic("AuthManager.ConfirmEmailChange is not stubbed")
+	}
+	return mock.ConfirmEmailChangeFunc(ctx, tenantID, token, ipAddress, userAgent)
+}
+
+// CreateSSOProvider calls CreateSSOProviderFunc
+func (mock *AuthManager) CreateSSOProvider(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.SSOProviderCreateRequest) (*models.SSOProvider, error) {
+	if mock.CreateSSOProviderFunc == nil {
+		panic("AuthManager.CreateSSOProvider is not stubbed")
+	}
+	return mock.CreateSSOProviderFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteSSOProvider calls DeleteSSOProviderFunc
+func (mock *AuthManager) DeleteSSOProvider(ctx context.Context, tenantID uuid.UUID, providerID uuid.UUID) (*models.SSOProvider, error) {
+	if mock.DeleteSSOProviderFunc == nil {
+		panic("AuthManager.DeleteSSOProvider is not stubbed")
+	}
+	return mock.DeleteSSOProviderFunc(ctx, tenantID, providerID)
+}
+
+// DeleteWebAuthnCredential calls DeleteWebAuthnCredentialFunc
+func (mock *AuthManager) DeleteWebAuthnCredential(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID) (*models.WebAuthnCredential, error) {
+	if mock.DeleteWebAuthnCredentialFunc == nil {
+		panic("AuthManager.DeleteWebAuthnCredential is not stubbed")
+	}
+	return mock.DeleteWebAuthnCredentialFunc(ctx, tenantID, userID, id)
+}
+
+// ExchangeSSOCode calls ExchangeSSOCodeFunc
+func (mock *AuthManager) ExchangeSSOCode(ctx context.Context, code string, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.ExchangeSSOCodeFunc == nil {
+		panic("AuthManager.ExchangeSSOCode is not stubbed")
+	}
+	return mock.ExchangeSSOCodeFunc(ctx, code, deviceInfo, ipAddress)
+}
+
+// FinishTwoFactorSetup calls FinishTwoFactorSetupFunc
+func (mock *AuthManager) FinishTwoFactorSetup(ctx context.Context, req *models.TwoFactorSetupFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.FinishTwoFactorSetupFunc == nil {
+		panic("AuthManager.FinishTwoFactorSetup is not stubbed")
+	}
+	return mock.FinishTwoFactorSetupFunc(ctx, req, deviceInfo, ipAddress)
+}
+
+// FinishWebAuthn2FA calls FinishWebAuthn2FAFunc
+func (mock *AuthManager) FinishWebAuthn2FA(ctx context.Context, req *models.WebAuthn2FAFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.FinishWebAuthn2FAFunc == nil {
+		panic("AuthManager.FinishWebAuthn2FA is not stubbed")
+	}
+	return mock.FinishWebAuthn2FAFunc(ctx, req, deviceInfo, ipAddress)
+}
+
+// FinishWebAuthnLogin calls FinishWebAuthnLoginFunc
+func (mock *AuthManager) FinishWebAuthnLogin(ctx context.Context, tenantID uuid.UUID, req *models.WebAuthnLoginFinishRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.FinishWebAuthnLoginFunc == nil {
+		panic("AuthManager.FinishWebAuthnLogin is not stubbed")
+	}
+	return mock.FinishWebAuthnLoginFunc(ctx, tenantID, req, deviceInfo, ipAddress)
+}
+
+// FinishWebAuthnRegistration calls FinishWebAuthnRegistrationFunc
+func (mock *AuthManager) FinishWebAuthnRegistration(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error) {
+	if mock.FinishWebAuthnRegistrationFunc == nil {
+		panic("AuthManager.FinishWebAuthnRegistration is not stubbed")
+	}
+	return mock.FinishWebAuthnRegistrationFunc(ctx, tenantID, userID, req)
+}
+
+// FlushSessionActivity calls FlushSessionActivityFunc
+func (mock *AuthManager) FlushSessionActivity(ctx context.Context) (int, error) {
+	if mock.FlushSessionActivityFunc == nil {
+		panic("AuthManager.FlushSessionActivity is not stubbed")
+	}
+	return mock.FlushSessionActivityFunc(ctx)
+}
+
+// ForcePasswordReset calls ForcePasswordResetFunc
+func (mock *AuthManager) ForcePasswordReset(ctx context.Context, tenantID uuid.UUID, email string) (*models.User, error) {
+	if mock.ForcePasswordResetFunc == nil {
+		panic("AuthManager.ForcePasswordReset is not stubbed")
+	}
+	return mock.ForcePasswordResetFunc(ctx, tenantID, email)
+}
+
+// GetSSOProvider calls GetSSOProviderFunc
+func (mock *AuthManager) GetSSOProvider(ctx context.Context, tenantID uuid.UUID, providerID uuid.UUID) (*models.SSOProvider, error) {
+	if mock.GetSSOProviderFunc == nil {
+		panic("AuthManager.GetSSOProvider is not stubbed")
+	}
+	return mock.GetSSOProviderFunc(ctx, tenantID, providerID)
+}
+
+// GetSSOSettings calls GetSSOSettingsFunc
+func (mock *AuthManager) GetSSOSettings(ctx context.Context, tenantID uuid.UUID) (*models.SSOSettings, error) {
+	if mock.GetSSOSettingsFunc == nil {
+		panic("AuthManager.GetSSOSettings is not stubbed")
+	}
+	return mock.GetSSOSettingsFunc(ctx, tenantID)
+}
+
+// GetSessionLimit calls GetSessionLimitFunc
+func (mock *AuthManager) GetSessionLimit(ctx context.Context, tenantID uuid.UUID) (*models.SessionLimit, error) {
+	if mock.GetSessionLimitFunc == nil {
+		panic("AuthManager.GetSessionLimit is not stubbed")
+	}
+	return mock.GetSessionLimitFunc(ctx, tenantID)
+}
+
+// GetTwoFactorPolicy calls GetTwoFactorPolicyFunc
+func (mock *AuthManager) GetTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID) (*models.TwoFactorPolicy, error) {
+	if mock.GetTwoFactorPolicyFunc == nil {
+		panic("AuthManager.GetTwoFactorPolicy is not stubbed")
+	}
+	return mock.GetTwoFactorPolicyFunc(ctx, tenantID)
+}
+
+// GetTwoFactorStatus calls GetTwoFactorStatusFunc
+func (mock *AuthManager) GetTwoFactorStatus(ctx context.Context, tenant *models.Tenant, user *models.User) (*models.TwoFactorStatus, error) {
+	if mock.GetTwoFactorStatusFunc == nil {
+		panic("AuthManager.GetTwoFactorStatus is not stubbed")
+	}
+	return mock.GetTwoFactorStatusFunc(ctx, tenant, user)
+}
+
+// IssueTwoFABypassCode calls IssueTwoFABypassCodeFunc
+func (mock *AuthManager) IssueTwoFABypassCode(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, issuerID uuid.UUID) (time.Time, error) {
+	if mock.IssueTwoFABypassCodeFunc == nil {
+		panic("AuthManager.IssueTwoFABypassCode is not stubbed")
+	}
+	return mock.IssueTwoFABypassCodeFunc(ctx, tenantID, userID, issuerID)
+}
+
+// ListSSOLoginProviders calls ListSSOLoginProvidersFunc
+func (mock *AuthManager) ListSSOLoginProviders(ctx context.Context, tenantSlug string) ([]models.SSOLoginProvider, bool, error) {
+	if mock.ListSSOLoginProvidersFunc == nil {
+		panic("AuthManager.ListSSOLoginProviders is not stubbed")
+	}
+	return mock.ListSSOLoginProvidersFunc(ctx, tenantSlug)
+}
+
+// ListWebAuthnCredentials calls ListWebAuthnCredentialsFunc
+func (mock *AuthManager) ListWebAuthnCredentials(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.WebAuthnCredential, error) {
+	if mock.ListWebAuthnCredentialsFunc == nil {
+		panic("AuthManager.ListWebAuthnCredentials is not stubbed")
+	}
+	return mock.ListWebAuthnCredentialsFunc(ctx, tenantID, userID)
+}
+
+// Login calls LoginFunc
+func (mock *AuthManager) Login(ctx context.Context, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.LoginFunc == nil {
+		panic("AuthManager.Login is not stubbed")
+	}
+	return mock.LoginFunc(ctx, req, deviceInfo, ipAddress)
+}
+
+// LoginWithTenant calls LoginWithTenantFunc
+func (mock *AuthManager) LoginWithTenant(ctx context.Context, tenantID uuid.UUID, req *models.UserLoginRequest, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.LoginWithTenantFunc == nil {
+		panic("AuthManager.LoginWithTenant is not stubbed")
+	}
+	return mock.LoginWithTenantFunc(ctx, tenantID, req, deviceInfo, ipAddress)
+}
+
+// Logout calls LogoutFunc
+func (mock *AuthManager) Logout(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, accessToken string) error {
+	if mock.LogoutFunc == nil {
+		panic("AuthManager.Logout is not stubbed")
+	}
+	return mock.LogoutFunc(ctx, tenantID, userID, accessToken)
+}
+
+// LogoutAll calls LogoutAllFunc
+func (mock *AuthManager) LogoutAll(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.LogoutAllFunc == nil {
+		panic("AuthManager.LogoutAll is not stubbed")
+	}
+	return mock.LogoutAllFunc(ctx, tenantID, userID)
+}
+
+// RefreshToken calls RefreshTokenFunc
+func (mock *AuthManager) RefreshToken(ctx context.Context, refreshToken string) (*models.UserLoginResponse, error) {
+	if mock.RefreshTokenFunc == nil {
+		panic("AuthManager.RefreshToken is not stubbed")
+	}
+	return mock.RefreshTokenFunc(ctx, refreshToken)
+}
+
+// RegisterTenant calls RegisterTenantFunc
+func (mock *AuthManager) RegisterTenant(ctx context.Context, req *models.TenantCreateRequest) (*models.Tenant, error) {
+	if mock.RegisterTenantFunc == nil {
+		panic("AuthManager.RegisterTenant is not stubbed")
+	}
+	return mock.RegisterTenantFunc(ctx, req)
+}
+
+// RemindTwoFactorSetup calls RemindTwoFactorSetupFunc
+func (mock *AuthManager) RemindTwoFactorSetup(ctx context.Context) (int, error) {
+	if mock.RemindTwoFactorSetupFunc == nil {
+		panic("AuthManager.RemindTwoFactorSetup is not stubbed")
+	}
+	return mock.RemindTwoFactorSetupFunc(ctx)
+}
+
+// RenameWebAuthnCredential calls RenameWebAuthnCredentialFunc
+func (mock *AuthManager) RenameWebAuthnCredential(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID, name string) (*models.WebAuthnCredential, error) {
+	if mock.RenameWebAuthnCredentialFunc == nil {
+		panic("AuthManager.RenameWebAuthnCredential is not stubbed")
+	}
+	return mock.RenameWebAuthnCredentialFunc(ctx, tenantID, userID, id, name)
+}
+
+// RequestEmailChange calls RequestEmailChangeFunc
+func (mock *AuthManager) RequestEmailChange(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, newEmail string, password string) (*models.User, error) {
+	if mock.RequestEmailChangeFunc == nil {
+		panic("AuthManager.RequestEmailChange is not stubbed")
+	}
+	return mock.RequestEmailChangeFunc(ctx, tenantID, userID, newEmail, password)
+}
+
+// RequestPasswordReset calls RequestPasswordResetFunc
+func (mock *AuthManager) RequestPasswordReset(ctx context.Context, tenantID uuid.UUID, email string) error {
+	if mock.RequestPasswordResetFunc == nil {
+		panic("AuthManager.RequestPasswordReset is not stubbed")
+	}
+	return mock.RequestPasswordResetFunc(ctx, tenantID, email)
+}
+
+// RequestTwoFABypassCode calls RequestTwoFABypassCodeFunc
+func (mock *AuthManager) RequestTwoFABypassCode(ctx context.Context, twoFactorToken string, deviceInfo utils.DeviceInfo, ipAddress string) (time.Time, error) {
+	if mock.RequestTwoFABypassCodeFunc == nil {
+		panic("AuthManager.RequestTwoFABypassCode is not stubbed")
+	}
+	return mock.RequestTwoFABypassCodeFunc(ctx, twoFactorToken, deviceInfo, ipAddress)
+}
+
+// ResendTenantVerification calls ResendTenantVerificationFunc
+func (mock *AuthManager) ResendTenantVerification(ctx context.Context, tenantID uuid.UUID) (*models.Tenant, error) {
+	if mock.ResendTenantVerificationFunc == nil {
+		panic("AuthManager.ResendTenantVerification is not stubbed")
+	}
+	return mock.ResendTenantVerificationFunc(ctx, tenantID)
+}
+
+// ResetPassword calls ResetPasswordFunc
+func (mock *AuthManager) ResetPassword(ctx context.Context, tenantID uuid.UUID, token uuid.UUID, newPassword string) error {
+	if mock.ResetPasswordFunc == nil {
+		panic("AuthManager.ResetPassword is not stubbed")
+	}
+	return mock.ResetPasswordFunc(ctx, tenantID, token, newPassword)
+}
+
+// SAMLMetadata calls SAMLMetadataFunc
+func (mock *AuthManager) SAMLMetadata(ctx context.Context, tenantSlug string, providerSlug string) ([]byte, error) {
+	if mock.SAMLMetadataFunc == nil {
+		panic("AuthManager.SAMLMetadata is not stubbed")
+	}
+	return mock.SAMLMetadataFunc(ctx, tenantSlug, providerSlug)
+}
+
+// SSOFrontendURL calls SSOFrontendURLFunc
+func (mock *AuthManager) SSOFrontendURL(query url.Values) string {
+	if mock.SSOFrontendURLFunc == nil {
+		panic("AuthManager.SSOFrontendURL is not stubbed")
+	}
+	return mock.SSOFrontendURLFunc(query)
+}
+
+// SetPasskeyOnly calls SetPasskeyOnlyFunc
+func (mock *AuthManager) SetPasskeyOnly(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, enabled bool) error {
+	if mock.SetPasskeyOnlyFunc == nil {
+		panic("AuthManager.SetPasskeyOnly is not stubbed")
+	}
+	return mock.SetPasskeyOnlyFunc(ctx, tenantID, userID, enabled)
+}
+
+// StartSSO calls StartSSOFunc
+func (mock *AuthManager) StartSSO(ctx context.Context, tenantSlug string, providerSlug string, rememberMe bool, redirect string) (string, error) {
+	if mock.StartSSOFunc == nil {
+		panic("AuthManager.StartSSO is not stubbed")
+	}
+	return mock.StartSSOFunc(ctx, tenantSlug, providerSlug, rememberMe, redirect)
+}
+
+// UpdateSSOProvider calls UpdateSSOProviderFunc
+func (mock *AuthManager) UpdateSSOProvider(ctx context.Context, tenantID uuid.UUID, providerID uuid.UUID, req *models.SSOProviderUpdateRequest) (*models.SSOProvider, error) {
+	if mock.UpdateSSOProviderFunc == nil {
+		panic("AuthManager.UpdateSSOProvider is not stubbed")
+	}
+	return mock.UpdateSSOProviderFunc(ctx, tenantID, providerID, req)
+}
+
+// UpdateSSOSettings calls UpdateSSOSettingsFunc
+func (mock *AuthManager) UpdateSSOSettings(ctx context.Context, tenantID uuid.UUID, req *models.SSOSettingsUpdateRequest) (*models.SSOSettings, error) {
+	if mock.UpdateSSOSettingsFunc == nil {
+		panic("AuthManager.UpdateSSOSettings is not stubbed")
+	}
+	return mock.UpdateSSOSettingsFunc(ctx, tenantID, req)
+}
+
+// UpdateSessionLimit calls UpdateSessionLimitFunc
+func (mock *AuthManager) UpdateSessionLimit(ctx context.Context, tenantID uuid.UUID, req *models.SessionLimitUpdateRequest) (*models.SessionLimit, error) {
+	if mock.UpdateSessionLimitFunc == nil {
+		panic("AuthManager.UpdateSessionLimit is not stubbed")
+	}
+	return mock.UpdateSessionLimitFunc(ctx, tenantID, req)
+}
+
+// UpdateTwoFactorPolicy calls UpdateTwoFactorPolicyFunc
+func (mock *AuthManager) UpdateTwoFactorPolicy(ctx context.Context, tenantID uuid.UUID, req *models.TwoFactorPolicyUpdateRequest) (*models.TwoFactorPolicy, error) {
+	if mock.UpdateTwoFactorPolicyFunc == nil {
+		panic("AuthManager.UpdateTwoFactorPolicy is not stubbed")
+	}
+	return mock.UpdateTwoFactorPolicyFunc(ctx, tenantID, req)
+}
+
+// ValidateSession calls ValidateSessionFunc
+func (mock *AuthManager) ValidateSession(ctx context.Context, accessToken string) (*models.User, *models.Tenant, error) {
+	if mock.ValidateSessionFunc == nil {
+		panic("AuthManager.ValidateSession is not stubbed")
+	}
+	return mock.ValidateSessionFunc(ctx, accessToken)
+}
+
+// Verify2FAAndLogin calls Verify2FAAndLoginFunc
+func (mock *AuthManager) Verify2FAAndLogin(ctx context.Context, twoFactorToken string, code string, deviceInfo utils.DeviceInfo, ipAddress string, rememberMe bool) (*models.UserLoginResponse, error) {
+	if mock.Verify2FAAndLoginFunc == nil {
+		panic("AuthManager.Verify2FAAndLogin is not stubbed")
+	}
+	return mock.Verify2FAAndLoginFunc(ctx, twoFactorToken, code, deviceInfo, ipAddress, rememberMe)
+}
+
+// VerifyTenantEmail calls VerifyTenantEmailFunc
+func (mock *AuthManager) VerifyTenantEmail(ctx context.Context, token uuid.UUID) (*models.Tenant, error) {
+	if mock.VerifyTenantEmailFunc == nil {
+		panic("AuthManager.VerifyTenantEmail is not stubbed")
+	}
+	return mock.VerifyTenantEmailFunc(ctx, token)
+}
+
+// VerifyTwoFABypassCode calls VerifyTwoFABypassCodeFunc
+func (mock *AuthManager) VerifyTwoFABypassCode(ctx context.Context, twoFactorToken string, code string, rememberMe bool, deviceInfo utils.DeviceInfo, ipAddress string) (*models.UserLoginResponse, error) {
+	if mock.VerifyTwoFABypassCodeFunc == nil {
+		panic("AuthManager.VerifyTwoFABypassCode is not stubbed")
+	}
+	return mock.VerifyTwoFABypassCodeFunc(ctx, twoFactorToken, code, rememberMe, deviceInfo, ipAddress)
+}
+
+// AutomationManager is a mock of services.AutomationManager
+type AutomationManager struct {
+	CleanupExecutionsFunc func(ctx context.Context) (int, error)
+	CreateRuleFunc        func(ctx context.Context, tenantID, userID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error)
+	DeleteRuleFunc        func(ctx context.Context, tenantID, ruleID uuid.UUID) error
+	GetExecutionFunc      func(ctx context.Context, tenantID, executionID uuid.UUID) (*models.AutomationExecution, error)
+	GetRuleFunc           func(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.AutomationRule, error)
+	HandleEventFunc       func(ctx context.Context, event *models.Event) error
+	ListExecutionsFunc    func(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit, offset int) ([]models.AutomationExecution, int, error)
+	ListRulesFunc         func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.AutomationRule, int, error)
+	ProcessQueueFunc      func(ctx context.Context) (int, error)
+	SetRuleEnabledFunc    func(ctx context.Context, tenantID, ruleID uuid.UUID, enabled bool) (*models.AutomationRule, error)
+	UpdateRuleFunc        func(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error)
+}
+
+// CleanupExecutions calls CleanupExecutionsFunc
+func (mock *AutomationManager) CleanupExecutions(ctx context.Context) (int, error) {
+	if mock.CleanupExecutionsFunc == nil {
+		panic("AutomationManager.CleanupExecutions is not stubbed")
+	}
+	return mock.CleanupExecutionsFunc(ctx)
+}
+
+// CreateRule calls CreateRuleFunc
+func (mock *AutomationManager) CreateRule(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error) {
+	if mock.CreateRuleFunc == nil {
+		panic("AutomationManager.CreateRule is not stubbed")
+	}
+	return mock.CreateRuleFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteRule calls DeleteRuleFunc
+func (mock *AutomationManager) DeleteRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID) error {
+	if mock.DeleteRuleFunc == nil {
+		panic("AutomationManager.DeleteRule is not stubbed")
+	}
+	return mock.DeleteRuleFunc(ctx, tenantID, ruleID)
+}
+
+// GetExecution calls GetExecutionFunc
+func (mock *AutomationManager) GetExecution(ctx context.Context, tenantID uuid.UUID, executionID uuid.UUID) (*models.AutomationExecution, error) {
+	if mock.GetExecutionFunc == nil {
+		panic("AutomationManager.GetExecution is not stubbed")
+	}
+	return mock.GetExecutionFunc(ctx, tenantID, executionID)
+}
+
+// GetRule calls GetRuleFunc
+func (mock *AutomationManager) GetRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID) (*models.AutomationRule, error) {
+	if mock.GetRuleFunc == nil {
+		panic("AutomationManager.GetRule is not stubbed")
+	}
+	return mock.GetRuleFunc(ctx, tenantID, ruleID)
+}
+
+// HandleEvent calls HandleEventFunc
+func (mock *AutomationManager) HandleEvent(ctx context.Context, event *models.Event) error {
+	if mock.HandleEventFunc == nil {
+		panic("AutomationManager.HandleEvent is not stubbed")
+	}
+	return mock.HandleEventFunc(ctx, event)
+}
+
+// ListExecutions calls ListExecutionsFunc
+func (mock *AutomationManager) ListExecutions(ctx context.Context, tenantID uuid.UUID, ruleID *uuid.UUID, status string, limit int, offset int) ([]models.AutomationExecution, int, error) {
+	if mock.ListExecutionsFunc == nil {
+		panic("AutomationManager.ListExecutions is not stubbed")
+	}
+	return mock.ListExecutionsFunc(ctx, tenantID, ruleID, status, limit, offset)
+}
+
+// ListRules calls ListRulesFunc
+func (mock *AutomationManager) ListRules(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.AutomationRule, int, error) {
+	if mock.ListRulesFunc == nil {
+		panic("AutomationManager.ListRules is not stubbed")
+	}
+	return mock.ListRulesFunc(ctx, tenantID, limit, offset)
+}
+
+// ProcessQueue calls ProcessQueueFunc
+func (mock *AutomationManager) ProcessQueue(ctx context.Context) (int, error) {
+	if mock.ProcessQueueFunc == nil {
+		panic("AutomationManager.ProcessQueue is not stubbed")
+	}
+	return mock.ProcessQueueFunc(ctx)
+}
+
+// SetRuleEnabled calls SetRuleEnabledFunc
+func (mock *AutomationManager) SetRuleEnabled(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID, enabled bool) (*models.AutomationRule, error) {
+	if mock.SetRuleEnabledFunc == nil {
+		panic("AutomationManager.SetRuleEnabled is not stubbed")
+	}
+	return mock.SetRuleEnabledFunc(ctx, tenantID, ruleID, enabled)
+}
+
+// UpdateRule calls UpdateRuleFunc
+func (mock *AutomationManager) UpdateRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID, req *models.AutomationRuleRequest) (*models.AutomationRule, error) {
+	if mock.UpdateRuleFunc == nil {
+		panic("AutomationManager.UpdateRule is not stubbed")
+	}
+	return mock.UpdateRuleFunc(ctx, tenantID, ruleID, req)
+}
+
+// AvatarManager is a mock of services.AvatarManager
+type AvatarManager struct {
+	MaxFileSizeFunc func() int64
+	RemoveFunc      func(ctx context.Context, tenantID, actorID, userID uuid.UUID) (*models.User, error)
+	UploadFunc      func(ctx context.Context, tenantID, actorID, userID uuid.UUID, data []byte) (*models.User, *models.UserAvatar, error)
+}
+
+// MaxFileSize calls MaxFileSizeFunc
+func (mock *AvatarManager) MaxFileSize() int64 {
+	if mock.MaxFileSizeFunc == nil {
+		panic("AvatarManager.MaxFileSize is not stubbed")
+	}
+	return mock.MaxFileSizeFunc()
+}
+
+// Remove calls RemoveFunc
+func (mock *AvatarManager) Remove(ctx context.Context, tenantID uuid.UUID, actorID uuid.UUID, userID uuid.UUID) (*models.User, error) {
+	if mock.RemoveFunc == nil {
+		panic("AvatarManager.Remove is not stubbed")
+	}
+	return mock.RemoveFunc(ctx, tenantID, actorID, userID)
+}
+
+// Upload calls UploadFunc
+func (mock *AvatarManager) Upload(ctx context.Context, tenantID uuid.UUID, actorID uuid.UUID, userID uuid.UUID, data []byte) (*models.User, *models.UserAvatar, error) {
+	if mock.UploadFunc == nil {
+		panic("AvatarManager.Upload is not stubbed")
+	}
+	return mock.UploadFunc(ctx, tenantID, actorID, userID, data)
+}
+
+// BillingManager is a mock of services.BillingManager
+type BillingManager struct {
+	ChangePlanFunc    func(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingStatus, error)
+	CheckoutFunc      func(ctx context.Context, tenantID, userID uuid.UUID, tier string) (*models.BillingCheckout, error)
+	GetStatusFunc     func(ctx context.Context, tenantID uuid.UUID) (*models.BillingStatus, error)
+	HandleWebhookFunc func(ctx context.Context, header http.Header, body []byte) error
+	HasFeatureFunc    func(ctx context.Context, tenantID uuid.UUID, feature string) (bool, string, error)
+	PlanFunc          func(tier string) *models.Plan
+	PlansFunc         func() []models.Plan
+}
+
+// ChangePlan calls ChangePlanFunc
+func (mock *BillingManager) ChangePlan(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, tier string) (*models.BillingStatus, error) {
+	if mock.ChangePlanFunc == nil {
+		panic("BillingManager.ChangePlan is not stubbed")
+	}
+	return mock.ChangePlanFunc(ctx, tenantID, userID, tier)
+}
+
+// Checkout calls CheckoutFunc
+func (mock *BillingManager) Checkout(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, tier string) (*models.BillingCheckout, error) {
+	if mock.CheckoutFunc == nil {
+		panic("BillingManager.Checkout is not stubbed")
+	}
+	return mock.CheckoutFunc(ctx, tenantID, userID, tier)
+}
+
+// GetStatus calls GetStatusFunc
+func (mock *BillingManager) GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.BillingStatus, error) {
+	if mock.GetStatusFunc == nil {
+		panic("BillingManager.GetStatus is not stubbed")
+	}
+	return mock.GetStatusFunc(ctx, tenantID)
+}
+
+// HandleWebhook calls HandleWebhookFunc
+func (mock *BillingManager) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	if mock.HandleWebhookFunc == nil {
+		panic("BillingManager.HandleWebhook is not stubbed")
+	}
+	return mock.HandleWebhookFunc(ctx, header, body)
+}
+
+// HasFeature calls HasFeatureFunc
+func (mock *BillingManager) HasFeature(ctx context.Context, tenantID uuid.UUID, feature string) (bool, string, error) {
+	if mock.HasFeatureFunc == nil {
+		panic("BillingManager.HasFeature is not stubbed")
+	}
+	return mock.HasFeatureFunc(ctx, tenantID, feature)
+}
+
+// Plan calls PlanFunc
+func (mock *BillingManager) Plan(tier string) *models.Plan {
+	if mock.PlanFunc == nil {
+		panic("BillingManager.Plan is not stubbed")
+	}
+	return mock.PlanFunc(tier)
+}
+
+// Plans calls PlansFunc
+func (mock *BillingManager) Plans() []models.Plan {
+	if mock.PlansFunc == nil {
+		panic("BillingManager.Plans is not stubbed")
+	}
+	return mock.PlansFunc()
+}
+
+// BroadcastManager is a mock of services.BroadcastManager
+type BroadcastManager struct {
+	CancelBroadcastFunc func(ctx context.Context, tenantID, userID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	CreateBroadcastFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.BroadcastCreateRequest) (*models.EmailBroadcast, error)
+	GetBroadcastFunc    func(ctx context.Context, tenantID, broadcastID uuid.UUID) (*models.EmailBroadcast, error)
+	ListBroadcastsFunc  func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EmailBroadcast, int, error)
+	ListRecipientsFunc  func(ctx context.Context, tenantID, broadcastID uuid.UUID, deliveryStatus string, limit, offset int) ([]models.BroadcastRecipient, int, error)
+	ProcessQueueFunc    func(ctx context.Context) (int, error)
+	UnsubscribeFunc     func(ctx context.Context, tenantID, token uuid.UUID) error
+}
+
+// CancelBroadcast calls CancelBroadcastFunc
+func (mock *BroadcastManager) CancelBroadcast(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	if mock.CancelBroadcastFunc == nil {
+		panic("BroadcastManager.CancelBroadcast is not stubbed")
+	}
+	return mock.CancelBroadcastFunc(ctx, tenantID, userID, broadcastID)
+}
+
+// CreateBroadcast calls CreateBroadcastFunc
+func (mock *BroadcastManager) CreateBroadcast(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.BroadcastCreateRequest) (*models.EmailBroadcast, error) {
+	if mock.CreateBroadcastFunc == nil {
+		panic("BroadcastManager.CreateBroadcast is not stubbed")
+	}
+	return mock.CreateBroadcastFunc(ctx, tenantID, userID, req)
+}
+
+// GetBroadcast calls GetBroadcastFunc
+func (mock *BroadcastManager) GetBroadcast(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID) (*models.EmailBroadcast, error) {
+	if mock.GetBroadcastFunc == nil {
+		panic("BroadcastManager.GetBroadcast is not stubbed")
+	}
+	return mock.GetBroadcastFunc(ctx, tenantID, broadcastID)
+}
+
+// ListBroadcasts calls ListBroadcastsFunc
+func (mock *BroadcastManager) ListBroadcasts(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.EmailBroadcast, int, error) {
+	if mock.ListBroadcastsFunc == nil {
+		panic("BroadcastManager.ListBroadcasts is not stubbed")
+	}
+	return mock.ListBroadcastsFunc(ctx, tenantID, limit, offset)
+}
+
+// ListRecipients calls ListRecipientsFunc
+func (mock *BroadcastManager) ListRecipients(ctx context.Context, tenantID uuid.UUID, broadcastID uuid.UUID, deliveryStatus string, limit int, offset int) ([]models.BroadcastRecipient, int, error) {
+	if mock.ListRecipientsFunc == nil {
+		panic("BroadcastManager.ListRecipients is not stubbed")
+	}
+	return mock.ListRecipientsFunc(ctx, tenantID, broadcastID, deliveryStatus, limit, offset)
+}
+
+// ProcessQueue calls ProcessQueueFunc
+func (mock *BroadcastManager) ProcessQueue(ctx context.Context) (int, error) {
+	if mock.ProcessQueueFunc == nil {
+		panic("BroadcastManager.ProcessQueue is not stubbed")
+	}
+	return mock.ProcessQueueFunc(ctx)
+}
+
+// Unsubscribe calls UnsubscribeFunc
+func (mock *BroadcastManager) Unsubscribe(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) error {
+	if mock.UnsubscribeFunc == nil {
+		panic("BroadcastManager.Unsubscribe is not stubbed")
+	}
+	return mock.UnsubscribeFunc(ctx, tenantID, token)
+}
+
+// CRMManager is a mock of services.CRMManager
+type CRMManager struct {
+	AddStageFunc            func(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error)
+	AssignFunc              func(ctx context.Context, tenantID, userID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID) error
+	CreateContactFunc       func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMContactCreateRequest) (*models.CRMContact, error)
+	CreateCustomerFunc      func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMCustomerCreateRequest) (*models.CRMCustomer, error)
+	CreateOpportunityFunc   func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMOpportunityCreateRequest) (*models.CRMOpportunity, error)
+	CreatePipelineFunc      func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error)
+	DeleteActivityFunc      func(ctx context.Context, tenantID, activityID uuid.UUID) error
+	DeleteContactFunc       func(ctx context.Context, tenantID, contactID uuid.UUID) error
+	DeleteCustomerFunc      func(ctx context.Context, tenantID, customerID uuid.UUID) error
+	DeleteOpportunityFunc   func(ctx context.Context, tenantID, opportunityID uuid.UUID) error
+	DeletePipelineFunc      func(ctx context.Context, tenantID, pipelineID uuid.UUID) error
+	DeleteStageFunc         func(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID) (*models.CRMPipeline, error)
+	DescribeContactFunc     func(ctx context.Context, tenantID, contactID uuid.UUID) (*models.EntitySummary, error)
+	DescribeCustomerFunc    func(ctx context.Context, tenantID, customerID uuid.UUID) (*models.EntitySummary, error)
+	DescribeOpportunityFunc func(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.EntitySummary, error)
+	GetActivityFunc         func(ctx context.Context, tenantID, activityID uuid.UUID) (*models.CRMActivity, error)
+	GetContactFunc          func(ctx context.Context, tenantID, contactID uuid.UUID) (*models.CRMContact, error)
+	GetCustomerFunc         func(ctx context.Context, tenantID, customerID uuid.UUID) (*models.CRMCustomer, error)
+	GetOpportunityFunc      func(ctx context.Context, tenantID, opportunityID uuid.UUID) (*models.CRMOpportunity, error)
+	GetPipelineFunc         func(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, error)
+	ListActivitiesFunc      func(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit, offset int) ([]models.CRMActivity, int, error)
+	ListContactsFunc        func(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit, offset int) ([]models.CRMContact, int, error)
+	ListCustomersFunc       func(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit, offset int) ([]models.CRMCustomer, int, error)
+	ListOpportunitiesFunc   func(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit, offset int) ([]models.CRMOpportunity, int, error)
+	ListPipelinesFunc       func(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error)
+	LogActivityFunc         func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CRMActivityCreateRequest) (*models.CRMActivity, error)
+	MoveStageFunc           func(ctx context.Context, tenantID, userID, opportunityID uuid.UUID, req *models.CRMStageChangeRequest) (*models.CRMOpportunity, error)
+	PipelineSummaryFunc     func(ctx context.Context, tenantID, pipelineID uuid.UUID) (*models.CRMPipeline, []models.CRMStageSummary, error)
+	UpdateActivityFunc      func(ctx context.Context, tenantID, userID, activityID uuid.UUID, req *models.CRMActivityUpdateRequest) (*models.CRMActivity, error)
+	UpdateContactFunc       func(ctx context.Context, tenantID, contactID uuid.UUID, req *models.CRMContactUpdateRequest) (*models.CRMContact, error)
+	UpdateCustomerFunc      func(ctx context.Context, tenantID, customerID uuid.UUID, req *models.CRMCustomerUpdateRequest) (*models.CRMCustomer, error)
+	UpdateOpportunityFunc   func(ctx context.Context, tenantID, opportunityID uuid.UUID, req *models.CRMOpportunityUpdateRequest) (*models.CRMOpportunity, error)
+	UpdatePipelineFunc      func(ctx context.Context, tenantID, pipelineID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error)
+	UpdateStageFunc         func(ctx context.Context, tenantID, pipelineID, stageID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error)
+}
+
+// AddStage calls AddStageFunc
+func (mock *CRMManager) AddStage(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error) {
+	if mock.AddStageFunc == nil {
+		panic("CRMManager.AddStage is not stubbed")
+	}
+	return mock.AddStageFunc(ctx, tenantID, pipelineID, req)
+}
+
+// Assign calls AssignFunc
+func (mock *CRMManager) Assign(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entity string, entityID uuid.UUID, ownerID *uuid.UUID) error {
+	if mock.AssignFunc == nil {
+		panic("CRMManager.Assign is not stubbed")
+	}
+	return mock.AssignFunc(ctx, tenantID, userID, entity, entityID, ownerID)
+}
+
+// CreateContact calls CreateContactFunc
+func (mock *CRMManager) CreateContact(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CRMContactCreateRequest) (*models.CRMContact, error) {
+	if mock.CreateContactFunc == nil {
+		panic("CRMManager.CreateContact is not stubbed")
+	}
+	return mock.CreateContactFunc(ctx, tenantID, userID, req)
+}
+
+// CreateCustomer calls CreateCustomerFunc
+func (mock *CRMManager) CreateCustomer(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CRMCustomerCreateRequest) (*models.CRMCustomer, error) {
+	if mock.CreateCustomerFunc == nil {
+		panic("CRMManager.CreateCustomer is not stubbed")
+	}
+	return mock.CreateCustomerFunc(ctx, tenantID, userID, req)
+}
+
+// CreateOpportunity calls CreateOpportunityFunc
+func (mock *CRMManager) CreateOpportunity(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CRMOpportunityCreateRequest) (*models.CRMOpportunity, error) {
+	if mock.CreateOpportunityFunc == nil {
+		panic("CRMManager.CreateOpportunity is not stubbed")
+	}
+	return mock.CreateOpportunityFunc(ctx, tenantID, userID, req)
+}
+
+// CreatePipeline calls CreatePipelineFunc
+func (mock *CRMManager) CreatePipeline(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error) {
+	if mock.CreatePipelineFunc == nil {
+		panic("CRMManager.CreatePipeline is not stubbed")
+	}
+	return mock.CreatePipelineFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteActivity calls DeleteActivityFunc
+func (mock *CRMManager) DeleteActivity(ctx context.Context, tenantID uuid.UUID, activityID uuid.UUID) error {
+	if mock.DeleteActivityFunc == nil {
+		panic("CRMManager.DeleteActivity is not stubbed")
+	}
+	return mock.DeleteActivityFunc(ctx, tenantID, activityID)
+}
+
+// DeleteContact calls DeleteContactFunc
+func (mock *CRMManager) DeleteContact(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID) error {
+	if mock.DeleteContactFunc == nil {
+		panic("CRMManager.DeleteContact is not stubbed")
+	}
+	return mock.DeleteContactFunc(ctx, tenantID, contactID)
+}
+
+// DeleteCustomer calls DeleteCustomerFunc
+func (mock *CRMManager) DeleteCustomer(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID) error {
+	if mock.DeleteCustomerFunc == nil {
+		panic("CRMManager.DeleteCustomer is not stubbed")
+	}
+	return mock.DeleteCustomerFunc(ctx, tenantID, customerID)
+}
+
+// DeleteOpportunity calls DeleteOpportunityFunc
+func (mock *CRMManager) DeleteOpportunity(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID) error {
+	if mock.DeleteOpportunityFunc == nil {
+		panic("CRMManager.DeleteOpportunity is not stubbed")
+	}
+	return mock.DeleteOpportunityFunc(ctx, tenantID, opportunityID)
+}
+
+// DeletePipeline calls DeletePipelineFunc
+func (mock *CRMManager) DeletePipeline(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) error {
+	if mock.DeletePipelineFunc == nil {
+		panic("CRMManager.DeletePipeline is not stubbed")
+	}
+	return mock.DeletePipelineFunc(ctx, tenantID, pipelineID)
+}
+
+// DeleteStage calls DeleteStageFunc
+func (mock *CRMManager) DeleteStage(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID, stageID uuid.UUID) (*models.CRMPipeline, error) {
+	if mock.DeleteStageFunc == nil {
+		panic("CRMManager.DeleteStage is not stubbed")
+	}
+	return mock.DeleteStageFunc(ctx, tenantID, pipelineID, stageID)
+}
+
+// DescribeContact calls DescribeContactFunc
+func (mock *CRMManager) DescribeContact(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeContactFunc == nil {
+		panic("CRMManager.DescribeContact is not stubbed")
+	}
+	return mock.DescribeContactFunc(ctx, tenantID, contactID)
+}
+
+// DescribeCustomer calls DescribeCustomerFunc
+func (mock *CRMManager) DescribeCustomer(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeCustomerFunc == nil {
+		panic("CRMManager.DescribeCustomer is not stubbed")
+	}
+	return mock.DescribeCustomerFunc(ctx, tenantID, customerID)
+}
+
+// DescribeOpportunity calls DescribeOpportunityFunc
+func (mock *CRMManager) DescribeOpportunity(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeOpportunityFunc == nil {
+		panic("CRMManager.DescribeOpportunity is not stubbed")
+	}
+	return mock.DescribeOpportunityFunc(ctx, tenantID, opportunityID)
+}
+
+// GetActivity calls GetActivityFunc
+func (mock *CRMManager) GetActivity(ctx context.Context, tenantID uuid.UUID, activityID uuid.UUID) (*models.CRMActivity, error) {
+	if mock.GetActivityFunc == nil {
+		panic("CRMManager.GetActivity is not stubbed")
+	}
+	return mock.GetActivityFunc(ctx, tenantID, activityID)
+}
+
+// GetContact calls GetContactFunc
+func (mock *CRMManager) GetContact(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID) (*models.CRMContact, error) {
+	if mock.GetContactFunc == nil {
+		panic("CRMManager.GetContact is not stubbed")
+	}
+	return mock.GetContactFunc(ctx, tenantID, contactID)
+}
+
+// GetCustomer calls GetCustomerFunc
+func (mock *CRMManager) GetCustomer(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID) (*models.CRMCustomer, error) {
+	if mock.GetCustomerFunc == nil {
+		panic("CRMManager.GetCustomer is not stubbed")
+	}
+	return mock.GetCustomerFunc(ctx, tenantID, customerID)
+}
+
+// GetOpportunity calls GetOpportunityFunc
+func (mock *CRMManager) GetOpportunity(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID) (*models.CRMOpportunity, error) {
+	if mock.GetOpportunityFunc == nil {
+		panic("CRMManager.GetOpportunity is not stubbed")
+	}
+	return mock.GetOpportunityFunc(ctx, tenantID, opportunityID)
+}
+
+// GetPipeline calls GetPipelineFunc
+func (mock *CRMManager) GetPipeline(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) (*models.CRMPipeline, error) {
+	if mock.GetPipelineFunc == nil {
+		panic("CRMManager.GetPipeline is not stubbed")
+	}
+	return mock.GetPipelineFunc(ctx, tenantID, pipelineID)
+}
+
+// ListActivities calls ListActivitiesFunc
+func (mock *CRMManager) ListActivities(ctx context.Context, tenantID uuid.UUID, filter models.CRMActivityFilter, limit int, offset int) ([]models.CRMActivity, int, error) {
+	if mock.ListActivitiesFunc == nil {
+		panic("CRMManager.ListActivities is not stubbed")
+	}
+	return mock.ListActivitiesFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListContacts calls ListContactsFunc
+func (mock *CRMManager) ListContacts(ctx context.Context, tenantID uuid.UUID, filter models.CRMContactFilter, limit int, offset int) ([]models.CRMContact, int, error) {
+	if mock.ListContactsFunc == nil {
+		panic("CRMManager.ListContacts is not stubbed")
+	}
+	return mock.ListContactsFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListCustomers calls ListCustomersFunc
+func (mock *CRMManager) ListCustomers(ctx context.Context, tenantID uuid.UUID, filter models.CRMCustomerFilter, limit int, offset int) ([]models.CRMCustomer, int, error) {
+	if mock.ListCustomersFunc == nil {
+		panic("CRMManager.ListCustomers is not stubbed")
+	}
+	return mock.ListCustomersFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListOpportunities calls ListOpportunitiesFunc
+func (mock *CRMManager) ListOpportunities(ctx context.Context, tenantID uuid.UUID, filter models.CRMOpportunityFilter, limit int, offset int) ([]models.CRMOpportunity, int, error) {
+	if mock.ListOpportunitiesFunc == nil {
+		panic("CRMManager.ListOpportunities is not stubbed")
+	}
+	return mock.ListOpportunitiesFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// ListPipelines calls ListPipelinesFunc
+func (mock *CRMManager) ListPipelines(ctx context.Context, tenantID uuid.UUID) ([]models.CRMPipeline, error) {
+	if mock.ListPipelinesFunc == nil {
+		panic("CRMManager.ListPipelines is not stubbed")
+	}
+	return mock.ListPipelinesFunc(ctx, tenantID)
+}
+
+// LogActivity calls LogActivityFunc
+func (mock *CRMManager) LogActivity(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CRMActivityCreateRequest) (*models.CRMActivity, error) {
+	if mock.LogActivityFunc == nil {
+		panic("CRMManager.LogActivity is not stubbed")
+	}
+	return mock.LogActivityFunc(ctx, tenantID, userID, req)
+}
+
+// MoveStage calls MoveStageFunc
+func (mock *CRMManager) MoveStage(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, opportunityID uuid.UUID, req *models.CRMStageChangeRequest) (*models.CRMOpportunity, error) {
+	if mock.MoveStageFunc == nil {
+		panic("CRMManager.MoveStage is not stubbed")
+	}
+	return mock.MoveStageFunc(ctx, tenantID, userID, opportunityID, req)
+}
+
+// PipelineSummary calls PipelineSummaryFunc
+func (mock *CRMManager) PipelineSummary(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID) (*models.CRMPipeline, []models.CRMStageSummary, error) {
+	if mock.PipelineSummaryFunc == nil {
+		panic("CRMManager.PipelineSummary is not stubbed")
+	}
+	return mock.PipelineSummaryFunc(ctx, tenantID, pipelineID)
+}
+
+// UpdateActivity calls UpdateActivityFunc
+func (mock *CRMManager) UpdateActivity(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, activityID uuid.UUID, req *models.CRMActivityUpdateRequest) (*models.CRMActivity, error) {
+	if mock.UpdateActivityFunc == nil {
+		panic("CRMManager.UpdateActivity is not stubbed")
+	}
+	return mock.UpdateActivityFunc(ctx, tenantID, userID, activityID, req)
+}
+
+// UpdateContact calls UpdateContactFunc
+func (mock *CRMManager) UpdateContact(ctx context.Context, tenantID uuid.UUID, contactID uuid.UUID, req *models.CRMContactUpdateRequest) (*models.CRMContact, error) {
+	if mock.UpdateContactFunc == nil {
+		panic("CRMManager.UpdateContact is not stubbed")
+	}
+	return mock.UpdateContactFunc(ctx, tenantID, contactID, req)
+}
+
+// UpdateCustomer calls UpdateCustomerFunc
+func (mock *CRMManager) UpdateCustomer(ctx context.Context, tenantID uuid.UUID, customerID uuid.UUID, req *models.CRMCustomerUpdateRequest) (*models.CRMCustomer, error) {
+	if mock.UpdateCustomerFunc == nil {
+		panic("CRMManager.UpdateCustomer is not stubbed")
+	}
+	return mock.UpdateCustomerFunc(ctx, tenantID, customerID, req)
+}
+
+// UpdateOpportunity calls UpdateOpportunityFunc
+func (mock *CRMManager) UpdateOpportunity(ctx context.Context, tenantID uuid.UUID, opportunityID uuid.UUID, req *models.CRMOpportunityUpdateRequest) (*models.CRMOpportunity, error) {
+	if mock.UpdateOpportunityFunc == nil {
+		panic("CRMManager.UpdateOpportunity is not stubbed")
+	}
+	return mock.UpdateOpportunityFunc(ctx, tenantID, opportunityID, req)
+}
+
+// UpdatePipeline calls UpdatePipelineFunc
+func (mock *CRMManager) UpdatePipeline(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID, req *models.CRMPipelineRequest) (*models.CRMPipeline, error) {
+	if mock.UpdatePipelineFunc == nil {
+		panic("CRMManager.UpdatePipeline is not stubbed")
+	}
+	return mock.UpdatePipelineFunc(ctx, tenantID, pipelineID, req)
+}
+
+// UpdateStage calls UpdateStageFunc
+func (mock *CRMManager) UpdateStage(ctx context.Context, tenantID uuid.UUID, pipelineID uuid.UUID, stageID uuid.UUID, req *models.CRMPipelineStageRequest) (*models.CRMPipeline, error) {
+	if mock.UpdateStageFunc == nil {
+		panic("CRMManager.UpdateStage is not stubbed")
+	}
+	return mock.UpdateStageFunc(ctx, tenantID, pipelineID, stageID, req)
+}
+
+// CompanySettingsManager is a mock of services.CompanySettingsManager
+type CompanySettingsManager struct {
+	GetSettingsFunc    func(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error)
+	UpdateSettingsFunc func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		req *models.CompanySettingsUpdateRequest,
+		nulls map[string]bool,
+	) (*models.CompanySettings, error)
+}
+
+// GetSettings calls GetSettingsFunc
+func (mock *CompanySettingsManager) GetSettings(ctx context.Context, tenantID uuid.UUID) (*models.CompanySettings, error) {
+	if mock.GetSettingsFunc == nil {
+		panic("CompanySettingsManager.GetSettings is not stubbed")
+	}
+	return mock.GetSettingsFunc(ctx, tenantID)
+}
+
+// UpdateSettings calls UpdateSettingsFunc
+func (mock *CompanySettingsManager) UpdateSettings(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CompanySettingsUpdateRequest, nulls map[string]bool) (*models.CompanySettings, error) {
+	if mock.UpdateSettingsFunc == nil {
+		panic("CompanySettingsManager.UpdateSettings is not stubbed")
+	}
+	return mock.UpdateSettingsFunc(ctx, tenantID, userID, req, nulls)
+}
+
+// ComplianceManager is a mock of services.ComplianceManager
+type ComplianceManager struct {
+	GetReportFunc     func(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	RequestReportFunc func(ctx context.Context, tenantID, userID uuid.UUID, params models.ComplianceReportJobParams) (*models.AsyncJob, error)
+	RunReportJobFunc  func(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error)
+}
+
+// GetReport calls GetReportFunc
+func (mock *ComplianceManager) GetReport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.GetReportFunc == nil {
+		panic("ComplianceManager.GetReport is not stubbed")
+	}
+	return mock.GetReportFunc(ctx, tenantID, userID, jobID)
+}
+
+// RequestReport calls RequestReportFunc
+func (mock *ComplianceManager) RequestReport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, params models.ComplianceReportJobParams) (*models.AsyncJob, error) {
+	if mock.RequestReportFunc == nil {
+		panic("ComplianceManager.RequestReport is not stubbed")
+	}
+	return mock.RequestReportFunc(ctx, tenantID, userID, params)
+}
+
+// RunReportJob calls RunReportJobFunc
+func (mock *ComplianceManager) RunReportJob(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error) {
+	if mock.RunReportJobFunc == nil {
+		panic("ComplianceManager.RunReportJob is not stubbed")
+	}
+	return mock.RunReportJobFunc(ctx, job, progress)
+}
+
+// CustomFieldManager is a mock of services.CustomFieldManager
+type CustomFieldManager struct {
+	ApplyValuesFunc func(ctx context.Context, tenantID uuid.UUID, entity string, current json.RawMessage, values models.CustomFieldValues, creating bool) (json.RawMessage, error)
+	CreateFieldFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.CustomFieldCreateRequest) (*models.CustomField, error)
+	DeleteFieldFunc func(ctx context.Context, tenantID, fieldID uuid.UUID) error
+	GetFieldFunc    func(ctx context.Context, tenantID, fieldID uuid.UUID) (*models.CustomField, error)
+	ListFieldsFunc  func(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error)
+	ParseFilterFunc func(ctx context.Context, tenantID uuid.UUID, entity string, query url.Values) (json.RawMessage, error)
+	UpdateFieldFunc func(ctx context.Context, tenantID, fieldID uuid.UUID, req *models.CustomFieldUpdateRequest) (*models.CustomField, error)
+}
+
+// ApplyValues calls ApplyValuesFunc
+func (mock *CustomFieldManager) ApplyValues(ctx context.Context, tenantID uuid.UUID, entity string, current json.RawMessage, values models.CustomFieldValues, creating bool) (json.RawMessage, error) {
+	if mock.ApplyValuesFunc == nil {
+		panic("CustomFieldManager.ApplyValues is not stubbed")
+	}
+	return mock.ApplyValuesFunc(ctx, tenantID, entity, current, values, creating)
+}
+
+// CreateField calls CreateFieldFunc
+func (mock *CustomFieldManager) CreateField(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.CustomFieldCreateRequest) (*models.CustomField, error) {
+	if mock.CreateFieldFunc == nil {
+		panic("CustomFieldManager.CreateField is not stubbed")
+	}
+	return mock.CreateFieldFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteField calls DeleteFieldFunc
+func (mock *CustomFieldManager) DeleteField(ctx context.Context, tenantID uuid.UUID, fieldID uuid.UUID) error {
+	if mock.DeleteFieldFunc == nil {
+		panic("CustomFieldManager.DeleteField is not stubbed")
+	}
+	return mock.DeleteFieldFunc(ctx, tenantID, fieldID)
+}
+
+// GetField calls GetFieldFunc
+func (mock *CustomFieldManager) GetField(ctx context.Context, tenantID uuid.UUID, fieldID uuid.UUID) (*models.CustomField, error) {
+	if mock.GetFieldFunc == nil {
+		panic("CustomFieldManager.GetField is not stubbed")
+	}
+	return mock.GetFieldFunc(ctx, tenantID, fieldID)
+}
+
+// ListFields calls ListFieldsFunc
+func (mock *CustomFieldManager) ListFields(ctx context.Context, tenantID uuid.UUID, entity string) ([]models.CustomField, error) {
+	if mock.ListFieldsFunc == nil {
+		panic("CustomFieldManager.ListFields is not stubbed")
+	}
+	return mock.ListFieldsFunc(ctx, tenantID, entity)
+}
+
+// ParseFilter calls ParseFilterFunc
+func (mock *CustomFieldManager) ParseFilter(ctx context.Context, tenantID uuid.UUID, entity string, query url.Values) (json.RawMessage, error) {
+	if mock.ParseFilterFunc == nil {
+		panic("CustomFieldManager.ParseFilter is not stubbed")
+	}
+	return mock.ParseFilterFunc(ctx, tenantID, entity, query)
+}
+
+// UpdateField calls UpdateFieldFunc
+func (mock *CustomFieldManager) UpdateField(ctx context.Context, tenantID uuid.UUID, fieldID uuid.UUID, req *models.CustomFieldUpdateRequest) (*models.CustomField, error) {
+	if mock.UpdateFieldFunc == nil {
+		panic("CustomFieldManager.UpdateField is not stubbed")
+	}
+	return mock.UpdateFieldFunc(ctx, tenantID, fieldID, req)
+}
+
+// DashboardManager is a mock of services.DashboardManager
+type DashboardManager struct {
+	GetStatsFunc func(ctx context.Context, tenantID uuid.UUID) (*models.DashboardStats, error)
+}
+
+// GetStats calls GetStatsFunc
+func (mock *DashboardManager) GetStats(ctx context.Context, tenantID uuid.UUID) (*models.DashboardStats, error) {
+	if mock.GetStatsFunc == nil {
+		panic("DashboardManager.GetStats is not stubbed")
+	}
+	return mock.GetStatsFunc(ctx, tenantID)
+}
+
+// DataQualityManager is a mock of services.DataQualityManager
+type DataQualityManager struct {
+	CreateRuleFunc   func(ctx context.Context, tenantID, userID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error)
+	DeleteRuleFunc   func(ctx context.Context, tenantID, ruleID uuid.UUID) error
+	GetReportFunc    func(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error)
+	GetRuleFunc      func(ctx context.Context, tenantID, ruleID uuid.UUID) (*models.DataQualityRule, error)
+	ListRulesFunc    func(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error)
+	RunReportFunc    func(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error)
+	RunScheduledFunc func(ctx context.Context) (int, error)
+	UpdateRuleFunc   func(ctx context.Context, tenantID, ruleID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error)
+}
+
+// CreateRule calls CreateRuleFunc
+func (mock *DataQualityManager) CreateRule(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error) {
+	if mock.CreateRuleFunc == nil {
+		panic("DataQualityManager.CreateRule is not stubbed")
+	}
+	return mock.CreateRuleFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteRule calls DeleteRuleFunc
+func (mock *DataQualityManager) DeleteRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID) error {
+	if mock.DeleteRuleFunc == nil {
+		panic("DataQualityManager.DeleteRule is not stubbed")
+	}
+	return mock.DeleteRuleFunc(ctx, tenantID, ruleID)
+}
+
+// GetReport calls GetReportFunc
+func (mock *DataQualityManager) GetReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error) {
+	if mock.GetReportFunc == nil {
+		panic("DataQualityManager.GetReport is not stubbed")
+	}
+	return mock.GetReportFunc(ctx, tenantID)
+}
+
+// GetRule calls GetRuleFunc
+func (mock *DataQualityManager) GetRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID) (*models.DataQualityRule, error) {
+	if mock.GetRuleFunc == nil {
+		panic("DataQualityManager.GetRule is not stubbed")
+	}
+	return mock.GetRuleFunc(ctx, tenantID, ruleID)
+}
+
+// ListRules calls ListRulesFunc
+func (mock *DataQualityManager) ListRules(ctx context.Context, tenantID uuid.UUID) ([]models.DataQualityRule, error) {
+	if mock.ListRulesFunc == nil {
+		panic("DataQualityManager.ListRules is not stubbed")
+	}
+	return mock.ListRulesFunc(ctx, tenantID)
+}
+
+// RunReport calls RunReportFunc
+func (mock *DataQualityManager) RunReport(ctx context.Context, tenantID uuid.UUID) (*models.DataQualityReport, error) {
+	if mock.RunReportFunc == nil {
+		panic("DataQualityManager.RunReport is not stubbed")
+	}
+	return mock.RunReportFunc(ctx, tenantID)
+}
+
+// RunScheduled calls RunScheduledFunc
+func (mock *DataQualityManager) RunScheduled(ctx context.Context) (int, error) {
+	if mock.RunScheduledFunc == nil {
+		panic("DataQualityManager.RunScheduled is not stubbed")
+	}
+	return mock.RunScheduledFunc(ctx)
+}
+
+// UpdateRule calls UpdateRuleFunc
+func (mock *DataQualityManager) UpdateRule(ctx context.Context, tenantID uuid.UUID, ruleID uuid.UUID, req *models.DataQualityRuleRequest) (*models.DataQualityRule, error) {
+	if mock.UpdateRuleFunc == nil {
+		panic("DataQualityManager.UpdateRule is not stubbed")
+	}
+	return mock.UpdateRuleFunc(ctx, tenantID, ruleID, req)
+}
+
+// DeletionManager is a mock of services.DeletionManager
+type DeletionManager struct {
+	GetDeletionFunc    func(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error)
+	ListDeletionsFunc  func(ctx context.Context, tenantID, userID uuid.UUID, status string, limit, offset int) ([]models.PendingDeletion, int, error)
+	ProcessDueFunc     func(ctx context.Context) (int, error)
+	RegisterTargetFunc func(entityType, resource string, execute services.DeletionExecutor)
+	ScheduleFunc       func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID, label string) (*models.PendingDeletion, error)
+	SubscribeFunc      func(name string, handler services.DeletionHandler)
+	UndoFunc           func(ctx context.Context, tenantID, userID, deletionID uuid.UUID) (*models.PendingDeletion, error)
+}
+
+// GetDeletion calls GetDeletionFunc
+func (mock *DeletionManager) GetDeletion(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, deletionID uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.GetDeletionFunc == nil {
+		panic("DeletionManager.GetDeletion is not stubbed")
+	}
+	return mock.GetDeletionFunc(ctx, tenantID, userID, deletionID)
+}
+
+// ListDeletions calls ListDeletionsFunc
+func (mock *DeletionManager) ListDeletions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, status string, limit int, offset int) ([]models.PendingDeletion, int, error) {
+	if mock.ListDeletionsFunc == nil {
+		panic("DeletionManager.ListDeletions is not stubbed")
+	}
+	return mock.ListDeletionsFunc(ctx, tenantID, userID, status, limit, offset)
+}
+
+// ProcessDue calls ProcessDueFunc
+func (mock *DeletionManager) ProcessDue(ctx context.Context) (int, error) {
+	if mock.ProcessDueFunc == nil {
+		panic("DeletionManager.ProcessDue is not stubbed")
+	}
+	return mock.ProcessDueFunc(ctx)
+}
+
+// RegisterTarget calls RegisterTargetFunc
+func (mock *DeletionManager) RegisterTarget(entityType string, resource string, execute services.DeletionExecutor) {
+	if mock.RegisterTargetFunc == nil {
+		panic("DeletionManager.RegisterTarget is not stubbed")
+	}
+	mock.RegisterTargetFunc(entityType, resource, execute)
+}
+
+// Schedule calls ScheduleFunc
+func (mock *DeletionManager) Schedule(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID, label string) (*models.PendingDeletion, error) {
+	if mock.ScheduleFunc == nil {
+		panic("DeletionManager.Schedule is not stubbed")
+	}
+	return mock.ScheduleFunc(ctx, tenantID, userID, entityType, entityID, label)
+}
+
+// Subscribe calls SubscribeFunc
+func (mock *DeletionManager) Subscribe(name string, handler services.DeletionHandler) {
+	if mock.SubscribeFunc == nil {
+		panic("DeletionManager.Subscribe is not stubbed")
+	}
+	mock.SubscribeFunc(name, handler)
+}
+
+// Undo calls UndoFunc
+func (mock *DeletionManager) Undo(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, deletionID uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.UndoFunc == nil {
+		panic("DeletionManager.Undo is not stubbed")
+	}
+	return mock.UndoFunc(ctx, tenantID, userID, deletionID)
+}
+
+// EmailQueueManager is a mock of services.EmailQueueManager
+type EmailQueueManager struct {
+	EnqueueFunc              func(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage) error
+	EnqueueStandaloneFunc    func(ctx context.Context, tenantID uuid.UUID, msg *models.EmailMessage) error
+	GetStatsFunc             func(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error)
+	HandleDeliveryEventsFunc func(ctx context.Context, events []mail.Event) error
+	ListEmailsFunc           func(ctx context.Context, tenantID uuid.UUID, status string, limit, offset int) ([]models.OutboxEmail, int, error)
+	ProcessQueueFunc         func(ctx context.Context) (int, error)
+	RetryEmailFunc           func(ctx context.Context, tenantID, userID, emailID uuid.UUID) error
+}
+
+// Enqueue calls EnqueueFunc
+func (mock *EmailQueueManager) Enqueue(ctx context.Context, tx *sqlx.Tx, tenantID uuid.UUID, msg *models.EmailMessage) error {
+	if mock.EnqueueFunc == nil {
+		panic("EmailQueueManager.Enqueue is not stubbed")
+	}
+	return mock.EnqueueFunc(ctx, tx, tenantID, msg)
+}
+
+// EnqueueStandalone calls EnqueueStandaloneFunc
+func (mock *EmailQueueManager) EnqueueStandalone(ctx context.Context, tenantID uuid.UUID, msg *models.EmailMessage) error {
+	if mock.EnqueueStandaloneFunc == nil {
+		panic("EmailQueueManager.EnqueueStandalone is not stubbed")
+	}
+	return mock.EnqueueStandaloneFunc(ctx, tenantID, msg)
+}
+
+// GetStats calls GetStatsFunc
+func (mock *EmailQueueManager) GetStats(ctx context.Context, tenantID uuid.UUID) (*models.EmailQueueStats, error) {
+	if mock.GetStatsFunc == nil {
+		panic("EmailQueueManager.GetStats is not stubbed")
+	}
+	return mock.GetStatsFunc(ctx, tenantID)
+}
+
+// HandleDeliveryEvents calls HandleDeliveryEventsFunc
+func (mock *EmailQueueManager) HandleDeliveryEvents(ctx context.Context, events []mail.Event) error {
+	if mock.HandleDeliveryEventsFunc == nil {
+		panic("EmailQueueManager.HandleDeliveryEvents is not stubbed")
+	}
+	return mock.HandleDeliveryEventsFunc(ctx, events)
+}
+
+// ListEmails calls ListEmailsFunc
+func (mock *EmailQueueManager) ListEmails(ctx context.Context, tenantID uuid.UUID, status string, limit int, offset int) ([]models.OutboxEmail, int, error) {
+	if mock.ListEmailsFunc == nil {
+		panic("EmailQueueManager.ListEmails is not stubbed")
+	}
+	return mock.ListEmailsFunc(ctx, tenantID, status, limit, offset)
+}
+
+// ProcessQueue calls ProcessQueueFunc
+func (mock *EmailQueueManager) ProcessQueue(ctx context.Context) (int, error) {
+	if mock.ProcessQueueFunc == nil {
+		panic("EmailQueueManager.ProcessQueue is not stubbed")
+	}
+	return mock.ProcessQueueFunc(ctx)
+}
+
+// RetryEmail calls RetryEmailFunc
+func (mock *EmailQueueManager) RetryEmail(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, emailID uuid.UUID) error {
+	if mock.RetryEmailFunc == nil {
+		panic("EmailQueueManager.RetryEmail is not stubbed")
+	}
+	return mock.RetryEmailFunc(ctx, tenantID, userID, emailID)
+}
+
+// EmailManager is a mock of services.EmailManager
+type EmailManager struct {
+	AccountSetupEmailFunc            func(email, lang, firstName, companyName, creatorName string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error)
+	ApprovalLinkURLFunc              func(tenantSlug, token, decision string) string
+	ApprovalRequestEmailFunc         func(email, lang, firstName, companyName, title, details, approveURL, rejectURL, reviewURL string, stepUp bool, expiresAt time.Time) (*models.EmailMessage, error)
+	AutomationEmailFunc              func(email, lang, companyName, subject string, content template.HTML) (*models.EmailMessage, error)
+	BroadcastEmailFunc               func(email, lang, companyName, subject string, content template.HTML, unsubscribeURL string) (*models.EmailMessage, error)
+	BroadcastUnsubscribeURLFunc      func(tenantSlug string, token uuid.UUID) string
+	DataQualityAlertEmailFunc        func(email, lang, firstName, companyName string, alerts []models.DataQualityAlert, reportURL string) (*models.EmailMessage, error)
+	DeletionScheduledEmailFunc       func(email, lang, firstName, label string, deletionID uuid.UUID, executeAt time.Time) (*models.EmailMessage, error)
+	EmailChangeEmailFunc             func(email, lang, firstName, currentEmail string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error)
+	EscalationEmailFunc              func(email, lang, firstName, companyName, title, details, reviewURL string, pendingSince time.Time) (*models.EmailMessage, error)
+	InvitationEmailFunc              func(email, lang, companyName, inviterName string, branding *models.TenantBranding, token uuid.UUID, message string) (*models.EmailMessage, error)
+	LeaveDecisionEmailFunc           func(email, lang, firstName, companyName, leaveType, period string, days float64, approved bool, deciderName, note, requestURL string) (*models.EmailMessage, error)
+	LeaveRequestEmailFunc            func(email, lang, firstName, companyName, employeeName, leaveType, period string, days float64, reason, reviewURL string) (*models.EmailMessage, error)
+	NotificationDigestEmailFunc      func(email, lang, firstName, companyName, digest string, notifications []models.Notification) (*models.EmailMessage, error)
+	NotificationEmailFunc            func(email, lang, firstName, companyName string, n *models.Notification) (*models.EmailMessage, error)
+	PasswordResetEmailFunc           func(email, lang, firstName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error)
+	PreviewFunc                      func(name, lang string, branding *models.TenantBranding) (*models.EmailTemplatePreview, error)
+	QuotaAlertEmailFunc              func(email, lang, firstName, companyName string, alerts []models.QuotaAlert, quotasURL string) (*models.EmailMessage, error)
+	ScheduledReportEmailFunc         func(email, lang, firstName, companyName, name string, periodStart, periodEnd time.Time, attachment models.EmailAttachment) (*models.EmailMessage, error)
+	SendEmailFunc                    func(to, subject, body, text string) error
+	SendMessageFunc                  func(ctx context.Context, msg *models.EmailMessage) error
+	TenantDeletionConfirmEmailFunc   func(email, lang, firstName, companyName string, branding *models.TenantBranding, token uuid.UUID, expiresAt time.Time, gracePeriod time.Duration) (*models.EmailMessage, error)
+	TenantDeletionScheduledEmailFunc func(email, lang, firstName, companyName string, branding *models.TenantBranding, deleteAt time.Time) (*models.EmailMessage, error)
+	TenantVerificationEmailFunc      func(email, lang, companyName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error)
+	TwoFactorBypassEmailFunc         func(email, lang, firstName, code, issuedBy string, expiresAt time.Time) (*models.EmailMessage, error)
+	TwoFactorSetupReminderEmailFunc  func(email, lang, firstName, companyName string, deadline time.Time) (*models.EmailMessage, error)
+	WelcomeEmailFunc                 func(email, lang, firstName string) (*models.EmailMessage, error)
+}
+
+// AccountSetupEmail calls AccountSetupEmailFunc
+func (mock *EmailManager) AccountSetupEmail(email string, lang string, firstName string, companyName string, creatorName string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error) {
+	if mock.AccountSetupEmailFunc == nil {
+		panic("EmailManager.AccountSetupEmail is not stubbed")
+	}
+	return mock.AccountSetupEmailFunc(email, lang, firstName, companyName, creatorName, token, expiresAt)
+}
+
+// ApprovalLinkURL calls ApprovalLinkURLFunc
+func (mock *EmailManager) ApprovalLinkURL(tenantSlug string, token string, decision string) string {
+	if mock.ApprovalLinkURLFunc == nil {
+		panic("EmailManager.ApprovalLinkURL is not stubbed")
+	}
+	return mock.ApprovalLinkURLFunc(tenantSlug, token, decision)
+}
+
+// ApprovalRequestEmail calls ApprovalRequestEmailFunc
+func (mock *EmailManager) ApprovalRequestEmail(email string, lang string, firstName string, companyName string, title string, details string, approveURL string, rejectURL string, reviewURL string, stepUp bool, expiresAt time.Time) (*models.EmailMessage, error) {
+	if mock.ApprovalRequestEmailFunc == nil {
+		panic("EmailManager.ApprovalRequestEmail is not stubbed")
+	}
+	return mock.ApprovalRequestEmailFunc(email, lang, firstName, companyName, title, details, approveURL, rejectURL, reviewURL, stepUp, expiresAt)
+}
+
+// AutomationEmail calls AutomationEmailFunc
+func (mock *EmailManager) AutomationEmail(email string, lang string, companyName string, subject string, content template.HTML) (*models.EmailMessage, error) {
+	if mock.AutomationEmailFunc == nil {
+		panic("EmailManager.AutomationEmail is not stubbed")
+	}
+	return mock.AutomationEmailFunc(email, lang, companyName, subject, content)
+}
+
+// BroadcastEmail calls BroadcastEmailFunc
+func (mock *EmailManager) BroadcastEmail(email string, lang string, companyName string, subject string, content template.HTML, unsubscribeURL string) (*models.EmailMessage, error) {
+	if mock.BroadcastEmailFunc == nil {
+		panic("EmailManager.BroadcastEmail is not stubbed")
+	}
+	return mock.BroadcastEmailFunc(email, lang, companyName, subject, content, unsubscribeURL)
+}
+
+// BroadcastUnsubscribeURL calls BroadcastUnsubscribeURLFunc
+func (mock *EmailManager) BroadcastUnsubscribeURL(tenantSlug string, token uuid.UUID) string {
+	if mock.BroadcastUnsubscribeURLFunc == nil {
+		panic("EmailManager.BroadcastUnsubscribeURL is not stubbed")
+	}
+	return mock.BroadcastUnsubscribeURLFunc(tenantSlug, token)
+}
+
+// DataQualityAlertEmail calls DataQualityAlertEmailFunc
+func (mock *EmailManager) DataQualityAlertEmail(email string, lang string, firstName string, companyName string, alerts []models.DataQualityAlert, reportURL string) (*models.EmailMessage, error) {
+	if mock.DataQualityAlertEmailFunc == nil {
+		panic("EmailManager.DataQualityAlertEmail is not stubbed")
+	}
+	return mock.DataQualityAlertEmailFunc(email, lang, firstName, companyName, alerts, reportURL)
+}
+
+// DeletionScheduledEmail calls DeletionScheduledEmailFunc
+func (mock *EmailManager) DeletionScheduledEmail(email string, lang string, firstName string, label string, deletionID uuid.UUID, executeAt time.Time) (*models.EmailMessage, error) {
+	if mock.DeletionScheduledEmailFunc == nil {
+		panic("EmailManager.DeletionScheduledEmail is not stubbed")
+	}
+	return mock.DeletionScheduledEmailFunc(email, lang, firstName, label, deletionID, executeAt)
+}
+
+// EmailChangeEmail calls EmailChangeEmailFunc
+func (mock *EmailManager) EmailChangeEmail(email string, lang string, firstName string, currentEmail string, token uuid.UUID, expiresAt time.Time) (*models.EmailMessage, error) {
+	if mock.EmailChangeEmailFunc == nil {
+		panic("EmailManager.EmailChangeEmail is not stubbed")
+	}
+	return mock.EmailChangeEmailFunc(email, lang, firstName, currentEmail, token, expiresAt)
+}
+
+// EscalationEmail calls EscalationEmailFunc
+func (mock *EmailManager) EscalationEmail(email string, lang string, firstName string, companyName string, title string, details string, reviewURL string, pendingSince time.Time) (*models.EmailMessage, error) {
+	if mock.EscalationEmailFunc == nil {
+		panic("EmailManager.EscalationEmail is not stubbed")
+	}
+	return mock.EscalationEmailFunc(email, lang, firstName, companyName, title, details, reviewURL, pendingSince)
+}
+
+// InvitationEmail calls InvitationEmailFunc
+func (mock *EmailManager) InvitationEmail(email string, lang string, companyName string, inviterName string, branding *models.TenantBranding, token uuid.UUID, message string) (*models.EmailMessage, error) {
+	if mock.InvitationEmailFunc == nil {
+		panic("EmailManager.InvitationEmail is not stubbed")
+	}
+	return mock.InvitationEmailFunc(email, lang, companyName, inviterName, branding, token, message)
+}
+
+// LeaveDecisionEmail calls LeaveDecisionEmailFunc
+func (mock *EmailManager) LeaveDecisionEmail(email string, lang string, firstName string, companyName string, leaveType string, period string, days float64, approved bool, deciderName string, note string, requestURL string) (*models.EmailMessage, error) {
+	if mock.LeaveDecisionEmailFunc == nil {
+		panic("EmailManager.LeaveDecisionEmail is not stubbed")
+	}
+	return mock.LeaveDecisionEmailFunc(email, lang, firstName, companyName, leaveType, period, days, approved, deciderName, note, requestURL)
+}
+
+// LeaveRequestEmail calls LeaveRequestEmailFunc
+func (mock *EmailManager) LeaveRequestEmail(email string, lang string, firstName string, companyName string, employeeName string, leaveType string, period string, days float64, reason string, reviewURL string) (*models.EmailMessage, error) {
+	if mock.LeaveRequestEmailFunc == nil {
+		panic("EmailManager.LeaveRequestEmail is not stubbed")
+	}
+	return mock.LeaveRequestEmailFunc(email, lang, firstName, companyName, employeeName, leaveType, period, days, reason, reviewURL)
+}
+
+// NotificationDigestEmail calls NotificationDigestEmailFunc
+func (mock *EmailManager) NotificationDigestEmail(email string, lang string, firstName string, companyName string, digest string, notifications []models.Notification) (*models.EmailMessage, error) {
+	if mock.NotificationDigestEmailFunc == nil {
+		panic("EmailManager.NotificationDigestEmail is not stubbed")
+	}
+	return mock.NotificationDigestEmailFunc(email, lang, firstName, companyName, digest, notifications)
+}
+
+// NotificationEmail calls NotificationEmailFunc
+func (mock *EmailManager) NotificationEmail(email string, lang string, firstName string, companyName string, n *models.Notification) (*models.EmailMessage, error) {
+	if mock.NotificationEmailFunc == nil {
+		panic("EmailManager.NotificationEmail is not stubbed")
+	}
+	return mock.NotificationEmailFunc(email, lang, firstName, companyName, n)
+}
+
+// PasswordResetEmail calls PasswordResetEmailFunc
+func (mock *EmailManager) PasswordResetEmail(email string, lang string, firstName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error) {
+	if mock.PasswordResetEmailFunc == nil {
+		panic("EmailManager.PasswordResetEmail is not stubbed")
+	}
+	return mock.PasswordResetEmailFunc(email, lang, firstName, branding, token)
+}
+
+// Preview calls PreviewFunc
+func (mock *EmailManager) Preview(name string, lang string, branding *models.TenantBranding) (*models.EmailTemplatePreview, error) {
+	if mock.PreviewFunc == nil {
+		panic("EmailManager.Preview is not stubbed")
+	}
+	return mock.PreviewFunc(name, lang, branding)
+}
+
+// QuotaAlertEmail calls QuotaAlertEmailFunc
+func (mock *EmailManager) QuotaAlertEmail(email string, lang string, firstName string, companyName string, alerts []models.QuotaAlert, quotasURL string) (*models.EmailMessage, error) {
+	if mock.QuotaAlertEmailFunc == nil {
+		panic("EmailManager.QuotaAlertEmail is not stubbed")
+	}
+	return mock.QuotaAlertEmailFunc(email, lang, firstName, companyName, alerts, quotasURL)
+}
+
+// ScheduledReportEmail calls ScheduledReportEmailFunc
+func (mock *EmailManager) ScheduledReportEmail(email string, lang string, firstName string, companyName string, name string, periodStart time.Time, periodEnd time.Time, attachment models.EmailAttachment) (*models.EmailMessage, error) {
+	if mock.ScheduledReportEmailFunc == nil {
+		panic("EmailManager.ScheduledReportEmail is not stubbed")
+	}
+	return mock.ScheduledReportEmailFunc(email, lang, firstName, companyName, name, periodStart, periodEnd, attachment)
+}
+
+// SendEmail calls SendEmailFunc
+func (mock *EmailManager) SendEmail(to string, subject string, body string, text string) error {
+	if mock.SendEmailFunc == nil {
+		panic("EmailManager.SendEmail is not stubbed")
+	}
+	return mock.SendEmailFunc(to, subject, body, text)
+}
+
+// SendMessage calls SendMessageFunc
+func (mock *EmailManager) SendMessage(ctx context.Context, msg *models.EmailMessage) error {
+	if mock.SendMessageFunc == nil {
+		panic("EmailManager.SendMessage is not stubbed")
+	}
+	return mock.SendMessageFunc(ctx, msg)
+}
+
+// TenantDeletionConfirmEmail calls TenantDeletionConfirmEmailFunc
+func (mock *EmailManager) TenantDeletionConfirmEmail(email string, lang string, firstName string, companyName string, branding *models.TenantBranding, token uuid.UUID, expiresAt time.Time, gracePeriod time.Duration) (*models.EmailMessage, error) {
+	if mock.TenantDeletionConfirmEmailFunc == nil {
+		panic("EmailManager.TenantDeletionConfirmEmail is not stubbed")
+	}
+	return mock.TenantDeletionConfirmEmailFunc(email, lang, firstName, companyName, branding, token, expiresAt, gracePeriod)
+}
+
+// TenantDeletionScheduledEmail calls TenantDeletionScheduledEmailFunc
+func (mock *EmailManager) TenantDeletionScheduledEmail(email string, lang string, firstName string, companyName string, branding *models.TenantBranding, deleteAt time.Time) (*models.EmailMessage, error) {
+	if mock.TenantDeletionScheduledEmailFunc == nil {
+		panic("EmailManager.TenantDeletionScheduledEmail is not stubbed")
+	}
+	return mock.TenantDeletionScheduledEmailFunc(email, lang, firstName, companyName, branding, deleteAt)
+}
+
+// TenantVerificationEmail calls TenantVerificationEmailFunc
+func (mock *EmailManager) TenantVerificationEmail(email string, lang string, companyName string, branding *models.TenantBranding, token uuid.UUID) (*models.EmailMessage, error) {
+	if mock.TenantVerificationEmailFunc == nil {
+		panic("EmailManager.TenantVerificationEmail is not stubbed")
+	}
+	return mock.TenantVerificationEmailFunc(email, lang, companyName, branding, token)
+}
+
+// TwoFactorBypassEmail calls TwoFactorBypassEmailFunc
+func (mock *EmailManager) TwoFactorBypassEmail(email string, lang string, firstName string, code string, issuedBy string, expiresAt time.Time) (*models.EmailMessage, error) {
+	if mock.TwoFactorBypassEmailFunc == nil {
+		panic("EmailManager.TwoFactorBypassEmail is not stubbed")
+	}
+	return mock.TwoFactorBypassEmailFunc(email, lang, firstName, code, issuedBy, expiresAt)
+}
+
+// TwoFactorSetupReminderEmail calls TwoFactorSetupReminderEmailFunc
+func (mock *EmailManager) TwoFactorSetupReminderEmail(email string, lang string, firstName string, companyName string, deadline time.Time) (*models.EmailMessage, error) {
+	if mock.TwoFactorSetupReminderEmailFunc == nil {
+		panic("EmailManager.TwoFactorSetupReminderEmail is not stubbed")
+	}
+	return mock.TwoFactorSetupReminderEmailFunc(email, lang, firstName, companyName, deadline)
+}
+
+// WelcomeEmail calls WelcomeEmailFunc
+func (mock *EmailManager) WelcomeEmail(email string, lang string, firstName string) (*models.EmailMessage, error) {
+	if mock.WelcomeEmailFunc == nil {
+		panic("EmailManager.WelcomeEmail is not stubbed")
+	}
+	return mock.WelcomeEmailFunc(email, lang, firstName)
+}
+
+// EmployeeManager is a mock of services.EmployeeManager
+type EmployeeManager struct {
+	CreateFunc    func(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.EmployeeCreateRequest) (*models.Employee, error)
+	DeleteFunc    func(ctx context.Context, tenantID, userID, employeeID uuid.UUID) (*models.PendingDeletion, error)
+	DescribeFunc  func(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.EntitySummary, error)
+	GetFunc       func(ctx context.Context, tenantID, employeeID uuid.UUID) (*models.Employee, error)
+	GetByUserFunc func(ctx context.Context, tenantID, userID uuid.UUID) (*models.Employee, error)
+	ListFunc      func(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit, offset int) ([]models.Employee, int, error)
+	OrgChartFunc  func(ctx context.Context, tenantID uuid.UUID, rootID *uuid.UUID, depth int) ([]models.OrgChartNode, error)
+	PurgeFunc     func(ctx context.Context, tenantID, _, employeeID uuid.UUID) error
+	UpdateFunc    func(ctx context.Context, tenantID, employeeID uuid.UUID, req *models.EmployeeUpdateRequest) (*models.Employee, error)
+}
+
+// Create calls CreateFunc
+func (mock *EmployeeManager) Create(ctx context.Context, tenantID uuid.UUID, creatorID uuid.UUID, req *models.EmployeeCreateRequest) (*models.Employee, error) {
+	if mock.CreateFunc == nil {
+		panic("EmployeeManager.Create is not stubbed")
+	}
+	return mock.CreateFunc(ctx, tenantID, creatorID, req)
+}
+
+// Delete calls DeleteFunc
+func (mock *EmployeeManager) Delete(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, employeeID uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.DeleteFunc == nil {
+		panic("EmployeeManager.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, userID, employeeID)
+}
+
+// Describe calls DescribeFunc
+func (mock *EmployeeManager) Describe(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeFunc == nil {
+		panic("EmployeeManager.Describe is not stubbed")
+	}
+	return mock.DescribeFunc(ctx, tenantID, employeeID)
+}
+
+// Get calls GetFunc
+func (mock *EmployeeManager) Get(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID) (*models.Employee, error) {
+	if mock.GetFunc == nil {
+		panic("EmployeeManager.Get is not stubbed")
+	}
+	return mock.GetFunc(ctx, tenantID, employeeID)
+}
+
+// GetByUser calls GetByUserFunc
+func (mock *EmployeeManager) GetByUser(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.Employee, error) {
+	if mock.GetByUserFunc == nil {
+		panic("EmployeeManager.GetByUser is not stubbed")
+	}
+	return mock.GetByUserFunc(ctx, tenantID, userID)
+}
+
+// List calls ListFunc
+func (mock *EmployeeManager) List(ctx context.Context, tenantID uuid.UUID, filter models.EmployeeFilter, limit int, offset int) ([]models.Employee, int, error) {
+	if mock.ListFunc == nil {
+		panic("EmployeeManager.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// OrgChart calls OrgChartFunc
+func (mock *EmployeeManager) OrgChart(ctx context.Context, tenantID uuid.UUID, rootID *uuid.UUID, depth int) ([]models.OrgChartNode, error) {
+	if mock.OrgChartFunc == nil {
+		panic("EmployeeManager.OrgChart is not stubbed")
+	}
+	return mock.OrgChartFunc(ctx, tenantID, rootID, depth)
+}
+
+// Purge calls PurgeFunc
+func (mock *EmployeeManager) Purge(ctx context.Context, tenantID uuid.UUID, arg2 uuid.UUID, employeeID uuid.UUID) error {
+	if mock.PurgeFunc == nil {
+		panic("EmployeeManager.Purge is not stubbed")
+	}
+	return mock.PurgeFunc(ctx, tenantID, arg2, employeeID)
+}
+
+// Update calls UpdateFunc
+func (mock *EmployeeManager) Update(ctx context.Context, tenantID uuid.UUID, employeeID uuid.UUID, req *models.EmployeeUpdateRequest) (*models.Employee, error) {
+	if mock.UpdateFunc == nil {
+		panic("EmployeeManager.Update is not stubbed")
+	}
+	return mock.UpdateFunc(ctx, tenantID, employeeID, req)
+}
+
+// EntitySchemaManager is a mock of services.EntitySchemaManager
+type EntitySchemaManager struct {
+	BuiltInSchemasFunc func() []models.EntitySchema
+	EntityTypesFunc    func() []string
+	GetSchemaFunc      func(ctx context.Context, tenantID, userID uuid.UUID, entityType string) (*models.EntitySchema, error)
+	ListSchemasFunc    func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.EntitySchema, error)
+	RegisterTypeFunc   func(entityType, resource string, model, input interface{}, options map[string][]string)
+}
+
+// BuiltInSchemas calls BuiltInSchemasFunc
+func (mock *EntitySchemaManager) BuiltInSchemas() []models.EntitySchema {
+	if mock.BuiltInSchemasFunc == nil {
+		panic("EntitySchemaManager.BuiltInSchemas is not stubbed")
+	}
+	return mock.BuiltInSchemasFunc()
+}
+
+// EntityTypes calls EntityTypesFunc
+func (mock *EntitySchemaManager) EntityTypes() []string {
+	if mock.EntityTypesFunc == nil {
+		panic("EntitySchemaManager.EntityTypes is not stubbed")
+	}
+	return mock.EntityTypesFunc()
+}
+
+// GetSchema calls GetSchemaFunc
+func (mock *EntitySchemaManager) GetSchema(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string) (*models.EntitySchema, error) {
+	if mock.GetSchemaFunc == nil {
+		panic("EntitySchemaManager.GetSchema is not stubbed")
+	}
+	return mock.GetSchemaFunc(ctx, tenantID, userID, entityType)
+}
+
+// ListSchemas calls ListSchemasFunc
+func (mock *EntitySchemaManager) ListSchemas(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.EntitySchema, error) {
+	if mock.ListSchemasFunc == nil {
+		panic("EntitySchemaManager.ListSchemas is not stubbed")
+	}
+	return mock.ListSchemasFunc(ctx, tenantID, userID)
+}
+
+// RegisterType calls RegisterTypeFunc
+func (mock *EntitySchemaManager) RegisterType(entityType string, resource string, model interface{}, input interface{}, options map[string][]string) {
+	if mock.RegisterTypeFunc == nil {
+		panic("EntitySchemaManager.RegisterType is not stubbed")
+	}
+	mock.RegisterTypeFunc(entityType, resource, model, input, options)
+}
+
+// EscalationManager is a mock of services.EscalationManager
+type EscalationManager struct {
+	CreatePolicyFunc     func(ctx context.Context, tenantID, userID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error)
+	DeletePolicyFunc     func(ctx context.Context, tenantID, policyID uuid.UUID) error
+	GetPolicyFunc        func(ctx context.Context, tenantID, policyID uuid.UUID) (*models.EscalationPolicy, error)
+	ListEscalationsFunc  func(ctx context.Context, tenantID, policyID uuid.UUID, limit, offset int) ([]models.Escalation, int, error)
+	ListForResourceFunc  func(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error)
+	ListPoliciesFunc     func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.EscalationPolicy, int, error)
+	ProcessDueFunc       func(ctx context.Context) (int, error)
+	RegisterSourceFunc   func(resourceType string, source services.EscalationSource)
+	ResourceTypesFunc    func() []string
+	SetPolicyEnabledFunc func(ctx context.Context, tenantID, policyID uuid.UUID, enabled bool) (*models.EscalationPolicy, error)
+	UpdatePolicyFunc     func(ctx context.Context, tenantID, policyID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error)
+}
+
+// CreatePolicy calls CreatePolicyFunc
+func (mock *EscalationManager) CreatePolicy(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	if mock.CreatePolicyFunc == nil {
+		panic("EscalationManager.CreatePolicy is not stubbed")
+	}
+	return mock.CreatePolicyFunc(ctx, tenantID, userID, req)
+}
+
+// DeletePolicy calls DeletePolicyFunc
+func (mock *EscalationManager) DeletePolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID) error {
+	if mock.DeletePolicyFunc == nil {
+		panic("EscalationManager.DeletePolicy is not stubbed")
+	}
+	return mock.DeletePolicyFunc(ctx, tenantID, policyID)
+}
+
+// GetPolicy calls GetPolicyFunc
+func (mock *EscalationManager) GetPolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID) (*models.EscalationPolicy, error) {
+	if mock.GetPolicyFunc == nil {
+		panic("EscalationManager.GetPolicy is not stubbed")
+	}
+	return mock.GetPolicyFunc(ctx, tenantID, policyID)
+}
+
+// ListEscalations calls ListEscalationsFunc
+func (mock *EscalationManager) ListEscalations(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, limit int, offset int) ([]models.Escalation, int, error) {
+	if mock.ListEscalationsFunc == nil {
+		panic("EscalationManager.ListEscalations is not stubbed")
+	}
+	return mock.ListEscalationsFunc(ctx, tenantID, policyID, limit, offset)
+}
+
+// ListForResource calls ListForResourceFunc
+func (mock *EscalationManager) ListForResource(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) ([]models.Escalation, error) {
+	if mock.ListForResourceFunc == nil {
+		panic("EscalationManager.ListForResource is not stubbed")
+	}
+	return mock.ListForResourceFunc(ctx, tenantID, resourceType, resourceID)
+}
+
+// ListPolicies calls ListPoliciesFunc
+func (mock *EscalationManager) ListPolicies(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.EscalationPolicy, int, error) {
+	if mock.ListPoliciesFunc == nil {
+		panic("EscalationManager.ListPolicies is not stubbed")
+	}
+	return mock.ListPoliciesFunc(ctx, tenantID, limit, offset)
+}
+
+// ProcessDue calls ProcessDueFunc
+func (mock *EscalationManager) ProcessDue(ctx context.Context) (int, error) {
+	if mock.ProcessDueFunc == nil {
+		panic("EscalationManager.ProcessDue is not stubbed")
+	}
+	return mock.ProcessDueFunc(ctx)
+}
+
+// RegisterSource calls RegisterSourceFunc
+func (mock *EscalationManager) RegisterSource(resourceType string, source services.EscalationSource) {
+	if mock.RegisterSourceFunc == nil {
+		panic("EscalationManager.RegisterSource is not stubbed")
+	}
+	mock.RegisterSourceFunc(resourceType, source)
+}
+
+// ResourceTypes calls ResourceTypesFunc
+func (mock *EscalationManager) ResourceTypes() []string {
+	if mock.ResourceTypesFunc == nil {
+		panic("EscalationManager.ResourceTypes is not stubbed")
+	}
+	return mock.ResourceTypesFunc()
+}
+
+// SetPolicyEnabled calls SetPolicyEnabledFunc
+func (mock *EscalationManager) SetPolicyEnabled(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, enabled bool) (*models.EscalationPolicy, error) {
+	if mock.SetPolicyEnabledFunc == nil {
+		panic("EscalationManager.SetPolicyEnabled is not stubbed")
+	}
+	return mock.SetPolicyEnabledFunc(ctx, tenantID, policyID, enabled)
+}
+
+// UpdatePolicy calls UpdatePolicyFunc
+func (mock *EscalationManager) UpdatePolicy(ctx context.Context, tenantID uuid.UUID, policyID uuid.UUID, req *models.EscalationPolicyRequest) (*models.EscalationPolicy, error) {
+	if mock.UpdatePolicyFunc == nil {
+		panic("EscalationManager.UpdatePolicy is not stubbed")
+	}
+	return mock.UpdatePolicyFunc(ctx, tenantID, policyID, req)
+}
+
+// FileManager is a mock of services.FileManager
+type FileManager struct {
+	DeleteFunc              func(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	DeleteResourceFilesFunc func(ctx context.Context, tenantID, userID uuid.UUID, category, resourceType string, resourceID uuid.UUID, keep []uuid.UUID) (int, error)
+	DownloadURLFunc         func(file *models.File) string
+	GetFunc                 func(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	ListFunc                func(ctx context.Context, tenantID, userID uuid.UUID, filter models.FileFilter) ([]models.File, int, error)
+	ListTenantFilesFunc     func(ctx context.Context, tenantID uuid.UUID) ([]models.File, error)
+	MaxFileSizeFunc         func() int64
+	OpenFunc                func(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, io.ReadCloser, error)
+	OpenPublicFunc          func(ctx context.Context, tenantID, fileID uuid.UUID) (*models.File, io.ReadCloser, error)
+	OpenSignedFunc          func(ctx context.Context, token string) (*models.File, io.ReadCloser, error)
+	PublicURLFunc           func(file *models.File) string
+	PurgeDeletedFunc        func(ctx context.Context) (int, error)
+	RemoveTenantFilesFunc   func(ctx context.Context, tenantID uuid.UUID) error
+	RestoreFunc             func(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.File, error)
+	SignedURLFunc           func(ctx context.Context, tenantID, userID, fileID uuid.UUID) (*models.FileURL, error)
+	StoreGeneratedFunc      func(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, contentType string, body io.Reader) (*models.File, error)
+	UploadFunc              func(ctx context.Context, tenantID, userID uuid.UUID, upload *models.FileUpload, body io.Reader) (*models.File, error)
+}
+
+// Delete calls DeleteFunc
+func (mock *FileManager) Delete(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileID uuid.UUID) (*models.File, error) {
+	if mock.DeleteFunc == nil {
+		panic("FileManager.Delete is not stubbed")
+	}
+	return mock.DeleteFunc(ctx, tenantID, userID, fileID)
+}
+
+// DeleteResourceFiles calls DeleteResourceFilesFunc
+func (mock *FileManager) DeleteResourceFiles(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, category string, resourceType string, resourceID uuid.UUID, keep []uuid.UUID) (int, error) {
+	if mock.DeleteResourceFilesFunc == nil {
+		panic("FileManager.DeleteResourceFiles is not stubbed")
+	}
+	return mock.DeleteResourceFilesFunc(ctx, tenantID, userID, category, resourceType, resourceID, keep)
+}
+
+// DownloadURL calls DownloadURLFunc
+func (mock *FileManager) DownloadURL(file *models.File) string {
+	if mock.DownloadURLFunc == nil {
+		panic("FileManager.DownloadURL is not stubbed")
+	}
+	return mock.DownloadURLFunc(file)
+}
+
+// Get calls GetFunc
+func (mock *FileManager) Get(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileID uuid.UUID) (*models.File, error) {
+	if mock.GetFunc == nil {
+		panic("FileManager.Get is not stubbed")
+	}
+	return mock.GetFunc(ctx, tenantID, userID, fileID)
+}
+
+// List calls ListFunc
+func (mock *FileManager) List(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.FileFilter) ([]models.File, int, error) {
+	if mock.ListFunc == nil {
+		panic("FileManager.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, userID, filter)
+}
+
+// ListTenantFiles calls ListTenantFilesFunc
+func (mock *FileManager) ListTenantFiles(ctx context.Context, tenantID uuid.UUID) ([]models.File, error) {
+	if mock.ListTenantFilesFunc == nil {
+		panic("FileManager.ListTenantFiles is not stubbed")
+	}
+	return mock.ListTenantFilesFunc(ctx, tenantID)
+}
+
+// MaxFileSize calls MaxFileSizeFunc
+func (mock *FileManager) MaxFileSize() int64 {
+	if mock.MaxFileSizeFunc == nil {
+		panic("FileManager.MaxFileSize is not stubbed")
+	}
+	return mock.MaxFileSizeFunc()
+}
+
+// Open calls OpenFunc
+func (mock *FileManager) Open(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileID uuid.UUID) (*models.File, io.ReadCloser, error) {
+	if mock.OpenFunc == nil {
+		panic("FileManager.Open is not stubbed")
+	}
+	return mock.OpenFunc(ctx, tenantID, userID, fileID)
+}
+
+// OpenPublic calls OpenPublicFunc
+func (mock *FileManager) OpenPublic(ctx context.Context, tenantID uuid.UUID, fileID uuid.UUID) (*models.File, io.ReadCloser, error) {
+	if mock.OpenPublicFunc == nil {
+		panic("FileManager.OpenPublic is not stubbed")
+	}
+	return mock.OpenPublicFunc(ctx, tenantID, fileID)
+}
+
+// OpenSigned calls OpenSignedFunc
+func (mock *FileManager) OpenSigned(ctx context.Context, token string) (*models.File, io.ReadCloser, error) {
+	if mock.OpenSignedFunc == nil {
+		panic("FileManager.OpenSigned is not stubbed")
+	}
+	return mock.OpenSignedFunc(ctx, token)
+}
+
+// PublicURL calls PublicURLFunc
+func (mock *FileManager) PublicURL(file *models.File) string {
+	if mock.PublicURLFunc == nil {
+		panic("FileManager.PublicURL is not stubbed")
+	}
+	return mock.PublicURLFunc(file)
+}
+
+// PurgeDeleted calls PurgeDeletedFunc
+func (mock *FileManager) PurgeDeleted(ctx context.Context) (int, error) {
+	if mock.PurgeDeletedFunc == nil {
+		panic("FileManager.PurgeDeleted is not stubbed")
+	}
+	return mock.PurgeDeletedFunc(ctx)
+}
+
+// RemoveTenantFiles calls RemoveTenantFilesFunc
+func (mock *FileManager) RemoveTenantFiles(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.RemoveTenantFilesFunc == nil {
+		panic("FileManager.RemoveTenantFiles is not stubbed")
+	}
+	return mock.RemoveTenantFilesFunc(ctx, tenantID)
+}
+
+// Restore calls RestoreFunc
+func (mock *FileManager) Restore(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileID uuid.UUID) (*models.File, error) {
+	if mock.RestoreFunc == nil {
+		panic("FileManager.Restore is not stubbed")
+	}
+	return mock.RestoreFunc(ctx, tenantID, userID, fileID)
+}
+
+// SignedURL calls SignedURLFunc
+func (mock *FileManager) SignedURL(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileID uuid.UUID) (*models.FileURL, error) {
+	if mock.SignedURLFunc == nil {
+		panic("FileManager.SignedURL is not stubbed")
+	}
+	return mock.SignedURLFunc(ctx, tenantID, userID, fileID)
+}
+
+// StoreGenerated calls StoreGeneratedFunc
+func (mock *FileManager) StoreGenerated(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, upload *models.FileUpload, contentType string, body io.Reader) (*models.File, error) {
+	if mock.StoreGeneratedFunc == nil {
+		panic("FileManager.StoreGenerated is not stubbed")
+	}
+	return mock.StoreGeneratedFunc(ctx, tenantID, userID, upload, contentType, body)
+}
+
+// Upload calls UploadFunc
+func (mock *FileManager) Upload(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, upload *models.FileUpload, body io.Reader) (*models.File, error) {
+	if mock.UploadFunc == nil {
+		panic("FileManager.Upload is not stubbed")
+	}
+	return mock.UploadFunc(ctx, tenantID, userID, upload, body)
+}
+
+// InvitationManager is a mock of services.InvitationManager
+type InvitationManager struct {
+	AcceptInvitationFunc func(
+		ctx context.Context,
+		token, password, firstName, lastName string,
+	) (*models.User, error)
+	CleanupExpiredInvitationsFunc func(ctx context.Context) (int, error)
+	CreateInvitationFunc          func(
+		ctx context.Context,
+		tenantID, invitedBy uuid.UUID,
+		email string,
+		roleIDs []uuid.UUID,
+		message string,
+	) (*models.Invitation, error)
+	GetInvitationFunc    func(ctx context.Context, tenantID, invitationID uuid.UUID) (*models.Invitation, error)
+	ListInvitationsFunc  func(ctx context.Context, tenantID uuid.UUID, filter models.InvitationFilter, page *pagination.Request) ([]models.Invitation, int, string, error)
+	ResendInvitationFunc func(ctx context.Context, tenantID, invitationID, resendBy uuid.UUID) error
+	RevokeInvitationFunc func(ctx context.Context, tenantID, invitationID uuid.UUID) error
+}
+
+// AcceptInvitation calls AcceptInvitationFunc
+func (mock *InvitationManager) AcceptInvitation(ctx context.Context, token string, password string, firstName string, lastName string) (*models.User, error) {
+	if mock.AcceptInvitationFunc == nil {
+		panic("InvitationManager.AcceptInvitation is not stubbed")
+	}
+	return mock.AcceptInvitationFunc(ctx, token, password, firstName, lastName)
+}
+
+// CleanupExpiredInvitations calls CleanupExpiredInvitationsFunc
+func (mock *InvitationManager) CleanupExpiredInvitations(ctx context.Context) (int, error) {
+	if mock.CleanupExpiredInvitationsFunc == nil {
+		panic("InvitationManager.CleanupExpiredInvitations is not stubbed")
+	}
+	return mock.CleanupExpiredInvitationsFunc(ctx)
+}
+
+// CreateInvitation calls CreateInvitationFunc
+func (mock *InvitationManager) CreateInvitation(ctx context.Context, tenantID uuid.UUID, invitedBy uuid.UUID, email string, roleIDs []uuid.UUID, message string) (*models.Invitation, error) {
+	if mock.CreateInvitationFunc == nil {
+		panic("InvitationManager.CreateInvitation is not stubbed")
+	}
+	return mock.CreateInvitationFunc(ctx, tenantID, invitedBy, email, roleIDs, message)
+}
+
+// GetInvitation calls GetInvitationFunc
+func (mock *InvitationManager) GetInvitation(ctx context.Context, tenantID uuid.UUID, invitationID uuid.UUID) (*models.Invitation, error) {
+	if mock.GetInvitationFunc == nil {
+		panic("InvitationManager.GetInvitation is not stubbed")
+	}
+	return mock.GetInvitationFunc(ctx, tenantID, invitationID)
+}
+
+// ListInvitations calls ListInvitationsFunc
+func (mock *InvitationManager) ListInvitations(ctx context.Context, tenantID uuid.UUID, filter models.InvitationFilter, page *pagination.Request) ([]models.Invitation, int, string, error) {
+	if mock.ListInvitationsFunc == nil {
+		panic("InvitationManager.ListInvitations is not stubbed")
+	}
+	return mock.ListInvitationsFunc(ctx, tenantID, filter, page)
+}
+
+// ResendInvitation calls ResendInvitationFunc
+func (mock *InvitationManager) ResendInvitation(ctx context.Context, tenantID uuid.UUID, invitationID uuid.UUID, resendBy uuid.UUID) error {
+	if mock.ResendInvitationFunc == nil {
+		panic("InvitationManager.ResendInvitation is not stubbed")
+	}
+	return mock.ResendInvitationFunc(ctx, tenantID, invitationID, resendBy)
+}
+
+// RevokeInvitation calls RevokeInvitationFunc
+func (mock *InvitationManager) RevokeInvitation(ctx context.Context, tenantID uuid.UUID, invitationID uuid.UUID) error {
+	if mock.RevokeInvitationFunc == nil {
+		panic("InvitationManager.RevokeInvitation is not stubbed")
+	}
+	return mock.RevokeInvitationFunc(ctx, tenantID, invitationID)
+}
+
+// JWTManager is a mock of services.JWTManager
+type JWTManager struct {
+	ExtractClaimsFunc        func(tokenString string) (*services.Claims, error)
+	Generate2FATokenFunc     func(userID, tenantID uuid.UUID, tenantSlug, email string) (string, error)
+	GenerateAccessTokenFunc  func(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox, rememberMe bool) (string, int64, error)
+	GenerateRefreshTokenFunc func(userID, tenantID uuid.UUID, tenantSlug, email string, sandbox bool) (string, error)
+	GetTokenExpiryFunc       func(tokenString string) (time.Time, error)
+	IsTokenExpiredFunc       func(tokenString string) bool
+	RefreshAccessTokenFunc   func(refreshToken string, rememberMe bool) (string, int64, error)
+	Validate2FATokenFunc     func(tokenString string) (*services.Claims, error)
+	ValidateAccessTokenFunc  func(tokenString string) (*services.Claims, error)
+	ValidateRefreshTokenFunc func(tokenString string) (*services.Claims, error)
+}
+
+// ExtractClaims calls ExtractClaimsFunc
+func (mock *JWTManager) ExtractClaims(tokenString string) (*services.Claims, error) {
+	if mock.ExtractClaimsFunc == nil {
+		panic("JWTManager.ExtractClaims is not stubbed")
+	}
+	return mock.ExtractClaimsFunc(tokenString)
+}
+
+// Generate2FAToken calls Generate2FATokenFunc
+func (mock *JWTManager) Generate2FAToken(userID uuid.UUID, tenantID uuid.UUID, tenantSlug string, email string) (string, error) {
+	if mock.Generate2FATokenFunc == nil {
+		panic("JWTManager.Generate2FAToken is not stubbed")
+	}
+	return mock.Generate2FATokenFunc(userID, tenantID, tenantSlug, email)
+}
+
+// GenerateAccessToken calls GenerateAccessTokenFunc
+func (mock *JWTManager) GenerateAccessToken(userID uuid.UUID, tenantID uuid.UUID, tenantSlug string, email string, sandbox bool, rememberMe bool) (string, int64, error) {
+	if mock.GenerateAccessTokenFunc == nil {
+		panic("JWTManager.GenerateAccessToken is not stubbed")
+	}
+	return mock.GenerateAccessTokenFunc(userID, tenantID, tenantSlug, email, sandbox, rememberMe)
+}
+
+// GenerateRefreshToken calls GenerateRefreshTokenFunc
+func (mock *JWTManager) GenerateRefreshToken(userID uuid.UUID, tenantID uuid.UUID, tenantSlug string, email string, sandbox bool) (string, error) {
+	if mock.GenerateRefreshTokenFunc == nil {
+		panic("JWTManager.GenerateRefreshToken is not stubbed")
+	}
+	return mock.GenerateRefreshTokenFunc(userID, tenantID, tenantSlug, email, sandbox)
+}
+
+// GetTokenExpiry calls GetTokenExpiryFunc
+func (mock *JWTManager) GetTokenExpiry(tokenString string) (time.Time, error) {
+	if mock.GetTokenExpiryFunc == nil {
+		panic("JWTManager.GetTokenExpiry is not stubbed")
+	}
+	return mock.GetTokenExpiryFunc(tokenString)
+}
+
+// IsTokenExpired calls IsTokenExpiredFunc
+func (mock *JWTManager) IsTokenExpired(tokenString string) bool {
+	if mock.IsTokenExpiredFunc == nil {
+		panic("JWTManager.IsTokenExpired is not stubbed")
+	}
+	return mock.IsTokenExpiredFunc(tokenString)
+}
+
+// RefreshAccessToken calls RefreshAccessTokenFunc
+func (mock *JWTManager) RefreshAccessToken(refreshToken string, rememberMe bool) (string, int64, error) {
+	if mock.RefreshAccessTokenFunc == nil {
+		panic("JWTManager.RefreshAccessToken is not stubbed")
+	}
+	return mock.RefreshAccessTokenFunc(refreshToken, rememberMe)
+}
+
+// Validate2FAToken calls Validate2FATokenFunc
+func (mock *JWTManager) Validate2FAToken(tokenString string) (*services.Claims, error) {
+	if mock.Validate2FATokenFunc == nil {
+		panic("JWTManager.Validate2FAToken is not stubbed")
+	}
+	return mock.Validate2FATokenFunc(tokenString)
+}
+
+// ValidateAccessToken calls ValidateAccessTokenFunc
+func (mock *JWTManager) ValidateAccessToken(tokenString string) (*services.Claims, error) {
+	if mock.ValidateAccessTokenFunc == nil {
+		panic("JWTManager.ValidateAccessToken is not stubbed")
+	}
+	return mock.ValidateAccessTokenFunc(tokenString)
+}
+
+// ValidateRefreshToken calls ValidateRefreshTokenFunc
+func (mock *JWTManager) ValidateRefreshToken(tokenString string) (*services.Claims, error) {
+	if mock.ValidateRefreshTokenFunc == nil {
+		panic("JWTManager.ValidateRefreshToken is not stubbed")
+	}
+	return mock.ValidateRefreshTokenFunc(tokenString)
+}
+
+// LeaveManager is a mock of services.LeaveManager
+type LeaveManager struct {
+	AdjustBalanceFunc func(ctx context.Context, tenantID uuid.UUID, req *models.LeaveBalanceAdjustRequest) (*models.LeaveBalance, error)
+	ApproveFunc       func(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error)
+	BalancesFunc      func(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, year int) ([]models.LeaveBalance, error)
+	CalendarFunc      func(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error)
+	CancelFunc        func(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	CreateTypeFunc    func(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error)
+	DeleteTypeFunc    func(ctx context.Context, tenantID, typeID uuid.UUID) error
+	GetFunc           func(ctx context.Context, tenantID, userID, requestID uuid.UUID) (*models.LeaveRequest, error)
+	GetTypeFunc       func(ctx context.Context, tenantID, typeID uuid.UUID) (*models.LeaveType, error)
+	ListFunc          func(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListApprovalsFunc func(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListOwnFunc       func(ctx context.Context, tenantID, userID uuid.UUID, filter models.LeaveRequestFilter, limit, offset int) ([]models.LeaveRequest, int, error)
+	ListTypesFunc     func(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error)
+	RejectFunc        func(ctx context.Context, tenantID, userID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error)
+	RunAccrualFunc    func(ctx context.Context) (int, error)
+	SubmitFunc        func(ctx context.Context, tenantID, userID uuid.UUID, req *models.LeaveRequestCreateRequest) (*models.LeaveRequest, error)
+	UpdateTypeFunc    func(ctx context.Context, tenantID, typeID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error)
+}
+
+// AdjustBalance calls AdjustBalanceFunc
+func (mock *LeaveManager) AdjustBalance(ctx context.Context, tenantID uuid.UUID, req *models.LeaveBalanceAdjustRequest) (*models.LeaveBalance, error) {
+	if mock.AdjustBalanceFunc == nil {
+		panic("LeaveManager.AdjustBalance is not stubbed")
+	}
+	return mock.AdjustBalanceFunc(ctx, tenantID, req)
+}
+
+// Approve calls ApproveFunc
+func (mock *LeaveManager) Approve(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error) {
+	if mock.ApproveFunc == nil {
+		panic("LeaveManager.Approve is not stubbed")
+	}
+	return mock.ApproveFunc(ctx, tenantID, userID, requestID, note)
+}
+
+// Balances calls BalancesFunc
+func (mock *LeaveManager) Balances(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, employeeID *uuid.UUID, year int) ([]models.LeaveBalance, error) {
+	if mock.BalancesFunc == nil {
+		panic("LeaveManager.Balances is not stubbed")
+	}
+	return mock.BalancesFunc(ctx, tenantID, userID, employeeID, year)
+}
+
+// Calendar calls CalendarFunc
+func (mock *LeaveManager) Calendar(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.LeaveCalendarFilter) ([]models.LeaveCalendarEntry, error) {
+	if mock.CalendarFunc == nil {
+		panic("LeaveManager.Calendar is not stubbed")
+	}
+	return mock.CalendarFunc(ctx, tenantID, userID, filter)
+}
+
+// Cancel calls CancelFunc
+func (mock *LeaveManager) Cancel(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	if mock.CancelFunc == nil {
+		panic("LeaveManager.Cancel is not stubbed")
+	}
+	return mock.CancelFunc(ctx, tenantID, userID, requestID)
+}
+
+// CreateType calls CreateTypeFunc
+func (mock *LeaveManager) CreateType(ctx context.Context, tenantID uuid.UUID, creatorID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error) {
+	if mock.CreateTypeFunc == nil {
+		panic("LeaveManager.CreateType is not stubbed")
+	}
+	return mock.CreateTypeFunc(ctx, tenantID, creatorID, req)
+}
+
+// DeleteType calls DeleteTypeFunc
+func (mock *LeaveManager) DeleteType(ctx context.Context, tenantID uuid.UUID, typeID uuid.UUID) error {
+	if mock.DeleteTypeFunc == nil {
+		panic("LeaveManager.DeleteType is not stubbed")
+	}
+	return mock.DeleteTypeFunc(ctx, tenantID, typeID)
+}
+
+// Get calls GetFunc
+func (mock *LeaveManager) Get(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	if mock.GetFunc == nil {
+		panic("LeaveManager.Get is not stubbed")
+	}
+	return mock.GetFunc(ctx, tenantID, userID, requestID)
+}
+
+// GetType calls GetTypeFunc
+func (mock *LeaveManager) GetType(ctx context.Context, tenantID uuid.UUID, typeID uuid.UUID) (*models.LeaveType, error) {
+	if mock.GetTypeFunc == nil {
+		panic("LeaveManager.GetType is not stubbed")
+	}
+	return mock.GetTypeFunc(ctx, tenantID, typeID)
+}
+
+// List calls ListFunc
+func (mock *LeaveManager) List(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.LeaveRequestFilter, limit int, offset int) ([]models.LeaveRequest, int, error) {
+	if mock.ListFunc == nil {
+		panic("LeaveManager.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, userID, filter, limit, offset)
+}
+
+// ListApprovals calls ListApprovalsFunc
+func (mock *LeaveManager) ListApprovals(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int, offset int) ([]models.LeaveRequest, int, error) {
+	if mock.ListApprovalsFunc == nil {
+		panic("LeaveManager.ListApprovals is not stubbed")
+	}
+	return mock.ListApprovalsFunc(ctx, tenantID, userID, limit, offset)
+}
+
+// ListOwn calls ListOwnFunc
+func (mock *LeaveManager) ListOwn(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.LeaveRequestFilter, limit int, offset int) ([]models.LeaveRequest, int, error) {
+	if mock.ListOwnFunc == nil {
+		panic("LeaveManager.ListOwn is not stubbed")
+	}
+	return mock.ListOwnFunc(ctx, tenantID, userID, filter, limit, offset)
+}
+
+// ListTypes calls ListTypesFunc
+func (mock *LeaveManager) ListTypes(ctx context.Context, tenantID uuid.UUID, activeOnly bool) ([]models.LeaveType, error) {
+	if mock.ListTypesFunc == nil {
+		panic("LeaveManager.ListTypes is not stubbed")
+	}
+	return mock.ListTypesFunc(ctx, tenantID, activeOnly)
+}
+
+// Reject calls RejectFunc
+func (mock *LeaveManager) Reject(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, requestID uuid.UUID, note *string) (*models.LeaveRequest, error) {
+	if mock.RejectFunc == nil {
+		panic("LeaveManager.Reject is not stubbed")
+	}
+	return mock.RejectFunc(ctx, tenantID, userID, requestID, note)
+}
+
+// RunAccrual calls RunAccrualFunc
+func (mock *LeaveManager) RunAccrual(ctx context.Context) (int, error) {
+	if mock.RunAccrualFunc == nil {
+		panic("LeaveManager.RunAccrual is not stubbed")
+	}
+	return mock.RunAccrualFunc(ctx)
+}
+
+// Submit calls SubmitFunc
+func (mock *LeaveManager) Submit(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.LeaveRequestCreateRequest) (*models.LeaveRequest, error) {
+	if mock.SubmitFunc == nil {
+		panic("LeaveManager.Submit is not stubbed")
+	}
+	return mock.SubmitFunc(ctx, tenantID, userID, req)
+}
+
+// UpdateType calls UpdateTypeFunc
+func (mock *LeaveManager) UpdateType(ctx context.Context, tenantID uuid.UUID, typeID uuid.UUID, req *models.LeaveTypeRequest) (*models.LeaveType, error) {
+	if mock.UpdateTypeFunc == nil {
+		panic("LeaveManager.UpdateType is not stubbed")
+	}
+	return mock.UpdateTypeFunc(ctx, tenantID, typeID, req)
+}
+
+// MaintenanceManager is a mock of services.MaintenanceManager
+type MaintenanceManager struct {
+	CancelWindowFunc   func(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error)
+	GetWindowFunc      func(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error)
+	ScheduleWindowFunc func(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) (*models.MaintenanceWindow, error)
+}
+
+// CancelWindow calls CancelWindowFunc
+func (mock *MaintenanceManager) CancelWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error) {
+	if mock.CancelWindowFunc == nil {
+		panic("MaintenanceManager.CancelWindow is not stubbed")
+	}
+	return mock.CancelWindowFunc(ctx, tenantID)
+}
+
+// GetWindow calls GetWindowFunc
+func (mock *MaintenanceManager) GetWindow(ctx context.Context, tenantID uuid.UUID) (*models.MaintenanceWindow, error) {
+	if mock.GetWindowFunc == nil {
+		panic("MaintenanceManager.GetWindow is not stubbed")
+	}
+	return mock.GetWindowFunc(ctx, tenantID)
+}
+
+// ScheduleWindow calls ScheduleWindowFunc
+func (mock *MaintenanceManager) ScheduleWindow(ctx context.Context, tenantID uuid.UUID, req *models.MaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	if mock.ScheduleWindowFunc == nil {
+		panic("MaintenanceManager.ScheduleWindow is not stubbed")
+	}
+	return mock.ScheduleWindowFunc(ctx, tenantID, req)
+}
+
+// MeteringManager is a mock of services.MeteringManager
+type MeteringManager struct {
+	CleanupFunc         func(ctx context.Context) (int, error)
+	GetUsageFunc        func(ctx context.Context, tenantID uuid.UUID, month time.Time) (*models.TenantUsageReport, error)
+	MonthToDateFunc     func(ctx context.Context, tenantID uuid.UUID, metric string) (int64, error)
+	RecordAPICallFunc   func(ctx context.Context, tenant *models.Tenant, userID uuid.UUID, exempt bool) (bool, int64, error)
+	RecordEmailSentFunc func(ctx context.Context, tenantID uuid.UUID)
+	RecordStorageFunc   func(ctx context.Context, tenantID uuid.UUID, bytes int64)
+	RollupFunc          func(ctx context.Context) (int, error)
+}
+
+// Cleanup calls CleanupFunc
+func (mock *MeteringManager) Cleanup(ctx context.Context) (int, error) {
+	if mock.CleanupFunc == nil {
+		panic("MeteringManager.Cleanup is not stubbed")
+	}
+	return mock.CleanupFunc(ctx)
+}
+
+// GetUsage calls GetUsageFunc
+func (mock *MeteringManager) GetUsage(ctx context.Context, tenantID uuid.UUID, month time.Time) (*models.TenantUsageReport, error) {
+	if mock.GetUsageFunc == nil {
+		panic("MeteringManager.GetUsage is not stubbed")
+	}
+	return mock.GetUsageFunc(ctx, tenantID, month)
+}
+
+// MonthToDate calls MonthToDateFunc
+func (mock *MeteringManager) MonthToDate(ctx context.Context, tenantID uuid.UUID, metric string) (int64, error) {
+	if mock.MonthToDateFunc == nil {
+		panic("MeteringManager.MonthToDate is not stubbed")
+	}
+	return mock.MonthToDateFunc(ctx, tenantID, metric)
+}
+
+// RecordAPICall calls RecordAPICallFunc
+func (mock *MeteringManager) RecordAPICall(ctx context.Context, tenant *models.Tenant, userID uuid.UUID, exempt bool) (bool, int64, error) {
+	if mock.RecordAPICallFunc == nil {
+		panic("MeteringManager.RecordAPICall is not stubbed")
+	}
+	return mock.RecordAPICallFunc(ctx, tenant, userID, exempt)
+}
+
+// RecordEmailSent calls RecordEmailSentFunc
+func (mock *MeteringManager) RecordEmailSent(ctx context.Context, tenantID uuid.UUID) {
+	if mock.RecordEmailSentFunc == nil {
+		panic("MeteringManager.RecordEmailSent is not stubbed")
+	}
+	mock.RecordEmailSentFunc(ctx, tenantID)
+}
+
+// RecordStorage calls RecordStorageFunc
+func (mock *MeteringManager) RecordStorage(ctx context.Context, tenantID uuid.UUID, bytes int64) {
+	if mock.RecordStorageFunc == nil {
+		panic("MeteringManager.RecordStorage is not stubbed")
+	}
+	mock.RecordStorageFunc(ctx, tenantID, bytes)
+}
+
+// Rollup calls RollupFunc
+func (mock *MeteringManager) Rollup(ctx context.Context) (int, error) {
+	if mock.RollupFunc == nil {
+		panic("MeteringManager.Rollup is not stubbed")
+	}
+	return mock.RollupFunc(ctx)
+}
+
+// NavigationManager is a mock of services.NavigationManager
+type NavigationManager struct {
+	AddFavoriteFunc    func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.Favorite, bool, error)
+	EntityTypesFunc    func() []string
+	FlushRecentFunc    func(ctx context.Context) (int, error)
+	ListFavoritesFunc  func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error)
+	ListRecentFunc     func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error)
+	RecordViewFunc     func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	RegisterTypeFunc   func(entityType, resource string, describe services.EntityDescriber)
+	RemoveFavoriteFunc func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+}
+
+// AddFavorite calls AddFavoriteFunc
+func (mock *NavigationManager) AddFavorite(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.Favorite, bool, error) {
+	if mock.AddFavoriteFunc == nil {
+		panic("NavigationManager.AddFavorite is not stubbed")
+	}
+	return mock.AddFavoriteFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// EntityTypes calls EntityTypesFunc
+func (mock *NavigationManager) EntityTypes() []string {
+	if mock.EntityTypesFunc == nil {
+		panic("NavigationManager.EntityTypes is not stubbed")
+	}
+	return mock.EntityTypesFunc()
+}
+
+// FlushRecent calls FlushRecentFunc
+func (mock *NavigationManager) FlushRecent(ctx context.Context) (int, error) {
+	if mock.FlushRecentFunc == nil {
+		panic("NavigationManager.FlushRecent is not stubbed")
+	}
+	return mock.FlushRecentFunc(ctx)
+}
+
+// ListFavorites calls ListFavoritesFunc
+func (mock *NavigationManager) ListFavorites(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Favorite, error) {
+	if mock.ListFavoritesFunc == nil {
+		panic("NavigationManager.ListFavorites is not stubbed")
+	}
+	return mock.ListFavoritesFunc(ctx, tenantID, userID)
+}
+
+// ListRecent calls ListRecentFunc
+func (mock *NavigationManager) ListRecent(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.RecentView, error) {
+	if mock.ListRecentFunc == nil {
+		panic("NavigationManager.ListRecent is not stubbed")
+	}
+	return mock.ListRecentFunc(ctx, tenantID, userID)
+}
+
+// RecordView calls RecordViewFunc
+func (mock *NavigationManager) RecordView(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.RecordViewFunc == nil {
+		panic("NavigationManager.RecordView is not stubbed")
+	}
+	return mock.RecordViewFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// RegisterType calls RegisterTypeFunc
+func (mock *NavigationManager) RegisterType(entityType string, resource string, describe services.EntityDescriber) {
+	if mock.RegisterTypeFunc == nil {
+		panic("NavigationManager.RegisterType is not stubbed")
+	}
+	mock.RegisterTypeFunc(entityType, resource, describe)
+}
+
+// RemoveFavorite calls RemoveFavoriteFunc
+func (mock *NavigationManager) RemoveFavorite(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.RemoveFavoriteFunc == nil {
+		panic("NavigationManager.RemoveFavorite is not stubbed")
+	}
+	return mock.RemoveFavoriteFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// NotificationManager is a mock of services.NotificationManager
+type NotificationManager struct {
+	CleanupReadFunc       func(ctx context.Context) (int, error)
+	CountUnreadFunc       func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	GetPreferencesFunc    func(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error)
+	ListFunc              func(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error)
+	MarkReadFunc          func(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	MarkUnreadFunc        func(ctx context.Context, tenantID, userID, id uuid.UUID) (int, error)
+	NotifyFunc            func(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) error
+	SendDigestsFunc       func(ctx context.Context) (int, error)
+	SubscribeFunc         func(ctx context.Context, tenantID, userID uuid.UUID) *redis.PubSub
+	UpdatePreferencesFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.NotificationPreferencesUpdateRequest) (*models.NotificationPreferences, error)
+}
+
+// CleanupRead calls CleanupReadFunc
+func (mock *NotificationManager) CleanupRead(ctx context.Context) (int, error) {
+	if mock.CleanupReadFunc == nil {
+		panic("NotificationManager.CleanupRead is not stubbed")
+	}
+	return mock.CleanupReadFunc(ctx)
+}
+
+// CountUnread calls CountUnreadFunc
+func (mock *NotificationManager) CountUnread(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.CountUnreadFunc == nil {
+		panic("NotificationManager.CountUnread is not stubbed")
+	}
+	return mock.CountUnreadFunc(ctx, tenantID, userID)
+}
+
+// GetPreferences calls GetPreferencesFunc
+func (mock *NotificationManager) GetPreferences(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	if mock.GetPreferencesFunc == nil {
+		panic("NotificationManager.GetPreferences is not stubbed")
+	}
+	return mock.GetPreferencesFunc(ctx, tenantID, userID)
+}
+
+// List calls ListFunc
+func (mock *NotificationManager) List(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, unreadOnly bool, limit int, offset int) ([]models.Notification, int, error) {
+	if mock.ListFunc == nil {
+		panic("NotificationManager.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// MarkRead calls MarkReadFunc
+func (mock *NotificationManager) MarkRead(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	if mock.MarkReadFunc == nil {
+		panic("NotificationManager.MarkRead is not stubbed")
+	}
+	return mock.MarkReadFunc(ctx, tenantID, userID, ids)
+}
+
+// MarkUnread calls MarkUnreadFunc
+func (mock *NotificationManager) MarkUnread(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, id uuid.UUID) (int, error) {
+	if mock.MarkUnreadFunc == nil {
+		panic("NotificationManager.MarkUnread is not stubbed")
+	}
+	return mock.MarkUnreadFunc(ctx, tenantID, userID, id)
+}
+
+// Notify calls NotifyFunc
+func (mock *NotificationManager) Notify(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) error {
+	if mock.NotifyFunc == nil {
+		panic("NotificationManager.Notify is not stubbed")
+	}
+	return mock.NotifyFunc(ctx, tenantID, userIDs, req)
+}
+
+// SendDigests calls SendDigestsFunc
+func (mock *NotificationManager) SendDigests(ctx context.Context) (int, error) {
+	if mock.SendDigestsFunc == nil {
+		panic("NotificationManager.SendDigests is not stubbed")
+	}
+	return mock.SendDigestsFunc(ctx)
+}
+
+// Subscribe calls SubscribeFunc
+func (mock *NotificationManager) Subscribe(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) *redis.PubSub {
+	if mock.SubscribeFunc == nil {
+		panic("NotificationManager.Subscribe is not stubbed")
+	}
+	return mock.SubscribeFunc(ctx, tenantID, userID)
+}
+
+// UpdatePreferences calls UpdatePreferencesFunc
+func (mock *NotificationManager) UpdatePreferences(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.NotificationPreferencesUpdateRequest) (*models.NotificationPreferences, error) {
+	if mock.UpdatePreferencesFunc == nil {
+		panic("NotificationManager.UpdatePreferences is not stubbed")
+	}
+	return mock.UpdatePreferencesFunc(ctx, tenantID, userID, req)
+}
+
+// OffboardingManager is a mock of services.OffboardingManager
+type OffboardingManager struct {
+	CancelDeletionFunc  func(ctx context.Context, tenantID uuid.UUID) error
+	ConfirmDeletionFunc func(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.TenantDeletion, error)
+	GetDeletionFunc     func(ctx context.Context, tenantID uuid.UUID) (*models.TenantDeletion, error)
+	GetExportFunc       func(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	PurgeDueFunc        func(ctx context.Context) (int, error)
+	RequestDeletionFunc func(ctx context.Context, tenantID, userID uuid.UUID, password string) (*models.TenantDeletion, error)
+	RequestExportFunc   func(ctx context.Context, tenantID, userID uuid.UUID) (*models.AsyncJob, error)
+	RunExportJobFunc    func(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error)
+}
+
+// CancelDeletion calls CancelDeletionFunc
+func (mock *OffboardingManager) CancelDeletion(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.CancelDeletionFunc == nil {
+		panic("OffboardingManager.CancelDeletion is not stubbed")
+	}
+	return mock.CancelDeletionFunc(ctx, tenantID)
+}
+
+// ConfirmDeletion calls ConfirmDeletionFunc
+func (mock *OffboardingManager) ConfirmDeletion(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.TenantDeletion, error) {
+	if mock.ConfirmDeletionFunc == nil {
+		panic("OffboardingManager.ConfirmDeletion is not stubbed")
+	}
+	return mock.ConfirmDeletionFunc(ctx, tenantID, token)
+}
+
+// GetDeletion calls GetDeletionFunc
+func (mock *OffboardingManager) GetDeletion(ctx context.Context, tenantID uuid.UUID) (*models.TenantDeletion, error) {
+	if mock.GetDeletionFunc == nil {
+		panic("OffboardingManager.GetDeletion is not stubbed")
+	}
+	return mock.GetDeletionFunc(ctx, tenantID)
+}
+
+// GetExport calls GetExportFunc
+func (mock *OffboardingManager) GetExport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.GetExportFunc == nil {
+		panic("OffboardingManager.GetExport is not stubbed")
+	}
+	return mock.GetExportFunc(ctx, tenantID, userID, jobID)
+}
+
+// PurgeDue calls PurgeDueFunc
+func (mock *OffboardingManager) PurgeDue(ctx context.Context) (int, error) {
+	if mock.PurgeDueFunc == nil {
+		panic("OffboardingManager.PurgeDue is not stubbed")
+	}
+	return mock.PurgeDueFunc(ctx)
+}
+
+// RequestDeletion calls RequestDeletionFunc
+func (mock *OffboardingManager) RequestDeletion(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, password string) (*models.TenantDeletion, error) {
+	if mock.RequestDeletionFunc == nil {
+		panic("OffboardingManager.RequestDeletion is not stubbed")
+	}
+	return mock.RequestDeletionFunc(ctx, tenantID, userID, password)
+}
+
+// RequestExport calls RequestExportFunc
+func (mock *OffboardingManager) RequestExport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.RequestExportFunc == nil {
+		panic("OffboardingManager.RequestExport is not stubbed")
+	}
+	return mock.RequestExportFunc(ctx, tenantID, userID)
+}
+
+// RunExportJob calls RunExportJobFunc
+func (mock *OffboardingManager) RunExportJob(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error) {
+	if mock.RunExportJobFunc == nil {
+		panic("OffboardingManager.RunExportJob is not stubbed")
+	}
+	return mock.RunExportJobFunc(ctx, job, progress)
+}
+
+// PerformanceManager is a mock of services.PerformanceManager
+type PerformanceManager struct {
+	CleanupFunc func(ctx context.Context) (int, error)
+	FlushFunc   func(ctx context.Context) (int, error)
+	ReportFunc  func(ctx context.Context, window time.Duration, sort string, limit int) (*models.PerformanceReport, error)
+	StartFunc   func(ctx context.Context)
+	StopFunc    func()
+}
+
+// Cleanup calls CleanupFunc
+func (mock *PerformanceManager) Cleanup(ctx context.Context) (int, error) {
+	if mock.CleanupFunc == nil {
+		panic("PerformanceManager.Cleanup is not stubbed")
+	}
+	return mock.CleanupFunc(ctx)
+}
+
+// Flush calls FlushFunc
+func (mock *PerformanceManager) Flush(ctx context.Context) (int, error) {
+	if mock.FlushFunc == nil {
+		panic("PerformanceManager.Flush is not stubbed")
+	}
+	return mock.FlushFunc(ctx)
+}
+
+// Report calls ReportFunc
+func (mock *PerformanceManager) Report(ctx context.Context, window time.Duration, sort string, limit int) (*models.PerformanceReport, error) {
+	if mock.ReportFunc == nil {
+		panic("PerformanceManager.Report is not stubbed")
+	}
+	return mock.ReportFunc(ctx, window, sort, limit)
+}
+
+// Start calls StartFunc
+func (mock *PerformanceManager) Start(ctx context.Context) {
+	if mock.StartFunc == nil {
+		panic("PerformanceManager.Start is not stubbed")
+	}
+	mock.StartFunc(ctx)
+}
+
+// Stop calls StopFunc
+func (mock *PerformanceManager) Stop() {
+	if mock.StopFunc == nil {
+		panic("PerformanceManager.Stop is not stubbed")
+	}
+	mock.StopFunc()
+}
+
+// PermissionManager is a mock of services.PermissionManager
+type PermissionManager struct {
+	CanAccessObjectFunc             func(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, action string) (bool, error)
+	CheckPermissionsFunc            func(ctx context.Context, tenantID, userID uuid.UUID, checks []services.PermissionCheck) ([]bool, error)
+	CheckUserRoleFunc               func(ctx context.Context, tenantID, userID uuid.UUID, roleName string) (bool, error)
+	ComparePermissionsFunc          func(ctx context.Context, tenantID, user1ID, user2ID uuid.UUID) (map[string]interface{}, error)
+	ExpireRoleAssignmentsFunc       func(ctx context.Context) (int, error)
+	GetEffectiveRolePermissionsFunc func(ctx context.Context, tenantID, roleID uuid.UUID) (*models.RoleEffectivePermissions, error)
+	GetObjectACLFunc                func(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error)
+	GetPermissionStatsFunc          func(ctx context.Context) (map[string]interface{}, error)
+	GetPermissionsVersionFunc       func(ctx context.Context, tenantID, userID uuid.UUID) (string, error)
+	GetRegistryFunc                 func(ctx context.Context) ([]models.PermissionModule, error)
+	GetRolePermissionsFunc          func(ctx context.Context, tenantID, roleID uuid.UUID) ([]models.Permission, error)
+	GetUserPermissionsFunc          func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Permission, error)
+	GetUserRoleNamesFunc            func(ctx context.Context, tenantID, userID uuid.UUID) ([]string, error)
+	GetUserRolesFunc                func(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error)
+	GrantTemporaryRoleFunc          func(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) (*models.Role, error)
+	HasAllPermissionsFunc           func(ctx context.Context, tenantID, userID uuid.UUID, checks []services.PermissionCheck) (bool, error)
+	HasAnyPermissionFunc            func(ctx context.Context, tenantID, userID uuid.UUID, checks []services.PermissionCheck) (bool, error)
+	HasPermissionFunc               func(ctx context.Context, tenantID, userID uuid.UUID, resource, action string) (bool, error)
+	InaccessibleObjectsFunc         func(ctx context.Context, tenantID, userID uuid.UUID, resourceType, action string) (map[uuid.UUID]bool, error)
+	InvalidateRolePermissionsFunc   func(ctx context.Context, tenantID, roleID uuid.UUID) error
+	InvalidateTenantPermissionsFunc func(ctx context.Context, tenantID uuid.UUID) error
+	InvalidateUserPermissionsFunc   func(ctx context.Context, tenantID, userID uuid.UUID) error
+	IsAdminFunc                     func(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	IsOwnerFunc                     func(ctx context.Context, tenantID, userID uuid.UUID) (bool, error)
+	ListAllPermissionsFunc          func(ctx context.Context) ([]models.Permission, error)
+	ListPermissionsByCategoryFunc   func(ctx context.Context) ([]models.PermissionGroup, error)
+	RevokeTemporaryRoleFunc         func(ctx context.Context, tenantID, userID, roleID uuid.UUID) error
+	SearchPermissionsFunc           func(ctx context.Context, searchTerm string) ([]models.Permission, error)
+	SetObjectACLFunc                func(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, req *models.ObjectACLUpdateRequest) (*models.ObjectACL, error)
+	ValidateParentRoleFunc          func(ctx context.Context, tenantID, roleID, parentID uuid.UUID) (*models.Role, error)
+	ValidatePermissionIDsFunc       func(ctx context.Context, permissionIDs []uuid.UUID) (bool, []uuid.UUID, error)
+}
+
+// CanAccessObject calls CanAccessObjectFunc
+func (mock *PermissionManager) CanAccessObject(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, action string) (bool, error) {
+	if mock.CanAccessObjectFunc == nil {
+		panic("PermissionManager.CanAccessObject is not stubbed")
+	}
+	return mock.CanAccessObjectFunc(ctx, tenantID, userID, resourceType, resourceID, action)
+}
+
+// CheckPermissions calls CheckPermissionsFunc
+func (mock *PermissionManager) CheckPermissions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, checks []services.PermissionCheck) ([]bool, error) {
+	if mock.CheckPermissionsFunc == nil {
+		panic("PermissionManager.CheckPermissions is not stubbed")
+	}
+	return mock.CheckPermissionsFunc(ctx, tenantID, userID, checks)
+}
+
+// CheckUserRole calls CheckUserRoleFunc
+func (mock *PermissionManager) CheckUserRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleName string) (bool, error) {
+	if mock.CheckUserRoleFunc == nil {
+		panic("PermissionManager.CheckUserRole is not stubbed")
+	}
+	return mock.CheckUserRoleFunc(ctx, tenantID, userID, roleName)
+}
+
+// ComparePermissions calls ComparePermissionsFunc
+func (mock *PermissionManager) ComparePermissions(ctx context.Context, tenantID uuid.UUID, user1ID uuid.UUID, user2ID uuid.UUID) (map[string]interface{}, error) {
+	if mock.ComparePermissionsFunc == nil {
+		panic("PermissionManager.ComparePermissions is not stubbed")
+	}
+	return mock.ComparePermissionsFunc(ctx, tenantID, user1ID, user2ID)
+}
+
+// ExpireRoleAssignments calls ExpireRoleAssignmentsFunc
+func (mock *PermissionManager) ExpireRoleAssignments(ctx context.Context) (int, error) {
+	if mock.ExpireRoleAssignmentsFunc == nil {
+		panic("PermissionManager.ExpireRoleAssignments is not stubbed")
+	}
+	return mock.ExpireRoleAssignmentsFunc(ctx)
+}
+
+// GetEffectiveRolePermissions calls GetEffectiveRolePermissionsFunc
+func (mock *PermissionManager) GetEffectiveRolePermissions(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) (*models.RoleEffectivePermissions, error) {
+	if mock.GetEffectiveRolePermissionsFunc == nil {
+		panic("PermissionManager.GetEffectiveRolePermissions is not stubbed")
+	}
+	return mock.GetEffectiveRolePermissionsFunc(ctx, tenantID, roleID)
+}
+
+// GetObjectACL calls GetObjectACLFunc
+func (mock *PermissionManager) GetObjectACL(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error) {
+	if mock.GetObjectACLFunc == nil {
+		panic("PermissionManager.GetObjectACL is not stubbed")
+	}
+	return mock.GetObjectACLFunc(ctx, tenantID, resourceType, resourceID)
+}
+
+// GetPermissionStats calls GetPermissionStatsFunc
+func (mock *PermissionManager) GetPermissionStats(ctx context.Context) (map[string]interface{}, error) {
+	if mock.GetPermissionStatsFunc == nil {
+		panic("PermissionManager.GetPermissionStats is not stubbed")
+	}
+	return mock.GetPermissionStatsFunc(ctx)
+}
+
+// GetPermissionsVersion calls GetPermissionsVersionFunc
+func (mock *PermissionManager) GetPermissionsVersion(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (string, error) {
+	if mock.GetPermissionsVersionFunc == nil {
+		panic("PermissionManager.GetPermissionsVersion is not stubbed")
+	}
+	return mock.GetPermissionsVersionFunc(ctx, tenantID, userID)
+}
+
+// GetRegistry calls GetRegistryFunc
+func (mock *PermissionManager) GetRegistry(ctx context.Context) ([]models.PermissionModule, error) {
+	if mock.GetRegistryFunc == nil {
+		panic("PermissionManager.GetRegistry is not stubbed")
+	}
+	return mock.GetRegistryFunc(ctx)
+}
+
+// GetRolePermissions calls GetRolePermissionsFunc
+func (mock *PermissionManager) GetRolePermissions(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) ([]models.Permission, error) {
+	if mock.GetRolePermissionsFunc == nil {
+		panic("PermissionManager.GetRolePermissions is not stubbed")
+	}
+	return mock.GetRolePermissionsFunc(ctx, tenantID, roleID)
+}
+
+// GetUserPermissions calls GetUserPermissionsFunc
+func (mock *PermissionManager) GetUserPermissions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Permission, error) {
+	if mock.GetUserPermissionsFunc == nil {
+		panic("PermissionManager.GetUserPermissions is not stubbed")
+	}
+	return mock.GetUserPermissionsFunc(ctx, tenantID, userID)
+}
+
+// GetUserRoleNames calls GetUserRoleNamesFunc
+func (mock *PermissionManager) GetUserRoleNames(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]string, error) {
+	if mock.GetUserRoleNamesFunc == nil {
+		panic("PermissionManager.GetUserRoleNames is not stubbed")
+	}
+	return mock.GetUserRoleNamesFunc(ctx, tenantID, userID)
+}
+
+// GetUserRoles calls GetUserRolesFunc
+func (mock *PermissionManager) GetUserRoles(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]models.Role, error) {
+	if mock.GetUserRolesFunc == nil {
+		panic("PermissionManager.GetUserRoles is not stubbed")
+	}
+	return mock.GetUserRolesFunc(ctx, tenantID, userID)
+}
+
+// GrantTemporaryRole calls GrantTemporaryRoleFunc
+func (mock *PermissionManager) GrantTemporaryRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) (*models.Role, error) {
+	if mock.GrantTemporaryRoleFunc == nil {
+		panic("PermissionManager.GrantTemporaryRole is not stubbed")
+	}
+	return mock.GrantTemporaryRoleFunc(ctx, tenantID, userID, assignedBy, req)
+}
+
+// HasAllPermissions calls HasAllPermissionsFunc
+func (mock *PermissionManager) HasAllPermissions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, checks []services.PermissionCheck) (bool, error) {
+	if mock.HasAllPermissionsFunc == nil {
+		panic("PermissionManager.HasAllPermissions is not stubbed")
+	}
+	return mock.HasAllPermissionsFunc(ctx, tenantID, userID, checks)
+}
+
+// HasAnyPermission calls HasAnyPermissionFunc
+func (mock *PermissionManager) HasAnyPermission(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, checks []services.PermissionCheck) (bool, error) {
+	if mock.HasAnyPermissionFunc == nil {
+		panic("PermissionManager.HasAnyPermission is not stubbed")
+	}
+	return mock.HasAnyPermissionFunc(ctx, tenantID, userID, checks)
+}
+
+// HasPermission calls HasPermissionFunc
+func (mock *PermissionManager) HasPermission(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, resource string, action string) (bool, error) {
+	if mock.HasPermissionFunc == nil {
+		panic("PermissionManager.HasPermission is not stubbed")
+	}
+	return mock.HasPermissionFunc(ctx, tenantID, userID, resource, action)
+}
+
+// InaccessibleObjects calls InaccessibleObjectsFunc
+func (mock *PermissionManager) InaccessibleObjects(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, resourceType string, action string) (map[uuid.UUID]bool, error) {
+	if mock.InaccessibleObjectsFunc == nil {
+		panic("PermissionManager.InaccessibleObjects is not stubbed")
+	}
+	return mock.InaccessibleObjectsFunc(ctx, tenantID, userID, resourceType, action)
+}
+
+// InvalidateRolePermissions calls InvalidateRolePermissionsFunc
+func (mock *PermissionManager) InvalidateRolePermissions(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID) error {
+	if mock.InvalidateRolePermissionsFunc == nil {
+		panic("PermissionManager.InvalidateRolePermissions is not stubbed")
+	}
+	return mock.InvalidateRolePermissionsFunc(ctx, tenantID, roleID)
+}
+
+// InvalidateTenantPermissions calls InvalidateTenantPermissionsFunc
+func (mock *PermissionManager) InvalidateTenantPermissions(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.InvalidateTenantPermissionsFunc == nil {
+		panic("PermissionManager.InvalidateTenantPermissions is not stubbed")
+	}
+	return mock.InvalidateTenantPermissionsFunc(ctx, tenantID)
+}
+
+// InvalidateUserPermissions calls InvalidateUserPermissionsFunc
+func (mock *PermissionManager) InvalidateUserPermissions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.InvalidateUserPermissionsFunc == nil {
+		panic("PermissionManager.InvalidateUserPermissions is not stubbed")
+	}
+	return mock.InvalidateUserPermissionsFunc(ctx, tenantID, userID)
+}
+
+// IsAdmin calls IsAdminFunc
+func (mock *PermissionManager) IsAdmin(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (bool, error) {
+	if mock.IsAdminFunc == nil {
+		panic("PermissionManager.IsAdmin is not stubbed")
+	}
+	return mock.IsAdminFunc(ctx, tenantID, userID)
+}
+
+// IsOwner calls IsOwnerFunc
+func (mock *PermissionManager) IsOwner(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (bool, error) {
+	if mock.IsOwnerFunc == nil {
+		panic("PermissionManager.IsOwner is not stubbed")
+	}
+	return mock.IsOwnerFunc(ctx, tenantID, userID)
+}
+
+// ListAllPermissions calls ListAllPermissionsFunc
+func (mock *PermissionManager) ListAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	if mock.ListAllPermissionsFunc == nil {
+		panic("PermissionManager.ListAllPermissions is not stubbed")
+	}
+	return mock.ListAllPermissionsFunc(ctx)
+}
+
+// ListPermissionsByCategory calls ListPermissionsByCategoryFunc
+func (mock *PermissionManager) ListPermissionsByCategory(ctx context.Context) ([]models.PermissionGroup, error) {
+	if mock.ListPermissionsByCategoryFunc == nil {
+		panic("PermissionManager.ListPermissionsByCategory is not stubbed")
+	}
+	return mock.ListPermissionsByCategoryFunc(ctx)
+}
+
+// RevokeTemporaryRole calls RevokeTemporaryRoleFunc
+func (mock *PermissionManager) RevokeTemporaryRole(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, roleID uuid.UUID) error {
+	if mock.RevokeTemporaryRoleFunc == nil {
+		panic("PermissionManager.RevokeTemporaryRole is not stubbed")
+	}
+	return mock.RevokeTemporaryRoleFunc(ctx, tenantID, userID, roleID)
+}
+
+// SearchPermissions calls SearchPermissionsFunc
+func (mock *PermissionManager) SearchPermissions(ctx context.Context, searchTerm string) ([]models.Permission, error) {
+	if mock.SearchPermissionsFunc == nil {
+		panic("PermissionManager.SearchPermissions is not stubbed")
+	}
+	return mock.SearchPermissionsFunc(ctx, searchTerm)
+}
+
+// SetObjectACL calls SetObjectACLFunc
+func (mock *PermissionManager) SetObjectACL(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, req *models.ObjectACLUpdateRequest) (*models.ObjectACL, error) {
+	if mock.SetObjectACLFunc == nil {
+		panic("PermissionManager.SetObjectACL is not stubbed")
+	}
+	return mock.SetObjectACLFunc(ctx, tenantID, userID, resourceType, resourceID, req)
+}
+
+// ValidateParentRole calls ValidateParentRoleFunc
+func (mock *PermissionManager) ValidateParentRole(ctx context.Context, tenantID uuid.UUID, roleID uuid.UUID, parentID uuid.UUID) (*models.Role, error) {
+	if mock.ValidateParentRoleFunc == nil {
+		panic("PermissionManager.ValidateParentRole is not stubbed")
+	}
+	return mock.ValidateParentRoleFunc(ctx, tenantID, roleID, parentID)
+}
+
+// ValidatePermissionIDs calls ValidatePermissionIDsFunc
+func (mock *PermissionManager) ValidatePermissionIDs(ctx context.Context, permissionIDs []uuid.UUID) (bool, []uuid.UUID, error) {
+	if mock.ValidatePermissionIDsFunc == nil {
+		panic("PermissionManager.ValidatePermissionIDs is not stubbed")
+	}
+	return mock.ValidatePermissionIDsFunc(ctx, permissionIDs)
+}
+
+// PersonalDataManager is a mock of services.PersonalDataManager
+type PersonalDataManager struct {
+	ExportFunc         func(ctx context.Context, tenantID, userID uuid.UUID) (*models.PersonalDataExport, error)
+	RequestErasureFunc func(ctx context.Context, tenantID, userID uuid.UUID, password string) error
+}
+
+// Export calls ExportFunc
+func (mock *PersonalDataManager) Export(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (*models.PersonalDataExport, error) {
+	if mock.ExportFunc == nil {
+		panic("PersonalDataManager.Export is not stubbed")
+	}
+	return mock.ExportFunc(ctx, tenantID, userID)
+}
+
+// RequestErasure calls RequestErasureFunc
+func (mock *PersonalDataManager) RequestErasure(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, password string) error {
+	if mock.RequestErasureFunc == nil {
+		panic("PersonalDataManager.RequestErasure is not stubbed")
+	}
+	return mock.RequestErasureFunc(ctx, tenantID, userID, password)
+}
+
+// PlatformManager is a mock of services.PlatformManager
+type PlatformManager struct {
+	ActivateTenantFunc     func(ctx context.Context, admin *models.User, tenantID uuid.UUID) (*models.Tenant, error)
+	ForcePasswordResetFunc func(ctx context.Context, admin *models.User, tenantID uuid.UUID, email string) error
+	GetStatsFunc           func(ctx context.Context) (*models.PlatformStats, error)
+	GetTenantHealthFunc    func(ctx context.Context, tenantID uuid.UUID) (*models.PlatformTenantHealth, error)
+	IsAdminFunc            func(ctx context.Context, user *models.User) (bool, error)
+	ListTenantsFunc        func(ctx context.Context, filter models.PlatformTenantFilter, page, pageSize int) ([]models.Tenant, int, error)
+	ResendVerificationFunc func(ctx context.Context, admin *models.User, tenantID uuid.UUID) error
+	SuspendTenantFunc      func(ctx context.Context, admin *models.User, tenantID uuid.UUID, reason string) (*models.Tenant, error)
+}
+
+// ActivateTenant calls ActivateTenantFunc
+func (mock *PlatformManager) ActivateTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID) (*models.Tenant, error) {
+	if mock.ActivateTenantFunc == nil {
+		panic("PlatformManager.ActivateTenant is not stubbed")
+	}
+	return mock.ActivateTenantFunc(ctx, admin, tenantID)
+}
+
+// ForcePasswordReset calls ForcePasswordResetFunc
+func (mock *PlatformManager) ForcePasswordReset(ctx context.Context, admin *models.User, tenantID uuid.UUID, email string) error {
+	if mock.ForcePasswordResetFunc == nil {
+		panic("PlatformManager.ForcePasswordReset is not stubbed")
+	}
+	return mock.ForcePasswordResetFunc(ctx, admin, tenantID, email)
+}
+
+// GetStats calls GetStatsFunc
+func (mock *PlatformManager) GetStats(ctx context.Context) (*models.PlatformStats, error) {
+	if mock.GetStatsFunc == nil {
+		panic("PlatformManager.GetStats is not stubbed")
+	}
+	return mock.GetStatsFunc(ctx)
+}
+
+// GetTenantHealth calls GetTenantHealthFunc
+func (mock *PlatformManager) GetTenantHealth(ctx context.Context, tenantID uuid.UUID) (*models.PlatformTenantHealth, error) {
+	if mock.GetTenantHealthFunc == nil {
+		panic("PlatformManager.GetTenantHealth is not stubbed")
+	}
+	return mock.GetTenantHealthFunc(ctx, tenantID)
+}
+
+// IsAdmin calls IsAdminFunc
+func (mock *PlatformManager) IsAdmin(ctx context.Context, user *models.User) (bool, error) {
+	if mock.IsAdminFunc == nil {
+		panic("PlatformManager.IsAdmin is not stubbed")
+	}
+	return mock.IsAdminFunc(ctx, user)
+}
+
+// ListTenants calls ListTenantsFunc
+func (mock *PlatformManager) ListTenants(ctx context.Context, filter models.PlatformTenantFilter, page int, pageSize int) ([]models.Tenant, int, error) {
+	if mock.ListTenantsFunc == nil {
+		panic("PlatformManager.ListTenants is not stubbed")
+	}
+	return mock.ListTenantsFunc(ctx, filter, page, pageSize)
+}
+
+// ResendVerification calls ResendVerificationFunc
+func (mock *PlatformManager) ResendVerification(ctx context.Context, admin *models.User, tenantID uuid.UUID) error {
+	if mock.ResendVerificationFunc == nil {
+		panic("PlatformManager.ResendVerification is not stubbed")
+	}
+	return mock.ResendVerificationFunc(ctx, admin, tenantID)
+}
+
+// SuspendTenant calls SuspendTenantFunc
+func (mock *PlatformManager) SuspendTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID, reason string) (*models.Tenant, error) {
+	if mock.SuspendTenantFunc == nil {
+		panic("PlatformManager.SuspendTenant is not stubbed")
+	}
+	return mock.SuspendTenantFunc(ctx, admin, tenantID, reason)
+}
+
+// PurchaseOrderManager is a mock of services.PurchaseOrderManager
+type PurchaseOrderManager struct {
+	ApproveSupplierInvoiceFunc   func(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, force bool) (*models.SupplierInvoice, error)
+	CancelOrderFunc              func(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	CloseOrderFunc               func(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	CreateOrderFunc              func(ctx context.Context, tenantID, userID uuid.UUID, req *models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error)
+	CreateSupplierFunc           func(ctx context.Context, tenantID, userID uuid.UUID, req *models.SupplierCreateRequest) (*models.Supplier, []models.SupplierDuplicate, error)
+	DecideInvoiceApprovalFunc    func(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, decision string) error
+	DeleteOrderFunc              func(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PendingDeletion, error)
+	DeleteSupplierFunc           func(ctx context.Context, tenantID, userID, supplierID uuid.UUID) error
+	DescribeInvoiceFunc          func(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.EntitySummary, error)
+	DescribeInvoiceApprovalFunc  func(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.ApprovalSubject, error)
+	DescribeOrderFunc            func(ctx context.Context, tenantID, poID uuid.UUID) (*models.EntitySummary, error)
+	DescribeSupplierFunc         func(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.EntitySummary, error)
+	FindDuplicatesOfSupplierFunc func(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierDuplicate, error)
+	FindSupplierDuplicatesFunc   func(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery) ([]models.SupplierDuplicate, error)
+	GetOrderFunc                 func(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	GetSupplierFunc              func(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error)
+	GetSupplierInvoiceFunc       func(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	ListOrdersFunc               func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		supplierID *uuid.UUID,
+		status string,
+		limit, offset int,
+	) ([]models.PurchaseOrder, int, error)
+	ListPendingInvoiceApprovalsFunc func(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.EscalationItem, error)
+	ListReceiptsFunc                func(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error)
+	ListSupplierInvoicesFunc        func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		poID *uuid.UUID,
+		matchStatus, status string,
+		limit, offset int,
+	) ([]models.SupplierInvoice, int, error)
+	ListSupplierMergesFunc func(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error)
+	ListSuppliersFunc      func(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error)
+	MergeSuppliersFunc     func(ctx context.Context, tenantID, userID, targetID, sourceID uuid.UUID) (*models.Supplier, *models.SupplierMerge, error)
+	PurgeOrderFunc         func(ctx context.Context, tenantID, userID, poID uuid.UUID) error
+	ReceiveGoodsFunc       func(
+		ctx context.Context,
+		tenantID, userID, poID uuid.UUID,
+		req *models.GoodsReceiptCreateRequest,
+	) (*models.GoodsReceipt, error)
+	RecordSupplierInvoiceFunc func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		req *models.SupplierInvoiceCreateRequest,
+	) (*models.SupplierInvoice, error)
+	RejectSupplierInvoiceFunc func(ctx context.Context, tenantID, userID, invoiceID uuid.UUID) (*models.SupplierInvoice, error)
+	SendOrderFunc             func(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error)
+	UpdateOrderFunc           func(
+		ctx context.Context,
+		tenantID, userID, poID uuid.UUID,
+		req *models.PurchaseOrderUpdateRequest,
+	) (*models.PurchaseOrder, error)
+	UpdateSupplierFunc func(
+		ctx context.Context,
+		tenantID, userID, supplierID uuid.UUID,
+		req *models.SupplierUpdateRequest,
+	) (*models.Supplier, error)
+}
+
+// ApproveSupplierInvoice calls ApproveSupplierInvoiceFunc
+func (mock *PurchaseOrderManager) ApproveSupplierInvoice(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, invoiceID uuid.UUID, force bool) (*models.SupplierInvoice, error) {
+	if mock.ApproveSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderManager.ApproveSupplierInvoice is not stubbed")
+	}
+	return mock.ApproveSupplierInvoiceFunc(ctx, tenantID, userID, invoiceID, force)
+}
+
+// CancelOrder calls CancelOrderFunc
+func (mock *PurchaseOrderManager) CancelOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	if mock.CancelOrderFunc == nil {
+		panic("PurchaseOrderManager.CancelOrder is not stubbed")
+	}
+	return mock.CancelOrderFunc(ctx, tenantID, userID, poID)
+}
+
+// CloseOrder calls CloseOrderFunc
+func (mock *PurchaseOrderManager) CloseOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	if mock.CloseOrderFunc == nil {
+		panic("PurchaseOrderManager.CloseOrder is not stubbed")
+	}
+	return mock.CloseOrderFunc(ctx, tenantID, userID, poID)
+}
+
+// CreateOrder calls CreateOrderFunc
+func (mock *PurchaseOrderManager) CreateOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error) {
+	if mock.CreateOrderFunc == nil {
+		panic("PurchaseOrderManager.CreateOrder is not stubbed")
+	}
+	return mock.CreateOrderFunc(ctx, tenantID, userID, req)
+}
+
+// CreateSupplier calls CreateSupplierFunc
+func (mock *PurchaseOrderManager) CreateSupplier(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.SupplierCreateRequest) (*models.Supplier, []models.SupplierDuplicate, error) {
+	if mock.CreateSupplierFunc == nil {
+		panic("PurchaseOrderManager.CreateSupplier is not stubbed")
+	}
+	return mock.CreateSupplierFunc(ctx, tenantID, userID, req)
+}
+
+// DecideInvoiceApproval calls DecideInvoiceApprovalFunc
+func (mock *PurchaseOrderManager) DecideInvoiceApproval(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, invoiceID uuid.UUID, decision string) error {
+	if mock.DecideInvoiceApprovalFunc == nil {
+		panic("PurchaseOrderManager.DecideInvoiceApproval is not stubbed")
+	}
+	return mock.DecideInvoiceApprovalFunc(ctx, tenantID, userID, invoiceID, decision)
+}
+
+// DeleteOrder calls DeleteOrderFunc
+func (mock *PurchaseOrderManager) DeleteOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.DeleteOrderFunc == nil {
+		panic("PurchaseOrderManager.DeleteOrder is not stubbed")
+	}
+	return mock.DeleteOrderFunc(ctx, tenantID, userID, poID)
+}
+
+// DeleteSupplier calls DeleteSupplierFunc
+func (mock *PurchaseOrderManager) DeleteSupplier(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, supplierID uuid.UUID) error {
+	if mock.DeleteSupplierFunc == nil {
+		panic("PurchaseOrderManager.DeleteSupplier is not stubbed")
+	}
+	return mock.DeleteSupplierFunc(ctx, tenantID, userID, supplierID)
+}
+
+// DescribeInvoice calls DescribeInvoiceFunc
+func (mock *PurchaseOrderManager) DescribeInvoice(ctx context.Context, tenantID uuid.UUID, invoiceID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeInvoiceFunc == nil {
+		panic("PurchaseOrderManager.DescribeInvoice is not stubbed")
+	}
+	return mock.DescribeInvoiceFunc(ctx, tenantID, invoiceID)
+}
+
+// DescribeInvoiceApproval calls DescribeInvoiceApprovalFunc
+func (mock *PurchaseOrderManager) DescribeInvoiceApproval(ctx context.Context, tenantID uuid.UUID, invoiceID uuid.UUID) (*models.ApprovalSubject, error) {
+	if mock.DescribeInvoiceApprovalFunc == nil {
+		panic("PurchaseOrderManager.DescribeInvoiceApproval is not stubbed")
+	}
+	return mock.DescribeInvoiceApprovalFunc(ctx, tenantID, invoiceID)
+}
+
+// DescribeOrder calls DescribeOrderFunc
+func (mock *PurchaseOrderManager) DescribeOrder(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeOrderFunc == nil {
+		panic("PurchaseOrderManager.DescribeOrder is not stubbed")
+	}
+	return mock.DescribeOrderFunc(ctx, tenantID, poID)
+}
+
+// DescribeSupplier calls DescribeSupplierFunc
+func (mock *PurchaseOrderManager) DescribeSupplier(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeSupplierFunc == nil {
+		panic("PurchaseOrderManager.DescribeSupplier is not stubbed")
+	}
+	return mock.DescribeSupplierFunc(ctx, tenantID, supplierID)
+}
+
+// FindDuplicatesOfSupplier calls FindDuplicatesOfSupplierFunc
+func (mock *PurchaseOrderManager) FindDuplicatesOfSupplier(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) ([]models.SupplierDuplicate, error) {
+	if mock.FindDuplicatesOfSupplierFunc == nil {
+		panic("PurchaseOrderManager.FindDuplicatesOfSupplier is not stubbed")
+	}
+	return mock.FindDuplicatesOfSupplierFunc(ctx, tenantID, supplierID)
+}
+
+// FindSupplierDuplicates calls FindSupplierDuplicatesFunc
+func (mock *PurchaseOrderManager) FindSupplierDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery) ([]models.SupplierDuplicate, error) {
+	if mock.FindSupplierDuplicatesFunc == nil {
+		panic("PurchaseOrderManager.FindSupplierDuplicates is not stubbed")
+	}
+	return mock.FindSupplierDuplicatesFunc(ctx, tenantID, query)
+}
+
+// GetOrder calls GetOrderFunc
+func (mock *PurchaseOrderManager) GetOrder(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	if mock.GetOrderFunc == nil {
+		panic("PurchaseOrderManager.GetOrder is not stubbed")
+	}
+	return mock.GetOrderFunc(ctx, tenantID, poID)
+}
+
+// GetSupplier calls GetSupplierFunc
+func (mock *PurchaseOrderManager) GetSupplier(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) (*models.Supplier, error) {
+	if mock.GetSupplierFunc == nil {
+		panic("PurchaseOrderManager.GetSupplier is not stubbed")
+	}
+	return mock.GetSupplierFunc(ctx, tenantID, supplierID)
+}
+
+// GetSupplierInvoice calls GetSupplierInvoiceFunc
+func (mock *PurchaseOrderManager) GetSupplierInvoice(ctx context.Context, tenantID uuid.UUID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	if mock.GetSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderManager.GetSupplierInvoice is not stubbed")
+	}
+	return mock.GetSupplierInvoiceFunc(ctx, tenantID, invoiceID)
+}
+
+// ListOrders calls ListOrdersFunc
+func (mock *PurchaseOrderManager) ListOrders(ctx context.Context, tenantID uuid.UUID, supplierID *uuid.UUID, status string, limit int, offset int) ([]models.PurchaseOrder, int, error) {
+	if mock.ListOrdersFunc == nil {
+		panic("PurchaseOrderManager.ListOrders is not stubbed")
+	}
+	return mock.ListOrdersFunc(ctx, tenantID, supplierID, status, limit, offset)
+}
+
+// ListPendingInvoiceApprovals calls ListPendingInvoiceApprovalsFunc
+func (mock *PurchaseOrderManager) ListPendingInvoiceApprovals(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.EscalationItem, error) {
+	if mock.ListPendingInvoiceApprovalsFunc == nil {
+		panic("PurchaseOrderManager.ListPendingInvoiceApprovals is not stubbed")
+	}
+	return mock.ListPendingInvoiceApprovalsFunc(ctx, tenantID, cutoff)
+}
+
+// ListReceipts calls ListReceiptsFunc
+func (mock *PurchaseOrderManager) ListReceipts(ctx context.Context, tenantID uuid.UUID, poID uuid.UUID) ([]models.GoodsReceipt, error) {
+	if mock.ListReceiptsFunc == nil {
+		panic("PurchaseOrderManager.ListReceipts is not stubbed")
+	}
+	return mock.ListReceiptsFunc(ctx, tenantID, poID)
+}
+
+// ListSupplierInvoices calls ListSupplierInvoicesFunc
+func (mock *PurchaseOrderManager) ListSupplierInvoices(ctx context.Context, tenantID uuid.UUID, poID *uuid.UUID, matchStatus string, status string, limit int, offset int) ([]models.SupplierInvoice, int, error) {
+	if mock.ListSupplierInvoicesFunc == nil {
+		panic("PurchaseOrderManager.ListSupplierInvoices is not stubbed")
+	}
+	return mock.ListSupplierInvoicesFunc(ctx, tenantID, poID, matchStatus, status, limit, offset)
+}
+
+// ListSupplierMerges calls ListSupplierMergesFunc
+func (mock *PurchaseOrderManager) ListSupplierMerges(ctx context.Context, tenantID uuid.UUID, supplierID uuid.UUID) ([]models.SupplierMerge, error) {
+	if mock.ListSupplierMergesFunc == nil {
+		panic("PurchaseOrderManager.ListSupplierMerges is not stubbed")
+	}
+	return mock.ListSupplierMergesFunc(ctx, tenantID, supplierID)
+}
+
+// ListSuppliers calls ListSuppliersFunc
+func (mock *PurchaseOrderManager) ListSuppliers(ctx context.Context, tenantID uuid.UUID, status string, search string, limit int, offset int) ([]models.Supplier, int, error) {
+	if mock.ListSuppliersFunc == nil {
+		panic("PurchaseOrderManager.ListSuppliers is not stubbed")
+	}
+	return mock.ListSuppliersFunc(ctx, tenantID, status, search, limit, offset)
+}
+
+// MergeSuppliers calls MergeSuppliersFunc
+func (mock *PurchaseOrderManager) MergeSuppliers(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, targetID uuid.UUID, sourceID uuid.UUID) (*models.Supplier, *models.SupplierMerge, error) {
+	if mock.MergeSuppliersFunc == nil {
+		panic("PurchaseOrderManager.MergeSuppliers is not stubbed")
+	}
+	return mock.MergeSuppliersFunc(ctx, tenantID, userID, targetID, sourceID)
+}
+
+// PurgeOrder calls PurgeOrderFunc
+func (mock *PurchaseOrderManager) PurgeOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID) error {
+	if mock.PurgeOrderFunc == nil {
+		panic("PurchaseOrderManager.PurgeOrder is not stubbed")
+	}
+	return mock.PurgeOrderFunc(ctx, tenantID, userID, poID)
+}
+
+// ReceiveGoods calls ReceiveGoodsFunc
+func (mock *PurchaseOrderManager) ReceiveGoods(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID, req *models.GoodsReceiptCreateRequest) (*models.GoodsReceipt, error) {
+	if mock.ReceiveGoodsFunc == nil {
+		panic("PurchaseOrderManager.ReceiveGoods is not stubbed")
+	}
+	return mock.ReceiveGoodsFunc(ctx, tenantID, userID, poID, req)
+}
+
+// RecordSupplierInvoice calls RecordSupplierInvoiceFunc
+func (mock *PurchaseOrderManager) RecordSupplierInvoice(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.SupplierInvoiceCreateRequest) (*models.SupplierInvoice, error) {
+	if mock.RecordSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderManager.RecordSupplierInvoice is not stubbed")
+	}
+	return mock.RecordSupplierInvoiceFunc(ctx, tenantID, userID, req)
+}
+
+// RejectSupplierInvoice calls RejectSupplierInvoiceFunc
+func (mock *PurchaseOrderManager) RejectSupplierInvoice(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	if mock.RejectSupplierInvoiceFunc == nil {
+		panic("PurchaseOrderManager.RejectSupplierInvoice is not stubbed")
+	}
+	return mock.RejectSupplierInvoiceFunc(ctx, tenantID, userID, invoiceID)
+}
+
+// SendOrder calls SendOrderFunc
+func (mock *PurchaseOrderManager) SendOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	if mock.SendOrderFunc == nil {
+		panic("PurchaseOrderManager.SendOrder is not stubbed")
+	}
+	return mock.SendOrderFunc(ctx, tenantID, userID, poID)
+}
+
+// UpdateOrder calls UpdateOrderFunc
+func (mock *PurchaseOrderManager) UpdateOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, poID uuid.UUID, req *models.PurchaseOrderUpdateRequest) (*models.PurchaseOrder, error) {
+	if mock.UpdateOrderFunc == nil {
+		panic("PurchaseOrderManager.UpdateOrder is not stubbed")
+	}
+	return mock.UpdateOrderFunc(ctx, tenantID, userID, poID, req)
+}
+
+// UpdateSupplier calls UpdateSupplierFunc
+func (mock *PurchaseOrderManager) UpdateSupplier(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, supplierID uuid.UUID, req *models.SupplierUpdateRequest) (*models.Supplier, error) {
+	if mock.UpdateSupplierFunc == nil {
+		panic("PurchaseOrderManager.UpdateSupplier is not stubbed")
+	}
+	return mock.UpdateSupplierFunc(ctx, tenantID, userID, supplierID, req)
+}
+
+// QueueManager is a mock of services.QueueManager
+type QueueManager struct {
+	GaugesFunc  func(ctx context.Context) ([]metrics.QueueGauge, error)
+	MonitorFunc func(ctx context.Context) (int, error)
+	StatsFunc   func(ctx context.Context) ([]models.QueueStats, error)
+}
+
+// Gauges calls GaugesFunc
+func (mock *QueueManager) Gauges(ctx context.Context) ([]metrics.QueueGauge, error) {
+	if mock.GaugesFunc == nil {
+		panic("QueueManager.Gauges is not stubbed")
+	}
+	return mock.GaugesFunc(ctx)
+}
+
+// Monitor calls MonitorFunc
+func (mock *QueueManager) Monitor(ctx context.Context) (int, error) {
+	if mock.MonitorFunc == nil {
+		panic("QueueManager.Monitor is not stubbed")
+	}
+	return mock.MonitorFunc(ctx)
+}
+
+// Stats calls StatsFunc
+func (mock *QueueManager) Stats(ctx context.Context) ([]models.QueueStats, error) {
+	if mock.StatsFunc == nil {
+		panic("QueueManager.Stats is not stubbed")
+	}
+	return mock.StatsFunc(ctx)
+}
+
+// QuotaManager is a mock of services.QuotaManager
+type QuotaManager struct {
+	AcknowledgeAlertFunc func(ctx context.Context, tenantID, alertID, userID uuid.UUID) (*models.QuotaAlert, error)
+	CheckPlanFitsFunc    func(ctx context.Context, tenant *models.Tenant, tier string) error
+	CheckScheduledFunc   func(ctx context.Context) (int, error)
+	CheckUserQuotaFunc   func(ctx context.Context, tenantID uuid.UUID, adding int) error
+	GetStatusFunc        func(ctx context.Context, tenantID uuid.UUID) (*models.QuotaStatus, error)
+}
+
+// AcknowledgeAlert calls AcknowledgeAlertFunc
+func (mock *QuotaManager) AcknowledgeAlert(ctx context.Context, tenantID uuid.UUID, alertID uuid.UUID, userID uuid.UUID) (*models.QuotaAlert, error) {
+	if mock.AcknowledgeAlertFunc == nil {
+		panic("QuotaManager.AcknowledgeAlert is not stubbed")
+	}
+	return mock.AcknowledgeAlertFunc(ctx, tenantID, alertID, userID)
+}
+
+// CheckPlanFits calls CheckPlanFitsFunc
+func (mock *QuotaManager) CheckPlanFits(ctx context.Context, tenant *models.Tenant, tier string) error {
+	if mock.CheckPlanFitsFunc == nil {
+		panic("QuotaManager.CheckPlanFits is not stubbed")
+	}
+	return mock.CheckPlanFitsFunc(ctx, tenant, tier)
+}
+
+// CheckScheduled calls CheckScheduledFunc
+func (mock *QuotaManager) CheckScheduled(ctx context.Context) (int, error) {
+	if mock.CheckScheduledFunc == nil {
+		panic("QuotaManager.CheckScheduled is not stubbed")
+	}
+	return mock.CheckScheduledFunc(ctx)
+}
+
+// CheckUserQuota calls CheckUserQuotaFunc
+func (mock *QuotaManager) CheckUserQuota(ctx context.Context, tenantID uuid.UUID, adding int) error {
+	if mock.CheckUserQuotaFunc == nil {
+		panic("QuotaManager.CheckUserQuota is not stubbed")
+	}
+	return mock.CheckUserQuotaFunc(ctx, tenantID, adding)
+}
+
+// GetStatus calls GetStatusFunc
+func (mock *QuotaManager) GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.QuotaStatus, error) {
+	if mock.GetStatusFunc == nil {
+		panic("QuotaManager.GetStatus is not stubbed")
+	}
+	return mock.GetStatusFunc(ctx, tenantID)
+}
+
+// SIEMManager is a mock of services.SIEMManager
+type SIEMManager struct {
+	CreateForwarderFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error)
+	DeleteForwarderFunc func(ctx context.Context, tenantID, forwarderID uuid.UUID) error
+	ForwardDueFunc      func(ctx context.Context) (int, error)
+	GetForwarderFunc    func(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error)
+	ListForwardersFunc  func(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error)
+	TestForwarderFunc   func(ctx context.Context, tenantID, userID, forwarderID uuid.UUID) (*models.SIEMForwarderTest, error)
+	UpdateForwarderFunc func(ctx context.Context, tenantID, forwarderID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error)
+}
+
+// CreateForwarder calls CreateForwarderFunc
+func (mock *SIEMManager) CreateForwarder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error) {
+	if mock.CreateForwarderFunc == nil {
+		panic("SIEMManager.CreateForwarder is not stubbed")
+	}
+	return mock.CreateForwarderFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteForwarder calls DeleteForwarderFunc
+func (mock *SIEMManager) DeleteForwarder(ctx context.Context, tenantID uuid.UUID, forwarderID uuid.UUID) error {
+	if mock.DeleteForwarderFunc == nil {
+		panic("SIEMManager.DeleteForwarder is not stubbed")
+	}
+	return mock.DeleteForwarderFunc(ctx, tenantID, forwarderID)
+}
+
+// ForwardDue calls ForwardDueFunc
+func (mock *SIEMManager) ForwardDue(ctx context.Context) (int, error) {
+	if mock.ForwardDueFunc == nil {
+		panic("SIEMManager.ForwardDue is not stubbed")
+	}
+	return mock.ForwardDueFunc(ctx)
+}
+
+// GetForwarder calls GetForwarderFunc
+func (mock *SIEMManager) GetForwarder(ctx context.Context, tenantID uuid.UUID, forwarderID uuid.UUID) (*models.SIEMForwarder, error) {
+	if mock.GetForwarderFunc == nil {
+		panic("SIEMManager.GetForwarder is not stubbed")
+	}
+	return mock.GetForwarderFunc(ctx, tenantID, forwarderID)
+}
+
+// ListForwarders calls ListForwardersFunc
+func (mock *SIEMManager) ListForwarders(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error) {
+	if mock.ListForwardersFunc == nil {
+		panic("SIEMManager.ListForwarders is not stubbed")
+	}
+	return mock.ListForwardersFunc(ctx, tenantID)
+}
+
+// TestForwarder calls TestForwarderFunc
+func (mock *SIEMManager) TestForwarder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, forwarderID uuid.UUID) (*models.SIEMForwarderTest, error) {
+	if mock.TestForwarderFunc == nil {
+		panic("SIEMManager.TestForwarder is not stubbed")
+	}
+	return mock.TestForwarderFunc(ctx, tenantID, userID, forwarderID)
+}
+
+// UpdateForwarder calls UpdateForwarderFunc
+func (mock *SIEMManager) UpdateForwarder(ctx context.Context, tenantID uuid.UUID, forwarderID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error) {
+	if mock.UpdateForwarderFunc == nil {
+		panic("SIEMManager.UpdateForwarder is not stubbed")
+	}
+	return mock.UpdateForwarderFunc(ctx, tenantID, forwarderID, req)
+}
+
+// SalesManager is a mock of services.SalesManager
+type SalesManager struct {
+	ChangeStatusFunc func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		docID uuid.UUID,
+		status string,
+	) (*models.SalesDocument, error)
+	ConvertQuoteToOrderFunc func(ctx context.Context, tenantID, userID, quoteID uuid.UUID) (*models.SalesDocument, error)
+	CreateDocumentFunc      func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		req *models.SalesDocumentCreateRequest,
+	) (*models.SalesDocument, error)
+	DeleteDocumentFunc   func(ctx context.Context, tenantID, userID uuid.UUID, documentType string, docID uuid.UUID) (*models.PendingDeletion, error)
+	DescribeDocumentFunc func(ctx context.Context, tenantID, docID uuid.UUID) (*models.EntitySummary, error)
+	GetDocumentFunc      func(ctx context.Context, tenantID uuid.UUID, documentType string, docID uuid.UUID) (*models.SalesDocument, error)
+	InvoiceOrderFunc     func(ctx context.Context, tenantID, userID, orderID uuid.UUID) (*models.SalesDocument, error)
+	ListDocumentsFunc    func(
+		ctx context.Context,
+		tenantID uuid.UUID,
+		filter models.SalesDocumentFilter,
+		limit, offset int,
+	) ([]models.SalesDocument, int, error)
+	PurgeDocumentFunc  func(ctx context.Context, tenantID, userID, docID uuid.UUID) error
+	UpdateDocumentFunc func(
+		ctx context.Context,
+		tenantID, userID uuid.UUID,
+		documentType string,
+		docID uuid.UUID,
+		req *models.SalesDocumentUpdateRequest,
+	) (*models.SalesDocument, error)
+}
+
+// ChangeStatus calls ChangeStatusFunc
+func (mock *SalesManager) ChangeStatus(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, documentType string, docID uuid.UUID, status string) (*models.SalesDocument, error) {
+	if mock.ChangeStatusFunc == nil {
+		panic("SalesManager.ChangeStatus is not stubbed")
+	}
+	return mock.ChangeStatusFunc(ctx, tenantID, userID, documentType, docID, status)
+}
+
+// ConvertQuoteToOrder calls ConvertQuoteToOrderFunc
+func (mock *SalesManager) ConvertQuoteToOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, quoteID uuid.UUID) (*models.SalesDocument, error) {
+	if mock.ConvertQuoteToOrderFunc == nil {
+		panic("SalesManager.ConvertQuoteToOrder is not stubbed")
+	}
+	return mock.ConvertQuoteToOrderFunc(ctx, tenantID, userID, quoteID)
+}
+
+// CreateDocument calls CreateDocumentFunc
+func (mock *SalesManager) CreateDocument(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, documentType string, req *models.SalesDocumentCreateRequest) (*models.SalesDocument, error) {
+	if mock.CreateDocumentFunc == nil {
+		panic("SalesManager.CreateDocument is not stubbed")
+	}
+	return mock.CreateDocumentFunc(ctx, tenantID, userID, documentType, req)
+}
+
+// DeleteDocument calls DeleteDocumentFunc
+func (mock *SalesManager) DeleteDocument(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, documentType string, docID uuid.UUID) (*models.PendingDeletion, error) {
+	if mock.DeleteDocumentFunc == nil {
+		panic("SalesManager.DeleteDocument is not stubbed")
+	}
+	return mock.DeleteDocumentFunc(ctx, tenantID, userID, documentType, docID)
+}
+
+// DescribeDocument calls DescribeDocumentFunc
+func (mock *SalesManager) DescribeDocument(ctx context.Context, tenantID uuid.UUID, docID uuid.UUID) (*models.EntitySummary, error) {
+	if mock.DescribeDocumentFunc == nil {
+		panic("SalesManager.DescribeDocument is not stubbed")
+	}
+	return mock.DescribeDocumentFunc(ctx, tenantID, docID)
+}
+
+// GetDocument calls GetDocumentFunc
+func (mock *SalesManager) GetDocument(ctx context.Context, tenantID uuid.UUID, documentType string, docID uuid.UUID) (*models.SalesDocument, error) {
+	if mock.GetDocumentFunc == nil {
+		panic("SalesManager.GetDocument is not stubbed")
+	}
+	return mock.GetDocumentFunc(ctx, tenantID, documentType, docID)
+}
+
+// InvoiceOrder calls InvoiceOrderFunc
+func (mock *SalesManager) InvoiceOrder(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, orderID uuid.UUID) (*models.SalesDocument, error) {
+	if mock.InvoiceOrderFunc == nil {
+		panic("SalesManager.InvoiceOrder is not stubbed")
+	}
+	return mock.InvoiceOrderFunc(ctx, tenantID, userID, orderID)
+}
+
+// ListDocuments calls ListDocumentsFunc
+func (mock *SalesManager) ListDocuments(ctx context.Context, tenantID uuid.UUID, filter models.SalesDocumentFilter, limit int, offset int) ([]models.SalesDocument, int, error) {
+	if mock.ListDocumentsFunc == nil {
+		panic("SalesManager.ListDocuments is not stubbed")
+	}
+	return mock.ListDocumentsFunc(ctx, tenantID, filter, limit, offset)
+}
+
+// PurgeDocument calls PurgeDocumentFunc
+func (mock *SalesManager) PurgeDocument(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, docID uuid.UUID) error {
+	if mock.PurgeDocumentFunc == nil {
+		panic("SalesManager.PurgeDocument is not stubbed")
+	}
+	return mock.PurgeDocumentFunc(ctx, tenantID, userID, docID)
+}
+
+// UpdateDocument calls UpdateDocumentFunc
+func (mock *SalesManager) UpdateDocument(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, documentType string, docID uuid.UUID, req *models.SalesDocumentUpdateRequest) (*models.SalesDocument, error) {
+	if mock.UpdateDocumentFunc == nil {
+		panic("SalesManager.UpdateDocument is not stubbed")
+	}
+	return mock.UpdateDocumentFunc(ctx, tenantID, userID, documentType, docID, req)
+}
+
+// SandboxManager is a mock of services.SandboxManager
+type SandboxManager struct {
+	CreateSandboxFunc         func(ctx context.Context, tenantID, userID uuid.UUID, req *models.SandboxCreateRequest) (*models.TenantSandbox, error)
+	DeleteSandboxFunc         func(ctx context.Context, tenantID, userID, sandboxID uuid.UUID) error
+	ExpireSandboxesFunc       func(ctx context.Context) (int, error)
+	GetSandboxFunc            func(ctx context.Context, tenantID, sandboxID uuid.UUID) (*models.TenantSandbox, error)
+	ListSandboxesFunc         func(ctx context.Context, tenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error)
+	ProcessPendingFunc        func(ctx context.Context) (int, error)
+	RemoveTenantSandboxesFunc func(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// CreateSandbox calls CreateSandboxFunc
+func (mock *SandboxManager) CreateSandbox(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.SandboxCreateRequest) (*models.TenantSandbox, error) {
+	if mock.CreateSandboxFunc == nil {
+		panic("SandboxManager.CreateSandbox is not stubbed")
+	}
+	return mock.CreateSandboxFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteSandbox calls DeleteSandboxFunc
+func (mock *SandboxManager) DeleteSandbox(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, sandboxID uuid.UUID) error {
+	if mock.DeleteSandboxFunc == nil {
+		panic("SandboxManager.DeleteSandbox is not stubbed")
+	}
+	return mock.DeleteSandboxFunc(ctx, tenantID, userID, sandboxID)
+}
+
+// ExpireSandboxes calls ExpireSandboxesFunc
+func (mock *SandboxManager) ExpireSandboxes(ctx context.Context) (int, error) {
+	if mock.ExpireSandboxesFunc == nil {
+		panic("SandboxManager.ExpireSandboxes is not stubbed")
+	}
+	return mock.ExpireSandboxesFunc(ctx)
+}
+
+// GetSandbox calls GetSandboxFunc
+func (mock *SandboxManager) GetSandbox(ctx context.Context, tenantID uuid.UUID, sandboxID uuid.UUID) (*models.TenantSandbox, error) {
+	if mock.GetSandboxFunc == nil {
+		panic("SandboxManager.GetSandbox is not stubbed")
+	}
+	return mock.GetSandboxFunc(ctx, tenantID, sandboxID)
+}
+
+// ListSandboxes calls ListSandboxesFunc
+func (mock *SandboxManager) ListSandboxes(ctx context.Context, tenantID uuid.UUID, includeDeleted bool, limit int, offset int) ([]models.TenantSandbox, int, error) {
+	if mock.ListSandboxesFunc == nil {
+		panic("SandboxManager.ListSandboxes is not stubbed")
+	}
+	return mock.ListSandboxesFunc(ctx, tenantID, includeDeleted, limit, offset)
+}
+
+// ProcessPending calls ProcessPendingFunc
+func (mock *SandboxManager) ProcessPending(ctx context.Context) (int, error) {
+	if mock.ProcessPendingFunc == nil {
+		panic("SandboxManager.ProcessPending is not stubbed")
+	}
+	return mock.ProcessPendingFunc(ctx)
+}
+
+// RemoveTenantSandboxes calls RemoveTenantSandboxesFunc
+func (mock *SandboxManager) RemoveTenantSandboxes(ctx context.Context, tenantID uuid.UUID) error {
+	if mock.RemoveTenantSandboxesFunc == nil {
+		panic("SandboxManager.RemoveTenantSandboxes is not stubbed")
+	}
+	return mock.RemoveTenantSandboxesFunc(ctx, tenantID)
+}
+
+// ScheduledReportManager is a mock of services.ScheduledReportManager
+type ScheduledReportManager struct {
+	CreateReportFunc func(ctx context.Context, tenantID, userID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error)
+	DeleteReportFunc func(ctx context.Context, tenantID, reportID uuid.UUID) error
+	GetReportFunc    func(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error)
+	ListReportsFunc  func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error)
+	RunDueFunc       func(ctx context.Context) (int, error)
+	RunReportFunc    func(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReportRun, error)
+	UpdateReportFunc func(ctx context.Context, tenantID, reportID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error)
+}
+
+// CreateReport calls CreateReportFunc
+func (mock *ScheduledReportManager) CreateReport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error) {
+	if mock.CreateReportFunc == nil {
+		panic("ScheduledReportManager.CreateReport is not stubbed")
+	}
+	return mock.CreateReportFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteReport calls DeleteReportFunc
+func (mock *ScheduledReportManager) DeleteReport(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID) error {
+	if mock.DeleteReportFunc == nil {
+		panic("ScheduledReportManager.DeleteReport is not stubbed")
+	}
+	return mock.DeleteReportFunc(ctx, tenantID, reportID)
+}
+
+// GetReport calls GetReportFunc
+func (mock *ScheduledReportManager) GetReport(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID) (*models.ScheduledReport, error) {
+	if mock.GetReportFunc == nil {
+		panic("ScheduledReportManager.GetReport is not stubbed")
+	}
+	return mock.GetReportFunc(ctx, tenantID, reportID)
+}
+
+// ListReports calls ListReportsFunc
+func (mock *ScheduledReportManager) ListReports(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.ScheduledReport, int, error) {
+	if mock.ListReportsFunc == nil {
+		panic("ScheduledReportManager.ListReports is not stubbed")
+	}
+	return mock.ListReportsFunc(ctx, tenantID, limit, offset)
+}
+
+// RunDue calls RunDueFunc
+func (mock *ScheduledReportManager) RunDue(ctx context.Context) (int, error) {
+	if mock.RunDueFunc == nil {
+		panic("ScheduledReportManager.RunDue is not stubbed")
+	}
+	return mock.RunDueFunc(ctx)
+}
+
+// RunReport calls RunReportFunc
+func (mock *ScheduledReportManager) RunReport(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID) (*models.ScheduledReportRun, error) {
+	if mock.RunReportFunc == nil {
+		panic("ScheduledReportManager.RunReport is not stubbed")
+	}
+	return mock.RunReportFunc(ctx, tenantID, reportID)
+}
+
+// UpdateReport calls UpdateReportFunc
+func (mock *ScheduledReportManager) UpdateReport(ctx context.Context, tenantID uuid.UUID, reportID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error) {
+	if mock.UpdateReportFunc == nil {
+		panic("ScheduledReportManager.UpdateReport is not stubbed")
+	}
+	return mock.UpdateReportFunc(ctx, tenantID, reportID, req)
+}
+
+// SearchManager is a mock of services.SearchManager
+type SearchManager struct {
+	SearchFunc func(ctx context.Context, tenantID, userID uuid.UUID, query, language string, types []string, limit int) (map[string][]models.SearchResult, error)
+}
+
+// Search calls SearchFunc
+func (mock *SearchManager) Search(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, query string, language string, types []string, limit int) (map[string][]models.SearchResult, error) {
+	if mock.SearchFunc == nil {
+		panic("SearchManager.Search is not stubbed")
+	}
+	return mock.SearchFunc(ctx, tenantID, userID, query, language, types, limit)
+}
+
+// SessionManager is a mock of services.SessionManager
+type SessionManager struct {
+	CleanupExpiredSessionsFunc func(ctx context.Context) (int, error)
+	GetRecentLoginsFunc        func(ctx context.Context, tenantID, userID uuid.UUID, limit int) ([]models.Session, error)
+	GetSessionByTokenFunc      func(ctx context.Context, tokenHash string) (*models.Session, error)
+	GetSessionStatsFunc        func(ctx context.Context, tenantID, userID uuid.UUID) (map[string]interface{}, error)
+	ListUserSessionsFunc       func(ctx context.Context, tenantID, userID uuid.UUID, currentTokenHash string) ([]services.SessionInfo, error)
+	RenameSessionFunc          func(ctx context.Context, tenantID, userID, sessionID uuid.UUID, name string) (*models.Session, error)
+	RevokeAllSessionsFunc      func(ctx context.Context, tenantID, userID uuid.UUID, exceptTokenHash string) (int, error)
+	RevokeAllUserSessionsFunc  func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	RevokeSessionFunc          func(ctx context.Context, tenantID, userID, sessionID uuid.UUID) error
+	UpdateSessionActivityFunc  func(ctx context.Context, tenantID, sessionID uuid.UUID) error
+}
+
+// CleanupExpiredSessions calls CleanupExpiredSessionsFunc
+func (mock *SessionManager) CleanupExpiredSessions(ctx context.Context) (int, error) {
+	if mock.CleanupExpiredSessionsFunc == nil {
+		panic("SessionManager.CleanupExpiredSessions is not stubbed")
+	}
+	return mock.CleanupExpiredSessionsFunc(ctx)
+}
+
+// GetRecentLogins calls GetRecentLoginsFunc
+func (mock *SessionManager) GetRecentLogins(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int) ([]models.Session, error) {
+	if mock.GetRecentLoginsFunc == nil {
+		panic("SessionManager.GetRecentLogins is not stubbed")
+	}
+	return mock.GetRecentLoginsFunc(ctx, tenantID, userID, limit)
+}
+
+// GetSessionByToken calls GetSessionByTokenFunc
+func (mock *SessionManager) GetSessionByToken(ctx context.Context, tokenHash string) (*models.Session, error) {
+	if mock.GetSessionByTokenFunc == nil {
+		panic("SessionManager.GetSessionByToken is not stubbed")
+	}
+	return mock.GetSessionByTokenFunc(ctx, tokenHash)
+}
+
+// GetSessionStats calls GetSessionStatsFunc
+func (mock *SessionManager) GetSessionStats(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (map[string]interface{}, error) {
+	if mock.GetSessionStatsFunc == nil {
+		panic("SessionManager.GetSessionStats is not stubbed")
+	}
+	return mock.GetSessionStatsFunc(ctx, tenantID, userID)
+}
+
+// ListUserSessions calls ListUserSessionsFunc
+func (mock *SessionManager) ListUserSessions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, currentTokenHash string) ([]services.SessionInfo, error) {
+	if mock.ListUserSessionsFunc == nil {
+		panic("SessionManager.ListUserSessions is not stubbed")
+	}
+	return mock.ListUserSessionsFunc(ctx, tenantID, userID, currentTokenHash)
+}
+
+// RenameSession calls RenameSessionFunc
+func (mock *SessionManager) RenameSession(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, sessionID uuid.UUID, name string) (*models.Session, error) {
+	if mock.RenameSessionFunc == nil {
+		panic("SessionManager.RenameSession is not stubbed")
+	}
+	return mock.RenameSessionFunc(ctx, tenantID, userID, sessionID, name)
+}
+
+// RevokeAllSessions calls RevokeAllSessionsFunc
+func (mock *SessionManager) RevokeAllSessions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, exceptTokenHash string) (int, error) {
+	if mock.RevokeAllSessionsFunc == nil {
+		panic("SessionManager.RevokeAllSessions is not stubbed")
+	}
+	return mock.RevokeAllSessionsFunc(ctx, tenantID, userID, exceptTokenHash)
+}
+
+// RevokeAllUserSessions calls RevokeAllUserSessionsFunc
+func (mock *SessionManager) RevokeAllUserSessions(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.RevokeAllUserSessionsFunc == nil {
+		panic("SessionManager.RevokeAllUserSessions is not stubbed")
+	}
+	return mock.RevokeAllUserSessionsFunc(ctx, tenantID, userID)
+}
+
+// RevokeSession calls RevokeSessionFunc
+func (mock *SessionManager) RevokeSession(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, sessionID uuid.UUID) error {
+	if mock.RevokeSessionFunc == nil {
+		panic("SessionManager.RevokeSession is not stubbed")
+	}
+	return mock.RevokeSessionFunc(ctx, tenantID, userID, sessionID)
+}
+
+// UpdateSessionActivity calls UpdateSessionActivityFunc
+func (mock *SessionManager) UpdateSessionActivity(ctx context.Context, tenantID uuid.UUID, sessionID uuid.UUID) error {
+	if mock.UpdateSessionActivityFunc == nil {
+		panic("SessionManager.UpdateSessionActivity is not stubbed")
+	}
+	return mock.UpdateSessionActivityFunc(ctx, tenantID, sessionID)
+}
+
+// TenantDomainManager is a mock of services.TenantDomainManager
+type TenantDomainManager struct {
+	AddDomainFunc          func(ctx context.Context, tenantID, userID uuid.UUID, name string) (*models.TenantDomain, error)
+	CanonicalHostFunc      func(host string, tenant *models.Tenant) (string, bool)
+	ChangeSlugFunc         func(ctx context.Context, tenantID, userID uuid.UUID, slug string) (*models.TenantSlugChange, error)
+	CleanupSlugHistoryFunc func(ctx context.Context) (int, error)
+	GetDomainFunc          func(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	IsTenantOriginFunc     func(ctx context.Context, origin string) bool
+	ListDomainsFunc        func(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error)
+	RemoveDomainFunc       func(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+	ResolveHostFunc        func(ctx context.Context, host string) (*models.Tenant, error)
+	ResolveSlugFunc        func(ctx context.Context, slug string) (*models.Tenant, error)
+	VerifyDomainFunc       func(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error)
+}
+
+// AddDomain calls AddDomainFunc
+func (mock *TenantDomainManager) AddDomain(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, name string) (*models.TenantDomain, error) {
+	if mock.AddDomainFunc == nil {
+		panic("TenantDomainManager.AddDomain is not stubbed")
+	}
+	return mock.AddDomainFunc(ctx, tenantID, userID, name)
+}
+
+// CanonicalHost calls CanonicalHostFunc
+func (mock *TenantDomainManager) CanonicalHost(host string, tenant *models.Tenant) (string, bool) {
+	if mock.CanonicalHostFunc == nil {
+		panic("TenantDomainManager.CanonicalHost is not stubbed")
+	}
+	return mock.CanonicalHostFunc(host, tenant)
+}
+
+// ChangeSlug calls ChangeSlugFunc
+func (mock *TenantDomainManager) ChangeSlug(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, slug string) (*models.TenantSlugChange, error) {
+	if mock.ChangeSlugFunc == nil {
+		panic("TenantDomainManager.ChangeSlug is not stubbed")
+	}
+	return mock.ChangeSlugFunc(ctx, tenantID, userID, slug)
+}
+
+// CleanupSlugHistory calls CleanupSlugHistoryFunc
+func (mock *TenantDomainManager) CleanupSlugHistory(ctx context.Context) (int, error) {
+	if mock.CleanupSlugHistoryFunc == nil {
+		panic("TenantDomainManager.CleanupSlugHistory is not stubbed")
+	}
+	return mock.CleanupSlugHistoryFunc(ctx)
+}
+
+// GetDomain calls GetDomainFunc
+func (mock *TenantDomainManager) GetDomain(ctx context.Context, tenantID uuid.UUID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	if mock.GetDomainFunc == nil {
+		panic("TenantDomainManager.GetDomain is not stubbed")
+	}
+	return mock.GetDomainFunc(ctx, tenantID, domainID)
+}
+
+// IsTenantOrigin calls IsTenantOriginFunc
+func (mock *TenantDomainManager) IsTenantOrigin(ctx context.Context, origin string) bool {
+	if mock.IsTenantOriginFunc == nil {
+		panic("TenantDomainManager.IsTenantOrigin is not stubbed")
+	}
+	return mock.IsTenantOriginFunc(ctx, origin)
+}
+
+// ListDomains calls ListDomainsFunc
+func (mock *TenantDomainManager) ListDomains(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error) {
+	if mock.ListDomainsFunc == nil {
+		panic("TenantDomainManager.ListDomains is not stubbed")
+	}
+	return mock.ListDomainsFunc(ctx, tenantID)
+}
+
+// RemoveDomain calls RemoveDomainFunc
+func (mock *TenantDomainManager) RemoveDomain(ctx context.Context, tenantID uuid.UUID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	if mock.RemoveDomainFunc == nil {
+		panic("TenantDomainManager.RemoveDomain is not stubbed")
+	}
+	return mock.RemoveDomainFunc(ctx, tenantID, domainID)
+}
+
+// ResolveHost calls ResolveHostFunc
+func (mock *TenantDomainManager) ResolveHost(ctx context.Context, host string) (*models.Tenant, error) {
+	if mock.ResolveHostFunc == nil {
+		panic("TenantDomainManager.ResolveHost is not stubbed")
+	}
+	return mock.ResolveHostFunc(ctx, host)
+}
+
+// ResolveSlug calls ResolveSlugFunc
+func (mock *TenantDomainManager) ResolveSlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	if mock.ResolveSlugFunc == nil {
+		panic("TenantDomainManager.ResolveSlug is not stubbed")
+	}
+	return mock.ResolveSlugFunc(ctx, slug)
+}
+
+// VerifyDomain calls VerifyDomainFunc
+func (mock *TenantDomainManager) VerifyDomain(ctx context.Context, tenantID uuid.UUID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	if mock.VerifyDomainFunc == nil {
+		panic("TenantDomainManager.VerifyDomain is not stubbed")
+	}
+	return mock.VerifyDomainFunc(ctx, tenantID, domainID)
+}
+
+// TenantSettingsManager is a mock of services.TenantSettingsManager
+type TenantSettingsManager struct {
+	ApplyPatchFunc   func(current *models.TenantSettings, patch map[string]json.RawMessage) (*models.TenantSettings, error)
+	GetSettingsFunc  func(ctx context.Context, tenantID uuid.UUID) (*models.TenantSettings, error)
+	MaxLogoSizeFunc  func() int64
+	RemoveLogoFunc   func(ctx context.Context, tenantID, actorID uuid.UUID) (*models.TenantSettings, error)
+	SaveSettingsFunc func(ctx context.Context, tenantID uuid.UUID, version string, settings *models.TenantSettings) (*models.TenantSettings, error)
+	UploadLogoFunc   func(ctx context.Context, tenantID, actorID uuid.UUID, name string, data []byte) (*models.TenantSettings, error)
+}
+
+// ApplyPatch calls ApplyPatchFunc
+func (mock *TenantSettingsManager) ApplyPatch(current *models.TenantSettings, patch map[string]json.RawMessage) (*models.TenantSettings, error) {
+	if mock.ApplyPatchFunc == nil {
+		panic("TenantSettingsManager.ApplyPatch is not stubbed")
+	}
+	return mock.ApplyPatchFunc(current, patch)
+}
+
+// GetSettings calls GetSettingsFunc
+func (mock *TenantSettingsManager) GetSettings(ctx context.Context, tenantID uuid.UUID) (*models.TenantSettings, error) {
+	if mock.GetSettingsFunc == nil {
+		panic("TenantSettingsManager.GetSettings is not stubbed")
+	}
+	return mock.GetSettingsFunc(ctx, tenantID)
+}
+
+// MaxLogoSize calls MaxLogoSizeFunc
+func (mock *TenantSettingsManager) MaxLogoSize() int64 {
+	if mock.MaxLogoSizeFunc == nil {
+		panic("TenantSettingsManager.MaxLogoSize is not stubbed")
+	}
+	return mock.MaxLogoSizeFunc()
+}
+
+// RemoveLogo calls RemoveLogoFunc
+func (mock *TenantSettingsManager) RemoveLogo(ctx context.Context, tenantID uuid.UUID, actorID uuid.UUID) (*models.TenantSettings, error) {
+	if mock.RemoveLogoFunc == nil {
+		panic("TenantSettingsManager.RemoveLogo is not stubbed")
+	}
+	return mock.RemoveLogoFunc(ctx, tenantID, actorID)
+}
+
+// SaveSettings calls SaveSettingsFunc
+func (mock *TenantSettingsManager) SaveSettings(ctx context.Context, tenantID uuid.UUID, version string, settings *models.TenantSettings) (*models.TenantSettings, error) {
+	if mock.SaveSettingsFunc == nil {
+		panic("TenantSettingsManager.SaveSettings is not stubbed")
+	}
+	return mock.SaveSettingsFunc(ctx, tenantID, version, settings)
+}
+
+// UploadLogo calls UploadLogoFunc
+func (mock *TenantSettingsManager) UploadLogo(ctx context.Context, tenantID uuid.UUID, actorID uuid.UUID, name string, data []byte) (*models.TenantSettings, error) {
+	if mock.UploadLogoFunc == nil {
+		panic("TenantSettingsManager.UploadLogo is not stubbed")
+	}
+	return mock.UploadLogoFunc(ctx, tenantID, actorID, name, data)
+}
+
+// TimesheetManager is a mock of services.TimesheetManager
+type TimesheetManager struct {
+	AddEntryFunc       func(ctx context.Context, tenantID, userID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error)
+	ApproveFunc        func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	CreateProjectFunc  func(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error)
+	CreateTaskFunc     func(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error)
+	DeleteEntryFunc    func(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.TimesheetEntry, error)
+	DeleteProjectFunc  func(ctx context.Context, tenantID, projectID uuid.UUID) error
+	DeleteTaskFunc     func(ctx context.Context, tenantID, projectID, taskID uuid.UUID) error
+	EmployeeReportFunc func(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error)
+	GetFunc            func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	GetProjectFunc     func(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error)
+	ListFunc           func(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error)
+	ListApprovalsFunc  func(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.Timesheet, int, error)
+	ListOwnFunc        func(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error)
+	ListProjectsFunc   func(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error)
+	ProjectReportFunc  func(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error)
+	RejectFunc         func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	ReopenFunc         func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error)
+	SubmitFunc         func(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error)
+	UpdateEntryFunc    func(ctx context.Context, tenantID, userID, entryID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error)
+	UpdateProjectFunc  func(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error)
+	UpdateTaskFunc     func(ctx context.Context, tenantID, projectID, taskID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error)
+	WeekFunc           func(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, day time.Time) (*models.Timesheet, error)
+}
+
+// AddEntry calls AddEntryFunc
+func (mock *TimesheetManager) AddEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error) {
+	if mock.AddEntryFunc == nil {
+		panic("TimesheetManager.AddEntry is not stubbed")
+	}
+	return mock.AddEntryFunc(ctx, tenantID, userID, req)
+}
+
+// Approve calls ApproveFunc
+func (mock *TimesheetManager) Approve(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	if mock.ApproveFunc == nil {
+		panic("TimesheetManager.Approve is not stubbed")
+	}
+	return mock.ApproveFunc(ctx, tenantID, userID, timesheetID, note)
+}
+
+// CreateProject calls CreateProjectFunc
+func (mock *TimesheetManager) CreateProject(ctx context.Context, tenantID uuid.UUID, creatorID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error) {
+	if mock.CreateProjectFunc == nil {
+		panic("TimesheetManager.CreateProject is not stubbed")
+	}
+	return mock.CreateProjectFunc(ctx, tenantID, creatorID, req)
+}
+
+// CreateTask calls CreateTaskFunc
+func (mock *TimesheetManager) CreateTask(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error) {
+	if mock.CreateTaskFunc == nil {
+		panic("TimesheetManager.CreateTask is not stubbed")
+	}
+	return mock.CreateTaskFunc(ctx, tenantID, projectID, req)
+}
+
+// DeleteEntry calls DeleteEntryFunc
+func (mock *TimesheetManager) DeleteEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID) (*models.TimesheetEntry, error) {
+	if mock.DeleteEntryFunc == nil {
+		panic("TimesheetManager.DeleteEntry is not stubbed")
+	}
+	return mock.DeleteEntryFunc(ctx, tenantID, userID, entryID)
+}
+
+// DeleteProject calls DeleteProjectFunc
+func (mock *TimesheetManager) DeleteProject(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID) error {
+	if mock.DeleteProjectFunc == nil {
+		panic("TimesheetManager.DeleteProject is not stubbed")
+	}
+	return mock.DeleteProjectFunc(ctx, tenantID, projectID)
+}
+
+// DeleteTask calls DeleteTaskFunc
+func (mock *TimesheetManager) DeleteTask(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID, taskID uuid.UUID) error {
+	if mock.DeleteTaskFunc == nil {
+		panic("TimesheetManager.DeleteTask is not stubbed")
+	}
+	return mock.DeleteTaskFunc(ctx, tenantID, projectID, taskID)
+}
+
+// EmployeeReport calls EmployeeReportFunc
+func (mock *TimesheetManager) EmployeeReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error) {
+	if mock.EmployeeReportFunc == nil {
+		panic("TimesheetManager.EmployeeReport is not stubbed")
+	}
+	return mock.EmployeeReportFunc(ctx, tenantID, filter)
+}
+
+// Get calls GetFunc
+func (mock *TimesheetManager) Get(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	if mock.GetFunc == nil {
+		panic("TimesheetManager.Get is not stubbed")
+	}
+	return mock.GetFunc(ctx, tenantID, userID, timesheetID)
+}
+
+// GetProject calls GetProjectFunc
+func (mock *TimesheetManager) GetProject(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID) (*models.TimesheetProject, error) {
+	if mock.GetProjectFunc == nil {
+		panic("TimesheetManager.GetProject is not stubbed")
+	}
+	return mock.GetProjectFunc(ctx, tenantID, projectID)
+}
+
+// List calls ListFunc
+func (mock *TimesheetManager) List(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.TimesheetFilter, limit int, offset int) ([]models.Timesheet, int, error) {
+	if mock.ListFunc == nil {
+		panic("TimesheetManager.List is not stubbed")
+	}
+	return mock.ListFunc(ctx, tenantID, userID, filter, limit, offset)
+}
+
+// ListApprovals calls ListApprovalsFunc
+func (mock *TimesheetManager) ListApprovals(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, limit int, offset int) ([]models.Timesheet, int, error) {
+	if mock.ListApprovalsFunc == nil {
+		panic("TimesheetManager.ListApprovals is not stubbed")
+	}
+	return mock.ListApprovalsFunc(ctx, tenantID, userID, limit, offset)
+}
+
+// ListOwn calls ListOwnFunc
+func (mock *TimesheetManager) ListOwn(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, filter models.TimesheetFilter, limit int, offset int) ([]models.Timesheet, int, error) {
+	if mock.ListOwnFunc == nil {
+		panic("TimesheetManager.ListOwn is not stubbed")
+	}
+	return mock.ListOwnFunc(ctx, tenantID, userID, filter, limit, offset)
+}
+
+// ListProjects calls ListProjectsFunc
+func (mock *TimesheetManager) ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error) {
+	if mock.ListProjectsFunc == nil {
+		panic("TimesheetManager.ListProjects is not stubbed")
+	}
+	return mock.ListProjectsFunc(ctx, tenantID, activeOnly, customerID)
+}
+
+// ProjectReport calls ProjectReportFunc
+func (mock *TimesheetManager) ProjectReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error) {
+	if mock.ProjectReportFunc == nil {
+		panic("TimesheetManager.ProjectReport is not stubbed")
+	}
+	return mock.ProjectReportFunc(ctx, tenantID, filter)
+}
+
+// Reject calls RejectFunc
+func (mock *TimesheetManager) Reject(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	if mock.RejectFunc == nil {
+		panic("TimesheetManager.Reject is not stubbed")
+	}
+	return mock.RejectFunc(ctx, tenantID, userID, timesheetID, note)
+}
+
+// Reopen calls ReopenFunc
+func (mock *TimesheetManager) Reopen(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	if mock.ReopenFunc == nil {
+		panic("TimesheetManager.Reopen is not stubbed")
+	}
+	return mock.ReopenFunc(ctx, tenantID, userID, timesheetID, note)
+}
+
+// Submit calls SubmitFunc
+func (mock *TimesheetManager) Submit(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	if mock.SubmitFunc == nil {
+		panic("TimesheetManager.Submit is not stubbed")
+	}
+	return mock.SubmitFunc(ctx, tenantID, userID, timesheetID)
+}
+
+// UpdateEntry calls UpdateEntryFunc
+func (mock *TimesheetManager) UpdateEntry(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entryID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error) {
+	if mock.UpdateEntryFunc == nil {
+		panic("TimesheetManager.UpdateEntry is not stubbed")
+	}
+	return mock.UpdateEntryFunc(ctx, tenantID, userID, entryID, req)
+}
+
+// UpdateProject calls UpdateProjectFunc
+func (mock *TimesheetManager) UpdateProject(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error) {
+	if mock.UpdateProjectFunc == nil {
+		panic("TimesheetManager.UpdateProject is not stubbed")
+	}
+	return mock.UpdateProjectFunc(ctx, tenantID, projectID, req)
+}
+
+// UpdateTask calls UpdateTaskFunc
+func (mock *TimesheetManager) UpdateTask(ctx context.Context, tenantID uuid.UUID, projectID uuid.UUID, taskID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error) {
+	if mock.UpdateTaskFunc == nil {
+		panic("TimesheetManager.UpdateTask is not stubbed")
+	}
+	return mock.UpdateTaskFunc(ctx, tenantID, projectID, taskID, req)
+}
+
+// Week calls WeekFunc
+func (mock *TimesheetManager) Week(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, employeeID *uuid.UUID, day time.Time) (*models.Timesheet, error) {
+	if mock.WeekFunc == nil {
+		panic("TimesheetManager.Week is not stubbed")
+	}
+	return mock.WeekFunc(ctx, tenantID, userID, employeeID, day)
+}
+
+// TwoFactorManager is a mock of services.TwoFactorManager
+type TwoFactorManager struct {
+	DisableTwoFactorFunc        func(ctx context.Context, tenantID, userID uuid.UUID) error
+	EnableTwoFactorFunc         func(ctx context.Context, tenantID, userID uuid.UUID, secret string, verificationCode string, backupCodes []string) error
+	GenerateSecretFunc          func(ctx context.Context, email, accountName string) (*services.TwoFactorSetup, error)
+	GetRemainingBackupCodesFunc func(ctx context.Context, tenantID, userID uuid.UUID) (int, error)
+	IsDeviceTrustedFunc         func(ctx context.Context, tenantID, userID uuid.UUID, deviceFingerprint, deviceToken string) bool
+	RegenerateBackupCodesFunc   func(ctx context.Context, tenantID, userID uuid.UUID) ([]string, error)
+	RememberDeviceFunc          func(ctx context.Context, tenantID, userID uuid.UUID, deviceFingerprint string) (string, error)
+	VerifyBackupCodeFunc        func(ctx context.Context, tenantID, userID uuid.UUID, code string) (bool, error)
+	VerifyTOTPFunc              func(ctx context.Context, tenantID, userID uuid.UUID, code string) (bool, error)
+}
+
+// DisableTwoFactor calls DisableTwoFactorFunc
+func (mock *TwoFactorManager) DisableTwoFactor(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) error {
+	if mock.DisableTwoFactorFunc == nil {
+		panic("TwoFactorManager.DisableTwoFactor is not stubbed")
+	}
+	return mock.DisableTwoFactorFunc(ctx, tenantID, userID)
+}
+
+// EnableTwoFactor calls EnableTwoFactorFunc
+func (mock *TwoFactorManager) EnableTwoFactor(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, secret string, verificationCode string, backupCodes []string) error {
+	if mock.EnableTwoFactorFunc == nil {
+		panic("TwoFactorManager.EnableTwoFactor is not stubbed")
+	}
+	return mock.EnableTwoFactorFunc(ctx, tenantID, userID, secret, verificationCode, backupCodes)
+}
+
+// GenerateSecret calls GenerateSecretFunc
+func (mock *TwoFactorManager) GenerateSecret(ctx context.Context, email string, accountName string) (*services.TwoFactorSetup, error) {
+	if mock.GenerateSecretFunc == nil {
+		panic("TwoFactorManager.GenerateSecret is not stubbed")
+	}
+	return mock.GenerateSecretFunc(ctx, email, accountName)
+}
+
+// GetRemainingBackupCodes calls GetRemainingBackupCodesFunc
+func (mock *TwoFactorManager) GetRemainingBackupCodes(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) (int, error) {
+	if mock.GetRemainingBackupCodesFunc == nil {
+		panic("TwoFactorManager.GetRemainingBackupCodes is not stubbed")
+	}
+	return mock.GetRemainingBackupCodesFunc(ctx, tenantID, userID)
+}
+
+// IsDeviceTrusted calls IsDeviceTrustedFunc
+func (mock *TwoFactorManager) IsDeviceTrusted(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, deviceFingerprint string, deviceToken string) bool {
+	if mock.IsDeviceTrustedFunc == nil {
+		panic("TwoFactorManager.IsDeviceTrusted is not stubbed")
+	}
+	return mock.IsDeviceTrustedFunc(ctx, tenantID, userID, deviceFingerprint, deviceToken)
+}
+
+// RegenerateBackupCodes calls RegenerateBackupCodesFunc
+func (mock *TwoFactorManager) RegenerateBackupCodes(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID) ([]string, error) {
+	if mock.RegenerateBackupCodesFunc == nil {
+		panic("TwoFactorManager.RegenerateBackupCodes is not stubbed")
+	}
+	return mock.RegenerateBackupCodesFunc(ctx, tenantID, userID)
+}
+
+// RememberDevice calls RememberDeviceFunc
+func (mock *TwoFactorManager) RememberDevice(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, deviceFingerprint string) (string, error) {
+	if mock.RememberDeviceFunc == nil {
+		panic("TwoFactorManager.RememberDevice is not stubbed")
+	}
+	return mock.RememberDeviceFunc(ctx, tenantID, userID, deviceFingerprint)
+}
+
+// VerifyBackupCode calls VerifyBackupCodeFunc
+func (mock *TwoFactorManager) VerifyBackupCode(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, code string) (bool, error) {
+	if mock.VerifyBackupCodeFunc == nil {
+		panic("TwoFactorManager.VerifyBackupCode is not stubbed")
+	}
+	return mock.VerifyBackupCodeFunc(ctx, tenantID, userID, code)
+}
+
+// VerifyTOTP calls VerifyTOTPFunc
+func (mock *TwoFactorManager) VerifyTOTP(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, code string) (bool, error) {
+	if mock.VerifyTOTPFunc == nil {
+		panic("TwoFactorManager.VerifyTOTP is not stubbed")
+	}
+	return mock.VerifyTOTPFunc(ctx, tenantID, userID, code)
+}
+
+// UsageManager is a mock of services.UsageManager
+type UsageManager struct {
+	CleanupFunc               func(ctx context.Context) (int, error)
+	ExportFunc                func(ctx context.Context) (int, error)
+	FlushFunc                 func(ctx context.Context) (int, error)
+	GetPrivacySettingsFunc    func(ctx context.Context, tenantID uuid.UUID) (*models.PrivacySettings, error)
+	RecordFunc                func(tenantID, userID uuid.UUID, endpoint string)
+	StartFunc                 func(ctx context.Context)
+	StopFunc                  func()
+	TenantRefFunc             func(tenantID uuid.UUID) string
+	UpdatePrivacySettingsFunc func(ctx context.Context, tenantID uuid.UUID, req *models.PrivacySettingsUpdateRequest) (*models.PrivacySettings, error)
+}
+
+// Cleanup calls CleanupFunc
+func (mock *UsageManager) Cleanup(ctx context.Context) (int, error) {
+	if mock.CleanupFunc == nil {
+		panic("UsageManager.Cleanup is not stubbed")
+	}
+	return mock.CleanupFunc(ctx)
+}
+
+// Export calls ExportFunc
+func (mock *UsageManager) Export(ctx context.Context) (int, error) {
+	if mock.ExportFunc == nil {
+		panic("UsageManager.Export is not stubbed")
+	}
+	return mock.ExportFunc(ctx)
+}
+
+// Flush calls FlushFunc
+func (mock *UsageManager) Flush(ctx context.Context) (int, error) {
+	if mock.FlushFunc == nil {
+		panic("UsageManager.Flush is not stubbed")
+	}
+	return mock.FlushFunc(ctx)
+}
+
+// GetPrivacySettings calls GetPrivacySettingsFunc
+func (mock *UsageManager) GetPrivacySettings(ctx context.Context, tenantID uuid.UUID) (*models.PrivacySettings, error) {
+	if mock.GetPrivacySettingsFunc == nil {
+		panic("UsageManager.GetPrivacySettings is not stubbed")
+	}
+	return mock.GetPrivacySettingsFunc(ctx, tenantID)
+}
+
+// Record calls RecordFunc
+func (mock *UsageManager) Record(tenantID uuid.UUID, userID uuid.UUID, endpoint string) {
+	if mock.RecordFunc == nil {
+		panic("UsageManager.Record is not stubbed")
+	}
+	mock.RecordFunc(tenantID, userID, endpoint)
+}
+
+// Start calls StartFunc
+func (mock *UsageManager) Start(ctx context.Context) {
+	if mock.StartFunc == nil {
+		panic("UsageManager.Start is not stubbed")
+	}
+	mock.StartFunc(ctx)
+}
+
+// Stop calls StopFunc
+func (mock *UsageManager) Stop() {
+	if mock.StopFunc == nil {
+		panic("UsageManager.Stop is not stubbed")
+	}
+	mock.StopFunc()
+}
+
+// TenantRef calls TenantRefFunc
+func (mock *UsageManager) TenantRef(tenantID uuid.UUID) string {
+	if mock.TenantRefFunc == nil {
+		panic("UsageManager.TenantRef is not stubbed")
+	}
+	return mock.TenantRefFunc(tenantID)
+}
+
+// UpdatePrivacySettings calls UpdatePrivacySettingsFunc
+func (mock *UsageManager) UpdatePrivacySettings(ctx context.Context, tenantID uuid.UUID, req *models.PrivacySettingsUpdateRequest) (*models.PrivacySettings, error) {
+	if mock.UpdatePrivacySettingsFunc == nil {
+		panic("UsageManager.UpdatePrivacySettings is not stubbed")
+	}
+	return mock.UpdatePrivacySettingsFunc(ctx, tenantID, req)
+}
+
+// UserImportManager is a mock of services.UserImportManager
+type UserImportManager struct {
+	GetImportFunc    func(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error)
+	ImportFunc       func(ctx context.Context, tenantID, userID uuid.UUID, fileName string, rows []models.UserImportRow, dryRun bool) (*models.UserImportReport, *models.AsyncJob, error)
+	MaxFileSizeFunc  func() int64
+	ParseFileFunc    func(fileName string, data []byte) ([]models.UserImportRow, error)
+	RunImportJobFunc func(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error)
+}
+
+// GetImport calls GetImportFunc
+func (mock *UserImportManager) GetImport(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	if mock.GetImportFunc == nil {
+		panic("UserImportManager.GetImport is not stubbed")
+	}
+	return mock.GetImportFunc(ctx, tenantID, userID, jobID)
+}
+
+// Import calls ImportFunc
+func (mock *UserImportManager) Import(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, fileName string, rows []models.UserImportRow, dryRun bool) (*models.UserImportReport, *models.AsyncJob, error) {
+	if mock.ImportFunc == nil {
+		panic("UserImportManager.Import is not stubbed")
+	}
+	return mock.ImportFunc(ctx, tenantID, userID, fileName, rows, dryRun)
+}
+
+// MaxFileSize calls MaxFileSizeFunc
+func (mock *UserImportManager) MaxFileSize() int64 {
+	if mock.MaxFileSizeFunc == nil {
+		panic("UserImportManager.MaxFileSize is not stubbed")
+	}
+	return mock.MaxFileSizeFunc()
+}
+
+// ParseFile calls ParseFileFunc
+func (mock *UserImportManager) ParseFile(fileName string, data []byte) ([]models.UserImportRow, error) {
+	if mock.ParseFileFunc == nil {
+		panic("UserImportManager.ParseFile is not stubbed")
+	}
+	return mock.ParseFileFunc(fileName, data)
+}
+
+// RunImportJob calls RunImportJobFunc
+func (mock *UserImportManager) RunImportJob(ctx context.Context, job *models.AsyncJob, progress *services.JobProgress) (interface{}, error) {
+	if mock.RunImportJobFunc == nil {
+		panic("UserImportManager.RunImportJob is not stubbed")
+	}
+	return mock.RunImportJobFunc(ctx, job, progress)
+}
+
+// WatchManager is a mock of services.WatchManager
+type WatchManager struct {
+	EntityTypesFunc    func() []string
+	GetWatchersFunc    func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatchers, error)
+	HandleDeletionFunc func(ctx context.Context, deletion *models.PendingDeletion) error
+	HandleEventFunc    func(ctx context.Context, event *models.Event) error
+	ListWatchesFunc    func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error)
+	RegisterTypeFunc   func(entityType, resource, assigneeField string, describe services.EntityDescriber)
+	UnwatchFunc        func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	WatchFunc          func(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatch, bool, error)
+}
+
+// EntityTypes calls EntityTypesFunc
+func (mock *WatchManager) EntityTypes() []string {
+	if mock.EntityTypesFunc == nil {
+		panic("WatchManager.EntityTypes is not stubbed")
+	}
+	return mock.EntityTypesFunc()
+}
+
+// GetWatchers calls GetWatchersFunc
+func (mock *WatchManager) GetWatchers(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatchers, error) {
+	if mock.GetWatchersFunc == nil {
+		panic("WatchManager.GetWatchers is not stubbed")
+	}
+	return mock.GetWatchersFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// HandleDeletion calls HandleDeletionFunc
+func (mock *WatchManager) HandleDeletion(ctx context.Context, deletion *models.PendingDeletion) error {
+	if mock.HandleDeletionFunc == nil {
+		panic("WatchManager.HandleDeletion is not stubbed")
+	}
+	return mock.HandleDeletionFunc(ctx, deletion)
+}
+
+// HandleEvent calls HandleEventFunc
+func (mock *WatchManager) HandleEvent(ctx context.Context, event *models.Event) error {
+	if mock.HandleEventFunc == nil {
+		panic("WatchManager.HandleEvent is not stubbed")
+	}
+	return mock.HandleEventFunc(ctx, event)
+}
+
+// ListWatches calls ListWatchesFunc
+func (mock *WatchManager) ListWatches(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, limit int, offset int) ([]models.RecordWatch, int, error) {
+	if mock.ListWatchesFunc == nil {
+		panic("WatchManager.ListWatches is not stubbed")
+	}
+	return mock.ListWatchesFunc(ctx, tenantID, userID, entityType, limit, offset)
+}
+
+// RegisterType calls RegisterTypeFunc
+func (mock *WatchManager) RegisterType(entityType string, resource string, assigneeField string, describe services.EntityDescriber) {
+	if mock.RegisterTypeFunc == nil {
+		panic("WatchManager.RegisterType is not stubbed")
+	}
+	mock.RegisterTypeFunc(entityType, resource, assigneeField, describe)
+}
+
+// Unwatch calls UnwatchFunc
+func (mock *WatchManager) Unwatch(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if mock.UnwatchFunc == nil {
+		panic("WatchManager.Unwatch is not stubbed")
+	}
+	return mock.UnwatchFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// Watch calls WatchFunc
+func (mock *WatchManager) Watch(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatch, bool, error) {
+	if mock.WatchFunc == nil {
+		panic("WatchManager.Watch is not stubbed")
+	}
+	return mock.WatchFunc(ctx, tenantID, userID, entityType, entityID)
+}
+
+// WebhookManager is a mock of services.WebhookManager
+type WebhookManager struct {
+	CleanupDeliveriesFunc func(ctx context.Context) (int, error)
+	CreateWebhookFunc     func(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebhookCreateRequest) (*models.Webhook, string, error)
+	DeleteWebhookFunc     func(ctx context.Context, tenantID, webhookID uuid.UUID) error
+	DeliverToFunc         func(ctx context.Context, tenantID, webhookID uuid.UUID, event *models.Event) (*models.WebhookDelivery, error)
+	DispatchFunc          func(ctx context.Context, event *models.Event) error
+	GetDeliveryFunc       func(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	GetWebhookFunc        func(ctx context.Context, tenantID, webhookID uuid.UUID) (*models.Webhook, error)
+	ListDeliveriesFunc    func(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error)
+	ListWebhooksFunc      func(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error)
+	PostJSONFunc          func(ctx context.Context, rawURL string, payload interface{}) error
+	ProcessQueueFunc      func(ctx context.Context) (int, error)
+	RetryDeliveryFunc     func(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error
+	RotateSecretFunc      func(ctx context.Context, tenantID, webhookID uuid.UUID) (string, error)
+	SendTestEventFunc     func(ctx context.Context, tenantID, userID, webhookID uuid.UUID) (*models.WebhookDelivery, error)
+	UpdateWebhookFunc     func(ctx context.Context, tenantID, webhookID uuid.UUID, req *models.WebhookUpdateRequest) (*models.Webhook, error)
+	ValidateURLFunc       func(rawURL string) error
+}
+
+// CleanupDeliveries calls CleanupDeliveriesFunc
+func (mock *WebhookManager) CleanupDeliveries(ctx context.Context) (int, error) {
+	if mock.CleanupDeliveriesFunc == nil {
+		panic("WebhookManager.CleanupDeliveries is not stubbed")
+	}
+	return mock.CleanupDeliveriesFunc(ctx)
+}
+
+// CreateWebhook calls CreateWebhookFunc
+func (mock *WebhookManager) CreateWebhook(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, req *models.WebhookCreateRequest) (*models.Webhook, string, error) {
+	if mock.CreateWebhookFunc == nil {
+		panic("WebhookManager.CreateWebhook is not stubbed")
+	}
+	return mock.CreateWebhookFunc(ctx, tenantID, userID, req)
+}
+
+// DeleteWebhook calls DeleteWebhookFunc
+func (mock *WebhookManager) DeleteWebhook(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID) error {
+	if mock.DeleteWebhookFunc == nil {
+		panic("WebhookManager.DeleteWebhook is not stubbed")
+	}
+	return mock.DeleteWebhookFunc(ctx, tenantID, webhookID)
+}
+
+// DeliverTo calls DeliverToFunc
+func (mock *WebhookManager) DeliverTo(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, event *models.Event) (*models.WebhookDelivery, error) {
+	if mock.DeliverToFunc == nil {
+		panic("WebhookManager.DeliverTo is not stubbed")
+	}
+	return mock.DeliverToFunc(ctx, tenantID, webhookID, event)
+}
+
+// Dispatch calls DispatchFunc
+func (mock *WebhookManager) Dispatch(ctx context.Context, event *models.Event) error {
+	if mock.DispatchFunc == nil {
+		panic("WebhookManager.Dispatch is not stubbed")
+	}
+	return mock.DispatchFunc(ctx, event)
+}
+
+// GetDelivery calls GetDeliveryFunc
+func (mock *WebhookManager) GetDelivery(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	if mock.GetDeliveryFunc == nil {
+		panic("WebhookManager.GetDelivery is not stubbed")
+	}
+	return mock.GetDeliveryFunc(ctx, tenantID, webhookID, deliveryID)
+}
+
+// GetWebhook calls GetWebhookFunc
+func (mock *WebhookManager) GetWebhook(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID) (*models.Webhook, error) {
+	if mock.GetWebhookFunc == nil {
+		panic("WebhookManager.GetWebhook is not stubbed")
+	}
+	return mock.GetWebhookFunc(ctx, tenantID, webhookID)
+}
+
+// ListDeliveries calls ListDeliveriesFunc
+func (mock *WebhookManager) ListDeliveries(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, status string, limit int, offset int) ([]models.WebhookDelivery, int, error) {
+	if mock.ListDeliveriesFunc == nil {
+		panic("WebhookManager.ListDeliveries is not stubbed")
+	}
+	return mock.ListDeliveriesFunc(ctx, tenantID, webhookID, status, limit, offset)
+}
+
+// ListWebhooks calls ListWebhooksFunc
+func (mock *WebhookManager) ListWebhooks(ctx context.Context, tenantID uuid.UUID, limit int, offset int) ([]models.Webhook, int, error) {
+	if mock.ListWebhooksFunc == nil {
+		panic("WebhookManager.ListWebhooks is not stubbed")
+	}
+	return mock.ListWebhooksFunc(ctx, tenantID, limit, offset)
+}
+
+// PostJSON calls PostJSONFunc
+func (mock *WebhookManager) PostJSON(ctx context.Context, rawURL string, payload interface{}) error {
+	if mock.PostJSONFunc == nil {
+		panic("WebhookManager.PostJSON is not stubbed")
+	}
+	return mock.PostJSONFunc(ctx, rawURL, payload)
+}
+
+// ProcessQueue calls ProcessQueueFunc
+func (mock *WebhookManager) ProcessQueue(ctx context.Context) (int, error) {
+	if mock.ProcessQueueFunc == nil {
+		panic("WebhookManager.ProcessQueue is not stubbed")
+	}
+	return mock.ProcessQueueFunc(ctx)
+}
+
+// RetryDelivery calls RetryDeliveryFunc
+func (mock *WebhookManager) RetryDelivery(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, deliveryID uuid.UUID) error {
+	if mock.RetryDeliveryFunc == nil {
+		panic("WebhookManager.RetryDelivery is not stubbed")
+	}
+	return mock.RetryDeliveryFunc(ctx, tenantID, webhookID, deliveryID)
+}
+
+// RotateSecret calls RotateSecretFunc
+func (mock *WebhookManager) RotateSecret(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID) (string, error) {
+	if mock.RotateSecretFunc == nil {
+		panic("WebhookManager.RotateSecret is not stubbed")
+	}
+	return mock.RotateSecretFunc(ctx, tenantID, webhookID)
+}
+
+// SendTestEvent calls SendTestEventFunc
+func (mock *WebhookManager) SendTestEvent(ctx context.Context, tenantID uuid.UUID, userID uuid.UUID, webhookID uuid.UUID) (*models.WebhookDelivery, error) {
+	if mock.SendTestEventFunc == nil {
+		panic("WebhookManager.SendTestEvent is not stubbed")
+	}
+	return mock.SendTestEventFunc(ctx, tenantID, userID, webhookID)
+}
+
+// UpdateWebhook calls UpdateWebhookFunc
+func (mock *WebhookManager) UpdateWebhook(ctx context.Context, tenantID uuid.UUID, webhookID uuid.UUID, req *models.WebhookUpdateRequest) (*models.Webhook, error) {
+	if mock.UpdateWebhookFunc == nil {
+		panic("WebhookManager.UpdateWebhook is not stubbed")
+	}
+	return mock.UpdateWebhookFunc(ctx, tenantID, webhookID, req)
+}
+
+// ValidateURL calls ValidateURLFunc
+func (mock *WebhookManager) ValidateURL(rawURL string) error {
+	if mock.ValidateURLFunc == nil {
+		panic("WebhookManager.ValidateURL is not stubbed")
+	}
+	return mock.ValidateURLFunc(rawURL)
+}
+
+var (
+	_ services.AccountingManager      = (*AccountingManager)(nil)
+	_ services.AnnouncementManager    = (*AnnouncementManager)(nil)
+	_ services.ApprovalLinkManager    = (*ApprovalLinkManager)(nil)
+	_ services.AsyncJobManager        = (*AsyncJobManager)(nil)
+	_ services.AuditManager           = (*AuditManager)(nil)
+	_ services.AuthManager            = (*AuthManager)(nil)
+	_ services.AutomationManager      = (*AutomationManager)(nil)
+	_ services.AvatarManager          = (*AvatarManager)(nil)
+	_ services.BillingManager         = (*BillingManager)(nil)
+	_ services.BroadcastManager       = (*BroadcastManager)(nil)
+	_ services.CRMManager             = (*CRMManager)(nil)
+	_ services.CompanySettingsManager = (*CompanySettingsManager)(nil)
+	_ services.ComplianceManager      = (*ComplianceManager)(nil)
+	_ services.CustomFieldManager     = (*CustomFieldManager)(nil)
+	_ services.DashboardManager       = (*DashboardManager)(nil)
+	_ services.DataQualityManager     = (*DataQualityManager)(nil)
+	_ services.DeletionManager        = (*DeletionManager)(nil)
+	_ services.EmailQueueManager      = (*EmailQueueManager)(nil)
+	_ services.EmailManager           = (*EmailManager)(nil)
+	_ services.EmployeeManager        = (*EmployeeManager)(nil)
+	_ services.EntitySchemaManager    = (*EntitySchemaManager)(nil)
+	_ services.EscalationManager      = (*EscalationManager)(nil)
+	_ services.FileManager            = (*FileManager)(nil)
+	_ services.InvitationManager      = (*InvitationManager)(nil)
+	_ services.JWTManager             = (*JWTManager)(nil)
+	_ services.LeaveManager           = (*LeaveManager)(nil)
+	_ services.MaintenanceManager     = (*MaintenanceManager)(nil)
+	_ services.MeteringManager        = (*MeteringManager)(nil)
+	_ services.NavigationManager      = (*NavigationManager)(nil)
+	_ services.NotificationManager    = (*NotificationManager)(nil)
+	_ services.OffboardingManager     = (*OffboardingManager)(nil)
+	_ services.PerformanceManager     = (*PerformanceManager)(nil)
+	_ services.PermissionManager      = (*PermissionManager)(nil)
+	_ services.PersonalDataManager    = (*PersonalDataManager)(nil)
+	_ services.PlatformManager        = (*PlatformManager)(nil)
+	_ services.PurchaseOrderManager   = (*PurchaseOrderManager)(nil)
+	_ services.QueueManager           = (*QueueManager)(nil)
+	_ services.QuotaManager           = (*QuotaManager)(nil)
+	_ services.SIEMManager            = (*SIEMManager)(nil)
+	_ services.SalesManager           = (*SalesManager)(nil)
+	_ services.SandboxManager         = (*SandboxManager)(nil)
+	_ services.ScheduledReportManager = (*ScheduledReportManager)(nil)
+	_ services.SearchManager          = (*SearchManager)(nil)
+	_ services.SessionManager         = (*SessionManager)(nil)
+	_ services.TenantDomainManager    = (*TenantDomainManager)(nil)
+	_ services.TenantSettingsManager  = (*TenantSettingsManager)(nil)
+	_ services.TimesheetManager       = (*TimesheetManager)(nil)
+	_ services.TwoFactorManager       = (*TwoFactorManager)(nil)
+	_ services.UsageManager           = (*UsageManager)(nil)
+	_ services.UserImportManager      = (*UserImportManager)(nil)
+	_ services.WatchManager           = (*WatchManager)(nil)
+	_ services.WebhookManager         = (*WebhookManager)(nil)
+)
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	recentViewsLimit     = 20                  // Entities kept per user
+	recentViewsTTL       = 30 * 24 * time.Hour // Idle lists are dropped from Redis; the saved copy remains
+	recentViewsFlushSize = 1000                // Max lists saved per flush
+	favoritesLimit       = 50                  // Max favorites per user
+)
+
+// recentViewsDirtyKey is the Redis set of lists changed since the last flush
+const recentViewsDirtyKey = "recent:dirty"
+
+// navigationType is an entity type that can be viewed and pinned
+type navigationType struct {
+	resource string // Permission resource whose view permission shows the entity
+	describe EntityDescriber
+}
+
+// NavigationService tracks the entities each user viewed recently and the
+// ones they pinned, for quick navigation. Recent views are a ring buffer per
+// user in Redis, saved to the database by the flush job (FlushRecent) so the
+// lists survive a Redis restart. Entities the user may no longer view, or
+// that were deleted, are left out of the lists.
+type NavigationService struct {
+	redis             *redis.Client
+	navigationRepo    repository.NavigationStore
+	permissionService PermissionManager
+	types             map[string]navigationType
+}
+
+// NewNavigationService creates a new navigation service
+func NewNavigationService(
+	redisClient *redis.Client,
+	navigationRepo repository.NavigationStore,
+	permissionService PermissionManager,
+) *NavigationService {
+	return &NavigationService{
+		redis:             redisClient,
+		navigationRepo:    navigationRepo,
+		permissionService: permissionService,
+		types:             make(map[string]navigationType),
+	}
+}
+
+// RegisterType registers an entity type that can be viewed and pinned
+func (s *NavigationService) RegisterType(entityType, resource string, describe EntityDescriber) {
+	s.types[entityType] = navigationType{resource: resource, describe: describe}
+}
+
+// EntityTypes lists the entity types that can be viewed and pinned
+func (s *NavigationService) EntityTypes() []string {
+	types := make([]string, 0, len(s.types))
+	for entityType := range s.types {
+		types = append(types, entityType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// recentViewsKey is the Redis sorted set of a user's recent views, scored by
+// the time of the view in milliseconds
+func recentViewsKey(tenantID, userID uuid.UUID) string {
+	return fmt.Sprintf("recent:%s:%s", tenantID, userID)
+}
+
+// RecordView moves an entity to the top of the user's recent views
+func (s *NavigationService) RecordView(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	if _, ok := s.types[entityType]; !ok {
+		return fmt.Errorf("unknown entity type: %s", entityType)
+	}
+
+	// Start from the saved list so the flush doesn't replace it with this
+	// one view
+	if _, err := s.loadRecent(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	key := recentViewsKey(tenantID, userID)
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  float64(time.Now().UnixMilli()),
+			Member: entityType + ":" + entityID.String(),
+		})
+		pipe.ZRemRangeByRank(ctx, key, 0, -recentViewsLimit-1)
+		pipe.Expire(ctx, key, recentViewsTTL)
+		pipe.SAdd(ctx, recentViewsDirtyKey, tenantID.String()+":"+userID.String())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+
+	return nil
+}
+
+// ListRecent retrieves the entities the user viewed recently, most recent
+// first
+func (s *NavigationService) ListRecent(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error) {
+	views, err := s.loadRecent(ctx, tenantID, userID)
+	if err != nil {
+		// The saved list is at most one flush behind
+		log.Printf("⚠️  Failed to read recent views from Redis, using saved list: %v", err)
+		if views, err = s.navigationRepo.ListRecent(ctx, tenantID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	visible := make([]models.RecentView, 0, len(views))
+	for _, view := range views {
+		summary, err := s.describe(ctx, tenantID, userID, view.EntityType, view.EntityID)
+		if err != nil || summary == nil {
+			continue
+		}
+		view.Entity = summary
+		visible = append(visible, view)
+	}
+
+	return visible, nil
+}
+
+// loadRecent reads a user's recent views from Redis, loading the saved list
+// into Redis first if it isn't there
+func (s *NavigationService) loadRecent(ctx context.Context, tenantID, userID uuid.UUID) ([]models.RecentView, error) {
+	key := recentViewsKey(tenantID, userID)
+	members, err := s.redis.ZRevRangeWithScores(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 0 {
+		return parseRecentViews(members), nil
+	}
+
+	views, err := s.navigationRepo.ListRecent(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(views) == 0 {
+		return views, nil
+	}
+
+	saved := make([]redis.Z, len(views))
+	for i, view := range views {
+		saved[i] = redis.Z{
+			Score:  float64(view.ViewedAt.UnixMilli()),
+			Member: view.EntityType + ":" + view.EntityID.String(),
+		}
+	}
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, saved...)
+		pipe.Expire(ctx, key, recentViewsTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+// parseRecentViews converts sorted set members to recent views. Members
+// that don't parse are skipped.
+func parseRecentViews(members []redis.Z) []models.RecentView {
+	views := make([]models.RecentView, 0, len(members))
+	for _, member := range members {
+		value, _ := member.Member.(string)
+		entityType, id, ok := strings.Cut(value, ":")
+		if !ok {
+			continue
+		}
+		entityID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		views = append(views, models.RecentView{
+			EntityType: entityType,
+			EntityID:   entityID,
+			ViewedAt:   time.UnixMilli(int64(member.Score)).UTC(),
+		})
+	}
+	return views
+}
+
+// FlushRecent saves the recent views lists changed since the last flush to
+// the database. Returns the number of lists saved.
+func (s *NavigationService) FlushRecent(ctx context.Context) (int, error) {
+	saved := 0
+	for saved < recentViewsFlushSize {
+		member, err := s.redis.SPop(ctx, recentViewsDirtyKey).Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return saved, fmt.Errorf("failed to read changed recent views: %w", err)
+		}
+
+		tenantPart, userPart, _ := strings.Cut(member, ":")
+		tenantID, err1 := uuid.Parse(tenantPart)
+		userID, err2 := uuid.Parse(userPart)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		members, err := s.redis.ZRevRangeWithScores(ctx, recentViewsKey(tenantID, userID), 0, -1).Result()
+		if err != nil || len(members) == 0 {
+			// Expired lists are already saved
+			continue
+		}
+
+		if err := s.navigationRepo.ReplaceRecent(ctx, tenantID, userID, parseRecentViews(members)); err != nil {
+			// Retry on the next flush
+			s.redis.SAdd(ctx, recentViewsDirtyKey, member)
+			return saved, err
+		}
+		saved++
+	}
+
+	return saved, nil
+}
+
+// ListFavorites retrieves the user's favorites in the order they were pinned.
+// Favorites whose entity was deleted have no entity summary, so they can
+// still be unpinned.
+func (s *NavigationService) ListFavorites(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Favorite, error) {
+	favorites, err := s.navigationRepo.ListFavorites(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.Favorite, 0, len(favorites))
+	for _, favorite := range favorites {
+		summary, err := s.describe(ctx, tenantID, userID, favorite.EntityType, favorite.EntityID)
+		if err == nil && summary == nil {
+			continue
+		}
+		favorite.Entity = summary
+		visible = append(visible, favorite)
+	}
+
+	return visible, nil
+}
+
+// AddFavorite pins an entity the user can view. Returns false if it was
+// already pinned.
+func (s *NavigationService) AddFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.Favorite, bool, error) {
+	t, ok := s.types[entityType]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown entity type: %s", entityType)
+	}
+
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, t.resource, models.ActionView)
+	if err != nil {
+		return nil, false, err
+	}
+	if !allowed {
+		return nil, false, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions to view entity")
+	}
+
+	summary, err := t.describe(ctx, tenantID, entityID)
+	if err != nil {
+		return nil, false, utils.NewNotFoundError("ENTITY_NOT_FOUND", "entity not found")
+	}
+
+	count, err := s.navigationRepo.CountFavorites(ctx, tenantID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if count >= favoritesLimit {
+		return nil, false, fmt.Errorf("favorite limit reached")
+	}
+
+	favorite := &models.Favorite{
+		TenantID:   tenantID,
+		UserID:     userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Entity:     summary,
+	}
+	created, err := s.navigationRepo.AddFavorite(ctx, favorite)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return favorite, created, nil
+}
+
+// RemoveFavorite unpins an entity
+func (s *NavigationService) RemoveFavorite(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	return s.navigationRepo.RemoveFavorite(ctx, tenantID, userID, entityType, entityID)
+}
+
+// describe summarizes an entity for the user. Returns nil without an error
+// if the user may not view entities of its type.
+func (s *NavigationService) describe(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.EntitySummary, error) {
+	t, ok := s.types[entityType]
+	if !ok {
+		return nil, nil
+	}
+
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, t.resource, models.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, nil
+	}
+
+	return t.describe(ctx, tenantID, entityID)
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const notificationEventsKeyPrefix = "notifications:events"
+
+// Notification stream events
+const (
+	NotificationEventCreated     = "notification" // A new notification, with the unread count
+	NotificationEventUnreadCount = "unread_count" // Notifications were read or unread elsewhere
+)
+
+// NotificationEvent is what a user's notification stream receives
+type NotificationEvent struct {
+	Event        string               `json:"event"`
+	Notification *models.Notification `json:"notification,omitempty"`
+	UnreadCount  int                  `json:"unread_count"`
+}
+
+// NotificationService keeps users' notifications. Other services call Notify;
+// each user gets the notification on the channels they chose for its
+// category. In-app notifications are saved, then published over Redis
+// pub/sub to the user's open streams on any replica. Security alerts are
+// emailed right away, other categories in the user's daily or weekly digest.
+type NotificationService struct {
+	db               *sqlx.DB
+	notificationRepo repository.NotificationStore
+	userRepo         repository.UserStore
+	tenantRepo       repository.TenantStore
+	emailService     EmailManager
+	emailQueue       EmailQueueManager
+	redis            *redis.Client
+	config           *config.NotificationConfig
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	db *sqlx.DB,
+	notificationRepo repository.NotificationStore,
+	userRepo repository.UserStore,
+	tenantRepo repository.TenantStore,
+	emailService EmailManager,
+	emailQueue EmailQueueManager,
+	redisClient *redis.Client,
+	cfg *config.NotificationConfig,
+) *NotificationService {
+	return &NotificationService{
+		db:               db,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		tenantRepo:       tenantRepo,
+		emailService:     emailService,
+		emailQueue:       emailQueue,
+		redis:            redisClient,
+		config:           cfg,
+	}
+}
+
+// Notify notifies each user on the channels they get the notification's
+// category on
+func (s *NotificationService) Notify(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	prefs, err := s.notificationRepo.ListPreferences(ctx, tenantID, userIDs)
+	if err != nil {
+		return err
+	}
+	category := models.NotificationCategoryOf(req.Type)
+	urgent := models.IsUrgentNotificationCategory(category)
+
+	data := json.RawMessage("{}")
+	if req.Data != nil {
+		encoded, err := json.Marshal(req.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification data: %w", err)
+		}
+		data = encoded
+	}
+	var link *string
+	if req.Link != "" {
+		link = &req.Link
+	}
+
+	notifications := make([]*models.Notification, 0, len(userIDs))
+	emailNow := []*models.Notification{}
+	for _, userID := range userIDs {
+		userPrefs := s.preferencesOf(tenantID, userID, prefs[userID])
+		channels := userPrefs.ChannelsFor(category)
+
+		n := &models.Notification{
+			UserID:        userID,
+			Type:          req.Type,
+			Title:         req.Title,
+			Body:          req.Body,
+			Link:          link,
+			Data:          data,
+			InApp:         channels.InApp,
+			DigestPending: channels.Email && !urgent && userPrefs.DigestPeriod() > 0,
+		}
+		if channels.Email && urgent {
+			emailNow = append(emailNow, n)
+		}
+		if n.InApp || n.DigestPending {
+			notifications = append(notifications, n)
+		}
+	}
+
+	if len(notifications) > 0 {
+		if err := s.notificationRepo.Create(ctx, tenantID, notifications); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range notifications {
+		if n.InApp {
+			s.publish(ctx, tenantID, n)
+		}
+	}
+	if len(emailNow) > 0 {
+		s.email(ctx, tenantID, emailNow)
+	}
+
+	return nil
+}
+
+// GetPreferences returns a user's notification preferences, the defaults
+// when they never changed them
+func (s *NotificationService) GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	prefs, err := s.notificationRepo.GetPreferences(ctx, tenantID, userID)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return nil, err
+	}
+	return s.preferencesOf(tenantID, userID, prefs), nil
+}
+
+// UpdatePreferences changes the channels of the categories in the request
+// and the digest frequency; the rest is kept
+func (s *NotificationService) UpdatePreferences(ctx context.Context, tenantID, userID uuid.UUID, req *models.NotificationPreferencesUpdateRequest) (*models.NotificationPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for category, channels := range req.Channels {
+		prefs.Channels[category] = channels
+	}
+	if req.Digest != nil {
+		prefs.Digest = *req.Digest
+	}
+
+	if err := s.notificationRepo.SavePreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SendDigests emails the digest of every user whose daily or weekly digest is
+// due and who has unread notifications waiting for it
+func (s *NotificationService) SendDigests(ctx context.Context) (int, error) {
+	tenants, err := s.tenantRepo.ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range tenants {
+		if err := ctx.Err(); err != nil {
+			return sent, nil
+		}
+
+		n, err := s.sendTenantDigests(ctx, &tenants[i])
+		sent += n
+		if err != nil {
+			log.Printf("⚠️  Notification digests of tenant %s failed: %v", tenants[i].ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// sendTenantDigests emails the digests due in a tenant
+func (s *NotificationService) sendTenantDigests(ctx context.Context, tenant *models.Tenant) (int, error) {
+	userIDs, err := s.notificationRepo.ListDigestRecipients(ctx, tenant.ID)
+	if err != nil || len(userIDs) == 0 {
+		return 0, err
+	}
+	prefs, err := s.notificationRepo.ListPreferences(ctx, tenant.ID, userIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	now := time.Now()
+	for _, userID := range userIDs {
+		userPrefs := s.preferencesOf(tenant.ID, userID, prefs[userID])
+
+		// Digests go out on a schedule, so allow for the job running a
+		// little earlier than a period after the last one
+		period := userPrefs.DigestPeriod()
+		if period > 0 && userPrefs.LastDigestAt != nil && now.Sub(*userPrefs.LastDigestAt) < period-digestScheduleSlack {
+			continue
+		}
+
+		emailed, err := s.sendDigest(ctx, tenant, userPrefs, now)
+		if err != nil {
+			return sent, err
+		}
+		if emailed {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// digestScheduleSlack is how much earlier than its period a digest may go out
+const digestScheduleSlack = time.Hour
+
+// sendDigest emails a user's digest and clears the notifications it covers.
+// Nothing is emailed when the notifications were read meanwhile, the user
+// turned the digest off, or is no longer active.
+func (s *NotificationService) sendDigest(ctx context.Context, tenant *models.Tenant, prefs *models.NotificationPreferences, now time.Time) (bool, error) {
+	user, err := s.userRepo.FindByID(ctx, tenant.ID, prefs.UserID)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return false, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenant.ID)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	notifications, err := s.notificationRepo.ListDigestPending(ctx, tx.Tx, tenant.ID, prefs.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	emailed := false
+	if len(notifications) > 0 && prefs.DigestPeriod() > 0 && user != nil && user.IsActive() {
+		msg, err := s.emailService.NotificationDigestEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, prefs.Digest, notifications)
+		if err != nil {
+			return false, fmt.Errorf("failed to render digest email: %w", err)
+		}
+		if err := s.emailQueue.Enqueue(ctx, tx.Tx, tenant.ID, msg); err != nil {
+			return false, err
+		}
+		emailed = true
+	}
+
+	if err := s.notificationRepo.FinishDigest(ctx, tx.Tx, prefs, now); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to send digest: %w", err)
+	}
+
+	return emailed, nil
+}
+
+// preferencesOf completes a user's saved preferences with the defaults, or
+// returns the defaults when they have none
+func (s *NotificationService) preferencesOf(tenantID, userID uuid.UUID, prefs *models.NotificationPreferences) *models.NotificationPreferences {
+	if prefs == nil {
+		return models.DefaultNotificationPreferences(tenantID, userID, s.config.DefaultDigest)
+	}
+	prefs.FillDefaults()
+	return prefs
+}
+
+// email sends notifications by email right away. Delivery is best effort:
+// the notifications are already saved.
+func (s *NotificationService) email(ctx context.Context, tenantID uuid.UUID, notifications []*models.Notification) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		log.Printf("⚠️  Failed to email notifications of tenant %s: %v", tenantID, err)
+		return
+	}
+
+	for _, n := range notifications {
+		user, err := s.userRepo.FindByID(ctx, tenantID, n.UserID)
+		if err != nil || !user.IsActive() {
+			continue
+		}
+
+		msg, err := s.emailService.NotificationEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, n)
+		if err == nil {
+			err = s.emailQueue.EnqueueStandalone(ctx, tenantID, msg)
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to email %s notification to user %s: %v", n.Type, n.UserID, err)
+		}
+	}
+}
+
+// List retrieves a user's notifications, newest first
+func (s *NotificationService) List(ctx context.Context, tenantID, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int, error) {
+	return s.notificationRepo.List(ctx, tenantID, userID, unreadOnly, limit, offset)
+}
+
+// CountUnread counts a user's unread notifications
+func (s *NotificationService) CountUnread(ctx context.Context, tenantID, userID uuid.UUID) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, tenantID, userID)
+}
+
+// MarkRead marks a user's notifications read, or all of them when ids is
+// empty, and returns the unread count left
+func (s *NotificationService) MarkRead(ctx context.Context, tenantID, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	marked, err := s.notificationRepo.MarkRead(ctx, tenantID, userID, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(ctx, tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if marked > 0 {
+		s.publishCount(ctx, tenantID, userID, unread)
+	}
+
+	return unread, nil
+}
+
+// MarkUnread marks one of a user's notifications unread again and returns
+// the unread count
+func (s *NotificationService) MarkUnread(ctx context.Context, tenantID, userID, id uuid.UUID) (int, error) {
+	if err := s.notificationRepo.MarkUnread(ctx, tenantID, userID, id); err != nil {
+		return 0, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(ctx, tenantID, userID)
+	if err != nil {
+		return 0, err
+	}
+	s.publishCount(ctx, tenantID, userID, unread)
+
+	return unread, nil
+}
+
+// Subscribe listens for a user's notification events. Each message is a
+// NotificationEvent encoded as JSON.
+func (s *NotificationService) Subscribe(ctx context.Context, tenantID, userID uuid.UUID) *redis.PubSub {
+	return s.redis.Subscribe(ctx, notificationEventsChannel(tenantID, userID))
+}
+
+// CleanupRead removes notifications read longer ago than the retention
+// period, in every data region
+func (s *NotificationService) CleanupRead(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Retention)
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.notificationRepo.DeleteReadBefore(ctx, db, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// publish sends a new notification and the user's unread count to their
+// streams. Delivery is best effort; clients can always fall back to
+// GET /notifications.
+func (s *NotificationService) publish(ctx context.Context, tenantID uuid.UUID, n *models.Notification) {
+	unread, err := s.notificationRepo.CountUnread(ctx, tenantID, n.UserID)
+	if err != nil {
+		log.Printf("⚠️  Failed to count unread notifications of user %s: %v", n.UserID, err)
+		return
+	}
+	s.send(ctx, tenantID, n.UserID, &NotificationEvent{Event: NotificationEventCreated, Notification: n, UnreadCount: unread})
+}
+
+// publishCount sends the user's unread count to their streams
+func (s *NotificationService) publishCount(ctx context.Context, tenantID, userID uuid.UUID, unread int) {
+	s.send(ctx, tenantID, userID, &NotificationEvent{Event: NotificationEventUnreadCount, UnreadCount: unread})
+}
+
+// send publishes an event to the user's streams
+func (s *NotificationService) send(ctx context.Context, tenantID, userID uuid.UUID, event *NotificationEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := s.redis.Publish(ctx, notificationEventsChannel(tenantID, userID), payload).Err(); err != nil {
+		log.Printf("⚠️  Failed to publish notification to user %s: %v", userID, err)
+	}
+}
+
+// notificationEventsChannel is the Redis channel a user's notification
+// events are published on
+func notificationEventsChannel(tenantID, userID uuid.UUID) string {
+	return database.CacheKey(notificationEventsKeyPrefix, tenantID.String(), userID.String())
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
+)
+
+// objectACLKeyPrefix caches records' ACLs, including the unrestricted default
+// of records without one
+const objectACLKeyPrefix = "object_acl:"
+
+// CanAccessObject checks if a user may perform an action on a single record.
+// The user needs the resource permission of the action and, if the record is
+// restricted, must be its owner, have been granted the action directly or
+// through a role, or be a tenant owner or administrator.
+func (s *PermissionService) CanAccessObject(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, action string) (bool, error) {
+	allowed, err := s.HasPermission(ctx, tenantID, userID, resourceType, action)
+	if err != nil || !allowed {
+		return false, err
+	}
+
+	acl, err := s.GetObjectACL(ctx, tenantID, resourceType, resourceID)
+	if err != nil {
+		return false, err
+	}
+	if !acl.Restricted {
+		return true, nil
+	}
+
+	roles, err := s.userRoleRepo.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	return objectACLAllows(acl, userID, roles, action), nil
+}
+
+// InaccessibleObjects returns the IDs of the restricted records of a
+// resource type the user may not perform an action on, to leave out of lists.
+// It does not check the resource permission itself.
+func (s *PermissionService) InaccessibleObjects(ctx context.Context, tenantID, userID uuid.UUID, resourceType, action string) (map[uuid.UUID]bool, error) {
+	acls, err := s.objectACLRepo.ListRestricted(ctx, tenantID, resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := map[uuid.UUID]bool{}
+	if len(acls) == 0 {
+		return hidden, nil
+	}
+
+	roles, err := s.userRoleRepo.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	for i := range acls {
+		if !objectACLAllows(&acls[i], userID, roles, action) {
+			hidden[acls[i].ResourceID] = true
+		}
+	}
+
+	return hidden, nil
+}
+
+// GetObjectACL retrieves a record's ACL (with caching). Records without one
+// get an unrestricted ACL without an owner.
+func (s *PermissionService) GetObjectACL(ctx context.Context, tenantID uuid.UUID, resourceType string, resourceID uuid.UUID) (*models.ObjectACL, error) {
+	// Try cache first
+	cacheKey := database.CacheKey(objectACLKeyPrefix, tenantID.String(), resourceType, resourceID.String())
+	if cachedData, err := s.redis.Get(ctx, cacheKey).Result(); err == nil {
+		var acl models.ObjectACL
+		if err := json.Unmarshal([]byte(cachedData), &acl); err == nil {
+			return &acl, nil
+		}
+	}
+
+	// Cache miss - query database
+	acl, err := s.objectACLRepo.Find(ctx, tenantID, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if acl == nil {
+		acl = &models.ObjectACL{ResourceType: resourceType, ResourceID: resourceID, Grants: []models.ObjectGrant{}}
+	}
+
+	// Cache the result
+	data, _ := json.Marshal(acl)
+	s.redis.Set(ctx, cacheKey, data, permissionCacheTTL)
+
+	return acl, nil
+}
+
+// SetObjectACL replaces a record's ACL. Without an owner in the request, the
+// current owner is kept, or the user becomes it. Callers check the user may
+// edit the record.
+func (s *PermissionService) SetObjectACL(ctx context.Context, tenantID, userID uuid.UUID, resourceType string, resourceID uuid.UUID, req *models.ObjectACLUpdateRequest) (*models.ObjectACL, error) {
+	current, err := s.GetObjectACL(ctx, tenantID, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	acl := &models.ObjectACL{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OwnerID:      req.OwnerID,
+		Restricted:   req.Restricted,
+		Grants:       []models.ObjectGrant{},
+	}
+	if acl.OwnerID == nil {
+		acl.OwnerID = current.OwnerID
+	}
+	if acl.OwnerID == nil {
+		acl.OwnerID = &userID
+	}
+	if *acl.OwnerID != userID {
+		if _, err := s.userRepo.FindByID(ctx, tenantID, *acl.OwnerID); err != nil {
+			return nil, utils.NewNotFoundError("OWNER_NOT_FOUND", "owner not found")
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, grant := range req.Grants {
+		if (grant.UserID == nil) == (grant.RoleID == nil) {
+			return nil, fmt.Errorf("each grant needs either a user or a role")
+		}
+		if !slices.Contains(models.ObjectGrantActions, grant.Action) {
+			return nil, fmt.Errorf("invalid grant action")
+		}
+
+		var key string
+		if grant.UserID != nil {
+			if _, err := s.userRepo.FindByID(ctx, tenantID, *grant.UserID); err != nil {
+				return nil, utils.NewNotFoundError("USER_NOT_FOUND", "grant user not found")
+			}
+			key = "user:" + grant.UserID.String()
+		} else {
+			if _, err := s.roleRepo.FindByID(ctx, tenantID, *grant.RoleID); err != nil {
+				return nil, utils.NewNotFoundError("ROLE_NOT_FOUND", "grant role not found")
+			}
+			key = "role:" + grant.RoleID.String()
+		}
+		if key += ":" + grant.Action; seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		acl.Grants = append(acl.Grants, models.ObjectGrant{UserID: grant.UserID, RoleID: grant.RoleID, Action: grant.Action})
+	}
+
+	if err := s.objectACLRepo.Save(ctx, tenantID, acl, userID); err != nil {
+		return nil, err
+	}
+
+	cacheKey := database.CacheKey(objectACLKeyPrefix, tenantID.String(), resourceType, resourceID.String())
+	s.redis.Del(ctx, cacheKey)
+
+	return acl, nil
+}
+
+// objectACLAllows checks if a restricted record's ACL lets a user with roles
+// perform an action
+func objectACLAllows(acl *models.ObjectACL, userID uuid.UUID, roles []models.Role, action string) bool {
+	if acl.OwnerID != nil && *acl.OwnerID == userID {
+		return true
+	}
+
+	roleIDs := make(map[uuid.UUID]bool, len(roles))
+	for _, role := range roles {
+		if role.Name == models.RoleOwner || role.Name == models.RoleAdmin {
+			return true
+		}
+		roleIDs[role.ID] = true
+	}
+
+	for _, grant := range acl.Grants {
+		if !grant.Covers(action) {
+			continue
+		}
+		if (grant.UserID != nil && *grant.UserID == userID) || (grant.RoleID != nil && roleIDs[*grant.RoleID]) {
+			return true
+		}
+	}
+	return false
+}
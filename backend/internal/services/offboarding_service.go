@@ -0,0 +1,413 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const tenantPurgeBatch = 5 // Max tenants deleted per purge run
+
+// tenantExportFileColumns are the columns of files.csv in a tenant export;
+// the files' contents are not included
+var tenantExportFileColumns = []string{
+	"id", "original_name", "content_type", "size_bytes", "checksum_sha256", "category",
+	"resource_type", "resource_id", "uploaded_by", "created_at", "deleted_at",
+}
+
+// OffboardingService handles tenants leaving the platform. Owners can export
+// all of the tenant's data as an archive, built by an async job like the
+// compliance packages, and delete the tenant: the deletion is confirmed
+// through an emailed link, then carried out by a background job once the
+// grace period has passed unless an owner cancels it first.
+type OffboardingService struct {
+	db                *sqlx.DB
+	tenantRepo        repository.TenantStore
+	userRepo          repository.UserStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	departmentRepo    repository.DepartmentStore
+	auditService      AuditManager
+	fileService       FileManager
+	sandboxService    SandboxManager
+	asyncJobService   AsyncJobManager
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	config            *config.Config
+}
+
+// NewOffboardingService creates a new offboarding service
+func NewOffboardingService(
+	db *sqlx.DB,
+	tenantRepo repository.TenantStore,
+	userRepo repository.UserStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	departmentRepo repository.DepartmentStore,
+	auditService AuditManager,
+	fileService FileManager,
+	sandboxService SandboxManager,
+	asyncJobService AsyncJobManager,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	cfg *config.Config,
+) *OffboardingService {
+	return &OffboardingService{
+		db:                db,
+		tenantRepo:        tenantRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		departmentRepo:    departmentRepo,
+		auditService:      auditService,
+		fileService:       fileService,
+		sandboxService:    sandboxService,
+		asyncJobService:   asyncJobService,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		config:            cfg,
+	}
+}
+
+// RequestExport queues the export of all of the tenant's data
+func (s *OffboardingService) RequestExport(ctx context.Context, tenantID, userID uuid.UUID) (*models.AsyncJob, error) {
+	return s.asyncJobService.Submit(ctx, tenantID, userID, models.JobTypeTenantExport, struct{}{})
+}
+
+// GetExport retrieves an export job the user may follow. Its result is the
+// stored archive once it has succeeded.
+func (s *OffboardingService) GetExport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	job, err := s.asyncJobService.GetJob(ctx, tenantID, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.JobType != models.JobTypeTenantExport {
+		return nil, utils.NewNotFoundError("JOB_NOT_FOUND", "job not found")
+	}
+	return job, nil
+}
+
+// RunExportJob builds and stores the archive of a tenant's data (the
+// tenant_export job type)
+func (s *OffboardingService) RunExportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, job.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The archive is built on disk: years of audit logs can be large
+	tmp, err := os.CreateTemp("", "tenant-export-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	records, err := s.export(ctx, tenant, tmp, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 95, "Storing the archive"); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	resourceType := models.FileResourceTenantExport
+	file, err := s.fileService.StoreGenerated(ctx, job.TenantID, job.RequestedBy, &models.FileUpload{
+		OriginalName: fmt.Sprintf("%s-export-%s.zip", tenant.Slug, time.Now().UTC().Format("2006-01-02")),
+		Size:         size,
+		Category:     models.FileCategoryArtifact,
+		ResourceType: &resourceType,
+		ResourceID:   &job.ID,
+	}, "application/zip", tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TenantExport{
+		File:        *file,
+		DownloadURL: s.fileService.DownloadURL(file),
+		Records:     records,
+	}, nil
+}
+
+// export writes the archive to w and returns the number of records of each
+// file in it
+func (s *OffboardingService) export(ctx context.Context, tenant *models.Tenant, w io.Writer, progress *JobProgress) (map[string]int, error) {
+	generatedAt := time.Now().UTC()
+	archive := &complianceArchive{zip: zip.NewWriter(w), modified: generatedAt}
+	records := map[string]int{}
+
+	if err := progress.Update(ctx, 0, "Exporting the organization"); err != nil {
+		return nil, err
+	}
+	if err := archive.json("tenant.json", tenant); err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 5, "Exporting users"); err != nil {
+		return nil, err
+	}
+	err := archive.csv("users.csv", models.UserExportColumns, func(write func([]string) error) error {
+		return s.userRepo.StreamForExport(ctx, tenant.ID, func(user *models.UserExport) error {
+			records["users.csv"]++
+			return write([]string{
+				utils.ExportValue(user.ID),
+				user.Email,
+				user.FirstName,
+				user.LastName,
+				utils.ExportValue(user.Phone),
+				utils.ExportValue(user.Roles),
+				utils.ExportValue(user.Department),
+				user.Status,
+				utils.ExportValue(user.EmailVerified),
+				utils.ExportValue(user.TwoFactorEnabled),
+				utils.ExportValue(user.LastLoginAt),
+				utils.ExportValue(user.CreatedAt),
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 20, "Exporting roles and departments"); err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.ListWithDetails(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	records["roles.json"] = len(roles)
+	if err := archive.json("roles.json", roles); err != nil {
+		return nil, err
+	}
+	departments, err := s.departmentRepo.List(ctx, tenant.ID, models.DepartmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+	records["departments.json"] = len(departments)
+	if err := archive.json("departments.json", departments); err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 30, "Exporting audit logs"); err != nil {
+		return nil, err
+	}
+	err = archive.csv("audit_logs.csv", models.AuditLogExportColumns, func(write func([]string) error) error {
+		return s.auditService.Export(ctx, tenant.ID, AuditFilters{}, func(log *models.AuditLog) error {
+			records["audit_logs.csv"]++
+			return write(complianceAuditRow(log))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := progress.Update(ctx, 85, "Exporting files"); err != nil {
+		return nil, err
+	}
+	files, err := s.fileService.ListTenantFiles(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	records["files.csv"] = len(files)
+	err = archive.csv("files.csv", tenantExportFileColumns, func(write func([]string) error) error {
+		for _, file := range files {
+			if err := write([]string{
+				utils.ExportValue(file.ID),
+				file.OriginalName,
+				file.ContentType,
+				utils.ExportValue(file.SizeBytes),
+				file.ChecksumSHA256,
+				file.Category,
+				utils.ExportValue(file.ResourceType),
+				utils.ExportValue(file.ResourceID),
+				utils.ExportValue(file.UploadedBy),
+				utils.ExportValue(file.CreatedAt),
+				utils.ExportValue(file.DeletedAt),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := archive.json("manifest.json", map[string]interface{}{
+		"tenant_id":    tenant.ID,
+		"generated_at": generatedAt,
+		"files":        archive.files,
+	}); err != nil {
+		return nil, err
+	}
+	if err := archive.zip.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write export archive: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetDeletion retrieves the tenant's requested deletion, nil when there is
+// none
+func (s *OffboardingService) GetDeletion(ctx context.Context, tenantID uuid.UUID) (*models.TenantDeletion, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return tenant.Deletion(), nil
+}
+
+// RequestDeletion starts the deletion of the tenant by an owner, who must
+// enter their password again. The deletion only takes effect once confirmed
+// through the link emailed to them.
+func (s *OffboardingService) RequestDeletion(ctx context.Context, tenantID, userID uuid.UUID, password string) (*models.TenantDeletion, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.DeletionScheduledAt != nil {
+		return nil, fmt.Errorf("tenant deletion already scheduled")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	// Users signing in with single sign-on have no password to confirm
+	if user.PasswordHash != "" && !utils.VerifyPassword(password, user.PasswordHash) {
+		return nil, utils.NewBadRequestError("PASSWORD_INCORRECT", "password is incorrect")
+	}
+
+	token := uuid.New()
+	expiresAt := time.Now().Add(s.config.Deletion.TenantConfirmationExpiry)
+	if err := s.tenantRepo.RequestDeletion(ctx, tenantID, userID, token, expiresAt); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.emailService.TenantDeletionConfirmEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, tenant.Branding(), token, expiresAt, s.config.Deletion.TenantGracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render tenant deletion email: %w", err)
+	}
+	if err := s.emailQueueService.EnqueueStandalone(ctx, tenantID, msg); err != nil {
+		return nil, fmt.Errorf("failed to queue tenant deletion email: %w", err)
+	}
+
+	return &models.TenantDeletion{
+		Status:      models.TenantDeletionPendingConfirmation,
+		RequestedAt: time.Now().UTC(),
+		RequestedBy: &userID,
+		ExpiresAt:   &expiresAt,
+	}, nil
+}
+
+// ConfirmDeletion schedules the tenant's deletion with the emailed token,
+// after the grace period, and notifies its owners
+func (s *OffboardingService) ConfirmDeletion(ctx context.Context, tenantID uuid.UUID, token uuid.UUID) (*models.TenantDeletion, error) {
+	scheduledAt := time.Now().Add(s.config.Deletion.TenantGracePeriod)
+	if err := s.tenantRepo.ConfirmDeletion(ctx, tenantID, token, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every owner can cancel, so every owner is told
+	if err := s.notifyOwners(ctx, tenant, scheduledAt); err != nil {
+		log.Printf("⚠️  Failed to notify the owners of tenant %s of its scheduled deletion: %v", tenantID, err)
+	}
+
+	return tenant.Deletion(), nil
+}
+
+// CancelDeletion cancels the tenant's requested or scheduled deletion
+func (s *OffboardingService) CancelDeletion(ctx context.Context, tenantID uuid.UUID) error {
+	return s.tenantRepo.CancelDeletion(ctx, tenantID)
+}
+
+// notifyOwners queues the notification of a scheduled deletion to each
+// active owner of the tenant
+func (s *OffboardingService) notifyOwners(ctx context.Context, tenant *models.Tenant, scheduledAt time.Time) error {
+	role, err := s.roleRepo.FindByName(ctx, tenant.ID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+	users, err := s.userRoleRepo.GetUsersByRole(ctx, tenant.ID, role.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if !user.IsActive() {
+			continue
+		}
+		msg, err := s.emailService.TenantDeletionScheduledEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, tenant.Branding(), scheduledAt)
+		if err != nil {
+			return err
+		}
+		if err := s.emailQueueService.EnqueueStandalone(ctx, tenant.ID, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeDue permanently deletes the tenants whose grace period has passed:
+// their sandboxes, stored files and every row of theirs, in their data
+// region and in the catalog. It is run periodically by the background job
+// runner and returns the number of tenants deleted; a tenant that fails is
+// retried on the next run.
+func (s *OffboardingService) PurgeDue(ctx context.Context) (int, error) {
+	tenantIDs, err := s.tenantRepo.ListDueForDeletion(ctx, tenantPurgeBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, tenantID := range tenantIDs {
+		if err := s.purge(ctx, tenantID); err != nil {
+			log.Printf("⚠️  Failed to delete tenant %s: %v", tenantID, err)
+			continue
+		}
+		log.Printf("🗑️  Deleted tenant %s at the end of its grace period", tenantID)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purge deletes a tenant and everything it owns. The tenant row goes last so
+// that a failed run leaves it scheduled and is retried.
+func (s *OffboardingService) purge(ctx context.Context, tenantID uuid.UUID) error {
+	if err := s.sandboxService.RemoveTenantSandboxes(ctx, tenantID); err != nil {
+		return err
+	}
+	if err := s.fileService.RemoveTenantFiles(ctx, tenantID); err != nil {
+		return err
+	}
+	return database.PurgeTenant(ctx, s.db, tenantID)
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	oidcResponseSize    = 1 << 20         // Largest discovery, key set or token response read
+	oidcKeyRefreshDelay = 1 * time.Minute // Unknown key IDs refetch the key set at most this often
+	oidcClockSkew       = 1 * time.Minute // Allowed difference between our clock and the provider's
+)
+
+// oidcSigningMethods are the ID token algorithms accepted. Symmetric
+// algorithms are refused: the client secret must not be usable to forge
+// tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384"}
+
+// oidcDiscovery is the part of a provider's discovery document used to sign
+// users on
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcClaims are the ID token claims used to link and provision users
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string   `json:"azp,omitempty"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   oidcBool `json:"email_verified"`
+	GivenName       string   `json:"given_name"`
+	FamilyName      string   `json:"family_name"`
+	Name            string   `json:"name"`
+}
+
+// oidcBool is a boolean claim some providers send as a string
+type oidcBool bool
+
+// UnmarshalJSON accepts true, false, "true" and "false"
+func (b *oidcBool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	*b = oidcBool(value == "true")
+	return nil
+}
+
+// oidcKey is a JSON Web Key of a provider's key set
+type oidcKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+type cachedDiscovery struct {
+	doc       *oidcDiscovery
+	fetchedAt time.Time
+}
+
+type cachedKeys struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// oidcClient speaks the OpenID Connect authorization code flow with tenant
+// configured providers. Discovery documents and signing keys are cached;
+// requests to loopback and private addresses are refused unless insecure
+// issuers are allowed, as issuers are entered by tenant admins.
+type oidcClient struct {
+	config *config.SSOConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery map[string]cachedDiscovery // By issuer
+	keys      map[string]cachedKeys      // By key set URL
+}
+
+// newOIDCClient creates an OpenID Connect client
+func newOIDCClient(cfg *config.SSOConfig) *oidcClient {
+	dialer := &net.Dialer{Timeout: cfg.HTTPTimeout}
+	if !cfg.AllowInsecureIssuers {
+		// Checked on the resolved address, so DNS cannot point an issuer at
+		// internal services
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateWebhookIP(ip) {
+				return fmt.Errorf("identity provider resolves to a private address")
+			}
+			return nil
+		}
+	}
+
+	return &oidcClient{
+		config: cfg,
+		client: &http.Client{
+			Timeout:   cfg.HTTPTimeout,
+			Transport: metrics.Transport("identity_provider", &http.Transport{DialContext: dialer.DialContext, Proxy: http.ProxyFromEnvironment}),
+		},
+		discovery: make(map[string]cachedDiscovery),
+		keys:      make(map[string]cachedKeys),
+	}
+}
+
+// validateURL checks that a provider URL may be requested. HTTPS is required
+// unless insecure issuers are allowed (development).
+func (c *oidcClient) validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("must be an absolute URL")
+	}
+	if parsed.Scheme != "https" && (parsed.Scheme != "http" || !c.config.AllowInsecureIssuers) {
+		return fmt.Errorf("must use https")
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("must not contain credentials")
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("must not contain a query or fragment")
+	}
+	return nil
+}
+
+// discover retrieves an issuer's discovery document
+func (c *oidcClient) discover(ctx context.Context, issuer string) (*oidcDiscovery, error) {
+	c.mu.Lock()
+	cached, ok := c.discovery[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < c.config.MetadataCacheTTL {
+		return cached.doc, nil
+	}
+
+	if err := c.validateURL(issuer); err != nil {
+		return nil, fmt.Errorf("invalid issuer: %s", err)
+	}
+
+	var doc oidcDiscovery
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("issuer discovery failed: %w", err)
+	}
+
+	// The document must be the issuer's own, or tokens could be accepted
+	// from another issuer
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("issuer discovery failed: document is for issuer %q", doc.Issuer)
+	}
+	for _, endpoint := range []string{doc.AuthorizationEndpoint, doc.TokenEndpoint, doc.JWKSURI} {
+		if err := c.validateURL(endpoint); err != nil {
+			return nil, fmt.Errorf("issuer discovery failed: endpoint %q %s", endpoint, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.discovery[issuer] = cachedDiscovery{doc: &doc, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	return &doc, nil
+}
+
+// authorizationURL builds the URL users are sent to to sign in, with a PKCE
+// challenge derived from verifier
+func (c *oidcClient) authorizationURL(doc *oidcDiscovery, clientID, scopes, redirectURI, state, nonce, verifier string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", scopes)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// exchangeCode redeems an authorization code at the token endpoint and
+// returns the ID token. Public clients have no secret.
+func (c *oidcClient) exchangeCode(ctx context.Context, doc *oidcDiscovery, clientID, clientSecret, code, redirectURI, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	if clientSecret == "" {
+		form.Set("client_id", clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("token request failed: status %d", resp.StatusCode)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("token request failed: %s %s", body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("token request failed: status %d without an ID token", resp.StatusCode)
+	}
+
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks an ID token's signature, issuer, audience, expiry and
+// nonce, and returns its claims
+func (c *oidcClient) verifyIDToken(ctx context.Context, doc *oidcDiscovery, clientID, rawToken, nonce string) (*oidcClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+
+	claims := &oidcClaims{}
+	_, err := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return c.signingKey(ctx, doc.JWKSURI, keyID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("invalid id token: nonce mismatch")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != clientID {
+		return nil, fmt.Errorf("invalid id token: issued to another client")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("invalid id token: no subject")
+	}
+
+	return claims, nil
+}
+
+// signingKey returns a key of the provider's key set. The set is refetched
+// when the key is unknown, as providers rotate their keys.
+func (c *oidcClient) signingKey(ctx context.Context, jwksURI, keyID string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	cached, ok := c.keys[jwksURI]
+	c.mu.Unlock()
+
+	fresh := ok && time.Since(cached.fetchedAt) < c.config.MetadataCacheTTL
+	if fresh {
+		if key := pickSigningKey(cached.keys, keyID); key != nil {
+			return key, nil
+		}
+		if time.Since(cached.fetchedAt) < oidcKeyRefreshDelay {
+			return nil, fmt.Errorf("unknown signing key %q", keyID)
+		}
+	}
+
+	var set struct {
+		Keys []oidcKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := parseOIDCKey(k); err == nil {
+			keys[k.KeyID] = key
+		}
+	}
+
+	c.mu.Lock()
+	c.keys[jwksURI] = cachedKeys{keys: keys, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	if key := pickSigningKey(keys, keyID); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+// pickSigningKey returns the key with the ID, or the only key when the token
+// names none
+func pickSigningKey(keys map[string]crypto.PublicKey, keyID string) crypto.PublicKey {
+	if keyID == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[keyID]
+}
+
+// parseOIDCKey converts an RSA or EC JSON Web Key to a public key
+func parseOIDCKey(k oidcKey) (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA key")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+		y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("invalid EC key")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
+
+// getJSON fetches a provider document
+func (c *oidcClient) getJSON(ctx context.Context, rawURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("%s returned invalid JSON", rawURL)
+	}
+
+	return nil
+}
+
+// newOIDCSecret generates a random state, nonce or PKCE verifier
+func newOIDCSecret() (string, error) {
+	b, err := utils.GenerateRandomBytes(32)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
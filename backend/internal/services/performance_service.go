@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+// PerformanceService persists the request cost each replica accounts for in
+// memory (see internal/metrics) and reports the slowest endpoints and queries
+type PerformanceService struct {
+	statRepo repository.RequestStatStore
+	config   *config.MetricsConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPerformanceService creates a new performance service
+func NewPerformanceService(statRepo repository.RequestStatStore, cfg *config.MetricsConfig) *PerformanceService {
+	return &PerformanceService{
+		statRepo: statRepo,
+		config:   cfg,
+	}
+}
+
+// Start flushes this replica's aggregates every METRICS_FLUSH_INTERVAL. Unlike
+// background jobs it runs on every replica, as each only sees its own requests.
+func (s *PerformanceService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Flush(ctx); err != nil {
+					log.Printf("⚠️  Failed to flush request stats: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops flushing and writes what was recorded since the last flush
+func (s *PerformanceService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.Flush(ctx); err != nil {
+		log.Printf("⚠️  Failed to flush request stats: %v", err)
+	}
+}
+
+// Flush adds the stats recorded since the last flush to the hourly rows.
+// Stats that fail to be written are dropped.
+func (s *PerformanceService) Flush(ctx context.Context) (int, error) {
+	stats := metrics.Drain()
+	if len(stats) == 0 {
+		return 0, nil
+	}
+
+	if err := s.statRepo.Add(ctx, stats); err != nil {
+		return 0, err
+	}
+
+	return len(stats), nil
+}
+
+// Report ranks the slowest endpoints and queries over the last window
+func (s *PerformanceService) Report(ctx context.Context, window time.Duration, sort string, limit int) (*models.PerformanceReport, error) {
+	if window <= 0 || window > s.config.Retention {
+		return nil, fmt.Errorf("window exceeds retention")
+	}
+	if sort == "" {
+		sort = models.PerformanceSortTotal
+	}
+
+	since := time.Now().Add(-window).UTC().Truncate(time.Hour)
+
+	endpoints, err := s.statRepo.TopEndpoints(ctx, since, sort, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := s.statRepo.TopQueries(ctx, since, sort, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PerformanceReport{
+		Since:     since,
+		Sort:      sort,
+		Endpoints: endpoints,
+		Queries:   queries,
+	}, nil
+}
+
+// Cleanup deletes hourly aggregates older than METRICS_RETENTION
+func (s *PerformanceService) Cleanup(ctx context.Context) (int, error) {
+	return s.statRepo.DeleteBefore(ctx, time.Now().Add(-s.config.Retention))
+}
@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,27 +14,34 @@ import (
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
 	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
 )
 
 // PermissionService handles permission-related business logic
 type PermissionService struct {
-	permissionRepo *repository.PermissionRepository
-	userRoleRepo   *repository.UserRoleRepository
-	roleRepo       *repository.RoleRepository
+	permissionRepo repository.PermissionStore
+	userRoleRepo   repository.UserRoleStore
+	roleRepo       repository.RoleStore
+	userRepo       repository.UserStore
+	objectACLRepo  repository.ObjectACLStore
 	redis          *redis.Client
 }
 
 // NewPermissionService creates a new permission service
 func NewPermissionService(
-	permissionRepo *repository.PermissionRepository,
-	userRoleRepo *repository.UserRoleRepository,
-	roleRepo *repository.RoleRepository,
+	permissionRepo repository.PermissionStore,
+	userRoleRepo repository.UserRoleStore,
+	roleRepo repository.RoleStore,
+	userRepo repository.UserStore,
+	objectACLRepo repository.ObjectACLStore,
 	redisClient *redis.Client,
 ) *PermissionService {
 	return &PermissionService{
 		permissionRepo: permissionRepo,
 		userRoleRepo:   userRoleRepo,
 		roleRepo:       roleRepo,
+		userRepo:       userRepo,
+		objectACLRepo:  objectACLRepo,
 		redis:          redisClient,
 	}
 }
@@ -62,13 +72,33 @@ func (s *PermissionService) GetUserPermissions(ctx context.Context, tenantID, us
 		return nil, err
 	}
 
-	// Cache the result
-	data, _ := json.Marshal(permissions)
-	s.redis.Set(ctx, cacheKey, data, permissionCacheTTL)
+	// Cache the result, no longer than until a temporary role assignment
+	// starts or ends
+	ttl := permissionCacheTTL
+	if next, err := s.userRoleRepo.NextAssignmentChange(ctx, tenantID, userID); err != nil {
+		return permissions, nil
+	} else if next != nil && time.Until(*next) < ttl {
+		ttl = time.Until(*next)
+	}
+	if ttl > 0 {
+		data, _ := json.Marshal(permissions)
+		s.redis.Set(ctx, cacheKey, data, ttl)
+	}
 
 	return permissions, nil
 }
 
+// ExpireRoleAssignments removes the temporary role assignments that ended
+// and clears their users' cached permissions. It is run periodically by the
+// background job runner and returns the number of assignments removed.
+func (s *PermissionService) ExpireRoleAssignments(ctx context.Context) (int, error) {
+	expired, err := s.userRoleRepo.DeleteExpired(ctx)
+	for _, assignment := range expired {
+		s.InvalidateUserPermissions(ctx, assignment.TenantID, assignment.UserID)
+	}
+	return len(expired), err
+}
+
 // getUserPermissionsFromDB retrieves user permissions from database
 func (s *PermissionService) getUserPermissionsFromDB(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Permission, error) {
 	// Get user's roles
@@ -81,17 +111,26 @@ func (s *PermissionService) getUserPermissionsFromDB(ctx context.Context, tenant
 		return []models.Permission{}, nil
 	}
 
-	// Collect all permissions from all roles
-	permissionMap := make(map[uuid.UUID]models.Permission)
+	// Collect all permissions from all roles and the roles they inherit
+	// from. A permission one role allows and another denies is kept twice,
+	// so the deny still applies.
+	type permissionKey struct {
+		id     uuid.UUID
+		effect string
+	}
+	permissionMap := make(map[permissionKey]models.Permission)
+	seen := make(map[uuid.UUID]bool)
 
-	for _, role := range roles {
-		rolePerms, err := s.roleRepo.GetPermissions(ctx, tenantID, role.ID)
-		if err != nil {
-			continue // Skip on error
-		}
+	for _, userRole := range roles {
+		for _, role := range s.roleChain(ctx, tenantID, userRole, seen) {
+			rolePerms, err := s.roleRepo.GetPermissions(ctx, tenantID, role.ID)
+			if err != nil {
+				continue // Skip on error
+			}
 
-		for _, perm := range rolePerms {
-			permissionMap[perm.ID] = perm
+			for _, perm := range rolePerms {
+				permissionMap[permissionKey{perm.ID, perm.Effect}] = perm
+			}
 		}
 	}
 
@@ -104,6 +143,98 @@ func (s *PermissionService) getUserPermissionsFromDB(ctx context.Context, tenant
 	return permissions, nil
 }
 
+// roleChain returns the role followed by its ancestors, nearest first. The
+// walk stops at a role already in seen, which ends cycles and skips ancestors
+// another of the user's roles already brought in.
+func (s *PermissionService) roleChain(ctx context.Context, tenantID uuid.UUID, role models.Role, seen map[uuid.UUID]bool) []models.Role {
+	var chain []models.Role
+	for !seen[role.ID] && len(chain) <= models.MaxRoleHierarchyDepth {
+		seen[role.ID] = true
+		chain = append(chain, role)
+
+		if role.ParentRoleID == nil {
+			break
+		}
+		parent, err := s.roleRepo.FindByID(ctx, tenantID, *role.ParentRoleID)
+		if err != nil {
+			break // Skip on error
+		}
+		role = *parent
+	}
+	return chain
+}
+
+// ValidateParentRole checks that a role may inherit from parentID and
+// returns the parent. roleID is the nil UUID for a role being created.
+func (s *PermissionService) ValidateParentRole(ctx context.Context, tenantID, roleID, parentID uuid.UUID) (*models.Role, error) {
+	if parentID == roleID {
+		return nil, utils.NewFieldError("INVALID_PARENT_ROLE", "parent_role_id", "role cannot inherit from itself")
+	}
+
+	parent, err := s.roleRepo.FindByID(ctx, tenantID, parentID)
+	if err != nil {
+		return nil, utils.NewFieldError("PARENT_ROLE_NOT_FOUND", "parent_role_id", "parent role not found")
+	}
+
+	chain := s.roleChain(ctx, tenantID, *parent, make(map[uuid.UUID]bool))
+	for _, ancestor := range chain {
+		if ancestor.ID == roleID {
+			return nil, utils.NewFieldError("INVALID_PARENT_ROLE", "parent_role_id", "role hierarchy cannot contain a cycle")
+		}
+	}
+	if len(chain) > models.MaxRoleHierarchyDepth {
+		return nil, utils.NewFieldError("INVALID_PARENT_ROLE", "parent_role_id", "role hierarchy is too deep")
+	}
+
+	return parent, nil
+}
+
+// GetEffectiveRolePermissions resolves a role's permissions through its
+// parent chain, recording which role each one comes from. A permission
+// several roles in the chain have is attributed to the nearest.
+func (s *PermissionService) GetEffectiveRolePermissions(ctx context.Context, tenantID, roleID uuid.UUID) (*models.RoleEffectivePermissions, error) {
+	role, err := s.roleRepo.FindByID(ctx, tenantID, roleID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("ROLE_NOT_FOUND", "role not found")
+	}
+
+	chain := s.roleChain(ctx, tenantID, *role, make(map[uuid.UUID]bool))
+
+	type permissionKey struct {
+		id     uuid.UUID
+		effect string
+	}
+	added := make(map[permissionKey]bool)
+	permissions := []models.EffectivePermission{}
+
+	for i, source := range chain {
+		rolePerms, err := s.GetRolePermissions(ctx, tenantID, source.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, perm := range rolePerms {
+			key := permissionKey{perm.ID, perm.Effect}
+			if added[key] {
+				continue
+			}
+			added[key] = true
+			permissions = append(permissions, models.EffectivePermission{
+				Permission:     perm,
+				SourceRoleID:   source.ID,
+				SourceRoleName: source.DisplayName,
+				Inherited:      i > 0,
+			})
+		}
+	}
+
+	return &models.RoleEffectivePermissions{
+		Role:        role,
+		Ancestors:   append([]models.Role{}, chain[1:]...),
+		Permissions: permissions,
+	}, nil
+}
+
 // HasPermission checks if a user has a specific permission (with caching)
 func (s *PermissionService) HasPermission(ctx context.Context, tenantID, userID uuid.UUID, resource, action string) (bool, error) {
 	// Get user permissions (from cache or DB)
@@ -112,14 +243,8 @@ func (s *PermissionService) HasPermission(ctx context.Context, tenantID, userID
 		return false, err
 	}
 
-	// Check if user has the required permission
-	for _, perm := range permissions {
-		if perm.Matches(resource, action) {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	// Check if user has the required permission; denies override grants
+	return models.PermissionsAllow(permissions, resource, action), nil
 }
 
 // HasAnyPermission checks if a user has any of the specified permissions
@@ -132,10 +257,8 @@ func (s *PermissionService) HasAnyPermission(ctx context.Context, tenantID, user
 
 	// Check if user has any of the required permissions
 	for _, check := range checks {
-		for _, perm := range permissions {
-			if perm.Matches(check.Resource, check.Action) {
-				return true, nil
-			}
+		if models.PermissionsAllow(permissions, check.Resource, check.Action) {
+			return true, nil
 		}
 	}
 
@@ -152,14 +275,7 @@ func (s *PermissionService) HasAllPermissions(ctx context.Context, tenantID, use
 
 	// Check if user has all required permissions
 	for _, check := range checks {
-		found := false
-		for _, perm := range permissions {
-			if perm.Matches(check.Resource, check.Action) {
-				found = true
-				break
-			}
-		}
-		if !found {
+		if !models.PermissionsAllow(permissions, check.Resource, check.Action) {
 			return false, nil
 		}
 	}
@@ -173,35 +289,156 @@ type PermissionCheck struct {
 	Action   string
 }
 
+// CheckPermissions evaluates each check against the user's cached permission
+// set and returns one result per check, in order
+func (s *PermissionService) CheckPermissions(ctx context.Context, tenantID, userID uuid.UUID, checks []PermissionCheck) ([]bool, error) {
+	permissions, err := s.GetUserPermissions(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(checks))
+	for i, check := range checks {
+		results[i] = models.PermissionsAllow(permissions, check.Resource, check.Action)
+	}
+
+	return results, nil
+}
+
+// GetPermissionsVersion returns a short fingerprint of the user's effective
+// permission set. It changes whenever a grant or deny is added or removed, so clients
+// can tell when permission results they cached have gone stale.
+func (s *PermissionService) GetPermissionsVersion(ctx context.Context, tenantID, userID uuid.UUID) (string, error) {
+	permissions, err := s.GetUserPermissions(ctx, tenantID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return permissionsVersion(permissions), nil
+}
+
+// permissionsVersion hashes the sorted resource.action names of a permission
+// set, prefixed with "!" for denies
+func permissionsVersion(permissions []models.Permission) string {
+	names := make([]string, len(permissions))
+	for i := range permissions {
+		names[i] = permissions[i].String()
+		if permissions[i].IsDeny() {
+			names[i] = "!" + names[i]
+		}
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// GrantTemporaryRole assigns a role to a user for req's period (from now
+// when req.ValidFrom is nil), optionally covering for another user who holds
+// the role. Returns the granted role.
+func (s *PermissionService) GrantTemporaryRole(ctx context.Context, tenantID, userID, assignedBy uuid.UUID, req *models.TemporaryRoleRequest) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(ctx, tenantID, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.IsOwner() {
+		return nil, utils.NewForbiddenError("OWNER_ROLE_NOT_TEMPORARY", "owner role cannot be granted temporarily")
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	if req.DelegatedFrom != nil {
+		if *req.DelegatedFrom == userID {
+			return nil, utils.NewFieldError("INVALID_DELEGATION", "delegated_from", "user cannot cover for themselves")
+		}
+		holds, err := s.userRoleRepo.HasRole(ctx, tenantID, *req.DelegatedFrom, req.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		if !holds {
+			return nil, utils.NewFieldError("INVALID_DELEGATION", "delegated_from", "delegating user does not have this role")
+		}
+	}
+
+	if req.ValidFrom == nil {
+		now := time.Now()
+		req.ValidFrom = &now
+	}
+
+	if err := s.userRoleRepo.GrantTemporaryRole(ctx, tenantID, userID, assignedBy, req); err != nil {
+		return nil, err
+	}
+
+	s.InvalidateUserPermissions(ctx, tenantID, userID)
+
+	return role, nil
+}
+
+// RevokeTemporaryRole ends a user's temporary assignment of a role early
+func (s *PermissionService) RevokeTemporaryRole(ctx context.Context, tenantID, userID, roleID uuid.UUID) error {
+	if err := s.userRoleRepo.RevokeTemporaryRole(ctx, tenantID, userID, roleID); err != nil {
+		return err
+	}
+
+	return s.InvalidateUserPermissions(ctx, tenantID, userID)
+}
+
 // InvalidateUserPermissions invalidates the permission cache for a user
 func (s *PermissionService) InvalidateUserPermissions(ctx context.Context, tenantID, userID uuid.UUID) error {
 	cacheKey := database.CacheKey(userPermissionKeyPrefix, tenantID.String(), userID.String())
 	return s.redis.Del(ctx, cacheKey).Err()
 }
 
-// InvalidateRolePermissions invalidates permission cache for all users with a role
+// InvalidateRolePermissions invalidates the role's permission cache and that
+// of all users with the role or a role inheriting from it
 func (s *PermissionService) InvalidateRolePermissions(ctx context.Context, tenantID, roleID uuid.UUID) error {
-	// Get all users with this role
-	users, err := s.userRoleRepo.GetUsersByRole(ctx, tenantID, roleID)
+	roleKey := database.CacheKey(rolePermissionKeyPrefix, tenantID.String(), roleID.String())
+	if err := s.redis.Del(ctx, roleKey).Err(); err != nil {
+		fmt.Printf("Failed to invalidate cache for role %s: %v\n", roleID, err)
+	}
+
+	// Inheriting roles only cache their own permissions, but their users'
+	// permission sets include this role's
+	descendantIDs, err := s.roleRepo.GetDescendantIDs(ctx, tenantID, roleID)
 	if err != nil {
 		return err
 	}
 
-	// Invalidate cache for each user
-	for _, user := range users {
-		if err := s.InvalidateUserPermissions(ctx, tenantID, user.ID); err != nil {
-			// Log error but continue
-			fmt.Printf("Failed to invalidate cache for user %s: %v\n", user.ID, err)
+	for _, id := range append([]uuid.UUID{roleID}, descendantIDs...) {
+		// Get all users with this role
+		users, err := s.userRoleRepo.GetUsersByRole(ctx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		// Invalidate cache for each user
+		for _, user := range users {
+			if err := s.InvalidateUserPermissions(ctx, tenantID, user.ID); err != nil {
+				// Log error but continue
+				fmt.Printf("Failed to invalidate cache for user %s: %v\n", user.ID, err)
+			}
 		}
 	}
 
 	return nil
 }
 
-// InvalidateTenantPermissions invalidates all permission caches for a tenant
+// InvalidateTenantPermissions invalidates all user and role permission caches
+// for a tenant
 func (s *PermissionService) InvalidateTenantPermissions(ctx context.Context, tenantID uuid.UUID) error {
-	pattern := database.CacheKey(userPermissionKeyPrefix, tenantID.String(), "*")
-	return database.InvalidatePattern(ctx, s.redis, pattern)
+	for _, prefix := range []string{userPermissionKeyPrefix, rolePermissionKeyPrefix} {
+		pattern := database.CacheKey(prefix, tenantID.String(), "*")
+		if err := database.InvalidatePattern(ctx, s.redis, pattern); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // GetRolePermissions retrieves permissions for a role (with caching)
@@ -241,6 +478,12 @@ func (s *PermissionService) ListPermissionsByCategory(ctx context.Context) ([]mo
 	return s.permissionRepo.ListByCategory(ctx)
 }
 
+// GetRegistry returns modules, resources and actions for building role editors
+// and navigation dynamically (static data, no caching needed)
+func (s *PermissionService) GetRegistry(ctx context.Context) ([]models.PermissionModule, error) {
+	return s.permissionRepo.GetRegistry(ctx)
+}
+
 // SearchPermissions searches for permissions
 func (s *PermissionService) SearchPermissions(ctx context.Context, searchTerm string) ([]models.Permission, error) {
 	return s.permissionRepo.Search(ctx, searchTerm)
@@ -322,6 +565,11 @@ func (s *PermissionService) IsAdmin(ctx context.Context, tenantID, userID uuid.U
 	return false, nil
 }
 
+// GetUserRoles returns the roles assigned to a user
+func (s *PermissionService) GetUserRoles(ctx context.Context, tenantID, userID uuid.UUID) ([]models.Role, error) {
+	return s.userRoleRepo.GetUserRoles(ctx, tenantID, userID)
+}
+
 // GetUserRoleNames returns role names for a user
 func (s *PermissionService) GetUserRoleNames(ctx context.Context, tenantID, userID uuid.UUID) ([]string, error) {
 	roles, err := s.userRoleRepo.GetUserRoles(ctx, tenantID, userID)
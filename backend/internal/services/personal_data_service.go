@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// PersonalDataService handles the data protection rights of users over
+// their own personal data: getting a copy of it, and having it erased.
+// Erasure anonymizes the user in place rather than deleting them, so audit
+// logs and the created_by of business records stay consistent. The HR record
+// of a linked employee is business data kept by the tenant and is left as is.
+type PersonalDataService struct {
+	personalDataRepo  repository.PersonalDataStore
+	userRepo          repository.UserStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	employeeRepo      repository.EmployeeStore
+	auditService      AuditManager
+	sessionService    SessionManager
+	permissionService PermissionManager
+	fileService       FileManager
+}
+
+// NewPersonalDataService creates a new personal data service
+func NewPersonalDataService(
+	personalDataRepo repository.PersonalDataStore,
+	userRepo repository.UserStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	employeeRepo repository.EmployeeStore,
+	auditService AuditManager,
+	sessionService SessionManager,
+	permissionService PermissionManager,
+	fileService FileManager,
+) *PersonalDataService {
+	return &PersonalDataService{
+		personalDataRepo:  personalDataRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		employeeRepo:      employeeRepo,
+		auditService:      auditService,
+		sessionService:    sessionService,
+		permissionService: permissionService,
+		fileService:       fileService,
+	}
+}
+
+// Export retrieves all the personal data held about a user
+func (s *PersonalDataService) Export(ctx context.Context, tenantID, userID uuid.UUID) (*models.PersonalDataExport, error) {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+
+	roles, err := s.userRoleRepo.GetUserRoles(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+
+	export := &models.PersonalDataExport{
+		GeneratedAt: time.Now().UTC(),
+		Profile:     user,
+		AuditLogs:   []models.AuditLog{},
+	}
+
+	// Not every user is an employee
+	if employee, err := s.employeeRepo.FindByUserID(ctx, tenantID, userID); err == nil {
+		export.Employee = employee
+	} else if err.Error() != "employee not found" {
+		return nil, err
+	}
+
+	if err := s.personalDataRepo.Export(ctx, tenantID, userID, export); err != nil {
+		return nil, err
+	}
+
+	err = s.auditService.Export(ctx, tenantID, AuditFilters{UserID: &userID}, func(log *models.AuditLog) error {
+		export.AuditLogs = append(export.AuditLogs, *log)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// RequestErasure erases a user's personal data at their request. They must
+// enter their password again, and cannot be the tenant's last active owner:
+// another owner must be appointed first, or the tenant deleted.
+func (s *PersonalDataService) RequestErasure(ctx context.Context, tenantID, userID uuid.UUID, password string) error {
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
+	}
+	// Users signing in with single sign-on have no password to confirm
+	if user.PasswordHash != "" && !utils.VerifyPassword(password, user.PasswordHash) {
+		return utils.NewBadRequestError("PASSWORD_INCORRECT", "password is incorrect")
+	}
+
+	lastOwner, err := s.isLastOwner(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if lastOwner {
+		return utils.NewConflictError("LAST_OWNER", "you are the last owner of the organization: appoint another owner or delete the organization first")
+	}
+
+	// Sessions are revoked through the session service so cached sessions go
+	// with them
+	if _, err := s.sessionService.RevokeAllUserSessions(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	if err := s.personalDataRepo.Anonymize(ctx, tenantID, userID, user.Email); err != nil {
+		return err
+	}
+
+	// Audited here rather than by the audit middleware, which would record
+	// the IP address and user agent of the request
+	if err := s.auditService.LogEvent(ctx, tenantID, userID, models.ActionUserErased, models.ResourceUsers, userID, models.AuditStatusSuccess, "", "", map[string]interface{}{}); err != nil {
+		log.Printf("⚠️  Failed to record the erasure of user %s: %v", userID, err)
+	}
+
+	if _, err := s.fileService.DeleteResourceFiles(ctx, tenantID, userID, models.FileCategoryAvatar, models.FileResourceUser, userID, nil); err != nil {
+		log.Printf("⚠️  Failed to delete the avatar of erased user %s: %v", userID, err)
+	}
+	if err := s.permissionService.InvalidateUserPermissions(ctx, tenantID, userID); err != nil {
+		log.Printf("⚠️  Failed to invalidate the permissions of erased user %s: %v", userID, err)
+	}
+
+	return nil
+}
+
+// isLastOwner reports whether the user is the only active owner of the tenant
+func (s *PersonalDataService) isLastOwner(ctx context.Context, tenantID, userID uuid.UUID) (bool, error) {
+	role, err := s.roleRepo.FindByName(ctx, tenantID, models.RoleOwner)
+	if err != nil {
+		return false, err
+	}
+	owners, err := s.userRoleRepo.GetUsersByRole(ctx, tenantID, role.ID)
+	if err != nil {
+		return false, err
+	}
+
+	isOwner, others := false, 0
+	for _, owner := range owners {
+		if owner.ID == userID {
+			isOwner = true
+		} else if owner.IsActive() {
+			others++
+		}
+	}
+
+	return isOwner && others == 0, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// PlatformService lets platform admins manage tenants across the platform:
+// list them, look at their health, suspend and reactivate them, and help
+// their users sign in. Actions on a tenant are audited in that tenant, with
+// the platform admin in the metadata as they are not one of its users.
+type PlatformService struct {
+	platformRepo      repository.PlatformStore
+	tenantRepo        repository.TenantStore
+	quotaRepo         repository.QuotaStore
+	authService       *AuthService
+	billingService    BillingManager
+	emailQueueService EmailQueueManager
+	auditService      AuditManager
+}
+
+// NewPlatformService creates a new platform service
+func NewPlatformService(
+	platformRepo repository.PlatformStore,
+	tenantRepo repository.TenantStore,
+	quotaRepo repository.QuotaStore,
+	authService *AuthService,
+	billingService BillingManager,
+	emailQueueService EmailQueueManager,
+	auditService AuditManager,
+) *PlatformService {
+	return &PlatformService{
+		platformRepo:      platformRepo,
+		tenantRepo:        tenantRepo,
+		quotaRepo:         quotaRepo,
+		authService:       authService,
+		billingService:    billingService,
+		emailQueueService: emailQueueService,
+		auditService:      auditService,
+	}
+}
+
+// IsAdmin reports whether a user may administer the platform: they must be
+// granted it and have a second factor, so their sign-in required one
+func (s *PlatformService) IsAdmin(ctx context.Context, user *models.User) (bool, error) {
+	granted, err := s.platformRepo.IsAdmin(ctx, user.TenantID, user.ID)
+	if err != nil || !granted {
+		return false, err
+	}
+
+	methods, err := s.authService.secondFactorMethods(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	return len(methods) > 0, nil
+}
+
+// ListTenants retrieves a filtered, paginated list of tenants. Their
+// settings are left out.
+func (s *PlatformService) ListTenants(ctx context.Context, filter models.PlatformTenantFilter, page, pageSize int) ([]models.Tenant, int, error) {
+	tenants, total, err := s.platformRepo.ListTenants(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range tenants {
+		tenants[i].Settings = nil
+	}
+
+	return tenants, total, nil
+}
+
+// GetTenantHealth retrieves a tenant's plan and usage, its unresolved quota
+// alerts and its email delivery
+func (s *PlatformService) GetTenantHealth(ctx context.Context, tenantID uuid.UUID) (*models.PlatformTenantHealth, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	tenant.Settings = nil
+
+	billingStatus, err := s.billingService.GetStatus(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.quotaRepo.ListOpenAlerts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	emailQueue, err := s.emailQueueService.GetStats(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PlatformTenantHealth{
+		Tenant:      tenant,
+		ReadOnly:    tenant.IsReadOnly(),
+		Billing:     billingStatus,
+		QuotaAlerts: alerts,
+		EmailQueue:  emailQueue,
+	}, nil
+}
+
+// SuspendTenant makes an active tenant read-only for the given reason
+func (s *PlatformService) SuspendTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID, reason string) (*models.Tenant, error) {
+	if !slices.Contains(models.TenantSuspensionReasons, reason) {
+		return nil, utils.NewValidationError("INVALID_SUSPENSION_REASON", "reason must be payment_overdue, terms_violation or requested")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.IsActive() && !tenant.IsSuspended() {
+		return nil, utils.NewConflictError("TENANT_NOT_ACTIVE", "only active tenants can be suspended")
+	}
+
+	if err := s.tenantRepo.Suspend(ctx, tenantID, reason); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, admin, tenantID, models.ActionPlatformTenantSuspended, models.AuditResourceTenant, tenantID, map[string]interface{}{
+		"reason": reason,
+	})
+
+	return s.tenantRepo.FindByID(ctx, tenantID)
+}
+
+// ActivateTenant lifts a tenant's suspension
+func (s *PlatformService) ActivateTenant(ctx context.Context, admin *models.User, tenantID uuid.UUID) (*models.Tenant, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.IsSuspended() {
+		return nil, utils.NewConflictError("TENANT_NOT_SUSPENDED", "tenant is not suspended")
+	}
+
+	if err := s.tenantRepo.Reactivate(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, admin, tenantID, models.ActionPlatformTenantActivated, models.AuditResourceTenant, tenantID, nil)
+
+	return s.tenantRepo.FindByID(ctx, tenantID)
+}
+
+// ResendVerification sends a tenant awaiting email verification a new
+// verification link
+func (s *PlatformService) ResendVerification(ctx context.Context, admin *models.User, tenantID uuid.UUID) error {
+	tenant, err := s.authService.ResendTenantVerification(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	s.audit(ctx, admin, tenantID, models.ActionPlatformVerificationResent, models.AuditResourceTenant, tenantID, map[string]interface{}{
+		"email": tenant.Email,
+	})
+
+	return nil
+}
+
+// ForcePasswordReset makes a user of a tenant choose a new password
+func (s *PlatformService) ForcePasswordReset(ctx context.Context, admin *models.User, tenantID uuid.UUID, email string) error {
+	if _, err := s.tenantRepo.FindByID(ctx, tenantID); err != nil {
+		return err
+	}
+
+	user, err := s.authService.ForcePasswordReset(ctx, tenantID, email)
+	if err != nil {
+		return err
+	}
+
+	s.audit(ctx, admin, tenantID, models.ActionPlatformPasswordResetForced, models.ResourceUsers, user.ID, map[string]interface{}{
+		"email": user.Email,
+	})
+
+	return nil
+}
+
+// GetStats retrieves statistics across all tenants: tenants by status and
+// plan, users across data regions, and this month's metered usage
+func (s *PlatformService) GetStats(ctx context.Context) (*models.PlatformStats, error) {
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -30)
+
+	stats := &models.PlatformStats{}
+	if err := s.platformRepo.CountTenants(ctx, since, stats); err != nil {
+		return nil, err
+	}
+
+	users, active, err := s.platformRepo.CountUsers(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	stats.Users = users
+	stats.ActiveUsersLast30d = active
+
+	// Rollups lag the live counters by up to JOBS_METERING_ROLLUP_INTERVAL
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	usage, err := s.platformRepo.SumUsage(ctx, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	stats.Usage = usage
+
+	return stats, nil
+}
+
+// audit logs a platform admin's action in the tenant acted on. The admin is
+// not one of its users, so they are recorded in the metadata.
+func (s *PlatformService) audit(ctx context.Context, admin *models.User, tenantID uuid.UUID, action, resourceType string, resourceID uuid.UUID, metadata map[string]interface{}) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["platform_admin_id"] = admin.ID
+	metadata["platform_admin_email"] = admin.Email
+
+	s.auditService.LogEvent(ctx, tenantID, uuid.Nil, action, resourceType, resourceID, models.AuditStatusSuccess, "", "", metadata)
+}
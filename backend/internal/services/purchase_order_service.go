@@ -0,0 +1,1012 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Purchasing audit actions
+const (
+	purchasingAuditSupplierCreated = "purchasing.supplier_created"
+	purchasingAuditSupplierUpdated = "purchasing.supplier_updated"
+	purchasingAuditSupplierDeleted = "purchasing.supplier_deleted"
+	purchasingAuditSupplierMerged  = "purchasing.supplier_merged"
+	purchasingAuditOrderCreated    = "purchasing.order_created"
+	purchasingAuditOrderUpdated    = "purchasing.order_updated"
+	purchasingAuditOrderDeleted    = "purchasing.order_deleted"
+	purchasingAuditStatusChanged   = "purchasing.status_changed"
+	purchasingAuditGoodsReceived   = "purchasing.goods_received"
+	purchasingAuditInvoiceRecorded = "purchasing.invoice_recorded"
+	purchasingAuditInvoiceApproved = "purchasing.invoice_approved"
+	purchasingAuditInvoiceRejected = "purchasing.invoice_rejected"
+)
+
+// Supplier duplicate detection: how similar (0-1) names and emails must be
+// for suppliers to be reported as likely duplicates
+const (
+	supplierDuplicateNameThreshold  = 0.6
+	supplierDuplicateEmailThreshold = 0.8
+	supplierDuplicateLimit          = 10
+)
+
+// Three-way match tolerances
+const (
+	purchasingPriceMatchTolerance  = 0.01
+	purchasingQuantityMatchEpsilon = 0.0001
+)
+
+// PurchaseOrderService handles suppliers, purchase orders, goods receipts and
+// three-way matching of supplier invoices
+type PurchaseOrderService struct {
+	supplierRepo        repository.SupplierStore
+	poRepo              repository.PurchaseOrderStore
+	auditService        AuditManager
+	deletionService     DeletionManager
+	approvalLinkService ApprovalLinkManager
+}
+
+// NewPurchaseOrderService creates a new purchase order service
+func NewPurchaseOrderService(
+	supplierRepo repository.SupplierStore,
+	poRepo repository.PurchaseOrderStore,
+	auditService AuditManager,
+	deletionService DeletionManager,
+	approvalLinkService ApprovalLinkManager,
+) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		supplierRepo:        supplierRepo,
+		poRepo:              poRepo,
+		auditService:        auditService,
+		deletionService:     deletionService,
+		approvalLinkService: approvalLinkService,
+	}
+}
+
+// CreateSupplier creates a new active supplier. Unless req.AllowDuplicate is
+// set, a supplier that looks like existing ones is not created; the likely
+// duplicates are returned instead.
+func (s *PurchaseOrderService) CreateSupplier(ctx context.Context, tenantID, userID uuid.UUID, req *models.SupplierCreateRequest) (*models.Supplier, []models.SupplierDuplicate, error) {
+	exists, err := s.supplierRepo.CheckNameExists(ctx, tenantID, req.Name, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		return nil, nil, utils.NewConflictError("SUPPLIER_NAME_EXISTS", "supplier name already exists")
+	}
+
+	if !req.AllowDuplicate {
+		query := models.SupplierDuplicateQuery{Name: req.Name}
+		if req.Email != nil {
+			query.Email = *req.Email
+		}
+		if req.TaxID != nil {
+			query.TaxID = *req.TaxID
+		}
+		duplicates, err := s.FindSupplierDuplicates(ctx, tenantID, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(duplicates) > 0 {
+			return nil, duplicates, nil
+		}
+	}
+
+	paymentTerms := 30
+	if req.PaymentTermsDays != nil {
+		paymentTerms = *req.PaymentTermsDays
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = "USD"
+	}
+
+	supplier := &models.Supplier{
+		Name:             req.Name,
+		Code:             req.Code,
+		Email:            req.Email,
+		Phone:            req.Phone,
+		Address:          req.Address,
+		TaxID:            req.TaxID,
+		PaymentTermsDays: paymentTerms,
+		Currency:         currency,
+		Notes:            req.Notes,
+		Status:           models.SupplierStatusActive,
+		CreatedBy:        &userID,
+	}
+
+	if err := s.supplierRepo.Create(ctx, tenantID, supplier); err != nil {
+		return nil, nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditSupplierCreated, models.ResourcePurchasing, supplier.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name":            supplier.Name,
+		"allow_duplicate": req.AllowDuplicate,
+	})
+
+	return supplier, nil, nil
+}
+
+// GetSupplier retrieves a supplier
+func (s *PurchaseOrderService) GetSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.Supplier, error) {
+	return s.supplierRepo.FindByID(ctx, tenantID, supplierID)
+}
+
+// ListSuppliers lists suppliers with filters and pagination
+func (s *PurchaseOrderService) ListSuppliers(ctx context.Context, tenantID uuid.UUID, status, search string, limit, offset int) ([]models.Supplier, int, error) {
+	return s.supplierRepo.List(ctx, tenantID, status, search, limit, offset)
+}
+
+// UpdateSupplier updates a supplier
+func (s *PurchaseOrderService) UpdateSupplier(
+	ctx context.Context,
+	tenantID, userID, supplierID uuid.UUID,
+	req *models.SupplierUpdateRequest,
+) (*models.Supplier, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != supplier.Name {
+		exists, err := s.supplierRepo.CheckNameExists(ctx, tenantID, *req.Name, &supplierID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, utils.NewConflictError("SUPPLIER_NAME_EXISTS", "supplier name already exists")
+		}
+		supplier.Name = *req.Name
+	}
+	if req.Code != nil {
+		supplier.Code = req.Code
+	}
+	if req.Email != nil {
+		supplier.Email = req.Email
+	}
+	if req.Phone != nil {
+		supplier.Phone = req.Phone
+	}
+	if req.Address != nil {
+		supplier.Address = req.Address
+	}
+	if req.TaxID != nil {
+		supplier.TaxID = req.TaxID
+	}
+	if req.PaymentTermsDays != nil {
+		supplier.PaymentTermsDays = *req.PaymentTermsDays
+	}
+	if req.Currency != nil {
+		supplier.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Notes != nil {
+		supplier.Notes = req.Notes
+	}
+	if req.Status != nil {
+		supplier.Status = *req.Status
+	}
+
+	if err := s.supplierRepo.Update(ctx, tenantID, supplier); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditSupplierUpdated, models.ResourcePurchasing, supplier.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name":   supplier.Name,
+		"status": supplier.Status,
+	})
+
+	return supplier, nil
+}
+
+// DeleteSupplier deletes a supplier that has no purchase orders
+func (s *PurchaseOrderService) DeleteSupplier(ctx context.Context, tenantID, userID, supplierID uuid.UUID) error {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.supplierRepo.Delete(ctx, tenantID, supplierID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditSupplierDeleted, models.ResourcePurchasing, supplier.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name": supplier.Name,
+	})
+
+	return nil
+}
+
+// FindSupplierDuplicates lists the suppliers that look like the same vendor
+// as query: a similar name or email, or the same tax ID. The most likely
+// duplicates come first.
+func (s *PurchaseOrderService) FindSupplierDuplicates(ctx context.Context, tenantID uuid.UUID, query models.SupplierDuplicateQuery) ([]models.SupplierDuplicate, error) {
+	query.Name = strings.TrimSpace(query.Name)
+	query.Email = strings.TrimSpace(query.Email)
+	query.TaxID = normalizeTaxID(query.TaxID)
+	if query.Name == "" && query.Email == "" && query.TaxID == "" {
+		return []models.SupplierDuplicate{}, nil
+	}
+
+	duplicates, err := s.supplierRepo.FindDuplicates(ctx, tenantID, query, supplierDuplicateNameThreshold, supplierDuplicateEmailThreshold, supplierDuplicateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range duplicates {
+		d := &duplicates[i]
+		d.Reasons = []string{}
+		if d.TaxIDMatch {
+			d.Reasons = append(d.Reasons, models.DuplicateReasonTaxID)
+			d.Score = 1
+		}
+		if d.EmailSimilarity >= supplierDuplicateEmailThreshold {
+			d.Reasons = append(d.Reasons, models.DuplicateReasonEmail)
+			d.Score = math.Max(d.Score, d.EmailSimilarity)
+		}
+		if d.NameSimilarity >= supplierDuplicateNameThreshold {
+			d.Reasons = append(d.Reasons, models.DuplicateReasonName)
+			d.Score = math.Max(d.Score, d.NameSimilarity)
+		}
+		d.Score = math.Round(d.Score*100) / 100
+	}
+
+	return duplicates, nil
+}
+
+// FindDuplicatesOfSupplier lists the other suppliers that look like the same
+// vendor as a supplier
+func (s *PurchaseOrderService) FindDuplicatesOfSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierDuplicate, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.SupplierDuplicateQuery{Name: supplier.Name, ExcludeID: &supplier.ID}
+	if supplier.Email != nil {
+		query.Email = *supplier.Email
+	}
+	if supplier.TaxID != nil {
+		query.TaxID = *supplier.TaxID
+	}
+	return s.FindSupplierDuplicates(ctx, tenantID, query)
+}
+
+// MergeSuppliers merges a duplicate supplier (source) into the supplier that
+// is kept (target). The source's purchase orders and supplier invoices move
+// to the target, details the target is missing (code, email, phone, address,
+// tax ID, notes) are taken from the source, and the source is deleted. The
+// merge is recorded with the source as it was.
+func (s *PurchaseOrderService) MergeSuppliers(ctx context.Context, tenantID, userID, targetID, sourceID uuid.UUID) (*models.Supplier, *models.SupplierMerge, error) {
+	if targetID == sourceID {
+		return nil, nil, utils.NewBadRequestError("SUPPLIER_MERGE_INTO_ITSELF", "cannot merge a supplier into itself")
+	}
+
+	target, err := s.supplierRepo.FindByID(ctx, tenantID, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	source, err := s.supplierRepo.FindByID(ctx, tenantID, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot, err := json.Marshal(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged supplier: %w", err)
+	}
+
+	for _, field := range []struct {
+		target **string
+		source *string
+	}{
+		{&target.Code, source.Code},
+		{&target.Email, source.Email},
+		{&target.Phone, source.Phone},
+		{&target.Address, source.Address},
+		{&target.TaxID, source.TaxID},
+		{&target.Notes, source.Notes},
+	} {
+		if (*field.target == nil || **field.target == "") && field.source != nil && *field.source != "" {
+			*field.target = field.source
+		}
+	}
+
+	merge := &models.SupplierMerge{
+		SourceID:   source.ID,
+		SourceName: source.Name,
+		Source:     snapshot,
+		MergedBy:   &userID,
+	}
+	if err := s.supplierRepo.Merge(ctx, tenantID, target, merge); err != nil {
+		return nil, nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditSupplierMerged, models.ResourcePurchasing, target.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name":                    target.Name,
+		"merged_supplier_id":      source.ID,
+		"merged_supplier_name":    source.Name,
+		"purchase_orders_moved":   merge.PurchaseOrdersMoved,
+		"supplier_invoices_moved": merge.SupplierInvoicesMoved,
+	})
+
+	return target, merge, nil
+}
+
+// ListSupplierMerges lists the suppliers merged into a supplier, newest first
+func (s *PurchaseOrderService) ListSupplierMerges(ctx context.Context, tenantID, supplierID uuid.UUID) ([]models.SupplierMerge, error) {
+	if _, err := s.supplierRepo.FindByID(ctx, tenantID, supplierID); err != nil {
+		return nil, err
+	}
+	return s.supplierRepo.ListMerges(ctx, tenantID, supplierID)
+}
+
+// normalizeTaxID drops everything but letters and digits from a tax ID, so
+// "FR 12 345" and "fr12345" compare equal
+func normalizeTaxID(taxID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return -1
+	}, taxID)
+}
+
+// CreateOrder creates a draft purchase order for an active supplier
+func (s *PurchaseOrderService) CreateOrder(ctx context.Context, tenantID, userID uuid.UUID, req *models.PurchaseOrderCreateRequest) (*models.PurchaseOrder, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier.Status != models.SupplierStatusActive {
+		return nil, utils.NewBadRequestError("SUPPLIER_INACTIVE", "supplier is inactive")
+	}
+
+	orderDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.OrderDate != nil {
+		orderDate = *req.OrderDate
+	}
+
+	// Default to the supplier's currency
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = supplier.Currency
+	}
+
+	po := &models.PurchaseOrder{
+		SupplierID:   supplier.ID,
+		Status:       models.POStatusDraft,
+		OrderDate:    orderDate,
+		ExpectedDate: req.ExpectedDate,
+		Currency:     currency,
+		Notes:        req.Notes,
+		Lines:        buildPurchaseOrderLines(req.Lines),
+		CreatedBy:    &userID,
+		SupplierName: &supplier.Name,
+	}
+	applyPurchaseOrderTotals(po)
+
+	if err := s.poRepo.Create(ctx, tenantID, po); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditOrderCreated, models.ResourcePurchasing, po.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number": po.PONumber,
+		"supplier":  supplier.Name,
+		"total":     po.Total,
+	})
+
+	return po, nil
+}
+
+// GetOrder retrieves a purchase order with its lines
+func (s *PurchaseOrderService) GetOrder(ctx context.Context, tenantID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	return s.poRepo.FindByID(ctx, tenantID, poID)
+}
+
+// ListOrders lists purchase orders with filters and pagination
+func (s *PurchaseOrderService) ListOrders(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	supplierID *uuid.UUID,
+	status string,
+	limit, offset int,
+) ([]models.PurchaseOrder, int, error) {
+	return s.poRepo.List(ctx, tenantID, supplierID, status, limit, offset)
+}
+
+// UpdateOrder updates a draft purchase order
+func (s *PurchaseOrderService) UpdateOrder(
+	ctx context.Context,
+	tenantID, userID, poID uuid.UUID,
+	req *models.PurchaseOrderUpdateRequest,
+) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if po.Status != models.POStatusDraft {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be edited")
+	}
+
+	if req.ExpectedDate != nil {
+		po.ExpectedDate = req.ExpectedDate
+	}
+	if req.Currency != nil {
+		po.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Notes != nil {
+		po.Notes = req.Notes
+	}
+	if req.Lines != nil {
+		po.Lines = buildPurchaseOrderLines(*req.Lines)
+	}
+	applyPurchaseOrderTotals(po)
+
+	if err := s.poRepo.Update(ctx, tenantID, po); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditOrderUpdated, models.ResourcePurchasing, po.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number": po.PONumber,
+		"total":     po.Total,
+	})
+
+	return po, nil
+}
+
+// DeleteOrder schedules a draft purchase order for deletion. It is deleted by
+// PurgeOrder once the undo window has passed.
+func (s *PurchaseOrderService) DeleteOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PendingDeletion, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if po.Status != models.POStatusDraft {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be deleted, cancel it instead")
+	}
+
+	return s.deletionService.Schedule(ctx, tenantID, userID, models.DeletionEntityPurchaseOrder, po.ID, po.PONumber)
+}
+
+// PurgeOrder permanently deletes a purchase order staged for deletion. The
+// order must still be a draft; it may have been sent in the meantime.
+func (s *PurchaseOrderService) PurgeOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) error {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return err
+	}
+
+	if po.Status != models.POStatusDraft {
+		return utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be deleted, cancel it instead")
+	}
+
+	if err := s.poRepo.Delete(ctx, tenantID, poID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditOrderDeleted, models.ResourcePurchasing, po.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number": po.PONumber,
+	})
+
+	return nil
+}
+
+// SendOrder marks a draft purchase order as sent to the supplier
+func (s *PurchaseOrderService) SendOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if po.Status != models.POStatusDraft {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_NOT_DRAFT", "only draft purchase orders can be sent")
+	}
+	if len(po.Lines) == 0 {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_HAS_NO_LINES", "cannot send a purchase order without lines")
+	}
+
+	return s.changeOrderStatus(ctx, tenantID, userID, po, models.POStatusSent)
+}
+
+// CloseOrder closes a purchase order; no further receipts or invoices are accepted
+func (s *PurchaseOrderService) CloseOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !po.CanInvoice() {
+		return nil, utils.NewBadRequestError("INVALID_STATUS_TRANSITION", fmt.Sprintf("cannot change status from %s to %s", po.Status, models.POStatusClosed))
+	}
+
+	return s.changeOrderStatus(ctx, tenantID, userID, po, models.POStatusClosed)
+}
+
+// CancelOrder cancels a purchase order that has not received any goods
+func (s *PurchaseOrderService) CancelOrder(ctx context.Context, tenantID, userID, poID uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if po.Status != models.POStatusDraft && po.Status != models.POStatusSent {
+		return nil, utils.NewBadRequestError("INVALID_STATUS_TRANSITION", fmt.Sprintf("cannot change status from %s to %s", po.Status, models.POStatusCancelled))
+	}
+
+	return s.changeOrderStatus(ctx, tenantID, userID, po, models.POStatusCancelled)
+}
+
+// changeOrderStatus applies a validated status transition and audits it
+func (s *PurchaseOrderService) changeOrderStatus(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	po *models.PurchaseOrder,
+	status string,
+) (*models.PurchaseOrder, error) {
+	if err := s.poRepo.UpdateStatus(ctx, tenantID, po.ID, po.Status, status); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditStatusChanged, models.ResourcePurchasing, po.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number":   po.PONumber,
+		"from_status": po.Status,
+		"to_status":   status,
+	})
+
+	return s.poRepo.FindByID(ctx, tenantID, po.ID)
+}
+
+// ReceiveGoods records goods received against a sent purchase order
+func (s *PurchaseOrderService) ReceiveGoods(
+	ctx context.Context,
+	tenantID, userID, poID uuid.UUID,
+	req *models.GoodsReceiptCreateRequest,
+) (*models.GoodsReceipt, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !po.CanReceive() {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_NOT_SENT", "goods can only be received on sent purchase orders")
+	}
+
+	orderLines := make(map[uuid.UUID]models.PurchaseOrderLine, len(po.Lines))
+	for _, line := range po.Lines {
+		orderLines[line.ID] = line
+	}
+
+	receipt := &models.GoodsReceipt{
+		PurchaseOrderID: po.ID,
+		ReceivedAt:      time.Now().UTC(),
+		ReceivedBy:      &userID,
+		Reference:       req.Reference,
+		Notes:           req.Notes,
+		Lines:           make([]models.GoodsReceiptLine, 0, len(req.Lines)),
+	}
+	if req.ReceivedAt != nil {
+		receipt.ReceivedAt = *req.ReceivedAt
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.Lines))
+	for _, l := range req.Lines {
+		orderLine, ok := orderLines[l.PurchaseOrderLineID]
+		if !ok {
+			return nil, utils.NewBadRequestError("PURCHASE_ORDER_LINE_NOT_FOUND", "purchase order line not found")
+		}
+		if seen[l.PurchaseOrderLineID] {
+			return nil, utils.NewBadRequestError("DUPLICATE_RECEIPT_LINE", "purchase order line received twice in the same receipt")
+		}
+		seen[l.PurchaseOrderLineID] = true
+
+		if orderLine.QuantityReceived+l.Quantity > orderLine.Quantity+purchasingQuantityMatchEpsilon {
+			return nil, utils.NewBadRequestError("QUANTITY_EXCEEDS_ORDERED", "received quantity exceeds ordered quantity")
+		}
+
+		receipt.Lines = append(receipt.Lines, models.GoodsReceiptLine{
+			PurchaseOrderLineID: l.PurchaseOrderLineID,
+			Quantity:            l.Quantity,
+		})
+	}
+
+	if err := s.poRepo.CreateReceipt(ctx, tenantID, receipt); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditGoodsReceived, models.ResourcePurchasing, po.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number":  po.PONumber,
+		"receipt_id": receipt.ID,
+		"lines":      len(receipt.Lines),
+	})
+
+	return receipt, nil
+}
+
+// ListReceipts lists goods receipts for a purchase order
+func (s *PurchaseOrderService) ListReceipts(ctx context.Context, tenantID, poID uuid.UUID) ([]models.GoodsReceipt, error) {
+	if _, err := s.poRepo.FindByID(ctx, tenantID, poID); err != nil {
+		return nil, err
+	}
+	return s.poRepo.ListReceipts(ctx, tenantID, poID)
+}
+
+// RecordSupplierInvoice records a supplier invoice and three-way matches it
+// against the purchase order (ordered quantity and price) and goods receipts
+// (received quantity). Invoices with discrepancies are stored with an
+// exception match status and need a forced approval.
+func (s *PurchaseOrderService) RecordSupplierInvoice(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	req *models.SupplierInvoiceCreateRequest,
+) (*models.SupplierInvoice, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, req.PurchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !po.CanInvoice() {
+		return nil, utils.NewBadRequestError("PURCHASE_ORDER_NOT_SENT", "invoices can only be recorded on sent purchase orders")
+	}
+
+	exists, err := s.poRepo.CheckInvoiceNumberExists(ctx, tenantID, po.SupplierID, req.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.NewConflictError("INVOICE_NUMBER_EXISTS", "invoice number already recorded for this supplier")
+	}
+
+	orderLines := make(map[uuid.UUID]models.PurchaseOrderLine, len(po.Lines))
+	for _, line := range po.Lines {
+		orderLines[line.ID] = line
+	}
+
+	invoice := &models.SupplierInvoice{
+		SupplierID:      po.SupplierID,
+		PurchaseOrderID: po.ID,
+		InvoiceNumber:   req.InvoiceNumber,
+		InvoiceDate:     req.InvoiceDate,
+		DueDate:         req.DueDate,
+		Currency:        po.Currency,
+		TaxTotal:        roundMoney(req.TaxTotal),
+		Status:          models.SupplierInvoiceStatusPending,
+		Notes:           req.Notes,
+		CreatedBy:       &userID,
+		Lines:           make([]models.SupplierInvoiceLine, 0, len(req.Lines)),
+	}
+
+	discrepancies := []models.MatchDiscrepancy{}
+	seen := make(map[uuid.UUID]bool, len(req.Lines))
+	var subtotal float64
+
+	for _, l := range req.Lines {
+		orderLine, ok := orderLines[l.PurchaseOrderLineID]
+		if !ok {
+			return nil, utils.NewBadRequestError("PURCHASE_ORDER_LINE_NOT_FOUND", "purchase order line not found")
+		}
+		if seen[l.PurchaseOrderLineID] {
+			return nil, utils.NewBadRequestError("DUPLICATE_INVOICE_LINE", "purchase order line invoiced twice in the same invoice")
+		}
+		seen[l.PurchaseOrderLineID] = true
+
+		discrepancies = append(discrepancies, matchInvoiceLine(orderLine, l)...)
+
+		lineTotal := roundMoney(l.Quantity * l.UnitPrice)
+		subtotal += lineTotal
+
+		invoice.Lines = append(invoice.Lines, models.SupplierInvoiceLine{
+			PurchaseOrderLineID: l.PurchaseOrderLineID,
+			Quantity:            l.Quantity,
+			UnitPrice:           l.UnitPrice,
+			LineTotal:           lineTotal,
+		})
+	}
+
+	invoice.Subtotal = roundMoney(subtotal)
+	invoice.Total = roundMoney(invoice.Subtotal + invoice.TaxTotal)
+
+	invoice.MatchStatus = models.MatchStatusMatched
+	if len(discrepancies) > 0 {
+		invoice.MatchStatus = models.MatchStatusException
+	}
+
+	details, err := json.Marshal(discrepancies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode match details: %w", err)
+	}
+	invoice.MatchDetails = details
+
+	if err := s.poRepo.CreateSupplierInvoice(ctx, tenantID, invoice); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditInvoiceRecorded, models.ResourcePurchasing, invoice.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"po_number":      po.PONumber,
+		"invoice_number": invoice.InvoiceNumber,
+		"total":          invoice.Total,
+		"match_status":   invoice.MatchStatus,
+		"discrepancies":  len(discrepancies),
+	})
+
+	// Approvers can decide from their inbox
+	if err := s.approvalLinkService.RequestApproval(ctx, tenantID, userID, models.ApprovalResourceSupplierInvoice, invoice.ID); err != nil {
+		log.Printf("⚠️  Failed to send approval links for supplier invoice %s: %v", invoice.ID, err)
+	}
+
+	return invoice, nil
+}
+
+// GetSupplierInvoice retrieves a supplier invoice with its lines
+func (s *PurchaseOrderService) GetSupplierInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	return s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+}
+
+// ListSupplierInvoices lists supplier invoices with filters and pagination
+func (s *PurchaseOrderService) ListSupplierInvoices(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	poID *uuid.UUID,
+	matchStatus, status string,
+	limit, offset int,
+) ([]models.SupplierInvoice, int, error) {
+	return s.poRepo.ListSupplierInvoices(ctx, tenantID, poID, matchStatus, status, limit, offset)
+}
+
+// ApproveSupplierInvoice approves a pending supplier invoice for payment.
+// Invoices that failed matching can only be approved with force.
+func (s *PurchaseOrderService) ApproveSupplierInvoice(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, force bool) (*models.SupplierInvoice, error) {
+	invoice, err := s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.MatchStatus != models.MatchStatusMatched && !force {
+		return nil, utils.NewBadRequestError("INVOICE_MISMATCH", "invoice does not match the purchase order and receipts")
+	}
+
+	if err := s.poRepo.UpdateSupplierInvoiceStatus(ctx, tenantID, invoiceID, userID, models.SupplierInvoiceStatusApproved); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditInvoiceApproved, models.ResourcePurchasing, invoice.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"invoice_number": invoice.InvoiceNumber,
+		"match_status":   invoice.MatchStatus,
+		"forced":         force && invoice.MatchStatus != models.MatchStatusMatched,
+	})
+
+	return s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+}
+
+// RejectSupplierInvoice rejects a pending supplier invoice
+func (s *PurchaseOrderService) RejectSupplierInvoice(ctx context.Context, tenantID, userID, invoiceID uuid.UUID) (*models.SupplierInvoice, error) {
+	invoice, err := s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.poRepo.UpdateSupplierInvoiceStatus(ctx, tenantID, invoiceID, userID, models.SupplierInvoiceStatusRejected); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, purchasingAuditInvoiceRejected, models.ResourcePurchasing, invoice.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"invoice_number": invoice.InvoiceNumber,
+		"match_status":   invoice.MatchStatus,
+	})
+
+	return s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+}
+
+// DescribeInvoiceApproval describes a supplier invoice for approval links
+func (s *PurchaseOrderService) DescribeInvoiceApproval(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.ApprovalSubject, error) {
+	invoice, err := s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, invoice.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("Total %.2f %s, dated %s.", invoice.Total, invoice.Currency, invoice.InvoiceDate.Format("2006-01-02"))
+	if invoice.MatchStatus != models.MatchStatusMatched {
+		details += " It does not match the purchase order and receipts, so it can only be approved in the app."
+	}
+
+	return &models.ApprovalSubject{
+		Title:     fmt.Sprintf("Supplier invoice %s from %s", invoice.InvoiceNumber, supplier.Name),
+		Details:   details,
+		Amount:    invoice.Total,
+		Currency:  invoice.Currency,
+		Pending:   invoice.Status == models.SupplierInvoiceStatusPending,
+		ReviewURL: fmt.Sprintf("/purchasing/invoices/%s", invoice.ID),
+	}, nil
+}
+
+// DescribeSupplier describes a supplier for recently viewed and favorites
+// lists
+func (s *PurchaseOrderService) DescribeSupplier(ctx context.Context, tenantID, supplierID uuid.UUID) (*models.EntitySummary, error) {
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.EntitySummary{
+		Title: supplier.Name,
+		Path:  fmt.Sprintf("/purchasing/suppliers/%s", supplier.ID),
+	}
+	if supplier.Code != nil {
+		summary.Subtitle = *supplier.Code
+	}
+	return summary, nil
+}
+
+// DescribeOrder describes a purchase order for recently viewed and favorites
+// lists
+func (s *PurchaseOrderService) DescribeOrder(ctx context.Context, tenantID, poID uuid.UUID) (*models.EntitySummary, error) {
+	po, err := s.poRepo.FindByID(ctx, tenantID, poID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.EntitySummary{
+		Title: po.PONumber,
+		Path:  fmt.Sprintf("/purchasing/orders/%s", po.ID),
+	}
+	if po.SupplierName != nil {
+		summary.Subtitle = *po.SupplierName
+	}
+	return summary, nil
+}
+
+// DescribeInvoice describes a supplier invoice for recently viewed and
+// favorites lists
+func (s *PurchaseOrderService) DescribeInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*models.EntitySummary, error) {
+	invoice, err := s.poRepo.FindSupplierInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier, err := s.supplierRepo.FindByID(ctx, tenantID, invoice.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    invoice.InvoiceNumber,
+		Subtitle: supplier.Name,
+		Path:     fmt.Sprintf("/purchasing/invoices/%s", invoice.ID),
+	}, nil
+}
+
+// DecideInvoiceApproval approves or rejects a supplier invoice from an
+// approval link. Invoices that failed matching are never force-approved this
+// way.
+func (s *PurchaseOrderService) DecideInvoiceApproval(ctx context.Context, tenantID, userID, invoiceID uuid.UUID, decision string) error {
+	var err error
+	if decision == models.ApprovalDecisionApprove {
+		_, err = s.ApproveSupplierInvoice(ctx, tenantID, userID, invoiceID, false)
+	} else {
+		_, err = s.RejectSupplierInvoice(ctx, tenantID, userID, invoiceID)
+	}
+	return err
+}
+
+// ListPendingInvoiceApprovals lists the supplier invoices recorded before
+// cutoff that are still waiting for approval, for escalation policies
+func (s *PurchaseOrderService) ListPendingInvoiceApprovals(ctx context.Context, tenantID uuid.UUID, cutoff time.Time) ([]models.EscalationItem, error) {
+	invoices, err := s.poRepo.ListPendingSupplierInvoices(ctx, tenantID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierNames := make(map[uuid.UUID]string)
+	items := make([]models.EscalationItem, 0, len(invoices))
+	for _, invoice := range invoices {
+		name, ok := supplierNames[invoice.SupplierID]
+		if !ok {
+			supplier, err := s.supplierRepo.FindByID(ctx, tenantID, invoice.SupplierID)
+			if err != nil {
+				return nil, err
+			}
+			name = supplier.Name
+			supplierNames[invoice.SupplierID] = name
+		}
+
+		items = append(items, models.EscalationItem{
+			ResourceID:   invoice.ID,
+			Title:        fmt.Sprintf("Supplier invoice %s from %s", invoice.InvoiceNumber, name),
+			Details:      fmt.Sprintf("Total %.2f %s, dated %s.", invoice.Total, invoice.Currency, invoice.InvoiceDate.Format("2006-01-02")),
+			RequestedBy:  invoice.CreatedBy,
+			PendingSince: invoice.CreatedAt,
+			ReviewURL:    fmt.Sprintf("/purchasing/invoices/%s", invoice.ID),
+		})
+	}
+
+	return items, nil
+}
+
+// matchInvoiceLine compares an invoice line with its order line. Quantities are
+// cumulative: what was already invoiced on the order line counts too.
+func matchInvoiceLine(orderLine models.PurchaseOrderLine, l models.SupplierInvoiceLineRequest) []models.MatchDiscrepancy {
+	discrepancies := []models.MatchDiscrepancy{}
+	invoiced := orderLine.QuantityInvoiced + l.Quantity
+
+	if invoiced > orderLine.QuantityReceived+purchasingQuantityMatchEpsilon {
+		discrepancies = append(discrepancies, models.MatchDiscrepancy{
+			PurchaseOrderLineID: orderLine.ID,
+			LineNumber:          orderLine.LineNumber,
+			Type:                models.DiscrepancyExceedsReceived,
+			Expected:            orderLine.QuantityReceived,
+			Actual:              invoiced,
+		})
+	}
+
+	if invoiced > orderLine.Quantity+purchasingQuantityMatchEpsilon {
+		discrepancies = append(discrepancies, models.MatchDiscrepancy{
+			PurchaseOrderLineID: orderLine.ID,
+			LineNumber:          orderLine.LineNumber,
+			Type:                models.DiscrepancyExceedsOrdered,
+			Expected:            orderLine.Quantity,
+			Actual:              invoiced,
+		})
+	}
+
+	if math.Abs(l.UnitPrice-orderLine.UnitPrice) > purchasingPriceMatchTolerance {
+		discrepancies = append(discrepancies, models.MatchDiscrepancy{
+			PurchaseOrderLineID: orderLine.ID,
+			LineNumber:          orderLine.LineNumber,
+			Type:                models.DiscrepancyPriceMismatch,
+			Expected:            orderLine.UnitPrice,
+			Actual:              l.UnitPrice,
+		})
+	}
+
+	return discrepancies
+}
+
+// buildPurchaseOrderLines converts request lines into purchase order lines
+func buildPurchaseOrderLines(reqLines []models.PurchaseOrderLineRequest) []models.PurchaseOrderLine {
+	lines := make([]models.PurchaseOrderLine, len(reqLines))
+	for i, l := range reqLines {
+		lines[i] = models.PurchaseOrderLine{
+			ProductCode: l.ProductCode,
+			Description: l.Description,
+			Quantity:    l.Quantity,
+			UnitPrice:   l.UnitPrice,
+			TaxRate:     l.TaxRate,
+		}
+	}
+	return lines
+}
+
+// applyPurchaseOrderTotals computes line totals and order totals, rounded to cents
+func applyPurchaseOrderTotals(po *models.PurchaseOrder) {
+	var subtotal, taxTotal float64
+
+	for i := range po.Lines {
+		line := &po.Lines[i]
+		line.LineTotal = roundMoney(line.Quantity * line.UnitPrice)
+		subtotal += line.LineTotal
+		taxTotal += roundMoney(line.LineTotal * line.TaxRate / 100)
+	}
+
+	po.Subtotal = roundMoney(subtotal)
+	po.TaxTotal = roundMoney(taxTotal)
+	po.Total = roundMoney(po.Subtotal + po.TaxTotal)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+// QueueService reports the depth of the queues worked by background jobs
+// (email outbox, webhook deliveries, async jobs) and warns when one backs up
+type QueueService struct {
+	db           *sqlx.DB
+	emailRepo    repository.EmailOutboxStore
+	webhookRepo  repository.WebhookStore
+	asyncJobRepo repository.AsyncJobStore
+	config       *config.QueueConfig
+}
+
+// NewQueueService creates a new queue service
+func NewQueueService(
+	db *sqlx.DB,
+	emailRepo repository.EmailOutboxStore,
+	webhookRepo repository.WebhookStore,
+	asyncJobRepo repository.AsyncJobStore,
+	cfg *config.QueueConfig,
+) *QueueService {
+	return &QueueService{
+		db:           db,
+		emailRepo:    emailRepo,
+		webhookRepo:  webhookRepo,
+		asyncJobRepo: asyncJobRepo,
+		config:       cfg,
+	}
+}
+
+// Stats measures every queue across all tenants and data regions
+func (s *QueueService) Stats(ctx context.Context) ([]models.QueueStats, error) {
+	queues := []struct {
+		stats   models.QueueStats
+		measure func(context.Context, *sqlx.DB) (*models.QueueStats, error)
+	}{
+		{models.QueueStats{Queue: models.QueueEmail, Limit: s.config.EmailMaxPending, LimitScope: "tenant"}, s.emailRepo.QueueStats},
+		{models.QueueStats{Queue: models.QueueWebhook, Limit: s.config.WebhookMaxPending, LimitScope: "webhook"}, s.webhookRepo.QueueStats},
+		{models.QueueStats{Queue: models.QueueAsyncJob, Limit: s.config.AsyncMaxQueued, LimitScope: "tenant"}, s.asyncJobRepo.QueueStats},
+	}
+
+	result := make([]models.QueueStats, 0, len(queues))
+	for _, queue := range queues {
+		stats := queue.stats
+		for _, db := range database.RegionDBs(s.db) {
+			region, err := queue.measure(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+			stats.Pending += region.Pending
+			stats.Due += region.Due
+			stats.DeadLetters += region.DeadLetters
+			if region.OldestDueAt != nil && (stats.OldestDueAt == nil || region.OldestDueAt.Before(*stats.OldestDueAt)) {
+				stats.OldestDueAt = region.OldestDueAt
+			}
+		}
+
+		stats.Alert = (s.config.AlertDepth > 0 && stats.Pending >= s.config.AlertDepth) ||
+			(s.config.AlertAge > 0 && stats.OldestDueAge() >= s.config.AlertAge)
+		result = append(result, stats)
+	}
+
+	return result, nil
+}
+
+// Gauges returns the queue depths for the Prometheus endpoint
+func (s *QueueService) Gauges(ctx context.Context) ([]metrics.QueueGauge, error) {
+	stats, err := s.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gauges := make([]metrics.QueueGauge, 0, len(stats))
+	for _, queue := range stats {
+		gauges = append(gauges, metrics.QueueGauge{
+			Queue:       queue.Queue,
+			Pending:     queue.Pending,
+			Due:         queue.Due,
+			DeadLetters: queue.DeadLetters,
+			OldestDue:   queue.OldestDueAge(),
+			Alert:       queue.Alert,
+		})
+	}
+
+	return gauges, nil
+}
+
+// Monitor logs a warning for every queue over QUEUE_ALERT_DEPTH or whose
+// oldest due item waited longer than QUEUE_ALERT_AGE. It is run periodically
+// by the background job runner and returns the number of queues alerting.
+func (s *QueueService) Monitor(ctx context.Context) (int, error) {
+	stats, err := s.Stats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	alerting := 0
+	for _, queue := range stats {
+		if !queue.Alert {
+			continue
+		}
+		alerting++
+		log.Printf("⚠️  Queue %s is backing up: %d pending, %d due, oldest due for %s, %d dead letters",
+			queue.Queue, queue.Pending, queue.Due, queue.OldestDueAge().Round(time.Second), queue.DeadLetters)
+	}
+
+	return alerting, nil
+}
+
+// drainQueue works a queue with up to concurrency workers calling next until
+// the queue is empty, batchSize items were attempted, or ctx is cancelled.
+// next reports whether it attempted an item. Returns the number attempted
+// and the first error, after which the workers stop.
+func drainQueue(ctx context.Context, concurrency, batchSize int, next func(context.Context) (bool, error)) (int, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	claimed, attempted := 0, 0
+	done := false
+
+	// take reserves one item of the batch for a worker
+	take := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if done || claimed >= batchSize || ctx.Err() != nil {
+			return false
+		}
+		claimed++
+		return true
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for take() {
+				ok, err := next(ctx)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					if firstErr == nil {
+						firstErr = err
+					}
+					done = true
+				case !ok:
+					done = true
+				default:
+					attempted++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return attempted, firstErr
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// QuotaService measures tenants' usage against the quotas of their plan.
+// The quota_check job (CheckScheduled) opens an alert whenever usage crosses
+// a warning threshold and emails the tenant's owners, then reminds them
+// until one of them acknowledges the alert. The user quota is enforced once
+// it is reached, and monthly API calls by the metering in authentication;
+// storage is only warned about.
+type QuotaService struct {
+	db                *sqlx.DB
+	quotaRepo         repository.QuotaStore
+	tenantRepo        repository.TenantStore
+	roleRepo          repository.RoleStore
+	userRoleRepo      repository.UserRoleStore
+	emailService      EmailManager
+	emailQueueService EmailQueueManager
+	notifier          NotificationManager
+	metering          MeteringManager
+	config            *config.Config
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(
+	db *sqlx.DB,
+	quotaRepo repository.QuotaStore,
+	tenantRepo repository.TenantStore,
+	roleRepo repository.RoleStore,
+	userRoleRepo repository.UserRoleStore,
+	emailService EmailManager,
+	emailQueueService EmailQueueManager,
+	notifier NotificationManager,
+	metering MeteringManager,
+	cfg *config.Config,
+) *QuotaService {
+	return &QuotaService{
+		db:                db,
+		quotaRepo:         quotaRepo,
+		tenantRepo:        tenantRepo,
+		roleRepo:          roleRepo,
+		userRoleRepo:      userRoleRepo,
+		emailService:      emailService,
+		emailQueueService: emailQueueService,
+		notifier:          notifier,
+		metering:          metering,
+		config:            cfg,
+	}
+}
+
+// GetStatus retrieves the tenant's usage of each quota and its open alerts
+func (s *QuotaService) GetStatus(ctx context.Context, tenantID uuid.UUID) (*models.QuotaStatus, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := s.measure(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.quotaRepo.ListOpenAlerts(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.QuotaStatus{
+		PlanTier: tenant.PlanTier,
+		Quotas:   quotas,
+		Alerts:   alerts,
+	}, nil
+}
+
+// CheckUserQuota returns an error if adding users would take the tenant over
+// the user limit of its plan
+func (s *QuotaService) CheckUserQuota(ctx context.Context, tenantID uuid.UUID, adding int) error {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	limit, ok := s.config.Quotas.UserLimits[tenant.PlanTier]
+	if !ok {
+		return nil
+	}
+
+	count, err := s.quotaRepo.CountUsers(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if count+int64(adding) > limit {
+		return utils.NewForbiddenError("PLAN_USER_LIMIT_REACHED", "plan user limit reached")
+	}
+
+	return nil
+}
+
+// CheckPlanFits returns an error if the tenant's usage exceeds a limit of
+// the plan tier, so it cannot move to that plan. API calls start over every
+// month and do not count.
+func (s *QuotaService) CheckPlanFits(ctx context.Context, tenant *models.Tenant, tier string) error {
+	target := *tenant
+	target.PlanTier = tier
+
+	quotas, err := s.measure(ctx, &target)
+	if err != nil {
+		return err
+	}
+	for _, quota := range quotas {
+		if quota.Metric == models.QuotaMetricAPICalls {
+			continue
+		}
+		if quota.Limit != nil && quota.Usage > *quota.Limit {
+			return fmt.Errorf("usage exceeds the plan's limits")
+		}
+	}
+
+	return nil
+}
+
+// AcknowledgeAlert records that userID saw an alert, so its owners are no
+// longer reminded of it
+func (s *QuotaService) AcknowledgeAlert(ctx context.Context, tenantID, alertID, userID uuid.UUID) (*models.QuotaAlert, error) {
+	return s.quotaRepo.Acknowledge(ctx, tenantID, alertID, userID)
+}
+
+// CheckScheduled measures the usage of every active tenant, opens an alert
+// for each threshold crossed and resolves the alerts of thresholds usage is
+// back under. Returns the number of tenants whose owners were emailed. A
+// tenant that fails is logged and skipped so it does not hold up the others.
+func (s *QuotaService) CheckScheduled(ctx context.Context) (int, error) {
+	tenants, err := s.tenantRepo.ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for i := range tenants {
+		if err := ctx.Err(); err != nil {
+			return notified, nil
+		}
+
+		sent, err := s.checkTenant(ctx, &tenants[i])
+		if err != nil {
+			log.Printf("⚠️  Quota check of tenant %s failed: %v", tenants[i].ID, err)
+			continue
+		}
+		if sent {
+			notified++
+		}
+	}
+
+	return notified, nil
+}
+
+// checkTenant updates a tenant's alerts, then emails its owners the alerts
+// that are new or due a reminder. Returns true if owners were emailed.
+func (s *QuotaService) checkTenant(ctx context.Context, tenant *models.Tenant) (bool, error) {
+	quotas, err := s.measure(ctx, tenant)
+	if err != nil {
+		return false, err
+	}
+
+	for _, quota := range quotas {
+		if quota.Metric == models.QuotaMetricStorage {
+			s.metering.RecordStorage(ctx, tenant.ID, quota.Usage)
+		}
+
+		percent := 0
+		if quota.Percent != nil {
+			percent = *quota.Percent
+		}
+		if _, err := s.quotaRepo.ResolveAlerts(ctx, tenant.ID, quota.Metric, percent); err != nil {
+			return false, err
+		}
+
+		for _, threshold := range s.config.Quotas.Thresholds {
+			if quota.Percent == nil || percent < threshold {
+				break
+			}
+			if _, err := s.quotaRepo.OpenAlert(ctx, &models.QuotaAlert{
+				TenantID:  tenant.ID,
+				Metric:    quota.Metric,
+				Threshold: threshold,
+				Usage:     quota.Usage,
+				Limit:     *quota.Limit,
+			}); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	alerts, err := s.quotaRepo.ListOpenAlerts(ctx, tenant.ID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	due := []models.QuotaAlert{}
+	for _, alert := range alerts {
+		if alert.IsAcknowledged() {
+			continue
+		}
+		if alert.LastNotifiedAt == nil || now.Sub(*alert.LastNotifiedAt) >= s.config.Quotas.ReminderInterval {
+			due = append(due, alert)
+		}
+	}
+	if len(due) == 0 {
+		return false, nil
+	}
+
+	return true, s.notifyOwners(ctx, tenant, due, now)
+}
+
+// notifyOwners queues an email listing the alerts to each active owner of
+// the tenant and records when they were notified, in one transaction
+func (s *QuotaService) notifyOwners(ctx context.Context, tenant *models.Tenant, alerts []models.QuotaAlert, now time.Time) error {
+	role, err := s.roleRepo.FindByName(ctx, tenant.ID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+	users, err := s.userRoleRepo.GetUsersByRole(ctx, tenant.ID, role.ID)
+	if err != nil {
+		return err
+	}
+
+	owners := []models.User{}
+	for _, user := range users {
+		if user.IsActive() {
+			owners = append(owners, user)
+		}
+	}
+	if len(owners) == 0 {
+		log.Printf("⚠️  Tenant %s crossed quota thresholds but has no owner to notify", tenant.ID)
+		return nil
+	}
+
+	quotasURL := s.config.App.FrontendURL + "/settings/quotas"
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenant.ID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, owner := range owners {
+		msg, err := s.emailService.QuotaAlertEmail(owner.Email, owner.Language, owner.FirstName, tenant.CompanyName, alerts, quotasURL)
+		if err != nil {
+			return err
+		}
+		if err := s.emailQueueService.Enqueue(ctx, tx.Tx, tenant.ID, msg); err != nil {
+			return err
+		}
+	}
+
+	alertIDs := make([]uuid.UUID, len(alerts))
+	for i, alert := range alerts {
+		alertIDs[i] = alert.ID
+	}
+	if err := s.quotaRepo.MarkNotified(ctx, tx.Tx, tenant.ID, alertIDs, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to queue quota alerts: %w", err)
+	}
+
+	s.notifyInApp(ctx, tenant.ID, owners, alerts)
+
+	return nil
+}
+
+// notifyInApp adds an in-app notification of the alerts for the owners
+func (s *QuotaService) notifyInApp(ctx context.Context, tenantID uuid.UUID, owners []models.User, alerts []models.QuotaAlert) {
+	ownerIDs := make([]uuid.UUID, len(owners))
+	for i, owner := range owners {
+		ownerIDs[i] = owner.ID
+	}
+
+	highest := 0
+	metrics := make([]string, len(alerts))
+	for i, alert := range alerts {
+		if alert.Threshold > highest {
+			highest = alert.Threshold
+		}
+		metrics[i] = alert.Metric
+	}
+
+	if err := s.notifier.Notify(ctx, tenantID, ownerIDs, &models.NotificationRequest{
+		Type:  models.NotificationTypeQuotaAlert,
+		Title: fmt.Sprintf("Your workspace reached %d%% of a plan limit", highest),
+		Body:  fmt.Sprintf("Usage of %s is approaching the limit of your plan.", strings.Join(metrics, ", ")),
+		Link:  "/settings/quotas",
+		Data:  map[string]interface{}{"alerts": alerts},
+	}); err != nil {
+		log.Printf("⚠️  Failed to notify owners of tenant %s of quota alerts: %v", tenantID, err)
+	}
+}
+
+// measure returns the tenant's usage of each quota against its plan's limits
+func (s *QuotaService) measure(ctx context.Context, tenant *models.Tenant) ([]models.QuotaUsage, error) {
+	quotas := make([]models.QuotaUsage, 0, len(models.QuotaMetrics))
+	for _, metric := range models.QuotaMetrics {
+		var usage int64
+		var limits map[string]int64
+		var err error
+
+		switch metric {
+		case models.QuotaMetricUsers:
+			usage, err = s.quotaRepo.CountUsers(ctx, tenant.ID)
+			limits = s.config.Quotas.UserLimits
+		case models.QuotaMetricStorage:
+			usage, err = s.quotaRepo.MeasureStorage(ctx, tenant.ID)
+			limits = s.config.Quotas.StorageLimits
+		case models.QuotaMetricAPICalls:
+			usage, err = s.metering.MonthToDate(ctx, tenant.ID, models.MeterAPICalls)
+			limits = s.config.Quotas.APICallLimits
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		quota := models.QuotaUsage{Metric: metric, Usage: usage}
+		if limit, ok := limits[tenant.PlanTier]; ok {
+			percent := 100
+			if limit > 0 {
+				percent = int(usage * 100 / limit)
+			}
+			quota.Limit = &limit
+			quota.Percent = &percent
+		}
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, nil
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Sales audit actions
+const (
+	salesAuditCreated       = "sales.document_created"
+	salesAuditUpdated       = "sales.document_updated"
+	salesAuditDeleted       = "sales.document_deleted"
+	salesAuditStatusChanged = "sales.status_changed"
+	salesAuditConverted     = "sales.document_converted"
+)
+
+// SalesService handles quotations, sales orders and invoices. Changes are
+// audited here rather than by the audit middleware, so they are not published
+// on the event bus: the service hands them to the watch service itself.
+type SalesService struct {
+	salesRepo       repository.SalesStore
+	txManager       *database.TxManager
+	auditService    AuditManager
+	deletionService DeletionManager
+	watchService    WatchManager
+}
+
+// NewSalesService creates a new sales service
+func NewSalesService(salesRepo repository.SalesStore, txManager *database.TxManager, auditService AuditManager, deletionService DeletionManager, watchService WatchManager) *SalesService {
+	return &SalesService{
+		salesRepo:       salesRepo,
+		txManager:       txManager,
+		auditService:    auditService,
+		deletionService: deletionService,
+		watchService:    watchService,
+	}
+}
+
+// CreateDocument creates a new draft sales document of the given type
+func (s *SalesService) CreateDocument(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	documentType string,
+	req *models.SalesDocumentCreateRequest,
+) (*models.SalesDocument, error) {
+	if !models.IsValidSalesDocumentType(documentType) {
+		return nil, utils.NewBadRequestError("INVALID_DOCUMENT_TYPE", "invalid sales document type")
+	}
+
+	issueDate := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.IssueDate != nil {
+		issueDate = *req.IssueDate
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = "USD"
+	}
+
+	doc := &models.SalesDocument{
+		DocumentType:    documentType,
+		CustomerName:    req.CustomerName,
+		CustomerEmail:   req.CustomerEmail,
+		CustomerAddress: req.CustomerAddress,
+		Status:          models.SalesStatusDraft,
+		IssueDate:       issueDate,
+		DueDate:         req.DueDate,
+		Currency:        currency,
+		Notes:           req.Notes,
+		Lines:           buildSalesLines(req.Lines),
+		CreatedBy:       &userID,
+	}
+	applySalesTotals(doc)
+
+	if err := s.salesRepo.Create(ctx, tenantID, doc); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, salesAuditCreated, models.ResourceSales, doc.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"document_type":   doc.DocumentType,
+		"document_number": doc.DocumentNumber,
+		"total":           doc.Total,
+	})
+	s.notifyWatchers(ctx, tenantID, userID, salesAuditCreated, doc)
+
+	return doc, nil
+}
+
+// GetDocument retrieves a sales document of the given type
+func (s *SalesService) GetDocument(ctx context.Context, tenantID uuid.UUID, documentType string, docID uuid.UUID) (*models.SalesDocument, error) {
+	doc, err := s.salesRepo.FindByID(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not let /quotes/{id} serve an invoice and vice versa
+	if doc.DocumentType != documentType {
+		return nil, utils.NewNotFoundError("SALES_DOCUMENT_NOT_FOUND", "sales document not found")
+	}
+
+	return doc, nil
+}
+
+// DescribeDocument describes a sales document for recently viewed,
+// favorites and watch lists
+func (s *SalesService) DescribeDocument(ctx context.Context, tenantID, docID uuid.UUID) (*models.EntitySummary, error) {
+	doc, err := s.salesRepo.FindByID(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EntitySummary{
+		Title:    doc.DocumentNumber,
+		Subtitle: doc.CustomerName,
+		Path:     fmt.Sprintf("/sales/%ss/%s", doc.DocumentType, doc.ID),
+	}, nil
+}
+
+// ListDocuments lists sales documents with filters and pagination
+func (s *SalesService) ListDocuments(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	filter models.SalesDocumentFilter,
+	limit, offset int,
+) ([]models.SalesDocument, int, error) {
+	return s.salesRepo.List(ctx, tenantID, filter, limit, offset)
+}
+
+// UpdateDocument updates a draft sales document
+func (s *SalesService) UpdateDocument(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	documentType string,
+	docID uuid.UUID,
+	req *models.SalesDocumentUpdateRequest,
+) (*models.SalesDocument, error) {
+	doc, err := s.GetDocument(ctx, tenantID, documentType, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.IsDraft() {
+		return nil, utils.NewBadRequestError("DOCUMENT_NOT_DRAFT", "only draft documents can be edited")
+	}
+
+	before := AuditSnapshot(doc)
+
+	if req.CustomerName != nil {
+		doc.CustomerName = *req.CustomerName
+	}
+	if req.CustomerEmail != nil {
+		doc.CustomerEmail = req.CustomerEmail
+	}
+	if req.CustomerAddress != nil {
+		doc.CustomerAddress = req.CustomerAddress
+	}
+	if req.IssueDate != nil {
+		doc.IssueDate = *req.IssueDate
+	}
+	if req.DueDate != nil {
+		doc.DueDate = req.DueDate
+	}
+	if req.Currency != nil {
+		doc.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Notes != nil {
+		doc.Notes = req.Notes
+	}
+	if req.Lines != nil {
+		doc.Lines = buildSalesLines(*req.Lines)
+	}
+	applySalesTotals(doc)
+
+	if err := s.salesRepo.Update(ctx, tenantID, doc); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, salesAuditUpdated, models.ResourceSales, doc.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"document_number": doc.DocumentNumber,
+		"total":           doc.Total,
+		"before":          before,
+		"after":           AuditSnapshot(doc),
+	})
+	s.notifyWatchers(ctx, tenantID, userID, salesAuditUpdated, doc)
+
+	return doc, nil
+}
+
+// DeleteDocument schedules a draft sales document for deletion. It is deleted
+// by PurgeDocument once the undo window has passed.
+func (s *SalesService) DeleteDocument(ctx context.Context, tenantID, userID uuid.UUID, documentType string, docID uuid.UUID) (*models.PendingDeletion, error) {
+	doc, err := s.GetDocument(ctx, tenantID, documentType, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.IsDraft() {
+		return nil, utils.NewBadRequestError("DOCUMENT_NOT_DRAFT", "only draft documents can be deleted, cancel it instead")
+	}
+
+	return s.deletionService.Schedule(ctx, tenantID, userID, models.DeletionEntitySalesDocument, doc.ID, doc.DocumentNumber)
+}
+
+// PurgeDocument permanently deletes a sales document staged for deletion. The
+// document must still be a draft; it may have been confirmed in the meantime.
+func (s *SalesService) PurgeDocument(ctx context.Context, tenantID, userID, docID uuid.UUID) error {
+	doc, err := s.salesRepo.FindByID(ctx, tenantID, docID)
+	if err != nil {
+		return err
+	}
+
+	if !doc.IsDraft() {
+		return utils.NewBadRequestError("DOCUMENT_NOT_DRAFT", "only draft documents can be deleted, cancel it instead")
+	}
+
+	if err := s.salesRepo.Delete(ctx, tenantID, docID); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, salesAuditDeleted, models.ResourceSales, doc.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"document_number": doc.DocumentNumber,
+	})
+
+	return nil
+}
+
+// ChangeStatus moves a sales document to a new status if the transition is allowed
+func (s *SalesService) ChangeStatus(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	documentType string,
+	docID uuid.UUID,
+	status string,
+) (*models.SalesDocument, error) {
+	doc, err := s.GetDocument(ctx, tenantID, documentType, docID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.CanTransitionTo(status) {
+		return nil, utils.NewBadRequestError("INVALID_STATUS_TRANSITION", fmt.Sprintf("cannot change status from %s to %s", doc.Status, status))
+	}
+
+	if status == models.SalesStatusConfirmed && len(doc.Lines) == 0 {
+		return nil, utils.NewBadRequestError("DOCUMENT_HAS_NO_LINES", "cannot confirm a document without lines")
+	}
+
+	if err := s.salesRepo.UpdateStatus(ctx, tenantID, docID, doc.Status, status); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, salesAuditStatusChanged, models.ResourceSales, doc.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"document_number": doc.DocumentNumber,
+		"from_status":     doc.Status,
+		"to_status":       status,
+		"before":          map[string]string{"status": doc.Status},
+		"after":           map[string]string{"status": status},
+	})
+
+	doc, err = s.salesRepo.FindByID(ctx, tenantID, docID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyWatchers(ctx, tenantID, userID, salesAuditStatusChanged, doc)
+
+	return doc, nil
+}
+
+// ConvertQuoteToOrder creates a draft sales order from a confirmed quotation.
+// The quotation is marked as fulfilled together with the creation of the
+// order, with the quotation locked so concurrent conversions cannot both
+// create an order.
+func (s *SalesService) ConvertQuoteToOrder(ctx context.Context, tenantID, userID, quoteID uuid.UUID) (*models.SalesDocument, error) {
+	var quote, order *models.SalesDocument
+	err := s.txManager.InTenantTx(ctx, tenantID, func(ctx context.Context) error {
+		if err := s.salesRepo.Lock(ctx, tenantID, quoteID); err != nil {
+			return err
+		}
+
+		var err error
+		quote, err = s.GetDocument(ctx, tenantID, models.SalesDocumentQuote, quoteID)
+		if err != nil {
+			return err
+		}
+
+		if quote.Status != models.SalesStatusConfirmed {
+			return utils.NewBadRequestError("QUOTATION_NOT_CONFIRMED", "only confirmed quotations can be converted to orders")
+		}
+
+		order, err = s.createDerived(ctx, tenantID, userID, quote, models.SalesDocumentOrder)
+		if err != nil {
+			return err
+		}
+
+		return s.salesRepo.UpdateStatus(ctx, tenantID, quote.ID, quote.Status, models.SalesStatusFulfilled)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditConverted(ctx, tenantID, userID, quote, order)
+	return order, nil
+}
+
+// InvoiceOrder creates a draft invoice for a confirmed or fulfilled sales order.
+// An order can only have one invoice that is not cancelled; the order is
+// locked while its invoices are checked and the new one is created.
+func (s *SalesService) InvoiceOrder(ctx context.Context, tenantID, userID, orderID uuid.UUID) (*models.SalesDocument, error) {
+	var order, invoice *models.SalesDocument
+	err := s.txManager.InTenantTx(ctx, tenantID, func(ctx context.Context) error {
+		if err := s.salesRepo.Lock(ctx, tenantID, orderID); err != nil {
+			return err
+		}
+
+		var err error
+		order, err = s.GetDocument(ctx, tenantID, models.SalesDocumentOrder, orderID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != models.SalesStatusConfirmed && order.Status != models.SalesStatusFulfilled {
+			return utils.NewBadRequestError("ORDER_NOT_INVOICEABLE", "only confirmed or fulfilled orders can be invoiced")
+		}
+
+		derived, err := s.salesRepo.FindBySource(ctx, tenantID, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, d := range derived {
+			if d.DocumentType == models.SalesDocumentInvoice && d.Status != models.SalesStatusCancelled {
+				return utils.NewConflictError("ORDER_ALREADY_INVOICED", fmt.Sprintf("order is already invoiced by %s", d.DocumentNumber))
+			}
+		}
+
+		invoice, err = s.createDerived(ctx, tenantID, userID, order, models.SalesDocumentInvoice)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditConverted(ctx, tenantID, userID, order, invoice)
+	return invoice, nil
+}
+
+// createDerived copies a source document into a new draft of another type
+func (s *SalesService) createDerived(
+	ctx context.Context,
+	tenantID, userID uuid.UUID,
+	source *models.SalesDocument,
+	documentType string,
+) (*models.SalesDocument, error) {
+	lines := make([]models.SalesDocumentLine, len(source.Lines))
+	for i, line := range source.Lines {
+		lines[i] = models.SalesDocumentLine{
+			ProductCode:     line.ProductCode,
+			Description:     line.Description,
+			Quantity:        line.Quantity,
+			UnitPrice:       line.UnitPrice,
+			DiscountPercent: line.DiscountPercent,
+			TaxRate:         line.TaxRate,
+		}
+	}
+
+	sourceID := source.ID
+	doc := &models.SalesDocument{
+		DocumentType:     documentType,
+		SourceDocumentID: &sourceID,
+		CustomerName:     source.CustomerName,
+		CustomerEmail:    source.CustomerEmail,
+		CustomerAddress:  source.CustomerAddress,
+		Status:           models.SalesStatusDraft,
+		IssueDate:        time.Now().UTC().Truncate(24 * time.Hour),
+		Currency:         source.Currency,
+		Notes:            source.Notes,
+		Lines:            lines,
+		CreatedBy:        &userID,
+	}
+	applySalesTotals(doc)
+
+	if err := s.salesRepo.Create(ctx, tenantID, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// auditConverted records the creation of a document from another, once
+// committed, and tells the watchers
+func (s *SalesService) auditConverted(ctx context.Context, tenantID, userID uuid.UUID, source, doc *models.SalesDocument) {
+	s.auditService.LogEvent(ctx, tenantID, userID, salesAuditConverted, models.ResourceSales, doc.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"source_document_number": source.DocumentNumber,
+		"document_number":        doc.DocumentNumber,
+		"document_type":          doc.DocumentType,
+	})
+	s.notifyWatchers(ctx, tenantID, userID, salesAuditConverted, doc)
+}
+
+// notifyWatchers hands a change of a document to the watch service, as an
+// event of its audit action. Failures are only logged: the change is already
+// committed. Deletions reach watchers once the staged deletion is executed.
+func (s *SalesService) notifyWatchers(ctx context.Context, tenantID, userID uuid.UUID, action string, doc *models.SalesDocument) {
+	object, err := json.Marshal(doc)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode sales document %s for its watchers: %v", doc.ID, err)
+		return
+	}
+
+	docID := doc.ID
+	event := &models.Event{
+		ID:           uuid.New(),
+		Type:         action,
+		OccurredAt:   time.Now().UTC(),
+		TenantID:     tenantID,
+		ResourceType: models.ResourceSales,
+		ResourceID:   &docID,
+		ActorID:      &userID,
+		Object:       object,
+	}
+	if err := s.watchService.HandleEvent(ctx, event); err != nil {
+		log.Printf("⚠️  Failed to notify watchers of sales document %s: %v", doc.ID, err)
+	}
+}
+
+// buildSalesLines converts request lines into document lines
+func buildSalesLines(reqLines []models.SalesLineRequest) []models.SalesDocumentLine {
+	lines := make([]models.SalesDocumentLine, len(reqLines))
+	for i, l := range reqLines {
+		lines[i] = models.SalesDocumentLine{
+			ProductCode:     l.ProductCode,
+			Description:     l.Description,
+			Quantity:        l.Quantity,
+			UnitPrice:       l.UnitPrice,
+			DiscountPercent: l.DiscountPercent,
+			TaxRate:         l.TaxRate,
+		}
+	}
+	return lines
+}
+
+// applySalesTotals computes line totals and document totals, rounded to cents
+func applySalesTotals(doc *models.SalesDocument) {
+	var subtotal, discountTotal, taxTotal float64
+
+	for i := range doc.Lines {
+		line := &doc.Lines[i]
+		gross := line.Quantity * line.UnitPrice
+		discount := roundMoney(gross * line.DiscountPercent / 100)
+		net := roundMoney(gross) - discount
+
+		line.LineTotal = net
+		subtotal += roundMoney(gross)
+		discountTotal += discount
+		taxTotal += roundMoney(net * line.TaxRate / 100)
+	}
+
+	doc.Subtotal = roundMoney(subtotal)
+	doc.DiscountTotal = roundMoney(discountTotal)
+	doc.TaxTotal = roundMoney(taxTotal)
+	doc.Total = roundMoney(doc.Subtotal - doc.DiscountTotal + doc.TaxTotal)
+}
+
+// roundMoney rounds an amount to 2 decimal places
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
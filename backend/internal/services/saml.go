@@ -0,0 +1,585 @@
+package services
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/utils"
+)
+
+// SAML and XML Signature namespaces and URIs
+const (
+	samlProtocolNS  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlAssertionNS = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlMetadataNS  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	xmlDSigNS       = "http://www.w3.org/2000/09/xmldsig#"
+
+	samlBindingRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlBindingPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlStatusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlBearer          = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlNameIDEmail     = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+
+	xmlExcC14N         = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	xmlDSigEnveloped   = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	xmlDSigSHA256      = "http://www.w3.org/2001/04/xmlenc#sha256"
+	xmlDSigSHA512      = "http://www.w3.org/2001/04/xmlenc#sha512"
+	xmlDSigRSASHA256   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	xmlDSigRSASHA512   = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	xmlDSigECDSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	xmlDSigECDSASHA512 = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512"
+)
+
+// samlClockSkew is how far the clocks of an identity provider and the API
+// may differ when checking assertion validity windows
+const samlClockSkew = 1 * time.Minute
+
+// samlMaxResponseSize bounds the decoded SAML responses that are parsed
+const samlMaxResponseSize = 256 << 10
+
+// samlDigests are the digest algorithms accepted in signature references.
+// SHA-1 is refused.
+var samlDigests = map[string]crypto.Hash{
+	xmlDSigSHA256: crypto.SHA256,
+	xmlDSigSHA512: crypto.SHA512,
+}
+
+// samlSignatureMethods are the signature algorithms accepted, with their hash
+var samlSignatureMethods = map[string]crypto.Hash{
+	xmlDSigRSASHA256:   crypto.SHA256,
+	xmlDSigRSASHA512:   crypto.SHA512,
+	xmlDSigECDSASHA256: crypto.SHA256,
+	xmlDSigECDSASHA512: crypto.SHA512,
+}
+
+// samlIdentityProvider is what an identity provider's metadata tells about it
+type samlIdentityProvider struct {
+	EntityID     string
+	SSOURL       string   // Where AuthnRequests are sent (HTTP-Redirect binding)
+	Certificates []string // PEM signing certificates
+}
+
+// samlAssertion is what a verified assertion tells about the user
+type samlAssertion struct {
+	NameID       string
+	NameIDFormat string
+	Attributes   map[string][]string // By attribute Name, and by FriendlyName
+}
+
+// parseSAMLMetadata reads the entity ID, sign-on URL and signing
+// certificates of the identity provider described by metadata. Metadata
+// signatures are not checked: the metadata is uploaded by a tenant
+// administrator.
+func parseSAMLMetadata(metadata string, allowInsecure bool) (*samlIdentityProvider, error) {
+	root, err := parseXMLDocument([]byte(strings.TrimSpace(metadata)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml metadata: %v", err)
+	}
+
+	descriptors := []*xmlNode{root}
+	if root.is(samlMetadataNS, "EntitiesDescriptor") {
+		descriptors = root.elements(samlMetadataNS, "EntityDescriptor")
+	}
+
+	for _, entity := range descriptors {
+		if !entity.is(samlMetadataNS, "EntityDescriptor") {
+			continue
+		}
+		idp := entity.element(samlMetadataNS, "IDPSSODescriptor")
+		if idp == nil {
+			continue
+		}
+
+		provider := &samlIdentityProvider{EntityID: entity.attr("entityID")}
+		if provider.EntityID == "" {
+			return nil, fmt.Errorf("invalid saml metadata: entityID is missing")
+		}
+
+		for _, service := range idp.elements(samlMetadataNS, "SingleSignOnService") {
+			if service.attr("Binding") == samlBindingRedirect {
+				provider.SSOURL = service.attr("Location")
+				break
+			}
+		}
+		if provider.SSOURL == "" {
+			return nil, fmt.Errorf("invalid saml metadata: no SingleSignOnService with the HTTP-Redirect binding")
+		}
+		if err := validateSAMLURL(provider.SSOURL, allowInsecure); err != nil {
+			return nil, err
+		}
+
+		for _, key := range idp.elements(samlMetadataNS, "KeyDescriptor") {
+			if use := key.attr("use"); use != "" && use != "signing" {
+				continue
+			}
+			data := key.path(xmlDSigNS, "KeyInfo", "X509Data", "X509Certificate")
+			if data == nil {
+				continue
+			}
+			der, err := base64.StdEncoding.DecodeString(stripXMLSpace(data.text()))
+			if err != nil {
+				return nil, fmt.Errorf("invalid saml metadata: invalid certificate")
+			}
+			if _, err := x509.ParseCertificate(der); err != nil {
+				return nil, fmt.Errorf("invalid saml metadata: invalid certificate: %v", err)
+			}
+			provider.Certificates = append(provider.Certificates, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+		}
+		if len(provider.Certificates) == 0 {
+			return nil, fmt.Errorf("invalid saml metadata: no signing certificate")
+		}
+
+		return provider, nil
+	}
+
+	return nil, fmt.Errorf("invalid saml metadata: no identity provider (IDPSSODescriptor) found")
+}
+
+// validateSAMLURL checks an identity provider URL users are sent to
+func validateSAMLURL(rawURL string, allowInsecure bool) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid saml metadata: invalid SingleSignOnService location")
+	}
+	if parsed.Scheme != "https" && !(allowInsecure && parsed.Scheme == "http") {
+		return fmt.Errorf("invalid saml metadata: the SingleSignOnService location must use https")
+	}
+	return nil
+}
+
+// newSAMLRequestID generates the ID of an AuthnRequest. IDs must not start
+// with a digit.
+func newSAMLRequestID() (string, error) {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString([]byte(token))[:40], nil
+}
+
+// samlAuthnRequestURL builds the URL sending a user to the identity provider
+// with an AuthnRequest (HTTP-Redirect binding). Requests are not signed.
+func samlAuthnRequestURL(idp *samlIdentityProvider, spEntityID, acsURL, requestID, relayState string) (string, error) {
+	var request bytes.Buffer
+	request.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + samlProtocolNS + `" xmlns:saml="` + samlAssertionNS + `"`)
+	request.WriteString(` ID="` + template.HTMLEscapeString(requestID) + `" Version="2.0"`)
+	request.WriteString(` IssueInstant="` + time.Now().UTC().Format("2006-01-02T15:04:05Z") + `"`)
+	request.WriteString(` Destination="` + template.HTMLEscapeString(idp.SSOURL) + `"`)
+	request.WriteString(` AssertionConsumerServiceURL="` + template.HTMLEscapeString(acsURL) + `"`)
+	request.WriteString(` ProtocolBinding="` + samlBindingPOST + `">`)
+	request.WriteString(`<saml:Issuer>` + template.HTMLEscapeString(spEntityID) + `</saml:Issuer>`)
+	request.WriteString(`<samlp:NameIDPolicy AllowCreate="true"/>`)
+	request.WriteString(`</samlp:AuthnRequest>`)
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := writer.Write(request.Bytes()); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	query.Set("RelayState", relayState)
+
+	separator := "?"
+	if strings.Contains(idp.SSOURL, "?") {
+		separator = "&"
+	}
+	return idp.SSOURL + separator + query.Encode(), nil
+}
+
+// samlServiceProviderMetadata describes the API as a service provider, for
+// tenants to register at their identity provider
+func samlServiceProviderMetadata(spEntityID, acsURL string) []byte {
+	var metadata bytes.Buffer
+	metadata.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	metadata.WriteString(`<md:EntityDescriptor xmlns:md="` + samlMetadataNS + `" entityID="` + template.HTMLEscapeString(spEntityID) + `">`)
+	metadata.WriteString(`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + samlProtocolNS + `">`)
+	metadata.WriteString(`<md:NameIDFormat>` + samlNameIDEmail + `</md:NameIDFormat>`)
+	metadata.WriteString(`<md:AssertionConsumerService Binding="` + samlBindingPOST + `" Location="` + template.HTMLEscapeString(acsURL) + `" index="0" isDefault="true"/>`)
+	metadata.WriteString(`</md:SPSSODescriptor>`)
+	metadata.WriteString(`</md:EntityDescriptor>` + "\n")
+	return metadata.Bytes()
+}
+
+// verifySAMLResponse verifies a base64 SAMLResponse posted to the assertion
+// consumer service and returns its assertion. The response or its assertion
+// must be signed by one of the identity provider's certificates, the
+// assertion must be addressed to this service provider and answer
+// requestID, and it must be within its validity window. Only the signed
+// bytes are read, so content outside the signature cannot be slipped in.
+func verifySAMLResponse(encoded string, idp *samlIdentityProvider, spEntityID, acsURL, requestID string, now time.Time) (*samlAssertion, error) {
+	data, err := base64.StdEncoding.DecodeString(stripXMLSpace(encoded))
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid saml response")
+	}
+	if len(data) > samlMaxResponseSize {
+		return nil, fmt.Errorf("invalid saml response: too large")
+	}
+
+	response, err := parseXMLDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml response: %v", err)
+	}
+	if !response.is(samlProtocolNS, "Response") {
+		return nil, fmt.Errorf("invalid saml response: not a Response")
+	}
+
+	if status := response.path(samlProtocolNS, "Status", "StatusCode"); status == nil || status.attr("Value") != samlStatusSuccess {
+		return nil, utils.NewUnauthorizedError("SAML_REJECTED", "saml sign-on rejected by identity provider")
+	}
+	if destination := response.attr("Destination"); destination != "" && destination != acsURL {
+		return nil, fmt.Errorf("invalid saml response: wrong destination")
+	}
+	if response.element(samlAssertionNS, "EncryptedAssertion") != nil {
+		return nil, fmt.Errorf("invalid saml response: encrypted assertions are not supported")
+	}
+	if len(response.elements(samlAssertionNS, "Assertion")) != 1 {
+		return nil, fmt.Errorf("invalid saml response: expected one assertion")
+	}
+
+	certificates, err := parseSAMLCertificates(idp.Certificates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the assertion from the bytes the signature covers
+	var assertion *xmlNode
+	if response.element(xmlDSigNS, "Signature") != nil {
+		signed, err := verifyXMLSignature(response, certificates)
+		if err != nil {
+			return nil, err
+		}
+		assertions := signed.elements(samlAssertionNS, "Assertion")
+		if len(assertions) != 1 {
+			return nil, fmt.Errorf("invalid saml response: expected one assertion")
+		}
+		assertion = assertions[0]
+	} else {
+		unsigned := response.element(samlAssertionNS, "Assertion")
+		if unsigned.element(xmlDSigNS, "Signature") == nil {
+			return nil, fmt.Errorf("invalid saml response: not signed")
+		}
+		if assertion, err = verifyXMLSignature(unsigned, certificates); err != nil {
+			return nil, err
+		}
+	}
+
+	return readSAMLAssertion(assertion, idp.EntityID, spEntityID, acsURL, requestID, now)
+}
+
+// readSAMLAssertion checks a verified assertion's issuer, subject
+// confirmation and conditions, and reads its subject and attributes
+func readSAMLAssertion(assertion *xmlNode, idpEntityID, spEntityID, acsURL, requestID string, now time.Time) (*samlAssertion, error) {
+	if issuer := assertion.element(samlAssertionNS, "Issuer"); issuer == nil || issuer.text() != idpEntityID {
+		return nil, fmt.Errorf("invalid saml response: wrong issuer")
+	}
+
+	subject := assertion.element(samlAssertionNS, "Subject")
+	if subject == nil {
+		return nil, fmt.Errorf("invalid saml response: no subject")
+	}
+	nameID := subject.element(samlAssertionNS, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return nil, fmt.Errorf("invalid saml response: no NameID")
+	}
+
+	confirmed := false
+	for _, confirmation := range subject.elements(samlAssertionNS, "SubjectConfirmation") {
+		if confirmation.attr("Method") != samlBearer {
+			continue
+		}
+		data := confirmation.element(samlAssertionNS, "SubjectConfirmationData")
+		if data == nil || data.attr("Recipient") != acsURL || data.attr("InResponseTo") != requestID {
+			continue
+		}
+		notOnOrAfter, err := parseSAMLTime(data.attr("NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, fmt.Errorf("invalid saml response: subject not confirmed for this sign-on")
+	}
+
+	conditions := assertion.element(samlAssertionNS, "Conditions")
+	if conditions == nil {
+		return nil, fmt.Errorf("invalid saml response: no conditions")
+	}
+	if value := conditions.attr("NotBefore"); value != "" {
+		notBefore, err := parseSAMLTime(value)
+		if err != nil || now.Add(samlClockSkew).Before(notBefore) {
+			return nil, fmt.Errorf("invalid saml response: assertion not yet valid")
+		}
+	}
+	if value := conditions.attr("NotOnOrAfter"); value != "" {
+		notOnOrAfter, err := parseSAMLTime(value)
+		if err != nil || !now.Before(notOnOrAfter.Add(samlClockSkew)) {
+			return nil, fmt.Errorf("invalid saml response: assertion expired")
+		}
+	}
+	restrictions := conditions.elements(samlAssertionNS, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, fmt.Errorf("invalid saml response: no audience restriction")
+	}
+	// Every restriction must include this service provider
+	for _, restriction := range restrictions {
+		allowed := false
+		for _, audience := range restriction.elements(samlAssertionNS, "Audience") {
+			if audience.text() == spEntityID {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("invalid saml response: wrong audience")
+		}
+	}
+
+	result := &samlAssertion{
+		NameID:       nameID.text(),
+		NameIDFormat: nameID.attr("Format"),
+		Attributes:   make(map[string][]string),
+	}
+	for _, statement := range assertion.elements(samlAssertionNS, "AttributeStatement") {
+		for _, attribute := range statement.elements(samlAssertionNS, "Attribute") {
+			var values []string
+			for _, value := range attribute.elements(samlAssertionNS, "AttributeValue") {
+				if text := value.text(); text != "" {
+					values = append(values, text)
+				}
+			}
+			for _, name := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if name != "" {
+					result.Attributes[name] = append(result.Attributes[name], values...)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// verifyXMLSignature verifies the enveloped signature of element (a direct
+// child ds:Signature referencing the element's ID) and returns the element
+// re-parsed from the canonical bytes the signature covers
+func verifyXMLSignature(element *xmlNode, certificates []*x509.Certificate) (*xmlNode, error) {
+	signatures := element.elements(xmlDSigNS, "Signature")
+	if len(signatures) != 1 {
+		return nil, fmt.Errorf("invalid saml signature: expected one signature")
+	}
+	signature := signatures[0]
+
+	signedInfo := signature.element(xmlDSigNS, "SignedInfo")
+	if signedInfo == nil {
+		return nil, fmt.Errorf("invalid saml signature: no SignedInfo")
+	}
+
+	c14n := signedInfo.element(xmlDSigNS, "CanonicalizationMethod")
+	if c14n == nil || c14n.attr("Algorithm") != xmlExcC14N {
+		return nil, fmt.Errorf("invalid saml signature: unsupported canonicalization")
+	}
+
+	method := signedInfo.element(xmlDSigNS, "SignatureMethod")
+	if method == nil {
+		return nil, fmt.Errorf("invalid saml signature: no SignatureMethod")
+	}
+	signatureHash, ok := samlSignatureMethods[method.attr("Algorithm")]
+	if !ok {
+		return nil, fmt.Errorf("invalid saml signature: unsupported algorithm %s", method.attr("Algorithm"))
+	}
+
+	// The one reference must be to the signed element itself
+	references := signedInfo.elements(xmlDSigNS, "Reference")
+	if len(references) != 1 {
+		return nil, fmt.Errorf("invalid saml signature: expected one reference")
+	}
+	reference := references[0]
+	id := element.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return nil, fmt.Errorf("invalid saml signature: reference does not cover the signed element")
+	}
+	root := element
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.countIDs(id) != 1 {
+		return nil, fmt.Errorf("invalid saml signature: duplicate ID")
+	}
+
+	var inclusive map[string]bool
+	transforms := reference.element(xmlDSigNS, "Transforms")
+	if transforms == nil {
+		return nil, fmt.Errorf("invalid saml signature: no transforms")
+	}
+	canonicalized := false
+	for _, transform := range transforms.elements(xmlDSigNS, "Transform") {
+		switch transform.attr("Algorithm") {
+		case xmlDSigEnveloped:
+			if canonicalized {
+				return nil, fmt.Errorf("invalid saml signature: unsupported transforms")
+			}
+		case xmlExcC14N:
+			if canonicalized {
+				return nil, fmt.Errorf("invalid saml signature: unsupported transforms")
+			}
+			canonicalized = true
+			inclusive = samlInclusivePrefixes(transform)
+		default:
+			return nil, fmt.Errorf("invalid saml signature: unsupported transform %s", transform.attr("Algorithm"))
+		}
+	}
+	if !canonicalized {
+		return nil, fmt.Errorf("invalid saml signature: unsupported transforms")
+	}
+
+	digestMethod := reference.element(xmlDSigNS, "DigestMethod")
+	if digestMethod == nil {
+		return nil, fmt.Errorf("invalid saml signature: no DigestMethod")
+	}
+	digestHash, ok := samlDigests[digestMethod.attr("Algorithm")]
+	if !ok {
+		return nil, fmt.Errorf("invalid saml signature: unsupported digest %s", digestMethod.attr("Algorithm"))
+	}
+	digestValue := reference.element(xmlDSigNS, "DigestValue")
+	if digestValue == nil {
+		return nil, fmt.Errorf("invalid saml signature: no DigestValue")
+	}
+	expectedDigest, err := base64.StdEncoding.DecodeString(stripXMLSpace(digestValue.text()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml signature: invalid DigestValue")
+	}
+
+	canonical := element.canonicalize(signature, inclusive)
+	if subtle.ConstantTimeCompare(samlHash(digestHash, canonical), expectedDigest) != 1 {
+		return nil, fmt.Errorf("invalid saml signature: digest mismatch")
+	}
+
+	signatureValue := signature.element(xmlDSigNS, "SignatureValue")
+	if signatureValue == nil {
+		return nil, fmt.Errorf("invalid saml signature: no SignatureValue")
+	}
+	sig, err := base64.StdEncoding.DecodeString(stripXMLSpace(signatureValue.text()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml signature: invalid SignatureValue")
+	}
+
+	signedInfoDigest := samlHash(signatureHash, signedInfo.canonicalize(nil, samlInclusivePrefixes(c14n)))
+	verified := false
+	for _, certificate := range certificates {
+		if verifySAMLSignatureValue(certificate, signatureHash, signedInfoDigest, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("invalid saml signature: not signed by the identity provider")
+	}
+
+	signed, err := parseXMLDocument(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saml signature: %v", err)
+	}
+	return signed, nil
+}
+
+// verifySAMLSignatureValue verifies a signature over digest with a
+// certificate's RSA or ECDSA key. ECDSA signatures are the concatenated r and
+// s values.
+func verifySAMLSignatureValue(certificate *x509.Certificate, hash crypto.Hash, digest, sig []byte) bool {
+	switch key := certificate.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		if len(sig) == 0 || len(sig)%2 != 0 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// samlInclusivePrefixes reads the InclusiveNamespaces PrefixList of an
+// exclusive canonicalization method or transform
+func samlInclusivePrefixes(method *xmlNode) map[string]bool {
+	list := method.element(xmlExcC14N, "InclusiveNamespaces")
+	if list == nil {
+		return nil
+	}
+	prefixes := make(map[string]bool)
+	for _, prefix := range strings.Fields(list.attr("PrefixList")) {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		prefixes[prefix] = true
+	}
+	return prefixes
+}
+
+// parseSAMLCertificates parses an identity provider's PEM certificates
+func parseSAMLCertificates(pems []string) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for _, data := range pems {
+		block, _ := pem.Decode([]byte(data))
+		if block == nil {
+			return nil, fmt.Errorf("invalid saml certificate")
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saml certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
+
+func samlHash(hash crypto.Hash, data []byte) []byte {
+	switch hash {
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+}
+
+// parseSAMLTime parses an xs:dateTime
+func parseSAMLTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// stripXMLSpace removes the whitespace base64 content may be wrapped with
+func stripXMLSpace(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIdPEntityID = "https://idp.example.com/metadata"
+	testSPEntityID  = "https://api.example.com/api/auth/sso/saml/metadata"
+	testACSURL      = "https://api.example.com/api/auth/sso/saml/acs"
+	testRequestID   = "_request1"
+)
+
+// testSAMLSigner is an identity provider key with its self-signed certificate
+type testSAMLSigner struct {
+	key         *rsa.PrivateKey
+	certificate string // PEM
+}
+
+func newTestSAMLSigner(t *testing.T) *testSAMLSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &testSAMLSigner{
+		key:         key,
+		certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// testSAMLAssertion writes an assertion for jane@acme.com. It is written in
+// its exclusive canonical form (sorted attributes, no empty-element tags),
+// so the digest computed over these bytes does not depend on the
+// canonicalization under test.
+func testSAMLAssertion(id, nameID string, now, conditionsExpiry time.Time) string {
+	instant := func(t time.Time) string { return t.UTC().Format("2006-01-02T15:04:05Z") }
+	return `<saml:Assertion xmlns:saml="` + samlAssertionNS + `" ID="` + id + `" IssueInstant="` + instant(now) + `" Version="2.0">` +
+		`<saml:Issuer>` + testIdPEntityID + `</saml:Issuer>` +
+		`<saml:Subject>` +
+		`<saml:NameID Format="` + samlNameIDEmail + `">` + nameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="` + samlBearer + `">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + testRequestID + `" NotOnOrAfter="` + instant(now.Add(5*time.Minute)) + `" Recipient="` + testACSURL + `"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="` + instant(now.Add(-5*time.Minute)) + `" NotOnOrAfter="` + instant(conditionsExpiry) + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + testSPEntityID + `</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute FriendlyName="givenName" Name="urn:oid:2.5.4.42"><saml:AttributeValue>Jane</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+// sign inserts an enveloped signature of a canonical assertion or response
+// after its Issuer. SignedInfo is also written in its canonical form.
+func (s *testSAMLSigner) sign(t *testing.T, element, id string) string {
+	t.Helper()
+	signedInfo := `<ds:SignedInfo xmlns:ds="` + xmlDSigNS + `">` +
+		`<ds:CanonicalizationMethod Algorithm="` + xmlExcC14N + `"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="` + xmlDSigRSASHA256 + `"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="` + xmlDSigEnveloped + `"></ds:Transform>` +
+		`<ds:Transform Algorithm="` + xmlExcC14N + `"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="` + xmlDSigSHA256 + `"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + digestOf(element) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	signedInfoDigest := sha256.Sum256([]byte(signedInfo))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, signedInfoDigest[:])
+	require.NoError(t, err)
+
+	signature := `<ds:Signature xmlns:ds="` + xmlDSigNS + `">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(sig) + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+
+	issuerEnd := strings.Index(element, `</saml:Issuer>`) + len(`</saml:Issuer>`)
+	return element[:issuerEnd] + signature + element[issuerEnd:]
+}
+
+// digestOf returns the base64 SHA-256 digest of a canonical element
+func digestOf(canonical string) string {
+	digest := sha256.Sum256([]byte(canonical))
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// testSAMLResponse wraps assertions in a successful response and encodes it
+// as posted to the assertion consumer service
+func testSAMLResponse(assertions ...string) string {
+	response := `<samlp:Response xmlns:samlp="` + samlProtocolNS + `" xmlns:saml="` + samlAssertionNS + `"` +
+		` Destination="` + testACSURL + `" ID="_response1" InResponseTo="` + testRequestID + `" IssueInstant="2026-01-01T00:00:00Z" Version="2.0">` +
+		`<saml:Issuer>` + testIdPEntityID + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="` + samlStatusSuccess + `"/></samlp:Status>` +
+		strings.Join(assertions, "") +
+		`</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+// testSignedSAMLResponse writes a response signed as a whole around an
+// unsigned assertion. The response is in its exclusive canonical form: the
+// saml namespace is declared on each element using it, not on the response.
+func testSignedSAMLResponse(t *testing.T, signer *testSAMLSigner, assertion string) string {
+	t.Helper()
+	response := `<samlp:Response xmlns:samlp="` + samlProtocolNS + `"` +
+		` Destination="` + testACSURL + `" ID="_response1" InResponseTo="` + testRequestID + `" IssueInstant="2026-01-01T00:00:00Z" Version="2.0">` +
+		`<saml:Issuer xmlns:saml="` + samlAssertionNS + `">` + testIdPEntityID + `</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="` + samlStatusSuccess + `"></samlp:StatusCode></samlp:Status>` +
+		assertion +
+		`</samlp:Response>`
+	return signer.sign(t, response, "_response1")
+}
+
+func TestVerifySAMLResponse(t *testing.T) {
+	signer := newTestSAMLSigner(t)
+	otherSigner := newTestSAMLSigner(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assertion := testSAMLAssertion("_assertion1", "jane@acme.com", now, now.Add(10*time.Minute))
+	signed := signer.sign(t, assertion, "_assertion1")
+	tampered := strings.Replace(assertion, "jane@acme.com", "admin@acme.com", 1)
+
+	// forged is an assertion for admin@acme.com carrying the signature of the
+	// genuine one, which is hidden in its Advice
+	signature := signed[strings.Index(signed, `<ds:Signature`) : strings.Index(signed, `</ds:Signature>`)+len(`</ds:Signature>`)]
+	signedResponse := testSignedSAMLResponse(t, signer, assertion)
+	forged := func(id string) string {
+		return strings.Replace(testSAMLAssertion(id, "admin@acme.com", now, now.Add(10*time.Minute)),
+			`</saml:Issuer>`, `</saml:Issuer>`+signature+`<saml:Advice>`+signed+`</saml:Advice>`, 1)
+	}
+
+	tests := []struct {
+		name        string
+		response    string
+		certificate string
+		wantErr     string
+	}{
+		{
+			name:     "Valid signed assertion",
+			response: testSAMLResponse(signed),
+		},
+		{
+			name:     "Valid signed response",
+			response: base64.StdEncoding.EncodeToString([]byte(signedResponse)),
+		},
+		{
+			name:     "Tampered signed response",
+			response: base64.StdEncoding.EncodeToString([]byte(strings.Replace(signedResponse, "jane@acme.com", "admin@acme.com", 1))),
+			wantErr:  "digest mismatch",
+		},
+		{
+			name:     "Tampered assertion",
+			response: testSAMLResponse(strings.Replace(signed, "jane@acme.com", "admin@acme.com", 1)),
+			wantErr:  "digest mismatch",
+		},
+		{
+			// The digest matches the tampered assertion, but the signature
+			// covers the original digest
+			name:     "Tampered assertion with its digest",
+			response: testSAMLResponse(strings.Replace(strings.Replace(signed, "jane@acme.com", "admin@acme.com", 1), digestOf(assertion), digestOf(tampered), 1)),
+			wantErr:  "not signed by the identity provider",
+		},
+		{
+			name:     "Unsigned assertion",
+			response: testSAMLResponse(assertion),
+			wantErr:  "not signed",
+		},
+		{
+			name:     "Signed assertion next to an unsigned one",
+			response: testSAMLResponse(signed, testSAMLAssertion("_assertion2", "admin@acme.com", now, now.Add(10*time.Minute))),
+			wantErr:  "expected one assertion",
+		},
+		{
+			name:     "Signature wrapping",
+			response: testSAMLResponse(forged("_forged")),
+			wantErr:  "reference does not cover the signed element",
+		},
+		{
+			name:     "Signature wrapping with a duplicate ID",
+			response: testSAMLResponse(forged("_assertion1")),
+			wantErr:  "duplicate ID",
+		},
+		{
+			name:        "Wrong certificate",
+			response:    testSAMLResponse(signed),
+			certificate: otherSigner.certificate,
+			wantErr:     "not signed by the identity provider",
+		},
+		{
+			name: "Expired conditions",
+			response: testSAMLResponse(signer.sign(t,
+				testSAMLAssertion("_assertion1", "jane@acme.com", now, now.Add(-2*time.Minute)), "_assertion1")),
+			wantErr: "assertion expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certificate := signer.certificate
+			if tt.certificate != "" {
+				certificate = tt.certificate
+			}
+			idp := &samlIdentityProvider{EntityID: testIdPEntityID, Certificates: []string{certificate}}
+
+			result, err := verifySAMLResponse(tt.response, idp, testSPEntityID, testACSURL, testRequestID, now)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "jane@acme.com", result.NameID)
+			assert.Equal(t, samlNameIDEmail, result.NameIDFormat)
+			assert.Equal(t, []string{"Jane"}, result.Attributes["givenName"])
+		})
+	}
+}
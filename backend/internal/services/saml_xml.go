@@ -0,0 +1,339 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// samlMaxXMLDepth bounds the nesting of parsed SAML messages
+const samlMaxXMLDepth = 64
+
+const xmlNamespaceURI = "http://www.w3.org/XML/1998/namespace"
+
+// xmlNode is an element of a parsed SAML message. Unlike encoding/xml
+// unmarshalling, it keeps namespace prefixes and declarations, so an element
+// can be canonicalized exactly as it was signed.
+type xmlNode struct {
+	prefix   string
+	local    string
+	attrs    []xml.Attr // Name.Space is the prefix; includes xmlns declarations
+	children []xmlChild
+	parent   *xmlNode
+}
+
+// xmlChild is either an element or character data
+type xmlChild struct {
+	elem *xmlNode
+	text string
+}
+
+// parseXMLDocument parses an XML document into its root element. Documents
+// with a DTD, comments or processing instructions are refused: SAML messages
+// have none, and they are the usual vehicles of parser and signature attacks.
+func parseXMLDocument(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root, current *xmlNode
+	depth := 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, fmt.Errorf("invalid xml: more than one root element")
+			}
+			depth++
+			if depth > samlMaxXMLDepth {
+				return nil, fmt.Errorf("invalid xml: nested too deeply")
+			}
+			node := &xmlNode{prefix: t.Name.Space, local: t.Name.Local, attrs: t.Copy().Attr, parent: current}
+			if current == nil {
+				root = node
+			} else {
+				current.children = append(current.children, xmlChild{elem: node})
+			}
+			current = node
+
+		case xml.EndElement:
+			if current == nil || t.Name.Space != current.prefix || t.Name.Local != current.local {
+				return nil, fmt.Errorf("invalid xml: unexpected end element %s", t.Name.Local)
+			}
+			depth--
+			current = current.parent
+
+		case xml.CharData:
+			if current == nil {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, fmt.Errorf("invalid xml: text outside the root element")
+				}
+				continue
+			}
+			current.children = append(current.children, xmlChild{text: string(t)})
+
+		case xml.ProcInst:
+			if t.Target != "xml" || root != nil {
+				return nil, fmt.Errorf("invalid xml: processing instructions are not allowed")
+			}
+
+		case xml.Comment:
+			return nil, fmt.Errorf("invalid xml: comments are not allowed")
+
+		case xml.Directive:
+			return nil, fmt.Errorf("invalid xml: DTDs are not allowed")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, fmt.Errorf("invalid xml: incomplete document")
+	}
+	if err := root.checkNamespaces(); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// checkNamespaces checks every prefix used in the subtree is declared
+func (n *xmlNode) checkNamespaces() error {
+	if _, ok := n.lookupNamespace(n.prefix); !ok && n.prefix != "" {
+		return fmt.Errorf("invalid xml: undeclared prefix %s", n.prefix)
+	}
+	for _, attr := range n.attrs {
+		if attr.Name.Space == "" || attr.Name.Space == "xmlns" {
+			continue
+		}
+		if _, ok := n.lookupNamespace(attr.Name.Space); !ok {
+			return fmt.Errorf("invalid xml: undeclared prefix %s", attr.Name.Space)
+		}
+	}
+	for _, child := range n.children {
+		if child.elem != nil {
+			if err := child.elem.checkNamespaces(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookupNamespace resolves a prefix ("" for the default namespace) in the
+// scope of the element
+func (n *xmlNode) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespaceURI, true
+	}
+	for node := n; node != nil; node = node.parent {
+		for _, attr := range node.attrs {
+			if (prefix == "" && attr.Name.Space == "" && attr.Name.Local == "xmlns") ||
+				(prefix != "" && attr.Name.Space == "xmlns" && attr.Name.Local == prefix) {
+				return attr.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// namespace returns the namespace URI of the element
+func (n *xmlNode) namespace() string {
+	uri, _ := n.lookupNamespace(n.prefix)
+	return uri
+}
+
+// is reports whether the element has the given namespace and local name
+func (n *xmlNode) is(namespace, local string) bool {
+	return n.local == local && n.namespace() == namespace
+}
+
+// elements returns the child elements with the given namespace and local name
+func (n *xmlNode) elements(namespace, local string) []*xmlNode {
+	var found []*xmlNode
+	for _, child := range n.children {
+		if child.elem != nil && child.elem.is(namespace, local) {
+			found = append(found, child.elem)
+		}
+	}
+	return found
+}
+
+// element returns the first child element with the given namespace and local
+// name, or nil
+func (n *xmlNode) element(namespace, local string) *xmlNode {
+	for _, child := range n.children {
+		if child.elem != nil && child.elem.is(namespace, local) {
+			return child.elem
+		}
+	}
+	return nil
+}
+
+// path follows a chain of child elements of one namespace, or returns nil
+func (n *xmlNode) path(namespace string, locals ...string) *xmlNode {
+	node := n
+	for _, local := range locals {
+		if node = node.element(namespace, local); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// attr returns the value of an unqualified attribute
+func (n *xmlNode) attr(local string) string {
+	for _, attr := range n.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text returns the element's own character data, trimmed
+func (n *xmlNode) text() string {
+	var b strings.Builder
+	for _, child := range n.children {
+		if child.elem == nil {
+			b.WriteString(child.text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// countIDs counts the elements of the subtree with the ID attribute id
+func (n *xmlNode) countIDs(id string) int {
+	count := 0
+	if n.attr("ID") == id {
+		count++
+	}
+	for _, child := range n.children {
+		if child.elem != nil {
+			count += child.elem.countIDs(id)
+		}
+	}
+	return count
+}
+
+// canonicalize serializes the subtree of n with Exclusive XML
+// Canonicalization 1.0 (without comments), leaving out the element exclude
+// (the enveloped signature). inclusive lists the prefixes of the transform's
+// InclusiveNamespaces PrefixList ("" for #default).
+func (n *xmlNode) canonicalize(exclude *xmlNode, inclusive map[string]bool) []byte {
+	var buf bytes.Buffer
+	n.writeCanonical(&buf, exclude, inclusive, map[string]string{})
+	return buf.Bytes()
+}
+
+func (n *xmlNode) writeCanonical(buf *bytes.Buffer, exclude *xmlNode, inclusive map[string]bool, rendered map[string]string) {
+	// Namespaces the element or its attributes use, and the inclusive ones
+	used := map[string]bool{n.prefix: true}
+	for _, attr := range n.attrs {
+		if attr.Name.Space != "" && attr.Name.Space != "xmlns" {
+			used[attr.Name.Space] = true
+		}
+	}
+	for prefix := range inclusive {
+		if _, ok := n.lookupNamespace(prefix); ok {
+			used[prefix] = true
+		}
+	}
+
+	type nsDecl struct{ prefix, uri string }
+	var decls []nsDecl
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		uri, _ := n.lookupNamespace(prefix)
+		current, ok := rendered[prefix]
+		if prefix == "" && uri == "" && (!ok || current == "") {
+			continue
+		}
+		if ok && current == uri {
+			continue
+		}
+		decls = append(decls, nsDecl{prefix, uri})
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+
+	if len(decls) > 0 {
+		inherited := rendered
+		rendered = make(map[string]string, len(inherited)+len(decls))
+		for prefix, uri := range inherited {
+			rendered[prefix] = uri
+		}
+		for _, decl := range decls {
+			rendered[decl.prefix] = decl.uri
+		}
+	}
+
+	type canonicalAttr struct{ namespace, local, name, value string }
+	var attrs []canonicalAttr
+	for _, attr := range n.attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		name, namespace := attr.Name.Local, ""
+		if attr.Name.Space != "" {
+			name = attr.Name.Space + ":" + attr.Name.Local
+			namespace, _ = n.lookupNamespace(attr.Name.Space)
+		}
+		attrs = append(attrs, canonicalAttr{namespace, attr.Name.Local, name, attr.Value})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].namespace != attrs[j].namespace {
+			return attrs[i].namespace < attrs[j].namespace
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	qname := n.local
+	if n.prefix != "" {
+		qname = n.prefix + ":" + n.local
+	}
+
+	buf.WriteString("<" + qname)
+	for _, decl := range decls {
+		if decl.prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(" xmlns:" + decl.prefix + `="`)
+		}
+		buf.WriteString(escapeCanonicalAttr(decl.uri) + `"`)
+	}
+	for _, attr := range attrs {
+		buf.WriteString(" " + attr.name + `="` + escapeCanonicalAttr(attr.value) + `"`)
+	}
+	buf.WriteString(">")
+
+	for _, child := range n.children {
+		if child.elem == nil {
+			buf.WriteString(escapeCanonicalText(child.text))
+		} else if child.elem != exclude {
+			child.elem.writeCanonical(buf, exclude, inclusive, rendered)
+		}
+	}
+
+	buf.WriteString("</" + qname + ">")
+}
+
+var canonicalTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var canonicalAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeCanonicalText(text string) string {
+	return canonicalTextEscaper.Replace(text)
+}
+
+func escapeCanonicalAttr(value string) string {
+	return canonicalAttrEscaper.Replace(value)
+}
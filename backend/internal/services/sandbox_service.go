@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	sandboxAuditCreated = "sandbox.created"
+	sandboxAuditDeleted = "sandbox.deleted"
+)
+
+const (
+	sandboxSlugSuffix   = "-sandbox-"
+	sandboxExpiryBatch  = 20 // Max sandboxes removed per expiry run
+	maxTenantSlugLength = 63
+)
+
+// sandboxSkipTables are never copied into a sandbox: they hold credentials,
+// pending emails, staged deletes, broadcasts and history that only belong to
+// production, and files whose stored contents production owns
+var sandboxSkipTables = []string{
+	"sessions", "invitations", "audit_logs", "email_outbox", "pending_deletions",
+	"email_broadcasts", "email_broadcast_recipients", "recent_views", "files",
+}
+
+// SandboxService manages sandbox copies of tenants. Clones run in the
+// background (ProcessPending) and sandboxes are removed once they expire
+// (ExpireSandboxes); both are driven by the job runner.
+type SandboxService struct {
+	db           *sqlx.DB
+	sandboxRepo  repository.SandboxStore
+	tenantRepo   repository.TenantStore
+	auditService AuditManager
+	config       *config.SandboxConfig
+}
+
+// NewSandboxService creates a new sandbox service
+func NewSandboxService(
+	db *sqlx.DB,
+	sandboxRepo repository.SandboxStore,
+	tenantRepo repository.TenantStore,
+	auditService AuditManager,
+	cfg *config.SandboxConfig,
+) *SandboxService {
+	return &SandboxService{
+		db:           db,
+		sandboxRepo:  sandboxRepo,
+		tenantRepo:   tenantRepo,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// CreateSandbox requests a sandbox copy of the tenant. The sandbox tenant is
+// created right away but only becomes accessible once the clone job has
+// copied the data.
+func (s *SandboxService) CreateSandbox(ctx context.Context, tenantID, userID uuid.UUID, req *models.SandboxCreateRequest) (*models.TenantSandbox, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.IsSandbox() {
+		return nil, utils.NewBadRequestError("SANDBOX_FROM_SANDBOX", "sandboxes cannot be created from a sandbox")
+	}
+
+	count, err := s.sandboxRepo.CountActive(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= s.config.MaxPerTenant {
+		return nil, utils.NewBadRequestError("SANDBOX_LIMIT_REACHED", fmt.Sprintf("sandbox limit reached (%d)", s.config.MaxPerTenant))
+	}
+
+	ttl := s.config.DefaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > s.config.MaxTTL {
+		return nil, utils.NewBadRequestError("INVALID_SANDBOX_LIFETIME", fmt.Sprintf("sandbox lifetime cannot exceed %d days", int(s.config.MaxTTL.Hours()/24)))
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	anonymize := true
+	if req.Anonymize != nil {
+		anonymize = *req.Anonymize
+	}
+
+	sandboxTenant := &models.Tenant{
+		Slug:             sandboxSlug(tenant.Slug),
+		CompanyName:      tenant.CompanyName + " (Sandbox)",
+		Email:            tenant.Email,
+		PlanTier:         tenant.PlanTier,
+		DataRegion:       tenant.DataRegion,
+		Settings:         tenant.Settings,
+		SandboxOf:        &tenant.ID,
+		SandboxExpiresAt: &expiresAt,
+	}
+
+	sandbox := &models.TenantSandbox{
+		SourceTenantID: tenantID,
+		Name:           strings.TrimSpace(req.Name),
+		Anonymize:      anonymize,
+		ExpiresAt:      expiresAt,
+		RequestedBy:    userID,
+	}
+
+	if err := s.sandboxRepo.Create(ctx, sandboxTenant, sandbox); err != nil {
+		return nil, err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, sandboxAuditCreated, models.ResourceSandboxes, sandbox.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name":       sandbox.Name,
+		"slug":       sandboxTenant.Slug,
+		"anonymize":  anonymize,
+		"expires_at": expiresAt,
+	})
+
+	return sandbox, nil
+}
+
+// ListSandboxes lists a tenant's sandboxes
+func (s *SandboxService) ListSandboxes(ctx context.Context, tenantID uuid.UUID, includeDeleted bool, limit, offset int) ([]models.TenantSandbox, int, error) {
+	return s.sandboxRepo.List(ctx, tenantID, includeDeleted, limit, offset)
+}
+
+// GetSandbox retrieves a tenant's sandbox
+func (s *SandboxService) GetSandbox(ctx context.Context, tenantID, sandboxID uuid.UUID) (*models.TenantSandbox, error) {
+	return s.sandboxRepo.FindByID(ctx, tenantID, sandboxID)
+}
+
+// DeleteSandbox removes a sandbox and all of its data before it expires
+func (s *SandboxService) DeleteSandbox(ctx context.Context, tenantID, userID, sandboxID uuid.UUID) error {
+	sandbox, err := s.sandboxRepo.FindByID(ctx, tenantID, sandboxID)
+	if err != nil {
+		return err
+	}
+
+	switch sandbox.Status {
+	case models.SandboxStatusDeleted:
+		return utils.NewNotFoundError("SANDBOX_NOT_FOUND", "sandbox not found")
+	case models.SandboxStatusCloning:
+		return utils.NewConflictError("SANDBOX_NOT_READY", "sandbox is being cloned, try again once it is ready")
+	}
+
+	if err := s.removeSandbox(ctx, sandbox); err != nil {
+		return err
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, sandboxAuditDeleted, models.ResourceSandboxes, sandbox.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"name": sandbox.Name,
+	})
+
+	return nil
+}
+
+// RemoveTenantSandboxes removes all of a tenant's sandboxes with their data,
+// before the tenant itself is deleted. It fails while one is being cloned so
+// that the caller retries once the clone is over.
+func (s *SandboxService) RemoveTenantSandboxes(ctx context.Context, tenantID uuid.UUID) error {
+	for {
+		// Removed sandboxes are marked deleted and no longer listed
+		sandboxes, _, err := s.sandboxRepo.List(ctx, tenantID, false, sandboxExpiryBatch, 0)
+		if err != nil || len(sandboxes) == 0 {
+			return err
+		}
+
+		for i := range sandboxes {
+			if sandboxes[i].Status == models.SandboxStatusCloning {
+				return fmt.Errorf("sandbox %s is being cloned", sandboxes[i].ID)
+			}
+			if err := s.removeSandbox(ctx, &sandboxes[i]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ProcessPending clones the oldest pending sandbox. It is run periodically by
+// the background job runner and returns the number of clones attempted.
+func (s *SandboxService) ProcessPending(ctx context.Context) (int, error) {
+	sandbox, err := s.sandboxRepo.ClaimNext(ctx)
+	if err != nil || sandbox == nil {
+		return 0, err
+	}
+
+	if cloneErr := s.cloneSandbox(ctx, sandbox); cloneErr != nil {
+		log.Printf("⚠️  Sandbox %s clone failed: %v", sandbox.ID, cloneErr)
+
+		// Record the failure even if the run timed out; the partial sandbox
+		// tenant is removed since its data is unusable
+		ctx = context.WithoutCancel(ctx)
+		if sandbox.SandboxTenantID != nil {
+			if err := database.PurgeTenant(ctx, s.db, *sandbox.SandboxTenantID); err != nil {
+				log.Printf("⚠️  Sandbox %s cleanup failed: %v", sandbox.ID, err)
+			}
+		}
+		if err := s.sandboxRepo.MarkFailed(ctx, sandbox.ID, cloneErr.Error()); err != nil {
+			return 1, err
+		}
+		return 1, nil
+	}
+
+	return 1, s.sandboxRepo.MarkReady(ctx, sandbox)
+}
+
+// cloneSandbox copies the source tenant's data into the sandbox tenant
+func (s *SandboxService) cloneSandbox(ctx context.Context, sandbox *models.TenantSandbox) error {
+	if sandbox.SandboxTenantID == nil {
+		return fmt.Errorf("sandbox tenant no longer exists")
+	}
+	sandboxTenantID := *sandbox.SandboxTenantID
+
+	opts := database.CloneTenantOptions{
+		SkipTables: sandboxSkipTables,
+	}
+	if sandbox.Anonymize {
+		opts.Transform = func(ctx context.Context, tx *sqlx.Tx) error {
+			return s.sandboxRepo.Anonymize(ctx, tx, sandboxTenantID, sandbox.RequestedBy)
+		}
+	}
+
+	return database.CloneTenant(ctx, s.db, sandbox.SourceTenantID, sandboxTenantID, opts)
+}
+
+// ExpireSandboxes removes sandboxes past their expiry. It is run periodically
+// by the background job runner and returns the number of sandboxes removed.
+func (s *SandboxService) ExpireSandboxes(ctx context.Context) (int, error) {
+	sandboxes, err := s.sandboxRepo.ListExpired(ctx, sandboxExpiryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range sandboxes {
+		if err := s.removeSandbox(ctx, &sandboxes[i]); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// removeSandbox deletes the sandbox tenant with its data and marks the
+// sandbox deleted
+func (s *SandboxService) removeSandbox(ctx context.Context, sandbox *models.TenantSandbox) error {
+	if sandbox.SandboxTenantID != nil {
+		if err := database.PurgeTenant(ctx, s.db, *sandbox.SandboxTenantID); err != nil {
+			return fmt.Errorf("failed to remove sandbox data: %w", err)
+		}
+	}
+
+	return s.sandboxRepo.MarkDeleted(ctx, sandbox.ID)
+}
+
+// sandboxSlug derives a unique, DNS-safe slug for a sandbox of the tenant
+// (e.g. acme-sandbox-3f9a1c)
+func sandboxSlug(tenantSlug string) string {
+	suffix := sandboxSlugSuffix + strings.ReplaceAll(uuid.New().String(), "-", "")[:6]
+
+	base := tenantSlug
+	if len(base)+len(suffix) > maxTenantSlugLength {
+		base = strings.TrimRight(base[:maxTenantSlugLength-len(suffix)], "-")
+	}
+
+	return base + suffix
+}
@@ -0,0 +1,509 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// maxReportRecipients bounds how many users a scheduled report is emailed to
+const maxReportRecipients = 20
+
+// minReportInterval is the shortest time allowed between two runs of a
+// report's schedule
+const minReportInterval = time.Hour
+
+// maxReportRows bounds the rows of the reports listing users or sign-ins
+const maxReportRows = 10000
+
+// maxReportPDFRows bounds the rows laid out in a PDF report; the CSV format
+// has them all
+const maxReportPDFRows = 500
+
+// reportBatchSize bounds how many reports a run of the scheduled reports job
+// sends per data region
+const reportBatchSize = 50
+
+// ScheduledReportService manages the reports admins schedule on users,
+// sign-ins and audit logs. The scheduled reports job (RunDue) renders each
+// report past its next run time as a CSV or PDF file and emails it to the
+// recipients, covering the time since its previous run.
+type ScheduledReportService struct {
+	db                *sqlx.DB
+	reportRepo        repository.ScheduledReportStore
+	userRepo          repository.UserStore
+	tenantRepo        repository.TenantStore
+	permissionService PermissionManager
+	emailService      EmailManager
+}
+
+// NewScheduledReportService creates a new scheduled report service
+func NewScheduledReportService(
+	db *sqlx.DB,
+	reportRepo repository.ScheduledReportStore,
+	userRepo repository.UserStore,
+	tenantRepo repository.TenantStore,
+	permissionService PermissionManager,
+	emailService EmailManager,
+) *ScheduledReportService {
+	return &ScheduledReportService{
+		db:                db,
+		reportRepo:        reportRepo,
+		userRepo:          userRepo,
+		tenantRepo:        tenantRepo,
+		permissionService: permissionService,
+		emailService:      emailService,
+	}
+}
+
+// ListReports retrieves a tenant's scheduled reports
+func (s *ScheduledReportService) ListReports(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.ScheduledReport, int, error) {
+	return s.reportRepo.List(ctx, tenantID, limit, offset)
+}
+
+// GetReport retrieves a scheduled report
+func (s *ScheduledReportService) GetReport(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReport, error) {
+	return s.reportRepo.FindByID(ctx, tenantID, reportID)
+}
+
+// CreateReport schedules a report. Its first run covers one interval of its
+// schedule.
+func (s *ScheduledReportService) CreateReport(ctx context.Context, tenantID, userID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error) {
+	report := &models.ScheduledReport{
+		TenantID:  tenantID,
+		CreatedBy: userID,
+	}
+	if err := s.apply(ctx, report, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// UpdateReport replaces a scheduled report's settings. The next run covers
+// the time since the previous one, whatever the new schedule.
+func (s *ScheduledReportService) UpdateReport(ctx context.Context, tenantID, reportID uuid.UUID, req *models.ScheduledReportRequest) (*models.ScheduledReport, error) {
+	report, err := s.reportRepo.FindByID(ctx, tenantID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, report, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// DeleteReport deletes a scheduled report
+func (s *ScheduledReportService) DeleteReport(ctx context.Context, tenantID, reportID uuid.UUID) error {
+	return s.reportRepo.Delete(ctx, tenantID, reportID)
+}
+
+// RunReport emails a report right away, covering the time since its previous
+// run. The schedule is unchanged, so the next scheduled run covers the time
+// since this one.
+func (s *ScheduledReportService) RunReport(ctx context.Context, tenantID, reportID uuid.UUID) (*models.ScheduledReportRun, error) {
+	report, err := s.reportRepo.FindByID(ctx, tenantID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, report, time.Now())
+}
+
+// RunDue emails the enabled reports past their next run time, in every data
+// region. It returns the number of reports run.
+func (s *ScheduledReportService) RunDue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		for sent := 0; sent < reportBatchSize; sent++ {
+			if err := ctx.Err(); err != nil {
+				return total, nil
+			}
+
+			report, err := s.claimDue(ctx, db)
+			if err != nil {
+				return total, err
+			}
+			if report == nil {
+				break
+			}
+
+			if _, err := s.run(ctx, report, time.Now()); err != nil {
+				log.Printf("⚠️  Scheduled report %s of tenant %s failed: %v", report.ID, report.TenantID, err)
+			}
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// claimDue claims the next report due in a data region and moves its next
+// run time past now, so no other replica runs it. Returns nil when nothing
+// is due.
+func (s *ScheduledReportService) claimDue(ctx context.Context, db *sqlx.DB) (*models.ScheduledReport, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report, err := s.reportRepo.ClaimDue(ctx, tx, jobs.PausedTenants(ctx))
+	if err != nil || report == nil {
+		return nil, err
+	}
+
+	schedule, err := jobs.ParseCron(report.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule of report %s: %w", report.ID, err)
+	}
+	report.NextRunAt = schedule.Next(time.Now())
+	if err := s.reportRepo.Reschedule(ctx, tx, report); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit scheduled report: %w", err)
+	}
+
+	return report, nil
+}
+
+// run renders a report for the period ending at end and emails it to its
+// recipients, then records the outcome. Recipients who were deactivated or
+// lost the permission to view reports are skipped.
+func (s *ScheduledReportService) run(ctx context.Context, report *models.ScheduledReport, end time.Time) (*models.ScheduledReportRun, error) {
+	start, err := reportPeriodStart(report, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ScheduledReportRun{PeriodStart: start, PeriodEnd: end}
+	runErr := s.send(ctx, report, result)
+	if err := s.reportRepo.RecordRun(ctx, report.TenantID, report.ID, end, runErr); err != nil {
+		return nil, err
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	return result, nil
+}
+
+// send renders a report and emails it to its recipients
+func (s *ScheduledReportService) send(ctx context.Context, report *models.ScheduledReport, result *models.ScheduledReportRun) error {
+	tenant, err := s.tenantRepo.FindByID(ctx, report.TenantID)
+	if err != nil {
+		return err
+	}
+
+	table, err := s.compile(ctx, report, result.PeriodStart, result.PeriodEnd)
+	if err != nil {
+		return err
+	}
+	result.Rows = len(table.rows)
+
+	attachment, err := renderReport(report, tenant.CompanyName, table, result)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	var sendErr error
+	for _, recipient := range report.Recipients {
+		user, err := s.recipient(ctx, report.TenantID, recipient)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			continue
+		}
+
+		msg, err := s.emailService.ScheduledReportEmail(user.Email, user.Language, user.FirstName, tenant.CompanyName, report.Name, result.PeriodStart, result.PeriodEnd, *attachment)
+		if err != nil {
+			return fmt.Errorf("failed to render report email: %w", err)
+		}
+		if err := s.emailService.SendMessage(ctx, msg); err != nil {
+			failed++
+			sendErr = err
+			continue
+		}
+		result.Emailed++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to email the report to %d of %d recipients: %w", failed, failed+result.Emailed, sendErr)
+	}
+	return nil
+}
+
+// recipient returns the user a report goes to, or nil when they are no
+// longer active or allowed to view reports
+func (s *ScheduledReportService) recipient(ctx context.Context, tenantID uuid.UUID, id string) (*models.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenantID, userID)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, nil
+	}
+
+	allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceReports, models.ActionView)
+	if err != nil || !allowed {
+		return nil, err
+	}
+	return user, nil
+}
+
+// apply sets a report's settings from a request, checking its schedule and
+// recipients, and computes its next run time
+func (s *ScheduledReportService) apply(ctx context.Context, report *models.ScheduledReport, req *models.ScheduledReportRequest) error {
+	schedule, err := jobs.ParseCron(req.Schedule)
+	if err != nil {
+		return utils.NewValidationError("INVALID_SCHEDULE", err.Error())
+	}
+	if !reportScheduleAllowed(schedule, time.Now()) {
+		return utils.NewValidationError("INVALID_SCHEDULE", "reports can run at most once an hour")
+	}
+
+	if len(req.Recipients) == 0 {
+		return utils.NewValidationError("RECIPIENTS_REQUIRED", "at least one recipient is required")
+	}
+	if len(req.Recipients) > maxReportRecipients {
+		return utils.NewValidationError("TOO_MANY_RECIPIENTS", fmt.Sprintf("a report can have at most %d recipients", maxReportRecipients))
+	}
+	seen := make(map[uuid.UUID]bool, len(req.Recipients))
+	recipients := make([]string, 0, len(req.Recipients))
+	for _, userID := range req.Recipients {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		user, err := s.recipient(ctx, report.TenantID, userID.String())
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return utils.NewValidationError("INVALID_RECIPIENT", fmt.Sprintf("user %s is not an active user allowed to view reports", userID))
+		}
+		recipients = append(recipients, userID.String())
+	}
+
+	report.Name = strings.TrimSpace(req.Name)
+	report.ReportType = req.ReportType
+	report.Format = req.Format
+	report.Schedule = strings.TrimSpace(req.Schedule)
+	report.Recipients = recipients
+	report.Enabled = req.Enabled == nil || *req.Enabled
+	report.NextRunAt = schedule.Next(time.Now())
+	return nil
+}
+
+// reportScheduleAllowed reports whether a schedule's next runs after t are at
+// least minReportInterval apart
+func reportScheduleAllowed(schedule jobs.Schedule, t time.Time) bool {
+	next := schedule.Next(t)
+	for i := 0; i < 24 && !next.IsZero(); i++ {
+		after := schedule.Next(next)
+		if !after.IsZero() && after.Sub(next) < minReportInterval {
+			return false
+		}
+		next = after
+	}
+	return true
+}
+
+// reportPeriodStart returns the start of the period a run ending at end
+// covers: the end of the previous successful run, or one interval of the
+// schedule before end for the first run
+func reportPeriodStart(report *models.ScheduledReport, end time.Time) (time.Time, error) {
+	if report.LastRunAt != nil && report.LastRunAt.Before(end) {
+		return *report.LastRunAt, nil
+	}
+
+	schedule, err := jobs.ParseCron(report.Schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule of report %s: %w", report.ID, err)
+	}
+	next := schedule.Next(end)
+	after := schedule.Next(next)
+	if next.IsZero() || after.IsZero() {
+		return end.AddDate(0, 0, -1), nil
+	}
+	return end.Add(-after.Sub(next)), nil
+}
+
+// reportTable is a report's data, ready to be rendered
+type reportTable struct {
+	summary [][2]string // Figures shown above the rows: label, value
+	columns []string
+	rows    [][]string
+}
+
+// compile reads the data of a report over a period
+func (s *ScheduledReportService) compile(ctx context.Context, report *models.ScheduledReport, start, end time.Time) (*reportTable, error) {
+	table := &reportTable{}
+
+	switch report.ReportType {
+	case models.ReportTypeActiveUsers:
+		users, err := s.reportRepo.ListActiveUsers(ctx, report.TenantID, start, end, maxReportRows)
+		if err != nil {
+			return nil, err
+		}
+		table.summary = [][2]string{{"Active users", strconv.Itoa(len(users))}}
+		table.columns = []string{"email", "first_name", "last_name", "department", "last_login_at", "last_active_at"}
+		for _, u := range users {
+			table.rows = append(table.rows, []string{
+				u.Email,
+				u.FirstName,
+				u.LastName,
+				utils.ExportValue(u.DepartmentName),
+				utils.ExportValue(u.LastLoginAt),
+				utils.ExportValue(u.LastActiveAt),
+			})
+		}
+
+	case models.ReportTypeLoginStats:
+		days, err := s.reportRepo.ListLoginDays(ctx, report.TenantID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		logins, failed := 0, 0
+		table.columns = []string{"day", "logins", "failed_logins", "users"}
+		for _, d := range days {
+			logins += d.Logins
+			failed += d.FailedLogins
+			table.rows = append(table.rows, []string{
+				d.Day.Format("2006-01-02"),
+				strconv.Itoa(d.Logins),
+				strconv.Itoa(d.FailedLogins),
+				strconv.Itoa(d.Users),
+			})
+		}
+		table.summary = [][2]string{{"Sign-ins", strconv.Itoa(logins)}, {"Failed sign-ins", strconv.Itoa(failed)}}
+
+	case models.ReportTypeFailedLogins:
+		logins, err := s.reportRepo.ListFailedLogins(ctx, report.TenantID, start, end, maxReportRows)
+		if err != nil {
+			return nil, err
+		}
+		table.summary = [][2]string{{"Failed sign-ins", strconv.Itoa(len(logins))}}
+		table.columns = []string{"created_at", "email", "reason", "ip_address", "user_agent"}
+		for _, l := range logins {
+			table.rows = append(table.rows, []string{
+				utils.ExportValue(l.CreatedAt),
+				utils.ExportValue(l.Email),
+				utils.ExportValue(l.Reason),
+				utils.ExportValue(l.IPAddress),
+				utils.ExportValue(l.UserAgent),
+			})
+		}
+
+	case models.ReportTypeAuditSummary:
+		actions, err := s.reportRepo.ListAuditActions(ctx, report.TenantID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		entries, failures := 0, 0
+		table.columns = []string{"action", "successes", "failures", "actors"}
+		for _, a := range actions {
+			entries += a.Successes + a.Failures
+			failures += a.Failures
+			table.rows = append(table.rows, []string{
+				a.Action,
+				strconv.Itoa(a.Successes),
+				strconv.Itoa(a.Failures),
+				strconv.Itoa(a.Actors),
+			})
+		}
+		table.summary = [][2]string{{"Audit log entries", strconv.Itoa(entries)}, {"Failures", strconv.Itoa(failures)}}
+
+	default:
+		return nil, fmt.Errorf("unknown report type %q", report.ReportType)
+	}
+
+	return table, nil
+}
+
+// renderReport writes a report's data as the file attached to its emails
+func renderReport(report *models.ScheduledReport, companyName string, table *reportTable, run *models.ScheduledReportRun) (*models.EmailAttachment, error) {
+	filename := fmt.Sprintf("%s-%s.%s", strings.ReplaceAll(report.ReportType, "_", "-"), run.PeriodEnd.UTC().Format("2006-01-02"), report.Format)
+	var buf bytes.Buffer
+
+	if report.Format == models.ReportFormatPDF {
+		doc := utils.NewPDFDocument(models.ReportTypeNames[report.ReportType])
+		doc.Field("Report", report.Name)
+		doc.Field("Organization", companyName)
+		doc.Field("Period", fmt.Sprintf("%s to %s (UTC)", run.PeriodStart.UTC().Format("2006-01-02 15:04"), run.PeriodEnd.UTC().Format("2006-01-02 15:04")))
+		for _, figure := range table.summary {
+			doc.Field(figure[0], figure[1])
+		}
+		doc.Space()
+
+		doc.Heading("Details")
+		doc.Text(strings.Join(table.columns, " | "))
+		for i, row := range table.rows {
+			if i == maxReportPDFRows {
+				doc.Text(fmt.Sprintf("... and %d more rows. Schedule the report as CSV for all of them.", len(table.rows)-maxReportPDFRows))
+				break
+			}
+			doc.Text(strings.Join(row, " | "))
+		}
+		if len(table.rows) == 0 {
+			doc.Text("Nothing to report for this period.")
+		}
+
+		if _, err := doc.WriteTo(&buf); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+		return &models.EmailAttachment{Filename: filename, ContentType: "application/pdf", Content: buf.Bytes()}, nil
+	}
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(table.columns); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	for _, row := range table.rows {
+		if err := w.Write(utils.SpreadsheetSafeRow(row)); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	return &models.EmailAttachment{Filename: filename, ContentType: "text/csv", Content: buf.Bytes()}, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+// searchPermissions is the permission each search type requires
+var searchPermissions = map[string]struct{ resource, action string }{
+	models.SearchTypeUser:       {models.ResourceUsers, models.ActionView},
+	models.SearchTypeRole:       {models.ResourceRoles, models.ActionView},
+	models.SearchTypeDepartment: {models.ResourceDepartments, models.ActionView},
+	models.SearchTypeCustomer:   {models.ResourceSales, models.ActionView},
+	models.SearchTypeProduct:    {models.ResourceSales, models.ActionView},
+}
+
+// SearchService searches users, roles, departments, customers and products
+// across languages
+type SearchService struct {
+	searchRepo        repository.SearchStore
+	permissionService PermissionManager
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(searchRepo repository.SearchStore, permissionService PermissionManager) *SearchService {
+	return &SearchService{
+		searchRepo:        searchRepo,
+		permissionService: permissionService,
+	}
+}
+
+// Search searches each of types the user has the permission for, stemming
+// the query in language. Returns the results by type; types the user may not
+// see are left out, as are restricted departments they may not view.
+func (s *SearchService) Search(ctx context.Context, tenantID, userID uuid.UUID, query, language string, types []string, limit int) (map[string][]models.SearchResult, error) {
+	config := models.SearchConfig(language)
+
+	results := make(map[string][]models.SearchResult, len(types))
+	for _, searchType := range types {
+		permission := searchPermissions[searchType]
+		allowed, err := s.permissionService.HasPermission(ctx, tenantID, userID, permission.resource, permission.action)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+
+		var found []models.SearchResult
+		switch searchType {
+		case models.SearchTypeUser:
+			found, err = s.searchRepo.SearchUsers(ctx, tenantID, query, config, limit)
+		case models.SearchTypeRole:
+			found, err = s.searchRepo.SearchRoles(ctx, tenantID, query, config, limit)
+		case models.SearchTypeDepartment:
+			found, err = s.searchRepo.SearchDepartments(ctx, tenantID, query, config, limit)
+			if err == nil {
+				found, err = s.visibleDepartments(ctx, tenantID, userID, found)
+			}
+		case models.SearchTypeCustomer:
+			found, err = s.searchRepo.SearchCustomers(ctx, tenantID, query, config, limit)
+		case models.SearchTypeProduct:
+			found, err = s.searchRepo.SearchProducts(ctx, tenantID, query, config, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		results[searchType] = found
+	}
+
+	return results, nil
+}
+
+// visibleDepartments leaves out the restricted departments the user may not
+// view
+func (s *SearchService) visibleDepartments(ctx context.Context, tenantID, userID uuid.UUID, results []models.SearchResult) ([]models.SearchResult, error) {
+	hidden, err := s.permissionService.InaccessibleObjects(ctx, tenantID, userID, models.ResourceDepartments, models.ActionView)
+	if err != nil || len(hidden) == 0 {
+		return results, err
+	}
+
+	visible := results[:0]
+	for _, result := range results {
+		if result.ID != nil && !hidden[*result.ID] {
+			visible = append(visible, result)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/models"
+)
+
+// Session records are cached in Redis so authenticating a request does not
+// query the sessions table. A user's sessions share one hash, keyed by token
+// hash, so revoking their sessions drops all of them with a single DEL.
+
+// cachedSession is a session record held in the cache until CachedUntil
+type cachedSession struct {
+	Session     models.Session `json:"session"`
+	CachedUntil time.Time      `json:"cached_until"`
+}
+
+// sessionCacheKey is the Redis hash holding a user's cached sessions
+func sessionCacheKey(tenantID, userID uuid.UUID) string {
+	return "session:cache:" + tenantID.String() + ":" + userID.String()
+}
+
+// invalidateSessionCache drops a user's cached sessions after some of them
+// were revoked. Failing to do so leaves them valid for at most the cache TTL.
+func invalidateSessionCache(ctx context.Context, rdb *redis.Client, tenantID, userID uuid.UUID) {
+	if err := rdb.Del(ctx, sessionCacheKey(tenantID, userID)).Err(); err != nil {
+		log.Printf("⚠️  Failed to invalidate cached sessions of user %s: %v", userID, err)
+	}
+}
+
+// findSession returns the unexpired session of an access token, from the
+// cache when possible. Cache entries last SessionCacheTTL and never outlive
+// the session.
+func (s *AuthService) findSession(ctx context.Context, tenantID, userID uuid.UUID, tokenHash string) (*models.Session, error) {
+	ttl := s.config.Security.SessionCacheTTL
+	if ttl <= 0 {
+		return s.sessionRepo.FindByTokenHash(ctx, tenantID, tokenHash)
+	}
+
+	key := sessionCacheKey(tenantID, userID)
+	if data, err := s.redis.HGet(ctx, key, tokenHash).Bytes(); err == nil {
+		var entry cachedSession
+		if json.Unmarshal(data, &entry) == nil && time.Now().Before(entry.CachedUntil) {
+			return &entry.Session, nil
+		}
+	}
+
+	session, err := s.sessionRepo.FindByTokenHash(ctx, tenantID, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedUntil := time.Now().Add(ttl)
+	if session.ExpiresAt.Before(cachedUntil) {
+		cachedUntil = session.ExpiresAt
+	}
+	data, err := json.Marshal(cachedSession{Session: *session, CachedUntil: cachedUntil})
+	if err == nil {
+		pipe := s.redis.TxPipeline()
+		pipe.HSet(ctx, key, tokenHash, data)
+		pipe.Expire(ctx, key, ttl)
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("⚠️  Failed to cache session %s: %v", session.ID, err)
+		}
+	}
+
+	return session, nil
+}
@@ -2,31 +2,39 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 	"myerp-v2/internal/database"
 	"myerp-v2/internal/models"
+	"myerp-v2/internal/utils"
 )
 
 // SessionService handles session management operations
 type SessionService struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	redis *redis.Client
 }
 
 // NewSessionService creates a new session service
-func NewSessionService(db *sqlx.DB) *SessionService {
+func NewSessionService(db *sqlx.DB, redisClient *redis.Client) *SessionService {
 	return &SessionService{
-		db: db,
+		db:    db,
+		redis: redisClient,
 	}
 }
 
 // SessionInfo contains detailed session information
 type SessionInfo struct {
 	models.Session
-	IsCurrent bool `json:"is_current" db:"-"`
+	IsCurrent   bool   `json:"is_current" db:"-"`
+	DisplayName string `json:"display_name" db:"-"` // Name, or browser and OS when unnamed
+	Location    string `json:"location,omitempty" db:"-"`
 }
 
 // ListUserSessions retrieves all active sessions for a user
@@ -42,6 +50,7 @@ func (s *SessionService) ListUserSessions(ctx context.Context, tenantID, userID
 			id,
 			tenant_id,
 			user_id,
+			name,
 			token_hash,
 			device_type,
 			browser,
@@ -69,8 +78,10 @@ func (s *SessionService) ListUserSessions(ctx context.Context, tenantID, userID
 	sessionInfos := make([]SessionInfo, len(sessions))
 	for i, session := range sessions {
 		sessionInfos[i] = SessionInfo{
-			Session:   session,
-			IsCurrent: session.TokenHash == currentTokenHash,
+			Session:     session,
+			IsCurrent:   currentTokenHash != "" && session.TokenHash == currentTokenHash,
+			DisplayName: session.DisplayName(),
+			Location:    session.LocationString(),
 		}
 	}
 
@@ -98,10 +109,65 @@ func (s *SessionService) RevokeSession(ctx context.Context, tenantID, userID, se
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("session not found or already revoked")
+		return utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found or already revoked")
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	invalidateSessionCache(ctx, s.redis, tenantID, userID)
+
+	return nil
+}
+
+// RenameSession gives one of a user's sessions a friendly name; an empty
+// name clears it
+func (s *SessionService) RenameSession(ctx context.Context, tenantID, userID, sessionID uuid.UUID, name string) (*models.Session, error) {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sessionName *string
+	if name = strings.TrimSpace(name); name != "" {
+		sessionName = &name
+	}
+
+	var session models.Session
+	query := `
+		UPDATE sessions
+		SET name = $1
+		WHERE id = $2
+		  AND user_id = $3
+		  AND expires_at > NOW()
+		RETURNING
+			id,
+			tenant_id,
+			user_id,
+			name,
+			token_hash,
+			device_type,
+			browser,
+			os,
+			ip_address,
+			user_agent,
+			country_code,
+			city,
+			last_activity_at,
+			expires_at,
+			created_at
+	`
+
+	err = tx.GetContext(ctx, &session, query, sessionName, sessionID, userID)
+	if err == sql.ErrNoRows {
+		return nil, utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found or already revoked")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename session: %w", err)
+	}
+
+	return &session, tx.Commit()
 }
 
 // RevokeAllSessions revokes all sessions except the current one
@@ -125,7 +191,12 @@ func (s *SessionService) RevokeAllSessions(ctx context.Context, tenantID, userID
 
 	rowsAffected, _ := result.RowsAffected()
 
-	return int(rowsAffected), tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	invalidateSessionCache(ctx, s.redis, tenantID, userID)
+
+	return int(rowsAffected), nil
 }
 
 // RevokeAllUserSessions revokes all sessions for a user (admin operation)
@@ -145,7 +216,12 @@ func (s *SessionService) RevokeAllUserSessions(ctx context.Context, tenantID, us
 
 	rowsAffected, _ := result.RowsAffected()
 
-	return int(rowsAffected), tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	invalidateSessionCache(ctx, s.redis, tenantID, userID)
+
+	return int(rowsAffected), nil
 }
 
 // GetSessionStats returns session statistics for a user
@@ -224,10 +300,25 @@ func (s *SessionService) GetSessionStats(ctx context.Context, tenantID, userID u
 	return stats, tx.Commit()
 }
 
-// CleanupExpiredSessions removes expired sessions (should be run periodically)
+// CleanupExpiredSessions removes expired sessions from every data region
+// (should be run periodically, see internal/jobs)
 func (s *SessionService) CleanupExpiredSessions(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.cleanupExpiredSessionsIn(ctx, db)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// cleanupExpiredSessionsIn removes expired sessions from a single database
+func (s *SessionService) cleanupExpiredSessionsIn(ctx context.Context, db *sqlx.DB) (int, error) {
 	// Use bypass RLS for cleanup task (system operation)
-	tx, err := database.WithBypassRLS(ctx, s.db)
+	tx, err := database.WithBypassRLS(ctx, db)
 	if err != nil {
 		return 0, err
 	}
@@ -263,6 +354,7 @@ func (s *SessionService) GetSessionByToken(ctx context.Context, tokenHash string
 			id,
 			tenant_id,
 			user_id,
+			name,
 			token_hash,
 			device_type,
 			browser,
@@ -282,7 +374,7 @@ func (s *SessionService) GetSessionByToken(ctx context.Context, tokenHash string
 
 	err = tx.GetContext(ctx, &session, query, tokenHash)
 	if err != nil {
-		return nil, fmt.Errorf("session not found or expired")
+		return nil, utils.NewNotFoundError("SESSION_NOT_FOUND", "session not found or expired")
 	}
 
 	return &session, tx.Commit()
@@ -323,6 +415,7 @@ func (s *SessionService) GetRecentLogins(ctx context.Context, tenantID, userID u
 			id,
 			tenant_id,
 			user_id,
+			name,
 			token_hash,
 			device_type,
 			browser,
@@ -0,0 +1,556 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/jobs"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const (
+	siemMaxForwarders    = 5               // SIEM forwarders a tenant may have
+	siemMaxActions       = 50              // Action prefixes a forwarder may filter on
+	siemClaimBatchSize   = 100             // Max forwarders worked per database per job run
+	siemSettleDelay      = 5 * time.Second // Age an event must reach before it is forwarded
+	siemResponseBodySize = 2048            // Bytes of an endpoint's response read for errors
+)
+
+// kafkaTopicPattern matches valid Kafka topic names
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// SIEMService manages the forwarders streaming tenants' audit logs to their
+// SIEM, and forwards events to them. Every audit event is written to
+// audit_logs first; the siem_forwarding job then sends each forwarder the
+// events after its cursor, in batches, and moves the cursor once a batch was
+// accepted. A SIEM that is down or slow only builds a backlog in the audit
+// log, retried with backoff; events lagging more than SIEM_MAX_LAG are
+// skipped, so a long outage can't turn into an unbounded catch-up.
+type SIEMService struct {
+	db            *sqlx.DB
+	forwarderRepo repository.SIEMForwarderStore
+	config        *config.Config
+	dialer        *net.Dialer
+	client        *http.Client
+	hostname      string
+}
+
+// NewSIEMService creates a new SIEM service
+func NewSIEMService(db *sqlx.DB, forwarderRepo repository.SIEMForwarderStore, cfg *config.Config) *SIEMService {
+	dialer := outboundDialer(cfg.SIEM.Timeout, cfg.Webhooks.AllowPrivateNetworks)
+	hostname, _ := os.Hostname()
+
+	return &SIEMService{
+		db:            db,
+		forwarderRepo: forwarderRepo,
+		config:        cfg,
+		dialer:        dialer,
+		client: &http.Client{
+			Timeout:   cfg.SIEM.Timeout,
+			Transport: metrics.Transport("siem", &http.Transport{DialContext: dialer.DialContext, Proxy: http.ProxyFromEnvironment}),
+			// Redirects are not followed: the endpoint must answer itself
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		hostname: hostname,
+	}
+}
+
+// ListForwarders lists a tenant's SIEM forwarders by name
+func (s *SIEMService) ListForwarders(ctx context.Context, tenantID uuid.UUID) ([]models.SIEMForwarder, error) {
+	return s.forwarderRepo.List(ctx, tenantID)
+}
+
+// GetForwarder retrieves a tenant's SIEM forwarder
+func (s *SIEMService) GetForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID) (*models.SIEMForwarder, error) {
+	return s.forwarderRepo.FindByID(ctx, tenantID, forwarderID)
+}
+
+// CreateForwarder adds a SIEM forwarder. It forwards the events logged from
+// now on.
+func (s *SIEMService) CreateForwarder(ctx context.Context, tenantID, userID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error) {
+	existing, err := s.forwarderRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= siemMaxForwarders {
+		return nil, utils.NewConflictError("SIEM_FORWARDER_LIMIT", fmt.Sprintf("a tenant may have at most %d SIEM forwarders", siemMaxForwarders))
+	}
+
+	forwarder := &models.SIEMForwarder{
+		TenantID:  tenantID,
+		Enabled:   true,
+		CreatedBy: userID,
+	}
+	if err := s.applyRequest(forwarder, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.forwarderRepo.Create(ctx, forwarder); err != nil {
+		return nil, err
+	}
+
+	return forwarder, nil
+}
+
+// UpdateForwarder replaces a SIEM forwarder's settings. Events not forwarded
+// yet go to the new destination.
+func (s *SIEMService) UpdateForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID, req *models.SIEMForwarderRequest) (*models.SIEMForwarder, error) {
+	forwarder, err := s.forwarderRepo.FindByID(ctx, tenantID, forwarderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(forwarder, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.forwarderRepo.Update(ctx, forwarder); err != nil {
+		return nil, err
+	}
+
+	return forwarder, nil
+}
+
+// DeleteForwarder deletes a SIEM forwarder
+func (s *SIEMService) DeleteForwarder(ctx context.Context, tenantID, forwarderID uuid.UUID) error {
+	return s.forwarderRepo.Delete(ctx, tenantID, forwarderID)
+}
+
+// TestForwarder sends a siem.test event through a forwarder right away,
+// whatever its actions and state, and reports whether the endpoint accepted
+// it. The cursor is not moved. Syslog over UDP can't confirm delivery.
+func (s *SIEMService) TestForwarder(ctx context.Context, tenantID, userID, forwarderID uuid.UUID) (*models.SIEMForwarderTest, error) {
+	forwarder, err := s.forwarderRepo.FindByID(ctx, tenantID, forwarderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceType := models.ResourceSecurity
+	event := models.SIEMEvent{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		Time:         time.Now().UTC(),
+		Action:       models.ActionSIEMTest,
+		Status:       "success",
+		UserID:       &userID,
+		ResourceType: &resourceType,
+		ResourceID:   &forwarder.ID,
+	}
+
+	if err := s.send(ctx, forwarder, []models.SIEMEvent{event}); err != nil {
+		return &models.SIEMForwarderTest{Delivered: false, Error: err.Error()}, nil
+	}
+	return &models.SIEMForwarderTest{Delivered: true}, nil
+}
+
+// ForwardDue sends new audit log events to every enabled forwarder due for an
+// attempt, in every data region, with JOBS_SIEM_CONCURRENCY workers. Each
+// forwarder is worked at most once per run. It is run periodically by the
+// background job runner and returns the number of forwarders worked.
+func (s *SIEMService) ForwardDue(ctx context.Context) (int, error) {
+	dueBy := time.Now()
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		worked, err := drainQueue(ctx, s.config.Jobs.SIEMConcurrency, siemClaimBatchSize, func(ctx context.Context) (bool, error) {
+			return s.forwardNext(ctx, db, dueBy)
+		})
+		total += worked
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// forwardNext claims one due forwarder, sends it its new events and records
+// its progress. The row stays locked while sending, so concurrent workers
+// never send the same events twice.
+func (s *SIEMService) forwardNext(ctx context.Context, db *sqlx.DB, dueBy time.Time) (bool, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	forwarder, err := s.forwarderRepo.ClaimDue(ctx, tx, jobs.PausedTenants(ctx), dueBy)
+	if err != nil || forwarder == nil {
+		return false, err
+	}
+
+	forwardErr := s.forward(ctx, tx, forwarder)
+
+	// Batches that went out must be recorded, even if the job is shutting
+	// down
+	ctx = context.WithoutCancel(ctx)
+
+	if forwardErr != nil {
+		forwarder.Failures++
+		message := forwardErr.Error()
+		forwarder.LastError = &message
+		forwarder.NextAttemptAt = time.Now().Add(webhookRetryDelay(forwarder.Failures))
+		log.Printf("⚠️  SIEM forwarder %s of tenant %s failed (%d in a row): %v", forwarder.ID, forwarder.TenantID, forwarder.Failures, forwardErr)
+	} else {
+		forwarder.Failures = 0
+		forwarder.LastError = nil
+		forwarder.NextAttemptAt = time.Now()
+	}
+
+	if err := s.forwarderRepo.SaveProgress(ctx, tx, forwarder); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record SIEM forwarding: %w", err)
+	}
+
+	return true, nil
+}
+
+// forward sends a claimed forwarder up to SIEM_MAX_BATCHES_PER_RUN batches of
+// the events after its cursor, moving the cursor past each batch sent. Events
+// lagging more than SIEM_MAX_LAG are skipped first and counted as dropped.
+func (s *SIEMService) forward(ctx context.Context, tx *sqlx.Tx, forwarder *models.SIEMForwarder) error {
+	now := time.Now()
+
+	if cutoff := now.Add(-s.config.SIEM.MaxLag); forwarder.CursorAt.Before(cutoff) {
+		skipped, err := s.forwarderRepo.CountEventsBefore(ctx, tx, forwarder, cutoff)
+		if err != nil {
+			return err
+		}
+		if skipped > 0 {
+			forwarder.EventsDropped += int64(skipped)
+			metrics.ObserveQueueRejected(models.QueueSIEM, skipped)
+			log.Printf("⚠️  SIEM forwarder %s of tenant %s skipped %d events older than %s", forwarder.ID, forwarder.TenantID, skipped, s.config.SIEM.MaxLag)
+		}
+		forwarder.CursorAt, forwarder.CursorID = cutoff, uuid.Nil
+	}
+
+	// Events are forwarded once they are a few seconds old: an event's
+	// created_at is the start of its transaction, so one committed late
+	// could otherwise land behind the cursor
+	until := now.Add(-siemSettleDelay)
+
+	for batch := 0; batch < s.config.SIEM.MaxBatchesPerRun; batch++ {
+		events, err := s.forwarderRepo.ListEvents(ctx, tx, forwarder, until, s.config.SIEM.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := s.send(ctx, forwarder, events); err != nil {
+			return err
+		}
+
+		last := events[len(events)-1]
+		forwarder.CursorAt, forwarder.CursorID = last.Time, last.ID
+		forwarder.EventsForwarded += int64(len(events))
+		sentAt := time.Now()
+		forwarder.LastForwardedAt = &sentAt
+
+		if len(events) < s.config.SIEM.BatchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// send delivers a batch of events over the forwarder's transport
+func (s *SIEMService) send(ctx context.Context, forwarder *models.SIEMForwarder, events []models.SIEMEvent) error {
+	formatted := make([][]byte, len(events))
+	for i := range events {
+		msg, err := formatSIEMEvent(forwarder.Format, s.config.App.Name, &events[i])
+		if err != nil {
+			return err
+		}
+		formatted[i] = msg
+	}
+
+	switch forwarder.Transport {
+	case models.SIEMTransportSyslog, models.SIEMTransportSyslogTCP, models.SIEMTransportSyslogUDP:
+		messages := make([][]byte, len(events))
+		for i := range events {
+			messages[i] = syslogMessage(s.config.App.Name, s.hostname, &events[i], formatted[i])
+		}
+		return s.sendSyslog(ctx, forwarder, messages)
+
+	case models.SIEMTransportWebhook:
+		// JSON events are posted as an array, CEF events one per line
+		contentType, body := "text/plain; charset=utf-8", bytes.Join(formatted, []byte("\n"))
+		if forwarder.Format == models.SIEMFormatJSON {
+			contentType, body = "application/json", append(append([]byte("["), bytes.Join(formatted, []byte(","))...), ']')
+		}
+		_, err := s.post(ctx, forwarder, forwarder.Endpoint, contentType, body)
+		return err
+
+	case models.SIEMTransportKafka:
+		return s.sendKafka(ctx, forwarder, formatted)
+	}
+
+	return fmt.Errorf("unknown SIEM transport %q", forwarder.Transport)
+}
+
+// sendSyslog writes syslog messages to the forwarder's endpoint over a new
+// connection: octet-counted frames over TLS or TCP, one datagram per message
+// over UDP
+func (s *SIEMService) sendSyslog(ctx context.Context, forwarder *models.SIEMForwarder, messages [][]byte) error {
+	var conn net.Conn
+	var err error
+	switch forwarder.Transport {
+	case models.SIEMTransportSyslog:
+		tlsDialer := &tls.Dialer{NetDialer: s.dialer, Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", forwarder.Endpoint)
+	case models.SIEMTransportSyslogUDP:
+		conn, err = s.dialer.DialContext(ctx, "udp", forwarder.Endpoint)
+	default:
+		conn, err = s.dialer.DialContext(ctx, "tcp", forwarder.Endpoint)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog endpoint: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(s.config.SIEM.Timeout))
+
+	if forwarder.Transport == models.SIEMTransportSyslogUDP {
+		for _, msg := range messages {
+			if _, err := conn.Write(msg); err != nil {
+				return fmt.Errorf("failed to send to syslog endpoint: %w", err)
+			}
+		}
+		return nil
+	}
+
+	out := bufio.NewWriter(conn)
+	for _, msg := range messages {
+		out.WriteString(strconv.Itoa(len(msg)))
+		out.WriteByte(' ')
+		out.Write(msg)
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to send to syslog endpoint: %w", err)
+	}
+
+	return nil
+}
+
+// kafkaProduceResponse is the answer of a Kafka REST proxy to a produce
+// request, with the outcome of each record
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+// sendKafka produces events to the forwarder's topic through a Kafka REST
+// proxy (v2 API), keyed by tenant so a tenant's events stay in order
+func (s *SIEMService) sendKafka(ctx context.Context, forwarder *models.SIEMForwarder, formatted [][]byte) error {
+	type record struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	records := make([]record, len(formatted))
+	for i, msg := range formatted {
+		value := json.RawMessage(msg)
+		if forwarder.Format != models.SIEMFormatJSON {
+			// CEF lines are sent as JSON strings
+			encoded, err := json.Marshal(string(msg))
+			if err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+			value = encoded
+		}
+		records[i] = record{Key: forwarder.TenantID.String(), Value: value}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	topic := ""
+	if forwarder.Topic != nil {
+		topic = *forwarder.Topic
+	}
+	endpoint := strings.TrimRight(forwarder.Endpoint, "/") + "/topics/" + url.PathEscape(topic)
+
+	respBody, err := s.post(ctx, forwarder, endpoint, "application/vnd.kafka.json.v2+json", body)
+	if err != nil {
+		return err
+	}
+
+	var resp kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("invalid Kafka REST proxy response: %w", err)
+	}
+	for _, offset := range resp.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			message := "unknown error"
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+			return fmt.Errorf("Kafka REST proxy rejected records: %s", message)
+		}
+	}
+
+	return nil
+}
+
+// post sends a batch to an HTTPS endpoint with the forwarder's Authorization
+// header. Any 2xx response is a success; its body is returned.
+func (s *SIEMService) post(ctx context.Context, forwarder *models.SIEMForwarder, endpoint, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIEM request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", s.config.App.Name+" SIEM Forwarder")
+	if forwarder.Transport == models.SIEMTransportKafka {
+		req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	}
+
+	if forwarder.AuthHeader != nil {
+		authHeader, err := utils.Decrypt(*forwarder.AuthHeader, []byte(s.config.Security.EncryptionKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt SIEM authorization: %w", err)
+		}
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, siemResponseBodySize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return respBody, nil
+}
+
+// applyRequest validates a forwarder request and copies it to forwarder,
+// encrypting the Authorization header
+func (s *SIEMService) applyRequest(forwarder *models.SIEMForwarder, req *models.SIEMForwarderRequest) error {
+	endpoint := strings.TrimSpace(req.Endpoint)
+	if err := s.validateEndpoint(req.Transport, endpoint); err != nil {
+		return utils.NewValidationError("INVALID_SIEM_ENDPOINT", "endpoint "+err.Error())
+	}
+
+	var topic *string
+	if req.Transport == models.SIEMTransportKafka {
+		if req.Topic == nil || !kafkaTopicPattern.MatchString(strings.TrimSpace(*req.Topic)) {
+			return utils.NewValidationError("INVALID_SIEM_TOPIC", "topic must be a Kafka topic name (letters, digits, '.', '_' and '-')")
+		}
+		trimmed := strings.TrimSpace(*req.Topic)
+		topic = &trimmed
+	}
+
+	actions := sortedActionPrefixes(req.Actions)
+	if len(actions) > siemMaxActions {
+		return utils.NewValidationError("INVALID_SIEM_ACTIONS", fmt.Sprintf("at most %d action prefixes may be forwarded", siemMaxActions))
+	}
+
+	if req.AuthHeader != nil {
+		authHeader := strings.TrimSpace(*req.AuthHeader)
+		switch {
+		case authHeader == "":
+			forwarder.AuthHeader = nil
+		case strings.ContainsAny(authHeader, "\r\n") || len(authHeader) > 4096:
+			return utils.NewValidationError("INVALID_SIEM_AUTH_HEADER", "auth_header must be a single line of at most 4096 characters")
+		default:
+			encrypted, err := utils.Encrypt(authHeader, []byte(s.config.Security.EncryptionKey))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt SIEM authorization: %w", err)
+			}
+			forwarder.AuthHeader = &encrypted
+		}
+	}
+
+	forwarder.Name = strings.TrimSpace(req.Name)
+	forwarder.Transport = req.Transport
+	forwarder.Format = req.Format
+	forwarder.Endpoint = endpoint
+	forwarder.Topic = topic
+	forwarder.Actions = actions
+	if req.Enabled != nil {
+		forwarder.Enabled = *req.Enabled
+	}
+	forwarder.HasAuthHeader = forwarder.AuthHeader != nil
+
+	return nil
+}
+
+// validateEndpoint checks a forwarder's endpoint for its transport: host:port
+// for syslog, an HTTPS URL otherwise. Private addresses are refused unless
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS is set; they are checked again on the
+// resolved address when connecting.
+func (s *SIEMService) validateEndpoint(transport, endpoint string) error {
+	switch transport {
+	case models.SIEMTransportWebhook, models.SIEMTransportKafka:
+		return validateEndpointURL(endpoint, s.config.Webhooks.AllowPrivateNetworks)
+	}
+
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil || host == "" {
+		return fmt.Errorf("must be host:port")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("must have a valid port")
+	}
+	if !s.config.Webhooks.AllowPrivateNetworks {
+		if ip := net.ParseIP(host); (ip != nil && isPrivateWebhookIP(ip)) || host == "localhost" {
+			return fmt.Errorf("must not point to a private address")
+		}
+	}
+
+	return nil
+}
+
+// sortedActionPrefixes trims, deduplicates and sorts the action prefixes of a
+// forwarder request
+func sortedActionPrefixes(actions []string) []string {
+	seen := make(map[string]bool, len(actions))
+	prefixes := []string{}
+	for _, action := range actions {
+		action = strings.TrimSpace(action)
+		if action == "" || seen[action] {
+			continue
+		}
+		seen[action] = true
+		prefixes = append(prefixes, action)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
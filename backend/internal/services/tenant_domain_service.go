@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Slug and domain lookups are cached in Redis as the tenant ID they resolve
+// to, so resolving the tenant of a request is a primary-key lookup. Hosts
+// that are no verified domain are cached too (as an empty value), so requests
+// for unknown hosts do not each query the catalog.
+
+// domainLookupTimeout bounds the DNS lookup of a verification
+const domainLookupTimeout = 5 * time.Second
+
+// tenantSlugCacheKey caches the tenant ID of a slug
+func tenantSlugCacheKey(slug string) string {
+	return "tenant:slug:" + slug
+}
+
+// tenantDomainCacheKey caches the tenant ID of a custom domain, empty when
+// the host is no verified domain
+func tenantDomainCacheKey(domain string) string {
+	return "tenant:domain:" + domain
+}
+
+// TenantDomainService resolves requests to tenants by slug, subdomain of
+// BASE_DOMAIN or verified custom domain, and manages the custom domains of
+// tenants
+type TenantDomainService struct {
+	domainRepo repository.TenantDomainStore
+	tenantRepo repository.TenantStore
+	redis      *redis.Client
+	config     *config.Config
+	lookupTXT  func(ctx context.Context, name string) ([]string, error)
+}
+
+// NewTenantDomainService creates a new tenant domain service
+func NewTenantDomainService(
+	domainRepo repository.TenantDomainStore,
+	tenantRepo repository.TenantStore,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *TenantDomainService {
+	return &TenantDomainService{
+		domainRepo: domainRepo,
+		tenantRepo: tenantRepo,
+		redis:      redisClient,
+		config:     cfg,
+		lookupTXT:  net.DefaultResolver.LookupTXT,
+	}
+}
+
+// ResolveSlug returns the tenant with a slug, or the renamed tenant a former
+// slug still redirects to; the tenant's Slug then differs from slug
+func (s *TenantDomainService) ResolveSlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	slug = strings.ToLower(slug)
+	key := tenantSlugCacheKey(slug)
+
+	if cached, found := s.cachedLookup(ctx, key); found {
+		// A tenant renamed since no longer has the slug
+		if tenant := s.cachedTenant(ctx, key, cached); tenant != nil && tenant.Slug == slug {
+			return tenant, nil
+		}
+	}
+
+	tenant, err := s.tenantRepo.FindBySlug(ctx, slug)
+	if errors.Is(err, utils.ErrNotFound) {
+		// Former slugs are not cached: they expire, and are looked up rarely
+		tenantID, err := s.tenantRepo.FindTenantIDByFormerSlug(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		return s.tenantRepo.FindByID(ctx, tenantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.cacheTenantID(ctx, key, tenant.ID.String())
+
+	return tenant, nil
+}
+
+// CanonicalHost returns the host a request for a tenant should move to: its
+// current subdomain, when host is the subdomain of a former slug. Returns
+// false for any other host.
+func (s *TenantDomainService) CanonicalHost(host string, tenant *models.Tenant) (string, bool) {
+	base := s.config.Domains.BaseDomain
+	name := normalizeHost(host)
+	if base == "" || !strings.HasSuffix(name, "."+base) {
+		return "", false
+	}
+	if subdomain := strings.TrimSuffix(name, "."+base); subdomain == tenant.Slug || strings.Contains(subdomain, ".") {
+		return "", false
+	}
+
+	canonical := tenant.Slug + "." + base
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		canonical = net.JoinHostPort(canonical, port)
+	}
+	return canonical, true
+}
+
+// ChangeSlug renames the tenant's slug. The old slug keeps resolving to the
+// tenant for SlugRedirectPeriod, and is taken for that long.
+func (s *TenantDomainService) ChangeSlug(ctx context.Context, tenantID, userID uuid.UUID, slug string) (*models.TenantSlugChange, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !utils.IsValidSlug(slug) {
+		return nil, utils.NewValidationError("INVALID_SLUG", "slug must be 3-63 characters, start with a letter, and contain only lowercase letters, numbers, and hyphens")
+	}
+	if s.config.Domains.IsReserved(slug) {
+		return nil, utils.NewConflictError("SLUG_TAKEN", "slug is already taken")
+	}
+
+	redirectUntil := time.Now().Add(s.config.Domains.SlugRedirectPeriod)
+	oldSlug, err := s.tenantRepo.ChangeSlug(ctx, tenantID, slug, userID, redirectUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The old slug must now resolve through the history, and the new one
+	// may still be cached for a tenant whose former slug it was
+	s.invalidate(ctx, tenantSlugCacheKey(oldSlug))
+	s.invalidate(ctx, tenantSlugCacheKey(slug))
+
+	return &models.TenantSlugChange{
+		Slug:          slug,
+		PreviousSlug:  oldSlug,
+		RedirectUntil: redirectUntil,
+	}, nil
+}
+
+// CleanupSlugHistory frees the former slugs whose redirect expired
+func (s *TenantDomainService) CleanupSlugHistory(ctx context.Context) (int, error) {
+	slugs, err := s.tenantRepo.CleanupExpiredSlugHistory(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, slug := range slugs {
+		s.invalidate(ctx, tenantSlugCacheKey(slug))
+	}
+	return len(slugs), nil
+}
+
+// ResolveHost returns the tenant a request host names: <slug>.<BASE_DOMAIN>
+// or a verified custom domain. Returns nil without error for the platform's
+// own hosts (BASE_DOMAIN, reserved subdomains, hosts without a dot).
+func (s *TenantDomainService) ResolveHost(ctx context.Context, host string) (*models.Tenant, error) {
+	host = normalizeHost(host)
+	base := s.config.Domains.BaseDomain
+	if host == "" || host == base || !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return nil, nil
+	}
+
+	// Subdomain of the platform: the tenant with that slug
+	if base != "" && strings.HasSuffix(host, "."+base) {
+		subdomain := strings.TrimSuffix(host, "."+base)
+		if strings.Contains(subdomain, ".") || s.config.Domains.IsReserved(subdomain) {
+			return nil, nil
+		}
+		return s.ResolveSlug(ctx, subdomain)
+	}
+
+	// Custom domain
+	key := tenantDomainCacheKey(host)
+	if cached, found := s.cachedLookup(ctx, key); found {
+		if cached == "" {
+			return nil, nil
+		}
+		if tenant := s.cachedTenant(ctx, key, cached); tenant != nil {
+			return tenant, nil
+		}
+	}
+
+	tenantID, err := s.domainRepo.FindTenantIDByDomain(ctx, host)
+	if errors.Is(err, utils.ErrNotFound) {
+		s.cacheTenantID(ctx, key, "")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheTenantID(ctx, key, tenant.ID.String())
+
+	return tenant, nil
+}
+
+// IsTenantOrigin reports whether a browser origin is served from a verified
+// custom domain, for CORS
+func (s *TenantDomainService) IsTenantOrigin(ctx context.Context, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	host := normalizeHost(u.Host)
+	if base := s.config.Domains.BaseDomain; base != "" && strings.HasSuffix(host, "."+base) {
+		return false
+	}
+
+	tenant, err := s.ResolveHost(ctx, host)
+	return err == nil && tenant != nil
+}
+
+// ListDomains retrieves the tenant's custom domains with the DNS record each
+// needs
+func (s *TenantDomainService) ListDomains(ctx context.Context, tenantID uuid.UUID) ([]models.TenantDomain, error) {
+	domains, err := s.domainRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range domains {
+		describeVerification(&domains[i])
+	}
+	return domains, nil
+}
+
+// GetDomain retrieves one of the tenant's custom domains
+func (s *TenantDomainService) GetDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	domain, err := s.domainRepo.FindByID(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	describeVerification(domain)
+	return domain, nil
+}
+
+// AddDomain adds a custom domain, unverified. Requests to it resolve to the
+// tenant once VerifyDomain found its TXT record.
+func (s *TenantDomainService) AddDomain(ctx context.Context, tenantID, userID uuid.UUID, name string) (*models.TenantDomain, error) {
+	name = normalizeHost(name)
+	if !utils.IsValidDomain(name) {
+		return nil, utils.NewValidationError("INVALID_DOMAIN", "invalid domain")
+	}
+	if base := s.config.Domains.BaseDomain; base != "" && (name == base || strings.HasSuffix(name, "."+base)) {
+		return nil, utils.NewValidationError("INVALID_DOMAIN", "subdomains of "+base+" cannot be added")
+	}
+
+	existing, err := s.domainRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= s.config.Domains.MaxPerTenant {
+		return nil, utils.NewConflictError("DOMAIN_LIMIT_REACHED", fmt.Sprintf("at most %d domains can be added", s.config.Domains.MaxPerTenant))
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	domain := &models.TenantDomain{
+		TenantID:          tenantID,
+		Domain:            name,
+		VerificationToken: hex.EncodeToString(token),
+		CreatedBy:         &userID,
+	}
+	if err := s.domainRepo.Create(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	describeVerification(domain)
+	return domain, nil
+}
+
+// VerifyDomain looks up the domain's TXT record and marks the domain verified
+// when it holds the verification token. A domain that is not verified yet is
+// returned as is: the record may still be propagating.
+func (s *TenantDomainService) VerifyDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	domain, err := s.domainRepo.FindByID(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	describeVerification(domain)
+	if domain.IsVerified() {
+		return domain, nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, domainLookupTimeout)
+	defer cancel()
+
+	verified := false
+	records, err := s.lookupTXT(lookupCtx, domain.VerificationRecord)
+	if err != nil {
+		log.Printf("⚠️  TXT lookup of %s failed: %v", domain.VerificationRecord, err)
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == domain.VerificationValue {
+			verified = true
+			break
+		}
+	}
+
+	if err := s.domainRepo.RecordCheck(ctx, domain, verified); err != nil {
+		return nil, err
+	}
+	if verified {
+		// Drop the cached miss, so the domain resolves right away
+		s.invalidate(ctx, tenantDomainCacheKey(domain.Domain))
+	}
+
+	return domain, nil
+}
+
+// RemoveDomain removes a custom domain; requests to it no longer resolve to
+// the tenant
+func (s *TenantDomainService) RemoveDomain(ctx context.Context, tenantID, domainID uuid.UUID) (*models.TenantDomain, error) {
+	domain, err := s.domainRepo.FindByID(ctx, tenantID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.domainRepo.Delete(ctx, tenantID, domainID); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, tenantDomainCacheKey(domain.Domain))
+	return domain, nil
+}
+
+// cachedLookup returns the value cached for a lookup; found is false on a
+// cache miss
+func (s *TenantDomainService) cachedLookup(ctx context.Context, key string) (string, bool) {
+	if s.config.Domains.CacheTTL <= 0 {
+		return "", false
+	}
+	cached, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		return "", false
+	}
+	return cached, true
+}
+
+// cachedTenant returns the tenant of the ID cached at key, or nil when the
+// tenant no longer exists
+func (s *TenantDomainService) cachedTenant(ctx context.Context, key, cached string) *models.Tenant {
+	tenantID, err := uuid.Parse(cached)
+	if err != nil {
+		return nil
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		// Deleted since: look it up again
+		s.invalidate(ctx, key)
+		return nil
+	}
+	return tenant
+}
+
+// cacheTenantID caches the tenant ID a lookup resolved to for CacheTTL
+func (s *TenantDomainService) cacheTenantID(ctx context.Context, key, tenantID string) {
+	if s.config.Domains.CacheTTL <= 0 {
+		return
+	}
+	if err := s.redis.Set(ctx, key, tenantID, s.config.Domains.CacheTTL).Err(); err != nil {
+		log.Printf("⚠️  Failed to cache tenant lookup %s: %v", key, err)
+	}
+}
+
+// invalidate drops a cached lookup. Failing to do so leaves it stale for at
+// most the cache TTL.
+func (s *TenantDomainService) invalidate(ctx context.Context, key string) {
+	if err := s.redis.Del(ctx, key).Err(); err != nil {
+		log.Printf("⚠️  Failed to invalidate tenant lookup %s: %v", key, err)
+	}
+}
+
+// describeVerification fills in the DNS record proving control of a domain
+func describeVerification(domain *models.TenantDomain) {
+	domain.VerificationRecord = models.TenantDomainVerificationPrefix + "." + domain.Domain
+	domain.VerificationValue = "myerp-verification=" + domain.VerificationToken
+}
+
+// normalizeHost lowercases a host name and strips its port and trailing dot
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
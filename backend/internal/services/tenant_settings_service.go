@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+)
+
+// TenantSettingsService handles the typed tenant settings: branding, locale
+// defaults and feature toggles, with the security policies read-only. The
+// logo is uploaded through the file service.
+type TenantSettingsService struct {
+	tenantRepo  repository.TenantStore
+	fileService *FileService
+}
+
+// NewTenantSettingsService creates a new tenant settings service
+func NewTenantSettingsService(tenantRepo repository.TenantStore, fileService *FileService) *TenantSettingsService {
+	return &TenantSettingsService{
+		tenantRepo:  tenantRepo,
+		fileService: fileService,
+	}
+}
+
+// GetSettings retrieves a tenant's settings and their version
+func (s *TenantSettingsService) GetSettings(ctx context.Context, tenantID uuid.UUID) (*models.TenantSettings, error) {
+	raw, version, err := s.tenantRepo.GetSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return typedTenantSettings(raw, version), nil
+}
+
+// typedTenantSettings reads a tenant's settings JSON through the tenant's
+// settings getters
+func typedTenantSettings(raw json.RawMessage, version string) *models.TenantSettings {
+	tenant := &models.Tenant{Settings: raw}
+	return &models.TenantSettings{
+		Branding: *tenant.Branding(),
+		Locale:   *tenant.Locale(),
+		Features: tenant.Features(),
+		Security: models.TenantSecurity{
+			SSORequired:          tenant.SSORequired(),
+			TwoFactorPolicy:      tenant.TwoFactorPolicy(),
+			SessionLimit:         tenant.SessionLimit(),
+			UsageAnalyticsOptOut: tenant.UsageAnalyticsOptOut(),
+		},
+		Version: version,
+	}
+}
+
+// ApplyPatch applies a JSON merge patch (RFC 7386) of the branding, locale
+// and features sections to a tenant's settings. A section that does not fit
+// the settings schema fails with "invalid <section>".
+func (s *TenantSettingsService) ApplyPatch(current *models.TenantSettings, patch map[string]json.RawMessage) (*models.TenantSettings, error) {
+	updated := *current
+	targets := map[string]interface{}{
+		"branding": &updated.Branding,
+		"locale":   &updated.Locale,
+		"features": &updated.Features,
+	}
+
+	for _, section := range models.TenantSettingsSections {
+		sectionPatch, ok := patch[section]
+		if !ok {
+			continue
+		}
+
+		var currentValue, patchValue interface{}
+		data, err := json.Marshal(targets[section])
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &currentValue); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(sectionPatch, &patchValue); err != nil {
+			return nil, fmt.Errorf("invalid %s", section)
+		}
+
+		merged, err := json.Marshal(mergePatch(currentValue, patchValue))
+		if err != nil {
+			return nil, err
+		}
+
+		// Decode into a fresh value so cleared members do not survive
+		decoder := json.NewDecoder(bytes.NewReader(merged))
+		decoder.DisallowUnknownFields()
+		switch section {
+		case "branding":
+			updated.Branding = models.TenantBranding{}
+		case "locale":
+			updated.Locale = models.TenantLocale{}
+		case "features":
+			updated.Features = nil
+		}
+		if err := decoder.Decode(targets[section]); err != nil {
+			return nil, fmt.Errorf("invalid %s", section)
+		}
+	}
+
+	if updated.Features == nil {
+		updated.Features = map[string]bool{}
+	}
+	// Cleared locale defaults fall back to the server's
+	updated.Locale = updated.Locale.WithDefaults()
+
+	return &updated, nil
+}
+
+// mergePatch applies a JSON merge patch to a decoded JSON value: objects are
+// merged member by member, null removes a member and anything else replaces
+// the target
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// SaveSettings stores the branding, locale and features sections of a
+// tenant's settings if they are still at the given version. Fails with
+// "settings version mismatch" when they changed in the meantime.
+func (s *TenantSettingsService) SaveSettings(ctx context.Context, tenantID uuid.UUID, version string, settings *models.TenantSettings) (*models.TenantSettings, error) {
+	values := map[string]interface{}{
+		"branding": settings.Branding,
+		"locale":   settings.Locale,
+		"features": settings.Features,
+	}
+	sections := make(map[string]json.RawMessage, len(values))
+	for section, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", section, err)
+		}
+		sections[section] = data
+	}
+
+	newVersion, err := s.tenantRepo.UpdateSettingsSections(ctx, tenantID, sections, version)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := *settings
+	saved.Version = newVersion
+	return &saved, nil
+}
+
+// MaxLogoSize returns the largest logo image accepted, in bytes
+func (s *TenantSettingsService) MaxLogoSize() int64 {
+	return s.fileService.config.Storage.MaxAvatarSize
+}
+
+// UploadLogo replaces the tenant's logo with an uploaded image and points the
+// branding's logo URL at it. The previous logo's files are deleted and
+// purged with other deleted files.
+func (s *TenantSettingsService) UploadLogo(ctx context.Context, tenantID, actorID uuid.UUID, name string, data []byte) (*models.TenantSettings, error) {
+	resourceType := models.FileResourceTenant
+	file, err := s.fileService.Upload(ctx, tenantID, actorID, &models.FileUpload{
+		OriginalName: name,
+		Size:         int64(len(data)),
+		Category:     models.FileCategoryLogo,
+		ResourceType: &resourceType,
+		ResourceID:   &tenantID,
+	}, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	logoURL := s.fileService.PublicURL(file)
+	if err := s.tenantRepo.SetBrandingLogoURL(ctx, tenantID, &logoURL); err != nil {
+		if _, delErr := s.fileService.Delete(ctx, tenantID, actorID, file.ID); delErr != nil {
+			log.Printf("⚠️  Failed to delete unused logo file %s: %v", file.ID, delErr)
+		}
+		return nil, err
+	}
+
+	// The previous logo stays restorable until deleted files are purged
+	if _, err := s.fileService.DeleteResourceFiles(ctx, tenantID, actorID, models.FileCategoryLogo, models.FileResourceTenant, tenantID, []uuid.UUID{file.ID}); err != nil {
+		log.Printf("⚠️  Failed to delete previous logo of tenant %s: %v", tenantID, err)
+	}
+
+	return s.GetSettings(ctx, tenantID)
+}
+
+// RemoveLogo clears the branding's logo URL and deletes the uploaded logo
+func (s *TenantSettingsService) RemoveLogo(ctx context.Context, tenantID, actorID uuid.UUID) (*models.TenantSettings, error) {
+	if err := s.tenantRepo.SetBrandingLogoURL(ctx, tenantID, nil); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.fileService.DeleteResourceFiles(ctx, tenantID, actorID, models.FileCategoryLogo, models.FileResourceTenant, tenantID, nil); err != nil {
+		return nil, err
+	}
+
+	return s.GetSettings(ctx, tenantID)
+}
@@ -0,0 +1,988 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// TimesheetService handles timesheet projects and tasks, time entries and
+// the weekly timesheet workflow. Employees log time on their own timesheet
+// for the week and submit it; it is routed to the user of their manager, and
+// users with timesheets.approve decide when there is none, and for anyone.
+// Entries are locked once a timesheet is submitted and stay locked after
+// approval until someone with timesheets.manage reopens it.
+type TimesheetService struct {
+	db                *sqlx.DB
+	timesheetRepo     repository.TimesheetStore
+	employeeRepo      repository.EmployeeStore
+	userRepo          repository.UserStore
+	userRoleRepo      repository.UserRoleStore
+	crmRepo           repository.CRMStore
+	permissionService PermissionManager
+	notifier          NotificationManager
+}
+
+// NewTimesheetService creates a new timesheet service
+func NewTimesheetService(
+	db *sqlx.DB,
+	timesheetRepo repository.TimesheetStore,
+	employeeRepo repository.EmployeeStore,
+	userRepo repository.UserStore,
+	userRoleRepo repository.UserRoleStore,
+	crmRepo repository.CRMStore,
+	permissionService PermissionManager,
+	notifier NotificationManager,
+) *TimesheetService {
+	return &TimesheetService{
+		db:                db,
+		timesheetRepo:     timesheetRepo,
+		employeeRepo:      employeeRepo,
+		userRepo:          userRepo,
+		userRoleRepo:      userRoleRepo,
+		crmRepo:           crmRepo,
+		permissionService: permissionService,
+		notifier:          notifier,
+	}
+}
+
+// ListProjects retrieves the tenant's projects with their tasks
+func (s *TimesheetService) ListProjects(ctx context.Context, tenantID uuid.UUID, activeOnly bool, customerID *uuid.UUID) ([]models.TimesheetProject, error) {
+	projects, err := s.timesheetRepo.ListProjects(ctx, tenantID, activeOnly, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.timesheetRepo.ListTasks(ctx, tenantID, nil, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	byProject := make(map[uuid.UUID][]*models.TimesheetTask)
+	for i := range tasks {
+		byProject[tasks[i].ProjectID] = append(byProject[tasks[i].ProjectID], &tasks[i])
+	}
+	for i := range projects {
+		projects[i].Tasks = byProject[projects[i].ID]
+	}
+
+	return projects, nil
+}
+
+// GetProject retrieves a project with all its tasks
+func (s *TimesheetService) GetProject(ctx context.Context, tenantID, projectID uuid.UUID) (*models.TimesheetProject, error) {
+	project, err := s.timesheetRepo.FindProjectByID(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.timesheetRepo.ListTasks(ctx, tenantID, &project.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		project.Tasks = append(project.Tasks, &tasks[i])
+	}
+
+	return project, nil
+}
+
+// CreateProject creates a project. Code and name are required.
+func (s *TimesheetService) CreateProject(ctx context.Context, tenantID, creatorID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error) {
+	project := &models.TimesheetProject{
+		Billable:  true,
+		Currency:  "USD",
+		IsActive:  true,
+		CreatedBy: &creatorID,
+	}
+	applyTimesheetProjectRequest(project, req)
+
+	if err := s.checkProjectCode(ctx, tenantID, project.Code, nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkCustomer(ctx, tenantID, project); err != nil {
+		return nil, err
+	}
+
+	if err := s.timesheetRepo.CreateProject(ctx, tenantID, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// UpdateProject updates the fields of a project set in the request. Rate
+// changes apply to entries written afterwards.
+func (s *TimesheetService) UpdateProject(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetProjectRequest) (*models.TimesheetProject, error) {
+	project, err := s.timesheetRepo.FindProjectByID(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyTimesheetProjectRequest(project, req)
+
+	if req.Code != nil {
+		if err := s.checkProjectCode(ctx, tenantID, project.Code, &project.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.CustomerID != nil {
+		if err := s.checkCustomer(ctx, tenantID, project); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.timesheetRepo.UpdateProject(ctx, tenantID, project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// DeleteProject deletes a project nobody logged time on
+func (s *TimesheetService) DeleteProject(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	return s.timesheetRepo.DeleteProject(ctx, tenantID, projectID)
+}
+
+// CreateTask adds a task to a project. Name is required.
+func (s *TimesheetService) CreateTask(ctx context.Context, tenantID, projectID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error) {
+	if _, err := s.timesheetRepo.FindProjectByID(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+
+	task := &models.TimesheetTask{
+		ProjectID: projectID,
+		Billable:  true,
+		IsActive:  true,
+	}
+	applyTimesheetTaskRequest(task, req)
+
+	if err := s.checkTaskName(ctx, tenantID, task); err != nil {
+		return nil, err
+	}
+
+	if err := s.timesheetRepo.CreateTask(ctx, tenantID, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// UpdateTask updates the fields of a project's task set in the request
+func (s *TimesheetService) UpdateTask(ctx context.Context, tenantID, projectID, taskID uuid.UUID, req *models.TimesheetTaskRequest) (*models.TimesheetTask, error) {
+	task, err := s.findTask(ctx, tenantID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyTimesheetTaskRequest(task, req)
+
+	if req.Name != nil {
+		if err := s.checkTaskName(ctx, tenantID, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.timesheetRepo.UpdateTask(ctx, tenantID, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// DeleteTask deletes a project's task nobody logged time on
+func (s *TimesheetService) DeleteTask(ctx context.Context, tenantID, projectID, taskID uuid.UUID) error {
+	if _, err := s.findTask(ctx, tenantID, projectID, taskID); err != nil {
+		return err
+	}
+	return s.timesheetRepo.DeleteTask(ctx, tenantID, taskID)
+}
+
+// Week retrieves an employee's timesheet for the week containing day, with
+// its entries. A week nobody logged time in yet is returned as an unsaved
+// draft. employeeID nil means the user's own employee record; others'
+// timesheets require timesheets.view or being their manager.
+func (s *TimesheetService) Week(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID, day time.Time) (*models.Timesheet, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ownsEmployee(employee, userID) {
+		allowed, err := s.can(ctx, tenantID, userID, models.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			allowed, err = s.isManager(ctx, tenantID, userID, employee)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+		}
+	}
+
+	week := weekStart(day)
+	timesheet, err := s.timesheetRepo.FindTimesheetByWeek(ctx, tenantID, employee.ID, week)
+	if err != nil {
+		return nil, err
+	}
+	if timesheet == nil {
+		return &models.Timesheet{
+			TenantID:       tenantID,
+			EmployeeID:     employee.ID,
+			WeekStart:      week,
+			Status:         models.TimesheetStatusDraft,
+			EmployeeName:   employee.FullName(),
+			EmployeeUserID: employee.UserID,
+			Entries:        []*models.TimesheetEntry{},
+		}, nil
+	}
+
+	return s.withEntries(ctx, tenantID, timesheet)
+}
+
+// Get retrieves a timesheet with its entries the user may see: their own, one
+// they may decide, or any with timesheets.view
+func (s *TimesheetService) Get(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.FindTimesheetByID(ctx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if timesheet.EmployeeUserID == nil || *timesheet.EmployeeUserID != userID {
+		allowed, err := s.can(ctx, tenantID, userID, models.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			employee, err := s.employeeRepo.FindByID(ctx, tenantID, timesheet.EmployeeID)
+			if err != nil {
+				return nil, err
+			}
+			allowed, err = s.canDecide(ctx, tenantID, userID, timesheet, employee)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !allowed {
+			return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+		}
+	}
+
+	return s.withEntries(ctx, tenantID, timesheet)
+}
+
+// List retrieves timesheets with filters. Without timesheets.view, users only
+// see their own timesheets and those of the employees they manage.
+func (s *TimesheetService) List(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error) {
+	canView, err := s.can(ctx, tenantID, userID, models.ActionView)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var visibleTo *uuid.UUID
+	if !canView {
+		visibleTo = &userID
+	}
+
+	return s.timesheetRepo.ListTimesheets(ctx, tenantID, filter, visibleTo, limit, offset)
+}
+
+// ListOwn retrieves the timesheets of the user's employee record
+func (s *TimesheetService) ListOwn(ctx context.Context, tenantID, userID uuid.UUID, filter models.TimesheetFilter, limit, offset int) ([]models.Timesheet, int, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter.EmployeeID = &employee.ID
+	return s.timesheetRepo.ListTimesheets(ctx, tenantID, filter, nil, limit, offset)
+}
+
+// ListApprovals retrieves the submitted timesheets the user may decide: all
+// of them with timesheets.approve, otherwise those of the employees they
+// manage
+func (s *TimesheetService) ListApprovals(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) ([]models.Timesheet, int, error) {
+	canApprove, err := s.can(ctx, tenantID, userID, models.ActionApprove)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := models.TimesheetFilter{Status: models.TimesheetStatusSubmitted}
+	if !canApprove {
+		filter.ApproverID = &userID
+	}
+
+	return s.timesheetRepo.ListTimesheets(ctx, tenantID, filter, nil, limit, offset)
+}
+
+// AddEntry logs time on the user's timesheet for the week of the work date,
+// starting the timesheet if needed
+func (s *TimesheetService) AddEntry(ctx context.Context, tenantID, userID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error) {
+	employee, err := s.resolveEmployee(ctx, tenantID, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.TimesheetEntry{
+		TenantID:  tenantID,
+		CreatedBy: &userID,
+	}
+	if err := s.applyEntryRequest(ctx, tenantID, employee, entry, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err := s.timesheetRepo.LockWeek(ctx, tx.Tx, tenantID, employee.ID, weekStart(entry.WorkDate))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEntryFits(ctx, tx.Tx, timesheet, entry); err != nil {
+		return nil, err
+	}
+
+	entry.TimesheetID = timesheet.ID
+	if err := s.timesheetRepo.CreateEntry(ctx, tx.Tx, entry); err != nil {
+		return nil, err
+	}
+	if err := s.timesheetRepo.RefreshTotal(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// UpdateEntry changes an entry of the user's timesheet. Entries stay in their
+// week; log time in another week as a new entry.
+func (s *TimesheetService) UpdateEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID, req *models.TimesheetEntryRequest) (*models.TimesheetEntry, error) {
+	entry, timesheet, err := s.ownEntry(ctx, tenantID, userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, timesheet.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyEntryRequest(ctx, tenantID, employee, entry, req); err != nil {
+		return nil, err
+	}
+	if !weekStart(entry.WorkDate).Equal(dateOnly(timesheet.WeekStart)) {
+		return nil, fmt.Errorf("entry cannot move to another week")
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err = s.timesheetRepo.LockTimesheet(ctx, tx.Tx, tenantID, timesheet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEntryFits(ctx, tx.Tx, timesheet, entry); err != nil {
+		return nil, err
+	}
+
+	if err := s.timesheetRepo.UpdateEntry(ctx, tx.Tx, entry); err != nil {
+		return nil, err
+	}
+	if err := s.timesheetRepo.RefreshTotal(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// DeleteEntry removes an entry from the user's timesheet
+func (s *TimesheetService) DeleteEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.TimesheetEntry, error) {
+	entry, timesheet, err := s.ownEntry(ctx, tenantID, userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err = s.timesheetRepo.LockTimesheet(ctx, tx.Tx, tenantID, timesheet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !timesheet.IsEditable() {
+		return nil, fmt.Errorf("timesheet is locked")
+	}
+
+	if err := s.timesheetRepo.DeleteEntry(ctx, tx.Tx, tenantID, entry.ID); err != nil {
+		return nil, err
+	}
+	if err := s.timesheetRepo.RefreshTotal(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Submit sends the user's draft or rejected timesheet for approval, locking
+// its entries, and asks the manager (or else the timesheet approvers) to
+// decide
+func (s *TimesheetService) Submit(ctx context.Context, tenantID, userID, timesheetID uuid.UUID) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.FindTimesheetByID(ctx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if timesheet.EmployeeUserID == nil || *timesheet.EmployeeUserID != userID {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, timesheet.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	approverID, err := s.managerUserID(ctx, tenantID, employee)
+	if err != nil {
+		return nil, err
+	}
+
+	deciders, err := s.deciders(ctx, tenantID, employee, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err = s.timesheetRepo.LockTimesheet(ctx, tx.Tx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if !timesheet.IsEditable() {
+		return nil, fmt.Errorf("timesheet is already submitted")
+	}
+	if timesheet.TotalHours <= 0 {
+		return nil, fmt.Errorf("timesheet has no entries")
+	}
+
+	now := time.Now()
+	timesheet.Status = models.TimesheetStatusSubmitted
+	timesheet.SubmittedAt = &now
+	timesheet.ApproverID = approverID
+	timesheet.DecidedBy = nil
+	timesheet.DecidedAt = nil
+	timesheet.DecisionNote = nil
+	if err := s.timesheetRepo.UpdateTimesheetStatus(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	deciderIDs := make([]uuid.UUID, len(deciders))
+	for i, decider := range deciders {
+		deciderIDs[i] = decider.ID
+	}
+	s.notifyInApp(ctx, tenantID, deciderIDs, &models.NotificationRequest{
+		Type:  models.NotificationTypeTimesheetSubmitted,
+		Title: fmt.Sprintf("%s submitted a timesheet", timesheet.EmployeeName),
+		Body:  fmt.Sprintf("%s (%s hours)", formatTimesheetWeek(timesheet), formatHours(timesheet.TotalHours)),
+		Link:  "/timesheets/approvals",
+		Data:  map[string]interface{}{"timesheet_id": timesheet.ID},
+	})
+
+	return timesheet, nil
+}
+
+// Approve approves a submitted timesheet; its entries stay locked
+func (s *TimesheetService) Approve(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	return s.decide(ctx, tenantID, userID, timesheetID, true, note)
+}
+
+// Reject sends a submitted timesheet back to the employee to correct
+func (s *TimesheetService) Reject(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	return s.decide(ctx, tenantID, userID, timesheetID, false, note)
+}
+
+// decide records the decision on a submitted timesheet and tells the
+// employee. The timesheet's approver, the employee's current manager and
+// users with timesheets.approve may decide, but never on their own time.
+func (s *TimesheetService) decide(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, approve bool, note *string) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.FindTimesheetByID(ctx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.employeeRepo.FindByID(ctx, tenantID, timesheet.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownsEmployee(employee, userID) {
+		return nil, fmt.Errorf("cannot decide on own timesheet")
+	}
+	allowed, err := s.canDecide(ctx, tenantID, userID, timesheet, employee)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions")
+	}
+
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err = s.timesheetRepo.LockTimesheet(ctx, tx.Tx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if timesheet.Status != models.TimesheetStatusSubmitted {
+		return nil, fmt.Errorf("timesheet is not submitted")
+	}
+
+	now := time.Now()
+	timesheet.DecidedBy = &userID
+	timesheet.DecidedAt = &now
+	timesheet.DecisionNote = note
+	timesheet.Status = models.TimesheetStatusRejected
+	if approve {
+		timesheet.Status = models.TimesheetStatusApproved
+	}
+	if err := s.timesheetRepo.UpdateTimesheetStatus(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if employee.UserID != nil {
+		s.notifyInApp(ctx, tenantID, []uuid.UUID{*employee.UserID}, &models.NotificationRequest{
+			Type:  models.NotificationTypeTimesheetDecided,
+			Title: fmt.Sprintf("Your timesheet was %s", timesheet.Status),
+			Body:  formatTimesheetWeek(timesheet),
+			Link:  "/timesheets/" + timesheet.ID.String(),
+			Data:  map[string]interface{}{"timesheet_id": timesheet.ID, "status": timesheet.Status},
+		})
+	}
+
+	return timesheet, nil
+}
+
+// Reopen returns an approved timesheet to draft so its entries can be
+// corrected and submitted again. Requires timesheets.manage.
+func (s *TimesheetService) Reopen(ctx context.Context, tenantID, userID, timesheetID uuid.UUID, note *string) (*models.Timesheet, error) {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	timesheet, err := s.timesheetRepo.LockTimesheet(ctx, tx.Tx, tenantID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if timesheet.Status != models.TimesheetStatusApproved {
+		return nil, fmt.Errorf("timesheet is not approved")
+	}
+
+	timesheet.Status = models.TimesheetStatusDraft
+	timesheet.SubmittedAt = nil
+	timesheet.DecidedBy = nil
+	timesheet.DecidedAt = nil
+	timesheet.DecisionNote = note
+	if err := s.timesheetRepo.UpdateTimesheetStatus(ctx, tx.Tx, timesheet); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if timesheet.EmployeeUserID != nil && *timesheet.EmployeeUserID != userID {
+		s.notifyInApp(ctx, tenantID, []uuid.UUID{*timesheet.EmployeeUserID}, &models.NotificationRequest{
+			Type:  models.NotificationTypeTimesheetDecided,
+			Title: "Your timesheet was reopened",
+			Body:  formatTimesheetWeek(timesheet),
+			Link:  "/timesheets/" + timesheet.ID.String(),
+			Data:  map[string]interface{}{"timesheet_id": timesheet.ID, "status": timesheet.Status},
+		})
+	}
+
+	return timesheet, nil
+}
+
+// ProjectReport sums the time logged over a period per project, for billing
+func (s *TimesheetService) ProjectReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error) {
+	return s.timesheetRepo.Report(ctx, tenantID, filter, false)
+}
+
+// EmployeeReport sums the time logged over a period per employee
+func (s *TimesheetService) EmployeeReport(ctx context.Context, tenantID uuid.UUID, filter models.TimesheetReportFilter) ([]models.TimesheetReportRow, error) {
+	return s.timesheetRepo.Report(ctx, tenantID, filter, true)
+}
+
+// applyEntryRequest validates an entry request for the employee and copies
+// it to the entry, with the billing of its project and task
+func (s *TimesheetService) applyEntryRequest(ctx context.Context, tenantID uuid.UUID, employee *models.Employee, entry *models.TimesheetEntry, req *models.TimesheetEntryRequest) error {
+	workDate := dateOnly(req.WorkDate)
+	if workDate.Before(dateOnly(employee.HireDate)) ||
+		(employee.TerminationDate != nil && workDate.After(*employee.TerminationDate)) {
+		return fmt.Errorf("work date is outside the employment period")
+	}
+
+	project, err := s.timesheetRepo.FindProjectByID(ctx, tenantID, req.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !project.IsActive {
+		return fmt.Errorf("project is not active")
+	}
+
+	entry.ProjectID = project.ID
+	entry.ProjectCode = project.Code
+	entry.ProjectName = project.Name
+	entry.TaskID = nil
+	entry.TaskName = nil
+	entry.Billable = project.Billable
+	entry.HourlyRate = project.HourlyRate
+
+	if req.TaskID != nil {
+		task, err := s.findTask(ctx, tenantID, project.ID, *req.TaskID)
+		if err != nil {
+			return err
+		}
+		if !task.IsActive {
+			return fmt.Errorf("task is not active")
+		}
+		entry.TaskID = &task.ID
+		entry.TaskName = &task.Name
+		entry.Billable = project.Billable && task.Billable
+		if task.HourlyRate != nil {
+			entry.HourlyRate = task.HourlyRate
+		}
+	}
+
+	entry.WorkDate = workDate
+	entry.Hours = req.Hours
+	entry.Description = req.Description
+	return nil
+}
+
+// checkEntryFits checks within tx that a timesheet can still change and that
+// the entry keeps its day within 24 hours
+func (s *TimesheetService) checkEntryFits(ctx context.Context, tx *sqlx.Tx, timesheet *models.Timesheet, entry *models.TimesheetEntry) error {
+	if !timesheet.IsEditable() {
+		return fmt.Errorf("timesheet is locked")
+	}
+
+	var excludeID *uuid.UUID
+	if entry.ID != uuid.Nil {
+		excludeID = &entry.ID
+	}
+	logged, err := s.timesheetRepo.DayHours(ctx, tx, timesheet.TenantID, timesheet.ID, entry.WorkDate, excludeID)
+	if err != nil {
+		return err
+	}
+	if logged+entry.Hours > 24 {
+		return fmt.Errorf("more than 24 hours logged on a day")
+	}
+
+	return nil
+}
+
+// ownEntry retrieves an entry of the user's own timesheet with the timesheet
+func (s *TimesheetService) ownEntry(ctx context.Context, tenantID, userID, entryID uuid.UUID) (*models.TimesheetEntry, *models.Timesheet, error) {
+	entry, err := s.timesheetRepo.FindEntryByID(ctx, tenantID, entryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timesheet, err := s.timesheetRepo.FindTimesheetByID(ctx, tenantID, entry.TimesheetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if timesheet.EmployeeUserID == nil || *timesheet.EmployeeUserID != userID {
+		return nil, nil, utils.NewNotFoundError("TIMESHEET_ENTRY_NOT_FOUND", "timesheet entry not found")
+	}
+
+	return entry, timesheet, nil
+}
+
+// withEntries loads a timesheet's entries into it
+func (s *TimesheetService) withEntries(ctx context.Context, tenantID uuid.UUID, timesheet *models.Timesheet) (*models.Timesheet, error) {
+	entries, err := s.timesheetRepo.ListEntries(ctx, tenantID, timesheet.ID)
+	if err != nil {
+		return nil, err
+	}
+	timesheet.Entries = entries
+	return timesheet, nil
+}
+
+// findTask retrieves a task of a project
+func (s *TimesheetService) findTask(ctx context.Context, tenantID, projectID, taskID uuid.UUID) (*models.TimesheetTask, error) {
+	task, err := s.timesheetRepo.FindTaskByID(ctx, tenantID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ProjectID != projectID {
+		return nil, utils.NewNotFoundError("TASK_NOT_FOUND", "task not found")
+	}
+	return task, nil
+}
+
+// resolveEmployee returns the employee with employeeID, or the user's own
+// employee record when nil
+func (s *TimesheetService) resolveEmployee(ctx context.Context, tenantID, userID uuid.UUID, employeeID *uuid.UUID) (*models.Employee, error) {
+	if employeeID != nil {
+		return s.employeeRepo.FindByID(ctx, tenantID, *employeeID)
+	}
+
+	employee, err := s.employeeRepo.FindByUserID(ctx, tenantID, userID)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			return nil, fmt.Errorf("no employee record")
+		}
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+// managerUserID returns the active user of the employee's manager, to route
+// the employee's timesheets to. Returns nil if there is none.
+func (s *TimesheetService) managerUserID(ctx context.Context, tenantID uuid.UUID, employee *models.Employee) (*uuid.UUID, error) {
+	if employee.ManagerID == nil {
+		return nil, nil
+	}
+
+	manager, err := s.employeeRepo.FindByID(ctx, tenantID, *employee.ManagerID)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if manager.UserID == nil || manager.IsTerminated() || ownsEmployee(employee, *manager.UserID) {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenantID, *manager.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, nil
+	}
+
+	return &user.ID, nil
+}
+
+// deciders returns the users asked to decide on an employee's timesheet: the
+// manager's user, or else every user with timesheets.approve
+func (s *TimesheetService) deciders(ctx context.Context, tenantID uuid.UUID, employee *models.Employee, approverID *uuid.UUID) ([]models.User, error) {
+	if approverID != nil {
+		approver, err := s.userRepo.FindByID(ctx, tenantID, *approverID)
+		if err != nil {
+			return nil, err
+		}
+		return []models.User{*approver}, nil
+	}
+
+	approvers, err := s.userRoleRepo.GetUsersWithPermission(ctx, tenantID, models.ResourceTimesheets, models.ActionApprove)
+	if err != nil {
+		return nil, err
+	}
+
+	deciders := make([]models.User, 0, len(approvers))
+	for _, approver := range approvers {
+		if !ownsEmployee(employee, approver.ID) {
+			deciders = append(deciders, approver)
+		}
+	}
+
+	return deciders, nil
+}
+
+// canDecide checks whether the user may decide on a timesheet: its approver,
+// the employee's current manager, or a user with timesheets.approve
+func (s *TimesheetService) canDecide(ctx context.Context, tenantID, userID uuid.UUID, timesheet *models.Timesheet, employee *models.Employee) (bool, error) {
+	if timesheet.ApproverID != nil && *timesheet.ApproverID == userID {
+		return true, nil
+	}
+
+	isManager, err := s.isManager(ctx, tenantID, userID, employee)
+	if err != nil || isManager {
+		return isManager, err
+	}
+
+	return s.can(ctx, tenantID, userID, models.ActionApprove)
+}
+
+// isManager checks whether the user is the employee's manager
+func (s *TimesheetService) isManager(ctx context.Context, tenantID, userID uuid.UUID, employee *models.Employee) (bool, error) {
+	managerUserID, err := s.managerUserID(ctx, tenantID, employee)
+	if err != nil {
+		return false, err
+	}
+	return managerUserID != nil && *managerUserID == userID, nil
+}
+
+// can checks a timesheet permission of the user
+func (s *TimesheetService) can(ctx context.Context, tenantID, userID uuid.UUID, action string) (bool, error) {
+	return s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceTimesheets, action)
+}
+
+// notifyInApp adds an in-app notification for the users. Failures are only
+// logged: the change is already committed.
+func (s *TimesheetService) notifyInApp(ctx context.Context, tenantID uuid.UUID, userIDs []uuid.UUID, req *models.NotificationRequest) {
+	if err := s.notifier.Notify(ctx, tenantID, userIDs, req); err != nil {
+		log.Printf("⚠️  Failed to send %s notification in tenant %s: %v", req.Type, tenantID, err)
+	}
+}
+
+// checkProjectCode checks that a project code is not taken
+func (s *TimesheetService) checkProjectCode(ctx context.Context, tenantID uuid.UUID, code string, projectID *uuid.UUID) error {
+	exists, err := s.timesheetRepo.CheckProjectCodeExists(ctx, tenantID, code, projectID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("PROJECT_CODE_EXISTS", "project code already exists")
+	}
+	return nil
+}
+
+// checkTaskName checks that a task name is not taken within its project
+func (s *TimesheetService) checkTaskName(ctx context.Context, tenantID uuid.UUID, task *models.TimesheetTask) error {
+	var taskID *uuid.UUID
+	if task.ID != uuid.Nil {
+		taskID = &task.ID
+	}
+	exists, err := s.timesheetRepo.CheckTaskNameExists(ctx, tenantID, task.ProjectID, task.Name, taskID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.NewConflictError("TASK_NAME_EXISTS", "task name already exists")
+	}
+	return nil
+}
+
+// checkCustomer checks that a project's customer exists in the CRM and fills
+// in its name
+func (s *TimesheetService) checkCustomer(ctx context.Context, tenantID uuid.UUID, project *models.TimesheetProject) error {
+	if project.CustomerID == nil {
+		return nil
+	}
+	customer, err := s.crmRepo.FindCustomerByID(ctx, tenantID, *project.CustomerID)
+	if err != nil {
+		return err
+	}
+	project.CustomerName = &customer.Name
+	return nil
+}
+
+// applyTimesheetProjectRequest copies the fields set in a request to a
+// project. Codes and currencies are stored upper case.
+func applyTimesheetProjectRequest(project *models.TimesheetProject, req *models.TimesheetProjectRequest) {
+	if req.Code != nil {
+		project.Code = strings.ToUpper(strings.TrimSpace(*req.Code))
+	}
+	if req.Name != nil {
+		project.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		project.Description = req.Description
+	}
+	if req.CustomerID != nil {
+		project.CustomerID = req.CustomerID
+	}
+	if req.Billable != nil {
+		project.Billable = *req.Billable
+	}
+	if req.HourlyRate != nil {
+		project.HourlyRate = req.HourlyRate
+	}
+	if req.Currency != nil {
+		project.Currency = strings.ToUpper(strings.TrimSpace(*req.Currency))
+	}
+	if req.IsActive != nil {
+		project.IsActive = *req.IsActive
+	}
+}
+
+// applyTimesheetTaskRequest copies the fields set in a request to a task
+func applyTimesheetTaskRequest(task *models.TimesheetTask, req *models.TimesheetTaskRequest) {
+	if req.Name != nil {
+		task.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Billable != nil {
+		task.Billable = *req.Billable
+	}
+	if req.HourlyRate != nil {
+		task.HourlyRate = req.HourlyRate
+	}
+	if req.IsActive != nil {
+		task.IsActive = *req.IsActive
+	}
+}
+
+// weekStart returns the Monday of the ISO week containing day
+func weekStart(day time.Time) time.Time {
+	date := dateOnly(day)
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
+
+// formatTimesheetWeek formats a timesheet's week for people, e.g.
+// "week of 2026-03-02"
+func formatTimesheetWeek(timesheet *models.Timesheet) string {
+	return "week of " + timesheet.WeekStart.Format("2006-01-02")
+}
+
+// formatHours formats hours without trailing zeros, e.g. "37.5"
+func formatHours(hours float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", hours), "0"), ".")
+}
@@ -96,7 +96,7 @@ func (s *TwoFactorService) EnableTwoFactor(ctx context.Context, tenantID, userID
 	// Verify the initial code
 	valid := totp.Validate(verificationCode, secret)
 	if !valid {
-		return fmt.Errorf("invalid verification code")
+		return utils.NewBadRequestError("INVALID_VERIFICATION_CODE", "invalid verification code")
 	}
 
 	// Encrypt the secret
@@ -165,7 +165,7 @@ func (s *TwoFactorService) DisableTwoFactor(ctx context.Context, tenantID, userI
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return utils.NewNotFoundError("USER_NOT_FOUND", "user not found")
 	}
 
 	// Clear all trusted devices
@@ -188,7 +188,7 @@ func (s *TwoFactorService) VerifyTOTP(ctx context.Context, tenantID, userID uuid
 			s.redis.Expire(ctx, rateLimitKey, twoFARateLimitWindow)
 		}
 		if attempts > twoFARateLimit {
-			return false, fmt.Errorf("too many failed 2FA attempts, please try again in 15 minutes")
+			return false, utils.NewTooManyRequestsError("TOO_MANY_2FA_ATTEMPTS", "too many failed 2FA attempts, please try again in 15 minutes")
 		}
 	}
 
@@ -203,7 +203,7 @@ func (s *TwoFactorService) VerifyTOTP(ctx context.Context, tenantID, userID uuid
 	query := `SELECT two_factor_secret FROM users WHERE id = $1 AND two_factor_enabled = true`
 	err = tx.QueryRowContext(ctx, query, userID).Scan(&encryptedSecret)
 	if err != nil {
-		return false, fmt.Errorf("2FA not enabled or user not found")
+		return false, utils.NewBadRequestError("TWO_FACTOR_NOT_ENABLED", "2FA not enabled or user not found")
 	}
 
 	// Decrypt secret
@@ -242,11 +242,11 @@ func (s *TwoFactorService) VerifyBackupCode(ctx context.Context, tenantID, userI
 	query := `SELECT two_factor_backup_codes FROM users WHERE id = $1 AND two_factor_enabled = true`
 	err = tx.QueryRowContext(ctx, query, userID).Scan(&encryptedCodesArray)
 	if err != nil {
-		return false, fmt.Errorf("2FA not enabled or user not found")
+		return false, utils.NewBadRequestError("TWO_FACTOR_NOT_ENABLED", "2FA not enabled or user not found")
 	}
 
 	if len(encryptedCodesArray) == 0 {
-		return false, fmt.Errorf("no backup codes available")
+		return false, utils.NewBadRequestError("NO_BACKUP_CODES", "no backup codes available")
 	}
 
 	// Decrypt and check codes
@@ -334,7 +334,7 @@ func (s *TwoFactorService) RegenerateBackupCodes(ctx context.Context, tenantID,
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("2FA not enabled")
+		return nil, utils.NewBadRequestError("TWO_FACTOR_NOT_ENABLED", "2FA not enabled")
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -401,7 +401,7 @@ func (s *TwoFactorService) GetRemainingBackupCodes(ctx context.Context, tenantID
 	query := `SELECT two_factor_backup_codes FROM users WHERE id = $1 AND two_factor_enabled = true`
 	err = tx.QueryRowContext(ctx, query, userID).Scan(&encryptedCodesArray)
 	if err != nil {
-		return 0, fmt.Errorf("2FA not enabled or user not found")
+		return 0, utils.NewBadRequestError("TWO_FACTOR_NOT_ENABLED", "2FA not enabled or user not found")
 	}
 
 	return len(encryptedCodesArray), tx.Commit()
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// usageActiveUsersTTL keeps a day's active user counter until the day's last
+// flushes are done
+const usageActiveUsersTTL = 48 * time.Hour
+
+// usageActiveUsersKey is the Redis HyperLogLog counting a tenant's distinct
+// active users of a day
+func usageActiveUsersKey(day time.Time, tenantRef string) string {
+	return "usage:active:" + day.Format("2006-01-02") + ":" + tenantRef
+}
+
+// usageCounter identifies a counter of requests to an endpoint
+type usageCounter struct {
+	day      time.Time
+	tenantID uuid.UUID
+	endpoint string
+}
+
+// usageDay identifies a tenant's usage of a day
+type usageDay struct {
+	day      time.Time
+	tenantID uuid.UUID
+}
+
+// UsageService collects anonymized feature usage per tenant for the product
+// team: requests per endpoint and module, and daily active users. Each
+// replica counts its own requests in memory and adds them to the daily rows
+// on every flush. Tenants are stored under a pseudonymous reference, and
+// users only enter a distinct count kept in Redis.
+type UsageService struct {
+	statRepo   repository.UsageStatStore
+	tenantRepo repository.TenantStore
+	redis      *redis.Client
+	config     *config.UsageAnalyticsConfig
+	client     *http.Client
+
+	mu      sync.Mutex
+	counts  map[usageCounter]int64
+	pending map[usageDay]map[uuid.UUID]struct{} // Users not yet added to the day's count
+	seen    map[usageDay]map[uuid.UUID]struct{} // Users this replica already counted
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewUsageService creates a new usage analytics service
+func NewUsageService(statRepo repository.UsageStatStore, tenantRepo repository.TenantStore, redis *redis.Client, cfg *config.UsageAnalyticsConfig) *UsageService {
+	return &UsageService{
+		statRepo:   statRepo,
+		tenantRepo: tenantRepo,
+		redis:      redis,
+		config:     cfg,
+		client: &http.Client{
+			Timeout:   cfg.SinkTimeout,
+			Transport: metrics.Transport("usage_sink", nil),
+		},
+		counts:  make(map[usageCounter]int64),
+		pending: make(map[usageDay]map[uuid.UUID]struct{}),
+		seen:    make(map[usageDay]map[uuid.UUID]struct{}),
+	}
+}
+
+// Record counts a successful request of a user to an endpoint (method and
+// route pattern). Callers leave out tenants that opted out and sandboxes.
+func (s *UsageService) Record(tenantID, userID uuid.UUID, endpoint string) {
+	if !s.config.Enabled {
+		return
+	}
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	key := usageDay{day: day, tenantID: tenantID}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[usageCounter{day: day, tenantID: tenantID, endpoint: endpoint}]++
+
+	if _, ok := s.seen[key][userID]; ok {
+		return
+	}
+	if s.seen[key] == nil {
+		s.seen[key] = make(map[uuid.UUID]struct{})
+	}
+	s.seen[key][userID] = struct{}{}
+	if s.pending[key] == nil {
+		s.pending[key] = make(map[uuid.UUID]struct{})
+	}
+	s.pending[key][userID] = struct{}{}
+}
+
+// Start flushes this replica's counters every USAGE_ANALYTICS_FLUSH_INTERVAL.
+// Unlike background jobs it runs on every replica, as each only sees its own
+// requests.
+func (s *UsageService) Start(ctx context.Context) {
+	if !s.config.Enabled {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Flush(ctx); err != nil {
+					log.Printf("⚠️  Failed to flush usage analytics: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops flushing and writes what was recorded since the last flush
+func (s *UsageService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.Flush(ctx); err != nil {
+		log.Printf("⚠️  Failed to flush usage analytics: %v", err)
+	}
+}
+
+// Flush adds the counters recorded since the last flush to the daily rows.
+// Tenants that opted out since are left out. Counters that fail to be written
+// are dropped; active users are counted again on the next flush.
+func (s *UsageService) Flush(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	counts, pending := s.counts, s.pending
+	s.counts = make(map[usageCounter]int64)
+	s.pending = make(map[usageDay]map[uuid.UUID]struct{})
+
+	// Only today's and yesterday's users can still be counted
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	for key := range s.seen {
+		if key.day.Before(yesterday) {
+			delete(s.seen, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(counts) == 0 && len(pending) == 0 {
+		return 0, nil
+	}
+
+	optedOut, err := s.optedOut(ctx, counts, pending)
+	if err != nil {
+		s.retryActiveUsers(pending)
+		return 0, err
+	}
+
+	stats := []models.UsageStat{}
+	modules := make(map[usageCounter]int64)
+	for counter, count := range counts {
+		if optedOut[counter.tenantID] {
+			continue
+		}
+		stats = append(stats, models.UsageStat{
+			Day:       counter.day,
+			TenantRef: s.TenantRef(counter.tenantID),
+			Kind:      models.UsageKindEndpoint,
+			Name:      counter.endpoint,
+			Count:     count,
+		})
+		modules[usageCounter{day: counter.day, tenantID: counter.tenantID, endpoint: usageModule(counter.endpoint)}] += count
+	}
+	for module, count := range modules {
+		stats = append(stats, models.UsageStat{
+			Day:       module.day,
+			TenantRef: s.TenantRef(module.tenantID),
+			Kind:      models.UsageKindModule,
+			Name:      module.endpoint,
+			Count:     count,
+		})
+	}
+
+	for key, users := range pending {
+		if optedOut[key.tenantID] {
+			continue
+		}
+		count, err := s.countActiveUsers(ctx, key, users)
+		if err != nil {
+			s.retryActiveUsers(map[usageDay]map[uuid.UUID]struct{}{key: users})
+			log.Printf("⚠️  Failed to count active users: %v", err)
+			continue
+		}
+		stats = append(stats, models.UsageStat{
+			Day:       key.day,
+			TenantRef: s.TenantRef(key.tenantID),
+			Kind:      models.UsageKindActiveUsers,
+			Count:     count,
+		})
+	}
+
+	if len(stats) == 0 {
+		return 0, nil
+	}
+	if err := s.statRepo.Add(ctx, stats); err != nil {
+		return 0, err
+	}
+
+	return len(stats), nil
+}
+
+// optedOut returns which of the tenants with counters opted out of usage
+// analytics
+func (s *UsageService) optedOut(ctx context.Context, counts map[usageCounter]int64, pending map[usageDay]map[uuid.UUID]struct{}) (map[uuid.UUID]bool, error) {
+	tenants := make(map[uuid.UUID]bool)
+	for counter := range counts {
+		tenants[counter.tenantID] = true
+	}
+	for key := range pending {
+		tenants[key.tenantID] = true
+	}
+
+	tenantIDs := make([]uuid.UUID, 0, len(tenants))
+	for tenantID := range tenants {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	ids, err := s.tenantRepo.FindUsageAnalyticsOptOuts(ctx, tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	optedOut := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		optedOut[id] = true
+	}
+	return optedOut, nil
+}
+
+// countActiveUsers adds users to the day's distinct count of the tenant and
+// returns the count. Only a keyed hash of each user ID is sent to Redis.
+func (s *UsageService) countActiveUsers(ctx context.Context, key usageDay, users map[uuid.UUID]struct{}) (int64, error) {
+	redisKey := usageActiveUsersKey(key.day, s.TenantRef(key.tenantID))
+
+	members := make([]interface{}, 0, len(users))
+	for userID := range users {
+		members = append(members, s.pseudonym("user", userID))
+	}
+
+	var count *redis.IntCmd
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFAdd(ctx, redisKey, members...)
+		pipe.Expire(ctx, redisKey, usageActiveUsersTTL)
+		count = pipe.PFCount(ctx, redisKey)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count.Val(), nil
+}
+
+// retryActiveUsers puts users back to be counted on the next flush
+func (s *UsageService) retryActiveUsers(pending map[usageDay]map[uuid.UUID]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, users := range pending {
+		if s.pending[key] == nil {
+			s.pending[key] = make(map[uuid.UUID]struct{})
+		}
+		for userID := range users {
+			s.pending[key][userID] = struct{}{}
+		}
+	}
+}
+
+// TenantRef returns the pseudonymous reference a tenant's usage is stored
+// under. It cannot be reversed without USAGE_ANALYTICS_SECRET.
+func (s *UsageService) TenantRef(tenantID uuid.UUID) string {
+	return s.pseudonym("tenant", tenantID)
+}
+
+// pseudonym returns a keyed hash of an ID
+func (s *UsageService) pseudonym(kind string, id uuid.UUID) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Secret))
+	mac.Write([]byte(kind + ":" + id.String()))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// usageModule returns the module of an endpoint: the first segment of its
+// route pattern, e.g. "crm" for "GET /crm/customers/{id}"
+func usageModule(endpoint string) string {
+	_, path, _ := strings.Cut(endpoint, " ")
+	module, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if module == "" {
+		return "(root)"
+	}
+	return module
+}
+
+// GetPrivacySettings retrieves a tenant's privacy settings
+func (s *UsageService) GetPrivacySettings(ctx context.Context, tenantID uuid.UUID) (*models.PrivacySettings, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("TENANT_NOT_FOUND", "tenant not found")
+	}
+
+	return &models.PrivacySettings{
+		UsageAnalytics: !tenant.UsageAnalyticsOptOut(),
+	}, nil
+}
+
+// UpdatePrivacySettings changes a tenant's privacy settings. Opting out of
+// usage analytics also deletes the usage collected so far.
+func (s *UsageService) UpdatePrivacySettings(ctx context.Context, tenantID uuid.UUID, req *models.PrivacySettingsUpdateRequest) (*models.PrivacySettings, error) {
+	if req.UsageAnalytics != nil {
+		if err := s.tenantRepo.SetUsageAnalyticsOptOut(ctx, tenantID, !*req.UsageAnalytics); err != nil {
+			return nil, err
+		}
+
+		if !*req.UsageAnalytics {
+			if err := s.forget(ctx, tenantID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.GetPrivacySettings(ctx, tenantID)
+}
+
+// forget deletes a tenant's collected usage and this replica's counters
+func (s *UsageService) forget(ctx context.Context, tenantID uuid.UUID) error {
+	s.mu.Lock()
+	for counter := range s.counts {
+		if counter.tenantID == tenantID {
+			delete(s.counts, counter)
+		}
+	}
+	for key := range s.pending {
+		if key.tenantID == tenantID {
+			delete(s.pending, key)
+		}
+	}
+	s.mu.Unlock()
+
+	ref := s.TenantRef(tenantID)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := s.redis.Del(ctx, usageActiveUsersKey(today, ref), usageActiveUsersKey(today.AddDate(0, 0, -1), ref)).Err(); err != nil {
+		log.Printf("⚠️  Failed to delete active user counts of tenant %s: %v", tenantID, err)
+	}
+
+	if _, err := s.statRepo.DeleteTenant(ctx, ref); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Export sends the previous UTC day's usage to USAGE_ANALYTICS_SINK_URL.
+// Returns the number of rows sent.
+func (s *UsageService) Export(ctx context.Context) (int, error) {
+	if s.config.SinkURL == "" {
+		return 0, nil
+	}
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	stats, err := s.statRepo.ListDay(ctx, day)
+	if err != nil {
+		return 0, err
+	}
+	if len(stats) == 0 {
+		return 0, nil
+	}
+
+	body, err := json.Marshal(models.UsageExport{
+		Day:   day.Format("2006-01-02"),
+		Stats: stats,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode usage export: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.SinkURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build usage export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.SinkToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.SinkToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("usage export failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("usage export failed with status %d", resp.StatusCode)
+	}
+
+	return len(stats), nil
+}
+
+// Cleanup deletes daily usage rows older than USAGE_ANALYTICS_RETENTION
+func (s *UsageService) Cleanup(ctx context.Context) (int, error) {
+	return s.statRepo.DeleteBefore(ctx, time.Now().Add(-s.config.Retention).UTC().Truncate(24*time.Hour))
+}
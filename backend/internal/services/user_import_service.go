@@ -0,0 +1,489 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+const userImportProgressEvery = 25 // Rows imported between progress updates of an import job
+
+// userImportPlan is a validated row and what it resolved to
+type userImportPlan struct {
+	row          models.UserImportRow
+	roleIDs      []uuid.UUID
+	departmentID *uuid.UUID
+}
+
+// UserImportService creates users in bulk from CSV or XLSX files. Every row
+// is validated before anything is created; valid rows become active users
+// who are emailed a link to set their password. Files larger than
+// SyncRowLimit rows are imported by an async job.
+type UserImportService struct {
+	db                *sqlx.DB
+	userRepo          repository.UserStore
+	roleRepo          repository.RoleStore
+	departmentRepo    repository.DepartmentStore
+	tenantRepo        repository.TenantStore
+	emailService      EmailManager
+	emailQueue        EmailQueueManager
+	asyncJobService   AsyncJobManager
+	permissionService PermissionManager
+	auditService      AuditManager
+	quotaService      QuotaManager
+	config            *config.UserImportConfig
+}
+
+// NewUserImportService creates a new user import service
+func NewUserImportService(
+	db *sqlx.DB,
+	userRepo repository.UserStore,
+	roleRepo repository.RoleStore,
+	departmentRepo repository.DepartmentStore,
+	tenantRepo repository.TenantStore,
+	emailService EmailManager,
+	emailQueue EmailQueueManager,
+	asyncJobService AsyncJobManager,
+	permissionService PermissionManager,
+	auditService AuditManager,
+	quotaService QuotaManager,
+	cfg *config.UserImportConfig,
+) *UserImportService {
+	return &UserImportService{
+		db:                db,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		departmentRepo:    departmentRepo,
+		tenantRepo:        tenantRepo,
+		emailService:      emailService,
+		emailQueue:        emailQueue,
+		asyncJobService:   asyncJobService,
+		permissionService: permissionService,
+		auditService:      auditService,
+		quotaService:      quotaService,
+		config:            cfg,
+	}
+}
+
+// MaxFileSize returns the largest import file accepted, in bytes
+func (s *UserImportService) MaxFileSize() int64 {
+	return s.config.MaxFileSize
+}
+
+// ParseFile reads the users of a CSV or XLSX import file, chosen by the file
+// name's extension. The first line is the header; blank lines are skipped.
+func (s *UserImportService) ParseFile(fileName string, data []byte) ([]models.UserImportRow, error) {
+	var records [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		records, err = readCSVRecords(data)
+	case ".xlsx":
+		records, err = utils.ReadXLSXRows(data)
+	default:
+		return nil, fmt.Errorf("unsupported file type, upload a .csv or .xlsx file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+		if _, ok := columns[name]; !ok && name != "" {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{models.UserImportColumnEmail, models.UserImportColumnFirstName, models.UserImportColumnLastName} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", required)
+		}
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := []models.UserImportRow{}
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == s.config.MaxRows {
+			return nil, fmt.Errorf("the file has more than %d users", s.config.MaxRows)
+		}
+
+		row := models.UserImportRow{
+			Row:        i + 2,
+			Email:      cell(record, models.UserImportColumnEmail),
+			FirstName:  cell(record, models.UserImportColumnFirstName),
+			LastName:   cell(record, models.UserImportColumnLastName),
+			Phone:      cell(record, models.UserImportColumnPhone),
+			Department: cell(record, models.UserImportColumnDepartment),
+		}
+		for _, role := range strings.FieldsFunc(cell(record, models.UserImportColumnRoles), func(r rune) bool { return r == ';' || r == ',' }) {
+			if role = strings.TrimSpace(role); role != "" {
+				row.Roles = append(row.Roles, role)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file has no users")
+	}
+
+	return rows, nil
+}
+
+// Import validates rows and creates the users of the valid ones. A dry run
+// only validates. Unless it is a dry run, files with more than SyncRowLimit
+// rows are queued as an async job, which is returned instead of a report.
+func (s *UserImportService) Import(ctx context.Context, tenantID, userID uuid.UUID, fileName string, rows []models.UserImportRow, dryRun bool) (*models.UserImportReport, *models.AsyncJob, error) {
+	if dryRun {
+		report, _, err := s.validate(ctx, tenantID, userID, fileName, rows)
+		return report, nil, err
+	}
+
+	if len(rows) > s.config.SyncRowLimit {
+		job, err := s.asyncJobService.Submit(ctx, tenantID, userID, models.JobTypeUserImport, models.UserImportJobParams{
+			FileName: fileName,
+			Rows:     rows,
+		})
+		return nil, job, err
+	}
+
+	report, err := s.importRows(ctx, tenantID, userID, fileName, rows, nil)
+	return report, nil, err
+}
+
+// GetImport retrieves an import job the user may follow. Its result is the
+// import report once it has succeeded.
+func (s *UserImportService) GetImport(ctx context.Context, tenantID, userID, jobID uuid.UUID) (*models.AsyncJob, error) {
+	job, err := s.asyncJobService.GetJob(ctx, tenantID, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.JobType != models.JobTypeUserImport {
+		return nil, utils.NewNotFoundError("JOB_NOT_FOUND", "job not found")
+	}
+	return job, nil
+}
+
+// RunImportJob executes a queued user import (the user_import job type)
+func (s *UserImportService) RunImportJob(ctx context.Context, job *models.AsyncJob, progress *JobProgress) (interface{}, error) {
+	var params models.UserImportJobParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid import parameters: %w", err)
+	}
+
+	return s.importRows(ctx, job.TenantID, job.RequestedBy, params.FileName, params.Rows, progress)
+}
+
+// importRows validates rows and creates a user for each valid one. Rows are
+// imported one by one, so a row that fails does not stop the others.
+func (s *UserImportService) importRows(ctx context.Context, tenantID, userID uuid.UUID, fileName string, rows []models.UserImportRow, progress *JobProgress) (*models.UserImportReport, error) {
+	report, plans, err := s.validate(ctx, tenantID, userID, fileName, rows)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = false
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	creatorName := "Your administrator"
+	if creator, err := s.userRepo.FindByID(ctx, tenantID, userID); err == nil {
+		creatorName = creator.FullName()
+	}
+
+	for i, plan := range plans {
+		if progress != nil && i%userImportProgressEvery == 0 {
+			if err := progress.Update(ctx, i*100/len(plans), fmt.Sprintf("Imported %d of %d rows", i, len(plans))); err != nil {
+				return nil, err
+			}
+		}
+		if plan == nil {
+			continue
+		}
+
+		result := &report.Rows[i]
+		err := s.quotaService.CheckUserQuota(ctx, tenantID, 1)
+		var user *models.User
+		if err == nil {
+			user, err = s.createUser(ctx, tenantID, userID, plan, tenant.CompanyName, creatorName)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			result.Status = models.UserImportRowFailed
+			if errors.Is(err, utils.ErrConflict) {
+				result.Errors = map[string]string{models.UserImportColumnEmail: "Email already registered"}
+			} else if errors.Is(err, utils.ErrForbidden) {
+				result.Errors = map[string]string{"row": "The plan's user limit has been reached"}
+			} else {
+				log.Printf("⚠️  User import row %d: %v", plan.row.Row, err)
+				result.Errors = map[string]string{"row": "Failed to create the user"}
+			}
+			report.Failed++
+			continue
+		}
+
+		result.Status = models.UserImportRowImported
+		result.UserID = &user.ID
+		report.Imported++
+
+		s.auditService.LogEvent(ctx, tenantID, userID, models.ActionUserCreated, models.ResourceUsers, user.ID, models.AuditStatusSuccess, "", "", map[string]interface{}{
+			"email":       user.Email,
+			"import_file": fileName,
+		})
+	}
+
+	s.auditService.LogEvent(ctx, tenantID, userID, models.ActionUserImported, models.ResourceUsers, uuid.Nil, models.AuditStatusSuccess, "", "", map[string]interface{}{
+		"file_name":  fileName,
+		"total_rows": report.TotalRows,
+		"imported":   report.Imported,
+		"invalid":    report.Invalid,
+		"failed":     report.Failed,
+	})
+
+	return report, nil
+}
+
+// createUser creates the user of a validated row and queues their
+// set-password email, in one transaction
+func (s *UserImportService) createUser(ctx context.Context, tenantID, userID uuid.UUID, plan *userImportPlan, companyName, creatorName string) (*models.User, error) {
+	tx, err := database.WithTenantContext(ctx, s.db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	token := uuid.New()
+	expiresAt := time.Now().Add(s.config.SetupLinkTTL)
+
+	user := &models.User{
+		Email:               plan.row.Email,
+		FirstName:           plan.row.FirstName,
+		LastName:            plan.row.LastName,
+		DepartmentID:        plan.departmentID,
+		Status:              models.UserStatusActive,
+		Timezone:            "UTC",
+		Language:            "en",
+		Preferences:         []byte("{}"),
+		ResetToken:          &token,
+		ResetTokenExpiresAt: &expiresAt,
+		CreatedBy:           &userID,
+	}
+	if plan.row.Phone != "" {
+		user.Phone = &plan.row.Phone
+	}
+
+	if err := s.userRepo.CreateImported(ctx, tx.Tx, tenantID, user, plan.roleIDs, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.emailService.AccountSetupEmail(user.Email, user.Language, user.FirstName, companyName, creatorName, token, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render account setup email: %w", err)
+	}
+	if err := s.emailQueue.Enqueue(ctx, tx.Tx, tenantID, msg); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// validate checks every row and resolves its roles and department. The
+// returned plans are indexed like the rows, nil for invalid rows.
+func (s *UserImportService) validate(ctx context.Context, tenantID, userID uuid.UUID, fileName string, rows []models.UserImportRow) (*models.UserImportReport, []*userImportPlan, error) {
+	roles, err := s.roleRepo.List(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rolesByName := make(map[string]models.Role, len(roles))
+	rolesByDisplayName := make(map[string]models.Role, len(roles))
+	for _, role := range roles {
+		rolesByName[strings.ToLower(role.Name)] = role
+		rolesByDisplayName[strings.ToLower(role.DisplayName)] = role
+	}
+
+	departments, err := s.departmentRepo.List(ctx, tenantID, models.DepartmentFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	departmentsByName := make(map[string]models.Department, len(departments))
+	for _, dept := range departments {
+		departmentsByName[strings.ToLower(dept.Name)] = dept
+	}
+
+	canAssignRoles, err := s.permissionService.HasPermission(ctx, tenantID, userID, models.ResourceRoles, models.ActionAssign)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Email != "" {
+			emails = append(emails, row.Email)
+		}
+	}
+	existing, err := s.userRepo.FindExistingEmails(ctx, tenantID, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &models.UserImportReport{
+		FileName:  fileName,
+		DryRun:    true,
+		TotalRows: len(rows),
+		Rows:      make([]models.UserImportRowResult, len(rows)),
+	}
+	plans := make([]*userImportPlan, len(rows))
+	firstRowByEmail := map[string]int{}
+
+	for i, row := range rows {
+		errors := utils.ValidationErrors{}
+		plan := &userImportPlan{row: row}
+
+		email := strings.ToLower(row.Email)
+		if row.Email == "" {
+			errors.Add(models.UserImportColumnEmail, "Email is required")
+		} else if !utils.IsValidEmail(row.Email) {
+			errors.Add(models.UserImportColumnEmail, "Invalid email address")
+		} else if first, ok := firstRowByEmail[email]; ok {
+			errors.Add(models.UserImportColumnEmail, fmt.Sprintf("Duplicate of row %d", first))
+		} else {
+			firstRowByEmail[email] = row.Row
+			if existing[email] {
+				errors.Add(models.UserImportColumnEmail, "Email already registered")
+			}
+		}
+
+		utils.ValidateRequired(models.UserImportColumnFirstName, row.FirstName, "First name", &errors)
+		if row.FirstName != "" {
+			utils.ValidateName(models.UserImportColumnFirstName, row.FirstName, "First name", &errors)
+		}
+		utils.ValidateRequired(models.UserImportColumnLastName, row.LastName, "Last name", &errors)
+		if row.LastName != "" {
+			utils.ValidateName(models.UserImportColumnLastName, row.LastName, "Last name", &errors)
+		}
+		if !utils.IsValidPhone(row.Phone) {
+			errors.Add(models.UserImportColumnPhone, "Invalid phone number")
+		}
+
+		if len(row.Roles) > 0 {
+			unknown := []string{}
+			for _, name := range row.Roles {
+				role, ok := rolesByName[strings.ToLower(name)]
+				if !ok {
+					role, ok = rolesByDisplayName[strings.ToLower(name)]
+				}
+				if !ok {
+					unknown = append(unknown, name)
+					continue
+				}
+				if role.Name == models.RoleOwner {
+					errors.Add(models.UserImportColumnRoles, "The owner role cannot be assigned by import")
+					break
+				}
+				if !containsUUID(plan.roleIDs, role.ID) {
+					plan.roleIDs = append(plan.roleIDs, role.ID)
+				}
+			}
+			switch {
+			case len(unknown) > 0:
+				sort.Strings(unknown)
+				errors.Add(models.UserImportColumnRoles, fmt.Sprintf("Unknown roles: %s", strings.Join(unknown, ", ")))
+			case !canAssignRoles:
+				errors.Add(models.UserImportColumnRoles, "You are not allowed to assign roles")
+			}
+		}
+
+		if row.Department != "" {
+			dept, ok := departmentsByName[strings.ToLower(row.Department)]
+			switch {
+			case !ok:
+				errors.Add(models.UserImportColumnDepartment, fmt.Sprintf("Unknown department: %s", row.Department))
+			case dept.Status != models.DepartmentStatusActive:
+				errors.Add(models.UserImportColumnDepartment, fmt.Sprintf("Department %s is not active", dept.Name))
+			default:
+				plan.departmentID = &dept.ID
+			}
+		}
+
+		result := models.UserImportRowResult{Row: row.Row, Email: row.Email}
+		if errors.HasErrors() {
+			result.Status = models.UserImportRowInvalid
+			result.Errors = errors.ToMap()
+			report.Invalid++
+		} else {
+			result.Status = models.UserImportRowValid
+			plans[i] = plan
+			report.Valid++
+		}
+		report.Rows[i] = result
+	}
+
+	return report, plans, nil
+}
+
+// readCSVRecords reads a CSV file, comma- or semicolon-separated (as some
+// spreadsheet applications export it), with or without a UTF-8 BOM
+func readCSVRecords(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("not a valid CSV file: %v", err)
+	}
+	return records, nil
+}
+
+// containsUUID reports whether ids contains id
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,514 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/utils"
+)
+
+// Changes to watched records, from the events published on the event bus
+const (
+	watchChangeCreated   = "created"
+	watchChangeUpdated   = "updated"
+	watchChangeAssigned  = "assigned"
+	watchChangeCommented = "commented"
+	watchChangeDeleted   = "deleted"
+)
+
+// EntityDescriber describes an entity for navigation and watch lists. It
+// returns an error if the entity no longer exists.
+type EntityDescriber func(ctx context.Context, tenantID, entityID uuid.UUID) (*models.EntitySummary, error)
+
+// watchType is a type of record users can watch
+type watchType struct {
+	resource      string // Permission resource whose view permission allows watching
+	assigneeField string // Field of the record holding its owner or head, if any
+	describe      EntityDescriber
+}
+
+// watchLink is a field of an event's object holding the ID of a record the
+// event concerns
+type watchLink struct {
+	field      string
+	entityType string
+}
+
+// watchEvent is an event type that concerns watched records
+type watchEvent struct {
+	change      string
+	entityTypes []string    // Types the event's resource may be of
+	links       []watchLink // For comments, the records commented on
+}
+
+// crmEntityTypes are the types of CRM records, which share assignment events
+var crmEntityTypes = []string{models.EntityCustomer, models.EntityContact, models.EntityOpportunity}
+
+// watchEvents maps the event types that concern watched records to the
+// change they make
+var watchEvents = map[string]watchEvent{
+	models.ActionDepartmentCreated:     {change: watchChangeCreated, entityTypes: []string{models.NavigationEntityDepartment}},
+	models.ActionDepartmentUpdated:     {change: watchChangeUpdated, entityTypes: []string{models.NavigationEntityDepartment}},
+	models.ActionDepartmentDeleted:     {change: watchChangeDeleted, entityTypes: []string{models.NavigationEntityDepartment}},
+	models.ActionCRMCustomerCreated:    {change: watchChangeCreated, entityTypes: []string{models.EntityCustomer}},
+	models.ActionCRMCustomerUpdated:    {change: watchChangeUpdated, entityTypes: []string{models.EntityCustomer}},
+	models.ActionCRMCustomerDeleted:    {change: watchChangeDeleted, entityTypes: []string{models.EntityCustomer}},
+	models.ActionCRMContactCreated:     {change: watchChangeCreated, entityTypes: []string{models.EntityContact}},
+	models.ActionCRMContactUpdated:     {change: watchChangeUpdated, entityTypes: []string{models.EntityContact}},
+	models.ActionCRMContactDeleted:     {change: watchChangeDeleted, entityTypes: []string{models.EntityContact}},
+	models.ActionCRMOpportunityCreated: {change: watchChangeCreated, entityTypes: []string{models.EntityOpportunity}},
+	models.ActionCRMOpportunityUpdated: {change: watchChangeUpdated, entityTypes: []string{models.EntityOpportunity}},
+	models.ActionCRMOpportunityStaged:  {change: watchChangeUpdated, entityTypes: []string{models.EntityOpportunity}},
+	models.ActionCRMOpportunityDeleted: {change: watchChangeDeleted, entityTypes: []string{models.EntityOpportunity}},
+	models.ActionCRMOwnerAssigned:      {change: watchChangeAssigned, entityTypes: crmEntityTypes},
+	models.ActionCRMActivityLogged: {change: watchChangeCommented, links: []watchLink{
+		{field: "customer_id", entityType: models.EntityCustomer},
+		{field: "contact_id", entityType: models.EntityContact},
+		{field: "opportunity_id", entityType: models.EntityOpportunity},
+	}},
+
+	// Handed over by SalesService, which keys them by its audit actions
+	salesAuditCreated:       {change: watchChangeCreated, entityTypes: []string{models.NavigationEntitySalesDocument}},
+	salesAuditConverted:     {change: watchChangeCreated, entityTypes: []string{models.NavigationEntitySalesDocument}},
+	salesAuditUpdated:       {change: watchChangeUpdated, entityTypes: []string{models.NavigationEntitySalesDocument}},
+	salesAuditStatusChanged: {change: watchChangeUpdated, entityTypes: []string{models.NavigationEntitySalesDocument}},
+}
+
+// WatchService lets users watch records and notifies watchers when someone
+// else changes them. It subscribes to the event bus: users watch the records
+// they create or are assigned to, and the watchers of a record updated,
+// commented on or deleted are notified, if they may still view it.
+type WatchService struct {
+	watchRepo         repository.WatchStore
+	userRepo          repository.UserStore
+	permissionService PermissionManager
+	notifier          NotificationManager
+	types             map[string]watchType
+}
+
+// NewWatchService creates a new watch service
+func NewWatchService(
+	watchRepo repository.WatchStore,
+	userRepo repository.UserStore,
+	permissionService PermissionManager,
+	notifier NotificationManager,
+) *WatchService {
+	return &WatchService{
+		watchRepo:         watchRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
+		notifier:          notifier,
+		types:             make(map[string]watchType),
+	}
+}
+
+// RegisterType registers an entity type that can be watched. assigneeField
+// is the JSON field of the record holding the user it is assigned to, if
+// any; they watch it automatically.
+func (s *WatchService) RegisterType(entityType, resource, assigneeField string, describe EntityDescriber) {
+	s.types[entityType] = watchType{resource: resource, assigneeField: assigneeField, describe: describe}
+}
+
+// EntityTypes lists the entity types that can be watched
+func (s *WatchService) EntityTypes() []string {
+	types := make([]string, 0, len(s.types))
+	for entityType := range s.types {
+		types = append(types, entityType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Watch starts the user watching a record they can view. Returns false if
+// they already watched it.
+func (s *WatchService) Watch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatch, bool, error) {
+	summary, err := s.viewable(ctx, tenantID, userID, entityType, entityID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	watch := &models.RecordWatch{
+		TenantID:   tenantID,
+		UserID:     userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Reason:     models.WatchReasonManual,
+		Entity:     summary,
+	}
+	created, err := s.watchRepo.Watch(ctx, watch)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return watch, created, nil
+}
+
+// Unwatch stops the user watching a record, whether they chose to watch it
+// or watched it automatically
+func (s *WatchService) Unwatch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	return s.watchRepo.Unwatch(ctx, tenantID, userID, entityType, entityID)
+}
+
+// ListWatches lists the records the user watches, most recent first. Records
+// that are gone or the user may no longer view are listed without their
+// summary, so they can still be unwatched.
+func (s *WatchService) ListWatches(ctx context.Context, tenantID, userID uuid.UUID, entityType string, limit, offset int) ([]models.RecordWatch, int, error) {
+	watches, totalCount, err := s.watchRepo.ListByUser(ctx, tenantID, userID, entityType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range watches {
+		watches[i].Entity, _ = s.viewable(ctx, tenantID, userID, watches[i].EntityType, watches[i].EntityID)
+	}
+
+	return watches, totalCount, nil
+}
+
+// GetWatchers lists the users watching a record the user can view
+func (s *WatchService) GetWatchers(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.RecordWatchers, error) {
+	if _, err := s.viewable(ctx, tenantID, userID, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	watchers, err := s.watchRepo.ListWatchers(ctx, tenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RecordWatchers{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Watchers:   watchers,
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID == userID {
+			result.Watching = true
+		}
+	}
+
+	return result, nil
+}
+
+// HandleEvent reacts to a change of a watchable record: the actor watches the
+// records they create, assignees the records assigned to them, and the
+// watchers of the record are notified of updates, comments and deletions
+func (s *WatchService) HandleEvent(ctx context.Context, event *models.Event) error {
+	e, ok := watchEvents[event.Type]
+	if !ok {
+		return nil
+	}
+
+	var actorID uuid.UUID
+	if event.ActorID != nil {
+		actorID = *event.ActorID
+	}
+	object := eventFields(event.Object)
+
+	if e.change == watchChangeCommented {
+		return s.notifyComment(ctx, event, actorID, e.links, object)
+	}
+	if event.ResourceID == nil {
+		return nil
+	}
+	entityID := *event.ResourceID
+
+	entityType, summary := s.resolve(ctx, event.TenantID, e.entityTypes, entityID)
+	if entityType == "" {
+		return nil
+	}
+	t := s.types[entityType]
+
+	// Users just assigned the record watch it, and are not told of the
+	// change that assigned it to them
+	exclude := []uuid.UUID{actorID}
+	if assigneeID := uuidField(object, t.assigneeField); assigneeID != nil {
+		previousID := uuidField(eventFields(event.Previous), t.assigneeField)
+		assigned := e.change == watchChangeCreated || e.change == watchChangeAssigned ||
+			(e.change == watchChangeUpdated && event.Previous != nil && (previousID == nil || *previousID != *assigneeID))
+		if assigned && *assigneeID != actorID {
+			s.autoWatch(ctx, event.TenantID, *assigneeID, entityType, entityID, models.WatchReasonAssigned)
+			exclude = append(exclude, *assigneeID)
+		}
+	}
+
+	title := recordTitle(summary, object, entityType)
+	var notification *models.NotificationRequest
+	switch e.change {
+	case watchChangeCreated:
+		if actorID != uuid.Nil {
+			s.autoWatch(ctx, event.TenantID, actorID, entityType, entityID, models.WatchReasonCreated)
+		}
+		return nil
+	case watchChangeUpdated:
+		notification = &models.NotificationRequest{
+			Type:  models.NotificationTypeRecordUpdated,
+			Title: fmt.Sprintf("%s was updated", title),
+			Body:  s.byActor(ctx, event.TenantID, actorID, "Changed by %s"),
+		}
+	case watchChangeAssigned:
+		notification = &models.NotificationRequest{
+			Type:  models.NotificationTypeRecordUpdated,
+			Title: fmt.Sprintf("%s was reassigned", title),
+			Body:  s.byActor(ctx, event.TenantID, actorID, "Reassigned by %s"),
+		}
+	case watchChangeDeleted:
+		// A staged deletion leaves the record in place until its undo window
+		// ends: watchers hear of it once it is executed (HandleDeletion), so
+		// an undone deletion notifies no one
+		if summary != nil {
+			return nil
+		}
+		notification = &models.NotificationRequest{
+			Type:  models.NotificationTypeRecordDeleted,
+			Title: fmt.Sprintf("%s was deleted", title),
+			Body:  s.byActor(ctx, event.TenantID, actorID, "Deleted by %s"),
+		}
+	default:
+		return nil
+	}
+
+	if _, err := s.notifyWatchers(ctx, event, entityType, entityID, summary, exclude, notification); err != nil {
+		return err
+	}
+
+	// Records deleted right away take their watches with them
+	if e.change == watchChangeDeleted {
+		return s.watchRepo.DeleteForEntity(ctx, event.TenantID, entityType, entityID)
+	}
+	return nil
+}
+
+// HandleDeletion reacts to the execution of a staged deletion: the watchers
+// of the record are notified, except the user who requested it, and its
+// watches are dropped
+func (s *WatchService) HandleDeletion(ctx context.Context, deletion *models.PendingDeletion) error {
+	if _, ok := s.types[deletion.EntityType]; !ok {
+		return nil
+	}
+
+	entityID := deletion.EntityID
+	requestedBy := deletion.RequestedBy
+	event := &models.Event{
+		Type:       deletionAuditExecuted,
+		TenantID:   deletion.TenantID,
+		ResourceID: &entityID,
+		ActorID:    &requestedBy,
+	}
+	title := recordTitle(nil, map[string]interface{}{"name": deletion.Label}, deletion.EntityType)
+	notification := &models.NotificationRequest{
+		Type:  models.NotificationTypeRecordDeleted,
+		Title: fmt.Sprintf("%s was deleted", title),
+		Body:  s.byActor(ctx, deletion.TenantID, requestedBy, "Deleted by %s"),
+	}
+	if _, err := s.notifyWatchers(ctx, event, deletion.EntityType, entityID, nil, []uuid.UUID{requestedBy}, notification); err != nil {
+		return err
+	}
+
+	return s.watchRepo.DeleteForEntity(ctx, deletion.TenantID, deletion.EntityType, entityID)
+}
+
+// notifyComment notifies the watchers of the records an activity was logged
+// on, once each
+func (s *WatchService) notifyComment(ctx context.Context, event *models.Event, actorID uuid.UUID, links []watchLink, object map[string]interface{}) error {
+	notified := []uuid.UUID{actorID}
+	subject, _ := object["subject"].(string)
+
+	for _, link := range links {
+		entityID := uuidField(object, link.field)
+		if entityID == nil {
+			continue
+		}
+		if _, ok := s.types[link.entityType]; !ok {
+			continue
+		}
+		summary, err := s.types[link.entityType].describe(ctx, event.TenantID, *entityID)
+		if err != nil {
+			continue
+		}
+
+		notification := &models.NotificationRequest{
+			Type:  models.NotificationTypeRecordCommented,
+			Title: fmt.Sprintf("New activity on %s", summary.Title),
+			Body:  subject,
+		}
+		if actor := s.byActor(ctx, event.TenantID, actorID, "%s"); actor != "" {
+			notification.Title = fmt.Sprintf("%s added activity on %s", actor, summary.Title)
+		}
+
+		recipients, err := s.notifyWatchers(ctx, event, link.entityType, *entityID, summary, notified, notification)
+		if err != nil {
+			return err
+		}
+		notified = append(notified, recipients...)
+	}
+
+	return nil
+}
+
+// notifyWatchers notifies the watchers of a record who may view it, except
+// the users in exclude. Returns the users notified.
+func (s *WatchService) notifyWatchers(ctx context.Context, event *models.Event, entityType string, entityID uuid.UUID, summary *models.EntitySummary, exclude []uuid.UUID, req *models.NotificationRequest) ([]uuid.UUID, error) {
+	watchers, err := s.watchRepo.ListWatchers(ctx, event.TenantID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := s.types[entityType].resource
+	recipients := []uuid.UUID{}
+	for _, watcher := range watchers {
+		if containsUUID(exclude, watcher.UserID) {
+			continue
+		}
+		allowed, err := s.permissionService.CanAccessObject(ctx, event.TenantID, watcher.UserID, resource, entityID, models.ActionView)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			recipients = append(recipients, watcher.UserID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, nil
+	}
+
+	if summary != nil {
+		req.Link = summary.Path
+	}
+	req.Data = map[string]interface{}{
+		"entity_type": entityType,
+		"entity_id":   entityID,
+		"event_type":  event.Type,
+	}
+	if err := s.notifier.Notify(ctx, event.TenantID, recipients, req); err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+// resolve finds which of the candidate types a record is of, with its
+// summary. A record of a single candidate type may be gone (deleted): the
+// type is returned without a summary.
+func (s *WatchService) resolve(ctx context.Context, tenantID uuid.UUID, entityTypes []string, entityID uuid.UUID) (string, *models.EntitySummary) {
+	for _, entityType := range entityTypes {
+		t, ok := s.types[entityType]
+		if !ok {
+			continue
+		}
+		if summary, err := t.describe(ctx, tenantID, entityID); err == nil {
+			return entityType, summary
+		}
+		if len(entityTypes) == 1 {
+			return entityType, nil
+		}
+	}
+	return "", nil
+}
+
+// viewable checks that a record of a watchable type exists and the user may
+// view it, and describes it
+func (s *WatchService) viewable(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID) (*models.EntitySummary, error) {
+	t, ok := s.types[entityType]
+	if !ok {
+		return nil, utils.NewValidationError("INVALID_ENTITY_TYPE", fmt.Sprintf("unknown entity type: %s", entityType))
+	}
+
+	allowed, err := s.permissionService.CanAccessObject(ctx, tenantID, userID, t.resource, entityID, models.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, utils.NewForbiddenError("INSUFFICIENT_PERMISSIONS", "insufficient permissions to view this record")
+	}
+
+	summary, err := t.describe(ctx, tenantID, entityID)
+	if err != nil {
+		return nil, utils.NewNotFoundError("ENTITY_NOT_FOUND", "record not found")
+	}
+
+	return summary, nil
+}
+
+// autoWatch starts a user watching a record they created or were assigned.
+// Failures are only logged: the change is already committed.
+func (s *WatchService) autoWatch(ctx context.Context, tenantID, userID uuid.UUID, entityType string, entityID uuid.UUID, reason string) {
+	watch := &models.RecordWatch{
+		TenantID:   tenantID,
+		UserID:     userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Reason:     reason,
+	}
+	if _, err := s.watchRepo.Watch(ctx, watch); err != nil {
+		log.Printf("⚠️  Failed to watch %s %s for user %s in tenant %s: %v", entityType, entityID, userID, tenantID, err)
+	}
+}
+
+// byActor formats the actor's name into format, or returns "" when the
+// actor is unknown
+func (s *WatchService) byActor(ctx context.Context, tenantID, actorID uuid.UUID, format string) string {
+	if actorID == uuid.Nil {
+		return ""
+	}
+	actor, err := s.userRepo.FindByID(ctx, tenantID, actorID)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(format, actor.FullName())
+}
+
+// recordTitle names a record in notifications: its summary's title or, once
+// it is gone, the name in the event's object
+func recordTitle(summary *models.EntitySummary, object map[string]interface{}, entityType string) string {
+	if summary != nil {
+		return summary.Title
+	}
+	for _, field := range []string{"name", "document_number"} {
+		if name, ok := object[field].(string); ok && name != "" {
+			return name
+		}
+	}
+	first, _ := object["first_name"].(string)
+	last, _ := object["last_name"].(string)
+	if name := strings.TrimSpace(first + " " + last); name != "" {
+		return name
+	}
+	return "A " + strings.ReplaceAll(entityType, "_", " ")
+}
+
+// eventFields decodes an event's JSON object, nil if there is none
+func eventFields(object json.RawMessage) map[string]interface{} {
+	if len(object) == 0 {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(object, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// uuidField reads a UUID field of an event's object, nil if it is missing
+// or null
+func uuidField(fields map[string]interface{}, field string) *uuid.UUID {
+	if field == "" {
+		return nil
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"myerp-v2/internal/utils"
+)
+
+// Authenticator data flags
+const (
+	webauthnFlagUserPresent    = 0x01
+	webauthnFlagUserVerified   = 0x04
+	webauthnFlagBackupEligible = 0x08
+	webauthnFlagBackedUp       = 0x10
+	webauthnFlagAttestedData   = 0x40
+)
+
+// Client data types of the two ceremonies
+const (
+	webauthnTypeCreate = "webauthn.create"
+	webauthnTypeGet    = "webauthn.get"
+)
+
+// COSE algorithms accepted for credential public keys, in order of
+// preference
+const (
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+)
+
+var webauthnAlgorithms = []int64{coseAlgES256, coseAlgEdDSA, coseAlgRS256}
+
+// webauthnMaxCredentialIDLength is the longest credential ID accepted
+const webauthnMaxCredentialIDLength = 1023
+
+// webauthnClientData is the part of the client data JSON that is verified
+type webauthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// webauthnAuthData is parsed authenticator data
+type webauthnAuthData struct {
+	raw       []byte
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	// Attested credential data, registrations only
+	aaguid       uuid.UUID
+	credentialID []byte
+	publicKey    []byte // COSE_Key, as stored
+}
+
+// webauthnCredential is a credential verified at registration
+type webauthnCredential struct {
+	ID             []byte
+	PublicKey      []byte
+	AAGUID         uuid.UUID
+	SignCount      uint32
+	BackupEligible bool
+	BackedUp       bool
+}
+
+// decodeWebAuthnBytes decodes a base64url value of a credential, with or
+// without padding
+func decodeWebAuthnBytes(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// encodeWebAuthnBytes encodes bytes as clients expect them in options
+func encodeWebAuthnBytes(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseWebAuthnClientData parses client data and checks it answers the
+// given challenge of a ceremony of the given type, from an allowed origin
+func parseWebAuthnClientData(data []byte, ceremonyType, challenge string, origins []string) error {
+	var clientData webauthnClientData
+	if err := json.Unmarshal(data, &clientData); err != nil {
+		return utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed client data")
+	}
+	if clientData.Type != ceremonyType {
+		return utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: wrong ceremony type")
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimRight(clientData.Challenge, "=")), []byte(challenge)) != 1 {
+		return utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: challenge mismatch")
+	}
+	if !webauthnOriginAllowed(clientData.Origin, origins) {
+		return utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: origin not allowed")
+	}
+	return nil
+}
+
+// webauthnClientDataChallenge reads the challenge a client data answers, to
+// find the ceremony it belongs to
+func webauthnClientDataChallenge(data []byte) (string, error) {
+	var clientData webauthnClientData
+	if err := json.Unmarshal(data, &clientData); err != nil || clientData.Challenge == "" {
+		return "", utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed client data")
+	}
+	return strings.TrimRight(clientData.Challenge, "="), nil
+}
+
+// webauthnOriginAllowed reports whether origin is one of the allowed origins.
+// An allowed origin "https://*.example.com" matches every subdomain of
+// example.com served over https.
+func webauthnOriginAllowed(origin string, allowed []string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	for _, pattern := range allowed {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if origin == pattern {
+			return true
+		}
+		p, err := url.Parse(pattern)
+		if err != nil || p.Scheme != u.Scheme || !strings.HasPrefix(p.Host, "*.") {
+			continue
+		}
+		if strings.HasSuffix(u.Host, p.Host[1:]) && len(u.Host) > len(p.Host)-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWebAuthnAuthData parses authenticator data and checks it is scoped to
+// the relying party and the user was present
+func parseWebAuthnAuthData(data []byte, rpID string) (*webauthnAuthData, error) {
+	if len(data) < 37 {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: authenticator data too short")
+	}
+
+	authData := &webauthnAuthData{
+		raw:       data,
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if subtle.ConstantTimeCompare(authData.rpIDHash, rpIDHash[:]) != 1 {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: relying party mismatch")
+	}
+	if authData.flags&webauthnFlagUserPresent == 0 {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: user not present")
+	}
+
+	if authData.flags&webauthnFlagAttestedData != 0 {
+		rest := data[37:]
+		if len(rest) < 18 {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: attested credential data too short")
+		}
+		copy(authData.aaguid[:], rest[:16])
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > webauthnMaxCredentialIDLength || len(rest) < idLength {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad credential id")
+		}
+		authData.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		// The public key is followed by the extensions, if any
+		_, extensions, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+		}
+		authData.publicKey = rest[:len(rest)-len(extensions)]
+	}
+
+	return authData, nil
+}
+
+// verifyWebAuthnRegistration verifies the response of a registration
+// ceremony and returns the new credential. Attestation statements are not
+// verified: registrations ask for no attestation, so the authenticator's
+// make is not vouched for, only that it holds the key.
+func verifyWebAuthnRegistration(clientDataJSON, attestationObject []byte, challenge, rpID string, origins []string) (*webauthnCredential, error) {
+	if err := parseWebAuthnClientData(clientDataJSON, webauthnTypeCreate, challenge, origins); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed attestation object")
+	}
+	object, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed attestation object")
+	}
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: malformed attestation object")
+	}
+
+	authData, err := parseWebAuthnAuthData(rawAuthData, rpID)
+	if err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: no attested credential data")
+	}
+	if _, err := parseCOSEKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &webauthnCredential{
+		ID:             authData.credentialID,
+		PublicKey:      authData.publicKey,
+		AAGUID:         authData.aaguid,
+		SignCount:      authData.signCount,
+		BackupEligible: authData.flags&webauthnFlagBackupEligible != 0,
+		BackedUp:       authData.flags&webauthnFlagBackedUp != 0,
+	}, nil
+}
+
+// verifyWebAuthnAssertion verifies the response of an authentication
+// ceremony against a credential's public key. It returns the authenticator
+// data, whose flags and signature counter the caller checks.
+func verifyWebAuthnAssertion(clientDataJSON, rawAuthData, signature, publicKey []byte, challenge, rpID string, origins []string) (*webauthnAuthData, error) {
+	if err := parseWebAuthnClientData(clientDataJSON, webauthnTypeGet, challenge, origins); err != nil {
+		return nil, err
+	}
+
+	authData, err := parseWebAuthnAuthData(rawAuthData, rpID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad signature")
+	}
+
+	return authData, nil
+}
+
+// webauthnSignCountValid reports whether an assertion's signature counter
+// may follow the stored one: it must increase, so answers from a cloned
+// authenticator are refused, unless the authenticator keeps no counter and
+// both are 0
+func webauthnSignCountValid(stored, received int64) bool {
+	return (received == 0 && stored == 0) || received > stored
+}
+
+// coseKey is a credential public key
+type coseKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// parseCOSEKey parses a COSE_Key of one of the accepted algorithms
+func parseCOSEKey(data []byte) (*coseKey, error) {
+	decoded, rest, err := decodeCBOR(data)
+	if err != nil || len(rest) != 0 {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+	}
+	params, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+	}
+
+	integer := func(label int64) int64 {
+		v, _ := params[label].(int64)
+		return v
+	}
+	byteString := func(label int64) []byte {
+		v, _ := params[label].([]byte)
+		return v
+	}
+
+	kty, alg := integer(1), integer(3)
+	switch {
+	case alg == coseAlgES256 && kty == 2 && integer(-1) == 1: // EC2, P-256
+		x, y := byteString(-2), byteString(-3)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+		}
+		return &coseKey{alg: alg, key: key}, nil
+
+	case alg == coseAlgEdDSA && kty == 1 && integer(-1) == 6: // OKP, Ed25519
+		x := byteString(-2)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+		}
+		return &coseKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+
+	case alg == coseAlgRS256 && kty == 3: // RSA
+		n, e := byteString(-1), byteString(-2)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: bad public key")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		return &coseKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}}, nil
+	}
+
+	return nil, utils.NewBadRequestError("INVALID_WEBAUTHN_CREDENTIAL", "invalid credential: unsupported public key algorithm")
+}
+
+// verify checks a signature over data
+func (k *coseKey) verify(data, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+// webauthnUserHandle is the user handle credentials are registered with: the
+// user's ID, which reveals nothing about them
+func webauthnUserHandle(userID uuid.UUID) []byte {
+	return userID[:]
+}
+
+// webauthnUserFromHandle reads the user ID of a user handle
+func webauthnUserFromHandle(handle []byte) (uuid.UUID, bool) {
+	if len(handle) != 16 {
+		return uuid.Nil, false
+	}
+	var id uuid.UUID
+	copy(id[:], handle)
+	return id, !bytes.Equal(handle, uuid.Nil[:])
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// cborMaxDepth bounds the nesting of decoded CBOR values
+const cborMaxDepth = 16
+
+// cborDecoder decodes the CBOR subset authenticators produce (RFC 8949 with
+// the CTAP2 restrictions): definite-length integers, byte and text strings,
+// arrays, maps, booleans and null. Integers decode to int64, byte strings to
+// []byte, text to string, arrays to []interface{} and maps to
+// map[interface{}]interface{} keyed by int64 or string.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// decodeCBOR decodes the first CBOR value of data and returns it with the
+// bytes that follow it, e.g. the extensions after a credential public key
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	d := &cborDecoder{data: data}
+	value, err := d.value(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, data[d.pos:], nil
+}
+
+// head reads an item's major type and argument
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, fmt.Errorf("invalid cbor: unexpected end of data")
+	}
+	initial := d.data[d.pos]
+	d.pos++
+
+	major, info := initial>>5, initial&0x1f
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("invalid cbor: indefinite lengths are not supported")
+	}
+
+	if len(d.data)-d.pos < size {
+		return 0, 0, fmt.Errorf("invalid cbor: unexpected end of data")
+	}
+	var arg uint64
+	switch size {
+	case 1:
+		arg = uint64(d.data[d.pos])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(d.data[d.pos:]))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(d.data[d.pos:]))
+	case 8:
+		arg = binary.BigEndian.Uint64(d.data[d.pos:])
+	}
+	d.pos += size
+	return major, arg, nil
+}
+
+// bytes reads the n bytes of a string
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("invalid cbor: unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// value decodes the next value
+func (d *cborDecoder) value(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, fmt.Errorf("invalid cbor: nested too deeply")
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0: // Unsigned integer
+		if arg > 1<<63-1 {
+			return nil, fmt.Errorf("invalid cbor: integer out of range")
+		}
+		return int64(arg), nil
+
+	case 1: // Negative integer
+		if arg > 1<<63-1 {
+			return nil, fmt.Errorf("invalid cbor: integer out of range")
+		}
+		return -1 - int64(arg), nil
+
+	case 2: // Byte string
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+
+	case 3: // Text string
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case 4: // Array
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("invalid cbor: unexpected end of data")
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case 5: // Map
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("invalid cbor: unexpected end of data")
+		}
+		entries := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("invalid cbor: unsupported map key")
+			}
+			if _, ok := entries[key]; ok {
+				return nil, fmt.Errorf("invalid cbor: duplicate map key")
+			}
+			value, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			entries[key] = value
+		}
+		return entries, nil
+
+	case 6: // Tag: the tagged value is used as is
+		return d.value(depth + 1)
+
+	case 7: // Simple values
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid cbor: unsupported simple value")
+	}
+
+	return nil, fmt.Errorf("invalid cbor: unsupported major type %d", major)
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID      = "app.example.com"
+	testOrigin    = "https://app.example.com"
+	testChallenge = "dGVzdC1jaGFsbGVuZ2U"
+)
+
+var testOrigins = []string{testOrigin}
+
+// testAuthenticator is an ES256 authenticator holding one credential
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testAuthenticator{key: key, credentialID: []byte("credential-0001")}
+}
+
+// coseKey returns the credential public key as a COSE_Key, with the CBOR
+// written out byte by byte:
+// {1: 2 (EC2), 3: -7 (ES256), -1: 1 (P-256), -2: x, -3: y}
+func (a *testAuthenticator) coseKey() []byte {
+	key := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}
+	key = append(key, a.key.PublicKey.X.FillBytes(make([]byte, 32))...)
+	key = append(key, 0x22, 0x58, 0x20)
+	return append(key, a.key.PublicKey.Y.FillBytes(make([]byte, 32))...)
+}
+
+// authData writes authenticator data for rpID with the given flags and
+// counter, with the attested credential data when attested is set
+func (a *testAuthenticator) authData(rpID string, flags byte, signCount uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	if attested {
+		flags |= webauthnFlagAttestedData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+// testAttestationObject wraps authenticator data in a "none" attestation:
+// {"fmt": "none", "attStmt": {}, "authData": authData}
+func testAttestationObject(authData []byte) []byte {
+	object, _ := hex.DecodeString("a3" + "63666d74" + "646e6f6e65" + "6761747453746d74" + "a0" + "68617574684461746159")
+	object = binary.BigEndian.AppendUint16(object, uint16(len(authData)))
+	return append(object, authData...)
+}
+
+// sign signs authenticator data and the hash of the client data
+func (a *testAuthenticator) sign(t *testing.T, authData, clientDataJSON []byte) []byte {
+	t.Helper()
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	return signature
+}
+
+func testClientData(ceremonyType, challenge, origin string) []byte {
+	return []byte(`{"type":"` + ceremonyType + `","challenge":"` + challenge + `","origin":"` + origin + `","crossOrigin":false}`)
+}
+
+func TestVerifyWebAuthnRegistration(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	clientData := testClientData(webauthnTypeCreate, testChallenge, testOrigin)
+	valid := testAttestationObject(authenticator.authData(testRPID, webauthnFlagUserPresent|webauthnFlagUserVerified|webauthnFlagBackupEligible, 0, true))
+
+	// Attested credential data claiming a 65535 byte credential ID
+	oversizedID := authenticator.authData(testRPID, webauthnFlagUserPresent|webauthnFlagAttestedData, 0, false)
+	oversizedID = append(append(oversizedID, make([]byte, 16)...), 0xff, 0xff, 0x01)
+
+	tests := []struct {
+		name              string
+		clientData        []byte
+		attestationObject []byte
+		wantErr           string
+	}{
+		{
+			name:              "Valid registration",
+			clientData:        clientData,
+			attestationObject: valid,
+		},
+		{
+			name:              "Wrong ceremony type",
+			clientData:        testClientData(webauthnTypeGet, testChallenge, testOrigin),
+			attestationObject: valid,
+			wantErr:           "wrong ceremony type",
+		},
+		{
+			name:              "Wrong challenge",
+			clientData:        testClientData(webauthnTypeCreate, "b3RoZXI", testOrigin),
+			attestationObject: valid,
+			wantErr:           "challenge mismatch",
+		},
+		{
+			name:              "Origin not allowed",
+			clientData:        testClientData(webauthnTypeCreate, testChallenge, "https://evil.example.net"),
+			attestationObject: valid,
+			wantErr:           "origin not allowed",
+		},
+		{
+			name:              "Wrong RP ID hash",
+			clientData:        clientData,
+			attestationObject: testAttestationObject(authenticator.authData("evil.example.net", webauthnFlagUserPresent, 0, true)),
+			wantErr:           "relying party mismatch",
+		},
+		{
+			name:              "User not present",
+			clientData:        clientData,
+			attestationObject: testAttestationObject(authenticator.authData(testRPID, webauthnFlagUserVerified, 0, true)),
+			wantErr:           "user not present",
+		},
+		{
+			name:              "No attested credential data",
+			clientData:        clientData,
+			attestationObject: testAttestationObject(authenticator.authData(testRPID, webauthnFlagUserPresent, 0, false)),
+			wantErr:           "no attested credential data",
+		},
+		{
+			name:              "Truncated attestation object",
+			clientData:        clientData,
+			attestationObject: valid[:len(valid)-10],
+			wantErr:           "malformed attestation object",
+		},
+		{
+			// Cut 40 bytes into the public key, after the header, attested
+			// credential data header and credential ID
+			name:              "Truncated public key",
+			clientData:        clientData,
+			attestationObject: testAttestationObject(authenticator.authData(testRPID, webauthnFlagUserPresent, 0, true)[:37+18+15+40]),
+			wantErr:           "bad public key",
+		},
+		{
+			name:              "Oversized credential ID length",
+			clientData:        clientData,
+			attestationObject: testAttestationObject(oversizedID),
+			wantErr:           "bad credential id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential, err := verifyWebAuthnRegistration(tt.clientData, tt.attestationObject, testChallenge, testRPID, testOrigins)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, authenticator.credentialID, credential.ID)
+			assert.Equal(t, authenticator.coseKey(), credential.PublicKey)
+			assert.Equal(t, uint32(0), credential.SignCount)
+			assert.True(t, credential.BackupEligible)
+			assert.False(t, credential.BackedUp)
+		})
+	}
+}
+
+func TestVerifyWebAuthnAssertion(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	otherAuthenticator := newTestAuthenticator(t)
+	clientData := testClientData(webauthnTypeGet, testChallenge, testOrigin)
+	authData := authenticator.authData(testRPID, webauthnFlagUserPresent|webauthnFlagUserVerified, 8, false)
+	signature := authenticator.sign(t, authData, clientData)
+
+	tamperedSignature := append([]byte{}, signature...)
+	tamperedSignature[len(tamperedSignature)-1] ^= 0xff
+
+	wrongRPAuthData := authenticator.authData("evil.example.net", webauthnFlagUserPresent, 8, false)
+
+	tests := []struct {
+		name       string
+		clientData []byte
+		authData   []byte
+		signature  []byte
+		publicKey  []byte
+		wantErr    string
+	}{
+		{
+			name:       "Valid assertion",
+			clientData: clientData,
+			authData:   authData,
+			signature:  signature,
+		},
+		{
+			name:       "Bad signature",
+			clientData: clientData,
+			authData:   authData,
+			signature:  tamperedSignature,
+			wantErr:    "bad signature",
+		},
+		{
+			name:       "Signed by another key",
+			clientData: clientData,
+			authData:   authData,
+			signature:  otherAuthenticator.sign(t, authData, clientData),
+			wantErr:    "bad signature",
+		},
+		{
+			name:       "Signature over other client data",
+			clientData: []byte(strings.Replace(string(clientData), `"crossOrigin":false`, `"crossOrigin":true`, 1)),
+			authData:   authData,
+			signature:  signature,
+			wantErr:    "bad signature",
+		},
+		{
+			name:       "Tampered counter",
+			clientData: clientData,
+			authData:   authenticator.authData(testRPID, webauthnFlagUserPresent|webauthnFlagUserVerified, 9, false),
+			signature:  signature,
+			wantErr:    "bad signature",
+		},
+		{
+			name:       "Wrong RP ID hash",
+			clientData: clientData,
+			authData:   wrongRPAuthData,
+			signature:  authenticator.sign(t, wrongRPAuthData, clientData),
+			wantErr:    "relying party mismatch",
+		},
+		{
+			name:       "Registration client data",
+			clientData: testClientData(webauthnTypeCreate, testChallenge, testOrigin),
+			authData:   authData,
+			signature:  signature,
+			wantErr:    "wrong ceremony type",
+		},
+		{
+			name:       "Truncated authenticator data",
+			clientData: clientData,
+			authData:   authData[:36],
+			signature:  signature,
+			wantErr:    "authenticator data too short",
+		},
+		{
+			name:       "Truncated public key",
+			clientData: clientData,
+			authData:   authData,
+			signature:  signature,
+			publicKey:  authenticator.coseKey()[:40],
+			wantErr:    "bad public key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicKey := authenticator.coseKey()
+			if tt.publicKey != nil {
+				publicKey = tt.publicKey
+			}
+
+			result, err := verifyWebAuthnAssertion(tt.clientData, tt.authData, tt.signature, publicKey, testChallenge, testRPID, testOrigins)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint32(8), result.signCount)
+			assert.NotZero(t, result.flags&webauthnFlagUserVerified)
+		})
+	}
+}
+
+func TestWebAuthnSignCountValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   int64
+		received int64
+		want     bool
+	}{
+		{name: "Authenticator without a counter", stored: 0, received: 0, want: true},
+		{name: "First use", stored: 0, received: 1, want: true},
+		{name: "Counter increased", stored: 5, received: 6, want: true},
+		{name: "Counter repeated", stored: 5, received: 5, want: false},
+		{name: "Counter regressed", stored: 5, received: 3, want: false},
+		{name: "Counter reset", stored: 5, received: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, webauthnSignCountValid(tt.stored, tt.received))
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string // hex
+		want     interface{}
+		wantRest string // hex
+		wantErr  string
+	}{
+		{
+			name: "Map",
+			data: "a2" + "01" + "02" + "63616c67" + "26",
+			want: map[interface{}]interface{}{int64(1): int64(2), "alg": int64(-7)},
+		},
+		{
+			name:     "Value followed by other bytes",
+			data:     "43010203" + "ff",
+			want:     []byte{1, 2, 3},
+			wantRest: "ff",
+		},
+		{
+			name: "Array of simple values",
+			data: "83" + "f4" + "f5" + "f6",
+			want: []interface{}{false, true, nil},
+		},
+		{
+			name:    "Empty",
+			data:    "",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Truncated byte string",
+			data:    "44010203",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Truncated argument",
+			data:    "1901",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Oversized byte string length",
+			data:    "5bffffffffffffffff00",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Oversized array length",
+			data:    "9b00000000ffffffff00",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Oversized map length",
+			data:    "baffffffff0000",
+			wantErr: "unexpected end of data",
+		},
+		{
+			name:    "Integer out of range",
+			data:    "1bffffffffffffffff",
+			wantErr: "integer out of range",
+		},
+		{
+			name:    "Indefinite length",
+			data:    "5f41ff",
+			wantErr: "indefinite lengths are not supported",
+		},
+		{
+			name:    "Duplicate map key",
+			data:    "a2" + "0101" + "0102",
+			wantErr: "duplicate map key",
+		},
+		{
+			name:    "Unsupported map key",
+			data:    "a1" + "4101" + "01",
+			wantErr: "unsupported map key",
+		},
+		{
+			name:    "Nested too deeply",
+			data:    strings.Repeat("81", cborMaxDepth+1) + "01",
+			wantErr: "nested too deeply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			value, rest, err := decodeCBOR(data)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+			assert.Equal(t, tt.wantRest, hex.EncodeToString(rest))
+		})
+	}
+}
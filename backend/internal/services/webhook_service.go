@@ -0,0 +1,541 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/metrics"
+	"myerp-v2/internal/models"
+	"myerp-v2/internal/repository"
+	"myerp-v2/internal/tracing"
+	"myerp-v2/internal/utils"
+)
+
+// Webhook request headers
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	webhookSecretPrefix     = "whsec_"
+	webhookRetryBaseDelay   = 30 * time.Second
+	webhookRetryMaxDelay    = 6 * time.Hour
+	webhookBatchSize        = 50   // Max deliveries attempted per database per worker run
+	webhookResponseBodySize = 2048 // Bytes of the endpoint's response kept in the delivery log
+)
+
+// WebhookService manages tenant webhooks and delivers events to them. Events
+// are queued as deliveries when they happen; the webhook worker
+// (ProcessQueue) posts them with an HMAC signature and retries failures with
+// exponential backoff.
+type WebhookService struct {
+	db          *sqlx.DB
+	webhookRepo repository.WebhookStore
+	config      *config.Config
+	client      *http.Client
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	db *sqlx.DB,
+	webhookRepo repository.WebhookStore,
+	cfg *config.Config,
+) *WebhookService {
+	dialer := outboundDialer(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateNetworks)
+
+	return &WebhookService{
+		db:          db,
+		webhookRepo: webhookRepo,
+		config:      cfg,
+		client: &http.Client{
+			Timeout:   cfg.Webhooks.Timeout,
+			Transport: metrics.Transport("webhook", &http.Transport{DialContext: dialer.DialContext, Proxy: http.ProxyFromEnvironment}),
+			// Redirects are not followed: the endpoint must answer itself
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// CreateWebhook registers a webhook. The returned secret is only shown once;
+// receivers use it to verify signatures.
+func (s *WebhookService) CreateWebhook(ctx context.Context, tenantID, userID uuid.UUID, req *models.WebhookCreateRequest) (*models.Webhook, string, error) {
+	secret, encryptedSecret, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	webhook := &models.Webhook{
+		TenantID:   tenantID,
+		URL:        strings.TrimSpace(req.URL),
+		EventTypes:     req.EventTypes,
+		Secret:         encryptedSecret,
+		IsActive:       true,
+		RedactionRules: append(pq.StringArray{}, req.RedactionRules...),
+		CreatedBy:      userID,
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		webhook.Description = &description
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, "", err
+	}
+
+	return webhook, secret, nil
+}
+
+// ListWebhooks lists a tenant's webhooks
+func (s *WebhookService) ListWebhooks(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]models.Webhook, int, error) {
+	return s.webhookRepo.List(ctx, tenantID, limit, offset)
+}
+
+// GetWebhook retrieves a tenant's webhook
+func (s *WebhookService) GetWebhook(ctx context.Context, tenantID, webhookID uuid.UUID) (*models.Webhook, error) {
+	return s.webhookRepo.FindByID(ctx, tenantID, webhookID)
+}
+
+// UpdateWebhook changes a webhook's endpoint, subscriptions, state or
+// redaction rules
+func (s *WebhookService) UpdateWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, req *models.WebhookUpdateRequest) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		webhook.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		webhook.Description = &description
+		if description == "" {
+			webhook.Description = nil
+		}
+	}
+	if req.EventTypes != nil {
+		webhook.EventTypes = *req.EventTypes
+	}
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+	if req.RedactionRules != nil {
+		webhook.RedactionRules = append(pq.StringArray{}, *req.RedactionRules...)
+	}
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook; its pending deliveries are dropped
+func (s *WebhookService) DeleteWebhook(ctx context.Context, tenantID, webhookID uuid.UUID) error {
+	return s.webhookRepo.Delete(ctx, tenantID, webhookID)
+}
+
+// RotateSecret replaces a webhook's signing secret and returns the new one.
+// Deliveries attempted from now on are signed with it.
+func (s *WebhookService) RotateSecret(ctx context.Context, tenantID, webhookID uuid.UUID) (string, error) {
+	secret, encryptedSecret, err := s.newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.webhookRepo.UpdateSecret(ctx, tenantID, webhookID, encryptedSecret); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// SendTestEvent queues a webhook.test event to a webhook, whatever event
+// types it is subscribed to
+func (s *WebhookService) SendTestEvent(ctx context.Context, tenantID, userID, webhookID uuid.UUID) (*models.WebhookDelivery, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newWebhookEvent(tenantID, models.WebhookEventTest, models.WebhookEventData{
+		ResourceType: models.ResourceWebhooks,
+		ResourceID:   &webhook.ID,
+		ActorID:      &userID,
+	})
+
+	return s.enqueueDelivery(ctx, webhook, event)
+}
+
+// ListDeliveries lists a webhook's delivery log
+func (s *WebhookService) ListDeliveries(ctx context.Context, tenantID, webhookID uuid.UUID, status string, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	if _, err := s.webhookRepo.FindByID(ctx, tenantID, webhookID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, tenantID, webhookID, status, limit, offset)
+}
+
+// GetDelivery retrieves one delivery of a webhook
+func (s *WebhookService) GetDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	return s.webhookRepo.FindDelivery(ctx, tenantID, webhookID, deliveryID)
+}
+
+// RetryDelivery requeues a permanently failed delivery
+func (s *WebhookService) RetryDelivery(ctx context.Context, tenantID, webhookID, deliveryID uuid.UUID) error {
+	return s.webhookRepo.RetryDelivery(ctx, tenantID, webhookID, deliveryID)
+}
+
+// Dispatch queues an event for every active webhook of the tenant subscribed
+// to its type. It is subscribed to the event bus. Webhooks whose queue is full
+// (their endpoint is down or too slow) miss the event.
+func (s *WebhookService) Dispatch(ctx context.Context, event *models.Event) error {
+	_, rejected, err := s.webhookRepo.EnqueueEvent(ctx, event.TenantID, webhookEventFrom(event), s.config.Webhooks.MaxAttempts, s.config.Queues.WebhookMaxPending)
+	if err != nil {
+		return err
+	}
+	if rejected > 0 {
+		metrics.ObserveQueueRejected(models.QueueWebhook, rejected)
+		log.Printf("⚠️  Event %s (%s) dropped for %d webhooks of tenant %s with full queues", event.ID, event.Type, rejected, event.TenantID)
+	}
+	return nil
+}
+
+// DeliverTo queues an event to one webhook, whatever event types it is
+// subscribed to (used by automation rules)
+func (s *WebhookService) DeliverTo(ctx context.Context, tenantID, webhookID uuid.UUID, event *models.Event) (*models.WebhookDelivery, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive {
+		return nil, fmt.Errorf("webhook is disabled")
+	}
+
+	return s.enqueueDelivery(ctx, webhook, webhookEventFrom(event))
+}
+
+// enqueueDelivery queues an event to one webhook, failing with "webhook queue
+// is full" once it has QUEUE_WEBHOOK_MAX_PENDING deliveries waiting
+func (s *WebhookService) enqueueDelivery(ctx context.Context, webhook *models.Webhook, event *models.WebhookEvent) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.EnqueueDelivery(ctx, webhook, event, s.config.Webhooks.MaxAttempts, s.config.Queues.WebhookMaxPending)
+	if err != nil && err.Error() == "webhook queue is full" {
+		metrics.ObserveQueueRejected(models.QueueWebhook, 1)
+	}
+	return delivery, err
+}
+
+// ValidateURL checks that url can be used as a webhook endpoint. HTTPS is
+// required unless private networks are allowed (development).
+func (s *WebhookService) ValidateURL(rawURL string) error {
+	return validateEndpointURL(rawURL, s.config.Webhooks.AllowPrivateNetworks)
+}
+
+// validateEndpointURL checks that url can be used as an outbound endpoint
+// (webhook, SIEM). HTTPS is required unless private networks are allowed.
+func validateEndpointURL(rawURL string, allowPrivateNetworks bool) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("must be an absolute URL")
+	}
+
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if !allowPrivateNetworks {
+			return fmt.Errorf("must use https")
+		}
+	default:
+		return fmt.Errorf("must use https")
+	}
+
+	if parsed.User != nil {
+		return fmt.Errorf("must not contain credentials")
+	}
+
+	if !allowPrivateNetworks {
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); (ip != nil && isPrivateWebhookIP(ip)) || host == "localhost" {
+			return fmt.Errorf("must not point to a private address")
+		}
+	}
+
+	return nil
+}
+
+// ProcessQueue posts due webhook deliveries in every data region with
+// JOBS_WEBHOOK_CONCURRENCY workers. It is run periodically by the background
+// job runner and returns the number of delivery attempts made.
+func (s *WebhookService) ProcessQueue(ctx context.Context) (int, error) {
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		attempted, err := drainQueue(ctx, s.config.Jobs.WebhookConcurrency, webhookBatchSize, func(ctx context.Context) (bool, error) {
+			return s.deliverNext(ctx, db)
+		})
+		total += attempted
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// CleanupDeliveries removes finished deliveries older than the retention
+// period from the delivery log, in every data region
+func (s *WebhookService) CleanupDeliveries(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Webhooks.DeliveryRetention)
+
+	total := 0
+	for _, db := range database.RegionDBs(s.db) {
+		deleted, err := s.webhookRepo.DeleteFinishedBefore(ctx, db, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+
+	return total, nil
+}
+
+// deliverNext claims one due delivery, posts it and records the outcome. The
+// row stays locked while posting, so concurrent workers never post it twice.
+func (s *WebhookService) deliverNext(ctx context.Context, db *sqlx.DB) (bool, error) {
+	tx, err := database.WithBypassRLS(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	delivery, err := s.webhookRepo.ClaimDue(ctx, tx)
+	if err != nil || delivery == nil {
+		return false, err
+	}
+
+	if !delivery.WebhookActive {
+		if err := s.webhookRepo.MarkFailed(ctx, tx, delivery, "webhook is disabled"); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	// The delivery continues the trace of the request that queued the event
+	ctx, span := tracing.StartFromStored(ctx, "webhook.deliver", delivery.TraceContext,
+		attribute.String("tenant.id", delivery.TenantID.String()),
+		attribute.String("webhook.id", delivery.WebhookID.String()),
+		attribute.String("webhook.event_type", delivery.EventType),
+		attribute.Int("webhook.attempt", delivery.Attempts+1),
+	)
+	var postErr error
+	defer func() { tracing.End(span, postErr) }()
+
+	start := time.Now()
+	responseStatus, responseBody, postErr := s.post(ctx, delivery)
+	duration := time.Since(start)
+	span.SetAttributes(attribute.Int("http.response.status_code", responseStatus))
+
+	// Once the request went out its outcome must be recorded, even if the
+	// worker is shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	if postErr != nil {
+		nextAttemptAt := time.Now().Add(webhookRetryDelay(delivery.Attempts + 1))
+		if err := s.webhookRepo.MarkAttemptFailed(ctx, tx, delivery, postErr.Error(), responseStatus, responseBody, duration, nextAttemptAt); err != nil {
+			return false, err
+		}
+		if delivery.Attempts+1 >= delivery.MaxAttempts {
+			metrics.ObserveDeadLetter(models.QueueWebhook)
+			log.Printf("⚠️  Webhook delivery %s to %s failed permanently after %d attempts: %v", delivery.ID, delivery.URL, delivery.Attempts+1, postErr)
+		}
+	} else if err := s.webhookRepo.MarkDelivered(ctx, tx, delivery, responseStatus, responseBody, duration); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return true, nil
+}
+
+// post sends a delivery to its endpoint. Any 2xx response is a success.
+func (s *WebhookService) post(ctx context.Context, delivery *models.ClaimedWebhookDelivery) (int, string, error) {
+	secret, err := utils.Decrypt(delivery.Secret, []byte(s.config.Security.EncryptionKey))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.config.App.Name+" Webhooks")
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderDelivery, delivery.EventID.String())
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "v1="+SignWebhookPayload(secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodySize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(body), nil
+}
+
+// PostJSON posts payload to an external URL (e.g. a Slack incoming webhook)
+// through the webhook client, so the same private network restrictions
+// apply. Any 2xx response is a success.
+func (s *WebhookService) PostJSON(ctx context.Context, rawURL string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.config.App.Name+" Webhooks")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseBodySize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// newSecret generates a signing secret and its encrypted form for storage
+func (s *WebhookService) newSecret() (string, string, error) {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	secret := webhookSecretPrefix + token
+
+	encrypted, err := utils.Encrypt(secret, []byte(s.config.Security.EncryptionKey))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	return secret, encrypted, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>"
+// keyed with the webhook's secret. Receivers recompute it to verify a
+// delivery and reject stale timestamps to prevent replays.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEventFrom builds the envelope posted for a bus event
+func webhookEventFrom(event *models.Event) *models.WebhookEvent {
+	return &models.WebhookEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		TenantID:  event.TenantID,
+		CreatedAt: event.OccurredAt,
+		Data: models.WebhookEventData{
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			ActorID:      event.ActorID,
+			Object:       event.Object,
+		},
+	}
+}
+
+// newWebhookEvent builds an event envelope
+func newWebhookEvent(tenantID uuid.UUID, eventType string, data models.WebhookEventData) *models.WebhookEvent {
+	return &models.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		TenantID:  tenantID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// webhookRetryDelay returns the exponential backoff before the given attempt
+// (30s, 1m, 2m, 4m, ... capped at 6h)
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// outboundDialer dials webhook and SIEM endpoints. Unless private networks
+// are allowed, it refuses addresses that are not public.
+func outboundDialer(timeout time.Duration, allowPrivateNetworks bool) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		// Checked on the resolved address, so DNS cannot point an endpoint
+		// at internal services
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateWebhookIP(ip) {
+				return fmt.Errorf("endpoint resolves to a private address")
+			}
+			return nil
+		}
+	}
+	return dialer
+}
+
+// isPrivateWebhookIP reports whether ip is loopback, private, link-local or
+// otherwise not a public internet address
+func isPrivateWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast()
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/metrics"
+)
+
+// azureVersion is the Blob service REST API version requests and shared
+// access signatures use
+const azureVersion = "2021-08-06"
+
+// AzureOptions configures an Azure Blob Storage backend
+type AzureOptions struct {
+	Endpoint   string // Defaults to https://{account}.blob.core.windows.net; e.g. http://azurite:10000/devstoreaccount1
+	Account    string
+	AccountKey string // Base64 shared key of the storage account
+	Container  string
+	Prefix     string // Put every key under this prefix
+
+	// Encryption scope stored objects are encrypted with, e.g. one using a
+	// customer-managed key. Empty for the container's default.
+	EncryptionScope string
+}
+
+// Azure stores objects as block blobs in a container of an Azure storage
+// account. Requests are authorized with the account's shared key.
+type Azure struct {
+	opts   AzureOptions
+	key    []byte
+	base   *url.URL // Endpoint with the container
+	client *http.Client
+}
+
+// NewAzure creates an Azure Blob Storage backend
+func NewAzure(opts AzureOptions) (*Azure, error) {
+	if opts.Account == "" || opts.Container == "" {
+		return nil, fmt.Errorf("azure account and container are required")
+	}
+	key, err := base64.StdEncoding.DecodeString(opts.AccountKey)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid azure account key")
+	}
+
+	if opts.Endpoint == "" {
+		opts.Endpoint = "https://" + opts.Account + ".blob.core.windows.net"
+	}
+	base, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid azure endpoint %q", opts.Endpoint)
+	}
+	base.Path = base.Path + "/" + opts.Container
+
+	return &Azure{
+		opts: opts,
+		key:  key,
+		base: base,
+		client: &http.Client{
+			Transport: metrics.Transport(BackendAzure, nil),
+		},
+	}, nil
+}
+
+// Name returns the backend's name
+func (a *Azure) Name() string {
+	return BackendAzure
+}
+
+// Put uploads the object as a block blob with a single Put Blob request
+func (a *Azure) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := a.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	if a.opts.EncryptionScope != "" {
+		req.Header.Set("x-ms-encryption-scope", a.opts.EncryptionScope)
+	}
+	a.sign(req)
+
+	resp, err := a.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open downloads the object; the response body is streamed to the caller
+func (a *Azure) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := a.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	a.sign(req)
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object. Unlike S3, Azure answers deletes of missing
+// blobs with 404, which is not an error here.
+func (a *Azure) Delete(ctx context.Context, key string) error {
+	req, err := a.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	a.sign(req)
+
+	resp, err := a.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignGet returns a URL with a read-only service shared access signature
+// for the object. The response headers are overridden so the browser gets
+// the file's type and name.
+func (a *Azure) PresignGet(key, contentType, contentDisposition string, ttl time.Duration) (string, error) {
+	u := a.objectURL(key)
+	expiry := time.Now().UTC().Add(ttl).Format("2006-01-02T15:04:05Z")
+
+	// Fields of the string to sign, in order; unused ones stay empty
+	stringToSign := strings.Join([]string{
+		"r",    // signedPermissions
+		"",     // signedStart
+		expiry, // signedExpiry
+		"/blob/" + a.opts.Account + "/" + a.opts.Container + "/" + prefixedKey(a.opts.Prefix, key),
+		"",           // signedIdentifier
+		"",           // signedIP
+		"",           // signedProtocol
+		azureVersion, // signedVersion
+		"b",          // signedResource: blob
+		"",           // signedSnapshotTime
+		"",           // signedEncryptionScope
+		"",           // rscc: Cache-Control
+		contentDisposition,
+		"", // rsce: Content-Encoding
+		"", // rscl: Content-Language
+		contentType,
+	}, "\n")
+
+	query := url.Values{}
+	query.Set("sv", azureVersion)
+	query.Set("sr", "b")
+	query.Set("sp", "r")
+	query.Set("se", expiry)
+	if contentDisposition != "" {
+		query.Set("rscd", contentDisposition)
+	}
+	if contentType != "" {
+		query.Set("rsct", contentType)
+	}
+	query.Set("sig", a.signature(stringToSign))
+
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+// newRequest builds a request for an object; callers set their headers and
+// sign it
+func (a *Azure) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.objectURL(key).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build azure request: %w", err)
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureVersion)
+	return req, nil
+}
+
+// sign authorizes a request with the account's shared key
+func (a *Azure) sign(req *http.Request) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var names []string
+	for name := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	// The Date header is left empty in favour of x-ms-date
+	stringToSign := strings.Join([]string{
+		req.Method,
+		"", // Content-Encoding
+		"", // Content-Language
+		contentLength,
+		"", // Content-MD5
+		req.Header.Get("Content-Type"),
+		"", // Date
+		"", // If-Modified-Since
+		"", // If-Match
+		"", // If-None-Match
+		"", // If-Unmodified-Since
+		"", // Range
+		canonicalHeaders.String() + "/" + a.opts.Account + req.URL.EscapedPath(),
+	}, "\n")
+
+	req.Header.Set("Authorization", "SharedKey "+a.opts.Account+":"+a.signature(stringToSign))
+}
+
+// do sends a request and turns error responses into errors
+func (a *Azure) do(req *http.Request) (*http.Response, error) {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("azure request failed: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("azure %s failed with status %d: %s", req.Method, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of key in the container
+func (a *Azure) objectURL(key string) *url.URL {
+	u := *a.base
+	u.Path = u.Path + "/" + prefixedKey(a.opts.Prefix, key)
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// signature computes the base64 HMAC-SHA256 of a string with the account key
+func (a *Azure) signature(stringToSign string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package storage
+
+import "fmt"
+
+// defaultGCSEndpoint is the XML API of Google Cloud Storage
+const defaultGCSEndpoint = "https://storage.googleapis.com"
+
+// GCSOptions configures a Google Cloud Storage backend
+type GCSOptions struct {
+	Endpoint        string // Defaults to https://storage.googleapis.com
+	Bucket          string
+	AccessKeyID     string // HMAC key of a service account
+	SecretAccessKey string // HMAC secret of the key
+	Prefix          string // Put every key under this prefix
+
+	// Cloud KMS key stored objects are encrypted with, e.g.
+	// projects/p/locations/l/keyRings/r/cryptoKeys/k. Empty for the bucket's
+	// default encryption.
+	KMSKeyName string
+}
+
+// NewGCS creates a Google Cloud Storage backend. It uses the XML API, which
+// is compatible with S3 and accepts AWS Signature Version 4 signed with a
+// service account's HMAC key, so uploads, downloads and presigned URLs work
+// as they do on S3.
+func NewGCS(opts GCSOptions) (*S3, error) {
+	if opts.Endpoint == "" {
+		opts.Endpoint = defaultGCSEndpoint
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, fmt.Errorf("gcs HMAC key is required")
+	}
+
+	putHeaders := map[string]string{}
+	if opts.KMSKeyName != "" {
+		putHeaders["x-goog-encryption-kms-key-name"] = opts.KMSKeyName
+	}
+
+	return newS3(BackendGCS, S3Options{
+		Endpoint:        opts.Endpoint,
+		Region:          "auto",
+		Bucket:          opts.Bucket,
+		AccessKeyID:     opts.AccessKeyID,
+		SecretAccessKey: opts.SecretAccessKey,
+		PathStyle:       true,
+		Prefix:          opts.Prefix,
+	}, putHeaders)
+}
@@ -0,0 +1,330 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"myerp-v2/internal/metrics"
+)
+
+// unsignedPayload lets uploads be streamed without hashing the body first
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 server-side encryption modes
+const (
+	S3EncryptionAES256 = "AES256"  // Keys managed by S3 (SSE-S3)
+	S3EncryptionKMS    = "aws:kms" // Keys managed by AWS KMS (SSE-KMS)
+)
+
+// S3Options configures an S3-compatible backend
+type S3Options struct {
+	Endpoint        string // e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool   // Address the bucket in the path instead of the host name
+	Prefix          string // Put every key under this prefix
+
+	// Server-side encryption of stored objects: empty for the bucket's
+	// default, AES256 or aws:kms with an optional KMS key
+	ServerSideEncryption string
+	KMSKeyID             string
+}
+
+// S3 stores objects in a bucket of an S3-compatible object store. Requests
+// are signed with AWS Signature Version 4.
+type S3 struct {
+	name       string
+	opts       S3Options
+	base       *url.URL          // Endpoint, with the bucket in the host name unless path-style
+	putHeaders map[string]string // Sent and signed with every upload, e.g. encryption
+	client     *http.Client
+}
+
+// NewS3 creates an S3 backend
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+
+	putHeaders := map[string]string{}
+	switch opts.ServerSideEncryption {
+	case "":
+	case S3EncryptionAES256:
+		putHeaders["x-amz-server-side-encryption"] = S3EncryptionAES256
+	case S3EncryptionKMS:
+		putHeaders["x-amz-server-side-encryption"] = S3EncryptionKMS
+		if opts.KMSKeyID != "" {
+			putHeaders["x-amz-server-side-encryption-aws-kms-key-id"] = opts.KMSKeyID
+		}
+	default:
+		return nil, fmt.Errorf("invalid S3 server-side encryption %q", opts.ServerSideEncryption)
+	}
+
+	return newS3(BackendS3, opts, putHeaders)
+}
+
+// newS3 creates a backend speaking the S3 API under name, sending putHeaders
+// with every upload
+func newS3(name string, opts S3Options, putHeaders map[string]string) (*S3, error) {
+	base, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid %s endpoint %q", name, opts.Endpoint)
+	}
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("%s bucket is required", name)
+	}
+
+	if opts.PathStyle {
+		base.Path = "/" + opts.Bucket
+	} else {
+		base.Host = opts.Bucket + "." + base.Host
+		base.Path = ""
+	}
+
+	return &S3{
+		name:       name,
+		opts:       opts,
+		base:       base,
+		putHeaders: putHeaders,
+		client: &http.Client{
+			Transport: metrics.Transport(name, nil),
+		},
+	}, nil
+}
+
+// Name returns the backend's name
+func (s *S3) Name() string {
+	return s.name
+}
+
+// Put uploads the object with a single PUT request
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body, s.putHeaders)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open downloads the object; the response body is streamed to the caller
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object. S3 answers deletes of missing objects with
+// success as well.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignGet returns a presigned GET URL for the object. The response headers
+// are overridden so the browser gets the file's type and name.
+func (s *S3) PresignGet(key, contentType, contentDisposition string, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.opts.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if contentType != "" {
+		query.Set("response-content-type", contentType)
+	}
+	if contentDisposition != "" {
+		query.Set("response-content-disposition", contentDisposition)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		escapePath(u.Path),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	u.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(now, canonicalRequest)
+	return u.String(), nil
+}
+
+// newRequest builds a signed request for an object, with headers (lower
+// case names) signed as well
+func (s *S3) newRequest(ctx context.Context, method, key string, body io.Reader, headers map[string]string) (*http.Request, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s request: %w", s.name, err)
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	signed := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           amzDate,
+	}
+	for name, value := range headers {
+		signed[name] = value
+	}
+
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(signed[name]) + "\n")
+		if name != "host" {
+			req.Header.Set(name, signed[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		escapePath(u.Path),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKeyID, s.scope(now), signedHeaders, s.signature(now, canonicalRequest),
+	))
+	return req, nil
+}
+
+// do sends a request and turns error responses into errors
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", s.name, err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s %s failed with status %d: %s", s.name, req.Method, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of key in the bucket
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.base
+	u.Path = u.Path + "/" + prefixedKey(s.opts.Prefix, key)
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// scope returns the credential scope of requests signed at t
+func (s *S3) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.opts.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request made at t
+func (s *S3) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			pairs = append(pairs, escape(name)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath URI-encodes each segment of a path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape URI-encodes everything but unreserved characters (RFC 3986)
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+// Package tracing records OpenTelemetry traces of requests and background
+// work and exports them over OTLP/HTTP.
+//
+// The Tracing middleware starts a span per request. The layers instrumented
+// by the metrics package (database connections, Redis, HTTP clients) add a
+// child span for each call made on behalf of a traced context; calls outside
+// a trace record nothing. Queued work (emails, webhook deliveries) stores the
+// trace context it was queued in (Inject), and its worker continues the
+// trace (StartFromStored).
+//
+// With tracing disabled the global tracer provider is a no-op, so spans cost
+// next to nothing.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"myerp-v2/internal/config"
+)
+
+// instrumentationName names the tracer of the spans recorded here
+const instrumentationName = "myerp-v2"
+
+// tracesPath is the path of the OTLP/HTTP traces endpoint of a collector
+const tracesPath = "/v1/traces"
+
+// Setup installs the tracer provider configured by OTEL_* and the W3C trace
+// context propagator. The returned function flushes spans not yet exported
+// and stops the exporter; call it on shutdown.
+func Setup(ctx context.Context, cfg *config.TracingConfig, environment string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// Like OTEL_EXPORTER_OTLP_ENDPOINT, the endpoint is the collector's base URL
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	if !strings.HasSuffix(endpoint, tracesPath) {
+		endpoint += tracesPath
+	}
+
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(cfg.Headers),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironmentName(environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// Recording reports whether ctx carries a span being recorded: child spans of
+// it are worth building
+func Recording(ctx context.Context) bool {
+	return trace.SpanFromContext(ctx).IsRecording()
+}
+
+// Record adds a finished client span of a call that started at start, as a
+// child of the span in ctx. Layers that only learn about a call once it is
+// over (e.g. database rows closed) use it instead of Start.
+func Record(ctx context.Context, name string, start time.Time, err error, attrs ...attribute.KeyValue) {
+	if !Recording(ctx) {
+		return
+	}
+	_, span := Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	End(span, err)
+}
+
+// End ends a span, marking it failed if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns the trace context of ctx for queued work to store, or nil
+// (NULL) when ctx is not part of a trace
+func Inject(ctx context.Context) *json.RawMessage {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	data, _ := json.Marshal(carrier)
+	raw := json.RawMessage(data)
+	return &raw
+}
+
+// StartFromStored starts the span of a worker processing queued work, in
+// the trace the work was queued in (see Inject). Work queued outside a trace
+// starts a new one.
+func StartFromStored(ctx context.Context, name string, stored *json.RawMessage, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if stored != nil {
+		carrier := propagation.MapCarrier{}
+		if err := json.Unmarshal(*stored, &carrier); err == nil {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+		}
+	}
+	return Start(ctx, name, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(attrs...))
+}
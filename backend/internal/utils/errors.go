@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Error kinds. A DomainError wraps one of them, which decides the HTTP status
+// of its response; errors.Is(err, ErrNotFound) reports whether an error is of
+// that kind.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrValidation      = errors.New("validation failed")
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// errorKinds maps each error kind to its HTTP status and default code
+var errorKinds = map[error]struct {
+	status int
+	code   string
+}{
+	ErrBadRequest:      {http.StatusBadRequest, "BAD_REQUEST"},
+	ErrUnauthorized:    {http.StatusUnauthorized, "UNAUTHORIZED"},
+	ErrForbidden:       {http.StatusForbidden, "FORBIDDEN"},
+	ErrNotFound:        {http.StatusNotFound, "NOT_FOUND"},
+	ErrConflict:        {http.StatusConflict, "CONFLICT"},
+	ErrValidation:      {http.StatusUnprocessableEntity, "VALIDATION_ERROR"},
+	ErrTooManyRequests: {http.StatusTooManyRequests, "TOO_MANY_REQUESTS"},
+}
+
+// DomainError is an error a service reports to the client. Its message is
+// safe to show, and Code is a stable machine-readable code such as
+// USER_NOT_FOUND that clients can rely on, unlike the message.
+type DomainError struct {
+	Kind    error
+	Code    string
+	Message string
+	Details map[string]string
+}
+
+// NewDomainError creates a domain error of the given kind. An empty code
+// defaults to the kind's code, e.g. NOT_FOUND.
+func NewDomainError(kind error, code, message string) *DomainError {
+	if code == "" {
+		code = errorKinds[kind].code
+	}
+	return &DomainError{Kind: kind, Code: code, Message: message}
+}
+
+// Error returns the message, so callers matching on err.Error() keep working
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the kind, for errors.Is
+func (e *DomainError) Unwrap() error {
+	return e.Kind
+}
+
+// WithDetails returns a copy of the error with field details, e.g. the
+// fields that failed validation
+func (e *DomainError) WithDetails(details map[string]string) *DomainError {
+	copy := *e
+	copy.Details = details
+	return &copy
+}
+
+// WriteError writes the response of a failed request. A DomainError gets the
+// status of its kind, its code and its message. Any other error is internal:
+// it is logged and the client only gets fallback, so database and driver
+// errors never reach the response.
+func WriteError(w http.ResponseWriter, err error, fallback string) {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		kind, ok := errorKinds[domainErr.Kind]
+		if !ok {
+			kind = errorKinds[ErrBadRequest]
+		}
+		ErrorWithDetails(w, kind.status, domainErr.Code, domainErr.Message, domainErr.Details)
+		return
+	}
+
+	log.Printf("⚠️  %s: %v", fallback, err)
+	InternalServerError(w, fallback)
+}
+
+// NewBadRequestError creates a domain error answered with 400 Bad Request
+func NewBadRequestError(code, message string) error {
+	return NewDomainError(ErrBadRequest, code, message)
+}
+
+// NewUnauthorizedError creates a domain error answered with 401 Unauthorized
+func NewUnauthorizedError(code, message string) error {
+	return NewDomainError(ErrUnauthorized, code, message)
+}
+
+// NewForbiddenError creates a domain error answered with 403 Forbidden
+func NewForbiddenError(code, message string) error {
+	return NewDomainError(ErrForbidden, code, message)
+}
+
+// NewNotFoundError creates a domain error answered with 404 Not Found
+func NewNotFoundError(code, message string) error {
+	return NewDomainError(ErrNotFound, code, message)
+}
+
+// NewConflictError creates a domain error answered with 409 Conflict
+func NewConflictError(code, message string) error {
+	return NewDomainError(ErrConflict, code, message)
+}
+
+// NewValidationError creates a domain error answered with 422 Unprocessable Entity
+func NewValidationError(code, message string) error {
+	return NewDomainError(ErrValidation, code, message)
+}
+
+// NewFieldError creates a validation error of a single field: the message
+// is also given as the field's detail
+func NewFieldError(code, field, message string) error {
+	return NewDomainError(ErrValidation, code, message).WithDetails(map[string]string{field: message})
+}
+
+// NewTooManyRequestsError creates a domain error answered with 429 Too Many Requests
+func NewTooManyRequestsError(code, message string) error {
+	return NewDomainError(ErrTooManyRequests, code, message)
+}
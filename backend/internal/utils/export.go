@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatJSON = "json"
+)
+
+// ExportFormats are the formats exports can be downloaded in
+var ExportFormats = []string{ExportFormatCSV, ExportFormatXLSX, ExportFormatJSON}
+
+const exportFlushRows = 500 // Records written between flushes to the client
+
+var exportContentTypes = map[string]string{
+	ExportFormatCSV:  "text/csv; charset=utf-8",
+	ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportFormatJSON: "application/json",
+}
+
+// ExportWriter streams an export to the client as records are read, so
+// exports of any size use constant memory. CSV and XLSX files get a header
+// row of columns and one row per record; JSON is an array of the records
+// themselves.
+type ExportWriter struct {
+	format  string
+	out     *bufio.Writer
+	rc      *http.ResponseController
+	csv     *csv.Writer
+	xlsx    *XLSXWriter
+	records int
+}
+
+// NewExportWriter starts a download named "<name>-<date>.<format>". Once it
+// returned, the response has started: errors can only abort it (see
+// ExportWriter.Abort).
+func NewExportWriter(w http.ResponseWriter, format, name string, columns []string) (*ExportWriter, error) {
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	rc := http.NewResponseController(w)
+	// The download outlives the server's write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	e := &ExportWriter{format: format, out: bufio.NewWriter(w), rc: rc}
+
+	switch format {
+	case ExportFormatCSV:
+		e.csv = csv.NewWriter(e.out)
+		if err := e.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case ExportFormatXLSX:
+		xlsx, err := NewXLSXWriter(e.out, name)
+		if err != nil {
+			return nil, err
+		}
+		e.xlsx = xlsx
+		if err := e.xlsx.WriteRow(columns); err != nil {
+			return nil, err
+		}
+	case ExportFormatJSON:
+		if _, err := e.out.WriteString("["); err != nil {
+			return nil, err
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return e, nil
+}
+
+// Write adds a record. row is the record's values in column order; JSON
+// exports encode record instead.
+func (e *ExportWriter) Write(record interface{}, row []string) error {
+	var err error
+	switch e.format {
+	case ExportFormatCSV:
+		err = e.csv.Write(SpreadsheetSafeRow(row))
+	case ExportFormatXLSX:
+		err = e.xlsx.WriteRow(row)
+	case ExportFormatJSON:
+		var data []byte
+		if data, err = json.Marshal(record); err != nil {
+			return err
+		}
+		if e.records > 0 {
+			e.out.WriteString(",")
+		}
+		e.out.WriteString("\n")
+		_, err = e.out.Write(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.records++
+	if e.records%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// Records returns the number of records written so far
+func (e *ExportWriter) Records() int {
+	return e.records
+}
+
+// Close ends the export and sends what is left
+func (e *ExportWriter) Close() error {
+	switch e.format {
+	case ExportFormatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case ExportFormatXLSX:
+		if err := e.xlsx.Close(); err != nil {
+			return err
+		}
+	case ExportFormatJSON:
+		if _, err := e.out.WriteString("\n]\n"); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// Abort logs err and drops the connection so the client sees a failed
+// download rather than a file that looks complete but is truncated. It does
+// not return.
+func (e *ExportWriter) Abort(err error) {
+	log.Printf("⚠️  %s export aborted after %d records: %v", e.format, e.records, err)
+	panic(http.ErrAbortHandler)
+}
+
+// flush sends the records buffered so far to the client
+func (e *ExportWriter) flush() error {
+	switch e.format {
+	case ExportFormatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case ExportFormatXLSX:
+		if err := e.xlsx.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := e.out.Flush(); err != nil {
+		return err
+	}
+	if err := e.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// ExportValue formats v as a CSV or XLSX cell: nil is empty, times are RFC
+// 3339 in UTC, lists are joined with ";" and maps are JSON
+func ExportValue(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return ""
+	}
+	if rv.Kind() == reflect.Ptr {
+		v = rv.Elem().Interface()
+		rv = rv.Elem()
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.String {
+			values := make([]string, rv.Len())
+			for i := range values {
+				values[i] = rv.Index(i).String()
+			}
+			return strings.Join(values, ";")
+		}
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return ""
+		}
+	default:
+		return fmt.Sprint(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// SpreadsheetSafeRow returns a CSV row with every value made safe to open
+// in spreadsheet applications (see spreadsheetSafe)
+func SpreadsheetSafeRow(row []string) []string {
+	safe := make([]string, len(row))
+	for i, value := range row {
+		safe[i] = spreadsheetSafe(value)
+	}
+	return safe
+}
+
+// spreadsheetSafe keeps spreadsheet applications from running CSV values as
+// formulas by prefixing those that would start one with a quote
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
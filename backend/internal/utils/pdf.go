@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PDF page layout, in points (A4 portrait)
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFooterY      = 30
+	pdfTextSize     = 10
+	pdfHeadingSize  = 14
+	pdfTitleSize    = 18
+	pdfLineSpacing  = 1.4
+	pdfAverageGlyph = 0.55 // Helvetica's average glyph width relative to the font size, used to wrap lines
+)
+
+// pdfLine is a line of text placed on a page
+type pdfLine struct {
+	x, y float64
+	font string // F1 (Helvetica) or F2 (Helvetica-Bold)
+	size float64
+	text string
+}
+
+// PDFDocument builds a text-only PDF of titled sections, such as a report
+// handed to auditors. Text is set in the standard Helvetica fonts, so the
+// file embeds no fonts; characters outside Latin-1 are replaced with "?".
+// Long lines are wrapped and pages are added as text is written.
+type PDFDocument struct {
+	title   string
+	created time.Time
+	pages   [][]pdfLine
+	y       float64
+}
+
+// NewPDFDocument starts a document whose first page opens with the title
+func NewPDFDocument(title string) *PDFDocument {
+	d := &PDFDocument{title: title, created: time.Now().UTC()}
+	d.newPage()
+	d.add("F2", pdfTitleSize, 0, title)
+	d.y -= pdfTextSize
+	return d
+}
+
+// Heading starts a section; a heading is never left alone at the bottom of
+// a page
+func (d *PDFDocument) Heading(text string) {
+	if d.y-3*pdfHeadingSize*pdfLineSpacing < pdfMargin {
+		d.newPage()
+	} else {
+		d.y -= pdfTextSize
+	}
+	d.add("F2", pdfHeadingSize, 0, text)
+}
+
+// Text adds a paragraph, wrapped to the page width
+func (d *PDFDocument) Text(text string) {
+	for _, line := range wrapPDFText(text, pdfPageWidth-2*pdfMargin, pdfTextSize) {
+		d.add("F1", pdfTextSize, 0, line)
+	}
+}
+
+// Field adds a "label: value" line with the label in bold
+func (d *PDFDocument) Field(label, value string) {
+	indent := 170.0
+	lines := wrapPDFText(value, pdfPageWidth-2*pdfMargin-indent, pdfTextSize)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	for i, line := range lines {
+		if i == 0 {
+			d.add("F2", pdfTextSize, 0, label)
+			d.y += pdfTextSize * pdfLineSpacing // Same line as the label
+		}
+		d.add("F1", pdfTextSize, indent, line)
+	}
+}
+
+// Space adds an empty line
+func (d *PDFDocument) Space() {
+	d.y -= pdfTextSize * pdfLineSpacing
+}
+
+// WriteTo writes the document, numbering its pages in their footers
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	out := &pdfWriter{w: bufio.NewWriter(w)}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-5 are the catalog, page tree, fonts and info; each page is
+	// followed by its contents
+	pageIDs := make([]int, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = 6 + 2*i
+	}
+
+	out.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	out.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageIDs)))
+	out.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	out.object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	out.object(5, fmt.Sprintf("<< /Title %s /Producer (MyERP) /CreationDate (D:%s) >>",
+		pdfString(d.title), d.created.Format("20060102150405Z")))
+
+	for i, lines := range d.pages {
+		footer := pdfLine{x: pdfMargin, y: pdfFooterY, font: "F1", size: 8,
+			text: fmt.Sprintf("%s - page %d of %d", d.title, i+1, len(d.pages))}
+
+		var content bytes.Buffer
+		for _, line := range append(lines, footer) {
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", line.font, line.size, line.x, line.y, pdfString(line.text))
+		}
+
+		out.object(pageIDs[i], fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pageIDs[i]+1))
+		out.object(pageIDs[i]+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.n
+	count := 6 + 2*len(d.pages)
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", count)
+	for id := 1; id < count; id++ {
+		out.printf("%010d 00000 n \n", out.offsets[id])
+	}
+	out.printf("trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", count, xref)
+
+	if out.err == nil {
+		out.err = out.w.Flush()
+	}
+	return out.n, out.err
+}
+
+// newPage starts a page with the cursor at the top margin
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// add places a line below the cursor, starting a page if it does not fit
+func (d *PDFDocument) add(font string, size, indent float64, text string) {
+	if d.y-size*pdfLineSpacing < pdfMargin {
+		d.newPage()
+	}
+	d.y -= size * pdfLineSpacing
+	page := len(d.pages) - 1
+	d.pages[page] = append(d.pages[page], pdfLine{x: pdfMargin + indent, y: d.y, font: font, size: size, text: text})
+}
+
+// wrapPDFText splits text into lines that fit width at the font size,
+// breaking at spaces and at explicit newlines
+func wrapPDFText(text string, width, size float64) []string {
+	maxChars := max(int(width/(size*pdfAverageGlyph)), 1)
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > maxChars {
+				if line != "" {
+					lines, line = append(lines, line), ""
+				}
+				runes := []rune(word)
+				lines, word = append(lines, string(runes[:maxChars])), string(runes[maxChars:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= maxChars:
+				line += " " + word
+			default:
+				lines, line = append(lines, line), word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfString encodes text as a PDF literal string in WinAnsiEncoding, which
+// matches Latin-1 for the characters kept
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// pdfWriter writes PDF objects, recording their offsets for the
+// cross-reference table
+type pdfWriter struct {
+	w       *bufio.Writer
+	n       int64
+	offsets map[int]int64
+	err     error
+}
+
+// printf writes formatted output, keeping the first error
+func (p *pdfWriter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.n += int64(n)
+	p.err = err
+}
+
+// object writes an indirect object
+func (p *pdfWriter) object(id int, body string) {
+	if p.offsets == nil {
+		p.offsets = map[int]int64{}
+	}
+	p.offsets[id] = p.n
+	p.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
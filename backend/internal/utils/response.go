@@ -2,7 +2,10 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Response represents a standard API response structure
@@ -22,10 +25,11 @@ type ErrorInfo struct {
 
 // Meta contains pagination and additional metadata
 type Meta struct {
-	Page       int `json:"page,omitempty"`
-	PageSize   int `json:"page_size,omitempty"`
-	TotalPages int `json:"total_pages,omitempty"`
-	TotalCount int `json:"total_count,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	TotalCount int    `json:"total_count,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"` // Set when paging by cursor and there are more rows
 }
 
 // JSON writes a JSON response with the given status code
@@ -60,6 +64,15 @@ func Created(w http.ResponseWriter, data interface{}) {
 	})
 }
 
+// Accepted writes a 202 Accepted response, for work that continues in the
+// background
+func Accepted(w http.ResponseWriter, data interface{}) {
+	JSON(w, http.StatusAccepted, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
 // NoContent writes a 204 No Content response
 func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
@@ -123,6 +136,20 @@ func InternalServerError(w http.ResponseWriter, message string) {
 	Error(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", message)
 }
 
+// VersionConflict writes a 409 Conflict response for an update based on a
+// version of a resource that is no longer current. The data carries the
+// current state, for the client to merge its change into.
+func VersionConflict(w http.ResponseWriter, message string, latest interface{}) {
+	JSON(w, http.StatusConflict, Response{
+		Success: false,
+		Data:    latest,
+		Error: &ErrorInfo{
+			Code:    "VERSION_CONFLICT",
+			Message: message,
+		},
+	})
+}
+
 // TooManyRequests writes a 429 Too Many Requests response
 func TooManyRequests(w http.ResponseWriter, message string) {
 	Error(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", message)
@@ -139,6 +166,65 @@ func ParseJSONBody(r *http.Request, v interface{}) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
 
+// ParseMergePatch parses a JSON Merge Patch (RFC 7386) request body into
+// the provided struct, whose pointer fields are left nil for members the
+// patch leaves out. A member set to null is nil too, so the returned set
+// names the members the patch clears.
+func ParseMergePatch(r *http.Request, v interface{}) (map[string]bool, error) {
+	defer r.Body.Close()
+
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&members); err != nil {
+		return nil, err
+	}
+	if members == nil {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	nulls := make(map[string]bool)
+	for name, value := range members {
+		if string(value) == "null" {
+			nulls[name] = true
+			delete(members, name)
+		}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return nulls, nil
+}
+
+// SetVersion sets the ETag header to the version of a resource, its last
+// update time, to send back in If-Match when updating it
+func SetVersion(w http.ResponseWriter, updatedAt time.Time) {
+	w.Header().Set("ETag", `"`+updatedAt.UTC().Format(time.RFC3339Nano)+`"`)
+}
+
+// ParseVersion returns the version an update is based on: the If-Match
+// header, or else the version field of the body. Either is the updated_at of
+// the resource as last read. It returns nil when the client sent neither, and
+// the update then overwrites whatever is current.
+func ParseVersion(r *http.Request, version *string) (*time.Time, error) {
+	value := strings.Trim(strings.TrimPrefix(r.Header.Get("If-Match"), "W/"), `"`)
+	if value == "" && version != nil {
+		value = *version
+	}
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, NewBadRequestError("INVALID_VERSION", "invalid version, send the updated_at of the resource")
+	}
+	return &t, nil
+}
+
 // NewMeta creates a new Meta struct for pagination
 func NewMeta(page, pageSize, totalCount int) *Meta {
 	totalPages := (totalCount + pageSize - 1) / pageSize
@@ -49,14 +49,21 @@ func IsValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// Password length limits enforced by IsValidPassword. Passwords must also
+// mix uppercase and lowercase letters, numbers and special characters.
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 128
+)
+
 // IsValidPassword validates a password based on security requirements
 func IsValidPassword(password string) (bool, string) {
-	if len(password) < 8 {
-		return false, "Password must be at least 8 characters long"
+	if len(password) < PasswordMinLength {
+		return false, fmt.Sprintf("Password must be at least %d characters long", PasswordMinLength)
 	}
 
-	if len(password) > 128 {
-		return false, "Password must not exceed 128 characters"
+	if len(password) > PasswordMaxLength {
+		return false, fmt.Sprintf("Password must not exceed %d characters", PasswordMaxLength)
 	}
 
 	var (
@@ -171,6 +178,34 @@ func IsValidSlug(slug string) bool {
 	return true
 }
 
+// IsValidDomain validates a lowercase host name like erp.acme.com: two or
+// more labels of letters, digits and inner hyphens, ending in a top-level
+// domain
+func IsValidDomain(domain string) bool {
+	if len(domain) > 253 {
+		return false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, char := range label {
+			if !(char >= 'a' && char <= 'z') && !unicode.IsDigit(char) && char != '-' {
+				return false
+			}
+		}
+	}
+
+	// Top-level domains are not numeric (no IP addresses)
+	tld := labels[len(labels)-1]
+	return !unicode.IsDigit(rune(tld[0]))
+}
+
 // SanitizeString removes leading/trailing whitespace and limits length
 func SanitizeString(s string, maxLength int) string {
 	trimmed := strings.TrimSpace(s)
@@ -187,6 +222,13 @@ func ValidateRequired(field, value, fieldName string, errors *ValidationErrors)
 	}
 }
 
+// ValidateDomain validates a host name field
+func ValidateDomain(field, domain string, errors *ValidationErrors) {
+	if !IsValidDomain(domain) {
+		errors.Add(field, "Invalid domain, e.g. erp.example.com")
+	}
+}
+
 // ValidateEmail validates an email field
 func ValidateEmail(field, email string, errors *ValidationErrors) {
 	if !IsValidEmail(email) {
@@ -232,3 +274,11 @@ func ValidateEnum(field, value string, allowed []string, fieldName string, error
 	}
 	errors.Add(field, fmt.Sprintf("%s must be one of: %s", fieldName, strings.Join(allowed, ", ")))
 }
+
+// ValidateNotNull checks that a merge patch does not clear a field that must
+// keep a value
+func ValidateNotNull(field string, nulls map[string]bool, fieldName string, errors *ValidationErrors) {
+	if nulls[field] {
+		errors.Add(field, fmt.Sprintf("%s cannot be null", fieldName))
+	}
+}
@@ -0,0 +1,352 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxMaxPartSize = 64 << 20 // Largest uncompressed part read from a file (guards against zip bombs)
+	xlsxMaxRows     = 1048576  // Rows of a worksheet
+	xlsxMaxColumns  = 16384    // Columns of a worksheet (A to XFD)
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is a plain (<t>) or rich text (<r><t>) string
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Ref   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSXRows reads the cell values of the first worksheet of an XLSX file.
+// Rows and cells keep their position in the sheet (row i is line i+1), so
+// skipped rows and cells are returned empty. Formula cells yield the value
+// last computed by the spreadsheet application.
+func ReadXLSXRows(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid XLSX file")
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := xlsxDecode(f, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("not a valid XLSX file: worksheet missing")
+	}
+	var sheet xlsxWorksheet
+	if err := xlsxDecode(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, sheetRow := range sheet.Rows {
+		rowIndex := len(rows)
+		if sheetRow.Ref > xlsxMaxRows {
+			return nil, fmt.Errorf("not a valid XLSX file: bad row number %d", sheetRow.Ref)
+		}
+		if sheetRow.Ref > 0 {
+			rowIndex = sheetRow.Ref - 1
+		}
+		for len(rows) <= rowIndex {
+			rows = append(rows, []string{})
+		}
+
+		row := []string{}
+		for _, cell := range sheetRow.Cells {
+			colIndex := len(row)
+			if cell.Ref != "" {
+				if colIndex, err = xlsxColumnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(row) <= colIndex {
+				row = append(row, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				i, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || i < 0 || i >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("not a valid XLSX file: bad shared string in cell %s", cell.Ref)
+				}
+				row[colIndex] = sharedStrings.Items[i].String()
+			case "inlineStr":
+				row[colIndex] = cell.Inline.String()
+			case "b":
+				row[colIndex] = "FALSE"
+				if cell.Value == "1" {
+					row[colIndex] = "TRUE"
+				}
+			default:
+				row[colIndex] = cell.Value
+			}
+		}
+		rows[rowIndex] = row
+	}
+
+	return rows, nil
+}
+
+// xlsxFirstSheetPath resolves the archive path of the workbook's first sheet
+func xlsxFirstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("not a valid XLSX file: workbook missing")
+	}
+	var workbook xlsxWorkbook
+	if err := xlsxDecode(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("the XLSX file has no worksheet")
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := xlsxDecode(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+// xlsxDecode parses an XML part of the archive into v
+func xlsxDecode(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("not a valid XLSX file: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, xlsxMaxPartSize+1))
+	if err != nil {
+		return fmt.Errorf("not a valid XLSX file: %w", err)
+	}
+	if len(data) > xlsxMaxPartSize {
+		return fmt.Errorf("the XLSX file is too large")
+	}
+
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("not a valid XLSX file: %s is malformed", f.Name)
+	}
+	return nil
+}
+
+// xlsxColumnIndex returns the zero-based column of a cell reference (e.g. 2
+// for "C7")
+func xlsxColumnIndex(ref string) (int, error) {
+	col := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		letters++
+		if col > xlsxMaxColumns {
+			break
+		}
+	}
+	if letters == 0 || col > xlsxMaxColumns {
+		return 0, fmt.Errorf("not a valid XLSX file: bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// XLSX parts written before the worksheet: a workbook with a single sheet
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="1"><fill><patternFill patternType="none"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="1"><xf/></cellXfs></styleSheet>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// XLSXWriter streams a single-sheet XLSX file: rows are written to the
+// archive as they come, so large sheets are never held in memory. Every cell
+// is written as text.
+type XLSXWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	rows    int
+	buf     bytes.Buffer
+}
+
+// NewXLSXWriter starts an XLSX file on w with one sheet named sheetName
+// (at most 31 characters, none of []:*?/\)
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	archive := zip.NewWriter(w)
+
+	var workbook bytes.Buffer
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	if err := xml.EscapeText(&workbook, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+	workbook.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+
+	parts := []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The worksheet is written last: the archive can only write one part at
+	// a time
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetStart); err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{archive: archive, sheet: sheet}, nil
+}
+
+// WriteRow appends a row to the sheet
+func (x *XLSXWriter) WriteRow(values []string) error {
+	if x.rows >= xlsxMaxRows {
+		return fmt.Errorf("the XLSX sheet is full (%d rows)", xlsxMaxRows)
+	}
+	if len(values) > xlsxMaxColumns {
+		return fmt.Errorf("an XLSX row can have at most %d columns", xlsxMaxColumns)
+	}
+	x.rows++
+
+	x.buf.Reset()
+	fmt.Fprintf(&x.buf, `<row r="%d">`, x.rows)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		fmt.Fprintf(&x.buf, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(i), x.rows)
+		// Characters XML cannot carry are replaced with U+FFFD
+		if err := xml.EscapeText(&x.buf, []byte(value)); err != nil {
+			return err
+		}
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	x.buf.WriteString(`</row>`)
+
+	_, err := x.sheet.Write(x.buf.Bytes())
+	return err
+}
+
+// Flush writes the rows compressed so far to the underlying writer
+func (x *XLSXWriter) Flush() error {
+	return x.archive.Flush()
+}
+
+// Close ends the sheet and the file. It does not close the underlying
+// writer.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// xlsxColumnName returns the letters of a zero-based column (e.g. "C" for 2)
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,204 @@
+-- Create sales documents tables
+-- Quotations, sales orders and invoices share one header table and one line table.
+-- A quote is converted into an order, and an order is invoiced; source_document_id
+-- keeps the chain so every invoice can be traced back to its order and quote.
+
+CREATE TABLE sales_documents (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Document type: quote | order | invoice
+    document_type VARCHAR(20) NOT NULL,
+    document_number VARCHAR(50) NOT NULL,   -- e.g., QT-000001, SO-000001, INV-000001
+    sequence_number INT NOT NULL,           -- Per-tenant, per-type counter
+
+    -- Source document (quote for an order, order for an invoice)
+    source_document_id UUID,
+
+    -- Customer
+    customer_name VARCHAR(255) NOT NULL,
+    customer_email VARCHAR(255),
+    customer_address TEXT,
+
+    -- Status: draft | confirmed | fulfilled | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+
+    -- Dates
+    issue_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    due_date DATE,  -- Quote validity, order delivery date or invoice payment due date
+
+    -- Amounts (computed from lines)
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    subtotal DECIMAL(15,2) NOT NULL DEFAULT 0,
+    discount_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tax_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total DECIMAL(15,2) NOT NULL DEFAULT 0,
+
+    notes TEXT,
+
+    -- Status timestamps
+    confirmed_at TIMESTAMPTZ,
+    fulfilled_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,  -- References users(id) in same tenant
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_sales_document_number UNIQUE(tenant_id, document_type, document_number),
+    CONSTRAINT unique_sales_document_sequence UNIQUE(tenant_id, document_type, sequence_number),
+    CONSTRAINT valid_document_type CHECK (document_type IN ('quote', 'order', 'invoice')),
+    CONSTRAINT valid_status CHECK (status IN ('draft', 'confirmed', 'fulfilled', 'cancelled')),
+    FOREIGN KEY (tenant_id, source_document_id) REFERENCES sales_documents(tenant_id, id) ON DELETE SET NULL
+);
+
+CREATE TABLE sales_document_lines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+
+    line_number INT NOT NULL,
+    product_code VARCHAR(100),
+    description TEXT NOT NULL,
+
+    quantity DECIMAL(15,3) NOT NULL DEFAULT 1,
+    unit_price DECIMAL(15,2) NOT NULL DEFAULT 0,
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0,  -- Percentage, e.g., 19.00
+    line_total DECIMAL(15,2) NOT NULL DEFAULT 0,  -- Net of discount, before tax
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_sales_line_number UNIQUE(tenant_id, document_id, line_number),
+    CONSTRAINT valid_quantity CHECK (quantity > 0),
+    CONSTRAINT valid_discount CHECK (discount_percent >= 0 AND discount_percent <= 100),
+    CONSTRAINT valid_tax_rate CHECK (tax_rate >= 0),
+    FOREIGN KEY (tenant_id, document_id) REFERENCES sales_documents(tenant_id, id) ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX idx_sales_documents_tenant ON sales_documents(tenant_id);
+CREATE INDEX idx_sales_documents_type_status ON sales_documents(tenant_id, document_type, status);
+CREATE INDEX idx_sales_documents_source ON sales_documents(tenant_id, source_document_id) WHERE source_document_id IS NOT NULL;
+CREATE INDEX idx_sales_documents_created_at ON sales_documents(tenant_id, created_at DESC);
+CREATE INDEX idx_sales_document_lines_document ON sales_document_lines(tenant_id, document_id);
+
+-- Enable RLS
+ALTER TABLE sales_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sales_document_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON sales_documents
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+CREATE POLICY bypass_rls_for_superuser ON sales_documents
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON sales_document_lines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+
+CREATE POLICY bypass_rls_for_superuser ON sales_document_lines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Trigger for updated_at
+CREATE TRIGGER update_sales_documents_updated_at
+    BEFORE UPDATE ON sales_documents
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE sales_documents IS 'Quotations, sales orders and invoices - RLS enforced';
+COMMENT ON TABLE sales_document_lines IS 'Line items for sales documents - RLS enforced';
+COMMENT ON COLUMN sales_documents.source_document_id IS 'Quote an order was converted from, or order an invoice was raised for';
+COMMENT ON COLUMN sales_documents.sequence_number IS 'Per-tenant counter per document type, used to build document_number';
+
+-- Sales permissions
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('sales', 'view', 'View Sales', 'View quotations, orders and invoices', 'Sales'),
+    ('sales', 'create', 'Create Sales Documents', 'Create quotations, orders and invoices', 'Sales'),
+    ('sales', 'edit', 'Edit Sales Documents', 'Edit draft sales documents', 'Sales'),
+    ('sales', 'delete', 'Delete Sales Documents', 'Delete draft sales documents', 'Sales'),
+    ('sales', 'manage_status', 'Manage Sales Status', 'Confirm, fulfill and cancel sales documents', 'Sales'),
+    ('sales', '*', 'All Sales Permissions', 'Full sales access', 'Sales')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign sales permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'sales'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'manager' AND p.action IN ('view', 'create', 'edit', 'manage_status'))
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include sales for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view';  -- View-only permissions
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments and sales permissions';
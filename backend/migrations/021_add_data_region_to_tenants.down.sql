@@ -0,0 +1,6 @@
+-- Rollback data residency columns
+
+DROP INDEX IF EXISTS idx_tenants_data_region;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS region_migration_started_at;
+ALTER TABLE tenants DROP COLUMN IF EXISTS data_region;
@@ -0,0 +1,11 @@
+-- Add data residency columns to tenants
+-- NULL data_region means the tenant lives in the default region (primary database)
+
+ALTER TABLE tenants ADD COLUMN data_region VARCHAR(50);
+ALTER TABLE tenants ADD COLUMN region_migration_started_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tenants_data_region ON tenants(data_region) WHERE data_region IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN tenants.data_region IS 'Region whose database holds this tenant''s data - NULL = default region';
+COMMENT ON COLUMN tenants.region_migration_started_at IS 'Set while the tenant is being moved between regions - tenant data access is refused';
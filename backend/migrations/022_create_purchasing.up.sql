@@ -0,0 +1,362 @@
+-- Create purchasing tables
+-- Suppliers, purchase orders, goods receipts and supplier invoices.
+-- Supplier invoices are three-way matched against the purchase order (ordered
+-- quantity and price) and goods receipts (received quantity).
+
+CREATE TABLE suppliers (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Basic Info
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50),             -- Internal vendor code
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    address TEXT,
+    tax_id VARCHAR(100),
+
+    -- Terms
+    payment_terms_days INT NOT NULL DEFAULT 30,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+
+    notes TEXT,
+
+    -- Status: active | inactive
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_supplier_name_per_tenant UNIQUE(tenant_id, name),
+    CONSTRAINT valid_supplier_status CHECK (status IN ('active', 'inactive'))
+);
+
+CREATE UNIQUE INDEX idx_suppliers_code ON suppliers(tenant_id, code) WHERE code IS NOT NULL;
+
+CREATE TABLE purchase_orders (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    po_number VARCHAR(50) NOT NULL,     -- e.g., PO-000001
+    sequence_number INT NOT NULL,
+    supplier_id UUID NOT NULL,
+
+    -- Status: draft | sent | partially_received | received | closed | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+
+    order_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    expected_date DATE,
+
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    subtotal DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tax_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total DECIMAL(15,2) NOT NULL DEFAULT 0,
+
+    notes TEXT,
+
+    -- Status timestamps
+    sent_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_po_number UNIQUE(tenant_id, po_number),
+    CONSTRAINT unique_po_sequence UNIQUE(tenant_id, sequence_number),
+    CONSTRAINT valid_po_status CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'closed', 'cancelled')),
+    FOREIGN KEY (tenant_id, supplier_id) REFERENCES suppliers(tenant_id, id) ON DELETE RESTRICT
+);
+
+CREATE TABLE purchase_order_lines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    purchase_order_id UUID NOT NULL,
+
+    line_number INT NOT NULL,
+    product_code VARCHAR(100),
+    description TEXT NOT NULL,
+
+    quantity DECIMAL(15,3) NOT NULL,
+    unit_price DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    line_total DECIMAL(15,2) NOT NULL DEFAULT 0,  -- Before tax
+
+    -- Running totals maintained by receiving and invoicing
+    quantity_received DECIMAL(15,3) NOT NULL DEFAULT 0,
+    quantity_invoiced DECIMAL(15,3) NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_po_line_number UNIQUE(tenant_id, purchase_order_id, line_number),
+    CONSTRAINT valid_po_line_quantity CHECK (quantity > 0),
+    FOREIGN KEY (tenant_id, purchase_order_id) REFERENCES purchase_orders(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE goods_receipts (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    purchase_order_id UUID NOT NULL,
+
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    received_by UUID,
+    reference VARCHAR(100),  -- Delivery note / packing slip number
+    notes TEXT,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, purchase_order_id) REFERENCES purchase_orders(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE goods_receipt_lines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    goods_receipt_id UUID NOT NULL,
+    purchase_order_line_id UUID NOT NULL,
+
+    quantity DECIMAL(15,3) NOT NULL,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_receipt_quantity CHECK (quantity > 0),
+    FOREIGN KEY (tenant_id, goods_receipt_id) REFERENCES goods_receipts(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, purchase_order_line_id) REFERENCES purchase_order_lines(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE supplier_invoices (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL,
+    purchase_order_id UUID NOT NULL,
+
+    invoice_number VARCHAR(100) NOT NULL,  -- Supplier's own invoice number
+    invoice_date DATE NOT NULL,
+    due_date DATE,
+
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    subtotal DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tax_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total DECIMAL(15,2) NOT NULL DEFAULT 0,
+
+    -- Three-way match result: matched | exception
+    match_status VARCHAR(20) NOT NULL,
+    match_details JSONB NOT NULL DEFAULT '[]',
+
+    -- Status: pending | approved | rejected
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    approved_at TIMESTAMPTZ,
+    approved_by UUID,
+
+    notes TEXT,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_supplier_invoice_number UNIQUE(tenant_id, supplier_id, invoice_number),
+    CONSTRAINT valid_match_status CHECK (match_status IN ('matched', 'exception')),
+    CONSTRAINT valid_supplier_invoice_status CHECK (status IN ('pending', 'approved', 'rejected')),
+    FOREIGN KEY (tenant_id, supplier_id) REFERENCES suppliers(tenant_id, id) ON DELETE RESTRICT,
+    FOREIGN KEY (tenant_id, purchase_order_id) REFERENCES purchase_orders(tenant_id, id) ON DELETE RESTRICT
+);
+
+CREATE TABLE supplier_invoice_lines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    supplier_invoice_id UUID NOT NULL,
+    purchase_order_line_id UUID NOT NULL,
+
+    quantity DECIMAL(15,3) NOT NULL,
+    unit_price DECIMAL(15,2) NOT NULL,
+    line_total DECIMAL(15,2) NOT NULL,
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, supplier_invoice_id) REFERENCES supplier_invoices(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, purchase_order_line_id) REFERENCES purchase_order_lines(tenant_id, id) ON DELETE RESTRICT
+);
+
+-- Indexes
+CREATE INDEX idx_suppliers_tenant ON suppliers(tenant_id);
+CREATE INDEX idx_suppliers_status ON suppliers(tenant_id, status);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(tenant_id, supplier_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(tenant_id, status);
+CREATE INDEX idx_purchase_orders_created_at ON purchase_orders(tenant_id, created_at DESC);
+CREATE INDEX idx_purchase_order_lines_po ON purchase_order_lines(tenant_id, purchase_order_id);
+CREATE INDEX idx_goods_receipts_po ON goods_receipts(tenant_id, purchase_order_id);
+CREATE INDEX idx_goods_receipt_lines_receipt ON goods_receipt_lines(tenant_id, goods_receipt_id);
+CREATE INDEX idx_supplier_invoices_po ON supplier_invoices(tenant_id, purchase_order_id);
+CREATE INDEX idx_supplier_invoices_status ON supplier_invoices(tenant_id, status, match_status);
+CREATE INDEX idx_supplier_invoice_lines_invoice ON supplier_invoice_lines(tenant_id, supplier_invoice_id);
+
+-- Enable RLS
+ALTER TABLE suppliers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE purchase_orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE purchase_order_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE goods_receipts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE goods_receipt_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE supplier_invoices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE supplier_invoice_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON suppliers
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON suppliers
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON purchase_orders
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON purchase_orders
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON purchase_order_lines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON purchase_order_lines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON goods_receipts
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON goods_receipts
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON goods_receipt_lines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON goods_receipt_lines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON supplier_invoices
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON supplier_invoices
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON supplier_invoice_lines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON supplier_invoice_lines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Triggers for updated_at
+CREATE TRIGGER update_suppliers_updated_at
+    BEFORE UPDATE ON suppliers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_purchase_orders_updated_at
+    BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_supplier_invoices_updated_at
+    BEFORE UPDATE ON supplier_invoices
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE suppliers IS 'Vendor records - RLS enforced';
+COMMENT ON TABLE purchase_orders IS 'Purchase orders sent to suppliers - RLS enforced';
+COMMENT ON TABLE goods_receipts IS 'Goods received against purchase orders - RLS enforced';
+COMMENT ON TABLE supplier_invoices IS 'Supplier invoices, three-way matched against PO and receipts - RLS enforced';
+COMMENT ON COLUMN supplier_invoices.match_details IS 'Per-line discrepancies found by three-way matching';
+
+-- Purchasing permissions
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('purchasing', 'view', 'View Purchasing', 'View suppliers, purchase orders and supplier invoices', 'Purchasing'),
+    ('purchasing', 'create', 'Create Purchasing Records', 'Create suppliers, purchase orders and supplier invoices', 'Purchasing'),
+    ('purchasing', 'edit', 'Edit Purchasing Records', 'Edit suppliers and draft purchase orders', 'Purchasing'),
+    ('purchasing', 'delete', 'Delete Purchasing Records', 'Delete suppliers and draft purchase orders', 'Purchasing'),
+    ('purchasing', 'manage_status', 'Manage Purchase Order Status', 'Send, close and cancel purchase orders', 'Purchasing'),
+    ('purchasing', 'receive', 'Receive Goods', 'Record goods receipts against purchase orders', 'Purchasing'),
+    ('purchasing', 'approve', 'Approve Supplier Invoices', 'Approve or reject matched supplier invoices', 'Purchasing'),
+    ('purchasing', '*', 'All Purchasing Permissions', 'Full purchasing access', 'Purchasing')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign purchasing permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'purchasing'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'manager' AND p.action IN ('view', 'create', 'edit', 'receive'))
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include purchasing for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view';  -- View-only permissions
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales and purchasing permissions';
@@ -0,0 +1,267 @@
+-- Create accounting tables
+-- Chart of accounts, posting periods and double-entry journal entries.
+-- Posted entries are immutable; corrections are made with reversing entries.
+
+CREATE TABLE accounts (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    code VARCHAR(20) NOT NULL,          -- e.g., 1000, 4000-01
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    -- Type: asset | liability | equity | revenue | expense
+    account_type VARCHAR(20) NOT NULL,
+    parent_id UUID,                     -- Optional grouping account
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_account_code UNIQUE(tenant_id, code),
+    CONSTRAINT valid_account_type CHECK (account_type IN ('asset', 'liability', 'equity', 'revenue', 'expense')),
+    FOREIGN KEY (tenant_id, parent_id) REFERENCES accounts(tenant_id, id) ON DELETE RESTRICT
+);
+
+CREATE TABLE accounting_periods (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(100) NOT NULL,         -- e.g., 2026-01, FY2026 Q1
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+
+    -- Status: open | closed
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    closed_at TIMESTAMPTZ,
+    closed_by UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_period_name UNIQUE(tenant_id, name),
+    CONSTRAINT valid_period_status CHECK (status IN ('open', 'closed')),
+    CONSTRAINT valid_period_dates CHECK (end_date >= start_date)
+);
+
+CREATE TABLE journal_entries (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    entry_number VARCHAR(50) NOT NULL,  -- e.g., JE-000001
+    sequence_number INT NOT NULL,
+    entry_date DATE NOT NULL,
+    period_id UUID,                     -- Set when posted
+
+    description TEXT NOT NULL,
+    reference VARCHAR(100),             -- External reference (invoice number, etc.)
+
+    -- Status: draft | posted
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    posted_at TIMESTAMPTZ,
+    posted_by UUID,
+
+    -- Reversals
+    reversal_of_id UUID,
+    reversed_by_id UUID,
+
+    total_debit DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_credit DECIMAL(15,2) NOT NULL DEFAULT 0,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_journal_entry_number UNIQUE(tenant_id, entry_number),
+    CONSTRAINT unique_journal_entry_sequence UNIQUE(tenant_id, sequence_number),
+    CONSTRAINT valid_journal_entry_status CHECK (status IN ('draft', 'posted')),
+    CONSTRAINT balanced_posted_entry CHECK (status != 'posted' OR total_debit = total_credit),
+    FOREIGN KEY (tenant_id, period_id) REFERENCES accounting_periods(tenant_id, id) ON DELETE RESTRICT,
+    FOREIGN KEY (tenant_id, reversal_of_id) REFERENCES journal_entries(tenant_id, id) ON DELETE RESTRICT,
+    FOREIGN KEY (tenant_id, reversed_by_id) REFERENCES journal_entries(tenant_id, id) ON DELETE SET NULL
+);
+
+CREATE TABLE journal_entry_lines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    journal_entry_id UUID NOT NULL,
+
+    line_number INT NOT NULL,
+    account_id UUID NOT NULL,
+    description TEXT,
+
+    debit DECIMAL(15,2) NOT NULL DEFAULT 0,
+    credit DECIMAL(15,2) NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_journal_line_number UNIQUE(tenant_id, journal_entry_id, line_number),
+    -- Each line is either a debit or a credit, never both
+    CONSTRAINT valid_journal_line_amounts CHECK (
+        debit >= 0 AND credit >= 0 AND (debit = 0) != (credit = 0)
+    ),
+    FOREIGN KEY (tenant_id, journal_entry_id) REFERENCES journal_entries(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, account_id) REFERENCES accounts(tenant_id, id) ON DELETE RESTRICT
+);
+
+-- Indexes
+CREATE INDEX idx_accounts_tenant ON accounts(tenant_id);
+CREATE INDEX idx_accounts_parent ON accounts(tenant_id, parent_id);
+CREATE INDEX idx_accounting_periods_dates ON accounting_periods(tenant_id, start_date, end_date);
+CREATE INDEX idx_journal_entries_date ON journal_entries(tenant_id, entry_date);
+CREATE INDEX idx_journal_entries_status ON journal_entries(tenant_id, status);
+CREATE INDEX idx_journal_entries_period ON journal_entries(tenant_id, period_id);
+CREATE INDEX idx_journal_entry_lines_entry ON journal_entry_lines(tenant_id, journal_entry_id);
+CREATE INDEX idx_journal_entry_lines_account ON journal_entry_lines(tenant_id, account_id);
+
+-- Enable RLS
+ALTER TABLE accounts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE accounting_periods ENABLE ROW LEVEL SECURITY;
+ALTER TABLE journal_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE journal_entry_lines ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON accounts
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON accounts
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON accounting_periods
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON accounting_periods
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON journal_entries
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON journal_entries
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON journal_entry_lines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON journal_entry_lines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Triggers for updated_at
+CREATE TRIGGER update_accounts_updated_at
+    BEFORE UPDATE ON accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_accounting_periods_updated_at
+    BEFORE UPDATE ON accounting_periods
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_journal_entries_updated_at
+    BEFORE UPDATE ON journal_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE accounts IS 'Chart of accounts - RLS enforced';
+COMMENT ON TABLE accounting_periods IS 'Posting periods; entries can only be posted into open periods - RLS enforced';
+COMMENT ON TABLE journal_entries IS 'Double-entry journal entries, immutable once posted - RLS enforced';
+COMMENT ON COLUMN journal_entries.reversal_of_id IS 'Posted entry this entry reverses';
+
+-- Accounting permissions
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('accounting', 'view', 'View Accounting', 'View chart of accounts, journal entries and trial balance', 'Accounting'),
+    ('accounting', 'create', 'Create Accounting Records', 'Create accounts and draft journal entries', 'Accounting'),
+    ('accounting', 'edit', 'Edit Accounting Records', 'Edit accounts and draft journal entries', 'Accounting'),
+    ('accounting', 'delete', 'Delete Accounting Records', 'Delete unused accounts and draft journal entries', 'Accounting'),
+    ('accounting', 'post', 'Post Journal Entries', 'Post and reverse journal entries', 'Accounting'),
+    ('accounting', 'manage_periods', 'Manage Posting Periods', 'Create, close and reopen posting periods', 'Accounting'),
+    ('accounting', '*', 'All Accounting Permissions', 'Full accounting access', 'Accounting')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign accounting permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'accounting'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'manager' AND p.action IN ('view', 'create', 'edit'))
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include accounting for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing', 'accounting')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view';  -- View-only permissions
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales, purchasing and accounting permissions';
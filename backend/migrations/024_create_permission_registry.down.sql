@@ -0,0 +1,9 @@
+-- Rollback RBAC resource registry
+
+ALTER TABLE permissions DROP CONSTRAINT IF EXISTS fk_permissions_resource;
+
+DROP TRIGGER IF EXISTS update_permission_resources_updated_at ON permission_resources;
+DROP TRIGGER IF EXISTS update_permission_modules_updated_at ON permission_modules;
+
+DROP TABLE IF EXISTS permission_resources;
+DROP TABLE IF EXISTS permission_modules;
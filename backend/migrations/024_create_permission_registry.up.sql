@@ -0,0 +1,78 @@
+-- Create RBAC resource registry
+-- Modules own resources; permissions (resource.action) must belong to a
+-- registered resource. New ERP modules register themselves in their own
+-- migration before inserting permissions:
+--
+--   INSERT INTO permission_modules (key, display_name, description, icon, sort_order)
+--   VALUES ('inventory', 'Inventory', 'Stock and warehouses', 'boxes', 60)
+--   ON CONFLICT (key) DO NOTHING;
+--
+--   INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order)
+--   VALUES ('inventory', 'inventory', 'Inventory', 'Items, stock levels and movements', 10)
+--   ON CONFLICT (resource) DO NOTHING;
+
+-- Registry tables are global catalogs (no RLS), like permissions
+CREATE TABLE permission_modules (
+    key VARCHAR(100) PRIMARY KEY,           -- e.g., sales, accounting
+    display_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    icon VARCHAR(100),                      -- Frontend icon name
+    sort_order INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE permission_resources (
+    resource VARCHAR(100) PRIMARY KEY,      -- Matches permissions.resource
+    module_key VARCHAR(100) NOT NULL REFERENCES permission_modules(key) ON DELETE RESTRICT,
+    display_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    sort_order INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_permission_resources_module ON permission_resources(module_key, sort_order);
+
+-- Triggers
+CREATE TRIGGER update_permission_modules_updated_at
+    BEFORE UPDATE ON permission_modules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_permission_resources_updated_at
+    BEFORE UPDATE ON permission_resources
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Seed existing modules
+INSERT INTO permission_modules (key, display_name, description, icon, sort_order) VALUES
+    ('administration', 'Administration', 'Users, roles, company settings and security', 'shield', 10),
+    ('organization', 'Organization', 'Company structure', 'building', 20),
+    ('sales', 'Sales', 'Quotes, orders and invoices', 'shopping-cart', 30),
+    ('purchasing', 'Purchasing', 'Suppliers, purchase orders and supplier invoices', 'truck', 40),
+    ('accounting', 'Accounting', 'General ledger, posting periods and financial reports', 'calculator', 50)
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('users', 'administration', 'Users', 'User accounts and their status', 10),
+    ('roles', 'administration', 'Roles', 'Roles and role assignments', 20),
+    ('settings', 'administration', 'Settings', 'Company settings', 30),
+    ('security', 'administration', 'Security', 'Audit logs and sessions', 40),
+    ('departments', 'organization', 'Departments', 'Departments and their members', 10),
+    ('sales', 'sales', 'Sales Documents', 'Quotes, sales orders and invoices', 10),
+    ('purchasing', 'purchasing', 'Purchasing', 'Suppliers, purchase orders, receipts and supplier invoices', 10),
+    ('accounting', 'accounting', 'Accounting', 'Chart of accounts, journal entries and posting periods', 10)
+ON CONFLICT (resource) DO NOTHING;
+
+-- Every permission must now belong to a registered resource
+ALTER TABLE permissions
+    ADD CONSTRAINT fk_permissions_resource
+    FOREIGN KEY (resource) REFERENCES permission_resources(resource) ON DELETE RESTRICT;
+
+-- Comments
+COMMENT ON TABLE permission_modules IS 'RBAC registry - ERP modules, used to group resources in the UI';
+COMMENT ON TABLE permission_resources IS 'RBAC registry - resources that permissions can be granted on';
+COMMENT ON COLUMN permission_modules.icon IS 'Frontend icon name for navigation and role editors';
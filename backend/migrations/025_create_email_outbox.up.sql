@@ -0,0 +1,52 @@
+-- Create email outbox
+-- Emails are inserted in the same transaction as the change that triggers
+-- them and delivered by a background worker with exponential backoff, so a
+-- failing SMTP server no longer loses messages.
+
+CREATE TABLE email_outbox (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    to_email VARCHAR(255) NOT NULL,
+    subject VARCHAR(500) NOT NULL,
+    body TEXT NOT NULL,
+    template VARCHAR(100),              -- e.g., invitation, password_reset
+
+    -- Delivery: pending -> sent | failed (attempts exhausted)
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_email_outbox_status CHECK (status IN ('pending', 'sent', 'failed'))
+);
+
+-- Worker polls due messages across tenants
+CREATE INDEX idx_email_outbox_due ON email_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_email_outbox_tenant_status ON email_outbox(tenant_id, status, created_at DESC);
+
+-- Triggers
+CREATE TRIGGER update_email_outbox_updated_at
+    BEFORE UPDATE ON email_outbox
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE email_outbox ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON email_outbox
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON email_outbox
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE email_outbox IS 'Transactional outbox for outgoing emails - delivered by the email worker with retry';
+COMMENT ON COLUMN email_outbox.next_attempt_at IS 'Earliest time of the next delivery attempt (exponential backoff)';
@@ -0,0 +1,145 @@
+-- Create tenant sandboxes
+-- A sandbox is a separate tenant holding a copy of another tenant's data, used
+-- to try configuration changes safely. The sandbox tenant row points at its
+-- source (tenants.sandbox_of) and is deleted automatically once it expires.
+-- The clone itself runs in the background (tenant_sandboxes tracks its state).
+
+ALTER TABLE tenants ADD COLUMN sandbox_of UUID REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE tenants ADD COLUMN sandbox_expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tenants_sandbox_of ON tenants(sandbox_of) WHERE sandbox_of IS NOT NULL;
+
+-- Catalog table (no RLS, like tenants). It deliberately has no tenant_id
+-- column so that clones and region moves do not copy it.
+CREATE TABLE tenant_sandboxes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sandbox_tenant_id UUID REFERENCES tenants(id) ON DELETE SET NULL,
+
+    name VARCHAR(100) NOT NULL,
+    anonymize BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Status: pending -> cloning -> ready | failed; deleted once expired or removed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error TEXT,
+
+    expires_at TIMESTAMPTZ NOT NULL,
+    requested_by UUID NOT NULL,  -- References users(id) in the source tenant
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+
+    CONSTRAINT valid_sandbox_status CHECK (status IN ('pending', 'cloning', 'ready', 'failed', 'deleted'))
+);
+
+-- Indexes
+CREATE INDEX idx_tenant_sandboxes_source ON tenant_sandboxes(source_tenant_id, created_at DESC);
+CREATE INDEX idx_tenant_sandboxes_pending ON tenant_sandboxes(created_at) WHERE status IN ('pending', 'cloning');
+CREATE INDEX idx_tenant_sandboxes_expires ON tenant_sandboxes(expires_at) WHERE status <> 'deleted';
+
+-- Triggers
+CREATE TRIGGER update_tenant_sandboxes_updated_at
+    BEFORE UPDATE ON tenant_sandboxes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON COLUMN tenants.sandbox_of IS 'Source tenant when this tenant is a sandbox - NULL for production tenants';
+COMMENT ON COLUMN tenants.sandbox_expires_at IS 'Sandbox tenants are refused access and deleted after this time';
+COMMENT ON TABLE tenant_sandboxes IS 'Sandbox clone requests and their lifecycle - catalog table, no RLS';
+
+-- Sandbox permissions
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('sandboxes', 'administration', 'Sandboxes', 'Sandbox copies of the company data for testing', 50)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('sandboxes', 'view', 'View Sandboxes', 'View sandbox environments and their status', 'Administration'),
+    ('sandboxes', 'create', 'Create Sandboxes', 'Clone company data into a new sandbox', 'Administration'),
+    ('sandboxes', 'delete', 'Delete Sandboxes', 'Delete sandbox environments before they expire', 'Administration'),
+    ('sandboxes', '*', 'All Sandbox Permissions', 'Full sandbox access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign sandbox permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'sandboxes'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include sandboxes for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing', 'accounting', 'sandboxes')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view';  -- View-only permissions
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales, purchasing, accounting and sandbox permissions';
@@ -0,0 +1,3 @@
+-- Rollback pending deletions
+
+DROP TABLE IF EXISTS pending_deletions CASCADE;
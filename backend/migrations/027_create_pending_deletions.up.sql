@@ -0,0 +1,57 @@
+-- Create pending deletions
+-- Deletes of users, roles, departments and documents are staged here for an
+-- undo window instead of running immediately. The deletion worker executes
+-- them permanently once execute_at has passed, unless they were undone.
+
+CREATE TABLE pending_deletions (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- What is being deleted
+    entity_type VARCHAR(50) NOT NULL,   -- user, role, department, sales_document, purchase_order
+    entity_id UUID NOT NULL,
+    label VARCHAR(255) NOT NULL,        -- Human-readable name shown in notifications
+
+    -- Lifecycle: pending -> executed | undone | failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    execute_at TIMESTAMPTZ NOT NULL,
+
+    requested_by UUID NOT NULL,
+    undone_by UUID,
+    undone_at TIMESTAMPTZ,
+    executed_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_pending_deletion_status CHECK (status IN ('pending', 'executed', 'undone', 'failed'))
+);
+
+-- An entity can only be staged for deletion once at a time
+CREATE UNIQUE INDEX idx_pending_deletions_entity ON pending_deletions(tenant_id, entity_type, entity_id) WHERE status = 'pending';
+
+-- Worker polls due deletions across tenants
+CREATE INDEX idx_pending_deletions_due ON pending_deletions(execute_at) WHERE status = 'pending';
+CREATE INDEX idx_pending_deletions_requested_by ON pending_deletions(tenant_id, requested_by, created_at DESC);
+
+-- Triggers
+CREATE TRIGGER update_pending_deletions_updated_at
+    BEFORE UPDATE ON pending_deletions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE pending_deletions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON pending_deletions
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON pending_deletions
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE pending_deletions IS 'Deletes staged for an undo window - executed by the deletion worker once due';
+COMMENT ON COLUMN pending_deletions.execute_at IS 'End of the undo window; the deletion becomes permanent after this time';
@@ -0,0 +1,203 @@
+-- Create email broadcasts
+-- Admins compose an email to all or a filtered set of the tenant's users.
+-- Recipients are resolved when the broadcast is created and handed to the
+-- email outbox by the broadcast worker a batch at a time (throttling).
+-- Delivery status per recipient comes from the outbox row it was queued as.
+
+CREATE TABLE email_broadcasts (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Content: Go templates rendered per recipient
+    subject VARCHAR(500) NOT NULL,
+    body TEXT NOT NULL,
+    variables JSONB NOT NULL DEFAULT '{}',  -- Custom template variables
+    filter JSONB NOT NULL DEFAULT '{}',     -- Recipient filter (role_ids, department_ids, statuses)
+
+    -- Lifecycle: sending -> completed | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'sending',
+    total_recipients INT NOT NULL DEFAULT 0,
+
+    created_by UUID NOT NULL,
+    completed_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_email_broadcast_status CHECK (status IN ('sending', 'completed', 'cancelled'))
+);
+
+CREATE INDEX idx_email_broadcasts_tenant_created ON email_broadcasts(tenant_id, created_at DESC);
+
+CREATE TABLE email_broadcast_recipients (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    broadcast_id UUID NOT NULL,
+
+    -- Snapshot of the recipient when the broadcast was created
+    user_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+
+    -- pending -> queued (handed to the outbox) | skipped (opted out) | failed (render error) | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    skip_reason TEXT,
+    outbox_email_id UUID,
+    queued_at TIMESTAMPTZ,
+
+    -- Used in the unsubscribe link of this recipient's email
+    unsubscribe_token UUID NOT NULL DEFAULT uuid_generate_v4(),
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, broadcast_id) REFERENCES email_broadcasts(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT unique_broadcast_recipient UNIQUE (tenant_id, broadcast_id, user_id),
+    CONSTRAINT valid_broadcast_recipient_status CHECK (status IN ('pending', 'queued', 'skipped', 'failed', 'cancelled'))
+);
+
+-- Worker polls pending recipients across tenants
+CREATE INDEX idx_broadcast_recipients_pending ON email_broadcast_recipients(created_at) WHERE status = 'pending';
+CREATE INDEX idx_broadcast_recipients_broadcast ON email_broadcast_recipients(tenant_id, broadcast_id, status);
+CREATE UNIQUE INDEX idx_broadcast_recipients_unsubscribe ON email_broadcast_recipients(unsubscribe_token);
+
+-- Users who unsubscribed from broadcasts; never emailed by later broadcasts
+CREATE TABLE email_opt_outs (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    opted_out_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    broadcast_id UUID,                  -- Broadcast whose link was used
+
+    PRIMARY KEY (tenant_id, user_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+-- Triggers
+CREATE TRIGGER update_email_broadcasts_updated_at
+    BEFORE UPDATE ON email_broadcasts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE email_broadcasts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE email_broadcast_recipients ENABLE ROW LEVEL SECURITY;
+ALTER TABLE email_opt_outs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON email_broadcasts
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON email_broadcasts
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON email_broadcast_recipients
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON email_broadcast_recipients
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON email_opt_outs
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON email_opt_outs
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE email_broadcasts IS 'Emails composed by admins for all or a filtered set of users';
+COMMENT ON TABLE email_broadcast_recipients IS 'Per-recipient state of a broadcast - handed to the email outbox in throttled batches';
+COMMENT ON TABLE email_opt_outs IS 'Users who unsubscribed from broadcasts';
+
+-- Register broadcasts in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('broadcasts', 'administration', 'Email Broadcasts', 'Emails sent to all or a filtered set of users', 60)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('broadcasts', 'view', 'View Broadcasts', 'View email broadcasts and their delivery status', 'Administration'),
+    ('broadcasts', 'create', 'Send Broadcasts', 'Compose and send emails to users', 'Administration'),
+    ('broadcasts', 'cancel', 'Cancel Broadcasts', 'Stop a broadcast that is still sending', 'Administration'),
+    ('broadcasts', '*', 'All Broadcast Permissions', 'Full broadcast access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign broadcast permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'broadcasts'
+  AND (
+       r.name = 'owner'
+    OR r.name = 'admin'
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include broadcasts for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view';  -- View-only permissions
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales, purchasing, accounting, sandbox and broadcast permissions';
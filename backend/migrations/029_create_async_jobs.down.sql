@@ -0,0 +1,3 @@
+-- Rollback async jobs
+
+DROP TABLE IF EXISTS async_jobs CASCADE;
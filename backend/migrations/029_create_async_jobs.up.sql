@@ -0,0 +1,60 @@
+-- Create async jobs
+-- Long-running work (imports, exports, reports, archival) is queued here and
+-- executed by the async job worker, which records progress, the result
+-- payload and errors on the row. Users poll GET /jobs/{id} or stream
+-- progress events until the job finishes.
+
+CREATE TABLE async_jobs (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- What to run
+    job_type VARCHAR(50) NOT NULL,      -- Registered by the module that owns the work
+    params JSONB NOT NULL DEFAULT '{}',
+
+    -- Lifecycle: queued -> running -> succeeded | failed | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    progress INT NOT NULL DEFAULT 0,
+    progress_message VARCHAR(255),
+    result JSONB,
+    error TEXT,
+    cancel_requested BOOLEAN NOT NULL DEFAULT false,
+
+    requested_by UUID NOT NULL,
+    cancelled_by UUID,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_async_job_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    CONSTRAINT valid_async_job_progress CHECK (progress BETWEEN 0 AND 100)
+);
+
+-- Worker claims queued jobs across tenants, oldest first
+CREATE INDEX idx_async_jobs_queued ON async_jobs(created_at) WHERE status = 'queued';
+CREATE INDEX idx_async_jobs_running ON async_jobs(status) WHERE status = 'running';
+CREATE INDEX idx_async_jobs_requested_by ON async_jobs(tenant_id, requested_by, created_at DESC);
+CREATE INDEX idx_async_jobs_completed ON async_jobs(completed_at) WHERE completed_at IS NOT NULL;
+
+-- Triggers
+CREATE TRIGGER update_async_jobs_updated_at
+    BEFORE UPDATE ON async_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE async_jobs ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON async_jobs
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON async_jobs
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE async_jobs IS 'Long-running background jobs with progress, result payload and cancellation';
+COMMENT ON COLUMN async_jobs.cancel_requested IS 'Set when a user cancels a running job; the worker stops it at its next check';
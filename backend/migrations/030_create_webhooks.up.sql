@@ -0,0 +1,187 @@
+-- Create webhooks
+-- Tenants register HTTPS endpoints and the event types they care about
+-- (user.created, invitation.accepted, role.assigned, ...). Every matching
+-- event is queued as a delivery and posted by the webhook worker with an
+-- HMAC signature, retrying with exponential backoff like the email outbox.
+
+CREATE TABLE webhooks (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    url VARCHAR(2048) NOT NULL,
+    description VARCHAR(255),
+    event_types TEXT[] NOT NULL,        -- Event types delivered, '*' for all
+    secret TEXT NOT NULL,               -- Signing secret (AES-256 encrypted)
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT webhook_has_event_types CHECK (cardinality(event_types) > 0)
+);
+
+CREATE INDEX idx_webhooks_tenant_active ON webhooks(tenant_id) WHERE is_active = true;
+
+CREATE TABLE webhook_deliveries (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    webhook_id UUID NOT NULL,
+
+    -- The event (one event is delivered once per subscribed webhook)
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+
+    -- Delivery: pending -> delivered | failed (attempts exhausted)
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    response_status INT,
+    response_body TEXT,                 -- Truncated
+    duration_ms INT,
+    delivered_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, webhook_id) REFERENCES webhooks(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT valid_webhook_delivery_status CHECK (status IN ('pending', 'delivered', 'failed'))
+);
+
+-- Worker polls due deliveries across tenants
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(tenant_id, webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_finished ON webhook_deliveries(updated_at) WHERE status != 'pending';
+
+-- Triggers
+CREATE TRIGGER update_webhooks_updated_at
+    BEFORE UPDATE ON webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_webhook_deliveries_updated_at
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON webhooks
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON webhooks
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON webhook_deliveries
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON webhook_deliveries
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE webhooks IS 'Tenant-registered endpoints notified of events for integrations';
+COMMENT ON TABLE webhook_deliveries IS 'Delivery log and outbox for webhook events - posted by the webhook worker with retry';
+COMMENT ON COLUMN webhooks.secret IS 'HMAC-SHA256 signing secret, encrypted with ENCRYPTION_KEY';
+
+-- Register webhooks in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('webhooks', 'administration', 'Webhooks', 'Endpoints notified of events for integrations', 70)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('webhooks', 'view', 'View Webhooks', 'View webhooks and their delivery log', 'Administration'),
+    ('webhooks', 'create', 'Create Webhooks', 'Register webhook endpoints', 'Administration'),
+    ('webhooks', 'edit', 'Edit Webhooks', 'Change, test and rotate secrets of webhooks; retry deliveries', 'Administration'),
+    ('webhooks', 'delete', 'Delete Webhooks', 'Remove webhook endpoints', 'Administration'),
+    ('webhooks', '*', 'All Webhook Permissions', 'Full webhook access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign webhook permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'webhooks'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include webhooks for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource != 'webhooks';  -- Integration endpoints are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales, purchasing, accounting, sandbox, broadcast and webhook permissions';
@@ -0,0 +1,12 @@
+-- Rollback user soft delete
+-- Soft-deleted users are removed, as they were before soft deletes existed
+
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_users_unique_email;
+ALTER TABLE users ADD CONSTRAINT unique_email_per_tenant UNIQUE(tenant_id, email);
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,18 @@
+-- Soft-deleted users
+-- Deleting a user keeps the row (and its audit history, documents and role
+-- assignments) and stamps deleted_at. Deleted users cannot sign in and are
+-- hidden from lists; they can be restored until the purge job removes them
+-- after the retention period (DELETION_USER_RETENTION).
+
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by UUID;
+
+-- A deleted user's email can be reused by a new account
+ALTER TABLE users DROP CONSTRAINT unique_email_per_tenant;
+CREATE UNIQUE INDEX idx_users_unique_email ON users(tenant_id, email) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN users.deleted_at IS 'Set when the user is deleted; NULL for live users';
+COMMENT ON COLUMN users.deleted_by IS 'User who deleted the account';
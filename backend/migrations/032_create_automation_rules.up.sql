@@ -0,0 +1,188 @@
+-- Create automation rules
+-- Admins define if-this-then-that rules per tenant: a trigger (an event type
+-- published on the event bus, e.g. user.created) with conditions on the
+-- changed resource, and actions (send a notification, assign a role, call a
+-- webhook). Matching events are queued as executions and run by the
+-- automation worker, which records the outcome of every action.
+
+CREATE TABLE automation_rules (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    trigger_event VARCHAR(100) NOT NULL,
+    conditions JSONB NOT NULL DEFAULT '[]'::jsonb,  -- All must match
+    actions JSONB NOT NULL,                         -- Run in order
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+
+    last_triggered_at TIMESTAMPTZ,
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_automation_rule_name UNIQUE(tenant_id, name),
+    CONSTRAINT automation_rule_has_actions CHECK (jsonb_array_length(actions) > 0)
+);
+
+CREATE INDEX idx_automation_rules_trigger ON automation_rules(tenant_id, trigger_event) WHERE is_enabled = true;
+
+CREATE TABLE automation_executions (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    rule_id UUID NOT NULL,
+
+    -- The event that triggered the rule
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    event JSONB NOT NULL,
+
+    -- Execution: pending -> succeeded | failed (one or more actions failed)
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    action_results JSONB,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, rule_id) REFERENCES automation_rules(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT valid_automation_execution_status CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+-- Worker polls pending executions across tenants
+CREATE INDEX idx_automation_executions_pending ON automation_executions(created_at) WHERE status = 'pending';
+CREATE INDEX idx_automation_executions_rule ON automation_executions(tenant_id, rule_id, created_at DESC);
+CREATE INDEX idx_automation_executions_tenant ON automation_executions(tenant_id, created_at DESC);
+CREATE INDEX idx_automation_executions_finished ON automation_executions(finished_at) WHERE status != 'pending';
+
+-- Triggers
+CREATE TRIGGER update_automation_rules_updated_at
+    BEFORE UPDATE ON automation_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_automation_executions_updated_at
+    BEFORE UPDATE ON automation_executions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE automation_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE automation_executions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON automation_rules
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON automation_rules
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON automation_executions
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON automation_executions
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE automation_rules IS 'Per-tenant if-this-then-that rules evaluated on published events';
+COMMENT ON TABLE automation_executions IS 'Execution log and queue of triggered automation rules - run by the automation worker';
+COMMENT ON COLUMN automation_rules.conditions IS 'Array of {field, operator, value} tested against the changed resource';
+COMMENT ON COLUMN automation_rules.actions IS 'Array of actions ({type, ...params}) run in order';
+
+-- Register automation in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('automation', 'administration', 'Automation', 'If-this-then-that rules run on events', 75)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('automation', 'view', 'View Automation Rules', 'View automation rules and their execution log', 'Administration'),
+    ('automation', 'create', 'Create Automation Rules', 'Define automation rules', 'Administration'),
+    ('automation', 'edit', 'Edit Automation Rules', 'Change, enable and disable automation rules', 'Administration'),
+    ('automation', 'delete', 'Delete Automation Rules', 'Remove automation rules', 'Administration'),
+    ('automation', '*', 'All Automation Permissions', 'Full automation access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign automation permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'automation'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include automation for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation');  -- Integrations and automation are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, sales, purchasing, accounting, sandbox, broadcast, webhook and automation permissions';
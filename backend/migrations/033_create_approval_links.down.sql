@@ -0,0 +1,3 @@
+-- Rollback approval links
+
+DROP TABLE IF EXISTS approval_links CASCADE;
@@ -0,0 +1,54 @@
+-- Create approval links
+-- Approvers are emailed signed, one-time Approve/Reject links so they can
+-- decide from their inbox without logging in. Each link belongs to one
+-- approver and one approval (e.g. a supplier invoice); using it, or the
+-- approval being decided another way, makes it unusable.
+
+CREATE TABLE approval_links (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- What is being approved, and by whom
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id UUID NOT NULL,
+    approver_id UUID NOT NULL,
+
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    -- Use of the link
+    used_at TIMESTAMPTZ,
+    decision VARCHAR(20),
+    step_up VARCHAR(20),               -- Verification required to use it: none | totp
+    used_ip INET,
+    used_user_agent TEXT,
+    failed_attempts INT NOT NULL DEFAULT 0,   -- Wrong verification codes
+    revoked_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_approval_link_decision CHECK (decision IS NULL OR decision IN ('approve', 'reject'))
+);
+
+CREATE INDEX idx_approval_links_resource ON approval_links(tenant_id, resource_type, resource_id);
+CREATE INDEX idx_approval_links_expires ON approval_links(expires_at);
+
+-- Triggers
+CREATE TRIGGER update_approval_links_updated_at
+    BEFORE UPDATE ON approval_links
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE approval_links ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON approval_links
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON approval_links
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE approval_links IS 'One-time Approve/Reject links emailed to approvers - the token is the link ID signed with ENCRYPTION_KEY';
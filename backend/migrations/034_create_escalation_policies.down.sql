@@ -0,0 +1,4 @@
+-- Rollback escalation policies
+
+DROP TABLE IF EXISTS escalations CASCADE;
+DROP TABLE IF EXISTS escalation_policies CASCADE;
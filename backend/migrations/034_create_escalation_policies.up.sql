@@ -0,0 +1,94 @@
+-- Create escalation policies
+-- Admins define escalation chains per tenant for approvals that stay
+-- unanswered (e.g. supplier invoices): after N hours pending, notify the
+-- requester's manager, a role or a user by email and/or Slack. The
+-- escalation worker checks pending approvals periodically and logs every
+-- step that fired; the log is shown with the approval.
+
+CREATE TABLE escalation_policies (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    resource_type VARCHAR(50) NOT NULL,             -- What is escalated, e.g. supplier_invoice
+    steps JSONB NOT NULL,                           -- In escalation order
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Slack incoming webhook for steps posting to Slack (encrypted)
+    slack_webhook_url TEXT,
+    slack_configured BOOLEAN GENERATED ALWAYS AS (slack_webhook_url IS NOT NULL) STORED,
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_escalation_policy_name UNIQUE(tenant_id, name),
+    CONSTRAINT escalation_policy_has_steps CHECK (jsonb_array_length(steps) > 0)
+);
+
+-- Worker polls enabled policies across tenants
+CREATE INDEX idx_escalation_policies_enabled ON escalation_policies(resource_type) WHERE is_enabled = true;
+
+CREATE TABLE escalations (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    policy_id UUID NOT NULL,
+
+    -- The pending approval
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id UUID NOT NULL,
+    title VARCHAR(500) NOT NULL,
+
+    -- The step that fired, as defined when it fired
+    step INTEGER NOT NULL,
+    after_hours INTEGER NOT NULL,
+    target VARCHAR(20) NOT NULL,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    recipients TEXT[] NOT NULL DEFAULT '{}',  -- Emails notified
+
+    -- Slack post: sent | failed, NULL when the step does not post to Slack
+    slack_status VARCHAR(20),
+    slack_error TEXT,
+
+    escalated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, policy_id) REFERENCES escalation_policies(tenant_id, id) ON DELETE CASCADE,
+    -- Each step fires once per approval
+    CONSTRAINT unique_escalation_step UNIQUE(tenant_id, policy_id, resource_type, resource_id, step),
+    CONSTRAINT valid_escalation_slack_status CHECK (slack_status IS NULL OR slack_status IN ('sent', 'failed'))
+);
+
+CREATE INDEX idx_escalations_resource ON escalations(tenant_id, resource_type, resource_id, escalated_at);
+CREATE INDEX idx_escalations_policy ON escalations(tenant_id, policy_id, escalated_at DESC);
+
+-- Triggers
+CREATE TRIGGER update_escalation_policies_updated_at
+    BEFORE UPDATE ON escalation_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE escalation_policies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE escalations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON escalation_policies
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON escalation_policies
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON escalations
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON escalations
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE escalation_policies IS 'Per-tenant escalation chains for approvals left unanswered - managed with the automation permissions';
+COMMENT ON TABLE escalations IS 'Escalation log: steps of a policy that fired for a pending approval - written by the escalation worker';
+COMMENT ON COLUMN escalation_policies.steps IS 'Array of {after_hours, target (manager|role|user), role_id, user_id, channels (email|slack)}';
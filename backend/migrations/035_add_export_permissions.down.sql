@@ -0,0 +1,2 @@
+-- Remove export permissions (cascades to role_permissions)
+DELETE FROM permissions WHERE resource IN ('users', 'security') AND action = 'export';
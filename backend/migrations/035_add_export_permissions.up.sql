@@ -0,0 +1,23 @@
+-- Add export permissions for users and audit logs
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('users', 'export', 'Export Users', 'Download the user list as CSV, XLSX or JSON', 'User Management'),
+    ('security', 'export', 'Export Audit Logs', 'Download audit logs as CSV, XLSX or JSON', 'Security')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign export permissions to existing system roles. New tenants get them
+-- from provision_tenant_system_roles: owners have every permission and admins
+-- every users permission but delete.
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.action = 'export'
+  AND (
+       (r.name = 'owner' AND p.resource IN ('users', 'security'))
+    OR (r.name = 'admin' AND p.resource = 'users')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
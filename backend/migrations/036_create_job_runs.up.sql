@@ -0,0 +1,28 @@
+-- Create background job run records
+-- One row per background job (internal/jobs), shared by every replica: the
+-- replica that runs a scheduled occurrence claims it here first, so replicas
+-- never run the same occurrence twice, then records how the run went. The
+-- admin jobs endpoint lists the rows with each job's schedule.
+
+-- Jobs are cluster-wide (no tenant, no RLS), like the permission catalog
+CREATE TABLE job_runs (
+    name VARCHAR(100) PRIMARY KEY,          -- Job name, e.g. session_cleanup
+
+    -- Last run
+    last_due_at TIMESTAMPTZ NOT NULL,       -- Scheduled time of the occurrence
+    last_started_at TIMESTAMPTZ NOT NULL,
+    last_finished_at TIMESTAMPTZ,
+    last_status VARCHAR(20) NOT NULL,       -- running | succeeded | failed
+    last_duration_ms BIGINT,
+    last_processed INT,                     -- Items the run processed
+
+    -- Totals since the job first ran
+    runs BIGINT NOT NULL DEFAULT 0,
+    failures BIGINT NOT NULL DEFAULT 0,
+    processed BIGINT NOT NULL DEFAULT 0,
+
+    CONSTRAINT valid_job_run_status CHECK (last_status IN ('running', 'succeeded', 'failed'))
+);
+
+COMMENT ON TABLE job_runs IS 'Last run and totals of each background job - cluster-wide, no RLS';
+COMMENT ON COLUMN job_runs.last_due_at IS 'Occurrence claimed by the last run; later claims must be for a later occurrence';
@@ -0,0 +1,3 @@
+-- Rollback record watches
+
+DROP TABLE IF EXISTS record_watches;
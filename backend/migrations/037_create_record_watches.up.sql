@@ -0,0 +1,40 @@
+-- Create record watches
+-- Users watch records (a department, a sales order...) to be notified when
+-- someone else updates or deletes them. Users automatically watch the
+-- records they create or are assigned to.
+
+CREATE TABLE record_watches (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+
+    -- Watched record, e.g. department or sales_document
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+
+    -- Why the user watches the record: manual, created or assigned
+    reason VARCHAR(20) NOT NULL DEFAULT 'manual',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id, entity_type, entity_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT valid_watch_reason CHECK (reason IN ('manual', 'created', 'assigned'))
+);
+
+-- Changes to a record notify its watchers
+CREATE INDEX idx_record_watches_entity ON record_watches(tenant_id, entity_type, entity_id);
+
+-- Users list the records they watch, most recent first
+CREATE INDEX idx_record_watches_user ON record_watches(tenant_id, user_id, created_at DESC);
+
+-- Row-Level Security
+ALTER TABLE record_watches ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON record_watches
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON record_watches
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE record_watches IS 'Records users are notified of changes to';
@@ -0,0 +1,3 @@
+-- Rollback recently viewed entities and favorites
+DROP TABLE IF EXISTS favorites;
+DROP TABLE IF EXISTS recent_views;
@@ -0,0 +1,52 @@
+-- Create recently viewed entities and favorites
+-- Views of entity pages are tracked per user in Redis (the last few
+-- entities, most recent first) and copied here periodically, so the list
+-- survives a Redis flush. Favorites are entities a user pinned. Both power
+-- quick navigation.
+
+CREATE TABLE recent_views (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,       -- e.g. user, sales_document
+    entity_id UUID NOT NULL,
+    viewed_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (tenant_id, user_id, entity_type, entity_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE favorites (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_favorite UNIQUE(tenant_id, user_id, entity_type, entity_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_favorites_user ON favorites(tenant_id, user_id, created_at);
+
+-- Row-Level Security
+ALTER TABLE recent_views ENABLE ROW LEVEL SECURITY;
+ALTER TABLE favorites ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON recent_views
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON recent_views
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON favorites
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON favorites
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE recent_views IS 'Recently viewed entities per user - copied from Redis by the recent_views_flush job';
+COMMENT ON TABLE favorites IS 'Entities pinned by users for quick navigation';
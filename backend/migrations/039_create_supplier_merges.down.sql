@@ -0,0 +1,2 @@
+-- Rollback supplier merges
+DROP TABLE IF EXISTS supplier_merges;
@@ -0,0 +1,37 @@
+-- Create supplier merges
+-- Duplicate suppliers are merged into the one that is kept: their purchase
+-- orders and supplier invoices are moved over and the duplicate is deleted.
+-- Each merge is recorded with the deleted supplier as it was.
+
+CREATE TABLE supplier_merges (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL,                -- Supplier kept
+    source_id UUID NOT NULL,                -- Supplier merged and deleted (no FK: it no longer exists)
+
+    source_name VARCHAR(255) NOT NULL,
+    source JSONB NOT NULL,                  -- The deleted supplier's record
+
+    purchase_orders_moved INT NOT NULL DEFAULT 0,
+    supplier_invoices_moved INT NOT NULL DEFAULT 0,
+
+    merged_by UUID,
+    merged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, target_id) REFERENCES suppliers(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_supplier_merges_target ON supplier_merges(tenant_id, target_id, merged_at DESC);
+
+-- Row-Level Security
+ALTER TABLE supplier_merges ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON supplier_merges
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON supplier_merges
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE supplier_merges IS 'Duplicate suppliers merged into another supplier';
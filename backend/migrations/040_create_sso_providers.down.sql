@@ -0,0 +1,3 @@
+-- Rollback single sign-on providers and identities
+DROP TABLE IF EXISTS sso_identities;
+DROP TABLE IF EXISTS sso_providers;
@@ -0,0 +1,78 @@
+-- Create single sign-on providers and identities
+-- Tenants configure OpenID Connect providers (Google Workspace, Microsoft
+-- Entra ID, Okta, ...) their users sign in with. Identities link a
+-- provider's subject to a user; users are linked by email on their first
+-- sign-on, or created when the provider provisions users automatically.
+-- Whether a tenant allows password logins at all is the sso_required key of
+-- tenants.settings.
+
+CREATE TABLE sso_providers (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    slug VARCHAR(50) NOT NULL,              -- Used in the login URL, e.g. google
+    name VARCHAR(100) NOT NULL,             -- Shown on the login page
+
+    issuer_url TEXT NOT NULL,               -- OpenID issuer, discovered via /.well-known/openid-configuration
+    client_id TEXT NOT NULL,
+    client_secret_encrypted TEXT,           -- AES-256-GCM; NULL for public clients
+    scopes VARCHAR(255) NOT NULL DEFAULT 'openid email profile',
+
+    allowed_domains TEXT[] NOT NULL DEFAULT '{}', -- Email domains that may sign in; empty = any
+    auto_provision BOOLEAN NOT NULL DEFAULT FALSE, -- Create users signing in for the first time
+    default_role_id UUID,                   -- Role given to provisioned users
+
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_sso_provider_slug UNIQUE(tenant_id, slug)
+);
+
+CREATE TABLE sso_identities (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    provider_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    subject VARCHAR(255) NOT NULL,          -- The provider's "sub" claim
+    email VARCHAR(255) NOT NULL,            -- Email at the last sign-on
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_sso_identity UNIQUE(tenant_id, provider_id, subject),
+    CONSTRAINT unique_sso_identity_user UNIQUE(tenant_id, provider_id, user_id),
+    FOREIGN KEY (tenant_id, provider_id) REFERENCES sso_providers(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sso_identities_user ON sso_identities(tenant_id, user_id);
+
+-- Updated at trigger
+CREATE TRIGGER update_sso_providers_updated_at
+    BEFORE UPDATE ON sso_providers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE sso_providers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sso_identities ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON sso_providers
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON sso_providers
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON sso_identities
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON sso_identities
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE sso_providers IS 'OpenID Connect providers tenants sign in with';
+COMMENT ON TABLE sso_identities IS 'Provider identities linked to users';
@@ -0,0 +1,2 @@
+-- Rollback data quality rules
+DROP TABLE IF EXISTS data_quality_rules CASCADE;
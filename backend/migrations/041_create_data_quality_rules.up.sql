@@ -0,0 +1,54 @@
+-- Create data quality rules
+-- Tenants enable built-in data-quality checks (suppliers without tax ID,
+-- customers without contact email, products without price, ...). The
+-- data_quality job runs every enabled rule on a schedule and keeps the last
+-- result on the rule; the results make up the tenant's data-quality report.
+-- Data stewards (the users with a rule's notify role) are emailed when the
+-- number of issues a rule finds grows.
+
+CREATE TABLE data_quality_rules (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    check_key VARCHAR(50) NOT NULL,                 -- Built-in check, e.g. supplier_missing_tax_id
+    severity VARCHAR(20) NOT NULL DEFAULT 'warning',
+    notify_role_id UUID,                            -- Data stewards; NULL (or a deleted role) notifies nobody
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Last run
+    last_checked_at TIMESTAMPTZ,
+    issue_count INTEGER,
+    samples JSONB NOT NULL DEFAULT '[]',            -- First records with the issue
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_data_quality_check UNIQUE(tenant_id, check_key),
+    CONSTRAINT valid_data_quality_severity CHECK (severity IN ('info', 'warning', 'critical'))
+);
+
+-- The data_quality job lists enabled rules across tenants
+CREATE INDEX idx_data_quality_rules_enabled ON data_quality_rules(tenant_id) WHERE is_enabled = true;
+
+-- Triggers
+CREATE TRIGGER update_data_quality_rules_updated_at
+    BEFORE UPDATE ON data_quality_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE data_quality_rules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON data_quality_rules
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON data_quality_rules
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE data_quality_rules IS 'Per-tenant data-quality checks and their last result - managed with the settings permissions';
+COMMENT ON COLUMN data_quality_rules.samples IS 'Array of {resource_id, label, path} for the first records the check found';
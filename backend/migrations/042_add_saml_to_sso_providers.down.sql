@@ -0,0 +1,19 @@
+-- Rollback SAML single sign-on providers
+
+DELETE FROM sso_providers WHERE protocol = 'saml';
+
+ALTER TABLE sso_providers
+    ALTER COLUMN issuer_url DROP DEFAULT,
+    ALTER COLUMN client_id DROP DEFAULT;
+
+ALTER TABLE sso_providers
+    DROP CONSTRAINT sso_provider_saml_idp,
+    DROP CONSTRAINT sso_provider_protocol,
+    DROP COLUMN saml_role_mapping,
+    DROP COLUMN saml_attribute_mapping,
+    DROP COLUMN saml_idp_certificates,
+    DROP COLUMN saml_idp_sso_url,
+    DROP COLUMN saml_idp_entity_id,
+    DROP COLUMN protocol;
+
+COMMENT ON TABLE sso_providers IS 'OpenID Connect providers tenants sign in with';
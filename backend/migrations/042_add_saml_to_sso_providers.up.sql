@@ -0,0 +1,26 @@
+-- Add SAML 2.0 to single sign-on providers
+-- A provider is either an OpenID Connect provider (issuer_url, client_id) or
+-- a SAML identity provider configured from the metadata a tenant uploads.
+-- The entity ID, sign-on URL and signing certificates are read from the
+-- metadata; attribute mappings say which assertion attributes hold the
+-- user's email, names and roles, and role mappings which tenant role each
+-- role value grants.
+
+ALTER TABLE sso_providers
+    ADD COLUMN protocol VARCHAR(10) NOT NULL DEFAULT 'oidc',
+    ADD COLUMN saml_idp_entity_id TEXT,
+    ADD COLUMN saml_idp_sso_url TEXT,                        -- HTTP-Redirect SingleSignOnService
+    ADD COLUMN saml_idp_certificates TEXT[] NOT NULL DEFAULT '{}', -- PEM signing certificates
+    ADD COLUMN saml_attribute_mapping JSONB NOT NULL DEFAULT '{}', -- {"email", "first_name", "last_name", "roles"}: attribute names
+    ADD COLUMN saml_role_mapping JSONB NOT NULL DEFAULT '{}',      -- Role attribute value -> role id
+    ADD CONSTRAINT sso_provider_protocol CHECK (protocol IN ('oidc', 'saml')),
+    ADD CONSTRAINT sso_provider_saml_idp CHECK (
+        protocol != 'saml' OR (saml_idp_entity_id IS NOT NULL AND saml_idp_sso_url IS NOT NULL)
+    );
+
+-- SAML providers have no issuer or client
+ALTER TABLE sso_providers
+    ALTER COLUMN issuer_url SET DEFAULT '',
+    ALTER COLUMN client_id SET DEFAULT '';
+
+COMMENT ON TABLE sso_providers IS 'OpenID Connect and SAML providers tenants sign in with';
@@ -0,0 +1,2 @@
+-- Rollback quota alerts
+DROP TABLE IF EXISTS quota_alerts CASCADE;
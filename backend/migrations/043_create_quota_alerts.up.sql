@@ -0,0 +1,45 @@
+-- Create quota alerts
+-- Plans limit a tenant's users and storage. The quota_check job measures
+-- usage and opens an alert when it crosses a warning threshold (80, 90 and
+-- 100% of the limit by default), emailing the tenant's owners. Open alerts
+-- are emailed again as reminders until an owner acknowledges them, and are
+-- resolved once usage drops back under their threshold, so crossing it again
+-- alerts again.
+
+CREATE TABLE quota_alerts (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    metric VARCHAR(20) NOT NULL,                    -- users | storage
+    threshold INTEGER NOT NULL,                     -- Percent of the limit
+    usage BIGINT NOT NULL,                          -- When the threshold was crossed
+    quota_limit BIGINT NOT NULL,
+
+    last_notified_at TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by UUID,
+    resolved_at TIMESTAMPTZ,                        -- Usage dropped under the threshold
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_quota_metric CHECK (metric IN ('users', 'storage')),
+    CONSTRAINT valid_quota_threshold CHECK (threshold > 0)
+);
+
+-- One open alert per metric and threshold
+CREATE UNIQUE INDEX idx_quota_alerts_open ON quota_alerts(tenant_id, metric, threshold) WHERE resolved_at IS NULL;
+
+-- Row-Level Security
+ALTER TABLE quota_alerts ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON quota_alerts
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON quota_alerts
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE quota_alerts IS 'Plan quota warning thresholds crossed by a tenant - acknowledged by owners';
+COMMENT ON COLUMN quota_alerts.usage IS 'Users, or bytes of storage';
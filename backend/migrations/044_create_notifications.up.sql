@@ -0,0 +1,45 @@
+-- Create notifications
+-- In-app notifications of a user, e.g. an invitation they sent was accepted
+-- or their roles changed. Services create them through the notification
+-- service, which also pushes them to the user's open notification streams.
+-- Read notifications are deleted after NOTIFICATION_RETENTION.
+
+CREATE TABLE notifications (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,                          -- Recipient
+
+    type VARCHAR(50) NOT NULL,                      -- e.g. invitation.accepted
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    link VARCHAR(500),                              -- Frontend path, e.g. /users/uuid
+    data JSONB NOT NULL DEFAULT '{}',               -- Type-specific details
+
+    read_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+-- A user's notifications, newest first, and their unread count
+CREATE INDEX idx_notifications_user ON notifications(tenant_id, user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(tenant_id, user_id) WHERE read_at IS NULL;
+
+-- Read notifications past retention (notification_cleanup job)
+CREATE INDEX idx_notifications_read ON notifications(read_at) WHERE read_at IS NOT NULL;
+
+-- Row-Level Security
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notifications
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON notifications
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE notifications IS 'In-app notifications of users - pushed to /api/notifications/stream';
+COMMENT ON COLUMN notifications.read_at IS 'NULL while unread';
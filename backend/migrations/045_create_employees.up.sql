@@ -0,0 +1,176 @@
+-- Create employees
+-- HR records of the people working for a tenant. An employee may be linked
+-- to a user account (one employee per user) and belongs to a department.
+-- manager_id builds the reporting hierarchy shown by the org chart; the
+-- application refuses manager changes that would create a cycle.
+
+CREATE TABLE employees (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Links
+    user_id UUID,                                   -- Nullable - not every employee signs in
+    department_id UUID,
+    manager_id UUID,
+
+    -- Basic Info
+    employee_number VARCHAR(50),
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(50),
+
+    -- Employment
+    job_title VARCHAR(255) NOT NULL,
+    employment_type VARCHAR(20) NOT NULL DEFAULT 'full_time',  -- full_time | part_time | contractor | intern
+    hire_date DATE NOT NULL,
+    termination_date DATE,
+    salary_band VARCHAR(50),                        -- Shown with employees.view_compensation only
+
+    -- Status: active | on_leave | terminated
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_employment_type CHECK (employment_type IN ('full_time', 'part_time', 'contractor', 'intern')),
+    CONSTRAINT valid_employee_status CHECK (status IN ('active', 'on_leave', 'terminated')),
+    CONSTRAINT employee_not_own_manager CHECK (manager_id IS NULL OR manager_id != id),
+    CONSTRAINT valid_termination_date CHECK (termination_date IS NULL OR termination_date >= hire_date),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (user_id),
+    FOREIGN KEY (tenant_id, department_id) REFERENCES departments(tenant_id, id) ON DELETE SET NULL (department_id),
+    FOREIGN KEY (tenant_id, manager_id) REFERENCES employees(tenant_id, id) ON DELETE SET NULL (manager_id)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX idx_employees_user ON employees(tenant_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_employees_number ON employees(tenant_id, employee_number) WHERE employee_number IS NOT NULL;
+CREATE INDEX idx_employees_manager ON employees(tenant_id, manager_id) WHERE manager_id IS NOT NULL;
+CREATE INDEX idx_employees_department ON employees(tenant_id, department_id) WHERE department_id IS NOT NULL;
+CREATE INDEX idx_employees_status ON employees(tenant_id, status);
+CREATE INDEX idx_employees_name ON employees(tenant_id, last_name, first_name);
+
+-- Enable RLS
+ALTER TABLE employees ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON employees
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON employees
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Trigger for updated_at
+CREATE TRIGGER update_employees_updated_at
+    BEFORE UPDATE ON employees
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE employees IS 'HR employee records with the manager hierarchy - RLS enforced';
+COMMENT ON COLUMN employees.user_id IS 'Linked user account - at most one employee per user';
+COMMENT ON COLUMN employees.manager_id IS 'Direct manager - the application prevents reporting cycles';
+COMMENT ON COLUMN employees.salary_band IS 'Compensation band, e.g. B3 - requires employees.view_compensation to read';
+
+-- Register HR in the permission registry
+INSERT INTO permission_modules (key, display_name, description, icon, sort_order)
+VALUES ('hr', 'Human Resources', 'Employees and reporting lines', 'id-card', 25)
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('employees', 'hr', 'Employees', 'Employee records and the org chart', 10)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('employees', 'view', 'View Employees', 'View employee records and the org chart', 'Human Resources'),
+    ('employees', 'create', 'Create Employees', 'Add employee records', 'Human Resources'),
+    ('employees', 'edit', 'Edit Employees', 'Edit employee records and reporting lines', 'Human Resources'),
+    ('employees', 'delete', 'Delete Employees', 'Remove employee records', 'Human Resources'),
+    ('employees', 'view_compensation', 'View Compensation', 'See and set employees'' salary bands', 'Human Resources'),
+    ('employees', '*', 'All Employee Permissions', 'Full HR access', 'Human Resources')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign employee permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'employees'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'manager' AND p.action = 'view')
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include employees for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation');  -- Integrations and automation are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, sales, purchasing, accounting, sandbox, broadcast, webhook and automation permissions';
@@ -0,0 +1,2 @@
+-- Rollback request cost statistics
+DROP TABLE IF EXISTS request_stats;
@@ -0,0 +1,36 @@
+-- Create request cost statistics
+-- Hourly aggregates of where requests spend their time (database, Redis,
+-- external services), per endpoint, and of slow queries. Every replica
+-- accounts for its own requests in memory (internal/metrics) and adds its
+-- totals to the current hour's rows every METRICS_FLUSH_INTERVAL. The admin
+-- performance endpoint ranks the slowest endpoints and queries from them.
+
+-- Statistics are cluster-wide (no tenant, no RLS), like job_runs
+CREATE TABLE request_stats (
+    bucket TIMESTAMPTZ NOT NULL,            -- Start of the hour
+    kind VARCHAR(20) NOT NULL,              -- endpoint | query
+    name TEXT NOT NULL,                     -- "GET /users/{id}" or normalized SQL
+
+    -- Totals over the hour
+    count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,  -- 5xx responses, failed queries
+    total_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    -- Time spent in each layer (endpoints only)
+    db_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    db_calls BIGINT NOT NULL DEFAULT 0,
+    redis_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    redis_calls BIGINT NOT NULL DEFAULT 0,
+    external_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    external_calls BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (bucket, kind, name),
+    CONSTRAINT valid_request_stat_kind CHECK (kind IN ('endpoint', 'query'))
+);
+
+CREATE INDEX idx_request_stats_kind_bucket ON request_stats(kind, bucket);
+
+COMMENT ON TABLE request_stats IS 'Hourly request cost and slow-query aggregates - cluster-wide, no RLS';
+COMMENT ON COLUMN request_stats.name IS 'Endpoint as method and route pattern, or query text with literals replaced by ?';
+COMMENT ON COLUMN request_stats.count IS 'Requests to the endpoint, or runs of the query that exceeded METRICS_SLOW_QUERY_THRESHOLD';
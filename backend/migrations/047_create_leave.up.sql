@@ -0,0 +1,243 @@
+-- Create leave management
+-- Leave types are configured per tenant (annual leave, sick leave, ...).
+-- Balances are kept per employee, leave type and calendar year: days accrue
+-- monthly from the type's annual allowance, unused days carry over up to the
+-- type's limit, and HR can adjust them. Leave requests are decided by the
+-- employee's manager (or users with leave.approve) and move days from
+-- pending to used.
+
+CREATE TABLE leave_types (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Basic Info
+    code VARCHAR(30) NOT NULL,                      -- e.g. ANNUAL, SICK
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    color VARCHAR(7),                               -- Calendar color, e.g. #4F46E5
+
+    -- Policy
+    paid BOOLEAN NOT NULL DEFAULT TRUE,
+    requires_approval BOOLEAN NOT NULL DEFAULT TRUE,
+    tracks_balance BOOLEAN NOT NULL DEFAULT TRUE,   -- FALSE: requests are not limited by a balance
+    annual_allowance NUMERIC(6,2) NOT NULL DEFAULT 0,  -- Days per year, accrued monthly
+    max_carryover NUMERIC(6,2) NOT NULL DEFAULT 0,     -- Unused days that move to the next year
+
+    -- Status
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT leave_type_code_unique UNIQUE (tenant_id, code),
+    CONSTRAINT valid_leave_allowance CHECK (annual_allowance >= 0 AND annual_allowance <= 366),
+    CONSTRAINT valid_leave_carryover CHECK (max_carryover >= 0)
+);
+
+CREATE TABLE leave_balances (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL,
+    leave_type_id UUID NOT NULL,
+    year INT NOT NULL,
+
+    -- Days; available = carried_over + accrued + adjustment - used - pending
+    accrued NUMERIC(6,2) NOT NULL DEFAULT 0,
+    carried_over NUMERIC(6,2) NOT NULL DEFAULT 0,
+    adjustment NUMERIC(6,2) NOT NULL DEFAULT 0,     -- Manual correction by HR
+    used NUMERIC(6,2) NOT NULL DEFAULT 0,           -- Approved requests
+    pending NUMERIC(6,2) NOT NULL DEFAULT 0,        -- Requests awaiting a decision
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT leave_balance_unique UNIQUE (tenant_id, employee_id, leave_type_id, year),
+    FOREIGN KEY (tenant_id, employee_id) REFERENCES employees(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, leave_type_id) REFERENCES leave_types(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE leave_requests (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL,
+    leave_type_id UUID NOT NULL,
+
+    -- Period
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    half_day BOOLEAN NOT NULL DEFAULT FALSE,        -- Single-day requests only
+    days NUMERIC(6,2) NOT NULL,                     -- Working days (Monday to Friday)
+    reason TEXT,
+
+    -- Workflow. Status: pending | approved | rejected | cancelled
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    approver_id UUID,                               -- User of the employee's manager when requested
+    decided_by UUID,
+    decided_at TIMESTAMPTZ,
+    decision_note TEXT,
+    cancelled_at TIMESTAMPTZ,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    requested_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_leave_period CHECK (end_date >= start_date),
+    CONSTRAINT valid_leave_half_day CHECK (NOT half_day OR start_date = end_date),
+    CONSTRAINT valid_leave_days CHECK (days > 0),
+    CONSTRAINT valid_leave_request_status CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    FOREIGN KEY (tenant_id, employee_id) REFERENCES employees(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, leave_type_id) REFERENCES leave_types(tenant_id, id) ON DELETE RESTRICT,
+    FOREIGN KEY (tenant_id, approver_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (approver_id)
+);
+
+-- Indexes
+CREATE INDEX idx_leave_types_active ON leave_types(tenant_id, is_active);
+CREATE INDEX idx_leave_balances_employee ON leave_balances(tenant_id, employee_id, year);
+CREATE INDEX idx_leave_requests_employee ON leave_requests(tenant_id, employee_id, start_date);
+CREATE INDEX idx_leave_requests_period ON leave_requests(tenant_id, start_date, end_date) WHERE status IN ('pending', 'approved');
+CREATE INDEX idx_leave_requests_approver ON leave_requests(tenant_id, approver_id) WHERE status = 'pending';
+
+-- Enable RLS
+ALTER TABLE leave_types ENABLE ROW LEVEL SECURITY;
+ALTER TABLE leave_balances ENABLE ROW LEVEL SECURITY;
+ALTER TABLE leave_requests ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON leave_types
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON leave_types
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON leave_balances
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON leave_balances
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON leave_requests
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON leave_requests
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Triggers for updated_at
+CREATE TRIGGER update_leave_types_updated_at
+    BEFORE UPDATE ON leave_types
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_leave_balances_updated_at
+    BEFORE UPDATE ON leave_balances
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_leave_requests_updated_at
+    BEFORE UPDATE ON leave_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE leave_types IS 'Kinds of leave a tenant offers, with their allowance policy - RLS enforced';
+COMMENT ON TABLE leave_balances IS 'Leave days per employee, leave type and year - RLS enforced';
+COMMENT ON TABLE leave_requests IS 'Leave requests and their approval - RLS enforced';
+COMMENT ON COLUMN leave_balances.accrued IS 'Allowance accrued so far this year, refreshed by the leave_accrual job';
+COMMENT ON COLUMN leave_balances.carried_over IS 'Unused days of the previous year, up to the type''s max_carryover';
+COMMENT ON COLUMN leave_requests.approver_id IS 'Manager''s user at request time; users with leave.approve may decide too';
+
+-- Register leave in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('leave', 'hr', 'Leave', 'Leave types, balances and requests', 20)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('leave', 'view', 'View Leave', 'View every employee''s leave requests, balances and calendar', 'Human Resources'),
+    ('leave', 'approve', 'Approve Leave', 'Decide on any leave request, not only those of direct reports', 'Human Resources'),
+    ('leave', 'manage', 'Manage Leave', 'Configure leave types, adjust balances and record leave for others', 'Human Resources'),
+    ('leave', '*', 'All Leave Permissions', 'Full leave management access', 'Human Resources')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign leave permissions to existing system roles. Employees request their
+-- own leave and managers decide for their reports without any permission.
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'leave'
+  AND r.name IN ('owner', 'admin')
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include leave for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation', 'leave');  -- Integrations, automation and everyone's leave are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, sales, purchasing, accounting, sandbox, broadcast, webhook and automation permissions';
@@ -0,0 +1,14 @@
+-- Rollback multilingual search
+DROP INDEX IF EXISTS idx_sales_document_lines_search_trgm;
+DROP INDEX IF EXISTS idx_sales_document_lines_search;
+DROP INDEX IF EXISTS idx_sales_documents_customer_search_trgm;
+DROP INDEX IF EXISTS idx_sales_documents_customer_search;
+DROP INDEX IF EXISTS idx_users_search_trgm;
+DROP INDEX IF EXISTS idx_users_search;
+
+DROP FUNCTION IF EXISTS search_query(TEXT, regconfig);
+DROP FUNCTION IF EXISTS search_vector_multilingual(TEXT);
+DROP FUNCTION IF EXISTS search_vector(TEXT);
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
+
+-- The unaccent extension is left installed: other objects may use it
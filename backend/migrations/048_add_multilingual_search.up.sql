@@ -0,0 +1,83 @@
+-- Add multilingual search
+-- Searches match accented and Arabic text whatever the spelling: text is
+-- normalized (accents removed, Arabic diacritics and tatweel stripped, alef,
+-- yaa and taa marbuta variants folded) before it is indexed or compared.
+-- Full-text search uses prefix matching with the English, French and Arabic
+-- stemmers; pg_trgm word similarity catches typos and partial words the
+-- stemmers miss. Users, customers (sales document customer names) and
+-- products (sales line product codes and descriptions) are searchable.
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Normalizes text for search. unaccent() is only STABLE because its
+-- dictionary could change; pinning the dictionary makes this wrapper safe to
+-- use in indexes.
+CREATE OR REPLACE FUNCTION search_normalize(value TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary,
+        translate(
+            regexp_replace(COALESCE(value, ''), '[\u064B-\u065F\u0670\u0640]', '', 'g'),
+            'أإآٱىة',
+            'اااايه'
+        )
+    ))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Names, emails, codes: no stemming
+CREATE OR REPLACE FUNCTION search_vector(value TEXT)
+RETURNS tsvector AS $$
+    SELECT to_tsvector('simple', search_normalize(value))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Free text: the words as written plus their English, French and Arabic stems
+CREATE OR REPLACE FUNCTION search_vector_multilingual(value TEXT)
+RETURNS tsvector AS $$
+    SELECT to_tsvector('simple', search_normalize(value))
+        || to_tsvector('english', search_normalize(value))
+        || to_tsvector('french', search_normalize(value))
+        || to_tsvector('arabic', search_normalize(value))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Builds a prefix query from user input: every word must match the start of
+-- a word, as written or stemmed with config (the searcher's language).
+-- Characters with a meaning in tsquery syntax are dropped.
+CREATE OR REPLACE FUNCTION search_query(value TEXT, config regconfig)
+RETURNS tsquery AS $$
+    WITH words AS (
+        SELECT string_agg('''' || word || ''':*', ' & ') AS terms
+        FROM regexp_split_to_table(
+            regexp_replace(search_normalize(value), '[&|!():*<>''\\]+', ' ', 'g'),
+            '\s+'
+        ) AS word
+        WHERE word != ''
+    )
+    SELECT CASE WHEN terms IS NULL THEN ''::tsquery
+        ELSE to_tsquery('simple', terms) || to_tsquery(config, terms)
+    END
+    FROM words
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Users: name and email
+CREATE INDEX idx_users_search ON users
+    USING GIN (search_vector(first_name || ' ' || last_name || ' ' || email))
+    WHERE deleted_at IS NULL;
+CREATE INDEX idx_users_search_trgm ON users
+    USING GIN (search_normalize(first_name || ' ' || last_name || ' ' || email) gin_trgm_ops)
+    WHERE deleted_at IS NULL;
+
+-- Customers: names on sales documents
+CREATE INDEX idx_sales_documents_customer_search ON sales_documents
+    USING GIN (search_vector(customer_name));
+CREATE INDEX idx_sales_documents_customer_search_trgm ON sales_documents
+    USING GIN (search_normalize(customer_name) gin_trgm_ops);
+
+-- Products: codes and descriptions on sales lines
+CREATE INDEX idx_sales_document_lines_search ON sales_document_lines
+    USING GIN (search_vector_multilingual(COALESCE(product_code, '') || ' ' || description));
+CREATE INDEX idx_sales_document_lines_search_trgm ON sales_document_lines
+    USING GIN (search_normalize(COALESCE(product_code, '') || ' ' || description) gin_trgm_ops);
+
+COMMENT ON FUNCTION search_normalize(TEXT) IS 'Lower-cases text and removes accents and Arabic diacritics for search';
+COMMENT ON FUNCTION search_vector(TEXT) IS 'Full-text vector of names, emails and codes (no stemming)';
+COMMENT ON FUNCTION search_vector_multilingual(TEXT) IS 'Full-text vector of free text with English, French and Arabic stems';
+COMMENT ON FUNCTION search_query(TEXT, regconfig) IS 'Prefix full-text query from user input, stemmed with the searcher''s language';
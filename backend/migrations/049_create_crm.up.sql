@@ -0,0 +1,395 @@
+-- Create CRM
+-- Customers (companies or individuals), their contacts, and the sales
+-- opportunities worked through a tenant's pipelines. Each pipeline has
+-- ordered stages; a stage's outcome (open, won, lost) sets the status of the
+-- opportunities in it. Activities are the timeline of a customer: calls,
+-- emails, meetings, notes and tasks logged by users, plus system entries for
+-- stage and owner changes. Every record may have an owner (a user).
+
+CREATE TABLE crm_customers (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Basic Info
+    name VARCHAR(255) NOT NULL,
+    customer_type VARCHAR(20) NOT NULL DEFAULT 'company',  -- company | individual
+    industry VARCHAR(100),
+    website VARCHAR(255),
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    address TEXT,
+    tax_id VARCHAR(100),
+    notes TEXT,
+
+    -- Status: lead | active | inactive
+    status VARCHAR(20) NOT NULL DEFAULT 'lead',
+
+    -- Assignment
+    owner_id UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_crm_customer_type CHECK (customer_type IN ('company', 'individual')),
+    CONSTRAINT valid_crm_customer_status CHECK (status IN ('lead', 'active', 'inactive')),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (owner_id)
+);
+
+CREATE TABLE crm_contacts (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL,
+
+    -- Basic Info
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    job_title VARCHAR(255),
+    notes TEXT,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,      -- At most one per customer
+
+    -- Assignment
+    owner_id UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES crm_customers(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (owner_id)
+);
+
+CREATE TABLE crm_pipelines (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(100) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,      -- Used when an opportunity names no pipeline
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id)
+);
+
+CREATE TABLE crm_pipeline_stages (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    pipeline_id UUID NOT NULL,
+
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL,                      -- Order within the pipeline, from 1
+    probability INTEGER NOT NULL DEFAULT 0,         -- Default win probability (%) of opportunities entering the stage
+    outcome VARCHAR(10) NOT NULL DEFAULT 'open',    -- open | won | lost
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_crm_stage_outcome CHECK (outcome IN ('open', 'won', 'lost')),
+    CONSTRAINT valid_crm_stage_probability CHECK (probability BETWEEN 0 AND 100),
+    FOREIGN KEY (tenant_id, pipeline_id) REFERENCES crm_pipelines(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE crm_opportunities (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Links
+    customer_id UUID NOT NULL,
+    contact_id UUID,
+    pipeline_id UUID NOT NULL,
+    stage_id UUID NOT NULL,
+
+    -- Deal
+    name VARCHAR(255) NOT NULL,
+    amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    probability INTEGER NOT NULL DEFAULT 0,
+    expected_close_date DATE,
+
+    -- Status follows the stage outcome: open | won | lost
+    status VARCHAR(10) NOT NULL DEFAULT 'open',
+    closed_at TIMESTAMPTZ,
+    lost_reason TEXT,
+
+    -- Assignment
+    owner_id UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_crm_opportunity_status CHECK (status IN ('open', 'won', 'lost')),
+    CONSTRAINT valid_crm_opportunity_probability CHECK (probability BETWEEN 0 AND 100),
+    CONSTRAINT valid_crm_opportunity_amount CHECK (amount >= 0),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES crm_customers(tenant_id, id),
+    FOREIGN KEY (tenant_id, contact_id) REFERENCES crm_contacts(tenant_id, id) ON DELETE SET NULL (contact_id),
+    FOREIGN KEY (tenant_id, pipeline_id) REFERENCES crm_pipelines(tenant_id, id),
+    FOREIGN KEY (tenant_id, stage_id) REFERENCES crm_pipeline_stages(tenant_id, id),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (owner_id)
+);
+
+CREATE TABLE crm_activities (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Links: always a customer, optionally narrowed to a contact or opportunity
+    customer_id UUID NOT NULL,
+    contact_id UUID,
+    opportunity_id UUID,
+
+    -- Type: call | email | meeting | note | task | system
+    activity_type VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT,
+    due_at TIMESTAMPTZ,                             -- Tasks and meetings
+    completed_at TIMESTAMPTZ,
+
+    -- Assignment
+    owner_id UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_crm_activity_type CHECK (activity_type IN ('call', 'email', 'meeting', 'note', 'task', 'system')),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES crm_customers(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, contact_id) REFERENCES crm_contacts(tenant_id, id) ON DELETE SET NULL (contact_id),
+    FOREIGN KEY (tenant_id, opportunity_id) REFERENCES crm_opportunities(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (owner_id)
+);
+
+-- Indexes
+CREATE INDEX idx_crm_customers_owner ON crm_customers(tenant_id, owner_id) WHERE owner_id IS NOT NULL;
+CREATE INDEX idx_crm_customers_status ON crm_customers(tenant_id, status);
+CREATE INDEX idx_crm_customers_name ON crm_customers(tenant_id, name);
+CREATE INDEX idx_crm_customers_search ON crm_customers
+    USING GIN (search_normalize(name || ' ' || COALESCE(email, '')) gin_trgm_ops);
+
+CREATE INDEX idx_crm_contacts_customer ON crm_contacts(tenant_id, customer_id);
+CREATE INDEX idx_crm_contacts_owner ON crm_contacts(tenant_id, owner_id) WHERE owner_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_crm_contacts_primary ON crm_contacts(tenant_id, customer_id) WHERE is_primary;
+CREATE INDEX idx_crm_contacts_search ON crm_contacts
+    USING GIN (search_normalize(first_name || ' ' || last_name || ' ' || COALESCE(email, '')) gin_trgm_ops);
+
+CREATE UNIQUE INDEX idx_crm_pipelines_name ON crm_pipelines(tenant_id, name);
+CREATE UNIQUE INDEX idx_crm_pipelines_default ON crm_pipelines(tenant_id) WHERE is_default;
+
+CREATE UNIQUE INDEX idx_crm_stages_name ON crm_pipeline_stages(tenant_id, pipeline_id, name);
+CREATE INDEX idx_crm_stages_pipeline ON crm_pipeline_stages(tenant_id, pipeline_id, position);
+
+CREATE INDEX idx_crm_opportunities_customer ON crm_opportunities(tenant_id, customer_id);
+CREATE INDEX idx_crm_opportunities_stage ON crm_opportunities(tenant_id, pipeline_id, stage_id);
+CREATE INDEX idx_crm_opportunities_owner ON crm_opportunities(tenant_id, owner_id) WHERE owner_id IS NOT NULL;
+CREATE INDEX idx_crm_opportunities_status ON crm_opportunities(tenant_id, status, expected_close_date);
+CREATE INDEX idx_crm_opportunities_search ON crm_opportunities
+    USING GIN (search_normalize(name) gin_trgm_ops);
+
+CREATE INDEX idx_crm_activities_customer ON crm_activities(tenant_id, customer_id, created_at DESC);
+CREATE INDEX idx_crm_activities_contact ON crm_activities(tenant_id, contact_id) WHERE contact_id IS NOT NULL;
+CREATE INDEX idx_crm_activities_opportunity ON crm_activities(tenant_id, opportunity_id, created_at DESC) WHERE opportunity_id IS NOT NULL;
+CREATE INDEX idx_crm_activities_open_tasks ON crm_activities(tenant_id, owner_id, due_at) WHERE completed_at IS NULL AND activity_type = 'task';
+
+-- Enable RLS
+ALTER TABLE crm_customers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE crm_contacts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE crm_pipelines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE crm_pipeline_stages ENABLE ROW LEVEL SECURITY;
+ALTER TABLE crm_opportunities ENABLE ROW LEVEL SECURITY;
+ALTER TABLE crm_activities ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON crm_customers
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_customers
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON crm_contacts
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_contacts
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON crm_pipelines
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_pipelines
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON crm_pipeline_stages
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_pipeline_stages
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON crm_opportunities
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_opportunities
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON crm_activities
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON crm_activities
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Triggers for updated_at
+CREATE TRIGGER update_crm_customers_updated_at
+    BEFORE UPDATE ON crm_customers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_crm_contacts_updated_at
+    BEFORE UPDATE ON crm_contacts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_crm_pipelines_updated_at
+    BEFORE UPDATE ON crm_pipelines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_crm_pipeline_stages_updated_at
+    BEFORE UPDATE ON crm_pipeline_stages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_crm_opportunities_updated_at
+    BEFORE UPDATE ON crm_opportunities
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_crm_activities_updated_at
+    BEFORE UPDATE ON crm_activities
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE crm_customers IS 'CRM customers and leads - RLS enforced';
+COMMENT ON TABLE crm_contacts IS 'People at CRM customers - RLS enforced';
+COMMENT ON TABLE crm_pipelines IS 'Sales pipelines; a default one is created with the first use of the CRM - RLS enforced';
+COMMENT ON TABLE crm_pipeline_stages IS 'Ordered stages of a pipeline - RLS enforced';
+COMMENT ON TABLE crm_opportunities IS 'Deals worked through a pipeline - RLS enforced';
+COMMENT ON TABLE crm_activities IS 'Timeline of calls, emails, meetings, notes, tasks and system events - RLS enforced';
+COMMENT ON COLUMN crm_pipeline_stages.outcome IS 'open, or won/lost for the closing stages; opportunities take their status from it';
+COMMENT ON COLUMN crm_opportunities.probability IS 'Win probability in percent, defaulted from the stage on each stage change';
+COMMENT ON COLUMN crm_opportunities.closed_at IS 'When the opportunity entered a won or lost stage';
+COMMENT ON COLUMN crm_activities.activity_type IS 'system entries are written by the application for stage and owner changes';
+
+-- Register the CRM in the permission registry
+INSERT INTO permission_modules (key, display_name, description, icon, sort_order)
+VALUES ('crm', 'CRM', 'Customers, contacts and opportunities', 'handshake', 28)
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('crm', 'crm', 'CRM', 'Customers, contacts, opportunities, pipelines and activities', 10)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('crm', 'view', 'View CRM', 'View customers, contacts, opportunities and activities', 'CRM'),
+    ('crm', 'create', 'Create CRM Records', 'Add customers, contacts, opportunities and activities', 'CRM'),
+    ('crm', 'edit', 'Edit CRM Records', 'Edit CRM records and move opportunities between stages', 'CRM'),
+    ('crm', 'delete', 'Delete CRM Records', 'Remove CRM records', 'CRM'),
+    ('crm', 'assign', 'Assign CRM Owners', 'Give customers, contacts and opportunities to another owner', 'CRM'),
+    ('crm', 'manage_pipelines', 'Manage Pipelines', 'Configure pipelines and their stages', 'CRM'),
+    ('crm', '*', 'All CRM Permissions', 'Full CRM access', 'CRM')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign CRM permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'crm'
+  AND (
+       r.name = 'owner'
+    OR (r.name = 'admin' AND p.action != 'delete')
+    OR (r.name = 'manager' AND p.action IN ('view', 'create', 'edit', 'assign'))
+    OR (r.name = 'user' AND p.action = 'view')
+  )
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include the CRM for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation', 'leave');  -- Integrations, automation and everyone's leave are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, crm, sales, purchasing, accounting, sandbox, broadcast, webhook and automation permissions';
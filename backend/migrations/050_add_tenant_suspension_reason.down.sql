@@ -0,0 +1,4 @@
+-- Rollback tenant suspension reason
+
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS valid_suspension_reason;
+ALTER TABLE tenants DROP COLUMN IF EXISTS suspension_reason;
@@ -0,0 +1,15 @@
+-- Add suspension reason to tenants
+-- Suspended tenants are read-only: their users can sign in and read their
+-- data, but changes are refused with the reason until the tenant is
+-- reactivated.
+
+ALTER TABLE tenants ADD COLUMN suspension_reason VARCHAR(50);
+
+ALTER TABLE tenants ADD CONSTRAINT valid_suspension_reason
+    CHECK (suspension_reason IN ('payment_overdue', 'terms_violation', 'requested'));
+
+-- Tenants suspended before reasons were recorded
+UPDATE tenants SET suspension_reason = 'requested' WHERE status = 'suspended' AND suspension_reason IS NULL;
+
+-- Comments
+COMMENT ON COLUMN tenants.suspension_reason IS 'Why a suspended tenant is read-only: payment_overdue | terms_violation | requested - NULL unless suspended';
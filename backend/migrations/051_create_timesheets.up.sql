@@ -0,0 +1,271 @@
+-- Create time tracking
+-- Projects (optionally billed to a CRM customer) and their tasks are
+-- configured per tenant. Employees log hours against them on one timesheet
+-- per ISO week, submit the week, and their manager (or users with
+-- timesheets.approve) approves or rejects it. Entries can only change while
+-- the timesheet is a draft or was rejected; approved weeks are locked until
+-- someone with timesheets.manage reopens them.
+
+CREATE TABLE timesheet_projects (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Basic Info
+    code VARCHAR(30) NOT NULL,                      -- e.g. ACME-WEB
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    customer_id UUID,                               -- CRM customer billed for the project
+
+    -- Billing
+    billable BOOLEAN NOT NULL DEFAULT TRUE,
+    hourly_rate NUMERIC(12,2),                      -- Default rate; tasks may override it
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+
+    -- Status
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT timesheet_project_code_unique UNIQUE (tenant_id, code),
+    CONSTRAINT valid_timesheet_project_rate CHECK (hourly_rate IS NULL OR hourly_rate >= 0),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES crm_customers(tenant_id, id) ON DELETE SET NULL (customer_id)
+);
+
+CREATE TABLE timesheet_tasks (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL,
+
+    name VARCHAR(255) NOT NULL,
+    billable BOOLEAN NOT NULL DEFAULT TRUE,
+    hourly_rate NUMERIC(12,2),                      -- NULL: the project's rate applies
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT timesheet_task_name_unique UNIQUE (tenant_id, project_id, name),
+    CONSTRAINT valid_timesheet_task_rate CHECK (hourly_rate IS NULL OR hourly_rate >= 0),
+    FOREIGN KEY (tenant_id, project_id) REFERENCES timesheet_projects(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE timesheets (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL,
+    week_start DATE NOT NULL,                       -- Monday of the ISO week
+    total_hours NUMERIC(6,2) NOT NULL DEFAULT 0,
+
+    -- Workflow. Status: draft | submitted | approved | rejected
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    submitted_at TIMESTAMPTZ,
+    approver_id UUID,                               -- User of the employee's manager when submitted
+    decided_by UUID,
+    decided_at TIMESTAMPTZ,
+    decision_note TEXT,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT timesheet_week_unique UNIQUE (tenant_id, employee_id, week_start),
+    CONSTRAINT valid_timesheet_week CHECK (EXTRACT(ISODOW FROM week_start) = 1),
+    CONSTRAINT valid_timesheet_status CHECK (status IN ('draft', 'submitted', 'approved', 'rejected')),
+    FOREIGN KEY (tenant_id, employee_id) REFERENCES employees(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, approver_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (approver_id)
+);
+
+CREATE TABLE timesheet_entries (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    timesheet_id UUID NOT NULL,
+    project_id UUID NOT NULL,
+    task_id UUID,
+
+    work_date DATE NOT NULL,
+    hours NUMERIC(5,2) NOT NULL,
+    description TEXT,
+
+    -- Billing, fixed when the entry is written
+    billable BOOLEAN NOT NULL DEFAULT TRUE,
+    hourly_rate NUMERIC(12,2),
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_timesheet_entry_hours CHECK (hours > 0 AND hours <= 24),
+    FOREIGN KEY (tenant_id, timesheet_id) REFERENCES timesheets(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, project_id) REFERENCES timesheet_projects(tenant_id, id) ON DELETE RESTRICT,
+    FOREIGN KEY (tenant_id, task_id) REFERENCES timesheet_tasks(tenant_id, id) ON DELETE SET NULL (task_id)
+);
+
+-- Indexes
+CREATE INDEX idx_timesheet_projects_active ON timesheet_projects(tenant_id, is_active);
+CREATE INDEX idx_timesheet_projects_customer ON timesheet_projects(tenant_id, customer_id) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_timesheet_tasks_project ON timesheet_tasks(tenant_id, project_id);
+CREATE INDEX idx_timesheets_week ON timesheets(tenant_id, week_start);
+CREATE INDEX idx_timesheets_approver ON timesheets(tenant_id, approver_id) WHERE status = 'submitted';
+CREATE INDEX idx_timesheet_entries_timesheet ON timesheet_entries(tenant_id, timesheet_id, work_date);
+CREATE INDEX idx_timesheet_entries_project ON timesheet_entries(tenant_id, project_id, work_date);
+
+-- Enable RLS
+ALTER TABLE timesheet_projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE timesheet_tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE timesheets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE timesheet_entries ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON timesheet_projects
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON timesheet_projects
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON timesheet_tasks
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON timesheet_tasks
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON timesheets
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON timesheets
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON timesheet_entries
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON timesheet_entries
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Triggers for updated_at
+CREATE TRIGGER update_timesheet_projects_updated_at
+    BEFORE UPDATE ON timesheet_projects
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_timesheet_tasks_updated_at
+    BEFORE UPDATE ON timesheet_tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_timesheets_updated_at
+    BEFORE UPDATE ON timesheets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_timesheet_entries_updated_at
+    BEFORE UPDATE ON timesheet_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE timesheet_projects IS 'Projects time is logged against, optionally billed to a CRM customer - RLS enforced';
+COMMENT ON TABLE timesheet_tasks IS 'Tasks within a timesheet project - RLS enforced';
+COMMENT ON TABLE timesheets IS 'One timesheet per employee and ISO week, with its approval - RLS enforced';
+COMMENT ON TABLE timesheet_entries IS 'Hours logged on a day against a project and task - RLS enforced';
+COMMENT ON COLUMN timesheets.approver_id IS 'Manager''s user at submission; users with timesheets.approve may decide too';
+COMMENT ON COLUMN timesheet_entries.hourly_rate IS 'Task rate, or else project rate, when the entry was written; billing reports use it';
+
+-- Register timesheets in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('timesheets', 'hr', 'Timesheets', 'Projects, time entries, weekly timesheets and billing reports', 30)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('timesheets', 'view', 'View Timesheets', 'View every employee''s timesheets and the billing reports', 'Human Resources'),
+    ('timesheets', 'approve', 'Approve Timesheets', 'Decide on any timesheet, not only those of direct reports', 'Human Resources'),
+    ('timesheets', 'manage', 'Manage Timesheets', 'Configure projects and tasks and reopen approved timesheets', 'Human Resources'),
+    ('timesheets', '*', 'All Timesheet Permissions', 'Full time tracking access', 'Human Resources')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign timesheet permissions to existing system roles. Employees log and
+-- submit their own time and managers decide for their reports without any
+-- permission.
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'timesheets'
+  AND r.name IN ('owner', 'admin')
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include timesheets for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'timesheets', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation', 'leave', 'timesheets');  -- Integrations, automation and everyone's leave and time are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, timesheets, crm, sales, purchasing, accounting, sandbox, broadcast, webhook and automation permissions';
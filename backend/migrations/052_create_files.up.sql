@@ -0,0 +1,157 @@
+-- Create file storage
+-- Uploaded avatars and attachments. Contents live on the storage backend
+-- (local disk or an S3-compatible bucket) under tenants/{tenant_id}/...; this
+-- table records each file's metadata and what it is attached to. Deleting a
+-- file only sets deleted_at: it can be restored until the retention passes,
+-- then the purge job removes the row and the stored object.
+
+CREATE TABLE files (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    -- Storage
+    storage_backend VARCHAR(20) NOT NULL,           -- local | s3
+    storage_key VARCHAR(500) NOT NULL,              -- tenants/{tenant_id}/{category}/{yyyy}/{mm}/{id}{ext}
+
+    -- Contents
+    original_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    checksum_sha256 VARCHAR(64) NOT NULL,
+
+    -- Usage. Category: avatar | attachment
+    category VARCHAR(20) NOT NULL,
+    resource_type VARCHAR(50),                      -- e.g. invoice, employee
+    resource_id UUID,
+
+    -- Metadata
+    uploaded_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    deleted_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT file_storage_key_unique UNIQUE (storage_key),
+    CONSTRAINT valid_file_category CHECK (category IN ('avatar', 'attachment')),
+    CONSTRAINT valid_file_size CHECK (size_bytes > 0),
+    CONSTRAINT valid_file_resource CHECK ((resource_type IS NULL) = (resource_id IS NULL)),
+    FOREIGN KEY (tenant_id, uploaded_by) REFERENCES users(tenant_id, id) ON DELETE SET NULL (uploaded_by),
+    FOREIGN KEY (tenant_id, deleted_by) REFERENCES users(tenant_id, id) ON DELETE SET NULL (deleted_by)
+);
+
+-- Indexes
+CREATE INDEX idx_files_resource ON files(tenant_id, resource_type, resource_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_files_uploaded_by ON files(tenant_id, uploaded_by, created_at DESC);
+CREATE INDEX idx_files_deleted ON files(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Enable RLS
+ALTER TABLE files ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON files
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON files
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Trigger for updated_at
+CREATE TRIGGER update_files_updated_at
+    BEFORE UPDATE ON files
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE files IS 'Uploaded files; contents are on the storage backend - RLS enforced';
+COMMENT ON COLUMN files.storage_key IS 'Object key on the storage backend, scoped by tenant';
+COMMENT ON COLUMN files.deleted_at IS 'Soft delete; the purge job removes the file once the retention passes';
+
+-- Register files in the permission registry
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('files', 'administration', 'Files', 'Uploaded avatars and attachments', 80)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('files', 'view', 'View Files', 'View and download every uploaded file', 'Administration'),
+    ('files', 'delete', 'Delete Files', 'Delete and restore files uploaded by anyone', 'Administration'),
+    ('files', '*', 'All File Permissions', 'Full file access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign file permissions to existing system roles. Uploaders always see and
+-- delete their own files without any permission.
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'files'
+  AND ((r.name = 'owner') OR (r.name = 'admin' AND p.action != 'delete'))
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include files for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'timesheets', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation', 'files')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('webhooks', 'automation', 'leave', 'timesheets', 'files');  -- Integrations, automation and everyone's leave, time and files are for administrators
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, timesheets, crm, sales, purchasing, accounting, sandbox, broadcast, webhook, automation and file permissions';
@@ -0,0 +1,2 @@
+-- Rollback usage analytics
+DROP TABLE IF EXISTS usage_stats;
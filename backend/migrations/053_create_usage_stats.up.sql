@@ -0,0 +1,27 @@
+-- Create usage analytics
+-- Anonymized daily feature usage per tenant for the product team: requests
+-- per endpoint and module, and daily active users. Every replica counts its
+-- own requests in memory and adds them to the day's rows every
+-- USAGE_ANALYTICS_FLUSH_INTERVAL; active users are counted in a Redis
+-- HyperLogLog so no user identifier is ever stored. Tenants that opted out
+-- (tenants.settings usage_analytics_opt_out) and sandboxes are not counted.
+
+-- Statistics are cluster-wide (no tenant, no RLS), like request_stats
+CREATE TABLE usage_stats (
+    day DATE NOT NULL,                      -- UTC day
+    tenant_ref VARCHAR(32) NOT NULL,        -- HMAC of the tenant ID, not reversible
+    kind VARCHAR(20) NOT NULL,              -- endpoint | module | active_users
+    name TEXT NOT NULL DEFAULT '',          -- "GET /crm/customers/{id}", "crm" or '' for active_users
+    count BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (day, tenant_ref, kind, name),
+    CONSTRAINT valid_usage_stat_kind CHECK (kind IN ('endpoint', 'module', 'active_users'))
+);
+
+CREATE INDEX idx_usage_stats_kind_day ON usage_stats(kind, day);
+CREATE INDEX idx_usage_stats_tenant_ref ON usage_stats(tenant_ref);
+
+COMMENT ON TABLE usage_stats IS 'Anonymized daily feature usage per tenant - cluster-wide, no RLS';
+COMMENT ON COLUMN usage_stats.tenant_ref IS 'Pseudonymous tenant reference: HMAC-SHA256 of the tenant ID under USAGE_ANALYTICS_SECRET, truncated';
+COMMENT ON COLUMN usage_stats.name IS 'Endpoint as method and route pattern, or module as the first segment of the route';
+COMMENT ON COLUMN usage_stats.count IS 'Successful requests, or distinct active users for active_users';
@@ -0,0 +1,3 @@
+-- Rollback object-level access control
+DROP TABLE IF EXISTS object_grants CASCADE;
+DROP TABLE IF EXISTS object_acls CASCADE;
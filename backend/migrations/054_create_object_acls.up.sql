@@ -0,0 +1,76 @@
+-- Create object-level access control
+-- Resource/action permissions decide what a user may do with a kind of
+-- record; an object ACL further restricts a single record, such as a
+-- department. A restricted record is only accessible to its owner, to users
+-- granted access directly or through one of their roles, and to tenant
+-- owners and administrators. Records without an ACL are not restricted.
+
+CREATE TABLE object_acls (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    resource_type VARCHAR(100) NOT NULL,            -- Permission resource, e.g. departments
+    resource_id UUID NOT NULL,
+
+    owner_id UUID,
+    restricted BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_by UUID,
+
+    PRIMARY KEY (tenant_id, resource_type, resource_id),
+    FOREIGN KEY (tenant_id, owner_id) REFERENCES users(tenant_id, id) ON DELETE SET NULL (owner_id),
+    FOREIGN KEY (tenant_id, updated_by) REFERENCES users(tenant_id, id) ON DELETE SET NULL (updated_by)
+);
+
+-- A grant gives a user, or every holder of a role, one action on a record
+CREATE TABLE object_grants (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    resource_type VARCHAR(100) NOT NULL,
+    resource_id UUID NOT NULL,
+
+    user_id UUID,
+    role_id UUID,
+    action VARCHAR(100) NOT NULL,                   -- view | edit | delete | *
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    created_by UUID,
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_object_grantee CHECK ((user_id IS NULL) <> (role_id IS NULL)),
+    FOREIGN KEY (tenant_id, resource_type, resource_id) REFERENCES object_acls(tenant_id, resource_type, resource_id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, role_id) REFERENCES roles(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users(tenant_id, id) ON DELETE SET NULL (created_by)
+);
+
+-- Indexes
+CREATE UNIQUE INDEX idx_object_grants_unique ON object_grants(tenant_id, resource_type, resource_id, COALESCE(user_id, role_id), action);
+CREATE INDEX idx_object_acls_restricted ON object_acls(tenant_id, resource_type) WHERE restricted;
+
+-- Enable RLS
+ALTER TABLE object_acls ENABLE ROW LEVEL SECURITY;
+ALTER TABLE object_grants ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON object_acls
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON object_acls
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON object_grants
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON object_grants
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Trigger for updated_at
+CREATE TRIGGER update_object_acls_updated_at
+    BEFORE UPDATE ON object_acls
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE object_acls IS 'Ownership and restriction of single records - RLS enforced';
+COMMENT ON COLUMN object_acls.restricted IS 'Only the owner, grantees, owners and administrators may access the record';
+COMMENT ON TABLE object_grants IS 'Actions granted on a record to a user or a role - RLS enforced';
@@ -0,0 +1,93 @@
+-- Add permission wildcards and deny rules
+-- A role permission is now either an allow or a deny. Resource '*' matches
+-- every resource and action '*' every action, so '*.view' allows viewing
+-- everything and 'users.*' everything on users. A matching deny from any of a
+-- user's roles overrides every allow, whichever role grants it, so
+-- "everything except delete" is '*.*' allowed with '*.delete' denied.
+
+ALTER TABLE role_permissions
+    ADD COLUMN effect VARCHAR(10) NOT NULL DEFAULT 'allow',
+    ADD CONSTRAINT valid_role_permission_effect CHECK (effect IN ('allow', 'deny'));
+
+COMMENT ON COLUMN role_permissions.effect IS 'allow | deny - a matching deny overrides every allow';
+
+-- Register the wildcard resource
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('*', 'administration', 'All Resources', 'Every resource, including ones added later', 0)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('*', 'view', 'View Everything', 'View every resource', 'Administration'),
+    ('*', 'create', 'Create Everything', 'Create records of every resource', 'Administration'),
+    ('*', 'edit', 'Edit Everything', 'Edit records of every resource', 'Administration'),
+    ('*', 'delete', 'Delete Everything', 'Delete records of every resource', 'Administration'),
+    ('*', 'export', 'Export Everything', 'Export every resource', 'Administration'),
+    ('*', '*', 'All Permissions', 'Full access to every resource', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Wildcards are only granted explicitly: keep them out of the view-only
+-- User role of new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'timesheets', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'webhooks', 'automation', 'files')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('*', 'webhooks', 'automation', 'leave', 'timesheets', 'files');  -- Integrations, automation and everyone's leave, time and files are for administrators; wildcards are granted explicitly
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, timesheets, crm, sales, purchasing, accounting, sandbox, broadcast, webhook, automation and file permissions';
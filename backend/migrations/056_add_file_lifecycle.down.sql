@@ -0,0 +1,9 @@
+-- Rollback file lifecycle and generated artifacts
+DROP INDEX IF EXISTS idx_files_expires;
+
+UPDATE files SET category = 'attachment' WHERE category = 'artifact';
+
+ALTER TABLE files DROP CONSTRAINT valid_file_category;
+ALTER TABLE files ADD CONSTRAINT valid_file_category CHECK (category IN ('avatar', 'attachment'));
+
+ALTER TABLE files DROP COLUMN IF EXISTS expires_at;
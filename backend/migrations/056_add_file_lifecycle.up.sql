@@ -0,0 +1,17 @@
+-- Add file lifecycle and generated artifacts
+-- Files the server generates, such as compliance reports, exports and
+-- archives, are stored like uploads in the artifact category. Categories
+-- with a lifecycle in STORAGE_LIFECYCLE get an expiry on upload; the
+-- file_purge job removes expired files with their stored contents.
+
+ALTER TABLE files ADD COLUMN expires_at TIMESTAMPTZ;
+
+ALTER TABLE files DROP CONSTRAINT valid_file_category;
+ALTER TABLE files ADD CONSTRAINT valid_file_category CHECK (category IN ('avatar', 'attachment', 'artifact'));
+
+-- Compliance reports were stored as attachments
+UPDATE files SET category = 'artifact' WHERE resource_type = 'compliance_report';
+
+CREATE INDEX idx_files_expires ON files(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON COLUMN files.expires_at IS 'End of the lifecycle of the file category; the purge job removes the file after it';
@@ -0,0 +1,5 @@
+-- Rollback queue limit indexes
+DROP INDEX IF EXISTS idx_async_jobs_tenant_queued;
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_pending;
+DROP INDEX IF EXISTS idx_email_outbox_failed;
+DROP INDEX IF EXISTS idx_email_outbox_tenant_pending;
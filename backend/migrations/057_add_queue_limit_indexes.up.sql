@@ -0,0 +1,9 @@
+-- Add queue limit indexes
+-- Enqueueing counts a tenant's pending emails and queued jobs, and a
+-- webhook's pending deliveries, against the QUEUE_* limits; the queue
+-- metrics count dead letters (failed rows) on every scrape.
+
+CREATE INDEX idx_email_outbox_tenant_pending ON email_outbox(tenant_id) WHERE status = 'pending';
+CREATE INDEX idx_email_outbox_failed ON email_outbox(updated_at) WHERE status = 'failed';
+CREATE INDEX idx_webhook_deliveries_webhook_pending ON webhook_deliveries(webhook_id) WHERE status = 'pending';
+CREATE INDEX idx_async_jobs_tenant_queued ON async_jobs(tenant_id) WHERE status = 'queued';
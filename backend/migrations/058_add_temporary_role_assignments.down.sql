@@ -0,0 +1,12 @@
+-- Rollback temporary role assignments
+DELETE FROM user_roles WHERE valid_until IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_user_roles_valid_until;
+
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS valid_role_assignment_period;
+
+ALTER TABLE user_roles
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS delegated_from,
+    DROP COLUMN IF EXISTS valid_until,
+    DROP COLUMN IF EXISTS valid_from;
@@ -0,0 +1,20 @@
+-- Add temporary role assignments
+-- A role can be granted for a period, e.g. to cover for a colleague on
+-- vacation. Assignments count only between valid_from and valid_until;
+-- permanent ones have neither. The role_assignment_expiry job removes
+-- assignments once valid_until has passed.
+
+ALTER TABLE user_roles
+    ADD COLUMN valid_from TIMESTAMPTZ,
+    ADD COLUMN valid_until TIMESTAMPTZ,
+    ADD COLUMN delegated_from UUID,
+    ADD COLUMN reason TEXT;
+
+ALTER TABLE user_roles ADD CONSTRAINT valid_role_assignment_period
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until > valid_from);
+
+CREATE INDEX idx_user_roles_valid_until ON user_roles(valid_until) WHERE valid_until IS NOT NULL;
+
+COMMENT ON COLUMN user_roles.valid_from IS 'Start of a temporary assignment; NULL counts from assigned_at';
+COMMENT ON COLUMN user_roles.valid_until IS 'End of a temporary assignment; NULL for permanent assignments';
+COMMENT ON COLUMN user_roles.delegated_from IS 'User holding the role whose duties the assignment covers';
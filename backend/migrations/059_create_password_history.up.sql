@@ -0,0 +1,32 @@
+-- Create password history
+-- The password a user replaces is recorded, so a new password can be
+-- checked against the current one and the previous ones, up to
+-- PASSWORD_HISTORY_COUNT passwords in all. Older entries are pruned whenever
+-- a password is set.
+
+CREATE TABLE password_history (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,            -- bcrypt hash
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX idx_password_history_user ON password_history(tenant_id, user_id, created_at DESC);
+
+-- Enable RLS
+ALTER TABLE password_history ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON password_history
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON password_history
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE password_history IS 'Recent password hashes of each user, which cannot be reused - RLS enforced';
@@ -0,0 +1,2 @@
+-- Rollback webhook redaction rules
+ALTER TABLE webhooks DROP COLUMN IF EXISTS redaction_rules;
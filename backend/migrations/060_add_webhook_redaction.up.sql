@@ -0,0 +1,9 @@
+-- Add webhook redaction rules
+-- Values matched by a webhook's rules are replaced with "[redacted]" in the
+-- event object before the delivery is queued, so the payload stored in the
+-- delivery log is the one sent. A rule is a category (emails, phones,
+-- amounts) or a field name.
+
+ALTER TABLE webhooks ADD COLUMN redaction_rules TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN webhooks.redaction_rules IS 'Categories (emails, phones, amounts) or field names redacted from event objects';
@@ -0,0 +1,7 @@
+-- Rollback email change verification
+DROP INDEX IF EXISTS idx_users_email_change_token;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS email_change_token,
+    DROP COLUMN IF EXISTS email_change_expires_at;
@@ -0,0 +1,12 @@
+-- Add email change verification
+-- A user changing their email keeps signing in with the current one until
+-- they confirm the new one through the token emailed to it.
+
+ALTER TABLE users
+    ADD COLUMN pending_email VARCHAR(255),
+    ADD COLUMN email_change_token UUID,
+    ADD COLUMN email_change_expires_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX idx_users_email_change_token ON users(email_change_token) WHERE email_change_token IS NOT NULL;
+
+COMMENT ON COLUMN users.pending_email IS 'Email address awaiting confirmation; the current one stays in use until then';
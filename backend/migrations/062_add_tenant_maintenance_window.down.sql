@@ -0,0 +1,8 @@
+-- Rollback tenant maintenance window
+
+DROP INDEX IF EXISTS idx_tenants_maintenance;
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS valid_maintenance_window;
+ALTER TABLE tenants DROP COLUMN IF EXISTS maintenance_allowed_paths;
+ALTER TABLE tenants DROP COLUMN IF EXISTS maintenance_message;
+ALTER TABLE tenants DROP COLUMN IF EXISTS maintenance_ends_at;
+ALTER TABLE tenants DROP COLUMN IF EXISTS maintenance_starts_at;
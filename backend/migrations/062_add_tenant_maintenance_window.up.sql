@@ -0,0 +1,23 @@
+-- Add a scheduled maintenance window to tenants
+-- During the window the tenant's API is frozen: reads continue, changes are
+-- refused with a Retry-After until the window ends, except on the routes the
+-- window allows (e.g. closing the accounting year), and background jobs that
+-- change the tenant's data are paused.
+
+ALTER TABLE tenants ADD COLUMN maintenance_starts_at TIMESTAMPTZ;
+ALTER TABLE tenants ADD COLUMN maintenance_ends_at TIMESTAMPTZ;
+ALTER TABLE tenants ADD COLUMN maintenance_message TEXT;
+ALTER TABLE tenants ADD COLUMN maintenance_allowed_paths TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE tenants ADD CONSTRAINT valid_maintenance_window
+    CHECK ((maintenance_starts_at IS NULL) = (maintenance_ends_at IS NULL)
+       AND (maintenance_starts_at IS NULL OR maintenance_ends_at > maintenance_starts_at));
+
+-- Background jobs look up the tenants under maintenance on every run
+CREATE INDEX idx_tenants_maintenance ON tenants(maintenance_ends_at) WHERE maintenance_ends_at IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN tenants.maintenance_starts_at IS 'Start of the scheduled maintenance window - NULL when none is scheduled';
+COMMENT ON COLUMN tenants.maintenance_ends_at IS 'End of the scheduled maintenance window, when changes are accepted again';
+COMMENT ON COLUMN tenants.maintenance_message IS 'Announcement shown to the tenant''s users about the maintenance window';
+COMMENT ON COLUMN tenants.maintenance_allowed_paths IS 'API path prefixes that accept changes during the maintenance window';
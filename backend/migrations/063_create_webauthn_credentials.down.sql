@@ -0,0 +1,3 @@
+-- Rollback WebAuthn credentials
+ALTER TABLE users DROP COLUMN IF EXISTS passkey_only;
+DROP TABLE IF EXISTS webauthn_credentials CASCADE;
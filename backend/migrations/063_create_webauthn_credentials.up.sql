@@ -0,0 +1,44 @@
+-- Create WebAuthn credentials
+-- Passkeys and security keys registered by users. A credential signs in
+-- without a password (passkeys) or satisfies the second factor of a password
+-- sign-in. Users with passkey_only set can only sign in with a credential.
+
+CREATE TABLE webauthn_credentials (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    credential_id BYTEA NOT NULL,                   -- Chosen by the authenticator
+    public_key BYTEA NOT NULL,                      -- COSE_Key
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    aaguid UUID,                                    -- Authenticator model, if it tells
+    transports TEXT[] NOT NULL DEFAULT '{}',        -- usb | nfc | ble | internal | hybrid
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE, -- Synced passkey
+    name VARCHAR(100) NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+
+    PRIMARY KEY (tenant_id, id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT unique_webauthn_credential UNIQUE (tenant_id, credential_id)
+);
+
+-- Indexes
+CREATE INDEX idx_webauthn_credentials_user ON webauthn_credentials(tenant_id, user_id);
+
+-- Passkey-only sign-in
+ALTER TABLE users ADD COLUMN passkey_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Enable RLS
+ALTER TABLE webauthn_credentials ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON webauthn_credentials
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON webauthn_credentials
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE webauthn_credentials IS 'Passkeys and security keys of users - RLS enforced';
+COMMENT ON COLUMN webauthn_credentials.sign_count IS 'Last signature counter seen; a counter that does not increase reveals a cloned authenticator';
+COMMENT ON COLUMN users.passkey_only IS 'The user can only sign in with a passkey, not a password';
@@ -0,0 +1,3 @@
+-- Rollback session name
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS name;
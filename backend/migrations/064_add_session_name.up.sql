@@ -0,0 +1,8 @@
+-- Add a friendly name to sessions
+-- Users name their devices (e.g. "Work laptop") to tell their sessions apart
+-- when reviewing or revoking them.
+
+ALTER TABLE sessions ADD COLUMN name VARCHAR(100);
+
+-- Comments
+COMMENT ON COLUMN sessions.name IS 'Friendly device name given by the user - NULL shows the browser and OS instead';
@@ -0,0 +1,5 @@
+-- Rollback tenant logo files
+UPDATE files SET category = 'attachment' WHERE category = 'logo';
+
+ALTER TABLE files DROP CONSTRAINT valid_file_category;
+ALTER TABLE files ADD CONSTRAINT valid_file_category CHECK (category IN ('avatar', 'attachment', 'artifact'));
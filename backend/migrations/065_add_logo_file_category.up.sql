@@ -0,0 +1,6 @@
+-- Add tenant logos to the file categories
+-- Tenants upload a logo for their branding; it is served without
+-- authentication so transactional emails can show it.
+
+ALTER TABLE files DROP CONSTRAINT valid_file_category;
+ALTER TABLE files ADD CONSTRAINT valid_file_category CHECK (category IN ('avatar', 'attachment', 'artifact', 'logo'));
@@ -0,0 +1,2 @@
+-- Rollback the plaintext alternative of outbox emails
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS text_body;
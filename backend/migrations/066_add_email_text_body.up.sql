@@ -0,0 +1,8 @@
+-- Add the plaintext alternative of outbox emails
+-- Emails are sent as multipart/alternative with an HTML and a plaintext part.
+-- Emails queued before this change have no plaintext part and are sent as
+-- HTML only.
+
+ALTER TABLE email_outbox ADD COLUMN text_body TEXT;
+
+COMMENT ON COLUMN email_outbox.text_body IS 'Plaintext alternative of the HTML body';
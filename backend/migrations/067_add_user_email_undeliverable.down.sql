@@ -0,0 +1,4 @@
+-- Rollback undeliverable email tracking
+ALTER TABLE users
+    DROP COLUMN IF EXISTS email_undeliverable_reason,
+    DROP COLUMN IF EXISTS email_undeliverable_at;
@@ -0,0 +1,11 @@
+-- Track addresses the email provider reported as undeliverable
+-- Permanent bounces and spam complaints reported through the provider's
+-- webhook mark the user's address, and no more emails are sent to it until
+-- the user changes their email.
+
+ALTER TABLE users
+    ADD COLUMN email_undeliverable_at TIMESTAMPTZ,
+    ADD COLUMN email_undeliverable_reason TEXT;
+
+COMMENT ON COLUMN users.email_undeliverable_at IS 'When the email provider reported a permanent bounce or complaint for the address';
+COMMENT ON COLUMN users.email_undeliverable_reason IS 'Bounce or complaint reported by the email provider';
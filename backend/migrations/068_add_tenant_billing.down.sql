@@ -0,0 +1,9 @@
+-- Rollback Stripe subscriptions of tenants
+DROP INDEX IF EXISTS idx_tenants_stripe_customer;
+
+ALTER TABLE tenants
+    DROP COLUMN IF EXISTS subscription_cancel_at_period_end,
+    DROP COLUMN IF EXISTS subscription_period_end,
+    DROP COLUMN IF EXISTS subscription_status,
+    DROP COLUMN IF EXISTS stripe_subscription_id,
+    DROP COLUMN IF EXISTS stripe_customer_id;
@@ -0,0 +1,18 @@
+-- Add Stripe subscriptions to tenants
+-- A tenant on a paid plan is a Stripe customer with one subscription. The
+-- plan tier follows the subscription's price through Stripe webhooks.
+
+ALTER TABLE tenants
+    ADD COLUMN stripe_customer_id TEXT,
+    ADD COLUMN stripe_subscription_id TEXT,
+    ADD COLUMN subscription_status VARCHAR(30),
+    ADD COLUMN subscription_period_end TIMESTAMPTZ,
+    ADD COLUMN subscription_cancel_at_period_end BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX idx_tenants_stripe_customer ON tenants(stripe_customer_id) WHERE stripe_customer_id IS NOT NULL;
+
+COMMENT ON COLUMN tenants.stripe_customer_id IS 'Stripe customer the tenant is billed as';
+COMMENT ON COLUMN tenants.stripe_subscription_id IS 'Current Stripe subscription (NULL on the free plan)';
+COMMENT ON COLUMN tenants.subscription_status IS 'Stripe subscription status: active | trialing | past_due | unpaid | canceled | incomplete';
+COMMENT ON COLUMN tenants.subscription_period_end IS 'End of the paid billing period';
+COMMENT ON COLUMN tenants.subscription_cancel_at_period_end IS 'The subscription ends at the end of the period and the tenant returns to the free plan';
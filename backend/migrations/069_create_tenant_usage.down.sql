@@ -0,0 +1,6 @@
+-- Rollback metered tenant usage
+DELETE FROM quota_alerts WHERE metric = 'api_calls';
+ALTER TABLE quota_alerts DROP CONSTRAINT valid_quota_metric;
+ALTER TABLE quota_alerts ADD CONSTRAINT valid_quota_metric CHECK (metric IN ('users', 'storage'));
+
+DROP TABLE IF EXISTS tenant_usage CASCADE;
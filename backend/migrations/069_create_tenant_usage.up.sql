@@ -0,0 +1,27 @@
+-- Create metered tenant usage
+-- Usage plans limit and billing reports, per tenant and UTC day: API calls,
+-- distinct active users, storage and emails sent. Requests and emails are
+-- counted in Redis as they happen and copied here every
+-- JOBS_METERING_ROLLUP_INTERVAL; storage is measured by the quota_check job.
+-- Unlike usage_stats it is not anonymized and tenants cannot opt out.
+
+-- Billing data lives in the catalog database with the tenants (no RLS)
+CREATE TABLE tenant_usage (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    day DATE NOT NULL,                      -- UTC day
+    metric VARCHAR(20) NOT NULL,            -- api_calls | active_users | storage_bytes | emails_sent
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, day, metric),
+    CONSTRAINT valid_tenant_usage_metric CHECK (metric IN ('api_calls', 'active_users', 'storage_bytes', 'emails_sent'))
+);
+
+CREATE INDEX idx_tenant_usage_day ON tenant_usage(day);
+
+COMMENT ON TABLE tenant_usage IS 'Metered daily usage per tenant for plan limits and billing - catalog, no RLS';
+COMMENT ON COLUMN tenant_usage.value IS 'Requests, distinct users, bytes or emails of the day';
+
+-- Plans limit API calls per month
+ALTER TABLE quota_alerts DROP CONSTRAINT valid_quota_metric;
+ALTER TABLE quota_alerts ADD CONSTRAINT valid_quota_metric CHECK (metric IN ('users', 'storage', 'api_calls'));
@@ -0,0 +1,2 @@
+-- Rollback platform administrators
+DROP TABLE IF EXISTS platform_admins CASCADE;
@@ -0,0 +1,19 @@
+-- Create platform administrators
+-- Operators of the platform manage tenants across it through /admin/tenants
+-- and /admin/stats. They sign in as users of a tenant (typically the
+-- operator's own) and are granted platform administration with
+-- cmd/platform-admin; a second factor is required to use it.
+
+-- Cluster-wide (no RLS), in the catalog database with the tenants
+CREATE TABLE platform_admins (
+    user_id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,            -- When granted, for listing
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_platform_admins_tenant ON platform_admins(tenant_id);
+
+COMMENT ON TABLE platform_admins IS 'Users granted platform administration across tenants - cluster-wide, no RLS';
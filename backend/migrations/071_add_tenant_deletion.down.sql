@@ -0,0 +1,9 @@
+-- Rollback scheduled deletion of tenants
+DROP INDEX IF EXISTS idx_tenants_deletion_scheduled;
+
+ALTER TABLE tenants
+    DROP COLUMN IF EXISTS deletion_scheduled_at,
+    DROP COLUMN IF EXISTS deletion_token_expires_at,
+    DROP COLUMN IF EXISTS deletion_token,
+    DROP COLUMN IF EXISTS deletion_requested_by,
+    DROP COLUMN IF EXISTS deletion_requested_at;
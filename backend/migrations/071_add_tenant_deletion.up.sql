@@ -0,0 +1,19 @@
+-- Add scheduled deletion of tenants (offboarding)
+-- An owner asks to delete the tenant and confirms through an emailed link;
+-- the tenant and all of its data are then permanently deleted once the
+-- grace period has passed, unless an owner cancels first.
+
+ALTER TABLE tenants
+    ADD COLUMN deletion_requested_at TIMESTAMPTZ,
+    ADD COLUMN deletion_requested_by UUID,
+    ADD COLUMN deletion_token UUID,
+    ADD COLUMN deletion_token_expires_at TIMESTAMPTZ,
+    ADD COLUMN deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tenants_deletion_scheduled ON tenants(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+COMMENT ON COLUMN tenants.deletion_requested_at IS 'When an owner asked to delete the tenant';
+COMMENT ON COLUMN tenants.deletion_requested_by IS 'Owner who asked to delete the tenant';
+COMMENT ON COLUMN tenants.deletion_token IS 'Token of the emailed link confirming the deletion (NULL once confirmed)';
+COMMENT ON COLUMN tenants.deletion_token_expires_at IS 'When the confirmation link expires';
+COMMENT ON COLUMN tenants.deletion_scheduled_at IS 'When the confirmed deletion is carried out; it can be canceled until then';
@@ -0,0 +1,3 @@
+-- Rollback user erasure tracking
+ALTER TABLE users
+    DROP COLUMN IF EXISTS erased_at;
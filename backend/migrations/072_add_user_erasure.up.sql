@@ -0,0 +1,8 @@
+-- Track users erased at their request (GDPR right to erasure)
+-- An erased user's personal data is anonymized in place: the row is kept so
+-- that audit logs and created_by references still point to a user.
+
+ALTER TABLE users
+    ADD COLUMN erased_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN users.erased_at IS 'When the user''s personal data was anonymized at their request';
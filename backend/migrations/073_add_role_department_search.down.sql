@@ -0,0 +1,5 @@
+-- Rollback role and department search
+DROP INDEX IF EXISTS idx_departments_search_trgm;
+DROP INDEX IF EXISTS idx_departments_search;
+DROP INDEX IF EXISTS idx_roles_search_trgm;
+DROP INDEX IF EXISTS idx_roles_search;
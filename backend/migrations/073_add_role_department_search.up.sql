@@ -0,0 +1,16 @@
+-- Add roles and departments to search
+-- Roles match on their display name and description, departments on their
+-- name and description, with the functions of migration 048. Descriptions
+-- are free text, so they are indexed with their stems.
+
+-- Roles: display name and description
+CREATE INDEX idx_roles_search ON roles
+    USING GIN (search_vector_multilingual(display_name || ' ' || COALESCE(description, '')));
+CREATE INDEX idx_roles_search_trgm ON roles
+    USING GIN (search_normalize(display_name || ' ' || COALESCE(description, '')) gin_trgm_ops);
+
+-- Departments: name and description
+CREATE INDEX idx_departments_search ON departments
+    USING GIN (search_vector_multilingual(name || ' ' || COALESCE(description, '')));
+CREATE INDEX idx_departments_search_trgm ON departments
+    USING GIN (search_normalize(name || ' ' || COALESCE(description, '')) gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- Rollback queue trace context
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS trace_context;
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS trace_context;
@@ -0,0 +1,8 @@
+-- Add the trace context of queued work
+-- Emails and webhook deliveries store the W3C trace context (traceparent,
+-- tracestate) of the request that queued them, so their delivery joins the
+-- request's trace. NULL when tracing was off or the work was queued outside
+-- a request.
+
+ALTER TABLE email_outbox ADD COLUMN trace_context JSONB;
+ALTER TABLE webhook_deliveries ADD COLUMN trace_context JSONB;
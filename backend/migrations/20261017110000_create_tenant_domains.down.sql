@@ -0,0 +1,3 @@
+-- Rollback tenant custom domains
+DROP TRIGGER IF EXISTS update_tenant_domains_updated_at ON tenant_domains;
+DROP TABLE IF EXISTS tenant_domains;
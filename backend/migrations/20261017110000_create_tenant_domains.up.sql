@@ -0,0 +1,35 @@
+-- Create custom domains of tenants
+-- A tenant serves its workspace from its own domain (erp.acme.com) besides
+-- its subdomain of BASE_DOMAIN. A domain is only used once the tenant proved
+-- it controls it: a TXT record at _myerp-verification.<domain> must hold the
+-- domain's verification token.
+
+-- Requests are resolved to a tenant by domain before the tenant is known, so
+-- domains live in the catalog database with the tenants (no RLS)
+CREATE TABLE tenant_domains (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    domain VARCHAR(253) NOT NULL,           -- Lowercase host name, e.g. erp.acme.com
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMPTZ,                -- NULL until the TXT record was found
+    last_checked_at TIMESTAMPTZ,            -- Last verification attempt
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by UUID,
+
+    CONSTRAINT unique_tenant_domain UNIQUE(domain)
+);
+
+CREATE INDEX idx_tenant_domains_tenant ON tenant_domains(tenant_id);
+
+-- Updated at trigger
+CREATE TRIGGER update_tenant_domains_updated_at
+    BEFORE UPDATE ON tenant_domains
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Comments
+COMMENT ON TABLE tenant_domains IS 'Custom domains tenants serve their workspace from - catalog, no RLS';
+COMMENT ON COLUMN tenant_domains.verification_token IS 'Value expected in the TXT record at _myerp-verification.<domain>';
+COMMENT ON COLUMN tenant_domains.verified_at IS 'When the TXT record was found; requests are only resolved by verified domains';
@@ -0,0 +1,2 @@
+-- Rollback tenant slug history
+DROP TABLE IF EXISTS tenant_slug_history;
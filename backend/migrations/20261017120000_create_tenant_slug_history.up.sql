@@ -0,0 +1,23 @@
+-- Create slug history of tenants
+-- When a tenant renames its slug, the old one keeps resolving to the tenant
+-- for a grace period (TENANT_SLUG_REDIRECT_PERIOD): bookmarks, email links
+-- and API clients using <old>.<BASE_DOMAIN> or X-Tenant-Slug keep working
+-- while they move to the new slug. Until it expires an old slug cannot be
+-- taken by another tenant; the tenant itself may take it back.
+
+-- Slugs are resolved before the tenant is known, so the history lives in the
+-- catalog database with the tenants (no RLS)
+CREATE TABLE tenant_slug_history (
+    old_slug VARCHAR(63) PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,        -- The old slug stops resolving and becomes available
+    changed_by UUID
+);
+
+CREATE INDEX idx_tenant_slug_history_tenant ON tenant_slug_history(tenant_id);
+CREATE INDEX idx_tenant_slug_history_expires ON tenant_slug_history(expires_at);
+
+-- Comments
+COMMENT ON TABLE tenant_slug_history IS 'Former slugs of tenants, redirected to the current one until they expire - catalog, no RLS';
+COMMENT ON COLUMN tenant_slug_history.expires_at IS 'End of the grace period; afterwards the slug no longer resolves and can be registered again';
@@ -0,0 +1,6 @@
+-- Rollback notification preferences
+DROP INDEX IF EXISTS idx_notifications_digest;
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS digest_pending,
+    DROP COLUMN IF EXISTS in_app;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,43 @@
+-- Create notification preferences
+-- Users choose, per notification category, whether they get notifications
+-- in the app and by email. Security alerts are emailed right away; emails of
+-- other categories are batched into a daily or weekly digest.
+
+CREATE TABLE notification_preferences (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+
+    channels JSONB NOT NULL DEFAULT '{}',           -- Category => {"in_app": bool, "email": bool}; missing categories use the defaults
+    digest VARCHAR(10) NOT NULL DEFAULT 'weekly',   -- off, daily, weekly
+    last_digest_at TIMESTAMPTZ,                     -- When the last digest was sent
+
+    -- Metadata
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT valid_notification_digest CHECK (digest IN ('off', 'daily', 'weekly'))
+);
+
+-- Notifications only emailed (in_app = false) are hidden from the app, and
+-- kept until the digest including them was sent
+ALTER TABLE notifications
+    ADD COLUMN in_app BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN digest_pending BOOLEAN NOT NULL DEFAULT false;
+
+-- Users with notifications waiting for their digest (notification_digest job)
+CREATE INDEX idx_notifications_digest ON notifications(tenant_id, user_id) WHERE digest_pending;
+
+-- Row-Level Security
+ALTER TABLE notification_preferences ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON notification_preferences
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON notification_preferences
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE notification_preferences IS 'Channels users get each notification category on, and their digest frequency';
+COMMENT ON COLUMN notifications.in_app IS 'false when the user only gets the category by email';
+COMMENT ON COLUMN notifications.digest_pending IS 'To include in the user''s next digest email';
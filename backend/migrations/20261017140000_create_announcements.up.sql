@@ -0,0 +1,169 @@
+-- Create announcements
+-- Admins post messages to all users or to those with given roles or in given
+-- departments. An announcement is shown from its publish time until it
+-- expires; once published it is also added to its audience's notifications
+-- (announcements job). Users mark announcements read.
+
+CREATE TABLE announcements (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+
+    -- Audience: all, roles (role_ids), departments (department_ids)
+    audience VARCHAR(20) NOT NULL DEFAULT 'all',
+    role_ids UUID[] NOT NULL DEFAULT '{}',
+    department_ids UUID[] NOT NULL DEFAULT '{}',
+
+    -- Shown from publish_at until expires_at (never expires when NULL)
+    publish_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ,
+    notified_at TIMESTAMPTZ,            -- When the audience was notified
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_announcement_audience CHECK (audience IN ('all', 'roles', 'departments')),
+    CONSTRAINT valid_announcement_expiry CHECK (expires_at IS NULL OR expires_at > publish_at)
+);
+
+CREATE INDEX idx_announcements_tenant_publish ON announcements(tenant_id, publish_at DESC);
+
+-- Announcements job polls published announcements not notified yet
+CREATE INDEX idx_announcements_unnotified ON announcements(publish_at) WHERE notified_at IS NULL;
+
+CREATE TABLE announcement_reads (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    announcement_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, announcement_id, user_id),
+    FOREIGN KEY (tenant_id, announcement_id) REFERENCES announcements(tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, id) ON DELETE CASCADE
+);
+
+-- Triggers
+CREATE TRIGGER update_announcements_updated_at
+    BEFORE UPDATE ON announcements
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE announcements ENABLE ROW LEVEL SECURITY;
+ALTER TABLE announcement_reads ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON announcements
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON announcements
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+CREATE POLICY tenant_isolation ON announcement_reads
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON announcement_reads
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE announcements IS 'Messages from admins to all or a targeted set of users, shown between publish_at and expires_at';
+COMMENT ON TABLE announcement_reads IS 'Users who read an announcement';
+
+-- Register announcements in the permission registry. Users see the
+-- announcements addressed to them without any permission.
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('announcements', 'administration', 'Announcements', 'Messages to all or a targeted set of users', 65)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('announcements', 'view', 'View Announcements', 'View every announcement, including scheduled ones, and who read them', 'Administration'),
+    ('announcements', 'create', 'Create Announcements', 'Post and schedule announcements', 'Administration'),
+    ('announcements', 'edit', 'Edit Announcements', 'Change announcements and their audience', 'Administration'),
+    ('announcements', 'delete', 'Delete Announcements', 'Delete announcements', 'Administration'),
+    ('announcements', '*', 'All Announcement Permissions', 'Full announcement access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign announcement permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'announcements'
+  AND ((r.name = 'owner') OR (r.name = 'admin' AND p.action != 'delete'))
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include announcements
+-- for new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'timesheets', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'announcements', 'webhooks', 'automation', 'files')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('*', 'webhooks', 'automation', 'leave', 'timesheets', 'files', 'announcements');  -- Integrations, automation, everyone's leave, time and files and scheduled announcements are for administrators; wildcards are granted explicitly
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, timesheets, crm, sales, purchasing, accounting, sandbox, broadcast, announcement, webhook, automation and file permissions';
@@ -0,0 +1,9 @@
+-- Rollback custom fields
+DROP INDEX IF EXISTS idx_departments_custom_fields;
+DROP INDEX IF EXISTS idx_users_custom_fields;
+
+ALTER TABLE departments DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE users DROP COLUMN IF EXISTS custom_fields;
+
+DROP TRIGGER IF EXISTS update_custom_fields_updated_at ON custom_fields;
+DROP TABLE IF EXISTS custom_fields;
@@ -0,0 +1,55 @@
+-- Create custom fields
+-- Tenants define their own fields (text, number, date, select) on users and
+-- departments. Definitions live in custom_fields; the values of an entity are
+-- kept in its custom_fields JSONB column, keyed by the field's key.
+
+CREATE TABLE custom_fields (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    entity VARCHAR(50) NOT NULL,            -- Entity type: user, department
+    key VARCHAR(50) NOT NULL,               -- Key of the value in the entity's custom_fields
+    label VARCHAR(100) NOT NULL,
+    field_type VARCHAR(20) NOT NULL,        -- text, number, date, select
+    options TEXT[] NOT NULL DEFAULT '{}',   -- Allowed values of a select field
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+
+    created_by UUID,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT unique_custom_field_key UNIQUE (tenant_id, entity, key),
+    CONSTRAINT valid_custom_field_entity CHECK (entity IN ('user', 'department')),
+    CONSTRAINT valid_custom_field_type CHECK (field_type IN ('text', 'number', 'date', 'select'))
+);
+
+-- Triggers
+CREATE TRIGGER update_custom_fields_updated_at
+    BEFORE UPDATE ON custom_fields
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE custom_fields ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON custom_fields
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON custom_fields
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Values of custom fields, filtered on by containment (custom_fields @> ...)
+ALTER TABLE users ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE departments ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_users_custom_fields ON users USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX idx_departments_custom_fields ON departments USING GIN (custom_fields jsonb_path_ops);
+
+-- Comments
+COMMENT ON TABLE custom_fields IS 'Fields tenants define on users and departments';
+COMMENT ON COLUMN users.custom_fields IS 'Values of the tenant''s custom fields, by key';
+COMMENT ON COLUMN departments.custom_fields IS 'Values of the tenant''s custom fields, by key';
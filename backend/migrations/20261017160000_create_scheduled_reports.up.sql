@@ -0,0 +1,152 @@
+-- Create scheduled reports
+-- Admins configure reports (active users, sign-in statistics, failed
+-- sign-ins, audit summaries) emailed to recipients on a cron schedule as a
+-- CSV or PDF attachment (scheduled_reports job). Each run covers the time
+-- since the previous one.
+
+CREATE TABLE scheduled_reports (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(100) NOT NULL,
+    report_type VARCHAR(30) NOT NULL,
+    format VARCHAR(10) NOT NULL DEFAULT 'csv',
+
+    -- Cron expression evaluated in UTC; recipients are user IDs
+    schedule VARCHAR(100) NOT NULL,
+    recipients UUID[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,            -- End of the period the last run covered
+    last_status VARCHAR(20),            -- succeeded | failed
+    last_error TEXT,
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_report_type CHECK (report_type IN ('active_users', 'login_stats', 'failed_logins', 'audit_summary')),
+    CONSTRAINT valid_report_format CHECK (format IN ('csv', 'pdf')),
+    CONSTRAINT valid_report_status CHECK (last_status IS NULL OR last_status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX idx_scheduled_reports_tenant ON scheduled_reports(tenant_id, name);
+
+-- Scheduled reports job polls enabled reports past their next run
+CREATE INDEX idx_scheduled_reports_due ON scheduled_reports(next_run_at) WHERE enabled = TRUE;
+
+-- Triggers
+CREATE TRIGGER update_scheduled_reports_updated_at
+    BEFORE UPDATE ON scheduled_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE scheduled_reports ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON scheduled_reports
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON scheduled_reports
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE scheduled_reports IS 'Reports emailed to recipients on a cron schedule, each run covering the time since the previous one';
+
+-- Register reports in the permission registry. Recipients must be allowed to
+-- view reports.
+INSERT INTO permission_resources (resource, module_key, display_name, description, sort_order) VALUES
+    ('reports', 'administration', 'Reports', 'Scheduled reports on users, sign-ins and audit logs', 66)
+ON CONFLICT (resource) DO NOTHING;
+
+INSERT INTO permissions (resource, action, display_name, description, category) VALUES
+    ('reports', 'view', 'View Reports', 'View scheduled reports and receive them by email', 'Administration'),
+    ('reports', 'create', 'Create Reports', 'Schedule reports', 'Administration'),
+    ('reports', 'edit', 'Edit Reports', 'Change scheduled reports and run them on demand', 'Administration'),
+    ('reports', 'delete', 'Delete Reports', 'Delete scheduled reports', 'Administration'),
+    ('reports', '*', 'All Report Permissions', 'Full report access', 'Administration')
+ON CONFLICT (resource, action) DO NOTHING;
+
+-- Assign report permissions to existing system roles
+INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+SELECT r.tenant_id, r.id, p.id
+FROM roles r
+CROSS JOIN permissions p
+WHERE p.resource = 'reports'
+  AND ((r.name = 'owner') OR (r.name = 'admin' AND p.action != 'delete'))
+  AND r.is_system = TRUE
+  AND NOT EXISTS (
+    SELECT 1 FROM role_permissions rp
+    WHERE rp.tenant_id = r.tenant_id AND rp.role_id = r.id AND rp.permission_id = p.id
+  );
+
+-- Update the provision_tenant_system_roles function to include reports for
+-- new tenants
+CREATE OR REPLACE FUNCTION provision_tenant_system_roles(p_tenant_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_owner_role_id UUID;
+    v_admin_role_id UUID;
+    v_manager_role_id UUID;
+    v_user_role_id UUID;
+BEGIN
+    -- Create Owner role (full access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'owner', 'Owner', 'Full system access - all permissions', TRUE, 0)
+    RETURNING id INTO v_owner_role_id;
+
+    -- Assign all permissions to Owner role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_owner_role_id, id
+    FROM permissions;
+
+    -- Create Admin role (most permissions except some sensitive security operations)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'admin', 'Administrator', 'Administrative access - manage users, roles, settings', TRUE, 1)
+    RETURNING id INTO v_admin_role_id;
+
+    -- Assign permissions to Admin role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_admin_role_id, id
+    FROM permissions
+    WHERE resource IN ('users', 'roles', 'settings', 'departments', 'employees', 'leave', 'timesheets', 'crm', 'sales', 'purchasing', 'accounting', 'sandboxes', 'broadcasts', 'announcements', 'reports', 'webhooks', 'automation', 'files')
+      AND action != 'delete';  -- Admins can't delete (only owners)
+
+    -- Create Manager role (limited permissions)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'manager', 'Manager', 'View and manage team members', TRUE, 2)
+    RETURNING id INTO v_manager_role_id;
+
+    -- Assign permissions to Manager role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_manager_role_id, id
+    FROM permissions
+    WHERE (resource = 'users' AND action IN ('view', 'edit'))
+       OR (resource = 'settings' AND action = 'view')
+       OR (resource = 'departments' AND action IN ('view', 'edit'))
+       OR (resource = 'employees' AND action = 'view')
+       OR (resource = 'crm' AND action IN ('view', 'create', 'edit', 'assign'))
+       OR (resource = 'sales' AND action IN ('view', 'create', 'edit', 'manage_status'))
+       OR (resource = 'purchasing' AND action IN ('view', 'create', 'edit', 'receive'))
+       OR (resource = 'accounting' AND action IN ('view', 'create', 'edit'));
+
+    -- Create User role (basic read-only access)
+    INSERT INTO roles (tenant_id, name, display_name, description, is_system, level)
+    VALUES (p_tenant_id, 'user', 'User', 'Basic user access - view only', TRUE, 3)
+    RETURNING id INTO v_user_role_id;
+
+    -- Assign permissions to User role
+    INSERT INTO role_permissions (tenant_id, role_id, permission_id)
+    SELECT p_tenant_id, v_user_role_id, id
+    FROM permissions
+    WHERE action = 'view'  -- View-only permissions
+      AND resource NOT IN ('*', 'webhooks', 'automation', 'leave', 'timesheets', 'files', 'announcements', 'reports');  -- Integrations, automation, everyone's leave, time and files, scheduled announcements and reports are for administrators; wildcards are granted explicitly
+
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+COMMENT ON FUNCTION provision_tenant_system_roles IS 'Creates system roles (owner, admin, manager, user) for a new tenant during provisioning - includes departments, employees, leave, timesheets, crm, sales, purchasing, accounting, sandbox, broadcast, announcement, report, webhook, automation and file permissions';
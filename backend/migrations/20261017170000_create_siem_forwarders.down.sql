@@ -0,0 +1,3 @@
+-- Rollback SIEM forwarders
+
+DROP TABLE IF EXISTS siem_forwarders;
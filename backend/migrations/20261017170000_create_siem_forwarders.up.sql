@@ -0,0 +1,69 @@
+-- Create SIEM forwarders
+-- Tenants stream their audit log to a SIEM over syslog, an HTTPS webhook or
+-- a Kafka REST proxy, formatted as CEF or JSON (siem_forwarding job). Each
+-- forwarder keeps a cursor into audit_logs: events up to the cursor were
+-- forwarded, so an outage of the SIEM only delays forwarding.
+
+CREATE TABLE siem_forwarders (
+    id UUID DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+
+    name VARCHAR(100) NOT NULL,
+    transport VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL DEFAULT 'cef',
+
+    -- host:port for syslog, URL for the webhook and the Kafka REST proxy
+    endpoint VARCHAR(500) NOT NULL,
+    topic VARCHAR(249),                 -- Kafka topic
+    auth_header TEXT,                   -- Encrypted Authorization header value
+
+    -- Audit action prefixes to forward; empty forwards every event
+    actions TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Position of the last forwarded event in (created_at, id) order
+    cursor_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    cursor_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    failures INTEGER NOT NULL DEFAULT 0,  -- Consecutive failed sends
+    last_forwarded_at TIMESTAMPTZ,
+    last_error TEXT,
+    events_forwarded BIGINT NOT NULL DEFAULT 0,
+    events_dropped BIGINT NOT NULL DEFAULT 0,  -- Skipped for lagging more than SIEM_MAX_LAG
+
+    created_by UUID NOT NULL,
+
+    -- Metadata
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, id),
+    CONSTRAINT valid_siem_transport CHECK (transport IN ('syslog', 'syslog_tcp', 'syslog_udp', 'webhook', 'kafka')),
+    CONSTRAINT valid_siem_format CHECK (format IN ('cef', 'json')),
+    CONSTRAINT siem_kafka_has_topic CHECK (transport != 'kafka' OR topic IS NOT NULL)
+);
+
+CREATE INDEX idx_siem_forwarders_tenant ON siem_forwarders(tenant_id, name);
+
+-- SIEM forwarding job polls enabled forwarders due for an attempt
+CREATE INDEX idx_siem_forwarders_due ON siem_forwarders(next_attempt_at) WHERE enabled = TRUE;
+
+-- Triggers
+CREATE TRIGGER update_siem_forwarders_updated_at
+    BEFORE UPDATE ON siem_forwarders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Row-Level Security
+ALTER TABLE siem_forwarders ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON siem_forwarders
+    USING (tenant_id = current_setting('app.current_tenant_id', true)::uuid);
+CREATE POLICY bypass_rls_for_superuser ON siem_forwarders
+    USING (current_setting('app.bypass_rls', true) = 'true')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'true');
+
+-- Comments
+COMMENT ON TABLE siem_forwarders IS 'Destinations the tenant audit log is streamed to, each with its cursor into audit_logs';
+COMMENT ON COLUMN siem_forwarders.auth_header IS 'Encrypted with the application encryption key';
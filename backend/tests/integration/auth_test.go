@@ -1,3 +1,4 @@
+//go:build integration
 // +build integration
 
 package integration
@@ -6,26 +7,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"myerp-v2/internal/config"
-	"myerp-v2/internal/server"
 )
 
 // TestAuthFlow tests the complete authentication flow
 func TestAuthFlow(t *testing.T) {
 	// Setup test server
-	cfg := loadTestConfig()
-	router := server.NewRouter(cfg)
-	srv := httptest.NewServer(router.Setup())
-	defer srv.Close()
+	srv := newTestServer(t)
+	mail := newMailpit(t)
 
 	// Test data
 	tenantSlug := "test-company-" + randomString(8)
-	email := "test@example.com"
+	email := "test-" + randomString(8) + "@example.com"
 	password := "Test@Password123"
 
 	t.Run("Register new tenant", func(t *testing.T) {
@@ -38,25 +34,33 @@ func TestAuthFlow(t *testing.T) {
 			"password":     password,
 		}
 
-		resp, err := makeRequest(srv.URL+"/api/auth/register", "POST", payload, "")
+		resp, err := makeRequest(srv.URL+"/auth/register", "POST", payload, "")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
 		var result map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&result)
 
-		assert.Equal(t, "success", result["status"])
+		assert.Equal(t, true, result["success"])
 		assert.NotNil(t, result["data"])
 	})
 
+	t.Run("Verify email", func(t *testing.T) {
+		msg := mail.WaitForMessage(t, email, "Verify your")
+		token := msg.ExtractToken(t, "/auth/verify")
+
+		resp, err := makeRequest(srv.URL+"/auth/verify-email", "POST", map[string]interface{}{"token": token}, "")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
 	t.Run("Login with credentials", func(t *testing.T) {
 		payload := map[string]interface{}{
-			"email":       email,
-			"password":    password,
-			"tenant_slug": tenantSlug,
+			"email":    email,
+			"password": password,
 		}
 
-		resp, err := makeRequest(srv.URL+"/api/auth/login", "POST", payload, "")
+		resp, err := makeRequest(srv.URL+"/auth/login", "POST", payload, "")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -72,45 +76,35 @@ func TestAuthFlow(t *testing.T) {
 
 	t.Run("Login with wrong password", func(t *testing.T) {
 		payload := map[string]interface{}{
-			"email":       email,
-			"password":    "WrongPassword123",
-			"tenant_slug": tenantSlug,
+			"email":    email,
+			"password": "WrongPassword123",
 		}
 
-		resp, err := makeRequest(srv.URL+"/api/auth/login", "POST", payload, "")
+		resp, err := makeRequest(srv.URL+"/auth/login", "POST", payload, "")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 
 	t.Run("Access protected endpoint without token", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/auth/me", "GET", nil, "")
+		resp, err := makeRequest(srv.URL+"/auth/me", "GET", nil, "")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 
 	t.Run("Access protected endpoint with token", func(t *testing.T) {
 		// First login to get token
-		payload := map[string]interface{}{
-			"email":       email,
-			"password":    password,
-			"tenant_slug": tenantSlug,
-		}
-
-		loginResp, _ := makeRequest(srv.URL+"/api/auth/login", "POST", payload, "")
-		var loginResult map[string]interface{}
-		json.NewDecoder(loginResp.Body).Decode(&loginResult)
-		data := loginResult["data"].(map[string]interface{})
-		token := data["access_token"].(string)
+		token := login(t, srv.URL, email, password)
 
 		// Access protected endpoint
-		resp, err := makeRequest(srv.URL+"/api/auth/me", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/auth/me", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 		var result map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&result)
 
-		userData := result["data"].(map[string]interface{})
+		data := result["data"].(map[string]interface{})
+		userData := data["user"].(map[string]interface{})
 		assert.Equal(t, email, userData["email"])
 	})
 }
@@ -118,16 +112,13 @@ func TestAuthFlow(t *testing.T) {
 // TestUserManagement tests user CRUD operations
 func TestUserManagement(t *testing.T) {
 	// Setup
-	cfg := loadTestConfig()
-	router := server.NewRouter(cfg)
-	srv := httptest.NewServer(router.Setup())
-	defer srv.Close()
+	srv := newTestServer(t)
 
 	// Create tenant and login
-	token := createTenantAndLogin(t, srv.URL)
+	token := createTenantAndLogin(t, srv.URL, newMailpit(t))
 
 	t.Run("List users", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/users?page=1&page_size=10", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/users?page=1&page_size=10", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -140,7 +131,7 @@ func TestUserManagement(t *testing.T) {
 	})
 
 	t.Run("Search users", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/users/search?query=john&page=1", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/users/search?query=john&page=1", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
@@ -148,15 +139,12 @@ func TestUserManagement(t *testing.T) {
 
 // TestRoleManagement tests role and permission operations
 func TestRoleManagement(t *testing.T) {
-	cfg := loadTestConfig()
-	router := server.NewRouter(cfg)
-	srv := httptest.NewServer(router.Setup())
-	defer srv.Close()
+	srv := newTestServer(t)
 
-	token := createTenantAndLogin(t, srv.URL)
+	token := createTenantAndLogin(t, srv.URL, newMailpit(t))
 
 	t.Run("List roles", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/roles", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/roles", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -167,7 +155,7 @@ func TestRoleManagement(t *testing.T) {
 	})
 
 	t.Run("List permissions", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/permissions", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/permissions", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -179,7 +167,7 @@ func TestRoleManagement(t *testing.T) {
 
 	t.Run("Create custom role", func(t *testing.T) {
 		// First get permission IDs
-		permResp, _ := makeRequest(srv.URL+"/api/permissions", "GET", nil, token)
+		permResp, _ := makeRequest(srv.URL+"/permissions", "GET", nil, token)
 		var permResult map[string]interface{}
 		json.NewDecoder(permResp.Body).Decode(&permResult)
 		permData := permResult["data"].(map[string]interface{})
@@ -194,7 +182,7 @@ func TestRoleManagement(t *testing.T) {
 			"permission_ids": []string{permissionID},
 		}
 
-		resp, err := makeRequest(srv.URL+"/api/roles", "POST", payload, token)
+		resp, err := makeRequest(srv.URL+"/roles", "POST", payload, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, resp.StatusCode)
 	})
@@ -202,28 +190,25 @@ func TestRoleManagement(t *testing.T) {
 
 // TestSecurityFeatures tests 2FA, sessions, and audit logs
 func TestSecurityFeatures(t *testing.T) {
-	cfg := loadTestConfig()
-	router := server.NewRouter(cfg)
-	srv := httptest.NewServer(router.Setup())
-	defer srv.Close()
+	srv := newTestServer(t)
 
-	token := createTenantAndLogin(t, srv.URL)
+	token := createTenantAndLogin(t, srv.URL, newMailpit(t))
 
 	t.Run("Setup 2FA", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/2fa/setup", "POST", nil, token)
+		resp, err := makeRequest(srv.URL+"/2fa/setup", "POST", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 		var result map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&result)
 		data := result["data"].(map[string]interface{})
-		assert.NotEmpty(t, data["qr_code"])
+		assert.NotEmpty(t, data["qr_code_url"])
 		assert.NotEmpty(t, data["secret"])
 		assert.NotNil(t, data["backup_codes"])
 	})
 
 	t.Run("List active sessions", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/sessions", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/sessions", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -234,7 +219,7 @@ func TestSecurityFeatures(t *testing.T) {
 	})
 
 	t.Run("Query audit logs", func(t *testing.T) {
-		resp, err := makeRequest(srv.URL+"/api/audit?page=1&page_size=10", "GET", nil, token)
+		resp, err := makeRequest(srv.URL+"/audit-logs?page=1&page_size=10", "GET", nil, token)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -247,32 +232,6 @@ func TestSecurityFeatures(t *testing.T) {
 
 // Helper functions
 
-func loadTestConfig() *config.Config {
-	// Load test configuration
-	// In practice, this would load from .env.test
-	return &config.Config{
-		Database: config.DatabaseConfig{
-			Host:     "localhost",
-			Port:     5432,
-			User:     "myerp",
-			Password: "test_password",
-			DBName:   "myerp_v2_test",
-		},
-		Redis: config.RedisConfig{
-			Host:     "localhost",
-			Port:     6379,
-			Password: "",
-		},
-		JWT: config.JWTConfig{
-			Secret: "test-secret-key-minimum-32-characters-long",
-		},
-		Server: config.ServerConfig{
-			Port:        8080,
-			Environment: "test",
-		},
-	}
-}
-
 func makeRequest(url, method string, payload interface{}, token string) (*http.Response, error) {
 	var body *bytes.Buffer
 	if payload != nil {
@@ -296,40 +255,63 @@ func makeRequest(url, method string, payload interface{}, token string) (*http.R
 	return client.Do(req)
 }
 
-func createTenantAndLogin(t *testing.T, baseURL string) string {
-	// Register
-	tenantSlug := "test-" + randomString(8)
+// createTenantAndLogin registers a tenant, verifies it through the email
+// Mailpit intercepts and returns an access token of its owner
+func createTenantAndLogin(t testing.TB, baseURL string, mail *Mailpit) string {
+	t.Helper()
+
+	email := "admin-" + randomString(8) + "@example.com"
+	password := "Admin@123456"
+	registerTenant(t, baseURL, mail, email, password)
+
+	return login(t, baseURL, email, password)
+}
+
+// registerTenant registers a tenant with an owner and verifies it through the
+// email Mailpit intercepts
+func registerTenant(t testing.TB, baseURL string, mail *Mailpit, email, password string) {
+	t.Helper()
+
 	registerPayload := map[string]interface{}{
 		"company_name": "Test Company",
-		"slug":         tenantSlug,
-		"email":        "admin@test.com",
+		"slug":         "test-" + randomString(8),
+		"email":        email,
 		"first_name":   "Admin",
 		"last_name":    "User",
-		"password":     "Admin@123456",
+		"password":     password,
 	}
 
-	makeRequest(baseURL+"/api/auth/register", "POST", registerPayload, "")
+	resp, err := makeRequest(baseURL+"/auth/register", "POST", registerPayload, "")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	msg := mail.WaitForMessage(t, email, "Verify your")
+	token := msg.ExtractToken(t, "/auth/verify")
+
+	resp, err = makeRequest(baseURL+"/auth/verify-email", "POST", map[string]interface{}{"token": token}, "")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// login logs in with email and password and returns the access token
+func login(t testing.TB, baseURL, email, password string) string {
+	t.Helper()
 
-	// Login
 	loginPayload := map[string]interface{}{
-		"email":       "admin@test.com",
-		"password":    "Admin@123456",
-		"tenant_slug": tenantSlug,
+		"email":    email,
+		"password": password,
 	}
 
-	resp, _ := makeRequest(baseURL+"/api/auth/login", "POST", loginPayload, "")
+	resp, err := makeRequest(baseURL+"/auth/login", "POST", loginPayload, "")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
 	var result map[string]interface{}
-	json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
 	data := result["data"].(map[string]interface{})
 
 	return data["access_token"].(string)
 }
-
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[i%len(charset)]
-	}
-	return string(b)
-}
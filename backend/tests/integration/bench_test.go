@@ -0,0 +1,93 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // Baseline driver
+	"github.com/stretchr/testify/require"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/repository"
+)
+
+// BenchmarkSessionValidation measures the database work of authenticating a
+// request, the session, user and tenant lookups of AuthService.ValidateSession,
+// on the pgx pool the server uses and, as the baseline, on lib/pq with the
+// same pool size:
+//
+//	go test -tags integration -run '^$' -bench SessionValidation -cpu 1,8,32 ./tests/integration
+//
+// pgx prepares each query once per connection and reuses the statement, where
+// lib/pq parses and plans it on every call, so throughput (ns/op) improves
+// most under concurrency.
+func BenchmarkSessionValidation(b *testing.B) {
+	srv := newTestServer(b)
+	token := createTenantAndLogin(b, srv.URL, newMailpit(b))
+
+	me := decodeResponse(b, srv.URL+"/auth/me", "GET", nil, token, http.StatusOK)
+	user := me["user"].(map[string]interface{})
+	userID := uuid.MustParse(user["id"].(string))
+	tenantID := uuid.MustParse(user["tenant_id"].(string))
+	hash := sha256.Sum256([]byte(token))
+	tokenHash := hex.EncodeToString(hash[:])
+
+	cfg, err := config.Load()
+	require.NoError(b, err)
+
+	pgxDB, err := database.NewPostgresDB(&cfg.Database)
+	require.NoError(b, err)
+	b.Cleanup(func() { pgxDB.Close() })
+
+	pqDB, err := sqlx.Connect("postgres", cfg.Database.DSN())
+	require.NoError(b, err)
+	pqDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	pqDB.SetMaxIdleConns(cfg.Database.MaxOpenConns)
+	b.Cleanup(func() { pqDB.Close() })
+
+	drivers := []struct {
+		name string
+		db   *sqlx.DB
+	}{
+		{"pgx", pgxDB},
+		{"lib-pq", pqDB},
+	}
+
+	for _, driver := range drivers {
+		sessions := repository.NewSessionRepository(driver.db)
+		users := repository.NewUserRepository(driver.db)
+		tenants := repository.NewTenantRepository(driver.db)
+
+		b.Run(driver.name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := sessions.FindByTokenHash(ctx, tenantID, tokenHash); err != nil {
+						b.Error(err)
+						return
+					}
+					if _, err := users.FindByID(ctx, tenantID, userID); err != nil {
+						b.Error(err)
+						return
+					}
+					if _, err := tenants.FindByID(ctx, tenantID); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
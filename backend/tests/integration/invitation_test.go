@@ -0,0 +1,135 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInvitationLifecycle invites a user, accepts the invitation from the
+// intercepted email, logs in as the new user and checks the invited role was
+// assigned
+func TestInvitationLifecycle(t *testing.T) {
+	srv := newTestServer(t)
+	mail := newMailpit(t)
+
+	ownerToken := createTenantAndLogin(t, srv.URL, mail)
+
+	inviteeEmail := "invitee-" + randomString(8) + "@example.com"
+	inviteePassword := "Invitee@123456"
+
+	// The invitee gets the Manager system role
+	var roleID string
+	t.Run("Find role", func(t *testing.T) {
+		result := decodeResponse(t, srv.URL+"/roles", "GET", nil, ownerToken, http.StatusOK)
+		for _, r := range result["roles"].([]interface{}) {
+			role := r.(map[string]interface{})
+			if role["name"] == "manager" {
+				roleID = role["id"].(string)
+			}
+		}
+		require.NotEmpty(t, roleID, "manager role not provisioned")
+	})
+
+	var invitationID string
+	t.Run("Create invitation", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"email":    inviteeEmail,
+			"role_ids": []string{roleID},
+			"message":  "Welcome aboard",
+		}
+
+		result := decodeResponse(t, srv.URL+"/invitations", "POST", payload, ownerToken, http.StatusCreated)
+		invitation := result["invitation"].(map[string]interface{})
+		assert.Equal(t, inviteeEmail, invitation["email"])
+		invitationID = invitation["id"].(string)
+	})
+
+	var token string
+	t.Run("Intercept invitation email", func(t *testing.T) {
+		msg := mail.WaitForMessage(t, inviteeEmail, "invited to join")
+		assert.Contains(t, msg.HTML, "Welcome aboard")
+
+		token = msg.ExtractToken(t, "/accept-invitation")
+	})
+
+	t.Run("Accept invitation", func(t *testing.T) {
+		require.NotEmpty(t, token)
+		payload := map[string]interface{}{
+			"token":      token,
+			"password":   inviteePassword,
+			"first_name": "Invited",
+			"last_name":  "User",
+		}
+
+		result := decodeResponse(t, srv.URL+"/invitations/accept", "POST", payload, "", http.StatusOK)
+		user := result["user"].(map[string]interface{})
+		assert.Equal(t, inviteeEmail, user["email"])
+	})
+
+	t.Run("Accepting again fails", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"token":      token,
+			"password":   inviteePassword,
+			"first_name": "Invited",
+			"last_name":  "User",
+		}
+
+		resp, err := makeRequest(srv.URL+"/invitations/accept", "POST", payload, "")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+	})
+
+	var inviteeToken, inviteeID string
+	t.Run("Log in as invitee", func(t *testing.T) {
+		inviteeToken = login(t, srv.URL, inviteeEmail, inviteePassword)
+
+		result := decodeResponse(t, srv.URL+"/auth/me", "GET", nil, inviteeToken, http.StatusOK)
+		user := result["user"].(map[string]interface{})
+		assert.Equal(t, inviteeEmail, user["email"])
+		inviteeID = user["id"].(string)
+	})
+
+	t.Run("Invited role is assigned", func(t *testing.T) {
+		require.NotEmpty(t, inviteeID)
+
+		result := decodeResponse(t, srv.URL+"/users/"+inviteeID+"/roles", "GET", nil, ownerToken, http.StatusOK)
+		var roleIDs []string
+		for _, r := range result["roles"].([]interface{}) {
+			roleIDs = append(roleIDs, r.(map[string]interface{})["id"].(string))
+		}
+		assert.Equal(t, []string{roleID}, roleIDs)
+	})
+
+	t.Run("Invitation is accepted", func(t *testing.T) {
+		require.NotEmpty(t, invitationID)
+
+		result := decodeResponse(t, srv.URL+"/invitations/"+invitationID, "GET", nil, ownerToken, http.StatusOK)
+		invitation := result["invitation"].(map[string]interface{})
+		assert.Equal(t, "accepted", invitation["status"])
+	})
+}
+
+// decodeResponse makes a request, checks its status and returns the data of
+// the response envelope
+func decodeResponse(t testing.TB, url, method string, payload interface{}, token string, status int) map[string]interface{} {
+	t.Helper()
+
+	resp, err := makeRequest(url, method, payload, token)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, status, resp.StatusCode, "unexpected response: %v", result)
+	require.Equal(t, true, result["success"])
+
+	return result["data"].(map[string]interface{})
+}
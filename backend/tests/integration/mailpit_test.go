@@ -0,0 +1,152 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Mailpit intercepts the emails the API sends. docker-compose runs it with
+// SMTP on localhost:11025 and its API on localhost:18025; MAILPIT_URL
+// overrides the API address.
+type Mailpit struct {
+	baseURL string
+	client  *http.Client
+}
+
+// MailpitMessage is a message captured by Mailpit
+type MailpitMessage struct {
+	ID      string
+	Subject string
+	To      []MailpitAddress
+	HTML    string
+	Text    string
+}
+
+// MailpitAddress is a sender or recipient of a captured message
+type MailpitAddress struct {
+	Name    string
+	Address string
+}
+
+// newMailpit returns a client for Mailpit's API, skipping the test when
+// Mailpit isn't running
+func newMailpit(t testing.TB) *Mailpit {
+	t.Helper()
+
+	baseURL := os.Getenv("MAILPIT_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:18025"
+	}
+	m := &Mailpit{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	resp, err := m.client.Get(m.baseURL + "/api/v1/info")
+	if err != nil {
+		t.Skipf("mailpit not available: %v", err)
+	}
+	resp.Body.Close()
+
+	return m
+}
+
+// WaitForMessage polls Mailpit until a message to the address whose subject
+// contains subject arrives, and returns it with its body. Outbox delivery is
+// asynchronous, so this waits up to 30 seconds.
+func (m *Mailpit) WaitForMessage(t testing.TB, to, subject string) *MailpitMessage {
+	t.Helper()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		var result struct {
+			Messages []MailpitMessage `json:"messages"`
+		}
+		query := url.Values{"query": {fmt.Sprintf("to:%q", to)}}
+		if err := m.get("/api/v1/search?"+query.Encode(), &result); err != nil {
+			t.Fatalf("failed to search mailpit: %v", err)
+		}
+
+		for _, msg := range result.Messages {
+			if strings.Contains(msg.Subject, subject) {
+				var full MailpitMessage
+				if err := m.get("/api/v1/message/"+msg.ID, &full); err != nil {
+					t.Fatalf("failed to fetch message %s: %v", msg.ID, err)
+				}
+				return &full
+			}
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("no email to %s with subject %q arrived", to, subject)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// DeleteMessages removes messages, so a later wait for the same address
+// doesn't pick them up again
+func (m *Mailpit) DeleteMessages(t testing.TB, msgs ...*MailpitMessage) {
+	t.Helper()
+
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	body, _ := json.Marshal(map[string][]string{"IDs": ids})
+
+	req, err := http.NewRequest(http.MethodDelete, m.baseURL+"/api/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build mailpit request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		t.Fatalf("failed to delete messages: %v", err)
+	}
+	resp.Body.Close()
+}
+
+// tokenPattern matches the token query parameter of a link
+var tokenPattern = `%s\?token=([0-9a-fA-F-]{36})`
+
+// ExtractToken returns the token of the first link to path in the message,
+// e.g. "/accept-invitation", "/auth/verify" or "/reset-password"
+func (msg *MailpitMessage) ExtractToken(t testing.TB, path string) string {
+	t.Helper()
+
+	re := regexp.MustCompile(fmt.Sprintf(tokenPattern, regexp.QuoteMeta(path)))
+	for _, body := range []string{msg.HTML, msg.Text} {
+		if match := re.FindStringSubmatch(body); match != nil {
+			return match[1]
+		}
+	}
+	t.Fatalf("no %s link in email %q", path, msg.Subject)
+	return ""
+}
+
+// get decodes a JSON response of Mailpit's API
+func (m *Mailpit) get(path string, v interface{}) error {
+	resp, err := m.client.Get(m.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mailpit returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
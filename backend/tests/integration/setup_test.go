@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"myerp-v2/internal/config"
+	"myerp-v2/internal/database"
+	"myerp-v2/internal/mail"
+	"myerp-v2/internal/server"
+	"myerp-v2/internal/storage"
+)
+
+// newTestServer starts the API against the database, Redis and SMTP server
+// configured in the environment (see .env.example), with background jobs
+// running so queued emails are delivered. Tests are skipped when the
+// database or Redis can't be reached.
+func newTestServer(t testing.TB) *httptest.Server {
+	t.Helper()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Skipf("integration config not available: %v", err)
+	}
+
+	// Deliver the email outbox quickly so tests can pick messages up from Mailpit
+	cfg.Jobs.Enabled = true
+	cfg.Jobs.EmailPollInterval = 500 * time.Millisecond
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		t.Skipf("database not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	redisClient, err := database.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		t.Skipf("redis not available: %v", err)
+	}
+	t.Cleanup(func() { redisClient.Close() })
+
+	files, err := storage.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to initialize file storage: %v", err)
+	}
+
+	mailer, err := mail.New(&cfg.Email)
+	if err != nil {
+		t.Fatalf("failed to initialize email provider: %v", err)
+	}
+
+	router := server.NewRouter(db, redisClient, files, mailer, cfg)
+	srv := httptest.NewServer(router.Setup())
+	t.Cleanup(srv.Close)
+
+	if err := router.Jobs().Start(context.Background()); err != nil {
+		t.Fatalf("failed to start background jobs: %v", err)
+	}
+	t.Cleanup(router.Jobs().Stop)
+
+	return srv
+}
+
+// randomString returns a random lowercase hex string of the given length,
+// for unique slugs and email addresses
+func randomString(length int) string {
+	b := make([]byte, (length+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)[:length]
+}
@@ -0,0 +1,112 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTwoFactorSignIn enables TOTP, signs out and signs back in with a code
+// of the authenticator app, then with a backup code, which can only be used
+// once
+func TestTwoFactorSignIn(t *testing.T) {
+	srv := newTestServer(t)
+	mail := newMailpit(t)
+
+	email := "2fa-" + randomString(8) + "@example.com"
+	password := "TwoFactor@123456"
+	registerTenant(t, srv.URL, mail, email, password)
+	token := login(t, srv.URL, email, password)
+
+	var secret string
+	var backupCodes []string
+	t.Run("Enable 2FA", func(t *testing.T) {
+		setup := decodeResponse(t, srv.URL+"/2fa/setup", "POST", nil, token, http.StatusOK)
+		secret = setup["secret"].(string)
+		for _, code := range setup["backup_codes"].([]interface{}) {
+			backupCodes = append(backupCodes, code.(string))
+		}
+		require.NotEmpty(t, backupCodes)
+
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		decodeResponse(t, srv.URL+"/2fa/enable", "POST", map[string]interface{}{
+			"secret":            secret,
+			"verification_code": code,
+			"backup_codes":      backupCodes,
+		}, token, http.StatusOK)
+	})
+
+	t.Run("Sign out", func(t *testing.T) {
+		resp, err := makeRequest(srv.URL+"/auth/logout", "POST", nil, token)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	// signIn starts a password sign-in and returns its 2FA token
+	signIn := func(t *testing.T) string {
+		data := decodeResponse(t, srv.URL+"/auth/login", "POST", map[string]interface{}{
+			"email":    email,
+			"password": password,
+		}, "", http.StatusOK)
+		assert.Equal(t, true, data["requires_two_factor"])
+		assert.Empty(t, data["access_token"])
+		require.NotEmpty(t, data["two_factor_token"])
+		return data["two_factor_token"].(string)
+	}
+
+	verify := func(t *testing.T, twoFactorToken, code string) *http.Response {
+		resp, err := makeRequest(srv.URL+"/auth/verify-2fa", "POST", map[string]interface{}{
+			"two_factor_token": twoFactorToken,
+			"code":             code,
+		}, "")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("Sign in with a wrong code", func(t *testing.T) {
+		code, err := totp.GenerateCode(secret, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		resp := verify(t, signIn(t), code)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Sign in with a TOTP code", func(t *testing.T) {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		data := decodeResponse(t, srv.URL+"/auth/verify-2fa", "POST", map[string]interface{}{
+			"two_factor_token": signIn(t),
+			"code":             code,
+		}, "", http.StatusOK)
+		require.NotEmpty(t, data["access_token"])
+
+		resp, err := makeRequest(srv.URL+"/auth/me", "GET", nil, data["access_token"].(string))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Sign in with a backup code", func(t *testing.T) {
+		data := decodeResponse(t, srv.URL+"/auth/verify-2fa", "POST", map[string]interface{}{
+			"two_factor_token": signIn(t),
+			"code":             backupCodes[0],
+		}, "", http.StatusOK)
+		assert.NotEmpty(t, data["access_token"])
+
+		// The backup code was consumed
+		resp := verify(t, signIn(t), backupCodes[0])
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}